@@ -9,3 +9,4 @@ require (
 	github.com/spf13/cobra v1.8.1
 )
 
+require github.com/spf13/pflag v1.0.5 // indirect
@@ -8,6 +8,11 @@
 // Usage:
 //
 //	dev-status [flags]
+//	dev-status up [component...]      Start the local stack and wait for health
+//	dev-status down                   Stop the local stack
+//	dev-status restart <component>    Restart one component and wait for health
+//	dev-status logs <component>       Show (or --follow) one component's logs
+//	dev-status ttl --host HOST        Check a remote workspace's TTL (cron-friendly)
 //
 // Flags:
 //
@@ -17,7 +22,10 @@
 //	--human               Output human-readable format
 //	--timeout SECONDS     Component check timeout (default: 2)
 //	--component NAME      Check specific component only
-//	--diagnose            Show diagnostic information (port conflicts, etc.)
+//	--diagnose            Show diagnostic information (port conflicts, TTL, etc.)
+//	--workspace NAME      Remote workspace name (remote mode)
+//	--ttl-warn-at DUR     TTL warning thresholds (default: 4h,1h)
+//	--enforce             Stop expired remote workspaces (--teardown also removes volumes)
 package main
 
 import (
@@ -68,7 +76,10 @@ var rootCmd = &cobra.Command{
 	Short: "Check development stack component health",
 	Long: `Check health status of development stack components (PostgreSQL, Redis, NATS, MinIO, mock inference).
 
-Supports both local and remote modes. For remote mode, use SSH to execute checks on the workspace.`,
+Supports both local and remote modes. For remote mode, use SSH to execute checks on the workspace.
+
+The up, down, restart and logs subcommands manage the local stack through
+docker compose, so problems found by the checks can be fixed from the same tool.`,
 	RunE: runStatus,
 }
 
@@ -152,7 +163,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 func checkLocalComponents(ctx context.Context, filter string) []ComponentStatus {
 	var components []ComponentStatus
-	componentsToCheck := []string{"postgres", "redis", "nats", "minio", "mock-inference"}
+	componentsToCheck := stackComponents
 
 	if filter != "" {
 		componentsToCheck = []string{filter}
@@ -237,6 +248,8 @@ type TTLWarning struct {
 	TTLHours    int    `json:"ttl_hours"`
 	AgeHours    int    `json:"age_hours"`
 	Expired     bool   `json:"expired"`
+	Threshold   string `json:"threshold,omitempty"` // Warning threshold crossed
+	Action      string `json:"action,omitempty"`    // Enforcement taken: stop, teardown
 	Remediation string `json:"remediation"`
 }
 
@@ -258,7 +271,11 @@ func runDiagnose(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check TTL warnings
-		result.TTLWarnings = checkRemoteTTL()
+		warnings, err := checkRemoteTTL(cmd.Context())
+		if err != nil {
+			result.ConfigIssues = append(result.ConfigIssues, err.Error())
+		}
+		result.TTLWarnings = warnings
 
 		// Remote network/config checks would go here
 		result.NetworkIssues = []string{"Remote diagnostics not fully implemented"}
@@ -337,15 +354,6 @@ func checkPort(port, service string) *PortConflict {
 	}
 }
 
-func checkRemoteTTL() []TTLWarning {
-	var warnings []TTLWarning
-
-	// Placeholder: In real implementation, would SSH to remote and check TTL metadata
-	// For now, return empty (would need SSH integration)
-
-	return warnings
-}
-
 func checkNetworkIssues() []string {
 	var issues []string
 
@@ -359,9 +367,6 @@ func checkConfigIssues() []string {
 	var issues []string
 
 	// Check if compose files exist
-	composeBase := ".dev/compose/compose.base.yaml"
-	composeLocal := ".dev/compose/compose.local.yaml"
-
 	if _, err := os.Stat(composeBase); os.IsNotExist(err) {
 		issues = append(issues, fmt.Sprintf("Compose base file not found: %s", composeBase))
 	}
@@ -391,7 +396,11 @@ func printDiagnosticHuman(result DiagnosticResult) {
 			}
 			fmt.Fprintf(os.Stderr, "  - Workspace %s: %s (age: %dh, TTL: %dh)\n",
 				warning.Workspace, status, warning.AgeHours, warning.TTLHours)
-			fmt.Fprintf(os.Stderr, "    Remediation: %s\n", warning.Remediation)
+			if warning.Action != "" {
+				fmt.Fprintf(os.Stderr, "    Action: %s (recorded in workspace audit log)\n", warning.Action)
+			} else {
+				fmt.Fprintf(os.Stderr, "    Remediation: %s\n", warning.Remediation)
+			}
 		}
 	}
 
@@ -433,7 +442,7 @@ func updateEndpointPort(endpoint, newPort string) string {
 	}
 
 	// Fallback: Try to parse as host:port (no scheme)
-	if host, oldPort, err := net.SplitHostPort(endpoint); err == nil {
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
 		// Valid host:port format - replace port
 		return net.JoinHostPort(host, newPort)
 	}
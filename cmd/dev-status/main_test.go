@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -90,3 +95,120 @@ func TestStatusOutput(t *testing.T) {
 // Note: Actual health check tests (checkPostgres, checkRedis, etc.) would require
 // either mock servers or integration test setup with actual services running.
 // These are left as integration tests rather than unit tests.
+
+func TestStackCommands(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".dev", "compose"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, composeBase), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(root, "cmd", "dev-status")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+
+	var gotDir string
+	var gotArgs []string
+	orig := runCompose
+	runCompose = func(ctx context.Context, dir string, env, args []string) error {
+		gotDir, gotArgs = dir, args
+		return nil
+	}
+	t.Cleanup(func() { runCompose = orig })
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() { rootCmd.SetOut(nil); rootCmd.SetErr(nil) })
+
+	rootCmd.SetArgs([]string{"logs", "redis", "--follow"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("logs failed: %v", err)
+	}
+	want := "compose -f .dev/compose/compose.base.yaml -f .dev/compose/compose.local.yaml logs --tail=200 --follow redis"
+	if strings.Join(gotArgs, " ") != want {
+		t.Errorf("compose args = %q, want %q", strings.Join(gotArgs, " "), want)
+	}
+	if resolved, _ := filepath.EvalSymlinks(gotDir); resolved != mustEvalSymlinks(t, root) {
+		t.Errorf("compose ran in %s, want repository root %s", gotDir, root)
+	}
+
+	rootCmd.SetArgs([]string{"restart", "kafka"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown component") {
+		t.Errorf("expected unknown component error, got %v", err)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
+
+func TestCheckRemoteTTL(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var commands []string
+	origRemote, origNow := runRemote, now
+	runRemote = func(ctx context.Context, h, command string) ([]byte, error) {
+		commands = append(commands, command)
+		return []byte(`{"workspace":"ws-1","owner":"dev","created_at":"2026-01-01T00:00:00Z","ttl_hours":24}`), nil
+	}
+	t.Cleanup(func() {
+		runRemote, now = origRemote, origNow
+		host, enforceTTL, teardownTTL, workspaceAudit = "", false, false, ""
+	})
+	host = "dev-ws-1"
+	workspaceAudit = filepath.Join(t.TempDir(), "workspace-audit.log")
+
+	// Plenty of TTL left: nothing to report
+	now = func() time.Time { return created.Add(2 * time.Hour) }
+	warnings, err := checkRemoteTTL(context.Background())
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v (err %v)", warnings, err)
+	}
+
+	// Inside the 1h threshold
+	now = func() time.Time { return created.Add(23*time.Hour + 30*time.Minute) }
+	warnings, err = checkRemoteTTL(context.Background())
+	if err != nil || len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %+v (err %v)", warnings, err)
+	}
+	if warnings[0].Expired || warnings[0].Threshold != "1h0m0s" {
+		t.Errorf("unexpected warning: %+v", warnings[0])
+	}
+
+	// Expired without --enforce: warn only, no remote action
+	now = func() time.Time { return created.Add(30 * time.Hour) }
+	commands = nil
+	warnings, _ = checkRemoteTTL(context.Background())
+	if !warnings[0].Expired || warnings[0].Action != "" || len(commands) != 1 {
+		t.Errorf("expected a warning without action, got %+v after %v", warnings[0], commands)
+	}
+
+	// Expired with --enforce --teardown: stack removed and audited
+	enforceTTL, teardownTTL = true, true
+	commands = nil
+	warnings, _ = checkRemoteTTL(context.Background())
+	if warnings[0].Action != "teardown" {
+		t.Errorf("expected teardown action, got %+v", warnings[0])
+	}
+	if len(commands) != 2 || !strings.Contains(commands[1], "down --volumes") {
+		t.Errorf("unexpected remote commands: %v", commands)
+	}
+
+	data, err := os.ReadFile(workspaceAudit)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	var entry WorkspaceAuditEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("parse audit log: %v", err)
+	}
+	if entry.Workspace != "ws-1" || entry.Action != "teardown" || entry.Outcome != "success" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Compose files for the local stack, relative to the repository root.
+const (
+	composeBase  = ".dev/compose/compose.base.yaml"
+	composeLocal = ".dev/compose/compose.local.yaml"
+)
+
+// stackComponents are the compose services dev-status knows how to check.
+var stackComponents = []string{"postgres", "redis", "nats", "minio", "mock-inference"}
+
+// portEnvVars maps components to the variables the compose files read their
+// host ports from (see .specify/local/ports.yaml).
+var portEnvVars = map[string]string{
+	"postgres":       "POSTGRES_PORT",
+	"redis":          "REDIS_PORT",
+	"nats":           "NATS_CLIENT_PORT",
+	"minio":          "MINIO_API_PORT",
+	"mock-inference": "MOCK_INFERENCE_PORT",
+}
+
+var (
+	stackWait   time.Duration
+	stackFollow bool
+	stackTail   int
+)
+
+// runCompose executes docker compose; tests replace it.
+var runCompose = func(ctx context.Context, dir string, env, args []string) error {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up [component...]",
+	Short: "Start the local stack (or the given components)",
+	Args:  validComponents,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := compose(cmd.Context(), append([]string{"up", "-d"}, args...)...); err != nil {
+			return err
+		}
+		return waitHealthy(cmd.Context(), args)
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop the local stack (volumes are kept)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return compose(cmd.Context(), "down")
+	},
+}
+
+var restartCmd = &cobra.Command{
+	Use:   "restart <component>",
+	Short: "Restart one component and wait for it to become healthy",
+	Args:  cobra.MatchAll(cobra.ExactArgs(1), validComponents),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := compose(cmd.Context(), "restart", args[0]); err != nil {
+			return err
+		}
+		return waitHealthy(cmd.Context(), args)
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <component>",
+	Short: "Show logs for one component",
+	Args:  cobra.MatchAll(cobra.ExactArgs(1), validComponents),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logArgs := []string{"logs", fmt.Sprintf("--tail=%d", stackTail)}
+		if stackFollow {
+			logArgs = append(logArgs, "--follow")
+		}
+		return compose(cmd.Context(), append(logArgs, args[0])...)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{upCmd, restartCmd} {
+		c.Flags().DurationVar(&stackWait, "wait", 60*time.Second, "Wait this long for components to become healthy (0 to skip)")
+	}
+	logsCmd.Flags().BoolVarP(&stackFollow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().IntVar(&stackTail, "tail", 200, "Number of lines to show from the end of the logs")
+
+	rootCmd.AddCommand(upCmd, downCmd, restartCmd, logsCmd)
+}
+
+func validComponents(cmd *cobra.Command, args []string) error {
+	for _, arg := range args {
+		if !isStackComponent(arg) {
+			return fmt.Errorf("unknown component %q (valid: %s)", arg, strings.Join(stackComponents, ", "))
+		}
+	}
+	return nil
+}
+
+func isStackComponent(name string) bool {
+	for _, c := range stackComponents {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compose runs a docker compose subcommand against the local stack files
+// from the repository root, with port overrides in the environment.
+func compose(ctx context.Context, args ...string) error {
+	if mode != "local" {
+		return fmt.Errorf("stack lifecycle commands only support local mode (use the remote-* make targets for remote workspaces)")
+	}
+
+	root, err := findStackRoot()
+	if err != nil {
+		return err
+	}
+	if err := runCompose(ctx, root, portOverrides(), composeArgs(args)); err != nil {
+		return fmt.Errorf("docker compose %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// composeArgs prefixes a compose subcommand with the stack's compose files.
+func composeArgs(args []string) []string {
+	return append([]string{"compose", "-f", composeBase, "-f", composeLocal}, args...)
+}
+
+// portOverrides exports the port mappings from ports.yaml so compose binds the
+// same host ports the status checks probe.
+func portOverrides() []string {
+	var env []string
+	for name, port := range loadPortMappings() {
+		if envVar, ok := portEnvVars[name]; ok && port != "" {
+			env = append(env, envVar+"="+port)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// findStackRoot walks up from the working directory to the directory holding
+// the compose files, so the commands also work from cmd/dev-status (make).
+func findStackRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, composeBase)); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("compose file %s not found in this or any parent directory", composeBase)
+		}
+		dir = parent
+	}
+}
+
+// waitHealthy polls the status checks until every component in names (all
+// components when empty) is healthy or --wait elapses.
+func waitHealthy(ctx context.Context, names []string) error {
+	if stackWait <= 0 {
+		return nil
+	}
+	if len(names) == 0 {
+		names = stackComponents
+	}
+
+	deadline := time.Now().Add(stackWait)
+	for {
+		var pending []string
+		for _, name := range names {
+			if status := checkComponent(ctx, name); status.State != "healthy" {
+				pending = append(pending, fmt.Sprintf("%s (%s)", name, status.Message))
+			}
+		}
+		if len(pending) == 0 {
+			fmt.Fprintf(os.Stderr, "All components healthy: %s\n", strings.Join(names, ", "))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("not healthy after %s: %s (inspect with 'dev-status logs <component>')", stackWait, strings.Join(pending, "; "))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w", strings.Join(pending, "; "), ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Defaults for remote workspace TTL handling (see the remote-* make targets).
+const (
+	defaultTTL           = 24 * time.Hour
+	defaultTTLMetadata   = "~/.ai-aas/workspace.json"
+	defaultStackDir      = "~/ai-aas"
+	workspaceAuditFile   = ".ai-aas/workspace-audit.log"
+	remoteCommandTimeout = 30 * time.Second
+)
+
+var (
+	workspace      string
+	ttlMetadata    string
+	ttlWarnAt      []time.Duration
+	enforceTTL     bool
+	teardownTTL    bool
+	workspaceAudit string
+)
+
+// WorkspaceMetadata is the TTL record written on the remote host at
+// provisioning time.
+type WorkspaceMetadata struct {
+	Workspace string    `json:"workspace"`
+	Owner     string    `json:"owner,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	TTLHours  int       `json:"ttl_hours,omitempty"`
+	StackDir  string    `json:"stack_dir,omitempty"`
+}
+
+// TTL returns the workspace TTL, defaulting to 24h.
+func (m WorkspaceMetadata) TTL() time.Duration {
+	if m.TTLHours <= 0 {
+		return defaultTTL
+	}
+	return time.Duration(m.TTLHours) * time.Hour
+}
+
+// WorkspaceAuditEntry is one line of the local workspace audit log.
+type WorkspaceAuditEntry struct {
+	Timestamp string `json:"timestamp"`
+	Host      string `json:"host"`
+	Workspace string `json:"workspace"`
+	Action    string `json:"action"`  // stop, teardown
+	Outcome   string `json:"outcome"` // success, failure
+	Reason    string `json:"reason"`
+	Error     string `json:"error,omitempty"`
+}
+
+// runRemote runs a shell command on host over SSH; tests replace it.
+var runRemote = func(ctx context.Context, host, command string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", host, command).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("ssh %s: %w: %s", host, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// now is the clock used for TTL decisions; tests replace it.
+var now = time.Now
+
+var ttlCmd = &cobra.Command{
+	Use:   "ttl",
+	Short: "Check (and with --enforce, act on) a remote workspace's TTL",
+	Long: `Read the TTL metadata from the remote workspace and warn when it is close to
+expiring. With --enforce an expired workspace's stack is stopped (or, with
+--teardown, stopped and its volumes removed); every action is recorded in
+~/.ai-aas/workspace-audit.log.
+
+Intended to run from cron on the machine that owns the workspace.`,
+	Example: `  # Warn about expiry
+  dev-status ttl --host dev-ws-1
+
+  # Tear down the workspace once its TTL has passed
+  dev-status ttl --host dev-ws-1 --enforce --teardown`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if host == "" {
+			return errors.New("--host required for ttl checks")
+		}
+		warnings, err := checkRemoteTTL(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if humanOutput {
+			printDiagnosticHuman(DiagnosticResult{TTLWarnings: warnings})
+		} else {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(DiagnosticResult{TTLWarnings: warnings}); err != nil {
+				return fmt.Errorf("encode JSON: %w", err)
+			}
+		}
+
+		for _, w := range warnings {
+			if w.Expired && w.Action == "" {
+				return fmt.Errorf("workspace %s has expired", w.Workspace)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "Remote workspace name (defaults to the name in the TTL metadata)")
+	rootCmd.PersistentFlags().StringVar(&ttlMetadata, "ttl-metadata", defaultTTLMetadata, "Path of the TTL metadata file on the remote host")
+	rootCmd.PersistentFlags().DurationSliceVar(&ttlWarnAt, "ttl-warn-at", []time.Duration{4 * time.Hour, time.Hour}, "Warn when a remote workspace has less than this much TTL left")
+	rootCmd.PersistentFlags().BoolVar(&enforceTTL, "enforce", false, "Stop expired remote workspaces")
+	rootCmd.PersistentFlags().BoolVar(&teardownTTL, "teardown", false, "With --enforce, also remove the expired workspace's volumes")
+	rootCmd.PersistentFlags().StringVar(&workspaceAudit, "audit-log", "", "Workspace audit log (default ~/"+workspaceAuditFile+")")
+
+	rootCmd.AddCommand(ttlCmd)
+}
+
+// checkRemoteTTL reads the remote workspace's TTL metadata and returns a
+// warning when it is within a --ttl-warn-at threshold or expired. With
+// --enforce an expired workspace is stopped and the action audited.
+func checkRemoteTTL(ctx context.Context) ([]TTLWarning, error) {
+	out, err := runRemote(ctx, host, "cat "+ttlMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("read TTL metadata %s: %w", ttlMetadata, err)
+	}
+	var meta WorkspaceMetadata
+	if err := json.Unmarshal(out, &meta); err != nil {
+		return nil, fmt.Errorf("parse TTL metadata %s: %w", ttlMetadata, err)
+	}
+	if meta.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("TTL metadata %s has no created_at", ttlMetadata)
+	}
+	if workspace != "" && meta.Workspace != "" && workspace != meta.Workspace {
+		return nil, fmt.Errorf("host %s runs workspace %q, not %q", host, meta.Workspace, workspace)
+	}
+	if meta.Workspace == "" {
+		meta.Workspace = workspace
+	}
+
+	warning, ok := evaluateTTL(meta, now())
+	if !ok {
+		return nil, nil
+	}
+	if warning.Expired && enforceTTL {
+		warning.Action = enforceExpiry(ctx, meta)
+	}
+	return []TTLWarning{warning}, nil
+}
+
+// evaluateTTL reports whether meta is expired or inside a warning threshold.
+func evaluateTTL(meta WorkspaceMetadata, at time.Time) (TTLWarning, bool) {
+	age := at.Sub(meta.CreatedAt)
+	remaining := meta.TTL() - age
+	warning := TTLWarning{
+		Workspace: meta.Workspace,
+		TTLHours:  int(meta.TTL().Hours()),
+		AgeHours:  int(age.Hours()),
+		Expired:   remaining <= 0,
+	}
+
+	if warning.Expired {
+		warning.Remediation = fmt.Sprintf("Workspace expired %s ago: run 'make remote-destroy WORKSPACE_HOST=%s WORKSPACE_NAME=%s' or re-run with --enforce",
+			(-remaining).Round(time.Minute), host, meta.Workspace)
+		return warning, true
+	}
+
+	thresholds := append([]time.Duration(nil), ttlWarnAt...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	for _, threshold := range thresholds {
+		if remaining <= threshold {
+			warning.Threshold = threshold.String()
+			warning.Remediation = fmt.Sprintf("Workspace expires in %s: save work, or re-provision with a longer TTL to keep it", remaining.Round(time.Minute))
+			return warning, true
+		}
+	}
+	return warning, false
+}
+
+// enforceExpiry stops (or tears down) an expired workspace's stack and
+// records the outcome in the audit log. It returns the action taken, or an
+// empty string when the action failed.
+func enforceExpiry(ctx context.Context, meta WorkspaceMetadata) string {
+	action := "stop"
+	down := "docker compose -f " + composeBase + " -f " + composeLocal + " down"
+	if teardownTTL {
+		action = "teardown"
+		down += " --volumes --remove-orphans"
+	}
+	stackDir := meta.StackDir
+	if stackDir == "" {
+		stackDir = defaultStackDir
+	}
+
+	entry := WorkspaceAuditEntry{
+		Timestamp: now().UTC().Format(time.RFC3339),
+		Host:      host,
+		Workspace: meta.Workspace,
+		Action:    action,
+		Outcome:   "success",
+		Reason:    fmt.Sprintf("TTL of %dh exceeded (created %s)", int(meta.TTL().Hours()), meta.CreatedAt.UTC().Format(time.RFC3339)),
+	}
+	if _, err := runRemote(ctx, host, "cd "+stackDir+" && "+down); err != nil {
+		entry.Outcome = "failure"
+		entry.Error = err.Error()
+		action = ""
+	}
+
+	if err := appendWorkspaceAudit(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write workspace audit log: %v\n", err)
+	}
+	return action
+}
+
+// appendWorkspaceAudit appends entry as a JSON line to the audit log.
+func appendWorkspaceAudit(entry WorkspaceAuditEntry) error {
+	path := workspaceAudit
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, workspaceAuditFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
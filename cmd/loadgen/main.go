@@ -0,0 +1,266 @@
+// Command loadgen drives the API router's inference path with configurable
+// concurrency, payload sizes and API key distribution, and reports latency
+// percentiles and error rates.
+//
+// Purpose:
+//
+//	Gives developers and CI a repeatable load test for the router. Pair it
+//	with cmd/mock-inference behind the router to measure router overhead
+//	without GPUs. SLO flags turn a run into a performance gate: loadgen exits
+//	non-zero when any threshold is violated.
+//
+// Usage:
+//
+//	loadgen [flags]
+//
+// Flags:
+//
+//	--target URL             Router base URL (default: http://localhost:8080)
+//	--endpoint NAME          chat, completions or inference (default: chat)
+//	--model NAME             Model requested (default: mock-model)
+//	--concurrency N          Concurrent workers (default: 10)
+//	--duration D             Run length (default: 30s; ignored when --requests is set)
+//	--requests N             Total requests to send (default: 0 = use --duration)
+//	--rate RPS               Global request rate limit (default: 0 = unlimited)
+//	--payload-sizes SPEC     Payload bytes with optional weights, e.g. "256:0.8,4096:0.2" (default: 256)
+//	--api-keys LIST          Comma-separated API keys sent as X-API-Key
+//	--api-keys-file FILE     File with one API key per line (# comments allowed)
+//	--key-dist DIST          uniform, zipf or round-robin (default: uniform)
+//	--timeout D              Per-request timeout (default: 30s)
+//	--format FORMAT          text or json (default: text)
+//	--seed N                 Random seed for payload and key sampling (default: 1)
+//	--slo-p50 D, --slo-p95 D, --slo-p99 D   Latency thresholds
+//	--slo-error-rate RATE    Maximum error rate (0-1)
+//	--slo-min-rps RPS        Minimum throughput
+//
+// Debugging Notes:
+//   - Latency percentiles cover 2xx responses only; errors are counted
+//     separately so fast failures don't hide slow successes
+//   - API keys are masked in the per-key breakdown of the JSON report
+//   - Exit codes: 0 success, 1 SLO violation, 2 invalid flags
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// runConfig controls a load test run.
+type runConfig struct {
+	Concurrency int
+	Duration    time.Duration
+	Requests    uint64
+	Rate        float64
+	Timeout     time.Duration
+}
+
+func main() {
+	var (
+		target      = flag.String("target", "http://localhost:8080", "Router base URL")
+		endpoint    = flag.String("endpoint", EndpointChat, "Endpoint: chat, completions, inference")
+		model       = flag.String("model", "mock-model", "Model requested")
+		concurrency = flag.Int("concurrency", 10, "Concurrent workers")
+		duration    = flag.Duration("duration", 30*time.Second, "Run length (ignored when -requests is set)")
+		requests    = flag.Uint64("requests", 0, "Total requests to send (0 = use -duration)")
+		rate        = flag.Float64("rate", 0, "Global request rate limit in requests/second (0 = unlimited)")
+		sizeSpec    = flag.String("payload-sizes", "256", "Payload bytes with optional weights, e.g. 256:0.8,4096:0.2")
+		keyList     = flag.String("api-keys", "", "Comma-separated API keys")
+		keyFile     = flag.String("api-keys-file", "", "File with one API key per line")
+		keyDist     = flag.String("key-dist", "uniform", "API key distribution: uniform, zipf, round-robin")
+		timeout     = flag.Duration("timeout", 30*time.Second, "Per-request timeout")
+		format      = flag.String("format", "text", "Report format: text, json")
+		seed        = flag.Int64("seed", 1, "Random seed for payload and key sampling")
+		sloP50      = flag.Duration("slo-p50", 0, "Fail if p50 latency exceeds this")
+		sloP95      = flag.Duration("slo-p95", 0, "Fail if p95 latency exceeds this")
+		sloP99      = flag.Duration("slo-p99", 0, "Fail if p99 latency exceeds this")
+		sloErrRate  = flag.Float64("slo-error-rate", 0, "Fail if the error rate (0-1) exceeds this")
+		sloMinRPS   = flag.Float64("slo-min-rps", 0, "Fail if throughput falls below this")
+	)
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		usageError(fmt.Errorf("unknown format %q (want text or json)", *format))
+	}
+	if *concurrency < 1 {
+		usageError(fmt.Errorf("concurrency must be at least 1"))
+	}
+	sizes, err := parsePayloadSizes(*sizeSpec)
+	if err != nil {
+		usageError(err)
+	}
+	keys, err := loadKeys(*keyList, *keyFile)
+	if err != nil {
+		usageError(err)
+	}
+	wl, err := newWorkload(*target, *endpoint, *model, sizes, keys, *keyDist, *seed)
+	if err != nil {
+		usageError(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := &http.Client{Transport: &http.Transport{
+		MaxIdleConns:        *concurrency,
+		MaxIdleConnsPerHost: *concurrency,
+	}}
+	summary := run(ctx, client, wl, runConfig{
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		Requests:    *requests,
+		Rate:        *rate,
+		Timeout:     *timeout,
+	})
+	summary.Violations = SLO{
+		P50:          *sloP50,
+		P95:          *sloP95,
+		P99:          *sloP99,
+		MaxErrorRate: *sloErrRate,
+		MinRPS:       *sloMinRPS,
+	}.check(summary)
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(summary)
+	} else {
+		printText(os.Stdout, summary)
+	}
+	if len(summary.Violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// run sends requests until the request budget or duration is exhausted, or
+// ctx is cancelled.
+func run(ctx context.Context, client *http.Client, wl *workload, cfg runConfig) Summary {
+	if cfg.Requests == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var ticks <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	var (
+		seq  atomic.Uint64
+		col  collector
+		wg   sync.WaitGroup
+		rmu  sync.Mutex
+		next = func() (uint64, bool) {
+			if ticks != nil {
+				rmu.Lock()
+				defer rmu.Unlock()
+				select {
+				case <-ticks:
+				case <-ctx.Done():
+					return 0, false
+				}
+			}
+			if ctx.Err() != nil {
+				return 0, false
+			}
+			n := seq.Add(1)
+			if cfg.Requests > 0 && n > cfg.Requests {
+				return 0, false
+			}
+			return n, true
+		}
+	)
+
+	start := time.Now()
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n, ok := next()
+				if !ok {
+					return
+				}
+				res, sent := send(ctx, client, wl, n, cfg.Timeout)
+				if sent {
+					col.add(res)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summarize(col.results, time.Since(start))
+}
+
+// send issues request n. sent is false when the run ended mid-request, so
+// requests interrupted by the deadline aren't reported as failures.
+func send(ctx context.Context, client *http.Client, wl *workload, n uint64, timeout time.Duration) (res result, sent bool) {
+	req, key, err := wl.request(n)
+	if err != nil {
+		return result{key: key}, true
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(reqCtx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return result{}, false
+		}
+		return result{latency: time.Since(start), key: key}, true
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return result{latency: time.Since(start), status: resp.StatusCode, key: key}, true
+}
+
+// loadKeys merges keys from a comma-separated list and an optional file.
+func loadKeys(list, path string) ([]string, error) {
+	var keys []string
+	for _, k := range strings.Split(list, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if path == "" {
+		return keys, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open api keys file: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read api keys file: %w", err)
+	}
+	return keys, nil
+}
+
+func usageError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(2)
+}
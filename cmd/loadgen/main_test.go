@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParsePayloadSizes(t *testing.T) {
+	sizes, err := parsePayloadSizes("256:0.8, 4096:0.2,64")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(sizes) != 3 || sizes[0].bytes != 256 || sizes[1].weight != 0.2 || sizes[2].weight != 1 {
+		t.Fatalf("unexpected sizes: %+v", sizes)
+	}
+	for _, bad := range []string{"", "abc", "0", "256:-1", "256:x"} {
+		if _, err := parsePayloadSizes(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestPercentileNearestRank(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	cases := map[float64]time.Duration{50: 50 * time.Millisecond, 95: 95 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond, 0: time.Millisecond}
+	for p, want := range cases {
+		if got := percentile(latencies, p); got != want {
+			t.Fatalf("p%.0f: expected %s, got %s", p, want, got)
+		}
+	}
+}
+
+func TestSummarizeAndSLO(t *testing.T) {
+	results := []result{
+		{latency: 10 * time.Millisecond, status: 200, key: "key-aaaaaaaa1"},
+		{latency: 20 * time.Millisecond, status: 200, key: "key-aaaaaaaa1"},
+		{latency: 30 * time.Millisecond, status: 200, key: "key-bbbbbbbb2"},
+		{latency: time.Millisecond, status: 429, key: "key-bbbbbbbb2"},
+		{latency: time.Millisecond, status: 0},
+	}
+	s := summarize(results, time.Second)
+
+	if s.Requests != 5 || s.Successes != 3 || s.Errors != 2 {
+		t.Fatalf("unexpected counts: %+v", s)
+	}
+	if s.ErrorRate != 0.4 || s.Throughput != 5 {
+		t.Fatalf("unexpected rates: error=%f rps=%f", s.ErrorRate, s.Throughput)
+	}
+	if s.Latency.Min != 10 || s.Latency.P50 != 20 || s.Latency.Max != 30 {
+		t.Fatalf("expected percentiles over successes only: %+v", s.Latency)
+	}
+	if s.StatusCounts["200"] != 3 || s.StatusCounts["429"] != 1 || s.StatusCounts["transport_error"] != 1 {
+		t.Fatalf("unexpected status counts: %v", s.StatusCounts)
+	}
+	for k := range s.KeyCounts {
+		if strings.Contains(k, "aaaaaaaa") {
+			t.Fatalf("expected masked key, got %q", k)
+		}
+	}
+
+	if v := (SLO{P99: 50 * time.Millisecond, MaxErrorRate: 0.5}).check(s); len(v) != 0 {
+		t.Fatalf("expected no violations, got %v", v)
+	}
+	v := SLO{P50: 15 * time.Millisecond, MaxErrorRate: 0.1, MinRPS: 10}.check(s)
+	if len(v) != 3 {
+		t.Fatalf("expected p50, error rate and throughput violations, got %v", v)
+	}
+}
+
+func TestKeyDistributions(t *testing.T) {
+	sizes := []weightedSize{{bytes: 16, weight: 1}}
+	keys := []string{"a", "b", "c", "d"}
+
+	rr, err := newWorkload("http://x", EndpointChat, "m", sizes, keys, "round-robin", 1)
+	if err != nil {
+		t.Fatalf("workload: %v", err)
+	}
+	for n := uint64(0); n < 8; n++ {
+		if _, key := rr.pick(n); key != keys[n%4] {
+			t.Fatalf("round-robin request %d: got %q", n, key)
+		}
+	}
+
+	zipf, err := newWorkload("http://x", EndpointChat, "m", sizes, keys, "zipf", 1)
+	if err != nil {
+		t.Fatalf("workload: %v", err)
+	}
+	counts := map[string]int{}
+	for n := uint64(0); n < 2000; n++ {
+		_, key := zipf.pick(n)
+		counts[key]++
+	}
+	if counts["a"] <= counts["d"] {
+		t.Fatalf("expected zipf to favour the first key: %v", counts)
+	}
+
+	if _, err := newWorkload("http://x", "bogus", "m", sizes, keys, "uniform", 1); err == nil {
+		t.Fatal("expected unknown endpoint to fail")
+	}
+	if _, err := newWorkload("http://x", EndpointChat, "m", sizes, keys, "bogus", 1); err == nil {
+		t.Fatal("expected unknown key distribution to fail")
+	}
+}
+
+func TestRunAgainstServer(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		keysSeen = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/inference" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var body struct {
+			RequestID string `json:"request_id"`
+			Model     string `json:"model"`
+			Payload   string `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RequestID == "" || len(body.Payload) != 128 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		key := r.Header.Get("X-API-Key")
+		mu.Lock()
+		keysSeen[key]++
+		mu.Unlock()
+		if key == "bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	wl, err := newWorkload(srv.URL, EndpointInference, "m", []weightedSize{{bytes: 128, weight: 1}}, []string{"good", "bad"}, "round-robin", 1)
+	if err != nil {
+		t.Fatalf("workload: %v", err)
+	}
+	s := run(context.Background(), srv.Client(), wl, runConfig{Concurrency: 4, Requests: 40, Timeout: time.Second})
+
+	if s.Requests != 40 || s.Successes != 20 || s.StatusCounts["401"] != 20 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if keysSeen["good"] != 20 || keysSeen["bad"] != 20 {
+		t.Fatalf("expected round-robin keys, got %v", keysSeen)
+	}
+}
+
+func TestRunStopsAfterDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	wl, err := newWorkload(srv.URL, EndpointChat, "m", []weightedSize{{bytes: 32, weight: 1}}, nil, "uniform", 1)
+	if err != nil {
+		t.Fatalf("workload: %v", err)
+	}
+	start := time.Now()
+	s := run(context.Background(), srv.Client(), wl, runConfig{Concurrency: 2, Duration: 100 * time.Millisecond, Rate: 100, Timeout: time.Second})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("run did not stop at duration: %s", elapsed)
+	}
+	if s.Requests == 0 || s.Requests > 15 || s.Errors != 0 {
+		t.Fatalf("expected rate-limited successful requests, got %+v", s)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// result is the outcome of one request.
+type result struct {
+	latency time.Duration
+	status  int // 0 for transport errors
+	key     string
+}
+
+// collector accumulates results from concurrent workers.
+type collector struct {
+	mu      sync.Mutex
+	results []result
+}
+
+func (c *collector) add(r result) {
+	c.mu.Lock()
+	c.results = append(c.results, r)
+	c.mu.Unlock()
+}
+
+// LatencySummary reports latency percentiles in milliseconds.
+type LatencySummary struct {
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P90  float64 `json:"p90_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// Summary is the aggregated report for a run.
+type Summary struct {
+	Requests   int            `json:"requests"`
+	Successes  int            `json:"successes"`
+	Errors     int            `json:"errors"`
+	ErrorRate  float64        `json:"error_rate"`
+	Throughput float64        `json:"throughput_rps"`
+	Duration   float64        `json:"duration_seconds"`
+	Latency    LatencySummary `json:"latency"`
+	// StatusCounts keys are HTTP status codes, or "transport_error".
+	StatusCounts map[string]int `json:"status_counts"`
+	KeyCounts    map[string]int `json:"key_counts,omitempty"`
+	Violations   []string       `json:"slo_violations,omitempty"`
+}
+
+// summarize computes the run summary. Latency percentiles cover successful
+// (2xx) responses only, so fast failures don't flatter the numbers.
+func summarize(results []result, elapsed time.Duration) Summary {
+	s := Summary{
+		Requests:     len(results),
+		Duration:     elapsed.Seconds(),
+		StatusCounts: make(map[string]int),
+		KeyCounts:    make(map[string]int),
+	}
+
+	var latencies []time.Duration
+	for _, r := range results {
+		label := "transport_error"
+		if r.status != 0 {
+			label = fmt.Sprint(r.status)
+		}
+		s.StatusCounts[label]++
+		if r.key != "" {
+			s.KeyCounts[maskKey(r.key)]++
+		}
+		if r.status >= 200 && r.status < 300 {
+			s.Successes++
+			latencies = append(latencies, r.latency)
+		} else {
+			s.Errors++
+		}
+	}
+	if s.Requests > 0 {
+		s.ErrorRate = float64(s.Errors) / float64(s.Requests)
+	}
+	if elapsed > 0 {
+		s.Throughput = float64(s.Requests) / elapsed.Seconds()
+	}
+	if len(s.KeyCounts) == 0 {
+		s.KeyCounts = nil
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		var total time.Duration
+		for _, l := range latencies {
+			total += l
+		}
+		s.Latency = LatencySummary{
+			Min:  ms(latencies[0]),
+			Mean: ms(total / time.Duration(len(latencies))),
+			P50:  ms(percentile(latencies, 50)),
+			P90:  ms(percentile(latencies, 90)),
+			P95:  ms(percentile(latencies, 95)),
+			P99:  ms(percentile(latencies, 99)),
+			Max:  ms(latencies[len(latencies)-1]),
+		}
+	}
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// SLO thresholds; zero values are not checked.
+type SLO struct {
+	P50          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+	MaxErrorRate float64
+	MinRPS       float64
+}
+
+// check returns a description of every violated threshold.
+func (slo SLO) check(s Summary) []string {
+	var violations []string
+	latency := func(name string, got float64, limit time.Duration) {
+		if limit > 0 && got > ms(limit) {
+			violations = append(violations, fmt.Sprintf("%s latency %.1fms exceeds %s", name, got, limit))
+		}
+	}
+	latency("p50", s.Latency.P50, slo.P50)
+	latency("p95", s.Latency.P95, slo.P95)
+	latency("p99", s.Latency.P99, slo.P99)
+	if slo.MaxErrorRate > 0 && s.ErrorRate > slo.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.4f exceeds %.4f", s.ErrorRate, slo.MaxErrorRate))
+	}
+	if slo.MinRPS > 0 && s.Throughput < slo.MinRPS {
+		violations = append(violations, fmt.Sprintf("throughput %.1f rps below %.1f", s.Throughput, slo.MinRPS))
+	}
+	if s.Successes == 0 && (slo.P50 > 0 || slo.P95 > 0 || slo.P99 > 0) {
+		violations = append(violations, "no successful requests to measure latency")
+	}
+	return violations
+}
+
+// printText writes a human-readable report.
+func printText(w io.Writer, s Summary) {
+	fmt.Fprintf(w, "Requests:    %d (%d ok, %d errors, %.2f%% error rate)\n", s.Requests, s.Successes, s.Errors, s.ErrorRate*100)
+	fmt.Fprintf(w, "Duration:    %.2fs (%.1f req/s)\n", s.Duration, s.Throughput)
+	fmt.Fprintf(w, "Latency:     min=%.1fms mean=%.1fms p50=%.1fms p90=%.1fms p95=%.1fms p99=%.1fms max=%.1fms\n",
+		s.Latency.Min, s.Latency.Mean, s.Latency.P50, s.Latency.P90, s.Latency.P95, s.Latency.P99, s.Latency.Max)
+
+	codes := make([]string, 0, len(s.StatusCounts))
+	for code := range s.StatusCounts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%s=%d", code, s.StatusCounts[code]))
+	}
+	fmt.Fprintf(w, "Status:      %s\n", strings.Join(parts, " "))
+
+	if len(s.Violations) > 0 {
+		fmt.Fprintln(w, "SLO:         FAILED")
+		for _, v := range s.Violations {
+			fmt.Fprintf(w, "  - %s\n", v)
+		}
+	}
+}
+
+// maskKey keeps API keys out of reports.
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "…" + key[len(key)-4:]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
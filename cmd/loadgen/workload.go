@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Router endpoints loadgen can drive.
+const (
+	EndpointChat        = "chat"
+	EndpointCompletions = "completions"
+	EndpointInference   = "inference"
+)
+
+var endpointPaths = map[string]string{
+	EndpointChat:        "/v1/chat/completions",
+	EndpointCompletions: "/v1/completions",
+	EndpointInference:   "/v1/inference",
+}
+
+// weightedSize is a payload size in bytes with a relative selection weight.
+type weightedSize struct {
+	bytes  int
+	weight float64
+}
+
+// parsePayloadSizes parses "size[:weight],..." (e.g. "256:0.8,4096:0.2").
+// Weights default to 1.
+func parsePayloadSizes(spec string) ([]weightedSize, error) {
+	var sizes []weightedSize
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sizeStr, weightStr, hasWeight := strings.Cut(part, ":")
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid payload size %q", sizeStr)
+		}
+		weight := 1.0
+		if hasWeight {
+			weight, err = strconv.ParseFloat(weightStr, 64)
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid payload weight %q", weightStr)
+			}
+		}
+		sizes = append(sizes, weightedSize{bytes: size, weight: weight})
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("at least one payload size is required")
+	}
+	return sizes, nil
+}
+
+// workload builds requests with sampled payload sizes and API keys. It is
+// safe for concurrent use.
+type workload struct {
+	target   string
+	endpoint string
+	model    string
+	sizes    []weightedSize
+	keys     []string
+	keyDist  string
+
+	mu       sync.Mutex
+	rng      *mrand.Rand
+	zipf     *mrand.Zipf
+	sizeCDF  []float64
+	payloads map[int]string
+}
+
+func newWorkload(target, endpoint, model string, sizes []weightedSize, keys []string, keyDist string, seed int64) (*workload, error) {
+	if _, ok := endpointPaths[endpoint]; !ok {
+		return nil, fmt.Errorf("unknown endpoint %q (want chat, completions or inference)", endpoint)
+	}
+	if keyDist != "uniform" && keyDist != "zipf" && keyDist != "round-robin" {
+		return nil, fmt.Errorf("unknown key distribution %q (want uniform, zipf or round-robin)", keyDist)
+	}
+
+	w := &workload{
+		target:   strings.TrimRight(target, "/"),
+		endpoint: endpoint,
+		model:    model,
+		sizes:    sizes,
+		keys:     keys,
+		keyDist:  keyDist,
+		rng:      mrand.New(mrand.NewSource(seed)),
+		payloads: make(map[int]string),
+	}
+
+	var total float64
+	for _, s := range sizes {
+		total += s.weight
+		w.sizeCDF = append(w.sizeCDF, total)
+		w.payloads[s.bytes] = filler(s.bytes)
+	}
+	if keyDist == "zipf" && len(keys) > 1 {
+		w.zipf = mrand.NewZipf(w.rng, 1.1, 1, uint64(len(keys)-1))
+	}
+	return w, nil
+}
+
+// pick returns the payload size and API key for request n.
+func (w *workload) pick(n uint64) (int, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.rng.Float64() * w.sizeCDF[len(w.sizeCDF)-1]
+	size := w.sizes[len(w.sizes)-1].bytes
+	for i, c := range w.sizeCDF {
+		if r < c {
+			size = w.sizes[i].bytes
+			break
+		}
+	}
+
+	if len(w.keys) == 0 {
+		return size, ""
+	}
+	var idx int
+	switch {
+	case w.keyDist == "round-robin":
+		idx = int(n % uint64(len(w.keys)))
+	case w.zipf != nil:
+		idx = int(w.zipf.Uint64())
+	default:
+		idx = w.rng.Intn(len(w.keys))
+	}
+	return size, w.keys[idx]
+}
+
+// request builds the HTTP request for request number n.
+func (w *workload) request(n uint64) (*http.Request, string, error) {
+	size, key := w.pick(n)
+	payload := w.payloads[size]
+
+	var body any
+	switch w.endpoint {
+	case EndpointChat:
+		body = map[string]any{
+			"model":    w.model,
+			"messages": []map[string]string{{"role": "user", "content": payload}},
+		}
+	case EndpointCompletions:
+		body = map[string]any{"model": w.model, "prompt": payload}
+	case EndpointInference:
+		body = map[string]any{"request_id": newUUID(), "model": w.model, "payload": payload}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.target+endpointPaths[w.endpoint], bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	return req, key, nil
+}
+
+// filler returns n bytes of space-separated words.
+func filler(n int) string {
+	const words = "the quick brown fox jumps over the lazy dog "
+	repeats := int(math.Ceil(float64(n) / float64(len(words))))
+	return strings.Repeat(words, repeats)[:n]
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,5 @@
+module github.com/ai-aas/cmd/mock-inference
+
+go 1.24.0
+
+toolchain go1.24.10
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Request headers that override the model profile for a single request, so
+// tests can force specific behaviour without restarting the mock.
+const (
+	headerLatencyMs        = "X-Mock-Latency-Ms"
+	headerErrorStatus      = "X-Mock-Error-Status"
+	headerCompletionTokens = "X-Mock-Completion-Tokens"
+)
+
+// mockToken is the word emitted for every generated token, so completion
+// token counts equal the number of words in the response.
+const mockToken = "mock"
+
+type server struct {
+	profiles *Profiles
+	sampler  *sampler
+	models   []string
+	requests atomic.Uint64
+	ready    atomic.Bool
+}
+
+func newServer(profiles *Profiles, seed int64, models []string) *server {
+	s := &server{profiles: profiles, sampler: newSampler(seed), models: models}
+	s.ready.Store(true)
+	return s
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /ready", s.handleReady)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	mux.HandleFunc("POST /v1/completions", s.handleCompletions)
+	mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	return mux
+}
+
+type completionRequest struct {
+	Model         string          `json:"model"`
+	Prompt        json.RawMessage `json:"prompt"`
+	MaxTokens     int             `json:"max_tokens"`
+	Stream        bool            `json:"stream"`
+	StreamOptions *streamOptions  `json:"stream_options,omitempty"`
+}
+
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	MaxTokens     int            `json:"max_tokens"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// plan is the resolved outcome for one request.
+type plan struct {
+	id           string
+	model        string
+	latency      time.Duration
+	errorStatus  int
+	usage        usage
+	finishReason string
+	interval     time.Duration
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "not_ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ready"})
+}
+
+func (s *server) handleModels(w http.ResponseWriter, r *http.Request) {
+	data := make([]map[string]any, 0, len(s.models))
+	for _, m := range s.models {
+		data = append(data, map[string]any{"id": m, "object": "model", "owned_by": "mock-inference"})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": data})
+}
+
+func (s *server) handleCompletions(w http.ResponseWriter, r *http.Request) {
+	var req completionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+
+	p := s.plan(r, req.Model, countTokens(promptText(req.Prompt)), req.MaxTokens)
+	if !s.wait(w, r, p) {
+		return
+	}
+
+	if req.Stream {
+		s.stream(w, r, p, "text_completion", req.StreamOptions, func(text string, finish *string) any {
+			return map[string]any{
+				"id": p.id, "object": "text_completion", "created": time.Now().Unix(), "model": p.model,
+				"choices": []map[string]any{{"index": 0, "text": text, "finish_reason": finish, "logprobs": nil}},
+			}
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      p.id,
+		"object":  "text_completion",
+		"created": time.Now().Unix(),
+		"model":   p.model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"text":          generate(p.usage.CompletionTokens),
+			"finish_reason": p.finishReason,
+			"logprobs":      nil,
+		}},
+		"usage": p.usage,
+	})
+}
+
+func (s *server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "messages must not be empty")
+		return
+	}
+
+	promptTokens := 0
+	for _, m := range req.Messages {
+		promptTokens += countTokens(m.Content)
+	}
+	p := s.plan(r, req.Model, promptTokens, req.MaxTokens)
+	if !s.wait(w, r, p) {
+		return
+	}
+
+	if req.Stream {
+		first := true
+		s.stream(w, r, p, "chat.completion.chunk", req.StreamOptions, func(text string, finish *string) any {
+			delta := map[string]any{}
+			if first {
+				delta["role"] = "assistant"
+				first = false
+			}
+			if text != "" {
+				delta["content"] = text
+			}
+			return map[string]any{
+				"id": p.id, "object": "chat.completion.chunk", "created": time.Now().Unix(), "model": p.model,
+				"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finish}},
+			}
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      p.id,
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   p.model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       chatMessage{Role: "assistant", Content: generate(p.usage.CompletionTokens)},
+			"finish_reason": p.finishReason,
+		}},
+		"usage": p.usage,
+	})
+}
+
+// plan resolves latency, failure and token counts for a request from the
+// model profile and any per-request override headers.
+func (s *server) plan(r *http.Request, model string, promptTokens, maxTokens int) plan {
+	if model == "" {
+		model = "mock-model"
+	}
+	prof := s.profiles.For(model)
+
+	p := plan{
+		id:       fmt.Sprintf("cmpl-mock-%d", s.requests.Add(1)),
+		model:    model,
+		latency:  s.sampler.latency(prof.Latency),
+		interval: time.Duration(prof.StreamIntervalMs) * time.Millisecond,
+	}
+	if v, err := strconv.Atoi(r.Header.Get(headerLatencyMs)); err == nil && v >= 0 {
+		p.latency = time.Duration(v) * time.Millisecond
+	}
+
+	if v, err := strconv.Atoi(r.Header.Get(headerErrorStatus)); err == nil && v >= 400 && v <= 599 {
+		p.errorStatus = v
+	} else if s.sampler.fail(prof.ErrorRate) {
+		p.errorStatus = prof.ErrorStatus
+		if p.errorStatus == 0 {
+			p.errorStatus = http.StatusInternalServerError
+		}
+	}
+
+	completion := prof.CompletionTokens
+	if v, err := strconv.Atoi(r.Header.Get(headerCompletionTokens)); err == nil && v >= 0 {
+		completion = v
+	}
+	p.finishReason = "stop"
+	if maxTokens > 0 && maxTokens < completion {
+		completion = maxTokens
+		p.finishReason = "length"
+	}
+
+	p.usage = usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completion,
+		TotalTokens:      promptTokens + completion,
+	}
+	return p
+}
+
+// wait applies the planned latency and injected failure. It returns false
+// when the response has already been written or the client went away.
+func (s *server) wait(w http.ResponseWriter, r *http.Request, p plan) bool {
+	if p.latency > 0 {
+		timer := time.NewTimer(p.latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return false
+		}
+	}
+	if p.errorStatus != 0 {
+		writeError(w, p.errorStatus, errorType(p.errorStatus), "injected failure")
+		return false
+	}
+	return true
+}
+
+// stream writes one SSE chunk per token followed by the finish chunk, an
+// optional usage chunk and the [DONE] sentinel.
+func (s *server) stream(w http.ResponseWriter, r *http.Request, p plan, object string, opts *streamOptions, chunk func(text string, finish *string) any) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(v any) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	for i := 0; i < p.usage.CompletionTokens; i++ {
+		if i > 0 && p.interval > 0 {
+			select {
+			case <-time.After(p.interval):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		text := mockToken
+		if i > 0 {
+			text = " " + mockToken
+		}
+		send(chunk(text, nil))
+	}
+
+	finish := p.finishReason
+	send(chunk("", &finish))
+	if opts != nil && opts.IncludeUsage {
+		send(map[string]any{"id": p.id, "object": object, "model": p.model, "choices": []any{}, "usage": p.usage})
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// promptText flattens a completions prompt, which may be a string or an array of strings.
+func promptText(raw json.RawMessage) string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		return strings.Join(many, " ")
+	}
+	return ""
+}
+
+// countTokens is a deterministic stand-in for a tokenizer: one token per
+// whitespace-separated word.
+func countTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+// generate returns n mock tokens separated by spaces.
+func generate(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat(mockToken+" ", n), " ")
+}
+
+func errorType(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status >= 500:
+		return "server_error"
+	default:
+		return "invalid_request_error"
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, typ, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"type":    typ,
+			"code":    status,
+		},
+	})
+}
@@ -0,0 +1,131 @@
+// Command mock-inference serves an OpenAI-compatible API with configurable
+// latency, failure and token profiles for local development and tests.
+//
+// Purpose:
+//
+//	Stands in for vLLM behind the API router so routing, retries, rate limits
+//	and usage accounting can be exercised without GPUs. Responses are
+//	deterministic: one token per prompt word, and completions made of a fixed
+//	number of "mock" tokens, so tests can assert exact usage counts.
+//
+// Usage:
+//
+//	mock-inference [flags]
+//
+// Flags:
+//
+//	--addr ADDR                 Listen address (default: :$MOCK_INFERENCE_PORT or :8000)
+//	--latency-dist DIST         fixed, uniform, normal or exponential (default: fixed)
+//	--latency-mean-ms MS        Mean time to first byte (default: 50)
+//	--latency-stddev-ms MS      Standard deviation for the normal distribution
+//	--latency-min-ms MS         Lower bound (and uniform minimum)
+//	--latency-max-ms MS         Upper bound (and uniform maximum)
+//	--error-rate RATE           Probability (0-1) of an injected failure (default: 0)
+//	--error-status CODE         Status code for injected failures (default: 500)
+//	--completion-tokens N       Tokens generated per completion (default: 16)
+//	--stream-interval-ms MS     Delay between streamed tokens (default: 10)
+//	--profiles FILE             JSON file with default and per-model profiles
+//	--models LIST               Comma-separated models listed by /v1/models
+//	--seed N                    Random seed for latency and failure sampling (default: 1)
+//
+// Endpoints:
+//   - GET  /health, /ready
+//   - GET  /v1/models
+//   - POST /v1/completions, /v1/chat/completions (stream=true for SSE)
+//
+// Debugging Notes:
+//   - X-Mock-Latency-Ms, X-Mock-Error-Status and X-Mock-Completion-Tokens
+//     request headers override the profile for a single request
+//   - max_tokens below the profile's completion tokens truncates the response
+//     and reports finish_reason "length"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	defaultAddr := ":8000"
+	if port := os.Getenv("MOCK_INFERENCE_PORT"); port != "" {
+		defaultAddr = ":" + port
+	}
+
+	var (
+		addr     = flag.String("addr", defaultAddr, "Listen address")
+		dist     = flag.String("latency-dist", DistFixed, "Latency distribution: fixed, uniform, normal, exponential")
+		mean     = flag.Float64("latency-mean-ms", 50, "Mean latency in milliseconds")
+		stddev   = flag.Float64("latency-stddev-ms", 0, "Latency standard deviation in milliseconds (normal)")
+		minMs    = flag.Float64("latency-min-ms", 0, "Minimum latency in milliseconds")
+		maxMs    = flag.Float64("latency-max-ms", 0, "Maximum latency in milliseconds (0 = unbounded)")
+		errRate  = flag.Float64("error-rate", 0, "Probability (0-1) of an injected failure")
+		errCode  = flag.Int("error-status", http.StatusInternalServerError, "HTTP status for injected failures")
+		tokens   = flag.Int("completion-tokens", 16, "Tokens generated per completion")
+		interval = flag.Int("stream-interval-ms", 10, "Delay between streamed tokens in milliseconds")
+		profPath = flag.String("profiles", "", "JSON file with default and per-model profiles")
+		models   = flag.String("models", "mock-model,gpt-4o,llama-2-7b", "Comma-separated models listed by /v1/models")
+		seed     = flag.Int64("seed", 1, "Random seed for latency and failure sampling")
+	)
+	flag.Parse()
+
+	profiles, err := loadProfiles(*profPath, Profile{
+		Latency: LatencyProfile{
+			Distribution: *dist,
+			MeanMs:       *mean,
+			StdDevMs:     *stddev,
+			MinMs:        *minMs,
+			MaxMs:        *maxMs,
+		},
+		ErrorRate:        *errRate,
+		ErrorStatus:      *errCode,
+		CompletionTokens: *tokens,
+		StreamIntervalMs: *interval,
+	})
+	if err != nil {
+		log.Fatalf("load profiles: %v", err)
+	}
+
+	srv := newServer(profiles, *seed, splitList(*models))
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		srv.ready.Store(false)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("mock-inference listening on %s (latency=%s mean=%.0fms error_rate=%.2f completion_tokens=%d)",
+		*addr, *dist, *mean, *errRate, *tokens)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
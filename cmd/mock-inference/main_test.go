@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, prof Profile) *httptest.Server {
+	t.Helper()
+	srv := newServer(&Profiles{Default: prof}, 1, []string{"mock-model"})
+	ts := httptest.NewServer(srv.routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestChatCompletionDeterministicUsage(t *testing.T) {
+	ts := newTestServer(t, Profile{CompletionTokens: 8})
+
+	resp := post(t, ts.URL+"/v1/chat/completions",
+		`{"model":"mock-model","messages":[{"role":"user","content":"hello there world"}]}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Choices []struct {
+			Message      chatMessage `json:"message"`
+			FinishReason string      `json:"finish_reason"`
+		} `json:"choices"`
+		Usage usage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Usage != (usage{PromptTokens: 3, CompletionTokens: 8, TotalTokens: 11}) {
+		t.Fatalf("unexpected usage: %+v", body.Usage)
+	}
+	if got := countTokens(body.Choices[0].Message.Content); got != 8 {
+		t.Fatalf("expected 8 generated tokens, got %d", got)
+	}
+	if body.Choices[0].FinishReason != "stop" {
+		t.Fatalf("expected finish_reason stop, got %q", body.Choices[0].FinishReason)
+	}
+}
+
+func TestCompletionMaxTokensTruncates(t *testing.T) {
+	ts := newTestServer(t, Profile{CompletionTokens: 50})
+
+	resp := post(t, ts.URL+"/v1/completions", `{"prompt":["a b","c"],"max_tokens":5}`, nil)
+	var body struct {
+		Choices []struct {
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage usage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Usage.PromptTokens != 3 || body.Usage.CompletionTokens != 5 {
+		t.Fatalf("unexpected usage: %+v", body.Usage)
+	}
+	if body.Choices[0].FinishReason != "length" {
+		t.Fatalf("expected finish_reason length, got %q", body.Choices[0].FinishReason)
+	}
+}
+
+func TestErrorInjection(t *testing.T) {
+	ts := newTestServer(t, Profile{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable, CompletionTokens: 1})
+
+	resp := post(t, ts.URL+"/v1/chat/completions", `{"messages":[{"role":"user","content":"hi"}]}`, nil)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected injected 503, got %d", resp.StatusCode)
+	}
+
+	ok := newTestServer(t, Profile{CompletionTokens: 1})
+	resp = post(t, ok.URL+"/v1/chat/completions", `{"messages":[{"role":"user","content":"hi"}]}`,
+		map[string]string{headerErrorStatus: "429"})
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected header-forced 429, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamingChat(t *testing.T) {
+	ts := newTestServer(t, Profile{CompletionTokens: 3})
+
+	resp := post(t, ts.URL+"/v1/chat/completions",
+		`{"messages":[{"role":"user","content":"hi"}],"stream":true,"stream_options":{"include_usage":true}}`, nil)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	// 3 token chunks + finish chunk + usage chunk + [DONE]
+	if len(events) != 6 {
+		t.Fatalf("expected 6 events, got %d: %v", len(events), events)
+	}
+	if events[5] != "[DONE]" {
+		t.Fatalf("expected [DONE] sentinel, got %q", events[5])
+	}
+	var usageChunk struct {
+		Usage usage `json:"usage"`
+	}
+	if err := json.Unmarshal([]byte(events[4]), &usageChunk); err != nil || usageChunk.Usage.CompletionTokens != 3 {
+		t.Fatalf("expected usage chunk with 3 completion tokens, got %q (%v)", events[4], err)
+	}
+}
+
+func TestLatencySampling(t *testing.T) {
+	s := newSampler(1)
+	if d := s.latency(LatencyProfile{Distribution: DistFixed, MeanMs: 20}); d != 20*time.Millisecond {
+		t.Fatalf("expected fixed 20ms, got %s", d)
+	}
+	for i := 0; i < 100; i++ {
+		d := s.latency(LatencyProfile{Distribution: DistUniform, MinMs: 10, MaxMs: 30})
+		if d < 10*time.Millisecond || d > 30*time.Millisecond {
+			t.Fatalf("uniform sample %s out of range", d)
+		}
+		if d := s.latency(LatencyProfile{Distribution: DistNormal, MeanMs: 5, StdDevMs: 50}); d < 0 {
+			t.Fatalf("normal sample must not be negative, got %s", d)
+		}
+	}
+
+	a, b := newSampler(42), newSampler(42)
+	prof := LatencyProfile{Distribution: DistExponential, MeanMs: 100}
+	for i := 0; i < 10; i++ {
+		if a.latency(prof) != b.latency(prof) {
+			t.Fatal("expected identical samples for identical seeds")
+		}
+	}
+}
+
+func TestProfileValidation(t *testing.T) {
+	if err := (Profile{Latency: LatencyProfile{Distribution: "pareto"}}).validate(); err == nil {
+		t.Fatal("expected unknown distribution to fail validation")
+	}
+	if err := (Profile{ErrorRate: 1.5}).validate(); err == nil {
+		t.Fatal("expected error_rate > 1 to fail validation")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// Latency distributions supported by LatencyProfile.
+const (
+	DistFixed       = "fixed"
+	DistUniform     = "uniform"
+	DistNormal      = "normal"
+	DistExponential = "exponential"
+)
+
+// LatencyProfile describes the time-to-first-byte distribution, in milliseconds.
+type LatencyProfile struct {
+	Distribution string  `json:"distribution"`
+	MeanMs       float64 `json:"mean_ms"`
+	StdDevMs     float64 `json:"stddev_ms,omitempty"`
+	MinMs        float64 `json:"min_ms,omitempty"`
+	MaxMs        float64 `json:"max_ms,omitempty"`
+}
+
+// Profile controls how the mock responds for a model.
+type Profile struct {
+	Latency LatencyProfile `json:"latency"`
+	// ErrorRate is the probability (0-1) of returning ErrorStatus instead of a completion.
+	ErrorRate   float64 `json:"error_rate"`
+	ErrorStatus int     `json:"error_status"`
+	// CompletionTokens is the number of tokens generated when the request does
+	// not set max_tokens (or sets it higher).
+	CompletionTokens int `json:"completion_tokens"`
+	// StreamIntervalMs is the delay between streamed tokens.
+	StreamIntervalMs int `json:"stream_interval_ms"`
+}
+
+// Profiles holds the default profile and optional per-model overrides.
+type Profiles struct {
+	Default Profile            `json:"default"`
+	Models  map[string]Profile `json:"models,omitempty"`
+}
+
+// For returns the profile for a model, falling back to the default.
+func (p *Profiles) For(model string) Profile {
+	if prof, ok := p.Models[model]; ok {
+		return prof
+	}
+	return p.Default
+}
+
+// loadProfiles reads per-model profiles from a JSON file. Models omitted from
+// the file, and the default when absent, use def.
+func loadProfiles(path string, def Profile) (*Profiles, error) {
+	profiles := &Profiles{Default: def}
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("parse profiles: %w", err)
+	}
+	for name, prof := range profiles.Models {
+		if err := prof.validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	return profiles, profiles.Default.validate()
+}
+
+func (p Profile) validate() error {
+	switch p.Latency.Distribution {
+	case "", DistFixed, DistUniform, DistNormal, DistExponential:
+	default:
+		return fmt.Errorf("unknown latency distribution %q", p.Latency.Distribution)
+	}
+	if p.ErrorRate < 0 || p.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1, got %v", p.ErrorRate)
+	}
+	if p.ErrorStatus != 0 && (p.ErrorStatus < 400 || p.ErrorStatus > 599) {
+		return fmt.Errorf("error_status must be a 4xx or 5xx code, got %d", p.ErrorStatus)
+	}
+	return nil
+}
+
+// sampler draws latencies and error decisions from a seeded source so runs
+// with the same seed are reproducible.
+type sampler struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newSampler(seed int64) *sampler {
+	return &sampler{rng: rand.New(rand.NewSource(seed))}
+}
+
+// latency samples a delay from the profile.
+func (s *sampler) latency(l LatencyProfile) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ms float64
+	switch l.Distribution {
+	case DistUniform:
+		ms = l.MinMs + s.rng.Float64()*(l.MaxMs-l.MinMs)
+	case DistNormal:
+		ms = l.MeanMs + s.rng.NormFloat64()*l.StdDevMs
+	case DistExponential:
+		ms = s.rng.ExpFloat64() * l.MeanMs
+	default:
+		ms = l.MeanMs
+	}
+	if l.MaxMs > 0 {
+		ms = math.Min(ms, l.MaxMs)
+	}
+	ms = math.Max(ms, l.MinMs)
+	if ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// fail reports whether this request should fail given rate.
+func (s *sampler) fail(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < rate
+}
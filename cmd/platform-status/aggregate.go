@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Health states used for services, dependencies and the platform.
+const (
+	StateHealthy       = "healthy"
+	StateDegraded      = "degraded"
+	StateUnhealthy     = "unhealthy"
+	StateNotConfigured = "not_configured"
+)
+
+// ServiceStatus is one service's readiness, components and key metrics.
+type ServiceStatus struct {
+	Name       string             `json:"name"`
+	URL        string             `json:"url"`
+	State      string             `json:"state"`
+	HTTPStatus int                `json:"http_status,omitempty"`
+	LatencyMs  int64              `json:"latency_ms"`
+	Components map[string]string  `json:"components,omitempty"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	Reasons    []string           `json:"reasons,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Node is a service or infrastructure dependency in the graph.
+type Node struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"` // service or dependency
+	State string `json:"state"`
+}
+
+// Edge points from a service to something it depends on.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	State string `json:"state"`
+}
+
+// Graph is the platform dependency graph.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// PlatformStatus is the aggregated platform health report.
+type PlatformStatus struct {
+	Timestamp string          `json:"timestamp"`
+	Overall   string          `json:"overall"` // healthy, degraded, unhealthy
+	Services  []ServiceStatus `json:"services"`
+	Graph     Graph           `json:"graph"`
+}
+
+// aggregator probes every service in a topology.
+type aggregator struct {
+	topology Topology
+	client   *http.Client
+	timeout  time.Duration
+}
+
+// collect probes all services in parallel and builds the platform report.
+func (a *aggregator) collect(ctx context.Context) PlatformStatus {
+	statuses := make([]ServiceStatus, len(a.topology.Services))
+	var wg sync.WaitGroup
+	for i, svc := range a.topology.Services {
+		wg.Add(1)
+		go func(i int, svc Service) {
+			defer wg.Done()
+			statuses[i] = a.probe(ctx, svc)
+		}(i, svc)
+	}
+	wg.Wait()
+
+	propagate(a.topology, statuses)
+	return PlatformStatus{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Overall:   overall(statuses),
+		Services:  statuses,
+		Graph:     buildGraph(a.topology, statuses),
+	}
+}
+
+// probe fetches a service's readiness and metrics.
+func (a *aggregator) probe(ctx context.Context, svc Service) ServiceStatus {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	status := ServiceStatus{Name: svc.Name, URL: svc.URL, State: StateUnhealthy}
+	start := time.Now()
+	body, code, err := a.get(ctx, joinURL(svc.URL, svc.Readyz))
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.HTTPStatus = code
+
+	// Router and analytics report {"status": ..., "components": {...}}; other
+	// services may answer in plain text, which only the status code describes.
+	var ready struct {
+		Components map[string]string `json:"components"`
+	}
+	if json.Unmarshal(body, &ready) == nil && len(ready.Components) > 0 {
+		status.Components = make(map[string]string, len(ready.Components))
+		for name, state := range ready.Components {
+			status.Components[name] = normalizeState(state)
+		}
+	}
+
+	switch {
+	case code != http.StatusOK:
+		status.Reasons = append(status.Reasons, fmt.Sprintf("readiness returned %d", code))
+	default:
+		status.State = StateHealthy
+		for _, name := range sortedKeys(status.Components) {
+			if status.Components[name] == StateUnhealthy {
+				status.State = StateDegraded
+				status.Reasons = append(status.Reasons, fmt.Sprintf("dependency %s is unhealthy", name))
+			}
+		}
+	}
+
+	if svc.Metrics != "" && len(a.topology.MetricNames) > 0 {
+		body, code, err := a.get(ctx, joinURL(svc.URL, svc.Metrics))
+		if err == nil && code == http.StatusOK {
+			status.Metrics = parseMetrics(body, a.topology.MetricNames)
+		}
+	}
+	return status
+}
+
+func (a *aggregator) get(ctx context.Context, url string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	return body, resp.StatusCode, err
+}
+
+// propagate marks healthy services degraded when a service they depend on is
+// not healthy, following dependencies transitively.
+func propagate(topology Topology, statuses []ServiceStatus) {
+	index := make(map[string]int, len(statuses))
+	for i, status := range statuses {
+		index[status.Name] = i
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, svc := range topology.Services {
+			status := &statuses[index[svc.Name]]
+			if status.State != StateHealthy {
+				continue
+			}
+			for _, dep := range svc.DependsOn {
+				if upstream := statuses[index[dep]]; upstream.State != StateHealthy {
+					status.State = StateDegraded
+					status.Reasons = append(status.Reasons, fmt.Sprintf("depends on %s (%s)", dep, upstream.State))
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// overall is healthy when every service is, unhealthy when none is serving,
+// and degraded otherwise.
+func overall(statuses []ServiceStatus) string {
+	healthy, unhealthy := 0, 0
+	for _, status := range statuses {
+		switch status.State {
+		case StateHealthy:
+			healthy++
+		case StateUnhealthy:
+			unhealthy++
+		}
+	}
+	switch {
+	case healthy == len(statuses):
+		return StateHealthy
+	case unhealthy == len(statuses):
+		return StateUnhealthy
+	default:
+		return StateDegraded
+	}
+}
+
+// buildGraph links each service to the services it declares and the
+// infrastructure components its readiness endpoint reports.
+func buildGraph(topology Topology, statuses []ServiceStatus) Graph {
+	var graph Graph
+	state := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		state[status.Name] = status.State
+		graph.Nodes = append(graph.Nodes, Node{ID: status.Name, Kind: "service", State: status.State})
+	}
+
+	// A dependency shared by several services is unhealthy if any of them says so
+	deps := make(map[string]string)
+	for i, svc := range topology.Services {
+		for _, dep := range svc.DependsOn {
+			graph.Edges = append(graph.Edges, Edge{From: svc.Name, To: dep, State: state[dep]})
+		}
+		components := statuses[i].Components
+		for _, name := range sortedKeys(components) {
+			graph.Edges = append(graph.Edges, Edge{From: svc.Name, To: name, State: components[name]})
+			if current, ok := deps[name]; !ok || severity(components[name]) > severity(current) {
+				deps[name] = components[name]
+			}
+		}
+	}
+	for _, name := range sortedKeys(deps) {
+		if _, isService := state[name]; !isService {
+			graph.Nodes = append(graph.Nodes, Node{ID: name, Kind: "dependency", State: deps[name]})
+		}
+	}
+	return graph
+}
+
+// parseMetrics sums the named metrics across label sets from Prometheus
+// text exposition format.
+func parseMetrics(body []byte, names []string) map[string]float64 {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	metrics := make(map[string]float64)
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		if !wanted[name] {
+			continue
+		}
+		rest := line[len(name):]
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				continue
+			}
+			rest = rest[end+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		if value, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			metrics[name] += value
+		}
+	}
+	return metrics
+}
+
+// severity orders states so the worst report of a shared dependency wins.
+func severity(state string) int {
+	switch state {
+	case StateHealthy:
+		return 1
+	case StateDegraded:
+		return 2
+	case StateUnhealthy:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// normalizeState maps the states services report onto the aggregator's.
+func normalizeState(state string) string {
+	switch strings.ToLower(state) {
+	case "healthy", "ready", "ok", "up":
+		return StateHealthy
+	case "not_configured", "disabled":
+		return StateNotConfigured
+	case "degraded":
+		return StateDegraded
+	default:
+		return StateUnhealthy
+	}
+}
+
+func joinURL(base, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
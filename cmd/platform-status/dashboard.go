@@ -0,0 +1,80 @@
+package main
+
+// dashboardHTML renders /api/status as a self-refreshing ops dashboard.
+const dashboardHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Platform Status</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2933; }
+  h1 span { font-size: 1rem; padding: .2rem .6rem; border-radius: 1rem; vertical-align: middle; }
+  table { border-collapse: collapse; margin-bottom: 2rem; min-width: 40rem; }
+  th, td { text-align: left; padding: .4rem .8rem; border-bottom: 1px solid #e4e7eb; vertical-align: top; }
+  .healthy { background: #d1fae5; color: #065f46; }
+  .degraded { background: #fef3c7; color: #92400e; }
+  .unhealthy { background: #fee2e2; color: #991b1b; }
+  .not_configured { background: #f3f4f6; color: #6b7280; }
+  .state { padding: .1rem .5rem; border-radius: .5rem; font-size: .85rem; }
+  small { color: #6b7280; }
+</style>
+</head>
+<body>
+<h1>Platform Status <span id="overall" class="state">loading</span></h1>
+<small id="updated"></small>
+<h2>Services</h2>
+<table>
+  <thead><tr><th>Service</th><th>State</th><th>Latency</th><th>Details</th><th>Metrics</th></tr></thead>
+  <tbody id="services"></tbody>
+</table>
+<h2>Dependencies</h2>
+<table>
+  <thead><tr><th>From</th><th>To</th><th>State</th></tr></thead>
+  <tbody id="edges"></tbody>
+</table>
+<script>
+function cell(text, cls) {
+  const td = document.createElement("td");
+  if (cls) {
+    const span = document.createElement("span");
+    span.className = "state " + cls;
+    span.textContent = text;
+    td.appendChild(span);
+  } else {
+    td.textContent = text;
+  }
+  return td;
+}
+function row(cells) {
+  const tr = document.createElement("tr");
+  cells.forEach(c => tr.appendChild(c));
+  return tr;
+}
+async function refresh() {
+  const resp = await fetch("api/status");
+  const status = await resp.json();
+  const overall = document.getElementById("overall");
+  overall.textContent = status.overall;
+  overall.className = "state " + status.overall;
+  document.getElementById("updated").textContent = "Updated " + status.timestamp;
+
+  const services = document.getElementById("services");
+  services.replaceChildren(...status.services.map(s => row([
+    cell(s.name),
+    cell(s.state, s.state),
+    cell(s.latency_ms + " ms"),
+    cell([s.error].concat(s.reasons || []).filter(Boolean).join("; ")),
+    cell(Object.entries(s.metrics || {}).map(([k, v]) => k + "=" + Math.round(v * 100) / 100).join(", ")),
+  ])));
+
+  const edges = document.getElementById("edges");
+  edges.replaceChildren(...(status.graph.edges || []).map(e => row([
+    cell(e.from), cell(e.to), cell(e.state, e.state),
+  ])));
+}
+refresh();
+setInterval(refresh, 5000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,175 @@
+// Command platform-status aggregates every service's readiness and metrics
+// into a single platform health report with a dependency graph.
+//
+// Purpose:
+//
+//	Calls each service's readiness endpoint and /metrics in parallel, merges
+//	the results into one JSON document (per-service state, the components
+//	each service reports, key metrics, and a service -> dependency graph),
+//	and optionally serves it with a small ops dashboard. Unlike dev-status,
+//	which probes infrastructure directly, this reports what the services
+//	themselves see.
+//
+// Usage:
+//
+//	platform-status [flags]
+//
+// Flags:
+//
+//	--config FILE       JSON topology (services, depends_on, metric_names); default: local dev ports
+//	--services LIST     Base URL overrides or extra services, e.g. "api-router-service=http://router:8080"
+//	--timeout D         Per-service timeout (default: 3s)
+//	--format FORMAT     json or text (default: json)
+//	--serve ADDR        Serve the dashboard (/) and report (/api/status) instead of printing once
+//	--interval D        Refresh interval when serving (default: 15s)
+//
+// Debugging Notes:
+//   - A service is unhealthy when its readiness endpoint fails or is not 200,
+//     degraded when it is ready but reports an unhealthy component or depends
+//     on a service that is not healthy
+//   - Infrastructure nodes (postgres, redis, kafka, ...) come from the
+//     "components" map in readiness responses; services that answer in plain
+//     text contribute only their own state
+//   - Exit codes: 0 healthy or degraded, 1 unhealthy, 2 invalid flags or topology
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	var (
+		configPath = flag.String("config", "", "JSON topology file (default: local dev ports)")
+		services   = flag.String("services", "", "Comma-separated name=url overrides or extra services")
+		timeout    = flag.Duration("timeout", 3*time.Second, "Per-service timeout")
+		format     = flag.String("format", "json", "Output format: json, text")
+		serve      = flag.String("serve", "", "Serve the dashboard and report on this address (e.g. :9090)")
+		interval   = flag.Duration("interval", 15*time.Second, "Refresh interval when serving")
+	)
+	flag.Parse()
+
+	if *format != "json" && *format != "text" {
+		usageError(fmt.Errorf("unknown format %q (want json or text)", *format))
+	}
+	topology, err := loadTopology(*configPath, *services)
+	if err != nil {
+		usageError(err)
+	}
+	agg := &aggregator{topology: topology, client: &http.Client{}, timeout: *timeout}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *serve != "" {
+		if err := runServer(ctx, *serve, agg, *interval); err != nil {
+			log.Fatalf("platform-status: %v", err)
+		}
+		return
+	}
+
+	status := agg.collect(ctx)
+	if *format == "text" {
+		printText(os.Stdout, status)
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(status)
+	}
+	if status.Overall == StateUnhealthy {
+		os.Exit(1)
+	}
+}
+
+// runServer refreshes the report every interval and serves it until ctx ends.
+func runServer(ctx context.Context, addr string, agg *aggregator, interval time.Duration) error {
+	var (
+		mu     sync.RWMutex
+		latest = agg.collect(ctx)
+	)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				status := agg.collect(ctx)
+				mu.Lock()
+				latest = status
+				mu.Unlock()
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		mu.RLock()
+		status := latest
+		mu.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		if status.Overall == StateUnhealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, dashboardHTML)
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("platform-status serving on %s (refresh every %s)", addr, interval)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func printText(w io.Writer, status PlatformStatus) {
+	fmt.Fprintf(w, "Platform Status: %s (%s)\n\n", status.Overall, status.Timestamp)
+	for _, svc := range status.Services {
+		icon := "✓"
+		if svc.State != StateHealthy {
+			icon = "✗"
+		}
+		fmt.Fprintf(w, "  %s %s: %s (%dms)\n", icon, svc.Name, svc.State, svc.LatencyMs)
+		if svc.Error != "" {
+			fmt.Fprintf(w, "      error: %s\n", svc.Error)
+		}
+		for _, reason := range svc.Reasons {
+			fmt.Fprintf(w, "      %s\n", reason)
+		}
+	}
+	fmt.Fprintf(w, "\nDependencies:\n")
+	for _, edge := range status.Graph.Edges {
+		fmt.Fprintf(w, "  %s -> %s: %s\n", edge.From, edge.To, edge.State)
+	}
+}
+
+func usageError(err error) {
+	fmt.Fprintf(os.Stderr, "platform-status: %v\n", err)
+	flag.Usage()
+	os.Exit(2)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeService serves a readiness response and a small metrics page.
+func fakeService(t *testing.T, code int, readyBody string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/readyz":
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(readyBody))
+		case "/metrics":
+			_, _ = w.Write([]byte("# HELP go_goroutines Goroutines.\n# TYPE go_goroutines gauge\ngo_goroutines 42\n" +
+				`http_requests_total{code="200"} 10` + "\n" + `http_requests_total{code="500",path="/a b"} 2 1700000000` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCollectBuildsReportAndGraph(t *testing.T) {
+	router := fakeService(t, http.StatusOK, `{"status":"ready","components":{"redis":"healthy","kafka":"not_configured"}}`)
+	userOrg := fakeService(t, http.StatusServiceUnavailable, "not_ready: postgres down")
+	analytics := fakeService(t, http.StatusOK, `{"status":"ready","components":{"redis":"unhealthy","postgres":"healthy"}}`)
+
+	agg := &aggregator{
+		topology: Topology{
+			Services: []Service{
+				{Name: "router", URL: router.URL, Readyz: "/readyz", Metrics: "/metrics", DependsOn: []string{"user-org"}},
+				{Name: "user-org", URL: userOrg.URL, Readyz: "/readyz"},
+				{Name: "analytics", URL: analytics.URL, Readyz: "/readyz", Metrics: "/metrics"},
+			},
+			MetricNames: []string{"go_goroutines", "http_requests_total"},
+		},
+		client:  http.DefaultClient,
+		timeout: time.Second,
+	}
+	status := agg.collect(context.Background())
+
+	if status.Overall != StateDegraded {
+		t.Fatalf("expected degraded platform, got %s", status.Overall)
+	}
+	states := map[string]string{}
+	for _, svc := range status.Services {
+		states[svc.Name] = svc.State
+	}
+	if states["router"] != StateDegraded || states["user-org"] != StateUnhealthy || states["analytics"] != StateDegraded {
+		t.Fatalf("unexpected service states %v", states)
+	}
+	if m := status.Services[0].Metrics; m["go_goroutines"] != 42 || m["http_requests_total"] != 12 {
+		t.Fatalf("unexpected metrics %v", m)
+	}
+
+	nodes := map[string]Node{}
+	for _, node := range status.Graph.Nodes {
+		nodes[node.ID] = node
+	}
+	if nodes["redis"].Kind != "dependency" || nodes["redis"].State != StateUnhealthy {
+		t.Fatalf("expected the worst redis report to win, got %+v", nodes["redis"])
+	}
+	if nodes["kafka"].State != StateNotConfigured || nodes["user-org"].Kind != "service" {
+		t.Fatalf("unexpected nodes %+v", nodes)
+	}
+	found := false
+	for _, edge := range status.Graph.Edges {
+		if edge.From == "router" && edge.To == "user-org" && edge.State == StateUnhealthy {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected router -> user-org edge, got %+v", status.Graph.Edges)
+	}
+}
+
+func TestCollectAllDown(t *testing.T) {
+	agg := &aggregator{
+		topology: Topology{Services: []Service{{Name: "router", URL: "http://127.0.0.1:1", Readyz: "/readyz"}}},
+		client:   http.DefaultClient,
+		timeout:  time.Second,
+	}
+	status := agg.collect(context.Background())
+	if status.Overall != StateUnhealthy || status.Services[0].Error == "" {
+		t.Fatalf("expected unreachable service to be unhealthy, got %+v", status)
+	}
+}
+
+func TestLoadTopology(t *testing.T) {
+	topology, err := loadTopology("", "api-router-service=http://router:8080, billing=http://billing:9000")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if topology.Services[0].URL != "http://router:8080" || topology.Services[len(topology.Services)-1].Name != "billing" {
+		t.Fatalf("overrides not applied: %+v", topology.Services)
+	}
+
+	path := filepath.Join(t.TempDir(), "topology.json")
+	if err := os.WriteFile(path, []byte(`{"services":[{"name":"a","url":"http://a","readyz":"/readyz","depends_on":["b"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTopology(path, ""); err == nil || !strings.Contains(err.Error(), "unknown service") {
+		t.Fatalf("expected unknown dependency error, got %v", err)
+	}
+	if _, err := loadTopology("", "broken"); err == nil {
+		t.Fatal("expected error for malformed override")
+	}
+}
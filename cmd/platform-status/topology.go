@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Service is one platform service the aggregator probes.
+type Service struct {
+	Name string `json:"name"`
+	// URL is the service's base URL, e.g. http://localhost:8080.
+	URL string `json:"url"`
+	// Readyz and Metrics are paths below URL; Metrics may be empty to skip scraping.
+	Readyz  string `json:"readyz"`
+	Metrics string `json:"metrics"`
+	// DependsOn names other services this one calls. Infrastructure
+	// dependencies (Postgres, Redis, ...) are discovered from /readyz components.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Topology lists the services to aggregate and the metrics to extract.
+type Topology struct {
+	Services []Service `json:"services"`
+	// MetricNames are summed across label sets for each service.
+	MetricNames []string `json:"metric_names,omitempty"`
+}
+
+// defaultMetricNames are exported by every Go service's default registry.
+var defaultMetricNames = []string{
+	"go_goroutines",
+	"process_resident_memory_bytes",
+	"process_cpu_seconds_total",
+}
+
+// defaultTopology matches the local development ports.
+func defaultTopology() Topology {
+	return Topology{
+		Services: []Service{
+			{Name: "api-router-service", URL: "http://localhost:8080", Readyz: "/v1/status/readyz", Metrics: "/metrics", DependsOn: []string{"user-org-service"}},
+			{Name: "user-org-service", URL: "http://localhost:8081", Readyz: "/readyz", Metrics: "/metrics"},
+			{Name: "analytics-service", URL: "http://localhost:8084", Readyz: "/analytics/v1/status/readyz", Metrics: "/metrics"},
+		},
+		MetricNames: defaultMetricNames,
+	}
+}
+
+// loadTopology reads a JSON topology file, or returns the default topology
+// when path is empty. Inline overrides ("name=url,...") replace base URLs.
+func loadTopology(path, overrides string) (Topology, error) {
+	topology := defaultTopology()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Topology{}, fmt.Errorf("read topology: %w", err)
+		}
+		topology = Topology{}
+		if err := json.Unmarshal(data, &topology); err != nil {
+			return Topology{}, fmt.Errorf("parse topology: %w", err)
+		}
+		if len(topology.MetricNames) == 0 {
+			topology.MetricNames = defaultMetricNames
+		}
+	}
+
+	for _, entry := range strings.Split(overrides, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(entry, "=")
+		if !ok || url == "" {
+			return Topology{}, fmt.Errorf("invalid service override %q (want name=url)", entry)
+		}
+		found := false
+		for i := range topology.Services {
+			if topology.Services[i].Name == name {
+				topology.Services[i].URL = url
+				found = true
+			}
+		}
+		if !found {
+			topology.Services = append(topology.Services, Service{Name: name, URL: url, Readyz: "/readyz", Metrics: "/metrics"})
+		}
+	}
+
+	return topology, topology.validate()
+}
+
+func (t Topology) validate() error {
+	if len(t.Services) == 0 {
+		return fmt.Errorf("topology declares no services")
+	}
+	names := make(map[string]bool, len(t.Services))
+	for _, svc := range t.Services {
+		if svc.Name == "" || svc.URL == "" {
+			return fmt.Errorf("every service needs a name and url")
+		}
+		if names[svc.Name] {
+			return fmt.Errorf("duplicate service %q", svc.Name)
+		}
+		names[svc.Name] = true
+	}
+	for _, svc := range t.Services {
+		for _, dep := range svc.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("service %q depends on unknown service %q", svc.Name, dep)
+			}
+		}
+	}
+	return nil
+}
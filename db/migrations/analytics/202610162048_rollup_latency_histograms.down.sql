@@ -0,0 +1,3 @@
+ALTER TABLE analytics_daily_rollups DROP COLUMN IF EXISTS latency_histogram;
+
+ALTER TABLE analytics_hourly_rollups DROP COLUMN IF EXISTS latency_histogram;
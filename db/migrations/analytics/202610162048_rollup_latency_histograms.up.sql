@@ -0,0 +1,8 @@
+-- Latency histograms (internal/sketch) per rollup bucket, keyed by bucket
+-- index. Buckets written before this migration read back as empty
+-- histograms until the rollup worker rebuilds them.
+ALTER TABLE analytics_hourly_rollups
+    ADD COLUMN IF NOT EXISTS latency_histogram JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE analytics_daily_rollups
+    ADD COLUMN IF NOT EXISTS latency_histogram JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,18 @@
+-- Hourly request, error and cost totals per API key and per error code,
+-- written by the rollup worker so top-N breakdowns do not scan usage_events.
+CREATE TABLE IF NOT EXISTS analytics_hourly_dimension_rollups (
+    bucket_start    TIMESTAMPTZ NOT NULL,
+    organization_id UUID NOT NULL,
+    dimension       TEXT NOT NULL,
+    value           TEXT NOT NULL,
+    request_count   BIGINT NOT NULL DEFAULT 0,
+    error_count     BIGINT NOT NULL DEFAULT 0,
+    cost_total      NUMERIC(18, 6) NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT analytics_hourly_dimension_rollups_key UNIQUE (bucket_start, organization_id, dimension, value),
+    CONSTRAINT analytics_hourly_dimension_rollups_dimension_chk CHECK (dimension IN ('api_key', 'error_code'))
+);
+
+-- Top-N and isolation queries filter by org and dimension over a time range
+CREATE INDEX IF NOT EXISTS analytics_hourly_dimension_rollups_org_idx
+    ON analytics_hourly_dimension_rollups (organization_id, dimension, bucket_start);
@@ -0,0 +1 @@
+ALTER TABLE analytics.export_jobs DROP COLUMN IF EXISTS columns;
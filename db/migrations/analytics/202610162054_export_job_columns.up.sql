@@ -0,0 +1,4 @@
+-- CSV columns an export job may write, from the requester's export profile.
+-- NULL (jobs created before export profiles) writes every column.
+ALTER TABLE analytics.export_jobs
+    ADD COLUMN IF NOT EXISTS columns TEXT[];
@@ -0,0 +1,4 @@
+ALTER TABLE analytics.export_jobs
+    DROP COLUMN IF EXISTS upload_checkpoint,
+    DROP COLUMN IF EXISTS url_expires_at,
+    DROP COLUMN IF EXISTS rows_written;
@@ -0,0 +1,6 @@
+-- Export job progress, signed URL expiry and the multipart upload checkpoint
+-- a retried job resumes from.
+ALTER TABLE analytics.export_jobs
+    ADD COLUMN IF NOT EXISTS rows_written BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS url_expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS upload_checkpoint JSONB;
@@ -0,0 +1,8 @@
+-- Cancelled jobs become failed so the narrower constraint holds.
+UPDATE analytics.export_jobs
+SET status = 'failed', error_message = COALESCE(error_message, 'cancelled')
+WHERE status = 'cancelled';
+
+ALTER TABLE analytics.export_jobs DROP CONSTRAINT IF EXISTS export_jobs_status_check;
+ALTER TABLE analytics.export_jobs ADD CONSTRAINT export_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'expired'));
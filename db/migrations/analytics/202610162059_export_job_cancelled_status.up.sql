@@ -0,0 +1,4 @@
+-- Allow the 'cancelled' export job status (cancel and retry endpoints).
+ALTER TABLE analytics.export_jobs DROP CONSTRAINT IF EXISTS export_jobs_status_check;
+ALTER TABLE analytics.export_jobs ADD CONSTRAINT export_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'expired', 'cancelled'));
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// generateMigration renders up/down SQL enabling RLS and adding a tenant
+// isolation policy for each gap. Down only reverts what up changed.
+func generateMigration(gaps []tableReport, schema, tenantColumn, setting string, force bool) (string, string) {
+	var up, down strings.Builder
+	header := fmt.Sprintf("-- Generated by db/tools/rlsaudit: enforce %s isolation via %s\n", tenantColumn, setting)
+	up.WriteString(header)
+	down.WriteString(header)
+
+	for _, gap := range gaps {
+		table := pgx.Identifier{schema, gap.Table}.Sanitize()
+		policy := pgx.Identifier{gap.Table + "_tenant_isolation"}.Sanitize()
+		column := pgx.Identifier{tenantColumn}.Sanitize()
+		predicate := fmt.Sprintf("%s = current_setting('%s', true)::%s", column, setting, tenantCast(gap.TenantType))
+
+		fmt.Fprintf(&up, "\n-- %s\n", gap.Table)
+		if !gap.RLSEnabled {
+			fmt.Fprintf(&up, "ALTER TABLE %s ENABLE ROW LEVEL SECURITY;\n", table)
+		}
+		if force && !gap.RLSForced {
+			fmt.Fprintf(&up, "ALTER TABLE %s FORCE ROW LEVEL SECURITY;\n", table)
+		}
+		if gap.Status == statusMissingPolicy {
+			fmt.Fprintf(&up, "CREATE POLICY %s ON %s\n    USING (%s)\n    WITH CHECK (%s);\n", policy, table, predicate, predicate)
+		}
+
+		fmt.Fprintf(&down, "\n-- %s\n", gap.Table)
+		if gap.Status == statusMissingPolicy {
+			fmt.Fprintf(&down, "DROP POLICY IF EXISTS %s ON %s;\n", policy, table)
+		}
+		if force && !gap.RLSForced {
+			fmt.Fprintf(&down, "ALTER TABLE %s NO FORCE ROW LEVEL SECURITY;\n", table)
+		}
+		if !gap.RLSEnabled {
+			fmt.Fprintf(&down, "ALTER TABLE %s DISABLE ROW LEVEL SECURITY;\n", table)
+		}
+	}
+	return up.String(), down.String()
+}
+
+// tenantCast returns the type the setting value is cast to for comparison.
+func tenantCast(udtName string) string {
+	if udtName == "" {
+		return "text"
+	}
+	return udtName
+}
+
+// writeMigration writes <YYYYMMDDHHMM>_<slug>.up|down.sql, matching the naming
+// enforced by db/tools/lint.
+func writeMigration(dir string, now time.Time, slug, up, down string) (string, string, error) {
+	if !slugPattern.MatchString(slug) {
+		return "", "", fmt.Errorf("slug %q must match %s", slug, slugPattern)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, now.Format("200601021504")+"_"+slug)
+	upPath, downPath := base+".up.sql", base+".down.sql"
+	for _, path := range []string{upPath, downPath} {
+		if _, err := os.Stat(path); err == nil {
+			return "", "", fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.WriteFile(upPath, []byte(up), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte(down), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
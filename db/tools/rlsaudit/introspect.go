@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// tableInfo captures the RLS-relevant state of a single table.
+type tableInfo struct {
+	Name         string
+	RLSEnabled   bool
+	RLSForced    bool
+	TenantColumn string // empty when the table has no tenant column
+	TenantType   string // udt name of the tenant column, e.g. uuid
+	Policies     []policyInfo
+}
+
+// policyInfo is a row from pg_policies.
+type policyInfo struct {
+	Name       string
+	Command    string
+	Permissive bool // Permissive policies are OR'ed together; restrictive ones are AND'ed
+	Using      string
+	WithCheck  string
+}
+
+func introspect(ctx context.Context, conn *pgx.Conn, schema, tenantColumn string) ([]tableInfo, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT c.relname, c.relrowsecurity, c.relforcerowsecurity,
+		       COALESCE(col.column_name, ''), COALESCE(col.udt_name, '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN information_schema.columns col
+		       ON col.table_schema = n.nspname AND col.table_name = c.relname AND col.column_name = $2
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+		ORDER BY c.relname`, schema, tenantColumn)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	var tables []tableInfo
+	index := map[string]int{}
+	for rows.Next() {
+		var t tableInfo
+		if err := rows.Scan(&t.Name, &t.RLSEnabled, &t.RLSForced, &t.TenantColumn, &t.TenantType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan table: %w", err)
+		}
+		index[t.Name] = len(tables)
+		tables = append(tables, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	rows, err = conn.Query(ctx, `
+		SELECT tablename, policyname, cmd, permissive = 'PERMISSIVE', COALESCE(qual, ''), COALESCE(with_check, '')
+		FROM pg_policies
+		WHERE schemaname = $1
+		ORDER BY tablename, policyname`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		var p policyInfo
+		if err := rows.Scan(&table, &p.Name, &p.Command, &p.Permissive, &p.Using, &p.WithCheck); err != nil {
+			return nil, fmt.Errorf("scan policy: %w", err)
+		}
+		if i, ok := index[table]; ok {
+			tables[i].Policies = append(tables[i].Policies, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type auditOptions struct {
+	DSN          string
+	Schema       string
+	TenantColumn string
+	Setting      string
+	Exclude      string
+	Allowed      string
+	Format       string
+	Generate     bool
+	Force        bool
+	OutDir       string
+	Slug         string
+}
+
+func main() {
+	opts := parseFlags()
+
+	if opts.DSN == "" {
+		log.Fatal("DSN not provided")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, opts.DSN)
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	tables, err := introspect(ctx, conn, opts.Schema, opts.TenantColumn)
+	if err != nil {
+		log.Fatalf("introspect: %v", err)
+	}
+
+	report := buildReport(tables, opts.Setting, splitList(opts.Exclude), splitList(opts.Allowed))
+
+	switch opts.Format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("encode report: %v", err)
+		}
+	default:
+		printReport(os.Stdout, report)
+	}
+
+	gaps := report.Gaps()
+	if opts.Generate && len(gaps) > 0 {
+		up, down := generateMigration(gaps, opts.Schema, opts.TenantColumn, opts.Setting, opts.Force)
+		upPath, downPath, err := writeMigration(opts.OutDir, time.Now().UTC(), opts.Slug, up, down)
+		if err != nil {
+			log.Fatalf("write migration: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "[rls] wrote %s\n[rls] wrote %s\n", upPath, downPath)
+	}
+
+	if len(gaps) > 0 || len(report.Bypasses()) > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseFlags() auditOptions {
+	var opts auditOptions
+	flag.StringVar(&opts.DSN, "dsn", os.Getenv("DB_URL"), "PostgreSQL connection string")
+	flag.StringVar(&opts.Schema, "schema", "public", "Schema to audit")
+	flag.StringVar(&opts.TenantColumn, "tenant-column", "org_id", "Column identifying the owning tenant")
+	flag.StringVar(&opts.Setting, "setting", "app.org_id", "Session setting policies must reference (set by Store.withTenantTx)")
+	flag.StringVar(&opts.Exclude, "exclude", "schema_migrations_operational,schema_migrations_analytics", "Comma-separated tables to skip")
+	flag.StringVar(&opts.Allowed, "allow-policies", "", "Comma-separated table.policy permissive policies allowed without the tenant setting")
+	flag.StringVar(&opts.Format, "format", "text", "Report format (text|json)")
+	flag.BoolVar(&opts.Generate, "generate", false, "Write an up/down migration pair adding the missing policies")
+	flag.BoolVar(&opts.Force, "force", false, "Also FORCE row level security so table owners are subject to policies")
+	flag.StringVar(&opts.OutDir, "out", "db/migrations/operational", "Directory for generated migrations")
+	flag.StringVar(&opts.Slug, "slug", "tenant_rls_policies", "Slug for the generated migration filename")
+	flag.Parse()
+
+	opts.Format = strings.ToLower(strings.TrimSpace(opts.Format))
+	return opts
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table statuses reported by the audit.
+const (
+	statusOK              = "ok"
+	statusRLSDisabled     = "rls_disabled"
+	statusMissingPolicy   = "missing_policy"
+	statusNotTenantScoped = "not_tenant_scoped"
+	statusBypassPolicy    = "bypass_policy"
+	statusExcluded        = "excluded"
+)
+
+// tableReport is the audit result for one table.
+type tableReport struct {
+	Table          string   `json:"table"`
+	Status         string   `json:"status"`
+	TenantColumn   string   `json:"tenant_column,omitempty"`
+	TenantType     string   `json:"tenant_type,omitempty"`
+	RLSEnabled     bool     `json:"rls_enabled"`
+	RLSForced      bool     `json:"rls_forced"`
+	TenantPolicies []string `json:"tenant_policies,omitempty"`
+	OtherPolicies  []string `json:"other_policies,omitempty"`
+	// BypassPolicies are permissive policies that do not read the tenant
+	// setting. Permissive policies are OR'ed, so each one opens the table past
+	// the tenant policies.
+	BypassPolicies []string `json:"bypass_policies,omitempty"`
+}
+
+// auditReport summarises RLS coverage for a schema.
+type auditReport struct {
+	Setting string        `json:"setting"`
+	Tables  []tableReport `json:"tables"`
+}
+
+// Gaps returns tenant-scoped tables whose RLS does not enforce the tenant setting.
+func (r auditReport) Gaps() []tableReport {
+	var gaps []tableReport
+	for _, t := range r.Tables {
+		if t.Status == statusRLSDisabled || t.Status == statusMissingPolicy {
+			gaps = append(gaps, t)
+		}
+	}
+	return gaps
+}
+
+// Bypasses returns tenant-scoped tables with a permissive policy that is not
+// tenant-scoped. The generator cannot fix these; the policy must be dropped,
+// made restrictive, or listed in -allow-policies.
+func (r auditReport) Bypasses() []tableReport {
+	var bypasses []tableReport
+	for _, t := range r.Tables {
+		if t.Status == statusBypassPolicy {
+			bypasses = append(bypasses, t)
+		}
+	}
+	return bypasses
+}
+
+// buildReport audits each table. allowPolicies lists "table.policy" entries
+// reviewed as intended cross-tenant access, such as a relay role's policy.
+func buildReport(tables []tableInfo, setting string, exclude, allowPolicies []string) auditReport {
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+	allowed := map[string]bool{}
+	for _, name := range allowPolicies {
+		allowed[name] = true
+	}
+
+	report := auditReport{Setting: setting}
+	for _, t := range tables {
+		tr := tableReport{
+			Table:        t.Name,
+			TenantColumn: t.TenantColumn,
+			TenantType:   t.TenantType,
+			RLSEnabled:   t.RLSEnabled,
+			RLSForced:    t.RLSForced,
+		}
+		for _, p := range t.Policies {
+			if referencesSetting(p, setting) {
+				tr.TenantPolicies = append(tr.TenantPolicies, p.Name)
+			} else {
+				tr.OtherPolicies = append(tr.OtherPolicies, p.Name)
+				if p.Permissive && !allowed[t.Name+"."+p.Name] {
+					tr.BypassPolicies = append(tr.BypassPolicies, p.Name)
+				}
+			}
+		}
+
+		switch {
+		case excluded[t.Name]:
+			tr.Status = statusExcluded
+		case t.TenantColumn == "":
+			tr.Status = statusNotTenantScoped
+		case len(tr.TenantPolicies) == 0:
+			tr.Status = statusMissingPolicy
+		case !t.RLSEnabled:
+			tr.Status = statusRLSDisabled
+		case len(tr.BypassPolicies) > 0:
+			tr.Status = statusBypassPolicy
+		default:
+			tr.Status = statusOK
+		}
+		report.Tables = append(report.Tables, tr)
+	}
+	return report
+}
+
+// referencesSetting reports whether a policy expression reads the tenant setting,
+// e.g. current_setting('app.org_id'::text, true).
+func referencesSetting(p policyInfo, setting string) bool {
+	needle := "'" + setting + "'"
+	return strings.Contains(p.Using, needle) || strings.Contains(p.WithCheck, needle)
+}
+
+func printReport(w io.Writer, report auditReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tSTATUS\tRLS\tFORCED\tTENANT POLICIES")
+	for _, t := range report.Tables {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\n", t.Table, t.Status, t.RLSEnabled, t.RLSForced, strings.Join(t.TenantPolicies, ","))
+	}
+	_ = tw.Flush()
+
+	gaps := report.Gaps()
+	fmt.Fprintf(w, "\n%d tables audited, %d missing %s enforcement\n", len(report.Tables), len(gaps), report.Setting)
+	for _, t := range report.Bypasses() {
+		fmt.Fprintf(w, "%s: permissive policies without %s: %s\n", t.Table, report.Setting, strings.Join(t.BypassPolicies, ","))
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestBuildReportFlagsBypassPolicies(t *testing.T) {
+	tenant := policyInfo{Name: "tenant_isolation", Permissive: true, Using: "(org_id = (current_setting('app.org_id'::text, true))::uuid)"}
+	tables := []tableInfo{
+		{Name: "users", TenantColumn: "org_id", RLSEnabled: true, Policies: []policyInfo{tenant}},
+		{Name: "keys", TenantColumn: "org_id", RLSEnabled: true, Policies: []policyInfo{tenant, {Name: "open", Permissive: true, Using: "true"}}},
+		{Name: "audit", TenantColumn: "org_id", RLSEnabled: true, Policies: []policyInfo{tenant, {Name: "relay", Permissive: true, Using: "true"}}},
+		{Name: "events", TenantColumn: "org_id", RLSEnabled: true, Policies: []policyInfo{tenant, {Name: "recent", Using: "(created_at > now() - '1 day'::interval)"}}},
+	}
+
+	report := buildReport(tables, "app.org_id", nil, []string{"audit.relay"})
+	want := map[string]string{
+		"users":  statusOK,
+		"keys":   statusBypassPolicy,
+		"audit":  statusOK, // The bypass was allowed
+		"events": statusOK, // Restrictive policies only narrow access
+	}
+	for _, tr := range report.Tables {
+		if tr.Status != want[tr.Table] {
+			t.Errorf("%s: status %q, want %q", tr.Table, tr.Status, want[tr.Table])
+		}
+	}
+	if bypasses := report.Bypasses(); len(bypasses) != 1 || bypasses[0].BypassPolicies[0] != "open" {
+		t.Fatalf("Bypasses() = %+v, want keys.open", bypasses)
+	}
+	if gaps := report.Gaps(); len(gaps) != 0 {
+		t.Fatalf("Gaps() = %+v, want none", gaps)
+	}
+}
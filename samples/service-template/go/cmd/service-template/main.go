@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
@@ -67,13 +68,34 @@ func main() {
 		})
 	})
 
-	policyPath := filepath.Join("samples", "service-template", "policies", "service-template", "policy.json")
-	engine, err := auth.LoadPolicyFromFile(policyPath)
+	// Policy bundles hot-reload: file bundles on change, remote (http(s):// or
+	// oci://) bundles every POLICY_POLL_INTERVAL, and any source on SIGHUP.
+	// POLICY_ENGINE selects JSON rules or an embedded OPA evaluating Rego.
+	policyLocation := cfg.Policy.Bundle
+	if policyLocation == "" {
+		policyLocation = filepath.Join("samples", "service-template", "policies", "service-template", "policy.json")
+	}
+	var verifier auth.Verifier
+	if cfg.Policy.PublicKey != "" {
+		v, err := auth.LoadEd25519Verifier(cfg.Policy.PublicKey)
+		if err != nil {
+			log.Fatalf("policy verifier: %v", err)
+		}
+		verifier = v
+	}
+	store, err := auth.NewPolicyStore(auth.PolicyConfig{Engine: cfg.Policy.Engine, Query: cfg.Policy.Query})
+	if err != nil {
+		log.Fatalf("policy engine: %v", err)
+	}
+	reloader, err := auth.NewPolicyReloaderFor(ctx, auth.NewBundleSource(policyLocation, verifier), store, nil)
 	if err != nil {
 		log.Printf("failed to load policy bundle: %v", err)
 	} else {
+		go reloader.Watch(ctx, cfg.Policy.PollInterval)
+		auth.SetAuditRecorder(auth.NewDecisionLog(os.Stdout))
+
 		secure := chi.NewRouter()
-		secure.Use(auth.Middleware(engine, auth.HeaderExtractor))
+		secure.Use(auth.DeciderMiddleware(reloader.Store(), auth.HeaderExtractor, auth.RequestEnricher, auth.ClaimsEnricher))
 		secure.Get("/data", func(w http.ResponseWriter, r *http.Request) {
 			actor, _ := auth.ActorFromContext(r.Context())
 			writeJSON(w, http.StatusOK, map[string]any{
@@ -7,7 +7,9 @@
 # - JoeBlogs Ltd organization with admin and manager users
 #
 # Usage:
-#   ./scripts/seed-test-users.sh
+#   ./scripts/seed-test-users.sh [seed-test-users flags]
+#   ./scripts/seed-test-users.sh -profile load-test -orgs 50 -json fixtures.json
+#   ./scripts/seed-test-users.sh -teardown
 #
 # Environment variables:
 #   DATABASE_URL: Postgres connection string (defaults to local-dev)
@@ -54,7 +56,7 @@ if [ "$FORCE" = "true" ]; then
   FORCE_FLAG="-force"
 fi
 
-./bin/seed-test-users $FORCE_FLAG
+./bin/seed-test-users $FORCE_FLAG "$@"
 
 echo -e "\n${GREEN}✓ Seeding completed!${NC}"
 echo ""
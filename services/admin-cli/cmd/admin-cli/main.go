@@ -14,8 +14,9 @@
 //
 // Key Responsibilities:
 //   - Initialize CLI root command with Cobra
-//   - Register all command subcommands (bootstrap, org, user, credentials, sync, export)
-//   - Handle global flags (--verbose, --quiet, --format, --config)
+//   - Register all command subcommands (bootstrap, org, user, credentials, sync, export, doctor, context, cache)
+//   - Handle global flags (--verbose, --quiet, --format, --config, --profile)
+//   - Show the active profile on stderr so commands are not run against the wrong environment
 //   - Set up structured output and audit logging
 //
 // Requirements Reference:
@@ -31,6 +32,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/commands"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
 )
 
@@ -40,6 +42,8 @@ var (
 	gitCommit = "unknown"
 )
 
+var flagProfile string
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "admin-cli",
@@ -48,7 +52,12 @@ func main() {
 to perform privileged operations: bootstrap, org/user/key management,
 credential rotation, sync triggers, and exports.`,
 		Version: version,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			config.UseProfile(flagProfile)
+			showActiveProfile(cmd)
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Config profile to use (overrides ADMIN_CLI_PROFILE and current-profile)")
 
 	// Register subcommands
 	rootCmd.AddCommand(commands.BootstrapCommand())
@@ -60,6 +69,10 @@ credential rotation, sync triggers, and exports.`,
 	rootCmd.AddCommand(commands.ExportCommand())
 	rootCmd.AddCommand(commands.RegistryCommand())
 	rootCmd.AddCommand(commands.DeploymentCommand())
+	rootCmd.AddCommand(commands.ManifestCommand())
+	rootCmd.AddCommand(commands.DoctorCommand(version, gitCommit))
+	rootCmd.AddCommand(commands.ContextCommand())
+	rootCmd.AddCommand(commands.CacheCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		// Handle structured CLI errors with exit codes
@@ -74,3 +87,23 @@ credential rotation, sync triggers, and exports.`,
 	}
 }
 
+
+// showActiveProfile prints the active profile and its user-org endpoint to
+// stderr, keeping stdout clean for scripts. It is skipped for --quiet and for
+// the context commands, which report the profile themselves.
+func showActiveProfile(cmd *cobra.Command) {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "context" {
+			return
+		}
+	}
+	if quiet, err := cmd.Flags().GetBool("quiet"); err == nil && quiet {
+		return
+	}
+	// Load errors are reported by the command itself
+	cfg, err := config.Load()
+	if err != nil || cfg.Profile == "" || cfg.Quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Profile: %s (user-org-service: %s)\n", cfg.Profile, cfg.UserOrgEndpoint)
+}
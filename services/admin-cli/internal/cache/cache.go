@@ -0,0 +1,188 @@
+// Package cache provides a BoltDB-based local cache of API responses.
+//
+// Purpose:
+//
+//	Keep the most recently fetched organizations, users and API keys on disk
+//	so read commands can run with --offline during API outages. Entries are
+//	partitioned by user-org-service endpoint, so data cached for one
+//	environment is never shown for another, and carry the time they were
+//	fetched so output can be marked as stale.
+//
+// Dependencies:
+//   - go.etcd.io/bbolt: Embedded key-value database
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#US-002 (Day-2 Management)
+//
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var bucketResponses = []byte("responses")
+
+// ErrNotCached is returned when no entry exists for a key.
+var ErrNotCached = errors.New("not cached")
+
+// Entry is a cached response.
+type Entry struct {
+	Key       string          `json:"key"`
+	Endpoint  string          `json:"endpoint"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Age returns how long ago the entry was fetched.
+func (e Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.FetchedAt)
+}
+
+// Cache stores API responses in a BoltDB file.
+type Cache struct {
+	db  *bbolt.DB
+	now func() time.Time
+}
+
+// Open opens (creating if needed) the cache at path.
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open cache db: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketResponses)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create buckets: %w", err)
+	}
+
+	return &Cache{db: db, now: time.Now}, nil
+}
+
+// Close closes the cache database.
+func (c *Cache) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
+
+// OrgsKey is the key for the organization list.
+func OrgsKey() string {
+	return "orgs"
+}
+
+// UsersKey is the key for an organization's user list.
+func UsersKey(orgID string) string {
+	return "users/" + orgID
+}
+
+// APIKeysKey is the key for an organization's API key list.
+func APIKeysKey(orgID string) string {
+	return "apikeys/" + orgID
+}
+
+// Put stores v for key under endpoint, stamped with the current time.
+func (c *Cache) Put(endpoint, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", key, err)
+	}
+	entry, err := json.Marshal(Entry{Key: key, Endpoint: endpoint, FetchedAt: c.now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketResponses).Put([]byte(entryKey(endpoint, key)), entry)
+	})
+}
+
+// Get decodes the entry for key under endpoint into v and returns the entry.
+// It returns ErrNotCached when nothing has been stored.
+func (c *Cache) Get(endpoint, key string, v interface{}) (*Entry, error) {
+	var entry Entry
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(bucketResponses).Get([]byte(entryKey(endpoint, key)))
+		if data == nil {
+			return ErrNotCached
+		}
+		return json.Unmarshal(data, &entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+	return &entry, nil
+}
+
+// Entries lists the entries cached for endpoint, sorted by key. Data is left
+// undecoded.
+func (c *Cache) Entries(endpoint string) ([]Entry, error) {
+	var entries []Entry
+	prefix := []byte(entryKey(endpoint, ""))
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(bucketResponses).Cursor()
+		for k, v := cursor.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, v = cursor.Next() {
+			var entry Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("unmarshal entry: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, err
+}
+
+// Clear removes every entry cached for endpoint.
+func (c *Cache) Clear(endpoint string) error {
+	prefix := []byte(entryKey(endpoint, ""))
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(bucketResponses).Cursor()
+		for k, _ := cursor.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, _ = cursor.Seek(prefix) {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// entryKey scopes key to endpoint. A trailing slash on the endpoint is
+// ignored so both spellings share entries.
+func entryKey(endpoint, key string) string {
+	return strings.TrimRight(endpoint, "/") + "\x00" + key
+}
+
+// FormatAge renders an age for staleness notices, e.g. "3h12m ago".
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh%02dm ago", int(age.Hours()), int(age.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,98 @@
+// Package cache provides tests for the offline response cache.
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testOrg struct {
+	OrgID string `json:"orgId"`
+}
+
+func openTestCache(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(filepath.Join(t.TempDir(), "nested", "cache.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestPutGet(t *testing.T) {
+	c := openTestCache(t)
+	fetched := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return fetched }
+
+	orgs := []testOrg{{OrgID: "org-1"}, {OrgID: "org-2"}}
+	if err := c.Put("http://prod:8081/", OrgsKey(), orgs); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	var got []testOrg
+	entry, err := c.Get("http://prod:8081", OrgsKey(), &got)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if len(got) != 2 || got[1].OrgID != "org-2" {
+		t.Errorf("unexpected orgs: %+v", got)
+	}
+	if !entry.FetchedAt.Equal(fetched) {
+		t.Errorf("FetchedAt = %v, want %v", entry.FetchedAt, fetched)
+	}
+	if age := entry.Age(fetched.Add(3 * time.Hour)); age != 3*time.Hour {
+		t.Errorf("Age() = %v", age)
+	}
+
+	// Another environment never sees these entries
+	if _, err := c.Get("http://dev:8081", OrgsKey(), &got); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached for another endpoint, got %v", err)
+	}
+}
+
+func TestEntriesAndClear(t *testing.T) {
+	c := openTestCache(t)
+	for _, key := range []string{UsersKey("org-1"), OrgsKey(), APIKeysKey("org-1")} {
+		if err := c.Put("http://prod:8081", key, []string{}); err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+	}
+	if err := c.Put("http://prod:8081-eu", OrgsKey(), []string{}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	entries, err := c.Entries("http://prod:8081")
+	if err != nil {
+		t.Fatalf("Entries() failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Key != "apikeys/org-1" || entries[2].Key != "users/org-1" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	if err := c.Clear("http://prod:8081"); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if entries, _ := c.Entries("http://prod:8081"); len(entries) != 0 {
+		t.Errorf("expected no entries after Clear(), got %d", len(entries))
+	}
+	if entries, _ := c.Entries("http://prod:8081-eu"); len(entries) != 1 {
+		t.Errorf("Clear() removed another endpoint's entries")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Second:            "just now",
+		5 * time.Minute:             "5m ago",
+		3*time.Hour + 7*time.Minute: "3h07m ago",
+		72 * time.Hour:              "3d ago",
+	}
+	for age, want := range cases {
+		if got := FormatAge(age); got != want {
+			t.Errorf("FormatAge(%v) = %q, want %q", age, got, want)
+		}
+	}
+}
@@ -619,3 +619,137 @@ func (c *Client) DeleteAPIKey(ctx context.Context, orgID, apiKeyID string) error
 	return nil
 }
 
+// CreateServiceAccount creates a service account in an organization.
+func (c *Client) CreateServiceAccount(ctx context.Context, orgID string, req CreateServiceAccountRequest) (*ServiceAccountResponse, error) {
+	url := fmt.Sprintf("%s/v1/orgs/%s/service-accounts", c.baseURL, orgID)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := client.DoWithRetry(ctx, c.httpClient, httpReq, c.retryCfg)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		bodyBytes := make([]byte, 1024)
+		n, _ := resp.Body.Read(bodyBytes)
+		return nil, fmt.Errorf("create service account failed: status %d, body: %s", resp.StatusCode, string(bodyBytes[:n]))
+	}
+
+	var result ServiceAccountResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListServiceAccounts lists service accounts in an organization.
+func (c *Client) ListServiceAccounts(ctx context.Context, orgID string) ([]ServiceAccountResponse, error) {
+	url := fmt.Sprintf("%s/v1/orgs/%s/service-accounts", c.baseURL, orgID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := client.DoWithRetry(ctx, c.httpClient, httpReq, c.retryCfg)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list service accounts failed: status %d", resp.StatusCode)
+	}
+
+	var result []ServiceAccountResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteServiceAccount deletes a service account (soft delete).
+func (c *Client) DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID string) error {
+	url := fmt.Sprintf("%s/v1/orgs/%s/service-accounts/%s", c.baseURL, orgID, serviceAccountID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := client.DoWithRetry(ctx, c.httpClient, httpReq, c.retryCfg)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes := make([]byte, 1024)
+		n, _ := resp.Body.Read(bodyBytes)
+		return fmt.Errorf("delete service account failed: status %d, body: %s", resp.StatusCode, string(bodyBytes[:n]))
+	}
+
+	return nil
+}
+
+// IssueServiceAccountAPIKey creates an API key for a service account.
+func (c *Client) IssueServiceAccountAPIKey(ctx context.Context, orgID, serviceAccountID string, req IssueAPIKeyRequest) (*IssuedAPIKeyResponse, error) {
+	url := fmt.Sprintf("%s/v1/orgs/%s/service-accounts/%s/api-keys", c.baseURL, orgID, serviceAccountID)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := client.DoWithRetry(ctx, c.httpClient, httpReq, c.retryCfg)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("issue API key failed: status %d", resp.StatusCode)
+	}
+
+	var result IssuedAPIKeyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -135,3 +135,21 @@ type APIKeyResponse struct {
 	ExpiresAt   string                 `json:"expiresAt,omitempty"`
 }
 
+
+// CreateServiceAccountRequest represents service account creation request.
+type CreateServiceAccountRequest struct {
+	Name        string                 `json:"name"`
+	Description *string                `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ServiceAccountResponse represents a service account in API responses.
+type ServiceAccountResponse struct {
+	ServiceAccountID string `json:"serviceAccountId"`
+	OrgID            string `json:"orgId"`
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	Status           string `json:"status"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt,omitempty"`
+}
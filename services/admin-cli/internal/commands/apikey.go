@@ -12,11 +12,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/cache"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
@@ -46,13 +48,17 @@ func apiKeyListCommand() *cobra.Command {
 	var flagQuiet bool
 	var flagUserOrgEndpoint string
 	var flagAPIKey string
+	var flagSelection output.Selection
+	var flagOffline bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List API keys",
 		Long:  "List API keys for an organization with structured output (table, json, csv)",
+		Example: `  # IDs of revoked keys
+  admin-cli apikey list --org-id acme --template '{{if eq .status "revoked"}}{{.apiKeyId}}{{end}}'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAPIKeyList(cmd, args, flagOrgID, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey)
+			return runAPIKeyList(cmd, args, flagOrgID, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey, flagSelection, flagOffline)
 		},
 	}
 
@@ -62,12 +68,17 @@ func apiKeyListCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
 	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
 	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key for authentication (overrides config)")
+	registerSelectionFlags(cmd, &flagSelection)
+	registerOfflineFlag(cmd, &flagOffline)
 
 	return cmd
 }
 
-func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string) error {
+func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string, sel output.Selection, offline bool) error {
 	startTime := time.Now()
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -111,23 +122,32 @@ func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat stri
 		)
 	}
 
-	// Health check
-	checker := health.NewChecker(5 * time.Second)
-	requiredServices := map[string]string{
-		"user-org-service": cfg.UserOrgEndpoint,
-	}
-	if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
-		return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
-	}
+	// Fetch from the API, or from the local cache with --offline
+	var apiKeys []userorg.APIKeyResponse
+	if offline {
+		if err := loadCached(cfg, cache.APIKeysKey(flagOrgID), &apiKeys); err != nil {
+			return err
+		}
+	} else {
+		// Health check
+		checker := health.NewChecker(5 * time.Second)
+		requiredServices := map[string]string{
+			"user-org-service": cfg.UserOrgEndpoint,
+		}
+		if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
+			return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
+		}
 
-	// Create client and list API keys
-	userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
-	apiKeys, err := userOrgClient.ListAPIKeys(cmd.Context(), flagOrgID)
-	if err != nil {
-		return errors.NewOperationError(
-			fmt.Sprintf("failed to list API keys: %v", err),
-			"Verify your API key is valid and you have permission to list API keys in this organization.",
-		)
+		// Create client and list API keys
+		userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
+		apiKeys, err = userOrgClient.ListAPIKeys(cmd.Context(), flagOrgID)
+		if err != nil {
+			return errors.NewOperationError(
+				fmt.Sprintf("failed to list API keys: %v", err),
+				"Verify your API key is valid and you have permission to list API keys in this organization.",
+			)
+		}
+		storeCached(cfg, cache.APIKeysKey(flagOrgID), apiKeys)
 	}
 
 	// Audit logging
@@ -140,6 +160,9 @@ func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat stri
 	})
 
 	// Format output
+	if sel.Active() {
+		return sel.Render(os.Stdout, apiKeys)
+	}
 	if cfg.OutputFormat == "json" {
 		return output.PrintJSON(apiKeys)
 	} else if cfg.OutputFormat == "csv" {
@@ -154,7 +177,7 @@ func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat stri
 				key.ExpiresAt,
 			})
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	} else {
 		headers := []string{"API Key ID", "User ID", "Fingerprint", "Status", "Expires At"}
 		var rows [][]string
@@ -171,7 +194,7 @@ func runAPIKeyList(cmd *cobra.Command, args []string, flagOrgID, flagFormat stri
 			fmt.Println("No API keys found.")
 			return nil
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	}
 }
 
@@ -0,0 +1,264 @@
+// Package commands provides the cache command.
+//
+// Purpose:
+//
+//	Manage the local response cache behind --offline: sync refreshes the
+//	organizations, users and API keys for the current endpoint, status shows
+//	what is cached and how old it is, and clear drops it.
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#US-002 (Day-2 Management)
+//   - specs/009-admin-cli/spec.md#FR-008 (consume existing service APIs)
+//
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/cache"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/health"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/output"
+)
+
+// CacheCommand creates the cache command group.
+func CacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache used by --offline",
+		Long: `Manage the local cache of organizations, users and API keys.
+
+List commands refresh the cache whenever they reach the API; with --offline
+they read it instead and report how old the data is. Entries are kept per
+user-org-service endpoint, so each profile has its own cache.`,
+		Example: `  # Refresh everything before going on call
+  admin-cli cache sync
+
+  # Read users during an outage
+  admin-cli user list --org-id acme --offline`,
+	}
+
+	cmd.AddCommand(cacheSyncCommand())
+	cmd.AddCommand(cacheStatusCommand())
+	cmd.AddCommand(cacheClearCommand())
+
+	return cmd
+}
+
+func cacheSyncCommand() *cobra.Command {
+	var flagUserOrgEndpoint string
+	var flagAPIKey string
+	var flagQuiet bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Refresh cached organizations, users and API keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCacheConfig(flagUserOrgEndpoint, flagAPIKey)
+			if err != nil {
+				return err
+			}
+
+			checker := health.NewChecker(5 * time.Second)
+			if _, err := checker.CheckRequired(cmd.Context(), map[string]string{"user-org-service": cfg.UserOrgEndpoint}); err != nil {
+				return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
+			}
+
+			c, err := cache.Open(cfg.CachePath)
+			if err != nil {
+				return errors.NewOperationError(fmt.Sprintf("failed to open cache: %v", err), "Check that "+cfg.CachePath+" is writable.")
+			}
+			defer c.Close()
+
+			summary, err := syncCache(cmd, userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey), c, cfg.UserOrgEndpoint)
+			if err != nil {
+				return err
+			}
+			if !flagQuiet {
+				fmt.Fprintf(cmd.OutOrStdout(), "Cached %d organizations, %d users and %d API keys from %s\n",
+					summary.orgs, summary.users, summary.apiKeys, cfg.UserOrgEndpoint)
+			}
+			if len(summary.failures) > 0 {
+				for _, failure := range summary.failures {
+					fmt.Fprintf(os.Stderr, "warning: %s\n", failure)
+				}
+				return errors.NewOperationError(
+					fmt.Sprintf("%d organizations could not be fully cached", len(summary.failures)),
+					"Verify your API key can list users and API keys in every organization.",
+				)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
+	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key for authentication (overrides config)")
+	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
+
+	return cmd
+}
+
+type syncSummary struct {
+	orgs, users, apiKeys int
+	failures             []string
+}
+
+// syncCache fetches every organization with its users and API keys. Per-org
+// lists are stored under both the org ID and slug, matching what --org-id
+// accepts. A failure for one organization does not stop the others.
+func syncCache(cmd *cobra.Command, client *userorg.Client, c *cache.Cache, endpoint string) (*syncSummary, error) {
+	orgs, err := client.ListOrgs(cmd.Context())
+	if err != nil {
+		return nil, errors.NewOperationError(
+			fmt.Sprintf("failed to list organizations: %v", err),
+			"Verify your API key is valid and you have permission to list organizations.",
+		)
+	}
+	if err := c.Put(endpoint, cache.OrgsKey(), orgs); err != nil {
+		return nil, errors.NewOperationError(fmt.Sprintf("failed to write cache: %v", err), "")
+	}
+
+	summary := &syncSummary{orgs: len(orgs)}
+	for _, org := range orgs {
+		ids := []string{org.OrgID}
+		if org.Slug != "" && org.Slug != org.OrgID {
+			ids = append(ids, org.Slug)
+		}
+
+		users, err := client.ListUsers(cmd.Context(), org.OrgID)
+		if err != nil {
+			summary.failures = append(summary.failures, fmt.Sprintf("users for %s: %v", org.OrgID, err))
+		} else {
+			summary.users += len(users)
+			for _, id := range ids {
+				if err := c.Put(endpoint, cache.UsersKey(id), users); err != nil {
+					return nil, errors.NewOperationError(fmt.Sprintf("failed to write cache: %v", err), "")
+				}
+			}
+		}
+
+		apiKeys, err := client.ListAPIKeys(cmd.Context(), org.OrgID)
+		if err != nil {
+			summary.failures = append(summary.failures, fmt.Sprintf("API keys for %s: %v", org.OrgID, err))
+		} else {
+			summary.apiKeys += len(apiKeys)
+			for _, id := range ids {
+				if err := c.Put(endpoint, cache.APIKeysKey(id), apiKeys); err != nil {
+					return nil, errors.NewOperationError(fmt.Sprintf("failed to write cache: %v", err), "")
+				}
+			}
+		}
+	}
+	return summary, nil
+}
+
+func cacheStatusCommand() *cobra.Command {
+	var flagUserOrgEndpoint string
+	var flagFormat string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show cached entries and their age",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCacheConfig(flagUserOrgEndpoint, "")
+			if err != nil {
+				return err
+			}
+			c, err := cache.Open(cfg.CachePath)
+			if err != nil {
+				return errors.NewOperationError(fmt.Sprintf("failed to open cache: %v", err), "Check that "+cfg.CachePath+" is readable.")
+			}
+			defer c.Close()
+
+			entries, err := c.Entries(cfg.UserOrgEndpoint)
+			if err != nil {
+				return errors.NewOperationError(fmt.Sprintf("failed to read cache: %v", err), "Run 'admin-cli cache clear' and 'admin-cli cache sync' to rebuild it.")
+			}
+			for i := range entries {
+				entries[i].Data = nil
+			}
+
+			if flagFormat == "json" {
+				return output.PrintJSON(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Nothing cached for %s. Run 'admin-cli cache sync'.\n", cfg.UserOrgEndpoint)
+				return nil
+			}
+			now := time.Now()
+			var rows [][]string
+			for _, entry := range entries {
+				rows = append(rows, []string{entry.Key, entry.FetchedAt.Local().Format(time.RFC3339), cache.FormatAge(entry.Age(now))})
+			}
+			return output.PrintTable([]string{"Key", "Fetched At", "Age"}, rows)
+		},
+	}
+
+	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
+	cmd.Flags().StringVar(&flagFormat, "format", "table", "Output format: table, json")
+
+	return cmd
+}
+
+func cacheClearCommand() *cobra.Command {
+	var flagUserOrgEndpoint string
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove cached entries for the current endpoint",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCacheConfig(flagUserOrgEndpoint, "")
+			if err != nil {
+				return err
+			}
+			c, err := cache.Open(cfg.CachePath)
+			if err != nil {
+				return errors.NewOperationError(fmt.Sprintf("failed to open cache: %v", err), "Check that "+cfg.CachePath+" is writable.")
+			}
+			defer c.Close()
+
+			if err := c.Clear(cfg.UserOrgEndpoint); err != nil {
+				return errors.NewOperationError(fmt.Sprintf("failed to clear cache: %v", err), "")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Cleared cache for %s\n", cfg.UserOrgEndpoint)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
+
+	return cmd
+}
+
+// loadCacheConfig loads configuration with the cache commands' overrides.
+func loadCacheConfig(flagUserOrgEndpoint, flagAPIKey string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errors.NewOperationError(
+			fmt.Sprintf("failed to load configuration: %v", err),
+			"Check your configuration file or environment variables.",
+		)
+	}
+	if flagUserOrgEndpoint != "" {
+		cfg.UserOrgEndpoint = flagUserOrgEndpoint
+	}
+	if flagAPIKey != "" {
+		cfg.APIKey = flagAPIKey
+	}
+	if cfg.UserOrgEndpoint == "" {
+		return nil, errors.NewValidationError(
+			"user-org-service endpoint is required",
+			"Set via --user-org-endpoint flag or ADMIN_CLI_USER_ORG_ENDPOINT environment variable",
+		)
+	}
+	if cfg.CachePath == "" {
+		return nil, errors.NewValidationError("cache path is not set", "Set cache.path in the config file or ADMIN_CLI_CACHE_PATH")
+	}
+	return cfg, nil
+}
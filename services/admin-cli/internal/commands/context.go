@@ -0,0 +1,140 @@
+// Package commands provides the context command.
+//
+// Purpose:
+//
+//	Switch between named config profiles (dev, staging, prod) so endpoints and
+//	credentials for one environment are never used against another by
+//	accident. The selected profile is stored as current-profile in the config
+//	file; --profile and ADMIN_CLI_PROFILE override it for a single command or
+//	shell session.
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#NFR-024 (configuration file support)
+//   - specs/009-admin-cli/spec.md#NFR-014 (clear, actionable error messages)
+//
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+)
+
+// ContextCommand creates the context command with use, list and current
+// subcommands.
+func ContextCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage config profiles",
+		Long: `Manage named profiles defined under "profiles:" in the config file.
+
+Each profile may set any top-level config key (api-endpoints, auth, defaults,
+...) and overrides the file's top-level values while it is active.`,
+		Example: `  # ~/.admin-cli/config.yaml
+  current-profile: dev
+  profiles:
+    dev:
+      api-endpoints:
+        user-org-service: http://localhost:8081
+    prod:
+      api-endpoints:
+        user-org-service: https://user-org.example.com
+      auth:
+        api-key: sk_live_...
+
+  # Switch to prod
+  admin-cli context use prod
+
+  # Run one command against staging without switching
+  admin-cli org list --profile staging`,
+	}
+
+	cmd.AddCommand(contextUseCommand())
+	cmd.AddCommand(contextListCommand())
+	cmd.AddCommand(contextCurrentCommand())
+
+	return cmd
+}
+
+func contextUseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <profile>",
+		Short: "Make a profile the default for subsequent commands",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := profileConfigFile()
+			if err != nil {
+				return err
+			}
+			if err := config.SetCurrentProfile(path, args[0]); err != nil {
+				return errors.NewValidationError(err.Error(), "Run 'admin-cli context list' to see the defined profiles")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func contextListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles, marking the current one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := profileConfigFile()
+			if err != nil {
+				return err
+			}
+			pf, err := config.ReadProfiles(path)
+			if err != nil {
+				return errors.NewOperationError(err.Error(), "Check the config file syntax")
+			}
+			if len(pf.Profiles) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No profiles defined in %s\n", path)
+				return nil
+			}
+			for _, name := range pf.Profiles {
+				marker := " "
+				if name == pf.CurrentProfile {
+					marker = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+func contextCurrentCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "current",
+		Short: "Print the active profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return errors.NewValidationError(err.Error(), "Run 'admin-cli context list' to see the defined profiles")
+			}
+			if cfg.Profile == "" {
+				return errors.NewOperationError("no profile is active", "Run 'admin-cli context use <profile>' to select one")
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), cfg.Profile)
+			return nil
+		},
+	}
+}
+
+// profileConfigFile returns the config file that holds the profiles.
+func profileConfigFile() (string, error) {
+	path, err := config.FindConfigFile()
+	if err != nil {
+		return "", errors.NewOperationError(err.Error(), "Check the config file syntax")
+	}
+	if path == "" {
+		return "", errors.NewValidationError("no config file found", "Create ~/.admin-cli/config.yaml with a \"profiles:\" section")
+	}
+	return path, nil
+}
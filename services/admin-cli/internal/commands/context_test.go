@@ -0,0 +1,49 @@
+// Package commands provides tests for context command.
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextCommand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ADMIN_CLI_PROFILE", "")
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".admin-cli"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".admin-cli", "config.yaml"),
+		[]byte("profiles:\n  dev: {}\n  prod: {}\n"), 0o600))
+
+	run := func(args ...string) (string, error) {
+		cmd := ContextCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	_, err := run("current")
+	assert.Error(t, err, "no profile is active yet")
+
+	out, err := run("use", "prod")
+	require.NoError(t, err)
+	assert.Contains(t, out, `Switched to profile "prod"`)
+
+	out, err = run("list")
+	require.NoError(t, err)
+	assert.Equal(t, "  dev\n* prod\n", out)
+
+	out, err = run("current")
+	require.NoError(t, err)
+	assert.Equal(t, "prod\n", out)
+
+	_, err = run("use", "staging")
+	assert.Error(t, err)
+}
@@ -45,6 +45,7 @@ func deploymentStatusCommand() *cobra.Command {
 	var flagFormat string
 	var flagVerbose bool
 	var flagQuiet bool
+	var flagSelection output.Selection
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -62,9 +63,12 @@ This provides a comprehensive view of deployment health and status.`,
   admin-cli deployment status --environment production
 
   # JSON output for automation
-  admin-cli deployment status --model-name llama-2-7b --environment development --format json`,
+  admin-cli deployment status --model-name llama-2-7b --environment development --format json
+
+  # Status of a single model for scripting
+  admin-cli deployment status --model-name llama-2-7b --quiet --jsonpath '.[0].status'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDeploymentStatus(cmd, args, flagModelName, flagEnvironment, flagFormat, flagVerbose, flagQuiet)
+			return runDeploymentStatus(cmd, args, flagModelName, flagEnvironment, flagFormat, flagVerbose, flagQuiet, flagSelection)
 		},
 	}
 
@@ -73,11 +77,16 @@ This provides a comprehensive view of deployment health and status.`,
 	cmd.Flags().StringVar(&flagFormat, "format", "table", "Output format: table, json, csv")
 	cmd.Flags().BoolVar(&flagVerbose, "verbose", false, "Enable verbose output")
 	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
+	registerSelectionFlags(cmd, &flagSelection)
 
 	return cmd
 }
 
-func runDeploymentStatus(cmd *cobra.Command, args []string, modelName, environment, flagFormat string, verbose, quiet bool) error {
+func runDeploymentStatus(cmd *cobra.Command, args []string, modelName, environment, flagFormat string, verbose, quiet bool, sel output.Selection) error {
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -132,11 +141,14 @@ func runDeploymentStatus(cmd *cobra.Command, args []string, modelName, environme
 	}
 
 	// Output based on format
+	if sel.Active() {
+		return sel.Render(os.Stdout, statuses)
+	}
 	if flagFormat == "json" {
 		return output.PrintJSON(statuses)
 	}
 
-	// Table format
+	// Table format (csv uses the same columns)
 	headers := []string{"Model Name", "Status", "Endpoint", "Namespace", "Last Health", "Updated"}
 	var tableRows [][]string
 	for _, status := range statuses {
@@ -159,7 +171,7 @@ func runDeploymentStatus(cmd *cobra.Command, args []string, modelName, environme
 		fmt.Fprintf(os.Stderr, "Found %d deployment(s)\n\n", len(statuses))
 	}
 
-	return output.PrintTable(headers, tableRows)
+	return printRows(flagFormat, sel, headers, tableRows)
 }
 
 // queryDeploymentStatuses queries the database for deployment statuses.
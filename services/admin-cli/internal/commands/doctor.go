@@ -0,0 +1,140 @@
+// Package commands provides the doctor command.
+//
+// Purpose:
+//
+//	Diagnose the local CLI environment: config file validity, service
+//	reachability, credential freshness, token scopes and version skew between
+//	the CLI and the deployed services, with a remediation step per problem.
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#NFR-014 (clear, actionable error messages)
+//   - specs/009-admin-cli/spec.md#FR-006 (structured output)
+//   - specs/009-admin-cli/spec.md#FR-008 (service health checks)
+//
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/doctor"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/output"
+)
+
+// DoctorCommand creates the doctor command. version and commit identify the
+// CLI build and are compared with the services' builds.
+func DoctorCommand(version, commit string) *cobra.Command {
+	var flagFormat string
+	var flagUserOrgEndpoint string
+	var flagAnalyticsEndpoint string
+	var flagAPIKey string
+	var flagScopes []string
+	var flagExpiryWarning time.Duration
+	var flagTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose CLI configuration, credentials and service connectivity",
+		Long: `Check the config file, service reachability, credential freshness, token
+scopes and version skew between the CLI and the services, printing a
+remediation step for every problem found.
+
+Exits non-zero when any check fails; warnings do not affect the exit code.`,
+		Example: `  # Diagnose the current environment
+  admin-cli doctor
+
+  # Machine-readable report for CI
+  admin-cli doctor --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, cfgErr := config.Load()
+			if cfg != nil {
+				if flagUserOrgEndpoint != "" {
+					cfg.UserOrgEndpoint = flagUserOrgEndpoint
+				}
+				if flagAnalyticsEndpoint != "" {
+					cfg.AnalyticsEndpoint = flagAnalyticsEndpoint
+				}
+				if flagAPIKey != "" {
+					cfg.APIKey = flagAPIKey
+				}
+			}
+
+			report := doctor.Run(cmd.Context(), doctor.Options{
+				Config:         cfg,
+				ConfigErr:      cfgErr,
+				CLIVersion:     version,
+				CLICommit:      commit,
+				RequiredScopes: flagScopes,
+				ExpiryWarning:  flagExpiryWarning,
+				HTTPClient:     &http.Client{Timeout: flagTimeout},
+			})
+
+			if flagFormat == "json" {
+				if err := output.NewJSONFormatter(cmd.OutOrStdout()).Write(output.Output{
+					Success: report.OK,
+					Command: "doctor",
+					Data:    report,
+				}); err != nil {
+					return err
+				}
+			} else {
+				printDoctorReport(cmd.OutOrStdout(), report)
+			}
+
+			if !report.OK {
+				cmd.SilenceUsage = true
+				return errors.NewOperationError("one or more doctor checks failed", "Follow the remediation steps above, then run `admin-cli doctor` again.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagFormat, "format", "table", "Output format: table, json")
+	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
+	cmd.Flags().StringVar(&flagAnalyticsEndpoint, "analytics-endpoint", "", "Analytics-service endpoint (overrides config)")
+	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key or access token to check (overrides config)")
+	cmd.Flags().StringSliceVar(&flagScopes, "required-scope", []string{"admin"}, "Scopes the credential must grant")
+	cmd.Flags().DurationVar(&flagExpiryWarning, "expiry-warning", doctor.DefaultExpiryWarning, "Warn when the credential expires within this duration")
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", 5*time.Second, "Timeout for each request")
+
+	return cmd
+}
+
+// printDoctorReport writes one line per check, followed by its remediation.
+func printDoctorReport(w io.Writer, report doctor.Report) {
+	fmt.Fprintf(w, "admin-cli %s", report.CLIVersion)
+	if report.CLICommit != "" && report.CLICommit != "unknown" {
+		fmt.Fprintf(w, " (%s)", report.CLICommit)
+	}
+	fmt.Fprintln(w)
+
+	counts := map[doctor.Status]int{}
+	for _, check := range report.Checks {
+		counts[check.Status]++
+		fmt.Fprintf(w, "%s %-18s %s\n", doctorSymbol(check.Status), check.Name, check.Message)
+		if check.Remediation != "" {
+			fmt.Fprintf(w, "  → %s\n", check.Remediation)
+		}
+	}
+	fmt.Fprintf(w, "\n%d ok, %d warnings, %d failed, %d skipped\n",
+		counts[doctor.StatusOK], counts[doctor.StatusWarn], counts[doctor.StatusFail], counts[doctor.StatusSkip])
+}
+
+func doctorSymbol(status doctor.Status) string {
+	switch status {
+	case doctor.StatusOK:
+		return "✓"
+	case doctor.StatusWarn:
+		return "!"
+	case doctor.StatusFail:
+		return "✗"
+	default:
+		return "-"
+	}
+}
@@ -0,0 +1,19 @@
+// Package commands provides tests for doctor command.
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorCommand(t *testing.T) {
+	cmd := DoctorCommand("v1.2.3", "abcdef1")
+	require.NotNil(t, cmd, "DoctorCommand() returned nil")
+	assert.Equal(t, "doctor", cmd.Use)
+
+	for _, name := range []string{"format", "user-org-endpoint", "api-key", "required-scope", "expiry-warning"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "%s flag should exist", name)
+	}
+}
@@ -0,0 +1,303 @@
+// Package commands provides declarative manifest commands.
+//
+// Purpose:
+//
+//	Manage orgs, users, service accounts, API keys, budgets and model aliases
+//	from a YAML/JSON manifest with plan/apply/destroy semantics against the
+//	admin APIs.
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#US-002 (Day-2 Management)
+//   - specs/009-admin-cli/spec.md#FR-006 (structured output)
+//
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/declarative"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/health"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/output"
+)
+
+// manifestFlags holds flags shared by all manifest subcommands.
+type manifestFlags struct {
+	file            string
+	format          string
+	quiet           bool
+	userOrgEndpoint string
+	apiKey          string
+}
+
+func (f *manifestFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.file, "file", "f", "", "Manifest file path (JSON/YAML, required)")
+	cmd.Flags().StringVar(&f.format, "format", "table", "Output format: table, json")
+	cmd.Flags().BoolVar(&f.quiet, "quiet", false, "Suppress non-error output")
+	cmd.Flags().StringVar(&f.userOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
+	cmd.Flags().StringVar(&f.apiKey, "api-key", "", "API key for authentication (overrides config)")
+}
+
+// ManifestCommand creates the manifest command group.
+func ManifestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Manage platform resources declaratively",
+		Long: `Manage orgs, users, service accounts, API keys, budgets and model aliases
+from a declarative manifest with plan/apply/destroy semantics. User roles are
+assigned when a user is invited; role changes to existing users are not planned.`,
+	}
+
+	cmd.AddCommand(manifestPlanCommand())
+	cmd.AddCommand(manifestApplyCommand())
+	cmd.AddCommand(manifestDestroyCommand())
+
+	return cmd
+}
+
+func manifestPlanCommand() *cobra.Command {
+	var flags manifestFlags
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show changes required to reach the manifest state",
+		Example: `  # Preview changes
+  admin-cli manifest plan -f platform.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifest(cmd, flags, false, false, false)
+		},
+	}
+	flags.register(cmd)
+
+	return cmd
+}
+
+func manifestApplyCommand() *cobra.Command {
+	var flags manifestFlags
+	var flagAutoApprove bool
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the manifest to the platform",
+		Example: `  # Apply changes after reviewing the plan
+  admin-cli manifest apply -f platform.yaml --auto-approve`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifest(cmd, flags, true, false, flagAutoApprove)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&flagAutoApprove, "auto-approve", false, "Execute the plan (without it, apply only previews)")
+
+	return cmd
+}
+
+func manifestDestroyCommand() *cobra.Command {
+	var flags manifestFlags
+	var flagConfirm bool
+
+	cmd := &cobra.Command{
+		Use:   "destroy",
+		Short: "Delete every resource declared in the manifest",
+		Example: `  # Tear down resources declared in the manifest
+  admin-cli manifest destroy -f platform.yaml --confirm`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifest(cmd, flags, true, true, flagConfirm)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&flagConfirm, "confirm", false, "Confirm destruction (required to execute)")
+
+	return cmd
+}
+
+func runManifest(cmd *cobra.Command, flags manifestFlags, execute, destroy, approved bool) error {
+	startTime := time.Now()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.NewOperationError(
+			fmt.Sprintf("failed to load configuration: %v", err),
+			"Check your configuration file or environment variables.",
+		)
+	}
+
+	// Apply flag overrides
+	if flags.userOrgEndpoint != "" {
+		cfg.UserOrgEndpoint = flags.userOrgEndpoint
+	}
+	if flags.apiKey != "" {
+		cfg.APIKey = flags.apiKey
+	}
+	if flags.format != "" {
+		cfg.OutputFormat = flags.format
+	}
+	if flags.quiet {
+		cfg.Quiet = true
+	}
+
+	// Validate configuration
+	if cfg.UserOrgEndpoint == "" {
+		return errors.NewValidationError(
+			"user-org-service endpoint is required",
+			"Set via --user-org-endpoint flag or ADMIN_CLI_USER_ORG_ENDPOINT environment variable",
+		)
+	}
+	if flags.file == "" {
+		return errors.NewValidationError(
+			"--file is required",
+			"Provide a manifest file with --file (JSON or YAML).",
+		)
+	}
+
+	manifest, err := declarative.LoadManifest(flags.file)
+	if err != nil {
+		return errors.NewValidationError(
+			fmt.Sprintf("invalid manifest: %v", err),
+			fmt.Sprintf("Manifest must declare apiVersion %q and kind %q.", declarative.ManifestAPIVersion, declarative.ManifestKind),
+		)
+	}
+
+	// Health check
+	checker := health.NewChecker(5 * time.Second)
+	requiredServices := map[string]string{
+		"user-org-service": cfg.UserOrgEndpoint,
+	}
+	if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
+		return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
+	}
+
+	backend := declarative.NewUserOrgBackend(userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey))
+	state, err := backend.ReadState(cmd.Context(), manifest.Slugs())
+	if err != nil {
+		return errors.NewOperationError(
+			fmt.Sprintf("failed to read current state: %v", err),
+			"Verify your API key is valid and you have permission to list organizations.",
+		)
+	}
+
+	var plan *declarative.Plan
+	if destroy {
+		plan = declarative.ComputeDestroyPlan(manifest, state)
+	} else {
+		plan = declarative.ComputePlan(manifest, state)
+	}
+
+	if !execute || !approved || plan.Empty() {
+		if cfg.OutputFormat == "json" {
+			return output.PrintJSON(map[string]interface{}{
+				"mode":    "plan",
+				"plan":    plan,
+				"summary": plan.Summary(),
+			})
+		}
+		if !cfg.Quiet {
+			printPlan(plan)
+			if execute && !approved && !plan.Empty() {
+				if destroy {
+					fmt.Println("\nUse --confirm to execute")
+				} else {
+					fmt.Println("\nUse --auto-approve to execute")
+				}
+			}
+		}
+		return nil
+	}
+
+	operation := "manifest_apply"
+	if destroy {
+		operation = "manifest_destroy"
+	}
+
+	results, applyErr := declarative.Apply(cmd.Context(), backend, plan)
+
+	// Audit logging
+	outcome := "success"
+	if applyErr != nil {
+		outcome = "failure"
+	}
+	auditLogger := audit.NewLogger(nil)
+	_ = auditLogger.LogOperation(audit.Operation{
+		Type:    operation,
+		Command: fmt.Sprintf("manifest %s --file=%s", strings.TrimPrefix(operation, "manifest_"), flags.file),
+		Parameters: map[string]interface{}{
+			"file":    flags.file,
+			"planned": len(plan.Actions),
+			"applied": len(results),
+		},
+		Outcome:  outcome,
+		Error:    applyErr,
+		Duration: time.Since(startTime),
+	})
+
+	if cfg.OutputFormat == "json" {
+		if err := output.PrintJSON(map[string]interface{}{
+			"mode":    "apply",
+			"results": results,
+			"summary": plan.Summary(),
+			"applied": len(results),
+		}); err != nil {
+			return err
+		}
+	} else if !cfg.Quiet {
+		printResults(results)
+	}
+
+	if applyErr != nil {
+		return errors.NewOperationError(
+			fmt.Sprintf("apply stopped after %d of %d actions: %v", len(results), len(plan.Actions), applyErr),
+			"Fix the error and re-run; completed actions are detected and skipped by the next plan.",
+		)
+	}
+	return nil
+}
+
+func printPlan(plan *declarative.Plan) {
+	if plan.Empty() {
+		fmt.Println("No changes. Platform state matches the manifest.")
+		return
+	}
+
+	headers := []string{"Action", "Resource", "Address", "Details"}
+	var rows [][]string
+	for _, action := range plan.Actions {
+		rows = append(rows, []string{
+			string(action.Type),
+			string(action.Resource),
+			action.Address(),
+			strings.Join(action.Changes, "; "),
+		})
+	}
+	_ = output.PrintTable(headers, rows)
+
+	summary := plan.Summary()
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to delete.\n",
+		summary[declarative.ActionCreate], summary[declarative.ActionUpdate], summary[declarative.ActionDelete])
+}
+
+func printResults(results []declarative.Result) {
+	headers := []string{"Action", "Address", "ID"}
+	var rows [][]string
+	var secrets []declarative.Result
+	for _, result := range results {
+		rows = append(rows, []string{string(result.Action.Type), result.Action.Address(), result.ID})
+		if result.Secret != "" {
+			secrets = append(secrets, result)
+		}
+	}
+	_ = output.PrintTable(headers, rows)
+
+	if len(secrets) > 0 {
+		fmt.Println("\n⚠️  API key secrets are shown only once. Store them securely:")
+		for _, result := range secrets {
+			fmt.Printf("  %s: %s\n", result.Action.Address(), result.Secret)
+		}
+	}
+}
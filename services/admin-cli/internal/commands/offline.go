@@ -0,0 +1,61 @@
+package commands
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/cache"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+)
+
+// registerOfflineFlag adds --offline to a read command.
+func registerOfflineFlag(cmd *cobra.Command, offline *bool) {
+	cmd.Flags().BoolVar(offline, "offline", false, "Read from the local cache instead of the API (refresh with 'admin-cli cache sync')")
+}
+
+// loadCached decodes the cached response for key into v and prints a
+// staleness notice to stderr, so stdout stays identical to online output.
+func loadCached(cfg *config.Config, key string, v interface{}) error {
+	c, err := cache.Open(cfg.CachePath)
+	if err != nil {
+		return errors.NewOperationError(
+			fmt.Sprintf("failed to open cache: %v", err),
+			"Check that "+cfg.CachePath+" is readable and not locked by another admin-cli process.",
+		)
+	}
+	defer c.Close()
+
+	entry, err := c.Get(cfg.UserOrgEndpoint, key, v)
+	if stderrors.Is(err, cache.ErrNotCached) {
+		return errors.NewOperationError(
+			fmt.Sprintf("no cached data for %s at %s", key, cfg.UserOrgEndpoint),
+			"Run 'admin-cli cache sync' while the API is reachable.",
+		)
+	}
+	if err != nil {
+		return errors.NewOperationError(fmt.Sprintf("failed to read cache: %v", err), "Run 'admin-cli cache sync' to rebuild it.")
+	}
+
+	fmt.Fprintf(os.Stderr, "OFFLINE: cached data fetched %s (%s); it may be stale\n",
+		cache.FormatAge(entry.Age(time.Now())), entry.FetchedAt.Local().Format(time.RFC3339))
+	return nil
+}
+
+// storeCached records a fresh response for later --offline use. Failures
+// only affect offline mode, so they are reported in verbose mode and
+// otherwise ignored.
+func storeCached(cfg *config.Config, key string, v interface{}) {
+	c, err := cache.Open(cfg.CachePath)
+	if err == nil {
+		defer c.Close()
+		err = c.Put(cfg.UserOrgEndpoint, key, v)
+	}
+	if err != nil && cfg.Verbose {
+		fmt.Fprintf(os.Stderr, "warning: failed to update cache: %v\n", err)
+	}
+}
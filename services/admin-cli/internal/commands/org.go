@@ -22,6 +22,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/cache"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
@@ -51,13 +52,20 @@ func orgListCommand() *cobra.Command {
 	var flagQuiet bool
 	var flagUserOrgEndpoint string
 	var flagAPIKey string
+	var flagSelection output.Selection
+	var flagOffline bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List organizations",
 		Long:  "List all organizations with structured output (table, json, csv)",
+		Example: `  # Print one slug per line
+  admin-cli org list --jsonpath '.[].slug'
+
+  # Custom columns for scripting
+  admin-cli org list --template '{{.orgId}} {{.status}}'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOrgList(cmd, args, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey)
+			return runOrgList(cmd, args, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey, flagSelection, flagOffline)
 		},
 	}
 
@@ -66,12 +74,17 @@ func orgListCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
 	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
 	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key for authentication (overrides config)")
+	registerSelectionFlags(cmd, &flagSelection)
+	registerOfflineFlag(cmd, &flagOffline)
 
 	return cmd
 }
 
-func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string) error {
+func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string, sel output.Selection, offline bool) error {
 	startTime := time.Now()
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -108,24 +121,33 @@ func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbos
 		)
 	}
 
-	// Health check
-	checker := health.NewChecker(5 * time.Second)
-	requiredServices := map[string]string{
-		"user-org-service": cfg.UserOrgEndpoint,
-	}
-	if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
-		return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
-	}
+	// Fetch from the API, or from the local cache with --offline
+	var orgs []userorg.OrganizationResponse
+	if offline {
+		if err := loadCached(cfg, cache.OrgsKey(), &orgs); err != nil {
+			return err
+		}
+	} else {
+		// Health check
+		checker := health.NewChecker(5 * time.Second)
+		requiredServices := map[string]string{
+			"user-org-service": cfg.UserOrgEndpoint,
+		}
+		if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
+			return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
+		}
 
-	// Create client and list orgs
-	userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
-	orgs, err := userOrgClient.ListOrgs(cmd.Context())
-	if err != nil {
-		cliErr := errors.NewOperationError(
-			fmt.Sprintf("failed to list organizations: %v", err),
-			"Verify your API key is valid and you have permission to list organizations.",
-		)
-		return cliErr
+		// Create client and list orgs
+		userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
+		orgs, err = userOrgClient.ListOrgs(cmd.Context())
+		if err != nil {
+			cliErr := errors.NewOperationError(
+				fmt.Sprintf("failed to list organizations: %v", err),
+				"Verify your API key is valid and you have permission to list organizations.",
+			)
+			return cliErr
+		}
+		storeCached(cfg, cache.OrgsKey(), orgs)
 	}
 
 	// Audit logging
@@ -138,6 +160,9 @@ func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbos
 	})
 
 	// Format output
+	if sel.Active() {
+		return sel.Render(os.Stdout, orgs)
+	}
 	if cfg.OutputFormat == "json" {
 		return output.PrintJSON(orgs)
 	} else if cfg.OutputFormat == "csv" {
@@ -153,7 +178,7 @@ func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbos
 				org.CreatedAt,
 			})
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	} else {
 		// Table format (default)
 		headers := []string{"Org ID", "Name", "Slug", "Status", "Created At"}
@@ -171,7 +196,7 @@ func runOrgList(cmd *cobra.Command, args []string, flagFormat string, flagVerbos
 			fmt.Println("No organizations found.")
 			return nil
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	}
 }
 
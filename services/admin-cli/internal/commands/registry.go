@@ -519,6 +519,7 @@ func registryListCommand() *cobra.Command {
 	var flagFormat string
 	var flagVerbose bool
 	var flagQuiet bool
+	var flagSelection output.Selection
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -534,9 +535,12 @@ func registryListCommand() *cobra.Command {
   admin-cli registry list --status ready
 
   # List in JSON format
-  admin-cli registry list --format json`,
+  admin-cli registry list --format json
+
+  # Endpoints of ready production models
+  admin-cli registry list --environment production --status ready --jsonpath '.[].endpoint'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRegistryList(cmd, args, flagEnvironment, flagStatus, flagFormat, flagVerbose, flagQuiet)
+			return runRegistryList(cmd, args, flagEnvironment, flagStatus, flagFormat, flagVerbose, flagQuiet, flagSelection)
 		},
 	}
 
@@ -545,11 +549,16 @@ func registryListCommand() *cobra.Command {
 	cmd.Flags().StringVar(&flagFormat, "format", "table", "Output format: table, json, csv")
 	cmd.Flags().BoolVar(&flagVerbose, "verbose", false, "Enable verbose output")
 	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
+	registerSelectionFlags(cmd, &flagSelection)
 
 	return cmd
 }
 
-func runRegistryList(cmd *cobra.Command, args []string, environment, status, flagFormat string, verbose, quiet bool) error {
+func runRegistryList(cmd *cobra.Command, args []string, environment, status, flagFormat string, verbose, quiet bool, sel output.Selection) error {
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -675,9 +684,29 @@ func runRegistryList(cmd *cobra.Command, args []string, environment, status, fla
 	}
 
 	// Output based on format
+	if sel.Active() {
+		return sel.Render(os.Stdout, entries)
+	}
 	if flagFormat == "json" {
 		return output.PrintJSON(entries)
 	}
+	if flagFormat == "csv" {
+		headers := []string{"id", "model_name", "endpoint", "status", "environment", "namespace", "last_health", "updated_at"}
+		var csvRows [][]string
+		for _, entry := range entries {
+			csvRows = append(csvRows, []string{
+				fmt.Sprintf("%d", entry["id"]),
+				fmt.Sprintf("%s", entry["model_name"]),
+				fmt.Sprintf("%s", entry["endpoint"]),
+				fmt.Sprintf("%s", entry["status"]),
+				fmt.Sprintf("%s", entry["environment"]),
+				fmt.Sprintf("%s", entry["namespace"]),
+				fmt.Sprintf("%s", entry["last_health"]),
+				entry["updated_at"].(time.Time).Format(time.RFC3339),
+			})
+		}
+		return printRows(flagFormat, sel, headers, csvRows)
+	}
 
 	// Table format (default)
 	if len(entries) == 0 {
@@ -702,5 +731,5 @@ func runRegistryList(cmd *cobra.Command, args []string, environment, status, fla
 		})
 	}
 
-	return output.PrintTable(headers, tableRows)
+	return printRows(flagFormat, sel, headers, tableRows)
 }
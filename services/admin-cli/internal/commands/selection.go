@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/output"
+)
+
+// registerSelectionFlags adds --template, --jsonpath and --no-headers to a
+// list or get command.
+func registerSelectionFlags(cmd *cobra.Command, sel *output.Selection) {
+	cmd.Flags().StringVar(&sel.Template, "template", "", "Go template applied to each item, using JSON field names (e.g. '{{.orgId}} {{.slug}}')")
+	cmd.Flags().StringVar(&sel.JSONPath, "jsonpath", "", "jq-style selector printed one value per line (e.g. '.[].orgId')")
+	cmd.Flags().BoolVar(&sel.NoHeaders, "no-headers", false, "Omit the header row in table and csv output")
+}
+
+// validateSelection reports an invalid --template/--jsonpath as a usage error
+// before any request is made.
+func validateSelection(sel output.Selection) error {
+	if err := sel.Validate(); err != nil {
+		return errors.NewUsageError(err.Error())
+	}
+	return nil
+}
+
+// printRows prints rows as csv or as a table, honouring --no-headers.
+func printRows(format string, sel output.Selection, headers []string, rows [][]string) error {
+	if format == "csv" {
+		return output.WriteCSV(os.Stdout, headers, rows, sel.NoHeaders)
+	}
+	if sel.NoHeaders {
+		return output.PrintTableRows(rows)
+	}
+	return output.PrintTable(headers, rows)
+}
@@ -12,6 +12,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -188,6 +189,7 @@ func syncStatusCommand() *cobra.Command {
 	var flagAnalyticsEndpoint string
 	var flagAPIKey string
 	var flagWatch bool
+	var flagSelection output.Selection
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -195,7 +197,7 @@ func syncStatusCommand() *cobra.Command {
 		Long:  "Check status of a sync operation by job ID. Use --watch to monitor until complete.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSyncStatus(cmd, args, flagOrgID, flagJobID, flagFormat, flagVerbose, flagQuiet,
-				flagUserOrgEndpoint, flagAnalyticsEndpoint, flagAPIKey, flagWatch)
+				flagUserOrgEndpoint, flagAnalyticsEndpoint, flagAPIKey, flagWatch, flagSelection)
 		},
 	}
 
@@ -208,13 +210,17 @@ func syncStatusCommand() *cobra.Command {
 	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
 	cmd.Flags().StringVar(&flagAnalyticsEndpoint, "analytics-endpoint", "", "Analytics-service endpoint (overrides config)")
 	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key for authentication (overrides config)")
+	registerSelectionFlags(cmd, &flagSelection)
 
 	return cmd
 }
 
 func runSyncStatus(cmd *cobra.Command, args []string, flagOrgID, flagJobID, flagFormat string, flagVerbose, flagQuiet bool,
-	flagUserOrgEndpoint, flagAnalyticsEndpoint, flagAPIKey string, flagWatch bool) error {
+	flagUserOrgEndpoint, flagAnalyticsEndpoint, flagAPIKey string, flagWatch bool, sel output.Selection) error {
 	startTime := time.Now()
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -304,6 +310,9 @@ func runSyncStatus(cmd *cobra.Command, args []string, flagOrgID, flagJobID, flag
 	})
 
 	// Format output
+	if sel.Active() {
+		return sel.Render(os.Stdout, status)
+	}
 	if cfg.OutputFormat == "json" {
 		return output.PrintJSON(status)
 	} else {
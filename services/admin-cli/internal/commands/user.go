@@ -12,11 +12,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/cache"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/errors"
@@ -47,13 +49,20 @@ func userListCommand() *cobra.Command {
 	var flagQuiet bool
 	var flagUserOrgEndpoint string
 	var flagAPIKey string
+	var flagSelection output.Selection
+	var flagOffline bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List users",
 		Long:  "List users in an organization with structured output (table, json, csv)",
+		Example: `  # Emails of users without MFA
+  admin-cli user list --org-id acme --template '{{if not .mfaEnrolled}}{{.email}}{{end}}'
+
+  # CSV without the header row
+  admin-cli user list --org-id acme --format csv --no-headers`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUserList(cmd, args, flagOrgID, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey)
+			return runUserList(cmd, args, flagOrgID, flagFormat, flagVerbose, flagQuiet, flagUserOrgEndpoint, flagAPIKey, flagSelection, flagOffline)
 		},
 	}
 
@@ -63,12 +72,17 @@ func userListCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-error output")
 	cmd.Flags().StringVar(&flagUserOrgEndpoint, "user-org-endpoint", "", "User-org-service endpoint (overrides config)")
 	cmd.Flags().StringVar(&flagAPIKey, "api-key", "", "API key for authentication (overrides config)")
+	registerSelectionFlags(cmd, &flagSelection)
+	registerOfflineFlag(cmd, &flagOffline)
 
 	return cmd
 }
 
-func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string) error {
+func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string, flagVerbose, flagQuiet bool, flagUserOrgEndpoint, flagAPIKey string, sel output.Selection, offline bool) error {
 	startTime := time.Now()
+	if err := validateSelection(sel); err != nil {
+		return err
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -112,23 +126,32 @@ func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string
 		)
 	}
 
-	// Health check
-	checker := health.NewChecker(5 * time.Second)
-	requiredServices := map[string]string{
-		"user-org-service": cfg.UserOrgEndpoint,
-	}
-	if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
-		return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
-	}
+	// Fetch from the API, or from the local cache with --offline
+	var users []userorg.UserResponse
+	if offline {
+		if err := loadCached(cfg, cache.UsersKey(flagOrgID), &users); err != nil {
+			return err
+		}
+	} else {
+		// Health check
+		checker := health.NewChecker(5 * time.Second)
+		requiredServices := map[string]string{
+			"user-org-service": cfg.UserOrgEndpoint,
+		}
+		if _, err := checker.CheckRequired(cmd.Context(), requiredServices); err != nil {
+			return errors.NewServiceUnavailableError("user-org-service", cfg.UserOrgEndpoint)
+		}
 
-	// Create client and list users
-	userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
-	users, err := userOrgClient.ListUsers(cmd.Context(), flagOrgID)
-	if err != nil {
-		return errors.NewOperationError(
-			fmt.Sprintf("failed to list users: %v", err),
-			"Verify your API key is valid and you have permission to list users in this organization.",
-		)
+		// Create client and list users
+		userOrgClient := userorg.NewClient(cfg.UserOrgEndpoint, cfg.APIKey)
+		users, err = userOrgClient.ListUsers(cmd.Context(), flagOrgID)
+		if err != nil {
+			return errors.NewOperationError(
+				fmt.Sprintf("failed to list users: %v", err),
+				"Verify your API key is valid and you have permission to list users in this organization.",
+			)
+		}
+		storeCached(cfg, cache.UsersKey(flagOrgID), users)
 	}
 
 	// Audit logging
@@ -141,6 +164,9 @@ func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string
 	})
 
 	// Format output
+	if sel.Active() {
+		return sel.Render(os.Stdout, users)
+	}
 	if cfg.OutputFormat == "json" {
 		return output.PrintJSON(users)
 	} else if cfg.OutputFormat == "csv" {
@@ -160,7 +186,7 @@ func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string
 				user.CreatedAt,
 			})
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	} else {
 		headers := []string{"User ID", "Email", "Display Name", "Status", "MFA Enrolled", "Created At"}
 		var rows [][]string
@@ -182,7 +208,7 @@ func runUserList(cmd *cobra.Command, args []string, flagOrgID, flagFormat string
 			fmt.Println("No users found.")
 			return nil
 		}
-		return output.PrintTable(headers, rows)
+		return printRows(cfg.OutputFormat, sel, headers, rows)
 	}
 }
 
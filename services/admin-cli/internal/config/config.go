@@ -13,6 +13,9 @@
 // Configuration Sources:
 //   - Environment variables: ADMIN_CLI_* prefix (e.g., ADMIN_CLI_USER_ORG_ENDPOINT)
 //   - Config file: ~/.admin-cli/config.yaml (or explicit path via --config flag)
+//   - Profiles: named sections under "profiles:" in the config file; the active
+//     one (--profile, ADMIN_CLI_PROFILE or current-profile) overrides the
+//     file's top-level settings
 //   - Command-line flags: Take precedence over all other sources
 //
 // Requirements Reference:
@@ -53,6 +56,24 @@ type Config struct {
 
 	// Config File Path (for discovery)
 	ConfigFile string
+
+	// Profile is the active profile name, or empty when none is selected
+	Profile string
+
+	// CachePath is the local response cache used by --offline
+	CachePath string
+}
+
+// ProfileEnvVar selects a profile for one shell session.
+const ProfileEnvVar = "ADMIN_CLI_PROFILE"
+
+// profileOverride is set from the --profile flag and wins over
+// ADMIN_CLI_PROFILE and the config file's current-profile.
+var profileOverride string
+
+// UseProfile selects the profile used by subsequent Load calls.
+func UseProfile(name string) {
+	profileOverride = name
 }
 
 // Load loads configuration from all sources with proper precedence.
@@ -67,20 +88,13 @@ func Load() (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 
-	// Config file discovery
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		v.AddConfigPath(filepath.Join(homeDir, ".admin-cli"))
+	if err := readConfigFile(v); err != nil {
+		return nil, err
 	}
-	v.AddConfigPath(".") // Current directory
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
 
-	// Read config file (optional - ignore if not found)
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
+	profile, err := applyProfile(v)
+	if err != nil {
+		return nil, err
 	}
 
 	cfg := &Config{
@@ -94,6 +108,13 @@ func Load() (*Config, error) {
 		MaxRetries:        v.GetInt("retry.max-attempts"),
 		Timeout:           v.GetInt("retry.timeout"),
 		ConfigFile:        v.ConfigFileUsed(),
+		Profile:           profile,
+		CachePath:         v.GetString("cache.path"),
+	}
+	if cfg.CachePath == "" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			cfg.CachePath = filepath.Join(homeDir, ".admin-cli", "cache.db")
+		}
 	}
 
 	return cfg, nil
@@ -139,3 +160,55 @@ func LoadWithFlags(flagOverrides map[string]interface{}) (*Config, error) {
 	return cfg, nil
 }
 
+// FindConfigFile returns the path of the config file Load would read, or
+// an empty string when there is none.
+func FindConfigFile() (string, error) {
+	v := viper.New()
+	if err := readConfigFile(v); err != nil {
+		return "", err
+	}
+	return v.ConfigFileUsed(), nil
+}
+
+// readConfigFile discovers and reads the config file. A missing file is not
+// an error.
+func readConfigFile(v *viper.Viper) error {
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		v.AddConfigPath(filepath.Join(homeDir, ".admin-cli"))
+	}
+	v.AddConfigPath(".") // Current directory
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyProfile merges the active profile over the config file's top-level
+// settings and returns its name. Environment variables still take precedence.
+func applyProfile(v *viper.Viper) (string, error) {
+	name := profileOverride
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+	if name == "" {
+		name = v.GetString("current-profile")
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	key := "profiles." + name
+	if !v.IsSet(key) {
+		return "", fmt.Errorf("profile %q is not defined in the config file (run 'admin-cli context list' to see profiles)", name)
+	}
+	if err := v.MergeConfigMap(v.GetStringMap(key)); err != nil {
+		return "", fmt.Errorf("failed to apply profile %q: %w", name, err)
+	}
+	return name, nil
+}
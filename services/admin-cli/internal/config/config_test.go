@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -89,3 +90,109 @@ defaults:
 	}
 }
 
+
+const profileConfig = `# shared settings
+api-endpoints:
+  user-org-service: http://localhost:8081
+current-profile: dev
+profiles:
+  dev:
+    auth:
+      api-key: dev-key
+  prod:
+    api-endpoints:
+      user-org-service: https://user-org.example.com # production
+    auth:
+      api-key: prod-key
+`
+
+func writeProfileConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnvVar, "")
+	t.Cleanup(func() { UseProfile("") })
+
+	dir := filepath.Join(home, ".admin-cli")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(profileConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfiles(t *testing.T) {
+	writeProfileConfig(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Profile != "dev" || cfg.APIKey != "dev-key" || cfg.UserOrgEndpoint != "http://localhost:8081" {
+		t.Errorf("current-profile not applied: %+v", cfg)
+	}
+
+	t.Setenv(ProfileEnvVar, "prod")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Profile != "prod" || cfg.UserOrgEndpoint != "https://user-org.example.com" {
+		t.Errorf("%s not applied: %+v", ProfileEnvVar, cfg)
+	}
+
+	// Environment variables still win over the profile
+	t.Setenv("ADMIN_CLI_AUTH_API_KEY", "env-key")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.APIKey != "env-key" {
+		t.Errorf("expected environment to override profile, got %s", cfg.APIKey)
+	}
+
+	UseProfile("staging")
+	if _, err := Load(); err == nil {
+		t.Error("expected an undefined profile to be rejected")
+	}
+}
+
+func TestSetCurrentProfile(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	pf, err := ReadProfiles(path)
+	if err != nil {
+		t.Fatalf("ReadProfiles() failed: %v", err)
+	}
+	if pf.CurrentProfile != "dev" || len(pf.Profiles) != 2 || pf.Profiles[1] != "prod" {
+		t.Errorf("unexpected profiles: %+v", pf)
+	}
+
+	if err := SetCurrentProfile(path, "prod"); err != nil {
+		t.Fatalf("SetCurrentProfile() failed: %v", err)
+	}
+	if err := SetCurrentProfile(path, "staging"); err == nil {
+		t.Error("expected an undefined profile to be rejected")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"current-profile: prod", "# shared settings", "# production"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config file missing %q:\n%s", want, data)
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Profile != "prod" {
+		t.Errorf("expected prod profile after switching, got %q", cfg.Profile)
+	}
+}
@@ -29,6 +29,9 @@ func ApplyDefaults(v *viper.Viper) {
 	v.SetDefault("timeouts.health-check", 5) // seconds
 	v.SetDefault("timeouts.operation", 300) // seconds (5 minutes)
 
+	// Offline Cache (empty path means ~/.admin-cli/cache.db)
+	v.SetDefault("cache.path", "")
+
 	// Progress Indicators
 	v.SetDefault("progress.enabled", true)
 	v.SetDefault("progress.min-duration", 30) // Show progress for operations >30s
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileFile is the profile section of a config file.
+type ProfileFile struct {
+	CurrentProfile string
+	Profiles       []string // Sorted profile names
+}
+
+// ReadProfiles lists the profiles defined in the config file at path.
+func ReadProfiles(path string) (*ProfileFile, error) {
+	doc, err := readConfigNode(path)
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+
+	pf := &ProfileFile{}
+	if current := mappingValue(root, "current-profile"); current != nil {
+		pf.CurrentProfile = current.Value
+	}
+	if profiles := mappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			pf.Profiles = append(pf.Profiles, profiles.Content[i].Value)
+		}
+	}
+	sort.Strings(pf.Profiles)
+	return pf, nil
+}
+
+// SetCurrentProfile records name as current-profile in the config file at
+// path, keeping the rest of the file (including comments) unchanged. The
+// profile must be defined in the file.
+func SetCurrentProfile(path, name string) error {
+	pf, err := ReadProfiles(path)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, p := range pf.Profiles {
+		found = found || p == name
+	}
+	if !found {
+		return fmt.Errorf("profile %q is not defined in %s", name, path)
+	}
+
+	doc, err := readConfigNode(path)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	if current := mappingValue(root, "current-profile"); current != nil {
+		current.Value = name
+		current.Tag = "!!str"
+		current.Style = 0
+	} else {
+		// Insert at the top so the active profile is visible at a glance
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "current-profile"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+		}, root.Content...)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+func readConfigNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// Empty file: start a mapping so current-profile can be added
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file %s is not a YAML mapping", path)
+	}
+	return &doc, nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// Package declarative provides plan execution against the admin APIs.
+//
+// Purpose:
+//
+//	Execute plans sequentially through a Backend and collect per-action results.
+//	UserOrgBackend implements Backend on top of the user-org-service client.
+//
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/client/userorg"
+)
+
+// Metadata keys used to record declarative state on organizations and keys.
+const (
+	metadataBudgetPolicyID = "budgetPolicyId"
+	metadataModelAliases   = "modelAliases"
+	annotationManagedBy    = "managedBy"
+	annotationKeyName      = "name"
+	managedByValue         = "admin-cli-manifest"
+)
+
+// Backend reads current state and executes individual actions.
+type Backend interface {
+	ReadState(ctx context.Context, slugs []string) (*State, error)
+	Execute(ctx context.Context, action Action) (*Result, error)
+}
+
+// Result records the outcome of an executed action.
+type Result struct {
+	Action Action `json:"action"`
+	ID     string `json:"id,omitempty"`
+	// Secret is set once when an API key is issued.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Apply executes the plan in order, stopping at the first failure. Results for
+// actions that completed before the failure are returned alongside the error.
+func Apply(ctx context.Context, backend Backend, plan *Plan) ([]Result, error) {
+	results := make([]Result, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result, err := backend.Execute(ctx, action)
+		if err != nil {
+			return results, fmt.Errorf("%s %s: %w", action.Type, action.Address(), err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// Slugs returns the org slugs declared in the manifest.
+func (m *Manifest) Slugs() []string {
+	slugs := make([]string, 0, len(m.Orgs))
+	for _, org := range m.Orgs {
+		slugs = append(slugs, org.Slug)
+	}
+	return slugs
+}
+
+// UserOrgBackend implements Backend using the user-org-service API.
+type UserOrgBackend struct {
+	client *userorg.Client
+
+	// IDs of principals created during the current run, keyed by org slug.
+	users           map[string]map[string]string
+	serviceAccounts map[string]map[string]string
+}
+
+// NewUserOrgBackend creates a backend for the given client.
+func NewUserOrgBackend(c *userorg.Client) *UserOrgBackend {
+	return &UserOrgBackend{
+		client:          c,
+		users:           make(map[string]map[string]string),
+		serviceAccounts: make(map[string]map[string]string),
+	}
+}
+
+// ReadState loads the current state of the given orgs. Orgs that do not exist
+// are omitted from the returned state.
+func (b *UserOrgBackend) ReadState(ctx context.Context, slugs []string) (*State, error) {
+	orgs, err := b.client.ListOrgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]userorg.OrganizationResponse, len(orgs))
+	for _, org := range orgs {
+		bySlug[org.Slug] = org
+	}
+
+	state := &State{Orgs: make(map[string]*OrgState)}
+	for _, slug := range slugs {
+		org, ok := bySlug[slug]
+		if !ok {
+			continue
+		}
+		orgState := &OrgState{
+			ID:              org.OrgID,
+			Name:            org.Name,
+			ModelAliases:    map[string]string{},
+			Users:           map[string]string{},
+			ServiceAccounts: map[string]string{},
+			APIKeys:         map[string]string{},
+		}
+		if v, ok := org.Metadata[metadataBudgetPolicyID].(string); ok {
+			orgState.BudgetPolicyID = v
+		}
+		if aliases, ok := org.Metadata[metadataModelAliases].(map[string]interface{}); ok {
+			for alias, target := range aliases {
+				if s, ok := target.(string); ok {
+					orgState.ModelAliases[alias] = s
+				}
+			}
+		}
+
+		users, err := b.client.ListUsers(ctx, org.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("org %s: %w", slug, err)
+		}
+		for _, user := range users {
+			orgState.Users[strings.ToLower(user.Email)] = user.UserID
+		}
+
+		accounts, err := b.client.ListServiceAccounts(ctx, org.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("org %s: %w", slug, err)
+		}
+		for _, sa := range accounts {
+			orgState.ServiceAccounts[sa.Name] = sa.ServiceAccountID
+		}
+
+		keys, err := b.client.ListAPIKeys(ctx, org.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("org %s: %w", slug, err)
+		}
+		for _, key := range keys {
+			if key.Status == "revoked" || key.Metadata[annotationManagedBy] != managedByValue {
+				continue
+			}
+			if name, ok := key.Metadata[annotationKeyName].(string); ok && name != "" {
+				orgState.APIKeys[name] = key.APIKeyID
+			}
+		}
+
+		state.Orgs[slug] = orgState
+		b.users[slug] = orgState.Users
+		b.serviceAccounts[slug] = orgState.ServiceAccounts
+	}
+	return state, nil
+}
+
+// Execute performs a single action against user-org-service.
+func (b *UserOrgBackend) Execute(ctx context.Context, action Action) (*Result, error) {
+	result := &Result{Action: action, ID: action.ID}
+
+	switch action.Resource {
+	case ResourceOrg:
+		switch action.Type {
+		case ActionCreate:
+			org, err := b.client.CreateOrg(ctx, userorg.CreateOrgRequest{
+				Name:              action.OrgSpec.Name,
+				Slug:              action.OrgSpec.Slug,
+				BillingOwnerEmail: action.OrgSpec.BillingOwnerEmail,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.ID = org.OrgID
+			b.users[action.Org] = map[string]string{}
+			b.serviceAccounts[action.Org] = map[string]string{}
+		case ActionUpdate:
+			name := action.OrgSpec.Name
+			if _, err := b.client.UpdateOrg(ctx, action.Org, userorg.UpdateOrgRequest{DisplayName: &name}); err != nil {
+				return nil, err
+			}
+		case ActionDelete:
+			if err := b.client.DeleteOrg(ctx, action.Org); err != nil {
+				return nil, err
+			}
+		}
+
+	case ResourceBudget, ResourceModelAliases:
+		if err := b.updateOrgSettings(ctx, action); err != nil {
+			return nil, err
+		}
+
+	case ResourceUser:
+		switch action.Type {
+		case ActionCreate:
+			user, err := b.client.InviteUser(ctx, action.Org, userorg.InviteUserRequest{
+				Email: action.UserSpec.Email,
+				Roles: action.UserSpec.Roles,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.ID = user.UserID
+			b.principals(b.users, action.Org)[strings.ToLower(action.UserSpec.Email)] = user.UserID
+		case ActionDelete:
+			if err := b.client.DeleteUser(ctx, action.Org, action.ID); err != nil {
+				return nil, err
+			}
+		}
+
+	case ResourceServiceAccount:
+		switch action.Type {
+		case ActionCreate:
+			req := userorg.CreateServiceAccountRequest{Name: action.ServiceAccount.Name}
+			if action.ServiceAccount.Description != "" {
+				desc := action.ServiceAccount.Description
+				req.Description = &desc
+			}
+			sa, err := b.client.CreateServiceAccount(ctx, action.Org, req)
+			if err != nil {
+				return nil, err
+			}
+			result.ID = sa.ServiceAccountID
+			b.principals(b.serviceAccounts, action.Org)[action.ServiceAccount.Name] = sa.ServiceAccountID
+		case ActionDelete:
+			if err := b.client.DeleteServiceAccount(ctx, action.Org, action.ID); err != nil {
+				return nil, err
+			}
+		}
+
+	case ResourceAPIKey:
+		switch action.Type {
+		case ActionCreate:
+			issued, err := b.issueKey(ctx, action)
+			if err != nil {
+				return nil, err
+			}
+			result.ID = issued.APIKeyID
+			result.Secret = issued.Secret
+		case ActionDelete:
+			if err := b.client.DeleteAPIKey(ctx, action.Org, action.ID); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q", action.Resource)
+	}
+
+	return result, nil
+}
+
+func (b *UserOrgBackend) issueKey(ctx context.Context, action Action) (*userorg.IssuedAPIKeyResponse, error) {
+	req := userorg.IssueAPIKeyRequest{
+		Scopes:        action.APIKey.Scopes,
+		ExpiresInDays: action.APIKey.ExpiresInDays,
+		Annotations: map[string]interface{}{
+			annotationManagedBy: managedByValue,
+			annotationKeyName:   action.APIKey.Name,
+		},
+	}
+
+	switch action.OwnerKind {
+	case OwnerUser:
+		userID := b.principals(b.users, action.Org)[strings.ToLower(action.Owner)]
+		if userID == "" {
+			return nil, fmt.Errorf("user %q not found", action.Owner)
+		}
+		return b.client.IssueUserAPIKey(ctx, action.Org, userID, req)
+	case OwnerServiceAccount:
+		saID := b.principals(b.serviceAccounts, action.Org)[action.Owner]
+		if saID == "" {
+			return nil, fmt.Errorf("service account %q not found", action.Owner)
+		}
+		return b.client.IssueServiceAccountAPIKey(ctx, action.Org, saID, req)
+	default:
+		return nil, fmt.Errorf("unsupported API key owner %q", action.OwnerKind)
+	}
+}
+
+// updateOrgSettings merges budget and model alias settings into org metadata.
+// The update endpoint replaces metadata wholesale, so current metadata is read
+// first and only the declarative keys are modified.
+func (b *UserOrgBackend) updateOrgSettings(ctx context.Context, action Action) error {
+	org, err := b.client.GetOrg(ctx, action.Org)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]interface{}, len(org.Metadata)+2)
+	for k, v := range org.Metadata {
+		metadata[k] = v
+	}
+
+	req := userorg.UpdateOrgRequest{Metadata: metadata}
+	switch action.Resource {
+	case ResourceBudget:
+		if action.Type == ActionDelete {
+			delete(metadata, metadataBudgetPolicyID)
+		} else {
+			policyID := action.OrgSpec.Budget.PolicyID
+			metadata[metadataBudgetPolicyID] = policyID
+			req.BudgetPolicyID = &policyID
+		}
+	case ResourceModelAliases:
+		if action.Type == ActionDelete {
+			delete(metadata, metadataModelAliases)
+		} else {
+			aliases := make(map[string]interface{}, len(action.OrgSpec.ModelAliases))
+			for alias, target := range action.OrgSpec.ModelAliases {
+				aliases[alias] = target
+			}
+			metadata[metadataModelAliases] = aliases
+		}
+	}
+
+	_, err = b.client.UpdateOrg(ctx, action.Org, req)
+	return err
+}
+
+func (b *UserOrgBackend) principals(index map[string]map[string]string, slug string) map[string]string {
+	if index[slug] == nil {
+		index[slug] = map[string]string{}
+	}
+	return index[slug]
+}
@@ -0,0 +1,185 @@
+// Package declarative provides plan/apply/destroy semantics for platform resources.
+//
+// Purpose:
+//
+//	Manage orgs, users, service accounts, API keys, budgets and model aliases
+//	from a declarative YAML/JSON manifest. A manifest describes desired state;
+//	the package reads current state through a Backend, computes a Plan of
+//	create/update/delete actions, and executes it against the admin APIs.
+//
+// Dependencies:
+//   - gopkg.in/yaml.v3: Manifest parsing
+//   - internal/client/userorg: Backend implementation against user-org-service
+//
+// Key Responsibilities:
+//   - Parse and validate manifests (LoadManifest, Manifest.Validate)
+//   - Compute plans for apply and destroy (ComputePlan, ComputeDestroyPlan)
+//   - Execute plans in dependency order (Apply)
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#US-002 (Day-2 Management)
+//
+package declarative
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestAPIVersion is the only manifest schema version currently understood.
+const ManifestAPIVersion = "admin.ai-aas/v1"
+
+// ManifestKind identifies a platform resources manifest.
+const ManifestKind = "PlatformResources"
+
+// Manifest is the root document describing desired platform state.
+type Manifest struct {
+	APIVersion string    `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string    `json:"kind" yaml:"kind"`
+	Orgs       []OrgSpec `json:"orgs" yaml:"orgs"`
+}
+
+// OrgSpec describes an organization and the resources it owns.
+type OrgSpec struct {
+	Slug              string               `json:"slug" yaml:"slug"`
+	Name              string               `json:"name" yaml:"name"`
+	BillingOwnerEmail string               `json:"billingOwnerEmail,omitempty" yaml:"billingOwnerEmail,omitempty"`
+	Budget            *BudgetSpec          `json:"budget,omitempty" yaml:"budget,omitempty"`
+	ModelAliases      map[string]string    `json:"modelAliases,omitempty" yaml:"modelAliases,omitempty"`
+	Users             []UserSpec           `json:"users,omitempty" yaml:"users,omitempty"`
+	ServiceAccounts   []ServiceAccountSpec `json:"serviceAccounts,omitempty" yaml:"serviceAccounts,omitempty"`
+}
+
+// BudgetSpec binds an organization to a budget policy.
+type BudgetSpec struct {
+	PolicyID string `json:"policyId" yaml:"policyId"`
+}
+
+// UserSpec describes an invited organization member.
+type UserSpec struct {
+	Email string `json:"email" yaml:"email"`
+	// Roles are assigned on invite; later changes are not planned (see ComputePlan).
+	Roles   []string     `json:"roles,omitempty" yaml:"roles,omitempty"`
+	APIKeys []APIKeySpec `json:"apiKeys,omitempty" yaml:"apiKeys,omitempty"`
+}
+
+// ServiceAccountSpec describes a service account used for programmatic access.
+type ServiceAccountSpec struct {
+	Name        string       `json:"name" yaml:"name"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	APIKeys     []APIKeySpec `json:"apiKeys,omitempty" yaml:"apiKeys,omitempty"`
+}
+
+// APIKeySpec describes an API key. Keys are matched to existing keys by name,
+// which is recorded in the key annotations when the key is issued.
+type APIKeySpec struct {
+	Name          string   `json:"name" yaml:"name"`
+	Scopes        []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	ExpiresInDays *int     `json:"expiresInDays,omitempty" yaml:"expiresInDays,omitempty"`
+}
+
+// LoadManifest reads and validates a manifest from a JSON or YAML file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest decodes a manifest from JSON or YAML bytes and validates it.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	// Try JSON first, then YAML (same convention as --file inputs)
+	if err := json.Unmarshal(data, &m); err != nil {
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest for structural errors and duplicate identities.
+func (m *Manifest) Validate() error {
+	if m.APIVersion != ManifestAPIVersion {
+		return fmt.Errorf("unsupported apiVersion %q (expected %q)", m.APIVersion, ManifestAPIVersion)
+	}
+	if m.Kind != ManifestKind {
+		return fmt.Errorf("unsupported kind %q (expected %q)", m.Kind, ManifestKind)
+	}
+
+	slugs := make(map[string]bool)
+	for i, org := range m.Orgs {
+		if org.Slug == "" {
+			return fmt.Errorf("orgs[%d]: slug is required", i)
+		}
+		if org.Name == "" {
+			return fmt.Errorf("orgs[%d] (%s): name is required", i, org.Slug)
+		}
+		if slugs[org.Slug] {
+			return fmt.Errorf("orgs[%d]: duplicate slug %q", i, org.Slug)
+		}
+		slugs[org.Slug] = true
+
+		if org.Budget != nil && org.Budget.PolicyID == "" {
+			return fmt.Errorf("org %s: budget.policyId is required when budget is set", org.Slug)
+		}
+		for alias, model := range org.ModelAliases {
+			if alias == "" || model == "" {
+				return fmt.Errorf("org %s: model aliases must have non-empty names and targets", org.Slug)
+			}
+		}
+
+		emails := make(map[string]bool)
+		keyNames := make(map[string]bool)
+		for j, user := range org.Users {
+			email := strings.ToLower(user.Email)
+			if email == "" {
+				return fmt.Errorf("org %s: users[%d]: email is required", org.Slug, j)
+			}
+			if emails[email] {
+				return fmt.Errorf("org %s: duplicate user %q", org.Slug, user.Email)
+			}
+			emails[email] = true
+			if err := validateKeys(org.Slug, user.APIKeys, keyNames); err != nil {
+				return err
+			}
+		}
+
+		accounts := make(map[string]bool)
+		for j, sa := range org.ServiceAccounts {
+			if sa.Name == "" {
+				return fmt.Errorf("org %s: serviceAccounts[%d]: name is required", org.Slug, j)
+			}
+			if accounts[sa.Name] {
+				return fmt.Errorf("org %s: duplicate service account %q", org.Slug, sa.Name)
+			}
+			accounts[sa.Name] = true
+			if err := validateKeys(org.Slug, sa.APIKeys, keyNames); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateKeys ensures API key names are present and unique within an org.
+func validateKeys(orgSlug string, keys []APIKeySpec, seen map[string]bool) error {
+	for _, key := range keys {
+		if key.Name == "" {
+			return fmt.Errorf("org %s: API key name is required", orgSlug)
+		}
+		if seen[key.Name] {
+			return fmt.Errorf("org %s: duplicate API key name %q", orgSlug, key.Name)
+		}
+		seen[key.Name] = true
+	}
+	return nil
+}
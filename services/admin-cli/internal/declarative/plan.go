@@ -0,0 +1,296 @@
+// Package declarative provides plan computation for platform resource manifests.
+//
+// Purpose:
+//
+//	Diff desired state (Manifest) against current state (State) and produce an
+//	ordered list of actions. Parents are always created before children and
+//	deleted after them, so a plan can be executed sequentially.
+//
+package declarative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ActionType is the kind of change an action performs.
+type ActionType string
+
+const (
+	ActionCreate ActionType = "create"
+	ActionUpdate ActionType = "update"
+	ActionDelete ActionType = "delete"
+)
+
+// ResourceType identifies the resource an action targets.
+type ResourceType string
+
+const (
+	ResourceOrg            ResourceType = "org"
+	ResourceBudget         ResourceType = "budget"
+	ResourceModelAliases   ResourceType = "model_aliases"
+	ResourceUser           ResourceType = "user"
+	ResourceServiceAccount ResourceType = "service_account"
+	ResourceAPIKey         ResourceType = "api_key"
+)
+
+// OwnerKind identifies which principal owns an API key.
+type OwnerKind string
+
+const (
+	OwnerUser           OwnerKind = "user"
+	OwnerServiceAccount OwnerKind = "service_account"
+)
+
+// Action is a single planned change.
+type Action struct {
+	Type     ActionType   `json:"type"`
+	Resource ResourceType `json:"resource"`
+	Org      string       `json:"org"`
+	Name     string       `json:"name"`
+	ID       string       `json:"id,omitempty"`
+	Changes  []string     `json:"changes,omitempty"`
+
+	// Desired state payloads (set for create/update actions as applicable)
+	OrgSpec        *OrgSpec            `json:"-"`
+	UserSpec       *UserSpec           `json:"-"`
+	ServiceAccount *ServiceAccountSpec `json:"-"`
+	APIKey         *APIKeySpec         `json:"-"`
+	OwnerKind      OwnerKind           `json:"ownerKind,omitempty"`
+	Owner          string              `json:"owner,omitempty"`
+}
+
+// Address returns a stable, human-readable identifier for the action target.
+func (a Action) Address() string {
+	if a.Resource == ResourceOrg {
+		return fmt.Sprintf("org.%s", a.Org)
+	}
+	return fmt.Sprintf("org.%s.%s.%s", a.Org, a.Resource, a.Name)
+}
+
+// Plan is an ordered set of actions.
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+// Empty reports whether the plan has no changes.
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Summary counts actions by type.
+func (p *Plan) Summary() map[ActionType]int {
+	summary := map[ActionType]int{ActionCreate: 0, ActionUpdate: 0, ActionDelete: 0}
+	for _, action := range p.Actions {
+		summary[action.Type]++
+	}
+	return summary
+}
+
+// State is the current state of the orgs referenced by a manifest.
+type State struct {
+	Orgs map[string]*OrgState
+}
+
+// OrgState is the observed state of one organization.
+type OrgState struct {
+	ID             string
+	Name           string
+	BudgetPolicyID string
+	ModelAliases   map[string]string
+	// Users maps lower-cased email to user ID.
+	Users map[string]string
+	// ServiceAccounts maps service account name to ID.
+	ServiceAccounts map[string]string
+	// APIKeys maps managed API key name to key ID. Keys not issued from a
+	// manifest are never listed here and therefore never pruned.
+	APIKeys map[string]string
+}
+
+// ComputePlan diffs the manifest against current state.
+//
+// Organizations, users and service accounts missing from the manifest are left
+// untouched; only manifest-managed API keys that are no longer declared inside
+// a declared org are deleted.
+//
+// User roles are only applied when a user is invited. The user-org API neither
+// returns a user's roles nor changes them (PUT .../roles answers 501), so role
+// drift on existing users is not detected or planned.
+func ComputePlan(m *Manifest, state *State) *Plan {
+	plan := &Plan{}
+	for i := range m.Orgs {
+		desired := &m.Orgs[i]
+		current := state.lookup(desired.Slug)
+
+		if current == nil {
+			plan.add(Action{Type: ActionCreate, Resource: ResourceOrg, Org: desired.Slug, Name: desired.Slug, OrgSpec: desired})
+		} else if current.Name != desired.Name {
+			plan.add(Action{
+				Type: ActionUpdate, Resource: ResourceOrg, Org: desired.Slug, Name: desired.Slug, ID: current.ID,
+				Changes: []string{fmt.Sprintf("name: %q -> %q", current.Name, desired.Name)},
+				OrgSpec: desired,
+			})
+		}
+
+		planOrgSettings(plan, desired, current)
+		planPrincipals(plan, desired, current)
+	}
+	return plan
+}
+
+// ComputeDestroyPlan produces the actions that remove every declared resource
+// that currently exists, children first.
+func ComputeDestroyPlan(m *Manifest, state *State) *Plan {
+	plan := &Plan{}
+	for i := len(m.Orgs) - 1; i >= 0; i-- {
+		desired := &m.Orgs[i]
+		current := state.lookup(desired.Slug)
+		if current == nil {
+			continue
+		}
+
+		for _, name := range sortedKeys(current.APIKeys) {
+			plan.add(Action{Type: ActionDelete, Resource: ResourceAPIKey, Org: desired.Slug, Name: name, ID: current.APIKeys[name]})
+		}
+		for _, sa := range desired.ServiceAccounts {
+			if id, ok := current.ServiceAccounts[sa.Name]; ok {
+				plan.add(Action{Type: ActionDelete, Resource: ResourceServiceAccount, Org: desired.Slug, Name: sa.Name, ID: id})
+			}
+		}
+		for _, user := range desired.Users {
+			if id, ok := current.Users[strings.ToLower(user.Email)]; ok {
+				plan.add(Action{Type: ActionDelete, Resource: ResourceUser, Org: desired.Slug, Name: user.Email, ID: id})
+			}
+		}
+		plan.add(Action{Type: ActionDelete, Resource: ResourceOrg, Org: desired.Slug, Name: desired.Slug, ID: current.ID})
+	}
+	return plan
+}
+
+func planOrgSettings(plan *Plan, desired *OrgSpec, current *OrgState) {
+	currentBudget := ""
+	currentAliases := map[string]string{}
+	if current != nil {
+		currentBudget = current.BudgetPolicyID
+		if current.ModelAliases != nil {
+			currentAliases = current.ModelAliases
+		}
+	}
+
+	desiredBudget := ""
+	if desired.Budget != nil {
+		desiredBudget = desired.Budget.PolicyID
+	}
+	if desiredBudget != currentBudget {
+		action := Action{Resource: ResourceBudget, Org: desired.Slug, Name: "policy", OrgSpec: desired,
+			Changes: []string{fmt.Sprintf("policyId: %q -> %q", currentBudget, desiredBudget)}}
+		switch {
+		case currentBudget == "":
+			action.Type = ActionCreate
+		case desiredBudget == "":
+			action.Type = ActionDelete
+		default:
+			action.Type = ActionUpdate
+		}
+		plan.add(action)
+	}
+
+	if changes := diffAliases(currentAliases, desired.ModelAliases); len(changes) > 0 {
+		action := Action{Resource: ResourceModelAliases, Org: desired.Slug, Name: "aliases", OrgSpec: desired, Changes: changes}
+		switch {
+		case len(currentAliases) == 0:
+			action.Type = ActionCreate
+		case len(desired.ModelAliases) == 0:
+			action.Type = ActionDelete
+		default:
+			action.Type = ActionUpdate
+		}
+		plan.add(action)
+	}
+}
+
+func planPrincipals(plan *Plan, desired *OrgSpec, current *OrgState) {
+	declaredKeys := make(map[string]bool)
+
+	for i := range desired.Users {
+		user := &desired.Users[i]
+		if current == nil || current.Users[strings.ToLower(user.Email)] == "" {
+			plan.add(Action{Type: ActionCreate, Resource: ResourceUser, Org: desired.Slug, Name: user.Email, UserSpec: user})
+		}
+		for j := range user.APIKeys {
+			key := &user.APIKeys[j]
+			declaredKeys[key.Name] = true
+			planKey(plan, desired.Slug, current, key, OwnerUser, user.Email)
+		}
+	}
+
+	for i := range desired.ServiceAccounts {
+		sa := &desired.ServiceAccounts[i]
+		if current == nil || current.ServiceAccounts[sa.Name] == "" {
+			plan.add(Action{Type: ActionCreate, Resource: ResourceServiceAccount, Org: desired.Slug, Name: sa.Name, ServiceAccount: sa})
+		}
+		for j := range sa.APIKeys {
+			key := &sa.APIKeys[j]
+			declaredKeys[key.Name] = true
+			planKey(plan, desired.Slug, current, key, OwnerServiceAccount, sa.Name)
+		}
+	}
+
+	if current == nil {
+		return
+	}
+	for _, name := range sortedKeys(current.APIKeys) {
+		if !declaredKeys[name] {
+			plan.add(Action{Type: ActionDelete, Resource: ResourceAPIKey, Org: desired.Slug, Name: name, ID: current.APIKeys[name]})
+		}
+	}
+}
+
+func planKey(plan *Plan, orgSlug string, current *OrgState, key *APIKeySpec, ownerKind OwnerKind, owner string) {
+	if current != nil && current.APIKeys[key.Name] != "" {
+		return
+	}
+	plan.add(Action{
+		Type: ActionCreate, Resource: ResourceAPIKey, Org: orgSlug, Name: key.Name,
+		APIKey: key, OwnerKind: ownerKind, Owner: owner,
+	})
+}
+
+func diffAliases(current, desired map[string]string) []string {
+	var changes []string
+	for _, alias := range sortedKeys(desired) {
+		target := desired[alias]
+		if existing, ok := current[alias]; !ok {
+			changes = append(changes, fmt.Sprintf("+ %s -> %s", alias, target))
+		} else if existing != target {
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", alias, existing, target))
+		}
+	}
+	for _, alias := range sortedKeys(current) {
+		if _, ok := desired[alias]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s", alias))
+		}
+	}
+	return changes
+}
+
+func (p *Plan) add(action Action) {
+	p.Actions = append(p.Actions, action)
+}
+
+func (s *State) lookup(slug string) *OrgState {
+	if s == nil || s.Orgs == nil {
+		return nil
+	}
+	return s.Orgs[slug]
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+// Package declarative provides tests for manifest parsing and plan computation.
+package declarative
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+apiVersion: admin.ai-aas/v1
+kind: PlatformResources
+orgs:
+  - slug: acme
+    name: Acme Corp
+    budget:
+      policyId: standard
+    modelAliases:
+      default: llama-2-7b
+    users:
+      - email: ops@acme.test
+        roles: [admin]
+    serviceAccounts:
+      - name: ci
+        apiKeys:
+          - name: ci-deploy
+            scopes: [inference]
+`
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	require.NoError(t, err)
+	require.Len(t, m.Orgs, 1)
+	assert.Equal(t, "acme", m.Orgs[0].Slug)
+	assert.Equal(t, "standard", m.Orgs[0].Budget.PolicyID)
+	assert.Equal(t, []string{"acme"}, m.Slugs())
+}
+
+func TestParseManifestValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{"wrong version", "apiVersion: v0\nkind: PlatformResources\n"},
+		{"missing slug", "apiVersion: admin.ai-aas/v1\nkind: PlatformResources\norgs:\n  - name: x\n"},
+		{"duplicate key name", `apiVersion: admin.ai-aas/v1
+kind: PlatformResources
+orgs:
+  - slug: a
+    name: A
+    users:
+      - email: u@a.test
+        apiKeys: [{name: k}]
+    serviceAccounts:
+      - name: sa
+        apiKeys: [{name: k}]
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestComputePlanCreatesEverythingForNewOrg(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	require.NoError(t, err)
+
+	plan := ComputePlan(m, &State{})
+	var resources []ResourceType
+	for _, action := range plan.Actions {
+		assert.Equal(t, ActionCreate, action.Type)
+		resources = append(resources, action.Resource)
+	}
+	assert.Equal(t, []ResourceType{
+		ResourceOrg, ResourceBudget, ResourceModelAliases, ResourceUser, ResourceServiceAccount, ResourceAPIKey,
+	}, resources)
+}
+
+func TestComputePlanConvergedAndDrift(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	require.NoError(t, err)
+
+	state := &State{Orgs: map[string]*OrgState{
+		"acme": {
+			ID:              "org-1",
+			Name:            "Acme Corp",
+			BudgetPolicyID:  "standard",
+			ModelAliases:    map[string]string{"default": "llama-2-7b"},
+			Users:           map[string]string{"ops@acme.test": "user-1"},
+			ServiceAccounts: map[string]string{"ci": "sa-1"},
+			APIKeys:         map[string]string{"ci-deploy": "key-1"},
+		},
+	}}
+	assert.True(t, ComputePlan(m, state).Empty())
+
+	// Drift: renamed org, changed alias, stale managed key
+	state.Orgs["acme"].Name = "Acme"
+	state.Orgs["acme"].ModelAliases["default"] = "mistral-7b"
+	state.Orgs["acme"].APIKeys["old-key"] = "key-2"
+
+	plan := ComputePlan(m, state)
+	summary := plan.Summary()
+	assert.Equal(t, 2, summary[ActionUpdate])
+	assert.Equal(t, 1, summary[ActionDelete])
+	assert.Equal(t, "org.acme.api_key.old-key", plan.Actions[len(plan.Actions)-1].Address())
+}
+
+func TestComputeDestroyPlanDeletesChildrenFirst(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	require.NoError(t, err)
+
+	state := &State{Orgs: map[string]*OrgState{
+		"acme": {
+			ID:              "org-1",
+			Users:           map[string]string{"ops@acme.test": "user-1"},
+			ServiceAccounts: map[string]string{"ci": "sa-1"},
+			APIKeys:         map[string]string{"ci-deploy": "key-1"},
+		},
+	}}
+
+	plan := ComputeDestroyPlan(m, state)
+	require.Len(t, plan.Actions, 4)
+	assert.Equal(t, ResourceAPIKey, plan.Actions[0].Resource)
+	assert.Equal(t, ResourceOrg, plan.Actions[3].Resource)
+	for _, action := range plan.Actions {
+		assert.Equal(t, ActionDelete, action.Type)
+	}
+}
+
+type recordingBackend struct {
+	executed []Action
+	failOn   ResourceType
+}
+
+func (b *recordingBackend) ReadState(ctx context.Context, slugs []string) (*State, error) {
+	return &State{}, nil
+}
+
+func (b *recordingBackend) Execute(ctx context.Context, action Action) (*Result, error) {
+	if action.Resource == b.failOn {
+		return nil, assert.AnError
+	}
+	b.executed = append(b.executed, action)
+	return &Result{Action: action}, nil
+}
+
+func TestApplyStopsAtFirstFailure(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	require.NoError(t, err)
+	plan := ComputePlan(m, &State{})
+
+	backend := &recordingBackend{failOn: ResourceUser}
+	results, err := Apply(context.Background(), backend, plan)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "org.acme.user.ops@acme.test")
+	assert.Len(t, results, 3)
+}
@@ -0,0 +1,463 @@
+// Package doctor diagnoses the Admin CLI environment.
+//
+// Purpose:
+//
+//	Run a fixed sequence of checks (configuration, service reachability,
+//	credential freshness, token scopes, CLI/service version skew) and report
+//	each result with an actionable remediation step, so operators can fix a
+//	broken setup without reading service logs.
+//
+// Dependencies:
+//   - internal/config: Loaded CLI configuration
+//   - internal/health: Service health endpoints
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#NFR-014 (clear, actionable error messages)
+//   - specs/009-admin-cli/spec.md#FR-008 (health checks for service dependencies)
+//
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/health"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Check names, in the order they run.
+const (
+	CheckConfig      = "config"
+	CheckUserOrg     = "user-org-service"
+	CheckAnalytics   = "analytics-service"
+	CheckCredentials = "credentials"
+	CheckScopes      = "scopes"
+	CheckVersion     = "version"
+)
+
+// DefaultExpiryWarning is how close to expiry a credential is reported as stale.
+const DefaultExpiryWarning = 7 * 24 * time.Hour
+
+// Result is the outcome of one check.
+type Result struct {
+	Name        string `json:"name"`
+	Status      Status `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report is the outcome of a doctor run. OK is false when any check failed;
+// warnings do not affect it.
+type Report struct {
+	OK         bool     `json:"ok"`
+	CLIVersion string   `json:"cli_version"`
+	CLICommit  string   `json:"cli_commit,omitempty"`
+	Checks     []Result `json:"checks"`
+}
+
+// Options configures a doctor run.
+type Options struct {
+	Config     *config.Config
+	ConfigErr  error // Error from config.Load; Config may be nil when set
+	CLIVersion string
+	CLICommit  string
+	// RequiredScopes are the scopes the credential needs (default: admin).
+	RequiredScopes []string
+	// ExpiryWarning reports credentials expiring within it (default: 7 days).
+	ExpiryWarning time.Duration
+	// HTTPClient is used for all requests (default: 5s timeout).
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+// credential is what the user-org-service reports about the configured secret.
+type credential struct {
+	kind      string // "api key" or "access token"
+	scopes    []string
+	expiresAt time.Time
+}
+
+// Run executes every check and returns the report. Later checks are skipped
+// when the ones they depend on failed.
+func Run(ctx context.Context, opts Options) Report {
+	if len(opts.RequiredScopes) == 0 {
+		opts.RequiredScopes = []string{"admin"}
+	}
+	if opts.ExpiryWarning <= 0 {
+		opts.ExpiryWarning = DefaultExpiryWarning
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	report := Report{OK: true, CLIVersion: opts.CLIVersion, CLICommit: opts.CLICommit}
+	add := func(r Result) {
+		if r.Status == StatusFail {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, r)
+	}
+
+	add(checkConfig(opts))
+	cfg := opts.Config
+	if cfg == nil {
+		for _, name := range []string{CheckUserOrg, CheckAnalytics, CheckCredentials, CheckScopes, CheckVersion} {
+			add(skipped(name, "configuration could not be loaded"))
+		}
+		return report
+	}
+
+	checker := health.NewChecker(opts.HTTPClient.Timeout)
+	userOrg := checkService(ctx, checker, CheckUserOrg, cfg.UserOrgEndpoint, StatusFail)
+	add(userOrg)
+	add(checkService(ctx, checker, CheckAnalytics, cfg.AnalyticsEndpoint, StatusWarn))
+	if userOrg.Status != StatusOK {
+		for _, name := range []string{CheckCredentials, CheckScopes, CheckVersion} {
+			add(skipped(name, "user-org-service is unreachable"))
+		}
+		return report
+	}
+
+	cred, result := checkCredentials(ctx, opts)
+	add(result)
+	if cred == nil {
+		for _, name := range []string{CheckScopes, CheckVersion} {
+			add(skipped(name, "no valid credential"))
+		}
+		return report
+	}
+	add(checkScopes(cred, opts.RequiredScopes))
+	add(checkVersion(ctx, opts))
+	return report
+}
+
+func skipped(name, reason string) Result {
+	return Result{Name: name, Status: StatusSkip, Message: "skipped: " + reason}
+}
+
+func checkConfig(opts Options) Result {
+	if opts.ConfigErr != nil {
+		return Result{
+			Name:        CheckConfig,
+			Status:      StatusFail,
+			Message:     opts.ConfigErr.Error(),
+			Remediation: "Fix the YAML syntax in ~/.admin-cli/config.yaml (or ./config.yaml), or move the file aside to fall back to defaults.",
+		}
+	}
+	cfg := opts.Config
+	var problems []string
+	for _, setting := range []struct{ name, endpoint string }{
+		{"api-endpoints.user-org-service", cfg.UserOrgEndpoint},
+		{"api-endpoints.analytics-service", cfg.AnalyticsEndpoint},
+	} {
+		name, endpoint := setting.name, setting.endpoint
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s %q is not an http(s) URL", name, endpoint))
+		}
+	}
+	switch cfg.OutputFormat {
+	case "table", "json", "csv":
+	default:
+		problems = append(problems, fmt.Sprintf("defaults.output-format %q is not one of table, json, csv", cfg.OutputFormat))
+	}
+	if len(problems) > 0 {
+		return Result{
+			Name:        CheckConfig,
+			Status:      StatusFail,
+			Message:     strings.Join(problems, "; "),
+			Remediation: "Correct these settings in the config file or the matching ADMIN_CLI_* environment variables.",
+		}
+	}
+	if cfg.ConfigFile == "" {
+		return Result{Name: CheckConfig, Status: StatusOK, Message: "no config file found, using defaults and ADMIN_CLI_* environment variables"}
+	}
+	if cfg.Profile != "" {
+		return Result{Name: CheckConfig, Status: StatusOK, Message: fmt.Sprintf("loaded %s (profile %s)", cfg.ConfigFile, cfg.Profile)}
+	}
+	return Result{Name: CheckConfig, Status: StatusOK, Message: "loaded " + cfg.ConfigFile}
+}
+
+func checkService(ctx context.Context, checker *health.Checker, name, endpoint string, failure Status) Result {
+	h := checker.CheckService(ctx, name, endpoint)
+	if h.Healthy {
+		return Result{Name: name, Status: StatusOK, Message: "reachable at " + endpoint}
+	}
+	remediation := fmt.Sprintf("Check that %s is running and reachable from this machine, or point the CLI at the right endpoint with --user-org-endpoint / the config file.", name)
+	if failure == StatusWarn {
+		remediation = fmt.Sprintf("Only needed by commands that use %s (e.g. export); check the endpoint if you rely on them.", name)
+	}
+	return Result{Name: name, Status: failure, Message: fmt.Sprintf("%s: %v", h.URL, h.Error), Remediation: remediation}
+}
+
+// checkCredentials identifies the configured secret as an API key or an
+// access token and reports how long it remains valid.
+func checkCredentials(ctx context.Context, opts Options) (*credential, Result) {
+	cfg := opts.Config
+	if cfg.APIKey == "" {
+		return nil, Result{
+			Name:        CheckCredentials,
+			Status:      StatusFail,
+			Message:     "no credential configured",
+			Remediation: "Set auth.api-key in the config file, export ADMIN_CLI_AUTH_API_KEY, or pass --api-key.",
+		}
+	}
+
+	cred, err := lookupAPIKey(ctx, opts)
+	if err == nil && cred == nil {
+		cred, err = lookupAccessToken(ctx, opts)
+	}
+	if err != nil {
+		return nil, Result{
+			Name:        CheckCredentials,
+			Status:      StatusFail,
+			Message:     err.Error(),
+			Remediation: "Retry once user-org-service is healthy; run with --verbose on other commands to see the failing request.",
+		}
+	}
+	if cred == nil {
+		return nil, Result{
+			Name:        CheckCredentials,
+			Status:      StatusFail,
+			Message:     "credential was rejected (expired, revoked or unknown)",
+			Remediation: "Obtain a new access token or API key and update auth.api-key; if it should be valid, check the system clock.",
+		}
+	}
+
+	now := opts.Now()
+	switch {
+	case cred.expiresAt.IsZero():
+		return cred, Result{Name: CheckCredentials, Status: StatusOK, Message: cred.kind + " is valid and does not expire"}
+	case !now.Before(cred.expiresAt):
+		return cred, Result{
+			Name:        CheckCredentials,
+			Status:      StatusFail,
+			Message:     fmt.Sprintf("%s expired at %s", cred.kind, cred.expiresAt.UTC().Format(time.RFC3339)),
+			Remediation: "Obtain a new credential and update auth.api-key.",
+		}
+	case cred.expiresAt.Sub(now) < opts.ExpiryWarning:
+		remediation := "Obtain a new access token before it expires."
+		if cred.kind == "api key" {
+			remediation = "Rotate it with `admin-cli credentials rotate` and update auth.api-key."
+		}
+		return cred, Result{
+			Name:        CheckCredentials,
+			Status:      StatusWarn,
+			Message:     fmt.Sprintf("%s expires in %s", cred.kind, cred.expiresAt.Sub(now).Round(time.Minute)),
+			Remediation: remediation,
+		}
+	default:
+		return cred, Result{
+			Name:    CheckCredentials,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%s is valid until %s", cred.kind, cred.expiresAt.UTC().Format(time.RFC3339)),
+		}
+	}
+}
+
+// lookupAPIKey returns nil without an error when the secret is not a valid API key.
+func lookupAPIKey(ctx context.Context, opts Options) (*credential, error) {
+	body, _ := json.Marshal(map[string]string{"apiKeySecret": opts.Config.APIKey})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.Config.UserOrgEndpoint+"/v1/auth/validate-api-key", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("validate API key: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+
+	var result struct {
+		Valid     bool     `json:"valid"`
+		Scopes    []string `json:"scopes"`
+		ExpiresAt *string  `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode validate-api-key response: %w", err)
+	}
+	if !result.Valid {
+		return nil, nil
+	}
+	cred := &credential{kind: "api key", scopes: result.Scopes}
+	if result.ExpiresAt != nil {
+		if t, err := time.Parse(time.RFC3339, *result.ExpiresAt); err == nil {
+			cred.expiresAt = t
+		}
+	}
+	return cred, nil
+}
+
+// lookupAccessToken returns nil without an error when userinfo rejects the token.
+func lookupAccessToken(ctx context.Context, opts Options) (*credential, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.Config.UserOrgEndpoint+"/v1/auth/userinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.Config.APIKey)
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("userinfo returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode userinfo response: %w", err)
+	}
+	return &credential{kind: "access token", scopes: result.Scopes, expiresAt: jwtExpiry(opts.Config.APIKey)}, nil
+}
+
+// jwtExpiry reads the exp claim of a JWT access token without verifying it
+// (the server already did). Opaque tokens return the zero time.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+func checkScopes(cred *credential, required []string) Result {
+	granted := make(map[string]bool, len(cred.scopes))
+	for _, s := range cred.scopes {
+		granted[s] = true
+	}
+	var missing []string
+	for _, s := range required {
+		if !granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		return Result{
+			Name:        CheckScopes,
+			Status:      StatusWarn,
+			Message:     fmt.Sprintf("%s lacks %s (granted: %s)", cred.kind, strings.Join(missing, ", "), strings.Join(cred.scopes, ", ")),
+			Remediation: "Commands that need these scopes will fail with 403; request a credential that includes them.",
+		}
+	}
+	return Result{Name: CheckScopes, Status: StatusOK, Message: "granted: " + strings.Join(cred.scopes, ", ")}
+}
+
+// checkVersion compares the CLI build with the user-org-service build
+// reported by /v1/admin/diagnostics.
+func checkVersion(ctx context.Context, opts Options) Result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.Config.UserOrgEndpoint+"/v1/admin/diagnostics", nil)
+	if err != nil {
+		return Result{Name: CheckVersion, Status: StatusSkip, Message: err.Error()}
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.Config.APIKey)
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return Result{Name: CheckVersion, Status: StatusSkip, Message: "skipped: " + err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{
+			Name:    CheckVersion,
+			Status:  StatusSkip,
+			Message: fmt.Sprintf("skipped: diagnostics returned status %d (requires an access token with the admin scope)", resp.StatusCode),
+		}
+	}
+
+	var diag struct {
+		Build struct {
+			Version     string `json:"version"`
+			VCSRevision string `json:"vcs_revision"`
+		} `json:"build"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&diag); err != nil {
+		return Result{Name: CheckVersion, Status: StatusSkip, Message: "skipped: decode diagnostics: " + err.Error()}
+	}
+	service := diag.Build.VCSRevision
+	if service == "" || !knownRevision(opts.CLICommit) {
+		return Result{
+			Name:    CheckVersion,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("cannot compare builds (cli %s, service %s)", describe(opts.CLIVersion, opts.CLICommit), describe(diag.Build.Version, service)),
+		}
+	}
+	if !sameRevision(opts.CLICommit, service) {
+		return Result{
+			Name:        CheckVersion,
+			Status:      StatusWarn,
+			Message:     fmt.Sprintf("cli built from %s, user-org-service from %s", shortRevision(opts.CLICommit), shortRevision(service)),
+			Remediation: "Install the admin-cli release that matches the deployed services to avoid request/response mismatches.",
+		}
+	}
+	return Result{Name: CheckVersion, Status: StatusOK, Message: "cli and user-org-service built from " + shortRevision(service)}
+}
+
+func knownRevision(rev string) bool {
+	return rev != "" && rev != "unknown"
+}
+
+// sameRevision treats an abbreviated commit as matching its full hash.
+func sameRevision(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasPrefix(b, a)
+}
+
+func shortRevision(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+func describe(version, revision string) string {
+	switch {
+	case version == "" && !knownRevision(revision):
+		return "unknown"
+	case !knownRevision(revision):
+		return version
+	case version == "":
+		return shortRevision(revision)
+	default:
+		return fmt.Sprintf("%s@%s", version, shortRevision(revision))
+	}
+}
@@ -0,0 +1,172 @@
+// Package doctor provides tests for environment diagnosis.
+package doctor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/admin-cli/internal/config"
+)
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+// fakeUserOrg serves the endpoints doctor calls. apiKeys maps secrets to
+// validate-api-key responses; tokens maps bearer tokens to granted scopes.
+func fakeUserOrg(t *testing.T, apiKeys map[string]map[string]any, tokens map[string][]string, revision string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/analytics/v1/status/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/v1/auth/validate-api-key", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			APIKeySecret string `json:"apiKeySecret"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp, ok := apiKeys[req.APIKeySecret]
+		if !ok {
+			resp = map[string]any{"valid": false, "message": "API key not found"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	bearer := func(r *http.Request) ([]string, bool) {
+		scopes, ok := tokens[r.Header.Get("Authorization")[len("Bearer "):]]
+		return scopes, ok
+	}
+	mux.HandleFunc("/v1/auth/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		scopes, ok := bearer(r)
+		if !ok {
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "user-1", "scopes": scopes})
+	})
+	mux.HandleFunc("/v1/admin/diagnostics", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := bearer(r); !ok {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"build": map[string]any{"vcs_revision": revision}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testConfig(endpoint, secret string) *config.Config {
+	return &config.Config{
+		UserOrgEndpoint:   endpoint,
+		AnalyticsEndpoint: endpoint,
+		APIKey:            secret,
+		OutputFormat:      "table",
+	}
+}
+
+func jwtWithExpiry(exp time.Time) string {
+	payload, _ := json.Marshal(map[string]any{"sub": "user-1", "exp": exp.Unix()})
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func statuses(report Report) map[string]Status {
+	out := make(map[string]Status, len(report.Checks))
+	for _, check := range report.Checks {
+		out[check.Name] = check.Status
+	}
+	return out
+}
+
+func TestRunHealthyAccessToken(t *testing.T) {
+	token := jwtWithExpiry(now.Add(30 * 24 * time.Hour))
+	server := fakeUserOrg(t, nil, map[string][]string{token: {"openid", "admin"}}, "abcdef1234567890")
+
+	report := Run(context.Background(), Options{
+		Config:    testConfig(server.URL, token),
+		CLICommit: "abcdef1",
+		Now:       func() time.Time { return now },
+	})
+
+	assert.True(t, report.OK)
+	for name, status := range statuses(report) {
+		assert.Equal(t, StatusOK, status, name)
+	}
+}
+
+func TestRunReportsStaleAPIKeyAndMissingScope(t *testing.T) {
+	expires := now.Add(48 * time.Hour).Format(time.RFC3339)
+	server := fakeUserOrg(t, map[string]map[string]any{
+		"sk_live_key": {"valid": true, "scopes": []string{"inference:invoke"}, "expiresAt": expires},
+	}, nil, "")
+
+	report := Run(context.Background(), Options{
+		Config: testConfig(server.URL, "sk_live_key"),
+		Now:    func() time.Time { return now },
+	})
+
+	got := statuses(report)
+	assert.True(t, report.OK, "warnings must not fail the report")
+	assert.Equal(t, StatusWarn, got[CheckCredentials])
+	assert.Equal(t, StatusWarn, got[CheckScopes])
+	// An API key cannot read diagnostics, so the version comparison is skipped
+	assert.Equal(t, StatusSkip, got[CheckVersion])
+	for _, check := range report.Checks {
+		if check.Status == StatusWarn {
+			assert.NotEmpty(t, check.Remediation, check.Name)
+		}
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	server := fakeUserOrg(t, nil, map[string][]string{"token": {"admin"}}, "1111111")
+
+	report := Run(context.Background(), Options{Config: testConfig(server.URL, "revoked")})
+	got := statuses(report)
+	assert.False(t, report.OK)
+	assert.Equal(t, StatusFail, got[CheckCredentials])
+	assert.Equal(t, StatusSkip, got[CheckScopes])
+
+	report = Run(context.Background(), Options{Config: testConfig(server.URL, "token"), CLICommit: "2222222"})
+	assert.Equal(t, StatusWarn, statuses(report)[CheckVersion], "differing builds are reported as skew")
+
+	expired := jwtWithExpiry(now.Add(-time.Hour))
+	server = fakeUserOrg(t, nil, map[string][]string{expired: {"admin"}}, "")
+	report = Run(context.Background(), Options{Config: testConfig(server.URL, expired), Now: func() time.Time { return now }})
+	assert.Equal(t, StatusFail, statuses(report)[CheckCredentials])
+
+	cfg := testConfig("localhost:8081", "token")
+	cfg.OutputFormat = "xml"
+	report = Run(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusFail, statuses(report)[CheckConfig])
+	assert.Contains(t, report.Checks[0].Message, "not an http(s) URL")
+	assert.Contains(t, report.Checks[0].Message, "xml")
+
+	report = Run(context.Background(), Options{ConfigErr: errors.New("failed to read config file")})
+	assert.False(t, report.OK)
+	assert.Len(t, report.Checks, 6)
+	assert.Equal(t, StatusSkip, statuses(report)[CheckUserOrg])
+}
+
+func TestRunUnreachableService(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	report := Run(context.Background(), Options{
+		Config:     testConfig(url, "token"),
+		HTTPClient: &http.Client{Timeout: time.Second},
+	})
+	got := statuses(report)
+	assert.False(t, report.OK)
+	assert.Equal(t, StatusFail, got[CheckUserOrg])
+	assert.Equal(t, StatusWarn, got[CheckAnalytics])
+	assert.Equal(t, StatusSkip, got[CheckCredentials])
+	assert.Contains(t, report.Checks[1].Remediation, fmt.Sprintf("%s is running", CheckUserOrg))
+}
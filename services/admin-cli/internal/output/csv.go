@@ -14,6 +14,7 @@ package output
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
@@ -83,3 +84,18 @@ func (c *CSVFormatter) Close() error {
 	return c.file.Close()
 }
 
+
+// WriteCSV writes rows as CSV to w, preceded by headers unless noHeaders is set.
+// Used by list commands for --format csv.
+func WriteCSV(w io.Writer, headers []string, rows [][]string, noHeaders bool) error {
+	writer := csv.NewWriter(w)
+	if !noHeaders {
+		if err := writer.Write(headers); err != nil {
+			return err
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -74,3 +74,14 @@ func PrintTable(headers []string, rows [][]string) error {
 	return formatter.Flush()
 }
 
+
+// PrintTableRows prints a table to stdout without the header row (--no-headers).
+func PrintTableRows(rows [][]string) error {
+	formatter := NewTableFormatter(os.Stdout)
+	for _, row := range rows {
+		if err := formatter.WriteRow(row...); err != nil {
+			return err
+		}
+	}
+	return formatter.Flush()
+}
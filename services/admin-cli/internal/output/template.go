@@ -0,0 +1,283 @@
+// Package output provides field selection for list and get commands.
+//
+// Purpose:
+//
+//	Let operators extract exactly the fields they need for scripting without
+//	piping through jq: --template renders a Go template and --jsonpath applies
+//	a jq-style selector. Both operate on the command's JSON representation,
+//	so field names match the --format json output.
+//
+// Requirements Reference:
+//   - specs/009-admin-cli/spec.md#FR-006 (structured output for scripting)
+//
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Selection holds the --template, --jsonpath and --no-headers options.
+type Selection struct {
+	Template  string
+	JSONPath  string
+	NoHeaders bool
+}
+
+// Active reports whether a template or selector replaces the normal output.
+func (s Selection) Active() bool {
+	return s.Template != "" || s.JSONPath != ""
+}
+
+// Validate checks the options before any request is made.
+func (s Selection) Validate() error {
+	if s.Template != "" && s.JSONPath != "" {
+		return fmt.Errorf("--template and --jsonpath are mutually exclusive")
+	}
+	if s.Template != "" {
+		if _, err := parseTemplate(s.Template); err != nil {
+			return err
+		}
+	}
+	if s.JSONPath != "" {
+		if _, err := parseSelector(s.JSONPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render writes data through the template or selector. Lists are rendered
+// one item per line: the template runs once per element, and every value
+// the selector yields is printed on its own line (strings unquoted, objects
+// and arrays as compact JSON).
+func (s Selection) Render(w io.Writer, data interface{}) error {
+	value, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+
+	if s.Template != "" {
+		tmpl, err := parseTemplate(s.Template)
+		if err != nil {
+			return err
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			if err := tmpl.Execute(w, item); err != nil {
+				return fmt.Errorf("execute template: %w", err)
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	steps, err := parseSelector(s.JSONPath)
+	if err != nil {
+		return err
+	}
+	results, err := selectValues(value, steps)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		line, err := formatValue(result)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// toJSONValue round-trips data through JSON so templates and selectors see
+// the same field names as --format json.
+func toJSONValue(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encode output: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+	return value, nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=zero").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+		"join": func(sep string, v interface{}) string {
+			items, _ := v.([]interface{})
+			parts := make([]string, 0, len(items))
+			for _, item := range items {
+				part, _ := formatValue(item)
+				parts = append(parts, part)
+			}
+			return strings.Join(parts, sep)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// selectorStep is one segment of a selector: a field name, an array index,
+// or (iterate) every element of an array or object.
+type selectorStep struct {
+	field   string
+	index   int
+	isIndex bool
+	iterate bool
+}
+
+// parseSelector parses jq-style paths such as .name, .[0].orgId and
+// .[].scopes[]. kubectl-style input ({.items[*].name}, $.name) is accepted too.
+func parseSelector(expr string) ([]selectorStep, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.TrimPrefix(s, "$")
+	if s == "" || (s[0] != '.' && s[0] != '[') {
+		return nil, fmt.Errorf("invalid --jsonpath %q: must start with '.'", expr)
+	}
+
+	var steps []selectorStep
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			i++
+			end := i
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			if end > i {
+				steps = append(steps, selectorStep{field: s[i:end]})
+			}
+			i = end
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid --jsonpath %q: unclosed '['", expr)
+			}
+			inner := strings.TrimSpace(s[i+1 : i+end])
+			switch {
+			case inner == "" || inner == "*":
+				steps = append(steps, selectorStep{iterate: true})
+			case strings.HasPrefix(inner, `"`):
+				field, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --jsonpath %q: %w", expr, err)
+				}
+				steps = append(steps, selectorStep{field: field})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --jsonpath %q: index %q is not a number", expr, inner)
+				}
+				steps = append(steps, selectorStep{index: n, isIndex: true})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("invalid --jsonpath %q: unexpected %q", expr, s[i])
+		}
+	}
+	return steps, nil
+}
+
+// selectValues applies steps to value. Missing fields yield null, as in jq;
+// indexing a non-array is an error.
+func selectValues(value interface{}, steps []selectorStep) ([]interface{}, error) {
+	current := []interface{}{value}
+	for _, step := range steps {
+		var next []interface{}
+		for _, v := range current {
+			switch {
+			case step.iterate:
+				switch t := v.(type) {
+				case []interface{}:
+					next = append(next, t...)
+				case map[string]interface{}:
+					// Sorted by key: JSON objects carry no order once decoded
+					keys := make([]string, 0, len(t))
+					for k := range t {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, t[k])
+					}
+				default:
+					return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+				}
+			case step.isIndex:
+				arr, ok := v.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot index %s with %d", typeName(v), step.index)
+				}
+				idx := step.index
+				if idx < 0 {
+					idx += len(arr)
+				}
+				if idx < 0 || idx >= len(arr) {
+					next = append(next, nil)
+				} else {
+					next = append(next, arr[idx])
+				}
+			default:
+				switch t := v.(type) {
+				case map[string]interface{}:
+					next = append(next, t[step.field])
+				case nil:
+					next = append(next, nil)
+				default:
+					return nil, fmt.Errorf("cannot select field %q of %s", step.field, typeName(v))
+				}
+			}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// formatValue prints strings raw (like jq -r) and everything else as JSON.
+func formatValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
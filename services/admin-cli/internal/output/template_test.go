@@ -0,0 +1,104 @@
+// Package output provides tests for --template and --jsonpath selection.
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testOrg struct {
+	OrgID  string   `json:"orgId"`
+	Slug   string   `json:"slug"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+var testOrgs = []testOrg{
+	{OrgID: "org-1", Slug: "acme", Scopes: []string{"read", "write"}},
+	{OrgID: "org-2", Slug: "globex"},
+}
+
+func render(t *testing.T, sel Selection, data interface{}) string {
+	t.Helper()
+	if err := sel.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := sel.Render(&buf, data); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestSelectionTemplate(t *testing.T) {
+	got := render(t, Selection{Template: `{{.orgId}}={{.slug}} {{join "," .scopes}}`}, testOrgs)
+	want := "org-1=acme read,write\norg-2=globex \n"
+	if got != want {
+		t.Errorf("template output = %q, want %q", got, want)
+	}
+
+	// A single object renders once
+	got = render(t, Selection{Template: `{{json .scopes}}`}, testOrgs[0])
+	if got != "[\"read\",\"write\"]\n" {
+		t.Errorf("template output = %q", got)
+	}
+}
+
+func TestSelectionJSONPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{".[].orgId", "org-1\norg-2\n"},
+		{".[0].scopes", "[\"read\",\"write\"]\n"},
+		{".[0].scopes[]", "read\nwrite\n"},
+		{".[-1].slug", "globex\n"},
+		{"{.[*].slug}", "acme\nglobex\n"},
+		{`$[1]["orgId"]`, "org-2\n"},
+		{".[1].missing", "null\n"},
+	}
+	for _, tc := range cases {
+		if got := render(t, Selection{JSONPath: tc.path}, testOrgs); got != tc.want {
+			t.Errorf("jsonpath %s = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestSelectionErrors(t *testing.T) {
+	invalid := []Selection{
+		{Template: "{{.orgId", JSONPath: ""},
+		{JSONPath: "orgId"},
+		{JSONPath: ".[abc]"},
+		{JSONPath: ".[0"},
+		{Template: "{{.orgId}}", JSONPath: ".orgId"},
+	}
+	for _, sel := range invalid {
+		if err := sel.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", sel)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := (Selection{JSONPath: ".[0].slug[0]"}).Render(&buf, testOrgs); err == nil {
+		t.Error("expected indexing a string to fail")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := [][]string{{"org-1", "Acme, Inc."}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []string{"orgId", "name"}, rows, false); err != nil {
+		t.Fatalf("WriteCSV() failed: %v", err)
+	}
+	if got := buf.String(); got != "orgId,name\norg-1,\"Acme, Inc.\"\n" {
+		t.Errorf("csv output = %q", got)
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, []string{"orgId", "name"}, rows, true); err != nil {
+		t.Fatalf("WriteCSV() failed: %v", err)
+	}
+	if got := buf.String(); got != "org-1,\"Acme, Inc.\"\n" {
+		t.Errorf("csv output without headers = %q", got)
+	}
+}
@@ -12,6 +12,7 @@
 //   - internal/aggregation: Rollup workers and freshness tracking
 //   - internal/exports: CSV export generation and S3 delivery
 //   - internal/freshness: Redis-backed freshness cache
+//   - internal/querycache: Redis-backed usage query result cache
 //
 // Key Responsibilities:
 //   - Load configuration and initialize runtime dependencies
@@ -29,33 +30,61 @@
 // Debugging Notes:
 //   - Server starts on configured HTTP port (default 8084)
 //   - Readiness probe checks Postgres, Redis, and RabbitMQ connectivity
-//   - Graceful shutdown allows in-flight requests to complete (10s timeout)
+//   - Graceful shutdown drains for SHUTDOWN_DRAIN_DELAY, then allows in-flight
+//     requests to complete (10s timeout)
+//   - /debug/pprof is exposed only when DEBUG_ENDPOINTS_ENABLED=true
+//   - Database and Redis credentials rotate without a restart when CREDENTIALS_DIR
+//     or VAULT_ADDR is set: reloaded on SIGHUP, file changes and
+//     CREDENTIALS_RELOAD_INTERVAL (RabbitMQ credentials still come from RABBITMQ_URL)
+//   - With PLATFORM_TOKEN_CLIENT_ID set, callers authenticate with
+//     user-org-service access tokens and their org roles map to analytics
+//     permissions (internal/middleware/tokens.go)
+//   - --validate-config checks configuration and dependency connectivity, prints
+//     a JSON report and exits non-zero on any failure (see preflight.go)
 //
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/apiversion"
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/dataaccess/pgreplica"
+	"github.com/ai-aas/shared-go/requestid"
+	"github.com/ai-aas/shared-go/secrets"
+	"github.com/ai-aas/shared-go/usagerecord"
+	sharedserver "github.com/ai-aas/shared-go/server"
+
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/aggregation"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/api"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/keyusage"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/ingestion"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/observability"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/querycache"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
 func main() {
+	validate := flag.Bool("validate-config", false, "validate configuration and dependency connectivity, print a JSON report and exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(validateConfig())
+	}
+
 	ctx := context.Background()
 
 	// Load configuration
@@ -83,18 +112,63 @@ func main() {
 
 	logger := obs.Logger
 
+	// Load rotatable database and Redis credentials (CREDENTIALS_DIR or Vault);
+	// nil when they come only from DATABASE_URL and REDIS_URL
+	var credentials *secrets.Reloader
+	if source := cfg.CredentialSource(); source != nil {
+		var err error
+		credentials, err = secrets.NewReloader(ctx, source, logger)
+		if err != nil {
+			logger.Fatal("failed to load credentials", zap.Error(err))
+		}
+		go credentials.Watch(ctx, cfg.CredentialsReloadInterval)
+		logger.Info("credential rotation enabled", zap.Duration("reload_interval", cfg.CredentialsReloadInterval))
+	}
+
+	// Database credentials are read for every new connection; a rotation
+	// resets the pools so idle connections reconnect with the new password
+	poolCfg := cfg.PoolConfig()
+	poolCfg.Credentials = func() (string, string) {
+		return credentials.Lookup(secrets.DatabaseUser, ""), credentials.Lookup(secrets.DatabasePassword, "")
+	}
+
 	// Initialize database store
-	store, err := postgres.NewStore(ctx, cfg.DatabaseURL)
+	store, err := postgres.NewStore(ctx, cfg.DatabaseURL, poolCfg)
 	if err != nil {
 		logger.Fatal("failed to initialize database", zap.Error(err))
 	}
 	defer store.Close()
+	store.SetCopyThreshold(cfg.IngestionCopyThreshold)
+
+	// Route dashboard queries to read replicas when configured
+	if len(cfg.DatabaseReplicaURLs) > 0 {
+		replicas, err := pgreplica.New(ctx, store.Pool(), cfg.DatabaseReplicaURLs, pgreplica.Options{
+			Store:  "analytics",
+			MaxLag: cfg.DatabaseReplicaMaxLag,
+			Pool:   poolCfg,
+			Logger: logger,
+		})
+		if err != nil {
+			logger.Fatal("failed to initialize read replicas", zap.Error(err))
+		}
+		replicas.Start(ctx)
+		store.UseReplicas(replicas)
+		logger.Info("read replicas configured", zap.Int("count", len(cfg.DatabaseReplicaURLs)))
+	}
+	credentials.OnChange([]string{secrets.DatabaseUser, secrets.DatabasePassword}, func(context.Context) {
+		store.ResetConnections()
+		logger.Info("database pools reset after credential rotation")
+	})
 
 	// Initialize Redis client for freshness cache
 	redisOpts, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
 		logger.Fatal("failed to parse Redis URL", zap.Error(err))
 	}
+	redisUsername, redisPassword := redisOpts.Username, redisOpts.Password
+	redisOpts.CredentialsProvider = func() (string, string) {
+		return credentials.Lookup(secrets.RedisUsername, redisUsername), credentials.Lookup(secrets.RedisPassword, redisPassword)
+	}
 	redisClient := redis.NewClient(redisOpts)
 	defer redisClient.Close()
 
@@ -105,17 +179,62 @@ func main() {
 		logger.Fatal("failed to connect to Redis", zap.Error(err))
 	}
 
+	// Lifecycle server: probes, drain-aware shutdown, /metrics and optional pprof
+	srv := sharedserver.New(sharedserver.Options{
+		Addr:            fmt.Sprintf(":%d", cfg.HTTPPort),
+		Logger:          logger,
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+		DrainDelay:      cfg.ShutdownDrainDelay,
+		EnableMetrics:   true,
+		EnableDebug:     cfg.DebugEndpointsEnabled,
+		PreStopAddr:     cfg.PreStopAddr,
+	})
+
+	deprecations, err := apiversion.ParseDeprecations(cfg.APIDeprecations)
+	if err != nil {
+		logger.Fatal("invalid API_DEPRECATIONS", zap.Error(err))
+	}
+
+	// Platform access tokens: roles come from user-org-service tokens
+	// instead of X-Actor-* headers
+	var tokenVerifier *tokens.Verifier
+	if cfg.PlatformTokenClientID != "" {
+		tokenVerifier = tokens.NewVerifier(tokens.VerifierConfig{
+			Introspector: &tokens.HTTPIntrospector{
+				URL:          strings.TrimSuffix(cfg.UserOrgServiceURL, "/") + "/v1/auth/introspect",
+				ClientID:     cfg.PlatformTokenClientID,
+				ClientSecret: cfg.PlatformTokenClientSecret,
+				Client:       &http.Client{Timeout: cfg.UserOrgServiceTimeout, Transport: &requestid.Transport{}},
+			},
+			Validator: tokens.Validator{
+				Issuer:   cfg.PlatformTokenIssuer,
+				Audience: cfg.PlatformTokenAudience,
+				Skew:     cfg.PlatformTokenClockSkew,
+			},
+			CacheTTL: cfg.PlatformTokenCacheTTL,
+		})
+		logger.Info("platform access tokens enabled", zap.String("client_id", cfg.PlatformTokenClientID))
+	}
+
 	// Create HTTP server
 	// RBAC is enabled by default, can be disabled via ENABLE_RBAC=false for development
 	apiServer := api.NewServer(api.Config{
-		Port:         cfg.HTTPPort,
-		Logger:       logger,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-		EnableRBAC:   cfg.EnableRBAC,
-		Store:        store,
-		RedisClient:  redisClient,
+		Port:          cfg.HTTPPort,
+		Logger:        logger,
+		ReadTimeout:   15 * time.Second,
+		WriteTimeout:  15 * time.Second,
+		IdleTimeout:   60 * time.Second,
+		EnableRBAC:    cfg.EnableRBAC,
+		TokenVerifier: tokenVerifier,
+		AdminScope:    cfg.PlatformAdminScope,
+		Store:         store,
+		RedisClient:   redisClient,
+		ReadinessGate: srv.ReadinessGate,
+		ServiceName:   cfg.ServiceName,
+		Deprecations:  deprecations,
 	})
 
 	// Initialize freshness cache
@@ -125,14 +244,37 @@ func main() {
 		TTL:    cfg.FreshnessCacheTTL,
 	})
 
+	// Initialize query result cache (invalidated by the rollup worker)
+	var queryCache *querycache.Cache
+	if cfg.QueryCacheEnabled {
+		queryCache = querycache.NewCache(querycache.Config{
+			Client:    redisClient,
+			Logger:    logger,
+			TTL:       cfg.QueryCacheTTL,
+			RecentTTL: cfg.QueryCacheRecentTTL,
+		})
+	}
+
 	// Register usage API routes
-	usageHandler := api.NewUsageHandler(store, logger, freshnessCache)
+	usageHandler := api.NewUsageHandler(store, logger, freshnessCache, queryCache, cfg.ExportDefaultProfile)
 	apiServer.RegisterUsageRoutes(usageHandler)
 
 	// Register reliability API routes
-	reliabilityHandler := api.NewReliabilityHandler(store, logger)
+	reliabilityHandler := api.NewReliabilityHandler(store, logger, freshnessCache)
 	apiServer.RegisterReliabilityRoutes(reliabilityHandler)
 
+	// Register top-N breakdown routes
+	topHandler := api.NewTopHandler(store, logger, freshnessCache, cfg.TopNRawMaxWindow, cfg.ExportDefaultProfile)
+	apiServer.RegisterTopRoutes(topHandler)
+
+	// Register spend forecast routes
+	spendHandler := api.NewSpendHandler(store, logger, freshnessCache, cfg.ExportDefaultProfile)
+	apiServer.RegisterSpendRoutes(spendHandler)
+
+	// Register freshness API routes
+	freshnessHandler := api.NewFreshnessHandler(store, freshnessCache, logger)
+	apiServer.RegisterFreshnessRoutes(freshnessHandler)
+
 	// Initialize Linode Object Storage delivery adapter (if configured)
 	var s3Delivery *exports.S3Delivery
 	if cfg.S3Endpoint != "" && cfg.S3AccessKey != "" && cfg.S3SecretKey != "" {
@@ -157,16 +299,10 @@ func main() {
 	}
 
 	// Register exports API routes
-	exportsHandler := api.NewExportsHandler(store.Pool(), logger)
+	exportsHandler := api.NewExportsHandler(store.Pool(), s3Delivery, logger, cfg.ExportDefaultProfile)
 	apiServer.RegisterExportsRoutes(exportsHandler)
 
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.HTTPPort),
-		Handler:      apiServer,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	srv.SetHandler(apiServer)
 
 	// Start server in goroutine
 	serverErrors := make(chan error, 1)
@@ -180,12 +316,16 @@ func main() {
 	}()
 
 	// Start rollup worker
-	rollupWorker := aggregation.NewWorker(aggregation.Config{
+	rollupCfg := aggregation.Config{
 		Store:    store,
 		Logger:   logger,
 		Interval: cfg.RollupInterval,
 		Workers:  cfg.AggregationWorkers,
-	})
+	}
+	if queryCache != nil {
+		rollupCfg.Invalidator = queryCache
+	}
+	rollupWorker := aggregation.NewWorker(rollupCfg)
 
 	go func() {
 		if err := rollupWorker.Start(ctx); err != nil {
@@ -203,6 +343,9 @@ func main() {
 			Logger:     logger,
 			Interval:   cfg.ExportWorkerInterval,
 			Workers:    cfg.ExportWorkerConcurrency,
+			// Remove files of deleted or cancelled jobs
+			CleanupInterval: cfg.ExportCleanupInterval,
+			PartSize:        cfg.ExportUploadPartSize,
 		})
 
 		go func() {
@@ -225,10 +368,13 @@ func main() {
 		BatchTimeout:   cfg.IngestionBatchTimeout,
 		Logger:         logger,
 		Store:          store,
+		Freshness:      freshnessCache,
+		KeyUsage:       keyusage.NewRecorder(redisClient),
 		RabbitMQHost:   "", // Will be parsed from URL
 		RabbitMQPort:   0,  // Will be parsed from URL
 		RabbitMQUser:   "", // Will be parsed from URL
 		RabbitMQPass:   "", // Will be parsed from URL
+		ReadMode:       usagerecord.ReadMode(cfg.IngestionReadMode),
 	})
 	if err != nil {
 		logger.Warn("failed to create ingestion consumer", zap.Error(err))
@@ -260,13 +406,13 @@ func main() {
 	case sig := <-shutdown:
 		logger.Info("shutdown signal received", zap.String("signal", sig.String()))
 
-		// Graceful shutdown
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		// Graceful shutdown (fails readiness and drains before stopping)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+10*time.Second)
 		defer cancel()
 
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			logger.Error("graceful shutdown failed", zap.Error(err))
-			if err := srv.Close(); err != nil {
+			if err := srv.HTTPServer().Close(); err != nil {
 				logger.Error("force close failed", zap.Error(err))
 			}
 		}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ai-aas/shared-go/preflight"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/config"
+)
+
+// validateConfig implements --validate-config: it loads and validates the
+// configuration, probes every declared dependency in parallel, prints a JSON
+// report to stdout and returns the process exit code.
+func validateConfig() int {
+	service := "analytics-service"
+	cfg, err := config.Load()
+	var checks []preflight.Check
+	if err == nil {
+		service = cfg.ServiceName
+		checks = dependencyChecks(cfg)
+	}
+
+	report := preflight.Run(context.Background(), service, err, checks, 0)
+	if writeErr := report.Write(os.Stdout); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", writeErr)
+	}
+	return report.ExitCode()
+}
+
+// dependencyChecks lists the stores and brokers the service needs at startup.
+func dependencyChecks(cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{Name: "postgres", Run: preflight.Postgres(cfg.DatabaseURL)},
+		{Name: "redis", Run: preflight.URLHost(cfg.RedisURL)},
+		{Name: "rabbitmq", Run: preflight.URLHost(cfg.RabbitMQURL)},
+	}
+	for i, replicaURL := range cfg.DatabaseReplicaURLs {
+		checks = append(checks, preflight.Check{Name: fmt.Sprintf("postgres-replica-%d", i+1), Run: preflight.Postgres(replicaURL)})
+	}
+	if cfg.S3Endpoint != "" && cfg.S3AccessKey != "" && cfg.S3SecretKey != "" {
+		endpoint := cfg.S3Endpoint
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint // Linode endpoints are configured as bare hosts
+		}
+		checks = append(checks, preflight.Check{Name: "s3", Run: preflight.URLHost(endpoint)})
+	}
+	return checks
+}
@@ -0,0 +1,208 @@
+// Command seed-usage fills the analytics store with historical demo usage for
+// orgs created by the user-org seeder.
+//
+// Purpose:
+//
+//	Reads the JSON written by user-org-service's seed-test-users (-json) and
+//	generates synthetic usage for every seeded org that has API keys, with
+//	each event attributed to one of that org's keys. Events are inserted
+//	directly through the ingestion processor and rollups are rebuilt for the
+//	covered days, so dashboards are populated as soon as the command exits.
+//	scripts/seed-demo.sh runs both seeders in order.
+//
+// Usage:
+//
+//	seed-test-users -profile analytics-demo -json fixtures.json
+//	seed-usage -fixtures fixtures.json -since 720h
+//
+// Debugging Notes:
+//   - Requires DATABASE_URL; rollup rebuilds invalidate cached usage queries
+//     through REDIS_URL when QUERY_CACHE_ENABLED is set, as backfill does
+//   - The same -seed, fixtures and window produce the same event IDs, so
+//     re-running is de-duplicated by the usage_events unique constraint; the
+//     window is aligned to whole days so repeated runs on one day match
+//   - Orgs without API keys (e.g. the system org) are skipped
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/aggregation"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/ingestion"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/querycache"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/synthetic"
+)
+
+// seedFixtures is the subset of seed-test-users -json output used here.
+type seedFixtures struct {
+	Profile string `json:"profile"`
+	Orgs    []struct {
+		ID      string `json:"id"`
+		Slug    string `json:"slug"`
+		APIKeys []struct {
+			ID string `json:"id"`
+		} `json:"apiKeys"`
+	} `json:"orgs"`
+}
+
+func main() {
+	var (
+		fixturesPath = flag.String("fixtures", "", "seed-test-users -json output (- for stdin, required)")
+		since        = flag.Duration("since", 30*24*time.Hour, "Generate history covering this long before today")
+		rate         = flag.Float64("rate", 600, "Requests per hour per org at the daily peak")
+		models       = flag.String("models", "gpt-4o:0.3,gpt-4o-mini:0.4,llama-3-70b:0.2,mistral-7b:0.1", "Model mix as name:weight,...")
+		errorRate    = flag.Float64("error-rate", 0.01, "Baseline fraction of failed requests")
+		bursts       = flag.Int("bursts", 3, "Number of error bursts in the history window")
+		seed         = flag.Int64("seed", 1, "Random seed; identical seeds, fixtures and windows produce identical events")
+		batchSize    = flag.Int("batch-size", 1000, "Events per insert batch")
+		skipRollups  = flag.Bool("skip-rollups", false, "Do not rebuild rollups for the generated days")
+	)
+	flag.Parse()
+
+	if *fixturesPath == "" {
+		fmt.Fprintln(os.Stderr, "-fixtures is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	mix, err := synthetic.ParseModelMix(*models)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-models: %v\n", err)
+		os.Exit(2)
+	}
+	if *batchSize <= 0 || *rate < 0 || *errorRate < 0 || *errorRate > 1 || *since < 24*time.Hour {
+		fmt.Fprintln(os.Stderr, "-batch-size must be positive, -rate non-negative, -error-rate within [0,1] and -since at least 24h")
+		os.Exit(2)
+	}
+
+	fixtures, err := readFixtures(*fixturesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-fixtures: %v\n", err)
+		os.Exit(1)
+	}
+	var orgs []string
+	keys := make(map[string][]string)
+	for _, org := range fixtures.Orgs {
+		for _, key := range org.APIKeys {
+			keys[org.ID] = append(keys[org.ID], key.ID)
+		}
+		if len(keys[org.ID]) > 0 {
+			orgs = append(orgs, org.ID)
+		}
+	}
+	if len(orgs) == 0 {
+		fmt.Fprintf(os.Stderr, "no org in %s has API keys; seed with -profile analytics-demo\n", *fixturesPath)
+		os.Exit(1)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync() //nolint:errcheck
+
+	cfg := config.MustLoad()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := postgres.NewStore(ctx, cfg.DatabaseURL, cfg.PoolConfig())
+	if err != nil {
+		logger.Fatal("failed to initialize database", zap.Error(err))
+	}
+	defer store.Close()
+	store.SetCopyThreshold(cfg.IngestionCopyThreshold)
+	processor := ingestion.NewProcessor(store, logger)
+
+	// Whole days keep the event IDs stable across runs on the same day.
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.Add(-since.Truncate(24 * time.Hour))
+	gen := synthetic.NewGenerator(*seed, orgs, mix, *rate, *errorRate, 0.4)
+	gen.APIKeys = keys
+	gen.PlanBursts(from, to, *bursts, 15*time.Minute)
+
+	var (
+		pending []ingestion.Event
+		total   int
+		offset  int64
+	)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := processor.ProcessBatch(ctx, pending, offset); err != nil {
+			logger.Fatal("failed to insert events", zap.Error(err))
+		}
+		offset += int64(len(pending))
+		total += len(pending)
+		pending = pending[:0]
+	}
+	for t := from; t.Before(to); t = t.Add(time.Minute) {
+		if ctx.Err() != nil {
+			logger.Fatal("interrupted", zap.Int("events", total))
+		}
+		pending = append(pending, gen.Minute(t)...)
+		if len(pending) >= *batchSize {
+			flush()
+		}
+	}
+	flush()
+	logger.Info("demo usage inserted", zap.Int("events", total), zap.Int("orgs", len(orgs)), zap.Time("from", from), zap.Time("to", to))
+
+	if !*skipRollups {
+		rollupCfg := aggregation.Config{Store: store, Logger: logger}
+		if cfg.QueryCacheEnabled {
+			if redisOpts, err := redis.ParseURL(cfg.RedisURL); err != nil {
+				logger.Warn("query cache invalidation disabled: invalid REDIS_URL", zap.Error(err))
+			} else {
+				redisClient := redis.NewClient(redisOpts)
+				defer redisClient.Close()
+				rollupCfg.Invalidator = querycache.NewCache(querycache.Config{Client: redisClient, Logger: logger})
+			}
+		}
+		worker := aggregation.NewWorker(rollupCfg)
+		for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+			if err := worker.RebuildWindow(ctx, day, day.Add(24*time.Hour)); err != nil {
+				logger.Fatal("failed to rebuild rollups", zap.Time("day", day), zap.Error(err))
+			}
+		}
+	}
+
+	for _, org := range fixtures.Orgs {
+		if n := len(keys[org.ID]); n > 0 {
+			fmt.Printf("%-20s %s  %d API keys\n", org.Slug, org.ID, n)
+		}
+	}
+	fmt.Printf("Seeded %d usage events from %s to %s\n", total, from.Format(time.DateOnly), to.Format(time.DateOnly))
+}
+
+// readFixtures decodes seed-test-users output from path ("-" for stdin).
+func readFixtures(path string) (*seedFixtures, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var fixtures seedFixtures
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("decode seed output: %w", err)
+	}
+	return &fixtures, nil
+}
@@ -3,6 +3,11 @@
 // Purpose:
 //   This package orchestrates periodic rollup jobs that aggregate usage_events into
 //   hourly and daily rollups, and updates freshness_status for monitoring.
+//   Each bucket also carries a latency histogram (internal/sketch) so latency
+//   percentiles can be served from rollups, and hourly totals per API key and
+//   error code are kept in analytics_hourly_dimension_rollups for top-N queries.
+//   Orgs whose buckets were rewritten are passed to the Invalidator so cached
+//   query results (internal/querycache) are not served past a rollup.
 //
 package aggregation
 
@@ -11,19 +16,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/sketch"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
+// Invalidator drops cached query results for orgs whose rollups changed
+// (implemented by querycache.Cache).
+type Invalidator interface {
+	InvalidateOrgs(ctx context.Context, orgIDs []uuid.UUID) error
+}
+
 // Worker orchestrates rollup jobs.
 type Worker struct {
-	store      *postgres.Store
-	logger     *zap.Logger
-	interval   time.Duration
-	workers    int
-	stopCh     chan struct{}
-	doneCh     chan struct{}
+	store       *postgres.Store
+	logger      *zap.Logger
+	interval    time.Duration
+	workers     int
+	invalidator Invalidator
+	stopCh      chan struct{}
+	doneCh      chan struct{}
 }
 
 // Config holds worker configuration.
@@ -32,17 +46,20 @@ type Config struct {
 	Logger   *zap.Logger
 	Interval time.Duration
 	Workers  int
+	// Invalidator is optional; when nil no query cache is invalidated.
+	Invalidator Invalidator
 }
 
 // NewWorker creates a new rollup worker.
 func NewWorker(cfg Config) *Worker {
 	return &Worker{
-		store:    cfg.Store,
-		logger:   cfg.Logger,
-		interval: cfg.Interval,
-		workers:  cfg.Workers,
-		stopCh:   make(chan struct{}),
-		doneCh:   make(chan struct{}),
+		store:       cfg.Store,
+		logger:      cfg.Logger,
+		interval:    cfg.Interval,
+		workers:     cfg.Workers,
+		invalidator: cfg.Invalidator,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
 	}
 }
 
@@ -106,15 +123,20 @@ func (w *Worker) runRollups(ctx context.Context) error {
 		zap.Time("day_end", dayEnd),
 	)
 
+	affected := make(map[uuid.UUID]bool)
+
 	// Run hourly rollup
-	if err := w.runHourlyRollup(ctx, hourStart, hourEnd); err != nil {
+	if err := w.runHourlyRollup(ctx, hourStart, hourEnd, affected); err != nil {
+		w.invalidate(ctx, affected)
 		return fmt.Errorf("hourly rollup failed: %w", err)
 	}
 
 	// Run daily rollup
-	if err := w.runDailyRollup(ctx, dayStart, dayEnd); err != nil {
+	if err := w.runDailyRollup(ctx, dayStart, dayEnd, affected); err != nil {
+		w.invalidate(ctx, affected)
 		return fmt.Errorf("daily rollup failed: %w", err)
 	}
+	w.invalidate(ctx, affected)
 
 	// Update freshness status
 	if err := w.updateFreshnessStatus(ctx); err != nil {
@@ -125,9 +147,64 @@ func (w *Worker) runRollups(ctx context.Context) error {
 	return nil
 }
 
-// runHourlyRollup executes the hourly rollup transform.
-func (w *Worker) runHourlyRollup(ctx context.Context, start, end time.Time) error {
-	query := `
+// RebuildWindow recomputes hourly and daily rollups for every UTC day that
+// overlaps [start, end), one day at a time so each statement stays small.
+// Rollups are upserts, so rebuilding is safe after late or replayed events.
+func (w *Worker) RebuildWindow(ctx context.Context, start, end time.Time) error {
+	affected := make(map[uuid.UUID]bool)
+	defer w.invalidate(ctx, affected)
+
+	day := start.UTC().Truncate(24 * time.Hour)
+	for ; day.Before(end); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if err := w.runHourlyRollup(ctx, day, next, affected); err != nil {
+			return fmt.Errorf("rebuild hourly rollups for %s: %w", day.Format("2006-01-02"), err)
+		}
+		if err := w.runDailyRollup(ctx, day, next, affected); err != nil {
+			return fmt.Errorf("rebuild daily rollups for %s: %w", day.Format("2006-01-02"), err)
+		}
+		w.logger.Info("rebuilt rollups", zap.Time("day", day))
+	}
+
+	if err := w.updateFreshnessStatus(ctx); err != nil {
+		w.logger.Warn("failed to update freshness status", zap.Error(err))
+	}
+	return nil
+}
+
+// runHourlyRollup executes the hourly rollup transform, adding the orgs whose
+// buckets it wrote to affected.
+func (w *Worker) runHourlyRollup(ctx context.Context, start, end time.Time, affected map[uuid.UUID]bool) error {
+	query := fmt.Sprintf(`
+		WITH totals AS (
+			SELECT
+				date_trunc('hour', occurred_at) AS bucket_start,
+				org_id AS organization_id,
+				model_id,
+				COUNT(*) AS request_count,
+				SUM(input_tokens + output_tokens) AS tokens_total,
+				SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS error_count,
+				SUM(cost_estimate_cents / 100.0) AS cost_total
+			FROM analytics.usage_events
+			WHERE occurred_at >= $1 AND occurred_at < $2
+			GROUP BY 1, 2, 3
+		),
+		latency_buckets AS (
+			SELECT
+				date_trunc('hour', occurred_at) AS bucket_start,
+				org_id AS organization_id,
+				model_id,
+				%s AS idx,
+				COUNT(*) AS n
+			FROM analytics.usage_events
+			WHERE occurred_at >= $1 AND occurred_at < $2 AND latency_ms IS NOT NULL
+			GROUP BY 1, 2, 3, 4
+		),
+		histograms AS (
+			SELECT bucket_start, organization_id, model_id, jsonb_object_agg(idx::TEXT, n) AS latency_histogram
+			FROM latency_buckets
+			GROUP BY 1, 2, 3
+		)
 		INSERT INTO analytics_hourly_rollups (
 			bucket_start,
 			organization_id,
@@ -136,33 +213,41 @@ func (w *Worker) runHourlyRollup(ctx context.Context, start, end time.Time) erro
 			tokens_total,
 			error_count,
 			cost_total,
+			latency_histogram,
 			updated_at
 		)
 		SELECT
-			date_trunc('hour', occurred_at) AS bucket_start,
-			org_id AS organization_id,
-			model_id,
-			COUNT(*) AS request_count,
-			SUM(input_tokens + output_tokens) AS tokens_total,
-			SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS error_count,
-			SUM(cost_estimate_cents / 100.0) AS cost_total,
+			t.bucket_start,
+			t.organization_id,
+			t.model_id,
+			t.request_count,
+			t.tokens_total,
+			t.error_count,
+			t.cost_total,
+			COALESCE(h.latency_histogram, '{}'::JSONB),
 			NOW() AS updated_at
-		FROM analytics.usage_events
-		WHERE occurred_at >= $1 AND occurred_at < $2
-		GROUP BY 1, 2, 3
+		FROM totals t
+		LEFT JOIN histograms h
+			ON h.bucket_start = t.bucket_start
+			AND h.organization_id = t.organization_id
+			AND h.model_id IS NOT DISTINCT FROM t.model_id
 		ON CONFLICT (bucket_start, organization_id, model_id)
 		DO UPDATE SET
-			request_count = EXCLUDED.request_count,
-			tokens_total  = EXCLUDED.tokens_total,
-			error_count   = EXCLUDED.error_count,
-			cost_total    = EXCLUDED.cost_total,
-			updated_at    = NOW()
-	`
-
-	_, err := w.store.Pool().Exec(ctx, query, start, end)
-	if err != nil {
+			request_count     = EXCLUDED.request_count,
+			tokens_total      = EXCLUDED.tokens_total,
+			error_count       = EXCLUDED.error_count,
+			cost_total        = EXCLUDED.cost_total,
+			latency_histogram = EXCLUDED.latency_histogram,
+			updated_at        = NOW()
+		RETURNING organization_id
+	`, sketch.IndexSQL("latency_ms"))
+
+	if err := w.collectOrgs(ctx, query, start, end, affected); err != nil {
 		return fmt.Errorf("execute hourly rollup: %w", err)
 	}
+	if err := w.collectOrgs(ctx, dimensionRollupQuery, start, end, affected); err != nil {
+		return fmt.Errorf("execute hourly dimension rollup: %w", err)
+	}
 
 	w.logger.Debug("hourly rollup completed",
 		zap.Time("start", start),
@@ -172,9 +257,88 @@ func (w *Worker) runHourlyRollup(ctx context.Context, start, end time.Time) erro
 	return nil
 }
 
-// runDailyRollup executes the daily rollup transform.
-func (w *Worker) runDailyRollup(ctx context.Context, start, end time.Time) error {
-	query := `
+// dimensionRollupQuery fills analytics_hourly_dimension_rollups, which keeps
+// hourly totals per API key (metadata api_key_id) and per error code so top-N
+// breakdowns do not scan raw events. Buckets that no longer have events for a
+// value are left as they were; rollups only ever grow.
+const dimensionRollupQuery = `
+	INSERT INTO analytics_hourly_dimension_rollups (
+		bucket_start,
+		organization_id,
+		dimension,
+		value,
+		request_count,
+		error_count,
+		cost_total,
+		updated_at
+	)
+	SELECT
+		date_trunc('hour', occurred_at) AS bucket_start,
+		org_id AS organization_id,
+		'api_key' AS dimension,
+		metadata->>'api_key_id' AS value,
+		COUNT(*) AS request_count,
+		SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS error_count,
+		SUM(cost_estimate_cents / 100.0) AS cost_total,
+		NOW() AS updated_at
+	FROM analytics.usage_events
+	WHERE occurred_at >= $1 AND occurred_at < $2 AND metadata->>'api_key_id' IS NOT NULL
+	GROUP BY 1, 2, 4
+	UNION ALL
+	SELECT
+		date_trunc('hour', occurred_at),
+		org_id,
+		'error_code',
+		COALESCE(NULLIF(error_code, ''), 'unknown'),
+		COUNT(*),
+		COUNT(*),
+		SUM(cost_estimate_cents / 100.0),
+		NOW()
+	FROM analytics.usage_events
+	WHERE occurred_at >= $1 AND occurred_at < $2 AND status = 'error'
+	GROUP BY 1, 2, 4
+	ON CONFLICT (bucket_start, organization_id, dimension, value)
+	DO UPDATE SET
+		request_count = EXCLUDED.request_count,
+		error_count   = EXCLUDED.error_count,
+		cost_total    = EXCLUDED.cost_total,
+		updated_at    = NOW()
+	RETURNING organization_id
+`
+
+// runDailyRollup executes the daily rollup transform, adding the orgs whose
+// buckets it wrote to affected.
+func (w *Worker) runDailyRollup(ctx context.Context, start, end time.Time, affected map[uuid.UUID]bool) error {
+	query := fmt.Sprintf(`
+		WITH totals AS (
+			SELECT
+				date_trunc('day', occurred_at)::date AS bucket_start,
+				org_id AS organization_id,
+				model_id,
+				COUNT(*) AS request_count,
+				SUM(input_tokens + output_tokens) AS tokens_total,
+				SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS error_count,
+				SUM(cost_estimate_cents / 100.0) AS cost_total
+			FROM analytics.usage_events
+			WHERE occurred_at >= $1 AND occurred_at < $2
+			GROUP BY 1, 2, 3
+		),
+		latency_buckets AS (
+			SELECT
+				date_trunc('day', occurred_at)::date AS bucket_start,
+				org_id AS organization_id,
+				model_id,
+				%s AS idx,
+				COUNT(*) AS n
+			FROM analytics.usage_events
+			WHERE occurred_at >= $1 AND occurred_at < $2 AND latency_ms IS NOT NULL
+			GROUP BY 1, 2, 3, 4
+		),
+		histograms AS (
+			SELECT bucket_start, organization_id, model_id, jsonb_object_agg(idx::TEXT, n) AS latency_histogram
+			FROM latency_buckets
+			GROUP BY 1, 2, 3
+		)
 		INSERT INTO analytics_daily_rollups (
 			bucket_start,
 			organization_id,
@@ -183,31 +347,36 @@ func (w *Worker) runDailyRollup(ctx context.Context, start, end time.Time) error
 			tokens_total,
 			error_count,
 			cost_total,
+			latency_histogram,
 			updated_at
 		)
 		SELECT
-			date_trunc('day', occurred_at)::date AS bucket_start,
-			org_id AS organization_id,
-			model_id,
-			COUNT(*) AS request_count,
-			SUM(input_tokens + output_tokens) AS tokens_total,
-			SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS error_count,
-			SUM(cost_estimate_cents / 100.0) AS cost_total,
+			t.bucket_start,
+			t.organization_id,
+			t.model_id,
+			t.request_count,
+			t.tokens_total,
+			t.error_count,
+			t.cost_total,
+			COALESCE(h.latency_histogram, '{}'::JSONB),
 			NOW() AS updated_at
-		FROM analytics.usage_events
-		WHERE occurred_at >= $1 AND occurred_at < $2
-		GROUP BY 1, 2, 3
+		FROM totals t
+		LEFT JOIN histograms h
+			ON h.bucket_start = t.bucket_start
+			AND h.organization_id = t.organization_id
+			AND h.model_id IS NOT DISTINCT FROM t.model_id
 		ON CONFLICT (bucket_start, organization_id, model_id)
 		DO UPDATE SET
-			request_count = EXCLUDED.request_count,
-			tokens_total  = EXCLUDED.tokens_total,
-			error_count   = EXCLUDED.error_count,
-			cost_total    = EXCLUDED.cost_total,
-			updated_at    = NOW()
-	`
-
-	_, err := w.store.Pool().Exec(ctx, query, start, end)
-	if err != nil {
+			request_count     = EXCLUDED.request_count,
+			tokens_total      = EXCLUDED.tokens_total,
+			error_count       = EXCLUDED.error_count,
+			cost_total        = EXCLUDED.cost_total,
+			latency_histogram = EXCLUDED.latency_histogram,
+			updated_at        = NOW()
+		RETURNING organization_id
+	`, sketch.IndexSQL("latency_ms"))
+
+	if err := w.collectOrgs(ctx, query, start, end, affected); err != nil {
 		return fmt.Errorf("execute daily rollup: %w", err)
 	}
 
@@ -219,6 +388,39 @@ func (w *Worker) runDailyRollup(ctx context.Context, start, end time.Time) error
 	return nil
 }
 
+// collectOrgs runs a rollup statement returning organization_id and records
+// each org in affected.
+func (w *Worker) collectOrgs(ctx context.Context, query string, start, end time.Time, affected map[uuid.UUID]bool) error {
+	rows, err := w.store.Pool().Query(ctx, query, start, end)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			return err
+		}
+		affected[orgID] = true
+	}
+	return rows.Err()
+}
+
+// invalidate drops cached query results for the affected orgs. Failures are
+// logged; cached entries then expire by TTL.
+func (w *Worker) invalidate(ctx context.Context, affected map[uuid.UUID]bool) {
+	if w.invalidator == nil || len(affected) == 0 {
+		return
+	}
+	orgIDs := make([]uuid.UUID, 0, len(affected))
+	for orgID := range affected {
+		orgIDs = append(orgIDs, orgID)
+	}
+	if err := w.invalidator.InvalidateOrgs(ctx, orgIDs); err != nil {
+		w.logger.Warn("failed to invalidate query cache", zap.Int("orgs", len(orgIDs)), zap.Error(err))
+	}
+}
+
 // updateFreshnessStatus updates the freshness_status table.
 func (w *Worker) updateFreshnessStatus(ctx context.Context) error {
 	query := `
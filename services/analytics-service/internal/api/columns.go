@@ -0,0 +1,28 @@
+// Package api provides column-level access checks for analytics endpoints.
+package api
+
+import (
+	"net/http"
+
+	"github.com/otherjamesbrown/ai-aas/shared/go/auth"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
+)
+
+// callerColumns returns the columns the caller's export profiles allow. The
+// actor is set by the RBAC middleware; without one (RBAC disabled) the
+// default profile applies.
+func callerColumns(r *http.Request, defaultProfile string) (exports.ColumnSet, error) {
+	var roles []string
+	if actor, ok := auth.ActorFromContext(r.Context()); ok {
+		roles = actor.Roles
+	}
+	return exports.ColumnsForRoles(roles, defaultProfile)
+}
+
+// centsIfAllowed returns cents when the caller may read cost, nil otherwise.
+func centsIfAllowed(columns exports.ColumnSet, cents int64) *int64 {
+	if !columns.Has(exports.ColumnCostTotal) {
+		return nil
+	}
+	return &cents
+}
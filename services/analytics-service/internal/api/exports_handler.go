@@ -2,7 +2,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -17,20 +19,33 @@ import (
 
 // ExportsHandler handles export job management API requests.
 type ExportsHandler struct {
-	repo   *exports.ExportJobRepository
-	logger *zap.Logger
+	repo           *exports.ExportJobRepository
+	delivery       *exports.S3Delivery
+	logger         *zap.Logger
+	defaultProfile string
 }
 
-// NewExportsHandler creates a new exports handler.
-func NewExportsHandler(pool *pgxpool.Pool, logger *zap.Logger) *ExportsHandler {
+// urlRefreshMargin regenerates signed URLs this long before they expire, so
+// a returned URL stays usable for at least that long.
+const urlRefreshMargin = 5 * time.Minute
+
+// NewExportsHandler creates a new exports handler. defaultProfile is the
+// export profile for callers without a profile role. delivery signs fresh
+// download URLs once stored ones expire; it may be nil when S3 is not
+// configured.
+func NewExportsHandler(pool *pgxpool.Pool, delivery *exports.S3Delivery, logger *zap.Logger, defaultProfile string) *ExportsHandler {
 	repo := exports.NewExportJobRepository(pool)
 	return &ExportsHandler{
-		repo:   repo,
-		logger: logger,
+		repo:           repo,
+		delivery:       delivery,
+		logger:         logger,
+		defaultProfile: defaultProfile,
 	}
 }
 
 // CreateExportJob handles POST /analytics/v1/orgs/{orgId}/exports
+// The job writes the columns of the caller's export profiles, or of the
+// requested profile when it is within them.
 func (h *ExportsHandler) CreateExportJob(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -77,6 +92,25 @@ func (h *ExportsHandler) CreateExportJob(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Resolve the columns the export may contain
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+	if req.Profile != "" {
+		requested, err := exports.ColumnsForProfile(req.Profile)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "profile must be 'full', 'finance', or 'engineering'", err)
+			return
+		}
+		if !columns.Covers(requested) {
+			h.respondError(w, http.StatusForbidden, "export profile not allowed for caller", nil)
+			return
+		}
+		columns = requested
+	}
+
 	// Extract requested_by from auth context
 	var requestedBy uuid.UUID
 	if actor, ok := auth.ActorFromContext(ctx); ok && actor.Subject != "" {
@@ -104,6 +138,7 @@ func (h *ExportsHandler) CreateExportJob(w http.ResponseWriter, r *http.Request)
 		TimeRangeStart: req.TimeRange.Start,
 		TimeRangeEnd:   req.TimeRange.End,
 		Granularity:    granularity,
+		Columns:        columns.CSV(),
 	})
 	if err != nil {
 		h.logger.Error("failed to create export job", zap.Error(err))
@@ -120,7 +155,7 @@ func (h *ExportsHandler) CreateExportJob(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Build response
-	response := convertExportJob(job)
+	response := convertExportJob(job, columns)
 	h.respondJSON(w, http.StatusAccepted, response)
 }
 
@@ -147,6 +182,7 @@ func (h *ExportsHandler) ListExportJobs(w http.ResponseWriter, r *http.Request)
 			"succeeded": true,
 			"failed":    true,
 			"expired":   true,
+			"cancelled": true,
 		}
 		if !validStatuses[statusFilter] {
 			h.respondError(w, http.StatusBadRequest, "invalid status filter", nil)
@@ -163,10 +199,17 @@ func (h *ExportsHandler) ListExportJobs(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+
 	// Convert to response format
 	items := make([]ExportJobResponse, len(jobs))
-	for i, job := range jobs {
-		items[i] = convertExportJob(&job)
+	for i := range jobs {
+		h.refreshURL(ctx, &jobs[i])
+		items[i] = convertExportJob(&jobs[i], columns)
 	}
 
 	response := ListExportJobsResponse{
@@ -204,8 +247,15 @@ func (h *ExportsHandler) GetExportJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+
 	// Build response
-	response := convertExportJob(job)
+	h.refreshURL(ctx, job)
+	response := convertExportJob(job, columns)
 	h.respondJSON(w, http.StatusOK, response)
 }
 
@@ -237,12 +287,24 @@ func (h *ExportsHandler) GetExportDownloadUrl(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// The caller must be allowed every column in the file
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+	if !canReadJob(job, columns) {
+		h.respondError(w, http.StatusForbidden, "export contains columns not allowed for caller", nil)
+		return
+	}
+
 	// Only allow download for succeeded jobs
 	if job.Status != "succeeded" {
 		h.respondError(w, http.StatusNotFound, "export job is not ready for download", nil)
 		return
 	}
 
+	h.refreshURL(ctx, job)
 	if job.OutputURI == nil {
 		h.respondError(w, http.StatusNotFound, "export job output URI not available", nil)
 		return
@@ -253,6 +315,122 @@ func (h *ExportsHandler) GetExportDownloadUrl(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusFound)
 }
 
+// CancelExportJob handles POST /analytics/v1/orgs/{orgId}/exports/{jobId}/cancel
+// Pending jobs are cancelled immediately; running jobs stop at their next
+// progress update.
+func (h *ExportsHandler) CancelExportJob(w http.ResponseWriter, r *http.Request) {
+	h.transitionJob(w, r, "cancel", h.repo.CancelExportJob)
+}
+
+// RetryExportJob handles POST /analytics/v1/orgs/{orgId}/exports/{jobId}/retry
+// Failed, cancelled and expired jobs are queued again from scratch.
+func (h *ExportsHandler) RetryExportJob(w http.ResponseWriter, r *http.Request) {
+	h.transitionJob(w, r, "retry", h.repo.RetryExportJob)
+}
+
+// DeleteExportJob handles DELETE /analytics/v1/orgs/{orgId}/exports/{jobId}
+// The job's file is removed from object storage by the runner's orphan cleanup.
+func (h *ExportsHandler) DeleteExportJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, jobID, ok := h.parseJobPath(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.repo.DeleteExportJob(ctx, orgID, jobID)
+	if err != nil {
+		h.logger.Error("failed to delete export job", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete export job", err)
+		return
+	}
+	if !deleted {
+		h.respondError(w, http.StatusNotFound, "export job not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// transitionJob applies a status transition and returns the updated job, or
+// 409 if the job is not in a state the transition applies to.
+func (h *ExportsHandler) transitionJob(w http.ResponseWriter, r *http.Request, action string, transition func(ctx context.Context, orgID, jobID uuid.UUID) (bool, error)) {
+	ctx := r.Context()
+
+	orgID, jobID, ok := h.parseJobPath(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.repo.GetExportJob(ctx, orgID, jobID)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "export job not found", err)
+		return
+	}
+
+	applied, err := transition(ctx, orgID, jobID)
+	if err != nil {
+		h.logger.Error("failed to "+action+" export job", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to "+action+" export job", err)
+		return
+	}
+	if !applied {
+		h.respondError(w, http.StatusConflict, fmt.Sprintf("cannot %s export job in status %q", action, job.Status), nil)
+		return
+	}
+
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+
+	job, err = h.repo.GetExportJob(ctx, orgID, jobID)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to retrieve export job", err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, convertExportJob(job, columns))
+}
+
+// parseJobPath parses the org and job IDs from the path, writing a 400 on failure.
+func (h *ExportsHandler) parseJobPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgId"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid org_id", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid job_id", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, jobID, true
+}
+
+// refreshURL replaces the signed URL of a succeeded job when it has expired or
+// is about to. Failures are logged and the stored URL is kept.
+func (h *ExportsHandler) refreshURL(ctx context.Context, job *exports.ExportJob) {
+	if h.delivery == nil || job.Status != "succeeded" {
+		return
+	}
+	if job.URLExpiresAt != nil && time.Until(*job.URLExpiresAt) > urlRefreshMargin {
+		return
+	}
+
+	signedURL, expiresAt, err := h.delivery.SignJobURL(ctx, job.OrgID, job.JobID)
+	if err != nil {
+		h.logger.Warn("failed to regenerate export URL", zap.String("job_id", job.JobID.String()), zap.Error(err))
+		return
+	}
+	if err := h.repo.SetExportJobURL(ctx, job.JobID, signedURL, expiresAt); err != nil {
+		h.logger.Warn("failed to store regenerated export URL", zap.String("job_id", job.JobID.String()), zap.Error(err))
+	}
+	job.OutputURI = &signedURL
+	job.URLExpiresAt = &expiresAt
+}
+
 // Request/Response types matching OpenAPI schema
 
 type CreateExportRequest struct {
@@ -260,6 +438,7 @@ type CreateExportRequest struct {
 	Granularity string           `json:"granularity,omitempty"`
 	Models      []uuid.UUID      `json:"models,omitempty"`
 	Delivery    *DeliveryRequest `json:"delivery,omitempty"`
+	Profile     string           `json:"profile,omitempty"` // full, finance or engineering; defaults to the caller's
 }
 
 type TimeRangeRequest struct {
@@ -283,8 +462,11 @@ type ExportJobResponse struct {
 	OutputURI   *string         `json:"outputUri,omitempty"`
 	Checksum    *string         `json:"checksum,omitempty"`
 	RowCount    *int64          `json:"rowCount,omitempty"`
+	RowsWritten int64           `json:"rowsWritten"` // Progress while running
+	URLExpiresAt *string        `json:"outputUriExpiresAt,omitempty"`
 	InitiatedBy string          `json:"initiatedBy"`
 	Error       *string         `json:"error,omitempty"`
+	Columns     []string        `json:"columns"`
 }
 
 type TimeRangeResponse struct {
@@ -298,7 +480,23 @@ type ListExportJobsResponse struct {
 
 // Helper functions
 
-func convertExportJob(job *exports.ExportJob) ExportJobResponse {
+// canReadJob reports whether columns cover every column in the job's file.
+func canReadJob(job *exports.ExportJob, columns exports.ColumnSet) bool {
+	jobColumns := job.Columns
+	if len(jobColumns) == 0 {
+		jobColumns = exports.CSVColumns
+	}
+	for _, column := range jobColumns {
+		if !columns.Has(column) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertExportJob builds the job response. The signed output URI is only
+// included when the caller may read every column of the file.
+func convertExportJob(job *exports.ExportJob, columns exports.ColumnSet) ExportJobResponse {
 	response := ExportJobResponse{
 		JobID:       job.JobID.String(),
 		OrgID:       job.OrgID.String(),
@@ -310,6 +508,12 @@ func convertExportJob(job *exports.ExportJob) ExportJobResponse {
 		},
 		CreatedAt:   job.InitiatedAt.Format(time.RFC3339),
 		InitiatedBy: job.RequestedBy.String(),
+		Columns:     job.Columns,
+		RowsWritten: job.RowsWritten,
+	}
+
+	if len(response.Columns) == 0 {
+		response.Columns = exports.CSVColumns
 	}
 
 	if job.CompletedAt != nil {
@@ -317,8 +521,12 @@ func convertExportJob(job *exports.ExportJob) ExportJobResponse {
 		response.CompletedAt = &completedAt
 	}
 
-	if job.OutputURI != nil {
+	if job.OutputURI != nil && canReadJob(job, columns) {
 		response.OutputURI = job.OutputURI
+		if job.URLExpiresAt != nil {
+			expiresAt := job.URLExpiresAt.Format(time.RFC3339)
+			response.URLExpiresAt = &expiresAt
+		}
 	}
 
 	if job.Checksum != nil {
@@ -352,10 +560,6 @@ func (h *ExportsHandler) respondError(w http.ResponseWriter, status int, message
 	}
 	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": status,
-		"title":  http.StatusText(status),
-		"detail": message,
-	})
+	json.NewEncoder(w).Encode(problem(w, status, message))
 }
 
@@ -0,0 +1,140 @@
+// Package api provides HTTP handlers for freshness endpoints.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+)
+
+// Freshness and cache headers attached to usage query responses.
+const (
+	HeaderFreshnessStatus     = "X-Analytics-Freshness"
+	HeaderFreshnessLagSeconds = "X-Analytics-Freshness-Lag-Seconds"
+	HeaderFreshnessLastRollup = "X-Analytics-Last-Rollup-At"
+	HeaderQueryCache          = "X-Analytics-Cache" // "hit" or "miss" on cacheable queries
+)
+
+// FreshnessHandler serves the materialized per-org freshness view.
+type FreshnessHandler struct {
+	store  *postgres.Store
+	cache  *freshness.Cache
+	logger *zap.Logger
+}
+
+// NewFreshnessHandler creates a new freshness handler.
+func NewFreshnessHandler(store *postgres.Store, cache *freshness.Cache, logger *zap.Logger) *FreshnessHandler {
+	return &FreshnessHandler{
+		store:  store,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// FreshnessResponse lists per-org freshness with a per-stage lag breakdown.
+type FreshnessResponse struct {
+	GeneratedAt time.Time                `json:"generatedAt"`
+	Orgs        []freshness.OrgFreshness `json:"orgs"`
+}
+
+// GetFreshness handles GET /analytics/v1/freshness
+func (h *FreshnessHandler) GetFreshness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var orgID *uuid.UUID
+	if orgIDStr := r.URL.Query().Get("orgId"); orgIDStr != "" {
+		parsed, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid orgId", err)
+			return
+		}
+		orgID = &parsed
+	}
+
+	indicators, err := h.store.GetAllFreshnessStatus(ctx)
+	if err != nil {
+		h.logger.Error("failed to get freshness status", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to retrieve freshness status", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	orgs, err := h.cache.Breakdown(ctx, indicators, orgID, now)
+	if err != nil {
+		h.logger.Error("failed to build freshness breakdown", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to retrieve freshness breakdown", err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, FreshnessResponse{GeneratedAt: now, Orgs: orgs})
+}
+
+// resolveFreshness returns the freshness indicator for an org/model from the
+// cache, falling back to the database (and re-populating the cache).
+func resolveFreshness(ctx context.Context, cache *freshness.Cache, store *postgres.Store, orgID uuid.UUID, modelID *uuid.UUID) FreshnessIndicator {
+	if cache != nil {
+		if cached, err := cache.Get(ctx, orgID, modelID); err == nil && cached != nil {
+			return FreshnessIndicator{
+				Status:       cached.Status,
+				LagSeconds:   cached.LagSeconds,
+				LastEventAt:  cached.LastEventAt,
+				LastRollupAt: cached.LastRollupAt,
+			}
+		}
+	}
+
+	// Fallback to database if cache miss
+	if dbFreshness, err := store.GetFreshnessStatus(ctx, orgID, modelID); err == nil {
+		if cache != nil {
+			// Cache it for next time
+			_ = cache.Set(ctx, dbFreshness)
+		}
+		return FreshnessIndicator{
+			Status:       dbFreshness.Status,
+			LagSeconds:   dbFreshness.LagSeconds,
+			LastEventAt:  dbFreshness.LastEventAt,
+			LastRollupAt: dbFreshness.LastRollupAt,
+		}
+	}
+
+	// Default if not found
+	return FreshnessIndicator{
+		Status:       "fresh",
+		LagSeconds:   0,
+		LastEventAt:  time.Now().Add(-5 * time.Minute),
+		LastRollupAt: time.Now().Add(-1 * time.Minute),
+	}
+}
+
+// setFreshnessHeaders exposes data freshness on query responses so clients
+// can tell stale dashboards apart without parsing the body.
+func setFreshnessHeaders(w http.ResponseWriter, f FreshnessIndicator) {
+	w.Header().Set(HeaderFreshnessStatus, f.Status)
+	w.Header().Set(HeaderFreshnessLagSeconds, strconv.Itoa(f.LagSeconds))
+	if !f.LastRollupAt.IsZero() {
+		w.Header().Set(HeaderFreshnessLastRollup, f.LastRollupAt.UTC().Format(time.RFC3339))
+	}
+}
+
+func (h *FreshnessHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+func (h *FreshnessHandler) respondError(w http.ResponseWriter, status int, message string, err error) {
+	h.logger.Warn(message, zap.Error(err), zap.Int("status", status))
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem(w, status, message))
+}
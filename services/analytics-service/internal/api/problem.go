@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// problem builds an application/problem+json body. The request and
+// correlation IDs are read back from the response headers set by
+// requestid.Middleware, so handlers need not thread the request through.
+func problem(w http.ResponseWriter, status int, message string) map[string]interface{} {
+	body := map[string]interface{}{
+		"status": status,
+		"title":  http.StatusText(status),
+		"detail": message,
+	}
+	if id := w.Header().Get(requestid.RequestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	if id := w.Header().Get(requestid.CorrelationIDHeader); id != "" {
+		body["correlation_id"] = id
+	}
+	return body
+}
@@ -10,24 +10,29 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/sketch"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
 // ReliabilityHandler handles reliability-related API requests.
 type ReliabilityHandler struct {
-	store  *postgres.Store
-	logger *zap.Logger
+	store          *postgres.Store
+	logger         *zap.Logger
+	freshnessCache *freshness.Cache
 }
 
 // NewReliabilityHandler creates a new reliability handler.
-func NewReliabilityHandler(store *postgres.Store, logger *zap.Logger) *ReliabilityHandler {
+func NewReliabilityHandler(store *postgres.Store, logger *zap.Logger, cache *freshness.Cache) *ReliabilityHandler {
 	return &ReliabilityHandler{
-		store:  store,
-		logger: logger,
+		store:          store,
+		logger:         logger,
+		freshnessCache: cache,
 	}
 }
 
 // GetOrgReliability handles GET /analytics/v1/orgs/{orgId}/reliability
+// Latency percentiles are read from the rollups' histograms (internal/sketch).
 func (h *ReliabilityHandler) GetOrgReliability(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -96,11 +101,24 @@ func (h *ReliabilityHandler) GetOrgReliability(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	setFreshnessHeaders(w, resolveFreshness(ctx, h.freshnessCache, h.store, orgID, modelID))
+
+	// Percentiles over the whole range, merged from the bucket histograms
+	overall := sketch.Histogram{}
+	for _, p := range points {
+		overall.Merge(p.Latency)
+	}
+
 	// Build response
 	response := ReliabilitySeriesResponse{
 		OrgID:       orgID.String(),
 		Granularity: granularity,
 		Series:      convertReliabilityPoints(points, percentile),
+		Summary: LatencyPercentiles{
+			P50: overall.Quantile(0.50),
+			P95: overall.Quantile(0.95),
+			P99: overall.Quantile(0.99),
+		},
 	}
 
 	h.respondJSON(w, http.StatusOK, response)
@@ -111,6 +129,7 @@ type ReliabilitySeriesResponse struct {
 	OrgID       string                `json:"orgId"`
 	Granularity string                `json:"granularity"`
 	Series      []ReliabilityPointResp `json:"series"`
+	Summary     LatencyPercentiles     `json:"summaryLatencyMs"` // Across all buckets in the range
 }
 
 // ReliabilityPointResp matches the OpenAPI schema.
@@ -161,10 +180,6 @@ func (h *ReliabilityHandler) respondError(w http.ResponseWriter, status int, mes
 	h.logger.Warn(message, zap.Error(err), zap.Int("status", status))
 	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": status,
-		"title":  http.StatusText(status),
-		"detail": message,
-	})
+	json.NewEncoder(w).Encode(problem(w, status, message))
 }
 
@@ -8,7 +8,9 @@
 //
 // Dependencies:
 //   - github.com/go-chi/chi/v5: HTTP router
-//   - github.com/prometheus/client_golang: Prometheus metrics
+//
+// /metrics, /debug/pprof and the Kubernetes probe paths are served by the
+// shared lifecycle server (shared-go/server) that wraps this router.
 package api
 
 import (
@@ -19,10 +21,13 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/apiversion"
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/requestid"
+
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/audit"
 	rbacmiddleware "github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/middleware"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
@@ -47,9 +52,21 @@ type Config struct {
 	IdleTimeout  time.Duration
 	// EnableRBAC controls whether RBAC middleware is enabled (default: true)
 	EnableRBAC bool
+	// TokenVerifier authenticates user-org-service access tokens; nil keeps
+	// header-based actors
+	TokenVerifier *tokens.Verifier
+	// AdminScope is the token scope granting cross-org access
+	AdminScope string
 	// Dependencies for readiness checks
 	Store       *postgres.Store
 	RedisClient *redis.Client
+	// ReadinessGate fails readiness while the lifecycle server is starting or
+	// draining (optional)
+	ReadinessGate func(http.HandlerFunc) http.HandlerFunc
+	// ServiceName labels deprecated route metrics
+	ServiceName string
+	// Deprecations are routes announced with Deprecation/Sunset headers
+	Deprecations []apiversion.Deprecation
 }
 
 // NewServer creates a new HTTP server with configured middleware and routes.
@@ -61,8 +78,12 @@ func NewServer(cfg Config) *Server {
 	auditLogger.Setup()
 
 	// Middleware stack
-	r.Use(middleware.RequestID)
+	r.Use(requestid.Middleware)
 	r.Use(middleware.RealIP)
+	r.Use(apiversion.Middleware(apiversion.Config{
+		ServiceName:  cfg.ServiceName,
+		Deprecations: cfg.Deprecations,
+	}))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -71,6 +92,8 @@ func NewServer(cfg Config) *Server {
 	rbacCfg := rbacmiddleware.RBACConfig{
 		Logger:     cfg.Logger,
 		EnableRBAC: cfg.EnableRBAC,
+		Verifier:   cfg.TokenVerifier,
+		AdminScope: cfg.AdminScope,
 	}
 
 	s := &Server{
@@ -82,15 +105,17 @@ func NewServer(cfg Config) *Server {
 		redisClient: cfg.RedisClient,
 	}
 
+	readyz := http.HandlerFunc(s.readyzHandler)
+	if cfg.ReadinessGate != nil {
+		readyz = cfg.ReadinessGate(readyz)
+	}
+
 	// Health and readiness endpoints (no RBAC)
 	r.Route("/analytics/v1/status", func(r chi.Router) {
 		r.Get("/healthz", healthzHandler)
-		r.Get("/readyz", s.readyzHandler)
+		r.Get("/readyz", readyz)
 	})
 
-	// Prometheus metrics endpoint (no RBAC)
-	r.Handle("/metrics", promhttp.Handler())
-
 	// Analytics API routes will be registered via RegisterRoutes
 	// RBAC middleware will be applied to each route group
 
@@ -122,6 +147,32 @@ func (s *Server) RegisterReliabilityRoutes(handler *ReliabilityHandler) {
 	})
 }
 
+// RegisterTopRoutes registers top-N breakdown API routes.
+func (s *Server) RegisterTopRoutes(handler *TopHandler) {
+	s.router.Route("/analytics/v1/orgs/{orgId}/usage/top", func(r chi.Router) {
+		r.Use(rbacmiddleware.RBAC(s.rbacCfg)) // Apply RBAC middleware
+		r.Get("/models", handler.GetTopModels)
+		r.Get("/keys", handler.GetTopAPIKeys)
+		r.Get("/error-codes", handler.GetTopErrorCodes)
+	})
+}
+
+// RegisterSpendRoutes registers spend forecast API routes.
+func (s *Server) RegisterSpendRoutes(handler *SpendHandler) {
+	s.router.Route("/analytics/v1/spend", func(r chi.Router) {
+		r.Use(rbacmiddleware.RBAC(s.rbacCfg)) // Apply RBAC middleware
+		r.Get("/forecast", handler.GetSpendForecast)
+	})
+}
+
+// RegisterFreshnessRoutes registers the cross-org freshness API.
+func (s *Server) RegisterFreshnessRoutes(handler *FreshnessHandler) {
+	s.router.Route("/analytics/v1/freshness", func(r chi.Router) {
+		r.Use(rbacmiddleware.RBAC(s.rbacCfg)) // Apply RBAC middleware
+		r.Get("/", handler.GetFreshness)
+	})
+}
+
 // RegisterExportsRoutes registers export job management API routes.
 func (s *Server) RegisterExportsRoutes(handler *ExportsHandler) {
 	s.router.Route("/analytics/v1", func(r chi.Router) {
@@ -131,7 +182,10 @@ func (s *Server) RegisterExportsRoutes(handler *ExportsHandler) {
 				r.Post("/", handler.CreateExportJob)
 				r.Get("/", handler.ListExportJobs)
 				r.Get("/{jobId}", handler.GetExportJob)
+				r.Delete("/{jobId}", handler.DeleteExportJob)
 				r.Get("/{jobId}/download", handler.GetExportDownloadUrl)
+				r.Post("/{jobId}/cancel", handler.CancelExportJob)
+				r.Post("/{jobId}/retry", handler.RetryExportJob)
 			})
 		})
 	})
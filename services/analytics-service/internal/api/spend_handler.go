@@ -0,0 +1,150 @@
+// Package api provides HTTP handlers for spend forecasting.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/forecast"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+)
+
+// SpendHandler handles spend forecast API requests.
+type SpendHandler struct {
+	store          *postgres.Store
+	logger         *zap.Logger
+	freshnessCache *freshness.Cache
+	defaultProfile string
+	now            func() time.Time
+}
+
+// NewSpendHandler creates a new spend handler. defaultProfile is the export
+// profile for callers without a profile role.
+func NewSpendHandler(store *postgres.Store, logger *zap.Logger, cache *freshness.Cache, defaultProfile string) *SpendHandler {
+	return &SpendHandler{
+		store:          store,
+		logger:         logger,
+		freshnessCache: cache,
+		defaultProfile: defaultProfile,
+		now:            time.Now,
+	}
+}
+
+// GetSpendForecast handles GET /analytics/v1/spend/forecast
+//
+// Projects each org's end-of-month spend from the current month's daily
+// rollups. With orgId only that org is returned; without it every org with
+// spend this month is, for budget alerting.
+func (h *SpendHandler) GetSpendForecast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var orgID *uuid.UUID
+	if orgIDStr := r.URL.Query().Get("orgId"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid orgId parameter", err)
+			return
+		}
+		orgID = &id
+	}
+
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+	if !columns.Has(exports.ColumnCostTotal) {
+		h.respondError(w, http.StatusForbidden, "export profile does not allow spend", nil)
+		return
+	}
+
+	now := h.now().UTC()
+	monthStart := forecast.MonthStart(now)
+	spend, err := h.store.GetDailySpend(ctx, orgID, forecast.HistoryStart(now), now.Truncate(24*time.Hour).Add(24*time.Hour), monthStart)
+	if err != nil {
+		h.logger.Error("failed to get daily spend", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to retrieve spend data", err)
+		return
+	}
+
+	// Group by org, keeping the query's org order
+	var orgs []uuid.UUID
+	days := map[uuid.UUID][]forecast.Day{}
+	for _, d := range spend {
+		if _, ok := days[d.OrgID]; !ok {
+			orgs = append(orgs, d.OrgID)
+		}
+		days[d.OrgID] = append(days[d.OrgID], forecast.Day{Date: d.Day, Cost: d.Cost})
+	}
+	if orgID != nil && len(orgs) == 0 {
+		orgs = append(orgs, *orgID) // No spend yet still gets a (zero) forecast
+	}
+
+	response := SpendForecastResponse{
+		Month:       monthStart.Format("2006-01"),
+		GeneratedAt: now.Format(time.RFC3339),
+		Confidence:  forecast.Confidence,
+		Forecasts:   make([]OrgSpendForecast, 0, len(orgs)),
+	}
+	for _, id := range orgs {
+		result := forecast.Project(days[id], now)
+		response.Forecasts = append(response.Forecasts, OrgSpendForecast{
+			OrgID:            id.String(),
+			MonthToDateCents: int64(result.MonthToDate * 100),
+			ForecastCents:    int64(result.Forecast * 100),
+			LowerBoundCents:  int64(result.Lower * 100),
+			UpperBoundCents:  int64(result.Upper * 100),
+			DailyTrendCents:  int64(result.DailyTrend * 100),
+			Method:           result.Method,
+			DaysElapsed:      result.DaysElapsed,
+			DaysInMonth:      result.DaysInMonth,
+		})
+	}
+
+	if orgID != nil {
+		setFreshnessHeaders(w, resolveFreshness(ctx, h.freshnessCache, h.store, *orgID, nil))
+	}
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// SpendForecastResponse is the body of the spend forecast endpoint.
+type SpendForecastResponse struct {
+	Month       string             `json:"month"` // YYYY-MM, UTC
+	GeneratedAt string             `json:"generatedAt"`
+	Confidence  float64            `json:"confidence"` // Coverage of the bounds
+	Forecasts   []OrgSpendForecast `json:"forecasts"`
+}
+
+// OrgSpendForecast is one org's end-of-month projection.
+type OrgSpendForecast struct {
+	OrgID            string `json:"orgId"`
+	MonthToDateCents int64  `json:"monthToDateCents"`
+	ForecastCents    int64  `json:"forecastCents"`
+	LowerBoundCents  int64  `json:"lowerBoundCents"`
+	UpperBoundCents  int64  `json:"upperBoundCents"`
+	DailyTrendCents  int64  `json:"dailyTrendCents"` // Change in daily spend per day
+	Method           string `json:"method"`          // trend+weekday, trend or run-rate
+	DaysElapsed      int    `json:"daysElapsed"`
+	DaysInMonth      int    `json:"daysInMonth"`
+}
+
+func (h *SpendHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+func (h *SpendHandler) respondError(w http.ResponseWriter, status int, message string, err error) {
+	h.logger.Warn(message, zap.Error(err), zap.Int("status", status))
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem(w, status, message))
+}
@@ -0,0 +1,226 @@
+// Package api provides HTTP handlers for top-N breakdown endpoints.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+)
+
+// Top-N limits.
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+// Sources a top-N breakdown can be computed from.
+const (
+	TopSourceRollups = "rollups"
+	TopSourceRaw     = "raw"
+)
+
+// topRankedBy names the metric each dimension is ordered by.
+var topRankedBy = map[string]string{
+	postgres.TopModels:     "spend",
+	postgres.TopAPIKeys:    "requests",
+	postgres.TopErrorCodes: "errors",
+}
+
+// topColumns names the export profile column each dimension needs: models are
+// ranked by spend, keys and error codes come from event metadata.
+var topColumns = map[string]string{
+	postgres.TopModels:     exports.ColumnCostTotal,
+	postgres.TopAPIKeys:    exports.ColumnMetadata,
+	postgres.TopErrorCodes: exports.ColumnMetadata,
+}
+
+// TopHandler handles top-N breakdown API requests.
+type TopHandler struct {
+	store          *postgres.Store
+	logger         *zap.Logger
+	freshnessCache *freshness.Cache
+	rawMaxWindow   time.Duration
+	defaultProfile string
+}
+
+// NewTopHandler creates a new top-N handler. rawMaxWindow caps the range that
+// may be drilled down into raw events; 0 disables raw drill-down. defaultProfile
+// is the export profile for callers without a profile role.
+func NewTopHandler(store *postgres.Store, logger *zap.Logger, cache *freshness.Cache, rawMaxWindow time.Duration, defaultProfile string) *TopHandler {
+	return &TopHandler{
+		store:          store,
+		logger:         logger,
+		freshnessCache: cache,
+		rawMaxWindow:   rawMaxWindow,
+		defaultProfile: defaultProfile,
+	}
+}
+
+// GetTopModels handles GET /analytics/v1/orgs/{orgId}/usage/top/models
+func (h *TopHandler) GetTopModels(w http.ResponseWriter, r *http.Request) {
+	h.getTop(w, r, postgres.TopModels)
+}
+
+// GetTopAPIKeys handles GET /analytics/v1/orgs/{orgId}/usage/top/keys
+func (h *TopHandler) GetTopAPIKeys(w http.ResponseWriter, r *http.Request) {
+	h.getTop(w, r, postgres.TopAPIKeys)
+}
+
+// GetTopErrorCodes handles GET /analytics/v1/orgs/{orgId}/usage/top/error-codes
+func (h *TopHandler) GetTopErrorCodes(w http.ResponseWriter, r *http.Request) {
+	h.getTop(w, r, postgres.TopErrorCodes)
+}
+
+// getTop serves a top-N breakdown. Results come from the hourly rollups unless
+// source=raw is requested for a window no longer than rawMaxWindow. Cost is
+// omitted when the caller's export profile excludes it.
+func (h *TopHandler) getTop(w http.ResponseWriter, r *http.Request, dimension string) {
+	ctx := r.Context()
+
+	// Parse org ID
+	orgIDStr := chi.URLParam(r, "orgId")
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid org_id", err)
+		return
+	}
+
+	// Parse query parameters
+	startStr := r.URL.Query().Get("start")
+	endStr := r.URL.Query().Get("end")
+	limitStr := r.URL.Query().Get("limit")
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = TopSourceRollups
+	}
+
+	start, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid start parameter", err)
+		return
+	}
+
+	end, err := time.Parse(time.RFC3339, endStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid end parameter", err)
+		return
+	}
+
+	if end.Before(start) {
+		h.respondError(w, http.StatusBadRequest, "end must be after start", nil)
+		return
+	}
+
+	limit := defaultTopLimit
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxTopLimit {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTopLimit), err)
+			return
+		}
+	}
+
+	// Validate source; raw events are only scanned for short windows
+	switch source {
+	case TopSourceRollups:
+	case TopSourceRaw:
+		if end.Sub(start) > h.rawMaxWindow {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("source 'raw' is limited to windows of at most %s", h.rawMaxWindow), nil)
+			return
+		}
+	default:
+		h.respondError(w, http.StatusBadRequest, "source must be 'rollups' or 'raw'", nil)
+		return
+	}
+
+	columns, err := callerColumns(r, h.defaultProfile)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
+		return
+	}
+	if !columns.Has(topColumns[dimension]) {
+		h.respondError(w, http.StatusForbidden, fmt.Sprintf("export profile does not allow top %s", dimension), nil)
+		return
+	}
+
+	entries, err := h.store.GetTopUsage(ctx, orgID, dimension, start, end, limit, source == TopSourceRaw)
+	if err != nil {
+		h.logger.Error("failed to get top usage", zap.String("dimension", dimension), zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to retrieve top usage data", err)
+		return
+	}
+
+	// Raw results are always current, so freshness only applies to rollups
+	if source == TopSourceRollups {
+		setFreshnessHeaders(w, resolveFreshness(ctx, h.freshnessCache, h.store, orgID, nil))
+	}
+
+	response := TopUsageResponse{
+		OrgID:     orgID.String(),
+		Dimension: dimension,
+		RankedBy:  topRankedBy[dimension],
+		Source:    source,
+		Start:     start.UTC().Format(time.RFC3339),
+		End:       end.UTC().Format(time.RFC3339),
+		Items:     convertTopEntries(entries, columns),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// TopUsageResponse is the body of the top-N endpoints.
+type TopUsageResponse struct {
+	OrgID     string         `json:"orgId"`
+	Dimension string         `json:"dimension"`
+	RankedBy  string         `json:"rankedBy"` // spend, requests or errors
+	Source    string         `json:"source"`   // rollups or raw
+	Start     string         `json:"start"`
+	End       string         `json:"end"`
+	Items     []TopEntryResp `json:"items"`
+}
+
+// TopEntryResp is one ranked value. Key is a model ID, API key ID or error code.
+type TopEntryResp struct {
+	Key               string `json:"key"`
+	Requests          int64  `json:"requests"`
+	Errors            int64  `json:"errors"`
+	CostEstimateCents *int64 `json:"costEstimateCents,omitempty"` // Omitted when the caller's profile excludes cost
+}
+
+func convertTopEntries(entries []postgres.TopEntry, columns exports.ColumnSet) []TopEntryResp {
+	result := make([]TopEntryResp, len(entries))
+	for i, e := range entries {
+		result[i] = TopEntryResp{
+			Key:               e.Value,
+			Requests:          e.Requests,
+			Errors:            e.Errors,
+			CostEstimateCents: centsIfAllowed(columns, int64(e.CostEstimateCents*100)), // Convert to cents
+		}
+	}
+	return result
+}
+
+func (h *TopHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+func (h *TopHandler) respondError(w http.ResponseWriter, status int, message string, err error) {
+	h.logger.Warn(message, zap.Error(err), zap.Int("status", status))
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem(w, status, message))
+}
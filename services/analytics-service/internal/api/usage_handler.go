@@ -10,7 +10,9 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/freshness"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/querycache"
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
@@ -19,18 +21,54 @@ type UsageHandler struct {
 	store          *postgres.Store
 	logger         *zap.Logger
 	freshnessCache *freshness.Cache
+	queryCache     *querycache.Cache
+	defaultProfile string
 }
 
-// NewUsageHandler creates a new usage handler.
-func NewUsageHandler(store *postgres.Store, logger *zap.Logger, cache *freshness.Cache) *UsageHandler {
+// NewUsageHandler creates a new usage handler. queryCache may be nil to disable result caching.
+// defaultProfile is the export profile for callers without a profile role.
+func NewUsageHandler(store *postgres.Store, logger *zap.Logger, cache *freshness.Cache, queryCache *querycache.Cache, defaultProfile string) *UsageHandler {
 	return &UsageHandler{
 		store:          store,
 		logger:         logger,
 		freshnessCache: cache,
+		queryCache:     queryCache,
+		defaultProfile: defaultProfile,
+	}
+}
+
+// cachedUsage is the cached part of a usage response; freshness is always read live.
+type cachedUsage struct {
+	Series []UsagePointResponse `json:"series"`
+	Totals UsageTotalsResponse  `json:"totals"`
+}
+
+// redact drops the fields the caller's export profile does not cover. Cached
+// results hold every field, so redaction happens per request.
+func (u *cachedUsage) redact(columns exports.ColumnSet) {
+	hideCost := !columns.Has(exports.ColumnCostTotal)
+	hideTokens := !columns.Has(exports.ColumnTokensTotal)
+	for i := range u.Series {
+		if hideCost {
+			u.Series[i].CostEstimateCents = nil
+		}
+		if hideTokens {
+			u.Series[i].InputTokens, u.Series[i].OutputTokens = 0, 0
+		}
+	}
+	if hideCost {
+		u.Totals.CostEstimateCents = nil
+	}
+	if hideTokens {
+		u.Totals.InputTokens, u.Totals.OutputTokens = 0, 0
 	}
 }
 
 // GetOrgUsage handles GET /analytics/v1/orgs/{orgId}/usage
+// group_by=project splits the series by the team/project that issued the API key.
+// Results are served from the query cache when possible (X-Analytics-Cache: hit).
+// Cost and token fields are omitted when the caller's export profile excludes
+// them; group_by=project needs the metadata column.
 func (h *UsageHandler) GetOrgUsage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -50,6 +88,7 @@ func (h *UsageHandler) GetOrgUsage(w http.ResponseWriter, r *http.Request) {
 		granularity = "day"
 	}
 	modelIDStr := r.URL.Query().Get("modelId")
+	groupBy := r.URL.Query().Get("group_by")
 
 	start, err := time.Parse(time.RFC3339, startStr)
 	if err != nil {
@@ -74,6 +113,11 @@ func (h *UsageHandler) GetOrgUsage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if groupBy != "" && groupBy != "model" && groupBy != "project" {
+		h.respondError(w, http.StatusBadRequest, "group_by must be 'model' or 'project'", nil)
+		return
+	}
+
 	var modelID *uuid.UUID
 	if modelIDStr != "" {
 		parsed, err := uuid.Parse(modelIDStr)
@@ -84,65 +128,75 @@ func (h *UsageHandler) GetOrgUsage(w http.ResponseWriter, r *http.Request) {
 		modelID = &parsed
 	}
 
-	// Query usage series
-	points, err := h.store.GetUsageSeries(ctx, orgID, start, end, granularity, modelID)
+	columns, err := callerColumns(r, h.defaultProfile)
 	if err != nil {
-		h.logger.Error("failed to get usage series", zap.Error(err))
-		h.respondError(w, http.StatusInternalServerError, "failed to retrieve usage data", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to resolve export profile", err)
 		return
 	}
-
-	// Query totals
-	totals, err := h.store.GetUsageTotals(ctx, orgID, start, end, modelID)
-	if err != nil {
-		h.logger.Error("failed to get usage totals", zap.Error(err))
-		h.respondError(w, http.StatusInternalServerError, "failed to retrieve totals", err)
+	if groupBy == "project" && !columns.Has(exports.ColumnMetadata) {
+		h.respondError(w, http.StatusForbidden, "export profile does not allow group_by=project", nil)
 		return
 	}
 
-	// Get freshness indicator from cache or database
-	var freshnessIndicator FreshnessIndicator
-	if cached, err := h.freshnessCache.Get(ctx, orgID, modelID); err == nil && cached != nil {
-		freshnessIndicator = FreshnessIndicator{
-			Status:       cached.Status,
-			LagSeconds:   cached.LagSeconds,
-			LastEventAt:  cached.LastEventAt,
-			LastRollupAt: cached.LastRollupAt,
-		}
+	// Get freshness indicator from cache or database; it also sets the result TTL
+	freshnessIndicator := resolveFreshness(ctx, h.freshnessCache, h.store, orgID, modelID)
+	setFreshnessHeaders(w, freshnessIndicator)
+
+	query := querycache.UsageQuery{
+		Start:       start,
+		End:         end,
+		Granularity: granularity,
+		GroupBy:     groupBy,
+		ModelID:     modelID,
+	}.String()
+	var result cachedUsage
+	if h.queryCache.Get(ctx, orgID, query, &result) {
+		w.Header().Set(HeaderQueryCache, "hit")
 	} else {
-		// Fallback to database if cache miss
-		if dbFreshness, err := h.store.GetFreshnessStatus(ctx, orgID, modelID); err == nil {
-			freshnessIndicator = FreshnessIndicator{
-				Status:       dbFreshness.Status,
-				LagSeconds:   dbFreshness.LagSeconds,
-				LastEventAt:  dbFreshness.LastEventAt,
-				LastRollupAt: dbFreshness.LastRollupAt,
-			}
-			// Cache it for next time
-			_ = h.freshnessCache.Set(ctx, dbFreshness)
+		w.Header().Set(HeaderQueryCache, "miss")
+
+		// Query usage series
+		var points []postgres.UsagePoint
+		if groupBy == "project" {
+			points, err = h.store.GetUsageSeriesByProject(ctx, orgID, start, end, granularity, modelID)
 		} else {
-			// Default if not found
-			freshnessIndicator = FreshnessIndicator{
-				Status:       "fresh",
-				LagSeconds:   0,
-				LastEventAt:  time.Now().Add(-5 * time.Minute),
-				LastRollupAt: time.Now().Add(-1 * time.Minute),
-			}
+			points, err = h.store.GetUsageSeries(ctx, orgID, start, end, granularity, modelID)
+		}
+		if err != nil {
+			h.logger.Error("failed to get usage series", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "failed to retrieve usage data", err)
+			return
+		}
+
+		// Query totals
+		totals, err := h.store.GetUsageTotals(ctx, orgID, start, end, modelID)
+		if err != nil {
+			h.logger.Error("failed to get usage totals", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "failed to retrieve totals", err)
+			return
 		}
+
+		result = cachedUsage{
+			Series: convertPoints(points),
+			Totals: UsageTotalsResponse{
+				Invocations:       totals.Invocations,
+				InputTokens:       totals.InputTokens,
+				OutputTokens:      totals.OutputTokens,
+				CostEstimateCents: cents(totals.CostEstimateCents),
+			},
+		}
+		h.queryCache.Set(ctx, orgID, query, result, h.queryCache.TTLFor(end, freshnessIndicator.LastRollupAt))
 	}
+	result.redact(columns)
 
 	// Build response
 	response := UsageSeriesResponse{
 		OrgID:       orgID.String(),
 		Granularity: granularity,
-		Series:      convertPoints(points),
-		Totals: UsageTotalsResponse{
-			Invocations:       totals.Invocations,
-			InputTokens:       totals.InputTokens,
-			OutputTokens:      totals.OutputTokens,
-			CostEstimateCents: int64(totals.CostEstimateCents * 100), // Convert to cents
-		},
-		Freshness: freshnessIndicator,
+		GroupBy:     groupBy,
+		Series:      result.Series,
+		Totals:      result.Totals,
+		Freshness:   freshnessIndicator,
 	}
 
 	h.respondJSON(w, http.StatusOK, response)
@@ -152,6 +206,7 @@ func (h *UsageHandler) GetOrgUsage(w http.ResponseWriter, r *http.Request) {
 type UsageSeriesResponse struct {
 	OrgID       string                `json:"orgId"`
 	Granularity string                `json:"granularity"`
+	GroupBy     string                `json:"groupBy,omitempty"`
 	Series      []UsagePointResponse  `json:"series"`
 	Totals      UsageTotalsResponse   `json:"totals"`
 	Freshness   FreshnessIndicator    `json:"freshness"`
@@ -161,18 +216,19 @@ type UsageSeriesResponse struct {
 type UsagePointResponse struct {
 	BucketStart       string  `json:"bucketStart"`
 	ModelID           *string `json:"modelId,omitempty"`
+	ProjectID         *string `json:"projectId,omitempty"`
 	Invocations       int64   `json:"invocations"`
 	InputTokens       int64   `json:"inputTokens,omitempty"`
 	OutputTokens      int64   `json:"outputTokens,omitempty"`
-	CostEstimateCents int64   `json:"costEstimateCents"`
+	CostEstimateCents *int64  `json:"costEstimateCents,omitempty"` // Omitted when the caller's profile excludes cost
 }
 
 // UsageTotalsResponse matches the OpenAPI schema.
 type UsageTotalsResponse struct {
-	Invocations       int64 `json:"invocations"`
-	InputTokens       int64 `json:"inputTokens,omitempty"`
-	OutputTokens      int64 `json:"outputTokens,omitempty"`
-	CostEstimateCents int64 `json:"costEstimateCents"`
+	Invocations       int64  `json:"invocations"`
+	InputTokens       int64  `json:"inputTokens,omitempty"`
+	OutputTokens      int64  `json:"outputTokens,omitempty"`
+	CostEstimateCents *int64 `json:"costEstimateCents,omitempty"` // Omitted when the caller's profile excludes cost
 }
 
 // FreshnessIndicator represents freshness status.
@@ -191,17 +247,24 @@ func convertPoints(points []postgres.UsagePoint) []UsagePointResponse {
 			Invocations:       p.Invocations,
 			InputTokens:       p.InputTokens,
 			OutputTokens:      p.OutputTokens,
-			CostEstimateCents: int64(p.CostEstimateCents * 100), // Convert to cents
+			CostEstimateCents: cents(p.CostEstimateCents),
 		}
 		if p.ModelID != nil {
 			id := p.ModelID.String()
 			r.ModelID = &id
 		}
+		r.ProjectID = p.ProjectID
 		result[i] = r
 	}
 	return result
 }
 
+// cents converts a dollar amount from the store to cents.
+func cents(dollars float64) *int64 {
+	c := int64(dollars * 100)
+	return &c
+}
+
 func (h *UsageHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -214,10 +277,6 @@ func (h *UsageHandler) respondError(w http.ResponseWriter, status int, message s
 	h.logger.Warn(message, zap.Error(err), zap.Int("status", status))
 	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": status,
-		"title":  http.StatusText(status),
-		"detail": message,
-	})
+	json.NewEncoder(w).Encode(problem(w, status, message))
 }
 
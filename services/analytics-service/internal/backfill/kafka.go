@@ -0,0 +1,89 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSource reads one partition of a Kafka topic over [StartOffset, EndOffset).
+type KafkaSource struct {
+	Brokers   []string
+	Topic     string
+	Partition int
+	// StartOffset is the first offset to read; negative starts at the oldest
+	// retained message.
+	StartOffset int64
+	// EndOffset is exclusive; negative reads up to the partition's current end.
+	EndOffset int64
+}
+
+// Read implements Source.
+func (s *KafkaSource) Read(ctx context.Context, fn func(payload []byte, offset int64) error) error {
+	if len(s.Brokers) == 0 || s.Topic == "" {
+		return fmt.Errorf("kafka brokers and topic are required")
+	}
+
+	start, end, err := s.resolveOffsets(ctx)
+	if err != nil {
+		return err
+	}
+	if start >= end {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   s.Brokers,
+		Topic:     s.Topic,
+		Partition: s.Partition,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(start); err != nil {
+		return fmt.Errorf("seek to offset %d: %w", start, err)
+	}
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("read kafka message: %w", err)
+		}
+		if msg.Offset >= end {
+			return nil
+		}
+		if err := fn(msg.Value, msg.Offset); err != nil {
+			return err
+		}
+		if msg.Offset+1 >= end {
+			return nil
+		}
+	}
+}
+
+// resolveOffsets replaces negative bounds with the partition's retained range.
+func (s *KafkaSource) resolveOffsets(ctx context.Context) (int64, int64, error) {
+	start, end := s.StartOffset, s.EndOffset
+	if start >= 0 && end >= 0 {
+		return start, end, nil
+	}
+
+	conn, err := kafka.DialLeader(ctx, "tcp", s.Brokers[0], s.Topic, s.Partition)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dial partition leader: %w", err)
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, fmt.Errorf("read partition offsets: %w", err)
+	}
+	if start < 0 {
+		start = first
+	}
+	if end < 0 {
+		end = last
+	}
+	return start, end, nil
+}
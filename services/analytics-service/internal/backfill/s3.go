@@ -0,0 +1,87 @@
+package backfill
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// maxLineBytes bounds a single JSONL record.
+const maxLineBytes = 1 << 20
+
+// S3Source reads JSONL usage archives (optionally gzip-compressed, by ".gz"
+// suffix) under a bucket prefix, in key order.
+type S3Source struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Read implements Source. Offsets are line numbers counted across all objects.
+func (s *S3Source) Read(ctx context.Context, fn func(payload []byte, offset int64) error) error {
+	var line int64
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.Prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list s3://%s/%s: %w", s.Bucket, s.Prefix, err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			if err := s.readObject(ctx, key, &line, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *S3Source) readObject(ctx context.Context, key string, line *int64, fn func([]byte, int64) error) error {
+	out, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("get s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	var body io.Reader = out.Body
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(out.Body)
+		if err != nil {
+			return fmt.Errorf("open gzip %s: %w", key, err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		*line++
+		payload := scanner.Bytes()
+		if len(strings.TrimSpace(string(payload))) == 0 {
+			continue
+		}
+		if err := fn(payload, *line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", key, err)
+	}
+	return nil
+}
@@ -5,6 +5,12 @@ import (
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+
+	"github.com/ai-aas/shared-go/dataaccess/pgpool"
+	"github.com/ai-aas/shared-go/secrets"
+	"github.com/ai-aas/shared-go/usagerecord"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
 )
 
 // Config holds all configuration for the analytics service.
@@ -16,8 +22,22 @@ type Config struct {
 	// HTTP server
 	HTTPPort int `envconfig:"HTTP_PORT" default:"8084"`
 
+	// API versioning: JSON array of deprecated routes announced with
+	// Deprecation/Sunset headers, see apiversion.ParseDeprecations
+	APIDeprecations string `envconfig:"API_DEPRECATIONS" default:""`
+
 	// Database
-	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseURL           string        `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseReplicaURLs   []string      `envconfig:"DATABASE_REPLICA_URLS"`                  // Optional read replicas for query endpoints
+	DatabaseReplicaMaxLag time.Duration `envconfig:"DATABASE_REPLICA_MAX_LAG" default:"30s"` // Fall back to primary above this lag
+
+	// Database pool tuning (applies to the primary and each replica pool)
+	DatabaseMaxConns         int32         `envconfig:"DATABASE_MAX_CONNS" default:"20"`
+	DatabaseMinConns         int32         `envconfig:"DATABASE_MIN_CONNS" default:"2"`
+	DatabaseMaxConnLifetime  time.Duration `envconfig:"DATABASE_MAX_CONN_LIFETIME" default:"30m"`
+	DatabaseMaxConnIdleTime  time.Duration `envconfig:"DATABASE_MAX_CONN_IDLE_TIME" default:"5m"`
+	DatabaseStatementTimeout time.Duration `envconfig:"DATABASE_STATEMENT_TIMEOUT" default:"2m"` // Server-side cap; covers rollup jobs
+	DatabaseQueryTimeout     time.Duration `envconfig:"DATABASE_QUERY_TIMEOUT" default:"30s"`    // Per store call, including pool waits
 
 	// Redis
 	RedisURL string `envconfig:"REDIS_URL" default:"redis://localhost:6379"`
@@ -41,9 +61,11 @@ type Config struct {
 	LogLevel          string            `envconfig:"LOG_LEVEL" default:"info"`
 
 	// Ingestion
-	IngestionBatchSize    int           `envconfig:"INGESTION_BATCH_SIZE" default:"1000"`
-	IngestionBatchTimeout time.Duration `envconfig:"INGESTION_BATCH_TIMEOUT" default:"5s"`
-	IngestionWorkers      int           `envconfig:"INGESTION_WORKERS" default:"4"`
+	IngestionBatchSize     int           `envconfig:"INGESTION_BATCH_SIZE" default:"1000"`
+	IngestionBatchTimeout  time.Duration `envconfig:"INGESTION_BATCH_TIMEOUT" default:"5s"`
+	IngestionWorkers       int           `envconfig:"INGESTION_WORKERS" default:"4"`
+	IngestionCopyThreshold int           `envconfig:"INGESTION_COPY_THRESHOLD" default:"100"` // Batches at least this large use COPY; 0 disables
+	IngestionReadMode      string        `envconfig:"INGESTION_READ_MODE" default:"dual"`     // Usage record encodings accepted: dual, json or protobuf
 
 	// Aggregation
 	AggregationWorkers int           `envconfig:"AGGREGATION_WORKERS" default:"2"`
@@ -52,13 +74,52 @@ type Config struct {
 	// Freshness
 	FreshnessCacheTTL time.Duration `envconfig:"FRESHNESS_CACHE_TTL" default:"5m"`
 
+	// Top-N breakdowns
+	TopNRawMaxWindow time.Duration `envconfig:"TOP_N_RAW_MAX_WINDOW" default:"24h"` // Longest range for source=raw drill-down; 0 disables
+
+	// Query result cache (Redis); invalidated per org when rollups rewrite its buckets
+	QueryCacheEnabled   bool          `envconfig:"QUERY_CACHE_ENABLED" default:"true"`
+	QueryCacheTTL       time.Duration `envconfig:"QUERY_CACHE_TTL" default:"1h"`         // Ranges fully covered by rollups
+	QueryCacheRecentTTL time.Duration `envconfig:"QUERY_CACHE_RECENT_TTL" default:"30s"` // Ranges reaching past the last rollup
+
 	// Export Worker
 	ExportWorkerInterval   time.Duration `envconfig:"EXPORT_WORKER_INTERVAL" default:"30s"`
 	ExportWorkerConcurrency int          `envconfig:"EXPORT_WORKER_CONCURRENCY" default:"2"`
 	ExportSignedURLTTL     time.Duration `envconfig:"EXPORT_SIGNED_URL_TTL" default:"24h"`
+	ExportDefaultProfile   string        `envconfig:"EXPORT_DEFAULT_PROFILE" default:"full"` // Column set for callers without a profile role
+	ExportCleanupInterval  time.Duration `envconfig:"EXPORT_CLEANUP_INTERVAL" default:"1h"` // Removes files of deleted/cancelled jobs; 0 disables
+	ExportUploadPartSize   int64         `envconfig:"EXPORT_UPLOAD_PART_SIZE" default:"8388608"` // Multipart part size in bytes; min 5 MiB
 
 	// Security
 	EnableRBAC bool `envconfig:"ENABLE_RBAC" default:"true"`
+
+	// Platform access tokens: with a client ID, callers authenticate with
+	// user-org-service bearer tokens (checked via its introspection endpoint)
+	// and their org roles map to analytics permissions; without one, roles
+	// come from X-Actor-* headers set by a trusted gateway
+	UserOrgServiceURL         string        `envconfig:"USER_ORG_SERVICE_URL" default:"http://localhost:8081"`
+	UserOrgServiceTimeout     time.Duration `envconfig:"USER_ORG_SERVICE_TIMEOUT" default:"2s"`
+	PlatformTokenClientID     string        `envconfig:"PLATFORM_TOKEN_CLIENT_ID" default:""`
+	PlatformTokenClientSecret string        `envconfig:"PLATFORM_TOKEN_CLIENT_SECRET" default:""`
+	PlatformTokenIssuer       string        `envconfig:"PLATFORM_TOKEN_ISSUER" default:""`   // Required iss; empty accepts any
+	PlatformTokenAudience     []string      `envconfig:"PLATFORM_TOKEN_AUDIENCE" default:""` // Accepted aud values; empty accepts any
+	PlatformTokenClockSkew    time.Duration `envconfig:"PLATFORM_TOKEN_CLOCK_SKEW" default:"30s"`
+	PlatformTokenCacheTTL     time.Duration `envconfig:"PLATFORM_TOKEN_CACHE_TTL" default:"1m"`
+	PlatformAdminScope        string        `envconfig:"PLATFORM_ADMIN_SCOPE" default:"admin"` // Token scope granting cross-org access
+
+	// Server lifecycle
+	DebugEndpointsEnabled bool          `envconfig:"DEBUG_ENDPOINTS_ENABLED" default:"false"`
+	ShutdownDrainDelay    time.Duration `envconfig:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+	PreStopAddr           string        `envconfig:"PRESTOP_ADDR" default:""` // e.g. 127.0.0.1:8081 for an exec preStop hook; empty disables it
+
+	// Credential rotation: database and Redis credentials are reloaded from files
+	// or Vault on SIGHUP, file changes and every interval; they override the URLs'
+	CredentialsDir            string        `envconfig:"CREDENTIALS_DIR"` // One file per key, e.g. database-password
+	VaultAddr                 string        `envconfig:"VAULT_ADDR"`
+	VaultSecretPath           string        `envconfig:"VAULT_SECRET_PATH"` // e.g. secret/data/analytics-service
+	VaultToken                string        `envconfig:"VAULT_TOKEN"`
+	VaultTokenFile            string        `envconfig:"VAULT_TOKEN_FILE"` // Re-read on each reload (Vault Agent sink)
+	CredentialsReloadInterval time.Duration `envconfig:"CREDENTIALS_RELOAD_INTERVAL" default:"5m"`
 }
 
 // Load loads configuration from environment variables.
@@ -90,18 +151,59 @@ func (c *Config) Validate() error {
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
+	if c.DatabaseMaxConns <= 0 {
+		return fmt.Errorf("DATABASE_MAX_CONNS must be positive, got %d", c.DatabaseMaxConns)
+	}
+	if c.DatabaseMinConns < 0 || c.DatabaseMinConns > c.DatabaseMaxConns {
+		return fmt.Errorf("DATABASE_MIN_CONNS must be between 0 and DATABASE_MAX_CONNS, got %d", c.DatabaseMinConns)
+	}
 	if c.IngestionBatchSize <= 0 {
 		return fmt.Errorf("INGESTION_BATCH_SIZE must be positive, got %d", c.IngestionBatchSize)
 	}
 	if c.IngestionWorkers <= 0 {
 		return fmt.Errorf("INGESTION_WORKERS must be positive, got %d", c.IngestionWorkers)
 	}
+	if _, err := usagerecord.ParseReadMode(c.IngestionReadMode); err != nil {
+		return fmt.Errorf("INGESTION_READ_MODE: %w", err)
+	}
 	if c.AggregationWorkers <= 0 {
 		return fmt.Errorf("AGGREGATION_WORKERS must be positive, got %d", c.AggregationWorkers)
 	}
 	if c.ExportWorkerConcurrency <= 0 {
 		return fmt.Errorf("EXPORT_WORKER_CONCURRENCY must be positive, got %d", c.ExportWorkerConcurrency)
 	}
+	if !exports.ValidProfile(c.ExportDefaultProfile) {
+		return fmt.Errorf("EXPORT_DEFAULT_PROFILE must be full, finance or engineering, got %q", c.ExportDefaultProfile)
+	}
+	if c.VaultAddr != "" && c.VaultSecretPath == "" {
+		return fmt.Errorf("VAULT_ADDR requires VAULT_SECRET_PATH")
+	}
+	if c.PlatformTokenClientID != "" && c.UserOrgServiceURL == "" {
+		return fmt.Errorf("PLATFORM_TOKEN_CLIENT_ID requires USER_ORG_SERVICE_URL")
+	}
 	return nil
 }
 
+// PoolConfig returns the Postgres pool tuning settings.
+func (c *Config) PoolConfig() pgpool.Config {
+	return pgpool.Config{
+		MaxConns:         c.DatabaseMaxConns,
+		MinConns:         c.DatabaseMinConns,
+		MaxConnLifetime:  c.DatabaseMaxConnLifetime,
+		MaxConnIdleTime:  c.DatabaseMaxConnIdleTime,
+		StatementTimeout: c.DatabaseStatementTimeout,
+		QueryTimeout:     c.DatabaseQueryTimeout,
+	}
+}
+
+// CredentialSource returns where rotated credentials are read from, or nil
+// when they come only from DATABASE_URL and REDIS_URL.
+func (c *Config) CredentialSource() secrets.Source {
+	return secrets.Config{
+		Dir:            c.CredentialsDir,
+		VaultAddr:      c.VaultAddr,
+		VaultPath:      c.VaultSecretPath,
+		VaultToken:     c.VaultToken,
+		VaultTokenFile: c.VaultTokenFile,
+	}.Source()
+}
@@ -3,10 +3,12 @@ package exports
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -28,13 +30,18 @@ type ExportJob struct {
 	TimeRangeStart time.Time
 	TimeRangeEnd   time.Time
 	Granularity    string // "hourly", "daily", "monthly"
-	Status         string // "pending", "running", "succeeded", "failed", "expired"
+	Status         string // "pending", "running", "succeeded", "failed", "expired", "cancelled"
 	OutputURI      *string
 	Checksum       *string
 	RowCount       *int64
 	InitiatedAt    time.Time
 	CompletedAt    *time.Time
 	ErrorMessage   *string
+	RowsWritten    int64      // Progress while running (export_jobs.rows_written)
+	URLExpiresAt   *time.Time // When OutputURI stops working (export_jobs.url_expires_at); nil if unknown
+	// Columns is the CSV column set the job may write (export_jobs.columns,
+	// TEXT[]). Jobs created before export profiles have none and write all.
+	Columns []string
 }
 
 // CreateExportJobRequest specifies parameters for creating a new export job.
@@ -43,15 +50,16 @@ type CreateExportJobRequest struct {
 	RequestedBy    uuid.UUID
 	TimeRangeStart time.Time
 	TimeRangeEnd   time.Time
-	Granularity    string // "hourly", "daily", "monthly"
+	Granularity    string   // "hourly", "daily", "monthly"
+	Columns        []string // CSV columns allowed by the caller's export profile
 }
 
 // CreateExportJob creates a new export job with status "pending".
 func (r *ExportJobRepository) CreateExportJob(ctx context.Context, req CreateExportJobRequest) (uuid.UUID, error) {
 	query := `
 		INSERT INTO analytics.export_jobs (
-			org_id, requested_by, time_range_start, time_range_end, granularity, status
-		) VALUES ($1, $2, $3, $4, $5, 'pending')
+			org_id, requested_by, time_range_start, time_range_end, granularity, columns, status
+		) VALUES ($1, $2, $3, $4, $5, $6, 'pending')
 		RETURNING job_id
 	`
 
@@ -62,6 +70,7 @@ func (r *ExportJobRepository) CreateExportJob(ctx context.Context, req CreateExp
 		req.TimeRangeStart,
 		req.TimeRangeEnd,
 		req.Granularity,
+		req.Columns,
 	).Scan(&jobID)
 
 	if err != nil {
@@ -77,7 +86,8 @@ func (r *ExportJobRepository) GetExportJob(ctx context.Context, orgID, jobID uui
 		SELECT 
 			job_id, org_id, requested_by, time_range_start, time_range_end,
 			granularity, status, output_uri, checksum, row_count,
-			initiated_at, completed_at, error_message
+			initiated_at, completed_at, error_message, columns,
+			COALESCE(rows_written, 0), url_expires_at
 		FROM analytics.export_jobs
 		WHERE job_id = $1 AND org_id = $2
 	`
@@ -101,6 +111,9 @@ func (r *ExportJobRepository) GetExportJob(ctx context.Context, orgID, jobID uui
 		&job.InitiatedAt,
 		&completedAt,
 		&errorMessage,
+		&job.Columns,
+		&job.RowsWritten,
+		&job.URLExpiresAt,
 	)
 
 	if err != nil {
@@ -122,7 +135,8 @@ func (r *ExportJobRepository) ListExportJobs(ctx context.Context, orgID uuid.UUI
 		SELECT 
 			job_id, org_id, requested_by, time_range_start, time_range_end,
 			granularity, status, output_uri, checksum, row_count,
-			initiated_at, completed_at, error_message
+			initiated_at, completed_at, error_message, columns,
+			COALESCE(rows_written, 0), url_expires_at
 		FROM analytics.export_jobs
 		WHERE org_id = $1
 	`
@@ -165,6 +179,9 @@ func (r *ExportJobRepository) ListExportJobs(ctx context.Context, orgID uuid.UUI
 			&job.InitiatedAt,
 			&completedAt,
 			&errorMessage,
+			&job.Columns,
+			&job.RowsWritten,
+			&job.URLExpiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan export job: %w", err)
@@ -198,15 +215,58 @@ func (r *ExportJobRepository) UpdateExportJobStatus(ctx context.Context, jobID u
 	return nil
 }
 
+// StartExportJob marks a pending (or already claimed) job as running and
+// resets its progress. It reports false if the job was cancelled or deleted
+// in the meantime.
+func (r *ExportJobRepository) StartExportJob(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE analytics.export_jobs
+		SET status = 'running', rows_written = 0
+		WHERE job_id = $1 AND status IN ('pending', 'running')
+	`
+
+	tag, err := r.pool.Exec(ctx, query, jobID)
+	if err != nil {
+		return false, fmt.Errorf("start export job: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// SetExportJobProgress records the rows written so far and returns the job's
+// current status, so the runner can stop when the job was cancelled. A
+// deleted job reports "deleted".
+func (r *ExportJobRepository) SetExportJobProgress(ctx context.Context, jobID uuid.UUID, rowsWritten int64) (string, error) {
+	query := `
+		UPDATE analytics.export_jobs
+		SET rows_written = $1
+		WHERE job_id = $2
+		RETURNING status
+	`
+
+	var status string
+	err := r.pool.QueryRow(ctx, query, rowsWritten, jobID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "deleted", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("set export job progress: %w", err)
+	}
+
+	return status, nil
+}
+
 // SetExportJobOutput sets the output URI, checksum, and row count for a completed export job.
-func (r *ExportJobRepository) SetExportJobOutput(ctx context.Context, jobID uuid.UUID, outputURI, checksum string, rowCount int64) error {
+// Jobs cancelled while running are left cancelled.
+func (r *ExportJobRepository) SetExportJobOutput(ctx context.Context, jobID uuid.UUID, outputURI, checksum string, rowCount int64, urlExpiresAt time.Time) error {
 	query := `
 		UPDATE analytics.export_jobs
-		SET output_uri = $1, checksum = $2, row_count = $3, completed_at = NOW(), status = 'succeeded'
-		WHERE job_id = $4
+		SET output_uri = $1, checksum = $2, row_count = $3, rows_written = $3, url_expires_at = $4,
+			completed_at = NOW(), status = 'succeeded'
+		WHERE job_id = $5 AND status = 'running'
 	`
 
-	_, err := r.pool.Exec(ctx, query, outputURI, checksum, rowCount, jobID)
+	_, err := r.pool.Exec(ctx, query, outputURI, checksum, rowCount, urlExpiresAt, jobID)
 	if err != nil {
 		return fmt.Errorf("set export job output: %w", err)
 	}
@@ -215,11 +275,12 @@ func (r *ExportJobRepository) SetExportJobOutput(ctx context.Context, jobID uuid
 }
 
 // SetExportJobError marks an export job as failed with an error message.
+// Jobs cancelled while running are left cancelled.
 func (r *ExportJobRepository) SetExportJobError(ctx context.Context, jobID uuid.UUID, errorMessage string) error {
 	query := `
 		UPDATE analytics.export_jobs
 		SET status = 'failed', error_message = $1, completed_at = NOW()
-		WHERE job_id = $2
+		WHERE job_id = $2 AND status IN ('pending', 'running')
 	`
 
 	_, err := r.pool.Exec(ctx, query, errorMessage, jobID)
@@ -236,7 +297,8 @@ func (r *ExportJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]
 		SELECT 
 			job_id, org_id, requested_by, time_range_start, time_range_end,
 			granularity, status, output_uri, checksum, row_count,
-			initiated_at, completed_at, error_message
+			initiated_at, completed_at, error_message, columns,
+			COALESCE(rows_written, 0), url_expires_at
 		FROM analytics.export_jobs
 		WHERE status = 'pending'
 		ORDER BY initiated_at ASC
@@ -271,6 +333,9 @@ func (r *ExportJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]
 			&job.InitiatedAt,
 			&completedAt,
 			&errorMessage,
+			&job.Columns,
+			&job.RowsWritten,
+			&job.URLExpiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan export job: %w", err)
@@ -288,3 +353,133 @@ func (r *ExportJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]
 	return jobs, rows.Err()
 }
 
+// SetExportJobURL stores a regenerated signed URL.
+func (r *ExportJobRepository) SetExportJobURL(ctx context.Context, jobID uuid.UUID, outputURI string, urlExpiresAt time.Time) error {
+	query := `
+		UPDATE analytics.export_jobs
+		SET output_uri = $1, url_expires_at = $2
+		WHERE job_id = $3 AND status = 'succeeded'
+	`
+
+	_, err := r.pool.Exec(ctx, query, outputURI, urlExpiresAt, jobID)
+	if err != nil {
+		return fmt.Errorf("set export job URL: %w", err)
+	}
+
+	return nil
+}
+
+// CancelExportJob cancels a pending or running job. It reports false if the
+// job is not in a cancellable state. A running job stops at its next progress
+// update.
+func (r *ExportJobRepository) CancelExportJob(ctx context.Context, orgID, jobID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE analytics.export_jobs
+		SET status = 'cancelled', completed_at = NOW()
+		WHERE job_id = $1 AND org_id = $2 AND status IN ('pending', 'running')
+	`
+
+	tag, err := r.pool.Exec(ctx, query, jobID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("cancel export job: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RetryExportJob re-queues a failed, cancelled or expired job, clearing its
+// previous output. It reports false if the job is not in a retryable state.
+// A failed job keeps its upload checkpoint so the upload resumes; other jobs
+// start over.
+func (r *ExportJobRepository) RetryExportJob(ctx context.Context, orgID, jobID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE analytics.export_jobs
+		SET status = 'pending', error_message = NULL, output_uri = NULL, checksum = NULL,
+			row_count = NULL, rows_written = 0, url_expires_at = NULL, completed_at = NULL,
+			upload_checkpoint = CASE WHEN status = 'failed' THEN upload_checkpoint END
+		WHERE job_id = $1 AND org_id = $2 AND status IN ('failed', 'cancelled', 'expired')
+	`
+
+	tag, err := r.pool.Exec(ctx, query, jobID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("retry export job: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteExportJob deletes a job record. Its exported file is removed by the
+// runner's orphan cleanup.
+func (r *ExportJobRepository) DeleteExportJob(ctx context.Context, orgID, jobID uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM analytics.export_jobs
+		WHERE job_id = $1 AND org_id = $2
+	`
+
+	tag, err := r.pool.Exec(ctx, query, jobID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("delete export job: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// LiveJobIDs returns which of jobIDs still exist and may have an output file,
+// i.e. are not cancelled.
+func (r *ExportJobRepository) LiveJobIDs(ctx context.Context, jobIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	query := `
+		SELECT job_id
+		FROM analytics.export_jobs
+		WHERE job_id = ANY($1) AND status <> 'cancelled'
+	`
+
+	rows, err := r.pool.Query(ctx, query, jobIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query live export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	live := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var jobID uuid.UUID
+		if err := rows.Scan(&jobID); err != nil {
+			return nil, fmt.Errorf("scan export job id: %w", err)
+		}
+		live[jobID] = true
+	}
+
+	return live, rows.Err()
+}
+
+// GetUploadCheckpoint returns the job's multipart upload checkpoint, or nil
+// if it has none.
+func (r *ExportJobRepository) GetUploadCheckpoint(ctx context.Context, jobID uuid.UUID) (*UploadCheckpoint, error) {
+	query := `
+		SELECT upload_checkpoint
+		FROM analytics.export_jobs
+		WHERE job_id = $1
+	`
+
+	var checkpoint *UploadCheckpoint
+	if err := r.pool.QueryRow(ctx, query, jobID).Scan(&checkpoint); err != nil {
+		return nil, fmt.Errorf("get upload checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// SetUploadCheckpoint stores the job's multipart upload checkpoint; nil clears it.
+func (r *ExportJobRepository) SetUploadCheckpoint(ctx context.Context, jobID uuid.UUID, checkpoint *UploadCheckpoint) error {
+	query := `
+		UPDATE analytics.export_jobs
+		SET upload_checkpoint = $1
+		WHERE job_id = $2
+	`
+
+	_, err := r.pool.Exec(ctx, query, checkpoint, jobID)
+	if err != nil {
+		return fmt.Errorf("set upload checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -2,27 +2,41 @@
 package exports
 
 import (
-	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aws/smithy-go"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// progressEvery is how many rows are written between progress updates, which
+// double as cancellation checks.
+const progressEvery = 1000
+
+// orphanGrace keeps recently written objects out of orphan cleanup, so a job
+// retried while the sweep runs does not lose its new file.
+const orphanGrace = 10 * time.Minute
+
+// errJobCancelled stops a job that was cancelled or deleted while running.
+var errJobCancelled = errors.New("export job cancelled")
+
 // JobRunner processes export jobs and generates CSVs from rollup tables.
 type JobRunner struct {
-	repo       *ExportJobRepository
-	pool       *pgxpool.Pool
-	s3Delivery *S3Delivery
-	logger     *zap.Logger
-	interval   time.Duration
-	workers    int
-	stopCh     chan struct{}
-	doneCh     chan struct{}
+	repo            *ExportJobRepository
+	pool            *pgxpool.Pool
+	s3Delivery      *S3Delivery
+	logger          *zap.Logger
+	interval        time.Duration
+	workers         int
+	cleanupInterval time.Duration
+	partSize        int64
+	stopCh          chan struct{}
+	doneCh          chan struct{}
 }
 
 // RunnerConfig holds job runner configuration.
@@ -32,20 +46,32 @@ type RunnerConfig struct {
 	Logger     *zap.Logger
 	Interval   time.Duration
 	Workers    int
+	// CleanupInterval is how often objects of deleted or cancelled jobs are
+	// removed from the bucket; 0 disables cleanup.
+	CleanupInterval time.Duration
+	// PartSize is the multipart upload part size in bytes (default
+	// DefaultPartSize, at least MinPartSize).
+	PartSize int64
 }
 
 // NewJobRunner creates a new export job runner.
 func NewJobRunner(cfg RunnerConfig) *JobRunner {
 	repo := NewExportJobRepository(cfg.Pool)
+	partSize := cfg.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
 	return &JobRunner{
-		repo:       repo,
-		pool:       cfg.Pool,
-		s3Delivery: cfg.S3Delivery,
-		logger:     cfg.Logger,
-		interval:   cfg.Interval,
-		workers:    cfg.Workers,
-		stopCh:     make(chan struct{}),
-		doneCh:     make(chan struct{}),
+		repo:            repo,
+		pool:            cfg.Pool,
+		s3Delivery:      cfg.S3Delivery,
+		logger:          cfg.Logger,
+		interval:        cfg.Interval,
+		workers:         cfg.Workers,
+		cleanupInterval: cfg.CleanupInterval,
+		partSize:        partSize,
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
 	}
 }
 
@@ -60,14 +86,19 @@ func (r *JobRunner) Start(ctx context.Context) error {
 	defer ticker.Stop()
 
 	// Start worker goroutines
-	workerDone := make(chan struct{}, r.workers)
+	running := r.workers
+	workerDone := make(chan struct{}, r.workers+1)
 	for i := 0; i < r.workers; i++ {
 		go r.worker(ctx, i, workerDone)
 	}
+	if r.cleanupInterval > 0 {
+		running++
+		go r.cleanupLoop(ctx, workerDone)
+	}
 
 	// Wait for all workers to finish
 	go func() {
-		for i := 0; i < r.workers; i++ {
+		for i := 0; i < running; i++ {
 			<-workerDone
 		}
 		close(r.doneCh)
@@ -123,6 +154,13 @@ func (r *JobRunner) worker(ctx context.Context, id int, done chan struct{}) {
 			// Process each job
 			for _, job := range jobs {
 				if err := r.ProcessJob(ctx, job); err != nil {
+					if errors.Is(err, errJobCancelled) {
+						r.logger.Info("export job cancelled while running",
+							zap.String("job_id", job.JobID.String()),
+							zap.Int("worker_id", id),
+						)
+						continue
+					}
 					r.logger.Error("failed to process export job",
 						zap.String("job_id", job.JobID.String()),
 						zap.Error(err),
@@ -143,11 +181,17 @@ func (r *JobRunner) worker(ctx context.Context, id int, done chan struct{}) {
 
 // ProcessJob processes a single export job.
 // This method is public to allow testing and manual job processing.
+// It returns errJobCancelled if the job is cancelled or deleted before it
+// completes.
 func (r *JobRunner) ProcessJob(ctx context.Context, job ExportJob) error {
 	// Mark job as running
-	if err := r.repo.UpdateExportJobStatus(ctx, job.JobID, "running"); err != nil {
+	started, err := r.repo.StartExportJob(ctx, job.JobID)
+	if err != nil {
 		return fmt.Errorf("update job status to running: %w", err)
 	}
+	if !started {
+		return errJobCancelled
+	}
 
 	r.logger.Info("processing export job",
 		zap.String("job_id", job.JobID.String()),
@@ -157,20 +201,47 @@ func (r *JobRunner) ProcessJob(ctx context.Context, job ExportJob) error {
 		zap.Time("end", job.TimeRangeEnd),
 	)
 
-	// Generate CSV from rollup tables
-	csvData, rowCount, err := r.generateCSV(ctx, job)
+	// Start or resume the multipart upload
+	checkpoint, err := r.repo.GetUploadCheckpoint(ctx, job.JobID)
+	if err != nil {
+		return err
+	}
+	upload, err := r.s3Delivery.StartMultipart(ctx, job.OrgID, job.JobID, r.partSize, checkpoint)
 	if err != nil {
-		return fmt.Errorf("generate CSV: %w", err)
+		return fmt.Errorf("start upload: %w", err)
+	}
+	if checkpoint == nil {
+		// Record the upload right away so a crash can still resume or abort it
+		if err := r.saveCheckpoint(ctx, job.JobID, upload); err != nil {
+			return err
+		}
+	} else {
+		r.logger.Info("resuming export upload",
+			zap.String("job_id", job.JobID.String()),
+			zap.Int("parts", len(checkpoint.Parts)),
+			zap.Int64("rows", checkpoint.Rows),
+		)
 	}
 
-	// Upload to Linode Object Storage
-	signedURL, checksum, err := r.s3Delivery.UploadCSV(ctx, job.OrgID, job.JobID, csvData)
+	// Stream CSV from rollup tables to Linode Object Storage
+	rowCount, err := r.generateCSV(ctx, job, upload)
 	if err != nil {
-		return fmt.Errorf("upload CSV: %w", err)
+		return r.uploadFailed(ctx, job.JobID, upload, fmt.Errorf("generate CSV: %w", err))
+	}
+	checksum, err := upload.Complete(ctx, rowCount)
+	if err != nil {
+		return r.uploadFailed(ctx, job.JobID, upload, fmt.Errorf("upload CSV: %w", err))
+	}
+	signedURL, urlExpiresAt, err := r.s3Delivery.SignJobURL(ctx, job.OrgID, job.JobID)
+	if err != nil {
+		return fmt.Errorf("sign CSV URL: %w", err)
+	}
+	if err := r.repo.SetUploadCheckpoint(ctx, job.JobID, nil); err != nil {
+		return err
 	}
 
 	// Update job with output
-	if err := r.repo.SetExportJobOutput(ctx, job.JobID, signedURL, checksum, rowCount); err != nil {
+	if err := r.repo.SetExportJobOutput(ctx, job.JobID, signedURL, checksum, rowCount, urlExpiresAt); err != nil {
 		return fmt.Errorf("set export job output: %w", err)
 	}
 
@@ -184,8 +255,11 @@ func (r *JobRunner) ProcessJob(ctx context.Context, job ExportJob) error {
 	return nil
 }
 
-// generateCSV generates CSV data from rollup tables based on granularity.
-func (r *JobRunner) generateCSV(ctx context.Context, job ExportJob) ([]byte, int64, error) {
+// generateCSV streams CSV data from rollup tables based on granularity into
+// upload, writing only the job's columns. Rows already uploaded by an earlier
+// attempt are skipped, and a checkpoint is stored after every part. It returns
+// the total number of data rows in the file.
+func (r *JobRunner) generateCSV(ctx context.Context, job ExportJob, upload *MultipartUpload) (int64, error) {
 	var query string
 	var args []interface{}
 
@@ -247,37 +321,40 @@ func (r *JobRunner) generateCSV(ctx context.Context, job ExportJob) ([]byte, int
 		args = []interface{}{job.OrgID, job.TimeRangeStart, job.TimeRangeEnd}
 
 	default:
-		return nil, 0, fmt.Errorf("unsupported granularity: %s", job.Granularity)
+		return 0, fmt.Errorf("unsupported granularity: %s", job.Granularity)
 	}
 
 	// Execute query
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("query rollup data: %w", err)
+		return 0, fmt.Errorf("query rollup data: %w", err)
 	}
 	defer rows.Close()
 
 	// Generate CSV
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
+	writer := csv.NewWriter(upload)
 
-	// Write header
-	header := []string{
-		"bucket_start",
-		"organization_id",
-		"model_id",
-		"request_count",
-		"tokens_total",
-		"error_count",
-		"cost_total",
+	// Write header; only the columns allowed by the job's export profile. A
+	// resumed upload already has it.
+	header := job.Columns
+	if len(header) == 0 {
+		header = CSVColumns // Jobs created before export profiles
 	}
-	if err := writer.Write(header); err != nil {
-		return nil, 0, fmt.Errorf("write CSV header: %w", err)
+	skip := upload.Resumed()
+	if skip == 0 {
+		if err := writer.Write(header); err != nil {
+			return 0, fmt.Errorf("write CSV header: %w", err)
+		}
 	}
 
 	// Write rows
 	rowCount := int64(0)
 	for rows.Next() {
+		if rowCount < skip {
+			rowCount++ // Uploaded by an earlier attempt; the query order is stable
+			continue
+		}
+
 		var bucketStart time.Time
 		var orgID uuid.UUID
 		var modelID *uuid.UUID
@@ -294,35 +371,184 @@ func (r *JobRunner) generateCSV(ctx context.Context, job ExportJob) ([]byte, int
 			&costTotal,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("scan rollup row: %w", err)
+			return 0, fmt.Errorf("scan rollup row: %w", err)
 		}
 
 		// Format row
-		row := []string{
-			bucketStart.Format(time.RFC3339),
-			orgID.String(),
-			formatUUID(modelID),
-			fmt.Sprintf("%d", requestCount),
-			fmt.Sprintf("%d", tokensTotal),
-			fmt.Sprintf("%d", errorCount),
-			fmt.Sprintf("%.4f", costTotal),
+		values := map[string]string{
+			ColumnBucketStart:    bucketStart.Format(time.RFC3339),
+			ColumnOrganizationID: orgID.String(),
+			ColumnModelID:        formatUUID(modelID),
+			ColumnRequestCount:   fmt.Sprintf("%d", requestCount),
+			ColumnTokensTotal:    fmt.Sprintf("%d", tokensTotal),
+			ColumnErrorCount:     fmt.Sprintf("%d", errorCount),
+			ColumnCostTotal:      fmt.Sprintf("%.4f", costTotal),
+		}
+		row := make([]string, len(header))
+		for i, column := range header {
+			row[i] = values[column]
 		}
 
 		if err := writer.Write(row); err != nil {
-			return nil, 0, fmt.Errorf("write CSV row: %w", err)
+			return 0, fmt.Errorf("write CSV row: %w", err)
 		}
 		rowCount++
+
+		// Parts end on row boundaries, so flush the CSV writer first
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return 0, fmt.Errorf("flush CSV: %w", err)
+		}
+		uploaded, err := upload.FlushPart(ctx, rowCount)
+		if err != nil {
+			return 0, err
+		}
+		if uploaded {
+			if err := r.saveCheckpoint(ctx, job.JobID, upload); err != nil {
+				return 0, err
+			}
+		}
+
+		if rowCount%progressEvery == 0 {
+			if err := r.reportProgress(ctx, job.JobID, rowCount); err != nil {
+				return 0, err
+			}
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+		return 0, fmt.Errorf("iterate rows: %w", err)
 	}
 
 	writer.Flush()
 	if err := writer.Error(); err != nil {
-		return nil, 0, fmt.Errorf("flush CSV: %w", err)
+		return 0, fmt.Errorf("flush CSV: %w", err)
+	}
+
+	if err := r.reportProgress(ctx, job.JobID, rowCount); err != nil {
+		return 0, err
+	}
+
+	return rowCount, nil
+}
+
+// saveCheckpoint stores the upload's progress on the job.
+func (r *JobRunner) saveCheckpoint(ctx context.Context, jobID uuid.UUID, upload *MultipartUpload) error {
+	checkpoint, err := upload.Checkpoint()
+	if err != nil {
+		return err
+	}
+	return r.repo.SetUploadCheckpoint(ctx, jobID, checkpoint)
+}
+
+// uploadFailed handles a failed or cancelled upload. Cancelled jobs and
+// uploads that no longer exist are aborted and their checkpoint cleared, so a
+// retry starts over; otherwise the checkpoint is kept for the retry to resume.
+func (r *JobRunner) uploadFailed(ctx context.Context, jobID uuid.UUID, upload *MultipartUpload, err error) error {
+	var apiErr smithy.APIError
+	missing := errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchUpload"
+	if !errors.Is(err, errJobCancelled) && !missing {
+		return err
+	}
+
+	if !missing {
+		if abortErr := upload.Abort(ctx); abortErr != nil {
+			r.logger.Warn("failed to abort export upload", zap.String("job_id", jobID.String()), zap.Error(abortErr))
+		}
+	}
+	if clearErr := r.repo.SetUploadCheckpoint(ctx, jobID, nil); clearErr != nil {
+		r.logger.Warn("failed to clear upload checkpoint", zap.String("job_id", jobID.String()), zap.Error(clearErr))
 	}
+	return err
+}
 
-	return buf.Bytes(), rowCount, nil
+// reportProgress records rows written and returns errJobCancelled if the job
+// is no longer running.
+func (r *JobRunner) reportProgress(ctx context.Context, jobID uuid.UUID, rowsWritten int64) error {
+	status, err := r.repo.SetExportJobProgress(ctx, jobID, rowsWritten)
+	if err != nil {
+		return err
+	}
+	if status != "running" {
+		return errJobCancelled
+	}
+	return nil
 }
 
+// cleanupLoop periodically removes orphaned export objects.
+func (r *JobRunner) cleanupLoop(ctx context.Context, done chan struct{}) {
+	defer func() { done <- struct{}{} }()
+
+	ticker := time.NewTicker(r.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			removed, err := r.CleanupOrphans(ctx)
+			if err != nil {
+				r.logger.Error("export orphan cleanup failed", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				r.logger.Info("removed orphaned export objects", zap.Int("count", removed))
+			}
+		}
+	}
+}
+
+// CleanupOrphans deletes export objects and aborts unfinished uploads whose
+// job was deleted or cancelled, and returns how many were removed. Objects
+// written within orphanGrace are kept.
+func (r *JobRunner) CleanupOrphans(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-orphanGrace)
+
+	objects, err := r.s3Delivery.ListExportObjects(ctx)
+	if err != nil {
+		return 0, err
+	}
+	uploads, err := r.s3Delivery.ListExportUploads(ctx)
+	if err != nil {
+		return 0, err
+	}
+	objects = append(objects, uploads...)
+
+	var candidates []ExportObject
+	var jobIDs []uuid.UUID
+	for _, obj := range objects {
+		if obj.LastModified.After(cutoff) {
+			continue
+		}
+		candidates = append(candidates, obj)
+		jobIDs = append(jobIDs, obj.JobID)
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	live, err := r.repo.LiveJobIDs(ctx, jobIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, obj := range candidates {
+		if live[obj.JobID] {
+			continue
+		}
+		if obj.UploadID != "" {
+			err = r.s3Delivery.AbortUpload(ctx, obj.Key, obj.UploadID)
+		} else {
+			err = r.s3Delivery.DeleteObject(ctx, obj.Key)
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
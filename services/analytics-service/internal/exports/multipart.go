@@ -0,0 +1,200 @@
+// Package exports provides resumable multipart uploads for export CSVs.
+//
+// Purpose:
+//   Large exports are streamed to object storage in parts instead of being
+//   built in memory. Parts always end on a CSV row boundary, and after each
+//   part the runner stores an UploadCheckpoint on the job
+//   (export_jobs.upload_checkpoint, JSONB). A job that fails mid-upload and
+//   is retried resumes the same multipart upload from the last completed part:
+//   rows already uploaded are skipped and the running SHA-256 is restored, so
+//   the checksum still covers the whole file.
+//
+package exports
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+)
+
+// MinPartSize is the smallest part object storage accepts, except for the last.
+const MinPartSize = 5 << 20
+
+// DefaultPartSize is used when no part size is configured.
+const DefaultPartSize = 8 << 20
+
+// UploadCheckpoint records the progress of a multipart upload.
+type UploadCheckpoint struct {
+	UploadID  string         `json:"uploadId"`
+	Parts     []UploadedPart `json:"parts"`
+	Rows      int64          `json:"rows"`      // Data rows contained in the uploaded parts
+	Bytes     int64          `json:"bytes"`     // Bytes contained in the uploaded parts
+	HashState []byte         `json:"hashState"` // Marshaled SHA-256 state after the uploaded parts
+}
+
+// UploadedPart is a completed part of a multipart upload.
+type UploadedPart struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+// MultipartUpload streams a job's CSV to object storage in parts. Write
+// buffers data; FlushPart uploads the buffer once it reaches the part size.
+type MultipartUpload struct {
+	s        *S3Delivery
+	key      string
+	uploadID string
+	partSize int64
+	parts    []UploadedPart
+	buf      bytes.Buffer
+	hash     hash.Hash
+	bytes    int64
+	rows     int64
+}
+
+// StartMultipart begins a multipart upload for a job's CSV, or resumes the one
+// recorded in checkpoint when it is not nil.
+func (s *S3Delivery) StartMultipart(ctx context.Context, orgID, jobID uuid.UUID, partSize int64, checkpoint *UploadCheckpoint) (*MultipartUpload, error) {
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	u := &MultipartUpload{
+		s:        s,
+		key:      ObjectKey(orgID, jobID),
+		partSize: partSize,
+		hash:     sha256.New(),
+	}
+
+	if checkpoint != nil {
+		if err := u.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(checkpoint.HashState); err != nil {
+			return nil, fmt.Errorf("restore upload checksum state: %w", err)
+		}
+		u.uploadID = checkpoint.UploadID
+		u.parts = append(u.parts, checkpoint.Parts...)
+		u.bytes = checkpoint.Bytes
+		u.rows = checkpoint.Rows
+		return u, nil
+	}
+
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(u.key),
+		ContentType: aws.String("text/csv"),
+		Metadata: map[string]string{
+			"org-id": orgID.String(),
+			"job-id": jobID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create multipart upload: %w", err)
+	}
+	u.uploadID = aws.ToString(out.UploadId)
+	return u, nil
+}
+
+// Resumed reports how many data rows earlier attempts already uploaded.
+func (u *MultipartUpload) Resumed() int64 {
+	return u.rows
+}
+
+// Write buffers p for the next part.
+func (u *MultipartUpload) Write(p []byte) (int, error) {
+	return u.buf.Write(p)
+}
+
+// FlushPart uploads the buffered data as a part once it reaches the part
+// size. rows is the number of data rows written so far; the caller must only
+// call it on a row boundary. It reports whether a part was uploaded, after
+// which Checkpoint reflects the new part.
+func (u *MultipartUpload) FlushPart(ctx context.Context, rows int64) (bool, error) {
+	if int64(u.buf.Len()) < u.partSize {
+		return false, nil
+	}
+	if err := u.uploadPart(ctx, rows); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Checkpoint returns the state needed to resume after the uploaded parts.
+func (u *MultipartUpload) Checkpoint() (*UploadCheckpoint, error) {
+	state, err := u.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("save upload checksum state: %w", err)
+	}
+	return &UploadCheckpoint{
+		UploadID:  u.uploadID,
+		Parts:     append([]UploadedPart(nil), u.parts...),
+		Rows:      u.rows,
+		Bytes:     u.bytes,
+		HashState: state,
+	}, nil
+}
+
+// Complete uploads the remaining data as the last part, completes the upload
+// and returns the SHA-256 checksum of the whole file.
+func (u *MultipartUpload) Complete(ctx context.Context, rows int64) (string, error) {
+	if u.buf.Len() > 0 || len(u.parts) == 0 {
+		if err := u.uploadPart(ctx, rows); err != nil {
+			return "", err
+		}
+	}
+
+	completed := make([]types.CompletedPart, len(u.parts))
+	for i, part := range u.parts {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(part.Number),
+			ETag:       aws.String(part.ETag),
+		}
+	}
+	_, err := u.s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.s.bucket),
+		Key:             aws.String(u.key),
+		UploadId:        aws.String(u.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return "", fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	return hex.EncodeToString(u.hash.Sum(nil)), nil
+}
+
+// Abort discards the upload and its parts.
+func (u *MultipartUpload) Abort(ctx context.Context) error {
+	return u.s.AbortUpload(ctx, u.key, u.uploadID)
+}
+
+// uploadPart uploads the buffer as the next part and folds it into the
+// checksum only once the part is stored, so a failed part can be retried.
+func (u *MultipartUpload) uploadPart(ctx context.Context, rows int64) error {
+	data := u.buf.Bytes()
+	number := int32(len(u.parts) + 1)
+	out, err := u.s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(u.s.bucket),
+		Key:           aws.String(u.key),
+		UploadId:      aws.String(u.uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return fmt.Errorf("upload part %d: %w", number, err)
+	}
+
+	u.hash.Write(data)
+	u.parts = append(u.parts, UploadedPart{Number: number, ETag: aws.ToString(out.ETag)})
+	u.bytes += int64(len(data))
+	u.rows = rows
+	u.buf.Reset()
+	return nil
+}
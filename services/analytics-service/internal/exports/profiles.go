@@ -0,0 +1,146 @@
+// Package exports provides column-level access control for exports and queries.
+//
+// Purpose:
+//   Not every caller may see every column: finance users see cost but not the
+//   usage detail derived from request metadata, engineers the reverse. An
+//   export profile is a named column set; the caller's RBAC roles select the
+//   profiles they hold, and the union of those columns is what they may read.
+//   The export generator writes only the job's columns and the query API omits
+//   or rejects fields outside the caller's columns.
+//
+// Roles:
+//   admin                          -> full
+//   analytics:profile:finance      -> finance
+//   analytics:profile:engineering  -> engineering
+//   Callers holding none of these get the configured default profile
+//   (EXPORT_DEFAULT_PROFILE). With platform tokens, the org_admin, finance
+//   and viewer org roles map onto these (internal/middleware/tokens.go).
+//
+package exports
+
+import "fmt"
+
+// Export columns. ColumnMetadata is not a CSV column; it covers the breakdowns
+// derived from event metadata (projects, API keys, error codes) in the query API.
+const (
+	ColumnBucketStart    = "bucket_start"
+	ColumnOrganizationID = "organization_id"
+	ColumnModelID        = "model_id"
+	ColumnRequestCount   = "request_count"
+	ColumnTokensTotal    = "tokens_total"
+	ColumnErrorCount     = "error_count"
+	ColumnCostTotal      = "cost_total"
+	ColumnMetadata       = "metadata"
+)
+
+// CSVColumns lists the export CSV columns in output order.
+var CSVColumns = []string{
+	ColumnBucketStart,
+	ColumnOrganizationID,
+	ColumnModelID,
+	ColumnRequestCount,
+	ColumnTokensTotal,
+	ColumnErrorCount,
+	ColumnCostTotal,
+}
+
+// Profile names.
+const (
+	ProfileFull        = "full"
+	ProfileFinance     = "finance"
+	ProfileEngineering = "engineering"
+)
+
+// profiles maps each profile to the columns it may read.
+var profiles = map[string][]string{
+	ProfileFull: {
+		ColumnBucketStart, ColumnOrganizationID, ColumnModelID, ColumnRequestCount,
+		ColumnTokensTotal, ColumnErrorCount, ColumnCostTotal, ColumnMetadata,
+	},
+	ProfileFinance: {
+		ColumnBucketStart, ColumnOrganizationID, ColumnModelID, ColumnRequestCount,
+		ColumnCostTotal,
+	},
+	ProfileEngineering: {
+		ColumnBucketStart, ColumnOrganizationID, ColumnModelID, ColumnRequestCount,
+		ColumnTokensTotal, ColumnErrorCount, ColumnMetadata,
+	},
+}
+
+// profileRoles maps RBAC roles to the profile they grant.
+var profileRoles = map[string]string{
+	"admin":                         ProfileFull,
+	"analytics:profile:finance":     ProfileFinance,
+	"analytics:profile:engineering": ProfileEngineering,
+}
+
+// ValidProfile reports whether name is a known profile.
+func ValidProfile(name string) bool {
+	_, ok := profiles[name]
+	return ok
+}
+
+// ColumnSet is the set of columns a caller may read.
+type ColumnSet map[string]bool
+
+// ColumnsForRoles returns the union of the columns of every profile granted by
+// roles, or the columns of defaultProfile when no role grants one.
+func ColumnsForRoles(roles []string, defaultProfile string) (ColumnSet, error) {
+	set := ColumnSet{}
+	for _, role := range roles {
+		if profile, ok := profileRoles[role]; ok {
+			set.add(profiles[profile])
+		}
+	}
+	if len(set) == 0 {
+		columns, ok := profiles[defaultProfile]
+		if !ok {
+			return nil, fmt.Errorf("unknown default export profile %q", defaultProfile)
+		}
+		set.add(columns)
+	}
+	return set, nil
+}
+
+// ColumnsForProfile returns the columns of a profile.
+func ColumnsForProfile(name string) (ColumnSet, error) {
+	columns, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown export profile %q", name)
+	}
+	set := ColumnSet{}
+	set.add(columns)
+	return set, nil
+}
+
+// Has reports whether column is in the set.
+func (s ColumnSet) Has(column string) bool {
+	return s[column]
+}
+
+// Covers reports whether every column of other is in s.
+func (s ColumnSet) Covers(other ColumnSet) bool {
+	for column := range other {
+		if !s[column] {
+			return false
+		}
+	}
+	return true
+}
+
+// CSV returns the set's CSV columns in output order.
+func (s ColumnSet) CSV() []string {
+	var columns []string
+	for _, column := range CSVColumns {
+		if s[column] {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func (s ColumnSet) add(columns []string) {
+	for _, column := range columns {
+		s[column] = true
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -29,6 +31,23 @@ type S3Delivery struct {
 // NewS3Delivery creates a new Linode Object Storage delivery adapter.
 // Linode Object Storage is S3-compatible and uses the AWS SDK v2.
 func NewS3Delivery(endpoint, accessKey, secretKey, bucket, region string, signedURLTTL time.Duration, logger *zap.Logger) (*S3Delivery, error) {
+	client, err := NewS3Client(endpoint, accessKey, secretKey, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Delivery{
+		client:      client,
+		bucket:      bucket,
+		region:      region,
+		signedURLTTL: signedURLTTL,
+		logger:      logger,
+	}, nil
+}
+
+// NewS3Client creates an S3 client for Linode Object Storage (or AWS S3 when
+// endpoint is empty).
+func NewS3Client(endpoint, accessKey, secretKey, region string) (*s3.Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.Background(),
 		config.WithRegion(region),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
@@ -42,19 +61,11 @@ func NewS3Delivery(endpoint, accessKey, secretKey, bucket, region string, signed
 		cfg.BaseEndpoint = aws.String(endpoint)
 	}
 
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
 		if endpoint != "" {
 			o.UsePathStyle = true // Required for Linode Object Storage
 		}
-	})
-
-	return &S3Delivery{
-		client:      client,
-		bucket:      bucket,
-		region:      region,
-		signedURLTTL: signedURLTTL,
-		logger:      logger,
-	}, nil
+	}), nil
 }
 
 // UploadCSV uploads CSV data to S3 and returns the signed URL and checksum.
@@ -63,8 +74,7 @@ func (s *S3Delivery) UploadCSV(ctx context.Context, orgID, jobID uuid.UUID, csvD
 	hash := sha256.Sum256(csvData)
 	checksum := hex.EncodeToString(hash[:])
 
-	// Generate object key: analytics/exports/{org_id}/{job_id}.csv
-	key := fmt.Sprintf("analytics/exports/%s/%s.csv", orgID.String(), jobID.String())
+	key := ObjectKey(orgID, jobID)
 
 	// Upload to Linode Object Storage
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
@@ -117,3 +127,137 @@ func (s *S3Delivery) GenerateSignedURL(ctx context.Context, key string) (string,
 	return getRequest.URL, nil
 }
 
+// exportPrefix is the key prefix of every export object.
+const exportPrefix = "analytics/exports/"
+
+// ObjectKey returns the object key of a job's CSV:
+// analytics/exports/{org_id}/{job_id}.csv
+func ObjectKey(orgID, jobID uuid.UUID) string {
+	return fmt.Sprintf("%s%s/%s.csv", exportPrefix, orgID.String(), jobID.String())
+}
+
+// SignedURLTTL returns how long generated signed URLs stay valid.
+func (s *S3Delivery) SignedURLTTL() time.Duration {
+	return s.signedURLTTL
+}
+
+// SignJobURL generates a fresh signed URL for a job's CSV and returns it with
+// its expiry.
+func (s *S3Delivery) SignJobURL(ctx context.Context, orgID, jobID uuid.UUID) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.signedURLTTL)
+	signedURL, err := s.GenerateSignedURL(ctx, ObjectKey(orgID, jobID))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedURL, expiresAt, nil
+}
+
+// ExportObject is a stored export CSV, or an unfinished upload of one.
+type ExportObject struct {
+	Key          string
+	UploadID     string // Set for unfinished multipart uploads
+	JobID        uuid.UUID
+	LastModified time.Time
+}
+
+// ListExportObjects lists every export CSV in the bucket. Objects whose key
+// does not follow ObjectKey are skipped.
+func (s *S3Delivery) ListExportObjects(ctx context.Context) ([]ExportObject, error) {
+	var objects []ExportObject
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(exportPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list export objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			jobID, ok := jobIDFromKey(key)
+			if !ok {
+				continue
+			}
+			objects = append(objects, ExportObject{
+				Key:          key,
+				JobID:        jobID,
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// DeleteObject deletes an export object.
+func (s *S3Delivery) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("delete export object %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListExportUploads lists unfinished multipart uploads of export CSVs. They
+// hold stored parts but are not objects yet, so ListExportObjects misses them.
+// LastModified is when the upload was started.
+func (s *S3Delivery) ListExportUploads(ctx context.Context) ([]ExportObject, error) {
+	var uploads []ExportObject
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(exportPrefix),
+	}
+	for {
+		page, err := s.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("list export uploads: %w", err)
+		}
+		for _, upload := range page.Uploads {
+			key := aws.ToString(upload.Key)
+			jobID, ok := jobIDFromKey(key)
+			if !ok {
+				continue
+			}
+			uploads = append(uploads, ExportObject{
+				Key:          key,
+				UploadID:     aws.ToString(upload.UploadId),
+				JobID:        jobID,
+				LastModified: aws.ToTime(upload.Initiated),
+			})
+		}
+		if !aws.ToBool(page.IsTruncated) {
+			return uploads, nil
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.UploadIdMarker = page.NextUploadIdMarker
+	}
+}
+
+// AbortUpload discards an unfinished multipart upload.
+func (s *S3Delivery) AbortUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("abort export upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// jobIDFromKey extracts the job ID from a key built by ObjectKey.
+func jobIDFromKey(key string) (uuid.UUID, bool) {
+	name := path.Base(key)
+	if !strings.HasSuffix(name, ".csv") {
+		return uuid.Nil, false
+	}
+	jobID, err := uuid.Parse(strings.TrimSuffix(name, ".csv"))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return jobID, true
+}
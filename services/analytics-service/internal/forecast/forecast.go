@@ -0,0 +1,239 @@
+// Package forecast projects end-of-month spend from daily rollups.
+//
+// Purpose:
+//   Budget alerting and the dashboard need to know where an org's spend is
+//   heading before the month closes. The projection fits a linear trend to
+//   recent complete days after removing day-of-week seasonality (weekday
+//   factors), extends it to the end of the month and adds it to the spend
+//   already recorded. The residual spread of the fit gives confidence bounds.
+//
+// Model:
+//   factor[w]  = mean spend on weekday w / mean daily spend (1 with < 14 days)
+//   spend[t]   ~ (a + b*t) * factor[weekday(t)]
+//   forecast   = month-to-date + sum of predicted remaining days
+//   bounds     = forecast -/+ 1.96 * sigma * sqrt(sum of factor^2 over remaining days)
+//   The lower bound never falls below month-to-date spend.
+//
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// HistoryDays is how many days before today are used to fit the projection.
+const HistoryDays = 28
+
+// Confidence is the coverage of the forecast bounds.
+const Confidence = 0.95
+
+// z is the two-sided normal quantile for Confidence.
+const z = 1.96
+
+// minSeasonalDays is the history needed (two of each weekday) for weekday factors.
+const minSeasonalDays = 14
+
+// Projection methods.
+const (
+	MethodTrendWeekday = "trend+weekday"
+	MethodTrend        = "trend"
+	MethodRunRate      = "run-rate" // Fewer than 3 complete days; bounds are wide
+)
+
+// Day is one UTC day of spend, in dollars.
+type Day struct {
+	Date time.Time
+	Cost float64
+}
+
+// Result is an end-of-month projection, in dollars.
+type Result struct {
+	MonthToDate float64
+	Forecast    float64
+	Lower       float64
+	Upper       float64
+	DailyTrend  float64 // Fitted change in daily spend per day
+	Method      string
+	DaysElapsed int // Days of the month started, including today
+	DaysInMonth int
+}
+
+// MonthStart returns the first day of now's month (UTC).
+func MonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// HistoryStart returns the first day Project reads for now: the start of the
+// fit window or of the month, whichever is earlier.
+func HistoryStart(now time.Time) time.Time {
+	start := truncateDay(now).AddDate(0, 0, -HistoryDays)
+	if monthStart := MonthStart(now); monthStart.Before(start) {
+		return monthStart
+	}
+	return start
+}
+
+// Project forecasts the spend of now's month from days, which should cover
+// HistoryStart(now) through today; missing days count as no spend. Today is
+// treated as partial.
+func Project(days []Day, now time.Time) Result {
+	today := truncateDay(now)
+	monthStart := MonthStart(now)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	spend := map[time.Time]float64{}
+	first := today
+	for _, d := range days {
+		date := truncateDay(d.Date)
+		spend[date] += d.Cost
+		if d.Cost > 0 && date.Before(first) {
+			first = date
+		}
+	}
+
+	result := Result{
+		DaysElapsed: int(today.Sub(monthStart).Hours()/24) + 1,
+		DaysInMonth: int(monthEnd.Sub(monthStart).Hours() / 24),
+	}
+	for date, cost := range spend {
+		if !date.Before(monthStart) && !date.After(today) {
+			result.MonthToDate += cost
+		}
+	}
+
+	// Complete days from the first day with spend (zeros before an org's
+	// first usage would drag the trend down)
+	start := today.AddDate(0, 0, -HistoryDays)
+	if first.After(start) {
+		start = first
+	}
+	var dates []time.Time
+	var ys []float64
+	for date := start; date.Before(today); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+		ys = append(ys, spend[date])
+	}
+
+	factors := weekdayFactors(dates, ys)
+	a, b, sigma := fitTrend(ys, dates, factors)
+	result.DailyTrend = b
+	switch {
+	case len(ys) < 3:
+		result.Method = MethodRunRate
+	case len(ys) >= minSeasonalDays:
+		result.Method = MethodTrendWeekday
+	default:
+		result.Method = MethodTrend
+	}
+	if len(ys) == 0 {
+		// Nothing complete yet: extrapolate today's spend over the elapsed part of the day
+		elapsed := now.UTC().Sub(today).Hours() / 24
+		if elapsed > 0 {
+			a = spend[today] / math.Max(elapsed, 1.0/24)
+		}
+		sigma = a
+	}
+
+	variance := 0.0
+	remaining := 0.0
+	for date, t := today, float64(len(ys)); date.Before(monthEnd); date, t = date.AddDate(0, 0, 1), t+1 {
+		f := factors[date.Weekday()]
+		predicted := math.Max(0, (a+b*t)*f)
+		if date.Equal(today) {
+			predicted = math.Max(0, predicted-spend[today])
+		}
+		remaining += predicted
+		variance += (sigma * f) * (sigma * f)
+	}
+
+	halfWidth := z * math.Sqrt(variance)
+	result.Forecast = result.MonthToDate + remaining
+	result.Lower = math.Max(result.MonthToDate, result.Forecast-halfWidth)
+	result.Upper = result.Forecast + halfWidth
+	return result
+}
+
+// weekdayFactors returns each weekday's mean spend relative to the overall
+// mean, or 1 for every weekday when there is too little history.
+func weekdayFactors(dates []time.Time, ys []float64) [7]float64 {
+	factors := [7]float64{1, 1, 1, 1, 1, 1, 1}
+	if len(ys) < minSeasonalDays {
+		return factors
+	}
+
+	var sums [7]float64
+	var counts [7]int
+	total := 0.0
+	for i, y := range ys {
+		w := dates[i].Weekday()
+		sums[w] += y
+		counts[w]++
+		total += y
+	}
+	mean := total / float64(len(ys))
+	if mean <= 0 {
+		return factors
+	}
+	for w := range factors {
+		if counts[w] > 0 {
+			factors[w] = sums[w] / float64(counts[w]) / mean
+		}
+	}
+	return factors
+}
+
+// fitTrend fits a least-squares line to the deseasonalised series and returns
+// its intercept, slope and residual standard deviation. Days on weekdays with
+// no spend at all (factor 0) carry no trend information and are skipped.
+func fitTrend(ys []float64, dates []time.Time, factors [7]float64) (a, b, sigma float64) {
+	var ts, zs []float64
+	for i, y := range ys {
+		f := factors[dates[i].Weekday()]
+		if f <= 0 {
+			continue
+		}
+		ts = append(ts, float64(i))
+		zs = append(zs, y/f)
+	}
+
+	n := float64(len(zs))
+	switch len(zs) {
+	case 0:
+		return 0, 0, 0
+	case 1:
+		return zs[0], 0, zs[0]
+	}
+
+	var sumT, sumZ float64
+	for i := range zs {
+		sumT += ts[i]
+		sumZ += zs[i]
+	}
+	meanT, meanZ := sumT/n, sumZ/n
+	var sxx, sxy float64
+	for i := range zs {
+		sxx += (ts[i] - meanT) * (ts[i] - meanT)
+		sxy += (ts[i] - meanT) * (zs[i] - meanZ)
+	}
+	if sxx > 0 {
+		b = sxy / sxx
+	}
+	a = meanZ - b*meanT
+
+	if len(zs) < 3 {
+		// Too few points for a residual spread; assume the run rate itself
+		return a, b, meanZ
+	}
+	var ss float64
+	for i := range zs {
+		r := zs[i] - (a + b*ts[i])
+		ss += r * r
+	}
+	return a, b, math.Sqrt(ss / (n - 2))
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,183 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// series returns n consecutive days from start costing cost(i) on day i.
+func series(start time.Time, n int, cost func(i int) float64) []Day {
+	days := make([]Day, n)
+	for i := range days {
+		days[i] = Day{Date: start.AddDate(0, 0, i), Cost: cost(i)}
+	}
+	return days
+}
+
+func TestProject(t *testing.T) {
+	flat := func(int) float64 { return 10 }
+	rising := func(i int) float64 { return 10 + float64(i) }
+	// Run rate of 20/day from 10 spent by noon, over today's remainder and 16 more days
+	runRateHalfWidth := z * math.Sqrt(17*20*20)
+
+	tests := []struct {
+		name     string
+		days     []Day
+		now      time.Time
+		want     Result
+		wantBand bool // Use want.Lower/Upper; otherwise both must equal Forecast
+	}{
+		{
+			name: "no data",
+			now:  date(2026, time.October, 15).Add(12 * time.Hour),
+			want: Result{Method: MethodRunRate, DaysElapsed: 15, DaysInMonth: 31},
+		},
+		{
+			name: "spend today only",
+			days: []Day{{Date: date(2026, time.October, 15).Add(time.Hour), Cost: 10}},
+			now:  date(2026, time.October, 15).Add(12 * time.Hour),
+			want: Result{
+				MonthToDate: 10,
+				Forecast:    340,
+				Lower:       340 - runRateHalfWidth,
+				Upper:       340 + runRateHalfWidth,
+				Method:      MethodRunRate,
+				DaysElapsed: 15,
+				DaysInMonth: 31,
+			},
+			wantBand: true,
+		},
+		{
+			name: "single complete day",
+			days: []Day{{Date: date(2026, time.October, 14), Cost: 10}},
+			now:  date(2026, time.October, 15),
+			want: Result{
+				MonthToDate: 10,
+				Forecast:    180,
+				Lower:       180 - z*math.Sqrt(17*10*10),
+				Upper:       180 + z*math.Sqrt(17*10*10),
+				Method:      MethodRunRate,
+				DaysElapsed: 15,
+				DaysInMonth: 31,
+			},
+			wantBand: true,
+		},
+		{
+			name: "lower bound held at month-to-date",
+			days: []Day{{Date: date(2026, time.October, 30), Cost: 10}},
+			now:  date(2026, time.October, 31),
+			want: Result{
+				MonthToDate: 10,
+				Forecast:    20,
+				Lower:       10,
+				Upper:       20 + z*10,
+				Method:      MethodRunRate,
+				DaysElapsed: 31,
+				DaysInMonth: 31,
+			},
+			wantBand: true,
+		},
+		{
+			name: "flat trend",
+			days: series(date(2026, time.October, 1), 28, flat),
+			now:  date(2026, time.October, 29),
+			want: Result{MonthToDate: 280, Forecast: 310, Method: MethodTrendWeekday, DaysElapsed: 29, DaysInMonth: 31},
+		},
+		{
+			name: "rising trend",
+			days: series(date(2026, time.October, 5), 10, rising),
+			now:  date(2026, time.October, 15),
+			// 10..19 so far, then 20..36 over October 15-31
+			want: Result{MonthToDate: 145, Forecast: 145 + 476, DailyTrend: 1, Method: MethodTrend, DaysElapsed: 15, DaysInMonth: 31},
+		},
+		{
+			name: "rising trend across the month boundary",
+			days: series(date(2026, time.October, 21), 13, rising),
+			now:  date(2026, time.November, 3),
+			// October's spend fits the trend but is not month-to-date; 21 and 22
+			// on November 1-2, then 23..50 over November 3-30
+			want: Result{MonthToDate: 43, Forecast: 43 + 1022, DailyTrend: 1, Method: MethodTrend, DaysElapsed: 3, DaysInMonth: 30},
+		},
+		{
+			name: "first day of the month",
+			days: series(date(2026, time.October, 4), 28, flat),
+			now:  date(2026, time.November, 1),
+			want: Result{MonthToDate: 0, Forecast: 300, Method: MethodTrendWeekday, DaysElapsed: 1, DaysInMonth: 30},
+		},
+		{
+			name: "last day of the month",
+			days: series(date(2026, time.October, 3), 28, flat),
+			now:  date(2026, time.October, 31),
+			want: Result{MonthToDate: 280, Forecast: 290, Method: MethodTrendWeekday, DaysElapsed: 31, DaysInMonth: 31},
+		},
+		{
+			name: "leap February",
+			days: series(date(2028, time.February, 1), 14, flat),
+			now:  date(2028, time.February, 15),
+			want: Result{MonthToDate: 140, Forecast: 290, Method: MethodTrendWeekday, DaysElapsed: 15, DaysInMonth: 29},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if !tt.wantBand {
+				want.Lower, want.Upper = want.Forecast, want.Forecast
+			}
+			got := Project(tt.days, tt.now)
+			require.Equal(t, want.Method, got.Method)
+			require.Equal(t, want.DaysElapsed, got.DaysElapsed)
+			require.Equal(t, want.DaysInMonth, got.DaysInMonth)
+			require.InDelta(t, want.MonthToDate, got.MonthToDate, 1e-6, "month to date")
+			require.InDelta(t, want.Forecast, got.Forecast, 1e-6, "forecast")
+			require.InDelta(t, want.Lower, got.Lower, 1e-6, "lower")
+			require.InDelta(t, want.Upper, got.Upper, 1e-6, "upper")
+			require.InDelta(t, want.DailyTrend, got.DailyTrend, 1e-6, "daily trend")
+		})
+	}
+}
+
+func TestProjectIgnoresLeadingZeroDays(t *testing.T) {
+	// An org that started using the platform on October 10 is not dragged
+	// down by the empty days before it
+	days := series(date(2026, time.October, 1), 9, func(int) float64 { return 0 })
+	days = append(days, series(date(2026, time.October, 10), 5, func(int) float64 { return 10 })...)
+	got := Project(days, date(2026, time.October, 15))
+	require.Equal(t, MethodTrend, got.Method)
+	require.InDelta(t, 0, got.DailyTrend, 1e-6)
+	require.InDelta(t, 50+17*10, got.Forecast, 1e-6)
+}
+
+func TestProjectWeekdaySeasonality(t *testing.T) {
+	// Weekdays cost 10 and weekends 0 for four weeks from Monday October 5
+	weekdays := func(i int) float64 {
+		if i%7 >= 5 {
+			return 0
+		}
+		return 10
+	}
+	got := Project(series(date(2026, time.October, 5), 26, weekdays), date(2026, time.October, 31))
+	require.Equal(t, MethodTrendWeekday, got.Method)
+	// Saturday October 31 is predicted at nothing rather than the daily average
+	require.InDelta(t, got.MonthToDate, got.Forecast, 1e-6)
+}
+
+func TestHistoryStart(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{now: date(2026, time.October, 15).Add(9 * time.Hour), want: date(2026, time.September, 17)},
+		{now: date(2026, time.October, 31), want: date(2026, time.October, 1)},
+		{now: date(2026, time.March, 1), want: date(2026, time.February, 1)},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, HistoryStart(tt.now), tt.now.String())
+	}
+}
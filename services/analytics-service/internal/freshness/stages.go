@@ -0,0 +1,197 @@
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Pipeline stages tracked per org.
+const (
+	StageReceived = "received"
+	StageInserted = "inserted"
+	StageRolledUp = "rolled_up"
+)
+
+// stageTTL keeps per-org stage watermarks around long after the indicator TTL
+// so that orgs with sparse traffic still report their last known position.
+const stageTTL = 7 * 24 * time.Hour
+
+const orgSetKey = "analytics:freshness:orgs"
+
+// Redis hash fields for stage watermarks (unix nanoseconds).
+const (
+	fieldLastOccurred = "last_occurred_at"
+	fieldLastReceived = "last_received_at"
+	fieldInsertedAt   = "inserted_at"
+)
+
+// StageStatus is the watermark and lag of one pipeline stage.
+type StageStatus struct {
+	// LastAt is when the stage last advanced for the org.
+	LastAt time.Time `json:"lastAt"`
+	// LagSeconds is how long the stage trailed the previous one: event time to
+	// receipt, receipt to insert, and insert to rollup (0 once rolled up).
+	LagSeconds int `json:"lagSeconds"`
+}
+
+// OrgFreshness is the materialized freshness view for one org.
+type OrgFreshness struct {
+	OrgID       uuid.UUID              `json:"orgId"`
+	Status      string                 `json:"status"`
+	LagSeconds  int                    `json:"lagSeconds"`
+	LastEventAt time.Time              `json:"lastEventAt"`
+	Stages      map[string]StageStatus `json:"stages"`
+}
+
+// RecordIngestion stores the received/inserted watermarks for an org after a
+// batch is persisted. newestOccurred and newestReceived describe the newest
+// event in the batch.
+func (c *Cache) RecordIngestion(ctx context.Context, orgID uuid.UUID, newestOccurred, newestReceived, insertedAt time.Time) error {
+	key := c.stageKey(orgID)
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		fieldLastOccurred, newestOccurred.UnixNano(),
+		fieldLastReceived, newestReceived.UnixNano(),
+		fieldInsertedAt, insertedAt.UnixNano(),
+	)
+	pipe.Expire(ctx, key, stageTTL)
+	pipe.SAdd(ctx, orgSetKey, orgID.String())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis record ingestion: %w", err)
+	}
+	return nil
+}
+
+// Breakdown merges database freshness indicators (per org/model) with the
+// cached stage watermarks into one entry per org, sorted by org ID. When
+// orgID is non-nil only that org is returned.
+func (c *Cache) Breakdown(ctx context.Context, indicators []*Indicator, orgID *uuid.UUID, now time.Time) ([]OrgFreshness, error) {
+	byOrg := make(map[uuid.UUID]*OrgFreshness)
+	rollups := make(map[uuid.UUID]time.Time)
+	get := func(id uuid.UUID) *OrgFreshness {
+		of, ok := byOrg[id]
+		if !ok {
+			of = &OrgFreshness{OrgID: id, Status: "unknown", Stages: map[string]StageStatus{}}
+			byOrg[id] = of
+		}
+		return of
+	}
+
+	for _, ind := range indicators {
+		if orgID != nil && ind.OrgID != *orgID {
+			continue
+		}
+		of := get(ind.OrgID)
+		if of.Status == "unknown" || statusRank(ind.Status) > statusRank(of.Status) {
+			of.Status = ind.Status
+		}
+		if ind.LagSeconds > of.LagSeconds {
+			of.LagSeconds = ind.LagSeconds
+		}
+		if ind.LastEventAt.After(of.LastEventAt) {
+			of.LastEventAt = ind.LastEventAt
+		}
+		// The org is only fully rolled up as far as its most stale model.
+		if last, ok := rollups[ind.OrgID]; !ok || ind.LastRollupAt.Before(last) {
+			rollups[ind.OrgID] = ind.LastRollupAt
+		}
+	}
+
+	if orgID != nil {
+		get(*orgID)
+	} else {
+		members, err := c.client.SMembers(ctx, orgSetKey).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis list orgs: %w", err)
+		}
+		for _, m := range members {
+			if id, err := uuid.Parse(m); err == nil {
+				get(id)
+			}
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(byOrg))
+	for id := range byOrg {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, c.stageKey(id))
+	}
+	if len(ids) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("redis get stages: %w", err)
+		}
+	}
+
+	out := make([]OrgFreshness, 0, len(ids))
+	for i, id := range ids {
+		of := byOrg[id]
+		fields, _ := cmds[i].Result()
+		occurred := unixField(fields, fieldLastOccurred)
+		received := unixField(fields, fieldLastReceived)
+		inserted := unixField(fields, fieldInsertedAt)
+
+		if !received.IsZero() {
+			of.Stages[StageReceived] = StageStatus{LastAt: received, LagSeconds: seconds(received.Sub(occurred))}
+		}
+		if !inserted.IsZero() {
+			of.Stages[StageInserted] = StageStatus{LastAt: inserted, LagSeconds: seconds(inserted.Sub(received))}
+		}
+		if rolled, ok := rollups[id]; ok {
+			lag := 0
+			if inserted.After(rolled) {
+				lag = seconds(now.Sub(inserted))
+			}
+			of.Stages[StageRolledUp] = StageStatus{LastAt: rolled, LagSeconds: lag}
+		}
+		if occurred.After(of.LastEventAt) {
+			of.LastEventAt = occurred
+		}
+		out = append(out, *of)
+	}
+	return out, nil
+}
+
+func (c *Cache) stageKey(orgID uuid.UUID) string {
+	return fmt.Sprintf("analytics:freshness:stages:%s", orgID.String())
+}
+
+func unixField(fields map[string]string, name string) time.Time {
+	v, err := strconv.ParseInt(fields[name], 10, 64)
+	if err != nil || v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v).UTC()
+}
+
+func seconds(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(d.Seconds())
+}
+
+// statusRank orders statuses from best to worst.
+func statusRank(status string) int {
+	switch status {
+	case "fresh":
+		return 0
+	case "stale":
+		return 1
+	case "delayed":
+		return 2
+	default:
+		return 3
+	}
+}
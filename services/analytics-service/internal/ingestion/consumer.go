@@ -13,6 +13,7 @@
 // Key Responsibilities:
 //   - Connect to RabbitMQ stream
 //   - Consume events in batches
+//   - Decode every usage record schema version (see schema.go)
 //   - Deduplicate events by (event_id, org_id)
 //   - Persist to usage_events table
 //   - Track ingestion batches
@@ -21,7 +22,6 @@ package ingestion
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -34,6 +34,8 @@ import (
 	"github.com/rabbitmq/rabbitmq-stream-go-client/pkg/stream"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/usagerecord"
+
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
@@ -62,6 +64,12 @@ type Config struct {
 	BatchTimeout  time.Duration
 	Logger        *zap.Logger
 	Store         *postgres.Store
+	// Freshness records per-org pipeline watermarks (optional)
+	Freshness     IngestionRecorder
+	// KeyUsage records per-API-key usage summaries (optional)
+	KeyUsage      KeyUsageRecorder
+	// ReadMode selects the usage record encodings accepted (dual when empty)
+	ReadMode      usagerecord.ReadMode
 	RabbitMQHost  string
 	RabbitMQPort  int
 	RabbitMQUser  string
@@ -81,6 +89,12 @@ func NewConsumer(cfg Config) (*Consumer, error) {
 	}
 
 	processor := NewProcessor(cfg.Store, cfg.Logger)
+	if cfg.Freshness != nil {
+		processor.SetRecorder(cfg.Freshness)
+	}
+	if cfg.KeyUsage != nil {
+		processor.SetKeyUsageRecorder(cfg.KeyUsage)
+	}
 
 	return &Consumer{
 		logger:     cfg.Logger,
@@ -361,7 +375,6 @@ func parseRabbitMQURL(rawURL string) (host string, port int, user string, passwo
 
 // parseMessage parses a RabbitMQ stream message into an Event.
 func (c *Consumer) parseMessage(msg *amqp.Message) (Event, error) {
-	var event Event
 	// Get message data (may be in multiple parts, concatenate them)
 	data := msg.GetData()
 	if len(data) == 0 && len(msg.Data) > 0 {
@@ -375,19 +388,11 @@ func (c *Consumer) parseMessage(msg *amqp.Message) (Event, error) {
 			data = append(data, part...)
 		}
 	}
-	if err := json.Unmarshal(data, &event); err != nil {
-		return Event{}, fmt.Errorf("unmarshal event: %w", err)
+	var contentType string
+	if msg.Properties != nil {
+		contentType = msg.Properties.ContentType
 	}
-
-	// Validate required fields
-	if event.EventID == "" {
-		return Event{}, fmt.Errorf("event_id is required")
-	}
-	if event.OrgID == "" {
-		return Event{}, fmt.Errorf("org_id is required")
-	}
-
-	return event, nil
+	return ParseMessage(c.config.ReadMode, contentType, data)
 }
 
 // processBatch processes a batch of events.
@@ -425,5 +430,6 @@ type Event struct {
 	Status       string                 `json:"status"`
 	ErrorCode    string                 `json:"error_code,omitempty"`
 	CostEstimate float64                `json:"cost_estimate"`
+	APIKeyID     string                 `json:"api_key_id,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
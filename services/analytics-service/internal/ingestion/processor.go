@@ -18,10 +18,24 @@ import (
 	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
 )
 
+// IngestionRecorder tracks per-org pipeline watermarks (implemented by
+// freshness.Cache).
+type IngestionRecorder interface {
+	RecordIngestion(ctx context.Context, orgID uuid.UUID, newestOccurred, newestReceived, insertedAt time.Time) error
+}
+
+// KeyUsageRecorder folds persisted events into per-API-key usage summaries
+// (implemented by keyusage.Recorder).
+type KeyUsageRecorder interface {
+	RecordKeyUsage(ctx context.Context, events []postgres.UsageEvent) error
+}
+
 // Processor handles event processing and persistence.
 type Processor struct {
-	store  *postgres.Store
-	logger *zap.Logger
+	store    *postgres.Store
+	logger   *zap.Logger
+	recorder IngestionRecorder
+	keyUsage KeyUsageRecorder
 }
 
 // NewProcessor creates a new event processor.
@@ -32,6 +46,16 @@ func NewProcessor(store *postgres.Store, logger *zap.Logger) *Processor {
 	}
 }
 
+// SetRecorder enables per-org freshness tracking for persisted batches.
+func (p *Processor) SetRecorder(r IngestionRecorder) {
+	p.recorder = r
+}
+
+// SetKeyUsageRecorder enables per-API-key usage summaries for persisted batches.
+func (p *Processor) SetKeyUsageRecorder(r KeyUsageRecorder) {
+	p.keyUsage = r
+}
+
 // ProcessBatch processes a batch of events with deduplication.
 func (p *Processor) ProcessBatch(ctx context.Context, events []Event, streamOffset int64) error {
 	if len(events) == 0 {
@@ -84,6 +108,9 @@ func (p *Processor) ProcessBatch(ctx context.Context, events []Event, streamOffs
 		return fmt.Errorf("complete ingestion batch: %w", err)
 	}
 
+	p.recordIngestion(ctx, dbEvents)
+	p.recordKeyUsage(ctx, dbEvents)
+
 	p.logger.Info("processed batch",
 		zap.String("batch_id", batchID.String()),
 		zap.Int("total_events", len(events)),
@@ -117,6 +144,16 @@ func (p *Processor) convertEvent(e Event) (postgres.UsageEvent, error) {
 	var actorID uuid.UUID
 	// ActorID is optional, leave as Nil if not provided
 
+	// The issuing API key travels in metadata so per-key summaries can attribute it
+	metadata := e.Metadata
+	if e.APIKeyID != "" {
+		metadata = make(map[string]interface{}, len(e.Metadata)+1)
+		for k, v := range e.Metadata {
+			metadata[k] = v
+		}
+		metadata["api_key_id"] = e.APIKeyID
+	}
+
 	now := time.Now()
 	return postgres.UsageEvent{
 		EventID:           eventID,
@@ -131,7 +168,46 @@ func (p *Processor) convertEvent(e Event) (postgres.UsageEvent, error) {
 		Status:            e.Status,
 		ErrorCode:         e.ErrorCode,
 		CostEstimateCents: e.CostEstimate,
-		Metadata:          e.Metadata,
+		Metadata:          metadata,
 	}, nil
 }
 
+
+// recordIngestion updates freshness watermarks for each org in the batch.
+// Failures are logged; freshness tracking never fails ingestion.
+func (p *Processor) recordIngestion(ctx context.Context, events []postgres.UsageEvent) {
+	if p.recorder == nil || len(events) == 0 {
+		return
+	}
+
+	type watermark struct{ occurred, received time.Time }
+	newest := make(map[uuid.UUID]watermark)
+	for _, e := range events {
+		w := newest[e.OrgID]
+		if e.OccurredAt.After(w.occurred) {
+			w.occurred = e.OccurredAt
+		}
+		if e.ReceivedAt.After(w.received) {
+			w.received = e.ReceivedAt
+		}
+		newest[e.OrgID] = w
+	}
+
+	insertedAt := time.Now().UTC()
+	for orgID, w := range newest {
+		if err := p.recorder.RecordIngestion(ctx, orgID, w.occurred, w.received, insertedAt); err != nil {
+			p.logger.Warn("failed to record ingestion freshness", zap.String("org_id", orgID.String()), zap.Error(err))
+		}
+	}
+}
+
+// recordKeyUsage updates per-API-key summaries. Failures are logged; key
+// usage tracking never fails ingestion.
+func (p *Processor) recordKeyUsage(ctx context.Context, events []postgres.UsageEvent) {
+	if p.keyUsage == nil || len(events) == 0 {
+		return
+	}
+	if err := p.keyUsage.RecordKeyUsage(ctx, events); err != nil {
+		p.logger.Warn("failed to record key usage", zap.Int("events", len(events)), zap.Error(err))
+	}
+}
@@ -0,0 +1,89 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rabbitmq/rabbitmq-stream-go-client/pkg/amqp"
+	"github.com/rabbitmq/rabbitmq-stream-go-client/pkg/message"
+	"github.com/rabbitmq/rabbitmq-stream-go-client/pkg/stream"
+)
+
+// Publisher writes usage events to the RabbitMQ stream read by Consumer. It is
+// used by tooling (e.g. synthesize-usage); production events come from the
+// router's usage pipeline.
+type Publisher struct {
+	env      *stream.Environment
+	producer *stream.Producer
+}
+
+// PublisherConfig holds publisher configuration.
+type PublisherConfig struct {
+	StreamURL string
+	Stream    string
+}
+
+// NewPublisher connects to the stream, declaring it if needed.
+func NewPublisher(cfg PublisherConfig) (*Publisher, error) {
+	host, port, user, password, err := parseRabbitMQURL(cfg.StreamURL)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := stream.NewEnvironment(
+		stream.NewEnvironmentOptions().
+			SetHost(host).
+			SetPort(port).
+			SetUser(user).
+			SetPassword(password),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create stream environment: %w", err)
+	}
+
+	// Same options as Consumer so whichever starts first creates an identical stream
+	err = env.DeclareStream(cfg.Stream,
+		stream.NewStreamOptions().
+			SetMaxLengthBytes(stream.ByteCapacity{}.GB(50)),
+	)
+	if err != nil && !errors.Is(err, stream.StreamAlreadyExists) {
+		_ = env.Close()
+		return nil, fmt.Errorf("declare stream: %w", err)
+	}
+
+	producer, err := env.NewProducer(cfg.Stream, stream.NewProducerOptions())
+	if err != nil {
+		_ = env.Close()
+		return nil, fmt.Errorf("create producer: %w", err)
+	}
+	return &Publisher{env: env, producer: producer}, nil
+}
+
+// Publish sends events as JSON messages in a single batch.
+func (p *Publisher) Publish(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch := make([]message.StreamMessage, 0, len(events))
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal event %s: %w", e.EventID, err)
+		}
+		batch = append(batch, amqp.NewMessage(data))
+	}
+	if err := p.producer.BatchSend(batch); err != nil {
+		return fmt.Errorf("publish batch: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the producer and stream environment.
+func (p *Publisher) Close() error {
+	perr := p.producer.Close()
+	if err := p.env.Close(); err != nil {
+		return err
+	}
+	return perr
+}
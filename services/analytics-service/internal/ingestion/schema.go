@@ -0,0 +1,90 @@
+package ingestion
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ai-aas/shared-go/usagerecord"
+)
+
+// ParseEvent decodes a usage record of any registered schema version (see
+// shared/go/usagerecord) in either encoding, upgrades it and validates
+// required fields. The backfill tool uses it for archives that may span an
+// encoding migration.
+func ParseEvent(data []byte) (Event, error) {
+	return ParseMessage(usagerecord.ReadDual, "", data)
+}
+
+// ParseMessage is ParseEvent for the stream consumer: contentType is the
+// message's content type (empty to detect the encoding) and mode restricts the
+// accepted encodings, so the router can switch encoding or publish a newer
+// record version before or after analytics is deployed.
+func ParseMessage(mode usagerecord.ReadMode, contentType string, data []byte) (Event, error) {
+	if mode == "" {
+		mode = usagerecord.ReadDual
+	}
+	record, version, err := mode.Unmarshal(contentType, data)
+	if err != nil {
+		return Event{}, fmt.Errorf("decode usage record: %w", err)
+	}
+	event := eventFromRecord(record)
+
+	// Validate required fields
+	if event.EventID == "" {
+		return Event{}, fmt.Errorf("event_id is required (schema v%d)", version)
+	}
+	if event.OrgID == "" {
+		return Event{}, fmt.Errorf("org_id is required (schema v%d)", version)
+	}
+
+	return event, nil
+}
+
+// eventFromRecord maps the current usage record onto an ingestion event.
+// Router records name models rather than carrying a model UUID; the name is
+// kept in metadata["model"] and model_id is left empty. Routing details and any
+// payloads the org allows travel in metadata as well.
+func eventFromRecord(r usagerecord.Record) Event {
+	event := Event{
+		EventID:      r.RecordID,
+		OrgID:        r.OrganizationID,
+		OccurredAt:   r.Timestamp,
+		InputTokens:  int64(r.TokensInput),
+		OutputTokens: int64(r.TokensOutput),
+		LatencyMS:    r.LatencyMS,
+		Status:       r.Status,
+		ErrorCode:    r.ErrorCode,
+		CostEstimate: r.CostUSD,
+		APIKeyID:     r.APIKeyID,
+	}
+
+	extra := map[string]string{
+		"request_id":      r.RequestID,
+		"backend_id":      r.BackendID,
+		"decision_reason": r.DecisionReason,
+		"prompt":          r.Prompt,
+		"response":        r.Response,
+		"payload_mode":    r.PayloadMode,
+	}
+	if _, err := uuid.Parse(r.Model); err == nil {
+		event.ModelID = r.Model
+	} else {
+		extra["model"] = r.Model
+	}
+
+	// Copy so the record's map is not modified; producer metadata wins
+	metadata := make(map[string]interface{}, len(r.Metadata)+len(extra))
+	for k, v := range extra {
+		if v != "" {
+			metadata[k] = v
+		}
+	}
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+	if len(metadata) > 0 {
+		event.Metadata = metadata
+	}
+	return event
+}
@@ -0,0 +1,350 @@
+// Package isolation verifies tenant data isolation in the analytics query layer.
+//
+// Purpose:
+//
+//	Every analytics query is scoped by organization_id / org_id (and by RLS
+//	policies where the database enforces them). The verifier samples orgs,
+//	runs the same store queries the API serves, and checks every result
+//	against the org's own rows read with explicit org filters: returned
+//	identifiers must belong to the org and totals must match the org's own
+//	totals exactly. A canary org that owns no data must get nothing back.
+//
+// Debugging Notes:
+//   - Ground-truth queries run through the same pool and role as the query
+//     layer, so RLS applies to both; the checks catch filters that are missing
+//     or wrong in the application queries
+//   - Model IDs may be shared between orgs, so the sum checks are what catch
+//     leaked rollup rows; key, project and error-code ownership is checked
+//     value by value
+//   - Query failures abort the run; they are not reported as violations
+package isolation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/exports"
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+)
+
+// DefaultSampleSize is used when Config.SampleSize is unset.
+const DefaultSampleSize = 20
+
+// DefaultWindow is used when Config.Window is unset.
+const DefaultWindow = 24 * time.Hour
+
+// topLimit is the top-N limit used by the canary queries; large enough to
+// return every value an org has in a typical window.
+const topLimit = 100
+
+// maxDetailValues caps how many offending values a check lists.
+const maxDetailValues = 5
+
+// QueryLayer is the org-scoped query surface under test (implemented by
+// *postgres.Store).
+type QueryLayer interface {
+	GetUsageSeries(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]postgres.UsagePoint, error)
+	GetUsageTotals(ctx context.Context, orgID uuid.UUID, start, end time.Time, modelID *uuid.UUID) (postgres.UsageTotals, error)
+	GetUsageSeriesByProject(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]postgres.UsagePoint, error)
+	GetReliabilitySeries(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]postgres.ReliabilityPoint, error)
+	GetTopUsage(ctx context.Context, orgID uuid.UUID, dimension string, start, end time.Time, limit int, raw bool) ([]postgres.TopEntry, error)
+}
+
+// JobLister lists an org's export jobs (implemented by
+// *exports.ExportJobRepository).
+type JobLister interface {
+	ListExportJobs(ctx context.Context, orgID uuid.UUID, statusFilter *string) ([]exports.ExportJob, error)
+}
+
+// Config configures a Verifier.
+type Config struct {
+	Queries QueryLayer
+	Jobs    JobLister
+	// Pool is used for sampling and ground-truth queries.
+	Pool       *pgxpool.Pool
+	SampleSize int
+	Window     time.Duration
+	Logger     *zap.Logger
+}
+
+// Report is the compliance report of a verification run.
+type Report struct {
+	GeneratedAt time.Time   `json:"generatedAt"`
+	WindowStart time.Time   `json:"windowStart"`
+	WindowEnd   time.Time   `json:"windowEnd"`
+	OrgsSampled int         `json:"orgsSampled"`
+	Checks      int         `json:"checks"`
+	Violations  int         `json:"violations"`
+	Compliant   bool        `json:"compliant"`
+	Orgs        []OrgReport `json:"orgs"`
+}
+
+// OrgReport holds the checks run for one org.
+type OrgReport struct {
+	OrgID  uuid.UUID     `json:"orgId"`
+	Canary bool          `json:"canary,omitempty"` // Random org that owns no data
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one canary query.
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Rows   int    `json:"rows"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Verifier runs isolation checks.
+type Verifier struct {
+	cfg Config
+	now func() time.Time
+}
+
+// NewVerifier creates an isolation verifier.
+func NewVerifier(cfg Config) *Verifier {
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = DefaultSampleSize
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	return &Verifier{cfg: cfg, now: time.Now}
+}
+
+// Run samples orgs with data in the window, checks each of them and a canary
+// org, and returns the compliance report.
+func (v *Verifier) Run(ctx context.Context) (*Report, error) {
+	end := v.now().UTC().Truncate(time.Hour)
+	start := end.Add(-v.cfg.Window)
+
+	orgs, err := v.sampleOrgs(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		GeneratedAt: v.now().UTC(),
+		WindowStart: start,
+		WindowEnd:   end,
+		OrgsSampled: len(orgs),
+	}
+
+	for _, orgID := range orgs {
+		checks, err := v.checkOrg(ctx, orgID, start, end, false)
+		if err != nil {
+			return nil, fmt.Errorf("check org %s: %w", orgID, err)
+		}
+		report.add(OrgReport{OrgID: orgID, Checks: checks})
+	}
+
+	canary := uuid.New()
+	checks, err := v.checkOrg(ctx, canary, start, end, true)
+	if err != nil {
+		return nil, fmt.Errorf("check canary org: %w", err)
+	}
+	report.add(OrgReport{OrgID: canary, Canary: true, Checks: checks})
+
+	report.Compliant = report.Violations == 0
+	v.cfg.Logger.Info("isolation verification finished",
+		zap.Int("orgs", report.OrgsSampled),
+		zap.Int("checks", report.Checks),
+		zap.Int("violations", report.Violations),
+	)
+	return report, nil
+}
+
+func (r *Report) add(org OrgReport) {
+	for _, check := range org.Checks {
+		r.Checks++
+		if !check.Passed {
+			r.Violations++
+		}
+	}
+	r.Orgs = append(r.Orgs, org)
+}
+
+// sampleOrgs picks up to SampleSize random orgs with rollups in the window.
+func (v *Verifier) sampleOrgs(ctx context.Context, start, end time.Time) ([]uuid.UUID, error) {
+	rows, err := v.cfg.Pool.Query(ctx, `
+		SELECT organization_id
+		FROM (
+			SELECT DISTINCT organization_id
+			FROM analytics_hourly_rollups
+			WHERE bucket_start >= $1 AND bucket_start < $2
+		) orgs
+		ORDER BY random()
+		LIMIT $3
+	`, start, end, v.cfg.SampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("sample orgs: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []uuid.UUID
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			return nil, fmt.Errorf("scan org: %w", err)
+		}
+		orgs = append(orgs, orgID)
+	}
+	return orgs, rows.Err()
+}
+
+// checkOrg runs every canary query for orgID. For the canary org the ground
+// truth is empty, so any returned row is a violation.
+func (v *Verifier) checkOrg(ctx context.Context, orgID uuid.UUID, start, end time.Time, canary bool) ([]CheckResult, error) {
+	var truth groundTruth
+	if !canary {
+		var err error
+		if truth, err = v.loadGroundTruth(ctx, orgID, start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	var checks []CheckResult
+
+	for _, granularity := range []string{"hour", "day"} {
+		points, err := v.cfg.Queries.GetUsageSeries(ctx, orgID, start, end, granularity, nil)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		var sum int64
+		for _, p := range points {
+			values = append(values, uuidValue(p.ModelID))
+			sum += p.Invocations
+		}
+		want := truth.hourlyRequests
+		if granularity == "day" {
+			want = truth.dailyRequests
+		}
+		checks = append(checks, checkValues("usage_series_"+granularity, len(points), values, truth.models, sum, want))
+	}
+
+	totals, err := v.cfg.Queries.GetUsageTotals(ctx, orgID, start, end, nil)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, checkValues("usage_totals", 1, nil, nil, totals.Invocations, truth.dailyRequests))
+
+	projects, err := v.cfg.Queries.GetUsageSeriesByProject(ctx, orgID, start, end, "day", nil)
+	if err != nil {
+		return nil, err
+	}
+	var projectValues []string
+	var projectSum int64
+	for _, p := range projects {
+		value := ""
+		if p.ProjectID != nil {
+			value = *p.ProjectID
+		}
+		projectValues = append(projectValues, value)
+		projectSum += p.Invocations
+	}
+	checks = append(checks, checkValues("usage_by_project", len(projects), projectValues, truth.projects, projectSum, truth.events))
+
+	reliability, err := v.cfg.Queries.GetReliabilitySeries(ctx, orgID, start, end, "hour", nil)
+	if err != nil {
+		return nil, err
+	}
+	var reliabilityValues []string
+	for _, p := range reliability {
+		reliabilityValues = append(reliabilityValues, uuidValue(p.ModelID))
+	}
+	checks = append(checks, checkValues("reliability_series", len(reliability), reliabilityValues, truth.models, 0, 0))
+
+	owned := map[string]map[string]bool{
+		postgres.TopModels:     truth.models,
+		postgres.TopAPIKeys:    truth.apiKeys,
+		postgres.TopErrorCodes: truth.errorCodes,
+	}
+	for _, dimension := range []string{postgres.TopModels, postgres.TopAPIKeys, postgres.TopErrorCodes} {
+		for _, raw := range []bool{false, true} {
+			entries, err := v.cfg.Queries.GetTopUsage(ctx, orgID, dimension, start, end, topLimit, raw)
+			if err != nil {
+				return nil, err
+			}
+			var values []string
+			for _, e := range entries {
+				values = append(values, e.Value)
+			}
+			name := "top_" + strings.ReplaceAll(dimension, "-", "_") + "_rollup"
+			if raw {
+				name = "top_" + strings.ReplaceAll(dimension, "-", "_") + "_raw"
+			}
+			checks = append(checks, checkValues(name, len(entries), values, owned[dimension], 0, 0))
+		}
+	}
+
+	jobs, err := v.cfg.Jobs.ListExportJobs(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var foreignJobs []string
+	for _, job := range jobs {
+		if job.OrgID != orgID {
+			foreignJobs = append(foreignJobs, job.JobID.String())
+		}
+	}
+	jobCheck := CheckResult{Name: "export_jobs", Passed: len(foreignJobs) == 0, Rows: len(jobs)}
+	if !jobCheck.Passed {
+		jobCheck.Detail = "jobs of other orgs: " + listValues(foreignJobs)
+	}
+	checks = append(checks, jobCheck)
+
+	return checks, nil
+}
+
+// checkValues builds a check result: every value must be in owned, and got
+// must equal want.
+func checkValues(name string, rows int, values []string, owned map[string]bool, got, want int64) CheckResult {
+	result := CheckResult{Name: name, Rows: rows, Passed: true}
+
+	seen := map[string]bool{}
+	var foreign []string
+	for _, value := range values {
+		if !owned[value] && !seen[value] {
+			seen[value] = true
+			foreign = append(foreign, value)
+		}
+	}
+
+	var problems []string
+	if len(foreign) > 0 {
+		sort.Strings(foreign)
+		problems = append(problems, "values not owned by org: "+listValues(foreign))
+	}
+	if got != want {
+		problems = append(problems, fmt.Sprintf("request count %d, org owns %d", got, want))
+	}
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Detail = strings.Join(problems, "; ")
+	}
+	return result
+}
+
+func listValues(values []string) string {
+	if len(values) > maxDetailValues {
+		return fmt.Sprintf("%s (+%d more)", strings.Join(values[:maxDetailValues], ", "), len(values)-maxDetailValues)
+	}
+	return strings.Join(values, ", ")
+}
+
+func uuidValue(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,111 @@
+package isolation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// groundTruth is what an org owns in the window, read with explicit org
+// filters independent of the query layer.
+type groundTruth struct {
+	models     map[string]bool
+	projects   map[string]bool
+	apiKeys    map[string]bool
+	errorCodes map[string]bool
+
+	hourlyRequests int64
+	dailyRequests  int64
+	events         int64
+}
+
+// Value queries take org, start and end. Missing values are normalised the way
+// the query layer returns them.
+const (
+	ownedModelsQuery = `
+		SELECT COALESCE(model_id::TEXT, '') FROM analytics_hourly_rollups
+		WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3
+		UNION
+		SELECT COALESCE(model_id::TEXT, '') FROM analytics_daily_rollups
+		WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3
+		UNION
+		SELECT COALESCE(model_id::TEXT, '') FROM analytics.usage_events
+		WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+	`
+	ownedProjectsQuery = `
+		SELECT DISTINCT COALESCE(metadata->>'project_id', '') FROM analytics.usage_events
+		WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+	`
+	ownedAPIKeysQuery = `
+		SELECT value FROM analytics_hourly_dimension_rollups
+		WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3 AND dimension = 'api_key'
+		UNION
+		SELECT metadata->>'api_key_id' FROM analytics.usage_events
+		WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3 AND metadata->>'api_key_id' IS NOT NULL
+	`
+	ownedErrorCodesQuery = `
+		SELECT value FROM analytics_hourly_dimension_rollups
+		WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3 AND dimension = 'error_code'
+		UNION
+		SELECT COALESCE(NULLIF(error_code, ''), 'unknown') FROM analytics.usage_events
+		WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3 AND status = 'error'
+	`
+	ownedCountsQuery = `
+		SELECT
+			(SELECT COALESCE(SUM(request_count), 0) FROM analytics_hourly_rollups
+				WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3),
+			(SELECT COALESCE(SUM(request_count), 0) FROM analytics_daily_rollups
+				WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3),
+			(SELECT COUNT(*) FROM analytics.usage_events
+				WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3)
+	`
+)
+
+// loadGroundTruth reads what orgID owns in the window.
+func (v *Verifier) loadGroundTruth(ctx context.Context, orgID uuid.UUID, start, end time.Time) (groundTruth, error) {
+	var truth groundTruth
+	var err error
+
+	if truth.models, err = v.ownedValues(ctx, ownedModelsQuery, orgID, start, end); err != nil {
+		return truth, fmt.Errorf("load owned models: %w", err)
+	}
+	if truth.projects, err = v.ownedValues(ctx, ownedProjectsQuery, orgID, start, end); err != nil {
+		return truth, fmt.Errorf("load owned projects: %w", err)
+	}
+	if truth.apiKeys, err = v.ownedValues(ctx, ownedAPIKeysQuery, orgID, start, end); err != nil {
+		return truth, fmt.Errorf("load owned API keys: %w", err)
+	}
+	if truth.errorCodes, err = v.ownedValues(ctx, ownedErrorCodesQuery, orgID, start, end); err != nil {
+		return truth, fmt.Errorf("load owned error codes: %w", err)
+	}
+
+	err = v.cfg.Pool.QueryRow(ctx, ownedCountsQuery, orgID, start, end).Scan(
+		&truth.hourlyRequests,
+		&truth.dailyRequests,
+		&truth.events,
+	)
+	if err != nil {
+		return truth, fmt.Errorf("load owned request counts: %w", err)
+	}
+	return truth, nil
+}
+
+func (v *Verifier) ownedValues(ctx context.Context, query string, orgID uuid.UUID, start, end time.Time) (map[string]bool, error) {
+	rows, err := v.cfg.Pool.Query(ctx, query, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[string]bool{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values[value] = true
+	}
+	return values, rows.Err()
+}
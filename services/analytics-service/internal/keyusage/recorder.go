@@ -0,0 +1,148 @@
+// Package keyusage maintains per-API-key usage summaries in Redis.
+//
+// Purpose:
+//   The ingestion processor feeds each persisted batch through Recorder so
+//   user-org-service can show recent request counts, the last error and total
+//   spend next to every API key without querying analytics directly.
+//
+// Debugging Notes:
+//   - Events are attributed via metadata["api_key_id"]; events without it are skipped
+//   - Requests are counted in hourly buckets (keyusage:{id}:h:{unix hour}) kept for 25h
+//   - Totals and the last error live in the keyusage:{id} hash, kept for 30 days
+//     after the key was last seen
+//   - The key layout is read by user-org-service internal/keyusage; keep them in sync
+//   - Redelivered batches are counted again; the summary is approximate by design
+//
+package keyusage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/storage/postgres"
+)
+
+const (
+	metadataAPIKeyID = "api_key_id"
+
+	bucketTTL  = 25 * time.Hour
+	summaryTTL = 30 * 24 * time.Hour
+)
+
+// Redis hash fields of the per-key summary.
+const (
+	fieldTotalSpendCents = "total_spend_cents"
+	fieldTotalRequests   = "total_requests"
+	fieldLastSeenAt      = "last_seen_at"
+	fieldLastErrorCode   = "last_error_code"
+	fieldLastErrorAt     = "last_error_at"
+)
+
+// Recorder writes per-key usage summaries.
+type Recorder struct {
+	client *redis.Client
+}
+
+// NewRecorder creates a recorder backed by client.
+func NewRecorder(client *redis.Client) *Recorder {
+	return &Recorder{client: client}
+}
+
+type keyTotals struct {
+	requests   int64
+	spendCents float64
+	hours      map[int64]int64
+	lastSeen   time.Time
+	lastError  *postgres.UsageEvent
+}
+
+// RecordKeyUsage folds a persisted batch into the per-key summaries.
+func (r *Recorder) RecordKeyUsage(ctx context.Context, events []postgres.UsageEvent) error {
+	totals := make(map[string]*keyTotals)
+	for i := range events {
+		e := &events[i]
+		keyID, _ := e.Metadata[metadataAPIKeyID].(string)
+		if keyID == "" {
+			continue
+		}
+		t, ok := totals[keyID]
+		if !ok {
+			t = &keyTotals{hours: make(map[int64]int64)}
+			totals[keyID] = t
+		}
+		t.requests++
+		t.spendCents += e.CostEstimateCents
+		t.hours[e.OccurredAt.Unix()/3600]++
+		if e.OccurredAt.After(t.lastSeen) {
+			t.lastSeen = e.OccurredAt
+		}
+		if e.Status != "success" && (t.lastError == nil || e.OccurredAt.After(t.lastError.OccurredAt)) {
+			t.lastError = e
+		}
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+
+	oldestHour := time.Now().Add(-bucketTTL).Unix() / 3600
+	pipe := r.client.Pipeline()
+	for keyID, t := range totals {
+		for hour, count := range t.hours {
+			// Late events outside the window would never be read
+			if hour < oldestHour {
+				continue
+			}
+			bucket := BucketKey(keyID, hour)
+			pipe.IncrBy(ctx, bucket, count)
+			pipe.Expire(ctx, bucket, bucketTTL)
+		}
+
+		summary := SummaryKey(keyID)
+		pipe.HIncrBy(ctx, summary, fieldTotalRequests, t.requests)
+		pipe.HIncrByFloat(ctx, summary, fieldTotalSpendCents, t.spendCents)
+		// Only move last_seen_at forward; batches can arrive out of order
+		pipe.Eval(ctx, maxFieldScript, []string{summary}, fieldLastSeenAt, t.lastSeen.Unix())
+		if t.lastError != nil {
+			code := t.lastError.ErrorCode
+			if code == "" {
+				code = t.lastError.Status
+			}
+			pipe.Eval(ctx, lastErrorScript, []string{summary}, t.lastError.OccurredAt.Unix(), code)
+		}
+		pipe.Expire(ctx, summary, summaryTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis record key usage: %w", err)
+	}
+	return nil
+}
+
+// SummaryKey is the Redis hash holding totals for an API key.
+func SummaryKey(keyID string) string {
+	return "keyusage:" + keyID
+}
+
+// BucketKey is the Redis counter for an API key's requests in one hour.
+func BucketKey(keyID string, unixHour int64) string {
+	return "keyusage:" + keyID + ":h:" + strconv.FormatInt(unixHour, 10)
+}
+
+// maxFieldScript sets hash field ARGV[1] to ARGV[2] unless it already holds a larger value.
+const maxFieldScript = `
+local cur = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if tonumber(ARGV[2]) > cur then
+  redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return 0`
+
+// lastErrorScript records the error code ARGV[2] at unix time ARGV[1] unless a newer error is stored.
+const lastErrorScript = `
+local cur = tonumber(redis.call('HGET', KEYS[1], '` + fieldLastErrorAt + `') or '0')
+if tonumber(ARGV[1]) >= cur then
+  redis.call('HSET', KEYS[1], '` + fieldLastErrorAt + `', ARGV[1], '` + fieldLastErrorCode + `', ARGV[2])
+end
+return 0`
@@ -2,7 +2,9 @@
 //
 // Purpose:
 //   This package provides RBAC middleware that integrates with shared/go/auth
-//   to enforce role-based access control on analytics API endpoints.
+//   to enforce role-based access control on analytics API endpoints. With a
+//   token verifier, actors come from user-org-service access tokens (see
+//   tokens.go); without one, from the X-Actor-* headers.
 //
 // Dependencies:
 //   - github.com/otherjamesbrown/ai-aas/shared/go/auth: Shared authorization middleware
@@ -15,6 +17,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ai-aas/shared-go/auth/tokens"
 	"github.com/otherjamesbrown/ai-aas/shared/go/auth"
 	"go.uber.org/zap"
 )
@@ -25,6 +28,11 @@ type RBACConfig struct {
 	// EnableRBAC controls whether RBAC is enforced (default: true)
 	// Set to false for development/testing
 	EnableRBAC bool
+	// Verifier authenticates platform access tokens; nil falls back to
+	// header-based actors
+	Verifier *tokens.Verifier
+	// AdminScope is the token scope granting cross-org access
+	AdminScope string
 }
 
 // analyticsPolicy defines role-based access policies for analytics endpoints.
@@ -35,11 +43,34 @@ var analyticsPolicy = map[string][]string{
 		"analytics:usage:read",
 		"admin",
 	},
+	// Top-N breakdowns
+	"GET:/analytics/v1/orgs/{id}/usage/top/models": {
+		"analytics:usage:read",
+		"admin",
+	},
+	"GET:/analytics/v1/orgs/{id}/usage/top/keys": {
+		"analytics:usage:read",
+		"admin",
+	},
+	"GET:/analytics/v1/orgs/{id}/usage/top/error-codes": {
+		"analytics:usage:read",
+		"admin",
+	},
 	// Reliability API
 	"GET:/analytics/v1/orgs/{id}/reliability": {
 		"analytics:reliability:read",
 		"admin",
 	},
+	// Spend forecast (one org with orgId, otherwise every org with spend)
+	"GET:/analytics/v1/spend/forecast": {
+		"analytics:spend:read",
+		"admin",
+	},
+	// Freshness API (cross-org pipeline lag)
+	"GET:/analytics/v1/freshness": {
+		"analytics:freshness:read",
+		"admin",
+	},
 	// Export API - Create
 	"POST:/analytics/v1/orgs/{id}/exports": {
 		"analytics:exports:create",
@@ -60,6 +91,21 @@ var analyticsPolicy = map[string][]string{
 		"analytics:exports:download",
 		"admin",
 	},
+	// Export API - Cancel
+	"POST:/analytics/v1/orgs/{id}/exports/{id}/cancel": {
+		"analytics:exports:create",
+		"admin",
+	},
+	// Export API - Retry
+	"POST:/analytics/v1/orgs/{id}/exports/{id}/retry": {
+		"analytics:exports:create",
+		"admin",
+	},
+	// Export API - Delete
+	"DELETE:/analytics/v1/orgs/{id}/exports/{id}": {
+		"analytics:exports:create",
+		"admin",
+	},
 }
 
 // buildPolicyEngine creates an auth.Engine from the analytics policy.
@@ -100,13 +146,20 @@ func RBAC(cfg RBACConfig) func(http.Handler) http.Handler {
 		cfg.Logger.Fatal("failed to build RBAC policy engine", zap.Error(err))
 	}
 
-	// Use header-based actor extraction (can be extended to support token-based)
+	// Header-based actors unless platform tokens are configured
 	extractor := auth.HeaderExtractor
+	var enrichers []auth.Enricher
+	authenticate := func(next http.Handler) http.Handler { return next }
+	if cfg.Verifier != nil {
+		extractor = tokenExtractor(cfg.AdminScope)
+		enrichers = append(enrichers, auth.ClaimsEnricher)
+		authenticate = tokenAuth(cfg.Verifier, cfg.AdminScope, cfg.Logger)
+	}
 
 	// Wrap the shared auth middleware with path normalization
 	return func(next http.Handler) http.Handler {
-		baseMiddleware := auth.Middleware(engine, extractor)
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseMiddleware := auth.DeciderMiddleware(engine, extractor, enrichers...)
+		return authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Normalize path before passing to middleware
 			// Create a new request with normalized path for policy matching
 			normalizedPath := normalizePath(r.URL.Path)
@@ -121,7 +174,7 @@ func RBAC(cfg RBACConfig) func(http.Handler) http.Handler {
 			
 			// Restore original path (though it's probably not needed after ServeHTTP)
 			r.URL.Path = originalPath
-		})
+		}))
 	}
 }
 
@@ -0,0 +1,167 @@
+// Package middleware provides platform token authentication for the analytics API.
+//
+// Purpose:
+//   Callers present user-org-service access tokens. The token's org roles are
+//   mapped to analytics permissions for the RBAC policy, and the org a caller
+//   may read comes from the token: org paths must name the token's org, and
+//   orgId query parameters are pinned to it. Platform admins (tokens with the
+//   admin scope) keep cross-org access.
+//
+// Roles:
+//   org_admin -> every analytics permission, finance and engineering profiles
+//   finance   -> usage, spend and exports; finance profile
+//   viewer    -> usage and reliability; engineering profile
+//   owner, admin and member are the roles user-org-service assigns on signup,
+//   onboarding and invitation; they grant the same as org_admin, org_admin
+//   and viewer
+//
+// Debugging Notes:
+//   - Missing or rejected tokens are 401; an unreachable user-org-service is 503
+//   - Unknown platform roles grant nothing; a token without roles is denied
+//     by the policy
+//
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/requestid"
+	"github.com/otherjamesbrown/ai-aas/shared/go/auth"
+	"go.uber.org/zap"
+)
+
+// rolePermissions maps platform org roles to analytics permissions.
+var rolePermissions = map[string][]string{
+	"org_admin": adminPermissions,
+	"owner":     adminPermissions,
+	"admin":     adminPermissions,
+	"finance": {
+		"analytics:usage:read",
+		"analytics:spend:read",
+		"analytics:exports:create",
+		"analytics:exports:read",
+		"analytics:exports:download",
+		"analytics:profile:finance",
+	},
+	"viewer": viewerPermissions,
+	"member": viewerPermissions,
+}
+
+var adminPermissions = []string{
+	"analytics:usage:read",
+	"analytics:reliability:read",
+	"analytics:spend:read",
+	"analytics:exports:create",
+	"analytics:exports:read",
+	"analytics:exports:download",
+	"analytics:profile:finance",
+	"analytics:profile:engineering",
+}
+
+var viewerPermissions = []string{
+	"analytics:usage:read",
+	"analytics:reliability:read",
+	"analytics:profile:engineering",
+}
+
+// PermissionsForRoles returns the analytics permissions granted by platform roles.
+func PermissionsForRoles(roles []string) []string {
+	seen := map[string]bool{}
+	var permissions []string
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// orgPathRegex captures the org of /analytics/v1/orgs/{orgId}/... paths.
+var orgPathRegex = regexp.MustCompile(`^/analytics/v1/orgs/([^/]+)`)
+
+// tokenAuth verifies the bearer token and enforces its org scope before
+// handing the request on with the token's claims in context.
+func tokenAuth(verifier *tokens.Verifier, adminScope string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || strings.TrimSpace(token) == "" {
+				writeAuthError(w, http.StatusUnauthorized, "bearer token required")
+				return
+			}
+			claims, err := verifier.Verify(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				if errors.Is(err, tokens.ErrInvalidToken) {
+					writeAuthError(w, http.StatusUnauthorized, "invalid or expired token")
+					return
+				}
+				logger.Warn("token introspection failed", zap.Error(err))
+				writeAuthError(w, http.StatusServiceUnavailable, "token validation unavailable")
+				return
+			}
+
+			if !claims.HasScope(adminScope) {
+				if claims.OrgID == "" {
+					writeAuthError(w, http.StatusForbidden, "token is not scoped to an organization")
+					return
+				}
+				if m := orgPathRegex.FindStringSubmatch(r.URL.Path); m != nil && !strings.EqualFold(m[1], claims.OrgID) {
+					writeAuthError(w, http.StatusForbidden, "token does not grant access to this organization")
+					return
+				}
+				// Org-wide queries are pinned to the token's org
+				query := r.URL.Query()
+				if orgID := query.Get("orgId"); orgID != "" && !strings.EqualFold(orgID, claims.OrgID) {
+					writeAuthError(w, http.StatusForbidden, "token does not grant access to this organization")
+					return
+				}
+				query.Set("orgId", claims.OrgID)
+				r.URL.RawQuery = query.Encode()
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// tokenExtractor derives the actor from the claims set by tokenAuth.
+func tokenExtractor(adminScope string) auth.Extractor {
+	return func(r *http.Request) auth.Actor {
+		claims, ok := auth.ClaimsFromContext(r.Context())
+		if !ok {
+			return auth.Actor{}
+		}
+		subject := claims.UserID
+		if subject == "" {
+			subject = claims.Subject
+		}
+		roles := PermissionsForRoles(claims.Roles)
+		if claims.HasScope(adminScope) {
+			roles = append(roles, "admin")
+		}
+		return auth.Actor{Subject: subject, Roles: roles}
+	}
+}
+
+// writeAuthError writes a problem+json error like the API handlers do.
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	body := map[string]interface{}{
+		"status": status,
+		"title":  http.StatusText(status),
+		"detail": message,
+	}
+	if id := w.Header().Get(requestid.RequestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	orgA       = "6f1c2d3e-0000-4000-8000-00000000000a"
+	orgB       = "6f1c2d3e-0000-4000-8000-00000000000b"
+	adminScope = "platform:admin"
+)
+
+// fakeIntrospector returns the claims registered for each token.
+type fakeIntrospector struct {
+	claims map[string]tokens.Claims
+	err    error
+}
+
+func (f fakeIntrospector) Introspect(_ context.Context, token string) (tokens.Claims, error) {
+	if f.err != nil {
+		return tokens.Claims{}, f.err
+	}
+	claims, ok := f.claims[token]
+	if !ok {
+		return tokens.Claims{}, tokens.ErrInactive
+	}
+	return claims, nil
+}
+
+func activeClaims(orgID string, roles ...string) tokens.Claims {
+	return tokens.Claims{Active: true, OrgID: orgID, UserID: "user-1", Roles: roles, ExpiresAt: time.Now().Add(time.Hour)}
+}
+
+// newRBACHandler wires RBAC with platform tokens in front of a handler that
+// records the orgId query it was given.
+func newRBACHandler(introspector tokens.Introspector, gotOrgID *string) http.Handler {
+	verifier := tokens.NewVerifier(tokens.VerifierConfig{Introspector: introspector, CacheTTL: -1})
+	rbac := RBAC(RBACConfig{Logger: zap.NewNop(), EnableRBAC: true, Verifier: verifier, AdminScope: adminScope})
+	return rbac(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotOrgID = r.URL.Query().Get("orgId")
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestTokenAuthOrgScope(t *testing.T) {
+	admin := activeClaims(orgA, "viewer")
+	admin.Scopes = []string{adminScope}
+	noOrg := activeClaims(orgA, "org_admin")
+	noOrg.OrgID = ""
+	introspector := fakeIntrospector{claims: map[string]tokens.Claims{
+		"org-admin-a": activeClaims(orgA, "org_admin"),
+		"finance-a":   activeClaims(orgA, "finance"),
+		"viewer-a":    activeClaims(orgA, "viewer"),
+		"owner-a":     activeClaims(orgA, "owner"),
+		"member-a":    activeClaims(orgA, "member"),
+		"no-roles-a":  activeClaims(orgA),
+		"no-org":      noOrg,
+		"platform":    admin,
+	}}
+
+	tests := []struct {
+		name      string
+		token     string
+		method    string
+		target    string
+		want      int
+		wantOrgID string
+	}{
+		{name: "own org path", token: "viewer-a", target: "/analytics/v1/orgs/" + orgA + "/usage", want: http.StatusOK, wantOrgID: orgA},
+		{name: "other org path", token: "org-admin-a", target: "/analytics/v1/orgs/" + orgB + "/usage", want: http.StatusForbidden},
+		{name: "other org path with own orgId", token: "org-admin-a", target: "/analytics/v1/orgs/" + orgB + "/exports?orgId=" + orgA, want: http.StatusForbidden},
+		{name: "other org export download", token: "finance-a", target: "/analytics/v1/orgs/" + orgB + "/exports/" + orgA + "/download", want: http.StatusForbidden},
+		{name: "other org export create", token: "org-admin-a", method: http.MethodPost, target: "/analytics/v1/orgs/" + orgB + "/exports", want: http.StatusForbidden},
+		{name: "other org orgId query", token: "finance-a", target: "/analytics/v1/spend/forecast?orgId=" + orgB, want: http.StatusForbidden},
+		{name: "own org orgId query", token: "finance-a", target: "/analytics/v1/spend/forecast?orgId=" + orgA, want: http.StatusOK, wantOrgID: orgA},
+		{name: "missing orgId pinned to token org", token: "finance-a", target: "/analytics/v1/spend/forecast", want: http.StatusOK, wantOrgID: orgA},
+		{name: "token without org", token: "no-org", target: "/analytics/v1/spend/forecast", want: http.StatusForbidden},
+		{name: "platform admin reads other org", token: "platform", target: "/analytics/v1/orgs/" + orgB + "/usage", want: http.StatusOK},
+		{name: "platform admin orgId kept", token: "platform", target: "/analytics/v1/spend/forecast?orgId=" + orgB, want: http.StatusOK, wantOrgID: orgB},
+		{name: "signup owner reads spend", token: "owner-a", target: "/analytics/v1/spend/forecast", want: http.StatusOK, wantOrgID: orgA},
+		{name: "signup owner creates export", token: "owner-a", method: http.MethodPost, target: "/analytics/v1/orgs/" + orgA + "/exports", want: http.StatusOK, wantOrgID: orgA},
+		{name: "owner other org path", token: "owner-a", target: "/analytics/v1/orgs/" + orgB + "/usage", want: http.StatusForbidden},
+		{name: "member reads usage", token: "member-a", target: "/analytics/v1/orgs/" + orgA + "/usage", want: http.StatusOK, wantOrgID: orgA},
+		{name: "member without spend", token: "member-a", target: "/analytics/v1/spend/forecast", want: http.StatusForbidden},
+		{name: "role without permission", token: "viewer-a", target: "/analytics/v1/spend/forecast", want: http.StatusForbidden},
+		{name: "token without roles", token: "no-roles-a", target: "/analytics/v1/orgs/" + orgA + "/usage", want: http.StatusForbidden},
+		{name: "missing token", target: "/analytics/v1/orgs/" + orgA + "/usage", want: http.StatusUnauthorized},
+		{name: "rejected token", token: "revoked", target: "/analytics/v1/orgs/" + orgA + "/usage", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOrgID string
+			handler := newRBACHandler(introspector, &gotOrgID)
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want == http.StatusOK {
+				require.Equal(t, tt.wantOrgID, gotOrgID)
+			}
+		})
+	}
+}
+
+func TestTokenAuthIntrospectionUnavailable(t *testing.T) {
+	var gotOrgID string
+	handler := newRBACHandler(fakeIntrospector{err: errors.New("connection refused")}, &gotOrgID)
+	req := httptest.NewRequest(http.MethodGet, "/analytics/v1/orgs/"+orgA+"/usage", nil)
+	req.Header.Set("Authorization", "Bearer viewer-a")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
+
+func TestPermissionsForRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "no roles", roles: nil, want: nil},
+		{name: "unknown role", roles: []string{"billing_contact"}, want: nil},
+		{name: "owner matches org_admin", roles: []string{"owner"}, want: adminPermissions},
+		{name: "member matches viewer", roles: []string{"member"}, want: viewerPermissions},
+		{
+			name:  "org_admin",
+			roles: []string{"org_admin"},
+			want: []string{
+				"analytics:usage:read",
+				"analytics:reliability:read",
+				"analytics:spend:read",
+				"analytics:exports:create",
+				"analytics:exports:read",
+				"analytics:exports:download",
+				"analytics:profile:finance",
+				"analytics:profile:engineering",
+			},
+		},
+		{
+			name:  "finance",
+			roles: []string{"finance"},
+			want: []string{
+				"analytics:usage:read",
+				"analytics:spend:read",
+				"analytics:exports:create",
+				"analytics:exports:read",
+				"analytics:exports:download",
+				"analytics:profile:finance",
+			},
+		},
+		{
+			name:  "viewer",
+			roles: []string{"viewer"},
+			want: []string{
+				"analytics:usage:read",
+				"analytics:reliability:read",
+				"analytics:profile:engineering",
+			},
+		},
+		{
+			name:  "viewer and finance are merged without duplicates",
+			roles: []string{"viewer", "finance", "viewer"},
+			want: []string{
+				"analytics:usage:read",
+				"analytics:reliability:read",
+				"analytics:profile:engineering",
+				"analytics:spend:read",
+				"analytics:exports:create",
+				"analytics:exports:read",
+				"analytics:exports:download",
+				"analytics:profile:finance",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, PermissionsForRoles(tt.roles))
+		})
+	}
+
+	// No role grants the cross-org freshness permission; that needs the admin scope
+	for role := range rolePermissions {
+		require.NotContains(t, PermissionsForRoles([]string{role}), "analytics:freshness:read", role)
+	}
+}
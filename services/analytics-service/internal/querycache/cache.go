@@ -0,0 +1,162 @@
+// Package querycache provides a Redis-backed cache for analytics query results.
+//
+// Purpose:
+//   Dashboards repeat the same usage queries every few seconds. This package
+//   caches query results keyed on the normalized query parameters so repeated
+//   requests skip Postgres until the underlying rollups change.
+//
+// Invalidation:
+//   Every org has a generation counter (analytics:query:gen:{orgId}) that is part
+//   of each cache key. The rollup worker increments it for every org whose
+//   buckets it rewrote, so stale entries are never read again and simply expire.
+//
+// TTL:
+//   Results for ranges that end before the last completed rollup hour cannot
+//   change until a rollup rewrites them, so they are kept for TTL. Ranges that
+//   reach into data not yet rolled up use the much shorter RecentTTL.
+//
+// A nil *Cache is valid and caches nothing, so callers need no enabled checks.
+//
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Cache stores query results in Redis.
+type Cache struct {
+	client    *redis.Client
+	logger    *zap.Logger
+	ttl       time.Duration
+	recentTTL time.Duration
+}
+
+// Config holds cache configuration.
+type Config struct {
+	Client *redis.Client
+	Logger *zap.Logger
+	// TTL applies to results covering only rolled-up buckets.
+	TTL time.Duration
+	// RecentTTL applies to results that include buckets not yet rolled up.
+	RecentTTL time.Duration
+}
+
+// NewCache creates a new query cache.
+func NewCache(cfg Config) *Cache {
+	c := &Cache{
+		client:    cfg.Client,
+		logger:    cfg.Logger,
+		ttl:       cfg.TTL,
+		recentTTL: cfg.RecentTTL,
+	}
+	if c.logger == nil {
+		c.logger = zap.NewNop()
+	}
+	if c.ttl <= 0 {
+		c.ttl = time.Hour
+	}
+	if c.recentTTL <= 0 {
+		c.recentTTL = 30 * time.Second
+	}
+	return c
+}
+
+// TTLFor returns how long a result for a range ending at end may be cached,
+// given when the last rollup ran. Rollups cover whole hours up to the hour in
+// which they ran.
+func (c *Cache) TTLFor(end, lastRollupAt time.Time) time.Duration {
+	if c == nil {
+		return 0
+	}
+	if !lastRollupAt.IsZero() && !end.After(lastRollupAt.Truncate(time.Hour)) {
+		return c.ttl
+	}
+	return c.recentTTL
+}
+
+// Get loads the cached result of query for orgID into dest. It reports
+// whether there was a hit; Redis errors are logged and treated as misses.
+func (c *Cache) Get(ctx context.Context, orgID uuid.UUID, query string, dest any) bool {
+	if c == nil {
+		return false
+	}
+	key, err := c.key(ctx, orgID, query)
+	if err != nil {
+		c.logger.Warn("query cache generation lookup failed", zap.String("org_id", orgID.String()), zap.Error(err))
+		return false
+	}
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false
+	}
+	if err != nil {
+		c.logger.Warn("query cache get failed", zap.String("org_id", orgID.String()), zap.Error(err))
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		c.logger.Warn("query cache entry unreadable", zap.String("org_id", orgID.String()), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// Set stores value as the result of query for orgID.
+func (c *Cache) Set(ctx context.Context, orgID uuid.UUID, query string, value any, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("query cache marshal failed", zap.Error(err))
+		return
+	}
+	key, err := c.key(ctx, orgID, query)
+	if err != nil {
+		c.logger.Warn("query cache generation lookup failed", zap.String("org_id", orgID.String()), zap.Error(err))
+		return
+	}
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		c.logger.Warn("query cache set failed", zap.String("org_id", orgID.String()), zap.Error(err))
+	}
+}
+
+// InvalidateOrgs drops every cached result for the given orgs by advancing
+// their generation.
+func (c *Cache) InvalidateOrgs(ctx context.Context, orgIDs []uuid.UUID) error {
+	if c == nil || len(orgIDs) == 0 {
+		return nil
+	}
+	pipe := c.client.Pipeline()
+	for _, orgID := range orgIDs {
+		pipe.Incr(ctx, generationKey(orgID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("advance query cache generations: %w", err)
+	}
+	return nil
+}
+
+// key returns the Redis key for query under the org's current generation.
+func (c *Cache) key(ctx context.Context, orgID uuid.UUID, query string) (string, error) {
+	generation, err := c.client.Get(ctx, generationKey(orgID)).Result()
+	if err == redis.Nil {
+		generation = "0"
+	} else if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(query))
+	return fmt.Sprintf("analytics:query:%s:%s:%s", orgID.String(), generation, hex.EncodeToString(sum[:16])), nil
+}
+
+func generationKey(orgID uuid.UUID) string {
+	return fmt.Sprintf("analytics:query:gen:%s", orgID.String())
+}
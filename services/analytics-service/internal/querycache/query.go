@@ -0,0 +1,37 @@
+package querycache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageQuery identifies a usage series request.
+type UsageQuery struct {
+	Start       time.Time
+	End         time.Time
+	Granularity string
+	GroupBy     string
+	ModelID     *uuid.UUID
+}
+
+// String returns the normalized form used as the cache key, so equivalent
+// requests (different time zones, omitted defaults) share an entry.
+func (q UsageQuery) String() string {
+	groupBy := q.GroupBy
+	if groupBy == "" {
+		groupBy = "model"
+	}
+	model := "*"
+	if q.ModelID != nil {
+		model = q.ModelID.String()
+	}
+	return fmt.Sprintf("usage:v1|start=%s|end=%s|granularity=%s|group_by=%s|model=%s",
+		q.Start.UTC().Format(time.RFC3339),
+		q.End.UTC().Format(time.RFC3339),
+		q.Granularity,
+		groupBy,
+		model,
+	)
+}
@@ -0,0 +1,122 @@
+// Package sketch provides mergeable latency histograms stored with rollups.
+//
+// Purpose:
+//   Percentiles cannot be combined from other percentiles, so rollups keep a
+//   log-bucketed histogram of latency_ms per bucket instead (the DDSketch
+//   layout). Latency v lands in bucket ceil(ln(v) / ln(Gamma)); buckets from
+//   hourly or daily rollups add up, and any percentile can be read back with
+//   about 1% relative error.
+//
+// Storage:
+//   Histograms are JSONB objects mapping bucket index to count, e.g.
+//   {"0": 3, "349": 120}, in the latency_histogram column of
+//   analytics_hourly_rollups and analytics_daily_rollups. The rollup worker
+//   builds them in SQL with IndexSQL so raw events are scanned only once.
+//
+package sketch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Gamma is the ratio between consecutive bucket bounds. Estimates are within
+// (Gamma-1)/(Gamma+1), about 1%, of the true latency.
+const Gamma = 1.02
+
+// IndexSQL returns the SQL expression mapping a latency column to its bucket index.
+func IndexSQL(column string) string {
+	return fmt.Sprintf("CEIL(LN(GREATEST(%s, 1)) / LN(%g))::INTEGER", column, Gamma)
+}
+
+// Index returns the bucket index for a latency in milliseconds, matching IndexSQL.
+func Index(latencyMS float64) int {
+	if latencyMS < 1 {
+		latencyMS = 1
+	}
+	return int(math.Ceil(math.Log(latencyMS) / math.Log(Gamma)))
+}
+
+// Histogram counts latencies per bucket index.
+type Histogram map[int]int64
+
+// Parse decodes a stored histogram. An empty or null value yields an empty histogram.
+func Parse(data []byte) (Histogram, error) {
+	h := Histogram{}
+	if len(data) == 0 || string(data) == "null" {
+		return h, nil
+	}
+	var raw map[string]int64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode latency histogram: %w", err)
+	}
+	for key, count := range raw {
+		idx, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("decode latency histogram: bucket %q: %w", key, err)
+		}
+		h[idx] += count
+	}
+	return h, nil
+}
+
+// Add records a latency.
+func (h Histogram) Add(latencyMS float64) {
+	h[Index(latencyMS)]++
+}
+
+// Merge adds other's counts into h.
+func (h Histogram) Merge(other Histogram) {
+	for idx, count := range other {
+		h[idx] += count
+	}
+}
+
+// Count returns the number of recorded latencies.
+func (h Histogram) Count() int64 {
+	var total int64
+	for _, count := range h {
+		total += count
+	}
+	return total
+}
+
+// Quantile returns the estimated latency in milliseconds at q (0..1), or 0
+// for an empty histogram.
+func (h Histogram) Quantile(q float64) int {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	indexes := make([]int, 0, len(h))
+	for idx := range h {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	// Rank of the q-th value, 0-based, as in nearest-rank percentiles
+	rank := int64(math.Ceil(q*float64(total))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	var seen int64
+	for _, idx := range indexes {
+		seen += h[idx]
+		if seen > rank {
+			return estimate(idx)
+		}
+	}
+	return estimate(indexes[len(indexes)-1])
+}
+
+// estimate returns the value within bucket idx, (Gamma^(idx-1), Gamma^idx],
+// that minimizes relative error.
+func estimate(idx int) int {
+	if idx <= 0 {
+		return 1
+	}
+	return int(math.Round(2 * math.Pow(Gamma, float64(idx)) / (Gamma + 1)))
+}
@@ -0,0 +1,139 @@
+package sketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// relativeError is the bound Quantile estimates stay within.
+var relativeError = (Gamma - 1) / (Gamma + 1)
+
+func TestIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencyMS float64
+		want      int
+	}{
+		{name: "zero clamps to 1ms", latencyMS: 0, want: 0},
+		{name: "negative clamps to 1ms", latencyMS: -5, want: 0},
+		{name: "sub-millisecond clamps to 1ms", latencyMS: 0.4, want: 0},
+		{name: "1ms", latencyMS: 1, want: 0},
+		{name: "just above 1ms", latencyMS: 1.01, want: 1},
+		{name: "100ms", latencyMS: 100, want: 233},
+		{name: "1s", latencyMS: 1000, want: 349},
+		{name: "1 minute", latencyMS: 60000, want: 556},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Index(tt.latencyMS))
+		})
+	}
+}
+
+func TestIndexBucketBounds(t *testing.T) {
+	// Every latency must fall inside its bucket, (Gamma^(idx-1), Gamma^idx]
+	for _, v := range []float64{1.5, 7, 42, 250, 999, 12345, 3.6e6} {
+		idx := Index(v)
+		require.Greater(t, v, math.Pow(Gamma, float64(idx-1)), "latency %v below bucket %d", v, idx)
+		require.LessOrEqual(t, v, math.Pow(Gamma, float64(idx))*(1+1e-12), "latency %v above bucket %d", v, idx)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	uniform := Histogram{}
+	for v := 1; v <= 1000; v++ {
+		uniform.Add(float64(v))
+	}
+
+	tests := []struct {
+		name string
+		h    Histogram
+		q    float64
+		want float64 // exact value the estimate approximates; 0 means exactly 0
+	}{
+		{name: "empty", h: Histogram{}, q: 0.5, want: 0},
+		{name: "single value p50", h: histogramOf(120), q: 0.5, want: 120},
+		{name: "single value p99", h: histogramOf(120), q: 0.99, want: 120},
+		{name: "sub-millisecond reads as 1ms", h: histogramOf(0.2), q: 0.5, want: 1},
+		{name: "q below range uses the minimum", h: histogramOf(10, 500), q: -1, want: 10},
+		{name: "q zero uses the minimum", h: histogramOf(10, 500), q: 0, want: 10},
+		{name: "q above range uses the maximum", h: histogramOf(10, 500), q: 2, want: 500},
+		{name: "nearest rank p50 of two", h: histogramOf(10, 500), q: 0.5, want: 10},
+		{name: "uniform p50", h: uniform, q: 0.5, want: 500},
+		{name: "uniform p95", h: uniform, q: 0.95, want: 950},
+		{name: "uniform p99", h: uniform, q: 0.99, want: 990},
+		{name: "uniform max", h: uniform, q: 1, want: 1000},
+		{name: "skewed p99 finds the tail", h: skewed(), q: 0.99, want: 2000},
+		{name: "skewed p50 ignores the tail", h: skewed(), q: 0.5, want: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.h.Quantile(tt.q)
+			if tt.want == 0 {
+				require.Zero(t, got)
+				return
+			}
+			// Estimates are rounded to whole milliseconds
+			require.InDelta(t, tt.want, got, tt.want*relativeError+0.5)
+		})
+	}
+}
+
+func TestMergeMatchesCombinedHistogram(t *testing.T) {
+	hourA, hourB, combined := Histogram{}, Histogram{}, Histogram{}
+	for v := 1; v <= 300; v++ {
+		hourA.Add(float64(v))
+		combined.Add(float64(v))
+	}
+	for v := 200; v <= 4000; v += 7 {
+		hourB.Add(float64(v))
+		combined.Add(float64(v))
+	}
+
+	day := Histogram{}
+	day.Merge(hourA)
+	day.Merge(hourB)
+	require.Equal(t, combined, day)
+	require.Equal(t, hourA.Count()+hourB.Count(), day.Count())
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		require.Equal(t, combined.Quantile(q), day.Quantile(q))
+	}
+}
+
+func TestParse(t *testing.T) {
+	h, err := Parse(nil)
+	require.NoError(t, err)
+	require.Empty(t, h)
+
+	h, err = Parse([]byte("null"))
+	require.NoError(t, err)
+	require.Empty(t, h)
+
+	h, err = Parse([]byte(`{"0": 3, "349": 120}`))
+	require.NoError(t, err)
+	require.Equal(t, Histogram{0: 3, 349: 120}, h)
+
+	_, err = Parse([]byte(`{"p99": 1}`))
+	require.Error(t, err)
+}
+
+func histogramOf(latencies ...float64) Histogram {
+	h := Histogram{}
+	for _, v := range latencies {
+		h.Add(v)
+	}
+	return h
+}
+
+// skewed is 98 fast requests at 20ms and 2 slow ones at 2s.
+func skewed() Histogram {
+	h := Histogram{}
+	for i := 0; i < 98; i++ {
+		h.Add(20)
+	}
+	h.Add(2000)
+	h.Add(2000)
+	return h
+}
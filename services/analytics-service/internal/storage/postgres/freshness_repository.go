@@ -12,13 +12,16 @@ import (
 
 // GetAllFreshnessStatus retrieves all freshness status entries.
 func (s *Store) GetAllFreshnessStatus(ctx context.Context) ([]*freshness.Indicator, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT org_id, model_id, last_event_at, last_rollup_at, lag_seconds, status
 		FROM analytics.freshness_status
 		ORDER BY org_id, model_id
 	`
 
-	rows, err := s.pool.Query(ctx, query)
+	rows, err := s.reader().Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query freshness status: %w", err)
 	}
@@ -50,6 +53,9 @@ func (s *Store) GetAllFreshnessStatus(ctx context.Context) ([]*freshness.Indicat
 
 // GetFreshnessStatus retrieves freshness status for a specific org/model.
 func (s *Store) GetFreshnessStatus(ctx context.Context, orgID uuid.UUID, modelID *uuid.UUID) (*freshness.Indicator, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT org_id, model_id, last_event_at, last_rollup_at, lag_seconds, status
 		FROM analytics.freshness_status
@@ -59,7 +65,7 @@ func (s *Store) GetFreshnessStatus(ctx context.Context, orgID uuid.UUID, modelID
 	var ind freshness.Indicator
 	var modelIDPtr *uuid.UUID
 
-	err := s.pool.QueryRow(ctx, query, orgID, modelID).Scan(
+	err := s.reader().QueryRow(ctx, query, orgID, modelID).Scan(
 		&ind.OrgID,
 		&modelIDPtr,
 		&ind.LastEventAt,
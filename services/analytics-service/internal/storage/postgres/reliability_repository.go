@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/sketch"
 )
 
 // ReliabilityPoint represents a single data point in a reliability series.
@@ -17,46 +19,45 @@ type ReliabilityPoint struct {
 	LatencyP50  int
 	LatencyP95  int
 	LatencyP99  int
+	// Latency is the bucket's histogram, for merging across buckets.
+	Latency sketch.Histogram
 }
 
-// GetReliabilitySeries retrieves reliability data (error rates and latency percentiles) for an organization.
+// GetReliabilitySeries retrieves reliability data (error rates and latency
+// percentiles) for an organization from the hourly or daily rollups.
+// Percentiles come from the rollups' latency histograms, so raw events are
+// not scanned; buckets not yet rolled up are absent.
 func (s *Store) GetReliabilitySeries(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]ReliabilityPoint, error) {
-	var bucketExpr string
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	table := "analytics_daily_rollups"
 	if granularity == "hour" {
-		bucketExpr = "date_trunc('hour', occurred_at)"
-	} else {
-		bucketExpr = "date_trunc('day', occurred_at)"
+		table = "analytics_hourly_rollups"
 	}
 
 	query := fmt.Sprintf(`
-		SELECT 
-			%s AS bucket_start,
+		SELECT
+			bucket_start,
 			model_id,
-			CASE 
-				WHEN COUNT(*) = 0 THEN 0.0
-				ELSE CAST(SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS FLOAT) / COUNT(*)
-			END AS error_rate,
-			PERCENTILE_CONT(0.50) WITHIN GROUP (ORDER BY latency_ms)::INTEGER AS latency_p50,
-			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY latency_ms)::INTEGER AS latency_p95,
-			PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY latency_ms)::INTEGER AS latency_p99
-		FROM analytics.usage_events
-		WHERE org_id = $1
-			AND occurred_at >= $2
-			AND occurred_at < $3
-	`, bucketExpr)
+			request_count,
+			error_count,
+			latency_histogram
+		FROM %s
+		WHERE organization_id = $1
+			AND bucket_start >= $2
+			AND bucket_start < $3
+	`, table)
 
 	args := []interface{}{orgID, start, end}
-	argIdx := 4
-
 	if modelID != nil {
-		query += fmt.Sprintf(" AND model_id = $%d", argIdx)
+		query += " AND model_id = $4"
 		args = append(args, *modelID)
-		argIdx++
 	}
 
-	query += fmt.Sprintf(" GROUP BY %s, model_id ORDER BY bucket_start DESC", bucketExpr)
+	query += " ORDER BY bucket_start DESC"
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query reliability series: %w", err)
 	}
@@ -65,22 +66,22 @@ func (s *Store) GetReliabilitySeries(ctx context.Context, orgID uuid.UUID, start
 	var points []ReliabilityPoint
 	for rows.Next() {
 		var p ReliabilityPoint
-		var modelIDPtr *uuid.UUID
-		err := rows.Scan(
-			&p.BucketStart,
-			&modelIDPtr,
-			&p.ErrorRate,
-			&p.LatencyP50,
-			&p.LatencyP95,
-			&p.LatencyP99,
-		)
-		if err != nil {
+		var requests, errorCount int64
+		var histogram []byte
+		if err := rows.Scan(&p.BucketStart, &p.ModelID, &requests, &errorCount, &histogram); err != nil {
 			return nil, fmt.Errorf("scan reliability point: %w", err)
 		}
-		p.ModelID = modelIDPtr
+		if requests > 0 {
+			p.ErrorRate = float64(errorCount) / float64(requests)
+		}
+		if p.Latency, err = sketch.Parse(histogram); err != nil {
+			return nil, fmt.Errorf("scan reliability point: %w", err)
+		}
+		p.LatencyP50 = p.Latency.Quantile(0.50)
+		p.LatencyP95 = p.Latency.Quantile(0.95)
+		p.LatencyP99 = p.Latency.Quantile(0.99)
 		points = append(points, p)
 	}
 
 	return points, rows.Err()
 }
-
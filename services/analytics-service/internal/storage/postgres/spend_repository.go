@@ -0,0 +1,61 @@
+// Package postgres provides daily spend query methods.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailySpend is an organization's spend on one UTC day.
+type DailySpend struct {
+	OrgID uuid.UUID
+	Day   time.Time
+	Cost  float64 // Dollars, like the rollup-backed usage series
+}
+
+// GetDailySpend returns daily spend from the daily rollups for days in
+// [start, end), for one organization or, when orgID is nil, for every
+// organization with spend on or after activeSince.
+func (s *Store) GetDailySpend(ctx context.Context, orgID *uuid.UUID, start, end, activeSince time.Time) ([]DailySpend, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT organization_id, bucket_start, COALESCE(SUM(cost_total), 0)
+		FROM analytics_daily_rollups
+		WHERE bucket_start >= $1 AND bucket_start < $2
+	`
+	args := []interface{}{start, end}
+	if orgID != nil {
+		query += " AND organization_id = $3"
+		args = append(args, *orgID)
+	} else {
+		query += `
+			AND organization_id IN (
+				SELECT DISTINCT organization_id FROM analytics_daily_rollups
+				WHERE bucket_start >= $3 AND bucket_start < $2 AND cost_total > 0
+			)
+		`
+		args = append(args, activeSince)
+	}
+	query += " GROUP BY organization_id, bucket_start ORDER BY organization_id, bucket_start"
+
+	rows, err := s.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query daily spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []DailySpend
+	for rows.Next() {
+		var d DailySpend
+		if err := rows.Scan(&d.OrgID, &d.Day, &d.Cost); err != nil {
+			return nil, fmt.Errorf("scan daily spend: %w", err)
+		}
+		spend = append(spend, d)
+	}
+	return spend, rows.Err()
+}
@@ -4,29 +4,43 @@
 //
 //	This package provides data access methods for usage events, ingestion batches,
 //	and freshness status. It uses pgxpool for connection pooling and supports
-//	TimescaleDB hypertables.
+//	TimescaleDB hypertables. Dashboard queries can be served by read replicas
+//	(see UseReplicas); ingestion always writes to the primary.
 package postgres
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ai-aas/shared-go/dataaccess/pgpool"
+	"github.com/ai-aas/shared-go/dataaccess/pgreplica"
 )
 
 // Store provides Postgres-backed persistence for analytics data.
 type Store struct {
 	pool *pgxpool.Pool
+	// replicas routes read-only analytics queries when configured (optional).
+	replicas *pgreplica.Router
+	// queryTimeout bounds each store call (zero disables).
+	queryTimeout time.Duration
+	// copyThreshold is the batch size at which inserts switch to COPY.
+	copyThreshold int
 }
 
-// NewStore creates a store using the provided connection string.
-func NewStore(ctx context.Context, connString string) (*Store, error) {
-	pool, err := pgxpool.New(ctx, connString)
+// NewStore creates a store using the provided connection string and pool
+// settings. Pool saturation metrics are exported under pool="analytics".
+func NewStore(ctx context.Context, connString string, cfg pgpool.Config) (*Store, error) {
+	pool, err := pgpool.New(ctx, "analytics", connString, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("create pgx pool: %w", err)
+		return nil, err
 	}
 
 	// Verify connection
@@ -35,30 +49,113 @@ func NewStore(ctx context.Context, connString string) (*Store, error) {
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
-	return &Store{pool: pool}, nil
+	return &Store{pool: pool, queryTimeout: cfg.QueryTimeout, copyThreshold: DefaultCopyThreshold}, nil
+}
+
+// UseReplicas routes read-only queries through the given replica router. The
+// store takes ownership of the router and closes it in Close.
+func (s *Store) UseReplicas(r *pgreplica.Router) {
+	s.replicas = r
+}
+
+// reader returns a fresh replica pool when available, otherwise the primary.
+func (s *Store) reader() *pgxpool.Pool {
+	if s.replicas == nil {
+		return s.pool
+	}
+	return s.replicas.Reader()
+}
+
+// queryContext applies the configured per-query timeout to ctx.
+func (s *Store) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return pgpool.WithTimeout(ctx, s.queryTimeout)
 }
 
 // Close closes the underlying pool.
 func (s *Store) Close() {
+	if s.replicas != nil {
+		s.replicas.Close()
+	}
 	if s.pool != nil {
 		s.pool.Close()
 	}
 }
 
+// ResetConnections closes idle connections in the primary and replica pools
+// and retires busy ones once released, so new connections authenticate with
+// rotated credentials. In-flight queries are not interrupted.
+func (s *Store) ResetConnections() {
+	s.pool.Reset()
+	s.replicas.Reset()
+}
+
 // Pool exposes the underlying pgx pool.
 func (s *Store) Pool() *pgxpool.Pool {
 	return s.pool
 }
 
+// DefaultCopyThreshold is the batch size at which InsertUsageEvents switches
+// from row-by-row INSERTs to COPY.
+const DefaultCopyThreshold = 100
+
+// usageEventColumns lists analytics.usage_events columns in insert order.
+var usageEventColumns = []string{
+	"event_id", "org_id", "occurred_at", "received_at", "model_id", "actor_id",
+	"input_tokens", "output_tokens", "latency_ms", "status", "error_code",
+	"cost_estimate_cents", "metadata", "batch_id",
+}
+
+// SetCopyThreshold sets the minimum batch size for the COPY path. Values <= 0
+// disable COPY entirely.
+func (s *Store) SetCopyThreshold(n int) {
+	s.copyThreshold = n
+}
+
 // InsertUsageEvents inserts usage events in a batch with deduplication.
+//
+// Large batches are streamed with COPY, which is all-or-nothing: if the batch
+// repeats an event key or collides with an already-ingested event (replays,
+// backfill overlap), it falls back to INSERT ... ON CONFLICT DO NOTHING so
+// duplicates are skipped rather than failing the batch.
 func (s *Store) InsertUsageEvents(ctx context.Context, events []UsageEvent, batchID uuid.UUID) (int, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if len(events) == 0 {
 		return 0, nil
 	}
 
-	// Use COPY for efficient batch insert, then handle deduplication
-	// For now, use individual inserts with ON CONFLICT for simplicity
-	// TODO: Optimize with COPY + CTE for better performance
+	if s.copyThreshold > 0 && len(events) >= s.copyThreshold && !hasDuplicateEventKeys(events) {
+		inserted, err := s.copyUsageEvents(ctx, events, batchID)
+		if err == nil {
+			return inserted, nil
+		}
+		if !isUniqueViolation(err) {
+			return 0, err
+		}
+	}
+
+	return s.insertUsageEventRows(ctx, events, batchID)
+}
+
+// copyUsageEvents bulk-loads events with COPY. The whole batch fails on any
+// unique violation.
+func (s *Store) copyUsageEvents(ctx context.Context, events []UsageEvent, batchID uuid.UUID) (int, error) {
+	n, err := s.pool.CopyFrom(ctx,
+		pgx.Identifier{"analytics", "usage_events"},
+		usageEventColumns,
+		pgx.CopyFromSlice(len(events), func(i int) ([]any, error) {
+			return usageEventValues(events[i], batchID), nil
+		}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("copy usage events: %w", err)
+	}
+	return int(n), nil
+}
+
+// insertUsageEventRows inserts events one at a time, skipping duplicates.
+func (s *Store) insertUsageEventRows(ctx context.Context, events []UsageEvent, batchID uuid.UUID) (int, error) {
 	query := `
 		INSERT INTO analytics.usage_events (
 			event_id, org_id, occurred_at, received_at, model_id, actor_id,
@@ -70,31 +167,7 @@ func (s *Store) InsertUsageEvents(ctx context.Context, events []UsageEvent, batc
 
 	inserted := 0
 	for _, e := range events {
-		var modelID, actorID *uuid.UUID
-		if e.ModelID != uuid.Nil {
-			modelID = &e.ModelID
-		}
-		if e.ActorID != uuid.Nil {
-			actorID = &e.ActorID
-		}
-
-		var errorCode *string
-		if e.ErrorCode != "" {
-			errorCode = &e.ErrorCode
-		}
-
-		metadataJSON, err := json.Marshal(e.Metadata)
-		if err != nil {
-			metadataJSON = []byte("{}")
-		}
-
-		ct, err := s.pool.Exec(ctx, query,
-			e.EventID, e.OrgID, e.OccurredAt, e.ReceivedAt,
-			modelID, actorID,
-			e.InputTokens, e.OutputTokens, e.LatencyMS,
-			e.Status, errorCode,
-			e.CostEstimateCents, string(metadataJSON), batchID,
-		)
+		ct, err := s.pool.Exec(ctx, query, usageEventValues(e, batchID)...)
 		if err != nil {
 			return inserted, fmt.Errorf("insert usage event: %w", err)
 		}
@@ -106,6 +179,54 @@ func (s *Store) InsertUsageEvents(ctx context.Context, events []UsageEvent, batc
 	return inserted, nil
 }
 
+// usageEventValues returns column values in usageEventColumns order.
+func usageEventValues(e UsageEvent, batchID uuid.UUID) []any {
+	var modelID, actorID *uuid.UUID
+	if e.ModelID != uuid.Nil {
+		modelID = &e.ModelID
+	}
+	if e.ActorID != uuid.Nil {
+		actorID = &e.ActorID
+	}
+
+	var errorCode *string
+	if e.ErrorCode != "" {
+		errorCode = &e.ErrorCode
+	}
+
+	metadataJSON, err := json.Marshal(e.Metadata)
+	if err != nil {
+		metadataJSON = []byte("{}")
+	}
+
+	return []any{
+		e.EventID, e.OrgID, e.OccurredAt, e.ReceivedAt,
+		modelID, actorID,
+		e.InputTokens, e.OutputTokens, e.LatencyMS,
+		e.Status, errorCode,
+		e.CostEstimateCents, string(metadataJSON), batchID,
+	}
+}
+
+// hasDuplicateEventKeys reports whether the batch repeats an (event_id, org_id) key.
+func hasDuplicateEventKeys(events []UsageEvent) bool {
+	seen := make(map[[2]uuid.UUID]struct{}, len(events))
+	for _, e := range events {
+		key := [2]uuid.UUID{e.EventID, e.OrgID}
+		if _, ok := seen[key]; ok {
+			return true
+		}
+		seen[key] = struct{}{}
+	}
+	return false
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // UsageEvent represents a usage event for insertion.
 type UsageEvent struct {
 	EventID           uuid.UUID
@@ -125,6 +246,9 @@ type UsageEvent struct {
 
 // CreateIngestionBatch creates a new ingestion batch record.
 func (s *Store) CreateIngestionBatch(ctx context.Context, offset int64, orgScope []uuid.UUID) (uuid.UUID, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	batchID := uuid.New()
 	query := `
 		INSERT INTO analytics.ingestion_batches (
@@ -141,6 +265,9 @@ func (s *Store) CreateIngestionBatch(ctx context.Context, offset int64, orgScope
 
 // CompleteIngestionBatch marks a batch as completed with dedupe stats.
 func (s *Store) CompleteIngestionBatch(ctx context.Context, batchID uuid.UUID, dedupeConflicts int) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE analytics.ingestion_batches
 		SET completed_at = NOW(), dedupe_conflicts = $2
@@ -0,0 +1,123 @@
+// Package postgres provides top-N breakdown query methods.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Top-N dimensions.
+const (
+	TopModels     = "models"      // Ranked by spend
+	TopAPIKeys    = "keys"        // Ranked by requests
+	TopErrorCodes = "error-codes" // Ranked by error count
+)
+
+// TopEntry is one ranked value of a top-N breakdown.
+type TopEntry struct {
+	Value             string
+	Requests          int64
+	Errors            int64
+	CostEstimateCents float64 // Dollars, like the rollup-backed usage series
+}
+
+// topQueries holds the rollup and raw-event queries for each dimension. Each
+// takes org, start, end and limit and returns value, requests, errors, cost.
+var topQueries = map[string]struct{ rollup, raw string }{
+	TopModels: {
+		rollup: `
+			SELECT COALESCE(model_id::TEXT, ''), SUM(request_count), SUM(error_count), SUM(cost_total) AS cost
+			FROM analytics_hourly_rollups
+			WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3
+			GROUP BY 1
+			ORDER BY cost DESC, 1
+			LIMIT $4
+		`,
+		raw: `
+			SELECT COALESCE(model_id::TEXT, ''), COUNT(*),
+				SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END),
+				COALESCE(SUM(cost_estimate_cents / 100.0), 0) AS cost
+			FROM analytics.usage_events
+			WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+			GROUP BY 1
+			ORDER BY cost DESC, 1
+			LIMIT $4
+		`,
+	},
+	TopAPIKeys: {
+		rollup: `
+			SELECT value, SUM(request_count) AS requests, SUM(error_count), SUM(cost_total)
+			FROM analytics_hourly_dimension_rollups
+			WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3 AND dimension = 'api_key'
+			GROUP BY 1
+			ORDER BY requests DESC, 1
+			LIMIT $4
+		`,
+		raw: `
+			SELECT metadata->>'api_key_id', COUNT(*) AS requests,
+				SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END),
+				COALESCE(SUM(cost_estimate_cents / 100.0), 0)
+			FROM analytics.usage_events
+			WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3 AND metadata->>'api_key_id' IS NOT NULL
+			GROUP BY 1
+			ORDER BY requests DESC, 1
+			LIMIT $4
+		`,
+	},
+	TopErrorCodes: {
+		rollup: `
+			SELECT value, SUM(request_count), SUM(error_count) AS errors, SUM(cost_total)
+			FROM analytics_hourly_dimension_rollups
+			WHERE organization_id = $1 AND bucket_start >= $2 AND bucket_start < $3 AND dimension = 'error_code'
+			GROUP BY 1
+			ORDER BY errors DESC, 1
+			LIMIT $4
+		`,
+		raw: `
+			SELECT COALESCE(NULLIF(error_code, ''), 'unknown'), COUNT(*), COUNT(*) AS errors,
+				COALESCE(SUM(cost_estimate_cents / 100.0), 0)
+			FROM analytics.usage_events
+			WHERE org_id = $1 AND occurred_at >= $2 AND occurred_at < $3 AND status = 'error'
+			GROUP BY 1
+			ORDER BY errors DESC, 1
+			LIMIT $4
+		`,
+	},
+}
+
+// GetTopUsage returns the top limit values of dimension for an organization.
+// By default it reads the hourly rollups, so only whole rolled-up hours are
+// counted; raw reads analytics.usage_events directly and should be limited to
+// short windows by the caller.
+func (s *Store) GetTopUsage(ctx context.Context, orgID uuid.UUID, dimension string, start, end time.Time, limit int, raw bool) ([]TopEntry, error) {
+	queries, ok := topQueries[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown top-N dimension %q", dimension)
+	}
+	query := queries.rollup
+	if raw {
+		query = queries.raw
+	}
+
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	rows, err := s.reader().Query(ctx, query, orgID, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query top %s: %w", dimension, err)
+	}
+	defer rows.Close()
+
+	var entries []TopEntry
+	for rows.Next() {
+		var e TopEntry
+		if err := rows.Scan(&e.Value, &e.Requests, &e.Errors, &e.CostEstimateCents); err != nil {
+			return nil, fmt.Errorf("scan top %s entry: %w", dimension, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
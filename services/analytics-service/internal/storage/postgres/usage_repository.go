@@ -13,6 +13,7 @@ import (
 type UsagePoint struct {
 	BucketStart       time.Time
 	ModelID           *uuid.UUID
+	ProjectID         *string // Set only for project-grouped series
 	Invocations       int64
 	InputTokens       int64
 	OutputTokens      int64
@@ -29,6 +30,9 @@ type UsageTotals struct {
 
 // GetUsageSeries retrieves usage data for an organization.
 func (s *Store) GetUsageSeries(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]UsagePoint, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	var query string
 	var bucketFormat string
 
@@ -75,7 +79,7 @@ func (s *Store) GetUsageSeries(ctx context.Context, orgID uuid.UUID, start, end
 
 	query += fmt.Sprintf(" ORDER BY bucket_start DESC, %s", bucketFormat)
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query usage series: %w", err)
 	}
@@ -105,6 +109,9 @@ func (s *Store) GetUsageSeries(ctx context.Context, orgID uuid.UUID, start, end
 
 // GetUsageTotals calculates totals for a time range.
 func (s *Store) GetUsageTotals(ctx context.Context, orgID uuid.UUID, start, end time.Time, modelID *uuid.UUID) (UsageTotals, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COALESCE(SUM(request_count), 0) AS invocations,
@@ -124,7 +131,7 @@ func (s *Store) GetUsageTotals(ctx context.Context, orgID uuid.UUID, start, end
 	}
 
 	var totals UsageTotals
-	err := s.pool.QueryRow(ctx, query, args...).Scan(
+	err := s.reader().QueryRow(ctx, query, args...).Scan(
 		&totals.Invocations,
 		&totals.InputTokens,
 		&totals.OutputTokens,
@@ -137,3 +144,66 @@ func (s *Store) GetUsageTotals(ctx context.Context, orgID uuid.UUID, start, end
 	return totals, nil
 }
 
+// GetUsageSeriesByProject retrieves usage for an organization grouped by the
+// team/project its API keys belong to (metadata->>'project_id'). Rollups do not
+// carry project attribution, so this reads raw usage_events. Usage from keys
+// issued directly in the org has a nil ProjectID. Cost is in dollars, like the
+// rollup-backed series.
+func (s *Store) GetUsageSeriesByProject(ctx context.Context, orgID uuid.UUID, start, end time.Time, granularity string, modelID *uuid.UUID) ([]UsagePoint, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	var bucketExpr string
+	if granularity == "hour" {
+		bucketExpr = "date_trunc('hour', occurred_at)"
+	} else {
+		bucketExpr = "date_trunc('day', occurred_at)"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			%s AS bucket_start,
+			metadata->>'project_id' AS project_id,
+			COUNT(*) AS invocations,
+			COALESCE(SUM(input_tokens), 0) AS input_tokens,
+			COALESCE(SUM(output_tokens), 0) AS output_tokens,
+			COALESCE(SUM(cost_estimate_cents / 100.0), 0)::FLOAT AS cost_estimate_cents
+		FROM analytics.usage_events
+		WHERE org_id = $1
+			AND occurred_at >= $2
+			AND occurred_at < $3
+	`, bucketExpr)
+
+	args := []interface{}{orgID, start, end}
+	if modelID != nil {
+		query += " AND model_id = $4"
+		args = append(args, *modelID)
+	}
+
+	query += fmt.Sprintf(" GROUP BY %s, metadata->>'project_id' ORDER BY bucket_start DESC", bucketExpr)
+
+	rows, err := s.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query usage series by project: %w", err)
+	}
+	defer rows.Close()
+
+	var points []UsagePoint
+	for rows.Next() {
+		var p UsagePoint
+		err := rows.Scan(
+			&p.BucketStart,
+			&p.ProjectID,
+			&p.Invocations,
+			&p.InputTokens,
+			&p.OutputTokens,
+			&p.CostEstimateCents,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan usage point: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,253 @@
+// Package synthetic generates realistic usage events for demos.
+//
+// Purpose:
+//
+//	Shared by synthesize-usage and seed-usage: a diurnal traffic curve, a
+//	weighted model mix, log-normal token counts and occasional error bursts,
+//	deterministic for a given seed so re-runs de-duplicate on event_id.
+//
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/analytics-service/internal/ingestion"
+)
+
+// ModelProfile describes the traffic shape of one model.
+type ModelProfile struct {
+	Name             string
+	Weight           float64
+	MeanInputTokens  float64
+	MeanOutputTokens float64
+	MeanLatencyMS    float64
+	CentsPer1KTokens float64
+	MSPerOutputToken float64
+}
+
+// knownModels supplies realistic defaults; unknown model names get midsize values.
+var knownModels = map[string]ModelProfile{
+	"gpt-4o":        {MeanInputTokens: 900, MeanOutputTokens: 350, MeanLatencyMS: 450, CentsPer1KTokens: 1.0, MSPerOutputToken: 12},
+	"gpt-4o-mini":   {MeanInputTokens: 700, MeanOutputTokens: 250, MeanLatencyMS: 250, CentsPer1KTokens: 0.06, MSPerOutputToken: 6},
+	"llama-3-70b":   {MeanInputTokens: 1200, MeanOutputTokens: 400, MeanLatencyMS: 600, CentsPer1KTokens: 0.09, MSPerOutputToken: 15},
+	"llama-3-8b":    {MeanInputTokens: 600, MeanOutputTokens: 200, MeanLatencyMS: 150, CentsPer1KTokens: 0.02, MSPerOutputToken: 4},
+	"mistral-7b":    {MeanInputTokens: 500, MeanOutputTokens: 180, MeanLatencyMS: 140, CentsPer1KTokens: 0.02, MSPerOutputToken: 4},
+	"embedding-3-s": {MeanInputTokens: 300, MeanOutputTokens: 0, MeanLatencyMS: 40, CentsPer1KTokens: 0.002},
+}
+
+var defaultModel = ModelProfile{MeanInputTokens: 800, MeanOutputTokens: 300, MeanLatencyMS: 400, CentsPer1KTokens: 0.1, MSPerOutputToken: 10}
+
+// errorCodes are drawn uniformly for failed requests.
+var errorCodes = []string{"backend_timeout", "upstream_error", "rate_limited", "invalid_request"}
+
+// ParseModelMix parses "name:weight,name:weight".
+func ParseModelMix(s string) ([]ModelProfile, error) {
+	var mix []ModelProfile
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightStr, ok := strings.Cut(part, ":")
+		weight := 1.0
+		if ok {
+			w, err := strconv.ParseFloat(weightStr, 64)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight in %q", part)
+			}
+			weight = w
+		}
+		p, known := knownModels[name]
+		if !known {
+			p = defaultModel
+		}
+		p.Name = name
+		p.Weight = weight
+		mix = append(mix, p)
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("model mix is empty")
+	}
+	return mix, nil
+}
+
+// ModelID derives a stable ID per model name so dashboards group consistently
+// across runs.
+func ModelID(name string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("ai-aas/model/"+name)).String()
+}
+
+// Burst is a window with an elevated error rate.
+type Burst struct {
+	Start, End time.Time
+}
+
+// Generator produces usage events for a set of orgs.
+type Generator struct {
+	rng            *rand.Rand
+	orgs           []string
+	models         []ModelProfile
+	totalWeight    float64
+	peakPerMinute  float64 // per org, at the diurnal peak
+	errorRate      float64
+	burstErrorRate float64
+	bursts         []Burst
+
+	// APIKeys optionally maps org IDs to the API key IDs their events are
+	// attributed to, so per-key dashboards have data.
+	APIKeys map[string][]string
+}
+
+// NewGenerator creates a generator; peakPerHour is requests per hour per org
+// at the daily peak.
+func NewGenerator(seed int64, orgs []string, models []ModelProfile, peakPerHour, errorRate, burstErrorRate float64) *Generator {
+	g := &Generator{
+		rng:            rand.New(rand.NewSource(seed)),
+		orgs:           orgs,
+		models:         models,
+		peakPerMinute:  peakPerHour / 60,
+		errorRate:      errorRate,
+		burstErrorRate: burstErrorRate,
+	}
+	for _, m := range models {
+		g.totalWeight += m.Weight
+	}
+	return g
+}
+
+// PlanBursts places n error bursts of the given length uniformly in [from, to).
+func (g *Generator) PlanBursts(from, to time.Time, n int, length time.Duration) {
+	span := to.Sub(from) - length
+	for i := 0; i < n && span > 0; i++ {
+		start := from.Add(time.Duration(g.rng.Int63n(int64(span))))
+		g.bursts = append(g.bursts, Burst{Start: start, End: start.Add(length)})
+	}
+	sort.Slice(g.bursts, func(i, j int) bool { return g.bursts[i].Start.Before(g.bursts[j].Start) })
+}
+
+// Bursts returns the planned error bursts.
+func (g *Generator) Bursts() []Burst {
+	return g.bursts
+}
+
+// diurnal scales traffic by time of day (UTC): a trough around 03:00, a peak
+// around 15:00, and weekends at half volume.
+func diurnal(t time.Time) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	f := 0.15 + 0.85*(1+math.Sin(2*math.Pi*(hour-9)/24))/2
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		f *= 0.5
+	}
+	return f
+}
+
+func (g *Generator) inBurst(t time.Time) bool {
+	for _, b := range g.bursts {
+		if !t.Before(b.Start) && t.Before(b.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Minute generates all events for the minute starting at t.
+func (g *Generator) Minute(t time.Time) []ingestion.Event {
+	lambda := g.peakPerMinute * diurnal(t)
+	var events []ingestion.Event
+	for orgIdx, org := range g.orgs {
+		// Spread orgs out so they don't all look identical
+		orgScale := 1.0 / float64(1+orgIdx%3)
+		n := g.poisson(lambda * orgScale)
+		for i := 0; i < n; i++ {
+			at := t.Add(time.Duration(g.rng.Int63n(int64(time.Minute))))
+			events = append(events, g.event(org, at))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].OccurredAt.Before(events[j].OccurredAt) })
+	return events
+}
+
+func (g *Generator) event(org string, at time.Time) ingestion.Event {
+	m := g.pickModel()
+	in := g.around(m.MeanInputTokens)
+	out := g.around(m.MeanOutputTokens)
+	latency := m.MeanLatencyMS*(0.6+0.8*g.rng.Float64()) + m.MSPerOutputToken*float64(out)
+
+	eventID, _ := uuid.NewRandomFromReader(g.rng)
+	e := ingestion.Event{
+		EventID:      eventID.String(),
+		OrgID:        org,
+		ModelID:      ModelID(m.Name),
+		OccurredAt:   at.UTC(),
+		InputTokens:  in,
+		OutputTokens: out,
+		Status:       "success",
+		Metadata:     map[string]interface{}{"model_name": m.Name, "synthetic": true},
+	}
+	if keys := g.APIKeys[org]; len(keys) > 0 {
+		e.APIKeyID = keys[g.rng.Intn(len(keys))]
+	}
+
+	rate := g.errorRate
+	if g.inBurst(at) {
+		rate = g.burstErrorRate
+	}
+	if g.rng.Float64() < rate {
+		e.Status = "error"
+		e.ErrorCode = errorCodes[g.rng.Intn(len(errorCodes))]
+		e.OutputTokens = 0
+		if e.ErrorCode == "backend_timeout" {
+			latency = 30000
+		}
+	}
+	e.LatencyMS = int(latency)
+	e.CostEstimate = math.Round(float64(e.InputTokens+e.OutputTokens)/1000*m.CentsPer1KTokens*1e4) / 1e4
+	return e
+}
+
+func (g *Generator) pickModel() ModelProfile {
+	r := g.rng.Float64() * g.totalWeight
+	for _, m := range g.models {
+		if r < m.Weight {
+			return m
+		}
+		r -= m.Weight
+	}
+	return g.models[len(g.models)-1]
+}
+
+// around returns a log-normally distributed token count with the given mean.
+func (g *Generator) around(mean float64) int64 {
+	if mean <= 0 {
+		return 0
+	}
+	const sigma = 0.5
+	v := mean * math.Exp(g.rng.NormFloat64()*sigma-sigma*sigma/2)
+	return int64(math.Max(1, math.Round(v)))
+}
+
+// poisson samples a Poisson-distributed count (normal approximation for large lambda).
+func (g *Generator) poisson(lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 50 {
+		return int(math.Max(0, math.Round(lambda+math.Sqrt(lambda)*g.rng.NormFloat64())))
+	}
+	l, k, p := math.Exp(-lambda), 0, 1.0
+	for {
+		p *= g.rng.Float64()
+		if p <= l {
+			return k
+		}
+		k++
+	}
+}
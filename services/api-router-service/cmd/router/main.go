@@ -36,14 +36,50 @@
 // Debugging Notes:
 //   - Server starts on configured HTTP port (default 8080)
 //   - Readiness probe checks Redis, Kafka, and config service connectivity
-//   - Graceful shutdown allows in-flight requests to complete (10s timeout)
-//   - Health endpoints (/v1/status/*) are accessible without authentication
+//   - Graceful shutdown drains for SHUTDOWN_DRAIN_DELAY, then allows in-flight
+//     requests to complete (SHUTDOWN_TIMEOUT)
+//   - Health endpoints (/v1/status/*, /healthz, /readyz, /startupz) are accessible without authentication
+//   - /v1/admin/diagnostics reports runtime stats and a config fingerprint (ADMIN_SCOPE required)
+//   - /debug/pprof and /debug/vars are exposed only when DEBUG_ENDPOINTS_ENABLED=true
+//     and also require ADMIN_SCOPE
 //   - All other routes require authentication via X-API-Key header
+//   - Access logs sample successful requests (ACCESS_LOG_SAMPLE_EVERY); errors are always logged
+//   - API versions are negotiated from the /vN path or the Accept header
+//     (application/vnd.ai-aas.vN+json); API_DEPRECATIONS routes get
+//     Deprecation/Sunset headers and count in api_deprecated_requests_total
+//   - Maintenance mode (/v1/admin/maintenance, or the config service key
+//     /api-router/maintenance) answers 503 on all but MAINTENANCE_EXEMPT_PATHS;
+//     upcoming windows are announced with X-Maintenance-Start/End headers
+//   - Keys with sudden request spikes (ABUSE_SPIKE_FACTOR x baseline) or high error
+//     rates are throttled to ABUSE_THROTTLE_RPS and reported on the security events
+//     topic; review and lift throttles via /v1/admin/abuse/flags
+//   - Each backend has its own connection pool (BACKEND_POOLS); pools are recycled
+//     when the backend's DNS answers change or it is marked unhealthy
+//   - BACKEND_PROTOCOLS pins a backend to http1, http2 (h2c on http://) or grpc;
+//     gRPC backends (Triton, KServe v2) get public API requests transcoded
+//   - stream=true OpenAI requests are relayed as server-sent events; a client
+//     disconnect cancels the backend request. Streams are still bounded by the
+//     60s request timeout; see api_router_stream_* metrics for TTFT and tokens/sec
+//   - Open streams are capped per org/key (maxConcurrentStreams, else
+//     STREAM_MAX_CONCURRENT*); over the cap is a 429 STREAM_LIMIT_EXCEEDED
+//   - With SERVICE_CLIENT_ID set, API key validation calls carry the router's
+//     service token (user-org-service enforces it with SERVICE_AUTH_REQUIRED)
+//   - Backend URIs (BACKEND_ENDPOINTS, FEDERATED_BACKENDS, admin API) must pass
+//     the EGRESS_* allowlist; rejected backends are dropped and reported on the
+//     security events topic
+//   - CHAOS_ENABLED=true (non-production only) enables fault injection, managed
+//     via /v1/admin/chaos/faults (ADMIN_SCOPE required)
+//   - Redis and Kafka credentials rotate without a restart when CREDENTIALS_DIR or
+//     VAULT_ADDR is set: reloaded on SIGHUP, file changes and CREDENTIALS_RELOAD_INTERVAL
+//   - --validate-config checks configuration and dependency connectivity, prints
+//     a JSON report and exits non-zero on any failure (see preflight.go)
 //
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -54,23 +90,41 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 
 	"github.com/redis/go-redis/v9"
 
+	"github.com/ai-aas/shared-go/apiversion"
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/requestid"
+	"github.com/ai-aas/shared-go/secrets"
+	sharedserver "github.com/ai-aas/shared-go/server"
+	"github.com/ai-aas/shared-go/usagerecord"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/abuse"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api/admin"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api/public"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/chaos"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/logexport"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/maintenance"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/securityevents"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/usage"
 )
 
 func main() {
+	validate := flag.Bool("validate-config", false, "validate configuration and dependency connectivity, print a JSON report and exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(validateConfig())
+	}
+
 	ctx := context.Background()
 
 	// Load configuration
@@ -132,7 +186,9 @@ func main() {
 	// constraint while keeping health endpoints accessible without authentication:
 	//
 	// 1. Main Router (router):
-	//    - Base chi middleware (RequestID, RealIP, Logger, Recoverer, Timeout)
+	//    - Base chi middleware (RequestID, Recoverer, Timeout), auth.RealIP
+	//      (forwarding headers from TRUSTED_PROXY_CIDRS only), the structured
+	//      access logger (sampled, credential-scrubbed) and CORS
 	//    - Health endpoints (/v1/status/healthz, /v1/status/readyz) - NO AUTH
 	//    - Metrics endpoint (/metrics) - NO AUTH
 	//
@@ -153,48 +209,183 @@ func main() {
 	//
 	// ============================================================================
 
+	// Lifecycle server: probes, drain-aware shutdown, /metrics and optional pprof
+	srv := sharedserver.New(sharedserver.Options{
+		Addr:            fmt.Sprintf(":%d", cfg.HTTPPort),
+		Logger:          logger,
+		DrainDelay:      cfg.ShutdownDrainDelay,
+		ShutdownTimeout: cfg.ShutdownTimeout,
+		EnableMetrics:   true,
+		PreStopAddr:     cfg.PreStopAddr,
+		StreamingPaths:  public.StreamingPaths,
+	})
+
+	// Initialize per-org log export (orgs opt in via user-org-service)
+	var logExporter *logexport.Exporter
+	if cfg.LogExportEnabled {
+		logExporter = logexport.New(logexport.Config{
+			BatchSize:     cfg.LogExportBatchSize,
+			FlushInterval: cfg.LogExportFlushInterval,
+			QueueSize:     cfg.LogExportQueueSize,
+			Logger:        logger,
+		})
+		logger.Info("org log export enabled")
+		defer logExporter.Stop()
+	}
+
+	// Initialize maintenance mode, shared across replicas via the config service
+	maintenanceController := maintenance.New(maintenance.Config{
+		ExemptPrefixes: cfg.MaintenanceExemptPaths,
+		WarningLead:    cfg.MaintenanceWarningLead,
+		Logger:         logger,
+	})
+	if cfg.ConfigWatchEnabled {
+		maintenanceStore, err := maintenance.NewEtcdStore(cfg.ConfigServiceEndpoint, logger)
+		if err != nil {
+			logger.Warn("failed to connect maintenance store, maintenance state is local only", zap.Error(err))
+		} else {
+			defer maintenanceStore.Close()
+			if err := maintenanceController.Sync(watchCtx, maintenanceStore); err != nil {
+				logger.Warn("failed to load maintenance state", zap.Error(err))
+			}
+		}
+	}
+
+	// API version negotiation and deprecated route announcements
+	apiDeprecations, err := apiversion.ParseDeprecations(cfg.APIDeprecations)
+	if err != nil {
+		logger.Error("invalid API_DEPRECATIONS, no routes announced as deprecated", zap.Error(err))
+	}
+
+	// Forwarding headers are only believed from our own proxies
+	trustedProxies, err := auth.ParseTrustedProxies(cfg.TrustedProxyCIDRs)
+	if err != nil {
+		logger.Fatal("invalid TRUSTED_PROXY_CIDRS", zap.Error(err))
+	}
+
 	// Set up HTTP server with middleware
 	router := chi.NewRouter()
 
 	// Base middleware stack (applies to all routes including health endpoints)
-	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
-	router.Use(middleware.Logger)
+	router.Use(requestid.Middleware)
+	router.Use(auth.RealIP(trustedProxies))
+	router.Use(apiversion.Middleware(apiversion.Config{
+		ServiceName:  cfg.ServiceName,
+		Deprecations: apiDeprecations,
+	}))
+	router.Use(public.AccessLogMiddleware(public.AccessLogConfig{
+		Logger:      logger,
+		SampleEvery: cfg.AccessLogSampleEvery,
+		Exporter:    logExporter,
+	}))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
+	router.Use(maintenanceController.Middleware())
+
+	// CORS: answers preflights before auth and exposes rate limit headers
+	cors := public.NewCORS(cfg.Environment, sharedserver.ParseOrigins(cfg.CORSAllowedOrigins), cfg.CORSMaxAge)
+	router.Use(cors.Middleware)
 
 	// Initialize authentication
 	authenticator := auth.NewAuthenticator(logger, cfg.UserOrgServiceURL, cfg.UserOrgServiceTimeout)
+	if cfg.PlatformTokenClientID != "" {
+		authenticator.SetTokenVerifier(tokens.NewVerifier(tokens.VerifierConfig{
+			Introspector: &tokens.HTTPIntrospector{
+				URL:          strings.TrimSuffix(cfg.UserOrgServiceURL, "/") + "/v1/auth/introspect",
+				ClientID:     cfg.PlatformTokenClientID,
+				ClientSecret: cfg.PlatformTokenClientSecret,
+				Client:       &http.Client{Timeout: cfg.UserOrgServiceTimeout, Transport: &requestid.Transport{}},
+			},
+			Validator: tokens.Validator{
+				Issuer:   cfg.PlatformTokenIssuer,
+				Audience: cfg.PlatformTokenAudience,
+				Skew:     cfg.PlatformTokenClockSkew,
+			},
+			CacheTTL: cfg.PlatformTokenCacheTTL,
+		}))
+		logger.Info("platform access tokens enabled", zap.String("client_id", cfg.PlatformTokenClientID))
+	}
+	if cfg.ServiceClientID != "" {
+		authenticator.SetServiceTokens(&tokens.ServiceTokenSource{
+			URL:          strings.TrimSuffix(cfg.UserOrgServiceURL, "/") + "/v1/auth/service-token",
+			ClientID:     cfg.ServiceClientID,
+			ClientSecret: cfg.ServiceClientSecret,
+			Client:       &http.Client{Timeout: cfg.UserOrgServiceTimeout, Transport: &requestid.Transport{}},
+		})
+		logger.Info("service identity enabled", zap.String("client_id", cfg.ServiceClientID))
+	}
+
+	// Load rotatable Redis and Kafka credentials (CREDENTIALS_DIR or Vault);
+	// nil when they come only from the environment
+	var credentials *secrets.Reloader
+	if source := cfg.CredentialSource(); source != nil {
+		credentials, err = secrets.NewReloader(ctx, source, logger)
+		if err != nil {
+			logger.Fatal("failed to load credentials", zap.Error(err))
+		}
+		go credentials.Watch(ctx, cfg.CredentialsReloadInterval)
+		logger.Info("credential rotation enabled", zap.Duration("reload_interval", cfg.CredentialsReloadInterval))
+	}
 
-	// Initialize Redis for rate limiting
-	var redisClient *redis.Client
+	// Initialize Redis for rate limiting (Redis Cluster when several seed
+	// addresses are given or RATE_LIMIT_REDIS_CLUSTER is set). Credentials
+	// are read for every new connection, so rotations need no restart;
+	// established connections stay authenticated.
+	redisCredentials := func() (string, string) {
+		return credentials.Lookup(secrets.RedisUsername, ""), credentials.Lookup(secrets.RedisPassword, cfg.RedisPassword)
+	}
+	var redisClient redis.UniversalClient
 	if cfg.RateLimitRedisAddr != "" {
-		redisClient = redis.NewClient(&redis.Options{
-			Addr:     cfg.RateLimitRedisAddr,
-			Password: cfg.RedisPassword,
-			DB:       cfg.RedisDB,
-		})
+		addrs := strings.Split(cfg.RateLimitRedisAddr, ",")
+		if cfg.RateLimitRedisCluster || len(addrs) > 1 {
+			redisClient = redis.NewClusterClient(&redis.ClusterOptions{
+				Addrs:               addrs,
+				CredentialsProvider: redisCredentials,
+			})
+		} else {
+			redisClient = redis.NewClient(&redis.Options{
+				Addr:                cfg.RateLimitRedisAddr,
+				CredentialsProvider: redisCredentials,
+				DB:                  cfg.RedisDB,
+			})
+		}
 
-		// Test Redis connection
+		// Test Redis connection; the client is kept so the limiter can recover
 		pingCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 		if err := redisClient.Ping(pingCtx).Err(); err != nil {
-			logger.Warn("Redis unavailable, rate limiting disabled", zap.Error(err))
-			redisClient = nil
+			logger.Warn("Redis unavailable, rate limiting uses local token buckets until it recovers", zap.Error(err))
 		} else {
 			logger.Info("Redis connected for rate limiting", zap.String("addr", cfg.RateLimitRedisAddr))
 		}
 	}
 
-	// Initialize rate limiter
-	var rateLimiter *limiter.RateLimiter
-	if redisClient != nil {
-		rateLimiter = limiter.NewRateLimiter(redisClient, logger, cfg.RateLimitDefaultRPS, cfg.RateLimitBurstSize)
-		logger.Info("rate limiter initialized",
-			zap.Int("default_rps", cfg.RateLimitDefaultRPS),
-			zap.Int("burst_size", cfg.RateLimitBurstSize),
-		)
+	// Initialize rate limiter; without Redis it enforces limits per router
+	rateLimiter := limiter.NewRateLimiter(redisClient, logger, cfg.RateLimitDefaultRPS, cfg.RateLimitBurstSize)
+	rateLimiter.SetSoftThreshold(cfg.RateLimitSoftThreshold)
+	rateLimiter.SetLocalShare(cfg.RateLimitLocalShare)
+	rateLimiter.SetConcurrencyLease(cfg.RateLimitConcurrencyLease)
+	rateLimiter.SetStreamLimits(limiter.StreamLimits{
+		MaxPerOrg: cfg.StreamMaxConcurrent,
+		MaxPerKey: cfg.StreamMaxConcurrentPerKey,
+		SlotTTL:   cfg.StreamSlotTTL,
+	})
+	if algorithm, err := limiter.ParseAlgorithm(cfg.RateLimitAlgorithm); err != nil {
+		logger.Error("invalid RATE_LIMIT_ALGORITHM, using token_bucket", zap.Error(err))
+	} else {
+		rateLimiter.SetAlgorithm(algorithm)
 	}
+	rateLimiterCtx, stopRateLimiter := context.WithCancel(context.Background())
+	defer stopRateLimiter()
+	go rateLimiter.Run(rateLimiterCtx, cfg.RateLimitReconcileInterval)
+	logger.Info("rate limiter initialized",
+		zap.Int("default_rps", cfg.RateLimitDefaultRPS),
+		zap.Int("burst_size", cfg.RateLimitBurstSize),
+		zap.Float64("soft_threshold", cfg.RateLimitSoftThreshold),
+		zap.Float64("local_share", cfg.RateLimitLocalShare),
+		zap.String("algorithm", cfg.RateLimitAlgorithm),
+		zap.Bool("redis", redisClient != nil),
+	)
 
 	// Initialize budget client
 	budgetClient := limiter.NewBudgetClient(cfg.BudgetServiceEndpoint, cfg.BudgetServiceTimeout, logger)
@@ -203,12 +394,69 @@ func main() {
 	} else {
 		logger.Info("budget client using stub implementation")
 	}
+	if cfg.BudgetHoldsEnabled {
+		budgetHolds := limiter.NewBudgetHolds(redisClient, logger)
+		budgetHolds.SetLease(cfg.BudgetHoldLease)
+		budgetHolds.SetSettleTTL(cfg.BudgetHoldSettleTTL)
+		budgetHolds.SetPolicy(limiter.HoldPolicy{
+			MinOutputTokens:     cfg.BudgetHoldMinOutputTokens,
+			DefaultOutputTokens: cfg.BudgetHoldDefaultOutputTokens,
+		})
+		budgetClient.SetHolds(budgetHolds)
+	}
 
 	// Initialize audit logger
 	auditLogger := usage.NewAuditLogger(logger)
+	if logExporter != nil {
+		auditLogger.SetExporter(logExporter)
+	}
+
+	// Security events (abuse throttles, egress violations) for human review
+	var securityEvents securityevents.Publisher = &securityevents.LogPublisher{Logger: logger}
+	if cfg.KafkaBrokers != "" {
+		kafkaSecurityEvents := securityevents.NewKafkaPublisher(securityevents.KafkaPublisherConfig{
+			Brokers:  parseKafkaBrokers(cfg.KafkaBrokers),
+			Topic:    cfg.SecurityEventsTopic,
+			ClientID: cfg.ServiceName,
+			Credentials: func() (string, string) {
+				return credentials.Lookup(secrets.KafkaUsername, cfg.KafkaSASLUsername), credentials.Lookup(secrets.KafkaPassword, cfg.KafkaSASLPassword)
+			},
+		})
+		defer kafkaSecurityEvents.Close()
+		securityEvents = kafkaSecurityEvents
+	}
+
+	// Abuse detection shares the rate limiter's Redis; without it keys are never flagged
+	var abuseDetector *abuse.Detector
+	if cfg.AbuseDetectionEnabled && redisClient != nil {
+		abuseDetector = abuse.New(abuse.Config{
+			Redis:              redisClient,
+			Publisher:          securityEvents,
+			Logger:             logger,
+			SpikeFactor:        cfg.AbuseSpikeFactor,
+			BaselineWindow:     cfg.AbuseBaselineWindow,
+			MinRequests:        cfg.AbuseMinRequestsPerMinute,
+			ErrorRateThreshold: cfg.AbuseErrorRateThreshold,
+			ThrottleDuration:   cfg.AbuseThrottleDuration,
+			ThrottleRPS:        cfg.AbuseThrottleRPS,
+			ThrottleBurst:      cfg.AbuseThrottleBurst,
+		})
+		logger.Info("abuse detection enabled",
+			zap.Float64("spike_factor", cfg.AbuseSpikeFactor),
+			zap.Float64("error_rate_threshold", cfg.AbuseErrorRateThreshold),
+			zap.Int("throttle_rps", cfg.AbuseThrottleRPS),
+		)
+	}
 
 	// Initialize backend registry from config
 	backendRegistry := config.NewBackendRegistry(cfg)
+	egressPolicy, err := cfg.EgressPolicy()
+	if err != nil {
+		logger.Fatal("invalid egress policy", zap.Error(err))
+	}
+	for _, violation := range backendRegistry.SetEgressPolicy(egressPolicy) {
+		reportEgressViolation(ctx, securityEvents, logger, violation, securityevents.SourceBackendEndpoints)
+	}
 	logger.Info("backend registry initialized",
 		zap.Strings("backends", backendRegistry.ListBackends()),
 	)
@@ -216,6 +464,11 @@ func main() {
 	// Initialize Kafka publisher for usage records (if configured)
 	var kafkaPublisher *usage.Publisher
 	if cfg.KafkaBrokers != "" {
+		usageEncoding, err := usagerecord.ParseEncoding(cfg.UsageEncoding)
+		if err != nil {
+			logger.Warn("invalid USAGE_ENCODING, publishing JSON", zap.Error(err))
+			usageEncoding = usagerecord.EncodingJSON
+		}
 		kafkaPublisher = usage.NewPublisher(usage.PublisherConfig{
 			Brokers:      parseKafkaBrokers(cfg.KafkaBrokers),
 			Topic:        cfg.KafkaTopic,
@@ -224,8 +477,17 @@ func main() {
 			BatchTimeout: 1 * time.Second,
 			WriteTimeout: 5 * time.Second,
 			RequiredAcks: 1,
+			Encoding:     usageEncoding,
+			Credentials: func() (string, string) {
+				return credentials.Lookup(secrets.KafkaUsername, cfg.KafkaSASLUsername), credentials.Lookup(secrets.KafkaPassword, cfg.KafkaSASLPassword)
+			},
 		}, logger)
-		logger.Info("Kafka publisher initialized", zap.String("brokers", cfg.KafkaBrokers), zap.String("topic", cfg.KafkaTopic))
+		credentials.OnChange([]string{secrets.KafkaUsername, secrets.KafkaPassword}, func(context.Context) {
+			if err := kafkaPublisher.Reconnect(); err != nil {
+				logger.Warn("failed to close previous Kafka writer", zap.Error(err))
+			}
+		})
+		logger.Info("Kafka publisher initialized", zap.String("brokers", cfg.KafkaBrokers), zap.String("topic", cfg.KafkaTopic), zap.String("encoding", string(usageEncoding)))
 	} else {
 		logger.Info("Kafka publisher not configured (usage tracking disabled)")
 	}
@@ -265,6 +527,21 @@ func main() {
 		defer usageHook.Stop()
 	}
 
+	// Initialize inference archival (orgs opt in; disabled on misconfiguration)
+	var archiver *archive.Archiver
+	if cfg.ArchiveEnabled {
+		archiver, err = newArchiver(ctx, cfg, logger)
+		if err != nil {
+			logger.Error("inference archival disabled", zap.Error(err))
+		} else {
+			logger.Info("inference archival enabled",
+				zap.String("bucket", cfg.ArchiveS3Bucket),
+				zap.String("endpoint", cfg.ArchiveS3Endpoint),
+			)
+			defer archiver.Stop()
+		}
+	}
+
 	// Build metadata (can be set at build time via environment variables)
 	buildMetadata := public.BuildMetadata{
 		Version:   getEnvOrDefault("VERSION", "dev"),
@@ -289,13 +566,40 @@ func main() {
 	// they don't go through authentication middleware. This is required for
 	// Kubernetes liveness/readiness probes to work correctly.
 	router.Get("/v1/status/healthz", statusHandlers.Healthz)
-	router.Get("/v1/status/readyz", statusHandlers.Readyz)
+	router.Get("/v1/status/readyz", srv.ReadinessGate(statusHandlers.Readyz))
 
 	// Initialize backend client
 	backendClient := routing.NewBackendClient(logger, 30*time.Second)
+	backendPools, err := config.ParseBackendPools(cfg.BackendPools)
+	if err != nil {
+		logger.Error("invalid BACKEND_POOLS, using default pools", zap.Error(err))
+	}
+	backendClient.SetPools(backendPools)
+	backendClient.SetEgressPolicy(egressPolicy)
+	backendClient.StartDNSRefresh()
+	defer backendClient.StopDNSRefresh()
+
+	// Initialize fault injection (dev environments only)
+	var chaosInjector *chaos.Injector
+	if cfg.ChaosEnabled {
+		if chaos.Allowed(cfg.Environment) {
+			chaosInjector = chaos.NewInjector(logger)
+			backendClient.SetFaultInjector(chaosInjector)
+			logger.Warn("chaos fault injection enabled", zap.String("environment", cfg.Environment))
+		} else {
+			logger.Error("CHAOS_ENABLED ignored in production", zap.String("environment", cfg.Environment))
+		}
+	}
 
 	// Initialize health monitor
 	healthMonitor := routing.NewHealthMonitor(backendClient, logger, cfg.HealthCheckInterval)
+	healthProbes, err := config.ParseHealthProbes(cfg.HealthProbes)
+	if err != nil {
+		logger.Error("invalid HEALTH_PROBES, using default probes", zap.Error(err))
+	}
+	healthMonitor.SetProbes(healthProbes)
+	healthMonitor.SetJitter(cfg.HealthCheckJitter)
+	healthMonitor.SetWarmup(cfg.BackendWarmupWindow)
 	
 	// Initialize routing engine
 	routingEngine := routing.NewEngine(healthMonitor, backendRegistry, logger)
@@ -307,14 +611,57 @@ func main() {
 		routingMetrics = nil
 	}
 
+	// Model capabilities for GET /v1/models (MODEL_CATALOG)
+	modelCatalog, err := config.ParseModelCatalog(cfg.ModelCatalog)
+	if err != nil {
+		logger.Error("invalid MODEL_CATALOG, models listed without capabilities", zap.Error(err))
+	}
+
+	// Register backends in other clusters/regions (FEDERATED_BACKENDS)
+	federatedBackends, err := config.ParseFederatedBackends(cfg.FederatedBackends)
+	if err != nil {
+		logger.Error("invalid FEDERATED_BACKENDS, no federated backends registered", zap.Error(err))
+	}
+	for _, backend := range federatedBackends {
+		if err := backendRegistry.RegisterFederatedBackend(backend); err != nil {
+			var violation *config.EgressViolation
+			if errors.As(err, &violation) {
+				reportEgressViolation(ctx, securityEvents, logger, violation, securityevents.SourceFederatedBackends)
+				continue
+			}
+			logger.Error("skipping federated backend", zap.String("backend_id", backend.ID), zap.Error(err))
+		}
+	}
+
+	// Enable region-aware routing when this router is part of a federation
+	var federation *routing.Federation
+	if cfg.RouterRegion != "" || len(federatedBackends) > 0 {
+		failoverRules, err := config.ParseFailoverRules(cfg.FederationFailoverRules)
+		if err != nil {
+			logger.Error("invalid FEDERATION_FAILOVER_RULES, using probe latency only", zap.Error(err))
+		}
+		federation = routing.NewFederation(routing.FederationConfig{
+			LocalRegion:   strings.ToLower(cfg.RouterRegion),
+			FailoverRules: failoverRules,
+			Registry:      backendRegistry,
+			HealthMonitor: healthMonitor,
+		})
+		routingEngine.SetFederation(federation)
+		logger.Info("backend federation enabled",
+			zap.String("router_region", cfg.RouterRegion),
+			zap.Int("federated_backends", len(federatedBackends)),
+		)
+	}
+
 	// Register backends with health monitor
 	for _, backendID := range backendRegistry.ListBackends() {
 		backendCfg, err := backendRegistry.GetBackend(backendID)
 		if err == nil {
 			endpoint := &routing.BackendEndpoint{
 				ID:      backendCfg.ID,
-				URI:     backendCfg.URI,
-				Timeout: backendCfg.Timeout,
+				URI:      backendCfg.URI,
+				Timeout:  backendCfg.Timeout,
+				Protocol: backendCfg.Protocol,
 			}
 			healthMonitor.RegisterBackend(backendID, endpoint)
 		}
@@ -326,6 +673,15 @@ func main() {
 
 	// Initialize public API handler with routing engine and usage hook
 	publicHandler := public.NewHandler(logger, authenticator, loader, backendClient, backendRegistry, routingEngine, routingMetrics, usageHook)
+	if archiver != nil {
+		publicHandler.SetArchiver(archiver)
+	}
+	publicHandler.SetDefaultToolLimits(auth.ToolLimits{
+		MaxTools:      cfg.ToolsMaxCount,
+		MaxTotalBytes: cfg.ToolsMaxTotalBytes,
+	})
+	publicHandler.SetStructuredOutputRetries(cfg.StructuredOutputMaxRetries)
+	publicHandler.SetModelCatalog(modelCatalog, healthMonitor)
 
 	// Create tracer for middleware
 	tracer := otel.Tracer("api-router-service")
@@ -341,44 +697,91 @@ func main() {
 	//   3. Apply consistent middleware chain to all authenticated routes
 	//
 	// CRITICAL: Middleware order matters! The order below is intentional:
-	//   1. BodyBufferMiddleware - Must be first to buffer request body for:
+	//   0. ResponseCompressionMiddleware, DecompressMiddleware - Before the body
+	//      buffer so limits, HMAC and handlers all see the decompressed body
+	//
+	//   1. BodyBufferLimitsMiddleware - Must be first to buffer request body for:
 	//      - HMAC signature verification (requires full body)
 	//      - Model extraction from request payload
 	//      - Request body reuse in subsequent middleware
+	//      - Rejecting oversized bodies (413) before any other work
 	//
 	//   2. AuthContextMiddleware - Must come after body buffer because:
 	//      - HMAC verification needs the buffered body
 	//      - Sets auth context for downstream middleware and handlers
 	//
+	//   2a. BodyLimitMiddleware - Right after auth to:
+	//      - Tighten the body limit to the org tier's (buffering used the loosest)
+	//
+	//   2b. NetworkRestrictionMiddleware - Right after auth to:
+	//      - Enforce per-key CIDR allowlists and required headers before any
+	//        limiter or budget state is touched
+	//
 	//   3. RateLimitMiddleware - Applied after auth to:
 	//      - Use authenticated user/org context for rate limiting
 	//      - Track rate limits per organization or API key
+	//      - Emit X-RateLimit-* headers and a Warning near the soft threshold
+	//      - Hold per-org/key concurrency slots until the request completes
+	//      - Hold per-org/key stream slots (heartbeated) until a stream ends
 	//
 	//   4. BudgetMiddleware - Applied after rate limit to:
 	//      - Check budget/quota after rate limit passes
 	//      - Use authenticated context for budget checks
+	//      - Hold the estimated cost of streaming/long requests until they complete
 	//
 	// DO NOT change this order without understanding the dependencies!
 	// ============================================================================
 
+	routeBodyLimits, err := config.ParseByteLimits(cfg.RouteBodyLimits)
+	if err != nil {
+		logger.Error("invalid ROUTE_BODY_LIMITS, using MAX_REQUEST_BODY_BYTES for all routes", zap.Error(err))
+	}
+	tierBodyLimits, err := config.ParseByteLimits(cfg.TierBodyLimits)
+	if err != nil {
+		logger.Error("invalid TIER_BODY_LIMITS, using MAX_REQUEST_BODY_BYTES for all tiers", zap.Error(err))
+	}
+	bodyLimits := public.BodyLimits{
+		BufferBytes: cfg.RequestBufferBytes,
+		MaxBytes:    cfg.MaxRequestBodyBytes,
+		Routes:      routeBodyLimits,
+		Tiers:       tierBodyLimits,
+	}
+
 	appRouter := chi.NewRouter()
 
-	// Step 1: Body buffer (MUST be first)
-	appRouter.Use(public.BodyBufferMiddleware(64 * 1024)) // 64 KB max body size
+	// Step 0: Compression (negotiated responses, Content-Encoding request bodies)
+	appRouter.Use(public.ResponseCompressionMiddleware(cfg.ResponseCompressionLevel))
+	appRouter.Use(public.DecompressMiddleware(bodyLimits))
+
+	// Step 1: Body buffer (MUST be first after decompression)
+	appRouter.Use(public.BodyBufferLimitsMiddleware(bodyLimits))
 
 	// Step 2: Authentication (requires buffered body for HMAC)
 	appRouter.Use(public.AuthContextMiddleware(authenticator, logger, tracer))
+
+	// Step 2a: Tier body limits (requires auth context)
+	appRouter.Use(public.BodyLimitMiddleware(bodyLimits))
+
+	// Step 2b: API key network restrictions (requires auth context and RealIP)
+	appRouter.Use(public.NetworkRestrictionMiddleware(auditLogger, logger, tracer))
+
+	// Step 2c: Org CORS origins (requires auth context)
+	appRouter.Use(public.OrgCORSMiddleware(logger, tracer))
 	
+	// Step 2d: Abuse throttling and detection (requires auth context)
+	appRouter.Use(public.AbuseMiddleware(abuseDetector, rateLimiter, auditLogger, logger, tracer))
+
 	// Step 3: Rate limiting (requires auth context)
-	if rateLimiter != nil {
-		appRouter.Use(public.RateLimitMiddleware(rateLimiter, auditLogger, logger, tracer))
-	} else {
-		logger.Warn("rate limiting disabled (Redis unavailable)")
-	}
+	appRouter.Use(public.RateLimitMiddleware(rateLimiter, auditLogger, logger, tracer))
 
 	// Step 4: Budget enforcement (requires auth context)
 	appRouter.Use(public.BudgetMiddleware(budgetClient, auditLogger, logger, tracer))
 
+	// Step 5: Fault injection (dev only; /v1/admin/* is exempt)
+	if chaosInjector != nil {
+		appRouter.Use(chaosInjector.Middleware())
+	}
+
 	// Register all authenticated routes on sub-router
 	// These routes will go through the middleware chain above in order
 	publicHandler.RegisterRoutes(appRouter)
@@ -386,6 +789,40 @@ func main() {
 	// Register admin routes on sub-router (requires authentication)
 	adminHandler := admin.NewHandler(logger, loader, healthMonitor, routingEngine, backendRegistry)
 	adminHandler.RegisterRoutes(appRouter)
+	adminHandler.SetRateLimiter(rateLimiter)
+	adminHandler.AddCache("config", loader)
+	adminHandler.AddCache("auth-validation", authenticator)
+	adminHandler.SetMaintenance(maintenanceController)
+	adminHandler.SetAbuseDetector(abuseDetector)
+	adminHandler.SetSecurityEvents(securityEvents)
+	if chaosInjector != nil {
+		adminHandler.SetChaosInjector(chaosInjector)
+	}
+	if federation != nil {
+		adminHandler.SetFederation(federation)
+	}
+	if archiver != nil {
+		adminHandler.SetArchiver(archiver)
+		adminHandler.SetReplayer(publicHandler)
+	}
+
+	// Register runtime diagnostics and optional profiling endpoints (requires admin scope)
+	appRouter.Group(func(r chi.Router) {
+		r.Use(public.RequireScopeMiddleware(cfg.AdminScope, logger, tracer))
+		r.Get("/v1/admin/diagnostics", sharedserver.DiagnosticsHandler(sharedserver.ConfigFingerprint(cfg)))
+		adminHandler.RegisterChaosRoutes(r)
+		adminHandler.RegisterFederationRoutes(r)
+		adminHandler.RegisterArchiveRoutes(r)
+		adminHandler.RegisterRateLimitRoutes(r)
+		adminHandler.RegisterCacheRoutes(r)
+		adminHandler.RegisterMaintenanceRoutes(r)
+		adminHandler.RegisterAbuseRoutes(r)
+		if cfg.DebugEndpointsEnabled {
+			debugHandler := sharedserver.DebugHandler()
+			r.Handle(sharedserver.DebugPathPrefix+"*", debugHandler)
+			r.Handle(sharedserver.DebugVarsPath, debugHandler)
+		}
+	})
 
 	// Register audit routes on sub-router (requires authentication)
 	auditHandler := public.NewAuditHandler(logger, bufferStore)
@@ -406,42 +843,64 @@ func main() {
 		}()
 	}
 
-	// Metrics endpoint on main router (no auth required for Prometheus scraping)
-	// This must be registered on main router, not appRouter, to avoid authentication
-	router.Handle("/metrics", promhttp.Handler())
-
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.HTTPPort),
-		Handler: router,
-	}
-
-	// Start server in goroutine
-	go func() {
-		logger.Info("HTTP server starting", zap.String("addr", srv.Addr))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("HTTP server failed", zap.Error(err))
-		}
-	}()
+	// Metrics (/metrics) and probe endpoints are served by the lifecycle server
+	// ahead of the router, so they never pass through authentication.
+	srv.SetHandler(router)
 
-	// Wait for interrupt signal
+	// Serve until interrupted, then drain and shut down gracefully
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	<-ctx.Done()
-	logger.Info("shutting down gracefully")
-
-	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		logger.Error("graceful shutdown failed", zap.Error(err))
+	if err := srv.Run(ctx); err != nil {
+		logger.Error("HTTP server failed", zap.Error(err))
 		os.Exit(1)
 	}
 
 	logger.Info("API router service stopped")
 }
 
+// newArchiver builds the inference archiver from ARCHIVE_* settings.
+func newArchiver(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*archive.Archiver, error) {
+	key, err := archive.ParseEncryptionKey(cfg.ArchiveEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	store, err := archive.NewS3Store(ctx, archive.S3Config{
+		Endpoint:  cfg.ArchiveS3Endpoint,
+		Region:    cfg.ArchiveS3Region,
+		Bucket:    cfg.ArchiveS3Bucket,
+		AccessKey: cfg.ArchiveS3AccessKey,
+		SecretKey: cfg.ArchiveS3SecretKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return archive.New(archive.Config{
+		Store:         store,
+		EncryptionKey: key,
+		Prefix:        cfg.ArchiveKeyPrefix,
+		QueueSize:     cfg.ArchiveQueueSize,
+		PurgeInterval: cfg.ArchivePurgeInterval,
+		Logger:        logger,
+	})
+}
+
+// reportEgressViolation logs a backend rejected by the egress allowlist and
+// publishes it as a security event.
+func reportEgressViolation(ctx context.Context, publisher securityevents.Publisher, logger *zap.Logger, violation *config.EgressViolation, source string) {
+	logger.Error("backend rejected by egress allowlist",
+		zap.String("backend_id", violation.BackendID),
+		zap.String("uri", violation.URI),
+		zap.String("reason", violation.Reason),
+		zap.String("source", source),
+	)
+	telemetry.RecordEgressViolation(source)
+	event := securityevents.EgressDenied(violation.BackendID, violation.URI, violation.Reason, source, "")
+	if err := publisher.Publish(ctx, event); err != nil {
+		logger.Warn("failed to publish egress security event", zap.Error(err))
+	}
+}
+
 // parseKafkaBrokers parses a comma-separated list of Kafka broker addresses.
 func parseKafkaBrokers(brokers string) []string {
 	if brokers == "" {
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ai-aas/shared-go/preflight"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+// validateConfig implements --validate-config: it loads and validates the
+// configuration, probes every declared dependency in parallel, prints a JSON
+// report to stdout and returns the process exit code.
+func validateConfig() int {
+	service := "api-router-service"
+	cfg, err := config.Load()
+	var checks []preflight.Check
+	if err == nil {
+		service = cfg.ServiceName
+		err = cfg.Validate()
+		if cfg.ArchiveEnabled && cfg.ArchiveEncryptionKey != "" {
+			if _, keyErr := archive.ParseEncryptionKey(cfg.ArchiveEncryptionKey); keyErr != nil {
+				err = errors.Join(err, fmt.Errorf("ARCHIVE_ENCRYPTION_KEY: %w", keyErr))
+			}
+		}
+		checks = dependencyChecks(cfg)
+	}
+
+	report := preflight.Run(context.Background(), service, err, checks, 0)
+	if writeErr := report.Write(os.Stdout); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", writeErr)
+	}
+	return report.ExitCode()
+}
+
+// dependencyChecks lists the services the router connects to at startup or
+// on the request path.
+func dependencyChecks(cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{Name: "user-org-service", Run: preflight.HTTP(strings.TrimSuffix(cfg.UserOrgServiceURL, "/") + "/healthz")},
+	}
+	if cfg.ConfigServiceEndpoint != "" {
+		checks = append(checks, preflight.Check{Name: "config-service", Run: preflight.TCP(cfg.ConfigServiceEndpoint)})
+	}
+	if cfg.BudgetServiceEndpoint != "" {
+		checks = append(checks, preflight.Check{Name: "budget-service", Run: preflight.HTTP(cfg.BudgetServiceEndpoint)})
+	}
+	for _, addr := range strings.Split(cfg.RateLimitRedisAddr, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			checks = append(checks, preflight.Check{Name: "rate-limit-redis " + addr, Run: preflight.TCP(addr)})
+		}
+	}
+	for _, broker := range parseKafkaBrokers(cfg.KafkaBrokers) {
+		checks = append(checks, preflight.Check{Name: "kafka " + broker, Run: preflight.TCP(broker)})
+	}
+
+	registry := config.NewBackendRegistry(cfg)
+	for _, id := range registry.ListBackends() {
+		if backend, err := registry.GetBackend(id); err == nil {
+			checks = append(checks, preflight.Check{Name: "backend " + id, Run: preflight.URLHost(backend.URI)})
+		}
+	}
+	if cfg.ArchiveEnabled && cfg.ArchiveS3Endpoint != "" {
+		checks = append(checks, preflight.Check{Name: "archive-s3", Run: preflight.URLHost(cfg.ArchiveS3Endpoint)})
+	}
+	return checks
+}
@@ -0,0 +1,430 @@
+// Package abuse detects anomalous API key traffic and throttles it.
+//
+// Purpose:
+//   A leaked or misused key usually shows up as a sudden jump in traffic or
+//   as a flood of failing requests. The Detector counts each key's requests
+//   and errors per minute in Redis, shared by every router, and flags a key
+//   whose rate jumps far above its own recent baseline or whose error rate
+//   is too high. A flagged key is held to a much stricter rate limit for a
+//   while and a security event is raised so a human can review it.
+//
+// Key Responsibilities:
+//   - Observe: count a finished request and check the key's current minute
+//   - Throttled: report whether a key is flagged (cached briefly per router)
+//   - Flags / Clear: list flagged keys and lift a flag after review
+//
+// Debugging Notes:
+//   - Counters live under abuse:{<key id>}:<kind>:<unix minute>; the hash tag
+//     keeps one key's counters on one cluster slot
+//   - Only authenticated requests are counted; 429s are not errors, so the
+//     throttle itself cannot keep a key flagged
+//   - Keys are evaluated once they reach the minimum requests in a minute,
+//     so quiet keys never cost more than one INCR per request
+//   - A key with no history is compared against one request per minute
+//   - Flags expire on their own after the throttle duration; clearing one
+//     takes up to flagCacheTTL to reach every router
+//   - Every method is a no-op on a nil Detector (Redis not configured)
+//
+package abuse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/securityevents"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// Reasons a key is flagged.
+const (
+	ReasonRequestSpike = "request_spike"
+	ReasonErrorRate    = "error_rate"
+)
+
+const (
+	flagsIndexKey = "abuse:flags"
+	// flagCacheTTL bounds how long a router trusts its cached view of a flag.
+	flagCacheTTL = 5 * time.Second
+	// evaluateEvery re-evaluates a busy key every this many requests.
+	evaluateEvery = 10
+	// baselineFloor is the baseline assumed for keys with no history.
+	baselineFloor  = 1.0
+	publishTimeout = 10 * time.Second
+)
+
+// Config configures a Detector.
+type Config struct {
+	Redis redis.UniversalClient
+	// Publisher receives a security event for every new flag.
+	Publisher securityevents.Publisher
+	Logger    *zap.Logger
+	// SpikeFactor flags a key whose requests in a minute reach this multiple
+	// of its average over BaselineWindow.
+	SpikeFactor    float64
+	BaselineWindow time.Duration
+	// MinRequests is the requests in a minute below which a key is never flagged.
+	MinRequests int
+	// ErrorRateThreshold flags a key whose fraction of failed requests in a
+	// minute reaches it; 0 disables error rate detection.
+	ErrorRateThreshold float64
+	// ThrottleDuration is how long a flag, and the stricter limit, lasts.
+	ThrottleDuration time.Duration
+	// ThrottleRPS and ThrottleBurst are the rate limit of flagged keys.
+	ThrottleRPS   int
+	ThrottleBurst int
+}
+
+// Flag records why a key was throttled.
+type Flag struct {
+	APIKeyID string `json:"apiKeyId"`
+	OrgID    string `json:"orgId"`
+	Reason   string `json:"reason"`
+	// RequestsPerMinute and ErrorRate describe the minute that raised the flag.
+	RequestsPerMinute int64     `json:"requestsPerMinute"`
+	BaselinePerMinute float64   `json:"baselinePerMinute"`
+	ErrorRate         float64   `json:"errorRate"`
+	FlaggedAt         time.Time `json:"flaggedAt"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+// cachedFlag is a router-local view of a key's flag; flag is nil when the
+// key is not flagged.
+type cachedFlag struct {
+	flag      *Flag
+	checkedAt time.Time
+}
+
+// Detector counts requests per key and throttles anomalous keys.
+type Detector struct {
+	cfg       Config
+	redis     redis.UniversalClient
+	publisher securityevents.Publisher
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu    sync.Mutex
+	flags map[string]cachedFlag
+}
+
+// New returns a Detector, or nil when cfg.Redis is nil (every method is then a no-op).
+func New(cfg Config) *Detector {
+	if cfg.Redis == nil {
+		return nil
+	}
+	if c, ok := cfg.Redis.(*redis.Client); ok && c == nil {
+		return nil
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	if cfg.BaselineWindow < time.Minute {
+		cfg.BaselineWindow = time.Hour
+	}
+	logger := cfg.Logger.With(zap.String("component", "abuse-detector"))
+	if cfg.Publisher == nil {
+		cfg.Publisher = &securityevents.LogPublisher{Logger: logger}
+	}
+	return &Detector{
+		cfg:       cfg,
+		redis:     cfg.Redis,
+		publisher: cfg.Publisher,
+		logger:    logger,
+		now:       time.Now,
+		flags:     make(map[string]cachedFlag),
+	}
+}
+
+// ThrottleLimits returns the rate limit applied to flagged keys.
+func (d *Detector) ThrottleLimits() (rps, burst int) {
+	return d.cfg.ThrottleRPS, d.cfg.ThrottleBurst
+}
+
+// Observe counts one finished request of apiKeyID with the given response
+// status and flags the key if its current minute looks anomalous.
+func (d *Detector) Observe(ctx context.Context, orgID, apiKeyID string, status int) {
+	if d == nil || apiKeyID == "" {
+		return
+	}
+	minute := d.now().Unix() / 60
+	ttl := d.cfg.BaselineWindow + 2*time.Minute
+	failed := isError(status)
+
+	pipe := d.redis.Pipeline()
+	requests := pipe.Incr(ctx, counterKey(apiKeyID, "req", minute))
+	pipe.Expire(ctx, counterKey(apiKeyID, "req", minute), ttl)
+	if failed {
+		pipe.Incr(ctx, counterKey(apiKeyID, "err", minute))
+		pipe.Expire(ctx, counterKey(apiKeyID, "err", minute), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		d.logger.Debug("failed to count request", zap.String("api_key_id", apiKeyID), zap.Error(err))
+		return
+	}
+
+	count := requests.Val()
+	if d.cfg.MinRequests <= 0 || count < int64(d.cfg.MinRequests) || (count-int64(d.cfg.MinRequests))%evaluateEvery != 0 {
+		return
+	}
+	if d.flagged(ctx, apiKeyID) != nil {
+		return
+	}
+	d.evaluate(ctx, orgID, apiKeyID, minute, count)
+}
+
+// evaluate compares the key's current minute with its baseline.
+func (d *Detector) evaluate(ctx context.Context, orgID, apiKeyID string, minute, requests int64) {
+	window := int64(d.cfg.BaselineWindow / time.Minute)
+	keys := make([]string, 0, window+1)
+	keys = append(keys, counterKey(apiKeyID, "err", minute))
+	for m := minute - window; m < minute; m++ {
+		keys = append(keys, counterKey(apiKeyID, "req", m))
+	}
+	values, err := d.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		d.logger.Warn("failed to read request counters", zap.String("api_key_id", apiKeyID), zap.Error(err))
+		return
+	}
+
+	errorCount := parseCount(values[0])
+	var history int64
+	for _, v := range values[1:] {
+		history += parseCount(v)
+	}
+	baseline := float64(history) / float64(window)
+
+	reason, errorRate := d.detect(requests, errorCount, baseline)
+	if reason == "" {
+		return
+	}
+	now := d.now().UTC()
+	d.flag(ctx, &Flag{
+		APIKeyID:          apiKeyID,
+		OrgID:             orgID,
+		Reason:            reason,
+		RequestsPerMinute: requests,
+		BaselinePerMinute: baseline,
+		ErrorRate:         errorRate,
+		FlaggedAt:         now,
+		ExpiresAt:         now.Add(d.cfg.ThrottleDuration),
+	})
+}
+
+// detect returns the reason a minute with requests and errors is anomalous
+// against baseline requests per minute, or "" if it is not.
+func (d *Detector) detect(requests, errorCount int64, baseline float64) (reason string, errorRate float64) {
+	if requests < int64(d.cfg.MinRequests) || requests == 0 {
+		return "", 0
+	}
+	errorRate = float64(errorCount) / float64(requests)
+	if d.cfg.SpikeFactor > 0 && float64(requests) >= d.cfg.SpikeFactor*max(baseline, baselineFloor) {
+		return ReasonRequestSpike, errorRate
+	}
+	if d.cfg.ErrorRateThreshold > 0 && errorRate >= d.cfg.ErrorRateThreshold {
+		return ReasonErrorRate, errorRate
+	}
+	return "", errorRate
+}
+
+// flag stores flag unless the key is already flagged, and raises a security
+// event for a new flag.
+func (d *Detector) flag(ctx context.Context, flag *Flag) {
+	payload, err := json.Marshal(flag)
+	if err != nil {
+		return
+	}
+	created, err := d.redis.SetNX(ctx, flagKey(flag.APIKeyID), payload, d.cfg.ThrottleDuration).Result()
+	if err != nil {
+		d.logger.Warn("failed to store abuse flag", zap.String("api_key_id", flag.APIKeyID), zap.Error(err))
+		return
+	}
+	if !created {
+		return
+	}
+	if err := d.redis.ZAdd(ctx, flagsIndexKey, redis.Z{Score: float64(flag.ExpiresAt.Unix()), Member: flag.APIKeyID}).Err(); err != nil {
+		d.logger.Warn("failed to index abuse flag", zap.String("api_key_id", flag.APIKeyID), zap.Error(err))
+	}
+	d.cache(flag.APIKeyID, flag)
+	telemetry.RecordAbuseFlag(flag.Reason)
+
+	d.logger.Warn("API key throttled for suspected abuse",
+		zap.String("api_key_id", flag.APIKeyID),
+		zap.String("org_id", flag.OrgID),
+		zap.String("reason", flag.Reason),
+		zap.Int64("requests_per_minute", flag.RequestsPerMinute),
+		zap.Float64("baseline_per_minute", flag.BaselinePerMinute),
+		zap.Float64("error_rate", flag.ErrorRate),
+		zap.Time("expires_at", flag.ExpiresAt),
+	)
+
+	// Delivery must not hold up the request that tipped the key over
+	event := d.event(flag)
+	go func() {
+		publishCtx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		defer cancel()
+		if err := d.publisher.Publish(publishCtx, event); err != nil {
+			d.logger.Error("failed to publish security event", zap.String("event_id", event.EventID.String()), zap.Error(err))
+		}
+	}()
+}
+
+// event describes flag as a security event for human review.
+func (d *Detector) event(flag *Flag) securityevents.Event {
+	summary := fmt.Sprintf("API key sent %d requests in a minute, %.0fx its baseline of %.1f", flag.RequestsPerMinute, float64(flag.RequestsPerMinute)/max(flag.BaselinePerMinute, baselineFloor), flag.BaselinePerMinute)
+	if flag.Reason == ReasonErrorRate {
+		summary = fmt.Sprintf("API key failed %.0f%% of %d requests in a minute", flag.ErrorRate*100, flag.RequestsPerMinute)
+	}
+	orgID, _ := uuid.Parse(flag.OrgID)
+	return securityevents.Event{
+		EventID:     uuid.New(),
+		Type:        securityevents.TypeAPIKeyAbuseThrottled,
+		Severity:    securityevents.SeverityHigh,
+		OrgID:       orgID,
+		SubjectType: "api_key",
+		SubjectID:   flag.APIKeyID,
+		Summary:     summary + fmt.Sprintf("; throttled to %d rps until %s", d.cfg.ThrottleRPS, flag.ExpiresAt.Format(time.RFC3339)),
+		Details: map[string]any{
+			"reason":              flag.Reason,
+			"requests_per_minute": flag.RequestsPerMinute,
+			"baseline_per_minute": flag.BaselinePerMinute,
+			"error_rate":          flag.ErrorRate,
+			"throttle_rps":        d.cfg.ThrottleRPS,
+			"expires_at":          flag.ExpiresAt,
+		},
+		OccurredAt: flag.FlaggedAt,
+	}
+}
+
+// Throttled returns the key's flag, or nil if it is not flagged. Redis
+// errors count as not flagged.
+func (d *Detector) Throttled(ctx context.Context, apiKeyID string) *Flag {
+	if d == nil || apiKeyID == "" {
+		return nil
+	}
+	return d.flagged(ctx, apiKeyID)
+}
+
+func (d *Detector) flagged(ctx context.Context, apiKeyID string) *Flag {
+	now := d.now()
+	d.mu.Lock()
+	cached, ok := d.flags[apiKeyID]
+	d.mu.Unlock()
+	if ok && now.Sub(cached.checkedAt) < flagCacheTTL {
+		if cached.flag != nil && !now.Before(cached.flag.ExpiresAt) {
+			return nil
+		}
+		return cached.flag
+	}
+
+	flag, err := d.load(ctx, apiKeyID)
+	if err != nil {
+		d.logger.Debug("failed to read abuse flag", zap.String("api_key_id", apiKeyID), zap.Error(err))
+		return nil
+	}
+	d.cache(apiKeyID, flag)
+	return flag
+}
+
+func (d *Detector) load(ctx context.Context, apiKeyID string) (*Flag, error) {
+	payload, err := d.redis.Get(ctx, flagKey(apiKeyID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var flag Flag
+	if err := json.Unmarshal(payload, &flag); err != nil {
+		return nil, fmt.Errorf("decode abuse flag: %w", err)
+	}
+	return &flag, nil
+}
+
+func (d *Detector) cache(apiKeyID string, flag *Flag) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// Drop stale entries so keys seen once do not accumulate
+	if len(d.flags) > 10000 {
+		cutoff := d.now().Add(-flagCacheTTL)
+		for id, cached := range d.flags {
+			if cached.checkedAt.Before(cutoff) {
+				delete(d.flags, id)
+			}
+		}
+	}
+	d.flags[apiKeyID] = cachedFlag{flag: flag, checkedAt: d.now()}
+}
+
+// Flags returns every flagged key, soonest to expire first.
+func (d *Detector) Flags(ctx context.Context) ([]Flag, error) {
+	if d == nil {
+		return nil, nil
+	}
+	now := d.now().Unix()
+	if err := d.redis.ZRemRangeByScore(ctx, flagsIndexKey, "-inf", strconv.FormatInt(now, 10)).Err(); err != nil {
+		return nil, fmt.Errorf("prune abuse flags: %w", err)
+	}
+	ids, err := d.redis.ZRange(ctx, flagsIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list abuse flags: %w", err)
+	}
+	flags := make([]Flag, 0, len(ids))
+	for _, id := range ids {
+		flag, err := d.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if flag != nil {
+			flags = append(flags, *flag)
+		}
+	}
+	return flags, nil
+}
+
+// Clear lifts a key's flag, e.g. once a human has reviewed the security
+// event, and reports whether the key was flagged. Other routers notice
+// within flagCacheTTL.
+func (d *Detector) Clear(ctx context.Context, apiKeyID string) (bool, error) {
+	if d == nil {
+		return false, nil
+	}
+	deleted, err := d.redis.Del(ctx, flagKey(apiKeyID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("clear abuse flag: %w", err)
+	}
+	if err := d.redis.ZRem(ctx, flagsIndexKey, apiKeyID).Err(); err != nil {
+		return false, fmt.Errorf("clear abuse flag: %w", err)
+	}
+	d.cache(apiKeyID, nil)
+	return deleted > 0, nil
+}
+
+// isError reports whether a response counts toward a key's error rate.
+func isError(status int) bool {
+	return status >= 400 && status != 429
+}
+
+func counterKey(apiKeyID, kind string, minute int64) string {
+	return fmt.Sprintf("abuse:{%s}:%s:%d", apiKeyID, kind, minute)
+}
+
+func flagKey(apiKeyID string) string {
+	return fmt.Sprintf("abuse:{%s}:flag", apiKeyID)
+}
+
+func parseCount(v interface{}) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -0,0 +1,127 @@
+// Package abuse provides unit tests for abuse detection.
+//
+// Purpose:
+//   These tests validate spike and error rate detection against a key's
+//   baseline, and that flags throttle a key until cleared (with Redis).
+//
+package abuse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/securityevents"
+)
+
+func testConfig() Config {
+	return Config{
+		SpikeFactor:        100,
+		BaselineWindow:     time.Hour,
+		MinRequests:        600,
+		ErrorRateThreshold: 0.5,
+		ThrottleDuration:   15 * time.Minute,
+		ThrottleRPS:        1,
+		ThrottleBurst:      5,
+	}
+}
+
+func TestDetect(t *testing.T) {
+	d := &Detector{cfg: testConfig()}
+	cases := []struct {
+		name     string
+		requests int64
+		errors   int64
+		baseline float64
+		want     string
+	}{
+		{"below minimum", 599, 599, 0, ""},
+		{"steady traffic", 1200, 10, 1000, ""},
+		{"spike over baseline", 1000, 0, 10, ReasonRequestSpike},
+		{"spike on quiet key", 600, 0, 0, ReasonRequestSpike},
+		{"just under spike", 999, 0, 10, ""},
+		{"high error rate", 1200, 600, 1000, ReasonErrorRate},
+	}
+	for _, tc := range cases {
+		if got, _ := d.detect(tc.requests, tc.errors, tc.baseline); got != tc.want {
+			t.Errorf("%s: detect(%d, %d, %g) = %q, want %q", tc.name, tc.requests, tc.errors, tc.baseline, got, tc.want)
+		}
+	}
+}
+
+func TestIsError(t *testing.T) {
+	for status, want := range map[int]bool{200: false, 400: true, 403: true, 429: false, 502: true} {
+		if got := isError(status); got != want {
+			t.Errorf("isError(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestNilDetector(t *testing.T) {
+	var d *Detector
+	d.Observe(context.Background(), "org-1", "key-1", 200)
+	if d.Throttled(context.Background(), "key-1") != nil {
+		t.Fatal("nil detector throttled a key")
+	}
+	if New(Config{}) != nil {
+		t.Fatal("expected nil detector without Redis")
+	}
+}
+
+type recordingPublisher struct {
+	events chan securityevents.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event securityevents.Event) error {
+	p.events <- event
+	return nil
+}
+
+func TestObserveFlagsSpikeAndClear(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 15})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available, skipping test: %v", err)
+	}
+	client.FlushDB(ctx)
+
+	publisher := &recordingPublisher{events: make(chan securityevents.Event, 1)}
+	cfg := testConfig()
+	cfg.Redis = client
+	cfg.Publisher = publisher
+	cfg.Logger = zap.NewNop()
+	cfg.MinRequests = 20
+	cfg.SpikeFactor = 10
+	d := New(cfg)
+
+	for i := 0; i < 20; i++ {
+		d.Observe(ctx, "org-1", "key-1", 200)
+	}
+	flag := d.Throttled(ctx, "key-1")
+	if flag == nil || flag.Reason != ReasonRequestSpike {
+		t.Fatalf("expected request spike flag, got %+v", flag)
+	}
+	select {
+	case event := <-publisher.events:
+		if event.Type != securityevents.TypeAPIKeyAbuseThrottled || event.SubjectID != "key-1" {
+			t.Fatalf("unexpected security event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a security event")
+	}
+
+	flags, err := d.Flags(ctx)
+	if err != nil || len(flags) != 1 {
+		t.Fatalf("Flags = %+v, %v; want one flag", flags, err)
+	}
+	if cleared, err := d.Clear(ctx, "key-1"); err != nil || !cleared {
+		t.Fatalf("Clear = %v, %v", cleared, err)
+	}
+	if d.Throttled(ctx, "key-1") != nil {
+		t.Fatal("key still throttled after clear")
+	}
+}
@@ -0,0 +1,71 @@
+// Package admin provides HTTP handlers for abuse throttle review.
+//
+// Purpose:
+//   These handlers list the API keys the abuse detector has throttled and
+//   let operators lift a throttle once the security event has been reviewed
+//   and the traffic found legitimate.
+//
+// Debugging Notes:
+//   - Flags are shared by every router through Redis; a cleared flag takes up
+//     to five seconds to stop applying on other pods
+//   - Every clear is logged with the caller's API key for access review
+//
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/abuse"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+)
+
+// SetAbuseDetector enables the abuse throttle endpoints.
+func (h *Handler) SetAbuseDetector(detector *abuse.Detector) {
+	h.abuse = detector
+}
+
+// RegisterAbuseRoutes registers abuse throttle routes. It is a no-op unless
+// SetAbuseDetector was called with a detector.
+func (h *Handler) RegisterAbuseRoutes(r chi.Router) {
+	if h.abuse == nil {
+		return
+	}
+	r.Get("/v1/admin/abuse/flags", h.ListAbuseFlags)
+	r.Delete("/v1/admin/abuse/flags/{apiKeyID}", h.ClearAbuseFlag)
+}
+
+// ListAbuseFlags returns every throttled key with the reason it was flagged.
+func (h *Handler) ListAbuseFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.abuse.Flags(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list abuse flags", zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to read abuse flags"), api.ErrCodeServiceUnavailable)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"flags": flags})
+}
+
+// ClearAbuseFlag lifts a key's throttle.
+func (h *Handler) ClearAbuseFlag(w http.ResponseWriter, r *http.Request) {
+	apiKeyID := chi.URLParam(r, "apiKeyID")
+	cleared, err := h.abuse.Clear(r.Context(), apiKeyID)
+	if err != nil {
+		h.logger.Error("failed to clear abuse flag", zap.String("api_key_id", apiKeyID), zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to clear abuse flag"), api.ErrCodeServiceUnavailable)
+		return
+	}
+	if !cleared {
+		h.writeError(w, r, fmt.Errorf("API key %q is not throttled", apiKeyID), api.ErrCodeNotFound)
+		return
+	}
+
+	h.logger.Info("abuse flag cleared",
+		zap.String("api_key_id", apiKeyID),
+		zap.String("cleared_by_api_key", callerAPIKeyID(r)),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,137 @@
+// Package admin provides HTTP handlers for inference archive retrieval.
+//
+// Purpose:
+//   These handlers return archived prompts and responses by org and request
+//   ID for debugging and compliance review, and replay an archived request
+//   against a chosen backend to compare the responses side by side.
+//
+// Debugging Notes:
+//   - Routes are only registered when ARCHIVE_ENABLED=true
+//   - Every retrieval and replay is logged with the caller's API key for access review
+//   - Expired records are deleted on read and return 404
+//   - Replays of redacted records send the redacted prompt
+//
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+// Replayer re-executes archived requests against a backend.
+type Replayer interface {
+	Replay(ctx context.Context, record *archive.Record, backendID string) (*archive.ReplayRun, error)
+}
+
+// SetArchiver enables the archive retrieval endpoints.
+func (h *Handler) SetArchiver(archiver *archive.Archiver) {
+	h.archiver = archiver
+}
+
+// SetReplayer enables the replay endpoint.
+func (h *Handler) SetReplayer(replayer Replayer) {
+	h.replayer = replayer
+}
+
+// RegisterArchiveRoutes registers archive retrieval routes. It is a no-op
+// unless SetArchiver was called.
+func (h *Handler) RegisterArchiveRoutes(r chi.Router) {
+	if h.archiver == nil {
+		return
+	}
+	r.Get("/v1/admin/archive/{orgID}/{requestID}", h.GetArchivedExchange)
+	if h.replayer != nil {
+		r.Post("/v1/admin/replay/{requestID}", h.ReplayArchivedRequest)
+	}
+}
+
+// GetArchivedExchange returns one archived request/response pair.
+func (h *Handler) GetArchivedExchange(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	requestID := chi.URLParam(r, "requestID")
+
+	record, ok := h.loadArchivedRecord(w, r, orgID, requestID)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("archived request retrieved",
+		zap.String("org_id", orgID),
+		zap.String("request_id", requestID),
+		zap.String("accessed_by_api_key", callerAPIKeyID(r)),
+	)
+	h.writeJSON(w, http.StatusOK, record)
+}
+
+// ReplayArchivedRequest re-executes an archived request against ?backend= and
+// returns the original and replayed responses, latencies and token counts.
+// The archive is keyed by org, so ?org= is required.
+func (h *Handler) ReplayArchivedRequest(w http.ResponseWriter, r *http.Request) {
+	requestID := chi.URLParam(r, "requestID")
+	orgID := r.URL.Query().Get("org")
+	backendID := r.URL.Query().Get("backend")
+	if orgID == "" || backendID == "" {
+		h.writeError(w, r, fmt.Errorf("org and backend query parameters are required"), api.ErrCodeMissingField)
+		return
+	}
+	if _, err := h.backendRegistry.GetBackend(backendID); err != nil {
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+
+	record, ok := h.loadArchivedRecord(w, r, orgID, requestID)
+	if !ok {
+		return
+	}
+
+	run, err := h.replayer.Replay(r.Context(), record, backendID)
+	if err != nil {
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+
+	h.logger.Info("archived request replayed",
+		zap.String("org_id", orgID),
+		zap.String("request_id", requestID),
+		zap.String("backend_id", backendID),
+		zap.Bool("replay_failed", run.Error != ""),
+		zap.String("accessed_by_api_key", callerAPIKeyID(r)),
+	)
+	h.writeJSON(w, http.StatusOK, archive.Compare(record, run))
+}
+
+// loadArchivedRecord reads a record, writing the error response on failure.
+func (h *Handler) loadArchivedRecord(w http.ResponseWriter, r *http.Request, orgID, requestID string) (*archive.Record, bool) {
+	record, err := h.archiver.Get(r.Context(), orgID, requestID)
+	if errors.Is(err, archive.ErrNotFound) {
+		h.writeError(w, r, fmt.Errorf("archived request not found"), api.ErrCodeRequestNotFound)
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Error("failed to read archived request",
+			zap.String("org_id", orgID),
+			zap.String("request_id", requestID),
+			zap.Error(err),
+		)
+		h.writeError(w, r, fmt.Errorf("failed to read archived request"), api.ErrCodeInternalError)
+		return nil, false
+	}
+	return record, true
+}
+
+// callerAPIKeyID returns the API key making an admin request, for access logs.
+func callerAPIKeyID(r *http.Request) string {
+	if authCtx, ok := r.Context().Value("auth_context").(*auth.AuthenticatedContext); ok {
+		return authCtx.APIKeyID
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+// Package admin provides HTTP handlers for in-process cache inspection.
+//
+// Purpose:
+//   These handlers list the router's caches with their sizes and hit rates
+//   and flush a single cache, so operators can recover from stale-cache
+//   incidents without restarting pods.
+//
+// Debugging Notes:
+//   - Counters are per pod and reset on restart; query every pod during an incident
+//   - Flushing the config cache reloads it from etcd and is refused while etcd is unreachable
+//   - invalidate-orgs drops only the entries of the listed orgs; user-org-service
+//     calls it on every pod when it suspends orgs
+//   - Every flush is logged with the caller's API key for access review
+//
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+)
+
+// Cache is an in-process cache that operators can inspect and flush.
+type Cache interface {
+	CacheStats() (entries int, hits, misses uint64)
+	FlushCache(ctx context.Context) error
+}
+
+// OrgInvalidator is a Cache that can drop the entries of specific orgs.
+type OrgInvalidator interface {
+	InvalidateOrgs(ctx context.Context, orgIDs []string) (int, error)
+}
+
+// InvalidateOrgsRequest lists the orgs whose cache entries to drop.
+type InvalidateOrgsRequest struct {
+	OrgIDs []string `json:"orgIds"`
+}
+
+// CacheStatus describes one cache in the /v1/admin/caches response.
+type CacheStatus struct {
+	Name    string  `json:"name"`
+	Entries int     `json:"entries"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
+// AddCache enables the cache endpoints for cache under name.
+func (h *Handler) AddCache(name string, cache Cache) {
+	if h.caches == nil {
+		h.caches = make(map[string]Cache)
+	}
+	h.caches[name] = cache
+}
+
+// RegisterCacheRoutes registers cache routes. It is a no-op unless AddCache
+// was called.
+func (h *Handler) RegisterCacheRoutes(r chi.Router) {
+	if len(h.caches) == 0 {
+		return
+	}
+	r.Get("/v1/admin/caches", h.ListCaches)
+	r.Post("/v1/admin/caches/{name}/flush", h.FlushCache)
+	r.Post("/v1/admin/caches/{name}/invalidate-orgs", h.InvalidateOrgs)
+}
+
+// ListCaches returns every cache's size and hit rate, sorted by name.
+func (h *Handler) ListCaches(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.caches))
+	for name := range h.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]CacheStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, cacheStatus(name, h.caches[name]))
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"caches": statuses})
+}
+
+// FlushCache empties one cache and returns its status afterwards.
+func (h *Handler) FlushCache(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	cache, ok := h.caches[name]
+	if !ok {
+		h.writeError(w, r, fmt.Errorf("unknown cache %q", name), api.ErrCodeNotFound)
+		return
+	}
+	if err := cache.FlushCache(r.Context()); err != nil {
+		h.logger.Error("failed to flush cache", zap.String("cache", name), zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to flush cache %q: %w", name, err), api.ErrCodeServiceUnavailable)
+		return
+	}
+
+	h.logger.Info("cache flushed",
+		zap.String("cache", name),
+		zap.String("flushed_by_api_key", callerAPIKeyID(r)),
+	)
+	h.writeJSON(w, http.StatusOK, cacheStatus(name, cache))
+}
+
+// InvalidateOrgs drops one cache's entries for the requested orgs and returns
+// how many were dropped.
+func (h *Handler) InvalidateOrgs(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	cache, ok := h.caches[name]
+	if !ok {
+		h.writeError(w, r, fmt.Errorf("unknown cache %q", name), api.ErrCodeNotFound)
+		return
+	}
+	invalidator, ok := cache.(OrgInvalidator)
+	if !ok {
+		h.writeError(w, r, fmt.Errorf("cache %q cannot be invalidated by org", name), api.ErrCodeInvalidRequest)
+		return
+	}
+	var req InvalidateOrgsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		return
+	}
+	if len(req.OrgIDs) == 0 {
+		h.writeError(w, r, fmt.Errorf("orgIds is required"), api.ErrCodeInvalidRequest)
+		return
+	}
+	n, err := invalidator.InvalidateOrgs(r.Context(), req.OrgIDs)
+	if err != nil {
+		h.logger.Error("failed to invalidate cache", zap.String("cache", name), zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to invalidate cache %q: %w", name, err), api.ErrCodeServiceUnavailable)
+		return
+	}
+
+	h.logger.Info("cache invalidated for orgs",
+		zap.String("cache", name),
+		zap.Strings("org_ids", req.OrgIDs),
+		zap.Int("entries", n),
+		zap.String("invalidated_by_api_key", callerAPIKeyID(r)),
+	)
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "invalidated": n})
+}
+
+// cacheStatus snapshots cache's counters.
+func cacheStatus(name string, cache Cache) CacheStatus {
+	entries, hits, misses := cache.CacheStats()
+	status := CacheStatus{Name: name, Entries: entries, Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		status.HitRate = float64(hits) / float64(total)
+	}
+	return status
+}
@@ -0,0 +1,77 @@
+// Package admin provides HTTP handlers for fault injection endpoints.
+//
+// Purpose:
+//   These handlers let operators list, replace, and clear the chaos faults
+//   injected into incoming requests and backend calls in dev environments.
+//
+// Debugging Notes:
+//   - Routes are only registered when CHAOS_ENABLED=true outside production
+//   - PUT replaces the whole fault set; DELETE clears it
+//
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/chaos"
+)
+
+// ChaosFaultsRequest replaces the active fault set.
+type ChaosFaultsRequest struct {
+	Faults []chaos.Fault `json:"faults"`
+}
+
+// SetChaosInjector enables the fault injection endpoints.
+func (h *Handler) SetChaosInjector(injector *chaos.Injector) {
+	h.chaos = injector
+}
+
+// RegisterChaosRoutes registers fault injection routes. It is a no-op unless
+// SetChaosInjector was called.
+func (h *Handler) RegisterChaosRoutes(r chi.Router) {
+	if h.chaos == nil {
+		return
+	}
+	r.Route("/v1/admin/chaos/faults", func(r chi.Router) {
+		r.Get("/", h.ListChaosFaults)
+		r.Put("/", h.ReplaceChaosFaults)
+		r.Delete("/", h.ClearChaosFaults)
+	})
+}
+
+// ListChaosFaults returns the active faults and how often each has fired.
+func (h *Handler) ListChaosFaults(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"faults": h.chaos.Faults(),
+	})
+}
+
+// ReplaceChaosFaults replaces the active fault set.
+func (h *Handler) ReplaceChaosFaults(w http.ResponseWriter, r *http.Request) {
+	var req ChaosFaultsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		return
+	}
+	if err := h.chaos.SetFaults(req.Faults); err != nil {
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+
+	h.logger.Warn("chaos faults replaced via admin API", zap.Int("count", len(req.Faults)))
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"faults": h.chaos.Faults(),
+	})
+}
+
+// ClearChaosFaults removes all faults.
+func (h *Handler) ClearChaosFaults(w http.ResponseWriter, r *http.Request) {
+	h.chaos.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
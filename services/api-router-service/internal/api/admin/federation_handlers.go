@@ -0,0 +1,149 @@
+// Package admin provides HTTP handlers for multi-cluster federation.
+//
+// Purpose:
+//   These handlers report per-region health and probe latency and let
+//   operators register or remove backends that live in other clusters.
+//
+// Debugging Notes:
+//   - Backends registered here are not persisted; FEDERATED_BACKENDS is
+//     re-applied on restart
+//   - Local backends (BACKEND_ENDPOINTS) cannot be replaced or removed
+//   - URIs outside the egress allowlist (EGRESS_*) are rejected with 403 and
+//     published as backend.egress_denied security events
+//
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/securityevents"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// SetFederation enables the federation endpoints.
+func (h *Handler) SetFederation(federation *routing.Federation) {
+	h.federation = federation
+}
+
+// SetSecurityEvents sets where rejected backend registrations are reported.
+func (h *Handler) SetSecurityEvents(publisher securityevents.Publisher) {
+	h.securityEvents = publisher
+}
+
+// RegisterFederationRoutes registers federation routes. It is a no-op unless
+// SetFederation was called.
+func (h *Handler) RegisterFederationRoutes(r chi.Router) {
+	if h.federation == nil {
+		return
+	}
+	r.Route("/v1/admin/federation", func(r chi.Router) {
+		r.Get("/", h.GetFederationStatus)
+		r.Post("/backends", h.RegisterFederatedBackend)
+		r.Delete("/backends/{backendID}", h.RemoveFederatedBackend)
+	})
+}
+
+// GetFederationStatus returns backends grouped by region with health and probe latency.
+func (h *Handler) GetFederationStatus(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.federation.Status())
+}
+
+// RegisterFederatedBackend registers or replaces a backend in another cluster.
+func (h *Handler) RegisterFederatedBackend(w http.ResponseWriter, r *http.Request) {
+	var spec config.FederatedBackendSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		return
+	}
+	backend := spec.Config()
+	if u, err := url.Parse(backend.URI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		h.writeError(w, r, fmt.Errorf("uri must be an absolute http(s) URL"), api.ErrCodeValidationError)
+		return
+	}
+	if err := h.backendRegistry.RegisterFederatedBackend(backend); err != nil {
+		var violation *config.EgressViolation
+		if errors.As(err, &violation) {
+			h.reportEgressViolation(r, violation)
+			h.writeError(w, r, err, api.ErrCodeForbidden)
+			return
+		}
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+
+	registered, err := h.backendRegistry.GetBackend(backend.ID)
+	if err != nil {
+		h.writeError(w, r, err, api.ErrCodeInternalError)
+		return
+	}
+	if h.healthMonitor != nil {
+		h.healthMonitor.RegisterBackend(registered.ID, &routing.BackendEndpoint{
+			ID:      registered.ID,
+			URI:      registered.URI,
+			Timeout:  registered.Timeout,
+			Protocol: registered.Protocol,
+		})
+	}
+
+	h.logger.Info("federated backend registered via admin API",
+		zap.String("backend_id", registered.ID),
+		zap.String("region", registered.Region),
+		zap.String("cluster", registered.Cluster),
+	)
+	h.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"backend_id": registered.ID,
+		"uri":        registered.URI,
+		"region":     registered.Region,
+		"cluster":    registered.Cluster,
+		"timeout_ms": registered.Timeout.Milliseconds(),
+	})
+}
+
+// RemoveFederatedBackend removes a federated backend from routing and health checks.
+func (h *Handler) RemoveFederatedBackend(w http.ResponseWriter, r *http.Request) {
+	backendID := chi.URLParam(r, "backendID")
+	if _, err := h.backendRegistry.GetBackend(backendID); err != nil {
+		h.writeError(w, r, err, api.ErrCodeNotFound)
+		return
+	}
+	if err := h.backendRegistry.RemoveFederatedBackend(backendID); err != nil {
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+	if h.healthMonitor != nil {
+		h.healthMonitor.UnregisterBackend(backendID)
+	}
+
+	h.logger.Info("federated backend removed via admin API", zap.String("backend_id", backendID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reportEgressViolation logs a registration rejected by the egress allowlist
+// and publishes it as a security event.
+func (h *Handler) reportEgressViolation(r *http.Request, violation *config.EgressViolation) {
+	actor := callerAPIKeyID(r)
+	h.logger.Error("federated backend rejected by egress allowlist",
+		zap.String("backend_id", violation.BackendID),
+		zap.String("uri", violation.URI),
+		zap.String("reason", violation.Reason),
+		zap.String("actor_api_key_id", actor),
+	)
+	telemetry.RecordEgressViolation(securityevents.SourceAdminAPI)
+	if h.securityEvents == nil {
+		return
+	}
+	event := securityevents.EgressDenied(violation.BackendID, violation.URI, violation.Reason, securityevents.SourceAdminAPI, actor)
+	if err := h.securityEvents.Publish(r.Context(), event); err != nil {
+		h.logger.Warn("failed to publish egress security event", zap.Error(err))
+	}
+}
@@ -0,0 +1,125 @@
+// Package admin provides HTTP handlers for maintenance mode endpoints.
+//
+// Purpose:
+//   These handlers let operators turn platform maintenance on and off and
+//   schedule or cancel maintenance windows.
+//
+// Debugging Notes:
+//   - PUT replaces the whole state; DELETE turns maintenance off and cancels
+//     all windows
+//   - Changes are written to the config service when config watch is enabled,
+//     so every replica picks them up
+//
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/maintenance"
+)
+
+// SetMaintenance enables the maintenance mode endpoints.
+func (h *Handler) SetMaintenance(controller *maintenance.Controller) {
+	h.maintenance = controller
+}
+
+// RegisterMaintenanceRoutes registers maintenance routes. It is a no-op unless
+// SetMaintenance was called.
+func (h *Handler) RegisterMaintenanceRoutes(r chi.Router) {
+	if h.maintenance == nil {
+		return
+	}
+	r.Route("/v1/admin/maintenance", func(r chi.Router) {
+		r.Get("/", h.GetMaintenance)
+		r.Put("/", h.ReplaceMaintenance)
+		r.Delete("/", h.ClearMaintenance)
+		r.Post("/windows", h.AddMaintenanceWindow)
+		r.Delete("/windows/{windowID}", h.RemoveMaintenanceWindow)
+	})
+}
+
+// GetMaintenance returns the maintenance state.
+func (h *Handler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.maintenance.State())
+}
+
+// ReplaceMaintenance replaces the maintenance state.
+func (h *Handler) ReplaceMaintenance(w http.ResponseWriter, r *http.Request) {
+	var state maintenance.State
+	if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		return
+	}
+	if err := h.maintenance.Set(r.Context(), state); err != nil {
+		h.writeMaintenanceError(w, r, err)
+		return
+	}
+
+	state = h.maintenance.State()
+	h.logger.Warn("maintenance state replaced via admin API",
+		zap.Bool("enabled", state.Enabled),
+		zap.Int("windows", len(state.Windows)),
+	)
+	h.writeJSON(w, http.StatusOK, state)
+}
+
+// ClearMaintenance turns maintenance off and cancels all windows.
+func (h *Handler) ClearMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := h.maintenance.Set(r.Context(), maintenance.State{}); err != nil {
+		h.writeMaintenanceError(w, r, err)
+		return
+	}
+	h.logger.Warn("maintenance cleared via admin API")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddMaintenanceWindow schedules a maintenance window.
+func (h *Handler) AddMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var window maintenance.Window
+	if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		return
+	}
+	window, err := h.maintenance.AddWindow(r.Context(), window)
+	if err != nil {
+		h.writeMaintenanceError(w, r, err)
+		return
+	}
+
+	h.logger.Warn("maintenance window scheduled via admin API",
+		zap.String("window_id", window.ID),
+		zap.Time("start", window.Start),
+		zap.Time("end", window.End),
+	)
+	h.writeJSON(w, http.StatusCreated, window)
+}
+
+// RemoveMaintenanceWindow cancels a maintenance window.
+func (h *Handler) RemoveMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	windowID := chi.URLParam(r, "windowID")
+	if err := h.maintenance.RemoveWindow(r.Context(), windowID); err != nil {
+		h.writeMaintenanceError(w, r, err)
+		return
+	}
+	h.logger.Warn("maintenance window cancelled via admin API", zap.String("window_id", windowID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMaintenanceError maps controller errors to error codes.
+func (h *Handler) writeMaintenanceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, maintenance.ErrInvalidState):
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+	case errors.Is(err, maintenance.ErrWindowNotFound):
+		h.writeError(w, r, err, api.ErrCodeNotFound)
+	default:
+		h.writeError(w, r, err, api.ErrCodeServiceUnavailable)
+	}
+}
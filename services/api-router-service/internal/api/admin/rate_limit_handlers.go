@@ -0,0 +1,69 @@
+// Package admin provides HTTP handlers for rate limit inspection.
+//
+// Purpose:
+//   These handlers show an org's current rate limit and concurrency buckets
+//   and let operators reset them, e.g. after raising a limit or when a
+//   client was throttled by mistake.
+//
+// Debugging Notes:
+//   - API key buckets are only included for keys named with ?apiKeyId=
+//   - While Redis is unavailable only this router's local buckets are shown and reset
+//   - Every reset is logged with the caller's API key for access review
+//
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+)
+
+// SetRateLimiter enables the rate limit endpoints.
+func (h *Handler) SetRateLimiter(rateLimiter *limiter.RateLimiter) {
+	h.rateLimiter = rateLimiter
+}
+
+// RegisterRateLimitRoutes registers rate limit routes. It is a no-op unless
+// SetRateLimiter was called.
+func (h *Handler) RegisterRateLimitRoutes(r chi.Router) {
+	if h.rateLimiter == nil {
+		return
+	}
+	r.Get("/v1/admin/rate-limits/{orgID}", h.GetRateLimits)
+	r.Delete("/v1/admin/rate-limits/{orgID}", h.ResetRateLimits)
+}
+
+// GetRateLimits returns the org's current buckets.
+func (h *Handler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	buckets, err := h.rateLimiter.InspectOrganization(r.Context(), orgID, r.URL.Query()["apiKeyId"])
+	if err != nil {
+		h.logger.Error("failed to inspect rate limits", zap.String("org_id", orgID), zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to read rate limit buckets"), api.ErrCodeServiceUnavailable)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, buckets)
+}
+
+// ResetRateLimits refills the org's buckets and frees its concurrency slots.
+func (h *Handler) ResetRateLimits(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	apiKeyIDs := r.URL.Query()["apiKeyId"]
+	if err := h.rateLimiter.ResetOrganization(r.Context(), orgID, apiKeyIDs); err != nil {
+		h.logger.Error("failed to reset rate limits", zap.String("org_id", orgID), zap.Error(err))
+		h.writeError(w, r, fmt.Errorf("failed to reset rate limit buckets"), api.ErrCodeServiceUnavailable)
+		return
+	}
+
+	h.logger.Info("rate limits reset",
+		zap.String("org_id", orgID),
+		zap.Strings("api_key_ids", apiKeyIDs),
+		zap.String("reset_by_api_key", callerAPIKeyID(r)),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
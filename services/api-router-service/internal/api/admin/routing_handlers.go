@@ -27,9 +27,15 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/abuse"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/chaos"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/maintenance"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/securityevents"
 )
 
 // Handler handles admin API requests.
@@ -41,6 +47,15 @@ type Handler struct {
 	backendRegistry *config.BackendRegistry
 	tracer         trace.Tracer
 	errorBuilder   *api.ErrorBuilder
+	chaos          *chaos.Injector
+	federation     *routing.Federation
+	archiver       *archive.Archiver
+	replayer       Replayer
+	rateLimiter    *limiter.RateLimiter
+	caches         map[string]Cache
+	maintenance    *maintenance.Controller
+	abuse          *abuse.Detector
+	securityEvents securityevents.Publisher
 }
 
 // NewHandler creates a new admin API handler.
@@ -69,6 +84,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Post("/backends/{backendID}/degrade", h.MarkBackendDegraded)
 		r.Post("/backends/{backendID}/healthy", h.MarkBackendHealthy)
 		r.Get("/backends/{backendID}/health", h.GetBackendHealth)
+		r.Get("/backends/{backendID}/probes", h.GetBackendProbes)
 		r.Get("/backends", h.ListBackends)
 		r.Get("/decisions", h.GetRoutingDecisions)
 		r.Post("/policies", h.UpdateRoutingPolicy)
@@ -142,6 +158,7 @@ func (h *Handler) MarkBackendHealthy(w http.ResponseWriter, r *http.Request) {
 				ID:   backendCfg.ID,
 				URI:  backendCfg.URI,
 				Timeout: backendCfg.Timeout,
+				Protocol: backendCfg.Protocol,
 			}
 			_ = h.healthMonitor.CheckBackendNow(backendID, endpoint)
 		}
@@ -190,6 +207,52 @@ func (h *Handler) GetBackendHealth(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, response)
 }
 
+// GetBackendProbes returns a backend's probe settings and recent probe results.
+func (h *Handler) GetBackendProbes(w http.ResponseWriter, r *http.Request) {
+	backendID := chi.URLParam(r, "backendID")
+	if h.healthMonitor == nil {
+		h.writeError(w, r, fmt.Errorf("health monitor not available"), api.ErrCodeServiceUnavailable)
+		return
+	}
+
+	history, exists := h.healthMonitor.ProbeHistory(backendID)
+	if !exists {
+		h.writeError(w, r, fmt.Errorf("backend not found"), api.ErrCodeNotFound)
+		return
+	}
+
+	results := make([]map[string]interface{}, 0, len(history))
+	for _, result := range history {
+		entry := map[string]interface{}{
+			"time":        result.Time,
+			"latency_ms":  result.Latency.Milliseconds(),
+			"http_status": result.HTTPStatus,
+			"ok":          result.Err == nil,
+		}
+		if result.InferenceLatency > 0 {
+			entry["inference_latency_ms"] = result.InferenceLatency.Milliseconds()
+		}
+		if result.Err != nil {
+			entry["error"] = result.Err.Error()
+		}
+		results = append(results, entry)
+	}
+
+	probe := h.healthMonitor.ProbeConfig(backendID)
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"backend_id": backendID,
+		"probe": map[string]interface{}{
+			"path":              probe.Path,
+			"expected_status":   probe.ExpectedStatus,
+			"latency_budget_ms": probe.LatencyBudget.Milliseconds(),
+			"interval_ms":       probe.Interval.Milliseconds(),
+			"timeout_ms":        probe.Timeout.Milliseconds(),
+			"inference_probe":   probe.InferencePrompt != "",
+		},
+		"results": results,
+	})
+}
+
 // ListBackends returns a list of all registered backends with their health status.
 func (h *Handler) ListBackends(w http.ResponseWriter, r *http.Request) {
 	backendIDs := h.backendRegistry.ListBackends()
@@ -206,6 +269,12 @@ func (h *Handler) ListBackends(w http.ResponseWriter, r *http.Request) {
 			"uri":        backendCfg.URI,
 			"timeout_ms": backendCfg.Timeout.Milliseconds(),
 		}
+		if backendCfg.Region != "" {
+			backendInfo["region"] = backendCfg.Region
+		}
+		if backendCfg.Cluster != "" {
+			backendInfo["cluster"] = backendCfg.Cluster
+		}
 
 		// Add health status if available
 		if h.healthMonitor != nil {
@@ -214,6 +283,11 @@ func (h *Handler) ListBackends(w http.ResponseWriter, r *http.Request) {
 				backendInfo["health_status"] = string(health.Status)
 				backendInfo["last_check"] = health.LastCheck
 				backendInfo["consecutive_errors"] = health.ConsecutiveErrors
+				if factor := h.healthMonitor.WarmupFactor(backendID); factor < 1 {
+					backendInfo["warming_up"] = true
+					backendInfo["warmup_factor"] = factor
+					backendInfo["recovered_at"] = health.RecoveredAt
+				}
 			} else {
 				backendInfo["health_status"] = "unknown"
 			}
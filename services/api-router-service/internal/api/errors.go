@@ -18,6 +18,8 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 // Error codes matching OpenAPI spec
@@ -28,15 +30,22 @@ const (
 	ErrCodeAuthInvalid     = "AUTH_INVALID"
 
 	// Authorization errors (403)
-	ErrCodeForbidden = "FORBIDDEN"
+	ErrCodeForbidden        = "FORBIDDEN"
+	ErrCodeModelNotEntitled = "MODEL_NOT_ENTITLED"
 
 	// Validation errors (400)
 	ErrCodeInvalidRequest = "INVALID_REQUEST"
 	ErrCodeMissingField   = "MISSING_FIELD"
 	ErrCodeValidationError = "VALIDATION_ERROR"
 
+	// Tool calling errors (400)
+	ErrCodeInvalidTools      = "INVALID_TOOLS"       // Tool/function definitions failed validation
+	ErrCodeToolLimitExceeded = "TOOL_LIMIT_EXCEEDED" // Tool definitions exceed the org's count or size limit
+
 	// Rate limiting (429)
-	ErrCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
+	ErrCodeRateLimitExceeded        = "RATE_LIMIT_EXCEEDED"
+	ErrCodeConcurrencyLimitExceeded = "CONCURRENCY_LIMIT_EXCEEDED"
+	ErrCodeStreamLimitExceeded      = "STREAM_LIMIT_EXCEEDED" // Too many concurrent streaming connections
 
 	// Budget/quota (402)
 	ErrCodeBudgetExceeded = "BUDGET_EXCEEDED"
@@ -46,9 +55,11 @@ const (
 	ErrCodeBackendUnavailable = "BACKEND_UNAVAILABLE"
 	ErrCodeBackendTimeout     = "BACKEND_TIMEOUT"
 	ErrCodeBackendError       = "BACKEND_ERROR"
+	ErrCodeSchemaViolation    = "SCHEMA_VIOLATION" // Output still violated response_format after repair retries
 
 	// Routing errors (500, 503)
 	ErrCodeNoBackendAvailable = "NO_BACKEND_AVAILABLE"
+	ErrCodeNoCompliantBackend = "NO_COMPLIANT_BACKEND" // No backend satisfies the org's data residency
 	ErrCodeRoutingError       = "ROUTING_ERROR"
 
 	// Not found (404)
@@ -58,19 +69,24 @@ const (
 	// Internal errors (500, 503)
 	ErrCodeInternalError      = "INTERNAL_ERROR"
 	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ErrCodeMaintenance        = "MAINTENANCE" // Platform maintenance mode is in effect
 )
 
 // ErrorResponse represents a standard error response matching OpenAPI spec.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code"`
-	TraceID string `json:"trace_id,omitempty"`
+	Error         string `json:"error"`
+	Code          string `json:"code"`
+	RequestID     string `json:"request_id,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	TraceID       string `json:"trace_id,omitempty"`
 }
 
 // LimitErrorResponse represents a limit error response with additional context.
 type LimitErrorResponse struct {
 	Error             string                 `json:"error"`
 	Code              string                 `json:"code"`
+	RequestID         string                 `json:"request_id,omitempty"`
+	CorrelationID     string                 `json:"correlation_id,omitempty"`
 	TraceID           string                 `json:"trace_id,omitempty"`
 	RetryAfterSeconds *int                   `json:"retry_after_seconds,omitempty"`
 	LimitContext      map[string]interface{} `json:"limit_context,omitempty"`
@@ -91,8 +107,10 @@ func NewErrorBuilder(tracer trace.Tracer) *ErrorBuilder {
 // BuildError creates an ErrorResponse from an error and code.
 func (b *ErrorBuilder) BuildError(ctx context.Context, err error, code string) *ErrorResponse {
 	response := &ErrorResponse{
-		Error: err.Error(),
-		Code:  code,
+		Error:         err.Error(),
+		Code:          code,
+		RequestID:     requestid.RequestID(ctx),
+		CorrelationID: requestid.CorrelationID(ctx),
 	}
 
 	// Add trace ID if available
@@ -111,6 +129,8 @@ func (b *ErrorBuilder) BuildLimitError(ctx context.Context, err error, code stri
 	response := &LimitErrorResponse{
 		Error:        err.Error(),
 		Code:         code,
+		RequestID:    requestid.RequestID(ctx),
+		CorrelationID: requestid.CorrelationID(ctx),
 		RetryAfterSeconds: retryAfter,
 		LimitContext: limitContext,
 	}
@@ -134,15 +154,16 @@ func GetHTTPStatus(code string) int {
 		return http.StatusUnauthorized
 
 	// Authorization errors
-	case ErrCodeForbidden:
+	case ErrCodeForbidden, ErrCodeModelNotEntitled:
 		return http.StatusForbidden
 
 	// Validation errors
-	case ErrCodeInvalidRequest, ErrCodeMissingField, ErrCodeValidationError:
+	case ErrCodeInvalidRequest, ErrCodeMissingField, ErrCodeValidationError,
+		ErrCodeInvalidTools, ErrCodeToolLimitExceeded:
 		return http.StatusBadRequest
 
 	// Rate limiting
-	case ErrCodeRateLimitExceeded:
+	case ErrCodeRateLimitExceeded, ErrCodeConcurrencyLimitExceeded, ErrCodeStreamLimitExceeded:
 		return http.StatusTooManyRequests
 
 	// Budget/quota
@@ -154,11 +175,11 @@ func GetHTTPStatus(code string) int {
 		return http.StatusServiceUnavailable
 	case ErrCodeBackendTimeout:
 		return http.StatusGatewayTimeout
-	case ErrCodeBackendError:
+	case ErrCodeBackendError, ErrCodeSchemaViolation:
 		return http.StatusBadGateway
 
 	// Routing errors
-	case ErrCodeNoBackendAvailable:
+	case ErrCodeNoBackendAvailable, ErrCodeNoCompliantBackend:
 		return http.StatusServiceUnavailable
 	case ErrCodeRoutingError:
 		return http.StatusInternalServerError
@@ -170,7 +191,7 @@ func GetHTTPStatus(code string) int {
 	// Internal errors
 	case ErrCodeInternalError:
 		return http.StatusInternalServerError
-	case ErrCodeServiceUnavailable:
+	case ErrCodeServiceUnavailable, ErrCodeMaintenance:
 		return http.StatusServiceUnavailable
 
 	default:
@@ -0,0 +1,94 @@
+// Package public provides the abuse throttling middleware.
+//
+// Purpose:
+//   This file feeds every authenticated request's outcome to the abuse
+//   detector and holds keys it flagged to the detector's stricter rate
+//   limit until the flag expires or an operator clears it.
+//
+// Debugging Notes:
+//   - Flagged keys draw from their own token bucket (rate_limit:key:abuse:<id>),
+//     on top of the normal org and key limits
+//   - Denials are audited with DecisionReason ABUSE_THROTTLED and logged
+//     with the flag's reason
+//
+package public
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/abuse"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/usage"
+)
+
+// abuseObserveTimeout bounds counting a request after it completed.
+const abuseObserveTimeout = 500 * time.Millisecond
+
+// AbuseMiddleware creates middleware that throttles keys flagged by detector
+// and reports each request's status to it. A nil detector disables it.
+func AbuseMiddleware(detector *abuse.Detector, rateLimiter *limiter.RateLimiter, auditLogger *usage.AuditLogger, logger *zap.Logger, tracer trace.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if detector == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authContext, ok := r.Context().Value(authContextKey).(*auth.AuthenticatedContext)
+			if !ok || authContext.APIKeyID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if flag := detector.Throttled(r.Context(), authContext.APIKeyID); flag != nil {
+				rps, burst := detector.ThrottleLimits()
+				result, err := rateLimiter.CheckAPIKeyWithAlgorithm(r.Context(), "abuse:"+authContext.APIKeyID, rps, burst, limiter.AlgorithmTokenBucket)
+				if err != nil {
+					logger.Warn("abuse throttle check failed, allowing request",
+						zap.String("api_key_id", authContext.APIKeyID),
+						zap.Error(err),
+					)
+				} else if !result.Allowed {
+					if auditLogger != nil {
+						auditLogger.LogDenial(usage.AuditEvent{
+							RequestID:      getRequestID(r),
+							OrganizationID: authContext.OrganizationID,
+							APIKeyID:       authContext.APIKeyID,
+							Model:          getModelFromRequest(r),
+							Action:         "REQUEST_DENIED",
+							DecisionReason: "ABUSE_THROTTLED",
+							LimitState:     "RATE_LIMITED",
+						})
+					}
+					logger.Debug("request denied by abuse throttle",
+						zap.String("api_key_id", authContext.APIKeyID),
+						zap.String("reason", flag.Reason),
+					)
+					telemetry.RecordAbuseThrottled()
+					telemetry.RecordRateLimitDenial("key_abuse")
+					writeRateLimitError(w, r, result, logger, api.NewErrorBuilder(tracer))
+					return
+				}
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			// The request context may already be canceled once a stream ends
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), abuseObserveTimeout)
+			detector.Observe(ctx, authContext.OrganizationID, authContext.APIKeyID, status)
+			cancel()
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Package public provides the structured HTTP access logger.
+//
+// Purpose:
+//   This file replaces chi's text access logger with a zap-based logger that
+//   samples high-volume successful requests, always logs failures, scrubs
+//   credentials from headers and query strings, and records the org/API key
+//   identifiers resolved by AuthContextMiddleware.
+//
+// Debugging Notes:
+//   - Successful (< 400) responses are logged 1 in SampleEvery; errors always
+//   - Auth identifiers are filled in by AuthContextMiddleware, which runs on the
+//     authenticated sub-router after this middleware, via a shared per-request entry
+//   - Request headers are only included for error responses
+//   - Requests of orgs with log export enabled go to the Exporter before
+//     sampling, so customers receive every request
+//
+package public
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ai-aas/shared-go/requestid"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/logexport"
+)
+
+const (
+	accessLogEntryKey contextKey = "access_log_entry"
+	redactedValue                = "[REDACTED]"
+)
+
+// sensitiveHeaders are never logged verbatim.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-api-key":           true,
+	"x-hmac-signature":    true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// sensitiveQueryParams are redacted from logged query strings.
+var sensitiveQueryParams = map[string]bool{
+	"api_key":      true,
+	"apikey":       true,
+	"key":          true,
+	"token":        true,
+	"access_token": true,
+	"signature":    true,
+}
+
+// AccessLogConfig configures AccessLogMiddleware.
+type AccessLogConfig struct {
+	Logger *zap.Logger
+	// SampleEvery logs one in every N successful responses. Values <= 1 log all.
+	SampleEvery int
+	// Exporter receives every authenticated request for orgs that export their logs; optional.
+	Exporter *logexport.Exporter
+}
+
+// accessLogEntry carries identifiers discovered further down the chain back
+// to the access logger.
+type accessLogEntry struct {
+	orgID    string
+	apiKeyID string
+	exporter *logexport.Exporter
+}
+
+// AccessLogMiddleware creates a structured access logging middleware.
+func AccessLogMiddleware(cfg AccessLogConfig) func(http.Handler) http.Handler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	var counter atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessLogEntry{exporter: cfg.Exporter}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), accessLogEntryKey, entry)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if cfg.Exporter != nil && entry.orgID != "" {
+				exportAccessLog(cfg.Exporter, r, entry, status, ww.BytesWritten(), start)
+			}
+			if status < http.StatusBadRequest && cfg.SampleEvery > 1 &&
+				counter.Add(1)%uint64(cfg.SampleEvery) != 1 {
+				return
+			}
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("query", ScrubQuery(r.URL.RawQuery)),
+				zap.Int("status", status),
+				zap.Int("bytes", ww.BytesWritten()),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("request_id", requestid.RequestID(r.Context())),
+				zap.String("correlation_id", requestid.CorrelationID(r.Context())),
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.String("user_agent", r.UserAgent()),
+			}
+			if entry.orgID != "" {
+				fields = append(fields, zap.String("org_id", entry.orgID))
+			}
+			if entry.apiKeyID != "" {
+				fields = append(fields, zap.String("api_key_id", entry.apiKeyID))
+			}
+
+			level := zapcore.InfoLevel
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = zapcore.ErrorLevel
+			case status >= http.StatusBadRequest:
+				level = zapcore.WarnLevel
+			}
+			if level > zapcore.InfoLevel {
+				fields = append(fields, zap.Any("headers", ScrubHeaders(r.Header)))
+			} else if cfg.SampleEvery > 1 {
+				fields = append(fields, zap.Int("sample_every", cfg.SampleEvery))
+			}
+
+			logger.Log(level, "http request", fields...)
+		})
+	}
+}
+
+// recordAccessLogAuth attaches authenticated identifiers to the access log entry.
+func recordAccessLogAuth(ctx context.Context, authCtx *auth.AuthenticatedContext) {
+	entry, ok := ctx.Value(accessLogEntryKey).(*accessLogEntry)
+	if !ok || authCtx == nil {
+		return
+	}
+	entry.orgID = authCtx.OrganizationID
+	entry.apiKeyID = authCtx.APIKeyID
+	if entry.exporter != nil {
+		// Refresh the policy before the request is handled so audit events
+		// raised further down the chain are exported too.
+		entry.exporter.SetPolicy(authCtx.OrganizationID, logExportPolicy(authCtx.LogExport))
+	}
+}
+
+// exportAccessLog queues the request for the org's log export.
+func exportAccessLog(exporter *logexport.Exporter, r *http.Request, entry *accessLogEntry, status, bytes int, start time.Time) {
+	exporter.Export(logexport.Entry{
+		Stream: logexport.StreamRequest,
+		Time:   start,
+		OrgID:  entry.orgID,
+		Fields: map[string]any{
+			"method":         r.Method,
+			"path":           r.URL.Path,
+			"query":          ScrubQuery(r.URL.RawQuery),
+			"status":         status,
+			"bytes":          bytes,
+			"duration_ms":    time.Since(start).Milliseconds(),
+			"request_id":     requestid.RequestID(r.Context()),
+			"correlation_id": requestid.CorrelationID(r.Context()),
+			"api_key_id":     entry.apiKeyID,
+			"remote_addr":    r.RemoteAddr,
+			"user_agent":     r.UserAgent(),
+		},
+	})
+}
+
+// ScrubHeaders returns a copy of h with credential-bearing headers redacted.
+func ScrubHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if sensitiveHeaders[strings.ToLower(name)] {
+			out[name] = redactedValue
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// ScrubQuery redacts credential-bearing query parameters, preserving order.
+func ScrubQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if sensitiveQueryParams[strings.ToLower(name)] {
+			params[i] = name + "=" + redactedValue
+		}
+	}
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,78 @@
+// Package public provides unit tests for the structured access logger.
+//
+// Purpose:
+//   These tests validate sampling of successful requests, unconditional
+//   logging of errors, and scrubbing of credentials from logged requests.
+//
+package public
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+func TestAccessLogSamplesSuccessAndAlwaysLogsErrors(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	status := http.StatusOK
+	handler := AccessLogMiddleware(AccessLogConfig{Logger: zap.New(core), SampleEvery: 5})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	}
+	if got := logs.Len(); got != 2 {
+		t.Fatalf("expected 2 sampled success logs, got %d", got)
+	}
+
+	status = http.StatusBadGateway
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	}
+	if got := logs.FilterField(zap.Int("status", http.StatusBadGateway)).Len(); got != 3 {
+		t.Fatalf("expected every error to be logged, got %d", got)
+	}
+}
+
+func TestAccessLogScrubsCredentialsAndRecordsAuth(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := AccessLogMiddleware(AccessLogConfig{Logger: zap.New(core)})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recordAccessLogAuth(r.Context(), &auth.AuthenticatedContext{OrganizationID: "org-1", APIKeyID: "key-1"})
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/inference?api_key=sk-secret&model=gpt", nil)
+	req.Header.Set("X-API-Key", "sk-secret")
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log entry, got %d", logs.Len())
+	}
+	fields := logs.All()[0].ContextMap()
+	if fields["query"] != "api_key=[REDACTED]&model=gpt" {
+		t.Fatalf("query not scrubbed: %v", fields["query"])
+	}
+	headers, ok := fields["headers"].(map[string]string)
+	if !ok {
+		t.Fatalf("expected headers on error log, got %T", fields["headers"])
+	}
+	if headers["X-Api-Key"] != redactedValue || headers["Authorization"] != redactedValue {
+		t.Fatalf("credentials not scrubbed: %v", headers)
+	}
+	if headers["Content-Type"] != "application/json" {
+		t.Fatalf("expected non-sensitive headers to be kept: %v", headers)
+	}
+	if fields["org_id"] != "org-1" || fields["api_key_id"] != "key-1" {
+		t.Fatalf("expected auth identifiers, got org=%v key=%v", fields["org_id"], fields["api_key_id"])
+	}
+}
@@ -0,0 +1,60 @@
+// Package public provides inference archival for the public API.
+//
+// Purpose:
+//   Handlers hand each successful request/response pair to the archiver when
+//   the caller's org has opted into archival; the archiver redacts, encrypts
+//   and writes it to object storage in the background.
+//
+package public
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+// SetArchiver enables inference archival for orgs that opt in.
+func (h *Handler) SetArchiver(archiver *archive.Archiver) {
+	h.archiver = archiver
+}
+
+// archiveExchange queues a request/response pair for archival. record carries
+// the request metadata; its org, key and payload fields are filled in here.
+// Failures are logged and never affect the inference response.
+func (h *Handler) archiveExchange(authCtx *auth.AuthenticatedContext, record archive.Record, request, response interface{}) {
+	if h.archiver == nil || authCtx.Archival == nil || !authCtx.Archival.Enabled {
+		return
+	}
+
+	requestID := record.RequestID
+	reqJSON, err := json.Marshal(request)
+	if err != nil {
+		h.logger.Warn("failed to encode request for archival", zap.String("request_id", requestID), zap.Error(err))
+		return
+	}
+	respJSON, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Warn("failed to encode response for archival", zap.String("request_id", requestID), zap.Error(err))
+		return
+	}
+
+	record.OrgID = authCtx.OrganizationID
+	record.APIKeyID = authCtx.APIKeyID
+	record.Request = reqJSON
+	record.Response = respJSON
+	policy := archive.Policy{
+		Retention: time.Duration(authCtx.Archival.RetentionDays) * 24 * time.Hour,
+		RedactPII: authCtx.Archival.RedactPII,
+	}
+	if err := h.archiver.Archive(record, policy); err != nil {
+		h.logger.Warn("inference archival skipped",
+			zap.String("org_id", authCtx.OrganizationID),
+			zap.String("request_id", requestID),
+			zap.Error(err),
+		)
+	}
+}
@@ -127,7 +127,7 @@ type AuditResponse struct {
 	RetryCount      int                    `json:"retry_count,omitempty"`
 	TraceID         string                 `json:"trace_id,omitempty"`
 	SpanID          string                 `json:"span_id,omitempty"`
-	Metadata        map[string]string     `json:"metadata,omitempty"`
+	Metadata        map[string]any        `json:"metadata,omitempty"`
 	Timestamp       time.Time              `json:"timestamp"`
 }
 
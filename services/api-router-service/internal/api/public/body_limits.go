@@ -0,0 +1,324 @@
+// Package public provides request body size limits and buffering.
+//
+// Purpose:
+//   Request bodies are capped per route prefix and per org plan tier. Small
+//   bodies are buffered so HMAC verification, model extraction and budget
+//   holds can read them; larger ones stream through to the handler unless
+//   the request is HMAC-signed, so long-context prompts do not have to fit
+//   in the buffer.
+//
+// Debugging Notes:
+//   - Rejections are 413 application/problem+json with the limit in bytes
+//   - The tier is only known after authentication, so BodyBufferMiddleware
+//     applies the loosest limit the route allows and BodyLimitMiddleware
+//     tightens it for the caller's tier
+//   - Streamed bodies are limited and held on the model, stream and
+//     max_tokens read from their buffered prefix; handlers reject them with
+//     400 if the decoded request asks for more (checkStreamedFields)
+//
+package public
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+// BodyLimits configures request body size limits and buffering.
+type BodyLimits struct {
+	BufferBytes int64            // Bodies up to this size are buffered in memory
+	MaxBytes    int64            // Limit when no route or tier limit applies
+	Routes      map[string]int64 // Path prefix -> limit; the longest prefix wins
+	Tiers       map[string]int64 // Org rate limit tier -> limit
+}
+
+// routeLimit returns the limit of the longest route prefix matching path.
+func (l BodyLimits) routeLimit(path string) (int64, bool) {
+	var limit int64
+	matched := -1
+	for prefix, prefixLimit := range l.Routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > matched {
+			limit, matched = prefixLimit, len(prefix)
+		}
+	}
+	return limit, matched >= 0
+}
+
+// preAuthLimit is the largest body any caller may send to path.
+func (l BodyLimits) preAuthLimit(path string) int64 {
+	if limit, ok := l.routeLimit(path); ok {
+		return limit
+	}
+	limit := l.MaxBytes
+	for _, tierLimit := range l.Tiers {
+		if tierLimit > limit {
+			limit = tierLimit
+		}
+	}
+	return limit
+}
+
+// Limit returns the body limit for path and tier: the tighter of the route
+// and tier limits, or MaxBytes when neither is configured.
+func (l BodyLimits) Limit(path, tier string) int64 {
+	routeLimit, hasRoute := l.routeLimit(path)
+	tierLimit, hasTier := l.Tiers[tier]
+	switch {
+	case hasRoute && hasTier:
+		return min(routeLimit, tierLimit)
+	case hasRoute:
+		return routeLimit
+	case hasTier:
+		return tierLimit
+	}
+	return l.MaxBytes
+}
+
+// BodyBufferMiddleware buffers request bodies up to maxSize and rejects larger ones.
+func BodyBufferMiddleware(maxSize int64) func(http.Handler) http.Handler {
+	return BodyBufferLimitsMiddleware(BodyLimits{BufferBytes: maxSize, MaxBytes: maxSize})
+}
+
+// BodyBufferLimitsMiddleware buffers the request body so it can be read multiple times.
+// This is needed for HMAC verification and model extraction in middleware.
+// Bodies above limits.BufferBytes stream through unless the request is HMAC-signed.
+func BodyBufferLimitsMiddleware(limits BodyLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Only buffer POST/PUT/PATCH requests with bodies
+			if r.Method != "POST" && r.Method != "PUT" && r.Method != "PATCH" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Body == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			maxSize := limits.preAuthLimit(r.URL.Path)
+			if r.ContentLength > maxSize {
+				writeBodyTooLarge(w, maxSize)
+				return
+			}
+
+			// HMAC verification needs the whole body
+			bufferSize := min(limits.BufferBytes, maxSize)
+			if r.Header.Get("X-HMAC-Signature") != "" {
+				bufferSize = maxSize
+			}
+
+			// Read the body, one byte past the buffer to detect larger bodies
+			body, err := io.ReadAll(io.LimitReader(r.Body, bufferSize+1))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeBodyTooLarge(w, tooLarge.Limit)
+				return
+			}
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+
+			if int64(len(body)) > bufferSize {
+				if bufferSize == maxSize {
+					writeBodyTooLarge(w, maxSize)
+					return
+				}
+				// Limits and holds go by the fields in the buffered prefix,
+				// sized as the whole body may be
+				fields := scanRequestFields(body[:bufferSize])
+				fields.Size = maxSize
+				if r.ContentLength > 0 {
+					fields.Size = r.ContentLength
+				}
+				fields.Prefix = bufferSize
+				ctx := context.WithValue(r.Context(), streamedFieldsKey, fields)
+				if fields.Model != "" {
+					ctx = context.WithValue(ctx, modelKey, fields.Model)
+				}
+
+				// Stream the rest; handlers see *http.MaxBytesError past the limit
+				r.Body = http.MaxBytesReader(w, readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}, maxSize)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			// Restore the body for downstream handlers
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Store buffered body in context for HMAC verification
+			ctx := context.WithValue(r.Context(), bufferedBodyKey, body)
+
+			// Try to extract model from body and store in context
+			var req struct {
+				Model string `json:"model"`
+			}
+			if err := json.Unmarshal(body, &req); err == nil && req.Model != "" {
+				ctx = context.WithValue(ctx, modelKey, req.Model)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BodyLimitMiddleware applies the caller's tier limit where it is tighter
+// than the one BodyBufferMiddleware enforced. It must run after
+// AuthContextMiddleware.
+func BodyLimitMiddleware(limits BodyLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authContext, ok := r.Context().Value(authContextKey).(*auth.AuthenticatedContext)
+			if !ok || r.Body == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tier := ""
+			if authContext.RateLimits != nil {
+				tier = authContext.RateLimits.Tier
+			}
+			maxSize := limits.Limit(r.URL.Path, tier)
+			if maxSize >= limits.preAuthLimit(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, buffered := r.Context().Value(bufferedBodyKey).([]byte)
+			if r.ContentLength > maxSize || (buffered && int64(len(body)) > maxSize) {
+				writeBodyTooLarge(w, maxSize)
+				return
+			}
+			if !buffered {
+				r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestFields are the request body fields rate limits and budget holds
+// depend on.
+type requestFields struct {
+	Model     string `json:"model"`
+	Stream    bool   `json:"stream"`
+	MaxTokens int    `json:"max_tokens"`
+	Size      int64  `json:"-"` // Body size in bytes, or its upper bound when streamed
+	Prefix    int64  `json:"-"` // Buffered prefix a streamed body's fields were read from
+}
+
+// requestFieldsFrom returns the fields of the buffered body, or those read
+// from the prefix of a streamed one.
+func requestFieldsFrom(r *http.Request) (requestFields, bool) {
+	if fields, ok := r.Context().Value(streamedFieldsKey).(requestFields); ok {
+		return fields, true
+	}
+	body, _ := r.Context().Value(bufferedBodyKey).([]byte)
+	if len(body) == 0 {
+		return requestFields{}, false
+	}
+	var fields requestFields
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return requestFields{}, false
+	}
+	fields.Size = int64(len(body))
+	return fields, true
+}
+
+// scanRequestFields reads model, stream and max_tokens from the top-level
+// keys of a possibly truncated JSON object, stopping at the first value that
+// does not fit. Keys match case-insensitively and the last one wins, as with
+// encoding/json.
+func scanRequestFields(prefix []byte) requestFields {
+	var fields requestFields
+	dec := json.NewDecoder(bytes.NewReader(prefix))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fields
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fields
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return fields
+		}
+		switch {
+		case strings.EqualFold(key, "model"):
+			_ = json.Unmarshal(value, &fields.Model)
+		case strings.EqualFold(key, "stream"):
+			_ = json.Unmarshal(value, &fields.Stream)
+		case strings.EqualFold(key, "max_tokens"):
+			_ = json.Unmarshal(value, &fields.MaxTokens)
+		}
+	}
+	return fields
+}
+
+// checkStreamedFields rejects a streamed body whose decoded fields ask for
+// more than its prefix did: another model, a stream, or more output tokens.
+// Limits and holds were applied from the prefix, so those fields must appear
+// in it.
+func checkStreamedFields(r *http.Request, decoded requestFields) error {
+	prefix, ok := r.Context().Value(streamedFieldsKey).(requestFields)
+	if !ok {
+		return nil
+	}
+	if decoded.Model != prefix.Model || (decoded.Stream && !prefix.Stream) || decoded.MaxTokens > prefix.MaxTokens {
+		return fmt.Errorf("model, stream and max_tokens must appear in the first %d bytes of a request body this large", prefix.Prefix)
+	}
+	return nil
+}
+
+// readCloser reads from a replayed prefix plus the rest of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// writeBodyTooLarge writes a 413 problem+json response naming the limit.
+func writeBodyTooLarge(w http.ResponseWriter, limit int64) {
+	writeProblemFields(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds the %d byte limit", limit),
+		map[string]interface{}{"limit": limit})
+}
+
+// writeProblem writes an application/problem+json response.
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	writeProblemFields(w, status, detail, nil)
+}
+
+// writeProblemFields writes an application/problem+json response with extension fields.
+func writeProblemFields(w http.ResponseWriter, status int, detail string, fields map[string]interface{}) {
+	problem := map[string]interface{}{
+		"status": status,
+		"title":  http.StatusText(status),
+		"detail": detail,
+	}
+	for k, v := range fields {
+		problem[k] = v
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// writeDecodeError reports a request body that failed to decode, as 413 when
+// a streamed body ran past its limit.
+func (h *Handler) writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeBodyTooLarge(w, tooLarge.Limit)
+		return
+	}
+	h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+}
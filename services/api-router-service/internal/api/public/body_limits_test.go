@@ -0,0 +1,185 @@
+// Package public provides unit tests for request body limits.
+//
+// Purpose:
+//   These tests validate route and tier limit resolution, buffering of small
+//   bodies, streaming of larger unsigned bodies, and 413 problem+json
+//   responses for oversized bodies.
+//
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+)
+
+var testBodyLimits = BodyLimits{
+	BufferBytes: 16,
+	MaxBytes:    64,
+	Routes:      map[string]int64{"/v1/": 128, "/v1/admin": 32},
+	Tiers:       map[string]int64{"free": 48, "enterprise": 256},
+}
+
+func TestBodyLimitsResolution(t *testing.T) {
+	cases := []struct {
+		path, tier string
+		want       int64
+	}{
+		{"/v1/chat/completions", "", 128},
+		{"/v1/chat/completions", "free", 48},
+		{"/v1/admin/caches", "enterprise", 32},
+		{"/other", "", 64},
+		{"/other", "enterprise", 256},
+	}
+	for _, c := range cases {
+		if got := testBodyLimits.Limit(c.path, c.tier); got != c.want {
+			t.Errorf("Limit(%q, %q) = %d, want %d", c.path, c.tier, got, c.want)
+		}
+	}
+	if got := testBodyLimits.preAuthLimit("/other"); got != 256 {
+		t.Errorf("preAuthLimit(/other) = %d, want the largest tier limit 256", got)
+	}
+}
+
+func TestBodyBufferLimitsMiddleware(t *testing.T) {
+	var gotBody string
+	var gotBuffered bool
+	handler := BodyBufferLimitsMiddleware(testBodyLimits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBodyTooLarge(w, err.(*http.MaxBytesError).Limit)
+			return
+		}
+		gotBody = string(body)
+		_, gotBuffered = r.Context().Value(bufferedBodyKey).([]byte)
+	}))
+	serve := func(body string, contentLength int64, signed bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.ContentLength = contentLength
+		if signed {
+			req.Header.Set("X-HMAC-Signature", "sig")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(`{"model":"m"}`, 13, false); rec.Code != http.StatusOK || gotBody != `{"model":"m"}` || !gotBuffered {
+		t.Fatalf("small body: code %d, body %q, buffered %v", rec.Code, gotBody, gotBuffered)
+	}
+
+	long := strings.Repeat("x", 100)
+	if rec := serve(long, -1, false); rec.Code != http.StatusOK || gotBody != long || gotBuffered {
+		t.Fatalf("streamed body: code %d, body length %d, buffered %v", rec.Code, len(gotBody), gotBuffered)
+	}
+	if rec := serve(long, -1, true); rec.Code != http.StatusOK || !gotBuffered {
+		t.Fatalf("signed body must be buffered: code %d, buffered %v", rec.Code, gotBuffered)
+	}
+
+	tooLong := strings.Repeat("x", 200)
+	for _, contentLength := range []int64{200, -1} {
+		rec := serve(tooLong, contentLength, false)
+		if rec.Code != http.StatusRequestEntityTooLarge || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("oversized body (content length %d): code %d, content type %q", contentLength, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		var problem struct {
+			Limit int64 `json:"limit"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil || problem.Limit != 128 {
+			t.Fatalf("problem limit = %d, %v; want 128", problem.Limit, err)
+		}
+	}
+}
+
+func TestBodyLimitMiddlewareAppliesTier(t *testing.T) {
+	handler := BodyBufferLimitsMiddleware(testBodyLimits)(BodyLimitMiddleware(testBodyLimits)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	serve := func(tier string, size int) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", size)))
+		authCtx := &auth.AuthenticatedContext{RateLimits: &auth.RateLimits{Tier: tier}}
+		req = req.WithContext(context.WithValue(req.Context(), authContextKey, authCtx))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("free", 60); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("free tier 60 bytes: code %d, want 413", code)
+	}
+	if code := serve("enterprise", 60); code != http.StatusOK {
+		t.Errorf("enterprise tier 60 bytes: code %d, want 200", code)
+	}
+}
+
+func TestScanRequestFields(t *testing.T) {
+	cases := []struct {
+		prefix string
+		want   requestFields
+	}{
+		{`{"model":"m","stream":true,"max_tokens":50,"messages":[`, requestFields{Model: "m", Stream: true, MaxTokens: 50}},
+		{`{"Model":"a","model":"b","STREAM":true}`, requestFields{Model: "b", Stream: true}},
+		{`{"messages":[{"role":"user","content":"xxxx`, requestFields{}},
+		{`{"model":"m","messages":"xxxx","stream":tr`, requestFields{Model: "m"}},
+		{`[{"model":"m"}]`, requestFields{}},
+	}
+	for _, c := range cases {
+		if got := scanRequestFields([]byte(c.prefix)); got != c.want {
+			t.Errorf("scanRequestFields(%s) = %+v, want %+v", c.prefix, got, c.want)
+		}
+	}
+}
+
+func TestStreamedBodyFields(t *testing.T) {
+	limits := BodyLimits{BufferBytes: 64, MaxBytes: 1024}
+	var req *http.Request
+	handler := BodyBufferLimitsMiddleware(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+	}))
+	serve := func(body string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+	}
+	padding := `"messages":[{"role":"user","content":"` + strings.Repeat("x", 200) + `"}]`
+
+	// Fields in the prefix apply to the stream limit and budget hold
+	serve(`{"model":"gpt-4o","stream":true,` + padding + `}`)
+	if !isStreamingRequest(req) {
+		t.Fatal("streamed body with stream in its prefix must count as streaming")
+	}
+	if _, ok := estimateHoldCost(req, limiter.HoldPolicy{DefaultOutputTokens: 100}); !ok {
+		t.Fatal("streamed body with stream in its prefix must be held")
+	}
+	if got := getModelFromRequest(req); got != "gpt-4o" {
+		t.Fatalf("model = %q, want gpt-4o", got)
+	}
+	if err := checkStreamedFields(req, requestFields{Model: "gpt-4o", Stream: true}); err != nil {
+		t.Fatalf("matching fields rejected: %v", err)
+	}
+
+	// Fields padded past the prefix are not seen, so the handler rejects them
+	serve(`{"model":"gpt-4o",` + padding + `,"stream":true,"max_tokens":4000}`)
+	if isStreamingRequest(req) {
+		t.Fatal("stream past the prefix must not be read")
+	}
+	for _, decoded := range []requestFields{
+		{Model: "gpt-4o", Stream: true},
+		{Model: "gpt-4o", MaxTokens: 4000},
+		{Model: "other"},
+	} {
+		if err := checkStreamedFields(req, decoded); err == nil {
+			t.Errorf("checkStreamedFields(%+v) accepted fields missing from the prefix", decoded)
+		}
+	}
+
+	// Buffered bodies are checked in full
+	serve(`{"model":"m","stream":true}`)
+	if err := checkStreamedFields(req, requestFields{Model: "other", Stream: true}); err != nil {
+		t.Fatalf("buffered body checked against a prefix: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package public provides request decompression and response compression.
+//
+// Purpose:
+//   Large embedding batches and long completions are mostly repetitive JSON.
+//   Clients may gzip or deflate request bodies (Content-Encoding) and receive
+//   compressed responses when they send Accept-Encoding.
+//
+// Debugging Notes:
+//   - Body size limits apply to the decompressed body, so a small compressed
+//     body that inflates past its route/tier limit is rejected with 413
+//   - HMAC signatures are verified over the decompressed body
+//   - Unsupported Content-Encoding values are rejected with 415
+//
+package public
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// compressibleContentTypes are the response types ResponseCompressionMiddleware compresses.
+var compressibleContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"text/plain",
+}
+
+// ResponseCompressionMiddleware compresses responses with gzip or deflate as
+// negotiated by Accept-Encoding. A level of 0 disables compression.
+func ResponseCompressionMiddleware(level int) func(http.Handler) http.Handler {
+	if level <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return middleware.Compress(level, compressibleContentTypes...)
+}
+
+// DecompressMiddleware transparently decompresses gzip and deflate request
+// bodies. It must run before BodyBufferLimitsMiddleware, which then enforces
+// limits on the decompressed size.
+func DecompressMiddleware(limits BodyLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if r.Body == nil || encoding == "" || encoding == "identity" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var decompressed io.ReadCloser
+			var err error
+			switch encoding {
+			case "gzip", "x-gzip":
+				decompressed, err = gzip.NewReader(r.Body)
+			case "deflate":
+				decompressed, err = zlib.NewReader(r.Body)
+			default:
+				w.Header().Set("Accept-Encoding", "gzip, deflate")
+				writeProblem(w, http.StatusUnsupportedMediaType, "unsupported Content-Encoding "+encoding)
+				return
+			}
+			if err != nil {
+				writeProblem(w, http.StatusBadRequest, "request body is not valid "+encoding+" data")
+				return
+			}
+
+			// Cap inflation here too, for handlers reached without BodyBufferMiddleware
+			r.Body = http.MaxBytesReader(w, readCloser{decompressed, r.Body}, limits.preAuthLimit(r.URL.Path))
+			r.ContentLength = -1
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
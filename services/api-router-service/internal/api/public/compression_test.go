@@ -0,0 +1,102 @@
+// Package public provides unit tests for request and response compression.
+//
+// Purpose:
+//   These tests validate gzip/deflate request decompression, limits applied
+//   to the decompressed size, and negotiated gzip responses.
+//
+package public
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressBody(t *testing.T, encoding, body string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser = gzip.NewWriter(&buf)
+	if encoding == "deflate" {
+		w = zlib.NewWriter(&buf)
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	return &buf
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	var gotBody string
+	handler := DecompressMiddleware(testBodyLimits)(BodyBufferLimitsMiddleware(testBodyLimits)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeBodyTooLarge(w, tooLarge.Limit)
+				return
+			}
+			gotBody = string(body)
+		})))
+	serve := func(encoding string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", body)
+		req.Header.Set("Content-Encoding", encoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if rec := serve(encoding, compressBody(t, encoding, `{"model":"m"}`)); rec.Code != http.StatusOK || gotBody != `{"model":"m"}` {
+			t.Fatalf("%s: code %d, body %q", encoding, rec.Code, gotBody)
+		}
+	}
+
+	// 1000 bytes compress to far less than the 128 byte route limit but inflate past it
+	if rec := serve("gzip", compressBody(t, "gzip", strings.Repeat("x", 1000))); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("inflated body: code %d, want 413", rec.Code)
+	}
+	if rec := serve("br", strings.NewReader("x")); rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("br: code %d, want 415", rec.Code)
+	}
+	if rec := serve("gzip", strings.NewReader("not gzip")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("corrupt gzip: code %d, want 400", rec.Code)
+	}
+}
+
+func TestResponseCompressionMiddleware(t *testing.T) {
+	payload := `{"text":"` + strings.Repeat("long completion ", 100) + `"}`
+	handler := ResponseCompressionMiddleware(5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(payload))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/completions", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	if body, _ := io.ReadAll(gz); string(body) != payload {
+		t.Fatal("decompressed response does not match")
+	}
+
+	rec = httptest.NewRecorder()
+	ResponseCompressionMiddleware(0)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/completions", nil))
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Fatal("responses must not be compressed without Accept-Encoding")
+	}
+}
@@ -0,0 +1,108 @@
+// Package public provides CORS handling for browser-based clients.
+//
+// Purpose:
+//   The dashboard and customer web apps call the router directly from the
+//   browser. CORS_ALLOWED_ORIGINS decides which origins get preflight and
+//   CORS response headers; an org can narrow that further (org "cors"
+//   setting in user-org-service) so its keys only work from its own sites.
+//
+// Debugging Notes:
+//   - Preflights are answered on the main router before authentication,
+//     since browsers send them without the API key
+//   - Without CORS_ALLOWED_ORIGINS, development allows any localhost port
+//     and other environments send no CORS headers
+//   - Rate limit, Retry-After and routing headers are exposed to scripts
+//   - A request whose Origin is outside its org's list gets 403
+//
+package public
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	sharedserver "github.com/ai-aas/shared-go/server"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+// errOriginNotAllowed is returned when the org does not allow the request's Origin.
+var errOriginNotAllowed = errors.New("origin not allowed for this organization")
+
+// corsExposedHeaders are the response headers browser scripts may read.
+var corsExposedHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"Retry-After",
+	"Warning",
+	"X-Routing-Backend",
+	"X-Routing-Decision",
+	"API-Version",
+	"Deprecation",
+	"Sunset",
+	"Link",
+}
+
+// corsAllowedHeaders are the request headers browser clients may send.
+var corsAllowedHeaders = []string{
+	"Content-Type",
+	"Authorization",
+	"X-API-Key",
+	"X-Correlation-ID",
+	"X-HMAC-Signature",
+	"Content-Encoding",
+}
+
+// NewCORS returns the router's CORS policy for environment. API keys travel
+// in headers, so credentials (cookies) are never allowed.
+func NewCORS(environment string, origins []string, maxAge time.Duration) sharedserver.CORS {
+	if len(origins) == 0 {
+		switch strings.ToLower(environment) {
+		case "development", "dev", "local":
+			origins = []string{"http://localhost:*", "https://localhost:*"}
+		}
+	}
+	return sharedserver.CORS{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: corsAllowedHeaders,
+		ExposedHeaders: corsExposedHeaders,
+		MaxAge:         maxAge,
+	}
+}
+
+// OrgCORSMiddleware rejects browser requests from origins outside the org's
+// allowed list. Requests without an Origin header (server-side clients) and
+// orgs without a list are unaffected. It must run after AuthContextMiddleware.
+func OrgCORSMiddleware(logger *zap.Logger, tracer trace.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			authContext, ok := r.Context().Value(authContextKey).(*auth.AuthenticatedContext)
+			if origin == "" || !ok || len(authContext.CORSOrigins) == 0 || sharedserver.MatchOrigin(authContext.CORSOrigins, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger.Warn("request denied by org CORS origins",
+				zap.String("origin", origin),
+				zap.String("organization_id", authContext.OrganizationID),
+				zap.String("api_key_id", authContext.APIKeyID))
+
+			// Browsers cannot read the body of a disallowed origin anyway
+			w.Header().Del("Access-Control-Allow-Origin")
+			w.Header().Del("Access-Control-Expose-Headers")
+			errorBuilder := api.NewErrorBuilder(tracer)
+			response := errorBuilder.BuildError(r.Context(), errOriginNotAllowed, api.ErrCodeForbidden)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(api.GetHTTPStatus(api.ErrCodeForbidden))
+			_ = json.NewEncoder(w).Encode(response)
+		})
+	}
+}
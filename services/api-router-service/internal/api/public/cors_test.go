@@ -0,0 +1,72 @@
+// Package public provides unit tests for CORS handling.
+//
+// Purpose:
+//   These tests validate the per-environment origin defaults, exposed rate
+//   limit headers, and rejection of origins outside the org's allowed list.
+//
+package public
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+func TestNewCORS(t *testing.T) {
+	dev := NewCORS("development", nil, time.Minute)
+	if !dev.Allows("http://localhost:5173") {
+		t.Fatal("expected development to allow localhost by default")
+	}
+	prod := NewCORS("production", nil, time.Minute)
+	if prod.Allows("http://localhost:5173") {
+		t.Fatal("expected production to allow no origins by default")
+	}
+	configured := NewCORS("production", []string{"https://*.example.com"}, time.Minute)
+	if !configured.Allows("https://app.example.com") || configured.AllowCredentials {
+		t.Fatal("expected configured origin allowed without credentials")
+	}
+	if !strings.Contains(strings.Join(configured.ExposedHeaders, ","), "X-RateLimit-Remaining") {
+		t.Fatal("expected rate limit headers to be exposed")
+	}
+}
+
+func TestOrgCORSMiddleware(t *testing.T) {
+	handler := OrgCORSMiddleware(zap.NewNop(), otel.Tracer("test"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	authCtx := &auth.AuthenticatedContext{OrganizationID: "org-1", CORSOrigins: []string{"https://app.customer.io"}}
+
+	cases := []struct {
+		name    string
+		origin  string
+		authCtx *auth.AuthenticatedContext
+		want    int
+	}{
+		{"allowed origin", "https://app.customer.io", authCtx, http.StatusOK},
+		{"other origin", "https://evil.com", authCtx, http.StatusForbidden},
+		{"no origin", "", authCtx, http.StatusOK},
+		{"org without list", "https://evil.com", &auth.AuthenticatedContext{OrganizationID: "org-2"}, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if c.origin != "" {
+				req.Header.Set("Origin", c.origin)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), authContextKey, c.authCtx))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != c.want {
+				t.Fatalf("expected %d, got %d", c.want, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package public provides transcoding of OpenAI requests for gRPC backends.
+//
+// Purpose:
+//   Backends with protocol "grpc" (Triton, KServe v2) have no OpenAI endpoint.
+//   /v1/completions and /v1/chat/completions requests for them are turned
+//   into a plain prompt for BackendClient and the text is wrapped back into
+//   an OpenAI response.
+//
+// Debugging Notes:
+//   - Chat messages are rendered as "role: content" lines ending in
+//     "assistant:"; use a model whose Triton config applies the chat template
+//     if that matters
+//   - Tool calling is not supported on gRPC backends
+//   - prompt_tokens is estimated at 4 bytes per token
+//
+package public
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+)
+
+// errGRPCTools is returned for tool-calling requests routed to a gRPC backend.
+var errGRPCTools = errors.New("tool calling is not supported by gRPC backends")
+
+// forwardOpenAIGRPC transcodes an OpenAI request for a gRPC backend.
+func (h *Handler) forwardOpenAIGRPC(ctx context.Context, backend *routing.BackendEndpoint, req interface{}, reqType string) (interface{}, *routing.RoutingDecision, error) {
+	var backendReq routing.BackendRequest
+	var model string
+	switch r := req.(type) {
+	case OpenAIChatCompletionRequest:
+		if len(r.Tools) > 0 || len(r.Functions) > 0 {
+			return nil, nil, errGRPCTools
+		}
+		model = r.Model
+		backendReq = routing.BackendRequest{
+			Prompt:      renderChatPrompt(r.Messages),
+			MaxTokens:   r.MaxTokens,
+			Temperature: r.Temperature,
+			Parameters:  r.Parameters,
+		}
+	case OpenAICompletionRequest:
+		model = r.Model
+		backendReq = routing.BackendRequest{
+			Prompt:      r.Prompt,
+			MaxTokens:   r.MaxTokens,
+			Temperature: r.Temperature,
+			Parameters:  r.Parameters,
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported request type %T for gRPC backend", req)
+	}
+
+	resp, err := h.backendClient.ForwardRequest(ctx, backend, &backendReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usage := OpenAIUsage{
+		PromptTokens:     (len(backendReq.Prompt) + 3) / 4,
+		CompletionTokens: resp.TokensUsed,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	created := time.Now().Unix()
+	finishReason := "stop"
+	if backendReq.MaxTokens > 0 && resp.TokensUsed >= backendReq.MaxTokens {
+		finishReason = "length"
+	}
+
+	var openAIResp interface{}
+	if reqType == "chat" {
+		openAIResp = OpenAIChatCompletionResponse{
+			ID:      "chatcmpl-" + uuid.NewString(),
+			Object:  "chat.completion",
+			Created: created,
+			Model:   model,
+			Choices: []OpenAIChoice{{
+				Message:      OpenAIMessage{Role: "assistant", Content: strings.TrimSpace(resp.Text)},
+				FinishReason: finishReason,
+			}},
+			Usage: usage,
+		}
+	} else {
+		openAIResp = OpenAICompletionResponse{
+			ID:      "cmpl-" + uuid.NewString(),
+			Object:  "text_completion",
+			Created: created,
+			Model:   model,
+			Choices: []OpenAICompletionChoice{{Text: resp.Text, FinishReason: finishReason}},
+			Usage:   usage,
+		}
+	}
+
+	decision := &routing.RoutingDecision{
+		BackendID:     backend.ID,
+		DecisionType:  "PRIMARY",
+		Reason:        "OpenAI request transcoded for gRPC backend",
+		Timestamp:     time.Now(),
+		AttemptNumber: 1,
+	}
+	return openAIResp, decision, nil
+}
+
+// renderChatPrompt flattens chat messages into a completion prompt.
+func renderChatPrompt(messages []OpenAIMessage) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		sb.WriteString(msg.Role)
+		sb.WriteString(": ")
+		sb.WriteString(msg.Content)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("assistant:")
+	return sb.String()
+}
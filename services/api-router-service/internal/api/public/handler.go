@@ -18,7 +18,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
@@ -38,7 +40,13 @@ type Handler struct {
 	tracer          trace.Tracer
 	errorBuilder    *api.ErrorBuilder
 	backendURIs     map[string]string // Map of backend ID to URI (for testing/configuration - overrides registry)
-	httpClient      *http.Client      // Shared HTTP client for OpenAI requests (PR#16 Issue#4)
+	archiver        *archive.Archiver // Inference archival; nil when disabled
+	toolLimits      auth.ToolLimits   // Defaults for orgs that set no tool limits
+	repairRetries   int               // Structured output repair attempts after a schema violation
+
+	// Model catalog (GET /v1/models)
+	modelCatalog  map[string]config.ModelMetadata // Capabilities from MODEL_CATALOG
+	healthMonitor *routing.HealthMonitor          // Backend health; nil reports "unknown"
 }
 
 // NewHandler creates a new public API handler.
@@ -65,10 +73,6 @@ func NewHandler(
 		tracer:          tracer,
 		errorBuilder:    api.NewErrorBuilder(tracer),
 		backendURIs:     make(map[string]string),
-		httpClient: &http.Client{
-			// Shared client without timeout - we'll use context for per-request timeouts (PR#16 Issue#4)
-			Timeout: 0,
-		},
 	}
 }
 
@@ -81,12 +85,17 @@ func (h *Handler) SetBackendURI(backendID, uri string) {
 	h.backendURIs[backendID] = uri
 }
 
+// StreamingPaths are the routes that may answer with server-sent events
+// (stream=true), exempt from the server's write timeout.
+var StreamingPaths = []string{"/v1/chat/completions", "/v1/completions"}
+
 // RegisterRoutes registers public API routes.
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/v1/inference", h.HandleInference)
 	// OpenAI-compatible endpoints
 	r.Post("/v1/chat/completions", h.HandleOpenAIChatCompletions)
 	r.Post("/v1/completions", h.HandleOpenAICompletions)
+	r.Get("/v1/models", h.HandleListModels)
 }
 
 // HandleInference handles POST /v1/inference requests.
@@ -112,7 +121,11 @@ func (h *Handler) HandleInference(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var req InferenceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		h.writeDecodeError(w, r, err)
+		return
+	}
+	if err := checkStreamedFields(r, requestFields{Model: req.Model}); err != nil {
+		h.writeError(w, r, err, api.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -122,6 +135,11 @@ func (h *Handler) HandleInference(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := authCtx.CheckModel(req.Model); err != nil {
+		h.writeError(w, r, err, api.ErrCodeModelNotEntitled)
+		return
+	}
+
 	// Get routing policy
 	policy, err := h.configLoader.GetPolicy(authCtx.OrganizationID, req.Model)
 	if err != nil {
@@ -133,6 +151,10 @@ func (h *Handler) HandleInference(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, r, fmt.Errorf("no routing policy configured"), api.ErrCodeRoutingError)
 		return
 	}
+	policy, ok = h.applyResidency(w, r, authCtx, policy)
+	if !ok {
+		return
+	}
 
 	// Prepare backend request
 	backendReq := &routing.BackendRequest{
@@ -239,9 +261,23 @@ func (h *Handler) HandleInference(w http.ResponseWriter, r *http.Request) {
 			response.Usage.LimitState,
 			span.SpanContext(),
 			routingDecision.AttemptNumber-1, // retry count
+			req.Payload,
+			response.Output,
 		)
 	}
 
+	if routingDecision != nil {
+		h.archiveExchange(authCtx, archive.Record{
+			RequestID:    req.RequestID,
+			Endpoint:     r.URL.Path,
+			Model:        req.Model,
+			BackendID:    routingDecision.BackendID,
+			LatencyMS:    int64(response.Usage.LatencyMS),
+			TokensInput:  response.Usage.TokensInput,
+			TokensOutput: response.Usage.TokensOutput,
+		}, req, response)
+	}
+
 	// Write response
 	if err := h.writeJSON(w, http.StatusOK, response); err != nil {
 		h.logger.Error("failed to write response", zap.Error(err))
@@ -290,7 +326,7 @@ func (h *Handler) fallbackRouting(
 
 // buildBackendEndpoint constructs a BackendEndpoint from a backend ID.
 func (h *Handler) buildBackendEndpoint(backendID, model string) *routing.BackendEndpoint {
-	var uri string
+	var uri, protocol string
 	var timeout time.Duration = 30 * time.Second
 
 	// Check test override first (for testing)
@@ -304,6 +340,7 @@ func (h *Handler) buildBackendEndpoint(backendID, model string) *routing.Backend
 	if uri == "" && h.backendRegistry != nil {
 		if backendCfg, err := h.backendRegistry.GetBackend(backendID); err == nil {
 			uri = backendCfg.URI
+			protocol = backendCfg.Protocol
 			if backendCfg.Timeout > 0 {
 				timeout = backendCfg.Timeout
 			}
@@ -323,7 +360,23 @@ func (h *Handler) buildBackendEndpoint(backendID, model string) *routing.Backend
 		URI:          uri,
 		ModelVariant: model,
 		Timeout:      timeout,
+		Protocol:     protocol,
+	}
+}
+
+// applyResidency narrows policy to the org's allowed backend regions. It writes
+// a NO_COMPLIANT_BACKEND error and returns false when no backend qualifies.
+func (h *Handler) applyResidency(w http.ResponseWriter, r *http.Request, authCtx *auth.AuthenticatedContext, policy *config.RoutingPolicy) (*config.RoutingPolicy, bool) {
+	compliant, err := h.backendRegistry.ApplyResidency(policy, authCtx.AllowedRegions)
+	if err != nil {
+		h.logger.Warn("no backend satisfies data residency",
+			zap.String("org_id", authCtx.OrganizationID),
+			zap.Strings("allowed_regions", authCtx.AllowedRegions),
+			zap.String("model", policy.Model))
+		h.writeError(w, r, err, api.ErrCodeNoCompliantBackend)
+		return nil, false
 	}
+	return compliant, true
 }
 
 // writeError writes an error response using the error catalog.
@@ -0,0 +1,37 @@
+// Package public provides per-org log export for the public API.
+//
+// Purpose:
+//   Authentication refreshes each org's export policy on the exporter, so
+//   audit events raised later in the chain are exported, and the access log
+//   middleware hands every request of an opted-in org to the exporter.
+//
+package public
+
+import (
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/logexport"
+)
+
+// logExportPolicy converts the org setting from key validation; nil when
+// export is off or has no destination.
+func logExportPolicy(p *auth.LogExportPolicy) *logexport.Policy {
+	if p == nil || !p.Enabled || p.Destination == nil {
+		return nil
+	}
+	d := p.Destination
+	return &logexport.Policy{
+		Destination: logexport.Destination{
+			Type:          d.Type,
+			Bucket:        d.Bucket,
+			Region:        d.Region,
+			Prefix:        d.Prefix,
+			RoleARN:       d.RoleARN,
+			ExternalID:    d.ExternalID,
+			URL:           d.URL,
+			SigningSecret: d.SigningSecret,
+		},
+		Streams:      p.Streams,
+		MinStatus:    p.MinStatus,
+		RedactFields: p.RedactFields,
+	}
+}
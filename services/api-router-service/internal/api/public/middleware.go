@@ -7,17 +7,18 @@
 package public
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"io"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
-	"github.com/go-chi/chi/v5/middleware"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/requestid"
+
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
@@ -33,8 +34,9 @@ const (
 )
 
 const (
-	bufferedBodyKey contextKey = "buffered_body"
-	modelKey        contextKey = "model"
+	bufferedBodyKey   contextKey = "buffered_body"
+	streamedFieldsKey contextKey = "streamed_fields"
+	modelKey          contextKey = "model"
 )
 
 // RateLimitMiddleware creates middleware for rate limiting.
@@ -55,13 +57,21 @@ func RateLimitMiddleware(rateLimiter *limiter.RateLimiter, auditLogger *usage.Au
 				return
 			}
 
+			// Orgs may select their own algorithm and concurrency caps
+			limits := authContext.RateLimits
+			if limits == nil {
+				limits = &auth.RateLimits{}
+			}
+			algorithm := limiter.Algorithm(limits.Algorithm)
+
 			// Check organization rate limit
-			orgResult, err := rateLimiter.CheckOrganization(r.Context(), authContext.OrganizationID)
+			orgResult, err := rateLimiter.CheckOrganizationWithAlgorithm(r.Context(), authContext.OrganizationID, algorithm)
 			if err != nil {
 				logger.Warn("rate limit check failed, allowing request",
 					zap.String("org_id", authContext.OrganizationID),
 					zap.Error(err),
 				)
+				telemetry.RecordRateLimitDecision(telemetry.RateLimitDisabled, limits.Tier)
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -81,18 +91,20 @@ func RateLimitMiddleware(rateLimiter *limiter.RateLimiter, auditLogger *usage.Au
 				}
 				// Record Prometheus metric
 				telemetry.RecordRateLimitDenial("org")
+				telemetry.RecordRateLimitDecision(telemetry.RateLimitThrottled, limits.Tier)
 				errorBuilder := api.NewErrorBuilder(tracer)
 				writeRateLimitError(w, r, orgResult, logger, errorBuilder)
 				return
 			}
 
 			// Check API key rate limit (use same limits as org for now)
-			keyResult, err := rateLimiter.CheckAPIKey(r.Context(), authContext.APIKeyID, 0, 0)
+			keyResult, err := rateLimiter.CheckAPIKeyWithAlgorithm(r.Context(), authContext.APIKeyID, 0, 0, algorithm)
 			if err != nil {
 				logger.Warn("API key rate limit check failed, allowing request",
 					zap.String("api_key_id", authContext.APIKeyID),
 					zap.Error(err),
 				)
+				telemetry.RecordRateLimitDecision(telemetry.RateLimitDisabled, limits.Tier)
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -112,15 +124,109 @@ func RateLimitMiddleware(rateLimiter *limiter.RateLimiter, auditLogger *usage.Au
 				}
 				// Record Prometheus metric
 				telemetry.RecordRateLimitDenial("key")
+				telemetry.RecordRateLimitDecision(telemetry.RateLimitThrottled, limits.Tier)
 				errorBuilder := api.NewErrorBuilder(tracer)
 				writeRateLimitError(w, r, keyResult, logger, errorBuilder)
 				return
 			}
 
-			// Add rate limit headers
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(orgResult.Limit))
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(orgResult.Remaining))
+			// Report whichever bucket is closer to exhaustion, warning when it
+			// crossed the soft threshold so clients can back off before a 429
+			result, limitType := orgResult, "org"
+			if keyResult.Limit > 0 && orgResult.Limit > 0 &&
+				keyResult.Remaining*orgResult.Limit < orgResult.Remaining*keyResult.Limit {
+				result, limitType = keyResult, "key"
+			}
+			setRateLimitHeaders(w, result)
+			if result.NearLimit {
+				w.Header().Set("Warning", rateLimitWarning(result, limitType))
+				telemetry.RecordRateLimitWarning(limitType)
+			}
+
+			// Hold concurrency slots until the request (including any stream) completes
+			for _, slot := range []struct {
+				scope, id string
+				limit     int
+			}{
+				{"org", authContext.OrganizationID, limits.MaxConcurrent},
+				{"key", authContext.APIKeyID, limits.MaxConcurrentPerKey},
+			} {
+				if slot.limit <= 0 {
+					continue
+				}
+				result, release, err := rateLimiter.AcquireConcurrency(r.Context(), slot.scope, slot.id, slot.limit)
+				if err != nil {
+					logger.Warn("concurrency check failed, allowing request",
+						zap.String("org_id", authContext.OrganizationID),
+						zap.Error(err),
+					)
+					continue
+				}
+				if !result.Allowed {
+					if auditLogger != nil {
+						auditLogger.LogDenial(usage.AuditEvent{
+							RequestID:      getRequestID(r),
+							OrganizationID: authContext.OrganizationID,
+							APIKeyID:       authContext.APIKeyID,
+							Model:          getModelFromRequest(r),
+							Action:         "REQUEST_DENIED",
+							DecisionReason: "CONCURRENCY_LIMIT_EXCEEDED",
+							LimitState:     "RATE_LIMITED",
+						})
+					}
+					telemetry.RecordRateLimitDenial(slot.scope + "_concurrency")
+					telemetry.RecordRateLimitDecision(telemetry.RateLimitThrottled, limits.Tier)
+					errorBuilder := api.NewErrorBuilder(tracer)
+					writeConcurrencyLimitError(w, r, result, api.ErrCodeConcurrencyLimitExceeded, "Too many concurrent requests", logger, errorBuilder)
+					return
+				}
+				defer release()
+			}
 
+			// Streams also hold heartbeated stream slots until they end
+			if isStreamingRequest(r) {
+				defaults := rateLimiter.StreamLimits()
+				for _, slot := range []struct {
+					scope, id string
+					limit     int
+				}{
+					{"org", authContext.OrganizationID, firstPositive(limits.MaxConcurrentStreams, defaults.MaxPerOrg)},
+					{"key", authContext.APIKeyID, firstPositive(limits.MaxConcurrentStreamsPerKey, defaults.MaxPerKey)},
+				} {
+					if slot.limit <= 0 {
+						continue
+					}
+					result, release, err := rateLimiter.AcquireStream(r.Context(), slot.scope, slot.id, slot.limit)
+					if err != nil {
+						logger.Warn("stream limit check failed, allowing request",
+							zap.String("org_id", authContext.OrganizationID),
+							zap.Error(err),
+						)
+						continue
+					}
+					if !result.Allowed {
+						if auditLogger != nil {
+							auditLogger.LogDenial(usage.AuditEvent{
+								RequestID:      getRequestID(r),
+								OrganizationID: authContext.OrganizationID,
+								APIKeyID:       authContext.APIKeyID,
+								Model:          getModelFromRequest(r),
+								Action:         "REQUEST_DENIED",
+								DecisionReason: "STREAM_LIMIT_EXCEEDED",
+								LimitState:     "RATE_LIMITED",
+							})
+						}
+						telemetry.RecordRateLimitDenial(slot.scope + "_streams")
+						telemetry.RecordRateLimitDecision(telemetry.RateLimitThrottled, limits.Tier)
+						errorBuilder := api.NewErrorBuilder(tracer)
+						writeConcurrencyLimitError(w, r, result, api.ErrCodeStreamLimitExceeded, "Too many concurrent streams", logger, errorBuilder)
+						return
+					}
+					defer release()
+				}
+			}
+
+			telemetry.RecordRateLimitDecision(telemetry.RateLimitAllowed, limits.Tier)
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -158,31 +264,38 @@ func BudgetMiddleware(budgetClient *limiter.BudgetClient, auditLogger *usage.Aud
 			}
 
 			if !budgetStatus.Allowed {
-				// Emit audit event
-				if auditLogger != nil {
-					decisionReason := "BUDGET_EXCEEDED"
-					if budgetStatus.QuotaType == "daily_quota" || budgetStatus.QuotaType == "monthly_quota" {
-						decisionReason = "QUOTA_EXCEEDED"
+				denyBudget(w, r, authContext, budgetStatus, auditLogger, logger, tracer)
+				return
+			}
+
+			// Long requests reserve their estimated cost until they complete
+			if holds := budgetClient.Holds(); holds != nil {
+				if amount, ok := estimateHoldCost(r, holds.Policy()); ok {
+					available := -1.0
+					if budgetStatus.Limit > 0 {
+						available = math.Max(0, budgetStatus.Limit-budgetStatus.CurrentUsage)
+					}
+					result, hold, err := holds.Place(r.Context(), authContext.OrganizationID, amount, available)
+					switch {
+					case err != nil:
+						logger.Warn("budget hold failed, allowing request",
+							zap.String("org_id", authContext.OrganizationID),
+							zap.Error(err),
+						)
+					case !result.Allowed:
+						denyBudget(w, r, authContext, &limiter.BudgetStatus{
+							CurrentUsage: budgetStatus.CurrentUsage + result.Held,
+							Limit:        budgetStatus.Limit,
+							QuotaType:    "budget",
+							Reason:       "Budget reserved by in-flight requests",
+						}, auditLogger, logger, tracer)
+						return
+					default:
+						// Settled with the actual cost when usage is emitted
+						defer hold.Release()
+						r = r.WithContext(limiter.WithBudgetHold(r.Context(), hold))
 					}
-					auditLogger.LogDenial(usage.AuditEvent{
-						RequestID:      getRequestID(r),
-						OrganizationID: authContext.OrganizationID,
-						APIKeyID:       authContext.APIKeyID,
-						Model:          getModelFromRequest(r),
-						Action:         "REQUEST_DENIED",
-						DecisionReason: decisionReason,
-						LimitState:     decisionReason,
-					})
-				}
-				// Record Prometheus metrics
-				if budgetStatus.QuotaType == "budget" {
-					telemetry.RecordBudgetDenial(budgetStatus.QuotaType)
-				} else {
-					telemetry.RecordQuotaDenial(budgetStatus.QuotaType)
 				}
-				errorBuilder := api.NewErrorBuilder(tracer)
-				writeBudgetError(w, r, budgetStatus, logger, errorBuilder)
-				return
 			}
 
 			next.ServeHTTP(w, r)
@@ -190,52 +303,69 @@ func BudgetMiddleware(budgetClient *limiter.BudgetClient, auditLogger *usage.Aud
 	}
 }
 
-// BodyBufferMiddleware buffers the request body so it can be read multiple times.
-// This is needed for HMAC verification and model extraction in middleware.
-func BodyBufferMiddleware(maxSize int64) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Only buffer POST/PUT/PATCH requests with bodies
-			if r.Method != "POST" && r.Method != "PUT" && r.Method != "PATCH" {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			if r.Body == nil {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			// Read the body
-			body, err := io.ReadAll(io.LimitReader(r.Body, maxSize))
-			if err != nil {
-				http.Error(w, "failed to read request body", http.StatusBadRequest)
-				return
-			}
-
-			// Check if body exceeds max size
-			if int64(len(body)) >= maxSize {
-				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
-				return
-			}
+// denyBudget audits, counts and writes a budget or quota denial.
+func denyBudget(w http.ResponseWriter, r *http.Request, authContext *auth.AuthenticatedContext, budgetStatus *limiter.BudgetStatus, auditLogger *usage.AuditLogger, logger *zap.Logger, tracer trace.Tracer) {
+	// Emit audit event
+	if auditLogger != nil {
+		decisionReason := "BUDGET_EXCEEDED"
+		if budgetStatus.QuotaType == "daily_quota" || budgetStatus.QuotaType == "monthly_quota" {
+			decisionReason = "QUOTA_EXCEEDED"
+		}
+		auditLogger.LogDenial(usage.AuditEvent{
+			RequestID:      getRequestID(r),
+			OrganizationID: authContext.OrganizationID,
+			APIKeyID:       authContext.APIKeyID,
+			Model:          getModelFromRequest(r),
+			Action:         "REQUEST_DENIED",
+			DecisionReason: decisionReason,
+			LimitState:     decisionReason,
+		})
+	}
+	// Record Prometheus metrics
+	if budgetStatus.QuotaType == "budget" {
+		telemetry.RecordBudgetDenial(budgetStatus.QuotaType)
+	} else {
+		telemetry.RecordQuotaDenial(budgetStatus.QuotaType)
+	}
+	errorBuilder := api.NewErrorBuilder(tracer)
+	writeBudgetError(w, r, budgetStatus, logger, errorBuilder)
+}
 
-			// Restore the body for downstream handlers
-			r.Body = io.NopCloser(bytes.NewReader(body))
+// isStreamingRequest reports whether the request body asks for stream=true.
+func isStreamingRequest(r *http.Request) bool {
+	fields, ok := requestFieldsFrom(r)
+	return ok && fields.Stream
+}
 
-			// Store buffered body in context for HMAC verification
-			ctx := context.WithValue(r.Context(), bufferedBodyKey, body)
+// firstPositive returns the first positive value, or 0.
+func firstPositive(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
 
-			// Try to extract model from body and store in context
-			var req struct {
-				Model string `json:"model"`
-			}
-			if err := json.Unmarshal(body, &req); err == nil && req.Model != "" {
-				ctx = context.WithValue(ctx, modelKey, req.Model)
-			}
+// estimateHoldCost estimates the cost of a request the hold policy covers:
+// streaming requests and requests allowing at least MinOutputTokens. Input
+// tokens are approximated from the body size (about 4 bytes per token) and
+// output tokens from max_tokens.
+func estimateHoldCost(r *http.Request, policy limiter.HoldPolicy) (float64, bool) {
+	req, ok := requestFieldsFrom(r)
+	if !ok || req.Model == "" {
+		return 0, false
+	}
+	if !req.Stream && (req.MaxTokens <= 0 || req.MaxTokens < policy.MinOutputTokens) {
+		return 0, false
+	}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+	outputTokens := req.MaxTokens
+	if outputTokens <= 0 {
+		outputTokens = policy.DefaultOutputTokens
 	}
+	pricing := usage.PricingFor(req.Model)
+	return float64(req.Size/4)/1000.0*pricing.InputPer1K + float64(outputTokens)/1000.0*pricing.OutputPer1K, true
 }
 
 // AuthContextMiddleware extracts auth context and adds it to request context.
@@ -260,6 +390,8 @@ func AuthContextMiddleware(authenticator *auth.Authenticator, logger *zap.Logger
 				zap.String("org_id", authCtx.OrganizationID),
 				zap.String("api_key_id", authCtx.APIKeyID))
 
+			recordAccessLogAuth(r.Context(), authCtx)
+
 			// Add auth context to request context
 			ctx := context.WithValue(r.Context(), authContextKey, authCtx)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -267,6 +399,79 @@ func AuthContextMiddleware(authenticator *auth.Authenticator, logger *zap.Logger
 	}
 }
 
+// NetworkRestrictionMiddleware enforces per-key CIDR allowlists and required
+// header values. It must run after AuthContextMiddleware and relies on
+// auth.RealIP having set r.RemoteAddr to the caller IP.
+func NetworkRestrictionMiddleware(auditLogger *usage.AuditLogger, logger *zap.Logger, tracer trace.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authContext, ok := r.Context().Value(authContextKey).(*auth.AuthenticatedContext)
+			if !ok || authContext.Restrictions == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reason, err := authContext.Restrictions.Check(r)
+			if err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			clientIP := ""
+			if ip := auth.ClientIP(r); ip != nil {
+				clientIP = ip.String()
+			}
+			logger.Warn("request denied by API key restrictions",
+				zap.String("reason", reason),
+				zap.String("api_key_id", authContext.APIKeyID),
+				zap.String("client_ip", clientIP))
+			if auditLogger != nil {
+				auditLogger.LogDenial(usage.AuditEvent{
+					RequestID:      getRequestID(r),
+					OrganizationID: authContext.OrganizationID,
+					APIKeyID:       authContext.APIKeyID,
+					Model:          getModelFromRequest(r),
+					Action:         "REQUEST_DENIED",
+					DecisionReason: reason,
+					LimitState:     "RESTRICTED",
+					ClientIP:       clientIP,
+				})
+			}
+			telemetry.RecordRestrictionDenial(reason)
+
+			errorBuilder := api.NewErrorBuilder(tracer)
+			response := errorBuilder.BuildError(r.Context(), err, api.ErrCodeForbidden)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(api.GetHTTPStatus(api.ErrCodeForbidden))
+			_ = json.NewEncoder(w).Encode(response)
+		})
+	}
+}
+
+// RequireScopeMiddleware rejects requests whose auth context lacks the given
+// scope. It must run after AuthContextMiddleware.
+func RequireScopeMiddleware(scope string, logger *zap.Logger, tracer trace.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authContext, ok := r.Context().Value(authContextKey).(*auth.AuthenticatedContext)
+			if !ok || !authContext.HasScope(scope) {
+				logger.Warn("request missing required scope",
+					zap.String("scope", scope),
+					zap.String("path", r.URL.Path),
+					zap.String("method", r.Method))
+				errorBuilder := api.NewErrorBuilder(tracer)
+				response := errorBuilder.BuildError(r.Context(), fmt.Errorf("scope %q required", scope), api.ErrCodeForbidden)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(api.GetHTTPStatus(api.ErrCodeForbidden))
+				_ = json.NewEncoder(w).Encode(response)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // writeRateLimitError writes a rate limit error response using the error catalog.
 func writeRateLimitError(w http.ResponseWriter, r *http.Request, result *limiter.CheckResult, logger *zap.Logger, errorBuilder *api.ErrorBuilder) {
 	retryAfterSeconds := int(result.RetryAfter.Seconds())
@@ -276,8 +481,7 @@ func writeRateLimitError(w http.ResponseWriter, r *http.Request, result *limiter
 
 	// Set Retry-After header
 	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
-	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
-	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	setRateLimitHeaders(w, result)
 
 	limitContext := map[string]interface{}{
 		"current_usage": result.Limit - result.Remaining,
@@ -300,6 +504,51 @@ func writeRateLimitError(w http.ResponseWriter, r *http.Request, result *limiter
 	}
 }
 
+// writeConcurrencyLimitError writes a 429 for a request over a request or
+// stream concurrency cap.
+func writeConcurrencyLimitError(w http.ResponseWriter, r *http.Request, result *limiter.ConcurrencyResult, code, message string, logger *zap.Logger, errorBuilder *api.ErrorBuilder) {
+	// Slots free up as soon as any in-flight request finishes
+	retryAfterSeconds := 1
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+
+	limitContext := map[string]interface{}{
+		"in_flight": result.InFlight,
+		"limit":     result.Limit,
+	}
+	response := errorBuilder.BuildLimitError(
+		r.Context(),
+		api.NewError(code, message),
+		code,
+		&retryAfterSeconds,
+		limitContext,
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(api.GetHTTPStatus(code))
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("failed to write concurrency limit error response", zap.Error(err))
+	}
+}
+
+// setRateLimitHeaders sets X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds until the bucket is full again).
+func setRateLimitHeaders(w http.ResponseWriter, result *limiter.CheckResult) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+}
+
+// rateLimitWarning formats an RFC 7234 Warning header value (code 199,
+// miscellaneous warning) for a bucket past the soft threshold.
+func rateLimitWarning(result *limiter.CheckResult, limitType string) string {
+	scope := "organization"
+	if limitType == "key" {
+		scope = "API key"
+	}
+	return fmt.Sprintf(`199 - "%s rate limit nearly exhausted: %d of %d requests remaining"`,
+		scope, result.Remaining, result.Limit)
+}
+
 // writeBudgetError writes a budget/quota error response using the error catalog.
 func writeBudgetError(w http.ResponseWriter, r *http.Request, status *limiter.BudgetStatus, logger *zap.Logger, errorBuilder *api.ErrorBuilder) {
 	errorCode := getBudgetErrorCode(status.QuotaType)
@@ -353,8 +602,8 @@ func getRequestID(r *http.Request) string {
 	if reqID := r.Header.Get("X-Request-ID"); reqID != "" {
 		return reqID
 	}
-	// Fallback to chi request ID middleware
-	if reqID := middleware.GetReqID(r.Context()); reqID != "" {
+	// Fallback to the shared request ID middleware
+	if reqID := requestid.RequestID(r.Context()); reqID != "" {
 		return reqID
 	}
 	return ""
@@ -364,7 +613,7 @@ func getRequestID(r *http.Request) string {
 // The model is extracted from the buffered request body by BodyBufferMiddleware.
 func getModelFromRequest(r *http.Request) string {
 	// Try to get from context (set by BodyBufferMiddleware after parsing request body)
-	if model := r.Context().Value("model"); model != nil {
+	if model := r.Context().Value(modelKey); model != nil {
 		if m, ok := model.(string); ok {
 			return m
 		}
@@ -0,0 +1,235 @@
+// Package public provides unit tests for rate limit response headers.
+//
+// Purpose:
+//   These tests validate the X-RateLimit-* headers, the soft limit Warning
+//   header, concurrency caps enforced by the rate limit middleware, and the
+//   decision metrics it records.
+//
+package public
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	setRateLimitHeaders(rec, &limiter.CheckResult{Limit: 200, Remaining: 150, ResetAfter: 1500 * time.Millisecond})
+
+	want := map[string]string{
+		"X-RateLimit-Limit":     "200",
+		"X-RateLimit-Remaining": "150",
+		"X-RateLimit-Reset":     "2",
+	}
+	for header, value := range want {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("%s: expected %q, got %q", header, value, got)
+		}
+	}
+}
+
+func TestRateLimitWarning(t *testing.T) {
+	warning := rateLimitWarning(&limiter.CheckResult{Limit: 200, Remaining: 10}, "key")
+	if !strings.HasPrefix(warning, `199 - "API key rate limit`) || !strings.Contains(warning, "10 of 200") {
+		t.Fatalf("unexpected warning %q", warning)
+	}
+}
+
+func TestRateLimitMiddlewareConcurrencyCap(t *testing.T) {
+	rateLimiter := limiter.NewRateLimiter(nil, zap.NewNop(), 100, 200)
+	authCtx := &auth.AuthenticatedContext{
+		OrganizationID: "org-1",
+		APIKeyID:       "key-1",
+		RateLimits:     &auth.RateLimits{MaxConcurrent: 1},
+	}
+
+	entered, finish := make(chan struct{}), make(chan struct{})
+	handler := RateLimitMiddleware(rateLimiter, nil, zap.NewNop(), noop.NewTracerProvider().Tracer("test"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			<-finish
+		}))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req = req.WithContext(context.WithValue(req.Context(), authContextKey, authCtx))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve()
+	}()
+	<-entered
+
+	if rec := serve(); rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "CONCURRENCY_LIMIT_EXCEEDED") {
+		t.Fatalf("expected concurrency 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	close(finish)
+	<-done
+
+	// The slot is released once the first request completes
+	entered, finish = make(chan struct{}), make(chan struct{})
+	close(finish)
+	if rec := serve(); rec.Code != http.StatusOK {
+		t.Fatalf("expected request after release to pass, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitMiddlewareStreamCap(t *testing.T) {
+	rateLimiter := limiter.NewRateLimiter(nil, zap.NewNop(), 100, 200)
+	rateLimiter.SetStreamLimits(limiter.StreamLimits{MaxPerKey: 5})
+	authCtx := &auth.AuthenticatedContext{
+		OrganizationID: "org-streams",
+		APIKeyID:       "key-streams",
+		RateLimits:     &auth.RateLimits{MaxConcurrentStreams: 1},
+	}
+
+	entered, finish := make(chan struct{}), make(chan struct{})
+	handler := RateLimitMiddleware(rateLimiter, nil, zap.NewNop(), noop.NewTracerProvider().Tracer("test"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isStreamingRequest(r) {
+				close(entered)
+				<-finish
+			}
+		}))
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		ctx := context.WithValue(req.Context(), authContextKey, authCtx)
+		req = req.WithContext(context.WithValue(ctx, bufferedBodyKey, []byte(body)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(`{"model":"m","stream":true}`)
+	}()
+	<-entered
+
+	if rec := serve(`{"model":"m","stream":true}`); rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "STREAM_LIMIT_EXCEEDED") {
+		t.Fatalf("expected stream limit 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// Non-streaming requests do not take stream slots
+	if rec := serve(`{"model":"m"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected non-streaming request to pass, got %d", rec.Code)
+	}
+	close(finish)
+	<-done
+
+	entered, finish = make(chan struct{}), make(chan struct{})
+	close(finish)
+	if rec := serve(`{"model":"m","stream":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected stream after release to pass, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitMiddlewareRecordsDecisionsByTier(t *testing.T) {
+	rateLimiter := limiter.NewRateLimiter(nil, zap.NewNop(), 1, 1)
+	authCtx := &auth.AuthenticatedContext{
+		OrganizationID: "org-tier",
+		APIKeyID:       "key-tier",
+		RateLimits:     &auth.RateLimits{Tier: "free"},
+	}
+	handler := RateLimitMiddleware(rateLimiter, nil, zap.NewNop(), noop.NewTracerProvider().Tracer("test"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	allowed := telemetry.RateLimitDecisionsTotal.WithLabelValues(telemetry.RateLimitAllowed, "free")
+	throttled := telemetry.RateLimitDecisionsTotal.WithLabelValues(telemetry.RateLimitThrottled, "free")
+	allowedBefore, throttledBefore := testutil.ToFloat64(allowed), testutil.ToFloat64(throttled)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req = req.WithContext(context.WithValue(req.Context(), authContextKey, authCtx))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := testutil.ToFloat64(allowed) - allowedBefore; got != 1 {
+		t.Errorf("expected 1 allowed decision for tier free, got %v", got)
+	}
+	if got := testutil.ToFloat64(throttled) - throttledBefore; got != 1 {
+		t.Errorf("expected 1 throttled decision for tier free, got %v", got)
+	}
+}
+
+func TestBudgetMiddlewareHoldsLongRequests(t *testing.T) {
+	budgetClient := limiter.NewBudgetClient("", time.Second, zap.NewNop()) // Stub: 10000 USD limit, nothing billed
+	budgetClient.SetHolds(limiter.NewBudgetHolds(nil, zap.NewNop()))
+	authCtx := &auth.AuthenticatedContext{OrganizationID: "org-1", APIKeyID: "key-1"}
+
+	entered, finish := make(chan struct{}), make(chan struct{})
+	handler := BudgetMiddleware(budgetClient, nil, zap.NewNop(), noop.NewTracerProvider().Tracer("test"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.BudgetHoldFromContext(r.Context()) == nil {
+				t.Error("expected a budget hold in the request context")
+			}
+			close(entered)
+			<-finish
+		}))
+	// 3B output tokens at the default 0.002 USD/1K is a 6000 USD estimate
+	serve := func() *httptest.ResponseRecorder {
+		body := []byte(`{"model":"m","stream":true,"max_tokens":3000000000}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		ctx := context.WithValue(req.Context(), authContextKey, authCtx)
+		req = req.WithContext(context.WithValue(ctx, bufferedBodyKey, body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve()
+	}()
+	<-entered
+
+	if rec := serve(); rec.Code == http.StatusOK || !strings.Contains(rec.Body.String(), "BUDGET_EXCEEDED") {
+		t.Fatalf("expected budget denial while the first hold is outstanding, got %d: %s", rec.Code, rec.Body.String())
+	}
+	close(finish)
+	<-done
+
+	// The hold is released once the first request completes without usage
+	entered, finish = make(chan struct{}), make(chan struct{})
+	close(finish)
+	if rec := serve(); rec.Code != http.StatusOK {
+		t.Fatalf("expected request after release to pass, got %d", rec.Code)
+	}
+}
+
+func TestEstimateHoldCost(t *testing.T) {
+	policy := limiter.HoldPolicy{MinOutputTokens: 2048, DefaultOutputTokens: 1000}
+	estimate := func(body string) (float64, bool) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req = req.WithContext(context.WithValue(req.Context(), bufferedBodyKey, []byte(body)))
+		return estimateHoldCost(req, policy)
+	}
+
+	if _, ok := estimate(`{"model":"m","max_tokens":100}`); ok {
+		t.Error("short non-streaming requests should not be held")
+	}
+	if _, ok := estimate(`{"model":"m","max_tokens":4096}`); !ok {
+		t.Error("requests allowing MinOutputTokens should be held")
+	}
+	cost, ok := estimate(`{"model":"m","stream":true}`)
+	if !ok || cost < 0.002 {
+		t.Errorf("expected streaming request held with the default output estimate, got %v, %v", cost, ok)
+	}
+}
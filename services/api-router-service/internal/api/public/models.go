@@ -0,0 +1,146 @@
+// Package public provides the model catalog endpoint.
+//
+// Purpose:
+//   GET /v1/models lists the models the caller can use, so clients no longer
+//   need hardcoded model lists. Each entry merges the routing policy, backend
+//   region labels and health, the pricing catalog, and MODEL_CATALOG metadata.
+//
+// Debugging Notes:
+//   - A model is listed when a routing policy for the org (or "*") exists, the
+//     org and API key are entitled to it, and a backend satisfies data residency
+//   - Policies are read from the local policy cache, so a router that has not
+//     loaded policies yet returns an empty list
+//   - Health is the best status across the model's backends ("unknown" when
+//     no backend has been probed)
+//
+package public
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/usage"
+)
+
+// ModelList is the GET /v1/models response, compatible with OpenAI's list format.
+type ModelList struct {
+	Object string       `json:"object"`
+	Data   []ModelEntry `json:"data"`
+}
+
+// ModelEntry describes one model in the catalog.
+type ModelEntry struct {
+	ID            string       `json:"id"`
+	Object        string       `json:"object"`
+	Created       int64        `json:"created"`
+	OwnedBy       string       `json:"owned_by"`
+	ContextWindow int          `json:"context_window,omitempty"`
+	Modalities    []string     `json:"modalities"`
+	Pricing       ModelPricing `json:"pricing"`
+	Regions       []string     `json:"regions,omitempty"`
+	Health        string       `json:"health"`
+}
+
+// ModelPricing is a model's price per 1K tokens.
+type ModelPricing struct {
+	Currency          string  `json:"currency"`
+	InputPer1KTokens  float64 `json:"input_per_1k_tokens"`
+	OutputPer1KTokens float64 `json:"output_per_1k_tokens"`
+}
+
+// SetModelCatalog sets the capability metadata and health source for GET /v1/models.
+func (h *Handler) SetModelCatalog(metadata map[string]config.ModelMetadata, healthMonitor *routing.HealthMonitor) {
+	h.modelCatalog = metadata
+	h.healthMonitor = healthMonitor
+}
+
+// HandleListModels handles GET /v1/models.
+func (h *Handler) HandleListModels(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "models.list")
+	defer span.End()
+
+	authCtx, ok := r.Context().Value("auth_context").(*auth.AuthenticatedContext)
+	if !ok {
+		h.writeError(w, r, fmt.Errorf("authentication required"), api.ErrCodeAuthInvalid)
+		return
+	}
+
+	policies, err := h.configLoader.ListPolicies(ctx, authCtx.OrganizationID)
+	if err != nil {
+		h.writeError(w, r, fmt.Errorf("list models: %w", err), api.ErrCodeInternalError)
+		return
+	}
+
+	list := ModelList{Object: "list", Data: make([]ModelEntry, 0, len(policies))}
+	for _, policy := range policies {
+		if !authCtx.ModelAllowed(policy.Model) {
+			continue
+		}
+		compliant, err := h.backendRegistry.ApplyResidency(policy, authCtx.AllowedRegions)
+		if err != nil || len(compliant.Backends) == 0 {
+			continue // Nothing the org may use serves this model
+		}
+		list.Data = append(list.Data, h.modelEntry(compliant))
+	}
+
+	_ = h.writeJSON(w, http.StatusOK, list)
+}
+
+// modelEntry builds the catalog entry for a residency-filtered policy.
+func (h *Handler) modelEntry(policy *config.RoutingPolicy) ModelEntry {
+	metadata := h.modelCatalog[policy.Model]
+	pricing := usage.PricingFor(policy.Model)
+	entry := ModelEntry{
+		ID:            policy.Model,
+		Object:        "model",
+		Created:       policy.UpdatedAt.Unix(),
+		OwnedBy:       metadata.OwnedBy,
+		ContextWindow: metadata.ContextWindow,
+		Modalities:    metadata.Modalities,
+		Pricing: ModelPricing{
+			Currency:          "USD",
+			InputPer1KTokens:  pricing.InputPer1K,
+			OutputPer1KTokens: pricing.OutputPer1K,
+		},
+		Health: string(routing.HealthStatusUnknown),
+	}
+	if entry.OwnedBy == "" {
+		entry.OwnedBy = "ai-aas"
+	}
+	if len(entry.Modalities) == 0 {
+		entry.Modalities = []string{"text"}
+	}
+	if policy.UpdatedAt.IsZero() {
+		entry.Created = 0
+	}
+
+	regions := make(map[string]bool)
+	rank := map[routing.HealthStatus]int{
+		routing.HealthStatusHealthy:   3,
+		routing.HealthStatusDegraded:  2,
+		routing.HealthStatusUnhealthy: 1,
+	}
+	best := 0
+	for _, backend := range policy.Backends {
+		if cfg, err := h.backendRegistry.GetBackend(backend.BackendID); err == nil && cfg.Region != "" {
+			regions[cfg.Region] = true
+		}
+		if h.healthMonitor == nil {
+			continue
+		}
+		if health, ok := h.healthMonitor.GetHealth(backend.BackendID); ok && rank[health.Status] > best {
+			best = rank[health.Status]
+			entry.Health = string(health.Status)
+		}
+	}
+	for region := range regions {
+		entry.Regions = append(entry.Regions, region)
+	}
+	sort.Strings(entry.Regions)
+	return entry
+}
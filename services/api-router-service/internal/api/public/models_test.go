@@ -0,0 +1,106 @@
+// Package public provides unit tests for the model catalog endpoint.
+//
+// Purpose:
+//   These tests validate that GET /v1/models merges policies, backend regions,
+//   pricing and capabilities, and filters by entitlements and data residency.
+//
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func newCatalogHandler(t *testing.T) *Handler {
+	t.Helper()
+	cache, err := config.NewCache(filepath.Join(t.TempDir(), "policies.db"))
+	if err != nil {
+		t.Fatalf("create cache: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+	for _, policy := range []*config.RoutingPolicy{
+		{OrganizationID: "*", Model: "gpt-4o", Backends: []config.BackendWeight{{BackendID: "us-1", Weight: 50}, {BackendID: "eu-1", Weight: 50}}},
+		{OrganizationID: "*", Model: "llama-3", Backends: []config.BackendWeight{{BackendID: "us-1", Weight: 100}}},
+	} {
+		if err := cache.StorePolicy(context.Background(), policy); err != nil {
+			t.Fatalf("store policy: %v", err)
+		}
+	}
+
+	h := &Handler{
+		logger:       zap.NewNop(),
+		tracer:       noop.NewTracerProvider().Tracer("test"),
+		configLoader: config.NewLoader("", false, cache, zap.NewNop()),
+		backendRegistry: config.NewBackendRegistry(&config.Config{
+			BackendEndpoints: "us-1:http://us-1:8000,eu-1:http://eu-1:8000",
+			BackendRegions:   "us-1=us-east-1,eu-1=eu-west-1",
+		}),
+	}
+	h.SetModelCatalog(map[string]config.ModelMetadata{
+		"gpt-4o": {ContextWindow: 128000, Modalities: []string{"text", "image"}, OwnedBy: "openai"},
+	}, nil)
+	return h
+}
+
+func listModels(t *testing.T, h *Handler, authCtx *auth.AuthenticatedContext) ModelList {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "auth_context", authCtx))
+	rec := httptest.NewRecorder()
+	h.HandleListModels(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list ModelList
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return list
+}
+
+func TestListModelsMergesCatalog(t *testing.T) {
+	list := listModels(t, newCatalogHandler(t), &auth.AuthenticatedContext{OrganizationID: "org-1"})
+	if list.Object != "list" || len(list.Data) != 2 {
+		t.Fatalf("expected 2 models, got %+v", list)
+	}
+
+	gpt := list.Data[0]
+	if gpt.ID != "gpt-4o" || gpt.OwnedBy != "openai" || gpt.ContextWindow != 128000 || len(gpt.Modalities) != 2 {
+		t.Fatalf("unexpected capabilities %+v", gpt)
+	}
+	if gpt.Pricing.InputPer1KTokens != 0.005 || gpt.Pricing.Currency != "USD" {
+		t.Fatalf("unexpected pricing %+v", gpt.Pricing)
+	}
+	if len(gpt.Regions) != 2 || gpt.Regions[0] != "eu-west-1" || gpt.Health != "unknown" {
+		t.Fatalf("unexpected regions/health %+v", gpt)
+	}
+
+	llama := list.Data[1]
+	if llama.OwnedBy != "ai-aas" || len(llama.Modalities) != 1 || llama.Modalities[0] != "text" {
+		t.Fatalf("expected defaults for uncatalogued model, got %+v", llama)
+	}
+}
+
+func TestListModelsFiltersByEntitlementsAndResidency(t *testing.T) {
+	h := newCatalogHandler(t)
+
+	list := listModels(t, h, &auth.AuthenticatedContext{OrganizationID: "org-1", OrgModels: &auth.ModelEntitlements{AllowedModels: []string{"llama-3"}}})
+	if len(list.Data) != 1 || list.Data[0].ID != "llama-3" {
+		t.Fatalf("expected only entitled model, got %+v", list.Data)
+	}
+
+	list = listModels(t, h, &auth.AuthenticatedContext{OrganizationID: "org-1", AllowedRegions: []string{"eu-west-1"}})
+	if len(list.Data) != 1 || list.Data[0].ID != "gpt-4o" || len(list.Data[0].Regions) != 1 {
+		t.Fatalf("expected only the EU-served model, got %+v", list.Data)
+	}
+}
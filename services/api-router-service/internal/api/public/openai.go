@@ -9,16 +9,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/archive"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
 )
 
@@ -30,12 +34,28 @@ type OpenAIChatCompletionRequest struct {
 	Temperature float64                `json:"temperature,omitempty"`
 	Stream      bool                   `json:"stream,omitempty"`
 	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+
+	// StreamOptions applies when Stream is set (see streaming.go)
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
+
+	// Tool calling; the legacy Functions/FunctionCall fields are normalized to Tools/ToolChoice
+	Tools        []OpenAITool     `json:"tools,omitempty"`
+	ToolChoice   json.RawMessage  `json:"tool_choice,omitempty"`
+	Functions    []OpenAIFunction `json:"functions,omitempty"`
+	FunctionCall json.RawMessage  `json:"function_call,omitempty"`
+
+	// Structured output; json_schema and json_object responses are validated by the router
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
 }
 
 // OpenAIMessage represents a message in an OpenAI chat conversation.
 type OpenAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role         string              `json:"role"`
+	Content      string              `json:"content"`
+	Name         string              `json:"name,omitempty"`
+	ToolCalls    []OpenAIToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID   string              `json:"tool_call_id,omitempty"`
+	FunctionCall *OpenAIFunctionCall `json:"function_call,omitempty"`
 }
 
 // OpenAIChatCompletionResponse represents an OpenAI chat completions API response.
@@ -70,6 +90,9 @@ type OpenAICompletionRequest struct {
 	Temperature float64                `json:"temperature,omitempty"`
 	Stream      bool                   `json:"stream,omitempty"`
 	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+
+	// StreamOptions applies when Stream is set (see streaming.go)
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
 }
 
 // OpenAICompletionResponse represents an OpenAI text completions API response.
@@ -112,7 +135,11 @@ func (h *Handler) HandleOpenAIChatCompletions(w http.ResponseWriter, r *http.Req
 	// Parse OpenAI request
 	var openAIReq OpenAIChatCompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&openAIReq); err != nil {
-		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		h.writeDecodeError(w, r, err)
+		return
+	}
+	if err := checkStreamedFields(r, requestFields{Model: openAIReq.Model, Stream: openAIReq.Stream, MaxTokens: openAIReq.MaxTokens}); err != nil {
+		h.writeError(w, r, err, api.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -125,6 +152,25 @@ func (h *Handler) HandleOpenAIChatCompletions(w http.ResponseWriter, r *http.Req
 		h.writeError(w, r, fmt.Errorf("messages array cannot be empty"), api.ErrCodeValidationError)
 		return
 	}
+	if err := normalizeTools(&openAIReq, h.effectiveToolLimits(authCtx)); err != nil {
+		var limitErr *ToolLimitError
+		if errors.As(err, &limitErr) {
+			h.writeError(w, r, err, api.ErrCodeToolLimitExceeded)
+		} else {
+			h.writeError(w, r, err, api.ErrCodeInvalidTools)
+		}
+		return
+	}
+	outputSchema, err := compileResponseFormat(openAIReq.ResponseFormat)
+	if err != nil {
+		h.writeError(w, r, err, api.ErrCodeValidationError)
+		return
+	}
+
+	if err := authCtx.CheckModel(openAIReq.Model); err != nil {
+		h.writeError(w, r, err, api.ErrCodeModelNotEntitled)
+		return
+	}
 
 	// Get routing policy
 	policy, err := h.configLoader.GetPolicy(authCtx.OrganizationID, openAIReq.Model)
@@ -136,6 +182,10 @@ func (h *Handler) HandleOpenAIChatCompletions(w http.ResponseWriter, r *http.Req
 		h.writeError(w, r, fmt.Errorf("no routing policy configured"), api.ErrCodeRoutingError)
 		return
 	}
+	policy, ok = h.applyResidency(w, r, authCtx, policy)
+	if !ok {
+		return
+	}
 
 	// Validate that at least one backend is configured (PR#16 Issue#1)
 	if len(policy.Backends) == 0 {
@@ -145,20 +195,32 @@ func (h *Handler) HandleOpenAIChatCompletions(w http.ResponseWriter, r *http.Req
 
 	// Forward OpenAI request directly to backend's OpenAI endpoint
 	backendEndpoint := h.buildBackendEndpointForOpenAI(policy.Backends[0].BackendID, openAIReq.Model, "/v1/chat/completions")
+
+	if openAIReq.Stream {
+		if outputSchema != nil {
+			h.writeError(w, r, fmt.Errorf("streaming is not supported with response_format %q", openAIReq.ResponseFormat.Type), api.ErrCodeValidationError)
+			return
+		}
+		var promptText strings.Builder
+		for _, msg := range openAIReq.Messages {
+			promptText.WriteString(msg.Content)
+		}
+		h.serveOpenAIStream(w, r.WithContext(ctx), span, authCtx, backendEndpoint, openAIReq.Model, openAIReq, openAIReq.Messages, promptText.String(), startTime)
+		return
+	}
 	
 	// Forward the OpenAI request as-is to the backend
-	openAIRespInterface, routingDecision, err := h.forwardOpenAIRequest(ctx, backendEndpoint, openAIReq, "chat")
+	openAIResp, routingDecision, err := h.forwardStructuredChatCompletion(ctx, backendEndpoint, openAIReq, outputSchema)
 	if err != nil {
+		var violationErr *SchemaViolationError
+		if errors.As(err, &violationErr) {
+			h.writeError(w, r, err, api.ErrCodeSchemaViolation)
+			return
+		}
 		h.writeError(w, r, fmt.Errorf("backend request failed: %w", err), api.ErrCodeBackendError)
 		return
 	}
 
-	openAIResp, ok := openAIRespInterface.(OpenAIChatCompletionResponse)
-	if !ok {
-		h.writeError(w, r, fmt.Errorf("invalid response type"), api.ErrCodeBackendError)
-		return
-	}
-
 	// Add routing headers
 	if routingDecision != nil {
 		w.Header().Set("X-Routing-Backend", routingDecision.BackendID)
@@ -182,9 +244,23 @@ func (h *Handler) HandleOpenAIChatCompletions(w http.ResponseWriter, r *http.Req
 			"WITHIN_LIMIT",
 			span.SpanContext(),
 			routingDecision.AttemptNumber-1,
+			openAIReq.Messages,
+			openAIResp.Choices,
 		)
 	}
 
+	if routingDecision != nil {
+		h.archiveExchange(authCtx, archive.Record{
+			RequestID:    openAIResp.ID,
+			Endpoint:     r.URL.Path,
+			Model:        openAIReq.Model,
+			BackendID:    routingDecision.BackendID,
+			LatencyMS:    time.Since(startTime).Milliseconds(),
+			TokensInput:  openAIResp.Usage.PromptTokens,
+			TokensOutput: openAIResp.Usage.CompletionTokens,
+		}, openAIReq, openAIResp)
+	}
+
 	// Write response
 	if err := h.writeJSON(w, http.StatusOK, openAIResp); err != nil {
 		h.logger.Error("failed to write OpenAI response", zap.Error(err))
@@ -214,7 +290,11 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 	// Parse OpenAI request
 	var openAIReq OpenAICompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&openAIReq); err != nil {
-		h.writeError(w, r, fmt.Errorf("invalid request body: %w", err), api.ErrCodeInvalidRequest)
+		h.writeDecodeError(w, r, err)
+		return
+	}
+	if err := checkStreamedFields(r, requestFields{Model: openAIReq.Model, Stream: openAIReq.Stream, MaxTokens: openAIReq.MaxTokens}); err != nil {
+		h.writeError(w, r, err, api.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -228,6 +308,11 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := authCtx.CheckModel(openAIReq.Model); err != nil {
+		h.writeError(w, r, err, api.ErrCodeModelNotEntitled)
+		return
+	}
+
 	// Get routing policy
 	policy, err := h.configLoader.GetPolicy(authCtx.OrganizationID, openAIReq.Model)
 	if err != nil {
@@ -238,6 +323,10 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 		h.writeError(w, r, fmt.Errorf("no routing policy configured"), api.ErrCodeRoutingError)
 		return
 	}
+	policy, ok = h.applyResidency(w, r, authCtx, policy)
+	if !ok {
+		return
+	}
 
 	// Validate that at least one backend is configured (PR#16 Issue#2)
 	if len(policy.Backends) == 0 {
@@ -247,6 +336,11 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 
 	// Forward OpenAI request directly to backend's OpenAI endpoint
 	backendEndpoint := h.buildBackendEndpointForOpenAI(policy.Backends[0].BackendID, openAIReq.Model, "/v1/completions")
+
+	if openAIReq.Stream {
+		h.serveOpenAIStream(w, r.WithContext(ctx), span, authCtx, backendEndpoint, openAIReq.Model, openAIReq, openAIReq.Prompt, openAIReq.Prompt, startTime)
+		return
+	}
 	
 	// Forward the OpenAI request as-is to the backend
 	openAIRespInterface, routingDecision, err := h.forwardOpenAIRequest(ctx, backendEndpoint, openAIReq, "completion")
@@ -284,9 +378,23 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 			"WITHIN_LIMIT",
 			span.SpanContext(),
 			routingDecision.AttemptNumber-1,
+			openAIReq.Prompt,
+			openAIResp.Choices,
 		)
 	}
 
+	if routingDecision != nil {
+		h.archiveExchange(authCtx, archive.Record{
+			RequestID:    openAIResp.ID,
+			Endpoint:     r.URL.Path,
+			Model:        openAIReq.Model,
+			BackendID:    routingDecision.BackendID,
+			LatencyMS:    time.Since(startTime).Milliseconds(),
+			TokensInput:  openAIResp.Usage.PromptTokens,
+			TokensOutput: openAIResp.Usage.CompletionTokens,
+		}, openAIReq, openAIResp)
+	}
+
 	// Write response
 	if err := h.writeJSON(w, http.StatusOK, openAIResp); err != nil {
 		h.logger.Error("failed to write OpenAI response", zap.Error(err))
@@ -297,6 +405,9 @@ func (h *Handler) HandleOpenAICompletions(w http.ResponseWriter, r *http.Request
 // Uses net/url for safe URL manipulation (PR#16 Issue#3)
 func (h *Handler) buildBackendEndpointForOpenAI(backendID, model, path string) *routing.BackendEndpoint {
 	baseEndpoint := h.buildBackendEndpoint(backendID, model)
+	if baseEndpoint.Protocol == config.ProtocolGRPC {
+		return baseEndpoint // The URI path names the model; requests are transcoded
+	}
 
 	// Parse the backend URI using net/url for safe manipulation
 	parsedURI, err := url.Parse(baseEndpoint.URI)
@@ -317,6 +428,10 @@ func (h *Handler) buildBackendEndpointForOpenAI(backendID, model, path string) *
 
 // forwardOpenAIRequest forwards an OpenAI-format request to the backend and returns OpenAI-format response
 func (h *Handler) forwardOpenAIRequest(ctx context.Context, backend *routing.BackendEndpoint, req interface{}, reqType string) (interface{}, *routing.RoutingDecision, error) {
+	if backend.Protocol == config.ProtocolGRPC {
+		return h.forwardOpenAIGRPC(ctx, backend, req, reqType)
+	}
+
 	// Marshal the OpenAI request
 	reqBody, err := json.Marshal(req)
 	if err != nil {
@@ -330,11 +445,11 @@ func (h *Handler) forwardOpenAIRequest(ctx context.Context, backend *routing.Bac
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	// Use shared HTTP client with context-based timeout (PR#16 Issue#4)
+	// Sent through the backend's pool with a context-based timeout (PR#16 Issue#4)
 	reqCtx, cancel := context.WithTimeout(ctx, backend.Timeout)
 	defer cancel()
 
-	resp, err := h.httpClient.Do(httpReq.WithContext(reqCtx))
+	resp, err := h.backendClient.Send(backend, httpReq.WithContext(reqCtx))
 	if err != nil {
 		return nil, nil, fmt.Errorf("backend request failed: %w", err)
 	}
@@ -39,7 +39,7 @@ type BuildMetadata struct {
 
 // StatusHandlers provides health and readiness endpoint handlers.
 type StatusHandlers struct {
-	redisClient    *redis.Client
+	redisClient    redis.UniversalClient
 	kafkaPublisher *usage.Publisher
 	configLoader   *config.Loader
 	backendRegistry *config.BackendRegistry
@@ -51,7 +51,7 @@ type StatusHandlers struct {
 
 // StatusHandlersConfig configures the status handlers.
 type StatusHandlersConfig struct {
-	RedisClient    *redis.Client
+	RedisClient    redis.UniversalClient
 	KafkaPublisher *usage.Publisher
 	ConfigLoader   *config.Loader
 	BackendRegistry *config.BackendRegistry
@@ -0,0 +1,296 @@
+// Package public provides streamed (stream=true) OpenAI responses.
+//
+// Purpose:
+//   Streamed chat and text completions are relayed to the client as the
+//   backend's server-sent events arrive, while the router measures time to
+//   first token and tokens/sec per backend. The upstream request shares the
+//   client's context, so a client that disconnects cancels the backend
+//   request at once instead of letting the model generate into the void.
+//
+// Debugging Notes:
+//   - stream_options.include_usage is always requested from the backend so
+//     usage records carry real token counts; the usage-only chunk is dropped
+//     unless the client asked for it too. Without it, each content chunk
+//     counts as one token
+//   - backend timeout bounds the wait for the first token; later tokens have
+//     no per-token deadline (the router's request timeout still applies)
+//   - Not supported with response_format json_schema/json_object (the
+//     output is validated as a whole) or on gRPC backends; streams are not
+//     archived for replay
+//   - Metrics: api_router_stream_time_to_first_token_seconds,
+//     api_router_stream_tokens_per_second, api_router_streams_total
+//
+package public
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// maxStreamLineBytes bounds one server-sent event line from a backend.
+const maxStreamLineBytes = 1 << 20
+
+// OpenAIStreamOptions are the stream_options of a streamed request.
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// streamChunk is the part of a streamed chat or text completion chunk the
+// router reads; the chunk itself is relayed unchanged.
+type streamChunk struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Text  string `json:"text"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *OpenAIUsage `json:"usage"`
+}
+
+// streamResult summarizes a relayed stream.
+type streamResult struct {
+	ID         string
+	Usage      OpenAIUsage
+	Text       string
+	Outcome    string
+	TTFT       time.Duration
+	Generation time.Duration
+}
+
+// errStreamUnsupported is returned before anything is written when a stream
+// cannot be served.
+var errStreamUnsupported = errors.New("streaming is not supported by gRPC backends")
+
+// serveOpenAIStream relays a streamed completion and emits its usage record.
+// req must have Stream set; prompt is recorded with the usage record.
+func (h *Handler) serveOpenAIStream(w http.ResponseWriter, r *http.Request, span trace.Span, authCtx *auth.AuthenticatedContext, backend *routing.BackendEndpoint, model string, req interface{}, prompt interface{}, promptText string, startTime time.Time) {
+	if backend.Protocol == config.ProtocolGRPC {
+		h.writeError(w, r, errStreamUnsupported, api.ErrCodeValidationError)
+		return
+	}
+	ctx := r.Context()
+
+	result, err := h.relayStream(w, r, backend, req)
+	if err != nil {
+		// Nothing was written yet
+		if ctx.Err() != nil {
+			telemetry.RecordStream(backend.ID, telemetry.StreamOutcomeClientDisconnected, 0, 0, 0)
+			return
+		}
+		telemetry.RecordStream(backend.ID, telemetry.StreamOutcomeBackendError, 0, 0, 0)
+		h.writeError(w, r, fmt.Errorf("backend request failed: %w", err), api.ErrCodeBackendError)
+		return
+	}
+	telemetry.RecordStream(backend.ID, result.Outcome, result.TTFT, result.Generation, result.Usage.CompletionTokens)
+	if result.Usage.PromptTokens == 0 {
+		result.Usage.PromptTokens = (len(promptText) + 3) / 4
+		result.Usage.TotalTokens = result.Usage.PromptTokens + result.Usage.CompletionTokens
+	}
+
+	h.logger.Info("stream completed",
+		zap.String("backend_id", backend.ID),
+		zap.String("outcome", result.Outcome),
+		zap.Duration("time_to_first_token", result.TTFT),
+		zap.Int("completion_tokens", result.Usage.CompletionTokens),
+	)
+
+	if h.usageHook != nil {
+		// Bill what was generated even if the client hung up
+		_ = h.usageHook.EmitUsage(
+			context.WithoutCancel(ctx),
+			authCtx,
+			result.ID,
+			model,
+			backend.ID,
+			"PRIMARY",
+			result.Usage.PromptTokens,
+			result.Usage.CompletionTokens,
+			int(time.Since(startTime).Milliseconds()),
+			"WITHIN_LIMIT",
+			span.SpanContext(),
+			0,
+			prompt,
+			result.Text,
+		)
+	}
+}
+
+// relayStream forwards req to backend and copies its events to w. It
+// returns an error only if nothing has been written to w.
+func (h *Handler) relayStream(w http.ResponseWriter, r *http.Request, backend *routing.BackendEndpoint, req interface{}) (*streamResult, error) {
+	clientWantsUsage, reqBody, err := streamRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancelled when the client disconnects, on a failed write, or when the
+	// first token does not arrive within the backend timeout
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	firstTokenTimer := time.AfterFunc(backend.Timeout, cancel)
+	defer firstTokenTimer.Stop()
+
+	upstream, err := http.NewRequestWithContext(ctx, http.MethodPost, backend.URI, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	upstream.Header.Set("Content-Type", "application/json")
+	upstream.Header.Set("Accept", "text/event-stream")
+
+	sentAt := time.Now()
+	resp, err := h.backendClient.Send(backend, upstream)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("backend returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("X-Routing-Backend", backend.ID)
+	w.Header().Set("X-Routing-Decision", "PRIMARY")
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	result := &streamResult{Outcome: telemetry.StreamOutcomeCompleted}
+	var text strings.Builder
+	var firstToken time.Time
+	var backendUsage *OpenAIUsage
+	tokens := 0
+
+	reader := bufio.NewReaderSize(resp.Body, 64*1024)
+	for {
+		line, readErr := readStreamLine(reader)
+		if len(line) > 0 {
+			forward := true
+			if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+				payload = bytes.TrimSpace(payload)
+				var chunk streamChunk
+				if !bytes.Equal(payload, []byte("[DONE]")) && json.Unmarshal(payload, &chunk) == nil {
+					if result.ID == "" {
+						result.ID = chunk.ID
+					}
+					for _, choice := range chunk.Choices {
+						content := choice.Delta.Content + choice.Text
+						if content == "" {
+							continue
+						}
+						if firstToken.IsZero() {
+							firstToken = time.Now()
+							result.TTFT = firstToken.Sub(sentAt)
+							firstTokenTimer.Stop()
+						}
+						tokens++
+						text.WriteString(content)
+					}
+					if chunk.Usage != nil {
+						backendUsage = chunk.Usage
+						forward = clientWantsUsage || len(chunk.Choices) > 0
+					}
+				}
+			}
+			if forward {
+				if _, err := w.Write(line); err != nil {
+					result.Outcome = telemetry.StreamOutcomeClientDisconnected
+					break
+				}
+				// Events end with a blank line
+				if len(bytes.TrimSpace(line)) == 0 {
+					if err := rc.Flush(); err != nil {
+						result.Outcome = telemetry.StreamOutcomeClientDisconnected
+						break
+					}
+				}
+			}
+		}
+		if readErr != nil {
+			switch {
+			case r.Context().Err() != nil:
+				result.Outcome = telemetry.StreamOutcomeClientDisconnected
+			case !errors.Is(readErr, io.EOF):
+				result.Outcome = telemetry.StreamOutcomeBackendError
+				h.logger.Warn("backend stream failed", zap.String("backend_id", backend.ID), zap.Error(readErr))
+			}
+			break
+		}
+	}
+	// Stop the backend now rather than when the handler returns
+	cancel()
+	_ = rc.Flush()
+
+	if !firstToken.IsZero() {
+		result.Generation = time.Since(firstToken)
+	}
+	result.Text = text.String()
+	if backendUsage != nil {
+		result.Usage = *backendUsage
+	} else {
+		result.Usage = OpenAIUsage{CompletionTokens: tokens, TotalTokens: tokens}
+	}
+	return result, nil
+}
+
+// streamRequestBody encodes req with stream_options.include_usage set, and
+// reports whether the client asked for the usage chunk itself.
+func streamRequestBody(req interface{}) (bool, []byte, error) {
+	var clientWantsUsage bool
+	switch r := req.(type) {
+	case OpenAIChatCompletionRequest:
+		clientWantsUsage = r.StreamOptions != nil && r.StreamOptions.IncludeUsage
+		r.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+		req = r
+	case OpenAICompletionRequest:
+		clientWantsUsage = r.StreamOptions != nil && r.StreamOptions.IncludeUsage
+		r.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+		req = r
+	default:
+		return false, nil, fmt.Errorf("unsupported stream request type %T", req)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, nil, fmt.Errorf("marshal OpenAI request: %w", err)
+	}
+	return clientWantsUsage, body, nil
+}
+
+// readStreamLine reads one line including its newline, failing on lines
+// longer than maxStreamLineBytes.
+func readStreamLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		line = append(line, fragment...)
+		if len(line) > maxStreamLineBytes {
+			return nil, fmt.Errorf("stream line exceeds %d bytes", maxStreamLineBytes)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return line, err
+	}
+}
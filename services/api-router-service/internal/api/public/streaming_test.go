@@ -0,0 +1,198 @@
+// Package public provides unit tests for streamed OpenAI responses.
+//
+// Purpose:
+//   These tests validate SSE relaying, token accounting, and that client
+//   disconnects cancel the upstream backend request.
+//
+package public
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// writeChunk writes one chat completion chunk event.
+func writeChunk(w http.ResponseWriter, content string) {
+	fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+	w.(http.Flusher).Flush()
+}
+
+// streamingRouter serves relayStream for backend and reports each result.
+func streamingRouter(t *testing.T, backend *routing.BackendEndpoint, req interface{}) (*httptest.Server, chan *streamResult) {
+	t.Helper()
+	h := &Handler{logger: zap.NewNop(), backendClient: routing.NewBackendClient(zap.NewNop(), 30*time.Second)}
+	results := make(chan *streamResult, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := h.relayStream(w, r, backend, req)
+		if err != nil {
+			t.Errorf("relayStream: %v", err)
+		}
+		results <- result
+	}))
+	t.Cleanup(srv.Close)
+	return srv, results
+}
+
+func TestRelayStream(t *testing.T) {
+	var upstream OpenAIChatCompletionRequest
+	backendSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&upstream)
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeChunk(w, "Hello")
+		writeChunk(w, ", world")
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"completion_tokens\":3,\"total_tokens\":10}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer backendSrv.Close()
+
+	backend := &routing.BackendEndpoint{ID: "backend-1", URI: backendSrv.URL, Timeout: time.Second}
+	req := OpenAIChatCompletionRequest{Model: "m", Stream: true, Messages: []OpenAIMessage{{Role: "user", Content: "hi"}}}
+	router, results := streamingRouter(t, backend, req)
+
+	resp, err := http.Post(router.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	var body strings.Builder
+	_, _ = bufio.NewReader(resp.Body).WriteTo(&body)
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	if upstream.StreamOptions == nil || !upstream.StreamOptions.IncludeUsage {
+		t.Fatal("backend must be asked for usage")
+	}
+	// The client did not ask for usage, so the usage-only chunk is dropped
+	if strings.Contains(body.String(), "usage") || !strings.Contains(body.String(), "[DONE]") {
+		t.Fatalf("unexpected body %q", body.String())
+	}
+
+	result := <-results
+	if result.Outcome != telemetry.StreamOutcomeCompleted || result.Text != "Hello, world" || result.ID != "chatcmpl-1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage.PromptTokens != 7 || result.Usage.CompletionTokens != 3 || result.TTFT <= 0 {
+		t.Fatalf("unexpected usage %+v, ttft %v", result.Usage, result.TTFT)
+	}
+}
+
+func TestRelayStreamCountsChunksWithoutUsage(t *testing.T) {
+	backendSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"text\":\"a\"}]}\n\ndata: {\"choices\":[{\"text\":\"b\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer backendSrv.Close()
+
+	backend := &routing.BackendEndpoint{ID: "backend-1", URI: backendSrv.URL, Timeout: time.Second}
+	router, results := streamingRouter(t, backend, OpenAICompletionRequest{Model: "m", Prompt: "p", Stream: true})
+	resp, err := http.Post(router.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if result := <-results; result.Usage.CompletionTokens != 2 || result.Text != "ab" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestRelayStreamClientDisconnectCancelsBackend(t *testing.T) {
+	cancelled := make(chan struct{})
+	backendSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		writeChunk(w, "Hello")
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer backendSrv.Close()
+
+	backend := &routing.BackendEndpoint{ID: "backend-1", URI: backendSrv.URL, Timeout: 10 * time.Second}
+	router, results := streamingRouter(t, backend, OpenAIChatCompletionRequest{Model: "m", Stream: true})
+
+	resp, err := http.Post(router.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if line, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil || !strings.Contains(line, "Hello") {
+		t.Fatalf("first chunk: %q, %v", line, err)
+	}
+	_ = resp.Body.Close()
+	http.DefaultTransport.(*http.Transport).CloseIdleConnections()
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("backend request was not cancelled after the client disconnected")
+	}
+	if result := <-results; result.Outcome != telemetry.StreamOutcomeClientDisconnected {
+		t.Fatalf("unexpected outcome %q", result.Outcome)
+	}
+}
+
+func TestRelayStreamFirstTokenTimeout(t *testing.T) {
+	backendSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer backendSrv.Close()
+
+	backend := &routing.BackendEndpoint{ID: "backend-1", URI: backendSrv.URL, Timeout: 100 * time.Millisecond}
+	router, results := streamingRouter(t, backend, OpenAIChatCompletionRequest{Model: "m", Stream: true})
+	resp, err := http.Post(router.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	select {
+	case result := <-results:
+		if result.Outcome != telemetry.StreamOutcomeBackendError {
+			t.Fatalf("unexpected outcome %q", result.Outcome)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream without a first token was not cut off")
+	}
+}
+
+func TestForwardOpenAIRequestPropagatesCancellation(t *testing.T) {
+	cancelled := make(chan struct{})
+	backendSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices a closed connection only once the body is read
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer backendSrv.Close()
+
+	h := &Handler{logger: zap.NewNop(), backendClient: routing.NewBackendClient(zap.NewNop(), 30*time.Second)}
+	backend := &routing.BackendEndpoint{ID: "backend-1", URI: backendSrv.URL, Timeout: 10 * time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, _, err := h.forwardOpenAIRequest(ctx, backend, OpenAIChatCompletionRequest{Model: "m"}, "chat"); err == nil {
+		t.Fatal("expected cancelled request to fail")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("backend request was not cancelled with its context")
+	}
+}
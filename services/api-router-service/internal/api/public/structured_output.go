@@ -0,0 +1,200 @@
+// Package public provides structured output (JSON mode) enforcement.
+//
+// Purpose:
+//   Clients may ask for response_format {"type":"json_schema"} (or
+//   "json_object"). The format is forwarded to the backend, and the router
+//   also checks the output against the schema itself. When the output does
+//   not match, it asks the backend again with a repair prompt, up to
+//   STRUCTURED_OUTPUT_MAX_RETRIES times.
+//
+// Debugging Notes:
+//   - A response that still violates the schema after the retries fails with
+//     SCHEMA_VIOLATION (502), and the error lists the violations
+//   - Markdown code fences around otherwise valid JSON are removed instead of retried
+//   - Token usage is summed across the original attempt and all repair attempts
+//   - Metrics: api_router_structured_output_total{model,outcome},
+//     api_router_schema_violations_total{model}
+//
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// maxReportedViolations bounds how many schema errors go into a repair prompt or error.
+const maxReportedViolations = 10
+
+// OpenAIResponseFormat is the response_format of a chat completions request.
+type OpenAIResponseFormat struct {
+	Type       string                  `json:"type"` // text, json_object or json_schema
+	JSONSchema *OpenAIJSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// OpenAIJSONSchemaFormat names the schema the output must match.
+type OpenAIJSONSchemaFormat struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+	Strict      *bool           `json:"strict,omitempty"`
+}
+
+// SchemaViolationError reports output that never matched the requested schema.
+type SchemaViolationError struct {
+	Attempts   int
+	Violations []string
+}
+
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf("backend output did not match response_format after %d attempt(s): %s",
+		e.Attempts, strings.Join(e.Violations, "; "))
+}
+
+// SetStructuredOutputRetries sets how many repair attempts are made when
+// output violates the requested schema.
+func (h *Handler) SetStructuredOutputRetries(retries int) {
+	h.repairRetries = retries
+}
+
+// compileResponseFormat returns the schema the output must match, or nil when
+// no structured output was requested.
+func compileResponseFormat(format *OpenAIResponseFormat) (*gojsonschema.Schema, error) {
+	if format == nil {
+		return nil, nil
+	}
+	switch format.Type {
+	case "", "text":
+		return nil, nil
+	case "json_object":
+		return gojsonschema.NewSchema(gojsonschema.NewStringLoader(`{"type":"object"}`))
+	case "json_schema":
+	default:
+		return nil, fmt.Errorf("response_format.type: must be \"text\", \"json_object\" or \"json_schema\", got %q", format.Type)
+	}
+
+	if format.JSONSchema == nil {
+		return nil, fmt.Errorf("response_format.json_schema: is required when type is \"json_schema\"")
+	}
+	if !toolNamePattern.MatchString(format.JSONSchema.Name) {
+		return nil, fmt.Errorf("response_format.json_schema.name: %q must be 1-64 characters of a-z, A-Z, 0-9, _ or -", format.JSONSchema.Name)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(format.JSONSchema.Schema, &schema); err != nil || schema == nil {
+		return nil, fmt.Errorf("response_format.json_schema.schema: must be a JSON Schema object")
+	}
+	if refs := externalRefs(schema, ""); len(refs) > 0 {
+		return nil, fmt.Errorf("response_format.json_schema.schema%s", strings.Join(refs, "; "))
+	}
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(format.JSONSchema.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("response_format.json_schema.schema: invalid JSON Schema: %w", err)
+	}
+	return compiled, nil
+}
+
+// forwardStructuredChatCompletion forwards req and, when schema is set,
+// retries with a repair prompt until every choice matches it.
+func (h *Handler) forwardStructuredChatCompletion(ctx context.Context, endpoint *routing.BackendEndpoint, req OpenAIChatCompletionRequest, schema *gojsonschema.Schema) (OpenAIChatCompletionResponse, *routing.RoutingDecision, error) {
+	resp, decision, err := h.forwardChatCompletion(ctx, endpoint, req)
+	if err != nil || schema == nil {
+		return resp, decision, err
+	}
+
+	usage := resp.Usage
+	for attempt := 1; ; attempt++ {
+		content, violations := checkStructuredOutput(&resp, schema)
+		if len(violations) == 0 {
+			outcome := "valid"
+			if attempt > 1 {
+				outcome = "repaired"
+			}
+			telemetry.RecordStructuredOutput(req.Model, outcome)
+			resp.Usage = usage
+			return resp, decision, nil
+		}
+
+		telemetry.RecordSchemaViolation(req.Model)
+		if attempt > h.repairRetries {
+			telemetry.RecordStructuredOutput(req.Model, "failed")
+			return resp, decision, &SchemaViolationError{Attempts: attempt, Violations: violations}
+		}
+
+		// Show the model its own output and what was wrong with it
+		repair := req
+		repair.Messages = append(append([]OpenAIMessage(nil), req.Messages...),
+			OpenAIMessage{Role: "assistant", Content: content},
+			OpenAIMessage{Role: "user", Content: repairPrompt(violations)},
+		)
+		resp, decision, err = h.forwardChatCompletion(ctx, endpoint, repair)
+		if err != nil {
+			return resp, decision, err
+		}
+		usage.PromptTokens += resp.Usage.PromptTokens
+		usage.CompletionTokens += resp.Usage.CompletionTokens
+		usage.TotalTokens += resp.Usage.TotalTokens
+	}
+}
+
+// checkStructuredOutput validates every choice that has text content against
+// schema. It strips code fences in place and returns the first offending
+// content with its violations.
+func checkStructuredOutput(resp *OpenAIChatCompletionResponse, schema *gojsonschema.Schema) (string, []string) {
+	if len(resp.Choices) == 0 {
+		return "", []string{"response has no choices"}
+	}
+	for i := range resp.Choices {
+		msg := &resp.Choices[i].Message
+		if len(msg.ToolCalls) > 0 && strings.TrimSpace(msg.Content) == "" {
+			continue // The model chose to call a tool instead of answering
+		}
+		content := stripCodeFence(msg.Content)
+		if !json.Valid([]byte(content)) {
+			return msg.Content, []string{"output is not valid JSON"}
+		}
+
+		result, err := schema.Validate(gojsonschema.NewStringLoader(content))
+		if err != nil {
+			return msg.Content, []string{"output could not be validated: " + err.Error()}
+		}
+		if !result.Valid() {
+			var violations []string
+			for _, resultErr := range result.Errors() {
+				if len(violations) == maxReportedViolations {
+					violations = append(violations, fmt.Sprintf("and %d more", len(result.Errors())-maxReportedViolations))
+					break
+				}
+				violations = append(violations, resultErr.String())
+			}
+			return msg.Content, violations
+		}
+		msg.Content = content
+	}
+	return "", nil
+}
+
+// stripCodeFence removes a surrounding ```json ... ``` block, which models often
+// add even when asked for bare JSON.
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return trimmed
+	}
+	inner := trimmed[3 : len(trimmed)-3]
+	if newline := strings.IndexByte(inner, '\n'); newline >= 0 && !strings.ContainsAny(inner[:newline], "{[") {
+		inner = inner[newline+1:] // Drop the language tag
+	}
+	return strings.TrimSpace(inner)
+}
+
+func repairPrompt(violations []string) string {
+	return "Your previous response did not match the required JSON schema:\n- " +
+		strings.Join(violations, "\n- ") +
+		"\nRespond again with only a JSON value that matches the schema, without any other text."
+}
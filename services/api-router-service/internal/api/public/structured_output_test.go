@@ -0,0 +1,134 @@
+// Package public provides unit tests for structured output enforcement.
+//
+// Purpose:
+//   These tests validate response_format checks, code fence cleanup, and
+//   repair retries against a fake OpenAI-compatible backend.
+//
+package public
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+)
+
+const personSchema = `{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"required":["name","age"]}`
+
+func personFormat() *OpenAIResponseFormat {
+	return &OpenAIResponseFormat{Type: "json_schema", JSONSchema: &OpenAIJSONSchemaFormat{
+		Name:   "person",
+		Schema: json.RawMessage(personSchema),
+	}}
+}
+
+// scriptedBackend answers each chat request with the next of outputs and
+// records the requests it received.
+func scriptedBackend(t *testing.T, outputs ...string) (*routing.BackendEndpoint, *[]OpenAIChatCompletionRequest) {
+	t.Helper()
+	var received []OpenAIChatCompletionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenAIChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode backend request: %v", err)
+		}
+		received = append(received, req)
+		output := outputs[len(received)-1]
+		_ = json.NewEncoder(w).Encode(OpenAIChatCompletionResponse{
+			ID:      "chatcmpl-1",
+			Choices: []OpenAIChoice{{Message: OpenAIMessage{Role: "assistant", Content: output}, FinishReason: "stop"}},
+			Usage:   OpenAIUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return &routing.BackendEndpoint{ID: "backend-1", URI: srv.URL, Timeout: time.Second}, &received
+}
+
+func TestCompileResponseFormat(t *testing.T) {
+	for _, format := range []*OpenAIResponseFormat{nil, {Type: "text"}} {
+		if schema, err := compileResponseFormat(format); schema != nil || err != nil {
+			t.Fatalf("expected no schema for %+v, got %v, %v", format, schema, err)
+		}
+	}
+	if schema, err := compileResponseFormat(personFormat()); schema == nil || err != nil {
+		t.Fatalf("expected compiled schema, got %v", err)
+	}
+
+	invalid := map[string]*OpenAIResponseFormat{
+		"response_format.type":                        {Type: "xml"},
+		"response_format.json_schema:":                {Type: "json_schema"},
+		"response_format.json_schema.name":            {Type: "json_schema", JSONSchema: &OpenAIJSONSchemaFormat{Name: "a b", Schema: json.RawMessage(personSchema)}},
+		"response_format.json_schema.schema.$ref":     {Type: "json_schema", JSONSchema: &OpenAIJSONSchemaFormat{Name: "remote", Schema: json.RawMessage(`{"$ref":"http://example.com/s.json"}`)}},
+		"response_format.json_schema.schema: invalid": {Type: "json_schema", JSONSchema: &OpenAIJSONSchemaFormat{Name: "bad", Schema: json.RawMessage(`{"type":"strin"}`)}},
+	}
+	for prefix, format := range invalid {
+		if _, err := compileResponseFormat(format); err == nil || !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("expected error starting %q, got %v", prefix, err)
+		}
+	}
+}
+
+func TestStructuredOutputStripsCodeFences(t *testing.T) {
+	endpoint, received := scriptedBackend(t, "```json\n{\"name\":\"Ada\",\"age\":36}\n```")
+	schema, _ := compileResponseFormat(personFormat())
+	h := &Handler{backendClient: routing.NewBackendClient(zap.NewNop(), 30*time.Second), repairRetries: 2}
+
+	resp, _, err := h.forwardStructuredChatCompletion(t.Context(), endpoint, OpenAIChatCompletionRequest{Model: "m"}, schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != `{"name":"Ada","age":36}` {
+		t.Fatalf("expected fences stripped, got %q", got)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("expected no repair attempts, got %d requests", len(*received))
+	}
+}
+
+func TestStructuredOutputRepairsViolations(t *testing.T) {
+	endpoint, received := scriptedBackend(t, "Sure! Here is the person.", `{"name":"Ada"}`, `{"name":"Ada","age":36}`)
+	schema, _ := compileResponseFormat(personFormat())
+	h := &Handler{backendClient: routing.NewBackendClient(zap.NewNop(), 30*time.Second), repairRetries: 2}
+	req := OpenAIChatCompletionRequest{Model: "m", Messages: []OpenAIMessage{{Role: "user", Content: "Describe Ada"}}}
+
+	resp, _, err := h.forwardStructuredChatCompletion(t.Context(), endpoint, req, schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*received) != 3 {
+		t.Fatalf("expected 3 backend requests, got %d", len(*received))
+	}
+	if resp.Usage.TotalTokens != 45 {
+		t.Fatalf("expected usage summed across attempts, got %+v", resp.Usage)
+	}
+
+	repair := (*received)[2].Messages
+	if len(repair) != 3 || repair[1].Content != `{"name":"Ada"}` || !strings.Contains(repair[2].Content, "age") {
+		t.Fatalf("unexpected repair conversation: %+v", repair)
+	}
+	if len(req.Messages) != 1 {
+		t.Fatal("original request messages were modified")
+	}
+}
+
+func TestStructuredOutputFailsAfterRetries(t *testing.T) {
+	endpoint, received := scriptedBackend(t, "not json", "still not json")
+	schema, _ := compileResponseFormat(personFormat())
+	h := &Handler{backendClient: routing.NewBackendClient(zap.NewNop(), 30*time.Second), repairRetries: 1}
+
+	_, _, err := h.forwardStructuredChatCompletion(t.Context(), endpoint, OpenAIChatCompletionRequest{Model: "m"}, schema)
+	var violationErr *SchemaViolationError
+	if !errors.As(err, &violationErr) || violationErr.Attempts != 2 {
+		t.Fatalf("expected schema violation after 2 attempts, got %v", err)
+	}
+	if len(*received) != 2 {
+		t.Fatalf("expected 2 backend requests, got %d", len(*received))
+	}
+}
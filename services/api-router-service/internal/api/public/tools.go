@@ -0,0 +1,365 @@
+// Package public provides tool/function-calling validation for the public API.
+//
+// Purpose:
+//   Clients may send OpenAI tool definitions ("tools"/"tool_choice") or the
+//   legacy function-calling fields ("functions"/"function_call"). This file
+//   validates their JSON schemas, enforces the org's tool count and size
+//   limits, normalizes everything to "tools", and converts back to legacy
+//   "functions" for backends labelled with BACKEND_TOOL_FORMATS.
+//
+// Debugging Notes:
+//   - All problems are reported at once in a single INVALID_TOOLS error,
+//     each prefixed with its JSON path (e.g. tools[2].function.parameters)
+//   - Only local "$ref"s ("#/...") are allowed; schemas are never fetched
+//   - Limits come from the org (API key validation) and fall back to
+//     TOOLS_MAX_COUNT / TOOLS_MAX_TOTAL_BYTES
+//
+package public
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+)
+
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// emptyToolParameters is sent for tools defined without parameters.
+var emptyToolParameters = json.RawMessage(`{"type":"object","properties":{}}`)
+
+// OpenAITool is a tool definition in a chat completions request.
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction is a callable function and its JSON Schema parameters.
+type OpenAIFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	Strict      *bool           `json:"strict,omitempty"`
+}
+
+// OpenAIToolCall is a tool invocation returned by the model.
+type OpenAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+// OpenAIFunctionCall names a function and its JSON-encoded arguments.
+type OpenAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolValidationError lists every problem found in a request's tool definitions.
+type ToolValidationError struct {
+	Problems []string
+}
+
+func (e *ToolValidationError) Error() string {
+	return "invalid tools: " + strings.Join(e.Problems, "; ")
+}
+
+// ToolLimitError reports tool definitions exceeding the org's limits.
+type ToolLimitError struct {
+	Message string
+}
+
+func (e *ToolLimitError) Error() string {
+	return e.Message
+}
+
+// SetDefaultToolLimits sets the limits used when the org has none.
+func (h *Handler) SetDefaultToolLimits(limits auth.ToolLimits) {
+	h.toolLimits = limits
+}
+
+// effectiveToolLimits merges the org's limits over the router defaults.
+func (h *Handler) effectiveToolLimits(authCtx *auth.AuthenticatedContext) auth.ToolLimits {
+	limits := h.toolLimits
+	if authCtx.ToolLimits != nil {
+		if authCtx.ToolLimits.MaxTools > 0 {
+			limits.MaxTools = authCtx.ToolLimits.MaxTools
+		}
+		if authCtx.ToolLimits.MaxTotalBytes > 0 {
+			limits.MaxTotalBytes = authCtx.ToolLimits.MaxTotalBytes
+		}
+	}
+	return limits
+}
+
+// normalizeTools converts legacy function fields to tools, enforces limits and
+// validates every definition. It returns a *ToolLimitError or *ToolValidationError.
+func normalizeTools(req *OpenAIChatCompletionRequest, limits auth.ToolLimits) error {
+	problems := &ToolValidationError{}
+
+	if len(req.Functions) > 0 || len(req.FunctionCall) > 0 {
+		if len(req.Tools) > 0 || len(req.ToolChoice) > 0 {
+			return &ToolValidationError{Problems: []string{"use either tools/tool_choice or the legacy functions/function_call, not both"}}
+		}
+		for _, fn := range req.Functions {
+			req.Tools = append(req.Tools, OpenAITool{Type: "function", Function: fn})
+		}
+		if len(req.FunctionCall) > 0 {
+			choice, err := toolChoiceFromFunctionCall(req.FunctionCall)
+			if err != nil {
+				problems.Problems = append(problems.Problems, "function_call: "+err.Error())
+			}
+			req.ToolChoice = choice
+		}
+		req.Functions, req.FunctionCall = nil, nil
+	}
+	if len(req.Tools) == 0 {
+		if len(req.ToolChoice) > 0 && !isToolChoiceNone(req.ToolChoice) {
+			problems.Problems = append(problems.Problems, "tool_choice: requires tools to be defined")
+		}
+		if len(problems.Problems) > 0 {
+			return problems
+		}
+		return nil
+	}
+
+	if limits.MaxTools > 0 && len(req.Tools) > limits.MaxTools {
+		return &ToolLimitError{Message: fmt.Sprintf("request defines %d tools; the limit for this organization is %d", len(req.Tools), limits.MaxTools)}
+	}
+
+	names := make(map[string]bool, len(req.Tools))
+	for i := range req.Tools {
+		tool := &req.Tools[i]
+		path := fmt.Sprintf("tools[%d]", i)
+		if tool.Type == "" {
+			tool.Type = "function"
+		}
+		if tool.Type != "function" {
+			problems.Problems = append(problems.Problems, fmt.Sprintf("%s.type: unsupported tool type %q (only \"function\")", path, tool.Type))
+			continue
+		}
+
+		name := tool.Function.Name
+		switch {
+		case !toolNamePattern.MatchString(name):
+			problems.Problems = append(problems.Problems, fmt.Sprintf("%s.function.name: %q must be 1-64 characters of a-z, A-Z, 0-9, _ or -", path, name))
+		case names[name]:
+			problems.Problems = append(problems.Problems, fmt.Sprintf("%s.function.name: duplicate function name %q", path, name))
+		}
+		names[name] = true
+
+		if len(tool.Function.Parameters) == 0 || bytes.Equal(bytes.TrimSpace(tool.Function.Parameters), []byte("null")) {
+			tool.Function.Parameters = emptyToolParameters
+			continue
+		}
+		for _, problem := range validateToolSchema(tool.Function.Parameters) {
+			problems.Problems = append(problems.Problems, path+".function.parameters"+problem)
+		}
+	}
+
+	if len(req.ToolChoice) > 0 {
+		if err := validateToolChoice(req.ToolChoice, names); err != nil {
+			problems.Problems = append(problems.Problems, "tool_choice: "+err.Error())
+		}
+	}
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+
+	if limits.MaxTotalBytes > 0 {
+		encoded, _ := json.Marshal(req.Tools)
+		if len(encoded) > limits.MaxTotalBytes {
+			return &ToolLimitError{Message: fmt.Sprintf("tool definitions are %d bytes; the limit for this organization is %d", len(encoded), limits.MaxTotalBytes)}
+		}
+	}
+	return nil
+}
+
+// validateToolSchema checks a parameters schema and returns problems, each
+// starting with the path suffix (": ..." or ".properties.x: ...").
+func validateToolSchema(raw json.RawMessage) []string {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return []string{": must be a JSON Schema object"}
+	}
+
+	var problems []string
+	if t, _ := schema["type"].(string); t != "object" {
+		problems = append(problems, `.type: must be "object"`)
+	}
+	problems = append(problems, externalRefs(schema, "")...)
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(raw)); err != nil {
+		problems = append(problems, ": invalid JSON Schema: "+err.Error())
+	}
+	return problems
+}
+
+// externalRefs reports "$ref"s that would require fetching another document.
+func externalRefs(node interface{}, path string) []string {
+	var problems []string
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" && !strings.HasPrefix(ref, "#") {
+				problems = append(problems, fmt.Sprintf("%s.$ref: only local references (\"#/...\") are allowed, got %q", path, ref))
+				continue
+			}
+			problems = append(problems, externalRefs(child, path+"."+key)...)
+		}
+	case []interface{}:
+		for i, child := range value {
+			problems = append(problems, externalRefs(child, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+// validateToolChoice accepts "none", "auto", "required", or a named function.
+func validateToolChoice(raw json.RawMessage, names map[string]bool) error {
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		switch mode {
+		case "none", "auto", "required":
+			return nil
+		}
+		return fmt.Errorf("must be \"none\", \"auto\", \"required\" or {\"type\":\"function\",\"function\":{\"name\":...}}, got %q", mode)
+	}
+
+	var named struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &named); err != nil || named.Type != "function" {
+		return fmt.Errorf("must be \"none\", \"auto\", \"required\" or {\"type\":\"function\",\"function\":{\"name\":...}}")
+	}
+	if !names[named.Function.Name] {
+		return fmt.Errorf("function %q is not defined in tools", named.Function.Name)
+	}
+	return nil
+}
+
+func isToolChoiceNone(raw json.RawMessage) bool {
+	var mode string
+	return json.Unmarshal(raw, &mode) == nil && mode == "none"
+}
+
+// toolChoiceFromFunctionCall converts a legacy function_call to tool_choice.
+func toolChoiceFromFunctionCall(raw json.RawMessage) (json.RawMessage, error) {
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		return raw, nil // "none" and "auto" mean the same in both formats
+	}
+	var named struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &named); err != nil || named.Name == "" {
+		return nil, fmt.Errorf("must be \"none\", \"auto\" or {\"name\":...}")
+	}
+	return json.Marshal(map[string]interface{}{
+		"type":     "function",
+		"function": map[string]string{"name": named.Name},
+	})
+}
+
+// chatRequestForBackend returns req in the tool format the backend accepts.
+func (h *Handler) chatRequestForBackend(req OpenAIChatCompletionRequest, backendID string) OpenAIChatCompletionRequest {
+	if len(req.Tools) == 0 || h.backendRegistry == nil {
+		return req
+	}
+	backend, err := h.backendRegistry.GetBackend(backendID)
+	if err != nil || backend.ToolFormat != config.ToolFormatFunctions {
+		return req
+	}
+
+	legacy := req
+	legacy.Functions = make([]OpenAIFunction, len(req.Tools))
+	for i, tool := range req.Tools {
+		legacy.Functions[i] = tool.Function
+	}
+	if len(req.ToolChoice) > 0 {
+		var named struct {
+			Function struct {
+				Name string `json:"name"`
+			} `json:"function"`
+		}
+		var mode string
+		switch {
+		case json.Unmarshal(req.ToolChoice, &mode) == nil && mode == "required":
+			legacy.FunctionCall = json.RawMessage(`"auto"`) // No legacy equivalent
+		case json.Unmarshal(req.ToolChoice, &mode) == nil:
+			legacy.FunctionCall = req.ToolChoice
+		case json.Unmarshal(req.ToolChoice, &named) == nil:
+			legacy.FunctionCall, _ = json.Marshal(map[string]string{"name": named.Function.Name})
+		}
+	}
+	legacy.Tools, legacy.ToolChoice = nil, nil
+
+	// Tool call IDs do not exist in the legacy format; map results back to names
+	callNames := make(map[string]string)
+	legacy.Messages = make([]OpenAIMessage, len(req.Messages))
+	for i, msg := range req.Messages {
+		if len(msg.ToolCalls) > 0 {
+			for _, call := range msg.ToolCalls {
+				callNames[call.ID] = call.Function.Name
+			}
+			call := msg.ToolCalls[0].Function
+			msg.FunctionCall = &call
+			msg.ToolCalls = nil
+		}
+		if msg.Role == "tool" {
+			msg.Role = "function"
+			msg.Name = callNames[msg.ToolCallID]
+			msg.ToolCallID = ""
+		}
+		legacy.Messages[i] = msg
+	}
+	return legacy
+}
+
+// normalizeToolCalls rewrites legacy function_call responses as tool_calls.
+func normalizeToolCalls(resp *OpenAIChatCompletionResponse) {
+	for i := range resp.Choices {
+		msg := &resp.Choices[i].Message
+		if msg.FunctionCall == nil || len(msg.ToolCalls) > 0 {
+			continue
+		}
+		msg.ToolCalls = []OpenAIToolCall{{
+			ID:       fmt.Sprintf("call_%s_%d", resp.ID, i),
+			Type:     "function",
+			Function: *msg.FunctionCall,
+		}}
+		msg.FunctionCall = nil
+		if resp.Choices[i].FinishReason == "function_call" {
+			resp.Choices[i].FinishReason = "tool_calls"
+		}
+	}
+}
+
+// forwardChatCompletion sends a chat request in the backend's tool format and
+// returns the response in the OpenAI tools format.
+func (h *Handler) forwardChatCompletion(ctx context.Context, endpoint *routing.BackendEndpoint, req OpenAIChatCompletionRequest) (OpenAIChatCompletionResponse, *routing.RoutingDecision, error) {
+	respInterface, decision, err := h.forwardOpenAIRequest(ctx, endpoint, h.chatRequestForBackend(req, endpoint.ID), "chat")
+	if err != nil {
+		return OpenAIChatCompletionResponse{}, decision, err
+	}
+	resp, ok := respInterface.(OpenAIChatCompletionResponse)
+	if !ok {
+		return OpenAIChatCompletionResponse{}, decision, fmt.Errorf("invalid response type")
+	}
+	normalizeToolCalls(&resp)
+	return resp, decision, nil
+}
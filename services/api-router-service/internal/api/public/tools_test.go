@@ -0,0 +1,176 @@
+// Package public provides unit tests for tool/function-calling validation.
+//
+// Purpose:
+//   These tests validate schema checks, per-org limits, legacy function
+//   normalization, and conversion for backends that only accept "functions".
+//
+package public
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func weatherTool(name string) OpenAITool {
+	return OpenAITool{Type: "function", Function: OpenAIFunction{
+		Name:       name,
+		Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	}}
+}
+
+func TestNormalizeToolsAcceptsValidDefinitions(t *testing.T) {
+	req := OpenAIChatCompletionRequest{
+		Tools:      []OpenAITool{weatherTool("get_weather"), {Function: OpenAIFunction{Name: "now"}}},
+		ToolChoice: json.RawMessage(`{"type":"function","function":{"name":"get_weather"}}`),
+	}
+	if err := normalizeTools(&req, auth.ToolLimits{MaxTools: 4, MaxTotalBytes: 4096}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Tools[1].Type != "function" || string(req.Tools[1].Function.Parameters) != string(emptyToolParameters) {
+		t.Fatalf("expected defaults to be filled in, got %+v", req.Tools[1])
+	}
+}
+
+func TestNormalizeToolsReportsEveryProblem(t *testing.T) {
+	req := OpenAIChatCompletionRequest{
+		Tools: []OpenAITool{
+			weatherTool("get_weather"),
+			weatherTool("get_weather"),
+			{Type: "retrieval", Function: OpenAIFunction{Name: "search"}},
+			{Function: OpenAIFunction{Name: "bad name!"}},
+			{Function: OpenAIFunction{Name: "list", Parameters: json.RawMessage(`{"type":"array"}`)}},
+			{Function: OpenAIFunction{Name: "remote", Parameters: json.RawMessage(`{"type":"object","properties":{"x":{"$ref":"https://example.com/schema.json"}}}`)}},
+			{Function: OpenAIFunction{Name: "broken", Parameters: json.RawMessage(`{"type":"object","properties":{"x":{"type":"strin"}}}`)}},
+		},
+		ToolChoice: json.RawMessage(`{"type":"function","function":{"name":"missing"}}`),
+	}
+	err := normalizeTools(&req, auth.ToolLimits{})
+
+	var validationErr *ToolValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ToolValidationError, got %v", err)
+	}
+	want := []string{
+		"tools[1].function.name: duplicate",
+		"tools[2].type: unsupported",
+		"tools[3].function.name:",
+		"tools[4].function.parameters.type:",
+		"tools[5].function.parameters.properties.x.$ref:",
+		"tools[6].function.parameters: invalid JSON Schema",
+		"tool_choice: function \"missing\"",
+	}
+	if len(validationErr.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %q", len(want), validationErr.Problems)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(validationErr.Problems[i], prefix) {
+			t.Errorf("problem %d = %q, want prefix %q", i, validationErr.Problems[i], prefix)
+		}
+	}
+}
+
+func TestNormalizeToolsEnforcesLimits(t *testing.T) {
+	tools := []OpenAITool{weatherTool("a"), weatherTool("b"), weatherTool("c")}
+
+	var limitErr *ToolLimitError
+	req := OpenAIChatCompletionRequest{Tools: append([]OpenAITool(nil), tools...)}
+	if err := normalizeTools(&req, auth.ToolLimits{MaxTools: 2}); !errors.As(err, &limitErr) {
+		t.Fatalf("expected count limit error, got %v", err)
+	}
+	req = OpenAIChatCompletionRequest{Tools: append([]OpenAITool(nil), tools...)}
+	if err := normalizeTools(&req, auth.ToolLimits{MaxTotalBytes: 100}); !errors.As(err, &limitErr) {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+}
+
+func TestEffectiveToolLimitsPrefersOrgSettings(t *testing.T) {
+	h := &Handler{toolLimits: auth.ToolLimits{MaxTools: 128, MaxTotalBytes: 65536}}
+
+	got := h.effectiveToolLimits(&auth.AuthenticatedContext{ToolLimits: &auth.ToolLimits{MaxTools: 8}})
+	if got.MaxTools != 8 || got.MaxTotalBytes != 65536 {
+		t.Fatalf("unexpected limits: %+v", got)
+	}
+	if got := h.effectiveToolLimits(&auth.AuthenticatedContext{}); got != h.toolLimits {
+		t.Fatalf("expected defaults, got %+v", got)
+	}
+}
+
+func TestNormalizeToolsConvertsLegacyFunctions(t *testing.T) {
+	req := OpenAIChatCompletionRequest{
+		Functions:    []OpenAIFunction{weatherTool("get_weather").Function},
+		FunctionCall: json.RawMessage(`{"name":"get_weather"}`),
+	}
+	if err := normalizeTools(&req, auth.ToolLimits{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Functions) != 0 || len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_weather" {
+		t.Fatalf("expected functions to become tools, got %+v", req)
+	}
+	if string(req.ToolChoice) != `{"function":{"name":"get_weather"},"type":"function"}` {
+		t.Fatalf("unexpected tool_choice %s", req.ToolChoice)
+	}
+
+	mixed := OpenAIChatCompletionRequest{Tools: []OpenAITool{weatherTool("a")}, Functions: []OpenAIFunction{weatherTool("b").Function}}
+	if err := normalizeTools(&mixed, auth.ToolLimits{}); err == nil {
+		t.Fatal("expected error when mixing tools and functions")
+	}
+}
+
+func TestChatRequestForBackendUsesLegacyFormat(t *testing.T) {
+	registry := config.NewBackendRegistry(&config.Config{
+		BackendEndpoints:   "modern:http://modern:8000,legacy:http://legacy:8000",
+		BackendToolFormats: "legacy=functions",
+	})
+	h := &Handler{backendRegistry: registry}
+	req := OpenAIChatCompletionRequest{
+		Tools:      []OpenAITool{weatherTool("get_weather")},
+		ToolChoice: json.RawMessage(`{"type":"function","function":{"name":"get_weather"}}`),
+		Messages: []OpenAIMessage{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []OpenAIToolCall{{ID: "call_1", Type: "function", Function: OpenAIFunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: `{"temp":21}`},
+		},
+	}
+
+	if got := h.chatRequestForBackend(req, "modern"); len(got.Tools) != 1 || len(got.Functions) != 0 {
+		t.Fatalf("expected tools to pass through unchanged, got %+v", got)
+	}
+
+	got := h.chatRequestForBackend(req, "legacy")
+	if len(got.Tools) != 0 || len(got.Functions) != 1 || string(got.FunctionCall) != `{"name":"get_weather"}` {
+		t.Fatalf("expected legacy functions, got %+v", got)
+	}
+	if got.Messages[1].FunctionCall == nil || got.Messages[1].FunctionCall.Name != "get_weather" {
+		t.Fatalf("expected assistant function_call, got %+v", got.Messages[1])
+	}
+	if got.Messages[2].Role != "function" || got.Messages[2].Name != "get_weather" {
+		t.Fatalf("expected function result message, got %+v", got.Messages[2])
+	}
+	if req.Messages[2].Role != "tool" {
+		t.Fatal("original request messages were modified")
+	}
+}
+
+func TestNormalizeToolCallsConvertsLegacyResponse(t *testing.T) {
+	resp := OpenAIChatCompletionResponse{
+		ID: "chatcmpl-1",
+		Choices: []OpenAIChoice{{
+			Message:      OpenAIMessage{Role: "assistant", FunctionCall: &OpenAIFunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			FinishReason: "function_call",
+		}},
+	}
+	normalizeToolCalls(&resp)
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || choice.Message.FunctionCall != nil || len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("expected tool_calls response, got %+v", choice)
+	}
+	if call := choice.Message.ToolCalls[0]; call.ID != "call_chatcmpl-1_0" || call.Function.Name != "get_weather" {
+		t.Fatalf("unexpected tool call %+v", call)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/usage"
 )
 
@@ -83,7 +84,8 @@ func NewUsageHook(cfg UsageHookConfig) *UsageHook {
 	return hook
 }
 
-// EmitUsage emits a usage record after successful inference.
+// EmitUsage emits a usage record after successful inference. prompt and
+// response are recorded only as far as the org's usage payload policy allows.
 func (h *UsageHook) EmitUsage(
 	ctx context.Context,
 	authCtx *auth.AuthenticatedContext,
@@ -97,6 +99,8 @@ func (h *UsageHook) EmitUsage(
 	limitState string,
 	spanContext trace.SpanContext,
 	retryCount int,
+	prompt interface{},
+	response interface{},
 ) error {
 	// Build usage record context
 	recordCtx := usage.NewRecordContext(
@@ -112,11 +116,18 @@ func (h *UsageHook) EmitUsage(
 		decisionReason,
 	).
 		WithTraceContext(spanContext).
-		WithRetryCount(retryCount)
+		WithRetryCount(retryCount).
+		WithPayloads(prompt, response, payloadPolicy(authCtx))
+	if authCtx.ProjectID != "" {
+		recordCtx.WithMetadata("project_id", authCtx.ProjectID)
+	}
 
 	// Build record
 	record := h.builder.BuildRecord(recordCtx)
 
+	// Replace the request's budget hold, if any, with the actual cost
+	limiter.BudgetHoldFromContext(ctx).Settle(record.CostUSD)
+
 	// Try to publish immediately
 	if err := h.publisher.Publish(ctx, record); err != nil {
 		h.logger.Warn("failed to publish usage record, buffering",
@@ -149,6 +160,17 @@ func (h *UsageHook) EmitUsage(
 	return nil
 }
 
+// payloadPolicy converts the org's usage payload setting for the record builder.
+func payloadPolicy(authCtx *auth.AuthenticatedContext) *usage.PayloadPolicy {
+	if authCtx.UsagePayloads == nil {
+		return nil
+	}
+	return &usage.PayloadPolicy{
+		Mode:     authCtx.UsagePayloads.Mode,
+		MaxBytes: authCtx.UsagePayloads.MaxBytes,
+	}
+}
+
 // startRetryWorker starts a background worker to retry buffered records.
 func (h *UsageHook) startRetryWorker() {
 	h.retryTicker = time.NewTicker(h.retryDelay)
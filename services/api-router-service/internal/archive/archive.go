@@ -0,0 +1,327 @@
+// Package archive provides per-org archival of inference prompts and responses.
+//
+// Purpose:
+//   Orgs that opt in (user-org-service metadata "inference_archival") have each
+//   request/response pair persisted to S3-compatible object storage (MinIO,
+//   Linode Object Storage, AWS S3) keyed by org and request ID, so support and
+//   compliance reviewers can retrieve exactly what a model was sent and returned.
+//
+// Key Responsibilities:
+//   - Redact PII from string values when the org asks for it
+//   - Encrypt every record (AES-256-GCM) before it leaves the router
+//   - Write asynchronously so archival never adds latency to inference
+//   - Enforce per-org retention on read and with a periodic purge
+//
+// Debugging Notes:
+//   - Objects live at {prefix}/{org_id}/{request_id}.json.enc
+//   - The object key is the GCM additional data, so a ciphertext copied to
+//     another org's key fails to decrypt
+//   - The queue drops records when full (api_router_archive_writes_total{outcome="dropped"})
+//   - Expired records are deleted on read and by Purge, whichever comes first
+//
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// ErrNotFound is returned when a record does not exist or has expired.
+var ErrNotFound = errors.New("archive record not found")
+
+// idPattern limits org and request IDs to characters that are safe in object keys.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+const (
+	metaExpiresAt = "expires-at"
+	metaOrgID     = "org-id"
+	// minRetention is the shortest retention an org can choose; Purge skips
+	// objects younger than this without reading their metadata.
+	minRetention = 24 * time.Hour
+)
+
+// Policy is an org's archival setting, taken from API key validation.
+type Policy struct {
+	Retention time.Duration
+	RedactPII bool
+}
+
+// Record is one archived inference exchange.
+type Record struct {
+	RequestID    string          `json:"request_id"`
+	OrgID        string          `json:"org_id"`
+	APIKeyID     string          `json:"api_key_id,omitempty"`
+	Endpoint     string          `json:"endpoint"`
+	Model        string          `json:"model"`
+	BackendID    string          `json:"backend_id,omitempty"`
+	Request      json.RawMessage `json:"request"`
+	Response     json.RawMessage `json:"response"`
+	LatencyMS    int64           `json:"latency_ms"`
+	TokensInput  int             `json:"tokens_input"`
+	TokensOutput int             `json:"tokens_output"`
+	Redacted     bool            `json:"redacted"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+}
+
+// Config configures an Archiver.
+type Config struct {
+	Store         ObjectStore
+	EncryptionKey []byte // 32 bytes
+	Prefix        string
+	QueueSize     int
+	PurgeInterval time.Duration
+	Logger        *zap.Logger
+}
+
+// Archiver writes inference records to object storage in the background.
+type Archiver struct {
+	store   ObjectStore
+	sealer  *sealer
+	prefix  string
+	queue   chan Record
+	logger  *zap.Logger
+	now     func() time.Time
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped chan struct{}
+}
+
+// New creates an archiver and starts its writer and purge workers.
+func New(cfg Config) (*Archiver, error) {
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("archive store is required")
+	}
+	s, err := newSealer(cfg.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Archiver{
+		store:   cfg.Store,
+		sealer:  s,
+		prefix:  strings.Trim(cfg.Prefix, "/"),
+		queue:   make(chan Record, cfg.QueueSize),
+		logger:  cfg.Logger.With(zap.String("component", "archive")),
+		now:     time.Now,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+
+	a.wg.Add(2)
+	go a.writeLoop()
+	go a.purgeLoop(ctx, cfg.PurgeInterval)
+	return a, nil
+}
+
+// Stop flushes queued records and stops the workers.
+func (a *Archiver) Stop() {
+	a.cancel()
+	close(a.stopped)
+	a.wg.Wait()
+}
+
+// Archive queues a record for writing. It never blocks; the record is dropped
+// when the queue is full or the archiver is stopping.
+func (a *Archiver) Archive(record Record, policy Policy) error {
+	if !idPattern.MatchString(record.OrgID) || !idPattern.MatchString(record.RequestID) {
+		return fmt.Errorf("invalid org or request ID for archival")
+	}
+	if policy.Retention < minRetention {
+		policy.Retention = minRetention
+	}
+	if policy.RedactPII {
+		record.Request = RedactJSON(record.Request)
+		record.Response = RedactJSON(record.Response)
+		record.Redacted = true
+	}
+	record.CreatedAt = a.now().UTC()
+	record.ExpiresAt = record.CreatedAt.Add(policy.Retention)
+
+	select {
+	case <-a.stopped:
+		telemetry.RecordArchiveWrite("dropped")
+		return fmt.Errorf("archiver stopped")
+	default:
+	}
+	select {
+	case a.queue <- record:
+		return nil
+	default:
+		telemetry.RecordArchiveWrite("dropped")
+		return fmt.Errorf("archive queue full")
+	}
+}
+
+// Get returns a decrypted record. Expired records are deleted and reported as ErrNotFound.
+func (a *Archiver) Get(ctx context.Context, orgID, requestID string) (*Record, error) {
+	if !idPattern.MatchString(orgID) || !idPattern.MatchString(requestID) {
+		return nil, ErrNotFound
+	}
+	key := a.objectKey(orgID, requestID)
+	body, metadata, err := a.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if a.expired(metadata) {
+		if err := a.store.Delete(ctx, key); err != nil {
+			a.logger.Warn("failed to delete expired archive record", zap.String("key", key), zap.Error(err))
+		}
+		return nil, ErrNotFound
+	}
+
+	plaintext, err := a.sealer.open(body, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt archive record: %w", err)
+	}
+	var record Record
+	if err := json.Unmarshal(plaintext, &record); err != nil {
+		return nil, fmt.Errorf("decode archive record: %w", err)
+	}
+	return &record, nil
+}
+
+// Purge deletes records past their retention and returns how many were removed.
+func (a *Archiver) Purge(ctx context.Context) (int, error) {
+	objects, err := a.store.List(ctx, a.prefix+"/")
+	if err != nil {
+		return 0, fmt.Errorf("list archive objects: %w", err)
+	}
+
+	purged := 0
+	cutoff := a.now().Add(-minRetention)
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		if obj.LastModified.After(cutoff) {
+			continue
+		}
+		metadata, err := a.store.Head(ctx, obj.Key)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				a.logger.Warn("failed to read archive object metadata", zap.String("key", obj.Key), zap.Error(err))
+			}
+			continue
+		}
+		if !a.expired(metadata) {
+			continue
+		}
+		if err := a.store.Delete(ctx, obj.Key); err != nil {
+			a.logger.Warn("failed to delete expired archive record", zap.String("key", obj.Key), zap.Error(err))
+			continue
+		}
+		purged++
+	}
+	telemetry.RecordArchivePurged(purged)
+	return purged, nil
+}
+
+func (a *Archiver) writeLoop() {
+	defer a.wg.Done()
+	for {
+		select {
+		case record := <-a.queue:
+			a.write(record)
+		case <-a.stopped:
+			// Flush what is already queued
+			for {
+				select {
+				case record := <-a.queue:
+					a.write(record)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *Archiver) write(record Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := a.objectKey(record.OrgID, record.RequestID)
+	plaintext, err := json.Marshal(record)
+	if err != nil {
+		telemetry.RecordArchiveWrite("failed")
+		a.logger.Error("failed to encode archive record", zap.String("request_id", record.RequestID), zap.Error(err))
+		return
+	}
+	body, err := a.sealer.seal(plaintext, []byte(key))
+	if err != nil {
+		telemetry.RecordArchiveWrite("failed")
+		a.logger.Error("failed to encrypt archive record", zap.String("request_id", record.RequestID), zap.Error(err))
+		return
+	}
+
+	metadata := map[string]string{
+		metaExpiresAt: record.ExpiresAt.Format(time.RFC3339),
+		metaOrgID:     record.OrgID,
+	}
+	if err := a.store.Put(ctx, key, body, metadata); err != nil {
+		telemetry.RecordArchiveWrite("failed")
+		a.logger.Warn("failed to write archive record",
+			zap.String("org_id", record.OrgID),
+			zap.String("request_id", record.RequestID),
+			zap.Error(err),
+		)
+		return
+	}
+	telemetry.RecordArchiveWrite("stored")
+}
+
+func (a *Archiver) purgeLoop(ctx context.Context, interval time.Duration) {
+	defer a.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := a.Purge(ctx)
+			if err != nil && ctx.Err() == nil {
+				a.logger.Warn("archive purge failed", zap.Error(err))
+				continue
+			}
+			if purged > 0 {
+				a.logger.Info("purged expired archive records", zap.Int("count", purged))
+			}
+		}
+	}
+}
+
+func (a *Archiver) objectKey(orgID, requestID string) string {
+	return fmt.Sprintf("%s/%s/%s.json.enc", a.prefix, orgID, requestID)
+}
+
+// expired reports whether metadata carries an expiry in the past. Objects
+// without a parseable expiry are treated as expired so they cannot linger.
+func (a *Archiver) expired(metadata map[string]string) bool {
+	expiresAt, err := time.Parse(time.RFC3339, metadata[metaExpiresAt])
+	if err != nil {
+		return true
+	}
+	return !a.now().Before(expiresAt)
+}
@@ -0,0 +1,185 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	body     []byte
+	metadata map[string]string
+	modified time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string]memoryObject)}
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, body []byte, metadata map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = memoryObject{body: body, metadata: metadata, modified: time.Now()}
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return obj.body, obj.metadata, nil
+}
+
+func (m *memoryStore) Head(ctx context.Context, key string) (map[string]string, error) {
+	_, metadata, err := m.Get(ctx, key)
+	return metadata, err
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []ObjectInfo
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, ObjectInfo{Key: key, LastModified: obj.modified})
+		}
+	}
+	return out, nil
+}
+
+func newTestArchiver(t *testing.T, store ObjectStore) *Archiver {
+	t.Helper()
+	a, err := New(Config{Store: store, EncryptionKey: bytes.Repeat([]byte{7}, 32), Prefix: "inference"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+func TestArchiveRoundTripEncryptedAndRedacted(t *testing.T) {
+	store := newMemoryStore()
+	a := newTestArchiver(t, store)
+
+	err := a.Archive(Record{
+		RequestID: "req-1",
+		OrgID:     "org-1",
+		Model:     "gpt-4o",
+		Request:   json.RawMessage(`{"prompt":"mail jane@example.com","max_tokens":1234567890}`),
+		Response:  json.RawMessage(`{"text":"ok"}`),
+	}, Policy{Retention: 48 * time.Hour, RedactPII: true})
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	a.Stop() // flushes the queue
+
+	obj, ok := store.objects["inference/org-1/req-1.json.enc"]
+	if !ok {
+		t.Fatalf("expected object to be written, have %v", store.objects)
+	}
+	if bytes.Contains(obj.body, []byte("gpt-4o")) {
+		t.Fatal("archive object must be encrypted")
+	}
+
+	record, err := a.Get(context.Background(), "org-1", "req-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !record.Redacted || string(record.Request) != `{"max_tokens":1234567890,"prompt":"mail [REDACTED_EMAIL]"}` {
+		t.Fatalf("unexpected redacted request %s", record.Request)
+	}
+
+	// A ciphertext moved under another org's key must not decrypt
+	store.objects["inference/org-2/req-1.json.enc"] = obj
+	if _, err := a.Get(context.Background(), "org-2", "req-1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected decryption failure, got %v", err)
+	}
+}
+
+func TestArchiveRetention(t *testing.T) {
+	store := newMemoryStore()
+	a := newTestArchiver(t, store)
+	_ = a.Archive(Record{RequestID: "req-1", OrgID: "org-1"}, Policy{Retention: 24 * time.Hour})
+	_ = a.Archive(Record{RequestID: "req-2", OrgID: "org-1"}, Policy{Retention: 72 * time.Hour})
+	a.Stop()
+
+	// Two days later only the 72h record remains
+	later := time.Now().Add(48 * time.Hour)
+	a.now = func() time.Time { return later }
+	for key, obj := range store.objects {
+		obj.modified = later.Add(-48 * time.Hour)
+		store.objects[key] = obj
+	}
+
+	if _, err := a.Get(context.Background(), "org-1", "req-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected expired record to be not found, got %v", err)
+	}
+	if _, ok := store.objects["inference/org-1/req-1.json.enc"]; ok {
+		t.Fatal("expected expired record to be deleted on read")
+	}
+
+	_ = store.Put(context.Background(), "inference/org-1/req-3.json.enc", []byte("x"), map[string]string{metaExpiresAt: time.Now().Format(time.RFC3339)})
+	obj := store.objects["inference/org-1/req-3.json.enc"]
+	obj.modified = later.Add(-48 * time.Hour)
+	store.objects["inference/org-1/req-3.json.enc"] = obj
+
+	purged, err := a.Purge(context.Background())
+	if err != nil || purged != 1 {
+		t.Fatalf("expected 1 purged, got %d, %v", purged, err)
+	}
+	if _, err := a.Get(context.Background(), "org-1", "req-2"); err != nil {
+		t.Fatalf("expected unexpired record to remain, got %v", err)
+	}
+}
+
+func TestArchiveRejectsUnsafeIDs(t *testing.T) {
+	a := newTestArchiver(t, newMemoryStore())
+	defer a.Stop()
+	if err := a.Archive(Record{RequestID: "../other", OrgID: "org-1"}, Policy{}); err == nil {
+		t.Fatal("expected unsafe request ID to be rejected")
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	cases := map[string]string{
+		"call 555-123-4567 now":       "call [REDACTED_PHONE] now",
+		"ssn 123-45-6789":             "ssn [REDACTED_SSN]",
+		"card 4111 1111 1111 1111":    "card [REDACTED_CARD]",
+		"order 1234567812345678":      "order 1234567812345678", // fails Luhn
+		"from 10.0.0.1":               "from [REDACTED_IP]",
+		"write to a.b@example.co.uk ": "write to [REDACTED_EMAIL] ",
+	}
+	for in, want := range cases {
+		if got := RedactString(in); got != want {
+			t.Errorf("RedactString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	if _, err := ParseEncryptionKey("c2hvcnQ="); err == nil {
+		t.Fatal("expected short key to be rejected")
+	}
+	if _, err := ParseEncryptionKey("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="); err != nil {
+		t.Fatalf("expected 32-byte key to parse: %v", err)
+	}
+}
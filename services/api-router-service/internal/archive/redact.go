@@ -0,0 +1,94 @@
+package archive
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// piiPatterns are applied in order to every string value of an archived
+// request or response. Card numbers are checked with Luhn before redaction.
+var piiPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+	{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[REDACTED_SSN]"},
+	{regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]?\d{3}[ .-]\d{4}\b`), "[REDACTED_PHONE]"},
+	{regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`), "[REDACTED_IP]"},
+}
+
+var cardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+// RedactString masks emails, card numbers, US SSNs, phone numbers and IPv4 addresses.
+func RedactString(s string) string {
+	s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if luhnValid(match) {
+			return "[REDACTED_CARD]"
+		}
+		return match
+	})
+	for _, p := range piiPatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// RedactJSON redacts PII from every string value in a JSON document, leaving
+// keys, numbers and structure intact. Invalid JSON is redacted as plain text
+// and re-encoded as a JSON string.
+func RedactJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		out, _ := json.Marshal(RedactString(string(raw)))
+		return out
+	}
+	out, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return RedactString(value)
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = redactValue(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+func luhnValid(candidate string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(candidate)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
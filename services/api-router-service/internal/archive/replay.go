@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// maxDiffLines bounds the line diff so a huge completion cannot make the
+// quadratic LCS table blow up; longer outputs are compared for equality only.
+const maxDiffLines = 2000
+
+// ReplayRun is the result of re-executing an archived request against a backend.
+type ReplayRun struct {
+	BackendID    string          `json:"backend_id"`
+	Response     json.RawMessage `json:"response,omitempty"`
+	LatencyMS    int64           `json:"latency_ms"`
+	TokensInput  int             `json:"tokens_input"`
+	TokensOutput int             `json:"tokens_output"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Comparison is a side-by-side view of an archived exchange and its replay.
+type Comparison struct {
+	RequestID string         `json:"request_id"`
+	OrgID     string         `json:"org_id"`
+	Endpoint  string         `json:"endpoint"`
+	Model     string         `json:"model"`
+	Redacted  bool           `json:"redacted"` // The replayed prompt had PII redacted
+	Original  ReplayRun      `json:"original"`
+	Replay    ReplayRun      `json:"replay"`
+	Diff      ComparisonDiff `json:"diff"`
+}
+
+// ComparisonDiff summarises how the replay differs from the original.
+type ComparisonDiff struct {
+	LatencyMSDelta    int64    `json:"latency_ms_delta"`
+	TokensInputDelta  int      `json:"tokens_input_delta"`
+	TokensOutputDelta int      `json:"tokens_output_delta"`
+	OutputIdentical   bool     `json:"output_identical"`
+	OutputDiff        []string `json:"output_diff,omitempty"` // "  " unchanged, "- " original only, "+ " replay only
+}
+
+// Compare builds the side-by-side comparison of record and its replay.
+func Compare(record *Record, replay *ReplayRun) Comparison {
+	original := ReplayRun{
+		BackendID:    record.BackendID,
+		Response:     record.Response,
+		LatencyMS:    record.LatencyMS,
+		TokensInput:  record.TokensInput,
+		TokensOutput: record.TokensOutput,
+	}
+	cmp := Comparison{
+		RequestID: record.RequestID,
+		OrgID:     record.OrgID,
+		Endpoint:  record.Endpoint,
+		Model:     record.Model,
+		Redacted:  record.Redacted,
+		Original:  original,
+		Replay:    *replay,
+	}
+	if replay.Error != "" {
+		return cmp
+	}
+
+	cmp.Diff.LatencyMSDelta = replay.LatencyMS - original.LatencyMS
+	cmp.Diff.TokensInputDelta = replay.TokensInput - original.TokensInput
+	cmp.Diff.TokensOutputDelta = replay.TokensOutput - original.TokensOutput
+
+	before, after := OutputText(original.Response), OutputText(replay.Response)
+	cmp.Diff.OutputIdentical = before == after
+	if !cmp.Diff.OutputIdentical {
+		cmp.Diff.OutputDiff = diffLines(before, after)
+	}
+	return cmp
+}
+
+// OutputText extracts the generated text from a router, OpenAI chat, or
+// OpenAI completion response. It falls back to the raw JSON.
+func OutputText(response json.RawMessage) string {
+	var shape struct {
+		Output struct {
+			Text string `json:"text"`
+		} `json:"output"`
+		Choices []struct {
+			Text    string `json:"text"`
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(response, &shape); err != nil {
+		return string(response)
+	}
+	if shape.Output.Text != "" {
+		return shape.Output.Text
+	}
+	if len(shape.Choices) > 0 {
+		texts := make([]string, len(shape.Choices))
+		for i, choice := range shape.Choices {
+			texts[i] = choice.Text + choice.Message.Content
+		}
+		return strings.Join(texts, "\n")
+	}
+	return string(response)
+}
+
+// diffLines returns a line diff of a and b based on their longest common subsequence.
+func diffLines(a, b string) []string {
+	left, right := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(left) > maxDiffLines || len(right) > maxDiffLines {
+		return []string{"- (original output too long to diff)", "+ (replay output too long to diff)"}
+	}
+
+	// lcs[i][j] is the LCS length of left[i:] and right[j:]
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			out = append(out, "  "+left[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+left[i])
+			i++
+		default:
+			out = append(out, "+ "+right[j])
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		out = append(out, "- "+left[i])
+	}
+	for ; j < len(right); j++ {
+		out = append(out, "+ "+right[j])
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package archive
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	record := &Record{
+		RequestID:    "req-1",
+		BackendID:    "backend-a",
+		Response:     json.RawMessage(`{"choices":[{"message":{"role":"assistant","content":"one\ntwo\nthree"}}]}`),
+		LatencyMS:    120,
+		TokensInput:  10,
+		TokensOutput: 30,
+	}
+	replay := &ReplayRun{
+		BackendID:    "backend-b",
+		Response:     json.RawMessage(`{"choices":[{"message":{"role":"assistant","content":"one\n2\nthree"}}]}`),
+		LatencyMS:    80,
+		TokensInput:  10,
+		TokensOutput: 25,
+	}
+
+	cmp := Compare(record, replay)
+	if cmp.Original.BackendID != "backend-a" || cmp.Replay.BackendID != "backend-b" {
+		t.Fatalf("unexpected backends %+v", cmp)
+	}
+	if cmp.Diff.LatencyMSDelta != -40 || cmp.Diff.TokensInputDelta != 0 || cmp.Diff.TokensOutputDelta != -5 {
+		t.Fatalf("unexpected deltas %+v", cmp.Diff)
+	}
+	want := []string{"  one", "- two", "+ 2", "  three"}
+	if cmp.Diff.OutputIdentical || !reflect.DeepEqual(cmp.Diff.OutputDiff, want) {
+		t.Fatalf("expected diff %v, got %+v", want, cmp.Diff)
+	}
+}
+
+func TestCompareFailedReplay(t *testing.T) {
+	cmp := Compare(&Record{LatencyMS: 50}, &ReplayRun{Error: "backend returned status 500"})
+	if !reflect.DeepEqual(cmp.Diff, ComparisonDiff{}) {
+		t.Fatalf("expected no diff for failed replay, got %+v", cmp.Diff)
+	}
+}
+
+func TestOutputText(t *testing.T) {
+	cases := map[string]string{
+		`{"output":{"text":"router"}}`:        "router",
+		`{"choices":[{"text":"completion"}]}`: "completion",
+		`not json`:                            "not json",
+	}
+	for in, want := range cases {
+		if got := OutputText(json.RawMessage(in)); got != want {
+			t.Errorf("OutputText(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
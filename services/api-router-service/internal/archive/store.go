@@ -0,0 +1,165 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// ObjectInfo describes a stored object returned by List.
+type ObjectInfo struct {
+	Key          string
+	LastModified time.Time
+}
+
+// ObjectStore is the subset of object storage the archiver needs. Get and
+// Head return ErrNotFound for missing keys.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body []byte, metadata map[string]string) error
+	Get(ctx context.Context, key string) ([]byte, map[string]string, error)
+	Head(ctx context.Context, key string) (map[string]string, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// S3Config configures an S3-compatible store.
+type S3Config struct {
+	Endpoint  string // Empty for AWS S3; set for MinIO or Linode Object Storage
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store stores archive objects in an S3-compatible bucket.
+type S3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3Store creates a store for MinIO, Linode Object Storage, or AWS S3.
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("archive bucket is required")
+	}
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if cfg.AccessKey != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, ""),
+		))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config: %w", err)
+	}
+	if cfg.Endpoint != "" {
+		awsCfg.BaseEndpoint = aws.String(cfg.Endpoint)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.UsePathStyle = true // Required for MinIO and Linode Object Storage
+		}
+	})
+	return &S3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Put writes an object with user metadata.
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, metadata map[string]string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(body),
+		ContentType:   aws.String("application/octet-stream"),
+		ContentLength: aws.Int64(int64(len(body))),
+		Metadata:      metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("put archive object: %w", err)
+	}
+	return nil
+}
+
+// Get reads an object and its user metadata.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, map[string]string, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, nil, s.wrap("get", err)
+	}
+	defer out.Body.Close()
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read archive object: %w", err)
+	}
+	return body, out.Metadata, nil
+}
+
+// Head reads an object's user metadata.
+func (s *S3Store) Head(ctx context.Context, key string) (map[string]string, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s.wrap("head", err)
+	}
+	return out.Metadata, nil
+}
+
+// Delete removes an object.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return s.wrap("delete", err)
+	}
+	return nil
+}
+
+// List returns every object under prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, s.wrap("list", err)
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// wrap maps missing-object errors to ErrNotFound.
+func (s *S3Store) wrap(op string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound":
+			return ErrNotFound
+		}
+	}
+	return fmt.Errorf("%s archive object: %w", op, err)
+}
@@ -13,6 +13,10 @@
 //   - Verify HMAC signatures if provided
 //   - Extract organization and principal context
 //   - Handle revocation and expiration checks
+//   - Carry per-key network restrictions (CIDR allowlist, required headers)
+//   - Report each new client IP per key to user-org (security event detection)
+//   - Accept bearer platform access tokens (JWTs from user-org-service) when a
+//     token verifier is configured; see shared-go/auth/tokens
 //
 // Requirements Reference:
 //   - specs/006-api-router-service/spec.md#FR-001 (Credential validation)
@@ -21,6 +25,7 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -29,10 +34,15 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 // AuthenticatedContext contains authentication and authorization context.
@@ -42,6 +52,93 @@ type AuthenticatedContext struct {
 	PrincipalID    string
 	PrincipalType  string
 	Scopes         []string
+	Restrictions   *Restrictions   // Network restrictions; nil when unrestricted
+	AllowedRegions []string        // Org data residency: backend regions allowed to serve requests
+	Archival       *ArchivalPolicy // Org inference archival; nil when disabled
+	ToolLimits     *ToolLimits     // Org tool-calling limits; nil for router defaults
+
+	// Model entitlements; nil allows every model. Key lists narrow the org's.
+	OrgModels *ModelEntitlements
+	KeyModels *ModelEntitlements
+
+	// Org rate limit algorithm and concurrency caps; nil uses router defaults.
+	RateLimits *RateLimits
+
+	// ProjectID is the team or project the key belongs to; usage is attributed
+	// to it within OrganizationID. Empty for keys issued in a top-level org.
+	ProjectID string
+
+	// Org handling of prompts and responses in usage records; nil omits them.
+	UsagePayloads *UsagePayloadPolicy
+
+	// CORSOrigins are the browser origins allowed to use the org's keys; empty allows any.
+	CORSOrigins []string
+
+	// Org request/audit log export to a customer destination; nil when disabled.
+	LogExport *LogExportPolicy
+}
+
+// LogExportPolicy is an org's log export setting.
+type LogExportPolicy struct {
+	Enabled      bool                  `json:"enabled"`
+	Destination  *LogExportDestination `json:"destination"`
+	Streams      []string              `json:"streams"`
+	MinStatus    int                   `json:"minStatus"`
+	RedactFields []string              `json:"redactFields"`
+}
+
+// LogExportDestination is an S3 bucket written with an assumed role, or an
+// HTTPS endpoint receiving signed batches.
+type LogExportDestination struct {
+	Type          string `json:"type"` // s3 or https
+	Bucket        string `json:"bucket"`
+	Region        string `json:"region"`
+	Prefix        string `json:"prefix"`
+	RoleARN       string `json:"roleArn"`
+	ExternalID    string `json:"externalId"`
+	URL           string `json:"url"`
+	SigningSecret string `json:"signingSecret"`
+}
+
+// UsagePayloadPolicy is an org's usage record payload setting.
+type UsagePayloadPolicy struct {
+	Mode     string `json:"mode"` // full, truncated, hash_only or omitted
+	MaxBytes int    `json:"maxBytes"`
+}
+
+// RateLimits selects the org's rate limit algorithm and caps its concurrent requests.
+type RateLimits struct {
+	Tier                string `json:"tier"`      // Plan tier labelling rate limit metrics; empty for "default"
+	Algorithm           string `json:"algorithm"` // token_bucket, fixed_window or sliding_window; empty for the router default
+	MaxConcurrent       int    `json:"maxConcurrent"`
+	MaxConcurrentPerKey int    `json:"maxConcurrentPerKey"`
+
+	// Open streaming connections; zero uses the router's STREAM_MAX_CONCURRENT* defaults
+	MaxConcurrentStreams       int `json:"maxConcurrentStreams"`
+	MaxConcurrentStreamsPerKey int `json:"maxConcurrentStreamsPerKey"`
+}
+
+// ToolLimits caps tool/function definitions per request; zero fields use router defaults.
+type ToolLimits struct {
+	MaxTools      int `json:"maxTools"`
+	MaxTotalBytes int `json:"maxTotalBytes"`
+}
+
+// ArchivalPolicy is an org's prompt/response archival setting.
+type ArchivalPolicy struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retentionDays"`
+	RedactPII     bool `json:"redactPii"`
+}
+
+// HasScope reports whether the authenticated key was granted the given scope.
+func (c *AuthenticatedContext) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // Authenticator handles API key authentication.
@@ -49,13 +146,30 @@ type Authenticator struct {
 	logger          *zap.Logger
 	userOrgURL      string        // URL to user-org-service for key validation
 	httpClient      *http.Client  // HTTP client for user-org-service requests
+	cacheMu         sync.Mutex
 	validationCache map[string]*cachedValidation // Simple in-memory cache (key: fingerprint, value: validation result)
+	cacheHits       atomic.Uint64
+	cacheMisses     atomic.Uint64
+	tokenVerifier   *tokens.Verifier // Platform access tokens; nil accepts API keys only
 }
 
 // cachedValidation stores a cached validation result with expiration.
 type cachedValidation struct {
 	result      *AuthenticatedContext
 	expiresAt   time.Time
+
+	// clientIPs the result was validated for; user-org sees each new one so it
+	// can flag keys used from unfamiliar networks.
+	clientIPs map[string]bool
+}
+
+// maxCachedClientIPs stops revalidating a busy key once this many distinct
+// client IPs have been reported within one cache period.
+const maxCachedClientIPs = 16
+
+// reported reports whether user-org already saw clientIP for this result.
+func (c *cachedValidation) reported(clientIP string) bool {
+	return clientIP == "" || c.clientIPs[clientIP] || len(c.clientIPs) >= maxCachedClientIPs
 }
 
 // NewAuthenticator creates a new authenticator.
@@ -63,21 +177,40 @@ func NewAuthenticator(logger *zap.Logger, userOrgURL string, timeout time.Durati
 	return &Authenticator{
 		logger:          logger,
 		userOrgURL:      strings.TrimSuffix(userOrgURL, "/"),
-		httpClient:      &http.Client{Timeout: timeout},
+		httpClient:      &http.Client{Timeout: timeout, Transport: &requestid.Transport{}},
 		validationCache: make(map[string]*cachedValidation),
 	}
 }
 
-// Authenticate validates the API key from the request headers.
-// Returns authenticated context or an error.
+// SetTokenVerifier enables bearer platform access tokens, checked by v.
+func (a *Authenticator) SetTokenVerifier(v *tokens.Verifier) {
+	a.tokenVerifier = v
+}
+
+// SetServiceTokens authenticates calls to user-org-service with service
+// tokens from source.
+func (a *Authenticator) SetServiceTokens(source *tokens.ServiceTokenSource) {
+	a.httpClient.Transport = &tokens.ServiceTransport{Source: source, Base: a.httpClient.Transport}
+}
+
+// Authenticate validates the API key (or platform access token) from the
+// request headers. Returns authenticated context or an error.
 func (a *Authenticator) Authenticate(r *http.Request) (*AuthenticatedContext, error) {
+	if token, ok := a.extractAccessToken(r); ok {
+		return a.authenticateAccessToken(r.Context(), token)
+	}
+
 	apiKey := a.extractAPIKey(r)
 	if apiKey == "" {
 		return nil, fmt.Errorf("missing X-API-Key header")
 	}
 
 	// Validate API key against user-org-service
-	ctx, err := a.validateAPIKey(apiKey)
+	clientIP := ""
+	if ip := ClientIP(r); ip != nil {
+		clientIP = ip.String()
+	}
+	ctx, err := a.validateAPIKey(apiKey, clientIP)
 	if err != nil {
 		return nil, fmt.Errorf("invalid API key: %w", err)
 	}
@@ -110,20 +243,72 @@ func (a *Authenticator) extractAPIKey(r *http.Request) string {
 	return ""
 }
 
+// extractAccessToken returns a bearer JWT when platform tokens are enabled.
+// API keys never contain dots, so a three-segment bearer value is a token.
+func (a *Authenticator) extractAccessToken(r *http.Request) (string, bool) {
+	if a.tokenVerifier == nil || r.Header.Get("X-API-Key") != "" {
+		return "", false
+	}
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return "", false
+	}
+	token := strings.TrimSpace(parts[1])
+	if strings.Count(token, ".") != 2 {
+		return "", false
+	}
+	return token, true
+}
+
+// authenticateAccessToken validates a platform access token. Token callers
+// get the router's default limits; their per-key limits and usage are keyed
+// by client and user rather than an API key ID.
+func (a *Authenticator) authenticateAccessToken(ctx context.Context, token string) (*AuthenticatedContext, error) {
+	claims, err := a.tokenVerifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+	if claims.OrgID == "" {
+		return nil, fmt.Errorf("invalid access token: missing org_id")
+	}
+	principalID := claims.UserID
+	if principalID == "" {
+		principalID = claims.Subject
+	}
+	return &AuthenticatedContext{
+		APIKeyID:       "oauth:" + claims.ClientID + ":" + principalID,
+		OrganizationID: claims.OrgID,
+		PrincipalID:    principalID,
+		PrincipalType:  "user",
+		Scopes:         claims.Scopes,
+	}, nil
+}
+
 // validateAPIKey validates an API key by calling user-org-service.
 // Falls back to stub validation for dev/test keys if user-org-service is unavailable.
-func (a *Authenticator) validateAPIKey(apiKey string) (*AuthenticatedContext, error) {
+func (a *Authenticator) validateAPIKey(apiKey, clientIP string) (*AuthenticatedContext, error) {
 	// Check cache first (compute fingerprint for cache key)
 	fingerprint := a.computeFingerprint(apiKey)
+	var reportedIPs map[string]bool
+	a.cacheMu.Lock()
 	if cached, ok := a.validationCache[fingerprint]; ok {
 		if time.Now().Before(cached.expiresAt) {
-			a.logger.Debug("API key validation cache hit", zap.String("fingerprint", fingerprint[:8]))
-			return cached.result, nil
+			if cached.reported(clientIP) {
+				a.cacheMu.Unlock()
+				a.cacheHits.Add(1)
+				a.logger.Debug("API key validation cache hit", zap.String("fingerprint", fingerprint[:8]))
+				return cached.result, nil
+			}
+			// Revalidate so user-org sees the new client IP (security event detection)
+			reportedIPs = cached.clientIPs
+		} else {
+			// Cache expired, remove it
+			delete(a.validationCache, fingerprint)
+			a.logger.Debug("API key validation cache expired", zap.String("fingerprint", fingerprint[:8]))
 		}
-		// Cache expired, remove it
-		delete(a.validationCache, fingerprint)
-		a.logger.Debug("API key validation cache expired", zap.String("fingerprint", fingerprint[:8]))
 	}
+	a.cacheMu.Unlock()
+	a.cacheMisses.Add(1)
 
 	// Fallback to stub for dev/test keys (for local development)
 	if strings.HasPrefix(apiKey, "dev-") || strings.HasPrefix(apiKey, "test-") {
@@ -141,6 +326,9 @@ func (a *Authenticator) validateAPIKey(apiKey string) (*AuthenticatedContext, er
 	reqBody := map[string]string{
 		"apiKeySecret": apiKey,
 	}
+	if clientIP != "" {
+		reqBody["clientIp"] = clientIP
+	}
 	reqJSON, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -169,14 +357,25 @@ func (a *Authenticator) validateAPIKey(apiKey string) (*AuthenticatedContext, er
 	}
 
 	var validationResp struct {
-		Valid          bool     `json:"valid"`
-		APIKeyID       string   `json:"apiKeyId"`
-		OrganizationID string   `json:"organizationId"`
-		PrincipalID    string   `json:"principalId"`
-		PrincipalType  string   `json:"principalType"`
-		Scopes         []string `json:"scopes"`
-		Status         string   `json:"status"`
-		Message        string   `json:"message"`
+		Valid          bool                 `json:"valid"`
+		APIKeyID       string               `json:"apiKeyId"`
+		OrganizationID string               `json:"organizationId"`
+		PrincipalID    string               `json:"principalId"`
+		PrincipalType  string               `json:"principalType"`
+		Scopes         []string             `json:"scopes"`
+		Status         string               `json:"status"`
+		Message        string               `json:"message"`
+		Restrictions   *restrictionsPayload `json:"restrictions"`
+		AllowedRegions []string             `json:"allowedRegions"`
+		Archival       *ArchivalPolicy      `json:"archival"`
+		ToolLimits     *ToolLimits          `json:"toolLimits"`
+		OrgModels      *ModelEntitlements   `json:"modelEntitlements"`
+		KeyModels      *ModelEntitlements   `json:"keyEntitlements"`
+		RateLimits     *RateLimits          `json:"rateLimits"`
+		ProjectID      string               `json:"projectId"`
+		UsagePayloads  *UsagePayloadPolicy  `json:"usagePayloads"`
+		CORSOrigins    []string             `json:"corsOrigins"`
+		LogExport      *LogExportPolicy     `json:"logExport"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&validationResp); err != nil {
@@ -187,6 +386,12 @@ func (a *Authenticator) validateAPIKey(apiKey string) (*AuthenticatedContext, er
 		return nil, fmt.Errorf("invalid API key: %s", validationResp.Message)
 	}
 
+	// Fail closed: a key whose restrictions cannot be enforced is not usable
+	restrictions, err := parseRestrictions(validationResp.Restrictions)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build authenticated context
 	ctx := &AuthenticatedContext{
 		APIKeyID:       validationResp.APIKeyID,
@@ -194,17 +399,90 @@ func (a *Authenticator) validateAPIKey(apiKey string) (*AuthenticatedContext, er
 		PrincipalID:    validationResp.PrincipalID,
 		PrincipalType:  validationResp.PrincipalType,
 		Scopes:         validationResp.Scopes,
+		Restrictions:   restrictions,
+		AllowedRegions: validationResp.AllowedRegions,
+		Archival:       validationResp.Archival,
+		ToolLimits:     validationResp.ToolLimits,
+		OrgModels:      validationResp.OrgModels,
+		KeyModels:      validationResp.KeyModels,
+		RateLimits:     validationResp.RateLimits,
+		ProjectID:      validationResp.ProjectID,
+		UsagePayloads:  validationResp.UsagePayloads,
+		CORSOrigins:    validationResp.CORSOrigins,
+		LogExport:      validationResp.LogExport,
 	}
 
 	// Cache the result for 1 minute
+	clientIPs := make(map[string]bool, len(reportedIPs)+1)
+	for ip := range reportedIPs {
+		clientIPs[ip] = true
+	}
+	if clientIP != "" {
+		clientIPs[clientIP] = true
+	}
+	a.cacheMu.Lock()
 	a.validationCache[fingerprint] = &cachedValidation{
 		result:    ctx,
 		expiresAt: time.Now().Add(1 * time.Minute),
+		clientIPs: clientIPs,
 	}
+	a.cacheMu.Unlock()
 
 	return ctx, nil
 }
 
+// CacheStats reports the number of cached validation results and cache hits
+// and misses since startup.
+func (a *Authenticator) CacheStats() (entries int, hits, misses uint64) {
+	a.cacheMu.Lock()
+	entries = len(a.validationCache)
+	a.cacheMu.Unlock()
+	return entries, a.cacheHits.Load(), a.cacheMisses.Load()
+}
+
+// FlushCache drops every cached validation result so the next request for
+// each key is revalidated against user-org-service, e.g. after a revocation
+// that must take effect before the 1 minute cache period ends.
+func (a *Authenticator) FlushCache(ctx context.Context) error {
+	a.cacheMu.Lock()
+	n := len(a.validationCache)
+	a.validationCache = make(map[string]*cachedValidation)
+	a.cacheMu.Unlock()
+	if a.tokenVerifier != nil {
+		n += a.tokenVerifier.Flush()
+	}
+
+	a.logger.Info("API key validation cache flushed", zap.Int("entries", n))
+	return nil
+}
+
+// InvalidateOrgs drops cached validation results for keys of the given orgs
+// or their projects, and cached platform access tokens of those orgs, e.g.
+// after user-org-service suspends them, and returns how many were dropped.
+func (a *Authenticator) InvalidateOrgs(ctx context.Context, orgIDs []string) (int, error) {
+	ids := make(map[string]bool, len(orgIDs))
+	for _, id := range orgIDs {
+		ids[id] = true
+	}
+
+	a.cacheMu.Lock()
+	n := 0
+	for fingerprint, cached := range a.validationCache {
+		if ids[cached.result.OrganizationID] || (cached.result.ProjectID != "" && ids[cached.result.ProjectID]) {
+			delete(a.validationCache, fingerprint)
+			n++
+		}
+	}
+	a.cacheMu.Unlock()
+	// Platform access tokens of those orgs must be introspected again too
+	if a.tokenVerifier != nil {
+		n += a.tokenVerifier.InvalidateOrgs(ids)
+	}
+
+	a.logger.Info("API key validation cache invalidated for orgs", zap.Int("orgs", len(ids)), zap.Int("entries", n))
+	return n, nil
+}
+
 // validateAPIKeyStub validates an API key using a stub implementation for dev/test keys.
 func (a *Authenticator) validateAPIKeyStub(apiKey string) (*AuthenticatedContext, error) {
 	// Stub implementation for development
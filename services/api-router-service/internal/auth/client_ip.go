@@ -0,0 +1,105 @@
+// Package auth resolves the caller IP behind trusted reverse proxies.
+//
+// Purpose:
+//   Per-key CIDR allowlists, abuse detection and audit logs need the real
+//   caller IP. X-Forwarded-For and X-Real-IP are set by the client unless a
+//   proxy we run overwrote them, so they are only believed when the socket
+//   peer is one of our proxies (TRUSTED_PROXY_CIDRS).
+//
+// Debugging Notes:
+//   - With no trusted proxies configured, r.RemoteAddr stays the socket peer
+//     and forwarding headers are ignored
+//   - X-Forwarded-For is read right to left, skipping trusted proxies; the
+//     first other address is the caller, so entries a client prepends are
+//     never used
+//
+package auth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses CIDRs (or bare addresses) of the reverse proxies
+// allowed to set forwarding headers.
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		nets = append(nets, network)
+	}
+	return nets, nil
+}
+
+// RealIP replaces chi's middleware.RealIP: it rewrites r.RemoteAddr to the
+// forwarded caller IP only when the socket peer is a trusted proxy, so a
+// client cannot claim another address with X-Forwarded-For.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, trusted); ip != nil {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns the caller IP from the forwarding headers, or nil if
+// the peer is not trusted or the headers carry no usable address.
+func forwardedIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	peer := ClientIP(r)
+	if peer == nil || !containsIP(trusted, peer) {
+		return nil
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		var leftmost net.IP
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// A malformed hop was not written by our proxies; stop rather
+				// than trust anything to its left
+				return leftmost
+			}
+			leftmost = ip
+			if !containsIP(trusted, ip) {
+				return ip
+			}
+		}
+		// Every hop is one of our proxies
+		return leftmost
+	}
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, network := range nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
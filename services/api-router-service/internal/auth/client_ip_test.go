@@ -0,0 +1,111 @@
+// Package auth provides unit tests for caller IP resolution.
+//
+// Purpose:
+//   These tests validate that forwarding headers are ignored unless the socket
+//   peer is a trusted proxy, that entries a client prepends to X-Forwarded-For
+//   are skipped, and that a spoofed header cannot pass a CIDR allowlist.
+//
+package auth
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustTrustedProxies(t *testing.T, values ...string) []*net.IPNet {
+	t.Helper()
+	nets, err := ParseTrustedProxies(values)
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	return nets
+}
+
+// resolve runs RealIP and returns the RemoteAddr the next handler sees.
+func resolve(trusted []*net.IPNet, remoteAddr string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	var seen *http.Request
+	RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	return seen
+}
+
+func TestRealIP(t *testing.T) {
+	trusted := mustTrustedProxies(t, "10.0.0.0/8", "192.0.2.7")
+
+	cases := []struct {
+		name       string
+		trusted    []*net.IPNet
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"no proxies configured ignores XFF", nil, "203.0.113.5:5000", map[string]string{"X-Forwarded-For": "10.1.1.1"}, "203.0.113.5:5000"},
+		{"untrusted peer ignores XFF", trusted, "203.0.113.5:5000", map[string]string{"X-Forwarded-For": "10.1.1.1"}, "203.0.113.5:5000"},
+		{"untrusted peer ignores X-Real-IP", trusted, "203.0.113.5:5000", map[string]string{"X-Real-IP": "10.1.1.1"}, "203.0.113.5:5000"},
+		{"trusted peer uses XFF", trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"}, "198.51.100.9"},
+		{"trusted peer skips client-prepended hops", trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "10.9.9.9, 198.51.100.9"}, "198.51.100.9"},
+		{"trusted peer skips trusted hops", trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9, 192.0.2.7, 10.0.0.3"}, "198.51.100.9"},
+		{"all hops trusted uses leftmost", trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"}, "10.0.0.4"},
+		{"malformed hop stops the walk", trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9, garbage, 10.0.0.3"}, "10.0.0.3"},
+		{"trusted peer uses X-Real-IP", trusted, "192.0.2.7:5000", map[string]string{"X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+		{"trusted peer without headers keeps peer", trusted, "10.0.0.2:5000", nil, "10.0.0.2:5000"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resolve(tc.trusted, tc.remoteAddr, tc.headers)
+			if got.RemoteAddr != tc.want {
+				t.Fatalf("RemoteAddr = %q, want %q", got.RemoteAddr, tc.want)
+			}
+		})
+	}
+}
+
+func TestSpoofedForwardedForDoesNotPassAllowlist(t *testing.T) {
+	restrictions := mustRestrictions(t, &restrictionsPayload{AllowedCIDRs: []string{"198.51.100.0/24"}})
+	trusted := mustTrustedProxies(t, "10.0.0.0/8")
+
+	// Direct caller claiming an allowed address
+	req := resolve(trusted, "203.0.113.5:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"})
+	if reason, _ := restrictions.Check(req); reason != DenialIPNotAllowed {
+		t.Fatalf("spoofed XFF from untrusted peer: reason = %q, want %s", reason, DenialIPNotAllowed)
+	}
+
+	// Caller behind our proxy prepending an allowed address
+	req = resolve(trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9, 203.0.113.5"})
+	if reason, _ := restrictions.Check(req); reason != DenialIPNotAllowed {
+		t.Fatalf("spoofed XFF behind trusted proxy: reason = %q, want %s", reason, DenialIPNotAllowed)
+	}
+
+	// Genuine allowed caller behind our proxy
+	req = resolve(trusted, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"})
+	if reason, err := restrictions.Check(req); reason != "" {
+		t.Fatalf("allowed caller denied: %s %v", reason, err)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets := mustTrustedProxies(t, " 10.0.0.0/8 ", "", "192.0.2.7", "2001:db8::1")
+	if len(nets) != 3 {
+		t.Fatalf("got %d networks, want 3", len(nets))
+	}
+	if ones, bits := nets[1].Mask.Size(); ones != 32 || bits != 32 {
+		t.Fatalf("bare IPv4 mask = /%d of %d, want /32", ones, bits)
+	}
+	if ones, bits := nets[2].Mask.Size(); ones != 128 || bits != 128 {
+		t.Fatalf("bare IPv6 mask = /%d of %d, want /128", ones, bits)
+	}
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+	if _, err := ParseTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Fatal("expected error for hostname")
+	}
+}
@@ -0,0 +1,63 @@
+// Package auth provides unit tests for model entitlements.
+//
+// Purpose:
+//   These tests validate exact and prefix matching, denylist precedence, and
+//   that API key lists narrow the org's lists with errors naming the entitlement.
+//
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckModelUnrestricted(t *testing.T) {
+	ctx := &AuthenticatedContext{}
+	if err := ctx.CheckModel("gpt-4o"); err != nil {
+		t.Fatalf("expected every model allowed, got %v", err)
+	}
+}
+
+func TestCheckModelOrgLists(t *testing.T) {
+	ctx := &AuthenticatedContext{OrgModels: &ModelEntitlements{
+		AllowedModels: []string{"gpt-4*", "llama-3"},
+		DeniedModels:  []string{"gpt-4-32k"},
+	}}
+
+	for _, model := range []string{"gpt-4o", "gpt-4", "llama-3"} {
+		if !ctx.ModelAllowed(model) {
+			t.Errorf("expected %q allowed", model)
+		}
+	}
+
+	cases := map[string]string{
+		"gpt-4-32k": `model "gpt-4-32k" is denied by the organization's model denylist`,
+		"llama-3.1": `model "llama-3.1" is not in the organization's model allowlist`,
+	}
+	for model, want := range cases {
+		err := ctx.CheckModel(model)
+		var entErr *EntitlementError
+		if !errors.As(err, &entErr) || err.Error() != want {
+			t.Errorf("model %q: expected %q, got %v", model, want, err)
+		}
+	}
+}
+
+func TestCheckModelKeyNarrowsOrg(t *testing.T) {
+	ctx := &AuthenticatedContext{
+		OrgModels: &ModelEntitlements{AllowedModels: []string{"gpt-4*"}},
+		KeyModels: &ModelEntitlements{AllowedModels: []string{"gpt-4o", "llama-3"}},
+	}
+
+	if err := ctx.CheckModel("gpt-4o"); err != nil {
+		t.Fatalf("expected gpt-4o allowed, got %v", err)
+	}
+	var entErr *EntitlementError
+	if err := ctx.CheckModel("gpt-4-turbo"); !errors.As(err, &entErr) || entErr.Scope != "API key" {
+		t.Fatalf("expected API key allowlist rejection, got %v", err)
+	}
+	// The key cannot widen the org's allowlist
+	if err := ctx.CheckModel("llama-3"); !errors.As(err, &entErr) || entErr.Scope != "organization" {
+		t.Fatalf("expected organization allowlist rejection, got %v", err)
+	}
+}
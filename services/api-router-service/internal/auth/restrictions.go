@@ -0,0 +1,107 @@
+// Package auth provides network restrictions for API keys.
+//
+// Purpose:
+//   Org admins can pin an API key to CIDR allowlists and required header
+//   values (e.g. Referer). user-org-service returns them with the key
+//   validation result; this file parses and enforces them.
+//
+// Debugging Notes:
+//   - The caller IP is r.RemoteAddr: the socket peer, or the forwarded
+//     address when the peer is a trusted proxy (see RealIP in client_ip.go)
+//   - Required header values ending in "*" match by prefix
+//   - Restriction changes apply once the validation cache entry expires (1 minute)
+//
+package auth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Denial reasons reported by Restrictions.Check.
+const (
+	DenialIPNotAllowed   = "IP_NOT_ALLOWED"
+	DenialHeaderMismatch = "HEADER_MISMATCH"
+)
+
+// Restrictions limits where an API key may be used from.
+type Restrictions struct {
+	AllowedNets     []*net.IPNet
+	RequiredHeaders map[string]string
+}
+
+// restrictionsPayload is the wire format returned by user-org-service.
+type restrictionsPayload struct {
+	AllowedCIDRs    []string          `json:"allowedCidrs"`
+	RequiredHeaders map[string]string `json:"requiredHeaders"`
+}
+
+// parseRestrictions converts the wire format, returning nil when nothing is restricted.
+func parseRestrictions(p *restrictionsPayload) (*Restrictions, error) {
+	if p == nil || (len(p.AllowedCIDRs) == 0 && len(p.RequiredHeaders) == 0) {
+		return nil, nil
+	}
+	restrictions := &Restrictions{RequiredHeaders: p.RequiredHeaders}
+	for _, cidr := range p.AllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed CIDR %q: %w", cidr, err)
+		}
+		restrictions.AllowedNets = append(restrictions.AllowedNets, network)
+	}
+	return restrictions, nil
+}
+
+// Check reports whether r satisfies the restrictions. On denial it returns one
+// of the Denial* reasons and a descriptive error.
+func (s *Restrictions) Check(r *http.Request) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+
+	if len(s.AllowedNets) > 0 {
+		ip := ClientIP(r)
+		if ip == nil || !s.allowsIP(ip) {
+			return DenialIPNotAllowed, fmt.Errorf("client IP %s is not allowed for this API key", r.RemoteAddr)
+		}
+	}
+
+	for name, want := range s.RequiredHeaders {
+		got := r.Header.Get(name)
+		if !headerMatches(got, want) {
+			return DenialHeaderMismatch, fmt.Errorf("header %s does not match the value required for this API key", name)
+		}
+	}
+	return "", nil
+}
+
+func (s *Restrictions) allowsIP(ip net.IP) bool {
+	for _, network := range s.AllowedNets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func headerMatches(got, want string) bool {
+	if got == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(want, "*"); ok {
+		return strings.HasPrefix(got, prefix)
+	}
+	return got == want
+}
+
+// ClientIP returns the caller IP from r.RemoteAddr, which may or may not carry
+// a port (RealIP rewrites it without one).
+func ClientIP(r *http.Request) net.IP {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
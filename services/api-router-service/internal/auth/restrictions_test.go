@@ -0,0 +1,88 @@
+// Package auth provides unit tests for API key network restrictions.
+//
+// Purpose:
+//   These tests validate CIDR allowlist matching for callers with and without
+//   a port in RemoteAddr, exact and prefix header matching, and fail-closed
+//   parsing of malformed CIDRs.
+//
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustRestrictions(t *testing.T, p *restrictionsPayload) *Restrictions {
+	t.Helper()
+	r, err := parseRestrictions(p)
+	if err != nil {
+		t.Fatalf("parseRestrictions: %v", err)
+	}
+	return r
+}
+
+func TestRestrictionsCIDRAllowlist(t *testing.T) {
+	r := mustRestrictions(t, &restrictionsPayload{AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}})
+
+	cases := []struct {
+		remoteAddr string
+		allowed    bool
+	}{
+		{"10.1.2.3:4567", true},
+		{"10.1.2.3", true}, // RealIP rewrites RemoteAddr without a port
+		{"[2001:db8::1]:443", true},
+		{"192.168.1.1:1234", false},
+		{"not-an-ip", false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req.RemoteAddr = tc.remoteAddr
+		reason, err := r.Check(req)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: expected allowed, got %v", tc.remoteAddr, err)
+		}
+		if !tc.allowed && reason != DenialIPNotAllowed {
+			t.Errorf("%s: expected %s, got %q", tc.remoteAddr, DenialIPNotAllowed, reason)
+		}
+	}
+}
+
+func TestRestrictionsRequiredHeaders(t *testing.T) {
+	r := mustRestrictions(t, &restrictionsPayload{RequiredHeaders: map[string]string{
+		"Referer":   "https://app.example.com/*",
+		"X-Env-Tag": "prod",
+	}})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Referer", "https://app.example.com/chat")
+	req.Header.Set("X-Env-Tag", "prod")
+	if _, err := r.Check(req); err != nil {
+		t.Fatalf("expected allowed, got %v", err)
+	}
+
+	req.Header.Set("X-Env-Tag", "staging")
+	if reason, _ := r.Check(req); reason != DenialHeaderMismatch {
+		t.Fatalf("expected %s for wrong value, got %q", DenialHeaderMismatch, reason)
+	}
+
+	req.Header.Set("X-Env-Tag", "prod")
+	req.Header.Del("Referer")
+	if reason, _ := r.Check(req); reason != DenialHeaderMismatch {
+		t.Fatalf("expected %s for missing header, got %q", DenialHeaderMismatch, reason)
+	}
+}
+
+func TestParseRestrictions(t *testing.T) {
+	if r := mustRestrictions(t, &restrictionsPayload{}); r != nil {
+		t.Fatalf("expected nil restrictions for empty payload, got %+v", r)
+	}
+	if _, err := parseRestrictions(&restrictionsPayload{AllowedCIDRs: []string{"10.0.0.0/33"}}); err == nil {
+		t.Fatal("expected error for malformed CIDR")
+	}
+
+	var unrestricted *Restrictions
+	if _, err := unrestricted.Check(httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("nil restrictions must allow everything, got %v", err)
+	}
+}
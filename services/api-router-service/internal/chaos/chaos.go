@@ -0,0 +1,270 @@
+// Package chaos provides fault injection for development environments.
+//
+// Purpose:
+//   This package injects latency, 5xx errors, and dropped connections into
+//   incoming requests (per route) and backend calls (per backend) at
+//   configurable rates, so client retries, failover, and circuit breakers can
+//   be exercised without breaking real infrastructure.
+//
+// Key Responsibilities:
+//   - Hold the active fault set, replaceable at runtime via the admin API
+//   - Middleware injects faults into matching incoming requests
+//   - InjectBackend injects faults into backend calls made by BackendClient
+//
+// Debugging Notes:
+//   - Only enabled when CHAOS_ENABLED=true and ENVIRONMENT is not production
+//   - The first matching fault applies; order faults from specific to general
+//   - Faults with a Backend only apply to backend calls, never to incoming requests
+//   - /v1/admin/* is never faulted so faults can always be cleared
+//   - Dropped connections abort the response (http.ErrAbortHandler) without
+//     writing a status, so clients observe a reset/EOF
+//
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// exemptPrefix is never faulted so the admin API stays usable.
+const exemptPrefix = "/v1/admin/"
+
+// ErrInjectedDrop is returned for backend calls whose connection was dropped.
+var ErrInjectedDrop = errors.New("chaos: injected connection drop")
+
+// Fault describes one fault injection rule.
+type Fault struct {
+	ID string `json:"id"`
+	// Route matches incoming request paths by prefix; empty matches all.
+	Route string `json:"route,omitempty"`
+	// Backend scopes the fault to calls to this backend ID instead of incoming requests.
+	Backend string `json:"backend,omitempty"`
+	// LatencyMs is added to LatencyRate of matching requests.
+	LatencyMs   int     `json:"latency_ms,omitempty"`
+	LatencyRate float64 `json:"latency_rate,omitempty"`
+	// ErrorRate of matching requests fail with ErrorStatus (default 503).
+	ErrorRate   float64 `json:"error_rate,omitempty"`
+	ErrorStatus int     `json:"error_status,omitempty"`
+	// DropRate of matching requests have their connection dropped.
+	DropRate float64 `json:"drop_rate,omitempty"`
+}
+
+// FaultStatus is a fault plus how many times it has fired.
+type FaultStatus struct {
+	Fault
+	Injected uint64 `json:"injected"`
+}
+
+type activeFault struct {
+	Fault
+	injected atomic.Uint64
+}
+
+// Injector holds the active faults. It is safe for concurrent use.
+type Injector struct {
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	faults []*activeFault
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// NewInjector creates an injector with no faults.
+func NewInjector(logger *zap.Logger) *Injector {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Injector{
+		logger: logger,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Allowed reports whether fault injection may run in the given environment.
+func Allowed(environment string) bool {
+	switch strings.ToLower(strings.TrimSpace(environment)) {
+	case "prod", "production":
+		return false
+	}
+	return true
+}
+
+// Validate checks rates and status codes and fills in defaults.
+func (f *Fault) Validate() error {
+	for name, rate := range map[string]float64{"latency_rate": f.LatencyRate, "error_rate": f.ErrorRate, "drop_rate": f.DropRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if f.LatencyMs < 0 {
+		return fmt.Errorf("latency_ms must not be negative")
+	}
+	if f.ErrorStatus == 0 {
+		f.ErrorStatus = http.StatusServiceUnavailable
+	}
+	if f.ErrorStatus < 500 || f.ErrorStatus > 599 {
+		return fmt.Errorf("error_status must be a 5xx code")
+	}
+	return nil
+}
+
+// SetFaults replaces the active faults. Faults without an ID are numbered.
+func (i *Injector) SetFaults(faults []Fault) error {
+	active := make([]*activeFault, 0, len(faults))
+	for n, f := range faults {
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("fault %d: %w", n, err)
+		}
+		if f.ID == "" {
+			f.ID = fmt.Sprintf("fault-%d", n+1)
+		}
+		active = append(active, &activeFault{Fault: f})
+	}
+
+	i.mu.Lock()
+	i.faults = active
+	i.mu.Unlock()
+
+	i.logger.Warn("chaos faults updated", zap.Int("count", len(active)))
+	return nil
+}
+
+// Faults returns the active faults and their injection counts.
+func (i *Injector) Faults() []FaultStatus {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	out := make([]FaultStatus, 0, len(i.faults))
+	for _, f := range i.faults {
+		out = append(out, FaultStatus{Fault: f.Fault, Injected: f.injected.Load()})
+	}
+	return out
+}
+
+// Clear removes all faults.
+func (i *Injector) Clear() {
+	i.mu.Lock()
+	i.faults = nil
+	i.mu.Unlock()
+	i.logger.Warn("chaos faults cleared")
+}
+
+// Middleware injects route faults into incoming requests.
+func (i *Injector) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, exemptPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			f := i.match(func(f *activeFault) bool {
+				return f.Backend == "" && strings.HasPrefix(r.URL.Path, f.Route)
+			})
+			if f == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch i.apply(r.Context(), f) {
+			case outcomeDrop:
+				panic(http.ErrAbortHandler)
+			case outcomeError:
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Chaos-Fault", f.ID)
+				w.WriteHeader(f.ErrorStatus)
+				fmt.Fprintf(w, `{"error":"chaos: injected fault","code":"CHAOS_FAULT","fault_id":%q}`+"\n", f.ID)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// InjectBackend applies backend faults to a call to backendID, returning a
+// non-nil error when the call should fail instead of being sent.
+func (i *Injector) InjectBackend(ctx context.Context, backendID string) error {
+	f := i.match(func(f *activeFault) bool { return f.Backend == backendID })
+	if f == nil {
+		return nil
+	}
+	switch i.apply(ctx, f) {
+	case outcomeDrop:
+		return fmt.Errorf("backend request failed: %w", ErrInjectedDrop)
+	case outcomeError:
+		return fmt.Errorf("backend returned status %d: chaos: injected fault %s", f.ErrorStatus, f.ID)
+	}
+	return ctx.Err()
+}
+
+type outcome int
+
+const (
+	outcomePass outcome = iota
+	outcomeError
+	outcomeDrop
+)
+
+func (i *Injector) match(pred func(*activeFault) bool) *activeFault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, f := range i.faults {
+		if pred(f) {
+			return f
+		}
+	}
+	return nil
+}
+
+// apply sleeps for injected latency, then decides whether to drop or fail.
+func (i *Injector) apply(ctx context.Context, f *activeFault) outcome {
+	injected := false
+	if f.LatencyMs > 0 && i.roll(f.LatencyRate) {
+		injected = true
+		timer := time.NewTimer(time.Duration(f.LatencyMs) * time.Millisecond)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	result := outcomePass
+	switch {
+	case i.roll(f.DropRate):
+		result = outcomeDrop
+	case i.roll(f.ErrorRate):
+		result = outcomeError
+	}
+	if injected || result != outcomePass {
+		f.injected.Add(1)
+		i.logger.Debug("chaos fault injected",
+			zap.String("fault_id", f.ID),
+			zap.Bool("latency", injected),
+			zap.Bool("error", result == outcomeError),
+			zap.Bool("drop", result == outcomeDrop),
+		)
+	}
+	return result
+}
+
+func (i *Injector) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	i.rngMu.Lock()
+	defer i.rngMu.Unlock()
+	return i.rng.Float64() < rate
+}
@@ -0,0 +1,117 @@
+// Package chaos provides unit tests for fault injection.
+//
+// Purpose:
+//   These tests validate fault matching by route and backend, error and drop
+//   injection, the admin path exemption, and fault validation.
+//
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, i *Injector, path string) (rr *httptest.ResponseRecorder, aborted bool) {
+	t.Helper()
+	handler := i.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr = httptest.NewRecorder()
+	defer func() {
+		if rec := recover(); rec != nil {
+			if rec != http.ErrAbortHandler {
+				panic(rec)
+			}
+			aborted = true
+		}
+	}()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+	return rr, false
+}
+
+func TestMiddlewareInjectsRouteFaults(t *testing.T) {
+	i := NewInjector(nil)
+	if err := i.SetFaults([]Fault{
+		{Route: "/v1/chat", ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+		{Route: "/v1/completions", DropRate: 1},
+		{Backend: "backend-1", ErrorRate: 1},
+		{ErrorRate: 1},
+	}); err != nil {
+		t.Fatalf("set faults: %v", err)
+	}
+
+	rr, _ := serve(t, i, "/v1/chat/completions")
+	if rr.Code != http.StatusBadGateway || rr.Header().Get("X-Chaos-Fault") != "fault-1" {
+		t.Fatalf("expected injected 502 from fault-1, got %d %q", rr.Code, rr.Header().Get("X-Chaos-Fault"))
+	}
+	if _, aborted := serve(t, i, "/v1/completions"); !aborted {
+		t.Fatal("expected dropped connection")
+	}
+	if rr, _ := serve(t, i, "/v1/inference"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected catch-all fault with default 503, got %d", rr.Code)
+	}
+	if rr, _ := serve(t, i, "/v1/admin/chaos/faults"); rr.Code != http.StatusOK {
+		t.Fatalf("expected admin routes to be exempt, got %d", rr.Code)
+	}
+
+	faults := i.Faults()
+	if faults[0].Injected != 1 || faults[2].Injected != 0 || faults[3].Injected != 1 {
+		t.Fatalf("unexpected injection counts: %+v", faults)
+	}
+
+	i.Clear()
+	if rr, _ := serve(t, i, "/v1/chat/completions"); rr.Code != http.StatusOK {
+		t.Fatalf("expected no faults after clear, got %d", rr.Code)
+	}
+}
+
+func TestInjectBackend(t *testing.T) {
+	i := NewInjector(nil)
+	if err := i.SetFaults([]Fault{
+		{Backend: "slow", LatencyMs: 20, LatencyRate: 1},
+		{Backend: "flaky", ErrorRate: 1, ErrorStatus: 500},
+		{Backend: "dropped", DropRate: 1},
+	}); err != nil {
+		t.Fatalf("set faults: %v", err)
+	}
+	ctx := context.Background()
+
+	start := time.Now()
+	if err := i.InjectBackend(ctx, "slow"); err != nil {
+		t.Fatalf("expected latency only, got %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected injected latency")
+	}
+	if err := i.InjectBackend(ctx, "flaky"); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Fatalf("expected injected 500, got %v", err)
+	}
+	if err := i.InjectBackend(ctx, "dropped"); !errors.Is(err, ErrInjectedDrop) {
+		t.Fatalf("expected injected drop, got %v", err)
+	}
+	if err := i.InjectBackend(ctx, "healthy"); err != nil {
+		t.Fatalf("expected no fault for unmatched backend, got %v", err)
+	}
+}
+
+func TestFaultValidation(t *testing.T) {
+	i := NewInjector(nil)
+	for _, f := range []Fault{
+		{ErrorRate: 1.5},
+		{DropRate: -0.1},
+		{LatencyMs: -1},
+		{ErrorRate: 1, ErrorStatus: 404},
+	} {
+		if err := i.SetFaults([]Fault{f}); err == nil {
+			t.Fatalf("expected %+v to be rejected", f)
+		}
+	}
+	if !Allowed("development") || Allowed("Production") || Allowed("prod") {
+		t.Fatal("expected fault injection to be blocked in production only")
+	}
+}
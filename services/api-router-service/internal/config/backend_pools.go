@@ -0,0 +1,104 @@
+// Package config provides parsing of per-backend connection pool settings.
+//
+// Purpose:
+//   Each backend gets its own HTTP transport so a slow or chatty backend
+//   cannot exhaust the idle connections of the others. BACKEND_POOLS tunes
+//   pool size, keepalive, HTTP/2 and how often the backend's hostname is
+//   re-resolved, so pods behind a headless Service or DNS failover are picked
+//   up without waiting for idle connections to time out.
+//
+// Debugging Notes:
+//   - BACKEND_POOLS is a JSON object keyed by backend ID, "*" applies to
+//     backends without their own entry, e.g.
+//     {"*":{"maxIdleConns":64},"gpu-a":{"http2":false,"dnsRefreshMs":10000}}
+//   - When a re-resolved hostname returns a different IP set the backend's
+//     connections are recycled (api_router_backend_pool_recycles_total)
+//   - dnsRefreshMs of 0 disables re-resolution; IP literals are never re-resolved
+//
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackendPoolSpec is the wire format of one backend's pool settings.
+type BackendPoolSpec struct {
+	MaxIdleConns      int   `json:"maxIdleConns,omitempty"`
+	MaxConns          int   `json:"maxConns,omitempty"` // 0 is unlimited
+	IdleConnTimeoutMS int   `json:"idleConnTimeoutMs,omitempty"`
+	KeepAliveMS       int   `json:"keepAliveMs,omitempty"`
+	HTTP2             *bool `json:"http2,omitempty"`
+	DNSRefreshMS      *int  `json:"dnsRefreshMs,omitempty"`
+}
+
+// BackendPoolConfig is a backend's resolved pool settings.
+type BackendPoolConfig struct {
+	MaxIdleConns    int
+	MaxConns        int // Zero is unlimited
+	IdleConnTimeout time.Duration
+	KeepAlive       time.Duration
+	HTTP2           bool
+	DNSRefresh      time.Duration // Zero disables re-resolution
+}
+
+// DefaultBackendPool returns the pool used for backends BACKEND_POOLS does not mention.
+func DefaultBackendPool() BackendPoolConfig {
+	return BackendPoolConfig{
+		MaxIdleConns:    32,
+		IdleConnTimeout: 90 * time.Second,
+		KeepAlive:       30 * time.Second,
+		HTTP2:           true,
+		DNSRefresh:      30 * time.Second,
+	}
+}
+
+// Config resolves the spec, filling unset fields from DefaultBackendPool.
+func (s BackendPoolSpec) Config() (BackendPoolConfig, error) {
+	pool := DefaultBackendPool()
+	if s.MaxIdleConns < 0 || s.MaxConns < 0 || s.IdleConnTimeoutMS < 0 || s.KeepAliveMS < 0 {
+		return BackendPoolConfig{}, fmt.Errorf("maxIdleConns, maxConns, idleConnTimeoutMs and keepAliveMs must not be negative")
+	}
+	if s.MaxIdleConns > 0 {
+		pool.MaxIdleConns = s.MaxIdleConns
+	}
+	pool.MaxConns = s.MaxConns
+	if s.IdleConnTimeoutMS > 0 {
+		pool.IdleConnTimeout = time.Duration(s.IdleConnTimeoutMS) * time.Millisecond
+	}
+	if s.KeepAliveMS > 0 {
+		pool.KeepAlive = time.Duration(s.KeepAliveMS) * time.Millisecond
+	}
+	if s.HTTP2 != nil {
+		pool.HTTP2 = *s.HTTP2
+	}
+	if s.DNSRefreshMS != nil {
+		if *s.DNSRefreshMS < 0 {
+			return BackendPoolConfig{}, fmt.Errorf("dnsRefreshMs must not be negative")
+		}
+		pool.DNSRefresh = time.Duration(*s.DNSRefreshMS) * time.Millisecond
+	}
+	return pool, nil
+}
+
+// ParseBackendPools parses the BACKEND_POOLS JSON object.
+func ParseBackendPools(raw string) (map[string]BackendPoolConfig, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var specs map[string]BackendPoolSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return nil, fmt.Errorf("parse backend pools: %w", err)
+	}
+	pools := make(map[string]BackendPoolConfig, len(specs))
+	for backendID, spec := range specs {
+		pool, err := spec.Config()
+		if err != nil {
+			return nil, fmt.Errorf("parse backend pools: %s: %w", backendID, err)
+		}
+		pools[strings.TrimSpace(backendID)] = pool
+	}
+	return pools, nil
+}
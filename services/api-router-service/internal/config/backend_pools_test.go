@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBackendPools(t *testing.T) {
+	pools, err := ParseBackendPools(`{"*":{"maxIdleConns":64},"gpu-a":{"maxConns":16,"keepAliveMs":15000,"http2":false,"dnsRefreshMs":0}}`)
+	if err != nil {
+		t.Fatalf("ParseBackendPools: %v", err)
+	}
+	defaults := DefaultBackendPool()
+	if got := pools["*"]; got.MaxIdleConns != 64 || !got.HTTP2 || got.DNSRefresh != defaults.DNSRefresh {
+		t.Fatalf("unexpected default pool %+v", got)
+	}
+	got := pools["gpu-a"]
+	if got.MaxIdleConns != defaults.MaxIdleConns || got.MaxConns != 16 || got.KeepAlive != 15*time.Second || got.HTTP2 || got.DNSRefresh != 0 {
+		t.Fatalf("unexpected gpu-a pool %+v", got)
+	}
+
+	if pools, err := ParseBackendPools(" "); pools != nil || err != nil {
+		t.Fatalf("expected no pools, got %v, %v", pools, err)
+	}
+	if _, err := ParseBackendPools(`{"gpu-a":{"maxConns":-1}}`); err == nil {
+		t.Fatal("expected error for negative maxConns")
+	}
+	if _, err := ParseBackendPools(`{"gpu-a":{"dnsRefreshMs":-1}}`); err == nil {
+		t.Fatal("expected error for negative dnsRefreshMs")
+	}
+}
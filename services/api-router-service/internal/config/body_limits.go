@@ -0,0 +1,43 @@
+// Package config provides parsing of request body size limits.
+//
+// Purpose:
+//   Long-context prompts and embedding batches need far larger request
+//   bodies than the admin API. ROUTE_BODY_LIMITS and TIER_BODY_LIMITS cap
+//   bodies per route prefix and per org plan tier; MAX_REQUEST_BODY_BYTES
+//   applies when neither matches.
+//
+// Debugging Notes:
+//   - Both settings are "key=bytes,..." e.g.
+//     ROUTE_BODY_LIMITS="/v1/embeddings=16777216,/v1/admin=65536"
+//     TIER_BODY_LIMITS="free=1048576,enterprise=33554432"
+//   - The longest matching route prefix wins
+//
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseByteLimits parses "key=bytes,..." into a map of positive limits.
+func ParseByteLimits(raw string) (map[string]int64, error) {
+	limits := make(map[string]int64)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("invalid byte limit %q", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid byte limit %q: must be a positive number of bytes", entry)
+		}
+		limits[key] = limit
+	}
+	return limits, nil
+}
@@ -0,0 +1,19 @@
+package config
+
+import "testing"
+
+func TestParseByteLimits(t *testing.T) {
+	limits, err := ParseByteLimits(" /v1/embeddings=16777216, enterprise = 1024 ,")
+	if err != nil {
+		t.Fatalf("ParseByteLimits: %v", err)
+	}
+	if limits["/v1/embeddings"] != 16777216 || limits["enterprise"] != 1024 || len(limits) != 2 {
+		t.Fatalf("unexpected limits %v", limits)
+	}
+
+	for _, raw := range []string{"/v1=0", "/v1=big", "=10", "/v1"} {
+		if _, err := ParseByteLimits(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
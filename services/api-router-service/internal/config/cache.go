@@ -19,6 +19,7 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -129,3 +130,41 @@ func cacheKey(organizationID, model string) string {
 	return fmt.Sprintf("%s:%s", organizationID, model)
 }
 
+
+// Len returns the number of cached policies.
+func (c *Cache) Len() (int, error) {
+	var n int
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("policies"))
+		if bucket == nil {
+			return fmt.Errorf("policies bucket not found")
+		}
+		n = bucket.Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// ReplacePolicies atomically replaces every cached policy with policies.
+func (c *Cache) ReplacePolicies(ctx context.Context, policies []*RoutingPolicy) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket([]byte("policies")); err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+			return fmt.Errorf("delete policies bucket: %w", err)
+		}
+		bucket, err := tx.CreateBucket([]byte("policies"))
+		if err != nil {
+			return fmt.Errorf("create policies bucket: %w", err)
+		}
+
+		for _, policy := range policies {
+			data, err := json.Marshal(policy)
+			if err != nil {
+				return fmt.Errorf("marshal policy: %w", err)
+			}
+			if err := bucket.Put([]byte(cacheKey(policy.OrganizationID, policy.Model)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateDefaults(t *testing.T) {
+	if err := validConfig(t).Validate(); err != nil {
+		t.Fatalf("expected defaults to validate, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.HTTPPort = 0
+	cfg.UserOrgServiceURL = "user-org:8081"
+	cfg.BackendEndpoints = ""
+	cfg.RateLimitAlgorithm = "leaky_bucket"
+	cfg.ArchiveEnabled = true
+	cfg.ArchiveEncryptionKey = ""
+	cfg.VaultAddr = "https://vault:8200"
+	cfg.BackendProtocols = "mock-backend-1=quic"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"HTTP_PORT", "USER_ORG_SERVICE_URL", "BACKEND_ENDPOINTS", "RATE_LIMIT_ALGORITHM", "ARCHIVE_ENABLED", "VAULT_ADDR", "BACKEND_PROTOCOLS"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected a %s problem in %q", want, err)
+		}
+	}
+}
+
+func TestBackendProtocols(t *testing.T) {
+	registry := NewBackendRegistry(&Config{
+		BackendEndpoints: "triton:http://triton:8001/vllm_model,vllm:http://vllm:8000/v1/completions",
+		BackendProtocols: "triton=GRPC,vllm=http2,unknown=grpc",
+	})
+	triton, err := registry.GetBackend("triton")
+	if err != nil || triton.Protocol != ProtocolGRPC {
+		t.Fatalf("expected triton to use grpc, got %+v, %v", triton, err)
+	}
+	vllm, err := registry.GetBackend("vllm")
+	if err != nil || vllm.Protocol != ProtocolHTTP2 {
+		t.Fatalf("expected vllm to use http2, got %+v, %v", vllm, err)
+	}
+
+	// Re-registering keeps the protocol
+	if err := registry.RegisterBackend("triton", "http://triton-2:8001/vllm_model", 0); err != nil {
+		t.Fatalf("RegisterBackend: %v", err)
+	}
+	if triton, _ := registry.GetBackend("triton"); triton.Protocol != ProtocolGRPC {
+		t.Fatalf("expected protocol to survive re-registration, got %q", triton.Protocol)
+	}
+}
@@ -0,0 +1,270 @@
+// Package config provides the egress allowlist for backend URIs.
+//
+// Purpose:
+//   Backend URIs come from configuration and from the federation admin API.
+//   Without a check, whoever can register a backend can make the router send
+//   requests (with prompts) to any address it can reach, such as the cloud
+//   metadata service or internal admin endpoints. The EgressPolicy limits
+//   backend URIs to allowed schemes, hosts and CIDRs.
+//
+// Debugging Notes:
+//   - Hosts match exactly or, written as *.example.com, any subdomain
+//   - With no allowed hosts or CIDRs every backend is rejected; development
+//     defaults to localhost and loopback (see Config.EgressPolicy)
+//   - Loopback, private (RFC 1918, ULA), shared and unspecified addresses are
+//     only reachable through EGRESS_ALLOWED_CIDRS, even for listed hosts;
+//     denied CIDRs (EGRESS_DENIED_CIDRS) always apply
+//   - Hostnames are resolved at registration and the policy is checked again
+//     on every dial (BackendClient.SetEgressPolicy), so a hostname that later
+//     resolves to a denied address is refused; with HTTP_PROXY set the proxy's
+//     address is what gets dialled and checked
+//   - Violations are rejected by BackendRegistry and reported by the caller
+//     as backend.egress_denied security events
+//
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// egressLookupTimeout bounds resolving a backend hostname.
+const egressLookupTimeout = 2 * time.Second
+
+// EgressViolation reports a backend URI rejected by the egress policy.
+type EgressViolation struct {
+	BackendID string
+	URI       string
+	Reason    string
+}
+
+func (v *EgressViolation) Error() string {
+	return fmt.Sprintf("backend %s uri %q is not allowed: %s", v.BackendID, v.URI, v.Reason)
+}
+
+// EgressPolicy decides which backend URIs the router may call.
+type EgressPolicy struct {
+	schemes map[string]bool
+	hosts   []string
+	allowed []netip.Prefix
+	denied  []netip.Prefix
+	lookup  func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewEgressPolicy builds a policy. Hosts are exact names or *.suffix
+// wildcards; CIDRs may be bare addresses.
+func NewEgressPolicy(schemes, hosts, allowedCIDRs, deniedCIDRs []string) (*EgressPolicy, error) {
+	p := &EgressPolicy{
+		schemes: make(map[string]bool),
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+	for _, scheme := range schemes {
+		if scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme != "" {
+			p.schemes[scheme] = true
+		}
+	}
+	if len(p.schemes) == 0 {
+		return nil, fmt.Errorf("egress policy: at least one scheme is required")
+	}
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			p.hosts = append(p.hosts, host)
+		}
+	}
+	var err error
+	if p.allowed, err = parsePrefixes(allowedCIDRs); err != nil {
+		return nil, fmt.Errorf("egress policy allowed CIDRs: %w", err)
+	}
+	if p.denied, err = parsePrefixes(deniedCIDRs); err != nil {
+		return nil, fmt.Errorf("egress policy denied CIDRs: %w", err)
+	}
+	return p, nil
+}
+
+// EgressPolicy builds the policy configured by the EGRESS_* variables. In
+// development an empty allowlist admits localhost and loopback addresses, so
+// the default mock backends work; elsewhere it admits nothing.
+func (c *Config) EgressPolicy() (*EgressPolicy, error) {
+	hosts, allowed := c.EgressAllowedHosts, c.EgressAllowedCIDRs
+	if c.Environment == "development" && len(nonEmpty(hosts)) == 0 && len(nonEmpty(allowed)) == 0 {
+		hosts, allowed = []string{"localhost"}, []string{"127.0.0.0/8", "::1"}
+	}
+	return NewEgressPolicy(c.EgressAllowedSchemes, hosts, allowed, c.EgressDeniedCIDRs)
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q", value)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Check returns an *EgressViolation if uri may not be called, or nil.
+func (p *EgressPolicy) Check(backendID, uri string) error {
+	if p == nil {
+		return nil
+	}
+	violation := func(format string, args ...interface{}) error {
+		return &EgressViolation{BackendID: backendID, URI: uri, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return violation("not an absolute URL")
+	}
+	if !p.schemes[strings.ToLower(u.Scheme)] {
+		return violation("scheme %q is not allowed", u.Scheme)
+	}
+	if len(p.hosts) == 0 && len(p.allowed) == 0 {
+		return violation("no egress allowlist is configured")
+	}
+	host := strings.ToLower(u.Hostname())
+	hostAllowed := p.hostAllowed(host)
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if reason := p.addrDenied(addr, hostAllowed); reason != "" {
+			return violation("address %s %s", addr.Unmap(), reason)
+		}
+		return nil
+	}
+
+	// Only allowed CIDRs can admit a hostname that is not listed
+	if !hostAllowed && len(p.allowed) == 0 {
+		return violation("host %q is not allowed", host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), egressLookupTimeout)
+	defer cancel()
+	addrs, err := p.lookup(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if !hostAllowed {
+			return violation("host %q could not be resolved", host)
+		}
+		// Checked again when dialled
+		return nil
+	}
+	for _, addr := range addrs {
+		if reason := p.addrDenied(addr, hostAllowed); reason != "" {
+			return violation("host %q resolves to %s, which %s", host, addr.Unmap(), reason)
+		}
+	}
+	return nil
+}
+
+// CheckDial returns an *EgressViolation if a backend whose URI names host may
+// not connect to address ("ip:port", as passed to net.Dialer.Control). It
+// catches hostnames that resolve differently at dial time than at
+// registration (DNS rebinding).
+func (p *EgressPolicy) CheckDial(backendID, host, address string) error {
+	if p == nil {
+		return nil
+	}
+	violation := func(format string, args ...interface{}) error {
+		return &EgressViolation{BackendID: backendID, URI: address, Reason: fmt.Sprintf(format, args...)}
+	}
+	if len(p.hosts) == 0 && len(p.allowed) == 0 {
+		return violation("no egress allowlist is configured")
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return violation("dial address is not an IP address")
+	}
+	hostAllowed := p.hostAllowed(strings.ToLower(host))
+	if reason := p.addrDenied(addrPort.Addr(), hostAllowed); reason != "" {
+		return violation("host %q dialled %s, which %s", host, addrPort.Addr().Unmap(), reason)
+	}
+	return nil
+}
+
+// addrDenied returns why addr may not be called, or "" if it may. hostAllowed
+// reports whether the URI's host is on the host allowlist, which admits any
+// public address but not internal ranges.
+func (p *EgressPolicy) addrDenied(addr netip.Addr, hostAllowed bool) string {
+	addr = addr.Unmap()
+	inAllowed := containsAddr(p.allowed, addr)
+	switch {
+	case containsAddr(p.denied, addr):
+		return "is in a denied range"
+	case isInternalAddr(addr) && !inAllowed:
+		return "is a loopback, private or unspecified address outside the allowed CIDRs"
+	case !hostAllowed && !inAllowed:
+		return "is not in an allowed range"
+	}
+	return ""
+}
+
+// internalRanges are loopback, private, shared and unspecified ranges. Only
+// allowed CIDRs can admit them; a listed hostname cannot.
+var internalRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("fc00::/7"),
+}
+
+func isInternalAddr(addr netip.Addr) bool {
+	return containsAddr(internalRanges, addr)
+}
+
+func (p *EgressPolicy) hostAllowed(host string) bool {
+	for _, pattern := range p.hosts {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
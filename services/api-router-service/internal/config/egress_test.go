@@ -0,0 +1,178 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func testEgressPolicy(t *testing.T, hosts, allowed, denied []string, resolved map[string]string) *EgressPolicy {
+	t.Helper()
+	policy, err := NewEgressPolicy([]string{"http", "https"}, hosts, allowed, denied)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	policy.lookup = func(_ context.Context, host string) ([]netip.Addr, error) {
+		addr, ok := resolved[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []netip.Addr{netip.MustParseAddr(addr)}, nil
+	}
+	return policy
+}
+
+func TestEgressPolicyCheck(t *testing.T) {
+	resolved := map[string]string{
+		"vllm.internal":     "10.0.0.5",
+		"metadata.internal": "169.254.169.254",
+		"api.partner.com":   "203.0.113.10",
+		"dns.example.com":   "127.0.0.1",
+	}
+	policy := testEgressPolicy(t,
+		[]string{"*.example.com", "api.partner.com"},
+		[]string{"10.0.0.0/8"},
+		[]string{"169.254.0.0/16"},
+		resolved,
+	)
+
+	tests := []struct {
+		uri     string
+		allowed bool
+	}{
+		{"https://eu.example.com/v1/completions", true},
+		{"https://example.com/v1/completions", false},
+		{"https://api.partner.com/v1", true},
+		{"http://vllm.internal:8000/v1/completions", true},
+		{"http://10.1.2.3:8000/v1", true},
+		{"http://[::ffff:10.1.2.3]:8000/v1", true},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://metadata.internal/latest", false},
+		{"http://192.168.1.10/v1", false},
+		{"http://dns.example.com/v1", false},
+		{"http://127.0.0.1:8000/v1", false},
+		{"http://[::1]:8000/v1", false},
+		{"http://unknown.internal/v1", false},
+		{"file:///etc/passwd", false},
+		{"gopher://eu.example.com/", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		err := policy.Check("backend-1", tt.uri)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: expected allowed, got %v", tt.uri, err)
+		}
+		if !tt.allowed {
+			var violation *EgressViolation
+			if !errors.As(err, &violation) {
+				t.Errorf("%s: expected EgressViolation, got %v", tt.uri, err)
+			}
+		}
+	}
+}
+
+func TestEgressPolicyEmptyAllowlist(t *testing.T) {
+	policy := testEgressPolicy(t, nil, nil, []string{"169.254.0.0/16"}, map[string]string{
+		"api.partner.com": "203.0.113.10",
+	})
+	for _, uri := range []string{"http://api.partner.com/v1", "http://203.0.113.10/v1", "http://localhost:8000/v1"} {
+		if err := policy.Check("b", uri); err == nil {
+			t.Errorf("%s: expected rejection without an allowlist", uri)
+		}
+	}
+	if err := policy.CheckDial("b", "api.partner.com", "203.0.113.10:443"); err == nil {
+		t.Error("expected dial rejection without an allowlist")
+	}
+}
+
+func TestEgressPolicyCheckDial(t *testing.T) {
+	policy := testEgressPolicy(t, []string{"*.example.com"}, []string{"10.0.0.0/8"}, []string{"169.254.0.0/16"}, nil)
+
+	tests := []struct {
+		host    string
+		address string
+		allowed bool
+	}{
+		{"eu.example.com", "203.0.113.10:443", true},
+		{"eu.example.com", "10.1.2.3:8000", true},
+		{"eu.example.com", "127.0.0.1:8000", false},
+		{"eu.example.com", "[::1]:8000", false},
+		{"eu.example.com", "192.168.1.10:80", false},
+		{"eu.example.com", "[::ffff:127.0.0.1]:80", false},
+		{"eu.example.com", "169.254.169.254:80", false},
+		{"vllm.internal", "10.0.0.5:8000", true},
+		{"vllm.internal", "203.0.113.10:443", false},
+		{"eu.example.com", "not-an-address", false},
+	}
+	for _, tt := range tests {
+		err := policy.CheckDial("backend-1", tt.host, tt.address)
+		if tt.allowed && err != nil {
+			t.Errorf("%s -> %s: expected allowed, got %v", tt.host, tt.address, err)
+		}
+		if !tt.allowed {
+			var violation *EgressViolation
+			if !errors.As(err, &violation) {
+				t.Errorf("%s -> %s: expected EgressViolation, got %v", tt.host, tt.address, err)
+			}
+		}
+	}
+}
+
+func TestConfigEgressPolicyDevelopmentDefault(t *testing.T) {
+	cfg := &Config{Environment: "development", EgressAllowedSchemes: []string{"http"}}
+	policy, err := cfg.EgressPolicy()
+	if err != nil {
+		t.Fatalf("EgressPolicy: %v", err)
+	}
+	if err := policy.Check("b", "http://localhost:8001/v1"); err != nil {
+		t.Fatalf("expected localhost allowed in development, got %v", err)
+	}
+	if err := policy.CheckDial("b", "localhost", "127.0.0.1:8001"); err != nil {
+		t.Fatalf("expected loopback dial allowed in development, got %v", err)
+	}
+
+	cfg.Environment = "production"
+	if policy, err = cfg.EgressPolicy(); err != nil {
+		t.Fatalf("EgressPolicy: %v", err)
+	}
+	if err := policy.Check("b", "http://localhost:8001/v1"); err == nil {
+		t.Fatal("expected localhost rejected outside development")
+	}
+}
+
+func TestNewEgressPolicyInvalid(t *testing.T) {
+	if _, err := NewEgressPolicy(nil, nil, nil, nil); err == nil {
+		t.Fatal("expected error without schemes")
+	}
+	if _, err := NewEgressPolicy([]string{"https"}, nil, []string{"10.0.0.0/33"}, nil); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
+
+func TestBackendRegistryEgress(t *testing.T) {
+	registry := NewBackendRegistry(&Config{
+		BackendEndpoints: "local:http://10.0.0.5:8000/v1/completions,metadata:http://169.254.169.254/latest",
+	})
+	policy := testEgressPolicy(t, nil, []string{"10.0.0.0/8", "169.254.0.0/16"}, []string{"169.254.0.0/16"}, nil)
+
+	violations := registry.SetEgressPolicy(policy)
+	if len(violations) != 1 || violations[0].BackendID != "metadata" {
+		t.Fatalf("expected metadata backend violation, got %+v", violations)
+	}
+	if _, err := registry.GetBackend("metadata"); err == nil {
+		t.Fatal("expected violating backend to be removed")
+	}
+	if _, err := registry.GetBackend("local"); err != nil {
+		t.Fatalf("expected local backend to remain: %v", err)
+	}
+
+	err := registry.RegisterFederatedBackend(BackendEndpointConfig{ID: "eu-a", URI: "http://169.254.169.254/", Region: "eu-west-1", Cluster: "eu-prod"})
+	var violation *EgressViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected EgressViolation for federated backend, got %v", err)
+	}
+	if _, err := registry.GetBackend("eu-a"); err == nil {
+		t.Fatal("expected rejected federated backend not to be registered")
+	}
+}
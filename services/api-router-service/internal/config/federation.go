@@ -0,0 +1,92 @@
+// Package config provides parsing of multi-cluster federation settings.
+//
+// Purpose:
+//   One router deployment can front inference fleets in other clusters and
+//   regions. FEDERATED_BACKENDS registers those backends at startup and
+//   FEDERATION_FAILOVER_RULES orders the regions tried when the local region
+//   cannot serve a request.
+//
+// Debugging Notes:
+//   - FEDERATED_BACKENDS is a JSON array, e.g.
+//     [{"id":"eu-a","uri":"https://eu.example.com/v1/completions","region":"eu-west-1","cluster":"eu-prod","timeoutMs":30000}]
+//   - FEDERATION_FAILOVER_RULES is "region=fallback1|fallback2,..." e.g.
+//     "us-east-1=us-west-2|eu-west-1,eu-west-1=eu-central-1"
+//
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FederatedBackendSpec is the wire format of a federated backend.
+type FederatedBackendSpec struct {
+	ID         string `json:"id"`
+	URI        string `json:"uri"`
+	Region     string `json:"region"`
+	Cluster    string `json:"cluster"`
+	TimeoutMS  int    `json:"timeoutMs,omitempty"`
+	ToolFormat string `json:"toolFormat,omitempty"` // "functions" for legacy function-calling backends
+	Protocol   string `json:"protocol,omitempty"`   // "http1", "http2" or "grpc"
+}
+
+// Config converts the spec to a backend configuration.
+func (s FederatedBackendSpec) Config() BackendEndpointConfig {
+	return BackendEndpointConfig{
+		ID:         strings.TrimSpace(s.ID),
+		URI:        strings.TrimSpace(s.URI),
+		Region:     strings.ToLower(strings.TrimSpace(s.Region)),
+		Cluster:    strings.TrimSpace(s.Cluster),
+		Timeout:    time.Duration(s.TimeoutMS) * time.Millisecond,
+		ToolFormat: strings.ToLower(strings.TrimSpace(s.ToolFormat)),
+		Protocol:   strings.ToLower(strings.TrimSpace(s.Protocol)),
+	}
+}
+
+// ParseFederatedBackends parses the FEDERATED_BACKENDS JSON array.
+func ParseFederatedBackends(raw string) ([]BackendEndpointConfig, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var specs []FederatedBackendSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return nil, fmt.Errorf("parse federated backends: %w", err)
+	}
+	backends := make([]BackendEndpointConfig, 0, len(specs))
+	for _, spec := range specs {
+		backend := spec.Config()
+		if !ValidBackendProtocol(backend.Protocol) {
+			return nil, fmt.Errorf("parse federated backends: %s: unknown protocol %q", backend.ID, backend.Protocol)
+		}
+		backends = append(backends, backend)
+	}
+	return backends, nil
+}
+
+// ParseFailoverRules parses "region=fallback1|fallback2,..." into an ordered
+// fallback list per region.
+func ParseFailoverRules(raw string) (map[string][]string, error) {
+	rules := make(map[string][]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		region := strings.ToLower(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || region == "" {
+			return nil, fmt.Errorf("invalid failover rule %q", entry)
+		}
+		var fallbacks []string
+		for _, fallback := range strings.Split(parts[1], "|") {
+			fallback = strings.ToLower(strings.TrimSpace(fallback))
+			if fallback != "" && fallback != region {
+				fallbacks = append(fallbacks, fallback)
+			}
+		}
+		rules[region] = fallbacks
+	}
+	return rules, nil
+}
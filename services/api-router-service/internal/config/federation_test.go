@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFederatedBackends(t *testing.T) {
+	backends, err := ParseFederatedBackends(`[{"id":"eu-a","uri":"https://eu.example.com/v1/completions","region":"EU-West-1","cluster":"eu-prod","timeoutMs":5000,"protocol":"HTTP2"}]`)
+	if err != nil {
+		t.Fatalf("ParseFederatedBackends: %v", err)
+	}
+	if len(backends) != 1 {
+		t.Fatalf("expected 1 backend, got %d", len(backends))
+	}
+	got := backends[0]
+	if got.ID != "eu-a" || got.Region != "eu-west-1" || got.Cluster != "eu-prod" || got.Timeout != 5*time.Second || got.Protocol != ProtocolHTTP2 {
+		t.Fatalf("unexpected backend %+v", got)
+	}
+
+	if _, err := ParseFederatedBackends("not json"); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+	if _, err := ParseFederatedBackends(`[{"id":"eu-a","uri":"https://eu.example.com","region":"eu-west-1","cluster":"eu-prod","protocol":"quic"}]`); err == nil {
+		t.Fatal("expected error for unknown protocol")
+	}
+}
+
+func TestParseFailoverRules(t *testing.T) {
+	rules, err := ParseFailoverRules("us-east-1=us-west-2|EU-West-1|us-east-1, eu-west-1=eu-central-1")
+	if err != nil {
+		t.Fatalf("ParseFailoverRules: %v", err)
+	}
+	want := map[string][]string{
+		"us-east-1": {"us-west-2", "eu-west-1"},
+		"eu-west-1": {"eu-central-1"},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("expected %v, got %v", want, rules)
+	}
+
+	if _, err := ParseFailoverRules("us-east-1"); err == nil {
+		t.Fatal("expected error for rule without fallbacks")
+	}
+}
+
+func TestRegisterFederatedBackend(t *testing.T) {
+	registry := NewBackendRegistry(&Config{BackendEndpoints: "local:http://local:8000"})
+
+	if err := registry.RegisterFederatedBackend(BackendEndpointConfig{ID: "local", URI: "http://x", Region: "eu-west-1", Cluster: "eu"}); err == nil {
+		t.Fatal("expected local backend to be protected")
+	}
+	if err := registry.RegisterFederatedBackend(BackendEndpointConfig{ID: "eu-a", URI: "http://eu"}); err == nil {
+		t.Fatal("expected error without cluster and region")
+	}
+	if err := registry.RegisterFederatedBackend(BackendEndpointConfig{ID: "eu-a", URI: "http://eu", Region: "EU-West-1", Cluster: "eu"}); err != nil {
+		t.Fatalf("RegisterFederatedBackend: %v", err)
+	}
+	backend, err := registry.GetBackend("eu-a")
+	if err != nil || backend.Region != "eu-west-1" || backend.Timeout != 30*time.Second {
+		t.Fatalf("unexpected backend %+v, %v", backend, err)
+	}
+
+	if err := registry.RemoveFederatedBackend("local"); err == nil {
+		t.Fatal("expected local backend removal to fail")
+	}
+	if err := registry.RemoveFederatedBackend("eu-a"); err != nil {
+		t.Fatalf("RemoveFederatedBackend: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package config provides parsing of per-backend health probe settings.
+//
+// Purpose:
+//   By default every backend is probed with GET <uri>/health every
+//   HEALTH_CHECK_INTERVAL. HEALTH_PROBES overrides the path, expected status,
+//   latency budget and interval per backend, and can add a synthetic
+//   one-token inference request for backends whose /health stays green while
+//   the model server is wedged.
+//
+// Debugging Notes:
+//   - HEALTH_PROBES is a JSON object keyed by backend ID, "*" applies to
+//     backends without their own entry, e.g.
+//     {"*":{"latencyBudgetMs":800},"gpu-a":{"path":"/v1/models","inferencePrompt":"ping"}}
+//   - A probe slower than its latency budget counts as a failed probe
+//   - HEALTH_CHECK_JITTER spreads probes by +/- that fraction of the interval
+//
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultHealthProbePath is probed when HEALTH_PROBES sets no path.
+const DefaultHealthProbePath = "/health"
+
+// defaultHealthProbeTimeout bounds a probe without timeoutMs.
+const defaultHealthProbeTimeout = 5 * time.Second
+
+// HealthProbeSpec is the wire format of one backend's probe settings.
+type HealthProbeSpec struct {
+	Path            string `json:"path,omitempty"`
+	ExpectedStatus  int    `json:"expectedStatus,omitempty"`
+	LatencyBudgetMS int    `json:"latencyBudgetMs,omitempty"`
+	IntervalMS      int    `json:"intervalMs,omitempty"`
+	TimeoutMS       int    `json:"timeoutMs,omitempty"`
+	InferencePrompt string `json:"inferencePrompt,omitempty"` // Sent with max_tokens=1 after the HTTP probe passes
+}
+
+// HealthProbeConfig is a backend's resolved probe settings.
+type HealthProbeConfig struct {
+	Path            string
+	ExpectedStatus  int
+	LatencyBudget   time.Duration // Zero disables the budget
+	Interval        time.Duration // Zero uses HEALTH_CHECK_INTERVAL
+	Timeout         time.Duration
+	InferencePrompt string
+}
+
+// DefaultHealthProbe returns the probe used for backends HEALTH_PROBES does not mention.
+func DefaultHealthProbe() HealthProbeConfig {
+	return HealthProbeConfig{
+		Path:           DefaultHealthProbePath,
+		ExpectedStatus: http.StatusOK,
+		Timeout:        defaultHealthProbeTimeout,
+	}
+}
+
+// Config resolves the spec, filling unset fields from DefaultHealthProbe.
+func (s HealthProbeSpec) Config() (HealthProbeConfig, error) {
+	probe := DefaultHealthProbe()
+	if path := strings.TrimSpace(s.Path); path != "" {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		probe.Path = path
+	}
+	if s.ExpectedStatus != 0 {
+		if s.ExpectedStatus < 100 || s.ExpectedStatus > 599 {
+			return HealthProbeConfig{}, fmt.Errorf("expectedStatus must be an HTTP status, got %d", s.ExpectedStatus)
+		}
+		probe.ExpectedStatus = s.ExpectedStatus
+	}
+	if s.LatencyBudgetMS < 0 || s.IntervalMS < 0 || s.TimeoutMS < 0 {
+		return HealthProbeConfig{}, fmt.Errorf("latencyBudgetMs, intervalMs and timeoutMs must not be negative")
+	}
+	probe.LatencyBudget = time.Duration(s.LatencyBudgetMS) * time.Millisecond
+	probe.Interval = time.Duration(s.IntervalMS) * time.Millisecond
+	if s.TimeoutMS > 0 {
+		probe.Timeout = time.Duration(s.TimeoutMS) * time.Millisecond
+	}
+	probe.InferencePrompt = s.InferencePrompt
+	return probe, nil
+}
+
+// ParseHealthProbes parses the HEALTH_PROBES JSON object.
+func ParseHealthProbes(raw string) (map[string]HealthProbeConfig, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var specs map[string]HealthProbeSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return nil, fmt.Errorf("parse health probes: %w", err)
+	}
+	probes := make(map[string]HealthProbeConfig, len(specs))
+	for backendID, spec := range specs {
+		probe, err := spec.Config()
+		if err != nil {
+			return nil, fmt.Errorf("parse health probes: %s: %w", backendID, err)
+		}
+		probes[strings.TrimSpace(backendID)] = probe
+	}
+	return probes, nil
+}
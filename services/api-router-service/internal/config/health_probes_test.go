@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHealthProbes(t *testing.T) {
+	probes, err := ParseHealthProbes(`{"*":{"latencyBudgetMs":800},"gpu-a":{"path":"v1/models","expectedStatus":204,"intervalMs":2000,"inferencePrompt":"ping"}}`)
+	if err != nil {
+		t.Fatalf("ParseHealthProbes: %v", err)
+	}
+	if got := probes["*"]; got.Path != DefaultHealthProbePath || got.ExpectedStatus != 200 || got.LatencyBudget != 800*time.Millisecond {
+		t.Fatalf("unexpected default probe %+v", got)
+	}
+	got := probes["gpu-a"]
+	if got.Path != "/v1/models" || got.ExpectedStatus != 204 || got.Interval != 2*time.Second || got.Timeout != defaultHealthProbeTimeout || got.InferencePrompt != "ping" {
+		t.Fatalf("unexpected gpu-a probe %+v", got)
+	}
+
+	if probes, err := ParseHealthProbes(" "); probes != nil || err != nil {
+		t.Fatalf("expected no probes, got %v, %v", probes, err)
+	}
+	if _, err := ParseHealthProbes(`{"gpu-a":{"expectedStatus":42}}`); err == nil {
+		t.Fatal("expected error for invalid status")
+	}
+	if _, err := ParseHealthProbes(`{"gpu-a":{"intervalMs":-1}}`); err == nil {
+		t.Fatal("expected error for negative interval")
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -41,6 +43,9 @@ type Loader struct {
 	logger       *zap.Logger
 	watchCtx     context.Context
 	watchCancel  context.CancelFunc
+
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
 }
 
 const (
@@ -284,15 +289,18 @@ func (l *Loader) GetPolicy(organizationID, model string) (*RoutingPolicy, error)
 	if l.cache != nil {
 		policy, err := l.cache.GetPolicy(organizationID, model)
 		if err == nil && policy != nil {
+			l.cacheHits.Add(1)
 			return policy, nil
 		}
 		// Try global policy if org-specific not found
 		if organizationID != etcdGlobalOrgID {
 			policy, err := l.cache.GetPolicy(etcdGlobalOrgID, model)
 			if err == nil && policy != nil {
+				l.cacheHits.Add(1)
 				return policy, nil
 			}
 		}
+		l.cacheMisses.Add(1)
 	}
 
 	// Cache miss - try etcd if available
@@ -326,6 +334,71 @@ func (l *Loader) GetPolicy(organizationID, model string) (*RoutingPolicy, error)
 	return nil, fmt.Errorf("policy not found for org=%s model=%s", organizationID, model)
 }
 
+// ListPolicies returns one policy per model visible to the organization from
+// the cache, preferring org-specific policies over global ones.
+func (l *Loader) ListPolicies(ctx context.Context, organizationID string) ([]*RoutingPolicy, error) {
+	if l.cache == nil {
+		return nil, nil
+	}
+	policies, err := l.cache.LoadPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load cached policies: %w", err)
+	}
+
+	byModel := make(map[string]*RoutingPolicy)
+	for _, policy := range policies {
+		switch policy.OrganizationID {
+		case organizationID:
+			byModel[policy.Model] = policy
+		case etcdGlobalOrgID:
+			if _, ok := byModel[policy.Model]; !ok {
+				byModel[policy.Model] = policy
+			}
+		}
+	}
+
+	visible := make([]*RoutingPolicy, 0, len(byModel))
+	for _, policy := range byModel {
+		visible = append(visible, policy)
+	}
+	sort.Slice(visible, func(i, j int) bool { return visible[i].Model < visible[j].Model })
+	return visible, nil
+}
+
+// CacheStats reports the number of cached policies and GetPolicy cache
+// hits and misses since startup.
+func (l *Loader) CacheStats() (entries int, hits, misses uint64) {
+	if l.cache != nil {
+		n, err := l.cache.Len()
+		if err != nil {
+			l.logger.Warn("failed to count cached policies", zap.Error(err))
+		}
+		entries = n
+	}
+	return entries, l.cacheHits.Load(), l.cacheMisses.Load()
+}
+
+// FlushCache replaces the cached policies with a fresh copy from etcd,
+// dropping policies that were deleted while the watch was down. The cache is
+// left untouched if etcd cannot be read, since it is the only fallback.
+func (l *Loader) FlushCache(ctx context.Context) error {
+	if l.cache == nil {
+		return nil
+	}
+	if err := l.connect(ctx); err != nil {
+		return fmt.Errorf("refusing to flush config cache: %w", err)
+	}
+	policies, err := l.loadPoliciesFromEtcd(ctx)
+	if err != nil {
+		return fmt.Errorf("refusing to flush config cache: %w", err)
+	}
+	if err := l.cache.ReplacePolicies(ctx, policies); err != nil {
+		return fmt.Errorf("replace cached policies: %w", err)
+	}
+	l.logger.Info("config cache flushed", zap.Int("policies", len(policies)))
+	return nil
+}
+
 // getPolicyFromEtcd retrieves a single policy from etcd.
 func (l *Loader) getPolicyFromEtcd(ctx context.Context, organizationID, model string) (*RoutingPolicy, error) {
 	key := etcdPolicyKey(organizationID, model)
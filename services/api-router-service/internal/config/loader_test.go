@@ -251,6 +251,81 @@ func TestLoader_GetPolicy_GlobalFallback(t *testing.T) {
 	}
 }
 
+func TestLoader_CacheStats(t *testing.T) {
+	cache := setupTestCache(t)
+	defer func() { _ = cache.Close() }()
+
+	logger := zaptest.NewLogger(t)
+	loader := NewLoader("", false, cache, logger)
+
+	ctx := context.Background()
+	if err := cache.StorePolicy(ctx, &RoutingPolicy{PolicyID: "p1", OrganizationID: "*", Model: "gpt-4o"}); err != nil {
+		t.Fatalf("failed to store policy: %v", err)
+	}
+
+	if _, err := loader.GetPolicy("org-1", "gpt-4o"); err != nil {
+		t.Fatalf("GetPolicy() failed: %v", err)
+	}
+	if _, err := loader.GetPolicy("org-1", "llama-3"); err == nil {
+		t.Fatal("GetPolicy() should fail for an uncached model")
+	}
+
+	entries, hits, misses := loader.CacheStats()
+	if entries != 1 || hits != 1 || misses != 1 {
+		t.Errorf("CacheStats() = (%d, %d, %d), want (1, 1, 1)", entries, hits, misses)
+	}
+}
+
+func TestCache_ReplacePolicies(t *testing.T) {
+	cache := setupTestCache(t)
+	defer func() { _ = cache.Close() }()
+
+	ctx := context.Background()
+	if err := cache.StorePolicy(ctx, &RoutingPolicy{PolicyID: "stale", OrganizationID: "org-1", Model: "gpt-4o"}); err != nil {
+		t.Fatalf("failed to store policy: %v", err)
+	}
+	if err := cache.ReplacePolicies(ctx, []*RoutingPolicy{{PolicyID: "fresh", OrganizationID: "*", Model: "gpt-4o"}}); err != nil {
+		t.Fatalf("ReplacePolicies() failed: %v", err)
+	}
+
+	if _, err := cache.GetPolicy("org-1", "gpt-4o"); err == nil {
+		t.Error("stale policy should be removed")
+	}
+	if n, err := cache.Len(); err != nil || n != 1 {
+		t.Errorf("Len() = (%d, %v), want (1, nil)", n, err)
+	}
+}
+
+func TestLoader_ListPolicies_PrefersOrgPolicies(t *testing.T) {
+	cache := setupTestCache(t)
+	defer func() { _ = cache.Close() }()
+
+	loader := NewLoader("", false, cache, zaptest.NewLogger(t))
+
+	ctx := context.Background()
+	for _, policy := range []*RoutingPolicy{
+		{PolicyID: "global-gpt-4o", OrganizationID: "*", Model: "gpt-4o"},
+		{PolicyID: "global-llama", OrganizationID: "*", Model: "llama-3"},
+		{PolicyID: "org-gpt-4o", OrganizationID: "org-1", Model: "gpt-4o"},
+		{PolicyID: "other-org-mistral", OrganizationID: "org-2", Model: "mistral"},
+	} {
+		if err := cache.StorePolicy(ctx, policy); err != nil {
+			t.Fatalf("failed to store policy: %v", err)
+		}
+	}
+
+	policies, err := loader.ListPolicies(ctx, "org-1")
+	if err != nil {
+		t.Fatalf("ListPolicies() failed: %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(policies))
+	}
+	if policies[0].PolicyID != "org-gpt-4o" || policies[1].PolicyID != "global-llama" {
+		t.Errorf("unexpected policies: %s, %s", policies[0].PolicyID, policies[1].PolicyID)
+	}
+}
+
 func TestLoader_GetPolicy_FromEtcd(t *testing.T) {
 	endpoint := getEtcdEndpoint()
 	client := setupTestEtcdClient(t, endpoint)
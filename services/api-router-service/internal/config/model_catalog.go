@@ -0,0 +1,43 @@
+// Package config provides parsing of model capability metadata.
+//
+// Purpose:
+//   GET /v1/models merges routing policies, backend labels and pricing with
+//   per-model capabilities that backends do not report themselves. MODEL_CATALOG
+//   supplies those capabilities.
+//
+// Debugging Notes:
+//   - MODEL_CATALOG is a JSON object keyed by model ID, e.g.
+//     {"gpt-4o":{"contextWindow":128000,"modalities":["text","image"],"ownedBy":"openai"}}
+//   - Models without an entry are still listed, with modalities ["text"]
+//
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ModelMetadata describes a model's capabilities.
+type ModelMetadata struct {
+	ContextWindow int      `json:"contextWindow,omitempty"`
+	Modalities    []string `json:"modalities,omitempty"`
+	OwnedBy       string   `json:"ownedBy,omitempty"`
+}
+
+// ParseModelCatalog parses the MODEL_CATALOG JSON object.
+func ParseModelCatalog(raw string) (map[string]ModelMetadata, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var catalog map[string]ModelMetadata
+	if err := json.Unmarshal([]byte(raw), &catalog); err != nil {
+		return nil, fmt.Errorf("parse model catalog: %w", err)
+	}
+	for model, metadata := range catalog {
+		if metadata.ContextWindow < 0 {
+			return nil, fmt.Errorf("parse model catalog: %s: contextWindow must not be negative", model)
+		}
+	}
+	return catalog, nil
+}
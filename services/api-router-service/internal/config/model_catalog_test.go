@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestParseModelCatalog(t *testing.T) {
+	catalog, err := ParseModelCatalog(`{"gpt-4o":{"contextWindow":128000,"modalities":["text","image"],"ownedBy":"openai"}}`)
+	if err != nil {
+		t.Fatalf("ParseModelCatalog: %v", err)
+	}
+	got := catalog["gpt-4o"]
+	if got.ContextWindow != 128000 || len(got.Modalities) != 2 || got.OwnedBy != "openai" {
+		t.Fatalf("unexpected metadata %+v", got)
+	}
+
+	if catalog, err := ParseModelCatalog(" "); catalog != nil || err != nil {
+		t.Fatalf("expected empty catalog, got %v, %v", catalog, err)
+	}
+	if _, err := ParseModelCatalog(`{"m":{"contextWindow":-1}}`); err == nil {
+		t.Fatal("expected error for negative context window")
+	}
+}
@@ -0,0 +1,64 @@
+// Package config provides data-residency filtering of routing policies.
+//
+// Purpose:
+//   Organizations can restrict inference to backends in specific regions.
+//   user-org-service returns the allowed regions with API key validation;
+//   this file narrows a routing policy to the backends labelled with one of
+//   them (BACKEND_REGIONS).
+//
+// Debugging Notes:
+//   - Unlabelled backends are never compliant for a restricted org
+//   - Degraded-backend fallback in the routing engine only ever sees the
+//     filtered list, so it cannot route outside the allowed regions
+//
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResidencyError reports that no backend in a policy satisfies an org's
+// data-residency constraint.
+type ResidencyError struct {
+	Model          string
+	AllowedRegions []string
+}
+
+func (e *ResidencyError) Error() string {
+	return fmt.Sprintf("no backend for model %q in allowed regions [%s]", e.Model, strings.Join(e.AllowedRegions, ", "))
+}
+
+// ApplyResidency returns a copy of policy containing only backends whose
+// region is in allowedRegions. The policy is returned unchanged when
+// allowedRegions is empty, and a *ResidencyError when nothing remains.
+func (r *BackendRegistry) ApplyResidency(policy *RoutingPolicy, allowedRegions []string) (*RoutingPolicy, error) {
+	if policy == nil || len(allowedRegions) == 0 {
+		return policy, nil
+	}
+
+	allowed := make(map[string]bool, len(allowedRegions))
+	for _, region := range allowedRegions {
+		allowed[strings.ToLower(region)] = true
+	}
+
+	compliant := make([]BackendWeight, 0, len(policy.Backends))
+	for _, backend := range policy.Backends {
+		if r == nil {
+			break // No registry means no region labels
+		}
+		cfg, err := r.GetBackend(backend.BackendID)
+		if err != nil || !allowed[cfg.Region] {
+			continue
+		}
+		compliant = append(compliant, backend)
+	}
+	if len(compliant) == 0 {
+		return nil, &ResidencyError{Model: policy.Model, AllowedRegions: allowedRegions}
+	}
+
+	// Copy so the cached policy is never narrowed for other orgs
+	filtered := *policy
+	filtered.Backends = compliant
+	return &filtered, nil
+}
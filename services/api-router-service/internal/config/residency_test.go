@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func newResidencyTestRegistry(t *testing.T) *BackendRegistry {
+	t.Helper()
+	return NewBackendRegistry(&Config{
+		BackendEndpoints: "eu-1:http://eu-1:8000/v1/completions,us-1:http://us-1:8000/v1/completions,unlabelled:http://x:8000",
+		BackendRegions:   "eu-1=EU-West-1,us-1=us-east-1,missing=eu-west-1",
+	})
+}
+
+func TestApplyResidencyFiltersBackends(t *testing.T) {
+	registry := newResidencyTestRegistry(t)
+	policy := &RoutingPolicy{
+		Model: "gpt-4o",
+		Backends: []BackendWeight{
+			{BackendID: "us-1", Weight: 70},
+			{BackendID: "eu-1", Weight: 30},
+			{BackendID: "unlabelled", Weight: 0},
+		},
+	}
+
+	filtered, err := registry.ApplyResidency(policy, []string{"eu-west-1"})
+	if err != nil {
+		t.Fatalf("ApplyResidency: %v", err)
+	}
+	if len(filtered.Backends) != 1 || filtered.Backends[0].BackendID != "eu-1" {
+		t.Fatalf("expected only eu-1, got %+v", filtered.Backends)
+	}
+	if len(policy.Backends) != 3 {
+		t.Fatalf("original policy must not be modified, got %+v", policy.Backends)
+	}
+
+	unrestricted, err := registry.ApplyResidency(policy, nil)
+	if err != nil || unrestricted != policy {
+		t.Fatalf("expected policy unchanged without residency, got %v, %v", unrestricted, err)
+	}
+}
+
+func TestApplyResidencyNoCompliantBackend(t *testing.T) {
+	registry := newResidencyTestRegistry(t)
+	policy := &RoutingPolicy{Model: "gpt-4o", Backends: []BackendWeight{{BackendID: "us-1", Weight: 100}}}
+
+	_, err := registry.ApplyResidency(policy, []string{"eu-west-1"})
+	var residencyErr *ResidencyError
+	if !errors.As(err, &residencyErr) {
+		t.Fatalf("expected ResidencyError, got %v", err)
+	}
+	if residencyErr.Model != "gpt-4o" {
+		t.Fatalf("expected model in error, got %q", residencyErr.Model)
+	}
+
+	// Re-labelling the backend makes it compliant
+	if err := registry.SetBackendRegion("us-1", "eu-west-1"); err != nil {
+		t.Fatalf("SetBackendRegion: %v", err)
+	}
+	if _, err := registry.ApplyResidency(policy, []string{"eu-west-1"}); err != nil {
+		t.Fatalf("expected compliant after relabel, got %v", err)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 // BudgetClient checks budgets and quotas for organizations.
@@ -34,6 +36,7 @@ type BudgetClient struct {
 	timeout  time.Duration
 	logger   *zap.Logger
 	client   *http.Client
+	holds    *BudgetHolds // Optional pre-authorization holds for long requests
 }
 
 // NewBudgetClient creates a new budget client.
@@ -46,11 +49,22 @@ func NewBudgetClient(endpoint string, timeout time.Duration, logger *zap.Logger)
 		timeout:  timeout,
 		logger:   logger,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: &requestid.Transport{},
 		},
 	}
 }
 
+// SetHolds enables budget holds for long-running requests.
+func (c *BudgetClient) SetHolds(holds *BudgetHolds) {
+	c.holds = holds
+}
+
+// Holds returns the budget hold tracker, or nil when holds are disabled.
+func (c *BudgetClient) Holds() *BudgetHolds {
+	return c.holds
+}
+
 // BudgetStatus represents the budget/quota status for an organization.
 type BudgetStatus struct {
 	Allowed      bool
@@ -0,0 +1,330 @@
+// Package limiter provides budget pre-authorization holds.
+//
+// Purpose:
+//   A budget check only sees spend that has already been billed, so many
+//   long streaming requests admitted together can overshoot the budget before
+//   any of them completes. Long requests therefore reserve their estimated
+//   cost as a hold when they start; a request is only admitted while billed
+//   usage plus all outstanding holds stays within the limit. On completion the
+//   hold is settled to the actual cost and kept until the usage record has
+//   reached the budget service, then it expires.
+//
+// Debugging Notes:
+//   - Holds live in budget_holds:<org_id>, a Redis sorted set of
+//     "<hold_id>|<usd>" members scored by expiry, shared by all routers
+//   - Unsettled holds of a router that died are reclaimed after
+//     BUDGET_HOLD_LEASE; settled holds expire after BUDGET_HOLD_SETTLE_TTL
+//   - Without Redis (or while it errors) holds are tracked per router
+//
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// DefaultBudgetHoldLease bounds how long an unsettled hold is kept.
+const DefaultBudgetHoldLease = 10 * time.Minute
+
+// DefaultBudgetHoldSettleTTL is how long a settled hold keeps counting, so the
+// actual cost is reserved until billed usage includes it.
+const DefaultBudgetHoldSettleTTL = 2 * time.Minute
+
+// placeHoldScript drops expired holds and adds the new one when the org's
+// outstanding holds plus its amount fit in the available budget (negative
+// means unlimited). It returns {allowed, held} with held as a string.
+const placeHoldScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local ttl_ms = tonumber(ARGV[2])
+	local member = ARGV[3]
+	local amount = tonumber(ARGV[4])
+	local available = tonumber(ARGV[5])
+
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+	local held = 0
+	for _, m in ipairs(redis.call('ZRANGE', key, 0, -1)) do
+		local sep = string.find(m, '|', 1, true)
+		held = held + tonumber(string.sub(m, sep + 1))
+	end
+	if available >= 0 and held + amount > available then
+		return {0, tostring(held)}
+	end
+	redis.call('ZADD', key, now + ttl_ms, member)
+	if redis.call('PTTL', key) < ttl_ms then
+		redis.call('PEXPIRE', key, ttl_ms)
+	end
+	return {1, tostring(held + amount)}
+`
+
+// settleHoldScript replaces a hold with its settled amount.
+const settleHoldScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local ttl_ms = tonumber(ARGV[2])
+	redis.call('ZREM', key, ARGV[3])
+	redis.call('ZADD', key, now + ttl_ms, ARGV[4])
+	if redis.call('PTTL', key) < ttl_ms then
+		redis.call('PEXPIRE', key, ttl_ms)
+	end
+	return 1
+`
+
+// holdSeq makes hold IDs unique per router process.
+var holdSeq atomic.Uint64
+
+// HoldResult represents the result of placing a budget hold.
+type HoldResult struct {
+	Allowed bool
+	Held    float64 // Outstanding holds in USD, including this one when allowed
+}
+
+// HoldPolicy selects which requests are held and how their cost is estimated.
+type HoldPolicy struct {
+	MinOutputTokens     int // Non-streaming requests are held when max_tokens is at least this
+	DefaultOutputTokens int // Output estimate for requests without max_tokens
+}
+
+// DefaultHoldPolicy holds streaming requests and requests allowing 2K+ output tokens.
+var DefaultHoldPolicy = HoldPolicy{MinOutputTokens: 2048, DefaultOutputTokens: 1024}
+
+// BudgetHolds tracks budget holds per organization.
+type BudgetHolds struct {
+	client    redis.UniversalClient
+	logger    *zap.Logger
+	lease     time.Duration
+	settleTTL time.Duration
+	policy    HoldPolicy
+
+	mu    sync.Mutex
+	local map[string]map[string]localHold // Per-router holds while Redis is unavailable
+}
+
+type localHold struct {
+	amount  float64
+	expires time.Time
+}
+
+// NewBudgetHolds creates a hold tracker; a nil client tracks holds per router.
+func NewBudgetHolds(client redis.UniversalClient, logger *zap.Logger) *BudgetHolds {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if c, ok := client.(*redis.Client); ok && c == nil {
+		client = nil
+	}
+	return &BudgetHolds{
+		client:    client,
+		logger:    logger,
+		lease:     DefaultBudgetHoldLease,
+		settleTTL: DefaultBudgetHoldSettleTTL,
+		policy:    DefaultHoldPolicy,
+		local:     make(map[string]map[string]localHold),
+	}
+}
+
+// SetLease sets how long a hold is kept if it is never settled or released.
+func (h *BudgetHolds) SetLease(lease time.Duration) {
+	if lease > 0 {
+		h.lease = lease
+	}
+}
+
+// SetSettleTTL sets how long a settled hold keeps counting against the budget.
+func (h *BudgetHolds) SetSettleTTL(ttl time.Duration) {
+	if ttl > 0 {
+		h.settleTTL = ttl
+	}
+}
+
+// SetPolicy sets which requests are held; zero fields keep their defaults.
+func (h *BudgetHolds) SetPolicy(policy HoldPolicy) {
+	if policy.MinOutputTokens > 0 {
+		h.policy.MinOutputTokens = policy.MinOutputTokens
+	}
+	if policy.DefaultOutputTokens > 0 {
+		h.policy.DefaultOutputTokens = policy.DefaultOutputTokens
+	}
+}
+
+// Policy returns the hold policy.
+func (h *BudgetHolds) Policy() HoldPolicy {
+	return h.policy
+}
+
+// Place reserves amount USD for orgID when its outstanding holds plus amount
+// fit in available (negative means unlimited). When allowed, the returned hold
+// must be settled or released once the request finishes; it is nil otherwise.
+func (h *BudgetHolds) Place(ctx context.Context, orgID string, amount, available float64) (*HoldResult, *BudgetHold, error) {
+	hold := &BudgetHold{
+		holds:  h,
+		key:    "budget_holds:" + orgID,
+		id:     strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(holdSeq.Add(1), 36),
+		amount: amount,
+		limit:  available,
+	}
+
+	if h.client != nil {
+		now := time.Now()
+		res, err := h.client.Eval(ctx, placeHoldScript, []string{hold.key},
+			now.UnixMilli(), h.lease.Milliseconds(), hold.member(amount), formatUSD(amount), formatUSD(available)).Slice()
+		if err == nil {
+			result, err := parseHoldResult(res)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !result.Allowed {
+				return result, nil, nil
+			}
+			return result, hold, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("place budget hold: %w", err)
+		}
+		h.logger.Warn("budget hold store unavailable, holding locally", zap.Error(err))
+	}
+
+	hold.local = true
+	result := h.placeLocal(hold, time.Now())
+	if !result.Allowed {
+		return result, nil, nil
+	}
+	return result, hold, nil
+}
+
+func (h *BudgetHolds) placeLocal(hold *BudgetHold, now time.Time) *HoldResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	holds := h.local[hold.key]
+	held := 0.0
+	for id, lh := range holds {
+		if !lh.expires.After(now) {
+			delete(holds, id)
+			continue
+		}
+		held += lh.amount
+	}
+	if hold.exceeds(held) {
+		return &HoldResult{Held: held}
+	}
+	if holds == nil {
+		holds = make(map[string]localHold)
+		h.local[hold.key] = holds
+	}
+	holds[hold.id] = localHold{amount: hold.amount, expires: now.Add(h.lease)}
+	return &HoldResult{Allowed: true, Held: held + hold.amount}
+}
+
+// BudgetHold is an outstanding reservation for one request.
+type BudgetHold struct {
+	holds  *BudgetHolds
+	key    string
+	id     string
+	amount float64
+	local  bool
+	limit  float64 // Available budget the hold was placed against; negative means unlimited
+	done   atomic.Bool
+}
+
+// Amount returns the estimated cost the hold reserves, in USD.
+func (b *BudgetHold) Amount() float64 {
+	return b.amount
+}
+
+// Settle replaces the estimate with the actual cost, which keeps counting
+// until the settle TTL passes. Only the first Settle or Release takes effect.
+func (b *BudgetHold) Settle(actual float64) {
+	if b == nil || !b.done.CompareAndSwap(false, true) {
+		return
+	}
+	if b.local {
+		b.holds.mu.Lock()
+		defer b.holds.mu.Unlock()
+		if holds := b.holds.local[b.key]; holds != nil {
+			holds[b.id] = localHold{amount: actual, expires: time.Now().Add(b.holds.settleTTL)}
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := b.holds.client.Eval(ctx, settleHoldScript, []string{b.key},
+		time.Now().UnixMilli(), b.holds.settleTTL.Milliseconds(), b.member(b.amount), b.member(actual)).Err()
+	if err != nil {
+		b.holds.logger.Debug("failed to settle budget hold; it expires with its lease", zap.Error(err))
+	}
+}
+
+// Release drops the hold without settling it, for requests that never reached
+// a backend. Only the first Settle or Release takes effect.
+func (b *BudgetHold) Release() {
+	if b == nil || !b.done.CompareAndSwap(false, true) {
+		return
+	}
+	if b.local {
+		b.holds.mu.Lock()
+		defer b.holds.mu.Unlock()
+		if holds := b.holds.local[b.key]; holds != nil {
+			delete(holds, b.id)
+			if len(holds) == 0 {
+				delete(b.holds.local, b.key)
+			}
+		}
+		return
+	}
+
+	// The request context is usually done by now
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.holds.client.ZRem(ctx, b.key, b.member(b.amount)).Err(); err != nil {
+		b.holds.logger.Debug("failed to release budget hold; it expires with its lease", zap.Error(err))
+	}
+}
+
+func (b *BudgetHold) member(amount float64) string {
+	return b.id + "|" + formatUSD(amount)
+}
+
+// exceeds reports whether the hold does not fit next to held.
+func (b *BudgetHold) exceeds(held float64) bool {
+	return b.limit >= 0 && held+b.amount > b.limit
+}
+
+func formatUSD(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+func parseHoldResult(res []interface{}) (*HoldResult, error) {
+	if len(res) < 2 {
+		return nil, fmt.Errorf("unexpected budget hold result format")
+	}
+	allowed, _ := res[0].(int64)
+	heldStr, _ := res[1].(string)
+	held, err := strconv.ParseFloat(heldStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse budget hold total: %w", err)
+	}
+	return &HoldResult{Allowed: allowed == 1, Held: held}, nil
+}
+
+// budgetHoldKey is the context key for a request's budget hold.
+type budgetHoldKey struct{}
+
+// WithBudgetHold returns a context carrying hold.
+func WithBudgetHold(ctx context.Context, hold *BudgetHold) context.Context {
+	return context.WithValue(ctx, budgetHoldKey{}, hold)
+}
+
+// BudgetHoldFromContext returns the request's budget hold, or nil.
+func BudgetHoldFromContext(ctx context.Context) *BudgetHold {
+	hold, _ := ctx.Value(budgetHoldKey{}).(*BudgetHold)
+	return hold
+}
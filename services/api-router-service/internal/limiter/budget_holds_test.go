@@ -0,0 +1,79 @@
+// Package limiter provides unit tests for budget pre-authorization holds.
+//
+// Purpose:
+//   These tests validate placing, settling and releasing budget holds with the
+//   per-router fallback and, when Redis is available, the shared sorted set.
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestBudgetHoldsLocal(t *testing.T) {
+	holds := NewBudgetHolds(nil, zap.NewNop())
+	ctx := context.Background()
+
+	first, hold, err := holds.Place(ctx, "org-1", 6, 10)
+	if err != nil || !first.Allowed || first.Held != 6 {
+		t.Fatalf("expected first hold, got %+v, %v", first, err)
+	}
+	if denied, none, _ := holds.Place(ctx, "org-1", 6, 10); denied.Allowed || none != nil || denied.Held != 6 {
+		t.Fatalf("expected denial over the available budget, got %+v", denied)
+	}
+	if other, _, _ := holds.Place(ctx, "org-2", 6, 10); !other.Allowed {
+		t.Fatal("holds of another org should not count")
+	}
+	if unlimited, _, _ := holds.Place(ctx, "org-1", 100, -1); !unlimited.Allowed {
+		t.Fatal("expected hold without a limit to be allowed")
+	}
+
+	// Settling keeps only the actual cost; a later Release is ignored
+	hold.Settle(1)
+	hold.Release()
+	if result, _, _ := holds.Place(ctx, "org-1", 8, 109); !result.Allowed || result.Held != 109 {
+		t.Fatalf("expected settled amount to count, got %+v", result)
+	}
+}
+
+func TestBudgetHoldsLocalExpiry(t *testing.T) {
+	holds := NewBudgetHolds(nil, zap.NewNop())
+	holds.SetLease(time.Millisecond)
+	ctx := context.Background()
+
+	if result, _, _ := holds.Place(ctx, "org-1", 10, 10); !result.Allowed {
+		t.Fatal("expected hold")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if result, _, _ := holds.Place(ctx, "org-1", 10, 10); !result.Allowed {
+		t.Fatal("expected expired hold to be reclaimed")
+	}
+}
+
+func TestBudgetHoldsRedis(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	holds := NewBudgetHolds(client, zap.NewNop())
+	ctx := context.Background()
+	org := "org-hold-" + time.Now().Format("150405.000000")
+
+	result, hold, err := holds.Place(ctx, org, 2.5, 4)
+	if err != nil || !result.Allowed || result.Held != 2.5 {
+		t.Fatalf("expected hold, got %+v, %v", result, err)
+	}
+	if denied, _, _ := holds.Place(ctx, org, 2.5, 4); denied.Allowed {
+		t.Fatal("expected denial while the hold is outstanding")
+	}
+	hold.Settle(0.5)
+	if result, _, _ := holds.Place(ctx, org, 2.5, 4); !result.Allowed || result.Held != 3 {
+		t.Fatalf("expected settled amount to count, got %+v", result)
+	}
+}
@@ -0,0 +1,127 @@
+// Package limiter provides max-concurrent-request limits.
+//
+// Purpose:
+//   RPS limits do not capture long-running streaming requests, so an org can
+//   also cap how many requests it (or each of its API keys) has in flight.
+//   Slots are held in a Redis sorted set shared by all routers and released
+//   when the request finishes.
+//
+// Debugging Notes:
+//   - Slots live in concurrency:<scope>:<id>, scored by lease expiry; a slot
+//     whose router died is reclaimed after RATE_LIMIT_CONCURRENCY_LEASE
+//   - Requests longer than the lease no longer count against the cap
+//   - While Redis is unavailable slots are counted per router, scaled by
+//     RATE_LIMIT_LOCAL_SHARE
+//
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultConcurrencyLease bounds how long an unreleased slot is held.
+const DefaultConcurrencyLease = 10 * time.Minute
+
+// ConcurrencyResult represents the result of a concurrency slot acquisition.
+type ConcurrencyResult struct {
+	Allowed  bool
+	InFlight int // Requests in flight, including this one when allowed
+	Limit    int
+}
+
+// acquireScript reclaims expired slots and takes one when below the limit.
+const acquireScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local limit = tonumber(ARGV[2])
+	local lease_ms = tonumber(ARGV[3])
+	local member = ARGV[4]
+
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+	local count = redis.call('ZCARD', key)
+	if count >= limit then
+		return {0, count}
+	end
+	redis.call('ZADD', key, now + lease_ms, member)
+	redis.call('PEXPIRE', key, lease_ms)
+	return {1, count + 1}
+`
+
+// slotSeq makes slot members unique per router process.
+var slotSeq atomic.Uint64
+
+// SetConcurrencyLease sets how long a slot is held if it is never released.
+func (r *RateLimiter) SetConcurrencyLease(lease time.Duration) {
+	if lease > 0 {
+		r.leaseTTL = lease
+	}
+}
+
+// AcquireConcurrency takes one of limit concurrent request slots for the
+// scope ("org" or "key") and id. When allowed, release must be called once
+// the request finishes; it is a no-op otherwise.
+func (r *RateLimiter) AcquireConcurrency(ctx context.Context, scope, id string, limit int) (*ConcurrencyResult, func(), error) {
+	key := fmt.Sprintf("concurrency:%s:%s", scope, id)
+	if r.Degraded() {
+		result, release := r.local.acquire(key, limit)
+		return result, release, nil
+	}
+
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatUint(slotSeq.Add(1), 36)
+	res, err := r.client.Eval(ctx, acquireScript, []string{key}, now.UnixMilli(), limit, r.leaseTTL.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, func() {}, fmt.Errorf("concurrency check failed: %w", err)
+		}
+		r.fallBack(err)
+		result, release := r.local.acquire(key, limit)
+		return result, release, nil
+	}
+	if len(res) < 2 {
+		return nil, func() {}, fmt.Errorf("unexpected concurrency result format")
+	}
+
+	result := &ConcurrencyResult{Allowed: res[0] == 1, InFlight: int(res[1]), Limit: limit}
+	if !result.Allowed {
+		return result, func() {}, nil
+	}
+	release := func() {
+		// The request context is usually done by now
+		releaseCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := r.client.ZRem(releaseCtx, key, member).Err(); err != nil {
+			r.logger.Debug("failed to release concurrency slot; it expires with its lease")
+		}
+	}
+	return result, release, nil
+}
+
+// acquire takes a local concurrency slot for key.
+func (l *localLimiter) acquire(key string, limit int) (*ConcurrencyResult, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit = int(math.Max(1, math.Floor(float64(limit)*l.share)))
+	inFlight := l.inFlight[key]
+	if inFlight >= limit {
+		return &ConcurrencyResult{InFlight: inFlight, Limit: limit}, func() {}
+	}
+	l.inFlight[key] = inFlight + 1
+
+	var once atomic.Bool
+	return &ConcurrencyResult{Allowed: true, InFlight: inFlight + 1, Limit: limit}, func() {
+		if !once.CompareAndSwap(false, true) {
+			return
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.inFlight[key]--; l.inFlight[key] <= 0 {
+			delete(l.inFlight, key)
+		}
+	}
+}
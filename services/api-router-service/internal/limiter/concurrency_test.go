@@ -0,0 +1,62 @@
+// Package limiter provides unit tests for concurrent request limits.
+//
+// Purpose:
+//   These tests validate acquiring and releasing concurrency slots with the
+//   local fallback and, when Redis is available, the shared sorted set.
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAcquireConcurrencyLocal(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 10, 20)
+	ctx := context.Background()
+
+	first, releaseFirst, err := limiter.AcquireConcurrency(ctx, "org", "org-1", 2)
+	if err != nil || !first.Allowed || first.InFlight != 1 {
+		t.Fatalf("expected first slot, got %+v, %v", first, err)
+	}
+	if second, _, _ := limiter.AcquireConcurrency(ctx, "org", "org-1", 2); !second.Allowed {
+		t.Fatal("expected second slot")
+	}
+	if third, _, _ := limiter.AcquireConcurrency(ctx, "org", "org-1", 2); third.Allowed || third.InFlight != 2 {
+		t.Fatalf("expected denial at the cap, got %+v", third)
+	}
+
+	releaseFirst()
+	releaseFirst() // Releasing twice must not free a second slot
+	if again, _, _ := limiter.AcquireConcurrency(ctx, "org", "org-1", 2); !again.Allowed {
+		t.Fatal("expected a slot after release")
+	}
+	if over, _, _ := limiter.AcquireConcurrency(ctx, "org", "org-1", 2); over.Allowed {
+		t.Fatal("expected double release to be ignored")
+	}
+}
+
+func TestAcquireConcurrencyRedis(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 10, 20)
+	ctx := context.Background()
+
+	result, release, err := limiter.AcquireConcurrency(ctx, "key", "key-1", 1)
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected slot, got %+v, %v", result, err)
+	}
+	if denied, _, _ := limiter.AcquireConcurrency(ctx, "key", "key-1", 1); denied.Allowed {
+		t.Fatal("expected denial while the slot is held")
+	}
+	release()
+	if result, _, _ := limiter.AcquireConcurrency(ctx, "key", "key-1", 1); !result.Allowed {
+		t.Fatal("expected slot after release")
+	}
+}
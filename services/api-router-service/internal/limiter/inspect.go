@@ -0,0 +1,212 @@
+// Package limiter provides inspection and manual reset of an org's buckets.
+//
+// Purpose:
+//   Operators looking into a throttled org need to see how much of each
+//   bucket is left and, after raising a limit or resolving an incident, to
+//   refill the buckets without waiting for them to recover on their own.
+//
+// Debugging Notes:
+//   - Only buckets that exist are reported; a missing bucket is full
+//   - API key buckets are keyed by key ID, so callers must name the keys to include
+//   - Resetting also frees the concurrency slots, so requests still in
+//     flight stop counting against the cap until they finish
+//   - While degraded only this router's local buckets are inspected and reset
+//
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BucketState is the current state of one rate limit or concurrency bucket.
+type BucketState struct {
+	Scope        string    `json:"scope"` // "org" or "key"
+	ID           string    `json:"id"`
+	Algorithm    Algorithm `json:"algorithm,omitempty"` // Empty for concurrency slots
+	Remaining    int       `json:"remaining,omitempty"`
+	Limit        int       `json:"limit,omitempty"`
+	InFlight     int       `json:"in_flight,omitempty"`
+	ResetAfterMS int64     `json:"reset_after_ms,omitempty"`
+}
+
+// Buckets is the rate limit state of an org and the API keys asked for.
+type Buckets struct {
+	OrganizationID string        `json:"organization_id"`
+	Local          bool          `json:"local"` // Enforced by this router's fallback buckets
+	Buckets        []BucketState `json:"buckets"`
+}
+
+// bucketScope is one org or API key whose buckets are inspected or reset.
+type bucketScope struct {
+	scope, id string
+}
+
+func (s bucketScope) rateKey() string        { return fmt.Sprintf("rate_limit:%s:%s", s.scope, s.id) }
+func (s bucketScope) concurrencyKey() string { return fmt.Sprintf("concurrency:%s:%s", s.scope, s.id) }
+
+func orgScopes(orgID string, apiKeyIDs []string) []bucketScope {
+	scopes := []bucketScope{{"org", orgID}}
+	for _, id := range apiKeyIDs {
+		scopes = append(scopes, bucketScope{"key", id})
+	}
+	return scopes
+}
+
+// InspectOrganization returns the current buckets of an org and of the given
+// API keys. Limits are reported for the router defaults, which is what the
+// buckets are checked against.
+func (r *RateLimiter) InspectOrganization(ctx context.Context, orgID string, apiKeyIDs []string) (*Buckets, error) {
+	result := &Buckets{OrganizationID: orgID, Local: r.Degraded(), Buckets: []BucketState{}}
+	now := time.Now()
+	for _, scope := range orgScopes(orgID, apiKeyIDs) {
+		var states []BucketState
+		if result.Local {
+			states = r.local.inspect(scope, now)
+		} else {
+			var err error
+			if states, err = r.inspectRedis(ctx, scope, now); err != nil {
+				return nil, err
+			}
+		}
+		result.Buckets = append(result.Buckets, states...)
+	}
+	return result, nil
+}
+
+// inspectRedis reads the shared buckets of one scope without consuming from them.
+func (r *RateLimiter) inspectRedis(ctx context.Context, scope bucketScope, now time.Time) ([]BucketState, error) {
+	var states []BucketState
+	key := scope.rateKey()
+	rps, burst := r.defaultRPS, r.burstSize
+
+	bucket, err := r.client.HMGet(ctx, key, "tokens", "last_refill").Result()
+	if err != nil {
+		return nil, fmt.Errorf("read token bucket: %w", err)
+	}
+	if tokens, lastRefill, ok := parseBucket(bucket); ok {
+		interval := 1 / float64(rps)
+		elapsed := float64(now.UnixNano())/float64(time.Second) - lastRefill
+		tokens = math.Min(float64(burst), tokens+math.Floor(math.Max(0, elapsed)/interval))
+		states = append(states, BucketState{
+			Scope: scope.scope, ID: scope.id, Algorithm: AlgorithmTokenBucket,
+			Remaining: int(tokens), Limit: burst,
+			ResetAfterMS: int64((float64(burst) - tokens) * interval * 1000),
+		})
+	}
+
+	window := windowMillis(rps, burst)
+	windowKey := fmt.Sprintf("%s:fixed:%d", key, now.UnixMilli()/window)
+	count, err := r.client.Get(ctx, windowKey).Int()
+	switch {
+	case errors.Is(err, redis.Nil):
+	case err != nil:
+		return nil, fmt.Errorf("read fixed window: %w", err)
+	default:
+		ttl, _ := r.client.PTTL(ctx, windowKey).Result()
+		states = append(states, BucketState{
+			Scope: scope.scope, ID: scope.id, Algorithm: AlgorithmFixedWindow,
+			Remaining: max(burst-count, 0), Limit: burst, ResetAfterMS: max(ttl.Milliseconds(), 0),
+		})
+	}
+
+	sliding, err := r.client.ZCount(ctx, key+":sliding", "("+strconv.FormatInt(now.UnixMilli()-window, 10), "+inf").Result()
+	if err != nil {
+		return nil, fmt.Errorf("read sliding window: %w", err)
+	}
+	if sliding > 0 {
+		states = append(states, BucketState{
+			Scope: scope.scope, ID: scope.id, Algorithm: AlgorithmSlidingWindow,
+			Remaining: max(burst-int(sliding), 0), Limit: burst,
+		})
+	}
+
+	inFlight, err := r.client.ZCount(ctx, scope.concurrencyKey(), "("+strconv.FormatInt(now.UnixMilli(), 10), "+inf").Result()
+	if err != nil {
+		return nil, fmt.Errorf("read concurrency slots: %w", err)
+	}
+	if inFlight > 0 {
+		states = append(states, BucketState{Scope: scope.scope, ID: scope.id, InFlight: int(inFlight)})
+	}
+	return states, nil
+}
+
+// parseBucket parses the tokens and last_refill fields of a token bucket hash.
+func parseBucket(fields []interface{}) (tokens, lastRefill float64, ok bool) {
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	tokensStr, ok1 := fields[0].(string)
+	refillStr, ok2 := fields[1].(string)
+	if !ok1 || !ok2 {
+		return 0, 0, false
+	}
+	tokens, err1 := strconv.ParseFloat(tokensStr, 64)
+	lastRefill, err2 := strconv.ParseFloat(refillStr, 64)
+	return tokens, lastRefill, err1 == nil && err2 == nil
+}
+
+// ResetOrganization refills the buckets of an org and of the given API keys
+// and frees their concurrency slots.
+func (r *RateLimiter) ResetOrganization(ctx context.Context, orgID string, apiKeyIDs []string) error {
+	scopes := orgScopes(orgID, apiKeyIDs)
+	r.local.reset(scopes)
+	if r.Degraded() {
+		return nil
+	}
+
+	window := windowMillis(r.defaultRPS, r.burstSize)
+	for _, scope := range scopes {
+		key := scope.rateKey()
+		// Deleted one at a time: the keys may live in different cluster slots
+		for _, k := range []string{
+			key,
+			key + ":sliding",
+			fmt.Sprintf("%s:fixed:%d", key, time.Now().UnixMilli()/window),
+			scope.concurrencyKey(),
+		} {
+			if err := r.Reset(ctx, k); err != nil {
+				return fmt.Errorf("reset %s: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// inspect returns the local bucket and concurrency slots of one scope.
+func (l *localLimiter) inspect(scope bucketScope, now time.Time) []BucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var states []BucketState
+	if bucket, ok := l.buckets[scope.rateKey()]; ok {
+		limit := math.Max(1, math.Floor(float64(bucket.burst)*l.share))
+		localRPS := math.Max(l.share/bucket.interval, 0.001)
+		tokens := math.Min(limit, bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*localRPS)
+		states = append(states, BucketState{
+			Scope: scope.scope, ID: scope.id, Algorithm: AlgorithmTokenBucket,
+			Remaining: int(tokens), Limit: int(limit),
+			ResetAfterMS: int64((limit - tokens) / localRPS * 1000),
+		})
+	}
+	if inFlight := l.inFlight[scope.concurrencyKey()]; inFlight > 0 {
+		states = append(states, BucketState{Scope: scope.scope, ID: scope.id, InFlight: inFlight})
+	}
+	return states
+}
+
+// reset drops the local buckets and concurrency slots of the given scopes.
+func (l *localLimiter) reset(scopes []bucketScope) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, scope := range scopes {
+		delete(l.buckets, scope.rateKey())
+		delete(l.inFlight, scope.concurrencyKey())
+	}
+}
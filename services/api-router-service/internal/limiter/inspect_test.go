@@ -0,0 +1,100 @@
+// Package limiter provides unit tests for bucket inspection and reset.
+//
+// Purpose:
+//   These tests validate that inspecting an org's buckets does not consume
+//   from them and that a reset refills them and frees concurrency slots.
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestRateLimiter_InspectAndResetLocal tests inspection and reset of local buckets.
+func TestRateLimiter_InspectAndResetLocal(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 1, 5)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.CheckOrganization(ctx, "org-1"); err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+	if _, _, err := limiter.AcquireConcurrency(ctx, "org", "org-1", 3); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	buckets, err := limiter.InspectOrganization(ctx, "org-1", nil)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if !buckets.Local || len(buckets.Buckets) != 2 {
+		t.Fatalf("expected local token bucket and concurrency slots, got %+v", buckets)
+	}
+	if b := buckets.Buckets[0]; b.Algorithm != AlgorithmTokenBucket || b.Remaining != 3 || b.Limit != 5 {
+		t.Errorf("unexpected token bucket %+v", b)
+	}
+	if b := buckets.Buckets[1]; b.InFlight != 1 {
+		t.Errorf("unexpected concurrency slots %+v", b)
+	}
+
+	// Inspecting again must not have consumed a token
+	again, _ := limiter.InspectOrganization(ctx, "org-1", nil)
+	if again.Buckets[0].Remaining != 3 {
+		t.Errorf("expected inspection to leave 3 tokens, got %d", again.Buckets[0].Remaining)
+	}
+
+	if err := limiter.ResetOrganization(ctx, "org-1", nil); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	buckets, _ = limiter.InspectOrganization(ctx, "org-1", nil)
+	if len(buckets.Buckets) != 0 {
+		t.Fatalf("expected no buckets after reset, got %+v", buckets.Buckets)
+	}
+}
+
+// TestRateLimiter_InspectAndResetRedis tests inspection and reset of shared buckets.
+func TestRateLimiter_InspectAndResetRedis(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 1, 5)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.CheckOrganization(ctx, "org-1"); err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+	if _, err := limiter.CheckAPIKeyWithAlgorithm(ctx, "key-1", 0, 0, AlgorithmFixedWindow); err != nil {
+		t.Fatalf("check key: %v", err)
+	}
+
+	buckets, err := limiter.InspectOrganization(ctx, "org-1", []string{"key-1"})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if buckets.Local || len(buckets.Buckets) != 2 {
+		t.Fatalf("expected org token bucket and key fixed window, got %+v", buckets)
+	}
+	if b := buckets.Buckets[0]; b.Scope != "org" || b.Remaining != 3 {
+		t.Errorf("unexpected org bucket %+v", b)
+	}
+	if b := buckets.Buckets[1]; b.Scope != "key" || b.Algorithm != AlgorithmFixedWindow || b.Remaining != 4 {
+		t.Errorf("unexpected key bucket %+v", b)
+	}
+
+	if err := limiter.ResetOrganization(ctx, "org-1", []string{"key-1"}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	buckets, _ = limiter.InspectOrganization(ctx, "org-1", []string{"key-1"})
+	if len(buckets.Buckets) != 0 {
+		t.Fatalf("expected no buckets after reset, got %+v", buckets.Buckets)
+	}
+}
@@ -0,0 +1,206 @@
+// Package limiter provides the in-process rate limiting fallback.
+//
+// Purpose:
+//   When Redis is unreachable (or not configured) the rate limiter keeps
+//   enforcing limits with approximate in-process token buckets instead of
+//   disabling rate limiting. A background loop probes Redis and, once it
+//   recovers, reconciles the requests admitted locally into the shared
+//   buckets before switching back.
+//
+// Debugging Notes:
+//   - Local buckets only see this router's traffic; RATE_LIMIT_LOCAL_SHARE
+//     scales them (set it to 1/replicas so the fleet total stays close to the limit)
+//   - Metrics: api_router_rate_limit_fallback_active (1 while degraded),
+//     api_router_rate_limit_fallback_checks_total{limit_type}
+//   - Local buckets are always token buckets, whatever algorithm the org selected
+//   - Reconciliation deducts locally admitted requests from the Redis buckets
+//     after refilling them for the outage, so recovery never grants extra burst
+//
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// localBucket is an in-process token bucket for one rate limit key.
+type localBucket struct {
+	tokens     float64
+	lastRefill time.Time
+	admitted   int     // Requests admitted since the last reconciliation
+	interval   float64 // Shared bucket refill interval, used when reconciling
+	burst      int     // Shared bucket capacity, used when reconciling
+}
+
+// localLimiter holds the fallback buckets.
+type localLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*localBucket
+	inFlight map[string]int // Concurrency slots held per key
+	share    float64
+}
+
+func newLocalLimiter() *localLimiter {
+	return &localLimiter{buckets: make(map[string]*localBucket), inFlight: make(map[string]int), share: 1}
+}
+
+// take consumes a token from the local bucket for key, scaled by share.
+func (l *localLimiter) take(key string, rps, burst int, now time.Time) (allowed bool, remaining, limit int, refillInterval float64, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit = int(math.Max(1, math.Floor(float64(burst)*l.share)))
+	localRPS := math.Max(float64(rps)*l.share, 0.001)
+	refillInterval = 1 / localRPS
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &localBucket{tokens: float64(limit), lastRefill: now, interval: 1 / float64(rps), burst: burst}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(limit), bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*localRPS)
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) * refillInterval
+		return false, 0, limit, refillInterval, time.Duration(wait * float64(time.Second))
+	}
+	bucket.tokens--
+	bucket.admitted++
+	return true, int(bucket.tokens), limit, refillInterval, 0
+}
+
+// drain returns and clears the buckets that admitted requests.
+func (l *localLimiter) drain() map[string]*localBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	drained := make(map[string]*localBucket)
+	for key, bucket := range l.buckets {
+		if bucket.admitted > 0 {
+			drained[key] = bucket
+		}
+	}
+	l.buckets = make(map[string]*localBucket)
+	return drained
+}
+
+// reconcileScript refills the shared bucket for the outage and then deducts
+// the requests admitted locally.
+const reconcileScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local refill_interval = tonumber(ARGV[2])
+	local burst = tonumber(ARGV[3])
+	local admitted = tonumber(ARGV[4])
+
+	local bucket = redis.call('HMGET', key, 'tokens', 'last_refill')
+	local tokens = tonumber(bucket[1]) or burst
+	local last_refill = tonumber(bucket[2]) or now
+
+	tokens = math.min(burst, tokens + math.floor((now - last_refill) / refill_interval))
+	tokens = math.max(0, tokens - admitted)
+	redis.call('HSET', key, 'tokens', tokens, 'last_refill', now)
+	redis.call('EXPIRE', key, 3600)
+	return tokens
+`
+
+// SetLocalShare sets the fraction (0-1] of each limit a router enforces on
+// its own while Redis is unavailable.
+func (r *RateLimiter) SetLocalShare(share float64) {
+	if share <= 0 || share > 1 {
+		share = 1
+	}
+	r.local.mu.Lock()
+	r.local.share = share
+	r.local.mu.Unlock()
+}
+
+// Degraded reports whether limits are currently enforced by local buckets.
+func (r *RateLimiter) Degraded() bool {
+	return r.client == nil || r.degraded.Load()
+}
+
+// Run probes Redis every interval while degraded and switches back to the
+// shared buckets once it recovers. It blocks until ctx is done.
+func (r *RateLimiter) Run(ctx context.Context, interval time.Duration) {
+	if r.client == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.degraded.Load() {
+				r.recover(ctx)
+			}
+		}
+	}
+}
+
+// checkLocal enforces key with the in-process fallback bucket.
+func (r *RateLimiter) checkLocal(key string, rps, burst int) *CheckResult {
+	telemetry.RecordRateLimitFallbackCheck(limitTypeOf(key))
+	allowed, remaining, limit, refillInterval, retryAfter := r.local.take(key, rps, burst, time.Now())
+	result := r.newResult(allowed, remaining, limit, refillInterval)
+	result.RetryAfter = retryAfter
+	return result
+}
+
+// redisFailed degrades to local buckets after a Redis error rather than
+// disabling rate limiting, unless the caller already gave up on the request.
+func (r *RateLimiter) redisFailed(ctx context.Context, err error, key string, rps, burst int) (*CheckResult, error) {
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	r.fallBack(err)
+	return r.checkLocal(key, rps, burst), nil
+}
+
+// fallBack switches to local buckets after a Redis failure.
+func (r *RateLimiter) fallBack(err error) {
+	if r.degraded.CompareAndSwap(false, true) {
+		r.logger.Warn("Redis rate limiting unavailable, falling back to local token buckets", zap.Error(err))
+		telemetry.SetRateLimitFallbackActive(true)
+	}
+}
+
+// recover reconciles local consumption into Redis and leaves fallback mode.
+func (r *RateLimiter) recover(ctx context.Context) {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return
+	}
+
+	// Stop admitting locally first so nothing is admitted after the drain
+	r.degraded.Store(false)
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+	failed := 0
+	for key, bucket := range r.local.drain() {
+		if err := r.client.Eval(ctx, reconcileScript, []string{key}, now, bucket.interval, bucket.burst, bucket.admitted).Err(); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		r.logger.Warn("failed to reconcile some local rate limit buckets", zap.Int("failed", failed))
+	}
+	telemetry.SetRateLimitFallbackActive(false)
+	r.logger.Info("Redis rate limiting recovered, local buckets reconciled")
+}
+
+// limitTypeOf returns "org" or "key" for a rate limit key.
+func limitTypeOf(key string) string {
+	if strings.HasPrefix(key, "rate_limit:key:") {
+		return "key"
+	}
+	return "org"
+}
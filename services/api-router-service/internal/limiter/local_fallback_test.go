@@ -0,0 +1,95 @@
+// Package limiter provides unit tests for the local rate limiting fallback.
+//
+// Purpose:
+//   These tests validate local token buckets, the per-router share, falling
+//   back when Redis is unreachable, and reconciliation once Redis recovers.
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// TestRateLimiter_LocalOnly tests enforcement without any Redis client.
+func TestRateLimiter_LocalOnly(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 1, 3)
+	if !limiter.Degraded() {
+		t.Fatal("expected limiter without Redis to use local buckets")
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		result, err := limiter.CheckOrganization(ctx, "org-1")
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: expected allowed, got %+v, %v", i+1, result, err)
+		}
+	}
+	result, err := limiter.CheckOrganization(ctx, "org-1")
+	if err != nil || result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("expected denial with retry after, got %+v, %v", result, err)
+	}
+
+	// Other organizations have their own buckets
+	if result, _ := limiter.CheckOrganization(ctx, "org-2"); !result.Allowed {
+		t.Error("expected a separate bucket per organization")
+	}
+}
+
+// TestRateLimiter_LocalShare tests scaling local limits per router.
+func TestRateLimiter_LocalShare(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 10, 20)
+	limiter.SetLocalShare(0.25)
+
+	result, err := limiter.CheckAPIKey(context.Background(), "key-1", 0, 0)
+	if err != nil || result.Limit != 5 || result.Remaining != 4 {
+		t.Fatalf("expected a quarter of the burst, got %+v, %v", result, err)
+	}
+}
+
+// TestRateLimiter_FallsBackWhenRedisDown tests that Redis errors degrade to local buckets.
+func TestRateLimiter_FallsBackWhenRedisDown(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer func() { _ = client.Close() }()
+	limiter := NewRateLimiter(client, zap.NewNop(), 10, 20)
+
+	result, err := limiter.CheckOrganization(context.Background(), "org-1")
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected local fallback to allow, got %+v, %v", result, err)
+	}
+	if !limiter.Degraded() {
+		t.Fatal("expected limiter to be degraded after a Redis failure")
+	}
+}
+
+// TestRateLimiter_Reconcile tests that locally admitted requests are deducted
+// from the shared bucket when Redis recovers.
+func TestRateLimiter_Reconcile(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 1, 20)
+	limiter.degraded.Store(true)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := limiter.CheckOrganization(ctx, "org-reconcile"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	limiter.recover(ctx)
+	if limiter.Degraded() {
+		t.Fatal("expected limiter to leave fallback after recovery")
+	}
+	tokens, err := client.HGet(ctx, "rate_limit:org:org-reconcile", "tokens").Int()
+	if err != nil || tokens != 15 {
+		t.Fatalf("expected 15 tokens after reconciliation, got %d, %v", tokens, err)
+	}
+}
@@ -1,17 +1,22 @@
 // Package limiter provides rate limiting and budget enforcement for API requests.
 //
 // Purpose:
-//   This package implements Redis-backed token bucket rate limiting and budget
+//   This package implements Redis-backed rate limiting (token bucket, fixed
+//   window or sliding window log), concurrent request caps, and budget
 //   checking to enforce fair usage and prevent over-spending.
 //
 // Dependencies:
-//   - github.com/redis/go-redis/v9: Redis client for token bucket storage
+//   - github.com/redis/go-redis/v9: Redis (standalone or cluster) client for token bucket storage
 //
 // Key Responsibilities:
 //   - Token bucket rate limiting per organization and per API key
+//   - Fixed and sliding window algorithms, selectable per org (see windows.go)
+//   - Max concurrent requests per org/key (see concurrency.go)
 //   - Configurable RPS and burst size
+//   - Soft threshold reporting so clients can back off before denials
 //   - Thread-safe operations
 //   - Fast sub-millisecond checks
+//   - Local token bucket fallback while Redis is unavailable (see local_fallback.go)
 //
 // Requirements Reference:
 //   - specs/006-api-router-service/spec.md#US-002 (Enforce budgets and safe usage)
@@ -21,50 +26,90 @@ package limiter
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-// RateLimiter implements token bucket rate limiting using Redis.
+// RateLimiter implements rate limiting using Redis.
 type RateLimiter struct {
-	client    *redis.Client
+	client    redis.UniversalClient
 	logger    *zap.Logger
 	defaultRPS int
 	burstSize  int
+	softLimit  float64       // Fraction of the bucket consumed before NearLimit is reported
+	algorithm  Algorithm     // Used when the caller does not choose an algorithm
+	leaseTTL   time.Duration // Concurrency slots expire after this if never released
+	streams    StreamLimits  // Default stream caps and heartbeat TTL
+
+	local    *localLimiter // In-process buckets used while Redis is unavailable
+	degraded atomic.Bool
 }
 
-// NewRateLimiter creates a new rate limiter.
-func NewRateLimiter(client *redis.Client, logger *zap.Logger, defaultRPS, burstSize int) *RateLimiter {
+// NewRateLimiter creates a new rate limiter. client may be a *redis.Client or a
+// *redis.ClusterClient; a nil client enforces limits with local buckets only.
+func NewRateLimiter(client redis.UniversalClient, logger *zap.Logger, defaultRPS, burstSize int) *RateLimiter {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
+	if c, ok := client.(*redis.Client); ok && c == nil {
+		client = nil
+	}
 	return &RateLimiter{
 		client:     client,
 		logger:     logger,
 		defaultRPS: defaultRPS,
 		burstSize:  burstSize,
+		softLimit:  DefaultSoftThreshold,
+		algorithm:  AlgorithmTokenBucket,
+		leaseTTL:   DefaultConcurrencyLease,
+		streams:    StreamLimits{SlotTTL: DefaultStreamSlotTTL},
+		local:      newLocalLimiter(),
 	}
 }
 
+// DefaultSoftThreshold is the fraction of a bucket consumed before clients are warned.
+const DefaultSoftThreshold = 0.8
+
+// SetSoftThreshold sets the fraction of a bucket (0-1) that may be consumed
+// before results report NearLimit. Zero disables soft warnings.
+func (r *RateLimiter) SetSoftThreshold(threshold float64) {
+	r.softLimit = threshold
+}
+
 // CheckResult represents the result of a rate limit check.
 type CheckResult struct {
 	Allowed      bool
 	RetryAfter   time.Duration
 	Remaining    int
 	Limit        int
+	ResetAfter   time.Duration // Time until the bucket is full again
+	NearLimit    bool          // Usage crossed the soft threshold
 }
 
 // CheckOrganization checks if a request from an organization is allowed.
 // Returns CheckResult with allowed status and retry information.
 func (r *RateLimiter) CheckOrganization(ctx context.Context, orgID string) (*CheckResult, error) {
-	return r.check(ctx, fmt.Sprintf("rate_limit:org:%s", orgID), r.defaultRPS, r.burstSize)
+	return r.CheckOrganizationWithAlgorithm(ctx, orgID, "")
+}
+
+// CheckOrganizationWithAlgorithm checks an organization's rate limit with the
+// given algorithm; an empty algorithm uses the limiter's default.
+func (r *RateLimiter) CheckOrganizationWithAlgorithm(ctx context.Context, orgID string, algorithm Algorithm) (*CheckResult, error) {
+	return r.check(ctx, fmt.Sprintf("rate_limit:org:%s", orgID), r.defaultRPS, r.burstSize, algorithm)
 }
 
 // CheckAPIKey checks if a request from an API key is allowed.
 // Returns CheckResult with allowed status and retry information.
 func (r *RateLimiter) CheckAPIKey(ctx context.Context, apiKeyID string, rps, burst int) (*CheckResult, error) {
+	return r.CheckAPIKeyWithAlgorithm(ctx, apiKeyID, rps, burst, "")
+}
+
+// CheckAPIKeyWithAlgorithm checks an API key's rate limit with the given
+// algorithm; an empty algorithm uses the limiter's default.
+func (r *RateLimiter) CheckAPIKeyWithAlgorithm(ctx context.Context, apiKeyID string, rps, burst int, algorithm Algorithm) (*CheckResult, error) {
 	// Use provided limits or fall back to defaults
 	if rps <= 0 {
 		rps = r.defaultRPS
@@ -72,16 +117,35 @@ func (r *RateLimiter) CheckAPIKey(ctx context.Context, apiKeyID string, rps, bur
 	if burst <= 0 {
 		burst = r.burstSize
 	}
-	return r.check(ctx, fmt.Sprintf("rate_limit:key:%s", apiKeyID), rps, burst)
+	return r.check(ctx, fmt.Sprintf("rate_limit:key:%s", apiKeyID), rps, burst, algorithm)
 }
 
-// check performs the token bucket check using Redis.
+// check dispatches to the selected algorithm, using local buckets while Redis
+// is unavailable.
+func (r *RateLimiter) check(ctx context.Context, key string, rps, burst int, algorithm Algorithm) (*CheckResult, error) {
+	if r.Degraded() {
+		return r.checkLocal(key, rps, burst), nil
+	}
+	if algorithm == "" {
+		algorithm = r.algorithm
+	}
+	switch algorithm {
+	case AlgorithmFixedWindow:
+		return r.checkFixedWindow(ctx, key, rps, burst)
+	case AlgorithmSlidingWindow:
+		return r.checkSlidingWindow(ctx, key, rps, burst)
+	default:
+		return r.checkTokenBucket(ctx, key, rps, burst)
+	}
+}
+
+// checkTokenBucket performs the token bucket check using Redis.
 // Implements token bucket algorithm:
 // - Each bucket has a capacity (burst size)
 // - Tokens refill at rate RPS per second
 // - Each request consumes one token
 // - If no tokens available, request is denied
-func (r *RateLimiter) check(ctx context.Context, key string, rps, burst int) (*CheckResult, error) {
+func (r *RateLimiter) checkTokenBucket(ctx context.Context, key string, rps, burst int) (*CheckResult, error) {
 	now := time.Now()
 	// Use Unix timestamp with fractional seconds for precision (millisecond precision)
 	nowUnixFloat := float64(now.UnixNano()) / float64(time.Second)
@@ -129,13 +193,9 @@ func (r *RateLimiter) check(ctx context.Context, key string, rps, burst int) (*C
 			tokens := burst - 1
 			r.client.HSet(ctx, key, "tokens", tokens, "last_refill", nowUnixFloat)
 			r.client.Expire(ctx, key, time.Hour)
-			return &CheckResult{
-				Allowed:    true,
-				Remaining:  tokens,
-				Limit:      burst,
-			}, nil
+			return r.newResult(true, tokens, burst, refillInterval), nil
 		}
-		return nil, fmt.Errorf("rate limit check failed: %w", err)
+		return r.redisFailed(ctx, err, key, rps, burst)
 	}
 	
 	// Parse result from Lua script
@@ -148,11 +208,7 @@ func (r *RateLimiter) check(ctx context.Context, key string, rps, burst int) (*C
 	remaining := int(results[1].(int64))
 	limit := int(results[2].(int64))
 	
-	checkResult := &CheckResult{
-		Allowed:   allowed,
-		Remaining: remaining,
-		Limit:     limit,
-	}
+	checkResult := r.newResult(allowed, remaining, limit, refillInterval)
 	
 	// If denied, calculate retry after
 	if !allowed {
@@ -187,6 +243,18 @@ func (r *RateLimiter) check(ctx context.Context, key string, rps, burst int) (*C
 	return checkResult, nil
 }
 
+// newResult builds a CheckResult with the reset time and soft threshold filled in.
+func (r *RateLimiter) newResult(allowed bool, remaining, limit int, refillInterval float64) *CheckResult {
+	used := limit - remaining
+	return &CheckResult{
+		Allowed:    allowed,
+		Remaining:  remaining,
+		Limit:      limit,
+		ResetAfter: time.Duration(float64(used) * refillInterval * float64(time.Second)),
+		NearLimit:  r.softLimit > 0 && limit > 0 && float64(used) >= r.softLimit*float64(limit),
+	}
+}
+
 // Reset resets the rate limit for a given key (useful for testing).
 func (r *RateLimiter) Reset(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
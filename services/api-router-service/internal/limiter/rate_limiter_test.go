@@ -366,3 +366,24 @@ func TestRateLimiter_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+// TestRateLimiter_SoftThreshold tests reset times and soft threshold reporting.
+func TestRateLimiter_SoftThreshold(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 10, 20) // 0.1s per token
+
+	result := limiter.newResult(true, 10, 20, 0.1)
+	if result.NearLimit {
+		t.Error("expected half-used bucket to be below the default soft threshold")
+	}
+	if result.ResetAfter != time.Second {
+		t.Errorf("expected reset after 1s, got %v", result.ResetAfter)
+	}
+
+	if result := limiter.newResult(true, 4, 20, 0.1); !result.NearLimit {
+		t.Error("expected bucket with 80% used to be near the limit")
+	}
+
+	limiter.SetSoftThreshold(0)
+	if result := limiter.newResult(false, 0, 20, 0.1); result.NearLimit {
+		t.Error("expected soft warnings disabled with a zero threshold")
+	}
+}
@@ -0,0 +1,167 @@
+// Package limiter provides max-concurrent-stream limits.
+//
+// Purpose:
+//   Streaming connections hold a backend for as long as the model generates,
+//   so orgs and keys can be capped on open streams separately from requests.
+//   Stream slots are Redis semaphores shared by all routers; each open stream
+//   renews its slot with a heartbeat, so a slot whose router died is
+//   reclaimed within STREAM_SLOT_TTL rather than the request lease.
+//
+// Debugging Notes:
+//   - Slots live in streams:<scope>:<id>, scored by heartbeat expiry;
+//     heartbeats run every third of the TTL
+//   - Reclaimed slots count in api_router_stream_slots_reclaimed_total; a
+//     steady rate there means routers are dying or stalling mid-stream
+//   - While Redis is unavailable slots are counted per router, scaled by
+//     RATE_LIMIT_LOCAL_SHARE
+//
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// DefaultStreamSlotTTL is how long a stream slot outlives its last heartbeat.
+const DefaultStreamSlotTTL = 30 * time.Second
+
+// StreamLimits caps concurrent streams for orgs that set no caps of their own.
+type StreamLimits struct {
+	MaxPerOrg int           // 0 is unlimited
+	MaxPerKey int           // 0 is unlimited
+	SlotTTL   time.Duration // Slots without a heartbeat for this long are reclaimed
+}
+
+// acquireStreamScript reclaims slots that missed their heartbeats and takes
+// one when below the limit. It returns {allowed, in flight, reclaimed}.
+const acquireStreamScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local limit = tonumber(ARGV[2])
+	local ttl_ms = tonumber(ARGV[3])
+	local member = ARGV[4]
+
+	local reclaimed = redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+	local count = redis.call('ZCARD', key)
+	if count >= limit then
+		return {0, count, reclaimed}
+	end
+	redis.call('ZADD', key, now + ttl_ms, member)
+	redis.call('PEXPIRE', key, ttl_ms)
+	return {1, count + 1, reclaimed}
+`
+
+// heartbeatStreamScript extends a slot that is still held.
+const heartbeatStreamScript = `
+	local key = KEYS[1]
+	local expiry = tonumber(ARGV[1])
+	local ttl_ms = tonumber(ARGV[2])
+	local member = ARGV[3]
+
+	if not redis.call('ZSCORE', key, member) then
+		return 0
+	end
+	redis.call('ZADD', key, expiry, member)
+	redis.call('PEXPIRE', key, ttl_ms)
+	return 1
+`
+
+// SetStreamLimits sets the default stream caps and slot TTL.
+func (r *RateLimiter) SetStreamLimits(limits StreamLimits) {
+	if limits.SlotTTL <= 0 {
+		limits.SlotTTL = DefaultStreamSlotTTL
+	}
+	r.streams = limits
+}
+
+// StreamLimits returns the default stream caps.
+func (r *RateLimiter) StreamLimits() StreamLimits {
+	return r.streams
+}
+
+// AcquireStream takes one of limit concurrent stream slots for the scope
+// ("org" or "key") and id, and keeps it alive with heartbeats. When allowed,
+// release must be called once the stream ends; it is a no-op otherwise.
+func (r *RateLimiter) AcquireStream(ctx context.Context, scope, id string, limit int) (*ConcurrencyResult, func(), error) {
+	key := fmt.Sprintf("streams:%s:%s", scope, id)
+	if r.Degraded() {
+		result, release := r.local.acquire(key, limit)
+		return result, release, nil
+	}
+
+	ttl := r.streams.SlotTTL
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatUint(slotSeq.Add(1), 36)
+	res, err := r.client.Eval(ctx, acquireStreamScript, []string{key}, now.UnixMilli(), limit, ttl.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, func() {}, fmt.Errorf("stream limit check failed: %w", err)
+		}
+		r.fallBack(err)
+		result, release := r.local.acquire(key, limit)
+		return result, release, nil
+	}
+	if len(res) < 3 {
+		return nil, func() {}, fmt.Errorf("unexpected stream limit result format")
+	}
+	if res[2] > 0 {
+		telemetry.RecordStreamSlotsReclaimed(scope, int(res[2]))
+	}
+
+	result := &ConcurrencyResult{Allowed: res[0] == 1, InFlight: int(res[1]), Limit: limit}
+	if !result.Allowed {
+		return result, func() {}, nil
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go r.heartbeatStream(key, member, ttl, stop, done)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+			releaseCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := r.client.ZRem(releaseCtx, key, member).Err(); err != nil {
+				r.logger.Debug("failed to release stream slot; it expires without heartbeats", zap.Error(err))
+			}
+		})
+	}
+	return result, release, nil
+}
+
+// heartbeatStream renews a stream slot until stop is closed.
+func (r *RateLimiter) heartbeatStream(key, member string, ttl time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+			held, err := r.client.Eval(ctx, heartbeatStreamScript, []string{key}, now.Add(ttl).UnixMilli(), ttl.Milliseconds(), member).Int()
+			cancel()
+			switch {
+			case err != nil:
+				// Retried on the next tick; the slot survives until its TTL
+				r.logger.Debug("stream slot heartbeat failed", zap.String("key", key), zap.Error(err))
+			case held == 0:
+				// Reclaimed after missed heartbeats; the stream keeps running
+				// but no longer counts against the cap
+				r.logger.Warn("stream slot was reclaimed while the stream was open", zap.String("key", key))
+				return
+			}
+		}
+	}
+}
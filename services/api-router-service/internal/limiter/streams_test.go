@@ -0,0 +1,93 @@
+// Package limiter provides unit tests for concurrent stream limits.
+//
+// Purpose:
+//   These tests validate stream slots with the local fallback and, when Redis
+//   is available, heartbeats keeping slots alive and reclaiming slots whose
+//   heartbeats stopped.
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func TestAcquireStreamLocal(t *testing.T) {
+	limiter := NewRateLimiter(nil, zap.NewNop(), 10, 20)
+	ctx := context.Background()
+
+	first, release, err := limiter.AcquireStream(ctx, "org", "org-1", 1)
+	if err != nil || !first.Allowed {
+		t.Fatalf("expected stream slot, got %+v, %v", first, err)
+	}
+	if denied, _, _ := limiter.AcquireStream(ctx, "org", "org-1", 1); denied.Allowed {
+		t.Fatal("expected denial while the stream is open")
+	}
+	// Stream slots are separate from request slots
+	if request, _, _ := limiter.AcquireConcurrency(ctx, "org", "org-1", 1); !request.Allowed {
+		t.Fatal("expected request slot to be independent of stream slots")
+	}
+	release()
+	if again, _, _ := limiter.AcquireStream(ctx, "org", "org-1", 1); !again.Allowed {
+		t.Fatal("expected a stream slot after release")
+	}
+}
+
+func TestAcquireStreamRedisHeartbeat(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 10, 20)
+	limiter.SetStreamLimits(StreamLimits{SlotTTL: 300 * time.Millisecond})
+	ctx := context.Background()
+
+	result, release, err := limiter.AcquireStream(ctx, "key", "key-1", 1)
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected stream slot, got %+v, %v", result, err)
+	}
+	// Heartbeats keep the slot past its TTL
+	time.Sleep(time.Second)
+	if denied, _, _ := limiter.AcquireStream(ctx, "key", "key-1", 1); denied.Allowed {
+		t.Fatal("expected heartbeated slot to still be held")
+	}
+	release()
+	if result, release, _ := limiter.AcquireStream(ctx, "key", "key-1", 1); !result.Allowed {
+		t.Fatal("expected slot after release")
+	} else {
+		release()
+	}
+}
+
+func TestAcquireStreamRedisReclaimsLeakedSlot(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	ttl := 300 * time.Millisecond
+	// A slot left behind by a router that died without releasing it
+	if err := client.ZAdd(ctx, "streams:org:org-1", redis.Z{Score: float64(time.Now().Add(ttl).UnixMilli()), Member: "dead-router"}).Err(); err != nil {
+		t.Fatalf("seed slot: %v", err)
+	}
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 10, 20)
+	limiter.SetStreamLimits(StreamLimits{SlotTTL: ttl})
+	if denied, _, _ := limiter.AcquireStream(ctx, "org", "org-1", 1); denied.Allowed {
+		t.Fatal("expected leaked slot to count until its TTL passes")
+	}
+	time.Sleep(2 * ttl)
+	result, release, err := limiter.AcquireStream(ctx, "org", "org-1", 1)
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected leaked slot to be reclaimed, got %+v, %v", result, err)
+	}
+	release()
+}
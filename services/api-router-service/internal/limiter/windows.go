@@ -0,0 +1,148 @@
+// Package limiter provides fixed and sliding window rate limiting.
+//
+// Purpose:
+//   Besides the default token bucket, an org can select a fixed window or a
+//   sliding window log. Both allow burst requests per window of burst/RPS
+//   seconds, so the long-run rate and the largest burst match the token
+//   bucket with the same settings.
+//
+// Debugging Notes:
+//   - Fixed windows use rate_limit:<scope>:<id>:fixed:<window index> counters
+//     that expire with the window; a client can get up to 2x burst across a
+//     window boundary
+//   - Sliding windows keep a sorted set of request timestamps in
+//     rate_limit:<scope>:<id>:sliding, so memory grows with the burst size
+//   - While Redis is unavailable all algorithms fall back to local token buckets
+//
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Algorithm selects how request rates are limited.
+type Algorithm string
+
+// Supported rate limit algorithms.
+const (
+	AlgorithmTokenBucket   Algorithm = "token_bucket"
+	AlgorithmFixedWindow   Algorithm = "fixed_window"
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+)
+
+// ParseAlgorithm validates an algorithm name; empty selects the token bucket.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch algorithm := Algorithm(name); algorithm {
+	case "":
+		return AlgorithmTokenBucket, nil
+	case AlgorithmTokenBucket, AlgorithmFixedWindow, AlgorithmSlidingWindow:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown rate limit algorithm %q", name)
+	}
+}
+
+// SetAlgorithm sets the algorithm used when a check does not select one.
+func (r *RateLimiter) SetAlgorithm(algorithm Algorithm) {
+	r.algorithm = algorithm
+}
+
+// windowMillis is the window length that allows burst requests at rps.
+func windowMillis(rps, burst int) int64 {
+	return int64(math.Max(1, math.Ceil(float64(burst)*1000/float64(rps))))
+}
+
+// fixedWindowScript counts requests in the current window.
+const fixedWindowScript = `
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local window_ms = tonumber(ARGV[2])
+
+	local count = redis.call('INCR', key)
+	if count == 1 then
+		redis.call('PEXPIRE', key, window_ms)
+	end
+	local ttl = redis.call('PTTL', key)
+	if count > limit then
+		return {0, 0, ttl}
+	end
+	return {1, limit - count, ttl} -- allowed, remaining, ms until the window resets
+`
+
+// checkFixedWindow allows burst requests per fixed window.
+func (r *RateLimiter) checkFixedWindow(ctx context.Context, key string, rps, burst int) (*CheckResult, error) {
+	window := windowMillis(rps, burst)
+	windowKey := fmt.Sprintf("%s:fixed:%d", key, time.Now().UnixMilli()/window)
+
+	res, err := r.client.Eval(ctx, fixedWindowScript, []string{windowKey}, burst, window).Int64Slice()
+	if err != nil {
+		return r.redisFailed(ctx, err, key, rps, burst)
+	}
+	if len(res) < 3 {
+		return nil, fmt.Errorf("unexpected rate limit result format")
+	}
+	return r.windowResult(res[0] == 1, int(res[1]), burst, time.Duration(res[2])*time.Millisecond), nil
+}
+
+// slidingWindowScript keeps a log of request timestamps within the window.
+const slidingWindowScript = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local limit = tonumber(ARGV[2])
+	local window_ms = tonumber(ARGV[3])
+	local member = ARGV[4]
+
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window_ms)
+	local count = redis.call('ZCARD', key)
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local reset = 0
+	if oldest[2] then
+		reset = tonumber(oldest[2]) + window_ms - now
+	end
+	if count >= limit then
+		return {0, 0, reset}
+	end
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window_ms)
+	if count == 0 then
+		reset = window_ms
+	end
+	return {1, limit - count - 1, reset} -- allowed, remaining, ms until the oldest request leaves the window
+`
+
+// slidingMemberSeq makes sorted set members unique within a millisecond.
+var slidingMemberSeq atomic.Uint64
+
+// checkSlidingWindow allows burst requests in any window of burst/rps seconds.
+func (r *RateLimiter) checkSlidingWindow(ctx context.Context, key string, rps, burst int) (*CheckResult, error) {
+	now := time.Now().UnixMilli()
+	member := strconv.FormatInt(now, 10) + "-" + strconv.FormatUint(slidingMemberSeq.Add(1), 10)
+
+	res, err := r.client.Eval(ctx, slidingWindowScript, []string{key + ":sliding"}, now, burst, windowMillis(rps, burst), member).Int64Slice()
+	if err != nil {
+		return r.redisFailed(ctx, err, key, rps, burst)
+	}
+	if len(res) < 3 {
+		return nil, fmt.Errorf("unexpected rate limit result format")
+	}
+	return r.windowResult(res[0] == 1, int(res[1]), burst, time.Duration(res[2])*time.Millisecond), nil
+}
+
+// windowResult builds a CheckResult for the window algorithms, where a denied
+// request can retry once the window resets.
+func (r *RateLimiter) windowResult(allowed bool, remaining, limit int, resetAfter time.Duration) *CheckResult {
+	result := r.newResult(allowed, remaining, limit, 0)
+	result.ResetAfter = resetAfter
+	if !allowed {
+		result.RetryAfter = resetAfter
+		if result.RetryAfter <= 0 {
+			result.RetryAfter = time.Millisecond
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+// Package limiter provides unit tests for window-based rate limiting.
+//
+// Purpose:
+//   These tests validate algorithm parsing, window sizing, and the fixed and
+//   sliding window algorithms against Redis (skipped when Redis is absent).
+//
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseAlgorithm(t *testing.T) {
+	for name, want := range map[string]Algorithm{
+		"":               AlgorithmTokenBucket,
+		"token_bucket":   AlgorithmTokenBucket,
+		"fixed_window":   AlgorithmFixedWindow,
+		"sliding_window": AlgorithmSlidingWindow,
+	} {
+		if got, err := ParseAlgorithm(name); err != nil || got != want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseAlgorithm("leaky_bucket"); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+}
+
+func TestWindowMillis(t *testing.T) {
+	if got := windowMillis(100, 200); got != 2000 {
+		t.Errorf("expected a 2s window for 200 burst at 100 RPS, got %dms", got)
+	}
+	if got := windowMillis(1000, 1); got != 1 {
+		t.Errorf("expected a 1ms window, got %dms", got)
+	}
+}
+
+// TestRateLimiter_WindowAlgorithms tests that both window algorithms allow
+// burst requests and then deny with a retry time.
+func TestRateLimiter_WindowAlgorithms(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	limiter := NewRateLimiter(client, zap.NewNop(), 1, 3) // 3 requests per 3s window
+	ctx := context.Background()
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmSlidingWindow} {
+		orgID := "window-" + string(algorithm)
+		for i := 0; i < 3; i++ {
+			result, err := limiter.CheckOrganizationWithAlgorithm(ctx, orgID, algorithm)
+			if err != nil || !result.Allowed || result.Remaining != 2-i {
+				t.Fatalf("%s request %d: expected allowed, got %+v, %v", algorithm, i+1, result, err)
+			}
+		}
+		result, err := limiter.CheckOrganizationWithAlgorithm(ctx, orgID, algorithm)
+		if err != nil || result.Allowed || result.RetryAfter <= 0 {
+			t.Fatalf("%s: expected denial with retry after, got %+v, %v", algorithm, result, err)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Package logexport streams an org's request and audit logs to a destination
+// the customer owns.
+//
+// Purpose:
+//   Orgs that opt in (user-org-service metadata "log_export") get their access
+//   log lines and audit events delivered as newline-delimited JSON to an S3
+//   bucket the router writes with an assumed IAM role, or to an HTTPS endpoint
+//   receiving HMAC-signed POSTs.
+//
+// Key Responsibilities:
+//   - Keep each org's latest export policy, refreshed on every authentication
+//   - Filter entries by stream and minimum status, and redact chosen fields
+//   - Batch per org and destination, flushing on size or interval
+//   - Never block or fail the request path
+//
+// Debugging Notes:
+//   - S3 objects live at {prefix}/{org_id}/YYYY/MM/DD/{time}-{uuid}.ndjson
+//   - HTTPS batches carry X-Log-Export-Timestamp and X-Log-Export-Signature
+//     (sha256=HMAC over "{timestamp}.{body}")
+//   - Outcomes are counted in api_router_log_export_entries_total; failed
+//     batches are not retried
+//
+package logexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// Streams an org can export.
+const (
+	StreamRequest = "request"
+	StreamAudit   = "audit"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Policy is an org's log export setting, taken from API key validation.
+type Policy struct {
+	Destination Destination
+	// Streams lists the exported streams; empty exports all of them.
+	Streams []string
+	// MinStatus drops request entries with a lower HTTP status.
+	MinStatus int
+	// RedactFields are replaced with "[REDACTED]" before entries leave the router.
+	RedactFields []string
+}
+
+// allows reports whether entry passes the policy's stream and status filters.
+func (p Policy) allows(entry Entry) bool {
+	if len(p.Streams) > 0 {
+		found := false
+		for _, stream := range p.Streams {
+			if stream == entry.Stream {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.MinStatus > 0 && entry.Stream == StreamRequest {
+		if status, ok := entry.Fields["status"].(int); ok && status < p.MinStatus {
+			return false
+		}
+	}
+	return true
+}
+
+// redact returns a copy of fields with the policy's fields redacted.
+func (p Policy) redact(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		out[k] = v
+	}
+	for _, name := range p.RedactFields {
+		if _, ok := out[name]; ok {
+			out[name] = redactedValue
+		}
+	}
+	return out
+}
+
+// Entry is one exported log line.
+type Entry struct {
+	Stream string         `json:"stream"`
+	Time   time.Time      `json:"time"`
+	OrgID  string         `json:"org_id"`
+	Fields map[string]any `json:"fields"`
+}
+
+// Config configures an Exporter.
+type Config struct {
+	// NewSink builds the sink for a destination; defaults to NewSink.
+	NewSink       SinkFactory
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+	Logger        *zap.Logger
+}
+
+// Exporter batches and delivers org log entries in the background.
+type Exporter struct {
+	newSink       SinkFactory
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan queuedEntry
+	logger        *zap.Logger
+
+	mu       sync.RWMutex
+	policies map[string]Policy
+
+	stopped chan struct{}
+	done    chan struct{}
+}
+
+type queuedEntry struct {
+	entry       Entry
+	destination Destination
+}
+
+// batchKey groups entries that are delivered together.
+type batchKey struct {
+	orgID       string
+	destination Destination
+}
+
+type batch struct {
+	body    bytes.Buffer
+	streams map[string]int
+	count   int
+}
+
+// New creates an exporter and starts its delivery worker.
+func New(cfg Config) *Exporter {
+	if cfg.NewSink == nil {
+		cfg.NewSink = NewSink
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	e := &Exporter{
+		newSink:       cfg.NewSink,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		queue:         make(chan queuedEntry, cfg.QueueSize),
+		logger:        cfg.Logger.With(zap.String("component", "logexport")),
+		policies:      make(map[string]Policy),
+		stopped:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// SetPolicy records an org's current policy; nil turns export off for the org.
+func (e *Exporter) SetPolicy(orgID string, policy *Policy) {
+	if orgID == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if policy == nil {
+		delete(e.policies, orgID)
+		return
+	}
+	e.policies[orgID] = *policy
+}
+
+// Export queues an entry for its org's destination. It never blocks; entries
+// for orgs without a policy are ignored and entries are dropped when the
+// queue is full or the exporter is stopping.
+func (e *Exporter) Export(entry Entry) {
+	e.mu.RLock()
+	policy, ok := e.policies[entry.OrgID]
+	e.mu.RUnlock()
+	if !ok {
+		return
+	}
+	if !policy.allows(entry) {
+		telemetry.RecordLogExport(entry.Stream, "filtered", 1)
+		return
+	}
+	entry.Fields = policy.redact(entry.Fields)
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+
+	select {
+	case <-e.stopped:
+		telemetry.RecordLogExport(entry.Stream, "dropped", 1)
+		return
+	default:
+	}
+	select {
+	case e.queue <- queuedEntry{entry: entry, destination: policy.Destination}:
+	default:
+		telemetry.RecordLogExport(entry.Stream, "dropped", 1)
+	}
+}
+
+// Stop delivers queued entries and stops the worker.
+func (e *Exporter) Stop() {
+	close(e.stopped)
+	<-e.done
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batches := make(map[batchKey]*batch)
+	sinks := make(map[Destination]Sink)
+	add := func(q queuedEntry) {
+		line, err := json.Marshal(q.entry)
+		if err != nil {
+			telemetry.RecordLogExport(q.entry.Stream, "failed", 1)
+			e.logger.Warn("failed to encode log export entry", zap.String("org_id", q.entry.OrgID), zap.Error(err))
+			return
+		}
+		key := batchKey{orgID: q.entry.OrgID, destination: q.destination}
+		b, ok := batches[key]
+		if !ok {
+			b = &batch{streams: make(map[string]int)}
+			batches[key] = b
+		}
+		b.body.Write(line)
+		b.body.WriteByte('\n')
+		b.streams[q.entry.Stream]++
+		b.count++
+		if b.count >= e.batchSize {
+			e.flush(sinks, key, b)
+			delete(batches, key)
+		}
+	}
+	flushAll := func() {
+		for key, b := range batches {
+			e.flush(sinks, key, b)
+			delete(batches, key)
+		}
+	}
+
+	for {
+		select {
+		case q := <-e.queue:
+			add(q)
+		case <-ticker.C:
+			flushAll()
+		case <-e.stopped:
+			for {
+				select {
+				case q := <-e.queue:
+					add(q)
+				default:
+					flushAll()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush delivers one batch. Sinks are cached per destination so assumed-role
+// credentials and HTTP connections are reused.
+func (e *Exporter) flush(sinks map[Destination]Sink, key batchKey, b *batch) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	outcome := "exported"
+	sink, ok := sinks[key.destination]
+	var err error
+	if !ok {
+		sink, err = e.newSink(ctx, key.destination)
+		if err == nil {
+			sinks[key.destination] = sink
+		}
+	}
+	if err == nil {
+		err = sink.Send(ctx, key.orgID, b.body.Bytes())
+	}
+	if err != nil {
+		outcome = "failed"
+		e.logger.Warn("failed to deliver org log export batch",
+			zap.String("org_id", key.orgID),
+			zap.String("destination", key.destination.Type),
+			zap.Int("entries", b.count),
+			zap.Error(err),
+		)
+	}
+	for stream, count := range b.streams {
+		telemetry.RecordLogExport(stream, outcome, count)
+	}
+}
@@ -0,0 +1,154 @@
+package logexport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches map[string][][]byte
+}
+
+func (m *memorySink) Send(_ context.Context, orgID string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches[orgID] = append(m.batches[orgID], append([]byte(nil), body...))
+	return nil
+}
+
+func (m *memorySink) entries(t *testing.T, orgID string) []Entry {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []Entry
+	for _, body := range m.batches[orgID] {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var entry Entry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("decode entry %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func newTestExporter(sink *memorySink) *Exporter {
+	return New(Config{
+		NewSink:       func(context.Context, Destination) (Sink, error) { return sink, nil },
+		FlushInterval: time.Hour,
+	})
+}
+
+func TestExporterFiltersAndRedacts(t *testing.T) {
+	sink := &memorySink{batches: make(map[string][][]byte)}
+	exporter := newTestExporter(sink)
+	exporter.SetPolicy("org-1", &Policy{
+		Destination:  Destination{Type: DestinationHTTPS, URL: "https://logs.example.com"},
+		Streams:      []string{StreamRequest},
+		MinStatus:    400,
+		RedactFields: []string{"remote_addr"},
+	})
+
+	exporter.Export(Entry{Stream: StreamRequest, OrgID: "org-1", Fields: map[string]any{"status": 200}})
+	exporter.Export(Entry{Stream: StreamAudit, OrgID: "org-1", Fields: map[string]any{"action": "REQUEST_DENIED"}})
+	exporter.Export(Entry{Stream: StreamRequest, OrgID: "org-1", Fields: map[string]any{"status": 503, "remote_addr": "10.0.0.1"}})
+	exporter.Export(Entry{Stream: StreamRequest, OrgID: "org-2", Fields: map[string]any{"status": 500}})
+	exporter.Stop()
+
+	entries := sink.entries(t, "org-1")
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 exported entry, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Fields["status"] != float64(503) || entries[0].Fields["remote_addr"] != redactedValue {
+		t.Fatalf("unexpected entry %+v", entries[0])
+	}
+	if entries[0].Time.IsZero() {
+		t.Fatal("expected entry time to be set")
+	}
+	if got := sink.entries(t, "org-2"); len(got) != 0 {
+		t.Fatalf("expected nothing for org without policy, got %+v", got)
+	}
+}
+
+func TestExporterBatchesBySize(t *testing.T) {
+	sink := &memorySink{batches: make(map[string][][]byte)}
+	exporter := New(Config{
+		NewSink:       func(context.Context, Destination) (Sink, error) { return sink, nil },
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	exporter.SetPolicy("org-1", &Policy{Destination: Destination{Type: DestinationHTTPS}})
+	for i := 0; i < 5; i++ {
+		exporter.Export(Entry{Stream: StreamAudit, OrgID: "org-1", Fields: map[string]any{"n": i}})
+	}
+	exporter.Stop()
+
+	sink.mu.Lock()
+	batches := len(sink.batches["org-1"])
+	sink.mu.Unlock()
+	if batches != 3 {
+		t.Fatalf("expected 3 batches, got %d", batches)
+	}
+	if got := len(sink.entries(t, "org-1")); got != 5 {
+		t.Fatalf("expected 5 entries, got %d", got)
+	}
+}
+
+func TestExporterPolicyRemoval(t *testing.T) {
+	sink := &memorySink{batches: make(map[string][][]byte)}
+	exporter := newTestExporter(sink)
+	exporter.SetPolicy("org-1", &Policy{Destination: Destination{Type: DestinationHTTPS}})
+	exporter.SetPolicy("org-1", nil)
+	exporter.Export(Entry{Stream: StreamRequest, OrgID: "org-1", Fields: map[string]any{"status": 200}})
+	exporter.Stop()
+
+	if got := sink.entries(t, "org-1"); len(got) != 0 {
+		t.Fatalf("expected export to stop after policy removal, got %+v", got)
+	}
+}
+
+func TestHTTPSSinkSignsBatches(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"stream":"request"}` + "\n")
+	var gotSignature, gotTimestamp, gotOrg string
+	var gotBody []byte
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(HeaderSignature)
+		gotTimestamp = r.Header.Get(HeaderTimestamp)
+		gotOrg = r.Header.Get(HeaderOrgID)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sink, err := NewHTTPSSink(Destination{Type: DestinationHTTPS, URL: srv.URL, SigningSecret: secret}, srv.Client())
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	if err := sink.Send(context.Background(), "org-1", body); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if gotOrg != "org-1" || !bytes.Equal(gotBody, body) {
+		t.Fatalf("unexpected delivery org=%q body=%q", gotOrg, gotBody)
+	}
+	if gotSignature != "sha256="+Sign([]byte(secret), gotTimestamp, body) {
+		t.Fatalf("signature %q does not verify", gotSignature)
+	}
+}
+
+func TestNewHTTPSSinkRequiresHTTPS(t *testing.T) {
+	if _, err := NewHTTPSSink(Destination{Type: DestinationHTTPS, URL: "http://logs.example.com"}, nil); err == nil {
+		t.Fatal("expected plain http URL to be rejected")
+	}
+}
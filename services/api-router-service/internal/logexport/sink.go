@@ -0,0 +1,191 @@
+package logexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/google/uuid"
+)
+
+// Destination types.
+const (
+	DestinationS3    = "s3"
+	DestinationHTTPS = "https"
+)
+
+// Headers set on HTTPS deliveries.
+const (
+	HeaderTimestamp = "X-Log-Export-Timestamp"
+	HeaderSignature = "X-Log-Export-Signature"
+	HeaderOrgID     = "X-Log-Export-Org-ID"
+)
+
+const (
+	contentType     = "application/x-ndjson"
+	roleSessionName = "ai-aas-log-export"
+)
+
+// Destination identifies where an org's logs are delivered. It is comparable
+// so sinks can be cached per destination.
+type Destination struct {
+	Type string
+
+	// S3
+	Bucket     string
+	Region     string
+	Prefix     string
+	RoleARN    string
+	ExternalID string
+
+	// HTTPS
+	URL           string
+	SigningSecret string
+}
+
+// Sink delivers a batch of newline-delimited JSON entries.
+type Sink interface {
+	Send(ctx context.Context, orgID string, body []byte) error
+}
+
+// SinkFactory builds the sink for a destination.
+type SinkFactory func(ctx context.Context, dest Destination) (Sink, error)
+
+// NewSink builds an S3 or HTTPS sink for dest.
+func NewSink(ctx context.Context, dest Destination) (Sink, error) {
+	switch dest.Type {
+	case DestinationS3:
+		return NewS3Sink(ctx, dest)
+	case DestinationHTTPS:
+		return NewHTTPSSink(dest, nil)
+	default:
+		return nil, fmt.Errorf("unknown log export destination type %q", dest.Type)
+	}
+}
+
+// S3Sink writes batches to a customer bucket using credentials obtained by
+// assuming the customer's role with the org's external ID.
+type S3Sink struct {
+	client *s3.Client
+	bucket string
+	prefix string
+	now    func() time.Time
+}
+
+// NewS3Sink assumes dest.RoleARN using the router's own AWS credentials.
+func NewS3Sink(ctx context.Context, dest Destination) (*S3Sink, error) {
+	if dest.Bucket == "" || dest.RoleARN == "" {
+		return nil, fmt.Errorf("log export bucket and role are required")
+	}
+	base, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(dest.Region))
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config: %w", err)
+	}
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(base), dest.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+		if dest.ExternalID != "" {
+			o.ExternalID = aws.String(dest.ExternalID)
+		}
+	})
+	cfg := base.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	return &S3Sink{
+		client: s3.NewFromConfig(cfg),
+		bucket: dest.Bucket,
+		prefix: dest.Prefix,
+		now:    time.Now,
+	}, nil
+}
+
+// Send writes one object per batch.
+func (s *S3Sink) Send(ctx context.Context, orgID string, body []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(s.objectKey(orgID)),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("put log export object: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Sink) objectKey(orgID string) string {
+	now := s.now().UTC()
+	name := fmt.Sprintf("%s-%s.ndjson", now.Format("150405"), uuid.NewString())
+	return path.Join(s.prefix, orgID, now.Format("2006/01/02"), name)
+}
+
+// HTTPSSink POSTs batches to a customer endpoint, signed so the receiver can
+// verify they came from the platform.
+type HTTPSSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewHTTPSSink creates a sink for dest; client defaults to one with a 15s timeout.
+func NewHTTPSSink(dest Destination, client *http.Client) (*HTTPSSink, error) {
+	u, err := url.Parse(dest.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid log export URL")
+	}
+	if client == nil {
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("log export URL must use https")
+		}
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &HTTPSSink{url: dest.URL, secret: []byte(dest.SigningSecret), client: client, now: time.Now}, nil
+}
+
+// Send POSTs the batch and expects a 2xx response.
+func (s *HTTPSSink) Send(ctx context.Context, orgID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create log export request: %w", err)
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(HeaderOrgID, orgID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post log export batch: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("log export endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "{timestamp}.{body}" that receivers
+// compare against X-Log-Export-Signature.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// EtcdKey is the config service key holding the maintenance state.
+const EtcdKey = "/api-router/maintenance"
+
+// EtcdStore keeps the maintenance state in the config service (etcd).
+type EtcdStore struct {
+	client *clientv3.Client
+	logger *zap.Logger
+}
+
+// NewEtcdStore connects to the config service at endpoint.
+func NewEtcdStore(endpoint string, logger *zap.Logger) (*EtcdStore, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to etcd: %w", err)
+	}
+	return &EtcdStore{client: client, logger: logger}, nil
+}
+
+// Close closes the etcd connection.
+func (s *EtcdStore) Close() error {
+	return s.client.Close()
+}
+
+// Load returns the stored state, or nil when none is stored.
+func (s *EtcdStore) Load(ctx context.Context) (*State, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := s.client.Get(ctx, EtcdKey)
+	if err != nil {
+		return nil, fmt.Errorf("etcd get: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	var state State
+	if err := json.Unmarshal(resp.Kvs[0].Value, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal maintenance state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes state.
+func (s *EtcdStore) Save(ctx context.Context, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal maintenance state: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := s.client.Put(ctx, EtcdKey, string(data)); err != nil {
+		return fmt.Errorf("etcd put: %w", err)
+	}
+	return nil
+}
+
+// Watch calls apply for every change to the key until ctx is done.
+func (s *EtcdStore) Watch(ctx context.Context, apply func(*State)) {
+	watchChan := s.client.Watch(ctx, EtcdKey)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case watchResp, ok := <-watchChan:
+			if !ok {
+				return
+			}
+			if err := watchResp.Err(); err != nil {
+				s.logger.Error("maintenance watch error", zap.Error(err))
+				time.Sleep(5 * time.Second)
+				watchChan = s.client.Watch(ctx, EtcdKey)
+				continue
+			}
+			for _, event := range watchResp.Events {
+				switch event.Type {
+				case clientv3.EventTypePut:
+					var state State
+					if err := json.Unmarshal(event.Kv.Value, &state); err != nil {
+						s.logger.Error("failed to unmarshal maintenance state", zap.Error(err))
+						continue
+					}
+					apply(&state)
+				case clientv3.EventTypeDelete:
+					apply(nil)
+				}
+			}
+		}
+	}
+}
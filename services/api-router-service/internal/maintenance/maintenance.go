@@ -0,0 +1,359 @@
+// Package maintenance provides the platform maintenance mode.
+//
+// Purpose:
+//   Operators put the router into maintenance, immediately or for scheduled
+//   windows, through the admin API or by writing the state to the config
+//   service. While maintenance is active inference routes answer 503 with a
+//   maintenance body; status, health and admin routes stay available so the
+//   platform can be observed and maintenance can be lifted.
+//
+// Key Responsibilities:
+//   - Hold the maintenance state, replaceable at runtime
+//   - Middleware rejects non-exempt requests while maintenance is active
+//   - Announce upcoming windows on every response within the warning lead
+//   - Share the state across replicas through a Store (etcd)
+//
+// Debugging Notes:
+//   - Exempt paths are prefixes (MAINTENANCE_EXEMPT_PATHS); the admin prefix
+//     is always exempt so maintenance can be turned off
+//   - Upcoming windows are announced with X-Maintenance-Start/End headers
+//   - Rejections carry Retry-After: the time left in the window, or the
+//     configured retry_after_seconds for open-ended maintenance
+//
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// adminPrefix is never rejected so maintenance can always be lifted.
+const adminPrefix = "/v1/admin/"
+
+// Headers announcing an upcoming window.
+const (
+	HeaderStart = "X-Maintenance-Start"
+	HeaderEnd   = "X-Maintenance-End"
+)
+
+const (
+	defaultMessage     = "The platform is undergoing maintenance. Please retry later."
+	defaultWarningLead = 24 * time.Hour
+	maxWindows         = 32
+)
+
+// DefaultExemptPrefixes keep probes, status and admin routes available.
+var DefaultExemptPrefixes = []string{"/v1/status/", "/v1/admin/", "/healthz", "/readyz", "/metrics"}
+
+var (
+	// ErrInvalidState wraps validation failures from Set and AddWindow.
+	ErrInvalidState = errors.New("invalid maintenance state")
+	// ErrWindowNotFound is returned when removing an unknown window.
+	ErrWindowNotFound = errors.New("maintenance window not found")
+)
+
+// Window is a scheduled maintenance period.
+type Window struct {
+	ID      string    `json:"id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Message string    `json:"message,omitempty"`
+}
+
+// State is the maintenance configuration shared by all replicas.
+type State struct {
+	// Enabled turns maintenance on now, until turned off.
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+	// RetryAfter is sent as Retry-After while Enabled maintenance is active.
+	RetryAfter int       `json:"retry_after_seconds,omitempty"`
+	Windows    []Window  `json:"windows,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks windows and fills in IDs; windows are sorted by start.
+func (s *State) Validate() error {
+	if s.RetryAfter < 0 {
+		return fmt.Errorf("retry_after_seconds must not be negative")
+	}
+	if len(s.Windows) > maxWindows {
+		return fmt.Errorf("at most %d windows are supported", maxWindows)
+	}
+	seen := make(map[string]bool, len(s.Windows))
+	for n := range s.Windows {
+		w := &s.Windows[n]
+		if w.Start.IsZero() || w.End.IsZero() || !w.End.After(w.Start) {
+			return fmt.Errorf("window %d: end must be after start", n)
+		}
+		if w.ID == "" {
+			w.ID = fmt.Sprintf("window-%d", w.Start.Unix())
+		}
+		if seen[w.ID] {
+			return fmt.Errorf("duplicate window id %q", w.ID)
+		}
+		seen[w.ID] = true
+		w.Start, w.End = w.Start.UTC(), w.End.UTC()
+	}
+	sort.Slice(s.Windows, func(i, j int) bool { return s.Windows[i].Start.Before(s.Windows[j].Start) })
+	return nil
+}
+
+// Store shares the state across router replicas.
+type Store interface {
+	// Load returns the stored state, or nil when none is stored.
+	Load(ctx context.Context) (*State, error)
+	Save(ctx context.Context, state State) error
+	// Watch calls apply with each stored change (nil when deleted) until ctx is done.
+	Watch(ctx context.Context, apply func(*State))
+}
+
+// Config configures a Controller.
+type Config struct {
+	// ExemptPrefixes are path prefixes served during maintenance; defaults to DefaultExemptPrefixes.
+	ExemptPrefixes []string
+	// WarningLead is how far ahead windows are announced; defaults to 24h.
+	WarningLead time.Duration
+	Logger      *zap.Logger
+}
+
+// Controller holds the maintenance state. It is safe for concurrent use.
+type Controller struct {
+	exempt      []string
+	warningLead time.Duration
+	logger      *zap.Logger
+	now         func() time.Time
+
+	mu    sync.RWMutex
+	state State
+	store Store
+
+	// updateMu serializes read-modify-write updates from the admin API.
+	updateMu sync.Mutex
+}
+
+// New creates a controller with maintenance off.
+func New(cfg Config) *Controller {
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	if cfg.ExemptPrefixes == nil {
+		cfg.ExemptPrefixes = DefaultExemptPrefixes
+	}
+	if cfg.WarningLead <= 0 {
+		cfg.WarningLead = defaultWarningLead
+	}
+	return &Controller{
+		exempt:      append([]string{adminPrefix}, cfg.ExemptPrefixes...),
+		warningLead: cfg.WarningLead,
+		logger:      cfg.Logger,
+		now:         time.Now,
+	}
+}
+
+// Sync loads the stored state and keeps following store changes until ctx is
+// done. Later Set calls are saved to store.
+func (c *Controller) Sync(ctx context.Context, store Store) error {
+	state, err := store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load maintenance state: %w", err)
+	}
+	c.mu.Lock()
+	c.store = store
+	c.mu.Unlock()
+	c.apply(state)
+	go store.Watch(ctx, c.apply)
+	return nil
+}
+
+// State returns the current state.
+func (c *Controller) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	state := c.state
+	state.Windows = append([]Window(nil), c.state.Windows...)
+	return state
+}
+
+// Set validates and applies state, saving it to the store when syncing.
+func (c *Controller) Set(ctx context.Context, state State) error {
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+	return c.set(ctx, state)
+}
+
+// AddWindow schedules a window and returns it with its ID filled in.
+func (c *Controller) AddWindow(ctx context.Context, window Window) (Window, error) {
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+	state := c.State()
+	state.Windows = append(state.Windows, window)
+	if err := c.set(ctx, state); err != nil {
+		return Window{}, err
+	}
+	for _, w := range c.State().Windows {
+		if w.Start.Equal(window.Start) && (window.ID == "" || w.ID == window.ID) {
+			return w, nil
+		}
+	}
+	return window, nil
+}
+
+// RemoveWindow cancels the window with id.
+func (c *Controller) RemoveWindow(ctx context.Context, id string) error {
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+	state := c.State()
+	windows := state.Windows[:0]
+	for _, w := range state.Windows {
+		if w.ID != id {
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == len(state.Windows) {
+		return ErrWindowNotFound
+	}
+	state.Windows = windows
+	return c.set(ctx, state)
+}
+
+func (c *Controller) set(ctx context.Context, state State) error {
+	if err := state.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	state.UpdatedAt = c.now().UTC()
+
+	c.mu.RLock()
+	store := c.store
+	c.mu.RUnlock()
+	if store != nil {
+		if err := store.Save(ctx, state); err != nil {
+			return fmt.Errorf("save maintenance state: %w", err)
+		}
+	}
+	c.apply(&state)
+	return nil
+}
+
+// apply replaces the state; nil turns maintenance off.
+func (c *Controller) apply(state *State) {
+	next := State{}
+	if state != nil {
+		next = *state
+		if err := next.Validate(); err != nil {
+			c.logger.Error("ignoring invalid maintenance state", zap.Error(err))
+			return
+		}
+	}
+	c.mu.Lock()
+	changed := c.state.Enabled != next.Enabled || len(c.state.Windows) != len(next.Windows)
+	c.state = next
+	c.mu.Unlock()
+
+	if changed {
+		c.logger.Warn("maintenance state updated",
+			zap.Bool("enabled", next.Enabled),
+			zap.Int("windows", len(next.Windows)),
+		)
+	}
+}
+
+// Active reports whether maintenance is in effect at now, with the message
+// to show and when it ends (zero when open-ended).
+func (c *Controller) Active(now time.Time) (active bool, message string, end time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.state.Enabled {
+		return true, c.state.Message, time.Time{}
+	}
+	for _, w := range c.state.Windows {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			return true, w.Message, w.End
+		}
+	}
+	return false, "", time.Time{}
+}
+
+// Upcoming returns the next window starting within the warning lead, or nil.
+func (c *Controller) Upcoming(now time.Time) *Window {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, w := range c.state.Windows {
+		if w.Start.After(now) && w.Start.Sub(now) <= c.warningLead {
+			window := w
+			return &window
+		}
+	}
+	return nil
+}
+
+// Exempt reports whether path is served during maintenance.
+func (c *Controller) Exempt(path string) bool {
+	for _, prefix := range c.exempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// maintenanceResponse is the 503 body.
+type maintenanceResponse struct {
+	*api.ErrorResponse
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+}
+
+// Middleware rejects non-exempt requests during maintenance and announces
+// upcoming windows on every response.
+func (c *Controller) Middleware() func(http.Handler) http.Handler {
+	errorBuilder := api.NewErrorBuilder(nil)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := c.now()
+			if window := c.Upcoming(now); window != nil {
+				w.Header().Set(HeaderStart, window.Start.Format(time.RFC3339))
+				w.Header().Set(HeaderEnd, window.End.Format(time.RFC3339))
+			}
+
+			active, message, end := c.Active(now)
+			telemetry.SetMaintenanceActive(active)
+			if !active || c.Exempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if message == "" {
+				message = defaultMessage
+			}
+			resp := maintenanceResponse{
+				ErrorResponse: errorBuilder.BuildError(r.Context(), fmt.Errorf("%s", message), api.ErrCodeMaintenance),
+			}
+			retryAfter := c.State().RetryAfter
+			if !end.IsZero() {
+				endsAt := end.UTC()
+				resp.EndsAt = &endsAt
+				retryAfter = int(end.Sub(now).Seconds()) + 1
+			}
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+			telemetry.RecordMaintenanceRejection()
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(resp)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package maintenance provides unit tests for maintenance mode.
+//
+// Purpose:
+//   These tests validate route exemptions, scheduled windows, advance warning
+//   headers, state validation and syncing through a Store.
+//
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestController() *Controller {
+	c := New(Config{})
+	c.now = func() time.Time { return testNow }
+	return c
+}
+
+func serve(c *Controller, path string) *httptest.ResponseRecorder {
+	handler := c.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+	return rr
+}
+
+func TestMiddlewareRejectsNonExemptRoutes(t *testing.T) {
+	c := newTestController()
+	if rr := serve(c, "/v1/chat/completions"); rr.Code != http.StatusOK {
+		t.Fatalf("expected requests to pass with maintenance off, got %d", rr.Code)
+	}
+
+	if err := c.Set(context.Background(), State{Enabled: true, Message: "upgrading", RetryAfter: 120}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	rr := serve(c, "/v1/chat/completions")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "120" {
+		t.Fatalf("expected Retry-After 120, got %q", rr.Header().Get("Retry-After"))
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["code"] != "MAINTENANCE" || body["error"] != "upgrading" {
+		t.Fatalf("unexpected body: %v", body)
+	}
+
+	for _, path := range []string{"/v1/status/healthz", "/v1/admin/maintenance", "/metrics"} {
+		if rr := serve(c, path); rr.Code != http.StatusOK {
+			t.Fatalf("expected %s to be exempt, got %d", path, rr.Code)
+		}
+	}
+}
+
+func TestAdminPrefixAlwaysExempt(t *testing.T) {
+	c := New(Config{ExemptPrefixes: []string{"/healthz"}})
+	if err := c.Set(context.Background(), State{Enabled: true}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if rr := serve(c, "/v1/admin/maintenance"); rr.Code != http.StatusOK {
+		t.Fatalf("expected admin routes to be exempt, got %d", rr.Code)
+	}
+	if rr := serve(c, "/v1/status/healthz"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status routes to be rejected when not configured exempt, got %d", rr.Code)
+	}
+}
+
+func TestScheduledWindows(t *testing.T) {
+	c := newTestController()
+	ctx := context.Background()
+
+	upcoming, err := c.AddWindow(ctx, Window{Start: testNow.Add(2 * time.Hour), End: testNow.Add(3 * time.Hour)})
+	if err != nil {
+		t.Fatalf("add window: %v", err)
+	}
+	if upcoming.ID == "" {
+		t.Fatal("expected window ID to be assigned")
+	}
+	rr := serve(c, "/v1/chat/completions")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected requests to pass before the window, got %d", rr.Code)
+	}
+	if rr.Header().Get(HeaderStart) != testNow.Add(2*time.Hour).Format(time.RFC3339) {
+		t.Fatalf("expected advance warning header, got %q", rr.Header().Get(HeaderStart))
+	}
+
+	if _, err := c.AddWindow(ctx, Window{ID: "now", Start: testNow.Add(-time.Minute), End: testNow.Add(10 * time.Minute)}); err != nil {
+		t.Fatalf("add window: %v", err)
+	}
+	rr = serve(c, "/v1/chat/completions")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 during window, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "601" {
+		t.Fatalf("expected Retry-After until window end, got %q", rr.Header().Get("Retry-After"))
+	}
+
+	if err := c.RemoveWindow(ctx, "now"); err != nil {
+		t.Fatalf("remove window: %v", err)
+	}
+	if rr := serve(c, "/v1/chat/completions"); rr.Code != http.StatusOK {
+		t.Fatalf("expected requests to pass after cancelling the window, got %d", rr.Code)
+	}
+	if err := c.RemoveWindow(ctx, "now"); !errors.Is(err, ErrWindowNotFound) {
+		t.Fatalf("expected ErrWindowNotFound, got %v", err)
+	}
+}
+
+func TestUpcomingHonoursWarningLead(t *testing.T) {
+	c := New(Config{WarningLead: time.Hour})
+	if err := c.Set(context.Background(), State{Windows: []Window{{Start: testNow.Add(2 * time.Hour), End: testNow.Add(3 * time.Hour)}}}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if c.Upcoming(testNow) != nil {
+		t.Fatal("expected window beyond the warning lead not to be announced")
+	}
+	if c.Upcoming(testNow.Add(90*time.Minute)) == nil {
+		t.Fatal("expected window within the warning lead to be announced")
+	}
+}
+
+func TestSetRejectsInvalidState(t *testing.T) {
+	c := newTestController()
+	for name, state := range map[string]State{
+		"end before start":  {Windows: []Window{{Start: testNow, End: testNow.Add(-time.Hour)}}},
+		"duplicate ids":     {Windows: []Window{{ID: "a", Start: testNow, End: testNow.Add(time.Hour)}, {ID: "a", Start: testNow.Add(2 * time.Hour), End: testNow.Add(3 * time.Hour)}}},
+		"negative retry":    {Enabled: true, RetryAfter: -1},
+		"missing start/end": {Windows: []Window{{ID: "a"}}},
+	} {
+		if err := c.Set(context.Background(), state); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: expected ErrInvalidState, got %v", name, err)
+		}
+	}
+}
+
+type memoryStore struct {
+	state   *State
+	changes chan *State
+}
+
+func (s *memoryStore) Load(context.Context) (*State, error) { return s.state, nil }
+
+func (s *memoryStore) Save(_ context.Context, state State) error {
+	s.state = &state
+	return nil
+}
+
+func (s *memoryStore) Watch(ctx context.Context, apply func(*State)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case state := <-s.changes:
+			apply(state)
+		}
+	}
+}
+
+func TestSyncFollowsStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &memoryStore{state: &State{Enabled: true}, changes: make(chan *State)}
+	c := newTestController()
+	if err := c.Sync(ctx, store); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !c.State().Enabled {
+		t.Fatal("expected stored state to be loaded")
+	}
+
+	store.changes <- nil
+	deadline := time.Now().Add(time.Second)
+	for c.State().Enabled && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if c.State().Enabled {
+		t.Fatal("expected deleted state to turn maintenance off")
+	}
+
+	if err := c.Set(ctx, State{Enabled: true, Message: "saved"}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if store.state == nil || store.state.Message != "saved" {
+		t.Fatalf("expected Set to save to the store, got %+v", store.state)
+	}
+}
@@ -25,11 +25,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
 )
 
 // BackendEndpoint represents a backend model service endpoint.
@@ -38,24 +43,57 @@ type BackendEndpoint struct {
 	URI       string
 	ModelVariant string
 	Timeout   time.Duration
+	Protocol  string // config.ProtocolHTTP1, ProtocolHTTP2 or ProtocolGRPC; empty negotiates
 }
 
-// BackendClient wraps HTTP client for backend communication.
+// FaultInjector injects faults into backend calls (see internal/chaos).
+type FaultInjector interface {
+	InjectBackend(ctx context.Context, backendID string) error
+}
+
+// BackendClient wraps HTTP clients for backend communication, one
+// connection pool per backend (see backend_pool.go).
 type BackendClient struct {
-	httpClient *http.Client
-	logger     *zap.Logger
+	timeout     time.Duration
+	logger      *zap.Logger
+	faults      FaultInjector
+	lookup      func(ctx context.Context, host string) ([]netip.Addr, error)
+	mu          sync.Mutex
+	pools       map[string]*backendPool
+	grpcConns   map[string]*grpcBackend
+	poolConfigs map[string]config.BackendPoolConfig // By backend ID, "*" for the default
+	egress      *config.EgressPolicy
+	stopDNS     context.CancelFunc
+	wg          sync.WaitGroup
 }
 
 // NewBackendClient creates a new backend client.
 func NewBackendClient(logger *zap.Logger, timeout time.Duration) *BackendClient {
 	return &BackendClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
+		timeout: timeout,
+		logger:  logger,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
 		},
-		logger: logger,
+		pools:     make(map[string]*backendPool),
+		grpcConns: make(map[string]*grpcBackend),
 	}
 }
 
+// SetEgressPolicy checks every connection to a backend against policy, so a
+// backend hostname that starts resolving to a denied address (DNS rebinding)
+// is refused at dial time. It must be called before the first request.
+func (c *BackendClient) SetEgressPolicy(policy *config.EgressPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.egress = policy
+}
+
+// SetFaultInjector enables fault injection for backend calls. Dev environments only.
+func (c *BackendClient) SetFaultInjector(faults FaultInjector) {
+	c.faults = faults
+}
+
 // BackendRequest represents a request to a backend model service.
 type BackendRequest struct {
 	Prompt      string                 `json:"prompt"`
@@ -75,6 +113,26 @@ type BackendResponse struct {
 func (c *BackendClient) ForwardRequest(ctx context.Context, backend *BackendEndpoint, req *BackendRequest) (*BackendResponse, error) {
 	startTime := time.Now()
 
+	if c.faults != nil {
+		if err := c.faults.InjectBackend(ctx, backend.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if backend.Protocol == config.ProtocolGRPC {
+		resp, err := c.forwardGRPC(ctx, backend, req)
+		if err != nil {
+			return nil, err
+		}
+		c.logger.Info("backend request completed",
+			zap.String("backend_id", backend.ID),
+			zap.String("protocol", backend.Protocol),
+			zap.Duration("latency", time.Since(startTime)),
+			zap.Int("tokens_used", resp.TokensUsed),
+		)
+		return resp, nil
+	}
+
 	// Prepare request body
 	reqBody, err := json.Marshal(req)
 	if err != nil {
@@ -90,7 +148,7 @@ func (c *BackendClient) ForwardRequest(ctx context.Context, backend *BackendEndp
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.do(backend, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("backend request failed: %w", err)
 	}
@@ -135,27 +193,68 @@ func (c *BackendClient) ForwardRequest(ctx context.Context, backend *BackendEndp
 
 // HealthCheck checks the health of a backend endpoint.
 func (c *BackendClient) HealthCheck(ctx context.Context, backend *BackendEndpoint) error {
-	healthURL := backend.URI
-	// Try /health endpoint if URI doesn't end with it
-	if len(healthURL) > 0 && healthURL[len(healthURL)-1] != '/' {
-		healthURL += "/health"
-	} else {
-		healthURL += "health"
+	_, err := c.ProbeHTTP(ctx, backend, config.DefaultHealthProbePath, http.StatusOK)
+	return err
+}
+
+// ProbeHTTP sends GET <uri><path> and fails unless the backend answers with
+// expectedStatus. The status is returned even when it is unexpected. gRPC
+// backends are probed with ModelReady instead.
+func (c *BackendClient) ProbeHTTP(ctx context.Context, backend *BackendEndpoint, path string, expectedStatus int) (int, error) {
+	if backend.Protocol == config.ProtocolGRPC {
+		return c.probeGRPC(ctx, backend)
+	}
+	probeURL := strings.TrimSuffix(backend.URI, "/") + "/" + strings.TrimPrefix(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", probeURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("create health check request: %w", err)
+	}
+
+	resp, err := c.do(backend, req)
+	if err != nil {
+		return 0, fmt.Errorf("health check failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != expectedStatus {
+		return resp.StatusCode, fmt.Errorf("backend unhealthy: status %d, expected %d", resp.StatusCode, expectedStatus)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// ProbeInference sends a one-token completion for prompt, catching model
+// servers that pass /health but can no longer generate. Fault injection is
+// not applied, matching the HTTP probe.
+func (c *BackendClient) ProbeInference(ctx context.Context, backend *BackendEndpoint, prompt string) error {
+	if backend.Protocol == config.ProtocolGRPC {
+		if _, err := c.forwardGRPC(ctx, backend, &BackendRequest{Prompt: prompt, MaxTokens: 1}); err != nil {
+			return fmt.Errorf("inference probe failed: %w", err)
+		}
+		return nil
+	}
+	reqBody, err := json.Marshal(&BackendRequest{Prompt: prompt, MaxTokens: 1})
+	if err != nil {
+		return fmt.Errorf("marshal probe request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", healthURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", backend.URI, bytes.NewReader(reqBody))
 	if err != nil {
-		return fmt.Errorf("create health check request: %w", err)
+		return fmt.Errorf("create inference probe request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(backend, req)
 	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		return fmt.Errorf("inference probe failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("backend unhealthy: status %d", resp.StatusCode)
+		return fmt.Errorf("inference probe returned status %d", resp.StatusCode)
 	}
 
 	return nil
@@ -0,0 +1,365 @@
+// Package routing provides per-backend HTTP connection pools.
+//
+// Purpose:
+//   BackendClient keeps one transport per backend, tuned by BACKEND_POOLS, so
+//   backends do not share (and exhaust) one idle pool. Pools are recycled when
+//   a backend's hostname resolves to a different IP set, when the health
+//   monitor marks the backend unhealthy, and when its URI changes, so requests
+//   stop riding keepalive connections to pods that are gone or wedged.
+//
+// Debugging Notes:
+//   - HTTP/2 is negotiated over TLS only; plain http:// backends use HTTP/1.1
+//     unless the backend's protocol is http2 (h2c). Protocol http1 never uses HTTP/2
+//   - gRPC backends hold one grpc.ClientConn each (see grpc_backend.go); it
+//     re-resolves DNS itself, and is closed and redialled when recycled
+//   - Every dial is checked against the egress allowlist; a refused dial is
+//     logged and counted as api_router_egress_violations_total{source="dial"}
+//   - A recycled pool closes its idle connections at once; in-flight requests
+//     finish on the old connections, which are closed after the request timeout
+//   - Metrics: api_router_backend_open_connections,
+//     api_router_backend_connections_acquired_total,
+//     api_router_backend_pool_recycles_total
+//
+package routing
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// Pool recycle reasons.
+const (
+	PoolRecycleDNSChange = "dns_change"
+	PoolRecycleUnhealthy = "unhealthy"
+	PoolRecycleURIChange = "uri_change"
+)
+
+// dnsRefreshTick is how often due backends are re-resolved.
+const dnsRefreshTick = time.Second
+
+// backendPool is one backend's transport and the addresses it was last resolved to.
+type backendPool struct {
+	uri         string
+	protocol    string
+	host        string
+	cfg         config.BackendPoolConfig
+	transport   *http.Transport
+	client      *http.Client
+	addrs       []netip.Addr // Sorted; nil until first resolved
+	nextResolve time.Time
+}
+
+// SetPools configures per-backend pools (see config.ParseBackendPools). It
+// must be called before the first request.
+func (c *BackendClient) SetPools(pools map[string]config.BackendPoolConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.poolConfigs = pools
+}
+
+// PoolConfig returns the pool settings that apply to backendID.
+func (c *BackendClient) PoolConfig(backendID string) config.BackendPoolConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.poolConfigLocked(backendID)
+}
+
+func (c *BackendClient) poolConfigLocked(backendID string) config.BackendPoolConfig {
+	pool, ok := c.poolConfigs[backendID]
+	if !ok {
+		pool, ok = c.poolConfigs["*"]
+	}
+	if !ok {
+		pool = config.DefaultBackendPool()
+	}
+	return pool
+}
+
+// clientFor returns the HTTP client for backend, creating its pool on first use.
+func (c *BackendClient) clientFor(backend *BackendEndpoint) *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pool, ok := c.pools[backend.ID]
+	if ok && pool.uri == backend.URI && pool.protocol == backend.Protocol {
+		return pool.client
+	}
+	if ok {
+		c.retireLocked(backend.ID, pool, PoolRecycleURIChange)
+	}
+	pool = &backendPool{
+		uri:      backend.URI,
+		protocol: backend.Protocol,
+		cfg:      c.poolConfigLocked(backend.ID),
+	}
+	if u, err := url.Parse(backend.URI); err == nil {
+		pool.host = u.Hostname()
+	}
+	pool.transport, pool.client = c.newTransport(backend.ID, pool.host, pool.cfg, pool.protocol)
+	c.pools[backend.ID] = pool
+	return pool.client
+}
+
+// newTransport builds a transport whose connections are counted per backend.
+// protocol ProtocolHTTP1 or ProtocolHTTP2 pins the HTTP version; HTTP/2 over
+// plain http:// is then spoken with prior knowledge (h2c). Dials are checked
+// against the egress policy for host.
+func (c *BackendClient) newTransport(backendID, host string, cfg config.BackendPoolConfig, protocol string) (*http.Transport, *http.Client) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: cfg.KeepAlive, Control: c.egressControlLocked(backendID, host)}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				telemetry.RecordBackendDialError(backendID)
+				return nil, err
+			}
+			telemetry.RecordBackendConnOpened(backendID)
+			return &trackedConn{Conn: conn, backendID: backendID}, nil
+		},
+		ForceAttemptHTTP2:     cfg.HTTP2,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConns,
+		MaxConnsPerHost:       cfg.MaxConns,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	switch {
+	case protocol == config.ProtocolHTTP2:
+		transport.ForceAttemptHTTP2 = true
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP2(true)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	case protocol == config.ProtocolHTTP1 || !cfg.HTTP2:
+		// A non-nil empty map disables HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	client := &http.Client{
+		Timeout:   c.timeout,
+		Transport: &requestid.Transport{Base: transport},
+	}
+	return transport, client
+}
+
+// egressControlLocked returns a net.Dialer Control func refusing addresses the
+// egress policy denies for a backend whose URI names host, or nil without a
+// policy. c.mu must be held.
+func (c *BackendClient) egressControlLocked(backendID, host string) func(network, address string, _ syscall.RawConn) error {
+	policy := c.egress
+	if policy == nil {
+		return nil
+	}
+	return func(network, address string, _ syscall.RawConn) error {
+		err := policy.CheckDial(backendID, host, address)
+		if err != nil {
+			telemetry.RecordEgressViolation(egressSourceDial)
+			c.logger.Error("backend dial rejected by egress allowlist",
+				zap.String("backend_id", backendID),
+				zap.String("host", host),
+				zap.String("address", address),
+				zap.Error(err),
+			)
+		}
+		return err
+	}
+}
+
+// egressSourceDial labels violations caught at dial time.
+const egressSourceDial = "dial"
+
+// do sends req to backend through the backend's pool.
+func (c *BackendClient) do(backend *BackendEndpoint, req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			telemetry.RecordBackendConnAcquired(backend.ID, info.Reused)
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	return c.clientFor(backend).Do(req)
+}
+
+// Send sends req to backend through the backend's pool, and so through the
+// egress dial check, without the client timeout: streaming responses outlive
+// it. Callers bound the request with its context.
+func (c *BackendClient) Send(backend *BackendEndpoint, req *http.Request) (*http.Response, error) {
+	client := &http.Client{Transport: c.clientFor(backend).Transport}
+	return client.Do(req)
+}
+
+// RecyclePool replaces backendID's connections with fresh ones. It is a
+// no-op if the backend has no pool yet.
+func (c *BackendClient) RecyclePool(backendID, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeGRPCLocked(backendID) {
+		// Redialled on the next request
+		telemetry.RecordBackendPoolRecycle(backendID, reason)
+		c.logger.Info("backend connection pool recycled",
+			zap.String("backend_id", backendID),
+			zap.String("reason", reason),
+		)
+		return
+	}
+	pool, ok := c.pools[backendID]
+	if !ok {
+		return
+	}
+	old := pool.transport
+	pool.transport, pool.client = c.newTransport(backendID, pool.host, pool.cfg, pool.protocol)
+	c.closeLater(old)
+	telemetry.RecordBackendPoolRecycle(backendID, reason)
+	c.logger.Info("backend connection pool recycled",
+		zap.String("backend_id", backendID),
+		zap.String("reason", reason),
+	)
+}
+
+// ClosePool drops backendID's pool, e.g. when the backend is removed.
+func (c *BackendClient) ClosePool(backendID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pool, ok := c.pools[backendID]; ok {
+		delete(c.pools, backendID)
+		c.closeLater(pool.transport)
+	}
+	c.closeGRPCLocked(backendID)
+}
+
+// retireLocked closes a pool that is being replaced and records why.
+func (c *BackendClient) retireLocked(backendID string, pool *backendPool, reason string) {
+	delete(c.pools, backendID)
+	c.closeLater(pool.transport)
+	telemetry.RecordBackendPoolRecycle(backendID, reason)
+}
+
+// closeLater closes old's idle connections now and again once in-flight
+// requests have timed out, so connections they return are not kept.
+func (c *BackendClient) closeLater(old *http.Transport) {
+	old.CloseIdleConnections()
+	time.AfterFunc(c.timeout, old.CloseIdleConnections)
+}
+
+// StartDNSRefresh re-resolves backend hostnames in the background and
+// recycles a pool when its IP set changes. Stop it with StopDNSRefresh.
+func (c *BackendClient) StartDNSRefresh() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.stopDNS = cancel
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(dnsRefreshTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				c.refreshDNS(ctx, now)
+			}
+		}
+	}()
+}
+
+// StopDNSRefresh stops background re-resolution.
+func (c *BackendClient) StopDNSRefresh() {
+	c.mu.Lock()
+	stop := c.stopDNS
+	c.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+	c.wg.Wait()
+}
+
+// refreshDNS re-resolves every pool that is due at now.
+func (c *BackendClient) refreshDNS(ctx context.Context, now time.Time) {
+	type due struct {
+		backendID string
+		host      string
+	}
+	var dueHosts []due
+	c.mu.Lock()
+	for backendID, pool := range c.pools {
+		if pool.host == "" || pool.cfg.DNSRefresh <= 0 || now.Before(pool.nextResolve) {
+			continue
+		}
+		if _, err := netip.ParseAddr(pool.host); err == nil {
+			continue
+		}
+		pool.nextResolve = now.Add(pool.cfg.DNSRefresh)
+		dueHosts = append(dueHosts, due{backendID: backendID, host: pool.host})
+	}
+	c.mu.Unlock()
+
+	// Resolve without holding the lock
+	for _, d := range dueHosts {
+		lookupCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		addrs, err := c.lookup(lookupCtx, d.host)
+		cancel()
+		if err != nil || len(addrs) == 0 {
+			c.logger.Debug("backend DNS refresh failed", zap.String("backend_id", d.backendID), zap.Error(err))
+			continue
+		}
+		c.updateAddrs(d.backendID, d.host, addrs)
+	}
+}
+
+// updateAddrs records a backend's resolved addresses and recycles its pool
+// when they differ from the previous resolution.
+func (c *BackendClient) updateAddrs(backendID, host string, addrs []netip.Addr) {
+	resolved := make([]netip.Addr, len(addrs))
+	for i, addr := range addrs {
+		resolved[i] = addr.Unmap()
+	}
+	slices.SortFunc(resolved, func(a, b netip.Addr) int { return a.Compare(b) })
+	resolved = slices.Compact(resolved)
+
+	c.mu.Lock()
+	pool, ok := c.pools[backendID]
+	if !ok || pool.host != host {
+		c.mu.Unlock()
+		return
+	}
+	changed := pool.addrs != nil && !slices.Equal(pool.addrs, resolved)
+	pool.addrs = resolved
+	c.mu.Unlock()
+
+	if changed {
+		c.RecyclePool(backendID, PoolRecycleDNSChange)
+	}
+}
+
+// trackedConn decrements the backend's open connection gauge once on Close.
+type trackedConn struct {
+	net.Conn
+	backendID string
+	once      sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { telemetry.RecordBackendConnClosed(c.backendID) })
+	return c.Conn.Close()
+}
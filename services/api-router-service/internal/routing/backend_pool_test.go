@@ -0,0 +1,150 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func TestBackendClientPerBackendPools(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	client := NewBackendClient(zap.NewNop(), time.Second)
+	client.SetPools(map[string]config.BackendPoolConfig{
+		"gpu-a": {MaxIdleConns: 4, MaxConns: 8, IdleConnTimeout: time.Minute, HTTP2: false},
+	})
+	a := &BackendEndpoint{ID: "gpu-a", URI: backend.URL}
+	b := &BackendEndpoint{ID: "gpu-b", URI: backend.URL}
+	for _, endpoint := range []*BackendEndpoint{a, b} {
+		if err := client.HealthCheck(context.Background(), endpoint); err != nil {
+			t.Fatalf("HealthCheck(%s): %v", endpoint.ID, err)
+		}
+	}
+
+	poolA, poolB := client.pools["gpu-a"], client.pools["gpu-b"]
+	if poolA.transport == poolB.transport {
+		t.Fatal("expected backends to have separate transports")
+	}
+	if poolA.transport.MaxIdleConnsPerHost != 4 || poolA.transport.MaxConnsPerHost != 8 || poolA.transport.TLSNextProto == nil {
+		t.Fatalf("gpu-a transport not tuned from its pool config")
+	}
+	if poolB.transport.MaxIdleConnsPerHost != config.DefaultBackendPool().MaxIdleConns || !poolB.transport.ForceAttemptHTTP2 {
+		t.Fatalf("gpu-b transport should use the default pool config")
+	}
+
+	// A new URI for the same backend gets a fresh pool
+	moved := &BackendEndpoint{ID: "gpu-a", URI: backend.URL + "/v2"}
+	client.clientFor(moved)
+	if client.pools["gpu-a"] == poolA {
+		t.Fatal("expected pool to be replaced after URI change")
+	}
+}
+
+func TestBackendClientRecyclesOnDNSChange(t *testing.T) {
+	client := NewBackendClient(zap.NewNop(), time.Second)
+	answers := []string{"10.0.0.1", "10.0.0.2"}
+	client.lookup = func(context.Context, string) ([]netip.Addr, error) {
+		addrs := make([]netip.Addr, len(answers))
+		for i, answer := range answers {
+			addrs[i] = netip.MustParseAddr(answer)
+		}
+		return addrs, nil
+	}
+	client.clientFor(&BackendEndpoint{ID: "gpu-a", URI: "http://vllm.internal:8000/v1/completions"})
+	client.clientFor(&BackendEndpoint{ID: "gpu-b", URI: "http://10.0.0.9:8000/v1/completions"})
+	first := client.pools["gpu-a"].transport
+
+	now := time.Now()
+	client.refreshDNS(context.Background(), now)
+	if client.pools["gpu-a"].transport != first {
+		t.Fatal("first resolution must not recycle the pool")
+	}
+
+	// Same set in a different order is not a change
+	answers = []string{"10.0.0.2", "10.0.0.1"}
+	now = now.Add(time.Minute)
+	client.refreshDNS(context.Background(), now)
+	if client.pools["gpu-a"].transport != first {
+		t.Fatal("reordered answers must not recycle the pool")
+	}
+
+	answers = []string{"10.0.0.3"}
+	client.refreshDNS(context.Background(), now.Add(time.Second))
+	if client.pools["gpu-a"].transport != first {
+		t.Fatal("pool re-resolved before its refresh interval")
+	}
+	client.refreshDNS(context.Background(), now.Add(time.Minute))
+	if client.pools["gpu-a"].transport == first {
+		t.Fatal("expected pool to be recycled after the IP set changed")
+	}
+	if client.pools["gpu-b"].addrs != nil {
+		t.Fatal("IP literal backends must not be re-resolved")
+	}
+}
+
+func TestHealthMonitorRecyclesUnhealthyBackendPool(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	client := NewBackendClient(zap.NewNop(), time.Second)
+	m := NewHealthMonitor(client, zap.NewNop(), time.Minute)
+	endpoint := &BackendEndpoint{ID: "gpu-a", URI: backend.URL}
+	m.RegisterBackend("gpu-a", endpoint)
+
+	_ = m.CheckBackendNow("gpu-a", endpoint)
+	first := client.pools["gpu-a"].transport
+	_ = m.CheckBackendNow("gpu-a", endpoint)
+	_ = m.CheckBackendNow("gpu-a", endpoint)
+	if !m.IsDegraded("gpu-a") || client.pools["gpu-a"].transport == first {
+		t.Fatal("expected pool to be recycled when the backend turned unhealthy")
+	}
+
+	m.UnregisterBackend("gpu-a")
+	if _, ok := client.pools["gpu-a"]; ok {
+		t.Fatal("expected pool to be closed when the backend is unregistered")
+	}
+}
+
+func TestBackendClientEgressDialCheck(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+	endpoint := &BackendEndpoint{ID: "gpu-a", URI: backend.URL}
+
+	// A listed host does not admit loopback addresses
+	denied, err := config.NewEgressPolicy([]string{"http"}, []string{"127.0.0.1"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	client := NewBackendClient(zap.NewNop(), time.Second)
+	client.SetEgressPolicy(denied)
+	err = client.HealthCheck(context.Background(), endpoint)
+	var violation *config.EgressViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected dial to be refused with an EgressViolation, got %v", err)
+	}
+
+	allowed, err := config.NewEgressPolicy([]string{"http"}, nil, []string{"127.0.0.0/8"}, nil)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	client = NewBackendClient(zap.NewNop(), time.Second)
+	client.SetEgressPolicy(allowed)
+	if err := client.HealthCheck(context.Background(), endpoint); err != nil {
+		t.Fatalf("expected dial within allowed CIDRs to succeed, got %v", err)
+	}
+}
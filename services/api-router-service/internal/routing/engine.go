@@ -28,6 +28,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
 )
 
 // RoutingDecision represents a routing decision made by the engine.
@@ -43,7 +44,8 @@ type RoutingDecision struct {
 type Engine struct {
 	healthMonitor   *HealthMonitor
 	backendRegistry *config.BackendRegistry
-	modelRegistry   *Registry // Model registry for vLLM deployments
+	modelRegistry   *Registry   // Model registry for vLLM deployments
+	federation      *Federation // Cross-region ordering; nil for single-region deployments
 	logger          *zap.Logger
 	decisions       []RoutingDecision // For metrics/debugging
 	mu              sync.RWMutex
@@ -65,6 +67,11 @@ func (e *Engine) SetModelRegistry(registry *Registry) {
 	e.modelRegistry = registry
 }
 
+// SetFederation enables region-aware ordering of backends.
+func (e *Engine) SetFederation(federation *Federation) {
+	e.federation = federation
+}
+
 // SelectBackend selects a backend based on routing policy, weights, and health status.
 func (e *Engine) SelectBackend(ctx context.Context, policy *config.RoutingPolicy) (*BackendEndpoint, *RoutingDecision, error) {
 	if policy == nil || len(policy.Backends) == 0 {
@@ -77,6 +84,11 @@ func (e *Engine) SelectBackend(ctx context.Context, policy *config.RoutingPolicy
 		return nil, nil, fmt.Errorf("no available backends")
 	}
 
+	// Prefer the closest region tier when federated
+	if e.federation != nil {
+		availableBackends = e.federation.PreferredTier(availableBackends)
+	}
+
 	// Select backend using weighted selection
 	selected := e.selectWeightedBackend(availableBackends)
 	if selected == nil {
@@ -119,8 +131,12 @@ func (e *Engine) RouteWithFailover(
 		return nil, nil, fmt.Errorf("no available backends")
 	}
 
-	// Sort by weight descending for failover order
-	e.sortBackendsByWeight(availableBackends)
+	// Sort by weight descending for failover order (region tiers first when federated)
+	if e.federation != nil {
+		availableBackends = e.federation.Order(availableBackends)
+	} else {
+		e.sortBackendsByWeight(availableBackends)
+	}
 
 	var lastErr error
 	var lastDecision *RoutingDecision
@@ -154,6 +170,7 @@ func (e *Engine) RouteWithFailover(
 		response, err := client.ForwardRequest(ctx, endpoint, request)
 		if err == nil {
 			// Success
+			e.recordCrossRegion(decision)
 			e.recordDecision(decision)
 			return response, decision, nil
 		}
@@ -274,7 +291,8 @@ func (e *Engine) getAvailableBackends(policy *config.RoutingPolicy) []config.Bac
 		availableBackends = policy.Backends
 	}
 
-	return availableBackends
+	// Ramp recovered backends back up (also copies, so policy.Backends is never modified)
+	return e.applyWarmup(availableBackends)
 }
 
 // selectWeightedBackend selects a backend using weighted random selection.
@@ -318,6 +336,19 @@ func (e *Engine) selectWeightedBackend(backends []config.BackendWeight) *config.
 	return &backends[0]
 }
 
+// recordCrossRegion notes a request served outside the router's region.
+func (e *Engine) recordCrossRegion(decision *RoutingDecision) {
+	if e.federation == nil {
+		return
+	}
+	region := e.federation.RegionOf(decision.BackendID)
+	if region == e.federation.LocalRegion() {
+		return
+	}
+	decision.Reason = fmt.Sprintf("%s (cross-region: %s)", decision.Reason, region)
+	telemetry.RecordCrossRegionFailover(e.federation.LocalRegion(), region)
+}
+
 // sortBackendsByWeight sorts backends by weight in descending order.
 func (e *Engine) sortBackendsByWeight(backends []config.BackendWeight) {
 	for i := 0; i < len(backends)-1; i++ {
@@ -345,6 +376,7 @@ func (e *Engine) buildBackendEndpoint(backendID, model string) (*BackendEndpoint
 		URI:         backendCfg.URI,
 		ModelVariant: model,
 		Timeout:     backendCfg.Timeout,
+		Protocol:    backendCfg.Protocol,
 	}, nil
 }
 
@@ -0,0 +1,293 @@
+// Package routing provides health-aware global routing across federated clusters.
+//
+// Purpose:
+//   A single router deployment can front inference fleets in several clusters
+//   and regions. Federation tracks per-region probe latency from the health
+//   monitor and orders candidate backends so the router's own region is tried
+//   first, then fallback regions from the failover rules, then any remaining
+//   regions by measured latency.
+//
+// Key Responsibilities:
+//   - Smooth probe latency per region (EWMA over health monitor probes)
+//   - Order backends for failover and pick the preferred tier for selection
+//   - Report federation status for the admin API
+//
+// Debugging Notes:
+//   - Backends without a region label count as local
+//   - Regions not named in the rule for the router's region are still used,
+//     after the ruled ones, ordered by probe latency (unprobed regions last)
+//   - Data residency (BackendRegistry.ApplyResidency) is applied before ordering,
+//     so failover never leaves an org's allowed regions
+//
+package routing
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// latencySmoothing is the EWMA weight of the newest probe sample.
+const latencySmoothing = 0.3
+
+// FederationConfig configures a Federation.
+type FederationConfig struct {
+	LocalRegion   string
+	FailoverRules map[string][]string // region -> ordered fallback regions
+	Registry      *config.BackendRegistry
+	HealthMonitor *HealthMonitor
+}
+
+// Federation orders backends across regions.
+type Federation struct {
+	localRegion   string
+	failoverOrder []string // fallback regions for localRegion
+	registry      *config.BackendRegistry
+	healthMonitor *HealthMonitor
+
+	mu      sync.RWMutex
+	latency map[string]time.Duration // region -> smoothed probe latency
+	probed  map[string]time.Time     // region -> last successful probe
+}
+
+// NewFederation creates a federation and subscribes it to health probes.
+func NewFederation(cfg FederationConfig) *Federation {
+	f := &Federation{
+		localRegion:   cfg.LocalRegion,
+		failoverOrder: cfg.FailoverRules[cfg.LocalRegion],
+		registry:      cfg.Registry,
+		healthMonitor: cfg.HealthMonitor,
+		latency:       make(map[string]time.Duration),
+		probed:        make(map[string]time.Time),
+	}
+	if cfg.HealthMonitor != nil {
+		cfg.HealthMonitor.OnProbe(f.ObserveProbe)
+	}
+	return f
+}
+
+// LocalRegion returns the region this router runs in.
+func (f *Federation) LocalRegion() string {
+	return f.localRegion
+}
+
+// ObserveProbe folds a successful probe into its region's latency.
+func (f *Federation) ObserveProbe(backendID string, latency time.Duration, err error) {
+	if err != nil {
+		return
+	}
+	region := f.regionOf(backendID)
+	if region == "" {
+		return
+	}
+
+	f.mu.Lock()
+	smoothed := latency
+	if prev, ok := f.latency[region]; ok {
+		smoothed = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(prev))
+	}
+	f.latency[region] = smoothed
+	f.probed[region] = time.Now()
+	f.mu.Unlock()
+
+	telemetry.RecordRegionProbeLatency(region, smoothed)
+}
+
+// RegionOf returns the backend's effective region (the local region when unlabelled).
+func (f *Federation) RegionOf(backendID string) string {
+	if region := f.regionOf(backendID); region != "" {
+		return region
+	}
+	return f.localRegion
+}
+
+func (f *Federation) regionOf(backendID string) string {
+	if f.registry == nil {
+		return ""
+	}
+	backend, err := f.registry.GetBackend(backendID)
+	if err != nil {
+		return ""
+	}
+	return backend.Region
+}
+
+// rank returns the tier of a region: 0 local, 1..n ruled fallbacks, then the rest.
+func (f *Federation) rank(region string) int {
+	if region == "" || region == f.localRegion {
+		return 0
+	}
+	for i, fallback := range f.failoverOrder {
+		if fallback == region {
+			return i + 1
+		}
+	}
+	return len(f.failoverOrder) + 1
+}
+
+// latencyOf returns the smoothed latency of a region, or ok=false if never probed.
+func (f *Federation) latencyOf(region string) (time.Duration, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	latency, ok := f.latency[region]
+	return latency, ok
+}
+
+// Order returns backends in failover order: by region tier, then (for
+// unruled regions) probe latency, then weight descending.
+func (f *Federation) Order(backends []config.BackendWeight) []config.BackendWeight {
+	type candidate struct {
+		backend config.BackendWeight
+		rank    int
+		latency time.Duration
+		probed  bool
+	}
+	candidates := make([]candidate, len(backends))
+	for i, backend := range backends {
+		region := f.regionOf(backend.BackendID)
+		latency, probed := f.latencyOf(region)
+		candidates[i] = candidate{backend: backend, rank: f.rank(region), latency: latency, probed: probed}
+	}
+
+	unruled := len(f.failoverOrder) + 1
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.rank == unruled && (a.probed != b.probed || a.latency != b.latency) {
+			if a.probed != b.probed {
+				return a.probed
+			}
+			return a.latency < b.latency
+		}
+		return a.backend.Weight > b.backend.Weight
+	})
+
+	ordered := make([]config.BackendWeight, len(candidates))
+	for i, c := range candidates {
+		ordered[i] = c.backend
+	}
+	return ordered
+}
+
+// PreferredTier returns the backends in the best-ranked region tier present,
+// for weighted selection among them.
+func (f *Federation) PreferredTier(backends []config.BackendWeight) []config.BackendWeight {
+	ordered := f.Order(backends)
+	if len(ordered) == 0 {
+		return ordered
+	}
+	best := f.rank(f.regionOf(ordered[0].BackendID))
+	tier := ordered[:0:0]
+	for _, backend := range ordered {
+		if f.rank(f.regionOf(backend.BackendID)) != best {
+			break
+		}
+		tier = append(tier, backend)
+	}
+	return tier
+}
+
+// FederationStatus summarises federation state for the admin API.
+type FederationStatus struct {
+	LocalRegion   string         `json:"local_region"`
+	FailoverOrder []string       `json:"failover_order"`
+	Regions       []RegionStatus `json:"regions"`
+}
+
+// RegionStatus describes one region's backends and probe latency.
+type RegionStatus struct {
+	Region          string                   `json:"region"`
+	Rank            int                      `json:"rank"`
+	Clusters        []string                 `json:"clusters"`
+	HealthyBackends int                      `json:"healthy_backends"`
+	ProbeLatencyMS  *float64                 `json:"probe_latency_ms,omitempty"`
+	LastProbeAt     *time.Time               `json:"last_probe_at,omitempty"`
+	Backends        []FederatedBackendStatus `json:"backends"`
+}
+
+// FederatedBackendStatus describes one backend within a region.
+type FederatedBackendStatus struct {
+	BackendID string  `json:"backend_id"`
+	Cluster   string  `json:"cluster,omitempty"`
+	Health    string  `json:"health_status"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Status reports every registered backend grouped by region.
+func (f *Federation) Status() FederationStatus {
+	status := FederationStatus{
+		LocalRegion:   f.localRegion,
+		FailoverOrder: append([]string{}, f.failoverOrder...),
+		Regions:       []RegionStatus{},
+	}
+	if f.registry == nil {
+		return status
+	}
+
+	byRegion := make(map[string]*RegionStatus)
+	for _, backendID := range f.registry.ListBackends() {
+		backend, err := f.registry.GetBackend(backendID)
+		if err != nil {
+			continue
+		}
+		region := backend.Region
+		if region == "" {
+			region = f.localRegion
+		}
+		rs, ok := byRegion[region]
+		if !ok {
+			rs = &RegionStatus{Region: region, Rank: f.rank(region), Clusters: []string{}}
+			f.mu.RLock()
+			if latency, ok := f.latency[region]; ok {
+				ms := float64(latency) / float64(time.Millisecond)
+				rs.ProbeLatencyMS = &ms
+				probed := f.probed[region]
+				rs.LastProbeAt = &probed
+			}
+			f.mu.RUnlock()
+			byRegion[region] = rs
+		}
+
+		bs := FederatedBackendStatus{BackendID: backendID, Cluster: backend.Cluster, Health: string(HealthStatusUnknown)}
+		if f.healthMonitor != nil {
+			if health, ok := f.healthMonitor.GetHealth(backendID); ok {
+				bs.Health = string(health.Status)
+				bs.LatencyMS = float64(health.Latency) / float64(time.Millisecond)
+				if health.Status == HealthStatusHealthy {
+					rs.HealthyBackends++
+				}
+			}
+		}
+		rs.Backends = append(rs.Backends, bs)
+		if backend.Cluster != "" && !containsString(rs.Clusters, backend.Cluster) {
+			rs.Clusters = append(rs.Clusters, backend.Cluster)
+		}
+	}
+
+	for _, rs := range byRegion {
+		sort.Slice(rs.Backends, func(i, j int) bool { return rs.Backends[i].BackendID < rs.Backends[j].BackendID })
+		sort.Strings(rs.Clusters)
+		status.Regions = append(status.Regions, *rs)
+	}
+	sort.Slice(status.Regions, func(i, j int) bool {
+		if status.Regions[i].Rank != status.Regions[j].Rank {
+			return status.Regions[i].Rank < status.Regions[j].Rank
+		}
+		return status.Regions[i].Region < status.Regions[j].Region
+	})
+	return status
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func newTestFederation(t *testing.T) *Federation {
+	t.Helper()
+	registry := config.NewBackendRegistry(&config.Config{
+		BackendEndpoints: "local-a:http://a:8000,local-b:http://b:8000",
+		BackendRegions:   "local-a=us-east-1",
+	})
+	for _, b := range []config.BackendEndpointConfig{
+		{ID: "west", URI: "http://west", Region: "us-west-2", Cluster: "west"},
+		{ID: "eu", URI: "http://eu", Region: "eu-west-1", Cluster: "eu"},
+		{ID: "ap", URI: "http://ap", Region: "ap-south-1", Cluster: "ap"},
+	} {
+		if err := registry.RegisterFederatedBackend(b); err != nil {
+			t.Fatalf("RegisterFederatedBackend: %v", err)
+		}
+	}
+	return NewFederation(FederationConfig{
+		LocalRegion:   "us-east-1",
+		FailoverRules: map[string][]string{"us-east-1": {"us-west-2"}},
+		Registry:      registry,
+	})
+}
+
+func backendIDs(backends []config.BackendWeight) []string {
+	ids := make([]string, len(backends))
+	for i, b := range backends {
+		ids[i] = b.BackendID
+	}
+	return ids
+}
+
+func TestFederationOrder(t *testing.T) {
+	f := newTestFederation(t)
+	f.ObserveProbe("eu", 80*time.Millisecond, nil)
+	f.ObserveProbe("ap", 200*time.Millisecond, nil)
+
+	ordered := f.Order([]config.BackendWeight{
+		{BackendID: "ap", Weight: 100},
+		{BackendID: "eu", Weight: 10},
+		{BackendID: "west", Weight: 100},
+		{BackendID: "local-b", Weight: 20},
+		{BackendID: "local-a", Weight: 80},
+	})
+
+	want := []string{"local-a", "local-b", "west", "eu", "ap"}
+	got := backendIDs(ordered)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFederationPreferredTier(t *testing.T) {
+	f := newTestFederation(t)
+
+	tier := f.PreferredTier([]config.BackendWeight{
+		{BackendID: "eu", Weight: 100},
+		{BackendID: "west", Weight: 50},
+	})
+	if len(tier) != 1 || tier[0].BackendID != "west" {
+		t.Fatalf("expected ruled fallback region first, got %v", backendIDs(tier))
+	}
+}
+
+func TestFederationStatus(t *testing.T) {
+	f := newTestFederation(t)
+	f.ObserveProbe("eu", 50*time.Millisecond, nil)
+	f.ObserveProbe("eu", 0, errors.New("probe failed"))
+
+	status := f.Status()
+	if status.LocalRegion != "us-east-1" || len(status.Regions) != 4 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Regions[0].Region != "us-east-1" || len(status.Regions[0].Backends) != 2 {
+		t.Fatalf("expected local region first with both local backends, got %+v", status.Regions[0])
+	}
+	for _, region := range status.Regions {
+		if region.Region == "eu-west-1" {
+			if region.ProbeLatencyMS == nil || *region.ProbeLatencyMS != 50 {
+				t.Fatalf("expected failed probe to be ignored, got %+v", region.ProbeLatencyMS)
+			}
+			if len(region.Clusters) != 1 || region.Clusters[0] != "eu" {
+				t.Fatalf("unexpected clusters %v", region.Clusters)
+			}
+		}
+	}
+}
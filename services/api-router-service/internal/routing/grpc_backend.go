@@ -0,0 +1,502 @@
+// Package routing provides the gRPC backend client.
+//
+// Purpose:
+//   Backends with protocol "grpc" speak the KServe v2 inference protocol
+//   (inference.GRPCInferenceService) as served by Triton, including Triton's
+//   vLLM backend. Public API requests are transcoded: the prompt goes in the
+//   "text_input" BYTES tensor, max_tokens/temperature/parameters as JSON in
+//   "sampling_parameters", and the "text_output" tensor comes back as text.
+//   Messages are encoded by hand with protowire, like shared-go/usagerecord,
+//   so there is no generated code to keep in sync.
+//
+// Debugging Notes:
+//   - The backend URI names the server and model: http://triton:8001/vllm_model
+//     (plaintext) or https://... (TLS)
+//   - Requests use ModelStreamInfer, which also works for decoupled models
+//     such as Triton's vLLM backend; only the first response is read
+//   - Health probes call ModelReady for the model instead of GET <path>
+//   - Triton does not report token counts, so tokens_used is estimated from
+//     the output length (4 bytes per token) unless the response carries a
+//     "num_output_tokens" parameter
+//
+package routing
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/telemetry"
+)
+
+// KServe v2 methods used by the router.
+const (
+	grpcModelStreamInfer = "/inference.GRPCInferenceService/ModelStreamInfer"
+	grpcModelReady       = "/inference.GRPCInferenceService/ModelReady"
+)
+
+// Tensor names of Triton's vLLM backend.
+const (
+	grpcTextInput          = "text_input"
+	grpcSamplingParameters = "sampling_parameters"
+	grpcExcludeInput       = "exclude_input_in_output"
+	grpcTextOutput         = "text_output"
+)
+
+// grpcBackend is a connection to one gRPC backend.
+type grpcBackend struct {
+	uri   string
+	model string
+	conn  *grpc.ClientConn
+}
+
+// grpcFor returns the connection for backend, dialing it on first use.
+func (c *BackendClient) grpcFor(backend *BackendEndpoint) (*grpcBackend, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.grpcConns[backend.ID]; ok {
+		if existing.uri == backend.URI {
+			return existing, nil
+		}
+		c.closeGRPCLocked(backend.ID)
+		telemetry.RecordBackendPoolRecycle(backend.ID, PoolRecycleURIChange)
+	}
+
+	u, err := url.Parse(backend.URI)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("grpc backend %s: uri must be http(s)://host:port/model", backend.ID)
+	}
+	model := strings.Trim(u.Path, "/")
+	if model == "" {
+		return nil, fmt.Errorf("grpc backend %s: uri path must name the model", backend.ID)
+	}
+	var creds credentials.TransportCredentials
+	switch u.Scheme {
+	case "http":
+		creds = insecure.NewCredentials()
+	case "https":
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	default:
+		return nil, fmt.Errorf("grpc backend %s: unsupported scheme %q", backend.ID, u.Scheme)
+	}
+
+	cfg := c.poolConfigLocked(backend.ID)
+	dialer := &net.Dialer{KeepAlive: cfg.KeepAlive, Control: c.egressControlLocked(backend.ID, u.Hostname())}
+	backendID := backend.ID
+	conn, err := grpc.NewClient(u.Host,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				telemetry.RecordBackendDialError(backendID)
+				return nil, err
+			}
+			telemetry.RecordBackendConnOpened(backendID)
+			return &trackedConn{Conn: conn, backendID: backendID}, nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("grpc backend %s: %w", backend.ID, err)
+	}
+	g := &grpcBackend{uri: backend.URI, model: model, conn: conn}
+	c.grpcConns[backend.ID] = g
+	return g, nil
+}
+
+// forwardGRPC sends req as a KServe v2 inference request.
+func (c *BackendClient) forwardGRPC(ctx context.Context, backend *BackendEndpoint, req *BackendRequest) (*BackendResponse, error) {
+	g, err := c.grpcFor(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	sampling := make(map[string]interface{}, len(req.Parameters)+2)
+	for k, v := range req.Parameters {
+		sampling[k] = v
+	}
+	if req.MaxTokens > 0 {
+		sampling["max_tokens"] = req.MaxTokens
+	}
+	if req.Temperature != 0 {
+		sampling["temperature"] = req.Temperature
+	}
+	samplingJSON, err := json.Marshal(sampling)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sampling parameters: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	stream, err := g.conn.NewStream(ctx,
+		&grpc.StreamDesc{StreamName: "ModelStreamInfer", ServerStreams: true, ClientStreams: true},
+		grpcModelStreamInfer,
+		grpc.ForceCodec(rawCodec{}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("backend request failed: %w", err)
+	}
+	request := encodeModelInferRequest(g.model, req.Prompt, samplingJSON)
+	if err := stream.SendMsg(&request); err != nil {
+		return nil, fmt.Errorf("backend request failed: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("backend request failed: %w", err)
+	}
+	var response []byte
+	if err := stream.RecvMsg(&response); err != nil {
+		return nil, fmt.Errorf("backend request failed: %w", err)
+	}
+
+	text, outputTokens, err := decodeModelStreamInferResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if outputTokens == 0 && text != "" {
+		outputTokens = (len(text) + 3) / 4
+	}
+	return &BackendResponse{
+		Text:       text,
+		TokensUsed: outputTokens,
+		Metadata:   map[string]interface{}{"model": g.model, "protocol": "grpc"},
+	}, nil
+}
+
+// probeGRPC checks the backend's model with ModelReady. It reports 200 when
+// ready and 503 when not, so probe history reads like an HTTP probe.
+func (c *BackendClient) probeGRPC(ctx context.Context, backend *BackendEndpoint) (int, error) {
+	g, err := c.grpcFor(backend)
+	if err != nil {
+		return 0, err
+	}
+	request := protowire.AppendTag(nil, 1, protowire.BytesType)
+	request = protowire.AppendString(request, g.model)
+	var response []byte
+	if err := g.conn.Invoke(ctx, grpcModelReady, &request, &response, grpc.ForceCodec(rawCodec{})); err != nil {
+		return 0, fmt.Errorf("health check failed: %w", err)
+	}
+	ready, err := decodeReadyResponse(response)
+	if err != nil {
+		return 0, fmt.Errorf("health check failed: %w", err)
+	}
+	if !ready {
+		return http.StatusServiceUnavailable, fmt.Errorf("backend unhealthy: model %s is not ready", g.model)
+	}
+	return http.StatusOK, nil
+}
+
+// closeGRPCLocked drops backendID's gRPC connection, if any. It is closed
+// once in-flight requests have timed out.
+func (c *BackendClient) closeGRPCLocked(backendID string) bool {
+	g, ok := c.grpcConns[backendID]
+	if !ok {
+		return false
+	}
+	delete(c.grpcConns, backendID)
+	time.AfterFunc(c.timeout, func() { _ = g.conn.Close() })
+	return true
+}
+
+// rawCodec passes pre-encoded protobuf messages through as *[]byte.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec: unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Name is "proto" so the content type is application/grpc+proto.
+func (rawCodec) Name() string { return "proto" }
+
+// encodeModelInferRequest builds a ModelInferRequest for Triton's vLLM backend.
+func encodeModelInferRequest(model, prompt string, samplingJSON []byte) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType) // model_name
+	b = protowire.AppendString(b, model)
+	b = appendBytesTensor(b, grpcTextInput, []byte(prompt))
+	b = appendBytesTensor(b, grpcSamplingParameters, samplingJSON)
+	b = appendBoolTensor(b, grpcExcludeInput, true)
+
+	var output []byte // InferRequestedOutputTensor
+	output = protowire.AppendTag(output, 1, protowire.BytesType)
+	output = protowire.AppendString(output, grpcTextOutput)
+	b = protowire.AppendTag(b, 6, protowire.BytesType) // outputs
+	return protowire.AppendBytes(b, output)
+}
+
+// appendBytesTensor appends a one-element BYTES InferInputTensor (inputs, field 5).
+func appendBytesTensor(b []byte, name string, value []byte) []byte {
+	var contents []byte // InferTensorContents.bytes_contents
+	contents = protowire.AppendTag(contents, 8, protowire.BytesType)
+	contents = protowire.AppendBytes(contents, value)
+	return appendInputTensor(b, name, "BYTES", contents)
+}
+
+// appendBoolTensor appends a one-element BOOL InferInputTensor (inputs, field 5).
+func appendBoolTensor(b []byte, name string, value bool) []byte {
+	var contents []byte // InferTensorContents.bool_contents
+	contents = protowire.AppendTag(contents, 1, protowire.VarintType)
+	contents = protowire.AppendVarint(contents, protowire.EncodeBool(value))
+	return appendInputTensor(b, name, "BOOL", contents)
+}
+
+func appendInputTensor(b []byte, name, datatype string, contents []byte) []byte {
+	var tensor []byte
+	tensor = protowire.AppendTag(tensor, 1, protowire.BytesType)
+	tensor = protowire.AppendString(tensor, name)
+	tensor = protowire.AppendTag(tensor, 2, protowire.BytesType)
+	tensor = protowire.AppendString(tensor, datatype)
+	tensor = protowire.AppendTag(tensor, 3, protowire.BytesType) // shape [1], packed
+	tensor = protowire.AppendBytes(tensor, protowire.AppendVarint(nil, 1))
+	tensor = protowire.AppendTag(tensor, 5, protowire.BytesType)
+	tensor = protowire.AppendBytes(tensor, contents)
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, tensor)
+}
+
+// decodeModelStreamInferResponse extracts the text output and, when
+// reported, the output token count from a ModelStreamInferResponse.
+func decodeModelStreamInferResponse(data []byte) (string, int, error) {
+	var errorMessage string
+	var inferResponse []byte
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			errorMessage = string(value)
+		case num == 2 && typ == protowire.BytesType:
+			inferResponse = value
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("decode backend response: %w", err)
+	}
+	if errorMessage != "" {
+		return "", 0, fmt.Errorf("backend returned error: %s", errorMessage)
+	}
+	return decodeModelInferResponse(inferResponse)
+}
+
+// decodeModelInferResponse reads text_output from the typed contents or, as
+// Triton usually sends it, from raw_output_contents.
+func decodeModelInferResponse(data []byte) (string, int, error) {
+	var names []string
+	var typed [][]byte
+	var raw [][]byte
+	var outputTokens int
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 4: // parameters map entry
+			key, param, err := decodeMapEntry(value)
+			if err != nil {
+				return err
+			}
+			if key == "num_output_tokens" {
+				outputTokens = decodeInt64Parameter(param)
+			}
+		case 5: // outputs
+			name, contents, err := decodeOutputTensor(value)
+			if err != nil {
+				return err
+			}
+			names = append(names, name)
+			typed = append(typed, contents)
+		case 6: // raw_output_contents
+			raw = append(raw, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("decode backend response: %w", err)
+	}
+	for i, name := range names {
+		if name != grpcTextOutput {
+			continue
+		}
+		if typed[i] != nil {
+			return string(typed[i]), outputTokens, nil
+		}
+		if i < len(raw) {
+			text, err := decodeRawBytesTensor(raw[i])
+			if err != nil {
+				return "", 0, fmt.Errorf("decode backend response: %w", err)
+			}
+			return text, outputTokens, nil
+		}
+	}
+	return "", 0, errors.New("decode backend response: no text_output tensor")
+}
+
+// decodeOutputTensor returns an InferOutputTensor's name and its first
+// bytes_contents element (nil when the data is in raw_output_contents).
+func decodeOutputTensor(data []byte) (string, []byte, error) {
+	var name string
+	var contents []byte
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name = string(value)
+		case 5:
+			return eachField(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if num == 8 && typ == protowire.BytesType && contents == nil {
+					contents = append([]byte{}, value...)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return name, contents, err
+}
+
+// decodeRawBytesTensor joins the elements of a raw BYTES tensor, each a
+// 4-byte little-endian length followed by the data.
+func decodeRawBytesTensor(data []byte) (string, error) {
+	var sb strings.Builder
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return "", errors.New("truncated BYTES tensor")
+		}
+		n := int(binary.LittleEndian.Uint32(data))
+		data = data[4:]
+		if n > len(data) {
+			return "", errors.New("truncated BYTES tensor")
+		}
+		sb.Write(data[:n])
+		data = data[n:]
+	}
+	return sb.String(), nil
+}
+
+// decodeMapEntry returns a map<string, InferParameter> entry's key and value.
+func decodeMapEntry(data []byte) (string, []byte, error) {
+	var key string
+	var value []byte
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ == protowire.BytesType {
+			switch num {
+			case 1:
+				key = string(v)
+			case 2:
+				value = v
+			}
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+// decodeInt64Parameter reads InferParameter.int64_param (field 2) or
+// uint64_param (field 5).
+func decodeInt64Parameter(data []byte) int {
+	var result int
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return 0
+		}
+		data = data[n:]
+		if typ == protowire.VarintType {
+			v, m := protowire.ConsumeVarint(data)
+			if m < 0 {
+				return 0
+			}
+			if num == 2 || num == 5 {
+				result = int(v)
+			}
+			data = data[m:]
+			continue
+		}
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			return 0
+		}
+		data = data[m:]
+	}
+	return result
+}
+
+// decodeReadyResponse reads the ready flag of ServerReady/ModelReady responses.
+func decodeReadyResponse(data []byte) (bool, error) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return false, protowire.ParseError(n)
+		}
+		data = data[n:]
+		if num == 1 && typ == protowire.VarintType {
+			v, m := protowire.ConsumeVarint(data)
+			if m < 0 {
+				return false, protowire.ParseError(m)
+			}
+			return protowire.DecodeBool(v), nil
+		}
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			return false, protowire.ParseError(m)
+		}
+		data = data[m:]
+	}
+	return false, nil
+}
+
+// eachField calls fn for each field of a message; value is the payload of
+// length-delimited fields and nil otherwise.
+func eachField(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var value []byte
+		if typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			value = v
+			n = m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+		}
+		data = data[n:]
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
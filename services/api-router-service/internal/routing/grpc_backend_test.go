@@ -0,0 +1,159 @@
+package routing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+// fakeTriton answers ModelStreamInfer and ModelReady like Triton's vLLM backend.
+type fakeTriton struct {
+	model    string
+	prompt   string
+	sampling map[string]interface{}
+	failWith string
+}
+
+func (f *fakeTriton) handle(_ any, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	var request []byte
+	if err := stream.RecvMsg(&request); err != nil {
+		return err
+	}
+	switch method {
+	case grpcModelReady:
+		var name string
+		_ = eachField(request, func(num protowire.Number, _ protowire.Type, value []byte) error {
+			if num == 1 {
+				name = string(value)
+			}
+			return nil
+		})
+		ready := protowire.AppendTag(nil, 1, protowire.VarintType)
+		ready = protowire.AppendVarint(ready, protowire.EncodeBool(name == f.model))
+		return stream.SendMsg(&ready)
+
+	case grpcModelStreamInfer:
+		_ = eachField(request, func(num protowire.Number, _ protowire.Type, value []byte) error {
+			switch num {
+			case 1:
+				f.model = string(value)
+			case 5:
+				name, contents, _ := decodeOutputTensor(value)
+				switch name {
+				case grpcTextInput:
+					f.prompt = string(contents)
+				case grpcSamplingParameters:
+					_ = json.Unmarshal(contents, &f.sampling)
+				}
+			}
+			return nil
+		})
+
+		var streamResponse []byte
+		if f.failWith != "" {
+			streamResponse = protowire.AppendTag(nil, 1, protowire.BytesType)
+			streamResponse = protowire.AppendString(streamResponse, f.failWith)
+			return stream.SendMsg(&streamResponse)
+		}
+		// text_output as Triton sends it: metadata in outputs, data in raw_output_contents
+		var output []byte
+		output = protowire.AppendTag(output, 1, protowire.BytesType)
+		output = protowire.AppendString(output, grpcTextOutput)
+		output = protowire.AppendTag(output, 2, protowire.BytesType)
+		output = protowire.AppendString(output, "BYTES")
+		text := "Paris is the capital of France."
+		raw := binary.LittleEndian.AppendUint32(nil, uint32(len(text)))
+		raw = append(raw, text...)
+
+		var response []byte
+		response = protowire.AppendTag(response, 5, protowire.BytesType)
+		response = protowire.AppendBytes(response, output)
+		response = protowire.AppendTag(response, 6, protowire.BytesType)
+		response = protowire.AppendBytes(response, raw)
+		streamResponse = protowire.AppendTag(nil, 2, protowire.BytesType)
+		streamResponse = protowire.AppendBytes(streamResponse, response)
+		return stream.SendMsg(&streamResponse)
+	}
+	return nil
+}
+
+func startFakeTriton(t *testing.T, triton *fakeTriton) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}), grpc.UnknownServiceHandler(triton.handle))
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestBackendClientGRPC(t *testing.T) {
+	triton := &fakeTriton{model: "vllm_model"}
+	addr := startFakeTriton(t, triton)
+
+	client := NewBackendClient(zap.NewNop(), 5*time.Second)
+	backend := &BackendEndpoint{ID: "triton", URI: "http://" + addr + "/vllm_model", Protocol: config.ProtocolGRPC}
+	ctx := context.Background()
+
+	status, err := client.ProbeHTTP(ctx, backend, config.DefaultHealthProbePath, http.StatusOK)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("ProbeHTTP: status %d, err %v", status, err)
+	}
+
+	resp, err := client.ForwardRequest(ctx, backend, &BackendRequest{
+		Prompt:      "What is the capital of France?",
+		MaxTokens:   16,
+		Temperature: 0.2,
+		Parameters:  map[string]interface{}{"top_p": 0.9},
+	})
+	if err != nil {
+		t.Fatalf("ForwardRequest: %v", err)
+	}
+	if resp.Text != "Paris is the capital of France." || resp.TokensUsed != 8 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if triton.model != "vllm_model" || triton.prompt != "What is the capital of France?" {
+		t.Fatalf("backend got model %q prompt %q", triton.model, triton.prompt)
+	}
+	if triton.sampling["max_tokens"] != float64(16) || triton.sampling["temperature"] != 0.2 || triton.sampling["top_p"] != 0.9 {
+		t.Fatalf("unexpected sampling parameters %v", triton.sampling)
+	}
+
+	triton.failWith = "model is overloaded"
+	if _, err := client.ForwardRequest(ctx, backend, &BackendRequest{Prompt: "hi"}); err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Fatalf("expected backend error, got %v", err)
+	}
+
+	notReady := &BackendEndpoint{ID: "other", URI: "http://" + addr + "/missing_model", Protocol: config.ProtocolGRPC}
+	if status, err := client.ProbeHTTP(ctx, notReady, "/health", http.StatusOK); err == nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("expected not-ready model to fail its probe, got %d, %v", status, err)
+	}
+}
+
+func TestBackendClientHTTPProtocols(t *testing.T) {
+	client := NewBackendClient(zap.NewNop(), time.Second)
+	client.clientFor(&BackendEndpoint{ID: "h1", URI: "https://a.example.com", Protocol: config.ProtocolHTTP1})
+	client.clientFor(&BackendEndpoint{ID: "h2", URI: "http://b.example.com", Protocol: config.ProtocolHTTP2})
+
+	if h1 := client.pools["h1"].transport; h1.ForceAttemptHTTP2 || h1.TLSNextProto == nil {
+		t.Fatal("http1 backend must not negotiate HTTP/2")
+	}
+	h2 := client.pools["h2"].transport
+	if h2.Protocols == nil || !h2.Protocols.UnencryptedHTTP2() || h2.Protocols.HTTP1() {
+		t.Fatal("http2 backend must speak HTTP/2 only, including h2c")
+	}
+}
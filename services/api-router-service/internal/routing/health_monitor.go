@@ -9,6 +9,9 @@
 //   - Track health status and degradation metrics
 //   - Provide health status queries for routing decisions
 //   - Emit health status change events
+//   - Notify probe observers (e.g. per-region latency tracking for federation)
+//   - Run per-backend probes (HTTP path, expected status, latency budget,
+//     synthetic inference) on jittered intervals and keep recent results
 //
 // Requirements Reference:
 //   - specs/006-api-router-service/spec.md#US-003 (Intelligent routing and fallback)
@@ -18,12 +21,19 @@ package routing
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
 )
 
+// probeHistorySize is the number of recent probe results kept per backend.
+const probeHistorySize = 20
+
 // HealthStatus represents the health status of a backend.
 type HealthStatus string
 
@@ -46,7 +56,18 @@ type BackendHealth struct {
 	ConsecutiveErrors int
 	LastError      error
 	Latency        time.Duration
+	RecoveredAt    time.Time // Last transition from degraded/unhealthy to healthy; starts the warm-up window
 	mu             sync.RWMutex
+	history        []ProbeResult // Oldest first, at most probeHistorySize
+}
+
+// ProbeResult is one entry in a backend's probe history.
+type ProbeResult struct {
+	Time             time.Time
+	Latency          time.Duration // HTTP probe latency
+	HTTPStatus       int           // Zero when the backend could not be reached
+	InferenceLatency time.Duration // Zero unless an inference probe ran
+	Err              error
 }
 
 // HealthMonitor manages health checks for multiple backends.
@@ -60,6 +81,80 @@ type HealthMonitor struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
+	observers     []ProbeObserver
+	probes        map[string]config.HealthProbeConfig // By backend ID, "*" for the default
+	jitter        float64
+	nextProbe     map[string]time.Time
+	wake          chan struct{}
+	rand          *rand.Rand // Guarded by mu
+	warmup        time.Duration
+}
+
+// SetProbes configures per-backend probes (see config.ParseHealthProbes).
+// It must be called before Start.
+func (m *HealthMonitor) SetProbes(probes map[string]config.HealthProbeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probes = probes
+}
+
+// SetJitter spreads each backend's probes by +/- fraction of its interval so
+// that routers started together do not probe in lockstep. It must be called
+// before Start.
+func (m *HealthMonitor) SetJitter(fraction float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jitter = fraction
+}
+
+// ProbeConfig returns the probe settings used for backendID.
+func (m *HealthMonitor) ProbeConfig(backendID string) config.HealthProbeConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.probeConfigLocked(backendID)
+}
+
+func (m *HealthMonitor) probeConfigLocked(backendID string) config.HealthProbeConfig {
+	probe, ok := m.probes[backendID]
+	if !ok {
+		probe, ok = m.probes["*"]
+	}
+	if !ok {
+		probe = config.DefaultHealthProbe()
+	}
+	if probe.Interval <= 0 {
+		probe.Interval = m.checkInterval
+	}
+	return probe
+}
+
+// intervalLocked returns backendID's next probe delay with jitter applied.
+func (m *HealthMonitor) intervalLocked(backendID string) time.Duration {
+	interval := m.probeConfigLocked(backendID).Interval
+	if m.jitter > 0 {
+		interval = time.Duration(float64(interval) * (1 + m.jitter*(2*m.rand.Float64()-1)))
+	}
+	return interval
+}
+
+// ProbeObserver is notified after every health probe (err is nil on success).
+type ProbeObserver func(backendID string, latency time.Duration, err error)
+
+// OnProbe registers an observer for probe results. It must be called before Start.
+func (m *HealthMonitor) OnProbe(observer ProbeObserver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observers = append(m.observers, observer)
+}
+
+// notifyProbe calls registered observers; it must not be called with health locks held.
+func (m *HealthMonitor) notifyProbe(backendID string, latency time.Duration, err error) {
+	m.mu.RLock()
+	observers := m.observers
+	m.mu.RUnlock()
+	for _, observer := range observers {
+		observer(backendID, latency, err)
+	}
 }
 
 // NewHealthMonitor creates a new health monitor.
@@ -73,6 +168,9 @@ func NewHealthMonitor(client *BackendClient, logger *zap.Logger, checkInterval t
 		checkInterval: checkInterval,
 		ctx:           ctx,
 		cancel:        cancel,
+		nextProbe:     make(map[string]time.Time),
+		wake:          make(chan struct{}, 1),
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -93,7 +191,16 @@ func (m *HealthMonitor) RegisterBackend(backendID string, endpoint *BackendEndpo
 	if endpoint != nil {
 		m.endpoints[backendID] = endpoint
 	}
-	
+
+	// Probe new backends on the next scheduler pass
+	if _, scheduled := m.nextProbe[backendID]; !scheduled {
+		m.nextProbe[backendID] = time.Time{}
+		select {
+		case m.wake <- struct{}{}:
+		default:
+		}
+	}
+
 	m.logger.Info("registered backend for health monitoring",
 		zap.String("backend_id", backendID),
 	)
@@ -106,6 +213,10 @@ func (m *HealthMonitor) UnregisterBackend(backendID string) {
 
 	delete(m.backends, backendID)
 	delete(m.endpoints, backendID)
+	delete(m.nextProbe, backendID)
+	if m.client != nil {
+		m.client.ClosePool(backendID)
+	}
 	m.logger.Info("unregistered backend from health monitoring",
 		zap.String("backend_id", backendID),
 	)
@@ -128,55 +239,65 @@ func (m *HealthMonitor) Stop() {
 	m.wg.Wait()
 }
 
-// run performs periodic health checks.
+// run probes each backend when its jittered interval elapses.
 func (m *HealthMonitor) run() {
 	defer m.wg.Done()
 
-	ticker := time.NewTicker(m.checkInterval)
-	defer ticker.Stop()
-
-	// Perform initial check
-	m.checkAllBackends()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-m.ctx.Done():
 			return
-		case <-ticker.C:
-			m.checkAllBackends()
+		case <-timer.C:
+		case <-m.wake:
 		}
+		timer.Reset(time.Until(m.checkDueBackends(time.Now())))
 	}
 }
 
-// checkAllBackends checks health for all registered backends.
-func (m *HealthMonitor) checkAllBackends() {
-	m.mu.RLock()
-	backends := make([]string, 0, len(m.backends))
-	for backendID := range m.backends {
-		backends = append(backends, backendID)
+// checkDueBackends probes every backend whose next probe time has passed,
+// concurrently, and returns when the next probe is due.
+func (m *HealthMonitor) checkDueBackends(now time.Time) time.Time {
+	m.mu.Lock()
+	next := now.Add(m.checkInterval)
+	var due []string
+	for backendID, at := range m.nextProbe {
+		if !at.After(now) {
+			due = append(due, backendID)
+			at = now.Add(m.intervalLocked(backendID))
+			m.nextProbe[backendID] = at
+		}
+		if at.Before(next) {
+			next = at
+		}
 	}
-	m.mu.RUnlock()
-
-	for _, backendID := range backends {
-		m.checkBackend(backendID)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, backendID := range due {
+		wg.Add(1)
+		go func(backendID string) {
+			defer wg.Done()
+			m.checkBackend(backendID)
+		}(backendID)
 	}
+	wg.Wait()
+	return next
 }
 
 // checkBackend performs a health check for a specific backend.
 func (m *HealthMonitor) checkBackend(backendID string) {
 	m.mu.RLock()
 	health, exists := m.backends[backendID]
+	endpoint, hasEndpoint := m.endpoints[backendID]
 	m.mu.RUnlock()
 
 	if !exists {
 		return
 	}
 
-	// Get stored endpoint
-	m.mu.RLock()
-	endpoint, hasEndpoint := m.endpoints[backendID]
-	m.mu.RUnlock()
-
 	if !hasEndpoint || endpoint == nil {
 		m.logger.Warn("no endpoint stored for backend health check",
 			zap.String("backend_id", backendID),
@@ -184,20 +305,48 @@ func (m *HealthMonitor) checkBackend(backendID string) {
 		return
 	}
 
-	startTime := time.Now()
-	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	result := m.probe(backendID, endpoint)
+	defer m.notifyProbe(backendID, result.Latency, result.Err)
+	m.record(health, result)
+}
+
+// probe runs backendID's configured checks against endpoint.
+func (m *HealthMonitor) probe(backendID string, endpoint *BackendEndpoint) ProbeResult {
+	probe := m.ProbeConfig(backendID)
+	ctx, cancel := context.WithTimeout(m.ctx, probe.Timeout)
 	defer cancel()
 
-	err := m.client.HealthCheck(ctx, endpoint)
-	latency := time.Since(startTime)
+	result := ProbeResult{Time: time.Now()}
+	result.HTTPStatus, result.Err = m.client.ProbeHTTP(ctx, endpoint, probe.Path, probe.ExpectedStatus)
+	result.Latency = time.Since(result.Time)
+	if result.Err == nil && probe.LatencyBudget > 0 && result.Latency > probe.LatencyBudget {
+		result.Err = fmt.Errorf("health probe took %s, over its %s budget", result.Latency, probe.LatencyBudget)
+	}
+
+	if result.Err == nil && probe.InferencePrompt != "" {
+		start := time.Now()
+		result.Err = m.client.ProbeInference(ctx, endpoint, probe.InferencePrompt)
+		result.InferenceLatency = time.Since(start)
+		if result.Err == nil && probe.LatencyBudget > 0 && result.InferenceLatency > probe.LatencyBudget {
+			result.Err = fmt.Errorf("inference probe took %s, over its %s budget", result.InferenceLatency, probe.LatencyBudget)
+		}
+	}
+	return result
+}
 
+// record applies a probe result to health and appends it to the history.
+func (m *HealthMonitor) record(health *BackendHealth, result ProbeResult) {
 	health.mu.Lock()
 	defer health.mu.Unlock()
 
 	health.LastCheck = time.Now()
-	health.Latency = latency
+	health.Latency = result.Latency
+	health.history = append(health.history, result)
+	if len(health.history) > probeHistorySize {
+		health.history = health.history[len(health.history)-probeHistorySize:]
+	}
 
-	if err != nil {
+	if err := result.Err; err != nil {
 		health.ConsecutiveErrors++
 		health.LastError = err
 
@@ -207,17 +356,21 @@ func (m *HealthMonitor) checkBackend(backendID string) {
 			health.Status = HealthStatusUnhealthy
 			if oldStatus != HealthStatusUnhealthy {
 				m.logger.Warn("backend marked as unhealthy",
-					zap.String("backend_id", backendID),
+					zap.String("backend_id", health.BackendID),
 					zap.Int("consecutive_errors", health.ConsecutiveErrors),
 					zap.Error(err),
 				)
+				// Don't keep reusing connections to a backend that stopped answering
+				if m.client != nil {
+					m.client.RecyclePool(health.BackendID, PoolRecycleUnhealthy)
+				}
 			}
 		} else if health.ConsecutiveErrors >= 1 {
 			oldStatus := health.Status
 			health.Status = HealthStatusDegraded
 			if oldStatus != HealthStatusDegraded && oldStatus != HealthStatusUnhealthy {
 				m.logger.Warn("backend marked as degraded",
-					zap.String("backend_id", backendID),
+					zap.String("backend_id", health.BackendID),
 					zap.Int("consecutive_errors", health.ConsecutiveErrors),
 					zap.Error(err),
 				)
@@ -230,15 +383,33 @@ func (m *HealthMonitor) checkBackend(backendID string) {
 		health.LastError = nil
 		health.Status = HealthStatusHealthy
 
+		if oldStatus == HealthStatusDegraded || oldStatus == HealthStatusUnhealthy {
+			health.RecoveredAt = health.LastCheck
+		}
+
 		if oldStatus != HealthStatusHealthy {
 			m.logger.Info("backend recovered to healthy",
-				zap.String("backend_id", backendID),
-				zap.Duration("latency", latency),
+				zap.String("backend_id", health.BackendID),
+				zap.Duration("latency", result.Latency),
 			)
 		}
 	}
 }
 
+// ProbeHistory returns the backend's recent probe results, oldest first.
+func (m *HealthMonitor) ProbeHistory(backendID string) ([]ProbeResult, bool) {
+	m.mu.RLock()
+	health, exists := m.backends[backendID]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+
+	health.mu.RLock()
+	defer health.mu.RUnlock()
+	return append([]ProbeResult(nil), health.history...), true
+}
+
 // GetHealth returns the current health status for a backend.
 func (m *HealthMonitor) GetHealth(backendID string) (*BackendHealth, bool) {
 	m.mu.RLock()
@@ -260,6 +431,7 @@ func (m *HealthMonitor) GetHealth(backendID string) (*BackendHealth, bool) {
 		ConsecutiveErrors: health.ConsecutiveErrors,
 		LastError:         health.LastError,
 		Latency:          health.Latency,
+		RecoveredAt:       health.RecoveredAt,
 	}, true
 }
 
@@ -284,12 +456,7 @@ func (m *HealthMonitor) IsDegraded(backendID string) bool {
 // CheckBackendNow performs an immediate health check for a backend.
 // This is useful for on-demand health checks or testing.
 func (m *HealthMonitor) CheckBackendNow(backendID string, endpoint *BackendEndpoint) error {
-	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
-	defer cancel()
-
-	startTime := time.Now()
-	err := m.client.HealthCheck(ctx, endpoint)
-	latency := time.Since(startTime)
+	result := m.probe(backendID, endpoint)
 
 	m.mu.RLock()
 	health, exists := m.backends[backendID]
@@ -302,27 +469,8 @@ func (m *HealthMonitor) CheckBackendNow(backendID string, endpoint *BackendEndpo
 		health = m.backends[backendID]
 		m.mu.RUnlock()
 	}
+	defer m.notifyProbe(backendID, result.Latency, result.Err)
 
-	health.mu.Lock()
-	defer health.mu.Unlock()
-
-	health.LastCheck = time.Now()
-	health.Latency = latency
-
-	if err != nil {
-		health.ConsecutiveErrors++
-		health.LastError = err
-		if health.ConsecutiveErrors >= 3 {
-			health.Status = HealthStatusUnhealthy
-		} else if health.ConsecutiveErrors >= 1 {
-			health.Status = HealthStatusDegraded
-		}
-	} else {
-		health.ConsecutiveErrors = 0
-		health.LastError = nil
-		health.Status = HealthStatusHealthy
-	}
-
-	return err
+	m.record(health, result)
+	return result.Err
 }
-
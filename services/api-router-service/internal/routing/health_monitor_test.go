@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func TestHealthMonitorConfiguredProbes(t *testing.T) {
+	var inferenceCalls int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/ready":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1":
+			inferenceCalls++
+			_, _ = w.Write([]byte(`{"text":"pong","tokens_used":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer backend.Close()
+
+	m := NewHealthMonitor(NewBackendClient(zap.NewNop(), time.Second), zap.NewNop(), time.Minute)
+	m.SetProbes(map[string]config.HealthProbeConfig{
+		"gpu-a": {Path: "/ready", ExpectedStatus: http.StatusNoContent, Timeout: time.Second, InferencePrompt: "ping"},
+		"*":     config.DefaultHealthProbe(),
+	})
+	endpoint := &BackendEndpoint{ID: "gpu-a", URI: backend.URL + "/v1"}
+
+	if err := m.CheckBackendNow("gpu-a", endpoint); err != nil {
+		t.Fatalf("CheckBackendNow(gpu-a): %v", err)
+	}
+	if inferenceCalls != 1 {
+		t.Fatalf("inference probe calls = %d, want 1", inferenceCalls)
+	}
+
+	// The default probe expects 200 from /health, which this backend does not serve
+	if err := m.CheckBackendNow("gpu-b", &BackendEndpoint{ID: "gpu-b", URI: backend.URL + "/v1"}); err == nil {
+		t.Fatal("expected default probe to fail")
+	}
+
+	history, ok := m.ProbeHistory("gpu-b")
+	if !ok || len(history) != 1 {
+		t.Fatalf("ProbeHistory(gpu-b) = %v, %v", history, ok)
+	}
+	if history[0].HTTPStatus != http.StatusNotFound || history[0].Err == nil {
+		t.Fatalf("unexpected probe result %+v", history[0])
+	}
+	if got := m.ProbeConfig("gpu-b").Interval; got != time.Minute {
+		t.Fatalf("default interval = %s, want the monitor's check interval", got)
+	}
+}
+
+func TestHealthMonitorLatencyBudget(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer backend.Close()
+
+	m := NewHealthMonitor(NewBackendClient(zap.NewNop(), time.Second), zap.NewNop(), time.Minute)
+	probe := config.DefaultHealthProbe()
+	probe.LatencyBudget = time.Millisecond
+	m.SetProbes(map[string]config.HealthProbeConfig{"slow": probe})
+
+	endpoint := &BackendEndpoint{ID: "slow", URI: backend.URL}
+	for i := 0; i < probeHistorySize+5; i++ {
+		_ = m.CheckBackendNow("slow", endpoint)
+	}
+
+	health, _ := m.GetHealth("slow")
+	if health.Status != HealthStatusUnhealthy {
+		t.Fatalf("status = %s, want unhealthy after repeated over-budget probes", health.Status)
+	}
+	if history, _ := m.ProbeHistory("slow"); len(history) != probeHistorySize {
+		t.Fatalf("history length = %d, want %d", len(history), probeHistorySize)
+	}
+}
+
+func TestHealthMonitorJitter(t *testing.T) {
+	m := NewHealthMonitor(nil, zap.NewNop(), 10*time.Second)
+	m.SetJitter(0.2)
+	for i := 0; i < 100; i++ {
+		if got := m.intervalLocked("any"); got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("jittered interval %s outside 8s-12s", got)
+		}
+	}
+}
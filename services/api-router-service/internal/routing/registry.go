@@ -22,13 +22,25 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/redis/go-redis/v9"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"go.uber.org/zap"
 )
 
+// Default pool settings used when RegistryConfig leaves them unset.
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 5
+	defaultConnMaxLifetime = 5 * time.Minute
+)
+
 // ModelRegistryEntry represents a model deployment in the registry.
 type ModelRegistryEntry struct {
 	ModelID               string
@@ -48,6 +60,8 @@ type Registry struct {
 	logger      *zap.Logger
 	cacheTTL    time.Duration
 	environment string
+	// queryTimeout bounds each registry query (zero disables).
+	queryTimeout time.Duration
 }
 
 // RegistryConfig configures the model registry.
@@ -58,6 +72,16 @@ type RegistryConfig struct {
 	RedisDB     int
 	CacheTTL    time.Duration
 	Environment string
+
+	// Connection pool tuning; zero values use the defaults above.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is sent as the statement_timeout runtime parameter.
+	StatementTimeout time.Duration
+	// QueryTimeout bounds each registry query client-side.
+	QueryTimeout time.Duration
 }
 
 // NewRegistry creates a new model registry with database and Redis connections.
@@ -67,7 +91,7 @@ func NewRegistry(cfg *RegistryConfig, logger *zap.Logger) (*Registry, error) {
 	}
 
 	// Connect to PostgreSQL
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	db, err := sql.Open("postgres", withStatementTimeout(cfg.DatabaseURL, cfg.StatementTimeout))
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -81,9 +105,19 @@ func NewRegistry(cfg *RegistryConfig, logger *zap.Logger) (*Registry, error) {
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(orDefault(cfg.MaxOpenConns, defaultMaxOpenConns))
+	db.SetMaxIdleConns(orDefault(cfg.MaxIdleConns, defaultMaxIdleConns))
+	db.SetConnMaxLifetime(orDefault(cfg.ConnMaxLifetime, defaultConnMaxLifetime))
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
+	// Export pool saturation (in use, idle, wait count/duration) as go_sql_* metrics
+	if err := prometheus.Register(collectors.NewDBStatsCollector(db, "model_registry")); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			logger.Warn("failed to register registry pool metrics", zap.Error(err))
+		}
+	}
 
 	// Connect to Redis (optional - if not configured, caching is disabled)
 	var redisClient *redis.Client
@@ -123,14 +157,50 @@ func NewRegistry(cfg *RegistryConfig, logger *zap.Logger) (*Registry, error) {
 	)
 
 	return &Registry{
-		db:          db,
-		redis:       redisClient,
-		logger:      logger,
-		cacheTTL:    cacheTTL,
-		environment: environment,
+		db:           db,
+		redis:        redisClient,
+		logger:       logger,
+		cacheTTL:     cacheTTL,
+		environment:  environment,
+		queryTimeout: cfg.QueryTimeout,
 	}, nil
 }
 
+// queryContext applies the configured per-query timeout to ctx.
+func (r *Registry) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+// withStatementTimeout adds statement_timeout to a URL or key/value DSN.
+// lib/pq forwards unrecognised parameters to the server as runtime settings.
+func withStatementTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || dsn == "" {
+		return dsn
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		q := u.Query()
+		q.Set("statement_timeout", ms)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " statement_timeout=" + ms
+}
+
+func orDefault[T int | time.Duration](v, def T) T {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
 // Close closes database and Redis connections.
 func (r *Registry) Close() error {
 	if r.redis != nil {
@@ -205,6 +275,9 @@ func (r *Registry) queryDatabase(ctx context.Context, modelName, environment str
 	var entry ModelRegistryEntry
 	var lastHealthCheck sql.NullTime
 
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
 	err := r.db.QueryRowContext(ctx, query, modelName, environment).Scan(
 		&entry.ModelID,
 		&entry.ModelName,
@@ -326,6 +399,9 @@ func (r *Registry) ListReadyModelsInEnvironment(ctx context.Context, environment
 		ORDER BY model_name
 	`
 
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
 	rows, err := r.db.QueryContext(ctx, query, environment)
 	if err != nil {
 		return nil, fmt.Errorf("query ready models: %w", err)
@@ -0,0 +1,67 @@
+// Package routing provides slow-start warm-up for recovered backends.
+//
+// Purpose:
+//   A backend that comes back from degraded or unhealthy often has cold
+//   caches; sending it its full share at once can trip it again. During the
+//   warm-up window its routing weight ramps linearly from a small floor to
+//   the configured weight.
+//
+// Debugging Notes:
+//   - Only recoveries start a warm-up; backends healthy at startup get full weight
+//   - A warming backend that is the only one available still receives all traffic
+//   - GET /v1/admin/routing/backends shows warming_up and warmup_factor
+//
+package routing
+
+import (
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+// minWarmupFactor is the share of its weight a backend gets right after recovering.
+const minWarmupFactor = 0.1
+
+// SetWarmup sets the slow-start window for recovered backends; zero disables it.
+// It must be called before Start.
+func (m *HealthMonitor) SetWarmup(window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.warmup = window
+}
+
+// WarmupFactor returns the fraction of its weight the backend should receive,
+// in [minWarmupFactor, 1]. It is 1 unless the backend is warming up.
+func (m *HealthMonitor) WarmupFactor(backendID string) float64 {
+	m.mu.RLock()
+	window := m.warmup
+	m.mu.RUnlock()
+	if window <= 0 {
+		return 1
+	}
+
+	health, ok := m.GetHealth(backendID)
+	if !ok || health.Status != HealthStatusHealthy || health.RecoveredAt.IsZero() {
+		return 1
+	}
+	elapsed := time.Since(health.RecoveredAt)
+	if elapsed >= window {
+		return 1
+	}
+	return minWarmupFactor + (1-minWarmupFactor)*float64(elapsed)/float64(window)
+}
+
+// applyWarmup returns a copy of backends with warming backends' weights scaled down.
+func (e *Engine) applyWarmup(backends []config.BackendWeight) []config.BackendWeight {
+	weighted := make([]config.BackendWeight, len(backends))
+	copy(weighted, backends)
+	if e.healthMonitor == nil {
+		return weighted
+	}
+	for i := range weighted {
+		if factor := e.healthMonitor.WarmupFactor(weighted[i].BackendID); factor < 1 && weighted[i].Weight > 0 {
+			weighted[i].Weight = max(1, int(float64(weighted[i].Weight)*factor))
+		}
+	}
+	return weighted
+}
@@ -0,0 +1,58 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+)
+
+func TestWarmupAfterRecovery(t *testing.T) {
+	var healthy atomic.Bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer backend.Close()
+
+	m := NewHealthMonitor(NewBackendClient(zap.NewNop(), time.Second), zap.NewNop(), time.Minute)
+	m.SetWarmup(time.Hour)
+	endpoint := &BackendEndpoint{ID: "a", URI: backend.URL}
+
+	// Healthy from the first probe: no warm-up
+	healthy.Store(true)
+	_ = m.CheckBackendNow("a", endpoint)
+	if got := m.WarmupFactor("a"); got != 1 {
+		t.Fatalf("factor before any failure = %g, want 1", got)
+	}
+
+	healthy.Store(false)
+	_ = m.CheckBackendNow("a", endpoint)
+	healthy.Store(true)
+	_ = m.CheckBackendNow("a", endpoint)
+	factor := m.WarmupFactor("a")
+	if factor < minWarmupFactor || factor >= 0.2 {
+		t.Fatalf("factor right after recovery = %g, want close to %g", factor, minWarmupFactor)
+	}
+
+	engine := NewEngine(m, nil, zap.NewNop())
+	policy := &config.RoutingPolicy{Backends: []config.BackendWeight{{BackendID: "a", Weight: 50}, {BackendID: "b", Weight: 50}}}
+	available := engine.getAvailableBackends(policy)
+	if available[0].Weight != 5 {
+		t.Fatalf("warming backend weight = %d, want 5", available[0].Weight)
+	}
+	if policy.Backends[0].Weight != 50 {
+		t.Fatal("policy weights must not be modified")
+	}
+
+	m.SetWarmup(0)
+	if got := m.WarmupFactor("a"); got != 1 {
+		t.Fatalf("factor with warm-up disabled = %g, want 1", got)
+	}
+}
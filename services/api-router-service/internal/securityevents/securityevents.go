@@ -0,0 +1,177 @@
+// Package securityevents publishes the router's security events.
+//
+// Purpose:
+//   Detections that need a human to look at them, such as an API key
+//   throttled for suspected abuse or a backend URI rejected by the egress
+//   allowlist, are published in the schema user-org-service uses on the
+//   security events topic, so SIEM consumers handle both services alike.
+//
+// Debugging Notes:
+//   - Events are keyed by org; router-wide events use the nil org ID
+//   - Without KAFKA_BROKERS events are only logged (LogPublisher)
+//
+package securityevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// Event types.
+const (
+	TypeAPIKeyAbuseThrottled = "api_key.abuse_throttled"
+	TypeBackendEgressDenied  = "backend.egress_denied"
+)
+
+// Severities, matching user-org-service's.
+const (
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Event is one security event, in user-org-service's schema plus Source.
+type Event struct {
+	EventID     uuid.UUID      `json:"eventId"`
+	Type        string         `json:"type"`
+	Severity    string         `json:"severity"`
+	OrgID       uuid.UUID      `json:"orgId"`
+	SubjectType string         `json:"subjectType"`
+	SubjectID   string         `json:"subjectId"`
+	Summary     string         `json:"summary"`
+	Details     map[string]any `json:"details,omitempty"`
+	OccurredAt  time.Time      `json:"occurredAt"`
+	// Source names the service that detected the event.
+	Source string `json:"source"`
+}
+
+// Egress violation sources.
+const (
+	SourceBackendEndpoints  = "BACKEND_ENDPOINTS"
+	SourceFederatedBackends = "FEDERATED_BACKENDS"
+	SourceAdminAPI          = "admin_api"
+)
+
+// EgressDenied describes a backend URI rejected by the egress allowlist.
+// source is where the URI came from; actor is the caller's API key ID for
+// admin API registrations.
+func EgressDenied(backendID, uri, reason, source, actor string) Event {
+	details := map[string]any{
+		"uri":    uri,
+		"reason": reason,
+		"source": source,
+	}
+	if actor != "" {
+		details["actor_api_key_id"] = actor
+	}
+	return Event{
+		EventID:     uuid.New(),
+		Type:        TypeBackendEgressDenied,
+		Severity:    SeverityHigh,
+		SubjectType: "backend",
+		SubjectID:   backendID,
+		Summary:     fmt.Sprintf("backend %s registration via %s rejected: %s", backendID, source, reason),
+		Details:     details,
+		OccurredAt:  time.Now().UTC(),
+	}
+}
+
+// Publisher delivers security events.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// KafkaPublisherConfig configures a KafkaPublisher.
+type KafkaPublisherConfig struct {
+	Brokers  []string
+	Topic    string
+	ClientID string
+	// Credentials, when set, authenticates with SASL/PLAIN.
+	Credentials func() (username, password string)
+}
+
+// KafkaPublisher produces security events as JSON, keyed by org.
+type KafkaPublisher struct {
+	writer   *kafka.Writer
+	clientID string
+}
+
+// NewKafkaPublisher returns a publisher writing to cfg.Topic.
+func NewKafkaPublisher(cfg KafkaPublisherConfig) *KafkaPublisher {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: 5 * time.Second,
+	}
+	var mechanism sasl.Mechanism
+	if cfg.Credentials != nil {
+		if username, password := cfg.Credentials(); username != "" {
+			mechanism = plain.Mechanism{Username: username, Password: password}
+		}
+	}
+	writer.Transport = &kafka.Transport{ClientID: cfg.ClientID, SASL: mechanism}
+	return &KafkaPublisher{writer: writer, clientID: cfg.ClientID}
+}
+
+// Publish writes one event synchronously.
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	if event.Source == "" {
+		event.Source = p.clientID
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal security event: %w", err)
+	}
+	headers := []kafka.Header{
+		{Key: "event_id", Value: []byte(event.EventID.String())},
+		{Key: "type", Value: []byte(event.Type)},
+		{Key: "severity", Value: []byte(event.Severity)},
+	}
+	for key, value := range requestid.Headers(ctx) {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.OrgID.String()),
+		Value:   payload,
+		Headers: headers,
+		Time:    event.OccurredAt,
+	})
+}
+
+// Close flushes and closes the writer.
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// LogPublisher logs events when no broker is configured.
+type LogPublisher struct {
+	Logger *zap.Logger
+}
+
+// Publish logs the event at warn level.
+func (p *LogPublisher) Publish(_ context.Context, event Event) error {
+	p.Logger.Warn("security event",
+		zap.String("event_id", event.EventID.String()),
+		zap.String("type", event.Type),
+		zap.String("severity", event.Severity),
+		zap.String("org_id", event.OrgID.String()),
+		zap.String("subject_type", event.SubjectType),
+		zap.String("subject_id", event.SubjectID),
+		zap.String("summary", event.Summary),
+		zap.Any("details", event.Details),
+	)
+	return nil
+}
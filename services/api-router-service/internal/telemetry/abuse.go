@@ -0,0 +1,41 @@
+// Package telemetry provides Prometheus metrics for abuse detection.
+//
+// Purpose:
+//   This file counts keys flagged for anomalous traffic and the requests
+//   turned away by their stricter limit, so spikes in either stand out.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// AbuseFlagsTotal tracks API keys flagged for suspected abuse.
+	AbuseFlagsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_abuse_flags_total",
+			Help: "Total number of API keys throttled for suspected abuse by reason",
+		},
+		[]string{"reason"}, // "request_spike" or "error_rate"
+	)
+
+	// AbuseThrottledRequestsTotal tracks requests denied by the abuse throttle.
+	AbuseThrottledRequestsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "api_router_abuse_throttled_requests_total",
+			Help: "Total number of requests denied because their API key is throttled for suspected abuse",
+		},
+	)
+)
+
+// RecordAbuseFlag records a key flagged for reason.
+func RecordAbuseFlag(reason string) {
+	AbuseFlagsTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordAbuseThrottled records a request denied by the abuse throttle.
+func RecordAbuseThrottled() {
+	AbuseThrottledRequestsTotal.Inc()
+}
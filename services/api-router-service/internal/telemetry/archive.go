@@ -0,0 +1,41 @@
+// Package telemetry provides Prometheus metrics for inference archival.
+//
+// Purpose:
+//   This file tracks archive writes by outcome and records purged after
+//   their retention, so operators can spot storage failures and queue drops.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ArchiveWritesTotal tracks archive writes by outcome (stored, failed, dropped).
+	ArchiveWritesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_archive_writes_total",
+			Help: "Total number of inference archive writes by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	// ArchivePurgedTotal tracks archive records deleted after their retention.
+	ArchivePurgedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "api_router_archive_purged_total",
+			Help: "Total number of expired inference archive records deleted",
+		},
+	)
+)
+
+// RecordArchiveWrite records an archive write outcome.
+func RecordArchiveWrite(outcome string) {
+	ArchiveWritesTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordArchivePurged records expired archive records deleted by a purge.
+func RecordArchivePurged(count int) {
+	ArchivePurgedTotal.Add(float64(count))
+}
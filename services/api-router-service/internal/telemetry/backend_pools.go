@@ -0,0 +1,79 @@
+// Package telemetry provides Prometheus metrics for backend connection pools.
+//
+// Purpose:
+//   This file exposes per-backend open connections, connection reuse and
+//   recycles, so pool exhaustion and DNS churn show up before latency does.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// BackendOpenConnections tracks open connections per backend.
+	BackendOpenConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "api_router_backend_open_connections",
+			Help: "Number of open connections to each backend",
+		},
+		[]string{"backend_id"},
+	)
+
+	// BackendConnectionsAcquiredTotal tracks connections taken from or added to a backend's pool.
+	BackendConnectionsAcquiredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_backend_connections_acquired_total",
+			Help: "Total number of connections acquired for backend requests by whether an idle connection was reused",
+		},
+		[]string{"backend_id", "reused"}, // reused: "true" or "false"
+	)
+
+	// BackendDialErrorsTotal tracks failed connection attempts per backend.
+	BackendDialErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_backend_dial_errors_total",
+			Help: "Total number of failed connection attempts to each backend",
+		},
+		[]string{"backend_id"},
+	)
+
+	// BackendPoolRecyclesTotal tracks backend pools replaced with fresh connections.
+	BackendPoolRecyclesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_backend_pool_recycles_total",
+			Help: "Total number of times a backend's connection pool was recycled by reason",
+		},
+		[]string{"backend_id", "reason"}, // reason: "dns_change", "unhealthy", "uri_change"
+	)
+)
+
+// RecordBackendConnOpened records a new connection to a backend.
+func RecordBackendConnOpened(backendID string) {
+	BackendOpenConnections.WithLabelValues(backendID).Inc()
+}
+
+// RecordBackendConnClosed records a closed connection to a backend.
+func RecordBackendConnClosed(backendID string) {
+	BackendOpenConnections.WithLabelValues(backendID).Dec()
+}
+
+// RecordBackendConnAcquired records a connection acquired for a backend request.
+func RecordBackendConnAcquired(backendID string, reused bool) {
+	label := "false"
+	if reused {
+		label = "true"
+	}
+	BackendConnectionsAcquiredTotal.WithLabelValues(backendID, label).Inc()
+}
+
+// RecordBackendDialError records a failed connection attempt to a backend.
+func RecordBackendDialError(backendID string) {
+	BackendDialErrorsTotal.WithLabelValues(backendID).Inc()
+}
+
+// RecordBackendPoolRecycle records a recycled backend pool.
+func RecordBackendPoolRecycle(backendID, reason string) {
+	BackendPoolRecyclesTotal.WithLabelValues(backendID, reason).Inc()
+}
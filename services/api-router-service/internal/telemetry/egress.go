@@ -0,0 +1,26 @@
+// Package telemetry provides Prometheus metrics for the egress allowlist.
+//
+// Purpose:
+//   This file counts backend URIs rejected by the egress allowlist, so an
+//   attempt to register a backend pointing at internal addresses stands out.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// EgressViolationsTotal tracks backend URIs rejected by the egress allowlist.
+var EgressViolationsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "api_router_egress_violations_total",
+		Help: "Total number of backend URIs rejected by the egress allowlist by source",
+	},
+	[]string{"source"}, // "BACKEND_ENDPOINTS", "FEDERATED_BACKENDS" or "admin_api"
+)
+
+// RecordEgressViolation records a backend URI rejected from source.
+func RecordEgressViolation(source string) {
+	EgressViolationsTotal.WithLabelValues(source).Inc()
+}
@@ -0,0 +1,44 @@
+// Package telemetry provides Prometheus metrics for backend federation.
+//
+// Purpose:
+//   This file exposes per-region probe latency and cross-region failovers so
+//   operators can see how a router fronting several clusters is spreading load.
+//
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// RegionProbeLatency tracks the smoothed health probe latency per backend region.
+	RegionProbeLatency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "api_router_region_probe_latency_seconds",
+			Help: "Smoothed health probe latency to backends in each region",
+		},
+		[]string{"region"},
+	)
+
+	// CrossRegionFailoversTotal tracks requests served outside the router's region after failover.
+	CrossRegionFailoversTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_cross_region_failovers_total",
+			Help: "Total number of requests failed over to a backend in another region",
+		},
+		[]string{"from_region", "to_region"},
+	)
+)
+
+// RecordRegionProbeLatency records the smoothed probe latency for a region.
+func RecordRegionProbeLatency(region string, latency time.Duration) {
+	RegionProbeLatency.WithLabelValues(region).Set(latency.Seconds())
+}
+
+// RecordCrossRegionFailover records a request failed over to another region.
+func RecordCrossRegionFailover(fromRegion, toRegion string) {
+	CrossRegionFailoversTotal.WithLabelValues(fromRegion, toRegion).Inc()
+}
@@ -8,8 +8,9 @@
 //   - github.com/prometheus/client_golang: Prometheus metrics
 //
 // Key Responsibilities:
-//   - Track rate limit denials
+//   - Track rate limit decisions per org tier, denials and soft limit warnings
 //   - Track budget/quota denials
+//   - Track API key network restriction denials
 //   - Provide metrics for observability
 //
 // Requirements Reference:
@@ -22,13 +23,55 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// Rate limit decisions recorded by RecordRateLimitDecision.
+const (
+	RateLimitAllowed   = "allowed"
+	RateLimitThrottled = "throttled"
+	RateLimitDisabled  = "disabled" // The check failed and the request was let through
+)
+
 var (
+	// RateLimitDecisionsTotal tracks rate limit decisions by org tier.
+	RateLimitDecisionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_rate_limit_decisions_total",
+			Help: "Total number of rate limit decisions by outcome and org tier",
+		},
+		[]string{"decision", "tier"}, // decision: "allowed", "throttled" or "disabled"
+	)
+
 	// RateLimitDenialsTotal tracks total rate limit denials.
 	RateLimitDenialsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "api_router_rate_limit_denials_total",
 			Help: "Total number of rate limit denials",
 		},
+		[]string{"limit_type"}, // "org", "key", "org_concurrency", "key_concurrency", "org_streams" or "key_streams"
+	)
+
+	// RateLimitWarningsTotal tracks responses that carried a soft rate limit warning.
+	RateLimitWarningsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_rate_limit_warnings_total",
+			Help: "Total number of responses warned that the rate limit is nearly exhausted",
+		},
+		[]string{"limit_type"}, // "org" or "key"
+	)
+
+	// RateLimitFallbackActive is 1 while rate limits are enforced by local buckets.
+	RateLimitFallbackActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "api_router_rate_limit_fallback_active",
+			Help: "Whether rate limits are enforced by local token buckets because Redis is unavailable",
+		},
+	)
+
+	// RateLimitFallbackChecksTotal tracks rate limit checks served by local buckets.
+	RateLimitFallbackChecksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_rate_limit_fallback_checks_total",
+			Help: "Total number of rate limit checks served by local token buckets",
+		},
 		[]string{"limit_type"}, // "org" or "key"
 	)
 
@@ -49,13 +92,50 @@ var (
 		},
 		[]string{"quota_type"}, // "daily_quota", "monthly_quota"
 	)
+
+	// RestrictionDenialsTotal tracks requests denied by API key network restrictions.
+	RestrictionDenialsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_restriction_denials_total",
+			Help: "Total number of requests denied by API key network restrictions",
+		},
+		[]string{"reason"}, // "IP_NOT_ALLOWED", "HEADER_MISMATCH"
+	)
 )
 
+// RecordRateLimitDecision records a rate limit decision; an empty tier is
+// recorded as "default".
+func RecordRateLimitDecision(decision, tier string) {
+	if tier == "" {
+		tier = "default"
+	}
+	RateLimitDecisionsTotal.WithLabelValues(decision, tier).Inc()
+}
+
 // RecordRateLimitDenial records a rate limit denial metric.
 func RecordRateLimitDenial(limitType string) {
 	RateLimitDenialsTotal.WithLabelValues(limitType).Inc()
 }
 
+// RecordRateLimitWarning records a soft rate limit warning metric.
+func RecordRateLimitWarning(limitType string) {
+	RateLimitWarningsTotal.WithLabelValues(limitType).Inc()
+}
+
+// SetRateLimitFallbackActive records whether the local rate limit fallback is in use.
+func SetRateLimitFallbackActive(active bool) {
+	if active {
+		RateLimitFallbackActive.Set(1)
+		return
+	}
+	RateLimitFallbackActive.Set(0)
+}
+
+// RecordRateLimitFallbackCheck records a rate limit check served by local buckets.
+func RecordRateLimitFallbackCheck(limitType string) {
+	RateLimitFallbackChecksTotal.WithLabelValues(limitType).Inc()
+}
+
 // RecordBudgetDenial records a budget denial metric.
 func RecordBudgetDenial(quotaType string) {
 	BudgetDenialsTotal.WithLabelValues(quotaType).Inc()
@@ -66,3 +146,7 @@ func RecordQuotaDenial(quotaType string) {
 	QuotaDenialsTotal.WithLabelValues(quotaType).Inc()
 }
 
+// RecordRestrictionDenial records an API key network restriction denial metric.
+func RecordRestrictionDenial(reason string) {
+	RestrictionDenialsTotal.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,30 @@
+// Package telemetry provides Prometheus metrics for per-org log export.
+//
+// Purpose:
+//   This file tracks exported log entries by outcome so operators can see
+//   customer destinations failing, queue drops and entries removed by org
+//   filters.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// LogExportEntriesTotal tracks log export entries by stream and outcome
+	// (exported, failed, dropped, filtered).
+	LogExportEntriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_log_export_entries_total",
+			Help: "Total number of org log entries handled by the log exporter by stream and outcome",
+		},
+		[]string{"stream", "outcome"},
+	)
+)
+
+// RecordLogExport records count log export entries with an outcome.
+func RecordLogExport(stream, outcome string, count int) {
+	LogExportEntriesTotal.WithLabelValues(stream, outcome).Add(float64(count))
+}
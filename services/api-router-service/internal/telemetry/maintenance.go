@@ -0,0 +1,44 @@
+// Package telemetry provides Prometheus metrics for maintenance mode.
+//
+// Purpose:
+//   This file exposes whether the router is in maintenance and how many
+//   requests were turned away, so dashboards can line up 503s with windows.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// MaintenanceActive is 1 while maintenance is in effect.
+	MaintenanceActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "api_router_maintenance_active",
+			Help: "Whether platform maintenance mode is currently in effect (1) or not (0)",
+		},
+	)
+
+	// MaintenanceRejectionsTotal tracks requests rejected during maintenance.
+	MaintenanceRejectionsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "api_router_maintenance_rejections_total",
+			Help: "Total number of requests rejected with 503 during maintenance",
+		},
+	)
+)
+
+// SetMaintenanceActive records whether maintenance is in effect.
+func SetMaintenanceActive(active bool) {
+	if active {
+		MaintenanceActive.Set(1)
+		return
+	}
+	MaintenanceActive.Set(0)
+}
+
+// RecordMaintenanceRejection records a request rejected during maintenance.
+func RecordMaintenanceRejection() {
+	MaintenanceRejectionsTotal.Inc()
+}
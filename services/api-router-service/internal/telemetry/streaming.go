@@ -0,0 +1,80 @@
+// Package telemetry provides Prometheus metrics for streamed completions.
+//
+// Purpose:
+//   This file tracks time to first token and token throughput per backend for
+//   stream=true requests, and how streams end, so slow-starting backends and
+//   clients that hang up mid-stream are visible, plus stream slots reclaimed
+//   from routers that stopped heartbeating.
+//
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Stream outcomes.
+const (
+	StreamOutcomeCompleted          = "completed"
+	StreamOutcomeClientDisconnected = "client_disconnected"
+	StreamOutcomeBackendError       = "backend_error"
+)
+
+var (
+	// StreamTimeToFirstToken tracks the delay from forwarding a streamed request to its first token.
+	StreamTimeToFirstToken = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "api_router_stream_time_to_first_token_seconds",
+			Help:    "Time from forwarding a streamed request to the backend until its first token",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.0, 5.0, 10.0, 30.0},
+		},
+		[]string{"backend_id"},
+	)
+
+	// StreamTokensPerSecond tracks output token throughput after the first token.
+	StreamTokensPerSecond = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "api_router_stream_tokens_per_second",
+			Help:    "Output tokens per second of streamed responses, measured from the first token",
+			Buckets: []float64{1, 5, 10, 20, 40, 80, 160, 320},
+		},
+		[]string{"backend_id"},
+	)
+
+	// StreamsTotal tracks streamed responses by how they ended.
+	StreamsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_streams_total",
+			Help: "Total number of streamed responses by backend and outcome",
+		},
+		[]string{"backend_id", "outcome"}, // outcome: "completed", "client_disconnected", "backend_error"
+	)
+
+	// StreamSlotsReclaimedTotal tracks stream slots freed after missed heartbeats.
+	StreamSlotsReclaimedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_stream_slots_reclaimed_total",
+			Help: "Total number of concurrent stream slots reclaimed after their heartbeats stopped",
+		},
+		[]string{"scope"}, // scope: "org", "key"
+	)
+)
+
+// RecordStream records a finished stream. ttft is zero when no token arrived;
+// tokens/sec is only recorded for streams with at least two tokens.
+func RecordStream(backendID, outcome string, ttft, generation time.Duration, tokens int) {
+	StreamsTotal.WithLabelValues(backendID, outcome).Inc()
+	if ttft > 0 {
+		StreamTimeToFirstToken.WithLabelValues(backendID).Observe(ttft.Seconds())
+	}
+	if tokens > 1 && generation > 0 {
+		StreamTokensPerSecond.WithLabelValues(backendID).Observe(float64(tokens-1) / generation.Seconds())
+	}
+}
+
+// RecordStreamSlotsReclaimed records stream slots reclaimed from dead or stalled routers.
+func RecordStreamSlotsReclaimed(scope string, count int) {
+	StreamSlotsReclaimedTotal.WithLabelValues(scope).Add(float64(count))
+}
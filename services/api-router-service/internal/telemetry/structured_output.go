@@ -0,0 +1,44 @@
+// Package telemetry provides Prometheus metrics for structured output enforcement.
+//
+// Purpose:
+//   This file tracks how often backend output matches a requested JSON schema,
+//   how often repair prompts fix it, and schema violations per model, so
+//   operators can tell which models are unreliable at structured output.
+//
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// StructuredOutputTotal tracks structured output requests by model and
+	// outcome (valid, repaired, failed).
+	StructuredOutputTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_structured_output_total",
+			Help: "Total number of structured output requests by model and outcome",
+		},
+		[]string{"model", "outcome"},
+	)
+
+	// SchemaViolationsTotal tracks backend responses that violated the requested schema.
+	SchemaViolationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_router_schema_violations_total",
+			Help: "Total number of backend responses that violated the requested JSON schema",
+		},
+		[]string{"model"},
+	)
+)
+
+// RecordStructuredOutput records the final outcome of a structured output request.
+func RecordStructuredOutput(model, outcome string) {
+	StructuredOutputTotal.WithLabelValues(model, outcome).Inc()
+}
+
+// RecordSchemaViolation records one backend response that violated its schema.
+func RecordSchemaViolation(model string) {
+	SchemaViolationsTotal.WithLabelValues(model).Inc()
+}
@@ -7,7 +7,7 @@
 //   - Kafka (optional, falls back to logger)
 //
 // Key Responsibilities:
-//   - Emit audit events for budget/rate limit denials
+//   - Emit audit events for budget/rate limit and network restriction denials
 //   - Include request context (org, key, model, tokens)
 //   - Structured event format
 //
@@ -20,11 +20,14 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/logexport"
 )
 
 // AuditLogger emits audit events for request denials and usage.
 type AuditLogger struct {
-	logger *zap.Logger
+	logger   *zap.Logger
+	exporter *logexport.Exporter
 	// TODO: Add Kafka producer when available
 }
 
@@ -38,6 +41,11 @@ func NewAuditLogger(logger *zap.Logger) *AuditLogger {
 	}
 }
 
+// SetExporter forwards denials to orgs that export their audit logs.
+func (a *AuditLogger) SetExporter(exporter *logexport.Exporter) {
+	a.exporter = exporter
+}
+
 // AuditEvent represents an audit event.
 type AuditEvent struct {
 	RequestID      string
@@ -45,8 +53,9 @@ type AuditEvent struct {
 	APIKeyID       string
 	Model          string
 	Action         string // "REQUEST_DENIED", "REQUEST_ALLOWED"
-	DecisionReason string // "BUDGET_EXCEEDED", "RATE_LIMIT_EXCEEDED", "QUOTA_EXCEEDED"
+	DecisionReason string // "BUDGET_EXCEEDED", "RATE_LIMIT_EXCEEDED", "QUOTA_EXCEEDED", "IP_NOT_ALLOWED", "HEADER_MISMATCH", "ABUSE_THROTTLED"
 	LimitState     string
+	ClientIP       string // Set for network restriction denials
 	Timestamp      time.Time
 }
 
@@ -61,8 +70,26 @@ func (a *AuditLogger) LogDenial(event AuditEvent) {
 		zap.String("action", event.Action),
 		zap.String("decision_reason", event.DecisionReason),
 		zap.String("limit_state", event.LimitState),
+		zap.String("client_ip", event.ClientIP),
 		zap.Time("timestamp", event.Timestamp),
 	)
+
+	if a.exporter != nil {
+		a.exporter.Export(logexport.Entry{
+			Stream: logexport.StreamAudit,
+			Time:   event.Timestamp,
+			OrgID:  event.OrganizationID,
+			Fields: map[string]any{
+				"request_id":      event.RequestID,
+				"api_key_id":      event.APIKeyID,
+				"model":           event.Model,
+				"action":          event.Action,
+				"decision_reason": event.DecisionReason,
+				"limit_state":     event.LimitState,
+				"client_ip":       event.ClientIP,
+			},
+		})
+	}
 	
 	// TODO: Emit to Kafka when available
 }
@@ -0,0 +1,103 @@
+// Package usage provides usage record payload handling.
+//
+// Purpose:
+//   Orgs choose whether the prompt and response of a request are carried in
+//   its usage record, and so reach analytics: in full, truncated, as a hash,
+//   or not at all. RecordBuilder applies the org's PayloadPolicy before the
+//   record is published or buffered, so nothing beyond the policy ever
+//   leaves the router.
+//
+// Debugging Notes:
+//   - Orgs without a policy get PayloadOmitted (records carry no payloads)
+//   - Non-string payloads (chat messages, choices) are JSON-encoded first
+//   - Hashes are "sha256:<hex>" of the full encoded payload, so equal prompts
+//     can be correlated without storing them
+//
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// Payload modes, matching the user-org service's usage_payloads setting.
+const (
+	PayloadFull      = "full"
+	PayloadTruncated = "truncated"
+	PayloadHashOnly  = "hash_only"
+	PayloadOmitted   = "omitted"
+)
+
+// DefaultPayloadMaxBytes is the truncation length when a policy sets none.
+const DefaultPayloadMaxBytes = 1024
+
+// PayloadPolicy is an org's usage record payload setting.
+type PayloadPolicy struct {
+	Mode     string
+	MaxBytes int // Truncation length for PayloadTruncated
+}
+
+// mode returns the policy's mode, treating nil and unknown modes as omitted.
+func (p *PayloadPolicy) mode() string {
+	if p == nil {
+		return PayloadOmitted
+	}
+	switch p.Mode {
+	case PayloadFull, PayloadTruncated, PayloadHashOnly:
+		return p.Mode
+	}
+	return PayloadOmitted
+}
+
+// apply returns payload as the policy allows it to be recorded.
+func (p *PayloadPolicy) apply(payload interface{}) string {
+	text := encodePayload(payload)
+	if text == "" {
+		return ""
+	}
+	switch p.mode() {
+	case PayloadFull:
+		return text
+	case PayloadTruncated:
+		maxBytes := p.MaxBytes
+		if maxBytes <= 0 {
+			maxBytes = DefaultPayloadMaxBytes
+		}
+		return truncateUTF8(text, maxBytes)
+	case PayloadHashOnly:
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
+
+// encodePayload returns strings as-is and JSON-encodes anything else.
+func encodePayload(payload interface{}) string {
+	switch v := payload.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a rune.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -9,6 +9,8 @@
 //   - Handle connection failures gracefully
 //   - Buffer records when Kafka is unavailable
 //   - Retry failed publishes
+//   - Serialize records as JSON or protobuf (USAGE_ENCODING); the content-type
+//     header tells consumers which, and analytics reads both during a migration
 //
 // Requirements Reference:
 //   - specs/006-api-router-service/spec.md#US-004 (Accurate, timely usage accounting)
@@ -18,13 +20,17 @@ package usage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
 	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+	"github.com/ai-aas/shared-go/usagerecord"
 )
 
 // Publisher publishes usage records to Kafka.
@@ -33,6 +39,7 @@ type Publisher struct {
 	logger *zap.Logger
 	mu     sync.RWMutex
 	topic  string
+	cfg    PublisherConfig
 }
 
 // PublisherConfig configures the Kafka publisher.
@@ -44,6 +51,11 @@ type PublisherConfig struct {
 	BatchTimeout time.Duration
 	WriteTimeout time.Duration
 	RequiredAcks kafka.RequiredAcks
+	Encoding     usagerecord.Encoding // JSON when empty
+
+	// Credentials, when set, authenticates with SASL/PLAIN. It is read each
+	// time the writer is built, so Reconnect picks up rotated credentials.
+	Credentials func() (username, password string)
 }
 
 // NewPublisher creates a new Kafka publisher for usage records.
@@ -52,6 +64,16 @@ func NewPublisher(cfg PublisherConfig, logger *zap.Logger) *Publisher {
 		logger = zap.NewNop()
 	}
 
+	return &Publisher{
+		writer: newWriter(cfg),
+		logger: logger.With(zap.String("component", "usage-publisher")),
+		topic:  cfg.Topic,
+		cfg:    cfg,
+	}
+}
+
+// newWriter builds a Kafka writer from cfg.
+func newWriter(cfg PublisherConfig) *kafka.Writer {
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Brokers...),
 		Topic:        cfg.Topic,
@@ -64,17 +86,37 @@ func NewPublisher(cfg PublisherConfig, logger *zap.Logger) *Publisher {
 		ReadTimeout:  5 * time.Second,
 	}
 
-	if cfg.ClientID != "" {
+	var mechanism sasl.Mechanism
+	if cfg.Credentials != nil {
+		if username, password := cfg.Credentials(); username != "" {
+			mechanism = plain.Mechanism{Username: username, Password: password}
+		}
+	}
+	if cfg.ClientID != "" || mechanism != nil {
 		writer.Transport = &kafka.Transport{
 			ClientID: cfg.ClientID,
+			SASL:     mechanism,
 		}
 	}
+	return writer
+}
 
-	return &Publisher{
-		writer: writer,
-		logger: logger.With(zap.String("component", "usage-publisher")),
-		topic:  cfg.Topic,
+// Reconnect replaces the Kafka writer so new connections authenticate with
+// the current credentials. The previous writer flushes pending messages
+// before it closes; a publish racing the swap fails and is buffered for
+// retry by the usage hook.
+func (p *Publisher) Reconnect() error {
+	p.mu.Lock()
+	previous := p.writer
+	if previous == nil {
+		p.mu.Unlock()
+		return fmt.Errorf("kafka writer is closed")
 	}
+	p.writer = newWriter(p.cfg)
+	p.mu.Unlock()
+
+	p.logger.Info("Kafka publisher reconnected with current credentials")
+	return previous.Close()
 }
 
 // Publish publishes a usage record to Kafka.
@@ -88,8 +130,7 @@ func (p *Publisher) Publish(ctx context.Context, record *UsageRecord) error {
 		return fmt.Errorf("kafka writer is closed")
 	}
 
-	// Serialize record to JSON
-	payload, err := json.Marshal(record)
+	message, err := p.message(ctx, record)
 	if err != nil {
 		p.logger.Error("failed to serialize usage record",
 			zap.String("record_id", record.RecordID),
@@ -99,20 +140,6 @@ func (p *Publisher) Publish(ctx context.Context, record *UsageRecord) error {
 		return fmt.Errorf("serialize usage record: %w", err)
 	}
 
-	// Create Kafka message with record ID as key for partitioning
-	message := kafka.Message{
-		Key:   []byte(record.RecordID),
-		Value: payload,
-		Headers: []kafka.Header{
-			{Key: "record_id", Value: []byte(record.RecordID)},
-			{Key: "request_id", Value: []byte(record.RequestID)},
-			{Key: "organization_id", Value: []byte(record.OrganizationID)},
-			{Key: "model", Value: []byte(record.Model)},
-			{Key: "backend_id", Value: []byte(record.BackendID)},
-		},
-		Time: record.Timestamp,
-	}
-
 	// Write to Kafka
 	if err := writer.WriteMessages(ctx, message); err != nil {
 		p.logger.Error("failed to publish usage record to Kafka",
@@ -150,7 +177,7 @@ func (p *Publisher) PublishBatch(ctx context.Context, records []*UsageRecord) er
 
 	messages := make([]kafka.Message, 0, len(records))
 	for _, record := range records {
-		payload, err := json.Marshal(record)
+		message, err := p.message(ctx, record)
 		if err != nil {
 			p.logger.Error("failed to serialize usage record in batch",
 				zap.String("record_id", record.RecordID),
@@ -158,19 +185,6 @@ func (p *Publisher) PublishBatch(ctx context.Context, records []*UsageRecord) er
 			)
 			continue // Skip invalid records
 		}
-
-		message := kafka.Message{
-			Key:   []byte(record.RecordID),
-			Value: payload,
-			Headers: []kafka.Header{
-				{Key: "record_id", Value: []byte(record.RecordID)},
-				{Key: "request_id", Value: []byte(record.RequestID)},
-				{Key: "organization_id", Value: []byte(record.OrganizationID)},
-				{Key: "model", Value: []byte(record.Model)},
-				{Key: "backend_id", Value: []byte(record.BackendID)},
-			},
-			Time: record.Timestamp,
-		}
 		messages = append(messages, message)
 	}
 
@@ -194,6 +208,33 @@ func (p *Publisher) PublishBatch(ctx context.Context, records []*UsageRecord) er
 	return nil
 }
 
+// message serializes a record in the configured encoding, keyed by record ID
+// for partitioning. The correlation ID of the originating request, when ctx
+// carries one, travels as a header so consumers can join their logs to it.
+func (p *Publisher) message(ctx context.Context, record *UsageRecord) (kafka.Message, error) {
+	payload, err := p.cfg.Encoding.Marshal(*record)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	headers := []kafka.Header{
+		{Key: "content-type", Value: []byte(p.cfg.Encoding.ContentType())},
+		{Key: "record_id", Value: []byte(record.RecordID)},
+		{Key: requestid.RequestIDKey, Value: []byte(record.RequestID)},
+		{Key: "organization_id", Value: []byte(record.OrganizationID)},
+		{Key: "model", Value: []byte(record.Model)},
+		{Key: "backend_id", Value: []byte(record.BackendID)},
+	}
+	if id := requestid.CorrelationID(ctx); id != "" {
+		headers = append(headers, kafka.Header{Key: requestid.CorrelationIDKey, Value: []byte(id)})
+	}
+	return kafka.Message{
+		Key:     []byte(record.RecordID),
+		Value:   payload,
+		Headers: headers,
+		Time:    record.Timestamp,
+	}, nil
+}
+
 // Close closes the Kafka writer connection.
 // Safe to call multiple times.
 func (p *Publisher) Close() error {
@@ -21,37 +21,17 @@ import (
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ai-aas/shared-go/usagerecord"
 )
 
-// UsageRecord represents a usage record for billing and analytics.
-// Matches the schema defined in usage-record.schema.yaml
-type UsageRecord struct {
-	RecordID       string                 `json:"record_id"`
-	RequestID      string                 `json:"request_id"`
-	OrganizationID string                 `json:"organization_id"`
-	APIKeyID       string                 `json:"api_key_id"`
-	Model          string                 `json:"model"`
-	BackendID      string                 `json:"backend_id"`
-	TokensInput    int                    `json:"tokens_input"`
-	TokensOutput   int                    `json:"tokens_output"`
-	LatencyMS      int                    `json:"latency_ms"`
-	CostUSD        float64                `json:"cost_usd"`
-	LimitState     string                 `json:"limit_state"`
-	DecisionReason string                 `json:"decision_reason"`
-	BudgetSnapshot *BudgetSnapshot        `json:"budget_snapshot,omitempty"`
-	RetryCount     int                    `json:"retry_count,omitempty"`
-	TraceID        string                 `json:"trace_id,omitempty"`
-	SpanID         string                 `json:"span_id,omitempty"`
-	Metadata       map[string]string      `json:"metadata,omitempty"`
-	Timestamp      time.Time              `json:"timestamp"`
-}
+// UsageRecord represents a usage record for billing and analytics. It is the
+// current version of the shared schema (shared/go/usagerecord); analytics
+// ingestion decodes every registered version, so new fields must be optional.
+type UsageRecord = usagerecord.Record
 
 // BudgetSnapshot represents budget state at the time of the request.
-type BudgetSnapshot struct {
-	Period            string  `json:"period"` // "DAILY" or "MONTHLY"
-	TokensRemaining   int     `json:"tokens_remaining"`
-	CurrencyRemaining float64 `json:"currency_remaining"`
-}
+type BudgetSnapshot = usagerecord.BudgetSnapshot
 
 // RecordBuilder builds usage records from request context.
 type RecordBuilder struct {
@@ -79,6 +59,7 @@ func (b *RecordBuilder) BuildRecord(ctx *RecordContext) *UsageRecord {
 	cost := b.costCalculator(ctx.TokensInput, ctx.TokensOutput, ctx.Model)
 
 	record := &UsageRecord{
+		SchemaVersion:  usagerecord.CurrentVersion,
 		RecordID:       recordID,
 		RequestID:      ctx.RequestID,
 		OrganizationID: ctx.OrganizationID,
@@ -89,6 +70,7 @@ func (b *RecordBuilder) BuildRecord(ctx *RecordContext) *UsageRecord {
 		TokensOutput:   ctx.TokensOutput,
 		LatencyMS:      ctx.LatencyMS,
 		CostUSD:        cost,
+		Status:         usagerecord.StatusSuccess,
 		LimitState:     ctx.LimitState,
 		DecisionReason: ctx.DecisionReason,
 		RetryCount:     ctx.RetryCount,
@@ -110,7 +92,17 @@ func (b *RecordBuilder) BuildRecord(ctx *RecordContext) *UsageRecord {
 
 	// Add metadata if available
 	if len(ctx.Metadata) > 0 {
-		record.Metadata = ctx.Metadata
+		record.Metadata = make(map[string]any, len(ctx.Metadata))
+		for k, v := range ctx.Metadata {
+			record.Metadata[k] = v
+		}
+	}
+
+	// Add payloads as far as the org's policy allows
+	record.Prompt = ctx.PayloadPolicy.apply(ctx.Prompt)
+	record.Response = ctx.PayloadPolicy.apply(ctx.Response)
+	if record.Prompt != "" || record.Response != "" {
+		record.PayloadMode = ctx.PayloadPolicy.mode()
 	}
 
 	return record
@@ -133,6 +125,9 @@ type RecordContext struct {
 	TraceID        string
 	SpanID         string
 	Metadata       map[string]string
+	Prompt         interface{} // Raw request payload; recorded per PayloadPolicy
+	Response       interface{} // Raw response payload; recorded per PayloadPolicy
+	PayloadPolicy  *PayloadPolicy
 }
 
 // NewRecordContext creates a new record context from basic fields.
@@ -175,6 +170,15 @@ func (c *RecordContext) WithRetryCount(count int) *RecordContext {
 	return c
 }
 
+// WithPayloads sets the prompt and response and the policy deciding how much
+// of them the record keeps; a nil policy omits them.
+func (c *RecordContext) WithPayloads(prompt, response interface{}, policy *PayloadPolicy) *RecordContext {
+	c.Prompt = prompt
+	c.Response = response
+	c.PayloadPolicy = policy
+	return c
+}
+
 // WithMetadata adds metadata key-value pairs.
 func (c *RecordContext) WithMetadata(key, value string) *RecordContext {
 	if c.Metadata == nil {
@@ -195,29 +199,38 @@ func (c *RecordContext) WithMetadataMap(metadata map[string]string) *RecordConte
 	return c
 }
 
+// ModelPricing is the price of a model per 1K tokens, in USD.
+type ModelPricing struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// These are example rates - should be configurable or fetched from pricing service
+var (
+	pricingCatalog = map[string]ModelPricing{
+		"gpt-4o":        {InputPer1K: 0.005, OutputPer1K: 0.015},
+		"gpt-4":         {InputPer1K: 0.005, OutputPer1K: 0.015},
+		"gpt-3.5-turbo": {InputPer1K: 0.0005, OutputPer1K: 0.0015},
+	}
+	defaultPricing = ModelPricing{InputPer1K: 0.001, OutputPer1K: 0.002}
+)
+
+// PricingFor returns the price of model, falling back to the default rates.
+// This is the pricing catalog used for both billing and GET /v1/models.
+func PricingFor(model string) ModelPricing {
+	if pricing, ok := pricingCatalog[model]; ok {
+		return pricing
+	}
+	return defaultPricing
+}
+
 // defaultCostCalculator calculates cost based on token usage and model.
 // This is a simplified cost model - in production, this would query
 // a pricing service or use a more sophisticated model.
 func defaultCostCalculator(tokensInput, tokensOutput int, model string) float64 {
-	// Simplified cost model (per 1K tokens)
-	// These are example rates - should be configurable or fetched from pricing service
-	var inputRate, outputRate float64
-
-	switch model {
-	case "gpt-4o", "gpt-4":
-		inputRate = 0.005   // $0.005 per 1K input tokens
-		outputRate = 0.015  // $0.015 per 1K output tokens
-	case "gpt-3.5-turbo":
-		inputRate = 0.0005  // $0.0005 per 1K input tokens
-		outputRate = 0.0015 // $0.0015 per 1K output tokens
-	default:
-		// Default rates
-		inputRate = 0.001
-		outputRate = 0.002
-	}
-
-	inputCost := (float64(tokensInput) / 1000.0) * inputRate
-	outputCost := (float64(tokensOutput) / 1000.0) * outputRate
+	pricing := PricingFor(model)
+	inputCost := (float64(tokensInput) / 1000.0) * pricing.InputPer1K
+	outputCost := (float64(tokensOutput) / 1000.0) * pricing.OutputPer1K
 
 	return inputCost + outputCost
 }
@@ -0,0 +1,149 @@
+package userorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+)
+
+// mockVars are the provider-state values used against the mock provider.
+var mockVars = map[string]string{
+	"apiKey":        "sk_live_contract_active_key",
+	"unknownApiKey": "sk_live_contract_unknown_key",
+	"accessToken":   "contract-access-token",
+	// clientIp is the RemoteAddr httptest.NewRequest assigns.
+	"clientIp": "192.0.2.1",
+}
+
+func authenticate(t *testing.T, baseURL, apiKey string) (*auth.AuthenticatedContext, error) {
+	t.Helper()
+	authenticator := auth.NewAuthenticator(zap.NewNop(), baseURL, 2*time.Second)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	return authenticator.Authenticate(req)
+}
+
+// TestConsumerValidatesActiveKey checks the router sends the contracted
+// request and maps every contracted response field it relies on.
+func TestConsumerValidatesActiveKey(t *testing.T) {
+	contract := loadContract(t)
+	mock := newMockProvider(t, contract, mockVars)
+	want := contract.find(t, "validate an active API key").Response.Body
+
+	authCtx, err := authenticate(t, mock.URL, mockVars["apiKey"])
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if authCtx.APIKeyID != want["apiKeyId"] || authCtx.OrganizationID != want["organizationId"] ||
+		authCtx.PrincipalID != want["principalId"] || authCtx.PrincipalType != want["principalType"] {
+		t.Fatalf("authenticated context does not reflect contract response: %+v", authCtx)
+	}
+	if fmt.Sprint(authCtx.Scopes) != fmt.Sprint(want["scopes"]) {
+		t.Fatalf("expected scopes %v, got %v", want["scopes"], authCtx.Scopes)
+	}
+	if mock.matched["validate an active API key"] != 1 {
+		t.Fatalf("expected one validate-api-key call, got %v", mock.matched)
+	}
+}
+
+// TestConsumerRejectsUnknownKey checks a valid=false response fails authentication.
+func TestConsumerRejectsUnknownKey(t *testing.T) {
+	contract := loadContract(t)
+	mock := newMockProvider(t, contract, mockVars)
+
+	if _, err := authenticate(t, mock.URL, mockVars["unknownApiKey"]); err == nil {
+		t.Fatal("expected unknown key to be rejected")
+	}
+	if mock.matched["validate an unknown API key"] != 1 {
+		t.Fatalf("expected one validate-api-key call, got %v", mock.matched)
+	}
+}
+
+// TestContractExamplesSatisfyRules keeps the examples served by the mock in
+// line with the rules enforced against the real provider.
+func TestContractExamplesSatisfyRules(t *testing.T) {
+	contract := loadContract(t)
+	if contract.Consumer != "api-router-service" || contract.Provider != "user-org-service" {
+		t.Fatalf("unexpected contract parties: %s -> %s", contract.Consumer, contract.Provider)
+	}
+	for _, interaction := range contract.Interactions {
+		body, err := json.Marshal(interaction.Response.Body)
+		if err != nil {
+			t.Fatalf("%s: marshal example: %v", interaction.Description, err)
+		}
+		header := http.Header{}
+		for k, v := range interaction.Response.Headers {
+			header.Set(k, v)
+		}
+		if v := responseViolations(interaction.Response, interaction.Response.Status, header, body); len(v) > 0 {
+			t.Errorf("%s: example violates contract: %s", interaction.Description, strings.Join(v, "; "))
+		}
+	}
+}
+
+// TestProviderHonoursContract replays every interaction against a running
+// user-org-service. Set USER_ORG_CONTRACT_URL to enable it.
+func TestProviderHonoursContract(t *testing.T) {
+	baseURL := strings.TrimSuffix(os.Getenv("USER_ORG_CONTRACT_URL"), "/")
+	if baseURL == "" {
+		t.Skip("USER_ORG_CONTRACT_URL not set; skipping provider verification")
+	}
+	vars := map[string]string{
+		"apiKey":        os.Getenv("USER_ORG_CONTRACT_API_KEY"),
+		"unknownApiKey": fmt.Sprintf("sk_contract_unknown_%d", time.Now().UnixNano()),
+		"accessToken":   os.Getenv("USER_ORG_CONTRACT_ACCESS_TOKEN"),
+		"clientIp":      "192.0.2.1",
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for _, interaction := range loadContract(t).Interactions {
+		interaction := interaction
+		t.Run(interaction.Description, func(t *testing.T) {
+			want, missing := interaction.resolvedRequest(vars)
+			if len(missing) > 0 {
+				t.Skipf("provider state %q needs %v", interaction.ProviderState, missing)
+			}
+
+			var body io.Reader
+			if want.Body != nil {
+				data, err := json.Marshal(want.Body)
+				if err != nil {
+					t.Fatalf("marshal request: %v", err)
+				}
+				body = bytes.NewReader(data)
+			}
+			req, err := http.NewRequest(want.Method, baseURL+want.Path, body)
+			if err != nil {
+				t.Fatalf("create request: %v", err)
+			}
+			for k, v := range want.Headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("call provider: %v", err)
+			}
+			defer resp.Body.Close()
+			respBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("read response: %v", err)
+			}
+
+			if v := responseViolations(interaction.Response, resp.StatusCode, resp.Header, respBody); len(v) > 0 {
+				t.Fatalf("provider drifted from contract: %s\nbody: %s", strings.Join(v, "; "), respBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Package userorg provides consumer-driven contract tests between the router
+// and the user-org-service auth API.
+//
+// Purpose:
+//   The contract in testdata/router-user-org-auth.json pins the request and
+//   response shapes the router depends on. Consumer tests run the router's
+//   authenticator against a mock generated from the contract; provider tests
+//   replay the same interactions against a running user-org-service.
+//
+// Key Responsibilities:
+//   - Load the contract and resolve {{variable}} placeholders
+//   - Serve contract responses from a mock provider, failing on request drift
+//   - Verify real provider responses against contract types and exact values
+//
+// Debugging Notes:
+//   - Provider tests are skipped unless USER_ORG_CONTRACT_URL is set
+//   - USER_ORG_CONTRACT_API_KEY and USER_ORG_CONTRACT_ACCESS_TOKEN supply
+//     provider state; interactions whose variables are unset are skipped
+//   - Changing the contract is a breaking change for one side; update both
+//
+package userorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Contract is a consumer-driven contract between two services.
+type Contract struct {
+	Consumer     string        `json:"consumer"`
+	Provider     string        `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request the consumer sends and the response it relies on.
+type Interaction struct {
+	Description   string           `json:"description"`
+	ProviderState string           `json:"providerState"`
+	Request       ContractRequest  `json:"request"`
+	Response      ContractResponse `json:"response"`
+}
+
+// ContractRequest is the exact request the consumer sends.
+type ContractRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    map[string]any    `json:"body"`
+}
+
+// ContractResponse is an example response plus the rules a real one must meet.
+type ContractResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    map[string]any    `json:"body"`
+	// Types maps required body fields to string, boolean, number, array, object or uuid.
+	Types map[string]string `json:"types"`
+	// Exact lists body fields whose value must equal the example.
+	Exact []string `json:"exact"`
+}
+
+var (
+	placeholder = regexp.MustCompile(`\{\{(\w+)\}\}`)
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// loadContract reads the router/user-org auth contract.
+func loadContract(t *testing.T) Contract {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "router-user-org-auth.json"))
+	if err != nil {
+		t.Fatalf("read contract: %v", err)
+	}
+	var c Contract
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("parse contract: %v", err)
+	}
+	return c
+}
+
+// find returns the interaction with the given description.
+func (c Contract) find(t *testing.T, description string) Interaction {
+	t.Helper()
+	for _, i := range c.Interactions {
+		if i.Description == description {
+			return i
+		}
+	}
+	t.Fatalf("contract has no interaction %q", description)
+	return Interaction{}
+}
+
+// resolve substitutes {{variable}} placeholders, reporting any left unresolved.
+func resolve(s string, vars map[string]string) (string, []string) {
+	var missing []string
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok && v != "" {
+			return v
+		}
+		missing = append(missing, name)
+		return m
+	})
+	return out, missing
+}
+
+// resolvedRequest returns the interaction's request with variables substituted.
+func (i Interaction) resolvedRequest(vars map[string]string) (ContractRequest, []string) {
+	var missing []string
+	req := ContractRequest{Method: i.Request.Method, Headers: map[string]string{}}
+	var m []string
+	req.Path, m = resolve(i.Request.Path, vars)
+	missing = append(missing, m...)
+	for k, v := range i.Request.Headers {
+		req.Headers[k], m = resolve(v, vars)
+		missing = append(missing, m...)
+	}
+	if i.Request.Body != nil {
+		req.Body = map[string]any{}
+		for k, v := range i.Request.Body {
+			if s, ok := v.(string); ok {
+				v, m = resolve(s, vars)
+				missing = append(missing, m...)
+			}
+			req.Body[k] = v
+		}
+	}
+	return req, missing
+}
+
+// mockProvider serves contract responses and records requests that match no
+// interaction, which means the consumer drifted from the contract.
+type mockProvider struct {
+	*httptest.Server
+	mu      sync.Mutex
+	drift   []string
+	matched map[string]int
+}
+
+// newMockProvider starts a mock of the provider for the given interactions.
+func newMockProvider(t *testing.T, contract Contract, vars map[string]string) *mockProvider {
+	t.Helper()
+	m := &mockProvider{matched: map[string]int{}}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reasons []string
+		for _, interaction := range contract.Interactions {
+			want, _ := interaction.resolvedRequest(vars)
+			mismatch := requestMismatch(want, r, body)
+			if mismatch == "" {
+				m.mu.Lock()
+				m.matched[interaction.Description]++
+				m.mu.Unlock()
+				for k, v := range interaction.Response.Headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(interaction.Response.Status)
+				_ = json.NewEncoder(w).Encode(interaction.Response.Body)
+				return
+			}
+			reasons = append(reasons, fmt.Sprintf("%q: %s", interaction.Description, mismatch))
+		}
+		m.mu.Lock()
+		m.drift = append(m.drift, fmt.Sprintf("%s %s matched no interaction (%s)", r.Method, r.URL.Path, strings.Join(reasons, "; ")))
+		m.mu.Unlock()
+		http.Error(w, "request does not match the contract", http.StatusNotImplemented)
+	}))
+	t.Cleanup(func() {
+		m.Close()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, d := range m.drift {
+			t.Errorf("contract drift: %s", d)
+		}
+	})
+	return m
+}
+
+// requestMismatch describes how r differs from want, or returns "".
+func requestMismatch(want ContractRequest, r *http.Request, body []byte) string {
+	if r.Method != want.Method || r.URL.Path != want.Path {
+		return "method or path differs"
+	}
+	for k, v := range want.Headers {
+		if got := r.Header.Get(k); got != v {
+			return fmt.Sprintf("header %s is %q", k, got)
+		}
+	}
+	if want.Body == nil {
+		if len(bytes.TrimSpace(body)) != 0 {
+			return "unexpected body"
+		}
+		return ""
+	}
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		return "body is not a JSON object"
+	}
+	for k, v := range want.Body {
+		if fmt.Sprint(got[k]) != fmt.Sprint(v) {
+			return fmt.Sprintf("body field %s is %v", k, got[k])
+		}
+	}
+	for k := range got {
+		if _, ok := want.Body[k]; !ok {
+			return fmt.Sprintf("body field %s is not in the contract", k)
+		}
+	}
+	return ""
+}
+
+// responseViolations checks a provider response against the contract rules.
+func responseViolations(want ContractResponse, status int, header http.Header, body []byte) []string {
+	var violations []string
+	if status != want.Status {
+		violations = append(violations, fmt.Sprintf("status %d, want %d", status, want.Status))
+	}
+	for k, v := range want.Headers {
+		if got := header.Get(k); !strings.HasPrefix(got, v) {
+			violations = append(violations, fmt.Sprintf("header %s is %q, want %q", k, got, v))
+		}
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		return append(violations, fmt.Sprintf("body is not a JSON object: %v", err))
+	}
+	fields := make([]string, 0, len(want.Types))
+	for field := range want.Types {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		v, ok := got[field]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("missing field %s", field))
+			continue
+		}
+		if !hasType(v, want.Types[field]) {
+			violations = append(violations, fmt.Sprintf("field %s is %T, want %s", field, v, want.Types[field]))
+		}
+	}
+	for _, field := range want.Exact {
+		if fmt.Sprint(got[field]) != fmt.Sprint(want.Body[field]) {
+			violations = append(violations, fmt.Sprintf("field %s is %v, want %v", field, got[field], want.Body[field]))
+		}
+	}
+	return violations
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "uuid":
+		s, ok := v.(string)
+		return ok && uuidPattern.MatchString(s)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return false
+}
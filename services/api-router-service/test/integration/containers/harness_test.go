@@ -0,0 +1,370 @@
+// Package containers provides a testcontainers-based integration harness for
+// the API Router Service.
+//
+// Purpose:
+//   These tests run the router's full middleware chain against real Redis and
+//   Kafka (Redpanda) containers, a stub user-org-service, and a stub model
+//   backend, exercising auth → rate limit → budget → routing → usage end to end.
+//
+// Key Responsibilities:
+//   - Start Redis and Redpanda containers per test and tear them down after
+//   - Wire the router the same way cmd/router/main.go does
+//   - Expose helpers to send inference requests and read usage records
+//
+// Debugging Notes:
+//   - Requires Docker; tests are skipped when no provider is healthy or with -short
+//   - Redpanda is bound to a fixed free host port so its advertised address
+//     survives a stop/start cycle
+//   - Usage retry runs every second so buffered records drain quickly
+//
+package containers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/api/public"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/auth"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/limiter"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/routing"
+	"github.com/otherjamesbrown/ai-aas/services/api-router-service/internal/usage"
+)
+
+const (
+	redisImage    = "redis:7-alpine"
+	redpandaImage = "docker.redpanda.com/redpandadata/redpanda:v24.2.7"
+	usageTopic    = "usage.records.v1"
+	testModel     = "gpt-4o"
+	stubBackendID = "stub-backend"
+
+	// validAPIKey is accepted by the stub user-org-service.
+	validAPIKey = "sk_integration_valid_key"
+	testOrgID   = "0b7e4c1d-2a3f-4b5c-8d6e-7f8091a2b3c4"
+	testKeyID   = "6f1c2a8e-3b4d-4e5f-9a0b-1c2d3e4f5a6b"
+)
+
+// harnessOptions tunes the router under test.
+type harnessOptions struct {
+	RateLimitRPS   int
+	RateLimitBurst int
+}
+
+// harness is a running router wired to containerised dependencies.
+type harness struct {
+	t        *testing.T
+	router   http.Handler
+	redis    *redis.Client
+	kafka    testcontainers.Container
+	brokers  []string
+	buffer   *usage.BufferStore
+	backend  *stubBackend
+	usageLog *usage.Publisher
+}
+
+// startHarness starts Redis, Redpanda and the stub services, then builds the router.
+func startHarness(t *testing.T, opts harnessOptions) *harness {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping container integration test in short mode")
+	}
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	if opts.RateLimitRPS == 0 {
+		opts.RateLimitRPS = 1000
+	}
+	if opts.RateLimitBurst == 0 {
+		opts.RateLimitBurst = 1000
+	}
+
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel))
+	h := &harness{t: t}
+
+	// Redis for rate limiting
+	redisAddr := startRedis(ctx, t)
+	h.redis = redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { _ = h.redis.Close() })
+
+	// Redpanda for usage records
+	h.kafka, h.brokers = startRedpanda(ctx, t)
+	createTopic(t, h.brokers[0], usageTopic)
+
+	// Stub dependencies
+	h.backend = newStubBackend(t)
+	userOrg := newStubUserOrg(t)
+
+	// Routing: one global policy pointing at the stub backend
+	cache, err := config.NewCache(":memory:")
+	if err != nil {
+		t.Fatalf("create config cache: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+	if err := cache.StorePolicy(ctx, &config.RoutingPolicy{
+		PolicyID:          "integration-policy",
+		OrganizationID:    "*",
+		Model:             testModel,
+		Backends:          []config.BackendWeight{{BackendID: stubBackendID, Weight: 100}},
+		FailoverThreshold: 1,
+		UpdatedAt:         time.Now(),
+		Version:           1,
+	}); err != nil {
+		t.Fatalf("store routing policy: %v", err)
+	}
+	loader := config.NewLoader("", false, cache, logger)
+	registry := config.NewBackendRegistry(&config.Config{
+		BackendEndpoints: stubBackendID + ":" + h.backend.URL + "/v1/completions",
+	})
+	backendClient := routing.NewBackendClient(logger, 5*time.Second)
+	engine := routing.NewEngine(nil, registry, logger)
+
+	// Usage: Kafka publisher with disk buffer fallback
+	h.usageLog = usage.NewPublisher(usage.PublisherConfig{
+		Brokers:      h.brokers,
+		Topic:        usageTopic,
+		ClientID:     "api-router-integration",
+		BatchSize:    1,
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: 2 * time.Second,
+		RequiredAcks: 1,
+	}, logger)
+	t.Cleanup(func() { _ = h.usageLog.Close() })
+	h.buffer, err = usage.NewBufferStore(usage.BufferStoreConfig{Dir: t.TempDir(), Logger: logger})
+	if err != nil {
+		t.Fatalf("create buffer store: %v", err)
+	}
+	usageHook := public.NewUsageHook(public.UsageHookConfig{
+		Publisher:   h.usageLog,
+		BufferStore: h.buffer,
+		Builder:     usage.NewRecordBuilder(),
+		Logger:      logger,
+		RetryDelay:  time.Second,
+	})
+	t.Cleanup(usageHook.Stop)
+
+	// Middleware chain in the same order as cmd/router/main.go
+	authenticator := auth.NewAuthenticator(logger, userOrg.URL, 2*time.Second)
+	auditLogger := usage.NewAuditLogger(logger)
+	tracer := otel.Tracer("integration")
+	router := chi.NewRouter()
+	router.Use(public.BodyBufferMiddleware(64 * 1024))
+	router.Use(public.AuthContextMiddleware(authenticator, logger, tracer))
+	router.Use(public.RateLimitMiddleware(limiter.NewRateLimiter(h.redis, logger, opts.RateLimitRPS, opts.RateLimitBurst), auditLogger, logger, tracer))
+	router.Use(public.BudgetMiddleware(limiter.NewBudgetClient("", 2*time.Second, logger), auditLogger, logger, tracer))
+	public.NewHandler(logger, authenticator, loader, backendClient, registry, engine, nil, usageHook).RegisterRoutes(router)
+	h.router = router
+
+	return h
+}
+
+// infer sends one inference request and returns the recorded response.
+func (h *harness) infer(apiKey string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	body, err := json.Marshal(map[string]interface{}{
+		"request_id": uuid.NewString(),
+		"model":      testModel,
+		"payload":    "Hello from the integration harness",
+	})
+	if err != nil {
+		h.t.Fatalf("marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/inference", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	rr := httptest.NewRecorder()
+	h.router.ServeHTTP(rr, req)
+	return rr
+}
+
+// readUsage reads n usage records from the start of the usage topic.
+func (h *harness) readUsage(n int, timeout time.Duration) []usage.UsageRecord {
+	h.t.Helper()
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   h.brokers,
+		Topic:     usageTopic,
+		Partition: 0,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	records := make([]usage.UsageRecord, 0, n)
+	for len(records) < n {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			h.t.Fatalf("read usage record %d of %d: %v", len(records)+1, n, err)
+		}
+		var record usage.UsageRecord
+		if err := json.Unmarshal(msg.Value, &record); err != nil {
+			h.t.Fatalf("decode usage record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// bufferedCount returns how many usage records are waiting on disk.
+func (h *harness) bufferedCount() int {
+	h.t.Helper()
+	n, err := h.buffer.Count()
+	if err != nil {
+		h.t.Fatalf("count buffered records: %v", err)
+	}
+	return n
+}
+
+// startRedis starts a Redis container and returns its address.
+func startRedis(ctx context.Context, t *testing.T) string {
+	t.Helper()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        redisImage,
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForListeningPort("6379/tcp").WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("start redis: %v", err)
+	}
+	addr, err := container.PortEndpoint(ctx, "6379/tcp", "")
+	if err != nil {
+		t.Fatalf("redis endpoint: %v", err)
+	}
+	return addr
+}
+
+// startRedpanda starts a single-node Redpanda broker bound to a fixed host
+// port, so the advertised address stays valid across Stop/Start.
+func startRedpanda(ctx context.Context, t *testing.T) (testcontainers.Container, []string) {
+	t.Helper()
+	port := freePort(t)
+	broker := "localhost:" + strconv.Itoa(port)
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        redpandaImage,
+			ExposedPorts: []string{fmt.Sprintf("%d:9092/tcp", port)},
+			Cmd: []string{
+				"redpanda", "start",
+				"--mode", "dev-container",
+				"--smp", "1",
+				"--kafka-addr", "PLAINTEXT://0.0.0.0:9092",
+				"--advertise-kafka-addr", "PLAINTEXT://" + broker,
+			},
+			WaitingFor: wait.ForLog("Successfully started Redpanda!").WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("start redpanda: %v", err)
+	}
+	return container, []string{broker}
+}
+
+// createTopic creates a single-partition topic on the broker.
+func createTopic(t *testing.T, broker, topic string) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		conn, err := kafka.Dial("tcp", broker)
+		if err == nil {
+			err = conn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+			_ = conn.Close()
+		}
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("create topic %s: %v", topic, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("find free port: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// stubBackend is a model backend that counts requests.
+type stubBackend struct {
+	*httptest.Server
+	requests chan struct{}
+}
+
+func newStubBackend(t *testing.T) *stubBackend {
+	t.Helper()
+	b := &stubBackend{requests: make(chan struct{}, 1024)}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case b.requests <- struct{}{}:
+		default:
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"text":        "stub completion",
+			"tokens_used": 7,
+		})
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// newStubUserOrg accepts validAPIKey and rejects everything else.
+func newStubUserOrg(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			APIKeySecret string `json:"apiKeySecret"`
+		}
+		if r.URL.Path != "/v1/auth/validate-api-key" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.APIKeySecret != validAPIKey {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"valid": false, "message": "API key not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid":          true,
+			"apiKeyId":       testKeyID,
+			"organizationId": testOrgID,
+			"principalId":    uuid.NewString(),
+			"principalType":  "service_account",
+			"scopes":         []string{"inference:invoke"},
+			"status":         "active",
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
@@ -0,0 +1,123 @@
+package containers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestInferencePipelineEndToEnd sends authenticated requests through the full
+// chain and checks the backend was called and usage reached Kafka.
+func TestInferencePipelineEndToEnd(t *testing.T) {
+	h := startHarness(t, harnessOptions{})
+
+	const requests = 3
+	for i := 0; i < requests; i++ {
+		rr := h.infer(validAPIKey)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d: %s", i, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("X-Routing-Backend"); got != stubBackendID {
+			t.Fatalf("expected routing to %s, got %q", stubBackendID, got)
+		}
+	}
+	if got := len(h.backend.requests); got != requests {
+		t.Fatalf("expected %d backend calls, got %d", requests, got)
+	}
+
+	for _, record := range h.readUsage(requests, 30*time.Second) {
+		if record.OrganizationID != testOrgID || record.APIKeyID != testKeyID {
+			t.Fatalf("usage record has wrong identity: %+v", record)
+		}
+		if record.BackendID != stubBackendID || record.Model != testModel || record.TokensOutput != 7 {
+			t.Fatalf("usage record has wrong routing data: %+v", record)
+		}
+	}
+	if n := h.bufferedCount(); n != 0 {
+		t.Fatalf("expected no buffered usage while Kafka is up, got %d", n)
+	}
+}
+
+// TestInvalidKeyRejectedBeforeBackend checks auth failures never reach routing.
+func TestInvalidKeyRejectedBeforeBackend(t *testing.T) {
+	h := startHarness(t, harnessOptions{})
+
+	if rr := h.infer("sk_integration_unknown_key"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for unknown key, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := h.infer(""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for missing key, got %d", rr.Code)
+	}
+	if got := len(h.backend.requests); got != 0 {
+		t.Fatalf("expected no backend calls, got %d", got)
+	}
+}
+
+// TestRateLimitEnforcedWithRedis checks the Redis-backed limiter returns 429
+// once the burst is spent.
+func TestRateLimitEnforcedWithRedis(t *testing.T) {
+	h := startHarness(t, harnessOptions{RateLimitRPS: 1, RateLimitBurst: 3})
+
+	limited := false
+	for i := 0; i < 10; i++ {
+		rr := h.infer(validAPIKey)
+		if rr.Code == http.StatusTooManyRequests {
+			if rr.Header().Get("Retry-After") == "" {
+				t.Fatal("expected Retry-After on 429")
+			}
+			limited = true
+			break
+		}
+		if rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 before the limit, got %d", i, rr.Code)
+		}
+	}
+	if !limited {
+		t.Fatal("expected rate limit to be enforced")
+	}
+	if keys, err := h.redis.Keys(context.Background(), "*").Result(); err != nil || len(keys) == 0 {
+		t.Fatalf("expected rate limit state in Redis, got %v (%v)", keys, err)
+	}
+}
+
+// TestUsageBufferedWhileKafkaDown stops Kafka mid-test and checks inference
+// keeps succeeding, usage is buffered to disk, and the buffer drains to Kafka
+// once the broker is back.
+func TestUsageBufferedWhileKafkaDown(t *testing.T) {
+	h := startHarness(t, harnessOptions{})
+	ctx := context.Background()
+
+	if rr := h.infer(validAPIKey); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 before outage, got %d", rr.Code)
+	}
+	h.readUsage(1, 30*time.Second)
+
+	stopTimeout := 10 * time.Second
+	if err := h.kafka.Stop(ctx, &stopTimeout); err != nil {
+		t.Fatalf("stop redpanda: %v", err)
+	}
+
+	const outageRequests = 2
+	for i := 0; i < outageRequests; i++ {
+		if rr := h.infer(validAPIKey); rr.Code != http.StatusOK {
+			t.Fatalf("expected inference to succeed while Kafka is down, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	if n := h.bufferedCount(); n != outageRequests {
+		t.Fatalf("expected %d buffered usage records, got %d", outageRequests, n)
+	}
+
+	if err := h.kafka.Start(ctx); err != nil {
+		t.Fatalf("restart redpanda: %v", err)
+	}
+
+	deadline := time.Now().Add(60 * time.Second)
+	for h.bufferedCount() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("buffered usage did not drain after Kafka recovered (%d left)", h.bufferedCount())
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	h.readUsage(1+outageRequests, 30*time.Second)
+}
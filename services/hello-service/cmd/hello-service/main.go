@@ -1,17 +1,86 @@
+// Command hello-service greets callers over HTTP; with world-service it is the
+// sample service for the shared Go service template.
+//
+// Purpose:
+//
+//	Serves GET /hello?name=<name> using shared-go config, observability and
+//	logging, and the shared lifecycle server for /healthz, /readyz, /metrics
+//	and graceful shutdown, so new services can copy its wiring unchanged.
+//
+// Debugging Notes:
+//   - SERVICE_NAME defaults to hello-service and SERVICE_ADDRESS to :8080
+//   - An unreachable OTLP endpoint degrades telemetry rather than failing startup
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/config"
+	"github.com/ai-aas/shared-go/logging"
+	"github.com/ai-aas/shared-go/observability"
+	"github.com/ai-aas/shared-go/server"
 
 	"github.com/otherjamesbrown/ai-aas/services/hello-service/pkg/hello"
 )
 
+const serviceName = "hello-service"
+
 func main() {
-	name := ""
-	if len(os.Args) > 1 {
-		name = os.Args[1]
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if _, ok := os.LookupEnv("SERVICE_NAME"); !ok {
+		_ = os.Setenv("SERVICE_NAME", serviceName)
+	}
+	cfg := config.MustLoad(ctx)
+
+	logCfg := logging.DefaultConfig().WithServiceName(cfg.Service.Name)
+	logger := logging.MustNew(logCfg).Logger
+	defer logger.Sync() //nolint:errcheck
+
+	telemetry := observability.MustInit(ctx, observability.Config{
+		ServiceName: cfg.Service.Name,
+		Environment: logCfg.Environment,
+		Endpoint:    cfg.Telemetry.Endpoint,
+		Protocol:    cfg.Telemetry.Protocol,
+		Headers:     cfg.Telemetry.Headers,
+		Insecure:    cfg.Telemetry.Insecure,
+	})
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := telemetry.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("failed to shutdown telemetry", zap.Error(err))
+		}
+	}()
+
+	srv := newServer(cfg, logger)
+	logger.Info("hello-service listening", zap.String("addr", cfg.Service.Address))
+	if err := srv.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal("server error", zap.Error(err))
 	}
+	logger.Info("hello-service stopped")
+}
+
+// newServer wires the application routes into the shared lifecycle server.
+func newServer(cfg config.Config, logger *zap.Logger) *server.Server {
+	router := chi.NewRouter()
+	router.Use(observability.RequestContextMiddleware)
+	router.Get("/hello", hello.Handler)
 
-	fmt.Println(hello.Greeting(name))
+	return server.New(server.Options{
+		Addr:          cfg.Service.Address,
+		Handler:       router,
+		Logger:        logger,
+		EnableMetrics: true,
+	})
 }
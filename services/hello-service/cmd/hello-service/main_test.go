@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/config"
+)
+
+func TestServerRoutes(t *testing.T) {
+	cfg := config.Config{Service: config.ServiceConfig{Name: serviceName, Address: ":0"}}
+	handler := newServer(cfg, zap.NewNop()).Handler()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/hello", http.StatusOK},
+		{"/healthz", http.StatusOK},
+		{"/metrics", http.StatusOK},
+		{"/missing", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.status {
+			t.Fatalf("GET %s: expected status %d, got %d", tc.path, tc.status, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("expected X-Request-ID header from request context middleware")
+	}
+}
@@ -1,10 +1,17 @@
 package hello
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
+// Response is the payload for the hello-service HTTP endpoint.
+type Response struct {
+	Message string `json:"message"`
+}
+
 // Greeting returns a friendly greeting for the provided name.
 // Names are trimmed and capitalized. Empty names default to "there".
 func Greeting(name string) string {
@@ -16,6 +23,13 @@ func Greeting(name string) string {
 	return fmt.Sprintf("Hello, %s!", capitalize(clean))
 }
 
+// Handler writes a JSON greeting for the "name" query parameter.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(Response{Message: Greeting(r.URL.Query().Get("name"))})
+}
+
 func capitalize(word string) string {
 	if word == "" {
 		return word
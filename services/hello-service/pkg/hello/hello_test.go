@@ -1,6 +1,11 @@
 package hello
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestGreeting(t *testing.T) {
 	tests := []struct {
@@ -23,3 +28,25 @@ func TestGreeting(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/hello?name=jane", nil)
+	rec := httptest.NewRecorder()
+
+	Handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected content-type application/json, got %s", ct)
+	}
+
+	var payload Response
+	if err := json.NewDecoder(rec.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Message != "Hello, Jane!" {
+		t.Fatalf("unexpected message: %s", payload.Message)
+	}
+}
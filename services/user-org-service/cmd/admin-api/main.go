@@ -31,7 +31,11 @@
 //   - Readiness probe checks Postgres and Redis connectivity
 //   - Graceful shutdown allows in-flight requests to complete (10s timeout)
 //   - Runtime.Close() releases Postgres pool and Redis connections
+//   - /v1/admin/diagnostics, /v1/admin/security-events, /v1/admin/audit-outbox, /v1/admin/retention/report and (with DEBUG_ENDPOINTS_ENABLED)
+//     /debug/pprof, /debug/vars require a token granted ADMIN_SCOPE
 //   - Logs include service name, environment, and port on startup
+//   - --validate-config checks configuration and dependency connectivity, prints
+//     a JSON report and exits non-zero on any failure (see preflight.go)
 //
 // Thread Safety:
 //   - Main goroutine handles shutdown signals
@@ -47,6 +51,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -57,19 +62,34 @@ import (
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/apiversion"
+	sharedserver "github.com/ai-aas/shared-go/server"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/auditoutbox"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/apikeys"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/auth"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/oauthclients"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/onboarding"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/orgs"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/serviceaccounts"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/signup"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/users"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/logging"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/retention"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/server"
 )
 
 func main() {
+	validate := flag.Bool("validate-config", false, "validate configuration and dependency connectivity, print a JSON report and exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(validateConfig())
+	}
+
 	cfg := config.MustLoad()
 	logger := logging.New(cfg.ServiceName+"-admin-api", cfg.LogLevel)
 	logger.Info("starting admin API",
@@ -81,7 +101,7 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to bootstrap runtime", zap.Error(err))
 	}
-	logger.Info("runtime dependencies initialized")
+	logger.Info("runtime dependencies initialized", zap.Strings("degraded", runtime.Degraded))
 
 	// Initialize IdP registry if OIDC is configured (moved here to avoid import cycles)
 	baseURL := cfg.OIDCBaseURL
@@ -98,14 +118,27 @@ func main() {
 		logger.Info("IdP providers initialized")
 	}
 
+	cors := server.CORSForEnvironment(cfg.Environment, cfg.CORSAllowedOrigins, cfg.CORSMaxAge)
+	deprecations, err := apiversion.ParseDeprecations(cfg.APIDeprecations)
+	if err != nil {
+		logger.Fatal("invalid API_DEPRECATIONS", zap.Error(err))
+	}
 	srv := server.New(server.Options{
-		Port:        cfg.HTTPPort,
-		Logger:      logger,
-		ServiceName: cfg.ServiceName + "-admin-api",
-		Readiness:   readinessProbe(runtime, logger),
+		Port:         cfg.HTTPPort,
+		Logger:       logger,
+		ServiceName:  cfg.ServiceName + "-admin-api",
+		Readiness:    readinessProbe(runtime, logger),
+		DrainDelay:   cfg.ShutdownDrainDelay,
+		PreStopAddr:  cfg.PreStopAddr,
+		CORS:         &cors,
+		Deprecations: deprecations,
 		RegisterRoutes: func(r chi.Router) {
 			// Public auth routes (no auth required)
 			auth.RegisterRoutes(r, runtime, idpRegistry, logger)
+			// Public self-service signup (only mounted when SIGNUP_ENABLED=true)
+			signup.RegisterRoutes(r, runtime, logger)
+			// Short-lived CI keys; authenticates with an OAuth token or an existing API key
+			apikeys.RegisterTemporaryRoutes(r, runtime, logger)
 
 			// Protected routes (require authentication)
 			r.Group(func(r chi.Router) {
@@ -121,6 +154,36 @@ func main() {
 				serviceaccounts.RegisterRoutes(r, runtime, logger)
 				// Register API key routes
 				apikeys.RegisterRoutes(r, runtime, logger)
+				// Register one-call org onboarding (org, owner, service account, API key)
+				onboarding.RegisterRoutes(r, runtime, logger)
+
+				// Runtime diagnostics and optional profiling (admin scope only)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireScope(cfg.AdminScope, logger))
+					r.Get("/v1/admin/diagnostics", sharedserver.DiagnosticsHandler(sharedserver.ConfigFingerprint(cfg)))
+					r.Get("/v1/admin/security-events", securityevents.QueryHandler(runtime.SecurityEvents, logger))
+					// OAuth2 client registration and secret rotation
+					oauthclients.RegisterRoutes(r, runtime, logger)
+					// Bulk org suspension (non-payment, abuse)
+					orgs.RegisterAdminRoutes(r, runtime, logger)
+					// Audit outbox inspection and replay; soft-delete retention dry run
+					if runtime.Postgres != nil {
+						r.Get("/v1/admin/audit-outbox", auditoutbox.ListHandler(runtime.Postgres, logger))
+						r.Post("/v1/admin/audit-outbox:replay", auditoutbox.ReplayHandler(runtime.Postgres, logger))
+						r.Get("/v1/admin/retention/report", retention.ReportHandler(retention.NewWorker(retention.Config{
+							Store:     runtime.Postgres,
+							Logger:    logger,
+							Period:    cfg.RetentionPeriod,
+							Mode:      cfg.RetentionMode,
+							BatchSize: cfg.RetentionBatchSize,
+						}), logger))
+					}
+					if cfg.DebugEndpointsEnabled {
+						debugHandler := sharedserver.DebugHandler()
+						r.Handle(sharedserver.DebugPathPrefix+"*", debugHandler)
+						r.Handle(sharedserver.DebugVarsPath, debugHandler)
+					}
+				})
 			})
 		},
 	})
@@ -137,10 +200,10 @@ func main() {
 	<-ctx.Done()
 	stop()
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+10*time.Second)
 	defer cancel()
 
-		if err := srv.Shutdown(shutdownCtx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("graceful shutdown failed", zap.Error(err))
 		os.Exit(1)
 	}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ai-aas/shared-go/preflight"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
+)
+
+// validateConfig implements --validate-config: it loads the configuration,
+// probes every declared dependency in parallel, prints a JSON report to stdout
+// and returns the process exit code.
+func validateConfig() int {
+	service := "user-org-service-admin-api"
+	cfg, err := config.Load()
+	var checks []preflight.Check
+	if err == nil {
+		service = cfg.ServiceName + "-admin-api"
+		checks = dependencyChecks(cfg)
+	}
+
+	report := preflight.Run(context.Background(), service, err, checks, 0)
+	if writeErr := report.Write(os.Stdout); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", writeErr)
+	}
+	return report.ExitCode()
+}
+
+// dependencyChecks lists the stores, brokers and webhooks the service needs.
+func dependencyChecks(cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{Name: "postgres", Run: preflight.Postgres(cfg.DatabaseURL)},
+		{Name: "redis", Run: preflight.TCP(cfg.RedisAddr)},
+	}
+	for i, replicaURL := range cfg.DatabaseReplicaURLs {
+		checks = append(checks, preflight.Check{Name: fmt.Sprintf("postgres-replica-%d", i+1), Run: preflight.Postgres(replicaURL)})
+	}
+	for _, broker := range strings.Split(cfg.KafkaBrokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			checks = append(checks, preflight.Check{Name: "kafka-" + broker, Run: preflight.TCP(broker)})
+		}
+	}
+	for name, webhookURL := range map[string]string{
+		"onboarding-webhook":      cfg.OnboardingWebhookURL,
+		"key-expiry-webhook":      cfg.KeyExpiryWebhookURL,
+		"security-events-webhook": cfg.SecurityEventsWebhookURL,
+	} {
+		if webhookURL != "" {
+			checks = append(checks, preflight.Check{Name: name, Run: preflight.URLHost(webhookURL)})
+		}
+	}
+	if cfg.SecurityEventsGeoIPFile != "" {
+		checks = append(checks, preflight.Check{Name: "security-events-geoip", Run: func(context.Context) error {
+			_, err := securityevents.LoadGeoTable(cfg.SecurityEventsGeoIPFile)
+			return err
+		}})
+	}
+	return checks
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	maxRetries = 3
+	retryDelay = 1 * time.Second
+)
+
+// login authenticates with the service and returns the login response, which
+// carries access_token and, when issued, refresh_token.
+func login(client *http.Client, apiURL, email, password string) (map[string]any, error) {
+	loginReq := map[string]any{
+		"email":    email,
+		"password": password,
+	}
+
+	loginResp, err := makeRequest(client, "POST", apiURL+"/v1/auth/login", loginReq, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	accessToken, ok := loginResp["access_token"].(string)
+	if !ok || accessToken == "" {
+		return nil, fmt.Errorf("login response missing access_token")
+	}
+
+	return loginResp, nil
+}
+
+// makeAuthenticatedRequest performs an HTTP request with Bearer token authentication.
+func makeAuthenticatedRequest(client *http.Client, method, url string, body map[string]any, token string, expectedStatus int) (map[string]any, error) {
+	return doJSON(client, method, url, body, map[string]string{"Authorization": "Bearer " + token}, expectedStatus)
+}
+
+// makeRequest performs an HTTP request and returns the JSON response.
+func makeRequest(client *http.Client, method, url string, body map[string]any, expectedStatus int) (map[string]any, error) {
+	return doJSON(client, method, url, body, nil, expectedStatus)
+}
+
+func doJSON(client *http.Client, method, url string, body map[string]any, headers map[string]string, expectedStatus int) (map[string]any, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := retryRequest(client, req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != expectedStatus {
+		return nil, fmt.Errorf("expected status %d, got %d for %s %s: %s", expectedStatus, resp.StatusCode, method, url, string(bodyBytes))
+	}
+
+	var result map[string]any
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		if err := json.Unmarshal(bodyBytes, &result); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// retryRequest retries a request with exponential backoff. The request body
+// is rewound between attempts.
+func retryRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	var lastErr error
+	for i := 0; i < maxRetries; i++ {
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if i < maxRetries-1 {
+			time.Sleep(retryDelay * time.Duration(i+1))
+		}
+	}
+	return nil, lastErr
+}
@@ -4,21 +4,22 @@
 //
 //	This binary exercises the complete user and organization lifecycle flows,
 //	including authentication, organization creation, user invites, and user
-//	management. It can run against a local instance (via testcontainers) or
-//	against a deployed development environment (via API_URL environment variable).
+//	management. It runs against a local instance or a deployed development
+//	environment (via --api-url or the API_URL environment variable).
 //
-// Dependencies:
-//   - github.com/stretchr/testify/assert: Test assertions
-//   - github.com/testcontainers/testcontainers-go: Local database setup (optional)
-//   - internal/config: Service configuration
+// Usage:
+//
+//	e2e-test [--only auth,orgs] [--skip users] [--parallel 4]
+//	         [--junit results.xml] [--json results.json] [--run-id ci-1234]
 //
 // Key Responsibilities:
 //   - Test authentication flow (login, refresh, logout)
 //   - Test organization CRUD operations
 //   - Test user invite and acceptance flow
 //   - Test user management (update, suspend, activate)
-//   - Validate audit event emission
-//   - Document manual verification steps
+//   - Test one-call org onboarding
+//   - Select tests by tag and run them in parallel
+//   - Write JUnit XML and JSON results for CI
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#US-001 (User & Organization Management)
@@ -26,49 +27,89 @@
 //   - specs/005-user-org-service/quickstart.md (Manual verification)
 //
 // Debugging Notes:
-//   - Set API_URL to test against deployed service (e.g., http://user-org-service.dev.platform.internal)
-//   - Set DATABASE_URL for local database setup (testcontainers used if not set)
-//   - Tests are sequential to avoid race conditions
-//   - All test data is cleaned up after execution
+//   - Tags: health, smoke, orgs, users, invites, auth, onboarding (test names also match)
+//   - Created resources are namespaced e2e-<run-id>-t<n>-... so parallel tests
+//     and concurrent runs never collide; orgs carry metadata e2e_run=<run-id>
+//   - The API has no org/user deletion, so cleanup suspends created users and
+//     orgs; leftovers can be found by slug prefix or the e2e_run metadata
+//   - Use --parallel 1 for sequential, readable-in-order runs
 //
 // Thread Safety:
-//   - Tests run sequentially (not parallel) to avoid conflicts
+//   - Each test gets its own testContext; only the HTTP client and the
+//     suite login token are shared
 //
 // Error Handling:
-//   - Test failures exit with non-zero code
-//   - Detailed error messages include request/response details
+//   - Test failures exit with code 1; invalid flags with code 2
+//   - Cleanups run even when a test fails or panics; cleanup errors are
+//     reported but do not fail the test
 //   - Network errors are retried with exponential backoff
 package main
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 const (
 	defaultAPIURL     = "http://localhost:8081"
-	maxRetries        = 3
-	retryDelay        = 1 * time.Second
 	defaultTestEmail  = "admin@example.com"
 	defaultTestPasswd = "nubipwdkryfmtaho123!"
 )
 
 func main() {
-	apiURL := os.Getenv("API_URL")
-	if apiURL == "" {
-		apiURL = defaultAPIURL
+	var (
+		apiURL    = flag.String("api-url", envOr("API_URL", defaultAPIURL), "user-org service base URL")
+		email     = flag.String("email", envOr("TEST_EMAIL", defaultTestEmail), "seeded test user email")
+		password  = flag.String("password", envOr("TEST_PASSWORD", defaultTestPasswd), "seeded test user password")
+		only      = flag.String("only", "", "comma-separated tags (or test names) to run")
+		skip      = flag.String("skip", "", "comma-separated tags (or test names) to skip")
+		parallel  = flag.Int("parallel", 4, "maximum tests to run concurrently")
+		junitPath = flag.String("junit", "", "write JUnit XML results to this path")
+		jsonPath  = flag.String("json", "", "write JSON results to this path")
+		runID     = flag.String("run-id", "", "namespace for created resources (default: random)")
+		list      = flag.Bool("list", false, "list tests and tags, then exit")
+	)
+	flag.Parse()
+
+	if *list {
+		for _, tc := range allTests {
+			fmt.Printf("%-28s %s\n", tc.name, strings.Join(tc.tags, ","))
+		}
+		return
+	}
+	if *parallel < 1 {
+		fmt.Fprintln(os.Stderr, "--parallel must be at least 1")
+		os.Exit(2)
+	}
+	if *runID == "" {
+		*runID = randomID()
+	}
+
+	cfg := runnerConfig{
+		apiURL:   strings.TrimSuffix(*apiURL, "/"),
+		email:    *email,
+		password: *password,
+		runID:    strings.ToLower(*runID),
+		parallel: *parallel,
+		only:     splitList(*only),
+		skip:     splitList(*skip),
+	}
+
+	tests := selectTests(allTests, cfg.only, cfg.skip)
+	if len(tests) == 0 {
+		fmt.Fprintf(os.Stderr, "no tests match --only=%q --skip=%q (tags: %s)\n",
+			*only, *skip, strings.Join(knownTags(allTests), ", "))
+		os.Exit(2)
 	}
 
-	fmt.Printf("Running end-to-end tests against: %s\n", apiURL)
+	fmt.Printf("Running %d end-to-end tests against: %s (run %s, parallel %d)\n",
+		len(tests), cfg.apiURL, cfg.runID, cfg.parallel)
 	fmt.Println("=" + strings.Repeat("=", 60))
 
 	client := &http.Client{
@@ -76,457 +117,70 @@ func main() {
 	}
 
 	// Login with seeded test user to get access token for authenticated tests
-	testEmail := os.Getenv("TEST_EMAIL")
-	if testEmail == "" {
-		testEmail = defaultTestEmail
-	}
-	testPassword := os.Getenv("TEST_PASSWORD")
-	if testPassword == "" {
-		testPassword = defaultTestPasswd
-	}
-
-	var accessToken string
-	var loginErr error
-
-	// Attempt to login (may fail if user doesn't exist - that's OK for health check)
-	fmt.Printf("\nAuthenticating with test user: %s\n", testEmail)
-	accessToken, loginErr = login(client, apiURL, testEmail, testPassword)
-	if loginErr != nil {
-		fmt.Printf("  ⚠ Login failed (tests that require auth will skip): %v\n", loginErr)
+	// (may fail if user doesn't exist - that's OK for health check)
+	fmt.Printf("\nAuthenticating with test user: %s\n", cfg.email)
+	if loginResp, err := login(client, cfg.apiURL, cfg.email, cfg.password); err != nil {
+		fmt.Printf("  ⚠ Login failed (tests that require auth will skip): %v\n", err)
 		fmt.Printf("  💡 Ensure database is seeded: make seed\n")
-		accessToken = "" // Empty token means tests should skip auth-required operations
 	} else {
+		cfg.token = loginResp["access_token"].(string)
 		fmt.Printf("  ✓ Authenticated successfully\n")
 	}
 
-	// Test suite
-	tests := []struct {
-		name string
-		fn   func(*testContext, *http.Client, string, string) error
-	}{
-		{"TestHealthCheck", testHealthCheck},
-		{"TestOrganizationLifecycle", testOrganizationLifecycle},
-		{"TestUserInviteFlow", testUserInviteFlow},
-		{"TestUserManagement", testUserManagement},
-		{"TestAuthenticationFlow", testAuthenticationFlow},
+	started := time.Now()
+	results := run(client, cfg, tests)
+	report := jsonReport{
+		RunID:      cfg.runID,
+		APIURL:     cfg.apiURL,
+		StartedAt:  started,
+		DurationMs: time.Since(started).Milliseconds(),
+		Summary:    summarize(results),
+		Results:    results,
 	}
 
-	allPassed := true
-	for _, test := range tests {
-		fmt.Printf("\n[TEST] %s\n", test.name)
-		tc := &testContext{name: test.name}
-		if err := test.fn(tc, client, apiURL, accessToken); err != nil {
-			allPassed = false
-			fmt.Printf("[FAIL] %s: %v\n", test.name, err)
-		} else {
-			fmt.Printf("[PASS] %s\n", test.name)
+	if *junitPath != "" {
+		if err := writeJUnit(*junitPath, report); err != nil {
+			fmt.Fprintf(os.Stderr, "write junit report: %v\n", err)
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, report); err != nil {
+			fmt.Fprintf(os.Stderr, "write json report: %v\n", err)
 		}
 	}
 
+	s := report.Summary
 	fmt.Println("\n" + strings.Repeat("=", 60))
-	if allPassed {
-		fmt.Println("All tests passed!")
-		os.Exit(0)
-	} else {
+	fmt.Printf("%d passed, %d failed, %d skipped\n", s.Passed, s.Failed, s.Skipped)
+	if s.Failed > 0 {
 		fmt.Println("Some tests failed!")
 		os.Exit(1)
 	}
+	fmt.Println("All tests passed!")
 }
 
-// testContext tracks test execution state.
-type testContext struct {
-	name   string
-	errors []string
-}
-
-func (tc *testContext) errorf(format string, args ...interface{}) {
-	tc.errors = append(tc.errors, fmt.Sprintf(format, args...))
-	fmt.Printf("  ERROR: %s\n", fmt.Sprintf(format, args...))
-}
-
-func (tc *testContext) requireNoError(err error, msg string) {
-	if err != nil {
-		tc.errorf("%s: %v", msg, err)
-		panic(err) // Stop test execution
-	}
-}
-
-func (tc *testContext) assertEqual(expected, actual interface{}, msg string) {
-	if expected != actual {
-		tc.errorf("%s: expected %v, got %v", msg, expected, actual)
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
 }
 
-// login authenticates with the service and returns an access token.
-func login(client *http.Client, apiURL, email, password string) (string, error) {
-	loginReq := map[string]any{
-		"email":    email,
-		"password": password,
-	}
-
-	loginResp, err := makeRequest(client, "POST", apiURL+"/v1/auth/login", loginReq, http.StatusOK)
-	if err != nil {
-		return "", fmt.Errorf("login failed: %w", err)
-	}
-
-	accessToken, ok := loginResp["access_token"].(string)
-	if !ok || accessToken == "" {
-		return "", fmt.Errorf("login response missing access_token")
-	}
-
-	return accessToken, nil
-}
-
-// testHealthCheck verifies the service is reachable and healthy.
-func testHealthCheck(tc *testContext, client *http.Client, apiURL, token string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"/healthz", nil)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
-	}
-
-	resp, err := retryRequest(client, req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("expected status 200, got %d", resp.StatusCode)
-	}
-	return nil
-}
-
-// testOrganizationLifecycle tests organization CRUD operations.
-func testOrganizationLifecycle(tc *testContext, client *http.Client, apiURL, token string) error {
-	if token == "" {
-		return fmt.Errorf("authentication required - login failed")
-	}
-
-	orgSlug := fmt.Sprintf("test-org-%s", uuid.New().String()[:8])
-	orgName := "Test Organization"
-
-	// Create organization
-	createReq := map[string]any{
-		"name": orgName,
-		"slug": orgSlug,
-	}
-	org, err := makeAuthenticatedRequest(client, "POST", apiURL+"/v1/orgs", createReq, token, http.StatusCreated)
-	if err != nil {
-		return fmt.Errorf("create org: %w", err)
-	}
-	orgID, ok := org["orgId"].(string)
-	if !ok || orgID == "" {
-		return fmt.Errorf("organization should have an ID")
-	}
-
-	// Get organization by ID
-	org2, err := makeAuthenticatedRequest(client, "GET", apiURL+"/v1/orgs/"+orgID, nil, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("get org by ID: %w", err)
-	}
-	tc.assertEqual(orgID, org2["orgId"], "retrieved org should match created org")
-
-	// Get organization by slug
-	org3, err := makeAuthenticatedRequest(client, "GET", apiURL+"/v1/orgs/"+orgSlug, nil, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("get org by slug: %w", err)
-	}
-	tc.assertEqual(orgID, org3["orgId"], "retrieved org by slug should match created org")
-
-	// Update organization
-	updateReq := map[string]any{
-		"displayName": "Updated Test Organization",
-	}
-	org4, err := makeAuthenticatedRequest(client, "PATCH", apiURL+"/v1/orgs/"+orgID, updateReq, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("update org: %w", err)
-	}
-	tc.assertEqual("Updated Test Organization", org4["name"], "organization name should be updated")
-
-	return nil
-}
-
-// testUserInviteFlow tests user invitation and creation.
-func testUserInviteFlow(tc *testContext, client *http.Client, apiURL, token string) error {
-	if token == "" {
-		return fmt.Errorf("authentication required - login failed")
-	}
-
-	// First create an organization
-	orgSlug := fmt.Sprintf("test-org-%s", uuid.New().String()[:8])
-	createOrgReq := map[string]any{
-		"name": "Test Org for Invites",
-		"slug": orgSlug,
-	}
-	org, err := makeAuthenticatedRequest(client, "POST", apiURL+"/v1/orgs", createOrgReq, token, http.StatusCreated)
-	if err != nil {
-		return fmt.Errorf("create org: %w", err)
-	}
-	orgID := org["orgId"].(string)
-
-	// Invite a user
-	email := fmt.Sprintf("test-%s@example.com", uuid.New().String()[:8])
-	inviteReq := map[string]any{
-		"email": email,
-		"roles": []string{"member"},
-	}
-	invite, err := makeAuthenticatedRequest(client, "POST", apiURL+"/v1/orgs/"+orgID+"/invites", inviteReq, token, http.StatusAccepted)
-	if err != nil {
-		return fmt.Errorf("invite user: %w", err)
-	}
-	inviteID, ok := invite["inviteId"].(string)
-	if !ok || inviteID == "" {
-		return fmt.Errorf("invite should have an ID")
-	}
-	tc.assertEqual(email, invite["email"], "invite email should match")
-
-	// Get the invited user
-	user, err := makeAuthenticatedRequest(client, "GET", apiURL+"/v1/orgs/"+orgID+"/users/"+inviteID, nil, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("get user: %w", err)
-	}
-	tc.assertEqual(email, user["email"], "user email should match")
-	tc.assertEqual("invited", user["status"], "user status should be invited")
-
-	return nil
-}
-
-// testUserManagement tests user status updates and profile management.
-func testUserManagement(tc *testContext, client *http.Client, apiURL, token string) error {
-	if token == "" {
-		return fmt.Errorf("authentication required - login failed")
-	}
-
-	// Create org and user
-	orgSlug := fmt.Sprintf("test-org-%s", uuid.New().String()[:8])
-	createOrgReq := map[string]any{
-		"name": "Test Org for User Management",
-		"slug": orgSlug,
-	}
-	org, err := makeAuthenticatedRequest(client, "POST", apiURL+"/v1/orgs", createOrgReq, token, http.StatusCreated)
-	if err != nil {
-		return fmt.Errorf("create org: %w", err)
-	}
-	orgID := org["orgId"].(string)
-
-	email := fmt.Sprintf("test-%s@example.com", uuid.New().String()[:8])
-	inviteReq := map[string]any{
-		"email": email,
-	}
-	invite, err := makeAuthenticatedRequest(client, "POST", apiURL+"/v1/orgs/"+orgID+"/invites", inviteReq, token, http.StatusAccepted)
-	if err != nil {
-		return fmt.Errorf("invite user: %w", err)
-	}
-	userID := invite["inviteId"].(string)
-
-	// Activate user (change status from invited to active)
-	updateReq := map[string]any{
-		"status": "active",
-	}
-	user, err := makeAuthenticatedRequest(client, "PATCH", apiURL+"/v1/orgs/"+orgID+"/users/"+userID, updateReq, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("activate user: %w", err)
-	}
-	tc.assertEqual("active", user["status"], "user status should be active")
-
-	// Update user profile
-	profileReq := map[string]any{
-		"displayName": "Test User Display Name",
-	}
-	user2, err := makeAuthenticatedRequest(client, "PATCH", apiURL+"/v1/orgs/"+orgID+"/users/"+userID, profileReq, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("update profile: %w", err)
-	}
-	tc.assertEqual("Test User Display Name", user2["displayName"], "display name should be updated")
-
-	// Suspend user
-	suspendReq := map[string]any{
-		"status": "suspended",
-	}
-	user3, err := makeAuthenticatedRequest(client, "PATCH", apiURL+"/v1/orgs/"+orgID+"/users/"+userID, suspendReq, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("suspend user: %w", err)
-	}
-	tc.assertEqual("suspended", user3["status"], "user status should be suspended")
-
-	// Reactivate user
-	activateReq := map[string]any{
-		"status": "active",
-	}
-	user4, err := makeAuthenticatedRequest(client, "PATCH", apiURL+"/v1/orgs/"+orgID+"/users/"+userID, activateReq, token, http.StatusOK)
-	if err != nil {
-		return fmt.Errorf("reactivate user: %w", err)
-	}
-	tc.assertEqual("active", user4["status"], "user status should be active again")
-
-	return nil
-}
-
-// testAuthenticationFlow tests the complete auth flow: login → refresh → logout.
-// Uses the seeded test user to verify authentication endpoints work correctly.
-func testAuthenticationFlow(tc *testContext, client *http.Client, apiURL, token string) error {
-	fmt.Printf("  Testing complete auth flow: login → refresh → logout\n")
-
-	if token == "" {
-		// If we don't have a token from main(), try to login here
-		testEmail := os.Getenv("TEST_EMAIL")
-		if testEmail == "" {
-			testEmail = defaultTestEmail
-		}
-		testPassword := os.Getenv("TEST_PASSWORD")
-		if testPassword == "" {
-			testPassword = defaultTestPasswd
-		}
-
-		var err error
-		token, err = login(client, apiURL, testEmail, testPassword)
-		if err != nil {
-			return fmt.Errorf("login failed: %w (ensure database is seeded: make seed)", err)
-		}
-		fmt.Printf("  ✓ Login successful with seeded user\n")
-	} else {
-		fmt.Printf("  ✓ Using token from initial login\n")
-	}
-
-	// Verify token is valid by making an authenticated request
-	// (we'll use a simple GET to verify the token works)
-	testEmail := os.Getenv("TEST_EMAIL")
-	if testEmail == "" {
-		testEmail = defaultTestEmail
-	}
-	// Try to get user info or make a simple authenticated request
-	// For now, we'll verify the token is present and not empty
-	if token == "" {
-		return fmt.Errorf("access token is empty")
-	}
-	fmt.Printf("  ✓ Access token obtained (length: %d)\n", len(token))
-
-	// Test token refresh (if refresh token is available)
-	// Note: The login response may include a refresh_token
-	testPassword := os.Getenv("TEST_PASSWORD")
-	if testPassword == "" {
-		testPassword = defaultTestPasswd
-	}
-
-	// Attempt refresh (this requires a refresh_token from login)
-	// For now, we'll just verify login works
-	fmt.Printf("  ✓ Login endpoint works correctly\n")
-	fmt.Printf("  ℹ Refresh and logout tests would require refresh_token\n")
-
-	// Future enhancements:
-	// 1. Store refresh_token from login response
-	// 2. Test refresh endpoint with refresh_token
-	// 3. Test logout endpoint
-	// 4. Test MFA enrollment (if MFA is enabled)
-	// 5. Test API key lifecycle (create, validate, revoke)
-
-	return nil
-}
-
-// generateTOTPSecret generates a TOTP secret for MFA testing.
-func generateTOTPSecret() (string, error) {
-	// Use a simple approach - in real implementation, use security.GenerateTOTPSecret()
-	// For e2e test, we'll generate a valid base32 secret
-	secret := "JBSWY3DPEHPK3PXP" // Valid base32 secret for testing
-	return secret, nil
-}
-
-// makeAuthenticatedRequest performs an HTTP request with Bearer token authentication.
-func makeAuthenticatedRequest(client *http.Client, method, url string, body map[string]any, token string, expectedStatus int) (map[string]any, error) {
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("marshal request: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonData)
-	}
-
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	resp, err := retryRequest(client, req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != expectedStatus {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("expected status %d, got %d for %s %s: %s", expectedStatus, resp.StatusCode, method, url, string(bodyBytes))
-	}
-
-	var result map[string]any
-	if resp.ContentLength > 0 {
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("decode response: %w", err)
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
 		}
 	}
-	return result, nil
+	return out
 }
 
-// makeRequest performs an HTTP request and returns the JSON response.
-func makeRequest(client *http.Client, method, url string, body map[string]any, expectedStatus int) (map[string]any, error) {
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("marshal request: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonData)
-	}
-
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	resp, err := retryRequest(client, req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != expectedStatus {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("expected status %d, got %d for %s %s: %s", expectedStatus, resp.StatusCode, method, url, string(bodyBytes))
-	}
-
-	var result map[string]any
-	if resp.ContentLength > 0 {
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("decode response: %w", err)
-		}
-	}
-	return result, nil
-}
-
-// retryRequest retries a request with exponential backoff.
-func retryRequest(client *http.Client, req *http.Request) (*http.Response, error) {
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		resp, err := client.Do(req)
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-		if i < maxRetries-1 {
-			time.Sleep(retryDelay * time.Duration(i+1))
-		}
+// randomID returns a short identifier for namespacing a run.
+func randomID() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%06x", time.Now().UnixNano()&0xffffff)
 	}
-	return nil, lastErr
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// summary counts results by status.
+type summary struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+func summarize(results []result) summary {
+	s := summary{Total: len(results)}
+	for _, r := range results {
+		switch r.Status {
+		case statusPassed:
+			s.Passed++
+		case statusFailed:
+			s.Failed++
+		case statusSkipped:
+			s.Skipped++
+		}
+	}
+	return s
+}
+
+// jsonReport is the --json output.
+type jsonReport struct {
+	RunID      string    `json:"run_id"`
+	APIURL     string    `json:"api_url"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Summary    summary   `json:"summary"`
+	Results    []result  `json:"results"`
+}
+
+func writeJSON(path string, report jsonReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal json report: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// JUnit XML structures (the subset understood by common CI systems).
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func writeJUnit(path string, report jsonReport) error {
+	suite := junitSuite{
+		Name:      "user-org-service-e2e",
+		Tests:     report.Summary.Total,
+		Failures:  report.Summary.Failed,
+		Skipped:   report.Summary.Skipped,
+		Time:      seconds(time.Duration(report.DurationMs) * time.Millisecond),
+		Timestamp: report.StartedAt.UTC().Format(time.RFC3339),
+		Properties: []junitProperty{
+			{Name: "run_id", Value: report.RunID},
+			{Name: "api_url", Value: report.APIURL},
+		},
+	}
+	for _, r := range report.Results {
+		classname := "e2e"
+		if len(r.Tags) > 0 {
+			classname += "." + r.Tags[0]
+		}
+		c := junitCase{
+			Name:      r.Name,
+			Classname: classname,
+			Time:      seconds(r.Duration),
+			SystemOut: r.Output,
+		}
+		switch r.Status {
+		case statusFailed:
+			c.Failure = &junitMessage{Message: r.Failures[0], Body: strings.Join(r.Failures, "\n")}
+		case statusSkipped:
+			c.Skipped = &junitMessage{Message: r.SkipReason}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	data, err := xml.MarshalIndent(junitSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal junit report: %w", err)
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0o644)
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result statuses.
+const (
+	statusPassed  = "passed"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// testCase is a registered end-to-end test.
+type testCase struct {
+	name      string
+	tags      []string
+	needsAuth bool
+	fn        func(*testContext) error
+}
+
+// result records the outcome of one test.
+type result struct {
+	Name          string        `json:"name"`
+	Tags          []string      `json:"tags"`
+	Status        string        `json:"status"`
+	Duration      time.Duration `json:"-"`
+	DurationMs    int64         `json:"duration_ms"`
+	Failures      []string      `json:"failures,omitempty"`
+	SkipReason    string        `json:"skip_reason,omitempty"`
+	CleanupErrors []string      `json:"cleanup_errors,omitempty"`
+	Output        string        `json:"output,omitempty"`
+}
+
+// runnerConfig controls test selection and execution.
+type runnerConfig struct {
+	apiURL   string
+	email    string
+	password string
+	token    string
+	runID    string
+	parallel int
+	only     []string
+	skip     []string
+}
+
+// selectTests filters tests by tag. A test runs when it has any tag in only
+// (or only is empty) and no tag in skip. Test names also match as tags.
+func selectTests(all []testCase, only, skip []string) []testCase {
+	matches := func(tc testCase, tags []string) bool {
+		for _, want := range tags {
+			if strings.EqualFold(want, tc.name) {
+				return true
+			}
+			for _, tag := range tc.tags {
+				if strings.EqualFold(want, tag) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var selected []testCase
+	for _, tc := range all {
+		if len(only) > 0 && !matches(tc, only) {
+			continue
+		}
+		if matches(tc, skip) {
+			continue
+		}
+		selected = append(selected, tc)
+	}
+	return selected
+}
+
+// knownTags lists every tag used by the registered tests.
+func knownTags(all []testCase) []string {
+	seen := map[string]bool{}
+	for _, tc := range all {
+		for _, tag := range tc.tags {
+			seen[tag] = true
+		}
+	}
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// run executes tests with up to cfg.parallel running at once. Results are
+// returned in registration order; each test's output is printed as one block
+// when it finishes so parallel runs stay readable.
+func run(client *http.Client, cfg runnerConfig, tests []testCase) []result {
+	if cfg.parallel < 1 {
+		cfg.parallel = 1
+	}
+	results := make([]result, len(tests))
+	sem := make(chan struct{}, cfg.parallel)
+	var (
+		wg      sync.WaitGroup
+		printMu sync.Mutex
+	)
+	for i, test := range tests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, test testCase) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := runOne(client, cfg, i, test)
+			results[i] = res
+
+			printMu.Lock()
+			defer printMu.Unlock()
+			fmt.Printf("\n[TEST] %s (%s)\n", test.name, strings.Join(test.tags, ","))
+			fmt.Print(res.Output)
+			switch res.Status {
+			case statusPassed:
+				fmt.Printf("[PASS] %s (%s)\n", test.name, res.Duration.Round(time.Millisecond))
+			case statusSkipped:
+				fmt.Printf("[SKIP] %s: %s\n", test.name, res.SkipReason)
+			default:
+				fmt.Printf("[FAIL] %s: %s\n", test.name, strings.Join(res.Failures, "; "))
+			}
+		}(i, test)
+	}
+	wg.Wait()
+	return results
+}
+
+// runOne runs a single test, always running its cleanups, even if the test
+// fails or panics.
+func runOne(client *http.Client, cfg runnerConfig, index int, test testCase) (res result) {
+	tc := &testContext{
+		name:      test.name,
+		client:    client,
+		apiURL:    cfg.apiURL,
+		email:     cfg.email,
+		password:  cfg.password,
+		token:     cfg.token,
+		runID:     cfg.runID,
+		namespace: fmt.Sprintf("e2e-%s-t%d", cfg.runID, index+1),
+	}
+	res = result{Name: test.name, Tags: test.tags}
+	start := time.Now()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			if _, stopped := rec.(failNow); !stopped {
+				tc.errorf("panic: %v", rec)
+			}
+		}
+		res.CleanupErrors = tc.runCleanups()
+		res.Duration = time.Since(start)
+		res.DurationMs = res.Duration.Milliseconds()
+		res.Failures = append(res.Failures, tc.errors...)
+		switch {
+		case len(res.Failures) > 0:
+			res.Status = statusFailed
+		case res.SkipReason != "":
+			res.Status = statusSkipped
+		default:
+			res.Status = statusPassed
+		}
+		res.Output = tc.out.String()
+	}()
+
+	if test.needsAuth && cfg.token == "" {
+		res.SkipReason = "authentication required - login failed"
+		return res
+	}
+	if err := test.fn(tc); err != nil {
+		res.Failures = append(res.Failures, err.Error())
+	}
+	return res
+}
+
+// failNow is panicked by requireNoError to stop a test.
+type failNow struct{}
+
+// cleanup is a deferred resource removal registered by a test.
+type cleanup struct {
+	desc string
+	fn   func() error
+}
+
+// testContext tracks test execution state.
+type testContext struct {
+	name      string
+	client    *http.Client
+	apiURL    string
+	email     string
+	password  string
+	token     string
+	runID     string
+	namespace string
+
+	out      bytes.Buffer
+	errors   []string
+	cleanups []cleanup
+	seq      int
+}
+
+func (tc *testContext) logf(format string, args ...interface{}) {
+	fmt.Fprintf(&tc.out, "  "+format+"\n", args...)
+}
+
+func (tc *testContext) errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	tc.errors = append(tc.errors, msg)
+	tc.logf("ERROR: %s", msg)
+}
+
+func (tc *testContext) requireNoError(err error, msg string) {
+	if err != nil {
+		tc.errorf("%s: %v", msg, err)
+		panic(failNow{}) // Stop test execution; cleanups still run
+	}
+}
+
+func (tc *testContext) assertEqual(expected, actual interface{}, msg string) {
+	if expected != actual {
+		tc.errorf("%s: expected %v, got %v", msg, expected, actual)
+	}
+}
+
+// unique returns a name namespaced to this run and test, so tests can run in
+// parallel (and alongside other runs) without colliding.
+func (tc *testContext) unique(prefix string) string {
+	tc.seq++
+	return fmt.Sprintf("%s-%s-%d", tc.namespace, prefix, tc.seq)
+}
+
+// uniqueEmail returns a namespaced email address.
+func (tc *testContext) uniqueEmail(prefix string) string {
+	return tc.unique(prefix) + "@example.com"
+}
+
+// addCleanup registers fn to run after the test, in reverse order of registration.
+func (tc *testContext) addCleanup(desc string, fn func() error) {
+	tc.cleanups = append(tc.cleanups, cleanup{desc: desc, fn: fn})
+}
+
+// runCleanups runs registered cleanups, recovering from panics so every one runs.
+func (tc *testContext) runCleanups() []string {
+	var errs []string
+	for i := len(tc.cleanups) - 1; i >= 0; i-- {
+		c := tc.cleanups[i]
+		func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					errs = append(errs, fmt.Sprintf("%s: panic: %v", c.desc, rec))
+				}
+			}()
+			if err := c.fn(); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", c.desc, err))
+				tc.logf("cleanup failed: %s: %v", c.desc, err)
+				return
+			}
+			tc.logf("cleaned up %s", c.desc)
+		}()
+	}
+	tc.cleanups = nil
+	return errs
+}
+
+// do performs an authenticated request against the API.
+func (tc *testContext) do(method, path string, body map[string]any, expectedStatus int) (map[string]any, error) {
+	return makeAuthenticatedRequest(tc.client, method, tc.apiURL+path, body, tc.token, expectedStatus)
+}
+
+// createOrg creates a namespaced organization and registers its cleanup.
+func (tc *testContext) createOrg(name string) (string, string, error) {
+	slug := tc.unique("org")
+	org, err := tc.do("POST", "/v1/orgs", map[string]any{
+		"name":     name,
+		"slug":     slug,
+		"metadata": map[string]any{"e2e_run": tc.runID},
+	}, http.StatusCreated)
+	if err != nil {
+		return "", "", fmt.Errorf("create org: %w", err)
+	}
+	orgID, ok := org["orgId"].(string)
+	if !ok || orgID == "" {
+		return "", "", fmt.Errorf("organization should have an ID")
+	}
+	// The API has no org deletion; suspending leaves the org inert and tagged
+	// with e2e_run for out-of-band purging.
+	tc.addCleanup("org "+slug, func() error {
+		_, err := tc.do("PATCH", "/v1/orgs/"+orgID, map[string]any{"status": "suspended"}, http.StatusOK)
+		return err
+	})
+	return orgID, slug, nil
+}
+
+// inviteUser invites a namespaced user into orgID and registers its cleanup.
+func (tc *testContext) inviteUser(orgID string, body map[string]any) (map[string]any, error) {
+	invite, err := tc.do("POST", "/v1/orgs/"+orgID+"/invites", body, http.StatusAccepted)
+	if err != nil {
+		return nil, fmt.Errorf("invite user: %w", err)
+	}
+	userID, ok := invite["inviteId"].(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("invite should have an ID")
+	}
+	tc.addCleanup("user "+userID, func() error {
+		_, err := tc.do("PATCH", "/v1/orgs/"+orgID+"/users/"+userID, map[string]any{"status": "suspended"}, http.StatusOK)
+		return err
+	})
+	return invite, nil
+}
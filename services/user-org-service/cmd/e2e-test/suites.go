@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// allTests is the registered test suite. Tags select subsets via --only/--skip.
+var allTests = []testCase{
+	{name: "TestHealthCheck", tags: []string{"health", "smoke"}, fn: testHealthCheck},
+	{name: "TestOrganizationLifecycle", tags: []string{"orgs"}, needsAuth: true, fn: testOrganizationLifecycle},
+	{name: "TestUserInviteFlow", tags: []string{"users", "invites"}, needsAuth: true, fn: testUserInviteFlow},
+	{name: "TestUserManagement", tags: []string{"users"}, needsAuth: true, fn: testUserManagement},
+	{name: "TestAuthenticationFlow", tags: []string{"auth", "smoke"}, fn: testAuthenticationFlow},
+	{name: "TestOnboarding", tags: []string{"onboarding", "orgs"}, needsAuth: true, fn: testOnboarding},
+}
+
+// testHealthCheck verifies the service is reachable and healthy.
+func testHealthCheck(tc *testContext) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", tc.apiURL+"/healthz", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := retryRequest(tc.client, req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// testOrganizationLifecycle tests organization CRUD operations.
+func testOrganizationLifecycle(tc *testContext) error {
+	orgID, orgSlug, err := tc.createOrg("Test Organization")
+	if err != nil {
+		return err
+	}
+	tc.logf("created org %s", orgSlug)
+
+	// Get organization by ID
+	org2, err := tc.do("GET", "/v1/orgs/"+orgID, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("get org by ID: %w", err)
+	}
+	tc.assertEqual(orgID, org2["orgId"], "retrieved org should match created org")
+
+	// Get organization by slug
+	org3, err := tc.do("GET", "/v1/orgs/"+orgSlug, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("get org by slug: %w", err)
+	}
+	tc.assertEqual(orgID, org3["orgId"], "retrieved org by slug should match created org")
+
+	// Update organization
+	updateReq := map[string]any{
+		"displayName": "Updated Test Organization",
+	}
+	org4, err := tc.do("PATCH", "/v1/orgs/"+orgID, updateReq, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("update org: %w", err)
+	}
+	tc.assertEqual("Updated Test Organization", org4["name"], "organization name should be updated")
+
+	return nil
+}
+
+// testUserInviteFlow tests user invitation and creation.
+func testUserInviteFlow(tc *testContext) error {
+	orgID, _, err := tc.createOrg("Test Org for Invites")
+	if err != nil {
+		return err
+	}
+
+	// Invite a user
+	email := tc.uniqueEmail("invite")
+	invite, err := tc.inviteUser(orgID, map[string]any{
+		"email": email,
+		"roles": []string{"member"},
+	})
+	if err != nil {
+		return err
+	}
+	inviteID := invite["inviteId"].(string)
+	tc.assertEqual(email, invite["email"], "invite email should match")
+
+	// Get the invited user
+	user, err := tc.do("GET", "/v1/orgs/"+orgID+"/users/"+inviteID, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	tc.assertEqual(email, user["email"], "user email should match")
+	tc.assertEqual("invited", user["status"], "user status should be invited")
+
+	return nil
+}
+
+// testUserManagement tests user status updates and profile management.
+func testUserManagement(tc *testContext) error {
+	orgID, _, err := tc.createOrg("Test Org for User Management")
+	if err != nil {
+		return err
+	}
+
+	invite, err := tc.inviteUser(orgID, map[string]any{
+		"email": tc.uniqueEmail("user"),
+	})
+	if err != nil {
+		return err
+	}
+	userPath := "/v1/orgs/" + orgID + "/users/" + invite["inviteId"].(string)
+
+	// Activate user (change status from invited to active)
+	user, err := tc.do("PATCH", userPath, map[string]any{"status": "active"}, http.StatusOK)
+	tc.requireNoError(err, "activate user")
+	tc.assertEqual("active", user["status"], "user status should be active")
+
+	// Update user profile
+	user2, err := tc.do("PATCH", userPath, map[string]any{"displayName": "Test User Display Name"}, http.StatusOK)
+	tc.requireNoError(err, "update profile")
+	tc.assertEqual("Test User Display Name", user2["displayName"], "display name should be updated")
+
+	// Suspend user
+	user3, err := tc.do("PATCH", userPath, map[string]any{"status": "suspended"}, http.StatusOK)
+	tc.requireNoError(err, "suspend user")
+	tc.assertEqual("suspended", user3["status"], "user status should be suspended")
+
+	// Reactivate user
+	user4, err := tc.do("PATCH", userPath, map[string]any{"status": "active"}, http.StatusOK)
+	tc.requireNoError(err, "reactivate user")
+	tc.assertEqual("active", user4["status"], "user status should be active again")
+
+	return nil
+}
+
+// testAuthenticationFlow tests the complete auth flow: login → refresh → logout.
+// It logs in separately from the shared suite token so logging out does not
+// affect tests running in parallel.
+func testAuthenticationFlow(tc *testContext) error {
+	loginResp, err := login(tc.client, tc.apiURL, tc.email, tc.password)
+	if err != nil {
+		return fmt.Errorf("%w (ensure database is seeded: make seed)", err)
+	}
+	token := loginResp["access_token"].(string)
+	tc.logf("✓ Login successful with seeded user")
+
+	refreshToken, _ := loginResp["refresh_token"].(string)
+	if refreshToken == "" {
+		tc.logf("ℹ Login did not issue a refresh_token; skipping refresh")
+	} else {
+		refreshed, err := makeRequest(tc.client, "POST", tc.apiURL+"/v1/auth/refresh", map[string]any{
+			"refresh_token": refreshToken,
+		}, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("refresh: %w", err)
+		}
+		newToken, ok := refreshed["access_token"].(string)
+		if !ok || newToken == "" {
+			return fmt.Errorf("refresh response missing access_token")
+		}
+		token = newToken
+		tc.logf("✓ Refresh issued a new access token")
+	}
+
+	if _, err := makeRequest(tc.client, "POST", tc.apiURL+"/v1/auth/logout", map[string]any{
+		"token":           token,
+		"token_type_hint": "access_token",
+	}, http.StatusOK); err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+	tc.logf("✓ Logout revoked the access token")
+
+	return nil
+}
+
+// testOnboarding creates an org, owner, service account and API key in one call.
+func testOnboarding(tc *testContext) error {
+	slug := tc.unique("onboard")
+	ownerEmail := tc.uniqueEmail("owner")
+	resp, err := tc.do("POST", "/v1/onboarding", map[string]any{
+		"org": map[string]any{
+			"name":     "Onboarded Org",
+			"slug":     slug,
+			"metadata": map[string]any{"e2e_run": tc.runID},
+		},
+		"owner": map[string]any{"email": ownerEmail},
+	}, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("onboard: %w", err)
+	}
+	orgID, _ := resp["orgId"].(string)
+	ownerID, _ := resp["ownerUserId"].(string)
+	if orgID == "" || ownerID == "" {
+		return fmt.Errorf("onboarding response missing orgId or ownerUserId")
+	}
+	tc.addCleanup("org "+slug, func() error {
+		_, err := tc.do("PATCH", "/v1/orgs/"+orgID, map[string]any{"status": "suspended"}, http.StatusOK)
+		return err
+	})
+	tc.addCleanup("user "+ownerID, func() error {
+		_, err := tc.do("PATCH", "/v1/orgs/"+orgID+"/users/"+ownerID, map[string]any{"status": "suspended"}, http.StatusOK)
+		return err
+	})
+
+	tc.assertEqual("invited", resp["ownerStatus"], "owner without password should be invited")
+	apiKey, _ := resp["apiKey"].(map[string]any)
+	if secret, _ := apiKey["secret"].(string); secret == "" {
+		tc.errorf("onboarding response should include the API key secret")
+	}
+
+	owner, err := tc.do("GET", "/v1/orgs/"+orgID+"/users/"+ownerID, nil, http.StatusOK)
+	tc.requireNoError(err, "get owner")
+	tc.assertEqual(ownerEmail, owner["email"], "owner email should match")
+
+	// A second onboarding with the same slug must conflict and create nothing
+	if _, err := tc.do("POST", "/v1/onboarding", map[string]any{
+		"org":   map[string]any{"name": "Duplicate", "slug": slug},
+		"owner": map[string]any{"email": tc.uniqueEmail("dup")},
+	}, http.StatusConflict); err != nil {
+		tc.errorf("duplicate onboarding: %v", err)
+	}
+	return nil
+}
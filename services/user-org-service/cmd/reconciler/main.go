@@ -16,7 +16,11 @@
 //
 // Key Responsibilities:
 //   - Initialize runtime dependencies (Postgres, Redis, OAuth provider)
-//   - Run background reconciliation worker (currently stub)
+//   - Run the declarative reconciliation worker (drift detection and approved changes)
+//   - Run the API key expiry worker (reminders and expired status)
+//   - Run the account deletion worker (purges accounts after the grace period)
+//   - Run the audit outbox relay (publishes outbox rows when AUDIT_OUTBOX_ENABLED)
+//   - Run the retention worker (anonymizes or purges long soft-deleted rows)
 //   - Expose health/readiness endpoints on separate port
 //   - Handle graceful shutdown
 //
@@ -26,7 +30,7 @@
 //
 // Debugging Notes:
 //   - Server runs on HTTP_PORT + 1 (default 8082) to avoid conflicts with admin-api
-//   - Worker loop is currently a stub (TODO: implement reconciliation logic)
+//   - Reconciliation is disabled unless RECONCILER_DESIRED_STATE_DIR is set
 //   - Uses same bootstrap.Initialize as admin-api for consistency
 //   - Readiness probe uses runtime.ReadinessProbe (checks Postgres/Redis)
 //
@@ -51,9 +55,14 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/accountdeletion"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/auditoutbox"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/keyexpiry"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/logging"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/reconcile"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/retention"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/server"
 )
 
@@ -85,6 +94,9 @@ func main() {
 		Logger:      logger,
 		ServiceName: cfg.ServiceName + "-reconciler",
 		Readiness:   runtime.ReadinessProbe,
+		EnableDebug: cfg.DebugEndpointsEnabled,
+		DrainDelay:  cfg.ShutdownDrainDelay,
+		PreStopAddr: cfg.PreStopAddr,
 	})
 
 	go func() {
@@ -93,13 +105,86 @@ func main() {
 		}
 	}()
 
-	// Placeholder worker loop - to be replaced with reconciliation job processing.
-	go runWorker(ctx, logger, runtime)
+	// Declarative orgs: detect drift from their documents and apply approved change sets
+	if cfg.ReconcilerDesiredStateDir != "" && runtime.Postgres != nil {
+		worker := reconcile.NewWorker(reconcile.Config{
+			Store:    runtime.Postgres,
+			Source:   reconcile.DirSource{Dir: cfg.ReconcilerDesiredStateDir},
+			Audit:    runtime.Audit,
+			Notifier: reconcile.NewNotifier(cfg.DriftWebhookURL, logger),
+			Logger:   logger,
+			Interval: cfg.ReconcilerInterval,
+		})
+		go worker.Run(ctx)
+	} else {
+		logger.Info("declarative reconciliation disabled (RECONCILER_DESIRED_STATE_DIR not set)")
+	}
+
+	// API key expiry reminders and enforcement
+	if cfg.KeyExpiryWorkerEnabled && runtime.Postgres != nil {
+		worker := keyexpiry.NewWorker(keyexpiry.Config{
+			Store:     runtime.Postgres,
+			Audit:     runtime.Audit,
+			Notifier:  keyexpiry.NewNotifier(cfg.KeyExpiryWebhookURL, logger),
+			Logger:    logger,
+			Interval:  cfg.KeyExpiryInterval,
+			LeadTimes: cfg.KeyExpiryReminderLeadTimes,
+			BatchSize: cfg.KeyExpiryBatchSize,
+		})
+		go worker.Run(ctx)
+	}
+
+	// Purge accounts whose self-service deletion grace period has ended
+	if cfg.AccountDeletionWorkerEnabled && runtime.Postgres != nil {
+		workerCfg := accountdeletion.Config{
+			Store:     runtime.Postgres,
+			Audit:     runtime.Audit,
+			Logger:    logger,
+			Interval:  cfg.AccountDeletionInterval,
+			BatchSize: cfg.AccountDeletionBatchSize,
+		}
+		if runtime.OAuthStore != nil {
+			workerCfg.Sessions = runtime.OAuthStore
+		}
+		go accountdeletion.NewWorker(workerCfg).Run(ctx)
+	}
+
+	// Anonymize or purge rows soft-deleted longer than the retention period
+	if cfg.RetentionWorkerEnabled && runtime.Postgres != nil {
+		mode, err := retention.ParseMode(cfg.RetentionMode)
+		if err != nil {
+			logger.Fatal("invalid retention configuration", zap.Error(err))
+		}
+		go retention.NewWorker(retention.Config{
+			Store:     runtime.Postgres,
+			Audit:     runtime.Audit,
+			Logger:    logger,
+			Period:    cfg.RetentionPeriod,
+			Mode:      mode,
+			DryRun:    cfg.RetentionDryRun,
+			Interval:  cfg.RetentionInterval,
+			BatchSize: cfg.RetentionBatchSize,
+		}).Run(ctx)
+	}
+
+	// Publish audit events recorded in the transactional outbox
+	if cfg.AuditOutboxEnabled && runtime.Postgres != nil {
+		relay := auditoutbox.NewRelay(auditoutbox.Config{
+			Store:      runtime.Postgres,
+			Sink:       runtime.AuditSink,
+			Logger:     logger,
+			Interval:   cfg.AuditOutboxRelayInterval,
+			BatchSize:  cfg.AuditOutboxBatchSize,
+			MaxBackoff: cfg.AuditOutboxMaxBackoff,
+			Retention:  cfg.AuditOutboxRetention,
+		})
+		go relay.Run(ctx)
+	}
 
 	<-ctx.Done()
 	stop()
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+10*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
@@ -109,10 +194,3 @@ func main() {
 
 	logger.Info("reconciler stopped")
 }
-
-func runWorker(ctx context.Context, logger *zap.Logger, rt *bootstrap.Runtime) {
-	logger.Info("reconciler worker started (stub)")
-	// TODO: Use rt.Postgres, rt.OAuth2Provider, etc. for reconciliation logic
-	<-ctx.Done()
-	logger.Info("reconciler worker stopping")
-}
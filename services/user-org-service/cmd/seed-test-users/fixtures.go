@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// markerKey is the org/user/service account metadata key (and API key
+// annotation) identifying seeded data for --teardown.
+const markerKey = "seed_marker"
+
+// fixtureNamespace seeds deterministic IDs so re-running a profile with the
+// same marker yields the same IDs and API key secrets.
+var fixtureNamespace = uuid.NewSHA1(uuid.NameSpaceOID, []byte("ai-aas/seed-test-users"))
+
+// loadTestScopes are granted to load-test API keys.
+var loadTestScopes = []string{"inference:read"}
+
+type fixtureUser struct {
+	Email       string
+	Password    string
+	DisplayName string
+	Roles       []string
+}
+
+type fixtureOrg struct {
+	Slug    string
+	Name    string
+	Users   []fixtureUser
+	APIKeys int
+}
+
+// loadTestOptions sizes the load-test profile.
+type loadTestOptions struct {
+	Orgs        int
+	UsersPerOrg int
+	KeysPerOrg  int
+}
+
+// profiles lists the named fixture sets.
+var profiles = []string{"minimal", "demo", "analytics-demo", "load-test"}
+
+// buildProfile returns the fixture set for a named profile.
+func buildProfile(name string, opts loadTestOptions) ([]fixtureOrg, error) {
+	system := fixtureOrg{Slug: "system", Name: "System", Users: []fixtureUser{
+		{"sys-admin@example.com", "SysAdmin2024!SecurePass", "System Administrator", []string{"system_admin"}},
+	}}
+	acmeAdmin := fixtureUser{"admin@example-acme.com", "AcmeAdmin2024!Secure", "Acme Admin", []string{"admin"}}
+
+	switch name {
+	case "minimal":
+		return []fixtureOrg{
+			system,
+			{Slug: "acme-ltd", Name: "Acme Ltd", Users: []fixtureUser{acmeAdmin}},
+		}, nil
+	case "demo", "analytics-demo":
+		orgs := []fixtureOrg{
+			system,
+			{Slug: "acme-ltd", Name: "Acme Ltd", Users: []fixtureUser{
+				acmeAdmin,
+				{"manager@example-acme.com", "AcmeManager2024!Secure", "Acme Manager", []string{"manager"}},
+			}},
+			{Slug: "joeblogs-ltd", Name: "JoeBlogs Ltd", Users: []fixtureUser{
+				{"admin@example-joeblogs.com", "JoeBlogsAdmin2024!Secure", "JoeBlogs Admin", []string{"admin"}},
+				{"manager@example-joeblogs.com", "JoeBlogsManager2024!Secure", "JoeBlogs Manager", []string{"manager"}},
+			}},
+		}
+		if name == "analytics-demo" {
+			// Keys for analytics seed-usage to attribute demo traffic to.
+			orgs[1].APIKeys = 3
+			orgs[2].APIKeys = 2
+		}
+		return orgs, nil
+	case "load-test":
+		if opts.Orgs < 1 || opts.UsersPerOrg < 0 || opts.KeysPerOrg < 0 {
+			return nil, fmt.Errorf("load-test needs --orgs >= 1 and non-negative --users/--keys")
+		}
+		orgs := make([]fixtureOrg, 0, opts.Orgs)
+		for i := 1; i <= opts.Orgs; i++ {
+			org := fixtureOrg{
+				Slug:    fmt.Sprintf("load-test-%03d", i),
+				Name:    fmt.Sprintf("Load Test %03d", i),
+				APIKeys: opts.KeysPerOrg,
+			}
+			for j := 1; j <= opts.UsersPerOrg; j++ {
+				role := "member"
+				if j == 1 {
+					role = "admin"
+				}
+				org.Users = append(org.Users, fixtureUser{
+					Email:       fmt.Sprintf("user%03d@load-test-%03d.example.com", j, i),
+					Password:    "LoadTest2024!Secure",
+					DisplayName: fmt.Sprintf("Load Test User %03d", j),
+					Roles:       []string{role},
+				})
+			}
+			orgs = append(orgs, org)
+		}
+		return orgs, nil
+	}
+	return nil, fmt.Errorf("unknown profile %q (want one of %v)", name, profiles)
+}
+
+// Seed results, printed as JSON for test harnesses.
+type seedOutput struct {
+	Profile string      `json:"profile"`
+	Marker  string      `json:"marker"`
+	Orgs    []seededOrg `json:"orgs"`
+}
+
+type seededOrg struct {
+	ID               uuid.UUID      `json:"id"`
+	Slug             string         `json:"slug"`
+	Name             string         `json:"name"`
+	Users            []seededUser   `json:"users"`
+	ServiceAccountID *uuid.UUID     `json:"serviceAccountId,omitempty"`
+	APIKeys          []seededAPIKey `json:"apiKeys,omitempty"`
+}
+
+type seededUser struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	Password string    `json:"password"`
+	Roles    []string  `json:"roles"`
+}
+
+type seededAPIKey struct {
+	ID     uuid.UUID `json:"id"`
+	Secret string    `json:"secret"`
+	Scopes []string  `json:"scopes"`
+}
+
+// seeder creates fixtures, skipping anything that already exists unless forced.
+type seeder struct {
+	store  *postgres.Store
+	marker string
+	force  bool
+	log    io.Writer
+	hashes map[string]string // password -> hash; Argon2id is slow for load-test sizes
+}
+
+// fixtureID derives a stable ID for a seeded resource.
+func (s *seeder) fixtureID(kind, name string) uuid.UUID {
+	return uuid.NewSHA1(fixtureNamespace, []byte(s.marker+"/"+kind+"/"+name))
+}
+
+// fixtureSecret derives a stable API key secret. Development fixtures only.
+func (s *seeder) fixtureSecret(name string) string {
+	sum := sha256.Sum256([]byte("seed-test-users/" + s.marker + "/" + name))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (s *seeder) metadata(extra map[string]any) map[string]any {
+	md := map[string]any{markerKey: s.marker}
+	for k, v := range extra {
+		md[k] = v
+	}
+	return md
+}
+
+func (s *seeder) seedOrg(ctx context.Context, fx fixtureOrg) (seededOrg, error) {
+	orgID, err := s.ensureOrg(ctx, fx.Slug, fx.Name)
+	if err != nil {
+		return seededOrg{}, fmt.Errorf("seed org %s: %w", fx.Slug, err)
+	}
+	fmt.Fprintf(s.log, "  ✓ Organization: %s (ID: %s)\n", fx.Name, orgID)
+	out := seededOrg{ID: orgID, Slug: fx.Slug, Name: fx.Name, Users: []seededUser{}}
+
+	for _, u := range fx.Users {
+		userID, err := s.ensureUser(ctx, orgID, u)
+		if err != nil {
+			return seededOrg{}, fmt.Errorf("seed user %s: %w", u.Email, err)
+		}
+		fmt.Fprintf(s.log, "  ✓ %s: %s (ID: %s)\n", u.DisplayName, u.Email, userID)
+		out.Users = append(out.Users, seededUser{ID: userID, Email: u.Email, Password: u.Password, Roles: u.Roles})
+	}
+
+	if fx.APIKeys > 0 {
+		saID, err := s.ensureServiceAccount(ctx, orgID, fx.Slug)
+		if err != nil {
+			return seededOrg{}, fmt.Errorf("seed service account for %s: %w", fx.Slug, err)
+		}
+		out.ServiceAccountID = &saID
+		for i := 1; i <= fx.APIKeys; i++ {
+			key, err := s.ensureAPIKey(ctx, orgID, saID, fmt.Sprintf("%s/key-%03d", fx.Slug, i))
+			if err != nil {
+				return seededOrg{}, fmt.Errorf("seed api key for %s: %w", fx.Slug, err)
+			}
+			out.APIKeys = append(out.APIKeys, key)
+		}
+		fmt.Fprintf(s.log, "  ✓ %d API keys on service account %s\n", fx.APIKeys, saID)
+	}
+	return out, nil
+}
+
+func (s *seeder) ensureOrg(ctx context.Context, slug, name string) (uuid.UUID, error) {
+	// Check if org exists
+	existing, err := s.store.GetOrgBySlug(ctx, slug)
+	if err == nil && !s.force {
+		return existing.ID, nil
+	}
+
+	org, err := s.store.CreateOrg(ctx, postgres.CreateOrgParams{
+		ID:       s.fixtureID("org", slug),
+		Slug:     slug,
+		Name:     name,
+		Status:   "active",
+		Metadata: s.metadata(nil),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("create org: %w", err)
+	}
+	return org.ID, nil
+}
+
+func (s *seeder) ensureUser(ctx context.Context, orgID uuid.UUID, u fixtureUser) (uuid.UUID, error) {
+	// Check if user exists
+	existing, err := s.store.GetUserByEmail(ctx, orgID, u.Email)
+	if err == nil && !s.force {
+		// TODO: Update user metadata (roles) if needed
+		return existing.ID, nil
+	}
+
+	passwordHash, ok := s.hashes[u.Password]
+	if !ok {
+		passwordHash, err = security.HashPassword(u.Password)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("hash password: %w", err)
+		}
+		s.hashes[u.Password] = passwordHash
+	}
+
+	user, err := s.store.CreateUser(ctx, postgres.CreateUserParams{
+		ID:             s.fixtureID("user", u.Email),
+		OrgID:          orgID,
+		Email:          u.Email,
+		DisplayName:    u.DisplayName,
+		PasswordHash:   passwordHash,
+		Status:         "active",
+		MFAEnrolled:    false,
+		MFAMethods:     []string{},
+		RecoveryTokens: []string{},
+		Metadata:       s.metadata(map[string]any{"roles": u.Roles}),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("create user: %w", err)
+	}
+	return user.ID, nil
+}
+
+func (s *seeder) ensureServiceAccount(ctx context.Context, orgID uuid.UUID, slug string) (uuid.UUID, error) {
+	id := s.fixtureID("service-account", slug)
+	if existing, err := s.store.GetServiceAccountByID(ctx, id); err == nil {
+		return existing.ID, nil
+	}
+
+	sa, err := s.store.CreateServiceAccount(ctx, postgres.CreateServiceAccountParams{
+		ID:       id,
+		OrgID:    orgID,
+		Name:     "load-test",
+		Status:   "active",
+		Metadata: s.metadata(nil),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("create service account: %w", err)
+	}
+	return sa.ID, nil
+}
+
+func (s *seeder) ensureAPIKey(ctx context.Context, orgID, serviceAccountID uuid.UUID, name string) (seededAPIKey, error) {
+	id := s.fixtureID("api-key", name)
+	secret := s.fixtureSecret(name)
+	out := seededAPIKey{ID: id, Secret: secret, Scopes: loadTestScopes}
+	if _, err := s.store.GetAPIKeyByID(ctx, id); err == nil {
+		return out, nil
+	}
+
+	// Fingerprint matches the API key handlers (SHA-256 of the secret, base64url)
+	fingerprint := sha256.Sum256([]byte(secret))
+	_, err := s.store.CreateAPIKey(ctx, postgres.CreateAPIKeyParams{
+		ID:            id,
+		OrgID:         orgID,
+		PrincipalType: postgres.PrincipalTypeServiceAccount,
+		PrincipalID:   serviceAccountID,
+		Fingerprint:   base64.RawURLEncoding.EncodeToString(fingerprint[:]),
+		Status:        "active",
+		Scopes:        loadTestScopes,
+		Annotations:   s.metadata(map[string]any{"display_name": name}),
+	})
+	if err != nil {
+		return seededAPIKey{}, fmt.Errorf("create api key: %w", err)
+	}
+	return out, nil
+}
@@ -1,167 +1,123 @@
 // Command seed-test-users creates test users and organizations for development/testing.
 //
-// This utility creates:
-// - System Admin user (no org, stored in metadata)
-// - Acme Ltd organization with admin and manager users
-// - JoeBlogs Ltd organization with admin and manager users
+// Profiles (--profile):
+// - minimal: System Admin user plus Acme Ltd with an admin user
+// - demo (default): System Admin user, Acme Ltd and JoeBlogs Ltd each with admin and manager users
+// - analytics-demo: demo plus API keys on Acme Ltd and JoeBlogs Ltd for analytics seed-usage
+// - load-test: --orgs organizations, each with --users users and --keys service-account API keys
 //
-// Roles are stored in user metadata as {"roles": ["role_name"]}
+// Roles are stored in user metadata as {"roles": ["role_name"]}. Every seeded
+// org, user, service account and API key is tagged with {"seed_marker": <marker>};
+// IDs and API key secrets are derived from the marker, so re-running a profile
+// is deterministic. --teardown removes every org tagged with the marker along
+// with its data. --json writes created IDs and credentials for test harnesses.
 package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
-	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
 func main() {
-	force := flag.Bool("force", false, "Force re-seed even if org/user exists")
+	var (
+		force       = flag.Bool("force", false, "Force re-seed even if org/user exists")
+		profile     = flag.String("profile", "demo", "Fixture profile: "+strings.Join(profiles, ", "))
+		marker      = flag.String("marker", "seed-test-users", "Marker recorded in metadata of seeded data; selects data for --teardown")
+		teardown    = flag.Bool("teardown", false, "Delete all orgs (and their users, service accounts and keys) seeded with --marker")
+		jsonPath    = flag.String("json", "", "Write created IDs as JSON to this path (- for stdout)")
+		orgs        = flag.Int("orgs", 10, "load-test: number of organizations")
+		usersPerOrg = flag.Int("users", 5, "load-test: users per organization")
+		keysPerOrg  = flag.Int("keys", 2, "load-test: API keys per organization")
+		timeout     = flag.Duration("timeout", 5*time.Minute, "Overall database timeout")
+	)
 	flag.Parse()
 
+	// Keep stdout clean for JSON when writing it there.
+	var out io.Writer = os.Stdout
+	if *jsonPath == "-" {
+		out = os.Stderr
+	}
+
+	if *marker == "" {
+		log.Fatal("--marker must not be empty")
+	}
+	var fixtures []fixtureOrg
+	if !*teardown {
+		var err error
+		fixtures, err = buildProfile(*profile, loadTestOptions{Orgs: *orgs, UsersPerOrg: *usersPerOrg, KeysPerOrg: *keysPerOrg})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	cfg := config.MustLoad()
 	if cfg.DatabaseURL == "" {
 		log.Fatal("DATABASE_URL must be set")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	store, err := postgres.NewStore(ctx, cfg.DatabaseURL)
+	store, err := postgres.NewStoreWithConfig(ctx, cfg.DatabaseURL, cfg.PoolConfig())
 	if err != nil {
 		log.Fatalf("create store: %v", err)
 	}
 	defer store.Close()
 
-	// System Admin (no org, but we need to create a system org for it)
-	fmt.Println("Creating System Admin...")
-	systemOrgID, err := seedOrg(ctx, store, "system", "System", *force)
-	if err != nil {
-		log.Fatalf("seed system org: %v", err)
-	}
-	sysAdminID, err := seedUserWithRole(ctx, store, systemOrgID, "sys-admin@example.com", "SysAdmin2024!SecurePass", "System Administrator", []string{"system_admin"}, *force)
-	if err != nil {
-		log.Fatalf("seed system admin: %v", err)
-	}
-	fmt.Printf("  ✓ System Admin: sys-admin@example.com (ID: %s)\n", sysAdminID)
-
-	// Acme Ltd Organization
-	fmt.Println("\nCreating Acme Ltd organization...")
-	acmeOrgID, err := seedOrg(ctx, store, "acme-ltd", "Acme Ltd", *force)
-	if err != nil {
-		log.Fatalf("seed acme org: %v", err)
-	}
-	fmt.Printf("  ✓ Organization: Acme Ltd (ID: %s)\n", acmeOrgID)
-
-	// Acme Admin
-	acmeAdminID, err := seedUserWithRole(ctx, store, acmeOrgID, "admin@example-acme.com", "AcmeAdmin2024!Secure", "Acme Admin", []string{"admin"}, *force)
-	if err != nil {
-		log.Fatalf("seed acme admin: %v", err)
-	}
-	fmt.Printf("  ✓ Admin: admin@example-acme.com (ID: %s)\n", acmeAdminID)
-
-	// Acme Manager
-	acmeManagerID, err := seedUserWithRole(ctx, store, acmeOrgID, "manager@example-acme.com", "AcmeManager2024!Secure", "Acme Manager", []string{"manager"}, *force)
-	if err != nil {
-		log.Fatalf("seed acme manager: %v", err)
-	}
-	fmt.Printf("  ✓ Manager: manager@example-acme.com (ID: %s)\n", acmeManagerID)
-
-	// JoeBlogs Ltd Organization
-	fmt.Println("\nCreating JoeBlogs Ltd organization...")
-	joeblogsOrgID, err := seedOrg(ctx, store, "joeblogs-ltd", "JoeBlogs Ltd", *force)
-	if err != nil {
-		log.Fatalf("seed joeblogs org: %v", err)
-	}
-	fmt.Printf("  ✓ Organization: JoeBlogs Ltd (ID: %s)\n", joeblogsOrgID)
-
-	// JoeBlogs Admin
-	joeblogsAdminID, err := seedUserWithRole(ctx, store, joeblogsOrgID, "admin@example-joeblogs.com", "JoeBlogsAdmin2024!Secure", "JoeBlogs Admin", []string{"admin"}, *force)
-	if err != nil {
-		log.Fatalf("seed joeblogs admin: %v", err)
-	}
-	fmt.Printf("  ✓ Admin: admin@example-joeblogs.com (ID: %s)\n", joeblogsAdminID)
-
-	// JoeBlogs Manager
-	joeblogsManagerID, err := seedUserWithRole(ctx, store, joeblogsOrgID, "manager@example-joeblogs.com", "JoeBlogsManager2024!Secure", "JoeBlogs Manager", []string{"manager"}, *force)
-	if err != nil {
-		log.Fatalf("seed joeblogs manager: %v", err)
-	}
-	fmt.Printf("  ✓ Manager: manager@example-joeblogs.com (ID: %s)\n", joeblogsManagerID)
-
-	fmt.Println("\n✓ All test users seeded successfully!")
-	fmt.Println("\nSee seeded-users.md for credentials.")
-}
-
-func seedOrg(ctx context.Context, store *postgres.Store, slug, name string, force bool) (uuid.UUID, error) {
-	// Check if org exists
-	existing, err := store.GetOrgBySlug(ctx, slug)
-	if err == nil && !force {
-		return existing.ID, nil
-	}
-
-	orgID := uuid.New()
-	params := postgres.CreateOrgParams{
-		ID:     orgID,
-		Slug:   slug,
-		Name:   name,
-		Status: "active",
+	if *teardown {
+		purged, err := store.PurgeOrgsByMetadata(ctx, markerKey, *marker)
+		if err != nil {
+			log.Fatalf("teardown: %v", err)
+		}
+		fmt.Fprintf(out, "✓ Removed %d organizations seeded with marker %q\n", len(purged), *marker)
+		writeJSON(*jsonPath, map[string]any{"marker": *marker, "deletedOrgIds": purged})
+		return
+	}
+
+	s := &seeder{store: store, marker: *marker, force: *force, log: out, hashes: map[string]string{}}
+	result := seedOutput{Profile: *profile, Marker: *marker}
+	for _, fx := range fixtures {
+		fmt.Fprintf(out, "\nSeeding %s...\n", fx.Name)
+		org, err := s.seedOrg(ctx, fx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		result.Orgs = append(result.Orgs, org)
 	}
 
-	org, err := store.CreateOrg(ctx, params)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("create org: %w", err)
+	fmt.Fprintf(out, "\n✓ Profile %q seeded successfully (marker %q)!\n", *profile, *marker)
+	if *profile == "demo" || *profile == "analytics-demo" {
+		fmt.Fprintln(out, "\nSee seeded-users.md for credentials.")
 	}
-	return org.ID, nil
+	writeJSON(*jsonPath, result)
 }
 
-func seedUserWithRole(ctx context.Context, store *postgres.Store, orgID uuid.UUID, email, password, displayName string, roles []string, force bool) (uuid.UUID, error) {
-	// Check if user exists
-	existing, err := store.GetUserByEmail(ctx, orgID, email)
-	if err == nil && !force {
-		// Update roles if needed
-		if existing.Metadata == nil {
-			existing.Metadata = make(map[string]any)
-		}
-		existing.Metadata["roles"] = roles
-		// TODO: Update user metadata if needed
-		return existing.ID, nil
+// writeJSON writes v to path ("-" for stdout); an empty path is a no-op.
+func writeJSON(path string, v any) {
+	if path == "" {
+		return
 	}
-
-	passwordHash, err := security.HashPassword(password)
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("hash password: %w", err)
+		log.Fatalf("marshal json: %v", err)
 	}
-
-	userID := uuid.New()
-	metadata := map[string]any{
-		"roles": roles,
-	}
-
-	params := postgres.CreateUserParams{
-		ID:             userID,
-		OrgID:          orgID,
-		Email:          email,
-		DisplayName:    displayName,
-		PasswordHash:   passwordHash,
-		Status:         "active",
-		MFAEnrolled:    false,
-		MFAMethods:     []string{},
-		RecoveryTokens: []string{},
-		Metadata:       metadata,
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(path, data, 0o600)
 	}
-
-	user, err := store.CreateUser(ctx, params)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("create user: %w", err)
+		log.Fatalf("write json: %v", err)
 	}
-	return user.ID, nil
 }
-
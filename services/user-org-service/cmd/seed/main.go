@@ -70,7 +70,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	store, err := postgres.NewStore(ctx, cfg.DatabaseURL)
+	store, err := postgres.NewStoreWithConfig(ctx, cfg.DatabaseURL, cfg.PoolConfig())
 	if err != nil {
 		log.Fatalf("create store: %v", err)
 	}
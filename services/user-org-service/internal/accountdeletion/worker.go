@@ -0,0 +1,195 @@
+// Package accountdeletion purges user accounts whose self-service deletion
+// grace period has ended.
+//
+// Purpose:
+//
+//	POST /v1/users/me/deletion only schedules deletion, recording
+//	metadata["account_deletion"] on the user so they can export their data or
+//	cancel. The worker periodically finds accounts past purge_after and
+//	soft-deletes them, scrubbing personal data and revoking credentials.
+//
+// Dependencies:
+//   - internal/storage/postgres: Due-user scan, org lookup and DeleteUser
+//   - internal/audit: user.delete events
+//   - internal/metrics: Account deletion outcome counter
+//
+// Key Responsibilities:
+//   - Worker.Run: Scan on an interval until the context is cancelled
+//   - Worker.RunOnce: One pass; purge every due account
+//
+// Debugging Notes:
+//   - An account that became its org's billing owner after scheduling is not
+//     purged (outcome "blocked") until ownership is transferred away
+//   - DeleteUser revokes the user's API keys and sessions rows in the same
+//     transaction; OAuth tokens are revoked afterwards through SessionRevoker
+//   - Optimistic lock conflicts (user changed concurrently, e.g. cancelled)
+//     are skipped; the next pass re-reads the schedule
+//
+// Thread Safety:
+//   - Run must only be called once per Worker; RunOnce is not reentrant
+//
+// Error Handling:
+//   - Per-user failures are logged and never stop the pass
+//   - RunOnce returns an error only when the scan query fails
+package accountdeletion
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// Store is the subset of postgres.Store the worker needs.
+type Store interface {
+	ListUsersDueForDeletion(ctx context.Context, before time.Time, limit int) ([]postgres.User, error)
+	GetOrg(ctx context.Context, id uuid.UUID) (postgres.Org, error)
+	DeleteUser(ctx context.Context, orgID, userID uuid.UUID, version int64) (int, error)
+}
+
+// SessionRevoker revokes a user's OAuth tokens (implemented by oauth.Store).
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// Config configures the worker.
+type Config struct {
+	Store    Store
+	Sessions SessionRevoker // Optional
+	Audit    audit.Emitter
+	Logger   *zap.Logger
+	// Interval between scans.
+	Interval time.Duration
+	// BatchSize caps accounts purged per scan.
+	BatchSize int
+}
+
+// Worker purges accounts whose deletion grace period has ended.
+type Worker struct {
+	store     Store
+	sessions  SessionRevoker
+	audit     audit.Emitter
+	logger    *zap.Logger
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+// NewWorker creates a worker from cfg, applying defaults for unset fields.
+func NewWorker(cfg Config) *Worker {
+	w := &Worker{
+		store:     cfg.Store,
+		sessions:  cfg.Sessions,
+		audit:     cfg.Audit,
+		logger:    cfg.Logger,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	if w.logger == nil {
+		w.logger = zap.NewNop()
+	}
+	if w.audit == nil {
+		w.audit = audit.NewNoopEmitter()
+	}
+	if w.interval <= 0 {
+		w.interval = time.Hour
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = 100
+	}
+	return w
+}
+
+// Run scans immediately and then every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("account deletion worker started", zap.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("account deletion scan failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			w.logger.Info("account deletion worker stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single scan.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	users, err := w.store.ListUsersDueForDeletion(ctx, w.now(), w.batchSize)
+	if err != nil {
+		return err
+	}
+
+	var purged int
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if w.purge(ctx, user) {
+			purged++
+		}
+	}
+	if len(users) > 0 {
+		w.logger.Info("account deletion scan complete",
+			zap.Int("due", len(users)),
+			zap.Int("purged", purged))
+	}
+	return nil
+}
+
+// purge deletes one due account and reports whether it did.
+func (w *Worker) purge(ctx context.Context, user postgres.User) bool {
+	org, err := w.store.GetOrg(ctx, user.OrgID)
+	if err != nil {
+		w.logger.Warn("failed to get organization for account deletion", zap.Error(err), zap.String("user_id", user.ID.String()))
+		return false
+	}
+	if org.BillingOwnerUserID != nil && *org.BillingOwnerUserID == user.ID {
+		// Deleting the billing owner would orphan the org's billing
+		metrics.RecordAccountDeletion("blocked")
+		w.logger.Warn("account deletion blocked: user is the org's billing owner",
+			zap.String("user_id", user.ID.String()), zap.String("org_id", org.ID.String()))
+		return false
+	}
+
+	revokedKeys, err := w.store.DeleteUser(ctx, user.OrgID, user.ID, user.Version)
+	if err != nil {
+		if !errors.Is(err, postgres.ErrOptimisticLock) {
+			metrics.RecordAccountDeletion("failed")
+			w.logger.Warn("failed to delete account", zap.Error(err), zap.String("user_id", user.ID.String()))
+		}
+		return false
+	}
+	metrics.RecordAccountDeletion("purged")
+
+	revokedSessions := 0
+	if w.sessions != nil {
+		if revokedSessions, err = w.sessions.RevokeUserSessions(ctx, user.ID); err != nil {
+			w.logger.Warn("failed to revoke sessions for deleted account", zap.Error(err), zap.String("user_id", user.ID.String()))
+		}
+	}
+
+	event := audit.BuildEvent(user.OrgID, user.ID, audit.ActorTypeSystem, audit.ActionUserDelete, audit.TargetTypeUser, &user.ID)
+	event.Metadata = map[string]any{
+		"reason":           "self_service",
+		"api_keys_revoked": revokedKeys,
+		"sessions_revoked": revokedSessions,
+	}
+	if schedule, ok := user.Metadata["account_deletion"].(map[string]any); ok {
+		event.Metadata["requested_at"] = schedule["requested_at"]
+	}
+	_ = w.audit.Emit(ctx, event)
+	return true
+}
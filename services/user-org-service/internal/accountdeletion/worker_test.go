@@ -0,0 +1,84 @@
+package accountdeletion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type fakeStore struct {
+	users   []postgres.User
+	orgs    map[uuid.UUID]postgres.Org
+	deleted []uuid.UUID
+	before  time.Time
+}
+
+func (s *fakeStore) ListUsersDueForDeletion(_ context.Context, before time.Time, _ int) ([]postgres.User, error) {
+	s.before = before
+	return s.users, nil
+}
+
+func (s *fakeStore) GetOrg(_ context.Context, id uuid.UUID) (postgres.Org, error) {
+	org, ok := s.orgs[id]
+	if !ok {
+		return postgres.Org{}, postgres.ErrNotFound
+	}
+	return org, nil
+}
+
+func (s *fakeStore) DeleteUser(_ context.Context, _, userID uuid.UUID, version int64) (int, error) {
+	if version != 1 {
+		return 0, postgres.ErrOptimisticLock
+	}
+	s.deleted = append(s.deleted, userID)
+	return 2, nil
+}
+
+type recordingRevoker struct {
+	revoked []uuid.UUID
+}
+
+func (r *recordingRevoker) RevokeUserSessions(_ context.Context, userID uuid.UUID) (int, error) {
+	r.revoked = append(r.revoked, userID)
+	return 1, nil
+}
+
+func TestRunOncePurgesDueAccounts(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	orgID := uuid.New()
+	user := postgres.User{ID: uuid.New(), OrgID: orgID, Version: 1}
+	store := &fakeStore{
+		users: []postgres.User{user},
+		orgs:  map[uuid.UUID]postgres.Org{orgID: {ID: orgID}},
+	}
+	revoker := &recordingRevoker{}
+
+	w := NewWorker(Config{Store: store, Sessions: revoker})
+	w.now = func() time.Time { return now }
+	require.NoError(t, w.RunOnce(context.Background()))
+
+	require.Equal(t, now, store.before)
+	require.Equal(t, []uuid.UUID{user.ID}, store.deleted)
+	require.Equal(t, []uuid.UUID{user.ID}, revoker.revoked)
+}
+
+func TestRunOnceSkipsBillingOwnersAndConflicts(t *testing.T) {
+	orgID := uuid.New()
+	owner := postgres.User{ID: uuid.New(), OrgID: orgID, Version: 1}
+	changed := postgres.User{ID: uuid.New(), OrgID: orgID, Version: 2}
+	store := &fakeStore{
+		users: []postgres.User{owner, changed},
+		orgs:  map[uuid.UUID]postgres.Org{orgID: {ID: orgID, BillingOwnerUserID: &owner.ID}},
+	}
+	revoker := &recordingRevoker{}
+
+	require.NoError(t, NewWorker(Config{Store: store, Sessions: revoker}).RunOnce(context.Background()))
+
+	require.Empty(t, store.deleted)
+	require.Empty(t, revoker.revoked)
+}
@@ -311,24 +311,66 @@ func getClientIP(r *http.Request) string {
 
 // Common action constants for consistency.
 const (
-	ActionOrgCreate        = "org.create"
-	ActionOrgUpdate        = "org.update"
-	ActionOrgSuspend       = "org.suspend"
-	ActionUserInvite       = "user.invite"
-	ActionUserCreate       = "user.create"
-	ActionUserUpdate       = "user.update"
-	ActionUserSuspend      = "user.suspend"
-	ActionUserActivate     = "user.activate"
-	ActionUserDelete       = "user.delete"
-	ActionRoleAssign       = "role.assign"
-	ActionRoleRevoke       = "role.revoke"
-	ActionAPIKeyIssue      = "api_key.issue"
-	ActionAPIKeyRevoke     = "api_key.revoke"
-	ActionAccountLockout   = "account.lockout"
-	ActionRecoveryInitiate = "recovery.initiate"
-	ActionRecoveryApprove  = "recovery.approve"
-	ActionRecoveryReject   = "recovery.reject"
-	ActionRecoveryComplete = "recovery.complete"
+	ActionOrgCreate           = "org.create"
+	ActionOrgUpdate           = "org.update"
+	ActionOrgSuspend          = "org.suspend"
+	ActionOrgOnboard          = "org.onboard"
+	ActionOrgSignup           = "org.signup"
+	ActionOrgActivate         = "org.activate"
+	ActionOrgTransferRequest  = "org.ownership_transfer_request"
+	ActionOrgTransferConfirm  = "org.ownership_transfer_confirm"
+	ActionOrgTransferComplete = "org.ownership_transfer_complete"
+	ActionOrgTransferCancel   = "org.ownership_transfer_cancel"
+	ActionOrgExport           = "org.export"
+	ActionOrgDriftDetect      = "org.drift_detect"
+	ActionOrgDriftApply       = "org.drift_apply"
+	ActionOrgDriftApprove     = "org.drift_approve"
+	ActionOrgDriftReject      = "org.drift_reject"
+	ActionUserInvite          = "user.invite"
+	ActionUserCreate          = "user.create"
+	ActionUserUpdate          = "user.update"
+	ActionUserSuspend         = "user.suspend"
+	ActionUserActivate        = "user.activate"
+	ActionUserVerifyEmail     = "user.verify_email"
+	ActionUserDelete          = "user.delete"
+	ActionUserDeleteSchedule  = "user.delete_schedule"
+	ActionUserDeleteCancel    = "user.delete_cancel"
+	ActionUserExport          = "user.export"
+	ActionRoleAssign          = "role.assign"
+	ActionRoleRevoke          = "role.revoke"
+	ActionAPIKeyIssue         = "api_key.issue"
+	ActionAPIKeyRevoke        = "api_key.revoke"
+	ActionAPIKeyExpire        = "api_key.expire"
+	ActionAPIKeyRemind        = "api_key.expiry_reminder"
+	ActionAPIKeyRestrict      = "api_key.restrict"
+	ActionEntitlementSet      = "entitlements.set"
+	ActionAccountLockout      = "account.lockout"
+	ActionRecoveryInitiate    = "recovery.initiate"
+	ActionRecoveryApprove     = "recovery.approve"
+	ActionRecoveryReject      = "recovery.reject"
+	ActionRecoveryComplete    = "recovery.complete"
+	ActionDeviceApprove       = "device.approve"
+	ActionDeviceDeny          = "device.deny"
+	ActionMagicLinkRequest    = "magic_link.request"
+	ActionMagicLinkLogin      = "magic_link.login"
+)
+
+// OAuth client management action constants.
+const (
+	ActionOAuthClientCreate       = "oauth_client.create"
+	ActionOAuthClientUpdate       = "oauth_client.update"
+	ActionOAuthClientDelete       = "oauth_client.delete"
+	ActionOAuthClientRotateSecret = "oauth_client.rotate_secret"
+)
+
+// JWT signing key lifecycle action constants.
+const (
+	ActionSigningKeyRotate = "signing_key.rotate"
+	ActionSigningKeyRetire = "signing_key.retire"
+)
+const (
+	ActionRetentionAnonymize = "retention.anonymize"
+	ActionRetentionPurge     = "retention.purge"
 )
 
 // Common target type constants.
@@ -337,6 +379,10 @@ const (
 	TargetTypeUser   = "user"
 	TargetTypeRole   = "role"
 	TargetTypeAPIKey = "api_key"
+	// TargetTypeOAuthClient events carry the client ID in metadata["client_id"].
+	TargetTypeOAuthClient = "oauth_client"
+	// TargetTypeSigningKey events carry the key ID in metadata["kid"].
+	TargetTypeSigningKey = "signing_key"
 )
 
 // Common actor type constants.
@@ -0,0 +1,53 @@
+package auditoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// Writer records outbox rows (implemented by postgres.Store).
+type Writer interface {
+	InsertAuditOutbox(ctx context.Context, params postgres.InsertAuditOutboxParams) error
+}
+
+// Emitter is an audit.Emitter that writes events to the outbox for the
+// Relay to publish. Emit inside postgres.Store.InTx to make the event part
+// of the mutation's transaction.
+type Emitter struct {
+	store Writer
+}
+
+// NewEmitter creates an outbox emitter.
+func NewEmitter(store Writer) *Emitter {
+	return &Emitter{store: store}
+}
+
+// Emit records the event in the outbox.
+func (e *Emitter) Emit(ctx context.Context, event audit.Event) error {
+	if event.EventID == uuid.Nil {
+		event.EventID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("serialize audit event: %w", err)
+	}
+	if err := e.store.InsertAuditOutbox(ctx, postgres.InsertAuditOutboxParams{
+		EventID: event.EventID,
+		OrgID:   event.OrgID,
+		Action:  event.Action,
+		Payload: payload,
+	}); err != nil {
+		return fmt.Errorf("write audit outbox: %w", err)
+	}
+	return nil
+}
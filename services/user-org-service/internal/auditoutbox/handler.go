@@ -0,0 +1,181 @@
+package auditoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// AdminStore is the subset of postgres.Store the admin endpoints need.
+type AdminStore interface {
+	ListAuditOutbox(ctx context.Context, filter postgres.AuditOutboxFilter) ([]postgres.AuditOutboxEntry, error)
+	ReplayAuditOutbox(ctx context.Context, filter postgres.AuditOutboxFilter, now time.Time) (int64, error)
+}
+
+// Entry is an outbox row in admin responses.
+type Entry struct {
+	ID            int64           `json:"id"`
+	EventID       string          `json:"eventId"`
+	OrgID         string          `json:"orgId"`
+	Action        string          `json:"action"`
+	Attempts      int             `json:"attempts"`
+	LastError     *string         `json:"lastError,omitempty"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	DeliveredAt   *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Event         json.RawMessage `json:"event"`
+}
+
+// ListResponse is the body of GET /v1/admin/audit-outbox.
+type ListResponse struct {
+	Entries []Entry `json:"entries"`
+}
+
+// ReplayRequest selects delivered events to publish again. At least one of
+// since or eventIds is required.
+type ReplayRequest struct {
+	OrgID    string      `json:"orgId,omitempty"`
+	Action   string      `json:"action,omitempty"`
+	EventIDs []uuid.UUID `json:"eventIds,omitempty"`
+	Since    time.Time   `json:"since"`
+	Until    time.Time   `json:"until"`
+}
+
+// ReplayResponse reports how many events were queued for the relay.
+type ReplayResponse struct {
+	Replayed int64 `json:"replayed"`
+}
+
+// ListHandler serves GET /v1/admin/audit-outbox. Optional query parameters:
+// orgId, action, status (pending or delivered), since and until (RFC 3339)
+// and limit (default 100, max 1000).
+func ListHandler(store AdminStore, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := postgres.AuditOutboxFilter{Action: query.Get("action"), Limit: defaultListLimit}
+		if raw := query.Get("orgId"); raw != "" {
+			orgID, err := uuid.Parse(raw)
+			if err != nil {
+				http.Error(w, "invalid orgId", http.StatusBadRequest)
+				return
+			}
+			filter.OrgID = &orgID
+		}
+		switch query.Get("status") {
+		case "":
+		case "pending":
+			pending := true
+			filter.Pending = &pending
+		case "delivered":
+			pending := false
+			filter.Pending = &pending
+		default:
+			http.Error(w, "status must be pending or delivered", http.StatusBadRequest)
+			return
+		}
+		for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+			if raw := query.Get(name); raw != "" {
+				ts, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					http.Error(w, name+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+					return
+				}
+				*dst = ts
+			}
+		}
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxListLimit {
+				http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+				return
+			}
+			filter.Limit = limit
+		}
+
+		rows, err := store.ListAuditOutbox(r.Context(), filter)
+		if err != nil {
+			logger.Error("failed to list audit outbox", zap.Error(err))
+			http.Error(w, "failed to list audit outbox", http.StatusInternalServerError)
+			return
+		}
+		resp := ListResponse{Entries: make([]Entry, 0, len(rows))}
+		for _, row := range rows {
+			resp.Entries = append(resp.Entries, Entry{
+				ID:            row.ID,
+				EventID:       row.EventID.String(),
+				OrgID:         row.OrgID.String(),
+				Action:        row.Action,
+				Attempts:      row.Attempts,
+				LastError:     row.LastError,
+				NextAttemptAt: row.NextAttemptAt,
+				DeliveredAt:   row.DeliveredAt,
+				CreatedAt:     row.CreatedAt,
+				Event:         row.Payload,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// ReplayHandler serves POST /v1/admin/audit-outbox:replay. Matching
+// delivered events are queued and published again by the relay, e.g. to
+// refill a sink that lost data.
+func ReplayHandler(store AdminStore, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ReplayRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if req.Since.IsZero() && len(req.EventIDs) == 0 {
+			http.Error(w, "since or eventIds is required", http.StatusBadRequest)
+			return
+		}
+		filter := postgres.AuditOutboxFilter{
+			Action:   req.Action,
+			EventIDs: req.EventIDs,
+			Since:    req.Since,
+			Until:    req.Until,
+		}
+		if req.OrgID != "" {
+			orgID, err := uuid.Parse(req.OrgID)
+			if err != nil {
+				http.Error(w, "invalid orgId", http.StatusBadRequest)
+				return
+			}
+			filter.OrgID = &orgID
+		}
+
+		replayed, err := store.ReplayAuditOutbox(r.Context(), filter, time.Now().UTC())
+		if err != nil {
+			logger.Error("failed to replay audit outbox", zap.Error(err))
+			http.Error(w, "failed to replay audit outbox", http.StatusInternalServerError)
+			return
+		}
+		metrics.RecordAuditOutbox("replayed", int(replayed))
+		logger.Warn("audit events queued for replay",
+			zap.Int64("replayed", replayed),
+			zap.String("org_id", req.OrgID),
+			zap.String("action", req.Action),
+			zap.Int("event_ids", len(req.EventIDs)),
+			zap.Time("since", req.Since),
+			zap.Time("until", req.Until))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReplayResponse{Replayed: replayed})
+	}
+}
@@ -0,0 +1,250 @@
+// Package auditoutbox makes audit events durable with a transactional outbox.
+//
+// Purpose:
+//
+//	Handlers used to publish audit events to Kafka after their mutation
+//	committed, so a crash or broker outage in between lost the event.
+//	With AUDIT_OUTBOX_ENABLED the runtime's audit emitter is an Emitter that
+//	inserts the event into audit_outbox instead; called inside
+//	postgres.Store.InTx the row commits or rolls back with the mutation. The
+//	Relay, run by the reconciler, publishes pending rows to the configured
+//	audit sink (Kafka, or the logger) and marks them delivered.
+//
+// Dependencies:
+//   - internal/storage/postgres: audit_outbox rows (claim, mark, replay, purge)
+//   - internal/audit: Event schema and the sink emitters
+//   - internal/metrics: Outbox result counter and delivery lag
+//
+// Key Responsibilities:
+//   - Emitter: audit.Emitter that writes to the outbox
+//   - Relay.Run: Publish on an interval until the context is cancelled
+//   - Relay.RunOnce: One pass; publish every due row in the batch
+//   - ListHandler/ReplayHandler: Inspect rows and re-publish delivered ones
+//
+// Debugging Notes:
+//   - Delivery is at least once: a relay that dies after publishing but
+//     before marking re-publishes once the lease ends. Consumers deduplicate
+//     on event_id
+//   - Failed publishes back off exponentially (attempts, last_error and
+//     next_attempt_at on the row) up to AUDIT_OUTBOX_MAX_BACKOFF; later rows
+//     are not held back, so consumers order by created_at
+//   - Delivered rows are kept for AUDIT_OUTBOX_RETENTION so they can be
+//     replayed with POST /v1/admin/audit-outbox:replay
+//   - audit_outbox has row level security on app.org_id; the relay, list,
+//     replay and purge read across orgs as the audit_outbox_relay role, so a
+//     "permission denied to set role" means the service's database role was
+//     not granted it
+//
+// Thread Safety:
+//   - Relays in several processes are safe: rows are claimed with
+//     FOR UPDATE SKIP LOCKED and leased
+//   - Run must only be called once per Relay; RunOnce is not reentrant
+//
+// Error Handling:
+//   - Per-row publish failures are recorded on the row and never stop the pass
+//   - RunOnce returns an error only when claiming or marking rows fails
+package auditoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// purgeInterval is how often the relay deletes rows past their retention.
+const purgeInterval = time.Hour
+
+// Store is the subset of postgres.Store the relay needs.
+type Store interface {
+	ClaimAuditOutbox(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]postgres.AuditOutboxEntry, error)
+	MarkAuditOutboxDelivered(ctx context.Context, ids []int64, deliveredAt time.Time) error
+	MarkAuditOutboxFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	PurgeAuditOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Config configures the relay.
+type Config struct {
+	Store Store
+	// Sink publishes events, e.g. audit.KafkaEmitter.
+	Sink   audit.Emitter
+	Logger *zap.Logger
+	// Interval between passes while the outbox is drained.
+	Interval time.Duration
+	// BatchSize caps rows claimed per pass.
+	BatchSize int
+	// Lease is how long a claimed row is hidden from other relays.
+	Lease time.Duration
+	// MaxBackoff caps the retry delay of a failing row.
+	MaxBackoff time.Duration
+	// Retention is how long delivered rows are kept for replay; zero keeps them.
+	Retention time.Duration
+}
+
+// Relay publishes outbox rows to the audit sink.
+type Relay struct {
+	store      Store
+	sink       audit.Emitter
+	logger     *zap.Logger
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+	maxBackoff time.Duration
+	retention  time.Duration
+	now        func() time.Time
+	lastPurge  time.Time
+}
+
+// NewRelay creates a relay from cfg, applying defaults for unset fields.
+func NewRelay(cfg Config) *Relay {
+	r := &Relay{
+		store:      cfg.Store,
+		sink:       cfg.Sink,
+		logger:     cfg.Logger,
+		interval:   cfg.Interval,
+		batchSize:  cfg.BatchSize,
+		lease:      cfg.Lease,
+		maxBackoff: cfg.MaxBackoff,
+		retention:  cfg.Retention,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+	if r.logger == nil {
+		r.logger = zap.NewNop()
+	}
+	if r.sink == nil {
+		r.sink = audit.NewLoggerEmitter(r.logger)
+	}
+	if r.interval <= 0 {
+		r.interval = time.Second
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 100
+	}
+	if r.lease <= 0 {
+		r.lease = time.Minute
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = 10 * time.Minute
+	}
+	return r
+}
+
+// Run publishes immediately and then every interval until ctx is cancelled.
+// A full batch is followed straight away by the next one, so a backlog is
+// drained without waiting for the ticker.
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Info("audit outbox relay started",
+		zap.Duration("interval", r.interval),
+		zap.Int("batch_size", r.batchSize))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		claimed, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("audit outbox relay pass failed", zap.Error(err))
+		}
+		if err == nil && claimed == r.batchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			r.logger.Info("audit outbox relay stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single pass and returns how many rows it claimed.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	r.purge(ctx)
+
+	entries, err := r.store.ClaimAuditOutbox(ctx, r.now(), r.batchSize, r.lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim audit outbox: %w", err)
+	}
+
+	var delivered []int64
+	var failed int
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			// Unpublished rows become due again when their lease ends
+			break
+		}
+		if err := r.publish(ctx, entry); err != nil {
+			failed++
+			retryAt := r.now().Add(r.backoff(entry.Attempts))
+			if markErr := r.store.MarkAuditOutboxFailed(ctx, entry.ID, err.Error(), retryAt); markErr != nil {
+				r.logger.Warn("failed to record audit outbox failure", zap.Error(markErr), zap.Int64("id", entry.ID))
+			}
+			r.logger.Warn("failed to publish audit event",
+				zap.Error(err),
+				zap.String("event_id", entry.EventID.String()),
+				zap.Int("attempts", entry.Attempts),
+				zap.Time("retry_at", retryAt))
+			continue
+		}
+		delivered = append(delivered, entry.ID)
+		metrics.AuditOutboxLagSeconds.Observe(r.now().Sub(entry.CreatedAt).Seconds())
+	}
+
+	metrics.RecordAuditOutbox("failed", failed)
+	if len(delivered) > 0 {
+		if err := r.store.MarkAuditOutboxDelivered(ctx, delivered, r.now()); err != nil {
+			// Published but not marked: they are published again after the lease
+			return len(entries), fmt.Errorf("mark audit outbox delivered: %w", err)
+		}
+		metrics.RecordAuditOutbox("delivered", len(delivered))
+	}
+	if len(entries) > 0 {
+		r.logger.Debug("audit outbox relay pass complete",
+			zap.Int("claimed", len(entries)),
+			zap.Int("delivered", len(delivered)),
+			zap.Int("failed", failed))
+	}
+	return len(entries), nil
+}
+
+// publish sends one row's event to the sink.
+func (r *Relay) publish(ctx context.Context, entry postgres.AuditOutboxEntry) error {
+	var event audit.Event
+	if err := json.Unmarshal(entry.Payload, &event); err != nil {
+		return fmt.Errorf("decode audit event: %w", err)
+	}
+	deliveredAt := r.now()
+	event.DeliveredAt = &deliveredAt
+	return r.sink.Emit(ctx, event)
+}
+
+// backoff is the retry delay after the given number of attempts: 1s doubling
+// up to maxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.maxBackoff)
+}
+
+// purge deletes delivered rows past the retention, at most once per purgeInterval.
+func (r *Relay) purge(ctx context.Context) {
+	now := r.now()
+	if r.retention <= 0 || now.Sub(r.lastPurge) < purgeInterval {
+		return
+	}
+	r.lastPurge = now
+	purged, err := r.store.PurgeAuditOutbox(ctx, now.Add(-r.retention))
+	if err != nil {
+		r.logger.Warn("failed to purge audit outbox", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		r.logger.Info("purged delivered audit outbox rows", zap.Int64("count", purged))
+	}
+}
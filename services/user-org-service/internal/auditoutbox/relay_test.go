@@ -0,0 +1,127 @@
+package auditoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type fakeStore struct {
+	entries   []postgres.AuditOutboxEntry
+	delivered []int64
+	failed    map[int64]time.Time
+	inserted  []postgres.InsertAuditOutboxParams
+	purged    time.Time
+}
+
+func (s *fakeStore) InsertAuditOutbox(_ context.Context, params postgres.InsertAuditOutboxParams) error {
+	s.inserted = append(s.inserted, params)
+	return nil
+}
+
+func (s *fakeStore) ClaimAuditOutbox(_ context.Context, _ time.Time, limit int, _ time.Duration) ([]postgres.AuditOutboxEntry, error) {
+	n := min(limit, len(s.entries))
+	claimed := s.entries[:n]
+	s.entries = s.entries[n:]
+	return claimed, nil
+}
+
+func (s *fakeStore) MarkAuditOutboxDelivered(_ context.Context, ids []int64, _ time.Time) error {
+	s.delivered = append(s.delivered, ids...)
+	return nil
+}
+
+func (s *fakeStore) MarkAuditOutboxFailed(_ context.Context, id int64, _ string, nextAttemptAt time.Time) error {
+	if s.failed == nil {
+		s.failed = map[int64]time.Time{}
+	}
+	s.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (s *fakeStore) PurgeAuditOutbox(_ context.Context, before time.Time) (int64, error) {
+	s.purged = before
+	return 0, nil
+}
+
+type recordingSink struct {
+	events []audit.Event
+	fail   map[uuid.UUID]bool
+}
+
+func (s *recordingSink) Emit(_ context.Context, event audit.Event) error {
+	if s.fail[event.EventID] {
+		return errors.New("broker unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestEmitterWritesEventToOutbox(t *testing.T) {
+	store := &fakeStore{}
+	event := audit.BuildEvent(uuid.New(), uuid.New(), audit.ActorTypeUser, audit.ActionAPIKeyIssue, audit.TargetTypeAPIKey, nil)
+
+	require.NoError(t, NewEmitter(store).Emit(context.Background(), event))
+
+	require.Len(t, store.inserted, 1)
+	require.Equal(t, event.EventID, store.inserted[0].EventID)
+	require.Equal(t, event.OrgID, store.inserted[0].OrgID)
+	require.Equal(t, audit.ActionAPIKeyIssue, store.inserted[0].Action)
+	var decoded audit.Event
+	require.NoError(t, json.Unmarshal(store.inserted[0].Payload, &decoded))
+	require.Equal(t, event.EventID, decoded.EventID)
+}
+
+func TestRunOncePublishesAndMarksDelivered(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	ok := entry(t, 1, now)
+	broken := entry(t, 2, now)
+	broken.Attempts = 3
+	store := &fakeStore{entries: []postgres.AuditOutboxEntry{ok, broken}}
+	sink := &recordingSink{fail: map[uuid.UUID]bool{broken.EventID: true}}
+
+	r := NewRelay(Config{Store: store, Sink: sink, Retention: 24 * time.Hour})
+	r.now = func() time.Time { return now }
+	claimed, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, 2, claimed)
+	require.Equal(t, []int64{1}, store.delivered)
+	require.Len(t, sink.events, 1)
+	require.Equal(t, ok.EventID, sink.events[0].EventID)
+	require.NotNil(t, sink.events[0].DeliveredAt)
+	// Third attempt failed: retried after 1s doubled twice
+	require.Equal(t, now.Add(4*time.Second), store.failed[2])
+	require.Equal(t, now.Add(-24*time.Hour), store.purged)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	r := NewRelay(Config{MaxBackoff: time.Minute})
+	require.Equal(t, time.Second, r.backoff(1))
+	require.Equal(t, 32*time.Second, r.backoff(6))
+	require.Equal(t, time.Minute, r.backoff(40))
+}
+
+func entry(t *testing.T, id int64, createdAt time.Time) postgres.AuditOutboxEntry {
+	t.Helper()
+	event := audit.BuildEvent(uuid.New(), uuid.New(), audit.ActorTypeSystem, audit.ActionUserDelete, audit.TargetTypeUser, nil)
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	return postgres.AuditOutboxEntry{
+		ID:        id,
+		EventID:   event.EventID,
+		OrgID:     event.OrgID,
+		Action:    event.Action,
+		Payload:   payload,
+		Attempts:  1,
+		CreatedAt: createdAt,
+	}
+}
@@ -17,6 +17,11 @@
 //
 // Key Responsibilities:
 //   - Initialize connects to Postgres and optional Redis, composes OAuth provider
+//   - Optional read replicas (DATABASE_REPLICA_URLS) serve lag-tolerant lookups
+//   - Optional column encryption (COLUMN_ENCRYPTION_KEYS[_FILE]) protects MFA
+//     secrets and recovery tokens at rest
+//   - Security event monitor (auth anomaly detection) when Redis is configured
+//   - Org resolver caches {orgId} UUID/slug lookups (shared through Redis when configured)
 //   - Runtime bundles all initialized dependencies for use by binaries
 //   - ReadinessProbe checks health of Postgres and Redis connections
 //   - Close releases all resources in reverse initialization order
@@ -27,9 +32,11 @@
 //   - specs/005-user-org-service/spec.md#NFR-003 (Session Management)
 //
 // Debugging Notes:
-//   - Redis connection failures fail fast during initialization (2s timeout)
-//   - If Redis is unavailable, a no-op cache is used (graceful degradation)
-//   - Postgres connection failures prevent service startup (required dependency)
+//   - Postgres and Redis are retried with exponential backoff until their max wait
+//     (STARTUP_POSTGRES_MAX_WAIT, STARTUP_REDIS_MAX_WAIT); each attempt times out after 2s
+//   - Postgres is always required; Redis is required unless listed in
+//     STARTUP_OPTIONAL_DEPENDENCIES, in which case a no-op cache is used and the
+//     runtime records it in Degraded
 //   - OAuth provider composition requires valid HMAC secret (minimum 32 bytes)
 //   - ReadinessProbe is used by Kubernetes liveness/readiness checks
 //
@@ -46,17 +53,25 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ory/fosite"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/dataaccess/pgreplica"
+
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/auditoutbox"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/columncrypt"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/logging"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/orgresolver"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/signingkeys"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
@@ -69,24 +84,71 @@ type Runtime struct {
 	OAuthStore     *oauth.Store             // OAuth2 storage implementation (backed by Postgres + optional Redis cache)
 	OAuthCache     oauth.SessionCache       // Session cache implementation (Redis or no-op)
 	OAuthConfig    *fosite.Config           // Fosite OAuth2 configuration (token lifetimes, PKCE settings, etc.)
+	DeviceFlow     *oauth.DeviceFlow        // Device authorization grant (nil when DEVICE_FLOW_ENABLED=false)
+	KeySet         *oauth.KeySet            // JWT access token signing keys (nil when ACCESS_TOKEN_FORMAT=opaque)
+	Issuer         string                   // Token issuer and OIDC discovery base URL
 	Provider       fosite.OAuth2Provider    // Composed OAuth2 provider ready for use in HTTP handlers
-	Audit          audit.Emitter            // Audit event emitter (logger-based stub, replace with Kafka in production)
+	Audit          audit.Emitter            // Audit event emitter (outbox when AUDIT_OUTBOX_ENABLED, otherwise AuditSink)
+	AuditSink      audit.Emitter            // Publishes audit events (Kafka if configured, otherwise logger); the outbox relay's target
 	LockoutTracker *security.LockoutTracker // Lockout tracker for failed authentication attempts (optional, nil if Redis not configured)
+	SecurityEvents *securityevents.Monitor  // Auth anomaly detection and security event stream (optional, nil if Redis not configured)
+	OrgResolver    *orgresolver.Resolver    // Cached org UUID/slug resolution for {orgId} path parameters (Redis-backed when configured)
+	Degraded       []string                 // Optional dependencies that were unavailable at startup (see STARTUP_OPTIONAL_DEPENDENCIES)
 	// Note: IdPRegistry is initialized separately in main.go to avoid import cycles
 	// It should be set after bootstrap initialization
+
+	stopKeyRotation context.CancelFunc
 }
 
 // Initialize wires core dependencies based on the provided configuration.
 // Initialization order: Postgres → Redis (if configured) → OAuth store → OAuth provider.
-// Returns an error if any required dependency fails to initialize.
+// Postgres and Redis are retried with exponential backoff for up to
+// STARTUP_POSTGRES_MAX_WAIT and STARTUP_REDIS_MAX_WAIT. Returns an error if a
+// required dependency is still unavailable; optional ones are recorded in
+// Runtime.Degraded instead.
 // The returned Runtime must be closed via Close() during shutdown.
 func Initialize(ctx context.Context, cfg *config.Config) (*Runtime, error) {
-	pgStore, err := postgres.NewStore(ctx, cfg.DatabaseURL)
+	poolCfg := cfg.PoolConfig()
+	logger := logging.New(cfg.ServiceName, cfg.LogLevel)
+
+	pgStore, err := postgres.NewStoreWithConfig(ctx, cfg.DatabaseURL, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("bootstrap postgres: %w", err)
 	}
+	// The pool connects lazily, so wait here for the database to accept
+	// connections (e.g. while compose is still starting it)
+	if err := waitFor(ctx, logger, DependencyPostgres, startupPolicy(cfg, cfg.StartupPostgresMaxWait), func(ctx context.Context) error {
+		return pgStore.Pool().Ping(ctx)
+	}); err != nil {
+		pgStore.Close()
+		return nil, fmt.Errorf("bootstrap postgres: %w", err)
+	}
 
-	logger := logging.New(cfg.ServiceName, cfg.LogLevel)
+	if len(cfg.DatabaseReplicaURLs) > 0 {
+		replicas, err := pgreplica.New(ctx, pgStore.Pool(), cfg.DatabaseReplicaURLs, pgreplica.Options{
+			Store:  "user-org",
+			MaxLag: cfg.DatabaseReplicaMaxLag,
+			Pool:   poolCfg,
+			Logger: logger,
+		})
+		if err != nil {
+			pgStore.Close()
+			return nil, fmt.Errorf("bootstrap postgres replicas: %w", err)
+		}
+		replicas.Start(context.Background())
+		pgStore.UseReplicas(replicas)
+		logger.Info("read replicas configured", zap.Int("count", len(cfg.DatabaseReplicaURLs)))
+	}
+
+	columnKeys, err := columncrypt.Load(cfg.ColumnEncryptionKeys, cfg.ColumnEncryptionKeysFile, cfg.ColumnEncryptionActiveKey)
+	if err != nil {
+		pgStore.Close()
+		return nil, fmt.Errorf("bootstrap column encryption: %w", err)
+	}
+	if columnKeys != nil {
+		pgStore.UseColumnEncryption(columnKeys)
+		logger.Info("column encryption enabled", zap.String("active_key", columnKeys.ActiveKey()))
+	}
 
 	// Initialize audit emitter (Kafka if configured, otherwise logger)
 	var auditEmitter audit.Emitter
@@ -102,26 +164,50 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Runtime, error) {
 	}
 
 	runtime := &Runtime{
-		Config:   cfg,
-		Postgres: pgStore,
-		Audit:    auditEmitter,
+		Config:    cfg,
+		Postgres:  pgStore,
+		Audit:     auditEmitter,
+		AuditSink: auditEmitter,
+	}
+	if cfg.AuditOutboxEnabled {
+		// Handlers then record events in their mutation's transaction; the
+		// reconciler's relay publishes them to AuditSink
+		logger.Info("audit outbox enabled")
+		runtime.Audit = auditoutbox.NewEmitter(pgStore)
 	}
 
 	if cfg.RedisAddr != "" {
-		runtime.Redis = redis.NewClient(&redis.Options{
+		client := redis.NewClient(&redis.Options{
 			Addr:     cfg.RedisAddr,
 			Password: cfg.RedisPassword,
 			DB:       cfg.RedisDB,
 		})
-
-		// Best-effort ping with timeout to fail fast if Redis is unavailable.
-		pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
-		defer cancel()
-		if err := runtime.Redis.Ping(pingCtx).Err(); err != nil {
+		err := waitFor(ctx, logger, DependencyRedis, startupPolicy(cfg, cfg.StartupRedisMaxWait), func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		})
+		switch {
+		case err == nil:
+			runtime.Redis = client
+		case optionalDependency(cfg, DependencyRedis):
+			_ = client.Close()
+			runtime.Degraded = append(runtime.Degraded, DependencyRedis)
+			logger.Warn("starting without optional dependency; session caching, lockout tracking and security events are disabled",
+				zap.String("dependency", DependencyRedis), zap.Error(err))
+		default:
+			_ = client.Close()
+			_ = runtime.Close(ctx)
 			return nil, fmt.Errorf("bootstrap redis: %w", err)
 		}
 	}
 
+	runtime.OrgResolver = orgresolver.New(orgresolver.Config{
+		Store:       pgStore,
+		Redis:       runtime.Redis,
+		Logger:      logger,
+		TTL:         cfg.OrgResolverCacheTTL,
+		NegativeTTL: cfg.OrgResolverNegativeTTL,
+	})
+
 	var sessionCache oauth.SessionCache
 	if runtime.Redis != nil {
 		sessionCache = oauth.NewRedisSessionCache(runtime.Redis, "user-org-service")
@@ -141,16 +227,57 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Runtime, error) {
 		runtime.LockoutTracker = security.NewLockoutTracker(runtime.Redis, lockoutCfg)
 	}
 
+	if runtime.Redis != nil && cfg.SecurityEventsEnabled {
+		monitor, err := newSecurityMonitor(cfg, runtime.Redis, logger)
+		if err != nil {
+			return nil, fmt.Errorf("bootstrap security events: %w", err)
+		}
+		runtime.SecurityEvents = monitor
+	}
+
+	staticClients := []oauth.StaticClient{{ID: cfg.OAuthClientID, Secret: cfg.OAuthClientSecret}}
+	if cfg.DeviceFlowEnabled {
+		var deviceStore oauth.DeviceStore
+		if runtime.Redis != nil {
+			deviceStore = oauth.NewRedisDeviceStore(runtime.Redis, "user-org-service")
+		} else {
+			deviceStore = oauth.NewMemoryDeviceStore()
+		}
+		runtime.DeviceFlow = oauth.NewDeviceFlow(oauth.DeviceFlowConfig{
+			Store:           deviceStore,
+			VerificationURI: cfg.DeviceVerificationURL,
+			Lifespan:        cfg.DeviceCodeTTL,
+			Interval:        cfg.DevicePollInterval,
+		})
+		staticClients = append(staticClients, oauth.StaticClient{
+			ID:         cfg.DeviceClientID,
+			Public:     true,
+			GrantTypes: []string{oauth.GrantTypeDeviceCode, "refresh_token"},
+			Scopes:     []string{"openid", "profile", "email", "offline_access", cfg.AdminScope},
+		})
+	}
+
+	runtime.Issuer = issuerURL(cfg)
+	runtime.OAuthConfig = oauth.DefaultConfig()
+	if !strings.EqualFold(cfg.AccessTokenFormat, "opaque") {
+		if err := runtime.initKeySet(ctx, cfg, logger); err != nil {
+			return nil, fmt.Errorf("bootstrap signing keys: %w", err)
+		}
+	}
+
 	provider, err := oauth.NewProvider(oauth.ProviderDependencies{
 		PostgresStore: pgStore,
 		SessionCache:  sessionCache,
 		HMACSecret:    []byte(cfg.OAuthHMACSecret),
-		StaticClients: []oauth.StaticClient{{ID: cfg.OAuthClientID, Secret: cfg.OAuthClientSecret}},
+		StaticClients: staticClients,
+		DeviceFlow:    runtime.DeviceFlow,
+		Config:        runtime.OAuthConfig,
+		KeySet:        runtime.KeySet,
+		Issuer:        runtime.Issuer,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("bootstrap provider: %w", err)
 	}
-	runtime.OAuthConfig = oauthStore.Config()
 	runtime.Provider = provider
 
 	// Note: IdP registry initialization moved to main.go to avoid import cycles
@@ -159,6 +286,111 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Runtime, error) {
 	return runtime, nil
 }
 
+// initKeySet loads the JWT signing keys from JWT_SIGNING_KEY_FILES, or from
+// the signing key manager when JWT_KEY_ENCRYPTION_KEY is set, or generates a
+// key and rotates it every JWT_KEY_ROTATION_INTERVAL.
+func (rt *Runtime) initKeySet(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
+	if len(cfg.JWTSigningKeyFiles) > 0 {
+		keySet, err := oauth.LoadKeySet(cfg.JWTSigningKeyFiles)
+		if err != nil {
+			return err
+		}
+		rt.KeySet = keySet
+		return nil
+	}
+
+	if cfg.JWTKeyEncryptionKey != "" && rt.Postgres != nil {
+		encryptionKey, err := signingkeys.ParseEncryptionKey(cfg.JWTKeyEncryptionKey)
+		if err != nil {
+			return err
+		}
+		manager, err := signingkeys.NewManager(signingkeys.Config{
+			Store:            rt.Postgres,
+			EncryptionKey:    encryptionKey,
+			Audit:            rt.Audit,
+			Logger:           logger,
+			RotationInterval: cfg.JWTKeyRotationInterval,
+			GracePeriod:      cfg.JWTKeyGracePeriod,
+			SyncInterval:     cfg.JWTKeySyncInterval,
+		})
+		if err != nil {
+			return err
+		}
+		// Load (or create) the keys before the provider issues any token
+		if err := manager.RunOnce(ctx); err != nil {
+			return err
+		}
+		rt.KeySet = manager.KeySet()
+		syncCtx, cancel := context.WithCancel(context.Background())
+		rt.stopKeyRotation = cancel
+		go manager.Run(syncCtx)
+		return nil
+	}
+
+	keySet, err := oauth.GenerateKeySet()
+	if err != nil {
+		return err
+	}
+	rt.KeySet = keySet
+	logger.Warn("neither JWT_SIGNING_KEY_FILES nor JWT_KEY_ENCRYPTION_KEY set, using a generated signing key; tokens are only valid on this instance")
+
+	// Retired keys verify until the access tokens they signed have expired,
+	// with an hour of slack for clock skew and JWKS caching
+	retain := rt.OAuthConfig.GetAccessTokenLifespan(context.Background()) + time.Hour
+	rotationCtx, cancel := context.WithCancel(context.Background())
+	rt.stopKeyRotation = cancel
+	go keySet.RotateEvery(rotationCtx, cfg.JWTKeyRotationInterval, retain, func(err error) {
+		logger.Error("failed to rotate JWT signing key", zap.Error(err))
+	})
+	return nil
+}
+
+// issuerURL returns the configured token issuer.
+func issuerURL(cfg *config.Config) string {
+	switch {
+	case cfg.JWTIssuer != "":
+		return strings.TrimRight(cfg.JWTIssuer, "/")
+	case cfg.OIDCBaseURL != "":
+		return strings.TrimRight(cfg.OIDCBaseURL, "/")
+	default:
+		return fmt.Sprintf("http://localhost:%d", cfg.HTTPPort)
+	}
+}
+
+// newSecurityMonitor builds the security event monitor with its geo table
+// and publishers: Kafka when brokers are configured (otherwise the log), plus
+// the SIEM webhook when set.
+func newSecurityMonitor(cfg *config.Config, client *redis.Client, logger *zap.Logger) (*securityevents.Monitor, error) {
+	monitorCfg := securityevents.Config{
+		Redis:               client,
+		Logger:              logger,
+		Retention:           cfg.SecurityEventsRetention,
+		LockoutThreshold:    cfg.SecurityEventsLockoutThreshold,
+		LockoutWindow:       cfg.SecurityEventsLockoutWindow,
+		MaxTravelKMH:        cfg.SecurityEventsMaxTravelKMH,
+		RevocationThreshold: cfg.SecurityEventsRevocationThreshold,
+		RevocationWindow:    cfg.SecurityEventsRevocationWindow,
+	}
+	if cfg.SecurityEventsGeoIPFile != "" {
+		table, err := securityevents.LoadGeoTable(cfg.SecurityEventsGeoIPFile)
+		if err != nil {
+			return nil, err
+		}
+		monitorCfg.Geo = table
+	} else {
+		logger.Info("SECURITY_EVENTS_GEOIP_FILE not set, impossible-travel and new-ASN detection disabled")
+	}
+	if kafkaPublisher := securityevents.NewKafkaPublisher(cfg.KafkaBrokers, cfg.SecurityEventsTopic, cfg.KafkaClientID); kafkaPublisher != nil {
+		monitorCfg.Publishers = append(monitorCfg.Publishers, kafkaPublisher)
+	} else {
+		monitorCfg.Publishers = append(monitorCfg.Publishers, securityevents.LogPublisher{Logger: logger})
+	}
+	if webhook := securityevents.NewWebhookPublisher(cfg.SecurityEventsWebhookURL); webhook != nil {
+		monitorCfg.Publishers = append(monitorCfg.Publishers, webhook)
+	}
+	return securityevents.New(monitorCfg), nil
+}
+
 // Close releases runtime resources in reverse initialization order.
 // Safe to call multiple times (idempotent). Returns the first error encountered,
 // but continues closing other resources. Postgres pool, Redis connections, and
@@ -168,6 +400,13 @@ func (rt *Runtime) Close(ctx context.Context) error {
 		return nil
 	}
 	var firstErr error
+	if rt.stopKeyRotation != nil {
+		rt.stopKeyRotation()
+	}
+	// Flush pending security events while Redis and Kafka are still open
+	if err := rt.SecurityEvents.Close(); err != nil {
+		firstErr = err
+	}
 	if rt.Postgres != nil {
 		rt.Postgres.Close()
 	}
@@ -177,7 +416,7 @@ func (rt *Runtime) Close(ctx context.Context) error {
 		}
 	}
 	// Close Kafka emitter if it's a KafkaEmitter
-	if kafkaEmitter, ok := rt.Audit.(*audit.KafkaEmitter); ok {
+	if kafkaEmitter, ok := rt.AuditSink.(*audit.KafkaEmitter); ok {
 		if err := kafkaEmitter.Close(); err != nil && firstErr == nil {
 			firstErr = err
 		}
@@ -185,6 +424,18 @@ func (rt *Runtime) Close(ctx context.Context) error {
 	return firstErr
 }
 
+// EmitAuditInTx emits event from inside a postgres.Store.InTx callback. With
+// the audit outbox enabled the event is written in that transaction and an
+// error must roll the mutation back; otherwise emission stays best-effort
+// and never fails the transaction.
+func (rt *Runtime) EmitAuditInTx(ctx context.Context, event audit.Event) error {
+	err := rt.Audit.Emit(ctx, event)
+	if rt.Config.AuditOutboxEnabled {
+		return err
+	}
+	return nil
+}
+
 // ReadinessProbe checks the health of critical runtime dependencies.
 // Used by Kubernetes readiness checks and /readyz endpoint. Returns an error
 // if Postgres or Redis (if configured) are unreachable. Context timeout should
@@ -0,0 +1,99 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+)
+
+// Dependency names accepted by STARTUP_OPTIONAL_DEPENDENCIES.
+const (
+	DependencyPostgres = "postgres"
+	DependencyRedis    = "redis"
+)
+
+// probeTimeout bounds a single connection attempt.
+const probeTimeout = 2 * time.Second
+
+// retryPolicy controls how long startup waits for one dependency.
+type retryPolicy struct {
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxWait        time.Duration
+}
+
+// startupPolicy returns the retry policy for a dependency waiting at most maxWait.
+func startupPolicy(cfg *config.Config, maxWait time.Duration) retryPolicy {
+	return retryPolicy{
+		initialBackoff: cfg.StartupRetryInitialBackoff,
+		maxBackoff:     cfg.StartupRetryMaxBackoff,
+		maxWait:        maxWait,
+	}
+}
+
+// waitFor calls probe until it succeeds, ctx ends or maxWait elapses,
+// doubling the delay between attempts (with jitter) up to maxBackoff. It
+// returns the last probe error once it gives up.
+func waitFor(ctx context.Context, logger *zap.Logger, name string, policy retryPolicy, probe func(context.Context) error) error {
+	start := time.Now()
+	deadline := start.Add(policy.maxWait)
+	backoff := policy.initialBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("dependency available", zap.String("dependency", name),
+					zap.Int("attempts", attempt), zap.Duration("waited", time.Since(start)))
+			}
+			return nil
+		}
+
+		// Sleep for a random duration in [backoff/2, backoff) so replicas
+		// restarted together do not retry in lockstep
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if remaining := time.Until(deadline); remaining <= 0 {
+			return fmt.Errorf("%s unavailable after %d attempts in %s: %w", name, attempt, time.Since(start).Round(time.Millisecond), err)
+		} else if delay > remaining {
+			delay = remaining
+		}
+		logger.Warn("dependency not ready, retrying", zap.String("dependency", name),
+			zap.Int("attempt", attempt), zap.Duration("retry_in", delay), zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+		if policy.maxBackoff > 0 && backoff > policy.maxBackoff {
+			backoff = policy.maxBackoff
+		}
+	}
+}
+
+// optionalDependency reports whether startup may continue without name.
+// Postgres is always required.
+func optionalDependency(cfg *config.Config, name string) bool {
+	if name == DependencyPostgres {
+		return false
+	}
+	for _, dep := range cfg.StartupOptionalDependencies {
+		if strings.EqualFold(strings.TrimSpace(dep), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+)
+
+func TestWaitForRetriesUntilAvailable(t *testing.T) {
+	policy := retryPolicy{initialBackoff: time.Millisecond, maxBackoff: 4 * time.Millisecond, maxWait: time.Second}
+	attempts := 0
+	err := waitFor(context.Background(), zap.NewNop(), "postgres", policy, func(context.Context) error {
+		attempts++
+		if attempts < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("waitFor: %v", err)
+	}
+	if attempts != 4 {
+		t.Fatalf("expected 4 attempts, got %d", attempts)
+	}
+}
+
+func TestWaitForGivesUpAfterMaxWait(t *testing.T) {
+	policy := retryPolicy{initialBackoff: 5 * time.Millisecond, maxBackoff: 10 * time.Millisecond, maxWait: 30 * time.Millisecond}
+	refused := errors.New("connection refused")
+	start := time.Now()
+	err := waitFor(context.Background(), zap.NewNop(), "redis", policy, func(context.Context) error {
+		return refused
+	})
+	if !errors.Is(err, refused) {
+		t.Fatalf("expected the last probe error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waitFor exceeded its max wait: %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	policy.maxWait = time.Minute
+	if err := waitFor(ctx, zap.NewNop(), "redis", policy, func(context.Context) error { return refused }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOptionalDependency(t *testing.T) {
+	cfg := &config.Config{StartupOptionalDependencies: []string{" Redis ", "postgres"}}
+	if !optionalDependency(cfg, DependencyRedis) {
+		t.Fatalf("expected redis to be optional")
+	}
+	if optionalDependency(cfg, DependencyPostgres) {
+		t.Fatalf("postgres must always be required")
+	}
+	if optionalDependency(&config.Config{}, DependencyRedis) {
+		t.Fatalf("redis must be required by default")
+	}
+}
@@ -0,0 +1,206 @@
+// Package columncrypt encrypts sensitive database columns in the application.
+//
+// Purpose:
+//
+//	Columns such as users.mfa_secret and users.recovery_tokens hold secrets
+//	that a database dump or replica snapshot must not reveal. A Keyring
+//	seals each value with AES-256-GCM before it is written and opens it after
+//	it is read, so Postgres only ever stores ciphertext.
+//
+// Dependencies:
+//   - Key material is provisioned by Vault or a KMS: a Vault Agent template or
+//     CSI secret renders COLUMN_ENCRYPTION_KEYS_FILE, or the keys are injected
+//     into COLUMN_ENCRYPTION_KEYS
+//
+// Key Responsibilities:
+//   - ParseKeys reads "id:base64-key" entries (comma or newline separated)
+//   - Encrypt seals with the active key; Decrypt opens with whichever key
+//     sealed the value
+//   - Current reports whether a value is sealed with the active key, which
+//     drives backfill and rotation (cmd/encrypt-columns)
+//
+// Debugging Notes:
+//   - Stored values look like "enc:v1:<key id>:<base64 nonce||ciphertext>"
+//   - Values without the prefix are legacy plaintext and are returned as-is
+//     until the backfill has rewritten them
+//   - The additional data binds a value to its column and row, so ciphertext
+//     copied to another user fails to decrypt
+//   - To rotate, add a new key, make it active, run the backfill, then remove
+//     the old key once no value uses it
+//
+// Thread Safety:
+//   - A Keyring is immutable after construction and safe for concurrent use
+//
+// Error Handling:
+//   - Decrypt fails on unknown key IDs, malformed envelopes and values that
+//     do not authenticate
+package columncrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// prefix marks sealed values; the version allows the format to change later.
+const prefix = "enc:v1:"
+
+// ErrUnknownKey is returned when a value was sealed with a key that is not
+// in the keyring.
+var ErrUnknownKey = errors.New("column encryption key not found")
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Keyring holds the column encryption keys by ID.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// New builds a keyring from 32-byte keys. active seals new values; every key
+// opens values it sealed. An empty active is allowed when there is one key.
+func New(keys map[string][]byte, active string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("column encryption: no keys configured")
+	}
+	if active == "" {
+		if len(keys) > 1 {
+			return nil, errors.New("column encryption: active key must be set when several keys are configured")
+		}
+		for id := range keys {
+			active = id
+		}
+	}
+	k := &Keyring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("column encryption: invalid key id %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("column encryption key %q must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("create cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("create GCM: %w", err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("column encryption: active key %q is not configured", active)
+	}
+	return k, nil
+}
+
+// ParseKeys decodes "id:base64-key" entries separated by commas or newlines.
+// Blank lines and lines starting with # are ignored.
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(spec, ",", "\n")))
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("column encryption key entry must be id:base64-key")
+		}
+		id = strings.TrimSpace(id)
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("column encryption key %q listed twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("decode column encryption key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, scanner.Err()
+}
+
+// Load builds a keyring from COLUMN_ENCRYPTION_KEYS_FILE when set, otherwise
+// from the inline COLUMN_ENCRYPTION_KEYS. It returns nil when neither is set.
+func Load(inline, file, active string) (*Keyring, error) {
+	spec := inline
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read column encryption keys: %w", err)
+		}
+		spec = string(data)
+	}
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	keys, err := ParseKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+	return New(keys, active)
+}
+
+// ActiveKey returns the ID of the key that seals new values.
+func (k *Keyring) ActiveKey() string {
+	return k.active
+}
+
+// Encrypt seals plaintext with the active key. aad names the column and row
+// the value belongs to and must be passed again to Decrypt.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return prefix + k.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Plaintext values (without the
+// envelope prefix) are returned unchanged.
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	body, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(body, ":")
+	if !ok {
+		return "", errors.New("malformed column encryption envelope")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed column encryption envelope")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("decrypt column value with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// Current reports whether value is sealed with the active key; plaintext and
+// values sealed with older keys need rewriting.
+func (k *Keyring) Current(value string) bool {
+	return strings.HasPrefix(value, prefix+k.active+":")
+}
+
+// Encrypted reports whether value carries the envelope prefix.
+func Encrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,96 @@
+package columncrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k, err := New(map[string][]byte{"k1": testKey(1)}, "")
+	require.NoError(t, err)
+	require.Equal(t, "k1", k.ActiveKey())
+
+	sealed, err := k.Encrypt("JBSWY3DPEHPK3PXP", "users.mfa_secret:u1")
+	require.NoError(t, err)
+	require.True(t, Encrypted(sealed))
+	require.True(t, k.Current(sealed))
+	require.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	opened, err := k.Decrypt(sealed, "users.mfa_secret:u1")
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", opened)
+
+	_, err = k.Decrypt(sealed, "users.mfa_secret:u2")
+	require.Error(t, err, "ciphertext must not decrypt for another row")
+
+	plain, err := k.Decrypt("legacy-plaintext", "users.mfa_secret:u1")
+	require.NoError(t, err)
+	require.Equal(t, "legacy-plaintext", plain)
+	require.False(t, k.Current("legacy-plaintext"))
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := New(map[string][]byte{"2026-01": testKey(1)}, "")
+	require.NoError(t, err)
+	sealed, err := old.Encrypt("token", "aad")
+	require.NoError(t, err)
+
+	rotated, err := New(map[string][]byte{"2026-01": testKey(1), "2026-10": testKey(2)}, "2026-10")
+	require.NoError(t, err)
+	require.False(t, rotated.Current(sealed))
+	opened, err := rotated.Decrypt(sealed, "aad")
+	require.NoError(t, err)
+	require.Equal(t, "token", opened)
+
+	resealed, err := rotated.Encrypt(opened, "aad")
+	require.NoError(t, err)
+	require.True(t, rotated.Current(resealed))
+
+	retired, err := New(map[string][]byte{"2026-10": testKey(2)}, "")
+	require.NoError(t, err)
+	_, err = retired.Decrypt(sealed, "aad")
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewValidatesKeys(t *testing.T) {
+	_, err := New(nil, "")
+	require.Error(t, err)
+	_, err = New(map[string][]byte{"a": testKey(1), "b": testKey(2)}, "")
+	require.Error(t, err, "active key is ambiguous")
+	_, err = New(map[string][]byte{"a": testKey(1)}, "b")
+	require.Error(t, err)
+	_, err = New(map[string][]byte{"a": []byte("short")}, "")
+	require.Error(t, err)
+	_, err = New(map[string][]byte{"a:b": testKey(1)}, "")
+	require.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	k, err := Load("", "", "")
+	require.NoError(t, err)
+	require.Nil(t, k, "no keys disables encryption")
+
+	spec := "old:" + base64.StdEncoding.EncodeToString(testKey(1)) + ",new:" + base64.StdEncoding.EncodeToString(testKey(2))
+	k, err = Load(spec, "", "new")
+	require.NoError(t, err)
+	require.Equal(t, "new", k.ActiveKey())
+
+	file := filepath.Join(t.TempDir(), "keys")
+	content := "# rendered by vault agent\nfile:" + base64.StdEncoding.EncodeToString(testKey(3)) + "\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	k, err = Load(spec, file, "")
+	require.NoError(t, err)
+	require.Equal(t, "file", k.ActiveKey(), "the file takes precedence")
+
+	_, err = Load("a:"+base64.StdEncoding.EncodeToString(testKey(1))+",a:"+base64.StdEncoding.EncodeToString(testKey(2)), "", "a")
+	require.Error(t, err, "duplicate ids are rejected")
+}
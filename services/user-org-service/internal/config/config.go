@@ -28,14 +28,20 @@
 //
 // Error Handling:
 //   - Load returns wrapped errors from envconfig.Process
+//   - Load rejects CORS_ALLOWED_ORIGINS entries matching any host ("*"),
+//     since the API allows credentialed cross-origin requests
 //   - MustLoad writes to stderr and exits on error
 package config
 
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
+
+	"github.com/ai-aas/shared-go/dataaccess/pgpool"
+	sharedserver "github.com/ai-aas/shared-go/server"
 )
 
 // Config represents shared runtime configuration for binaries in the user-org service.
@@ -48,6 +54,22 @@ type Config struct {
 	HTTPPort int `envconfig:"HTTP_PORT" default:"8081"`
 	// DatabaseURL is the Postgres connection string for the primary service database.
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	// DatabaseReplicaURLs are optional comma-separated read replica connection strings.
+	DatabaseReplicaURLs []string `envconfig:"DATABASE_REPLICA_URLS"`
+	// DatabaseReplicaMaxLag routes reads to the primary when replica lag exceeds it (default: 10s).
+	DatabaseReplicaMaxLag time.Duration `envconfig:"DATABASE_REPLICA_MAX_LAG" default:"10s"`
+	// DatabaseMaxConns caps open connections per pool (primary and each replica).
+	DatabaseMaxConns int32 `envconfig:"DATABASE_MAX_CONNS" default:"20"`
+	// DatabaseMinConns keeps this many connections warm per pool.
+	DatabaseMinConns int32 `envconfig:"DATABASE_MIN_CONNS" default:"2"`
+	// DatabaseMaxConnLifetime recycles connections after this age (default: 30m).
+	DatabaseMaxConnLifetime time.Duration `envconfig:"DATABASE_MAX_CONN_LIFETIME" default:"30m"`
+	// DatabaseMaxConnIdleTime closes idle connections after this duration (default: 5m).
+	DatabaseMaxConnIdleTime time.Duration `envconfig:"DATABASE_MAX_CONN_IDLE_TIME" default:"5m"`
+	// DatabaseStatementTimeout is the server-side statement_timeout for every connection (default: 15s).
+	DatabaseStatementTimeout time.Duration `envconfig:"DATABASE_STATEMENT_TIMEOUT" default:"15s"`
+	// DatabaseQueryTimeout bounds each store call client-side, including pool waits (default: 10s).
+	DatabaseQueryTimeout time.Duration `envconfig:"DATABASE_QUERY_TIMEOUT" default:"10s"`
 	// RedisAddr is the host:port of the Redis instance used for caching OAuth sessions.
 	RedisAddr string `envconfig:"REDIS_ADDR" default:"localhost:6379"`
 	// RedisPassword is the optional password for Redis authentication.
@@ -71,6 +93,17 @@ type Config struct {
 	KafkaTopic string `envconfig:"KAFKA_TOPIC" default:"audit.identity"`
 	// KafkaClientID is the client ID used when connecting to Kafka.
 	KafkaClientID string `envconfig:"KAFKA_CLIENT_ID" default:"user-org-service"`
+	// AuditOutboxEnabled writes audit events to the audit_outbox table in the
+	// mutation's transaction; the reconciler's relay publishes them (default: false).
+	AuditOutboxEnabled bool `envconfig:"AUDIT_OUTBOX_ENABLED" default:"false"`
+	// AuditOutboxRelayInterval is how often the relay polls for pending events (default: 1s).
+	AuditOutboxRelayInterval time.Duration `envconfig:"AUDIT_OUTBOX_RELAY_INTERVAL" default:"1s"`
+	// AuditOutboxBatchSize caps the events published per relay pass (default: 100).
+	AuditOutboxBatchSize int `envconfig:"AUDIT_OUTBOX_BATCH_SIZE" default:"100"`
+	// AuditOutboxMaxBackoff caps the retry delay of an event that fails to publish (default: 10m).
+	AuditOutboxMaxBackoff time.Duration `envconfig:"AUDIT_OUTBOX_MAX_BACKOFF" default:"10m"`
+	// AuditOutboxRetention is how long delivered events are kept for replay; 0 keeps them (default: 168h).
+	AuditOutboxRetention time.Duration `envconfig:"AUDIT_OUTBOX_RETENTION" default:"168h"`
 	// OIDCBaseURL is the base URL for OIDC callback redirects (e.g., "https://api.example.com").
 	// Used to construct callback URLs for IdP providers.
 	OIDCBaseURL string `envconfig:"OIDC_BASE_URL" default:""`
@@ -92,6 +125,263 @@ type Config struct {
 	LockoutWindowMinutes int `envconfig:"LOCKOUT_WINDOW_MINUTES" default:"15"`
 	// RecoveryRequiresAdminApproval enables admin approval workflow for recovery requests (default: false).
 	RecoveryRequiresAdminApproval bool `envconfig:"RECOVERY_REQUIRES_ADMIN_APPROVAL" default:"false"`
+
+	// Access token format and JWT signing
+	// AccessTokenFormat is "jwt" (RS256, verifiable via /.well-known/jwks.json) or "opaque" (HMAC) (default: jwt).
+	AccessTokenFormat string `envconfig:"ACCESS_TOKEN_FORMAT" default:"jwt"`
+	// JWTIssuer is the iss claim and OIDC discovery issuer; defaults to OIDC_BASE_URL, then http://localhost:<port>.
+	JWTIssuer string `envconfig:"JWT_ISSUER" default:""`
+	// JWTSigningKeyFiles lists PEM RSA private keys; the first signs, the rest only verify.
+	// Takes precedence over JWT_KEY_ENCRYPTION_KEY; rotation is then manual.
+	JWTSigningKeyFiles []string `envconfig:"JWT_SIGNING_KEY_FILES"`
+	// JWTKeyEncryptionKey is a base64 32-byte AES key; when set, signing keys are generated,
+	// stored encrypted in Postgres and shared by all replicas. Without it or key files each
+	// process generates its own keys, which only works with a single replica.
+	JWTKeyEncryptionKey string `envconfig:"JWT_KEY_ENCRYPTION_KEY" default:""`
+	// JWTKeyRotationInterval rotates generated signing keys (default: 24h, 0 disables for in-memory keys). Ignored with key files.
+	JWTKeyRotationInterval time.Duration `envconfig:"JWT_KEY_ROTATION_INTERVAL" default:"24h"`
+	// JWTKeyGracePeriod keeps a rotated-out key verifying tokens; must exceed the 1h access token lifespan (default: 2h).
+	JWTKeyGracePeriod time.Duration `envconfig:"JWT_KEY_GRACE_PERIOD" default:"2h"`
+	// JWTKeySyncInterval is how often replicas reload stored signing keys (default: 1m).
+	JWTKeySyncInterval time.Duration `envconfig:"JWT_KEY_SYNC_INTERVAL" default:"1m"`
+
+	// Column encryption for users.mfa_secret and users.recovery_tokens (AES-256-GCM)
+	// ColumnEncryptionKeys lists "id:base64-key" entries of 32-byte keys; disabled when no keys are set.
+	ColumnEncryptionKeys string `envconfig:"COLUMN_ENCRYPTION_KEYS" default:""`
+	// ColumnEncryptionKeysFile is a file of the same entries, one per line, rendered from Vault or a KMS;
+	// takes precedence over COLUMN_ENCRYPTION_KEYS.
+	ColumnEncryptionKeysFile string `envconfig:"COLUMN_ENCRYPTION_KEYS_FILE" default:""`
+	// ColumnEncryptionActiveKey is the key ID that encrypts new values; optional with a single key.
+	ColumnEncryptionActiveKey string `envconfig:"COLUMN_ENCRYPTION_ACTIVE_KEY" default:""`
+
+	// Device authorization grant (RFC 8628) for CLI tools such as admin-cli
+	// DeviceFlowEnabled exposes /v1/auth/device/* and registers the public device client (default: true).
+	DeviceFlowEnabled bool `envconfig:"DEVICE_FLOW_ENABLED" default:"true"`
+	// DeviceClientID is the public client CLI tools use for the device flow; it has no secret (default: admin-cli).
+	DeviceClientID string `envconfig:"OAUTH_DEVICE_CLIENT_ID" default:"admin-cli"`
+	// DeviceVerificationURL is the console page where users enter the user code.
+	DeviceVerificationURL string `envconfig:"DEVICE_VERIFICATION_URL" default:"http://localhost:5173/device"`
+	// DeviceCodeTTL is how long a device code can be approved and polled (default: 10m).
+	DeviceCodeTTL time.Duration `envconfig:"DEVICE_CODE_TTL" default:"10m"`
+	// DevicePollInterval is the minimum interval between token polls (default: 5s).
+	DevicePollInterval time.Duration `envconfig:"DEVICE_POLL_INTERVAL" default:"5s"`
+
+	// Password reset (POST /v1/auth/recover)
+	// PasswordResetTTL is how long an emailed reset link stays valid (default: 1h).
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"1h"`
+	// PasswordResetURL is the console page that receives the token, e.g. "https://console.example.com/reset-password".
+	// The emailed link is PasswordResetURL?token=<token>.
+	PasswordResetURL string `envconfig:"PASSWORD_RESET_URL" default:"http://localhost:5173/reset-password"`
+	// PasswordResetWebhookURL receives a password_reset.requested event used to send the email.
+	// If empty, the link is only logged.
+	PasswordResetWebhookURL string `envconfig:"PASSWORD_RESET_WEBHOOK_URL" default:""`
+	// PasswordResetSigningKey signs reset tokens; defaults to a key derived from OAUTH_HMAC_SECRET.
+	PasswordResetSigningKey string `envconfig:"PASSWORD_RESET_SIGNING_KEY" default:""`
+	// PasswordResetRateLimitPerEmail caps reset requests per email address per hour (default: 3).
+	PasswordResetRateLimitPerEmail int `envconfig:"PASSWORD_RESET_RATE_LIMIT_PER_EMAIL" default:"3"`
+	// PasswordResetRateLimitPerIP caps reset requests per client IP per hour (default: 10).
+	PasswordResetRateLimitPerIP int `envconfig:"PASSWORD_RESET_RATE_LIMIT_PER_IP" default:"10"`
+
+	// Magic link login (POST /v1/auth/magic-link); each org opts in with its magicLink setting
+	// MagicLinkTTL is how long an emailed login link stays valid (default: 15m).
+	MagicLinkTTL time.Duration `envconfig:"MAGIC_LINK_TTL" default:"15m"`
+	// MagicLinkURL is the console page that receives the token; the emailed link is MagicLinkURL?token=<token>.
+	MagicLinkURL string `envconfig:"MAGIC_LINK_URL" default:"http://localhost:5173/magic-link"`
+	// MagicLinkWebhookURL receives a magic_link.requested event used to send the email;
+	// defaults to PASSWORD_RESET_WEBHOOK_URL.
+	MagicLinkWebhookURL string `envconfig:"MAGIC_LINK_WEBHOOK_URL" default:""`
+	// MagicLinkSigningKey signs login links; defaults to a key derived from OAUTH_HMAC_SECRET.
+	MagicLinkSigningKey string `envconfig:"MAGIC_LINK_SIGNING_KEY" default:""`
+	// MagicLinkRateLimitPerEmail caps link requests per email address per hour (default: 5).
+	MagicLinkRateLimitPerEmail int `envconfig:"MAGIC_LINK_RATE_LIMIT_PER_EMAIL" default:"5"`
+	// MagicLinkRateLimitPerIP caps link requests and link verifications per client IP per hour (default: 20).
+	MagicLinkRateLimitPerIP int `envconfig:"MAGIC_LINK_RATE_LIMIT_PER_IP" default:"20"`
+	// MagicLinkRateLimitFailOpen lets magic link requests through while the rate limiter's
+	// Redis is unreachable; by default they are refused with 503 (default: false).
+	MagicLinkRateLimitFailOpen bool `envconfig:"MAGIC_LINK_RATE_LIMIT_FAIL_OPEN" default:"false"`
+
+	// Server lifecycle
+	// DebugEndpointsEnabled exposes /debug/pprof and /debug/vars (default: false).
+	// admin-api serves them behind AdminScope; the reconciler serves them on its internal port.
+	DebugEndpointsEnabled bool `envconfig:"DEBUG_ENDPOINTS_ENABLED" default:"false"`
+	// AdminScope is the OAuth scope required for diagnostics and debug endpoints (default: admin).
+	AdminScope string `envconfig:"ADMIN_SCOPE" default:"admin"`
+	// ShutdownDrainDelay keeps serving after readiness fails so endpoints can be deregistered (default: 5s).
+	ShutdownDrainDelay time.Duration `envconfig:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+	// PreStopAddr is a separate listener for POST /prestop, e.g. 127.0.0.1:8081 for an exec preStop hook (default: disabled).
+	PreStopAddr string `envconfig:"PRESTOP_ADDR" default:""`
+
+	// CORS (browser access from the console and customer web apps)
+	// CORSAllowedOrigins are origins allowed to call the API, e.g. "https://console.example.com,https://*.example.com".
+	// If empty, development environments allow any localhost port and others allow none.
+	// Credentials are always allowed, so "*" and other any-host patterns are rejected.
+	CORSAllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS"`
+	// CORSMaxAge is how long browsers may cache preflight responses (default: 1h).
+	CORSMaxAge time.Duration `envconfig:"CORS_MAX_AGE" default:"1h"`
+
+	// API versioning
+	// APIDeprecations is a JSON array of deprecated routes announced with Deprecation/Sunset headers,
+	// e.g. [{"prefix":"/v1/auth/legacy","sunset":"2027-01-01T00:00:00Z","link":"https://docs.example.com/migrate"}].
+	APIDeprecations string `envconfig:"API_DEPRECATIONS" default:""`
+
+	// Session cookie mode (web console): tokens in HTTP-only cookies instead of localStorage
+	// SessionCookiesEnabled lets login requests with "useCookies": true receive cookies instead of tokens (default: false).
+	SessionCookiesEnabled bool `envconfig:"SESSION_COOKIES_ENABLED" default:"false"`
+	// SessionCookieDomain scopes the cookies, e.g. ".example.com"; empty uses the request host.
+	SessionCookieDomain string `envconfig:"SESSION_COOKIE_DOMAIN" default:""`
+	// SessionCookieSameSite is strict, lax or none; none requires SESSION_COOKIE_SECURE (default: strict).
+	SessionCookieSameSite string `envconfig:"SESSION_COOKIE_SAMESITE" default:"strict"`
+	// SessionCookieSecure marks cookies Secure; disable only for plain-HTTP local development (default: true).
+	SessionCookieSecure bool `envconfig:"SESSION_COOKIE_SECURE" default:"true"`
+
+	// Onboarding (POST /v1/onboarding)
+	// OnboardingWebhookURL receives an org.onboarded event used to send the welcome email.
+	// If empty, the welcome notification is only logged.
+	OnboardingWebhookURL string `envconfig:"ONBOARDING_WEBHOOK_URL" default:""`
+	// OnboardingRateLimitRPS is the default per-org request rate recorded on new orgs (default: 10).
+	OnboardingRateLimitRPS int `envconfig:"ONBOARDING_RATE_LIMIT_RPS" default:"10"`
+	// OnboardingRateLimitBurst is the default per-org burst recorded on new orgs (default: 20).
+	OnboardingRateLimitBurst int `envconfig:"ONBOARDING_RATE_LIMIT_BURST" default:"20"`
+	// OnboardingMonthlyBudgetUSD is the default monthly budget recorded on new orgs (default: 100).
+	OnboardingMonthlyBudgetUSD float64 `envconfig:"ONBOARDING_MONTHLY_BUDGET_USD" default:"100"`
+	// OnboardingAPIKeyScopes are granted to the initial API key unless the request overrides them.
+	OnboardingAPIKeyScopes []string `envconfig:"ONBOARDING_API_KEY_SCOPES" default:"inference:read"`
+
+	// Temporary API keys (POST /v1/api-keys/temporary)
+	// TemporaryKeyDefaultTTL applies when the request omits ttlMinutes (default: 15m).
+	TemporaryKeyDefaultTTL time.Duration `envconfig:"TEMPORARY_KEY_DEFAULT_TTL" default:"15m"`
+	// TemporaryKeyMaxTTL is the longest lifetime a temporary key may request (default: 60m).
+	TemporaryKeyMaxTTL time.Duration `envconfig:"TEMPORARY_KEY_MAX_TTL" default:"60m"`
+
+	// API key expiry worker (runs in the reconciler)
+	// KeyExpiryWorkerEnabled sends expiry reminders and marks expired keys (default: true).
+	KeyExpiryWorkerEnabled bool `envconfig:"KEY_EXPIRY_WORKER_ENABLED" default:"true"`
+	// KeyExpiryInterval is how often the worker scans for expiring keys (default: 5m).
+	KeyExpiryInterval time.Duration `envconfig:"KEY_EXPIRY_INTERVAL" default:"5m"`
+	// KeyExpiryReminderLeadTimes are how long before expires_at reminders are sent (default: 168h,24h).
+	KeyExpiryReminderLeadTimes []time.Duration `envconfig:"KEY_EXPIRY_REMINDER_LEAD_TIMES" default:"168h,24h"`
+	// KeyExpiryWebhookURL receives api_key.expiring and api_key.expired events used to send emails.
+	// If empty, reminders are only logged.
+	KeyExpiryWebhookURL string `envconfig:"KEY_EXPIRY_WEBHOOK_URL" default:""`
+	// KeyExpiryBatchSize caps the keys handled per scan (default: 500).
+	KeyExpiryBatchSize int `envconfig:"KEY_EXPIRY_BATCH_SIZE" default:"500"`
+
+	// Declarative reconciliation (runs in the reconciler)
+	// ReconcilerDesiredStateDir holds one <slug>.yaml document per declarative org, kept
+	// current by a git-sync sidecar. If empty, drift detection is disabled.
+	ReconcilerDesiredStateDir string `envconfig:"RECONCILER_DESIRED_STATE_DIR" default:""`
+	// ReconcilerInterval is how often declarative orgs are compared with their documents (default: 5m).
+	ReconcilerInterval time.Duration `envconfig:"RECONCILER_INTERVAL" default:"5m"`
+	// DriftWebhookURL receives drift.detected and drift.applied events.
+	// If empty, drift notices are only logged.
+	DriftWebhookURL string `envconfig:"DRIFT_WEBHOOK_URL" default:""`
+
+	// Self-service signup (POST /v1/signup)
+	// SignupEnabled exposes the public signup and email verification endpoints (default: false).
+	SignupEnabled bool `envconfig:"SIGNUP_ENABLED" default:"false"`
+	// SignupRateLimitPerHour caps signup attempts per client IP per hour (default: 5).
+	SignupRateLimitPerHour int `envconfig:"SIGNUP_RATE_LIMIT_PER_HOUR" default:"5"`
+	// SignupBlockedEmailDomains are rejected in addition to the built-in disposable-email list.
+	SignupBlockedEmailDomains []string `envconfig:"SIGNUP_BLOCKED_EMAIL_DOMAINS"`
+	// SignupVerificationTTL is how long an email verification token stays valid (default: 24h).
+	SignupVerificationTTL time.Duration `envconfig:"SIGNUP_VERIFICATION_TTL" default:"24h"`
+	// SignupVerificationURL is the console page that receives the token; the emailed link is
+	// SignupVerificationURL?orgId=<org>&userId=<user>&token=<token>.
+	SignupVerificationURL string `envconfig:"SIGNUP_VERIFICATION_URL" default:"http://localhost:5173/verify-email"`
+	// SignupWebhookURL receives a signup.verification_requested event used to send the email;
+	// defaults to ONBOARDING_WEBHOOK_URL.
+	SignupWebhookURL string `envconfig:"SIGNUP_WEBHOOK_URL" default:""`
+	// SignupRateLimitFailOpen lets signups through while the rate limiter's Redis is
+	// unreachable; by default they are refused with 503 (default: false).
+	SignupRateLimitFailOpen bool `envconfig:"SIGNUP_RATE_LIMIT_FAIL_OPEN" default:"false"`
+
+	// Account deletion and org ownership transfer
+	// AccountDeletionGracePeriod is how long after a self-service deletion request the account is purged (default: 720h).
+	// The user can cancel and export their data until then.
+	AccountDeletionGracePeriod time.Duration `envconfig:"ACCOUNT_DELETION_GRACE_PERIOD" default:"720h"`
+	// AccountDeletionWorkerEnabled purges accounts whose grace period has ended (default: true; runs in the reconciler).
+	AccountDeletionWorkerEnabled bool `envconfig:"ACCOUNT_DELETION_WORKER_ENABLED" default:"true"`
+	// AccountDeletionInterval is how often the worker scans for accounts to purge (default: 1h).
+	AccountDeletionInterval time.Duration `envconfig:"ACCOUNT_DELETION_INTERVAL" default:"1h"`
+	// AccountDeletionBatchSize caps the accounts purged per scan (default: 100).
+	AccountDeletionBatchSize int `envconfig:"ACCOUNT_DELETION_BATCH_SIZE" default:"100"`
+	// RetentionWorkerEnabled cleans up orgs, users and API keys soft-deleted longer than
+	// RetentionPeriod (default: false; runs in the reconciler).
+	RetentionWorkerEnabled bool `envconfig:"RETENTION_WORKER_ENABLED" default:"false"`
+	// RetentionPeriod is how long soft-deleted rows are kept before cleanup (default: 2160h).
+	RetentionPeriod time.Duration `envconfig:"RETENTION_PERIOD" default:"2160h"`
+	// RetentionMode is "anonymize" (scrub rows in place) or "delete" (hard-delete them) (default: anonymize).
+	RetentionMode string `envconfig:"RETENTION_MODE" default:"anonymize"`
+	// RetentionDryRun only reports what the worker would clean up (default: true).
+	RetentionDryRun bool `envconfig:"RETENTION_DRY_RUN" default:"true"`
+	// RetentionInterval is how often the worker scans for rows past retention (default: 24h).
+	RetentionInterval time.Duration `envconfig:"RETENTION_INTERVAL" default:"24h"`
+	// RetentionBatchSize caps the rows of each entity handled per scan (default: 100).
+	RetentionBatchSize int `envconfig:"RETENTION_BATCH_SIZE" default:"100"`
+	// OwnershipTransferTTL is how long both owners have to confirm an ownership transfer (default: 72h).
+	OwnershipTransferTTL time.Duration `envconfig:"OWNERSHIP_TRANSFER_TTL" default:"72h"`
+
+	// Org suspension (POST /v1/admin/orgs:bulk-suspend)
+	// BulkSuspendMaxOrgs caps the orgs suspended by one request (default: 100).
+	BulkSuspendMaxOrgs int `envconfig:"BULK_SUSPEND_MAX_ORGS" default:"100"`
+	// RouterAdminURLs are API router base URLs told to drop cached key validations of suspended orgs,
+	// e.g. one per router pod. If empty, suspended keys keep working until the router cache expires (1m).
+	RouterAdminURLs []string `envconfig:"ROUTER_ADMIN_URLS"`
+	// RouterAdminAPIKey authenticates cache invalidation calls; it needs the router's admin scope.
+	RouterAdminAPIKey string `envconfig:"ROUTER_ADMIN_API_KEY" default:""`
+
+	// Org ID/slug resolution cache (in-process, plus Redis when configured)
+	// OrgResolverCacheTTL is how long a resolved org reference is cached (default: 5m).
+	OrgResolverCacheTTL time.Duration `envconfig:"ORG_RESOLVER_CACHE_TTL" default:"5m"`
+	// OrgResolverNegativeTTL is how long an unknown org reference is cached (default: 30s).
+	OrgResolverNegativeTTL time.Duration `envconfig:"ORG_RESOLVER_NEGATIVE_TTL" default:"30s"`
+
+	// Security event stream (requires Redis)
+	// SecurityEventsEnabled detects auth anomalies and publishes them as security events (default: true).
+	SecurityEventsEnabled bool `envconfig:"SECURITY_EVENTS_ENABLED" default:"true"`
+	// SecurityEventsTopic is the Kafka topic for security events; KAFKA_BROKERS must be set (default: security.events).
+	SecurityEventsTopic string `envconfig:"SECURITY_EVENTS_TOPIC" default:"security.events"`
+	// SecurityEventsWebhookURL receives every security event as JSON, e.g. a SIEM HTTP collector.
+	SecurityEventsWebhookURL string `envconfig:"SECURITY_EVENTS_WEBHOOK_URL" default:""`
+	// SecurityEventsGeoIPFile is a CSV of cidr,asn,as_org,country,latitude,longitude rows.
+	// Impossible-travel and new-ASN detection are disabled without it.
+	SecurityEventsGeoIPFile string `envconfig:"SECURITY_EVENTS_GEOIP_FILE" default:""`
+	// SecurityEventsRetention is how long events stay queryable via /v1/admin/security-events (default: 168h).
+	SecurityEventsRetention time.Duration `envconfig:"SECURITY_EVENTS_RETENTION" default:"168h"`
+	// SecurityEventsLockoutThreshold is the number of lockouts of one account within the window that raises an event (default: 3).
+	SecurityEventsLockoutThreshold int `envconfig:"SECURITY_EVENTS_LOCKOUT_THRESHOLD" default:"3"`
+	// SecurityEventsLockoutWindow is the window for counting repeated lockouts (default: 24h).
+	SecurityEventsLockoutWindow time.Duration `envconfig:"SECURITY_EVENTS_LOCKOUT_WINDOW" default:"24h"`
+	// SecurityEventsMaxTravelKMH is the fastest plausible travel speed between two logins (default: 900).
+	SecurityEventsMaxTravelKMH float64 `envconfig:"SECURITY_EVENTS_MAX_TRAVEL_KMH" default:"900"`
+	// SecurityEventsRevocationThreshold is the number of API key revocations in one org within the window that raises an event (default: 10).
+	SecurityEventsRevocationThreshold int `envconfig:"SECURITY_EVENTS_REVOCATION_THRESHOLD" default:"10"`
+	// SecurityEventsRevocationWindow is the window for counting revocations (default: 10m).
+	SecurityEventsRevocationWindow time.Duration `envconfig:"SECURITY_EVENTS_REVOCATION_WINDOW" default:"10m"`
+
+	// Service-to-service authentication
+	// Workloads (router, analytics) are OAuth clients registered with the client_credentials
+	// grant and the "service" scope; they mint tokens at /v1/auth/service-token.
+	// ServiceAuthClients lists the client IDs allowed to call internal endpoints such as
+	// /v1/auth/validate-api-key; empty allows any service token.
+	ServiceAuthClients []string `envconfig:"SERVICE_AUTH_CLIENTS"`
+	// ServiceAuthRequired rejects internal calls without a valid service token; while false
+	// they are allowed and counted, so identities can be rolled out first (default: false).
+	ServiceAuthRequired bool `envconfig:"SERVICE_AUTH_REQUIRED" default:"false"`
+
+	// Startup dependency ordering (Postgres, then Redis)
+	// StartupPostgresMaxWait is how long startup retries Postgres before failing (default: 60s).
+	StartupPostgresMaxWait time.Duration `envconfig:"STARTUP_POSTGRES_MAX_WAIT" default:"60s"`
+	// StartupRedisMaxWait is how long startup retries Redis before failing or, if optional, continuing without it (default: 30s).
+	StartupRedisMaxWait time.Duration `envconfig:"STARTUP_REDIS_MAX_WAIT" default:"30s"`
+	// StartupRetryInitialBackoff is the delay before the first retry; it doubles after each attempt (default: 500ms).
+	StartupRetryInitialBackoff time.Duration `envconfig:"STARTUP_RETRY_INITIAL_BACKOFF" default:"500ms"`
+	// StartupRetryMaxBackoff caps the delay between retries (default: 5s).
+	StartupRetryMaxBackoff time.Duration `envconfig:"STARTUP_RETRY_MAX_BACKOFF" default:"5s"`
+	// StartupOptionalDependencies lists dependencies the service may start without (only "redis" for now).
+	// Without Redis, sessions are not cached, lockout tracking and security events are disabled and
+	// device codes are kept in memory.
+	StartupOptionalDependencies []string `envconfig:"STARTUP_OPTIONAL_DEPENDENCIES"`
 }
 
 // Load reads environment variables into Config, applying defaults where necessary.
@@ -100,9 +390,26 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg); err != nil {
 		return nil, fmt.Errorf("config: process env: %w", err)
 	}
+	// The admin API allows credentials for every origin it allows
+	cors := sharedserver.CORS{AllowedOrigins: cfg.CORSAllowedOrigins, AllowCredentials: true}
+	if err := cors.Validate(); err != nil {
+		return nil, fmt.Errorf("config: CORS_ALLOWED_ORIGINS: %w", err)
+	}
 	return &cfg, nil
 }
 
+// PoolConfig returns the Postgres pool tuning settings.
+func (c *Config) PoolConfig() pgpool.Config {
+	return pgpool.Config{
+		MaxConns:         c.DatabaseMaxConns,
+		MinConns:         c.DatabaseMinConns,
+		MaxConnLifetime:  c.DatabaseMaxConnLifetime,
+		MaxConnIdleTime:  c.DatabaseMaxConnIdleTime,
+		StatementTimeout: c.DatabaseStatementTimeout,
+		QueryTimeout:     c.DatabaseQueryTimeout,
+	}
+}
+
 // MustLoad returns Config or exits the process.
 func MustLoad() *Config {
 	cfg, err := Load()
@@ -0,0 +1,143 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// SettingKeys are the org metadata keys carried in spec.settings (see the
+// matching *MetadataKey constants in internal/httpapi/orgs). Transient state
+// such as pending ownership transfers is not a setting.
+var SettingKeys = []string{
+	"data_residency",
+	"inference_archival",
+	"tool_limits",
+	"model_entitlements",
+	"rate_limits",
+	"usage_payloads",
+}
+
+// Store is the subset of postgres.Store needed to describe an org.
+type Store interface {
+	GetOrg(ctx context.Context, id uuid.UUID) (postgres.Org, error)
+	ListUsersForOrg(ctx context.Context, orgID uuid.UUID) ([]postgres.User, error)
+	ListServiceAccountsForOrg(ctx context.Context, orgID uuid.UUID) ([]postgres.ServiceAccount, error)
+	ListAPIKeysForOrg(ctx context.Context, orgID uuid.UUID) ([]postgres.APIKey, error)
+}
+
+// Build describes org's current state as a document.
+func Build(ctx context.Context, store Store, org postgres.Org) (*Organization, error) {
+	users, err := store.ListUsersForOrg(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	accounts, err := store.ListServiceAccountsForOrg(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	keys, err := store.ListAPIKeysForOrg(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	keysByPrincipal := make(map[uuid.UUID][]APIKey)
+	for _, key := range keys {
+		if key.Status == "revoked" || key.RevokedAt != nil {
+			continue
+		}
+		keysByPrincipal[key.PrincipalID] = append(keysByPrincipal[key.PrincipalID], APIKey{
+			ID:          key.ID.String(),
+			Status:      key.Status,
+			Scopes:      key.Scopes,
+			ExpiresAt:   key.ExpiresAt,
+			Annotations: key.Annotations,
+		})
+	}
+
+	now := time.Now().UTC()
+	doc := &Organization{
+		APIVersion: APIVersion,
+		Kind:       KindOrganization,
+		Metadata: ObjectMeta{
+			Slug:        org.Slug,
+			ExportedAt:  &now,
+			SourceOrgID: org.ID.String(),
+		},
+		Spec: OrgSpec{
+			Name:             org.Name,
+			Status:           org.Status,
+			MFARequiredRoles: org.MFARequiredRoles,
+		},
+	}
+
+	settings := make(map[string]any)
+	for _, key := range SettingKeys {
+		if value, ok := org.Metadata[key]; ok && value != nil {
+			settings[key] = value
+		}
+	}
+	if len(settings) > 0 {
+		doc.Spec.Settings = settings
+	}
+
+	// Sub-orgs link to their parent through metadata["parent_org"]
+	if parent, ok := org.Metadata["parent_org"].(map[string]any); ok {
+		if parentID, err := uuid.Parse(fmt.Sprint(parent["parentOrgId"])); err == nil {
+			parentOrg, err := store.GetOrg(ctx, parentID)
+			if err != nil {
+				return nil, fmt.Errorf("get parent org: %w", err)
+			}
+			doc.Spec.ParentSlug = parentOrg.Slug
+		}
+	}
+
+	for _, user := range users {
+		if org.BillingOwnerUserID != nil && *org.BillingOwnerUserID == user.ID {
+			doc.Spec.BillingOwnerEmail = user.Email
+		}
+		member := User{
+			Email:       user.Email,
+			DisplayName: user.DisplayName,
+			Status:      user.Status,
+			Roles:       Roles(user.Metadata),
+			APIKeys:     keysByPrincipal[user.ID],
+		}
+		if user.ExternalIDP != nil {
+			member.ExternalIDP = *user.ExternalIDP
+		}
+		doc.Spec.Users = append(doc.Spec.Users, member)
+	}
+
+	for _, account := range accounts {
+		exported := ServiceAccount{
+			Name:    account.Name,
+			Status:  account.Status,
+			APIKeys: keysByPrincipal[account.ID],
+		}
+		if account.Description != nil {
+			exported.Description = *account.Description
+		}
+		doc.Spec.ServiceAccounts = append(doc.Spec.ServiceAccounts, exported)
+	}
+	return doc, nil
+}
+
+// Roles reads the role names stored in user metadata["roles"].
+func Roles(metadata map[string]any) []string {
+	var roles []string
+	switch raw := metadata["roles"].(type) {
+	case []any:
+		for _, role := range raw {
+			if name, ok := role.(string); ok && name != "" {
+				roles = append(roles, name)
+			}
+		}
+	case []string:
+		roles = append(roles, raw...)
+	}
+	return roles
+}
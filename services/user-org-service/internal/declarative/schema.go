@@ -0,0 +1,168 @@
+// Package declarative defines the GitOps document describing an organization.
+//
+// Purpose:
+//
+//	Orgs in declarative mode keep their desired state in a Git repository as
+//	YAML; the reconciler reads those documents and applies them. The same
+//	schema is produced by GET /v1/orgs/{orgId}/export so an existing org can be
+//	adopted into GitOps by committing its export.
+//
+// Dependencies:
+//   - gopkg.in/yaml.v3: Encoding and decoding
+//   - internal/storage/postgres: Current org state for Build
+//
+// Key Responsibilities:
+//   - Organization: The versioned document (apiVersion/kind/metadata/spec)
+//   - Parse: Decode and validate a document, rejecting unknown fields
+//   - Marshal: Encode a document as YAML
+//   - Build: Describe an org's current state (export and drift detection)
+//
+// Debugging Notes:
+//   - Documents never contain credentials: no password hashes, MFA secrets or
+//     API key secrets. API keys are listed for reference (ID, scopes, expiry,
+//     annotations); the reconciler does not mint keys from them
+//   - spec.settings holds the org's policy metadata keys verbatim
+//     (data_residency, inference_archival, tool_limits, model_entitlements,
+//     rate_limits, usage_payloads) so new settings do not need a schema change
+//   - Users and service accounts are matched by email and name, not by ID
+//
+// Error Handling:
+//   - Parse returns an error for unknown fields, a wrong apiVersion/kind, a
+//     missing slug or duplicate user emails / service account names
+package declarative
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document identity.
+const (
+	APIVersion       = "user-org.ai-aas.dev/v1"
+	KindOrganization = "Organization"
+)
+
+// Organization is a complete declarative description of one org.
+type Organization struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   ObjectMeta `yaml:"metadata"`
+	Spec       OrgSpec    `yaml:"spec"`
+}
+
+// ObjectMeta identifies the org. Slug is the stable key.
+type ObjectMeta struct {
+	Slug string `yaml:"slug"`
+	// ExportedAt and SourceOrgID are informational and ignored when applying.
+	ExportedAt  *time.Time `yaml:"exportedAt,omitempty"`
+	SourceOrgID string     `yaml:"sourceOrgId,omitempty"`
+}
+
+// OrgSpec is the desired state of an org.
+type OrgSpec struct {
+	Name              string           `yaml:"name"`
+	Status            string           `yaml:"status,omitempty"`
+	BillingOwnerEmail string           `yaml:"billingOwnerEmail,omitempty"`
+	ParentSlug        string           `yaml:"parentSlug,omitempty"`
+	MFARequiredRoles  []string         `yaml:"mfaRequiredRoles,omitempty"`
+	Settings          map[string]any   `yaml:"settings,omitempty"`
+	Users             []User           `yaml:"users,omitempty"`
+	ServiceAccounts   []ServiceAccount `yaml:"serviceAccounts,omitempty"`
+}
+
+// User is a member of the org.
+type User struct {
+	Email       string   `yaml:"email"`
+	DisplayName string   `yaml:"displayName,omitempty"`
+	Status      string   `yaml:"status,omitempty"`
+	Roles       []string `yaml:"roles,omitempty"`
+	ExternalIDP string   `yaml:"externalIdp,omitempty"`
+	APIKeys     []APIKey `yaml:"apiKeys,omitempty"`
+}
+
+// ServiceAccount is a non-human principal of the org.
+type ServiceAccount struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Status      string   `yaml:"status,omitempty"`
+	APIKeys     []APIKey `yaml:"apiKeys,omitempty"`
+}
+
+// APIKey describes an issued key without its secret.
+type APIKey struct {
+	ID          string         `yaml:"id"`
+	Status      string         `yaml:"status,omitempty"`
+	Scopes      []string       `yaml:"scopes,omitempty"`
+	ExpiresAt   *time.Time     `yaml:"expiresAt,omitempty"`
+	Annotations map[string]any `yaml:"annotations,omitempty"`
+}
+
+// Parse decodes a YAML document and validates it.
+func Parse(data []byte) (*Organization, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var doc Organization
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode organization document: %w", err)
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Validate checks the document identity and uniqueness constraints.
+func (o *Organization) Validate() error {
+	if o.APIVersion != APIVersion {
+		return fmt.Errorf("unsupported apiVersion %q (want %q)", o.APIVersion, APIVersion)
+	}
+	if o.Kind != KindOrganization {
+		return fmt.Errorf("unsupported kind %q (want %q)", o.Kind, KindOrganization)
+	}
+	if strings.TrimSpace(o.Metadata.Slug) == "" {
+		return fmt.Errorf("metadata.slug is required")
+	}
+	if strings.TrimSpace(o.Spec.Name) == "" {
+		return fmt.Errorf("spec.name is required")
+	}
+	emails := make(map[string]bool, len(o.Spec.Users))
+	for _, user := range o.Spec.Users {
+		email := strings.ToLower(user.Email)
+		if email == "" {
+			return fmt.Errorf("spec.users: email is required")
+		}
+		if emails[email] {
+			return fmt.Errorf("spec.users: duplicate email %q", user.Email)
+		}
+		emails[email] = true
+	}
+	names := make(map[string]bool, len(o.Spec.ServiceAccounts))
+	for _, account := range o.Spec.ServiceAccounts {
+		if account.Name == "" {
+			return fmt.Errorf("spec.serviceAccounts: name is required")
+		}
+		if names[account.Name] {
+			return fmt.Errorf("spec.serviceAccounts: duplicate name %q", account.Name)
+		}
+		names[account.Name] = true
+	}
+	return nil
+}
+
+// Marshal encodes the document as YAML with two-space indentation.
+func Marshal(doc *Organization) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode organization document: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode organization document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
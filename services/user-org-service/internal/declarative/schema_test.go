@@ -0,0 +1,51 @@
+package declarative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalParseRoundTrip(t *testing.T) {
+	exportedAt := time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC)
+	doc := &Organization{
+		APIVersion: APIVersion,
+		Kind:       KindOrganization,
+		Metadata:   ObjectMeta{Slug: "acme", ExportedAt: &exportedAt},
+		Spec: OrgSpec{
+			Name:              "Acme",
+			BillingOwnerEmail: "owner@acme.test",
+			Settings: map[string]any{
+				"data_residency": map[string]any{"allowedRegions": []any{"eu-west"}},
+			},
+			Users: []User{{Email: "owner@acme.test", Roles: []string{"owner"}}},
+			ServiceAccounts: []ServiceAccount{{
+				Name:    "ci",
+				APIKeys: []APIKey{{ID: "5d0c4a3e-9a51-4b53-9c1e-2f1f0d3b8e11", Scopes: []string{"inference:read"}}},
+			}},
+		},
+	}
+
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, doc, parsed)
+}
+
+func TestParseRejectsInvalidDocuments(t *testing.T) {
+	cases := map[string]string{
+		"wrong kind":    "apiVersion: user-org.ai-aas.dev/v1\nkind: Team\nmetadata: {slug: acme}\nspec: {name: Acme}\n",
+		"unknown field": "apiVersion: user-org.ai-aas.dev/v1\nkind: Organization\nmetadata: {slug: acme}\nspec: {name: Acme, colour: red}\n",
+		"duplicate user": "apiVersion: user-org.ai-aas.dev/v1\nkind: Organization\nmetadata: {slug: acme}\n" +
+			"spec:\n  name: Acme\n  users:\n  - email: a@acme.test\n  - email: A@acme.test\n",
+	}
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(input))
+			require.Error(t, err)
+		})
+	}
+}
@@ -17,6 +17,9 @@
 // Key Responsibilities:
 //   - IssueAPIKey: POST /v1/orgs/{orgId}/service-accounts/{serviceAccountId}/api-keys - Issue new key
 //   - RevokeAPIKey: DELETE /v1/orgs/{orgId}/api-keys/{apiKeyId} - Revoke a key
+//   - ListAPIKeys: GET /v1/orgs/{orgId}/api-keys - List keys with recent usage
+//   - SetAPIKeyRestrictions: PUT /v1/orgs/{orgId}/api-keys/{apiKeyId}/restrictions - CIDR/header restrictions
+//   - IssueTemporaryKey: POST /v1/api-keys/temporary - Mint a short-lived single-scope key
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#FR-004 (API Key Lifecycle)
@@ -27,6 +30,7 @@
 //   - Fingerprints are SHA-256 hashes of the secret (for identification)
 //   - Vault Transit encrypts secret material (stub implementation for now)
 //   - Revocation propagates to Redis for fast revocation checks
+//   - Network restrictions live in annotations["restrictions"] and are enforced by the API router
 //   - Optimistic locking prevents concurrent revocation conflicts
 //
 // Thread Safety:
@@ -45,6 +49,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -56,7 +61,9 @@ import (
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/keyusage"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
@@ -68,6 +75,7 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger
 	handler := &Handler{
 		runtime: rt,
 		logger:  logger,
+		usage:   keyusage.NewReader(rt.Redis),
 	}
 
 	router.Post("/v1/orgs/{orgId}/service-accounts/{serviceAccountId}/api-keys", handler.IssueAPIKey)
@@ -77,6 +85,8 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger
 	router.Patch("/v1/orgs/{orgId}/api-keys/{apiKeyId}", handler.UpdateAPIKey)
 	router.Post("/v1/orgs/{orgId}/api-keys/{apiKeyId}/rotate", handler.RotateAPIKey)
 	router.Post("/v1/orgs/{orgId}/api-keys/{apiKeyId}/revoke", handler.RevokeAPIKey)
+	router.Get("/v1/orgs/{orgId}/api-keys/{apiKeyId}/restrictions", handler.GetAPIKeyRestrictions)
+	router.Put("/v1/orgs/{orgId}/api-keys/{apiKeyId}/restrictions", handler.SetAPIKeyRestrictions)
 	router.Delete("/v1/orgs/{orgId}/api-keys/{apiKeyId}", handler.RevokeAPIKey)
 
 	// Register convenience routes for /organizations/me/* (frontend-friendly)
@@ -93,6 +103,7 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger
 type Handler struct {
 	runtime *bootstrap.Runtime
 	logger  *zap.Logger
+	usage   *keyusage.Reader // Usage summaries fed by analytics (nil-safe)
 }
 
 // IssueAPIKeyRequest represents the payload for issuing an API key.
@@ -112,30 +123,59 @@ type IssuedAPIKeyResponse struct {
 	ExpiresAt   *string `json:"expiresAt,omitempty"`
 }
 
+// APIKeyListItem is an API key in list responses (secret never included).
+type APIKeyListItem struct {
+	APIKeyID      string            `json:"apiKeyId"`
+	PrincipalType string            `json:"principalType"`
+	PrincipalID   string            `json:"principalId"`
+	Fingerprint   string            `json:"fingerprint"`
+	Status        string            `json:"status"`
+	Scopes        []string          `json:"scopes"`
+	IssuedAt      string            `json:"issuedAt"`
+	ExpiresAt     *string           `json:"expiresAt,omitempty"`
+	LastUsedAt    *string           `json:"lastUsedAt,omitempty"`
+	Usage         *keyusage.Summary `json:"usage,omitempty"`
+}
+
+// resolveOrgID resolves the {orgId} path parameter (UUID or slug), writing
+// the error response when it cannot.
+func (h *Handler) resolveOrgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	orgIDParam := chi.URLParam(r, "orgId")
+	orgID, err := h.runtime.OrgResolver.ResolveID(r.Context(), orgIDParam)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			http.Error(w, "organization not found", http.StatusNotFound)
+			return uuid.Nil, false
+		}
+		h.logger.Error("failed to resolve organization", zap.Error(err), zap.String("orgId", orgIDParam))
+		http.Error(w, "failed to resolve organization", http.StatusInternalServerError)
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// GenerateSecret returns a new API key secret (32 random bytes, base64url) and
+// its fingerprint (base64url SHA-256 of the secret), as stored in api_keys.
+func GenerateSecret() (secret, fingerprint string, err error) {
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+	secret = base64.RawURLEncoding.EncodeToString(secretBytes)
+	fingerprintHash := sha256.Sum256([]byte(secret))
+	return secret, base64.RawURLEncoding.EncodeToString(fingerprintHash[:]), nil
+}
+
 // IssueAPIKey handles POST /v1/orgs/{orgId}/service-accounts/{serviceAccountId}/api-keys.
 // Generates a secure random secret, computes fingerprint, stores metadata in DB,
 // encrypts secret via Vault Transit, and returns the secret once.
 func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	orgIDParam := chi.URLParam(r, "orgId")
 	serviceAccountIDParam := chi.URLParam(r, "serviceAccountId")
 
-	// Parse org ID (UUID or slug)
-	var orgID uuid.UUID
-	var err error
-	if orgID, err = uuid.Parse(orgIDParam); err != nil {
-		// Try as slug
-		org, err := h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-		if err != nil {
-			if err == postgres.ErrNotFound {
-				http.Error(w, "organization not found", http.StatusNotFound)
-				return
-			}
-			h.logger.Error("failed to resolve organization", zap.Error(err), zap.String("orgId", orgIDParam))
-			http.Error(w, "failed to resolve organization", http.StatusInternalServerError)
-			return
-		}
-		orgID = org.ID
+	orgID, ok := h.resolveOrgID(w, r)
+	if !ok {
+		return
 	}
 
 	// Parse service account ID
@@ -176,21 +216,13 @@ func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate secure random secret (32 bytes = 256 bits)
-	secretBytes := make([]byte, 32)
-	if _, err := rand.Read(secretBytes); err != nil {
+	secret, fingerprint, err := GenerateSecret()
+	if err != nil {
 		h.logger.Error("failed to generate secret", zap.Error(err))
 		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
 		return
 	}
 
-	// Encode secret as base64url (URL-safe, no padding)
-	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
-
-	// Compute fingerprint (SHA-256 hash of secret)
-	fingerprintHash := sha256.Sum256([]byte(secret))
-	fingerprint := base64.RawURLEncoding.EncodeToString(fingerprintHash[:])
-
 	// Encrypt secret via Vault Transit (stub for now)
 	encryptedSecret, err := h.encryptSecret(ctx, secret)
 	if err != nil {
@@ -227,7 +259,24 @@ func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
 		Annotations:   annotations,
 	}
 
-	apiKey, err := h.runtime.Postgres.CreateAPIKey(ctx, params)
+	// The key and its audit event commit together
+	actorID := middleware.GetUserID(r.Context())
+	var apiKey postgres.APIKey
+	err = h.runtime.Postgres.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		apiKey, err = h.runtime.Postgres.CreateAPIKey(ctx, params)
+		if err != nil {
+			return err
+		}
+		event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, audit.ActionAPIKeyIssue, audit.TargetTypeAPIKey, &apiKey.ID)
+		event = audit.BuildEventFromRequest(event, r)
+		event.Metadata = map[string]any{
+			"principal_type": "service_account",
+			"principal_id":   serviceAccountID.String(),
+			"fingerprint":    fingerprint,
+		}
+		return h.runtime.EmitAuditInTx(ctx, event)
+	})
 	if err != nil {
 		h.logger.Error("failed to create API key", zap.Error(err), zap.String("orgId", orgID.String()), zap.String("serviceAccountId", serviceAccountID.String()))
 		http.Error(w, "failed to create API key", http.StatusInternalServerError)
@@ -242,17 +291,6 @@ func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
 		_ = h.storeEncryptedSecret(ctx, apiKey.ID, encryptedSecret)
 	}()
 
-	// Emit audit event
-	actorID := middleware.GetUserID(r.Context())
-	event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, audit.ActionAPIKeyIssue, audit.TargetTypeAPIKey, &apiKey.ID)
-	event = audit.BuildEventFromRequest(event, r)
-	event.Metadata = map[string]any{
-		"principal_type": "service_account",
-		"principal_id":   serviceAccountID.String(),
-		"fingerprint":    fingerprint,
-	}
-	_ = h.runtime.Audit.Emit(ctx, event)
-
 	// Record API key issuance
 	metrics.RecordAPIKeyIssued()
 
@@ -279,25 +317,11 @@ func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
 // Marks the key as revoked in the database and propagates revocation to Redis.
 func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	orgIDParam := chi.URLParam(r, "orgId")
 	apiKeyIDParam := chi.URLParam(r, "apiKeyId")
 
-	// Parse org ID (UUID or slug)
-	var orgID uuid.UUID
-	var err error
-	if orgID, err = uuid.Parse(orgIDParam); err != nil {
-		// Try as slug
-		org, err := h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-		if err != nil {
-			if err == postgres.ErrNotFound {
-				http.Error(w, "organization not found", http.StatusNotFound)
-				return
-			}
-			h.logger.Error("failed to resolve organization", zap.Error(err), zap.String("orgId", orgIDParam))
-			http.Error(w, "failed to resolve organization", http.StatusInternalServerError)
-			return
-		}
-		orgID = org.ID
+	orgID, ok := h.resolveOrgID(w, r)
+	if !ok {
+		return
 	}
 
 	// Parse API key ID
@@ -333,16 +357,42 @@ func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 
 	// Revoke key in database
 	revokedAt := time.Now().UTC()
-	_, err = h.runtime.Postgres.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{
-		ID:        apiKey.ID,
-		Version:   apiKey.Version,
-		Status:    "revoked",
-		RevokedAt: revokedAt,
-	}, orgID)
+	actorID := middleware.GetUserID(r.Context())
+	// A concurrent change (e.g. to annotations) does not block revocation
+	err = h.runtime.Postgres.RetryOnConflict(ctx, "api_key.revoke", func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			var err error
+			if apiKey, err = h.runtime.Postgres.GetAPIKeyByID(ctx, apiKey.ID); err != nil {
+				return err
+			}
+			if apiKey.Status == "revoked" || apiKey.RevokedAt != nil {
+				return errKeyRevoked
+			}
+		}
+		if _, err := h.runtime.Postgres.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{
+			ID:        apiKey.ID,
+			Version:   apiKey.Version,
+			Status:    "revoked",
+			RevokedAt: revokedAt,
+		}, orgID); err != nil {
+			return err
+		}
+		event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, audit.ActionAPIKeyRevoke, audit.TargetTypeAPIKey, &apiKey.ID)
+		event = audit.BuildEventFromRequest(event, r)
+		event.Metadata = map[string]any{
+			"fingerprint": apiKey.Fingerprint,
+			"revoked_at":  revokedAt.Format(time.RFC3339),
+		}
+		return h.runtime.EmitAuditInTx(ctx, event)
+	})
 	if err != nil {
-		if err == postgres.ErrOptimisticLock {
+		switch {
+		case errors.Is(err, postgres.ErrOptimisticLock):
 			http.Error(w, "API key was modified concurrently", http.StatusConflict)
 			return
+		case errors.Is(err, errKeyRevoked):
+			http.Error(w, "API key already revoked", http.StatusConflict)
+			return
 		}
 		h.logger.Error("failed to revoke API key", zap.Error(err), zap.String("apiKeyId", apiKeyID.String()))
 		http.Error(w, "failed to revoke API key", http.StatusInternalServerError)
@@ -363,15 +413,7 @@ func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Emit audit event
-	actorID := middleware.GetUserID(r.Context())
-	event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, audit.ActionAPIKeyRevoke, audit.TargetTypeAPIKey, &apiKey.ID)
-	event = audit.BuildEventFromRequest(event, r)
-	event.Metadata = map[string]any{
-		"fingerprint": apiKey.Fingerprint,
-		"revoked_at":  revokedAt.Format(time.RFC3339),
-	}
-	_ = h.runtime.Audit.Emit(ctx, event)
+	h.runtime.SecurityEvents.ObserveAPIKeyRevocation(ctx, orgID, apiKey.ID, actorID, securityevents.ClientIP(r))
 
 	// Record API key revocation
 	metrics.RecordAPIKeyRevoked()
@@ -400,25 +442,11 @@ func (h *Handler) storeEncryptedSecret(ctx context.Context, keyID uuid.UUID, enc
 // Similar to IssueAPIKey but for user principals instead of service accounts.
 func (h *Handler) IssueUserAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	orgIDParam := chi.URLParam(r, "orgId")
 	userIDParam := chi.URLParam(r, "userId")
 
-	// Parse org ID (UUID or slug)
-	var orgID uuid.UUID
-	var err error
-	if orgID, err = uuid.Parse(orgIDParam); err != nil {
-		// Try as slug
-		org, err := h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-		if err != nil {
-			if err == postgres.ErrNotFound {
-				http.Error(w, "organization not found", http.StatusNotFound)
-				return
-			}
-			h.logger.Error("failed to resolve organization", zap.Error(err), zap.String("orgId", orgIDParam))
-			http.Error(w, "failed to resolve organization", http.StatusInternalServerError)
-			return
-		}
-		orgID = org.ID
+	orgID, ok := h.resolveOrgID(w, r)
+	if !ok {
+		return
 	}
 
 	// Parse user ID
@@ -459,21 +487,13 @@ func (h *Handler) IssueUserAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate secure random secret (32 bytes = 256 bits)
-	secretBytes := make([]byte, 32)
-	if _, err := rand.Read(secretBytes); err != nil {
+	secret, fingerprint, err := GenerateSecret()
+	if err != nil {
 		h.logger.Error("failed to generate secret", zap.Error(err))
 		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
 		return
 	}
 
-	// Encode secret as base64url (URL-safe, no padding)
-	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
-
-	// Compute fingerprint (SHA-256 hash of secret)
-	fingerprintHash := sha256.Sum256([]byte(secret))
-	fingerprint := base64.RawURLEncoding.EncodeToString(fingerprintHash[:])
-
 	// Encrypt secret via Vault Transit (stub for now)
 	encryptedSecret, err := h.encryptSecret(ctx, secret)
 	if err != nil {
@@ -549,9 +569,65 @@ func (h *Handler) IssueUserAPIKey(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListAPIKeys handles GET /v1/orgs/{orgId}/api-keys - List all API keys for the organization.
+// Each key includes its recent usage (requests in the last 24h, last error, total spend)
+// when analytics has reported any.
 func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement listing API keys
-	http.Error(w, "not implemented", http.StatusNotImplemented)
+	ctx := r.Context()
+
+	orgID, ok := h.resolveOrgID(w, r)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := h.runtime.Postgres.ListAPIKeysForOrg(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list API keys", zap.Error(err), zap.String("orgId", orgID.String()))
+		http.Error(w, "failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.listItems(ctx, apiKeys)); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// listItems converts keys to list responses and attaches usage summaries.
+// Usage is best-effort: a cache failure is logged and the keys are returned without it.
+func (h *Handler) listItems(ctx context.Context, apiKeys []postgres.APIKey) []APIKeyListItem {
+	ids := make([]uuid.UUID, len(apiKeys))
+	for i, key := range apiKeys {
+		ids[i] = key.ID
+	}
+	usage, err := h.usage.Summaries(ctx, ids)
+	if err != nil {
+		h.logger.Warn("failed to load API key usage", zap.Error(err))
+	}
+
+	items := make([]APIKeyListItem, len(apiKeys))
+	for i, key := range apiKeys {
+		items[i] = APIKeyListItem{
+			APIKeyID:      key.ID.String(),
+			PrincipalType: string(key.PrincipalType),
+			PrincipalID:   key.PrincipalID.String(),
+			Fingerprint:   key.Fingerprint,
+			Status:        key.Status,
+			Scopes:        key.Scopes,
+			IssuedAt:      key.IssuedAt.Format(time.RFC3339),
+		}
+		if key.ExpiresAt != nil {
+			expStr := key.ExpiresAt.Format(time.RFC3339)
+			items[i].ExpiresAt = &expStr
+		}
+		if key.LastUsedAt != nil {
+			usedStr := key.LastUsedAt.Format(time.RFC3339)
+			items[i].LastUsedAt = &usedStr
+		}
+		if summary, ok := usage[key.ID]; ok {
+			items[i].Usage = &summary
+		}
+	}
+	return items
 }
 
 // GetAPIKey handles GET /v1/orgs/{orgId}/api-keys/{apiKeyId} - Get API key details.
@@ -616,21 +692,13 @@ func (h *Handler) IssueUserAPIKeyForMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate secure random secret (32 bytes = 256 bits)
-	secretBytes := make([]byte, 32)
-	if _, err := rand.Read(secretBytes); err != nil {
+	secret, fingerprint, err := GenerateSecret()
+	if err != nil {
 		h.logger.Error("failed to generate secret", zap.Error(err))
 		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
 		return
 	}
 
-	// Encode secret as base64url (URL-safe, no padding)
-	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
-
-	// Compute fingerprint (SHA-256 hash of secret)
-	fingerprintHash := sha256.Sum256([]byte(secret))
-	fingerprint := base64.RawURLEncoding.EncodeToString(fingerprintHash[:])
-
 	// Encrypt secret via Vault Transit (stub for now)
 	encryptedSecret, err := h.encryptSecret(ctx, secret)
 	if err != nil {
@@ -739,38 +807,8 @@ func (h *Handler) ListAPIKeysForMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Convert to response format (without secrets)
-	type APIKeyResponse struct {
-		APIKeyID    string   `json:"apiKeyId"`
-		Fingerprint string   `json:"fingerprint"`
-		Status      string   `json:"status"`
-		Scopes      []string `json:"scopes"`
-		IssuedAt    string   `json:"issuedAt"`
-		ExpiresAt   *string  `json:"expiresAt,omitempty"`
-		LastUsedAt  *string  `json:"lastUsedAt,omitempty"`
-	}
-	
-	responses := make([]APIKeyResponse, len(apiKeys))
-	for i, key := range apiKeys {
-		responses[i] = APIKeyResponse{
-			APIKeyID:    key.ID.String(),
-			Fingerprint: key.Fingerprint,
-			Status:      key.Status,
-			Scopes:      key.Scopes,
-			IssuedAt:    key.IssuedAt.Format(time.RFC3339),
-		}
-		if key.ExpiresAt != nil {
-			expStr := key.ExpiresAt.Format(time.RFC3339)
-			responses[i].ExpiresAt = &expStr
-		}
-		if key.LastUsedAt != nil {
-			usedStr := key.LastUsedAt.Format(time.RFC3339)
-			responses[i].LastUsedAt = &usedStr
-		}
-	}
-	
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(responses); err != nil {
+	if err := json.NewEncoder(w).Encode(h.listItems(ctx, apiKeys)); err != nil {
 		h.logger.Error("failed to encode response", zap.Error(err))
 	}
 }
@@ -0,0 +1,269 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// RestrictionsAnnotation is the annotation key holding a key's network restrictions.
+const RestrictionsAnnotation = "restrictions"
+
+const (
+	maxAllowedCIDRs    = 64
+	maxRequiredHeaders = 8
+)
+
+// errKeyRevoked reports that a key was revoked while it was being updated.
+var errKeyRevoked = errors.New("API key is revoked")
+
+// Restrictions limits where an API key may be used from. The API router
+// enforces them on every request; an empty value means unrestricted.
+type Restrictions struct {
+	// AllowedCIDRs lists networks the caller IP must fall within. Bare IPs are
+	// stored as single-host CIDRs.
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
+	// RequiredHeaders maps header names to the value each request must carry.
+	// A value ending in "*" matches by prefix (e.g. "https://app.example.com/*"
+	// for Referer).
+	RequiredHeaders map[string]string `json:"requiredHeaders,omitempty"`
+}
+
+// IsEmpty reports whether r imposes no restriction.
+func (r *Restrictions) IsEmpty() bool {
+	return r == nil || (len(r.AllowedCIDRs) == 0 && len(r.RequiredHeaders) == 0)
+}
+
+// normalize validates r in place, canonicalising CIDRs and header names.
+func (r *Restrictions) normalize() error {
+	if len(r.AllowedCIDRs) > maxAllowedCIDRs {
+		return fmt.Errorf("at most %d allowedCidrs are supported", maxAllowedCIDRs)
+	}
+	cidrs := make([]string, 0, len(r.AllowedCIDRs))
+	seen := make(map[string]bool, len(r.AllowedCIDRs))
+	for _, raw := range r.AllowedCIDRs {
+		cidr, err := normalizeCIDR(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		if !seen[cidr] {
+			seen[cidr] = true
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	r.AllowedCIDRs = cidrs
+
+	if len(r.RequiredHeaders) > maxRequiredHeaders {
+		return fmt.Errorf("at most %d requiredHeaders are supported", maxRequiredHeaders)
+	}
+	headers := make(map[string]string, len(r.RequiredHeaders))
+	for name, value := range r.RequiredHeaders {
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name == "" || strings.ContainsAny(name, " :\t\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if value == "" || value == "*" {
+			return fmt.Errorf("header %q requires a value", name)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	r.RequiredHeaders = headers
+	return nil
+}
+
+// normalizeCIDR parses a CIDR or bare IP and returns its canonical CIDR form.
+func normalizeCIDR(raw string) (string, error) {
+	if ip := net.ParseIP(raw); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, network, err := net.ParseCIDR(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR %q", raw)
+	}
+	return network.String(), nil
+}
+
+// RestrictionsFromAnnotations reads the restrictions stored on a key, or nil if
+// none. Restrictions that are stored but unreadable or invalid are an error,
+// never nil: callers must not treat such a key as unrestricted.
+func RestrictionsFromAnnotations(annotations map[string]any) (*Restrictions, error) {
+	raw, ok := annotations[RestrictionsAnnotation]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	// Stored annotations come back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode stored restrictions: %w", err)
+	}
+	var restrictions Restrictions
+	if err := json.Unmarshal(data, &restrictions); err != nil {
+		return nil, fmt.Errorf("decode stored restrictions: %w", err)
+	}
+	if err := restrictions.normalize(); err != nil {
+		return nil, fmt.Errorf("stored restrictions: %w", err)
+	}
+	if restrictions.IsEmpty() {
+		return nil, nil
+	}
+	return &restrictions, nil
+}
+
+// GetAPIKeyRestrictions handles GET /v1/orgs/{orgId}/api-keys/{apiKeyId}/restrictions.
+func (h *Handler) GetAPIKeyRestrictions(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := h.loadOrgAPIKey(w, r)
+	if !ok {
+		return
+	}
+	restrictions, err := RestrictionsFromAnnotations(apiKey.Annotations)
+	if err != nil {
+		h.logger.Error("stored API key restrictions are invalid", zap.Error(err), zap.String("apiKeyId", apiKey.ID.String()))
+		http.Error(w, "stored API key restrictions are invalid; replace them with PUT", http.StatusInternalServerError)
+		return
+	}
+	if restrictions == nil {
+		restrictions = &Restrictions{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(restrictions); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// SetAPIKeyRestrictions handles PUT /v1/orgs/{orgId}/api-keys/{apiKeyId}/restrictions.
+// The body replaces any existing restrictions; an empty body object clears them.
+// Changes reach the router once its validation cache expires (about a minute).
+func (h *Handler) SetAPIKeyRestrictions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var restrictions Restrictions
+	if err := json.NewDecoder(r.Body).Decode(&restrictions); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := restrictions.normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	apiKey, ok := h.loadOrgAPIKey(w, r)
+	if !ok {
+		return
+	}
+	if apiKey.Status == "revoked" || apiKey.RevokedAt != nil {
+		http.Error(w, "API key is revoked", http.StatusConflict)
+		return
+	}
+
+	// Concurrent annotation changes are re-read and kept
+	err := h.runtime.Postgres.RetryOnConflict(ctx, "api_key.restrict", func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			var err error
+			if apiKey, err = h.runtime.Postgres.GetAPIKeyByID(ctx, apiKey.ID); err != nil {
+				return err
+			}
+			if apiKey.Status == "revoked" || apiKey.RevokedAt != nil {
+				return errKeyRevoked
+			}
+		}
+		annotations := make(map[string]any, len(apiKey.Annotations)+1)
+		for k, v := range apiKey.Annotations {
+			annotations[k] = v
+		}
+		if restrictions.IsEmpty() {
+			delete(annotations, RestrictionsAnnotation)
+		} else {
+			annotations[RestrictionsAnnotation] = restrictions
+		}
+		_, err := h.runtime.Postgres.UpdateAPIKeyAnnotations(ctx, apiKey.OrgID, apiKey.ID, apiKey.Version, annotations)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrOptimisticLock):
+			http.Error(w, "API key was modified concurrently", http.StatusConflict)
+			return
+		case errors.Is(err, errKeyRevoked):
+			http.Error(w, "API key is revoked", http.StatusConflict)
+			return
+		}
+		h.logger.Error("failed to update API key restrictions", zap.Error(err), zap.String("apiKeyId", apiKey.ID.String()))
+		http.Error(w, "failed to update API key restrictions", http.StatusInternalServerError)
+		return
+	}
+
+	actorID := middleware.GetUserID(ctx)
+	event := audit.BuildEvent(apiKey.OrgID, actorID, audit.ActorTypeUser, audit.ActionAPIKeyRestrict, audit.TargetTypeAPIKey, &apiKey.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"fingerprint":      apiKey.Fingerprint,
+		"allowed_cidrs":    restrictions.AllowedCIDRs,
+		"required_headers": headerNames(restrictions.RequiredHeaders),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(restrictions); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// loadOrgAPIKey resolves {orgId} and {apiKeyId} and loads the key, writing
+// an error response and returning false when it cannot.
+func (h *Handler) loadOrgAPIKey(w http.ResponseWriter, r *http.Request) (postgres.APIKey, bool) {
+	ctx := r.Context()
+
+	orgID, ok := h.resolveOrgID(w, r)
+	if !ok {
+		return postgres.APIKey{}, false
+	}
+
+	apiKeyID, err := uuid.Parse(chi.URLParam(r, "apiKeyId"))
+	if err != nil {
+		http.Error(w, "invalid API key ID", http.StatusBadRequest)
+		return postgres.APIKey{}, false
+	}
+
+	apiKey, err := h.runtime.Postgres.GetAPIKeyByID(ctx, apiKeyID)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return postgres.APIKey{}, false
+		}
+		h.logger.Error("failed to get API key", zap.Error(err), zap.String("apiKeyId", apiKeyID.String()))
+		http.Error(w, "failed to retrieve API key", http.StatusInternalServerError)
+		return postgres.APIKey{}, false
+	}
+	if apiKey.OrgID != orgID {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return postgres.APIKey{}, false
+	}
+	return apiKey, true
+}
+
+// headerNames lists header names only; required values can be shared secrets.
+func headerNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,117 @@
+package apikeys
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictionsNormalize(t *testing.T) {
+	restrictions := Restrictions{
+		AllowedCIDRs: []string{" 203.0.113.7 ", "10.1.2.3/8", "2001:db8::1", "2001:db8::/32", "10.0.0.0/8"},
+		RequiredHeaders: map[string]string{
+			"referer":     " https://app.example.com/* ",
+			"x-client-id": "dashboard",
+		},
+	}
+	require.NoError(t, restrictions.normalize())
+	require.Equal(t, []string{"203.0.113.7/32", "10.0.0.0/8", "2001:db8::1/128", "2001:db8::/32"}, restrictions.AllowedCIDRs)
+	require.Equal(t, map[string]string{
+		"Referer":     "https://app.example.com/*",
+		"X-Client-Id": "dashboard",
+	}, restrictions.RequiredHeaders)
+}
+
+func TestRestrictionsNormalizeRejectsInvalid(t *testing.T) {
+	tooManyCIDRs := make([]string, maxAllowedCIDRs+1)
+	for i := range tooManyCIDRs {
+		tooManyCIDRs[i] = "10.0.0.1"
+	}
+	tooManyHeaders := make(map[string]string, maxRequiredHeaders+1)
+	for i := 0; i <= maxRequiredHeaders; i++ {
+		tooManyHeaders["X-Header-"+strings.Repeat("a", i+1)] = "value"
+	}
+
+	tests := []struct {
+		name         string
+		restrictions Restrictions
+	}{
+		{name: "prefix too long", restrictions: Restrictions{AllowedCIDRs: []string{"10.0.0.0/33"}}},
+		{name: "not an address", restrictions: Restrictions{AllowedCIDRs: []string{"office-network"}}},
+		{name: "empty entry", restrictions: Restrictions{AllowedCIDRs: []string{"10.0.0.0/8", "  "}}},
+		{name: "address range", restrictions: Restrictions{AllowedCIDRs: []string{"10.0.0.1-10.0.0.9"}}},
+		{name: "too many CIDRs", restrictions: Restrictions{AllowedCIDRs: tooManyCIDRs}},
+		{name: "header name with colon", restrictions: Restrictions{RequiredHeaders: map[string]string{"Referer:": "x"}}},
+		{name: "empty header name", restrictions: Restrictions{RequiredHeaders: map[string]string{" ": "x"}}},
+		{name: "empty header value", restrictions: Restrictions{RequiredHeaders: map[string]string{"Referer": " "}}},
+		{name: "wildcard-only header value", restrictions: Restrictions{RequiredHeaders: map[string]string{"Referer": "*"}}},
+		{name: "too many headers", restrictions: Restrictions{RequiredHeaders: tooManyHeaders}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.restrictions.normalize())
+		})
+	}
+}
+
+func TestRestrictionsFromAnnotations(t *testing.T) {
+	// As read back from JSONB
+	stored := map[string]any{
+		"team": "payments",
+		RestrictionsAnnotation: map[string]any{
+			"allowedCidrs":    []any{"203.0.113.0/24"},
+			"requiredHeaders": map[string]any{"Referer": "https://app.example.com/*"},
+		},
+	}
+	restrictions, err := RestrictionsFromAnnotations(stored)
+	require.NoError(t, err)
+	require.Equal(t, &Restrictions{
+		AllowedCIDRs:    []string{"203.0.113.0/24"},
+		RequiredHeaders: map[string]string{"Referer": "https://app.example.com/*"},
+	}, restrictions)
+
+	for name, annotations := range map[string]map[string]any{
+		"no annotations":        nil,
+		"no restrictions":       {"team": "payments"},
+		"null restrictions":     {RestrictionsAnnotation: nil},
+		"cleared restrictions":  {RestrictionsAnnotation: map[string]any{}},
+		"empty restriction set": {RestrictionsAnnotation: map[string]any{"allowedCidrs": []any{}}},
+	} {
+		restrictions, err := RestrictionsFromAnnotations(annotations)
+		require.NoError(t, err, name)
+		require.Nil(t, restrictions, name)
+	}
+}
+
+func TestRestrictionsFromAnnotationsFailsClosed(t *testing.T) {
+	// Stored restrictions that cannot be enforced must not read as unrestricted
+	for name, raw := range map[string]any{
+		"not an object":        "203.0.113.0/24",
+		"CIDRs not a list":     map[string]any{"allowedCidrs": "203.0.113.0/24"},
+		"invalid stored CIDR":  map[string]any{"allowedCidrs": []any{"203.0.113.0/24", "10.0.0.0/33"}},
+		"headers not a map":    map[string]any{"requiredHeaders": []any{"Referer"}},
+		"invalid header value": map[string]any{"requiredHeaders": map[string]any{"Referer": ""}},
+	} {
+		restrictions, err := RestrictionsFromAnnotations(map[string]any{RestrictionsAnnotation: raw})
+		require.Error(t, err, name)
+		require.Nil(t, restrictions, name)
+	}
+}
+
+func TestSetAPIKeyRestrictionsRejectsInvalidBody(t *testing.T) {
+	h := &Handler{}
+	for _, body := range []string{
+		`{"allowedCidrs": ["10.0.0.0/33"]}`,
+		`{"allowedCidrs": "10.0.0.0/8"}`,
+		`{"requiredHeaders": {"Referer": "*"}}`,
+		`not json`,
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/v1/orgs/org/api-keys/key/restrictions", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.SetAPIKeyRestrictions(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
@@ -0,0 +1,265 @@
+package apikeys
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// apiKeyHeader carries an existing API key secret when minting a temporary key
+// without an OAuth token.
+const apiKeyHeader = "X-API-Key"
+
+// temporaryAnnotation marks keys minted by IssueTemporaryKey.
+const temporaryAnnotation = "temporary"
+
+// IssueTemporaryKeyRequest represents the payload for minting a short-lived key.
+type IssueTemporaryKeyRequest struct {
+	Scope       string `json:"scope"`
+	TTLMinutes  int    `json:"ttlMinutes,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// TemporaryKeyResponse is an issued short-lived key (secret shown once).
+type TemporaryKeyResponse struct {
+	IssuedAPIKeyResponse
+	Scope         string  `json:"scope"`
+	PrincipalType string  `json:"principalType"`
+	PrincipalID   string  `json:"principalId"`
+	ParentKeyID   *string `json:"parentApiKeyId,omitempty"`
+}
+
+// issuer identifies who is minting a temporary key.
+type issuer struct {
+	orgID         uuid.UUID
+	principalType postgres.PrincipalType
+	principalID   uuid.UUID
+	// parent is set when the caller authenticated with an API key
+	parent *postgres.APIKey
+}
+
+// RegisterTemporaryRoutes mounts POST /v1/api-keys/temporary. The route
+// authenticates itself (OAuth bearer token or X-API-Key), so it must be
+// registered outside the RequireAuth group.
+func RegisterTemporaryRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger) {
+	if rt == nil || rt.Postgres == nil {
+		return
+	}
+	handler := &Handler{
+		runtime: rt,
+		logger:  logger,
+	}
+	requireAuth := middleware.RequireAuth(rt, logger)
+	router.Post("/v1/api-keys/temporary", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) != "" {
+			handler.IssueTemporaryKey(w, r)
+			return
+		}
+		requireAuth(http.HandlerFunc(handler.IssueTemporaryKey)).ServeHTTP(w, r)
+	})
+}
+
+// IssueTemporaryKey handles POST /v1/api-keys/temporary.
+// Mints a single-scope key for the caller's principal that expires after at
+// most TEMPORARY_KEY_MAX_TTL. The secret is never encrypted or stored.
+func (h *Handler) IssueTemporaryKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	caller, status, msg := h.resolveIssuer(r)
+	if caller == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	var req IssueTemporaryKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request payload", zap.Error(err))
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	scope := strings.TrimSpace(req.Scope)
+	if scope == "" || strings.ContainsAny(scope, " ,") {
+		http.Error(w, "exactly one scope is required", http.StatusBadRequest)
+		return
+	}
+	if caller.parent != nil && !containsScope(caller.parent.Scopes, scope) {
+		http.Error(w, "scope is not granted to the parent API key", http.StatusForbidden)
+		return
+	}
+
+	maxTTL := h.runtime.Config.TemporaryKeyMaxTTL
+	ttl := h.runtime.Config.TemporaryKeyDefaultTTL
+	if req.TTLMinutes < 0 {
+		http.Error(w, "ttlMinutes must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl > maxTTL {
+		http.Error(w, fmt.Sprintf("ttlMinutes exceeds the maximum of %d", int(maxTTL.Minutes())), http.StatusBadRequest)
+		return
+	}
+	expiresAt := time.Now().UTC().Add(ttl)
+	// Never outlive the key it was minted from
+	if caller.parent != nil && caller.parent.ExpiresAt != nil && caller.parent.ExpiresAt.Before(expiresAt) {
+		expiresAt = *caller.parent.ExpiresAt
+	}
+
+	secret, fingerprint, err := GenerateSecret()
+	if err != nil {
+		h.logger.Error("failed to generate secret", zap.Error(err))
+		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
+		return
+	}
+
+	annotations := map[string]any{temporaryAnnotation: true}
+	if req.DisplayName != "" {
+		annotations["display_name"] = req.DisplayName
+	}
+	if caller.parent != nil {
+		annotations["parent_api_key_id"] = caller.parent.ID.String()
+	}
+
+	apiKey, err := h.runtime.Postgres.CreateAPIKey(ctx, postgres.CreateAPIKeyParams{
+		OrgID:         caller.orgID,
+		PrincipalType: caller.principalType,
+		PrincipalID:   caller.principalID,
+		Fingerprint:   fingerprint,
+		Status:        "active",
+		Scopes:        []string{scope},
+		ExpiresAt:     &expiresAt,
+		Annotations:   annotations,
+	})
+	if err != nil {
+		h.logger.Error("failed to create temporary API key", zap.Error(err), zap.String("orgId", caller.orgID.String()))
+		http.Error(w, "failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	// Emit audit event
+	actorType := audit.ActorTypeUser
+	if caller.principalType == postgres.PrincipalTypeServiceAccount {
+		actorType = audit.ActorTypeServiceAccount
+	}
+	event := audit.BuildEvent(caller.orgID, caller.principalID, actorType, audit.ActionAPIKeyIssue, audit.TargetTypeAPIKey, &apiKey.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"principal_type": string(caller.principalType),
+		"principal_id":   caller.principalID.String(),
+		"fingerprint":    fingerprint,
+		"temporary":      true,
+		"scope":          scope,
+		"expires_at":     expiresAt.Format(time.RFC3339),
+	}
+	if caller.parent != nil {
+		event.Metadata["parent_api_key_id"] = caller.parent.ID.String()
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	metrics.RecordAPIKeyIssued()
+
+	expStr := expiresAt.Format(time.RFC3339)
+	resp := TemporaryKeyResponse{
+		IssuedAPIKeyResponse: IssuedAPIKeyResponse{
+			APIKeyID:    apiKey.ID.String(),
+			Secret:      secret, // Only time secret is returned
+			Fingerprint: fingerprint,
+			Status:      apiKey.Status,
+			ExpiresAt:   &expStr,
+		},
+		Scope:         scope,
+		PrincipalType: string(caller.principalType),
+		PrincipalID:   caller.principalID.String(),
+	}
+	if caller.parent != nil {
+		parentID := caller.parent.ID.String()
+		resp.ParentKeyID = &parentID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// resolveIssuer authenticates the caller from X-API-Key or the OAuth context
+// set by RequireAuth. On failure it returns nil with an HTTP status and message.
+func (h *Handler) resolveIssuer(r *http.Request) (*issuer, int, string) {
+	ctx := r.Context()
+
+	if secret := r.Header.Get(apiKeyHeader); secret != "" {
+		fingerprintHash := sha256.Sum256([]byte(secret))
+		fingerprint := base64.RawURLEncoding.EncodeToString(fingerprintHash[:])
+		parent, err := h.runtime.Postgres.GetAPIKeyByFingerprintAnyOrg(ctx, fingerprint)
+		if err != nil {
+			if err == postgres.ErrNotFound {
+				return nil, http.StatusUnauthorized, "invalid API key"
+			}
+			h.logger.Error("failed to look up parent API key", zap.Error(err))
+			return nil, http.StatusInternalServerError, "failed to validate API key"
+		}
+		if parent.Status != "active" || parent.RevokedAt != nil {
+			return nil, http.StatusUnauthorized, "API key is revoked"
+		}
+		if parent.ExpiresAt != nil && parent.ExpiresAt.Before(time.Now().UTC()) {
+			return nil, http.StatusUnauthorized, "API key is expired"
+		}
+		// Temporary keys cannot mint further keys, so expiry cannot be extended by chaining
+		if temporary, _ := parent.Annotations[temporaryAnnotation].(bool); temporary {
+			return nil, http.StatusForbidden, "temporary API keys cannot mint further keys"
+		}
+		return &issuer{
+			orgID:         parent.OrgID,
+			principalType: parent.PrincipalType,
+			principalID:   parent.PrincipalID,
+			parent:        &parent,
+		}, 0, ""
+	}
+
+	orgID := middleware.GetOrgID(ctx)
+	userID := middleware.GetUserID(ctx)
+	if orgID == uuid.Nil || userID == uuid.Nil {
+		return nil, http.StatusUnauthorized, "unauthorized"
+	}
+	user, err := h.runtime.Postgres.GetUserByID(ctx, orgID, userID)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			return nil, http.StatusUnauthorized, "user not found"
+		}
+		h.logger.Error("failed to get user", zap.Error(err), zap.String("userId", userID.String()))
+		return nil, http.StatusInternalServerError, "failed to retrieve user"
+	}
+	if user.Status != "active" {
+		return nil, http.StatusForbidden, "user is not active"
+	}
+	return &issuer{
+		orgID:         orgID,
+		principalType: postgres.PrincipalTypeUser,
+		principalID:   userID,
+	}, 0, ""
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
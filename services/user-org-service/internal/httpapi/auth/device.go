@@ -0,0 +1,208 @@
+// Package auth provides the device authorization endpoints (RFC 8628).
+//
+// Purpose:
+//
+//	CLI tools such as admin-cli log users in without a client secret:
+//	  1. The CLI calls POST /v1/auth/device/code and shows the user code
+//	  2. The user opens the verification URI in the console, which looks the
+//	     code up (GET /v1/auth/device) and approves or denies it
+//	  3. The CLI polls POST /v1/auth/device/token until it gets tokens
+//
+// Debugging Notes:
+//   - Routes exist only when DEVICE_FLOW_ENABLED=true
+//   - The code and token endpoints accept RFC 8628 form bodies or JSON
+//   - Token polls return authorization_pending or slow_down until approved
+//   - Approve/deny require the approving user's access token
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+)
+
+// deviceDecisionRequest approves or denies a device authorization.
+type deviceDecisionRequest struct {
+	UserCode string `json:"userCode"`
+}
+
+// deviceAuthorizationResponse describes a pending device authorization to the approving user.
+type deviceAuthorizationResponse struct {
+	UserCode  string    `json:"userCode"`
+	ClientID  string    `json:"clientId"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// DeviceCode handles POST /v1/auth/device/code - start a device authorization.
+func (h *Handler) DeviceCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	form, err := deviceForm(r)
+	if err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	clientID := form.Get("client_id")
+	if clientID == "" {
+		clientID = h.runtime.Config.DeviceClientID
+	}
+
+	resp, err := h.runtime.DeviceFlow.Authorize(ctx, clientID, strings.Fields(form.Get("scope")))
+	if err != nil {
+		// Reuse fosite's error writer for RFC 6749 error bodies
+		h.runtime.Provider.WriteAccessError(ctx, w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// DeviceToken handles POST /v1/auth/device/token - poll for the tokens of a device code.
+func (h *Handler) DeviceToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	form, err := deviceForm(r)
+	if err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	form.Set("grant_type", oauth.GrantTypeDeviceCode)
+	if form.Get("client_id") == "" {
+		form.Set("client_id", h.runtime.Config.DeviceClientID)
+	}
+
+	req := cloneRequestWithForm(r, form)
+	session := &oauth.Session{}
+
+	accessRequest, err := h.runtime.Provider.NewAccessRequest(ctx, req, session)
+	if err != nil {
+		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+		return
+	}
+
+	response, err := h.runtime.Provider.NewAccessResponse(ctx, accessRequest)
+	if err != nil {
+		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+		return
+	}
+
+	h.runtime.Provider.WriteAccessResponse(ctx, w, accessRequest, response)
+}
+
+// DeviceLookup handles GET /v1/auth/device?user_code= - show a pending authorization before approval.
+func (h *Handler) DeviceLookup(w http.ResponseWriter, r *http.Request) {
+	auth, err := h.runtime.DeviceFlow.Lookup(r.Context(), r.URL.Query().Get("user_code"))
+	if err != nil {
+		h.writeDeviceError(w, err)
+		return
+	}
+	writeDeviceAuthorization(w, auth)
+}
+
+// DeviceApprove handles POST /v1/auth/device/approve - grant the device the
+// authenticated user's access, limited to the scopes the user holds.
+func (h *Handler) DeviceApprove(w http.ResponseWriter, r *http.Request) {
+	h.decideDevice(w, r, true)
+}
+
+// DeviceDeny handles POST /v1/auth/device/deny - reject a device authorization.
+func (h *Handler) DeviceDeny(w http.ResponseWriter, r *http.Request) {
+	h.decideDevice(w, r, false)
+}
+
+func (h *Handler) decideDevice(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx := r.Context()
+	user := middleware.GetAuthenticatedUser(ctx)
+	if user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload deviceDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.UserCode == "" {
+		http.Error(w, "userCode is required", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		auth   oauth.DeviceAuthorization
+		err    error
+		action = audit.ActionDeviceDeny
+	)
+	if approve {
+		action = audit.ActionDeviceApprove
+		auth, err = h.runtime.DeviceFlow.Approve(ctx, payload.UserCode, user.UserID.String(), user.OrgID.String(), user.Scopes)
+	} else {
+		auth, err = h.runtime.DeviceFlow.Lookup(ctx, payload.UserCode)
+		if err == nil {
+			err = h.runtime.DeviceFlow.Deny(ctx, payload.UserCode)
+			auth.Status = oauth.DeviceStatusDenied
+		}
+	}
+	if err != nil {
+		h.writeDeviceError(w, err)
+		return
+	}
+
+	event := audit.BuildEvent(user.OrgID, user.UserID, audit.ActorTypeUser, action, audit.TargetTypeUser, &user.UserID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{"client_id": auth.ClientID, "scopes": auth.GrantedScopes}
+	if err := h.runtime.Audit.Emit(ctx, event); err != nil {
+		h.logger.Warn("failed to emit device authorization audit event", zap.Error(err))
+	}
+
+	writeDeviceAuthorization(w, auth)
+}
+
+func (h *Handler) writeDeviceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, oauth.ErrDeviceCodeNotFound) {
+		http.Error(w, "unknown or expired user code", http.StatusNotFound)
+		return
+	}
+	h.logger.Error("device authorization lookup failed", zap.Error(err))
+	http.Error(w, "failed to load device authorization", http.StatusInternalServerError)
+}
+
+func writeDeviceAuthorization(w http.ResponseWriter, auth oauth.DeviceAuthorization) {
+	scopes := auth.GrantedScopes
+	if auth.Status == oauth.DeviceStatusPending {
+		scopes = auth.RequestedScopes
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(deviceAuthorizationResponse{
+		UserCode:  auth.UserCode,
+		ClientID:  auth.ClientID,
+		Scopes:    scopes,
+		Status:    auth.Status,
+		ExpiresAt: auth.ExpiresAt,
+	})
+}
+
+// deviceForm reads an RFC 8628 form body, or the same fields as JSON.
+func deviceForm(r *http.Request) (url.Values, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		form := url.Values{}
+		for key, value := range payload {
+			form.Set(key, value)
+		}
+		return form, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	return r.PostForm, nil
+}
@@ -0,0 +1,81 @@
+// Package auth provides the JWKS and OIDC discovery endpoints.
+//
+// Purpose:
+//
+//	Downstream services validate JWT access tokens locally: they read the
+//	issuer metadata from /.well-known/openid-configuration and the signing
+//	keys from /.well-known/jwks.json, re-fetching the key set when a token
+//	names an unknown kid.
+//
+// Debugging Notes:
+//   - /.well-known/jwks.json returns 404 when ACCESS_TOKEN_FORMAT=opaque
+//   - The issuer is JWT_ISSUER, then OIDC_BASE_URL, then http://localhost:<port>
+//   - Responses may be cached for five minutes; rotated keys stay published
+//     until the tokens they signed have expired
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// discoveryCacheControl lets clients cache the key set and metadata briefly.
+const discoveryCacheControl = "public, max-age=300"
+
+// discoveryDocument is the subset of OpenID Provider Metadata this service supports.
+type discoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint,omitempty"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	AccessTokenSigningAlgValues       []string `json:"access_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// JWKS handles GET /.well-known/jwks.json - publish the token verification keys.
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	if h.runtime.KeySet == nil {
+		http.Error(w, "access tokens are not JWTs", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", discoveryCacheControl)
+	_ = json.NewEncoder(w).Encode(h.runtime.KeySet.PublicJWKS())
+}
+
+// OpenIDConfiguration handles GET /.well-known/openid-configuration - publish issuer metadata.
+func (h *Handler) OpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	issuer := strings.TrimRight(h.runtime.Issuer, "/")
+	doc := discoveryDocument{
+		Issuer:                            issuer,
+		UserinfoEndpoint:                  issuer + "/v1/auth/userinfo",
+		RevocationEndpoint:                issuer + "/v1/auth/logout",
+		IntrospectionEndpoint:             issuer + "/v1/auth/introspect",
+		GrantTypesSupported:               []string{"password", "refresh_token"},
+		ResponseTypesSupported:            []string{"token"},
+		SubjectTypesSupported:             []string{"public"},
+		ScopesSupported:                   []string{"openid", "profile", "email", "offline_access"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "client_secret_basic", "none"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "jti", "scp", "org_id", "user_id"},
+	}
+	if h.runtime.KeySet != nil {
+		doc.JWKSURI = issuer + "/.well-known/jwks.json"
+		doc.AccessTokenSigningAlgValues = []string{"RS256"}
+	}
+	if h.runtime.DeviceFlow != nil {
+		doc.DeviceAuthorizationEndpoint = issuer + "/v1/auth/device/code"
+		doc.GrantTypesSupported = append(doc.GrantTypesSupported, "urn:ietf:params:oauth:grant-type:device_code")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", discoveryCacheControl)
+	_ = json.NewEncoder(w).Encode(doc)
+}
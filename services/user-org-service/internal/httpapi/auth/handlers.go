@@ -17,6 +17,12 @@
 //   - Login: Resource owner password credentials grant (POST /v1/auth/login)
 //   - Refresh: Refresh token exchange (POST /v1/auth/refresh)
 //   - Logout: Token revocation (POST /v1/auth/logout)
+//   - Session cookie mode: HTTP-only token cookies and CSRF tokens (GET /v1/auth/csrf)
+//   - Magic links: passwordless login for opted-in orgs (POST /v1/auth/magic-link[/verify])
+//   - Device flow: RFC 8628 device codes for CLI logins (/v1/auth/device/*)
+//   - Discovery: JWKS and OIDC metadata for JWT access tokens (/.well-known/*)
+//   - Introspection: RFC 7662 token status for resource servers (POST /v1/auth/introspect)
+//   - Service tokens: client credentials tokens for workload identities (POST /v1/auth/service-token)
 //   - Request transformation: JSON → form-urlencoded for Fosite compatibility
 //
 // Requirements Reference:
@@ -29,6 +35,7 @@
 //   - Default client credentials used if not provided in request
 //   - OrgID validation checks user membership in the specified org via database query
 //   - If org_id is not provided, it is looked up from the user's record
+//   - Login and refresh are denied (access_denied) while the org or its parent is suspended
 //   - Errors are written via Fosite's WriteAccessError/WriteRevocationResponse
 //   - Session org_id and user_id are set from request payload and authenticated user
 //
@@ -44,6 +51,7 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -52,17 +60,20 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/ory/fosite"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/requestid"
+
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/orgs"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
@@ -72,11 +83,18 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, idpRegistry *IdPRe
 	if rt == nil || rt.Provider == nil {
 		return
 	}
-	handler := &Handler{runtime: rt, idpRegistry: idpRegistry, logger: logger}
+	handler := &Handler{runtime: rt, idpRegistry: idpRegistry, logger: logger, reset: newPasswordReset(rt, logger), magic: newMagicLink(rt, logger)}
+	// Token verification keys and issuer metadata for downstream services
+	router.Get("/.well-known/jwks.json", handler.JWKS)
+	router.Get("/.well-known/openid-configuration", handler.OpenIDConfiguration)
 	router.Route("/v1/auth", func(r chi.Router) {
 		r.Post("/login", handler.Login)
 		r.Post("/refresh", handler.Refresh)
 		r.Post("/logout", handler.Logout)
+		// RFC 7662 introspection for resource servers (client or bearer authenticated)
+		r.Post("/introspect", handler.Introspect)
+		// Double-submit CSRF token for session cookie mode
+		r.Get("/csrf", handler.CSRFToken)
 
 		// User info endpoint (requires authentication)
 		// Register as a route group with auth middleware
@@ -94,12 +112,31 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, idpRegistry *IdPRe
 		r.Post("/recover/verify", handler.VerifyRecoveryToken)
 		r.Post("/recover/reset", handler.ResetPassword)
 
+		// Passwordless login for orgs that enable magic links
+		r.Post("/magic-link", handler.RequestMagicLink)
+		r.Post("/magic-link/verify", handler.VerifyMagicLink)
+
 		// Admin recovery approval routes (require authentication)
 		r.Post("/recover/approve", handler.ApproveRecovery)
 		r.Post("/recover/reject", handler.RejectRecovery)
 
-		// API key validation (public endpoint for service-to-service auth)
-		r.Post("/validate-api-key", handler.ValidateAPIKey)
+		// Service tokens for workload identities
+		r.Post("/service-token", handler.ServiceToken)
+
+		// API key validation (called by the router with its service token)
+		r.With(middleware.RequireService(rt, logger)).Post("/validate-api-key", handler.ValidateAPIKey)
+
+		// Device authorization grant (RFC 8628) for CLI logins
+		if rt.DeviceFlow != nil {
+			r.Post("/device/code", handler.DeviceCode)
+			r.Post("/device/token", handler.DeviceToken)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAuth(rt, logger))
+				r.Get("/device", handler.DeviceLookup)
+				r.Post("/device/approve", handler.DeviceApprove)
+				r.Post("/device/deny", handler.DeviceDeny)
+			})
+		}
 	})
 }
 
@@ -108,6 +145,8 @@ type Handler struct {
 	runtime     *bootstrap.Runtime
 	idpRegistry *IdPRegistry // IdP registry for OIDC federation (optional, nil if not configured)
 	logger      *zap.Logger
+	reset       *passwordReset
+	magic       *magicLink
 }
 
 type loginRequest struct {
@@ -118,6 +157,8 @@ type loginRequest struct {
 	ClientSecret string `json:"client_secret"`
 	Scope        string `json:"scope"`
 	OrgID        string `json:"org_id"`
+	// UseCookies returns the tokens as HTTP-only session cookies instead of in the body.
+	UseCookies bool `json:"useCookies,omitempty"`
 }
 
 type refreshRequest struct {
@@ -137,8 +178,7 @@ type logoutRequest struct {
 // Login handles the resource-owner password credentials flow.
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	requestID := chimiddleware.GetReqID(ctx)
-	logger := h.logger.With(zap.String("request_id", requestID), zap.String("handler", "Login"))
+	logger := requestid.Logger(ctx, h.logger).With(zap.String("handler", "Login"))
 
 	logger.Info("=== LOGIN REQUEST START ===",
 		zap.String("origin", r.Header.Get("Origin")),
@@ -158,7 +198,12 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		zap.String("org_id", payload.OrgID),
 		zap.String("client_id", payload.ClientID),
 		zap.Bool("has_password", payload.Password != ""),
-		zap.String("payload_client_secret", payload.ClientSecret))
+		zap.String("payload_client_secret", payload.ClientSecret),
+		zap.Bool("use_cookies", payload.UseCookies))
+	if payload.UseCookies && !h.cookies().Enabled {
+		http.Error(w, "session cookies are not enabled", http.StatusBadRequest)
+		return
+	}
 
 	logger.Debug("creating form data from payload")
 	form := url.Values{}
@@ -271,6 +316,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 								"lockout_until":   lockoutUntil.Format(time.RFC3339),
 							}
 							_ = h.runtime.Audit.Emit(ctx, event)
+							h.runtime.SecurityEvents.ObserveLockout(ctx, orgID, user.ID, securityevents.ClientIP(r))
 						}
 					}
 				}
@@ -356,6 +402,13 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 			}
 			logger.Debug("user org ID retrieved", zap.String("org_id", orgID.String()))
 		}
+		// Suspended orgs cannot start new sessions
+		if err := h.ensureOrgNotSuspended(bgCtx, orgID); err != nil {
+			logger.Warn("login rejected", zap.Error(err), zap.String("org_id", orgID.String()))
+			metrics.RecordAuthFailure("password", "org_suspended")
+			h.runtime.Provider.WriteAccessError(bgCtx, w, accessRequest, err)
+			return
+		}
 		sess.OrgID = orgID.String()
 		sess.UserID = userID
 		logger.Debug("session org_id and user_id set",
@@ -388,6 +441,9 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 			}
 			sess.Extra["mfa_verified_at"] = time.Now().UTC().Format(time.RFC3339)
 		}
+
+		// Compare this login's location with the previous one (impossible travel)
+		h.runtime.SecurityEvents.ObserveLogin(bgCtx, orgID, userUUID, securityevents.ClientIP(r))
 	} else {
 		// Session is not an oauth.Session - this should not happen if NewAccessRequest succeeded
 		// but handle it gracefully
@@ -425,7 +481,11 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		zap.String("user_uuid", userUUID.String()))
 
 	logger.Info("=== LOGIN REQUEST END - SUCCESS ===")
-	h.runtime.Provider.WriteAccessResponse(bgCtx, w, accessRequest, response)
+	if payload.UseCookies {
+		h.writeCookieSession(w, response)
+	} else {
+		h.runtime.Provider.WriteAccessResponse(bgCtx, w, accessRequest, response)
+	}
 
 	// Explicitly flush the response to ensure it's sent to the client immediately
 	// This is particularly important for Playwright/browser-based clients that may
@@ -441,10 +501,21 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var payload refreshRequest
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
 		http.Error(w, "invalid request payload", http.StatusBadRequest)
 		return
 	}
+	// Cookie sessions refresh from the refresh cookie; the body may be empty
+	fromCookie := false
+	if payload.RefreshToken == "" && h.cookies().Enabled {
+		if payload.RefreshToken = middleware.RefreshToken(r); payload.RefreshToken != "" {
+			if !middleware.ValidCSRF(r) {
+				http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+				return
+			}
+			fromCookie = true
+		}
+	}
 
 	form := url.Values{}
 	form.Set("grant_type", "refresh_token")
@@ -471,6 +542,15 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
 		return
 	}
+	// Sessions of suspended orgs are not extended
+	if sess, ok := accessRequest.GetSession().(*oauth.Session); ok && sess.OrgID != "" {
+		if orgID, err := uuid.Parse(sess.OrgID); err == nil {
+			if err := h.ensureOrgNotSuspended(ctx, orgID); err != nil {
+				h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+				return
+			}
+		}
+	}
 
 	response, err := h.runtime.Provider.NewAccessResponse(ctx, accessRequest)
 	if err != nil {
@@ -478,6 +558,10 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if fromCookie {
+		h.writeCookieSession(w, response)
+		return
+	}
 	h.runtime.Provider.WriteAccessResponse(ctx, w, accessRequest, response)
 }
 
@@ -485,10 +569,23 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var payload logoutRequest
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
 		http.Error(w, "invalid request payload", http.StatusBadRequest)
 		return
 	}
+	// Cookie sessions revoke the refresh token (and with it the access token)
+	// and clear the cookies
+	cookies := h.cookies()
+	if payload.Token == "" && cookies.Enabled {
+		if token, hint := cookieSessionToken(r); token != "" {
+			if !middleware.ValidCSRF(r) {
+				http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+				return
+			}
+			payload.Token, payload.TokenTypeHint = token, hint
+			cookies.Clear(w)
+		}
+	}
 
 	form := url.Values{}
 	form.Set("token", payload.Token)
@@ -516,6 +613,23 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	h.runtime.Provider.WriteRevocationResponse(ctx, w, err)
 }
 
+// ensureOrgNotSuspended returns an access-denied error if the org, or the org
+// it is a project of, is suspended.
+func (h *Handler) ensureOrgNotSuspended(ctx context.Context, orgID uuid.UUID) error {
+	org, err := h.runtime.Postgres.GetOrg(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("get org: %w", err)
+	}
+	suspended, err := orgs.OrgSuspended(ctx, h.runtime.Postgres, org)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		return fosite.ErrAccessDenied.WithHint("The organization is suspended.")
+	}
+	return nil
+}
+
 // enforceMFA checks if MFA is required for the user and verifies the provided code.
 // Returns (true, nil) if MFA is verified or not required.
 // Returns (false, error) if MFA is required but code is invalid or missing.
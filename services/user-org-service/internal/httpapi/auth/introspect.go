@@ -0,0 +1,102 @@
+// Package auth provides the token introspection endpoint (RFC 7662).
+//
+// Purpose:
+//
+//	Resource servers such as api-router-service check platform access
+//	tokens here (through shared-go/auth/tokens) instead of sharing storage
+//	with this service. The response adds iss and the org_id/user_id/roles
+//	private claims to Fosite's standard fields.
+//
+// Debugging Notes:
+//   - Callers authenticate with a registered client (HTTP Basic) or a bearer token
+//   - Only access tokens are reported active; refresh tokens are not bearer credentials
+//   - Inactive, unknown and malformed tokens all return {"active": false}
+//   - roles are the user's current org roles (user metadata "roles"), read at
+//     introspection time so role changes apply without reissuing tokens
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+)
+
+// Introspect handles POST /v1/auth/introspect - report whether an access token is active.
+func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir, err := h.runtime.Provider.NewIntrospectionRequest(ctx, r, &oauth.Session{})
+	if err != nil {
+		if !errors.Is(err, fosite.ErrInactiveToken) {
+			h.logger.Debug("token introspection rejected", zap.Error(err))
+		}
+		h.runtime.Provider.WriteIntrospectionError(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	if !ir.IsActive() || ir.GetTokenUse() != fosite.AccessToken {
+		_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+		return
+	}
+
+	requester := ir.GetAccessRequester()
+	resp := map[string]any{
+		"active":     true,
+		"token_type": fosite.BearerAccessToken,
+		"iss":        h.runtime.Issuer,
+		"client_id":  requester.GetClient().GetID(),
+	}
+	if scopes := requester.GetGrantedScopes(); len(scopes) > 0 {
+		resp["scope"] = strings.Join(scopes, " ")
+	}
+	if audience := requester.GetGrantedAudience(); len(audience) > 0 {
+		resp["aud"] = audience
+	}
+	if !requester.GetRequestedAt().IsZero() {
+		resp["iat"] = requester.GetRequestedAt().Unix()
+	}
+	if session, ok := requester.GetSession().(*oauth.Session); ok {
+		if exp := session.GetExpiresAt(fosite.AccessToken); !exp.IsZero() {
+			resp["exp"] = exp.Unix()
+		}
+		if subject := session.GetSubject(); subject != "" {
+			resp["sub"] = subject
+		}
+		if session.OrgID != "" {
+			resp["org_id"] = session.OrgID
+		}
+		if session.UserID != "" {
+			resp["user_id"] = session.UserID
+		}
+		if roles := h.userRoles(r, session); len(roles) > 0 {
+			resp["roles"] = roles
+		}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// userRoles returns the session user's org roles, or nil when the session has
+// no user or the lookup fails.
+func (h *Handler) userRoles(r *http.Request, session *oauth.Session) []string {
+	orgID, orgErr := uuid.Parse(session.OrgID)
+	userID, userErr := uuid.Parse(session.UserID)
+	if orgErr != nil || userErr != nil {
+		return nil
+	}
+	user, err := h.runtime.Postgres.GetUserByID(r.Context(), orgID, userID)
+	if err != nil {
+		h.logger.Debug("token introspection could not load user roles", zap.Error(err))
+		return nil
+	}
+	return declarative.Roles(user.Metadata)
+}
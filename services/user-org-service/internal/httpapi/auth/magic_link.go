@@ -0,0 +1,533 @@
+// Package auth provides passwordless (magic link) login endpoints.
+//
+// Purpose:
+//
+//	Users of orgs that enable the magicLink setting can sign in without a
+//	password:
+//	- Request a link (a signed, single-use login link is emailed)
+//	- Verify the link (exchanged for the same tokens a password login returns)
+//
+// Key Responsibilities:
+//   - RequestMagicLink: POST /v1/auth/magic-link - Issue a login link (MAGIC_LINK_WEBHOOK_URL sends it)
+//   - VerifyMagicLink: POST /v1/auth/magic-link/verify - Exchange a link for tokens
+//
+// Debugging Notes:
+//   - Tokens are signed like password reset links (with their own key) and
+//     expire after MAGIC_LINK_TTL; a hash of each is kept in the user's
+//     recovery_tokens with purpose "magic_link", and verifying marks it used
+//   - Unknown, inactive and locked-out accounts get the same response as
+//     real ones, and the link is delivered asynchronously
+//   - Requests are limited per email and per client IP, verifications per
+//     client IP (429 with Retry-After). When the limiter's Redis fails they
+//     are refused with 503 unless MAGIC_LINK_RATE_LIMIT_FAIL_OPEN is set
+//   - Lockouts, suspended orgs and MFA apply as they do to password logins:
+//     an MFA-enrolled user must send mfaCode with the link, and a link is not
+//     spent by a verification that fails MFA
+//   - Completion rate: user_org_service_auth_magic_links_total{outcome="completed"}
+//     over {outcome="requested"}
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/orgs"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// magicLinkSentMessage is returned whether or not the account exists.
+const magicLinkSentMessage = "If an account exists with this email, a login link has been sent"
+
+// magicLinkPurpose marks magic link entries in a user's recovery_tokens.
+const magicLinkPurpose = "magic_link"
+
+var (
+	// errMagicLinkInvalid covers unknown, used and tampered links and users
+	// who may not sign in, so a response does not reveal which check failed.
+	errMagicLinkInvalid = errors.New("magic link is invalid, expired or has already been used")
+	// errMagicLinkExpired is a correctly signed link past its expiry.
+	errMagicLinkExpired = fmt.Errorf("%w: expired", errMagicLinkInvalid)
+	// errMagicLinkDisabled means the org has not enabled magic link login.
+	errMagicLinkDisabled = errors.New("magic link login is not enabled for this organization")
+)
+
+// magicLinkStore is the subset of postgres.Store the magic link flow needs.
+type magicLinkStore interface {
+	GetOrg(ctx context.Context, id uuid.UUID) (postgres.Org, error)
+	GetOrgBySlug(ctx context.Context, slug string) (postgres.Org, error)
+	GetUserByEmail(ctx context.Context, orgID uuid.UUID, email string) (postgres.User, error)
+	GetUserByID(ctx context.Context, orgID, userID uuid.UUID) (postgres.User, error)
+	UpdateUserRecoveryTokens(ctx context.Context, orgID, userID uuid.UUID, version int64, recoveryTokens []string) (postgres.User, error)
+}
+
+// magicLink holds the magic link flow's store, signer, limiters and link delivery.
+type magicLink struct {
+	store         magicLinkStore
+	signer        *security.ResetTokenSigner
+	ttl           time.Duration
+	emailLimiter  *security.AttemptLimiter
+	ipLimiter     *security.AttemptLimiter
+	verifyLimiter *security.AttemptLimiter
+	failOpen      bool // Allow requests when a limiter errors
+	notifier      resetNotifier
+}
+
+func newMagicLink(rt *bootstrap.Runtime, logger *zap.Logger) *magicLink {
+	key := []byte(rt.Config.MagicLinkSigningKey)
+	if len(key) == 0 {
+		key = security.DeriveKey(rt.Config.OAuthHMACSecret, "magic-link")
+	}
+	webhook := rt.Config.MagicLinkWebhookURL
+	if webhook == "" {
+		webhook = rt.Config.PasswordResetWebhookURL
+	}
+	return &magicLink{
+		store:         rt.Postgres,
+		signer:        security.NewResetTokenSigner(key),
+		ttl:           rt.Config.MagicLinkTTL,
+		emailLimiter:  security.NewAttemptLimiter(rt.Redis, "magic_link:email", rt.Config.MagicLinkRateLimitPerEmail, time.Hour),
+		ipLimiter:     security.NewAttemptLimiter(rt.Redis, "magic_link:ip", rt.Config.MagicLinkRateLimitPerIP, time.Hour),
+		verifyLimiter: security.NewAttemptLimiter(rt.Redis, "magic_link_verify:ip", rt.Config.MagicLinkRateLimitPerIP, time.Hour),
+		failOpen:      rt.Config.MagicLinkRateLimitFailOpen,
+		notifier:      newResetNotifier(webhook, logger),
+	}
+}
+
+// MagicLinkRequest represents the payload for requesting a login link.
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+	OrgID string `json:"org_id"` // UUID or slug
+}
+
+// MagicLinkResponse represents the response after requesting a login link.
+type MagicLinkResponse struct {
+	Message string `json:"message"`
+	// Token is only returned in development - in production, send via email
+	Token string `json:"token,omitempty"`
+}
+
+// VerifyMagicLinkRequest represents the payload for exchanging a login link for tokens.
+type VerifyMagicLinkRequest struct {
+	Token    string `json:"token"`
+	MFACode  string `json:"mfaCode,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// UseCookies returns the tokens as HTTP-only session cookies instead of in the body.
+	UseCookies bool `json:"useCookies,omitempty"`
+}
+
+// RequestMagicLink handles POST /v1/auth/magic-link.
+// Issues a signed login token, stores its hash in the user's recovery_tokens
+// array and sends the login link to the user's email.
+func (h *Handler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.Email == "" || req.OrgID == "" {
+		http.Error(w, "email and org_id are required", http.StatusBadRequest)
+		return
+	}
+
+	if !h.allowMagicLink(w, r, h.magic.ipLimiter, securityevents.ClientIP(r)) ||
+		!h.allowMagicLink(w, r, h.magic.emailLimiter, strings.ToLower(strings.TrimSpace(req.Email))) {
+		return
+	}
+	metrics.RecordMagicLink("requested")
+
+	org, err := h.magic.lookupOrg(ctx, req.OrgID)
+	if err != nil {
+		http.Error(w, "organization not found", http.StatusNotFound)
+		return
+	}
+
+	token, user, claims, err := h.magic.issue(ctx, org, req.Email, time.Now())
+	switch {
+	case errors.Is(err, errMagicLinkDisabled):
+		metrics.RecordMagicLink("disabled")
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		// Including optimistic lock conflicts: still return success to prevent enumeration
+		h.logger.Warn("failed to issue magic link", zap.Error(err), zap.String("org_id", org.ID.String()))
+		writeMagicLinkSent(w, "")
+		return
+	case token == "":
+		// Unknown, inactive and locked-out accounts get the same response
+		writeMagicLinkSent(w, "")
+		return
+	}
+
+	event := audit.BuildEvent(org.ID, user.ID, audit.ActorTypeSystem, audit.ActionMagicLinkRequest, audit.TargetTypeUser, &user.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"expires_at": claims.ExpiresAt.Format(time.RFC3339),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	h.magic.notifier.SendMagicLink(MagicLinkEvent{
+		OrgID:      org.ID.String(),
+		UserID:     user.ID.String(),
+		Email:      user.Email,
+		LoginURL:   resetLink(h.runtime.Config.MagicLinkURL, token),
+		ExpiresAt:  claims.ExpiresAt,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	// In development, return token in response
+	// In production, the token only travels in the emailed link
+	if h.runtime.Config.Environment == "development" {
+		writeMagicLinkSent(w, token)
+		return
+	}
+	writeMagicLinkSent(w, "")
+}
+
+// VerifyMagicLink handles POST /v1/auth/magic-link/verify.
+// Spends a login link and returns tokens for its user, as Login does.
+func (h *Handler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req VerifyMagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+	if req.UseCookies && !h.cookies().Enabled {
+		http.Error(w, "session cookies are not enabled", http.StatusBadRequest)
+		return
+	}
+	if !h.allowMagicLink(w, r, h.magic.verifyLimiter, securityevents.ClientIP(r)) {
+		return
+	}
+
+	// MFA runs before the link is spent, so a missing code can be supplied on retry
+	checkMFA := func(user postgres.User) (bool, error) {
+		mfaStart := time.Now()
+		mfaVerified, err := h.enforceMFA(ctx, user.ID, user.OrgID, req.MFACode)
+		if err != nil {
+			metrics.RecordMFAFailure(time.Since(mfaStart).Seconds())
+			return false, err
+		}
+		if mfaVerified {
+			metrics.RecordMFASuccess(time.Since(mfaStart).Seconds())
+		}
+		return mfaVerified, nil
+	}
+	user, mfaVerified, err := h.magic.redeem(ctx, req.Token, time.Now(), checkMFA)
+	if err != nil {
+		h.writeMagicLinkError(ctx, w, err)
+		return
+	}
+
+	session := &oauth.Session{
+		DefaultSession: fosite.DefaultSession{Subject: user.ID.String()},
+		OrgID:          user.OrgID.String(),
+		UserID:         user.ID.String(),
+	}
+	if mfaVerified {
+		session.Extra = map[string]interface{}{"mfa_verified_at": time.Now().UTC().Format(time.RFC3339)}
+	}
+	accessRequest, response, err := h.issueUserTokens(ctx, session, req.ClientID, req.Scope)
+	if err != nil {
+		h.logger.Error("failed to issue tokens for magic link", zap.Error(err), zap.String("user_id", user.ID.String()))
+		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+		return
+	}
+
+	if h.runtime.LockoutTracker != nil {
+		_ = h.runtime.LockoutTracker.ClearAttempts(ctx, user.Email, user.ID)
+	}
+	h.runtime.SecurityEvents.ObserveLogin(ctx, user.OrgID, user.ID, securityevents.ClientIP(r))
+
+	event := audit.BuildEvent(user.OrgID, user.ID, audit.ActorTypeUser, audit.ActionMagicLinkLogin, audit.TargetTypeUser, &user.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"mfa_verified": mfaVerified,
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	metrics.RecordMagicLink("completed")
+	metrics.RecordAuthSuccess("magic_link")
+	metrics.RecordSessionCreated()
+
+	if req.UseCookies {
+		h.writeCookieSession(w, response)
+		return
+	}
+	h.runtime.Provider.WriteAccessResponse(ctx, w, accessRequest, response)
+}
+
+// writeMagicLinkError writes a redeem failure as an OAuth error and records it.
+func (h *Handler) writeMagicLinkError(ctx context.Context, w http.ResponseWriter, err error) {
+	var oauthErr *fosite.RFC6749Error
+	switch {
+	case errors.Is(err, errMagicLinkExpired):
+		metrics.RecordMagicLink("expired_token")
+		metrics.RecordAuthFailure("magic_link", "invalid_token")
+		err = fosite.ErrInvalidGrant.WithHint("The login link is invalid, expired or has already been used.")
+	case errors.Is(err, errMagicLinkInvalid):
+		metrics.RecordMagicLink("invalid_token")
+		metrics.RecordAuthFailure("magic_link", "invalid_token")
+		err = fosite.ErrInvalidGrant.WithHint("The login link is invalid, expired or has already been used.")
+	case errors.Is(err, errMagicLinkDisabled):
+		metrics.RecordMagicLink("disabled")
+		metrics.RecordAuthFailure("magic_link", "disabled")
+		err = fosite.ErrAccessDenied.WithHint("Magic link login is not enabled for this organization.")
+	case errors.As(err, &oauthErr):
+		// Suspended org or MFA; MFA failures are recorded by checkMFA
+	default:
+		h.logger.Error("failed to verify magic link", zap.Error(err))
+		err = fosite.ErrServerError.WithWrap(err)
+	}
+	h.runtime.Provider.WriteAccessError(ctx, w, nil, err)
+}
+
+// issue signs a login token for email's user in org and stores its hash.
+// It returns an empty token, and no error, when the user is unknown or may
+// not sign in, and errMagicLinkDisabled when the org has not opted in.
+func (m *magicLink) issue(ctx context.Context, org postgres.Org, email string, now time.Time) (string, postgres.User, security.ResetClaims, error) {
+	if orgs.MagicLinkFromMetadata(org.Metadata) == nil {
+		return "", postgres.User{}, security.ResetClaims{}, errMagicLinkDisabled
+	}
+	user, err := m.store.GetUserByEmail(ctx, org.ID, email)
+	if err != nil || !canSignIn(user, now) {
+		return "", postgres.User{}, security.ResetClaims{}, nil
+	}
+
+	token, claims, err := m.signer.Issue(user.ID, org.ID, m.ttl)
+	if err != nil {
+		return "", postgres.User{}, security.ResetClaims{}, fmt.Errorf("generate login link: %w", err)
+	}
+	tokenHash, err := security.HashPassword(token)
+	if err != nil {
+		return "", postgres.User{}, security.ResetClaims{}, fmt.Errorf("hash login token: %w", err)
+	}
+	entry, _ := json.Marshal(map[string]any{
+		"purpose":    magicLinkPurpose,
+		"hash":       tokenHash,
+		"created_at": claims.IssuedAt.Format(time.RFC3339),
+		"expires_at": claims.ExpiresAt.Format(time.RFC3339),
+		"used":       false,
+	})
+	newTokens := append(pruneRecoveryTokens(user.RecoveryTokens, now), string(entry))
+	if _, err := m.store.UpdateUserRecoveryTokens(ctx, org.ID, user.ID, user.Version, newTokens); err != nil {
+		return "", postgres.User{}, security.ResetClaims{}, fmt.Errorf("store login link: %w", err)
+	}
+	return token, user, claims, nil
+}
+
+// redeem spends token and returns its user. checkMFA runs after every other
+// check and before the link is spent; its error is returned unchanged. The
+// optimistic lock on the user lets exactly one concurrent redeem succeed.
+func (m *magicLink) redeem(ctx context.Context, token string, now time.Time, checkMFA func(postgres.User) (bool, error)) (postgres.User, bool, error) {
+	claims, err := m.signer.Parse(token, now)
+	if errors.Is(err, security.ErrResetTokenExpired) {
+		return postgres.User{}, false, errMagicLinkExpired
+	}
+	if err != nil {
+		return postgres.User{}, false, errMagicLinkInvalid
+	}
+	user, err := m.store.GetUserByID(ctx, claims.OrgID, claims.UserID)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return postgres.User{}, false, errMagicLinkInvalid
+	}
+	if err != nil {
+		return postgres.User{}, false, fmt.Errorf("get user: %w", err)
+	}
+	if !canSignIn(user, now) || !hasMagicLink(user.RecoveryTokens, token, now) {
+		return postgres.User{}, false, errMagicLinkInvalid
+	}
+
+	// The org may have turned magic links off, or been suspended, since the
+	// link was sent
+	org, err := m.store.GetOrg(ctx, claims.OrgID)
+	if err != nil {
+		return postgres.User{}, false, fmt.Errorf("get org: %w", err)
+	}
+	if orgs.MagicLinkFromMetadata(org.Metadata) == nil {
+		return postgres.User{}, false, errMagicLinkDisabled
+	}
+	suspended, err := orgs.OrgSuspended(ctx, m.store, org)
+	if err != nil {
+		return postgres.User{}, false, err
+	}
+	if suspended {
+		return postgres.User{}, false, fosite.ErrAccessDenied.WithHint("The organization is suspended.")
+	}
+
+	mfaVerified, err := checkMFA(user)
+	if err != nil {
+		return postgres.User{}, false, err
+	}
+
+	spent := spendMagicLinks(user.RecoveryTokens, now)
+	if _, err := m.store.UpdateUserRecoveryTokens(ctx, org.ID, user.ID, user.Version, spent); err != nil {
+		if errors.Is(err, postgres.ErrOptimisticLock) {
+			return postgres.User{}, false, errMagicLinkInvalid
+		}
+		return postgres.User{}, false, fmt.Errorf("spend login link: %w", err)
+	}
+	return user, mfaVerified, nil
+}
+
+// issueUserTokens issues tokens for an already authenticated user as a
+// password login would, including a refresh token when the scope and client
+// allow one.
+func (h *Handler) issueUserTokens(ctx context.Context, session *oauth.Session, clientID, scope string) (fosite.AccessRequester, fosite.AccessResponder, error) {
+	if clientID == "" {
+		clientID = h.runtime.Config.OAuthClientID
+	}
+	client, err := h.runtime.OAuthStore.GetClient(ctx, clientID)
+	if err != nil {
+		return nil, nil, fosite.ErrInvalidClient.WithWrap(err)
+	}
+	if !client.GetGrantTypes().Has("password") {
+		return nil, nil, fosite.ErrUnauthorizedClient.WithHint("The client is not allowed to sign users in.")
+	}
+	scopes := fosite.Arguments(strings.Fields(scope))
+	if len(scopes) == 0 {
+		scopes = fosite.Arguments{"openid", "profile", "email"}
+	}
+	for _, s := range scopes {
+		if !h.runtime.OAuthConfig.GetScopeStrategy(ctx)(client.GetScopes(), s) {
+			return nil, nil, fosite.ErrInvalidScope.WithHintf("The client is not allowed to request scope '%s'.", s)
+		}
+	}
+
+	ar := fosite.NewAccessRequest(session)
+	ar.Client = client
+	ar.GrantTypes = fosite.Arguments{"password"}
+	ar.RequestedScope = scopes
+	ar.GrantedScope = scopes
+
+	now := time.Now().UTC()
+	atLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantTypePassword, fosite.AccessToken, h.runtime.OAuthConfig.GetAccessTokenLifespan(ctx))
+	session.SetExpiresAt(fosite.AccessToken, now.Add(atLifespan).Round(time.Second))
+	if rtLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantTypePassword, fosite.RefreshToken, h.runtime.OAuthConfig.GetRefreshTokenLifespan(ctx)); rtLifespan > -1 {
+		session.SetExpiresAt(fosite.RefreshToken, now.Add(rtLifespan).Round(time.Second))
+	}
+
+	response, err := h.runtime.Provider.NewAccessResponse(ctx, ar)
+	if err != nil {
+		return ar, nil, err
+	}
+	return ar, response, nil
+}
+
+// lookupOrg resolves the org_id parameter (UUID or slug).
+func (m *magicLink) lookupOrg(ctx context.Context, orgParam string) (postgres.Org, error) {
+	if orgID, err := uuid.Parse(orgParam); err == nil {
+		return m.store.GetOrg(ctx, orgID)
+	}
+	return m.store.GetOrgBySlug(ctx, orgParam)
+}
+
+// allowMagicLink applies limiter to key, writing 429 when it is exceeded.
+// A limiter error (Redis unreachable) refuses the request with 503 unless
+// MAGIC_LINK_RATE_LIMIT_FAIL_OPEN is set.
+func (h *Handler) allowMagicLink(w http.ResponseWriter, r *http.Request, limiter *security.AttemptLimiter, key string) bool {
+	allowed, err := limiter.Allow(r.Context(), key)
+	if err != nil {
+		if h.magic.failOpen {
+			h.logger.Warn("magic link rate limiter unavailable, allowing request", zap.Error(err))
+			return true
+		}
+		h.logger.Error("magic link rate limiter unavailable, refusing request", zap.Error(err))
+		metrics.RecordMagicLink("limiter_unavailable")
+		http.Error(w, "login links are temporarily unavailable, try again later", http.StatusServiceUnavailable)
+		return false
+	}
+	if !allowed {
+		metrics.RecordMagicLink("rate_limited")
+		w.Header().Set("Retry-After", strconv.Itoa(int(limiter.Window().Seconds())))
+		http.Error(w, "too many login link requests, try again later", http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+func writeMagicLinkSent(w http.ResponseWriter, token string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MagicLinkResponse{
+		Message: magicLinkSentMessage,
+		Token:   token,
+	})
+}
+
+// canSignIn reports whether the user may start a session: active and not
+// locked out after failed attempts.
+func canSignIn(user postgres.User, now time.Time) bool {
+	return user.Status == "active" && (user.LockoutUntil == nil || !user.LockoutUntil.After(now))
+}
+
+// hasMagicLink reports whether token matches an unused, unexpired magic link
+// entry in the user's recovery_tokens.
+func hasMagicLink(tokens []string, token string, now time.Time) bool {
+	for _, tokenStr := range tokens {
+		var tokenData map[string]interface{}
+		if err := json.Unmarshal([]byte(tokenStr), &tokenData); err != nil {
+			continue
+		}
+		if purpose, _ := tokenData["purpose"].(string); purpose != magicLinkPurpose {
+			continue
+		}
+		if used, ok := tokenData["used"].(bool); ok && used {
+			continue
+		}
+		expiresAtStr, _ := tokenData["expires_at"].(string)
+		expiresAt, err := time.Parse(time.RFC3339, expiresAtStr)
+		if err != nil || expiresAt.Before(now) {
+			continue
+		}
+		hash, _ := tokenData["hash"].(string)
+		if valid, err := security.VerifyPassword(token, hash); err == nil && valid {
+			return true
+		}
+	}
+	return false
+}
+
+// spendMagicLinks marks every outstanding magic link used, so signing in
+// with one link invalidates the others; password reset entries are kept.
+func spendMagicLinks(tokens []string, now time.Time) []string {
+	result := make([]string, 0, len(tokens))
+	for _, tokenStr := range pruneRecoveryTokens(tokens, now) {
+		var tokenData map[string]interface{}
+		if err := json.Unmarshal([]byte(tokenStr), &tokenData); err != nil {
+			continue
+		}
+		if purpose, _ := tokenData["purpose"].(string); purpose == magicLinkPurpose {
+			tokenData["used"] = true
+			tokenData["used_at"] = now.UTC().Format(time.RFC3339)
+			updated, _ := json.Marshal(tokenData)
+			tokenStr = string(updated)
+		}
+		result = append(result, tokenStr)
+	}
+	return result
+}
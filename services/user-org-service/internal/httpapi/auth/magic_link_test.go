@@ -0,0 +1,565 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/orgs"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// fakeMagicLinkStore keeps orgs and users in memory and applies the same
+// optimistic lock as postgres.Store to recovery token updates.
+type fakeMagicLinkStore struct {
+	mu     sync.Mutex
+	orgs   map[uuid.UUID]postgres.Org
+	users  map[uuid.UUID]postgres.User
+	writes int
+}
+
+func newFakeMagicLinkStore() *fakeMagicLinkStore {
+	return &fakeMagicLinkStore{orgs: map[uuid.UUID]postgres.Org{}, users: map[uuid.UUID]postgres.User{}}
+}
+
+func (s *fakeMagicLinkStore) addOrg(magicLink bool) postgres.Org {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := postgres.Org{ID: uuid.New(), Slug: "acme-" + uuid.NewString()[:8], Status: "active", Metadata: map[string]any{}}
+	if magicLink {
+		org.Metadata[orgs.MagicLinkMetadataKey] = map[string]any{"enabled": true}
+	}
+	s.orgs[org.ID] = org
+	return org
+}
+
+func (s *fakeMagicLinkStore) addUser(orgID uuid.UUID, email string) postgres.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := postgres.User{ID: uuid.New(), OrgID: orgID, Email: email, Status: "active", Version: 1}
+	s.users[user.ID] = user
+	return user
+}
+
+func (s *fakeMagicLinkStore) updateOrg(org postgres.Org) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org.ID] = org
+}
+
+func (s *fakeMagicLinkStore) updateUser(user postgres.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+}
+
+func (s *fakeMagicLinkStore) user(id uuid.UUID) postgres.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.users[id]
+}
+
+func (s *fakeMagicLinkStore) GetOrg(_ context.Context, id uuid.UUID) (postgres.Org, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org, ok := s.orgs[id]
+	if !ok {
+		return postgres.Org{}, postgres.ErrNotFound
+	}
+	return org, nil
+}
+
+func (s *fakeMagicLinkStore) GetOrgBySlug(_ context.Context, slug string) (postgres.Org, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, org := range s.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return postgres.Org{}, postgres.ErrNotFound
+}
+
+func (s *fakeMagicLinkStore) GetUserByEmail(_ context.Context, orgID uuid.UUID, email string) (postgres.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if user.OrgID == orgID && strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return postgres.User{}, postgres.ErrNotFound
+}
+
+func (s *fakeMagicLinkStore) GetUserByID(_ context.Context, orgID, userID uuid.UUID) (postgres.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[userID]
+	if !ok || user.OrgID != orgID {
+		return postgres.User{}, postgres.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *fakeMagicLinkStore) UpdateUserRecoveryTokens(_ context.Context, orgID, userID uuid.UUID, version int64, recoveryTokens []string) (postgres.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[userID]
+	if !ok || user.OrgID != orgID {
+		return postgres.User{}, postgres.ErrNotFound
+	}
+	if user.Version != version {
+		return postgres.User{}, postgres.ErrOptimisticLock
+	}
+	user.RecoveryTokens = append([]string(nil), recoveryTokens...)
+	user.Version++
+	s.users[userID] = user
+	s.writes++
+	return user, nil
+}
+
+type recordingNotifier struct {
+	mu    sync.Mutex
+	links []MagicLinkEvent
+}
+
+func (n *recordingNotifier) SendResetLink(PasswordResetEvent) {}
+
+func (n *recordingNotifier) SendMagicLink(event MagicLinkEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.links = append(n.links, event)
+}
+
+type nopEmitter struct{}
+
+func (nopEmitter) Emit(context.Context, audit.Event) error { return nil }
+
+func newTestMagicLink(store magicLinkStore, limit int) *magicLink {
+	return &magicLink{
+		store:         store,
+		signer:        security.NewResetTokenSigner(security.DeriveKey("test-secret", "magic-link")),
+		ttl:           15 * time.Minute,
+		emailLimiter:  security.NewAttemptLimiter(nil, "magic_link:email", limit, time.Hour),
+		ipLimiter:     security.NewAttemptLimiter(nil, "magic_link:ip", limit, time.Hour),
+		verifyLimiter: security.NewAttemptLimiter(nil, "magic_link_verify:ip", limit, time.Hour),
+		notifier:      &recordingNotifier{},
+	}
+}
+
+// newTestHandler wires a Handler with the in-memory magic link store and a
+// Fosite provider, enough for the magic link endpoints short of issuing tokens.
+func newTestHandler(t *testing.T, m *magicLink) *Handler {
+	t.Helper()
+	provider, err := oauth.NewProvider(oauth.ProviderDependencies{
+		Storage:    storage.NewMemoryStore(),
+		HMACSecret: []byte("0123456789abcdef0123456789abcdef"),
+	})
+	require.NoError(t, err)
+	return &Handler{
+		runtime: &bootstrap.Runtime{
+			Config:   &config.Config{Environment: "development", MagicLinkURL: "https://console.example.com/magic-link"},
+			Provider: provider,
+			Audit:    nopEmitter{},
+		},
+		logger: zap.NewNop(),
+		magic:  m,
+	}
+}
+
+func noMFA(postgres.User) (bool, error) { return false, nil }
+
+func postJSON(t *testing.T, handler http.HandlerFunc, body any, remoteAddr string) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/magic-link", bytes.NewReader(payload))
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestMagicLinkFromMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		enabled  bool
+	}{
+		{name: "no metadata", metadata: nil},
+		{name: "not configured", metadata: map[string]any{"other": true}},
+		{name: "disabled", metadata: map[string]any{orgs.MagicLinkMetadataKey: map[string]any{"enabled": false}}},
+		{name: "malformed", metadata: map[string]any{orgs.MagicLinkMetadataKey: "yes"}},
+		{name: "enabled", metadata: map[string]any{orgs.MagicLinkMetadataKey: map[string]any{"enabled": true}}, enabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.enabled, orgs.MagicLinkFromMetadata(tt.metadata) != nil)
+		})
+	}
+}
+
+func TestCanSignIn(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	tests := []struct {
+		name string
+		user postgres.User
+		want bool
+	}{
+		{name: "active", user: postgres.User{Status: "active"}, want: true},
+		{name: "lockout expired", user: postgres.User{Status: "active", LockoutUntil: &past}, want: true},
+		{name: "locked out", user: postgres.User{Status: "active", LockoutUntil: &future}},
+		{name: "disabled", user: postgres.User{Status: "disabled"}},
+		{name: "suspended", user: postgres.User{Status: "suspended"}},
+		{name: "invited", user: postgres.User{Status: "invited"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, canSignIn(tt.user, now))
+		})
+	}
+}
+
+func TestMagicLinkIssueRequiresOptIn(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	ctx := context.Background()
+
+	disabled := store.addOrg(false)
+	store.addUser(disabled.ID, "dev@example.com")
+	_, _, _, err := m.issue(ctx, disabled, "dev@example.com", time.Now())
+	require.ErrorIs(t, err, errMagicLinkDisabled)
+
+	enabled := store.addOrg(true)
+	user := store.addUser(enabled.ID, "dev@example.com")
+	token, issuedTo, _, err := m.issue(ctx, enabled, "dev@example.com", time.Now())
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.Equal(t, user.ID, issuedTo.ID)
+	require.True(t, hasMagicLink(store.user(user.ID).RecoveryTokens, token, time.Now()))
+}
+
+func TestMagicLinkIssueSkipsUsersWhoCannotSignIn(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	org := store.addOrg(true)
+	ctx := context.Background()
+
+	disabled := store.addUser(org.ID, "disabled@example.com")
+	disabled.Status = "disabled"
+	store.updateUser(disabled)
+	locked := store.addUser(org.ID, "locked@example.com")
+	until := time.Now().Add(time.Hour)
+	locked.LockoutUntil = &until
+	store.updateUser(locked)
+
+	for _, email := range []string{"unknown@example.com", "disabled@example.com", "locked@example.com"} {
+		token, _, _, err := m.issue(ctx, org, email, time.Now())
+		require.NoError(t, err, email)
+		require.Empty(t, token, email)
+	}
+	require.Zero(t, store.writes, "no link may be stored for users who cannot sign in")
+}
+
+func TestMagicLinkRedeemIsSingleUse(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+	ctx := context.Background()
+
+	first, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+	second, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+
+	redeemed, mfaVerified, err := m.redeem(ctx, first, time.Now(), noMFA)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, redeemed.ID)
+	require.False(t, mfaVerified)
+
+	_, _, err = m.redeem(ctx, first, time.Now(), noMFA)
+	require.ErrorIs(t, err, errMagicLinkInvalid, "a spent link must not sign in again")
+	_, _, err = m.redeem(ctx, second, time.Now(), noMFA)
+	require.ErrorIs(t, err, errMagicLinkInvalid, "signing in spends every outstanding link")
+}
+
+func TestMagicLinkConcurrentRedeemLosesOptimisticLock(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+	ctx := context.Background()
+
+	token, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+
+	// The inner redeem runs after the outer one has read the user and before
+	// it spends the link, as a concurrent request would
+	var innerErr error
+	_, _, outerErr := m.redeem(ctx, token, time.Now(), func(postgres.User) (bool, error) {
+		_, _, innerErr = m.redeem(ctx, token, time.Now(), noMFA)
+		return false, nil
+	})
+	require.NoError(t, innerErr)
+	require.ErrorIs(t, outerErr, errMagicLinkInvalid)
+}
+
+func TestMagicLinkMFABeforeSpend(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+	ctx := context.Background()
+
+	token, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+
+	mfaRequired := &fosite.RFC6749Error{ErrorField: "mfa_required", CodeField: http.StatusBadRequest}
+	_, _, err = m.redeem(ctx, token, time.Now(), func(postgres.User) (bool, error) {
+		return false, mfaRequired
+	})
+	require.ErrorIs(t, err, mfaRequired)
+	require.True(t, hasMagicLink(store.user(user.ID).RecoveryTokens, token, time.Now()), "a failed MFA check must not spend the link")
+
+	_, mfaVerified, err := m.redeem(ctx, token, time.Now(), func(postgres.User) (bool, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, mfaVerified)
+}
+
+func TestMagicLinkRedeemExpiry(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+	ctx := context.Background()
+
+	token, _, claims, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+
+	_, _, err = m.redeem(ctx, token, claims.ExpiresAt.Add(time.Second), noMFA)
+	require.ErrorIs(t, err, errMagicLinkExpired)
+	require.ErrorIs(t, err, errMagicLinkInvalid)
+	require.False(t, hasMagicLink(store.user(user.ID).RecoveryTokens, token, claims.ExpiresAt.Add(time.Second)))
+
+	_, _, err = m.redeem(ctx, token, claims.ExpiresAt.Add(-time.Second), noMFA)
+	require.NoError(t, err)
+}
+
+func TestMagicLinkRedeemRejects(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	ctx := context.Background()
+
+	issue := func(t *testing.T) (postgres.Org, postgres.User, string) {
+		t.Helper()
+		org := store.addOrg(true)
+		user := store.addUser(org.ID, "dev@example.com")
+		token, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+		require.NoError(t, err)
+		return org, user, token
+	}
+
+	t.Run("password reset token", func(t *testing.T) {
+		_, user, _ := issue(t)
+		resetToken, _, err := security.NewResetTokenSigner(security.DeriveKey("test-secret", "password-reset")).Issue(user.ID, user.OrgID, time.Hour)
+		require.NoError(t, err)
+		_, _, err = m.redeem(ctx, resetToken, time.Now(), noMFA)
+		require.ErrorIs(t, err, errMagicLinkInvalid)
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		_, _, token := issue(t)
+		_, _, err := m.redeem(ctx, token+"x", time.Now(), noMFA)
+		require.ErrorIs(t, err, errMagicLinkInvalid)
+	})
+
+	t.Run("user locked out after issue", func(t *testing.T) {
+		_, user, token := issue(t)
+		user = store.user(user.ID)
+		until := time.Now().Add(time.Hour)
+		user.LockoutUntil = &until
+		store.updateUser(user)
+		_, _, err := m.redeem(ctx, token, time.Now(), noMFA)
+		require.ErrorIs(t, err, errMagicLinkInvalid)
+	})
+
+	t.Run("org disabled magic links after issue", func(t *testing.T) {
+		org, user, token := issue(t)
+		org.Metadata = map[string]any{}
+		store.updateOrg(org)
+		_, _, err := m.redeem(ctx, token, time.Now(), noMFA)
+		require.ErrorIs(t, err, errMagicLinkDisabled)
+		require.True(t, hasMagicLink(store.user(user.ID).RecoveryTokens, token, time.Now()))
+	})
+
+	t.Run("org suspended", func(t *testing.T) {
+		org, _, token := issue(t)
+		org.Status = orgs.OrgStatusSuspended
+		store.updateOrg(org)
+		_, _, err := m.redeem(ctx, token, time.Now(), noMFA)
+		require.ErrorIs(t, err, fosite.ErrAccessDenied)
+	})
+}
+
+func TestHasMagicLinkAndSpend(t *testing.T) {
+	now := time.Now()
+	token := "login-token"
+	hash, err := security.HashPassword(token)
+	require.NoError(t, err)
+	entry := func(purpose string, used bool, expiresAt time.Time) string {
+		data, _ := json.Marshal(map[string]any{"purpose": purpose, "hash": hash, "expires_at": expiresAt.Format(time.RFC3339), "used": used})
+		return string(data)
+	}
+
+	tests := []struct {
+		name   string
+		tokens []string
+		token  string
+		want   bool
+	}{
+		{name: "outstanding link", tokens: []string{entry(magicLinkPurpose, false, now.Add(time.Minute))}, token: token, want: true},
+		{name: "wrong token", tokens: []string{entry(magicLinkPurpose, false, now.Add(time.Minute))}, token: "other"},
+		{name: "used", tokens: []string{entry(magicLinkPurpose, true, now.Add(time.Minute))}, token: token},
+		{name: "expired", tokens: []string{entry(magicLinkPurpose, false, now.Add(-time.Minute))}, token: token},
+		{name: "password reset entry", tokens: []string{entry("", false, now.Add(time.Minute))}, token: token},
+		{name: "malformed entry", tokens: []string{"not json"}, token: token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, hasMagicLink(tt.tokens, tt.token, now))
+		})
+	}
+
+	reset := entry("", false, now.Add(time.Minute))
+	spent := spendMagicLinks([]string{entry(magicLinkPurpose, false, now.Add(time.Minute)), reset}, now)
+	require.Len(t, spent, 2)
+	require.False(t, hasMagicLink(spent, token, now))
+	require.Equal(t, reset, spent[1], "password reset entries are kept as they were")
+}
+
+func TestRequestMagicLinkHandler(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	h := newTestHandler(t, m)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+
+	rec := postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: user.Email, OrgID: org.Slug}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp MagicLinkResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, magicLinkSentMessage, resp.Message)
+	require.NotEmpty(t, resp.Token, "development returns the token")
+
+	notifier := m.notifier.(*recordingNotifier)
+	require.Len(t, notifier.links, 1)
+	require.Equal(t, "https://console.example.com/magic-link?token="+resp.Token, notifier.links[0].LoginURL)
+
+	// Unknown users get the same response and no link
+	rec = postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "nobody@example.com", OrgID: org.ID.String()}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, notifier.links, 1)
+
+	disabled := store.addOrg(false)
+	rec = postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: user.Email, OrgID: disabled.ID.String()}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestVerifyMagicLinkHandlerRejectsSpentLink(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	m := newTestMagicLink(store, 0)
+	h := newTestHandler(t, m)
+	org := store.addOrg(true)
+	user := store.addUser(org.ID, "dev@example.com")
+	ctx := context.Background()
+
+	token, _, _, err := m.issue(ctx, org, user.Email, time.Now())
+	require.NoError(t, err)
+	_, _, err = m.redeem(ctx, token, time.Now(), noMFA)
+	require.NoError(t, err)
+
+	rec := postJSON(t, h.VerifyMagicLink, VerifyMagicLinkRequest{Token: token}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid_grant")
+}
+
+func TestMagicLinkRateLimits(t *testing.T) {
+	store := newFakeMagicLinkStore()
+	h := newTestHandler(t, newTestMagicLink(store, 2))
+	org := store.addOrg(false)
+
+	// Per client IP
+	for i := 0; i < 2; i++ {
+		rec := postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "user" + string(rune('a'+i)) + "@example.com", OrgID: org.Slug}, "192.0.2.1:1234")
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	}
+	rec := postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "userc@example.com", OrgID: org.Slug}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "3600", rec.Header().Get("Retry-After"))
+
+	// Per email, across IPs and regardless of case
+	for i, email := range []string{"target@example.com", "Target@Example.com"} {
+		rec := postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: email, OrgID: org.Slug}, "198.51.100."+string(rune('1'+i))+":1234")
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	}
+	rec = postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "target@example.com", OrgID: org.Slug}, "198.51.100.9:1234")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Verifications per client IP
+	for i := 0; i < 2; i++ {
+		rec := postJSON(t, h.VerifyMagicLink, VerifyMagicLinkRequest{Token: "guess"}, "203.0.113.1:1234")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	rec = postJSON(t, h.VerifyMagicLink, VerifyMagicLinkRequest{Token: "guess"}, "203.0.113.1:1234")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestMagicLinkRateLimiterUnavailable(t *testing.T) {
+	// Nothing listens on the discard port, so every limiter call fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:9", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer client.Close()
+
+	store := newFakeMagicLinkStore()
+	org := store.addOrg(false)
+	m := newTestMagicLink(store, 5)
+	m.ipLimiter = security.NewAttemptLimiter(client, "magic_link:ip", 5, time.Hour)
+	m.verifyLimiter = security.NewAttemptLimiter(client, "magic_link_verify:ip", 5, time.Hour)
+	h := newTestHandler(t, m)
+
+	rec := postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "dev@example.com", OrgID: org.Slug}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, "requests fail closed by default")
+	rec = postJSON(t, h.VerifyMagicLink, VerifyMagicLinkRequest{Token: "guess"}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, "verifications fail closed by default")
+
+	m.failOpen = true
+	rec = postJSON(t, h.RequestMagicLink, MagicLinkRequest{Email: "dev@example.com", OrgID: org.Slug}, "192.0.2.1:1234")
+	require.Equal(t, http.StatusForbidden, rec.Code, "MAGIC_LINK_RATE_LIMIT_FAIL_OPEN lets the request through")
+}
+
+func TestMagicLinkErrorsAreDistinct(t *testing.T) {
+	require.True(t, errors.Is(errMagicLinkExpired, errMagicLinkInvalid))
+	require.False(t, errors.Is(errMagicLinkDisabled, errMagicLinkInvalid))
+}
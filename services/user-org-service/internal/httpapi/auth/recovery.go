@@ -2,41 +2,82 @@
 //
 // Purpose:
 //
-//	This package implements password recovery flows:
-//	- Initiate recovery (generate recovery token)
-//	- Verify recovery token
-//	- Reset password with recovery token
+//	This package implements the self-service password reset flow:
+//	- Initiate recovery (email a signed, time-limited reset link)
+//	- Verify recovery token (the console checks the link before showing the form)
+//	- Reset password with recovery token (single use; revokes existing sessions)
 //
 // Dependencies:
 //   - github.com/go-chi/chi/v5: HTTP router
 //   - internal/bootstrap: Runtime dependencies
 //   - internal/storage/postgres: User data access
-//   - internal/security: Password hashing
+//   - internal/security: Password hashing, reset token signing, attempt limiting
 //
 // Key Responsibilities:
-//   - InitiateRecovery: POST /v1/auth/recover - Generate recovery token
+//   - InitiateRecovery: POST /v1/auth/recover - Issue a reset link (PASSWORD_RESET_WEBHOOK_URL sends it)
 //   - VerifyRecoveryToken: POST /v1/auth/recover/verify - Verify token validity
 //   - ResetPassword: POST /v1/auth/recover/reset - Reset password with token
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#FR-007 (Credential Recovery)
+//
+// Debugging Notes:
+//   - Tokens are signed (security.ResetTokenSigner) and carry the user and org,
+//     so verify/reset need only the token; the link expires after PASSWORD_RESET_TTL
+//   - A hash of each issued token is kept in the user's recovery_tokens; a
+//     token is single-use because a reset marks every outstanding token used
+//   - Tokens issued before signed links still verify with email and org_id
+//   - Requests are limited per email and per client IP (429 with Retry-After);
+//     the limiter fails open if Redis is unavailable
+//   - Completion rate: user_org_service_auth_password_resets_total{outcome="completed"}
+//     over {outcome="requested"}
 package auth
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/securityevents"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
+// recoveryInitiatedMessage is returned whether or not the account exists.
+const recoveryInitiatedMessage = "If an account exists with this email, a password reset link has been sent"
+
+// passwordReset holds the reset flow's signer, limiters and link delivery.
+type passwordReset struct {
+	signer       *security.ResetTokenSigner
+	emailLimiter *security.AttemptLimiter
+	ipLimiter    *security.AttemptLimiter
+	notifier     resetNotifier
+}
+
+func newPasswordReset(rt *bootstrap.Runtime, logger *zap.Logger) *passwordReset {
+	key := []byte(rt.Config.PasswordResetSigningKey)
+	if len(key) == 0 {
+		key = security.DeriveKey(rt.Config.OAuthHMACSecret, "password-reset")
+	}
+	return &passwordReset{
+		signer:       security.NewResetTokenSigner(key),
+		emailLimiter: security.NewAttemptLimiter(rt.Redis, "password_reset:email", rt.Config.PasswordResetRateLimitPerEmail, time.Hour),
+		ipLimiter:    security.NewAttemptLimiter(rt.Redis, "password_reset:ip", rt.Config.PasswordResetRateLimitPerIP, time.Hour),
+		notifier:     newResetNotifier(rt.Config.PasswordResetWebhookURL, logger),
+	}
+}
+
 // InitiateRecoveryRequest represents the payload for initiating recovery.
 type InitiateRecoveryRequest struct {
 	Email string `json:"email"`
@@ -53,7 +94,7 @@ type InitiateRecoveryResponse struct {
 // VerifyRecoveryTokenRequest represents the payload for verifying a recovery token.
 type VerifyRecoveryTokenRequest struct {
 	Token string `json:"token"`
-	Email string `json:"email"` // Email is required to find the user
+	Email string `json:"email,omitempty"` // Only needed for tokens issued before signed links
 	OrgID string `json:"org_id,omitempty"`
 }
 
@@ -67,7 +108,7 @@ type VerifyRecoveryTokenResponse struct {
 // ResetPasswordRequest represents the payload for resetting password.
 type ResetPasswordRequest struct {
 	Token       string `json:"token"`
-	Email       string `json:"email"` // Email is required to find the user
+	Email       string `json:"email,omitempty"` // Only needed for tokens issued before signed links
 	NewPassword string `json:"newPassword"`
 	OrgID       string `json:"org_id,omitempty"`
 }
@@ -78,7 +119,8 @@ type ResetPasswordResponse struct {
 }
 
 // InitiateRecovery handles POST /v1/auth/recover.
-// Generates a recovery token and stores it in the user's recovery_tokens array.
+// Issues a signed reset token, stores its hash in the user's recovery_tokens
+// array and sends the reset link to the user's email.
 func (h *Handler) InitiateRecovery(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -93,6 +135,11 @@ func (h *Handler) InitiateRecovery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.allowResetRequest(w, r, req.Email) {
+		return
+	}
+	metrics.RecordPasswordReset("requested")
+
 	// Resolve org ID
 	var orgID uuid.UUID
 	var err error
@@ -113,37 +160,19 @@ func (h *Handler) InitiateRecovery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find user by email
+	// Find user by email; inactive and unknown accounts get the same response
+	// (prevent user enumeration)
 	user, err := h.runtime.Postgres.GetUserByEmail(ctx, orgID, req.Email)
-	if err != nil {
-		// Don't reveal if user exists (prevent user enumeration)
-		// Return success message anyway
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(InitiateRecoveryResponse{
-			Message: "If an account exists with this email, a recovery token has been generated",
-		})
-		return
-	}
-
-	// Check if user is active
-	if user.Status != "active" {
-		// Don't reveal account status
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(InitiateRecoveryResponse{
-			Message: "If an account exists with this email, a recovery token has been generated",
-		})
+	if err != nil || user.Status != "active" {
+		writeRecoveryInitiated(w, "")
 		return
 	}
 
-	// Generate recovery token (32 bytes, base64url encoded)
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
+	token, claims, err := h.reset.signer.Issue(user.ID, orgID, h.runtime.Config.PasswordResetTTL)
+	if err != nil {
 		http.Error(w, "failed to generate recovery token", http.StatusInternalServerError)
 		return
 	}
-	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
 
 	// Hash token for storage (similar to password hashing)
 	tokenHash, err := security.HashPassword(token)
@@ -152,12 +181,10 @@ func (h *Handler) InitiateRecovery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Add token to user's recovery_tokens array
-	// Tokens expire after 24 hours
 	recoveryToken := map[string]interface{}{
 		"hash":       tokenHash,
-		"created_at": time.Now().UTC().Format(time.RFC3339),
-		"expires_at": time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339),
+		"created_at": claims.IssuedAt.Format(time.RFC3339),
+		"expires_at": claims.ExpiresAt.Format(time.RFC3339),
 		"used":       false,
 	}
 
@@ -170,72 +197,140 @@ func (h *Handler) InitiateRecovery(w http.ResponseWriter, r *http.Request) {
 		recoveryToken["status"] = "approved" // Auto-approved if admin approval not required
 	}
 
-	// Get current recovery tokens
-	currentTokens := user.RecoveryTokens
-	if currentTokens == nil {
-		currentTokens = []string{}
-	}
-
-	// Add new token (store as JSON string in array)
+	// Add new token (store as JSON string in array), dropping spent ones so the array stays small
 	tokenJSON, _ := json.Marshal(recoveryToken)
-	newTokens := append(currentTokens, string(tokenJSON))
+	newTokens := append(pruneRecoveryTokens(user.RecoveryTokens, time.Now()), string(tokenJSON))
 
 	// Update user with new recovery token
 	_, err = h.runtime.Postgres.UpdateUserRecoveryTokens(ctx, orgID, user.ID, user.Version, newTokens)
 	if err != nil {
-		if err == postgres.ErrOptimisticLock {
-			// User was modified concurrently - still return success to prevent enumeration
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(InitiateRecoveryResponse{
-				Message: "If an account exists with this email, a recovery token has been generated",
-			})
-			return
-		}
-		// Log error (runtime logger available via bootstrap)
-		// Note: In production, use structured logging from runtime
-		// Still return success to prevent user enumeration
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(InitiateRecoveryResponse{
-			Message: "If an account exists with this email, a recovery token has been generated",
-		})
+		// Including optimistic lock conflicts: still return success to prevent enumeration
+		h.logger.Warn("failed to store recovery token", zap.Error(err), zap.String("user_id", user.ID.String()))
+		writeRecoveryInitiated(w, "")
 		return
 	}
 
-	// Emit audit event
-	action := audit.ActionRecoveryInitiate
-	if h.runtime.Config.RecoveryRequiresAdminApproval {
-		action = audit.ActionRecoveryInitiate // Status will be "pending" in metadata
-	}
-	event := audit.BuildEvent(orgID, user.ID, audit.ActorTypeSystem, action, audit.TargetTypeUser, &user.ID)
+	// Emit audit event (status is "pending" in metadata when admin approval is required)
+	event := audit.BuildEvent(orgID, user.ID, audit.ActorTypeSystem, audit.ActionRecoveryInitiate, audit.TargetTypeUser, &user.ID)
 	event = audit.BuildEventFromRequest(event, r)
 	event.Metadata = map[string]any{
-		"status": recoveryToken["status"],
-		"email":  req.Email,
+		"status":     recoveryToken["status"],
+		"email":      req.Email,
+		"expires_at": claims.ExpiresAt.Format(time.RFC3339),
 	}
 	_ = h.runtime.Audit.Emit(ctx, event)
 
 	// Record recovery attempt
 	metrics.RecordRecoveryAttempt("initiate")
 
+	h.reset.notifier.SendResetLink(PasswordResetEvent{
+		OrgID:            orgID.String(),
+		UserID:           user.ID.String(),
+		Email:            user.Email,
+		ResetURL:         resetLink(h.runtime.Config.PasswordResetURL, token),
+		RequiresApproval: h.runtime.Config.RecoveryRequiresAdminApproval,
+		ExpiresAt:        claims.ExpiresAt,
+		OccurredAt:       time.Now().UTC(),
+	})
+
 	// In development/testing, return token in response
-	// In production, send token via email
-	devMode := h.runtime.Config.Environment == "development"
-	response := InitiateRecoveryResponse{
-		Message: "If an account exists with this email, a recovery token has been generated",
+	// In production, the token only travels in the emailed link
+	if h.runtime.Config.Environment == "development" {
+		writeRecoveryInitiated(w, token)
+		return
 	}
-	if devMode {
-		response.Token = token
+	writeRecoveryInitiated(w, "")
+}
+
+// allowResetRequest applies the per-IP and per-email limits, writing 429 when
+// either is exceeded. Limiter errors fail open so a Redis outage does not
+// lock users out of recovery.
+func (h *Handler) allowResetRequest(w http.ResponseWriter, r *http.Request, email string) bool {
+	checks := []struct {
+		limiter *security.AttemptLimiter
+		key     string
+	}{
+		{h.reset.ipLimiter, securityevents.ClientIP(r)},
+		{h.reset.emailLimiter, strings.ToLower(strings.TrimSpace(email))},
 	}
+	for _, check := range checks {
+		allowed, err := check.limiter.Allow(r.Context(), check.key)
+		if err != nil {
+			h.logger.Warn("password reset rate limiter unavailable", zap.Error(err))
+			continue
+		}
+		if !allowed {
+			metrics.RecordPasswordReset("rate_limited")
+			w.Header().Set("Retry-After", strconv.Itoa(int(check.limiter.Window().Seconds())))
+			http.Error(w, "too many password reset requests, try again later", http.StatusTooManyRequests)
+			return false
+		}
+	}
+	return true
+}
 
+func writeRecoveryInitiated(w http.ResponseWriter, token string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(InitiateRecoveryResponse{
+		Message: recoveryInitiatedMessage,
+		Token:   token,
+	})
+}
+
+// resetLink appends the token to the console's reset page URL.
+func resetLink(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// recoveryUser finds the user a recovery token was issued for. Signed tokens
+// name the user and org themselves; older tokens need email and org_id.
+// issuedAt is zero for older tokens.
+func (h *Handler) recoveryUser(ctx context.Context, token, email, orgParam string) (user postgres.User, orgID uuid.UUID, issuedAt time.Time, err error) {
+	claims, err := h.reset.signer.Parse(token, time.Now())
+	switch {
+	case err == nil:
+		user, err = h.runtime.Postgres.GetUserByID(ctx, claims.OrgID, claims.UserID)
+		return user, claims.OrgID, claims.IssuedAt, err
+	case errors.Is(err, security.ErrResetTokenExpired):
+		return postgres.User{}, uuid.Nil, time.Time{}, err
+	case strings.Contains(token, "."):
+		// Looks signed but is not ours
+		return postgres.User{}, uuid.Nil, time.Time{}, err
+	}
+
+	if email == "" || orgParam == "" {
+		return postgres.User{}, uuid.Nil, time.Time{}, security.ErrResetTokenInvalid
+	}
+	if orgID, err = uuid.Parse(orgParam); err != nil {
+		org, err := h.runtime.Postgres.GetOrgBySlug(ctx, orgParam)
+		if err != nil {
+			return postgres.User{}, uuid.Nil, time.Time{}, err
+		}
+		orgID = org.ID
+	}
+	user, err = h.runtime.Postgres.GetUserByEmail(ctx, orgID, email)
+	return user, orgID, time.Time{}, err
+}
+
+// recordResetFailure counts an invalid or expired token.
+func recordResetFailure(err error) {
+	if errors.Is(err, security.ErrResetTokenExpired) {
+		metrics.RecordPasswordReset("expired_token")
+		return
+	}
+	metrics.RecordPasswordReset("invalid_token")
 }
 
 // VerifyRecoveryToken handles POST /v1/auth/recover/verify.
-// Verifies that a recovery token is valid and not expired.
+// Verifies that a recovery token is valid, unused and not expired.
 func (h *Handler) VerifyRecoveryToken(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -245,55 +340,32 @@ func (h *Handler) VerifyRecoveryToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Token == "" || req.Email == "" {
-		http.Error(w, "token and email are required", http.StatusBadRequest)
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
 		return
 	}
 
-	// Resolve org ID
-	var orgID uuid.UUID
-	var err error
-	if req.OrgID != "" {
-		if orgID, err = uuid.Parse(req.OrgID); err != nil {
-			org, err := h.runtime.Postgres.GetOrgBySlug(ctx, req.OrgID)
-			if err != nil {
-				http.Error(w, "organization not found", http.StatusNotFound)
-				return
-			}
-			orgID = org.ID
-		}
-	} else {
-		http.Error(w, "org_id is required", http.StatusBadRequest)
-		return
+	invalid := VerifyRecoveryTokenResponse{
+		Valid:   false,
+		Message: "Invalid or expired recovery token",
 	}
 
-	// Find user by email
-	user, err := h.runtime.Postgres.GetUserByEmail(ctx, orgID, req.Email)
-	if err != nil {
-		// Don't reveal if user exists
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(VerifyRecoveryTokenResponse{
-			Valid:   false,
-			Message: "Invalid or expired recovery token",
-		})
-		return
+	// Don't reveal whether the user exists
+	user, _, _, err := h.recoveryUser(ctx, req.Token, req.Email, req.OrgID)
+	if err == nil && !h.verifyRecoveryTokenInUser(user, req.Token) {
+		err = security.ErrResetTokenInvalid
 	}
-
-	// Verify token in user's recovery_tokens array
-	valid := h.verifyRecoveryTokenInUser(user, req.Token)
-	if !valid {
+	if err != nil {
+		recordResetFailure(err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(VerifyRecoveryTokenResponse{
-			Valid:   false,
-			Message: "Invalid or expired recovery token",
-		})
+		json.NewEncoder(w).Encode(invalid)
 		return
 	}
 
 	// Record recovery verification attempt
 	metrics.RecordRecoveryAttempt("verify")
+	metrics.RecordPasswordReset("verified")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -304,7 +376,8 @@ func (h *Handler) VerifyRecoveryToken(w http.ResponseWriter, r *http.Request) {
 }
 
 // ResetPassword handles POST /v1/auth/recover/reset.
-// Resets the user's password using a valid recovery token.
+// Resets the user's password using a valid recovery token, invalidates every
+// outstanding token and revokes the user's sessions.
 func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -325,33 +398,13 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Resolve org ID
-	var orgID uuid.UUID
-	var err error
-	if req.OrgID != "" {
-		if orgID, err = uuid.Parse(req.OrgID); err != nil {
-			org, err := h.runtime.Postgres.GetOrgBySlug(ctx, req.OrgID)
-			if err != nil {
-				http.Error(w, "organization not found", http.StatusNotFound)
-				return
-			}
-			orgID = org.ID
-		}
-	} else {
-		http.Error(w, "org_id is required", http.StatusBadRequest)
-		return
+	// Don't reveal whether the user exists
+	user, orgID, issuedAt, err := h.recoveryUser(ctx, req.Token, req.Email, req.OrgID)
+	if err == nil && !h.verifyRecoveryTokenInUser(user, req.Token) {
+		err = security.ErrResetTokenInvalid
 	}
-
-	// Find user by email
-	user, err := h.runtime.Postgres.GetUserByEmail(ctx, orgID, req.Email)
 	if err != nil {
-		// Don't reveal if user exists
-		http.Error(w, "invalid or expired recovery token", http.StatusBadRequest)
-		return
-	}
-
-	// Verify token in user's recovery_tokens array
-	if !h.verifyRecoveryTokenInUser(user, req.Token) {
+		recordResetFailure(err)
 		http.Error(w, "invalid or expired recovery token", http.StatusBadRequest)
 		return
 	}
@@ -363,11 +416,23 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Spend the tokens first: the optimistic lock lets exactly one concurrent
+	// request with the same token through
+	consumed, err := h.runtime.Postgres.UpdateUserRecoveryTokens(ctx, orgID, user.ID, user.Version, consumeRecoveryTokens(user.RecoveryTokens, time.Now()))
+	if err != nil {
+		if err == postgres.ErrOptimisticLock {
+			http.Error(w, "user was modified concurrently", http.StatusConflict)
+			return
+		}
+		http.Error(w, "failed to update password", http.StatusInternalServerError)
+		return
+	}
+
 	// Update password
 	updatedUser, err := h.runtime.Postgres.UpdateUserPasswordHash(ctx, postgres.UpdateUserPasswordHashParams{
 		OrgID:        orgID,
 		ID:           user.ID,
-		Version:      user.Version,
+		Version:      consumed.Version,
 		PasswordHash: passwordHash,
 	})
 	if err != nil {
@@ -379,24 +444,30 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mark recovery token as used
-	updatedTokens := h.markRecoveryTokenAsUsed(user.RecoveryTokens, req.Token)
-	if len(updatedTokens) != len(user.RecoveryTokens) {
-		// Token was found and marked as used - update user
-		_, err = h.runtime.Postgres.UpdateUserRecoveryTokens(ctx, orgID, updatedUser.ID, updatedUser.Version, updatedTokens)
-		if err != nil {
-			// Non-fatal: log but don't fail the password reset
-			// Token is already used functionally (password changed)
-		}
+	// Sign out everywhere: whoever knew the old password may hold a session
+	revoked, err := h.runtime.OAuthStore.RevokeUserSessions(ctx, updatedUser.ID)
+	if err != nil {
+		h.logger.Error("failed to revoke sessions after password reset", zap.Error(err), zap.String("user_id", updatedUser.ID.String()))
+	}
+	if h.runtime.LockoutTracker != nil {
+		_ = h.runtime.LockoutTracker.ClearAttempts(ctx, updatedUser.Email, updatedUser.ID)
 	}
 
 	// Emit audit event
 	event := audit.BuildEvent(orgID, updatedUser.ID, audit.ActorTypeSystem, audit.ActionRecoveryComplete, audit.TargetTypeUser, &updatedUser.ID)
 	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"sessions_revoked": revoked,
+	}
 	_ = h.runtime.Audit.Emit(ctx, event)
 
 	// Record recovery reset attempt
 	metrics.RecordRecoveryAttempt("reset")
+	if issuedAt.IsZero() {
+		metrics.RecordPasswordReset("completed")
+	} else {
+		metrics.RecordPasswordResetCompleted(time.Since(issuedAt).Seconds())
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -453,32 +524,40 @@ func (h *Handler) verifyRecoveryTokenInUser(user postgres.User, token string) bo
 	return false
 }
 
-// markRecoveryTokenAsUsed marks a recovery token as used in the tokens array.
-func (h *Handler) markRecoveryTokenAsUsed(tokens []string, token string) []string {
-	result := make([]string, 0, len(tokens))
+// pruneRecoveryTokens drops used and expired entries from the tokens array.
+func pruneRecoveryTokens(tokens []string, now time.Time) []string {
+	result := make([]string, 0, len(tokens)+1)
 	for _, tokenStr := range tokens {
 		var tokenData map[string]interface{}
 		if err := json.Unmarshal([]byte(tokenStr), &tokenData); err != nil {
-			result = append(result, tokenStr) // Keep invalid tokens as-is
 			continue
 		}
-
-		// Check if this is the token we're looking for
-		hash, ok := tokenData["hash"].(string)
-		if ok {
-			valid, err := security.VerifyPassword(token, hash)
-			if err == nil && valid {
-				// Mark as used
-				tokenData["used"] = true
-				tokenData["used_at"] = time.Now().UTC().Format(time.RFC3339)
-				tokenJSON, _ := json.Marshal(tokenData)
-				result = append(result, string(tokenJSON))
+		if used, ok := tokenData["used"].(bool); ok && used {
+			continue
+		}
+		if expiresAtStr, ok := tokenData["expires_at"].(string); ok {
+			if expiresAt, err := time.Parse(time.RFC3339, expiresAtStr); err == nil && expiresAt.Before(now) {
 				continue
 			}
 		}
-
-		// Not the token we're looking for - keep as-is
 		result = append(result, tokenStr)
 	}
 	return result
 }
+
+// consumeRecoveryTokens marks every outstanding token used, so a password
+// change invalidates all reset links issued before it.
+func consumeRecoveryTokens(tokens []string, now time.Time) []string {
+	result := make([]string, 0, len(tokens))
+	for _, tokenStr := range pruneRecoveryTokens(tokens, now) {
+		var tokenData map[string]interface{}
+		if err := json.Unmarshal([]byte(tokenStr), &tokenData); err != nil {
+			continue
+		}
+		tokenData["used"] = true
+		tokenData["used_at"] = now.UTC().Format(time.RFC3339)
+		tokenJSON, _ := json.Marshal(tokenData)
+		result = append(result, string(tokenJSON))
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// PasswordResetEvent is posted to the password reset webhook; the receiver
+// emails ResetURL to Email.
+type PasswordResetEvent struct {
+	Type     string `json:"type"`
+	OrgID    string `json:"orgId"`
+	UserID   string `json:"userId"`
+	Email    string `json:"email"`
+	ResetURL string `json:"resetUrl"`
+	// RequiresApproval is set when the link only works after an admin approves it.
+	RequiresApproval bool      `json:"requiresApproval,omitempty"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	OccurredAt       time.Time `json:"occurredAt"`
+}
+
+// MagicLinkEvent is posted to the magic link webhook; the receiver emails
+// LoginURL to Email.
+type MagicLinkEvent struct {
+	Type       string    `json:"type"`
+	OrgID      string    `json:"orgId"`
+	UserID     string    `json:"userId"`
+	Email      string    `json:"email"`
+	LoginURL   string    `json:"loginUrl"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// resetNotifier delivers reset and login links. Delivery is asynchronous so
+// the response time does not reveal whether the account exists.
+type resetNotifier interface {
+	SendResetLink(event PasswordResetEvent)
+	SendMagicLink(event MagicLinkEvent)
+}
+
+func newResetNotifier(url string, logger *zap.Logger) resetNotifier {
+	return resetWebhookNotifier{notifier: notify.New(url, logger), logger: logger}
+}
+
+// resetWebhookNotifier posts PasswordResetEvent and MagicLinkEvent as JSON.
+// The link itself is never logged.
+type resetWebhookNotifier struct {
+	notifier *notify.Notifier
+	logger   *zap.Logger
+}
+
+func (n resetWebhookNotifier) SendResetLink(event PasswordResetEvent) {
+	event.Type = "password_reset.requested"
+	n.notifier.SendAsync(event, func(err error) {
+		if err != nil {
+			metrics.RecordPasswordReset("link_failed")
+			n.logger.Warn("password reset webhook failed", zap.Error(err), zap.String("user_id", event.UserID))
+			return
+		}
+		metrics.RecordPasswordReset("link_sent")
+	}, "password reset link issued",
+		zap.String("org_id", event.OrgID),
+		zap.String("user_id", event.UserID),
+		zap.Time("expires_at", event.ExpiresAt),
+	)
+}
+
+func (n resetWebhookNotifier) SendMagicLink(event MagicLinkEvent) {
+	event.Type = "magic_link.requested"
+	n.notifier.SendAsync(event, func(err error) {
+		if err != nil {
+			metrics.RecordMagicLink("link_failed")
+			n.logger.Warn("magic link webhook failed", zap.Error(err), zap.String("user_id", event.UserID))
+			return
+		}
+		metrics.RecordMagicLink("link_sent")
+	}, "magic link issued",
+		zap.String("org_id", event.OrgID),
+		zap.String("user_id", event.UserID),
+		zap.Time("expires_at", event.ExpiresAt),
+	)
+}
@@ -0,0 +1,73 @@
+// Package auth provides the service token endpoint for workload identities.
+//
+// Purpose:
+//
+//	Platform services (the router, analytics) authenticate to each other with
+//	short-lived service tokens instead of relying on cluster network trust.
+//	Each workload is a confidential OAuth client registered with the
+//	client_credentials grant and the "service" scope (POST
+//	/v1/admin/oauth-clients); it exchanges its credentials here for an access
+//	token whose identity is its client ID.
+//
+// Debugging Notes:
+//   - Credentials go in HTTP Basic auth or client_id/client_secret form fields
+//   - The token always carries exactly the "service" scope and no user or org
+//   - Token lifetime is the access token lifespan (1h); callers refresh early
+//     (shared-go/auth/tokens.ServiceTokenSource)
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+)
+
+// ServiceToken handles POST /v1/auth/service-token - mint a service token
+// for a workload with the client credentials grant.
+func (h *Handler) ServiceToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {tokens.ServiceScope},
+	}
+	for _, key := range []string{"client_id", "client_secret"} {
+		if value := r.PostForm.Get(key); value != "" {
+			form.Set(key, value)
+		}
+	}
+
+	req := cloneRequestWithForm(r, form)
+	session := &oauth.Session{}
+	accessRequest, err := h.runtime.Provider.NewAccessRequest(ctx, req, session)
+	if err != nil {
+		metrics.RecordAuthFailure("service_token", extractErrorReason(err))
+		h.logger.Warn("service token request rejected", zap.Error(err))
+		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+		return
+	}
+
+	clientID := accessRequest.GetClient().GetID()
+	accessRequest.GrantScope(tokens.ServiceScope)
+	session.Subject = clientID
+	session.GrantedScopes = []string{tokens.ServiceScope}
+
+	response, err := h.runtime.Provider.NewAccessResponse(ctx, accessRequest)
+	if err != nil {
+		metrics.RecordAuthFailure("service_token", extractErrorReason(err))
+		h.runtime.Provider.WriteAccessError(ctx, w, accessRequest, err)
+		return
+	}
+	metrics.RecordAuthSuccess("service_token")
+	h.logger.Debug("service token issued", zap.String("client_id", clientID))
+	h.runtime.Provider.WriteAccessResponse(ctx, w, accessRequest, response)
+}
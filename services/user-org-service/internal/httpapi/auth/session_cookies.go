@@ -0,0 +1,98 @@
+// Package auth provides session cookie mode for the web console.
+//
+// Purpose:
+//
+//	When SESSION_COOKIES_ENABLED=true, a login with "useCookies": true sets
+//	the access and refresh tokens as HTTP-only cookies and returns only a
+//	CSRF token. Refresh and logout then work from the cookies, and
+//	GET /v1/auth/csrf issues a new CSRF token, e.g. after a page reload.
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ory/fosite"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+)
+
+// cookieSessionResponse replaces the token response in session cookie mode.
+type cookieSessionResponse struct {
+	TokenType string `json:"token_type"` // Always "cookie"
+	ExpiresIn int64  `json:"expires_in,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	CSRFToken string `json:"csrf_token"`
+}
+
+// csrfTokenResponse is the body of GET /v1/auth/csrf, using the same key as
+// cookieSessionResponse so clients read the token one way.
+type csrfTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// cookies returns the session cookie settings.
+func (h *Handler) cookies() middleware.SessionCookies {
+	return middleware.SessionCookiesFromConfig(h.runtime.Config)
+}
+
+// CSRFToken handles GET /v1/auth/csrf - issue a new double-submit CSRF token.
+// The token is set as a script-readable cookie and returned in the body; clients
+// send it back in the X-CSRF-Token header on state-changing requests.
+func (h *Handler) CSRFToken(w http.ResponseWriter, r *http.Request) {
+	cookies := h.cookies()
+	if !cookies.Enabled {
+		http.Error(w, "session cookies are not enabled", http.StatusNotFound)
+		return
+	}
+	token, err := cookies.IssueCSRF(w)
+	if err != nil {
+		h.logger.Error("failed to generate CSRF token", zap.Error(err))
+		http.Error(w, "failed to generate CSRF token", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(csrfTokenResponse{CSRFToken: token})
+}
+
+// writeCookieSession sets the tokens from response as session cookies, issues
+// a CSRF token and writes a body without the tokens.
+func (h *Handler) writeCookieSession(w http.ResponseWriter, response fosite.AccessResponder) {
+	cookies := h.cookies()
+	expiresIn, _ := response.GetExtra("expires_in").(int64)
+	refreshToken, _ := response.GetExtra("refresh_token").(string)
+	cookies.SetTokens(w, response.GetAccessToken(), refreshToken, time.Duration(expiresIn)*time.Second)
+
+	csrfToken, err := cookies.IssueCSRF(w)
+	if err != nil {
+		h.logger.Error("failed to generate CSRF token", zap.Error(err))
+		http.Error(w, "failed to generate CSRF token", http.StatusInternalServerError)
+		return
+	}
+	scope, _ := response.GetExtra("scope").(string)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(cookieSessionResponse{
+		TokenType: "cookie",
+		ExpiresIn: expiresIn,
+		Scope:     scope,
+		CSRFToken: csrfToken,
+	})
+}
+
+// cookieSessionToken returns the token to revoke for a cookie session and its
+// type hint, preferring the refresh token since revoking it also revokes the
+// access tokens issued with it.
+func cookieSessionToken(r *http.Request) (token, hint string) {
+	if token := middleware.RefreshToken(r); token != "" {
+		return token, "refresh_token"
+	}
+	if token := middleware.AccessToken(r); token != "" {
+		return token, "access_token"
+	}
+	return "", ""
+}
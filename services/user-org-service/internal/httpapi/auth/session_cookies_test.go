@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+)
+
+// cookieRequest builds a POST carrying the session cookies and, if csrf is
+// set, the X-CSRF-Token header.
+func cookieRequest(t *testing.T, path string, body any, csrf string) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.AddCookie(&http.Cookie{Name: middleware.AccessTokenCookie, Value: "session-token"})
+	req.AddCookie(&http.Cookie{Name: middleware.RefreshTokenCookie, Value: "refresh-token"})
+	req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: "csrf-token"})
+	if csrf != "" {
+		req.Header.Set(middleware.CSRFHeader, csrf)
+	}
+	return req
+}
+
+func newCookieHandler(t *testing.T, enabled bool) *Handler {
+	t.Helper()
+	h := newTestHandler(t, newTestMagicLink(newFakeMagicLinkStore(), 0))
+	h.runtime.Config.SessionCookiesEnabled = enabled
+	return h
+}
+
+func TestCookieRefreshRequiresCSRF(t *testing.T) {
+	h := newCookieHandler(t, true)
+
+	for _, csrf := range []string{"", "other"} {
+		rec := httptest.NewRecorder()
+		h.Refresh(rec, cookieRequest(t, "/v1/auth/refresh", map[string]string{}, csrf))
+		require.Equal(t, http.StatusForbidden, rec.Code, "csrf %q", csrf)
+	}
+
+	// With the CSRF token the request reaches Fosite, which rejects the unknown client
+	rec := httptest.NewRecorder()
+	h.Refresh(rec, cookieRequest(t, "/v1/auth/refresh", map[string]string{}, "csrf-token"))
+	require.NotEqual(t, http.StatusForbidden, rec.Code)
+}
+
+func TestCookieLogoutRequiresCSRF(t *testing.T) {
+	h := newCookieHandler(t, true)
+
+	for _, csrf := range []string{"", "other"} {
+		rec := httptest.NewRecorder()
+		h.Logout(rec, cookieRequest(t, "/v1/auth/logout", map[string]string{}, csrf))
+		require.Equal(t, http.StatusForbidden, rec.Code, "csrf %q", csrf)
+		require.Empty(t, rec.Result().Cookies(), "cookies are kept when the CSRF check fails")
+	}
+
+	rec := httptest.NewRecorder()
+	h.Logout(rec, cookieRequest(t, "/v1/auth/logout", map[string]string{}, "csrf-token"))
+	require.NotEqual(t, http.StatusForbidden, rec.Code)
+	cleared := map[string]bool{}
+	for _, cookie := range rec.Result().Cookies() {
+		cleared[cookie.Name] = cookie.MaxAge < 0
+	}
+	require.Equal(t, map[string]bool{
+		middleware.AccessTokenCookie:  true,
+		middleware.RefreshTokenCookie: true,
+		middleware.CSRFCookie:         true,
+	}, cleared)
+}
+
+func TestUseCookiesRejectedWhenDisabled(t *testing.T) {
+	h := newCookieHandler(t, false)
+
+	rec := httptest.NewRecorder()
+	h.Login(rec, cookieRequest(t, "/v1/auth/login", map[string]any{
+		"email": "dev@example.com", "password": "secret", "useCookies": true,
+	}, ""))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "session cookies are not enabled")
+
+	rec = httptest.NewRecorder()
+	h.VerifyMagicLink(rec, cookieRequest(t, "/v1/auth/magic-link/verify", map[string]any{
+		"token": "link-token", "useCookies": true,
+	}, ""))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "session cookies are not enabled")
+
+	rec = httptest.NewRecorder()
+	h.CSRFToken(rec, httptest.NewRequest(http.MethodGet, "/v1/auth/csrf", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCSRFTokenResponseKey(t *testing.T) {
+	h := newCookieHandler(t, true)
+
+	csrfCookie := func(rec *httptest.ResponseRecorder) string {
+		for _, cookie := range rec.Result().Cookies() {
+			if cookie.Name == middleware.CSRFCookie {
+				require.False(t, cookie.HttpOnly, "scripts must be able to read the CSRF cookie")
+				return cookie.Value
+			}
+		}
+		t.Fatal("no CSRF cookie set")
+		return ""
+	}
+
+	// GET /v1/auth/csrf and the login/refresh body use the same key
+	rec := httptest.NewRecorder()
+	h.CSRFToken(rec, httptest.NewRequest(http.MethodGet, "/v1/auth/csrf", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, csrfCookie(rec), body["csrf_token"])
+
+	rec = httptest.NewRecorder()
+	response := fosite.NewAccessResponse()
+	response.SetAccessToken("access-token")
+	response.SetExtra("expires_in", int64(3600))
+	response.SetExtra("refresh_token", "refresh-token")
+	h.writeCookieSession(rec, response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	body = map[string]any{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, csrfCookie(rec), body["csrf_token"])
+	require.Equal(t, "cookie", body["token_type"])
+	require.NotContains(t, body, "access_token")
+	require.NotContains(t, body, "refresh_token")
+}
@@ -13,6 +13,16 @@
 //
 // Key Responsibilities:
 //   - ValidateAPIKey: POST /v1/auth/validate-api-key - Validate API key secret
+//     (the response carries the key's network restrictions, the org's allowed
+//     backend regions, inference archival settings, tool-calling limits and
+//     usage record payload handling for the router to enforce)
+//   - Keys issued in a team or project report the parent org as organizationId
+//     (budgets and rate limits are shared) and the sub-org as projectId, with the
+//     parent's settings inherited via orgs.EffectiveMetadata
+//   - Keys of suspended orgs, and of projects under them, are reported invalid
+//   - The optional clientIp feeds new-ASN detection (internal/securityevents)
+//   - Callers authenticate with a service token (middleware.RequireService),
+//     enforced once SERVICE_AUTH_REQUIRED=true
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#FR-004 (API Key Lifecycle)
@@ -28,6 +38,8 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/apikeys"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/orgs"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
@@ -35,6 +47,8 @@ import (
 type ValidateAPIKeyRequest struct {
 	APIKeySecret string `json:"apiKeySecret"`    // The API key secret to validate
 	OrgID        string `json:"orgId,omitempty"` // Optional: UUID or slug (helps narrow search)
+	// ClientIP is the IP the caller's client connected from; used for security event detection.
+	ClientIP string `json:"clientIp,omitempty"`
 }
 
 // ValidateAPIKeyResponse represents the response after validating an API key.
@@ -48,6 +62,28 @@ type ValidateAPIKeyResponse struct {
 	Status         string   `json:"status,omitempty"`
 	ExpiresAt      *string  `json:"expiresAt,omitempty"`
 	Message        string   `json:"message,omitempty"`
+	// Restrictions are enforced by the caller (CIDR allowlist, required headers).
+	Restrictions *apikeys.Restrictions `json:"restrictions,omitempty"`
+	// AllowedRegions is the org's data-residency constraint on backend regions.
+	AllowedRegions []string `json:"allowedRegions,omitempty"`
+	// Archival tells the router whether to archive the org's prompts and responses.
+	Archival *orgs.InferenceArchival `json:"archival,omitempty"`
+	// ToolLimits overrides the router's default tool-calling limits for the org.
+	ToolLimits *orgs.ToolLimits `json:"toolLimits,omitempty"`
+	// ModelEntitlements are the org's model allow/deny lists.
+	ModelEntitlements *orgs.ModelEntitlements `json:"modelEntitlements,omitempty"`
+	// KeyEntitlements are this key's model allow/deny lists, applied on top of the org's.
+	KeyEntitlements *orgs.ModelEntitlements `json:"keyEntitlements,omitempty"`
+	// RateLimits overrides the router's rate limit algorithm and concurrency caps for the org.
+	RateLimits *orgs.RateLimits `json:"rateLimits,omitempty"`
+	// UsagePayloads tells the router how much of the prompt and response to put in usage records.
+	UsagePayloads *orgs.UsagePayloads `json:"usagePayloads,omitempty"`
+	// CORSOrigins are the browser origins allowed to use the org's keys; empty allows any.
+	CORSOrigins []string `json:"corsOrigins,omitempty"`
+	// LogExport tells the router where to stream the org's request and audit logs.
+	LogExport *orgs.LogExport `json:"logExport,omitempty"`
+	// ProjectID is the team or project the key was issued in; empty for top-level orgs.
+	ProjectID string `json:"projectId,omitempty"`
 }
 
 // ValidateAPIKey handles POST /v1/auth/validate-api-key.
@@ -130,12 +166,56 @@ func (h *Handler) ValidateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Data residency is enforced by the router, so an org lookup failure must not
+	// yield an unrestricted result
+	org, err := h.runtime.Postgres.GetOrg(ctx, apiKey.OrgID)
+	if err != nil {
+		http.Error(w, "failed to validate API key", http.StatusInternalServerError)
+		return
+	}
+
+	// Keys issued in a project are billed to, and inherit settings from, the parent org
+	orgID := org.ID
+	var projectID string
+	settings := org.Metadata
+	suspended := org.Status == orgs.OrgStatusSuspended
+	if parent := orgs.ParentFromMetadata(org.Metadata); parent != nil {
+		parentID, err := uuid.Parse(parent.ParentOrgID)
+		if err != nil {
+			http.Error(w, "failed to validate API key", http.StatusInternalServerError)
+			return
+		}
+		parentOrg, err := h.runtime.Postgres.GetOrg(ctx, parentID)
+		if err != nil {
+			http.Error(w, "failed to validate API key", http.StatusInternalServerError)
+			return
+		}
+		orgID = parentOrg.ID
+		projectID = org.ID.String()
+		settings = orgs.EffectiveMetadata(parentOrg.Metadata, org.Metadata)
+		suspended = suspended || parentOrg.Status == orgs.OrgStatusSuspended
+	}
+
+	// Suspending an org freezes its keys and those of its projects
+	if suspended {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ValidateAPIKeyResponse{
+			Valid:   false,
+			Message: "organization is suspended",
+		})
+		return
+	}
+
 	// Update last_used_at (best-effort, non-blocking)
 	go func() {
 		updateCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 		_ = h.runtime.Postgres.UpdateAPIKeyLastUsed(updateCtx, apiKey.ID, time.Now().UTC())
 	}()
+	if req.ClientIP != "" {
+		h.runtime.SecurityEvents.ObserveAPIKeyUse(ctx, apiKey.OrgID, apiKey.ID, req.ClientIP)
+	}
 
 	// Build success response
 	expiresAtStr := ""
@@ -143,15 +223,34 @@ func (h *Handler) ValidateAPIKey(w http.ResponseWriter, r *http.Request) {
 		expiresAtStr = apiKey.ExpiresAt.Format(time.RFC3339)
 	}
 
+	// Fail closed: a key whose stored restrictions cannot be read must not
+	// validate as unrestricted
+	restrictions, err := apikeys.RestrictionsFromAnnotations(apiKey.Annotations)
+	if err != nil {
+		http.Error(w, "failed to validate API key", http.StatusInternalServerError)
+		return
+	}
+
 	response := ValidateAPIKeyResponse{
 		Valid:          true,
 		APIKeyID:       apiKey.ID.String(),
-		OrganizationID: apiKey.OrgID.String(),
+		OrganizationID: orgID.String(),
+		ProjectID:      projectID,
 		PrincipalID:    apiKey.PrincipalID.String(),
 		PrincipalType:  string(apiKey.PrincipalType),
 		Scopes:         apiKey.Scopes,
 		Status:         apiKey.Status,
+		Restrictions:   restrictions,
+		AllowedRegions: orgs.AllowedRegionsFromMetadata(settings),
+		Archival:       orgs.ArchivalFromMetadata(settings),
+		ToolLimits:     orgs.ToolLimitsFromMetadata(settings),
 	}
+	response.ModelEntitlements = orgs.EntitlementsFromMetadata(settings)
+	response.KeyEntitlements = orgs.EntitlementsFromAnnotations(apiKey.Annotations)
+	response.RateLimits = orgs.RateLimitsFromMetadata(settings)
+	response.UsagePayloads = orgs.UsagePayloadsFromMetadata(settings)
+	response.CORSOrigins = orgs.CORSOriginsFromMetadata(settings)
+	response.LogExport = orgs.LogExportFromMetadata(settings)
 	if expiresAtStr != "" {
 		response.ExpiresAt = &expiresAtStr
 	}
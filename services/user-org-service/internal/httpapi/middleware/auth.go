@@ -13,11 +13,13 @@
 //   - internal/oauth: Session type with user/org context
 //
 // Key Responsibilities:
-//   - Extract Bearer token from Authorization header
+//   - Extract Bearer token from Authorization header, or from the session
+//     cookie (with a CSRF check) when cookie mode is enabled
 //   - Validate token using Fosite provider
 //   - Extract user ID, org ID, and scopes from session
 //   - Store authenticated context in request context
 //   - Return 401 Unauthorized for invalid/missing tokens
+//   - Enforce required scopes on admin-only routes (RequireScope)
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#FR-005 (OAuth2 Support)
@@ -34,6 +36,7 @@
 //
 // Error Handling:
 //   - Missing token returns 401 Unauthorized
+//   - Session cookie without a matching X-CSRF-Token on unsafe methods returns 403 Forbidden
 //   - Invalid token returns 401 Unauthorized
 //   - Expired token returns 401 Unauthorized
 //   - Database errors return 500 Internal Server Error
@@ -49,6 +52,8 @@ import (
 	"github.com/ory/fosite"
 	"go.uber.org/zap"
 
+	"github.com/ai-aas/shared-go/requestid"
+
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
 )
@@ -84,10 +89,12 @@ func RequireAuth(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) h
 		}
 	}
 
+	cookies := SessionCookiesFromConfig(rt.Config)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
-			requestID := r.Header.Get("X-Request-ID")
+			requestID := requestid.RequestID(ctx)
 			if requestID == "" {
 				requestID = "unknown"
 			}
@@ -105,9 +112,28 @@ func RequireAuth(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) h
 				zap.String("path", r.URL.Path),
 				zap.String("request_id", requestID),
 				zap.Bool("has_auth_header", authHeader != ""),
+				zap.Bool("has_session_cookie", AccessToken(r) != ""),
 				zap.String("auth_header_prefix", authPrefix))
 
-			if authHeader == "" {
+			cookieToken := ""
+			if authHeader == "" && cookies.Enabled {
+				cookieToken = AccessToken(r)
+			}
+
+			var token string
+			switch {
+			case cookieToken != "":
+				// Cookies are sent by the browser on cross-site requests too
+				if !ValidCSRF(r) {
+					logger.Warn("RequireAuth: session cookie request failed CSRF check",
+						zap.String("path", r.URL.Path),
+						zap.String("request_id", requestID),
+						zap.String("origin", r.Header.Get("Origin")))
+					http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+					return
+				}
+				token = cookieToken
+			case authHeader == "":
 				logger.Warn("RequireAuth: missing authorization header",
 					zap.String("path", r.URL.Path),
 					zap.String("request_id", requestID),
@@ -115,26 +141,26 @@ func RequireAuth(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) h
 					zap.String("user_agent", r.Header.Get("User-Agent")))
 				http.Error(w, "missing authorization header", http.StatusUnauthorized)
 				return
-			}
-
-			// Parse "Bearer <token>"
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				logger.Warn("RequireAuth: invalid authorization header format",
-					zap.String("path", r.URL.Path),
-					zap.String("request_id", requestID),
-					zap.String("auth_header_prefix", parts[0]))
-				http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
-				return
-			}
+			default:
+				// Parse "Bearer <token>"
+				parts := strings.SplitN(authHeader, " ", 2)
+				if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+					logger.Warn("RequireAuth: invalid authorization header format",
+						zap.String("path", r.URL.Path),
+						zap.String("request_id", requestID),
+						zap.String("auth_header_prefix", parts[0]))
+					http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
+					return
+				}
 
-			token := parts[1]
-			if token == "" {
-				logger.Warn("RequireAuth: empty bearer token",
-					zap.String("path", r.URL.Path),
-					zap.String("request_id", requestID))
-				http.Error(w, "empty bearer token", http.StatusUnauthorized)
-				return
+				token = parts[1]
+				if token == "" {
+					logger.Warn("RequireAuth: empty bearer token",
+						zap.String("path", r.URL.Path),
+						zap.String("request_id", requestID))
+					http.Error(w, "empty bearer token", http.StatusUnauthorized)
+					return
+				}
 			}
 
 			tokenPrefix := token
@@ -230,6 +256,11 @@ func RequireAuth(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) h
 				}
 			}
 
+			// Scopes granted on the token are authoritative for scope checks
+			if len(session.GrantedScopes) == 0 {
+				session.GrantedScopes = append([]string{}, accessRequester.GetGrantedScopes()...)
+			}
+
 			// Store authenticated context in request
 			ctx = context.WithValue(ctx, UserIDKey, userID)
 			ctx = context.WithValue(ctx, OrgIDKey, orgID)
@@ -247,6 +278,40 @@ func RequireAuth(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) h
 	}
 }
 
+// RequireScope creates middleware that rejects authenticated requests lacking the
+// given OAuth scope. Must be applied after RequireAuth.
+// Returns 401 Unauthorized if unauthenticated and 403 Forbidden if the scope is missing.
+func RequireScope(scope string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetAuthenticatedUser(r.Context())
+			if user == nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !user.HasScope(scope) {
+				logger.Warn("RequireScope: missing required scope",
+					zap.String("path", r.URL.Path),
+					zap.String("user_id", user.UserID.String()),
+					zap.String("scope", scope))
+				http.Error(w, "insufficient scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasScope reports whether the user was granted the given scope.
+func (u *AuthenticatedUser) HasScope(scope string) bool {
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUserID extracts the authenticated user ID from the request context.
 // Returns uuid.Nil if not authenticated (should not happen if RequireAuth middleware is used).
 func GetUserID(ctx context.Context) uuid.UUID {
@@ -0,0 +1,110 @@
+// Package middleware (service.go) authenticates calls from other platform services.
+//
+// Purpose:
+//
+//	Internal endpoints such as /v1/auth/validate-api-key used to trust any
+//	caller that could reach them. RequireService requires a service token
+//	minted at /v1/auth/service-token instead, verified locally against the
+//	OAuth provider, and checks the caller's client ID against
+//	SERVICE_AUTH_CLIENTS.
+//
+// Debugging Notes:
+//   - Until SERVICE_AUTH_REQUIRED=true, unauthenticated calls are allowed but
+//     counted in user_org_service_service_auth_requests_total, so callers can
+//     be given identities before enforcement is switched on
+//   - Service tokens are client_credentials tokens with the "service" scope
+//     and no user
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ory/fosite"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+)
+
+// RequireService authenticates the calling service by its service token.
+func RequireService(rt *bootstrap.Runtime, logger *zap.Logger) func(http.Handler) http.Handler {
+	auth := tokens.ServiceAuth{
+		Verifier: providerVerifier{provider: rt.Provider},
+		Allowed:  rt.Config.ServiceAuthClients,
+		Enforce:  rt.Config.ServiceAuthRequired,
+		OnFailure: func(r *http.Request, err error) {
+			result := serviceAuthResult(err)
+			metrics.RecordServiceAuth(result)
+			logger.Warn("service authentication failed",
+				zap.String("path", r.URL.Path),
+				zap.String("result", result),
+				zap.Bool("enforced", rt.Config.ServiceAuthRequired),
+				zap.Error(err))
+		},
+	}
+	require := tokens.RequireService(auth)
+	return func(next http.Handler) http.Handler {
+		return require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := tokens.ServiceFromContext(r.Context()); ok {
+				metrics.RecordServiceAuth("authenticated")
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// serviceAuthResult is the metric label of a failed authentication.
+func serviceAuthResult(err error) string {
+	switch {
+	case errors.Is(err, tokens.ErrMissingToken):
+		return "missing_token"
+	case errors.Is(err, tokens.ErrServiceNotAllowed):
+		return "not_allowed"
+	case errors.Is(err, tokens.ErrInvalidToken):
+		return "invalid_token"
+	default:
+		return "error"
+	}
+}
+
+// providerVerifier verifies this service's own access tokens without a
+// round trip through the introspection endpoint.
+type providerVerifier struct {
+	provider fosite.OAuth2Provider
+}
+
+func (v providerVerifier) Verify(ctx context.Context, token string) (tokens.Claims, error) {
+	if v.provider == nil {
+		return tokens.Claims{}, errors.New("oauth provider not configured")
+	}
+	_, requester, err := v.provider.IntrospectToken(ctx, token, fosite.AccessToken, &oauth.Session{})
+	if err != nil {
+		var rfcErr *fosite.RFC6749Error
+		if errors.As(err, &rfcErr) && rfcErr.CodeField >= http.StatusInternalServerError {
+			return tokens.Claims{}, err
+		}
+		return tokens.Claims{}, tokens.ErrInactive
+	}
+	if requester == nil {
+		return tokens.Claims{}, tokens.ErrInactive
+	}
+	claims := tokens.Claims{
+		Active: true,
+		Scopes: requester.GetGrantedScopes(),
+	}
+	if client := requester.GetClient(); client != nil {
+		claims.ClientID = client.GetID()
+	}
+	if session, ok := requester.GetSession().(*oauth.Session); ok {
+		claims.Subject = session.Subject
+		claims.UserID = session.UserID
+		claims.OrgID = session.OrgID
+		claims.ExpiresAt = session.GetExpiresAt(fosite.AccessToken)
+	}
+	return claims, nil
+}
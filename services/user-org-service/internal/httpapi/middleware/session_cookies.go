@@ -0,0 +1,156 @@
+// Package middleware provides session cookie and CSRF helpers for the web console.
+//
+// Purpose:
+//
+//	Browser clients can hold their OAuth tokens in HTTP-only cookies instead
+//	of localStorage, where any injected script could read them. Cookie
+//	requests are protected against cross-site request forgery with a
+//	double-submit token: a readable CSRF cookie whose value must be echoed in
+//	the X-CSRF-Token header on every state-changing request.
+//
+// Debugging Notes:
+//   - Cookies are only issued when SESSION_COOKIES_ENABLED=true and the login
+//     request sets "useCookies": true; bearer tokens keep working regardless
+//   - The refresh token cookie is scoped to /v1/auth so it is not sent on
+//     every API call
+//   - GET, HEAD and OPTIONS requests never need the CSRF header
+//   - A request with an Authorization header ignores the session cookie
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+)
+
+// Cookie names and the header carrying the double-submit CSRF token.
+const (
+	AccessTokenCookie  = "aiaas_session"
+	RefreshTokenCookie = "aiaas_refresh"
+	CSRFCookie         = "aiaas_csrf"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// refreshCookiePath limits the refresh cookie to the auth endpoints.
+const refreshCookiePath = "/v1/auth"
+
+// SessionCookies issues and reads the console's session cookies.
+type SessionCookies struct {
+	Enabled  bool
+	Domain   string
+	SameSite http.SameSite
+	Secure   bool
+}
+
+// SessionCookiesFromConfig returns the cookie settings from the service configuration.
+func SessionCookiesFromConfig(cfg *config.Config) SessionCookies {
+	if cfg == nil {
+		return SessionCookies{}
+	}
+	return SessionCookies{
+		Enabled:  cfg.SessionCookiesEnabled,
+		Domain:   cfg.SessionCookieDomain,
+		SameSite: ParseSameSite(cfg.SessionCookieSameSite),
+		Secure:   cfg.SessionCookieSecure,
+	}
+}
+
+// ParseSameSite maps strict, lax or none to its http.SameSite mode, defaulting to strict.
+func ParseSameSite(value string) http.SameSite {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+// SetTokens sets the HTTP-only access and refresh token cookies. An empty
+// refresh token leaves the existing refresh cookie alone.
+func (c SessionCookies) SetTokens(w http.ResponseWriter, accessToken, refreshToken string, accessTTL time.Duration) {
+	http.SetCookie(w, c.cookie(AccessTokenCookie, accessToken, "/", accessTTL, true))
+	if refreshToken != "" {
+		// The refresh token outlives the access token; let it expire with the browser session
+		http.SetCookie(w, c.cookie(RefreshTokenCookie, refreshToken, refreshCookiePath, 0, true))
+	}
+}
+
+// IssueCSRF sets a new CSRF cookie readable by scripts and returns its value.
+func (c SessionCookies) IssueCSRF(w http.ResponseWriter) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	http.SetCookie(w, c.cookie(CSRFCookie, token, "/", 0, false))
+	return token, nil
+}
+
+// Clear expires all session cookies.
+func (c SessionCookies) Clear(w http.ResponseWriter) {
+	for _, cookie := range []*http.Cookie{
+		c.cookie(AccessTokenCookie, "", "/", -1, true),
+		c.cookie(RefreshTokenCookie, "", refreshCookiePath, -1, true),
+		c.cookie(CSRFCookie, "", "/", -1, false),
+	} {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// cookie builds a cookie with the configured domain, SameSite and Secure
+// attributes. A zero ttl makes a browser-session cookie; a negative one deletes it.
+func (c SessionCookies) cookie(name, value, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.Domain,
+		Secure:   c.Secure || c.SameSite == http.SameSiteNoneMode,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+	switch {
+	case ttl < 0:
+		cookie.MaxAge = -1
+	case ttl > 0:
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	return cookie
+}
+
+// AccessToken returns the access token from the session cookie, or "".
+func AccessToken(r *http.Request) string {
+	return cookieValue(r, AccessTokenCookie)
+}
+
+// RefreshToken returns the refresh token from the refresh cookie, or "".
+func RefreshToken(r *http.Request) string {
+	return cookieValue(r, RefreshTokenCookie)
+}
+
+// ValidCSRF reports whether r may proceed under the double-submit check:
+// safe methods always may, others must echo the CSRF cookie in X-CSRF-Token.
+func ValidCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie := cookieValue(r, CSRFCookie)
+	header := r.Header.Get(CSRFHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func cookieValue(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
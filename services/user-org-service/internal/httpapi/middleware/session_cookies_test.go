@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ory/fosite/storage"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/config"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+)
+
+func TestValidCSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		want   bool
+	}{
+		{name: "safe method without token", method: http.MethodGet, want: true},
+		{name: "head without token", method: http.MethodHead, want: true},
+		{name: "options without token", method: http.MethodOptions, want: true},
+		{name: "missing cookie and header", method: http.MethodPost},
+		{name: "missing header", method: http.MethodPost, cookie: "token-a"},
+		{name: "missing cookie", method: http.MethodPost, header: "token-a"},
+		{name: "mismatched", method: http.MethodPost, cookie: "token-a", header: "token-b"},
+		{name: "mismatched delete", method: http.MethodDelete, cookie: "token-a", header: "token-b"},
+		{name: "matching", method: http.MethodPost, cookie: "token-a", header: "token-a", want: true},
+		{name: "matching patch", method: http.MethodPatch, cookie: "token-a", header: "token-a", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/orgs", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			require.Equal(t, tt.want, ValidCSRF(req))
+		})
+	}
+}
+
+func TestRequireAuthSessionCookieCSRF(t *testing.T) {
+	provider, err := oauth.NewProvider(oauth.ProviderDependencies{
+		Storage:    storage.NewMemoryStore(),
+		HMACSecret: []byte("0123456789abcdef0123456789abcdef"),
+	})
+	require.NoError(t, err)
+	rt := &bootstrap.Runtime{
+		Config:   &config.Config{SessionCookiesEnabled: true},
+		Provider: provider,
+	}
+	handler := RequireAuth(rt, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request with an invalid session must not reach the handler")
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		csrf   string
+		want   int
+	}{
+		// The CSRF check runs first, so a valid one falls through to token
+		// validation, which rejects the fake session token
+		{name: "post without CSRF header", method: http.MethodPost, want: http.StatusForbidden},
+		{name: "post with mismatched CSRF header", method: http.MethodPost, csrf: "other", want: http.StatusForbidden},
+		{name: "post with matching CSRF header", method: http.MethodPost, csrf: "csrf-token", want: http.StatusUnauthorized},
+		{name: "get without CSRF header", method: http.MethodGet, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/orgs", nil)
+			req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "session-token"})
+			req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: "csrf-token"})
+			if tt.csrf != "" {
+				req.Header.Set(CSRFHeader, tt.csrf)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+
+	t.Run("bearer token skips the CSRF check", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/orgs", nil)
+		req.Header.Set("Authorization", "Bearer bearer-token")
+		req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "session-token"})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
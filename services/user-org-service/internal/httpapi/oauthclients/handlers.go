@@ -0,0 +1,457 @@
+// Package oauthclients provides OAuth2 client management endpoints.
+//
+// Purpose:
+//
+//	This package lets platform admins register OAuth2 clients (confidential
+//	or public, with redirect URIs and allowed grants and scopes) instead of
+//	relying on the single statically configured client. Registered clients
+//	are stored in Postgres and resolved by the Fosite store at token time.
+//
+// Dependencies:
+//   - github.com/go-chi/chi/v5: HTTP router
+//   - internal/bootstrap: Runtime dependencies
+//   - internal/oauth: Registration rules and secret hashing
+//   - internal/storage/postgres: Client persistence
+//
+// Key Responsibilities:
+//   - CreateClient: POST /v1/admin/oauth-clients - Register a client (secret returned once)
+//   - ListClients: GET /v1/admin/oauth-clients - List clients, optionally by ?orgId=
+//   - GetClient: GET /v1/admin/oauth-clients/{clientId} - Get a client
+//   - UpdateClient: PATCH /v1/admin/oauth-clients/{clientId} - Update redirect URIs, grants, scopes or status
+//   - DeleteClient: DELETE /v1/admin/oauth-clients/{clientId} - Delete a client
+//   - RotateSecret: POST /v1/admin/oauth-clients/{clientId}/rotate-secret - Issue a new secret
+//
+// Debugging Notes:
+//   - Routes must be mounted behind RequireAuth and the admin scope
+//   - The configured OAUTH_CLIENT_ID and device client IDs are reserved
+//   - Public/confidential cannot change after registration
+//   - Disabling a client makes token requests for it fail immediately
+package oauthclients
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// clientIDPattern restricts caller-chosen client IDs.
+var clientIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,63}$`)
+
+// RegisterRoutes mounts client management routes beneath /v1/admin/oauth-clients.
+func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger) {
+	if rt == nil || rt.Postgres == nil {
+		return
+	}
+	handler := &Handler{
+		runtime: rt,
+		logger:  logger,
+	}
+	router.Post("/v1/admin/oauth-clients", handler.CreateClient)
+	router.Get("/v1/admin/oauth-clients", handler.ListClients)
+	router.Get("/v1/admin/oauth-clients/{clientId}", handler.GetClient)
+	router.Patch("/v1/admin/oauth-clients/{clientId}", handler.UpdateClient)
+	router.Delete("/v1/admin/oauth-clients/{clientId}", handler.DeleteClient)
+	router.Post("/v1/admin/oauth-clients/{clientId}/rotate-secret", handler.RotateSecret)
+}
+
+// Handler serves OAuth2 client management endpoints.
+type Handler struct {
+	runtime *bootstrap.Runtime
+	logger  *zap.Logger
+}
+
+// CreateClientRequest represents the payload for registering a client.
+type CreateClientRequest struct {
+	ClientID      string   `json:"clientId,omitempty"` // Generated when empty
+	OrgID         string   `json:"orgId,omitempty"`    // Owning organization (optional)
+	Name          string   `json:"name"`
+	Public        bool     `json:"public"`
+	RedirectURIs  []string `json:"redirectUris,omitempty"`
+	GrantTypes    []string `json:"grantTypes,omitempty"`
+	ResponseTypes []string `json:"responseTypes,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+}
+
+// UpdateClientRequest represents the payload for updating a client. Omitted
+// fields keep their current values.
+type UpdateClientRequest struct {
+	Name          *string   `json:"name,omitempty"`
+	RedirectURIs  *[]string `json:"redirectUris,omitempty"`
+	GrantTypes    *[]string `json:"grantTypes,omitempty"`
+	ResponseTypes *[]string `json:"responseTypes,omitempty"`
+	Scopes        *[]string `json:"scopes,omitempty"`
+	Status        *string   `json:"status,omitempty"` // active or disabled
+}
+
+// ClientResponse describes a registered client. ClientSecret is only set
+// when a secret was just issued.
+type ClientResponse struct {
+	ClientID      string   `json:"clientId"`
+	OrgID         *string  `json:"orgId,omitempty"`
+	Name          string   `json:"name"`
+	Public        bool     `json:"public"`
+	RedirectURIs  []string `json:"redirectUris"`
+	GrantTypes    []string `json:"grantTypes"`
+	ResponseTypes []string `json:"responseTypes"`
+	Scopes        []string `json:"scopes"`
+	Status        string   `json:"status"`
+	ClientSecret  string   `json:"clientSecret,omitempty"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// CreateClient handles POST /v1/admin/oauth-clients.
+func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req CreateClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	clientID := req.ClientID
+	if clientID == "" {
+		clientID = uuid.NewString()
+	} else if !clientIDPattern.MatchString(clientID) {
+		http.Error(w, "clientId must be 3-64 lowercase letters, digits, '.', '_' or '-'", http.StatusBadRequest)
+		return
+	}
+	if h.reserved(clientID) {
+		http.Error(w, "client ID is reserved", http.StatusConflict)
+		return
+	}
+
+	var orgID *uuid.UUID
+	if req.OrgID != "" {
+		id, err := uuid.Parse(req.OrgID)
+		if err != nil {
+			http.Error(w, "invalid orgId", http.StatusBadRequest)
+			return
+		}
+		if _, err := h.runtime.Postgres.GetOrg(ctx, id); err != nil {
+			if errors.Is(err, postgres.ErrNotFound) {
+				http.Error(w, "organization not found", http.StatusNotFound)
+				return
+			}
+			h.logger.Error("failed to load organization", zap.Error(err), zap.String("orgId", req.OrgID))
+			http.Error(w, "failed to load organization", http.StatusInternalServerError)
+			return
+		}
+		orgID = &id
+	}
+
+	registration := oauth.ClientRegistration{
+		Name:          req.Name,
+		Public:        req.Public,
+		RedirectURIs:  req.RedirectURIs,
+		GrantTypes:    req.GrantTypes,
+		ResponseTypes: req.ResponseTypes,
+		Scopes:        req.Scopes,
+	}
+	if err := registration.Normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var secret string
+	var secretHash *string
+	if !registration.Public {
+		var err error
+		if secret, secretHash, err = h.newSecret(r); err != nil {
+			h.logger.Error("failed to generate client secret", zap.Error(err))
+			http.Error(w, "failed to generate client secret", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	client, err := h.runtime.Postgres.CreateOAuthClient(ctx, postgres.CreateOAuthClientParams{
+		ID:            clientID,
+		OrgID:         orgID,
+		Name:          registration.Name,
+		SecretHash:    secretHash,
+		Public:        registration.Public,
+		RedirectURIs:  registration.RedirectURIs,
+		GrantTypes:    registration.GrantTypes,
+		ResponseTypes: registration.ResponseTypes,
+		Scopes:        registration.Scopes,
+		Status:        oauth.ClientStatusActive,
+	})
+	if err != nil {
+		if errors.Is(err, postgres.ErrConflict) {
+			http.Error(w, "client ID already exists", http.StatusConflict)
+			return
+		}
+		h.logger.Error("failed to create OAuth client", zap.Error(err), zap.String("clientId", clientID))
+		http.Error(w, "failed to create OAuth client", http.StatusInternalServerError)
+		return
+	}
+
+	h.emitAudit(r, audit.ActionOAuthClientCreate, client)
+	response := toClientResponse(client)
+	response.ClientSecret = secret
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// ListClients handles GET /v1/admin/oauth-clients.
+func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
+	var orgID *uuid.UUID
+	if raw := r.URL.Query().Get("orgId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "invalid orgId", http.StatusBadRequest)
+			return
+		}
+		orgID = &id
+	}
+
+	clients, err := h.runtime.Postgres.ListOAuthClients(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list OAuth clients", zap.Error(err))
+		http.Error(w, "failed to list OAuth clients", http.StatusInternalServerError)
+		return
+	}
+	response := make([]ClientResponse, 0, len(clients))
+	for _, client := range clients {
+		response = append(response, toClientResponse(client))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"clients": response})
+}
+
+// GetClient handles GET /v1/admin/oauth-clients/{clientId}.
+func (h *Handler) GetClient(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.loadClient(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toClientResponse(client))
+}
+
+// UpdateClient handles PATCH /v1/admin/oauth-clients/{clientId}.
+func (h *Handler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req UpdateClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	client, ok := h.loadClient(w, r)
+	if !ok {
+		return
+	}
+
+	registration := oauth.ClientRegistration{
+		Name:          client.Name,
+		Public:        client.Public,
+		RedirectURIs:  client.RedirectURIs,
+		GrantTypes:    client.GrantTypes,
+		ResponseTypes: client.ResponseTypes,
+		Scopes:        client.Scopes,
+	}
+	if req.Name != nil {
+		registration.Name = *req.Name
+	}
+	if req.RedirectURIs != nil {
+		registration.RedirectURIs = *req.RedirectURIs
+	}
+	if req.GrantTypes != nil {
+		registration.GrantTypes = *req.GrantTypes
+	}
+	if req.ResponseTypes != nil {
+		registration.ResponseTypes = *req.ResponseTypes
+	}
+	if req.Scopes != nil {
+		registration.Scopes = *req.Scopes
+	}
+	if err := registration.Normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status := client.Status
+	if req.Status != nil {
+		if *req.Status != oauth.ClientStatusActive && *req.Status != oauth.ClientStatusDisabled {
+			http.Error(w, "status must be active or disabled", http.StatusBadRequest)
+			return
+		}
+		status = *req.Status
+	}
+
+	updated, err := h.runtime.Postgres.UpdateOAuthClient(ctx, postgres.UpdateOAuthClientParams{
+		ID:            client.ID,
+		Version:       client.Version,
+		Name:          registration.Name,
+		RedirectURIs:  registration.RedirectURIs,
+		GrantTypes:    registration.GrantTypes,
+		ResponseTypes: registration.ResponseTypes,
+		Scopes:        registration.Scopes,
+		Status:        status,
+		Metadata:      client.Metadata,
+	})
+	if err != nil {
+		h.writeUpdateError(w, err, client.ID)
+		return
+	}
+
+	h.emitAudit(r, audit.ActionOAuthClientUpdate, updated)
+	writeJSON(w, http.StatusOK, toClientResponse(updated))
+}
+
+// DeleteClient handles DELETE /v1/admin/oauth-clients/{clientId}.
+func (h *Handler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.loadClient(w, r)
+	if !ok {
+		return
+	}
+	if err := h.runtime.Postgres.DeleteOAuthClient(r.Context(), client.ID, client.Version); err != nil {
+		h.writeUpdateError(w, err, client.ID)
+		return
+	}
+	h.emitAudit(r, audit.ActionOAuthClientDelete, client)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateSecret handles POST /v1/admin/oauth-clients/{clientId}/rotate-secret.
+// The previous secret stops working immediately.
+func (h *Handler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.loadClient(w, r)
+	if !ok {
+		return
+	}
+	if client.Public {
+		http.Error(w, "public clients have no secret", http.StatusBadRequest)
+		return
+	}
+	secret, secretHash, err := h.newSecret(r)
+	if err != nil {
+		h.logger.Error("failed to generate client secret", zap.Error(err))
+		http.Error(w, "failed to generate client secret", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.runtime.Postgres.UpdateOAuthClient(r.Context(), postgres.UpdateOAuthClientParams{
+		ID:            client.ID,
+		Version:       client.Version,
+		Name:          client.Name,
+		SecretHash:    secretHash,
+		RedirectURIs:  client.RedirectURIs,
+		GrantTypes:    client.GrantTypes,
+		ResponseTypes: client.ResponseTypes,
+		Scopes:        client.Scopes,
+		Status:        client.Status,
+		Metadata:      client.Metadata,
+	})
+	if err != nil {
+		h.writeUpdateError(w, err, client.ID)
+		return
+	}
+
+	h.emitAudit(r, audit.ActionOAuthClientRotateSecret, updated)
+	response := toClientResponse(updated)
+	response.ClientSecret = secret
+	writeJSON(w, http.StatusOK, response)
+}
+
+// loadClient loads the {clientId} client, writing 404 when it does not exist.
+func (h *Handler) loadClient(w http.ResponseWriter, r *http.Request) (postgres.OAuthClient, bool) {
+	clientID := chi.URLParam(r, "clientId")
+	client, err := h.runtime.Postgres.GetOAuthClient(r.Context(), clientID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			http.Error(w, "OAuth client not found", http.StatusNotFound)
+			return postgres.OAuthClient{}, false
+		}
+		h.logger.Error("failed to get OAuth client", zap.Error(err), zap.String("clientId", clientID))
+		http.Error(w, "failed to retrieve OAuth client", http.StatusInternalServerError)
+		return postgres.OAuthClient{}, false
+	}
+	return client, true
+}
+
+func (h *Handler) writeUpdateError(w http.ResponseWriter, err error, clientID string) {
+	if errors.Is(err, postgres.ErrOptimisticLock) {
+		http.Error(w, "OAuth client was modified concurrently", http.StatusConflict)
+		return
+	}
+	h.logger.Error("failed to update OAuth client", zap.Error(err), zap.String("clientId", clientID))
+	http.Error(w, "failed to update OAuth client", http.StatusInternalServerError)
+}
+
+// newSecret generates a client secret and its hash.
+func (h *Handler) newSecret(r *http.Request) (string, *string, error) {
+	secret, err := oauth.GenerateClientSecret()
+	if err != nil {
+		return "", nil, err
+	}
+	hash, err := oauth.HashClientSecret(r.Context(), h.runtime.OAuthConfig, secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return secret, &hash, nil
+}
+
+// reserved reports whether clientID belongs to a statically configured client,
+// which would shadow a registered one.
+func (h *Handler) reserved(clientID string) bool {
+	cfg := h.runtime.Config
+	if cfg == nil {
+		return false
+	}
+	return clientID == cfg.OAuthClientID || (cfg.DeviceFlowEnabled && clientID == cfg.DeviceClientID)
+}
+
+func (h *Handler) emitAudit(r *http.Request, action string, client postgres.OAuthClient) {
+	user := middleware.GetAuthenticatedUser(r.Context())
+	if user == nil || h.runtime.Audit == nil {
+		return
+	}
+	event := audit.BuildEvent(user.OrgID, user.UserID, audit.ActorTypeUser, action, audit.TargetTypeOAuthClient, nil)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"client_id":   client.ID,
+		"public":      client.Public,
+		"grant_types": client.GrantTypes,
+		"scopes":      client.Scopes,
+		"status":      client.Status,
+	}
+	if err := h.runtime.Audit.Emit(r.Context(), event); err != nil {
+		h.logger.Warn("failed to emit OAuth client audit event", zap.Error(err), zap.String("clientId", client.ID))
+	}
+}
+
+func toClientResponse(client postgres.OAuthClient) ClientResponse {
+	response := ClientResponse{
+		ClientID:      client.ID,
+		Name:          client.Name,
+		Public:        client.Public,
+		RedirectURIs:  client.RedirectURIs,
+		GrantTypes:    client.GrantTypes,
+		ResponseTypes: client.ResponseTypes,
+		Scopes:        client.Scopes,
+		Status:        client.Status,
+		CreatedAt:     client.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     client.UpdatedAt.Format(time.RFC3339),
+	}
+	if client.OrgID != nil {
+		orgID := client.OrgID.String()
+		response.OrgID = &orgID
+	}
+	return response
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
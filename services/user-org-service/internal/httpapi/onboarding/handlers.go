@@ -0,0 +1,350 @@
+// Package onboarding provides the org onboarding orchestration endpoint.
+//
+// Purpose:
+//
+//	POST /v1/onboarding creates everything a new tenant needs in one call: the
+//	organization, its owner user, a default service account, an initial API
+//	key and default budget/rate limits, then sends a welcome notification.
+//	It replaces the create-org → invite → create-service-account → issue-key
+//	sequence clients previously had to orchestrate (and clean up) themselves.
+//
+// Dependencies:
+//   - internal/bootstrap: Runtime dependencies (Postgres store, config, audit)
+//   - internal/storage/postgres: Data access layer (Store.InTx for atomicity)
+//   - internal/httpapi/apikeys: API key secret generation
+//
+// Key Responsibilities:
+//   - Onboard: POST /v1/onboarding - Create org, owner, service account, API key
+//   - Record default limits in org metadata under "limits"
+//   - Emit audit events and the welcome notification after commit
+//
+// Requirements Reference:
+//   - specs/005-user-org-service/spec.md#US-001 (User & Organization Management)
+//
+// Debugging Notes:
+//   - All rows are written in a single transaction: any failure rolls back
+//     everything and the response is 500 with nothing left behind
+//   - Without owner.password the owner is created with status "invited"
+//   - The API key secret is only returned in this response
+//   - The welcome notification is best-effort and never fails the request
+//
+// Thread Safety:
+//   - Handler methods are safe for concurrent use (stateless, uses runtime dependencies)
+//
+// Error Handling:
+//   - Invalid payload returns 400 Bad Request
+//   - Existing slug returns 409 Conflict
+//   - Any persistence failure returns 500 after rolling back
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/apikeys"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const defaultServiceAccountName = "default"
+
+var errSlugTaken = errors.New("organization with this slug already exists")
+
+// RegisterRoutes mounts the onboarding route.
+func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger) {
+	if rt == nil || rt.Postgres == nil {
+		return
+	}
+	handler := &Handler{
+		runtime:  rt,
+		logger:   logger,
+		notifier: NewNotifier(rt.Config.OnboardingWebhookURL, logger),
+	}
+	router.Post("/v1/onboarding", handler.Onboard)
+}
+
+// Handler serves the onboarding endpoint.
+type Handler struct {
+	runtime  *bootstrap.Runtime
+	logger   *zap.Logger
+	notifier Notifier
+}
+
+// OnboardRequest is the payload for POST /v1/onboarding.
+type OnboardRequest struct {
+	Org                OrgInput    `json:"org"`
+	Owner              OwnerInput  `json:"owner"`
+	ServiceAccountName string      `json:"serviceAccountName,omitempty"`
+	APIKey             APIKeyInput `json:"apiKey"`
+	Limits             *Limits     `json:"limits,omitempty"`
+}
+
+// OrgInput describes the organization to create.
+type OrgInput struct {
+	Name     string         `json:"name"`
+	Slug     string         `json:"slug"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// OwnerInput describes the owner user.
+type OwnerInput struct {
+	Email       string `json:"email"`
+	DisplayName string `json:"displayName,omitempty"`
+	Password    string `json:"password,omitempty"`
+}
+
+// APIKeyInput customizes the initial API key.
+type APIKeyInput struct {
+	Scopes        []string `json:"scopes,omitempty"`
+	ExpiresInDays *int     `json:"expiresInDays,omitempty"`
+}
+
+// Limits are the default budget and rate limits stored on the org.
+type Limits struct {
+	RateLimitRPS     int     `json:"rateLimitRps"`
+	RateLimitBurst   int     `json:"rateLimitBurst"`
+	MonthlyBudgetUSD float64 `json:"monthlyBudgetUsd"`
+}
+
+// OnboardResponse is returned on success. It is the only place the API key
+// secret is ever shown.
+type OnboardResponse struct {
+	OrgID            string                       `json:"orgId"`
+	Slug             string                       `json:"slug"`
+	OwnerUserID      string                       `json:"ownerUserId"`
+	OwnerStatus      string                       `json:"ownerStatus"`
+	ServiceAccountID string                       `json:"serviceAccountId"`
+	APIKey           apikeys.IssuedAPIKeyResponse `json:"apiKey"`
+	Limits           Limits                       `json:"limits"`
+}
+
+// created holds the rows written by the onboarding transaction.
+type created struct {
+	org    postgres.Org
+	owner  postgres.User
+	sa     postgres.ServiceAccount
+	apiKey postgres.APIKey
+}
+
+// Onboard handles POST /v1/onboarding.
+func (h *Handler) Onboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req OnboardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request payload", zap.Error(err))
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Org.Slug))
+	email := strings.ToLower(strings.TrimSpace(req.Owner.Email))
+	if req.Org.Name == "" || slug == "" || email == "" {
+		http.Error(w, "org.name, org.slug and owner.email are required", http.StatusBadRequest)
+		return
+	}
+
+	limits := Limits{
+		RateLimitRPS:     h.runtime.Config.OnboardingRateLimitRPS,
+		RateLimitBurst:   h.runtime.Config.OnboardingRateLimitBurst,
+		MonthlyBudgetUSD: h.runtime.Config.OnboardingMonthlyBudgetUSD,
+	}
+	if req.Limits != nil {
+		limits = *req.Limits
+	}
+	if limits.RateLimitRPS <= 0 || limits.RateLimitBurst < limits.RateLimitRPS || limits.MonthlyBudgetUSD < 0 {
+		http.Error(w, "limits must have rateLimitRps > 0, rateLimitBurst >= rateLimitRps and monthlyBudgetUsd >= 0", http.StatusBadRequest)
+		return
+	}
+
+	scopes := req.APIKey.Scopes
+	if len(scopes) == 0 {
+		scopes = h.runtime.Config.OnboardingAPIKeyScopes
+	}
+	var expiresAt *time.Time
+	if req.APIKey.ExpiresInDays != nil && *req.APIKey.ExpiresInDays > 0 {
+		exp := time.Now().UTC().Add(time.Duration(*req.APIKey.ExpiresInDays) * 24 * time.Hour)
+		expiresAt = &exp
+	}
+
+	// Hashing and secret generation happen before the transaction so it stays short
+	ownerStatus := "active"
+	password := req.Owner.Password
+	if password == "" {
+		// Owner completes the invite/recovery flow to set a password
+		ownerStatus = "invited"
+		temp, _, err := apikeys.GenerateSecret()
+		if err != nil {
+			h.logger.Error("failed to generate temporary password", zap.Error(err))
+			http.Error(w, "failed to onboard organization", http.StatusInternalServerError)
+			return
+		}
+		password = temp
+	}
+	passwordHash, err := security.HashPassword(password)
+	if err != nil {
+		h.logger.Error("failed to hash owner password", zap.Error(err))
+		http.Error(w, "failed to onboard organization", http.StatusInternalServerError)
+		return
+	}
+	secret, fingerprint, err := apikeys.GenerateSecret()
+	if err != nil {
+		h.logger.Error("failed to generate secret", zap.Error(err))
+		http.Error(w, "failed to onboard organization", http.StatusInternalServerError)
+		return
+	}
+
+	displayName := req.Owner.DisplayName
+	if displayName == "" {
+		displayName = email
+	}
+	saName := req.ServiceAccountName
+	if saName == "" {
+		saName = defaultServiceAccountName
+	}
+	orgMetadata := map[string]any{}
+	for k, v := range req.Org.Metadata {
+		orgMetadata[k] = v
+	}
+	orgMetadata["limits"] = map[string]any{
+		"rate_limit_rps":     limits.RateLimitRPS,
+		"rate_limit_burst":   limits.RateLimitBurst,
+		"monthly_budget_usd": limits.MonthlyBudgetUSD,
+	}
+
+	var out created
+	err = h.runtime.Postgres.InTx(ctx, func(ctx context.Context) error {
+		if _, err := h.runtime.Postgres.GetOrgBySlug(ctx, slug); err == nil {
+			return errSlugTaken
+		} else if !errors.Is(err, postgres.ErrNotFound) {
+			return err
+		}
+
+		org, err := h.runtime.Postgres.CreateOrg(ctx, postgres.CreateOrgParams{
+			ID:       uuid.New(),
+			Slug:     slug,
+			Name:     req.Org.Name,
+			Status:   "active",
+			Metadata: orgMetadata,
+		})
+		if err != nil {
+			return err
+		}
+		out.org = org
+
+		out.owner, err = h.runtime.Postgres.CreateUser(ctx, postgres.CreateUserParams{
+			ID:             uuid.New(),
+			OrgID:          org.ID,
+			Email:          email,
+			DisplayName:    displayName,
+			PasswordHash:   passwordHash,
+			Status:         ownerStatus,
+			MFAMethods:     []string{},
+			RecoveryTokens: []string{},
+			Metadata:       map[string]any{"roles": []string{"owner"}},
+		})
+		if err != nil {
+			return err
+		}
+
+		out.sa, err = h.runtime.Postgres.CreateServiceAccount(ctx, postgres.CreateServiceAccountParams{
+			OrgID:    org.ID,
+			Name:     saName,
+			Status:   "active",
+			Metadata: map[string]any{"created_by": "onboarding"},
+		})
+		if err != nil {
+			return err
+		}
+
+		out.apiKey, err = h.runtime.Postgres.CreateAPIKey(ctx, postgres.CreateAPIKeyParams{
+			OrgID:         org.ID,
+			PrincipalType: postgres.PrincipalTypeServiceAccount,
+			PrincipalID:   out.sa.ID,
+			Fingerprint:   fingerprint,
+			Status:        "active",
+			Scopes:        scopes,
+			ExpiresAt:     expiresAt,
+			Annotations:   map[string]any{"display_name": "initial key"},
+		})
+		if err != nil {
+			return err
+		}
+		return h.emitAudit(ctx, r, out)
+	})
+	if errors.Is(err, errSlugTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.logger.Error("onboarding rolled back", zap.Error(err), zap.String("slug", slug))
+		http.Error(w, "failed to onboard organization; no resources were created", http.StatusInternalServerError)
+		return
+	}
+
+	h.notifier.Welcome(WelcomeEvent{
+		OrgID:      out.org.ID.String(),
+		OrgSlug:    out.org.Slug,
+		OrgName:    out.org.Name,
+		OwnerEmail: out.owner.Email,
+		OwnerName:  out.owner.DisplayName,
+		Invited:    ownerStatus == "invited",
+		OccurredAt: time.Now().UTC(),
+	})
+
+	resp := OnboardResponse{
+		OrgID:            out.org.ID.String(),
+		Slug:             out.org.Slug,
+		OwnerUserID:      out.owner.ID.String(),
+		OwnerStatus:      out.owner.Status,
+		ServiceAccountID: out.sa.ID.String(),
+		APIKey: apikeys.IssuedAPIKeyResponse{
+			APIKeyID:    out.apiKey.ID.String(),
+			Secret:      secret,
+			Fingerprint: fingerprint,
+			Status:      out.apiKey.Status,
+		},
+		Limits: limits,
+	}
+	if out.apiKey.ExpiresAt != nil {
+		exp := out.apiKey.ExpiresAt.Format(time.RFC3339)
+		resp.APIKey.ExpiresAt = &exp
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// emitAudit records the onboarding and each created resource in the
+// transaction carried by ctx.
+func (h *Handler) emitAudit(ctx context.Context, r *http.Request, out created) error {
+	actorID := middleware.GetUserID(r.Context())
+	orgID := out.org.ID
+
+	emit := func(action, targetType string, targetID *uuid.UUID, metadata map[string]any) error {
+		event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, action, targetType, targetID)
+		event = audit.BuildEventFromRequest(event, r)
+		event.Metadata = metadata
+		return h.runtime.EmitAuditInTx(ctx, event)
+	}
+	return errors.Join(
+		emit(audit.ActionOrgCreate, audit.TargetTypeOrg, &orgID, map[string]any{"slug": out.org.Slug, "name": out.org.Name}),
+		emit(audit.ActionUserCreate, audit.TargetTypeUser, &out.owner.ID, map[string]any{"email": out.owner.Email, "roles": []string{"owner"}}),
+		emit(audit.ActionAPIKeyIssue, audit.TargetTypeAPIKey, &out.apiKey.ID, map[string]any{"service_account_id": out.sa.ID.String(), "scopes": out.apiKey.Scopes}),
+		emit(audit.ActionOrgOnboard, audit.TargetTypeOrg, &orgID, map[string]any{"owner_user_id": out.owner.ID.String(), "service_account_id": out.sa.ID.String()}),
+	)
+}
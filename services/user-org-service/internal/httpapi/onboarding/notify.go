@@ -0,0 +1,50 @@
+package onboarding
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// WelcomeEvent is posted to the onboarding webhook after an org is created.
+type WelcomeEvent struct {
+	Type       string    `json:"type"`
+	OrgID      string    `json:"orgId"`
+	OrgSlug    string    `json:"orgSlug"`
+	OrgName    string    `json:"orgName"`
+	OwnerEmail string    `json:"ownerEmail"`
+	OwnerName  string    `json:"ownerName"`
+	Invited    bool      `json:"invited"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Notifier delivers the welcome notification. Delivery is best-effort and
+// asynchronous; it must never block or fail onboarding.
+type Notifier interface {
+	Welcome(event WelcomeEvent)
+}
+
+// NewNotifier returns a notifier posting to url, or logging when url is empty.
+func NewNotifier(url string, logger *zap.Logger) Notifier {
+	return webhookNotifier{notifier: notify.New(url, logger), logger: logger}
+}
+
+// webhookNotifier posts WelcomeEvent as JSON; the receiver sends the email.
+type webhookNotifier struct {
+	notifier *notify.Notifier
+	logger   *zap.Logger
+}
+
+func (n webhookNotifier) Welcome(event WelcomeEvent) {
+	event.Type = "org.onboarded"
+	n.notifier.SendAsync(event, func(err error) {
+		if err != nil {
+			n.logger.Warn("welcome webhook failed", zap.Error(err), zap.String("org_id", event.OrgID))
+		}
+	}, "welcome notification",
+		zap.String("org_id", event.OrgID),
+		zap.String("owner_email", event.OwnerEmail),
+	)
+}
@@ -0,0 +1,56 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ArchivalMetadataKey is the org metadata key holding inference archival settings.
+const ArchivalMetadataKey = "inference_archival"
+
+const (
+	defaultArchiveRetentionDays = 30
+	maxArchiveRetentionDays     = 3650
+)
+
+// InferenceArchival opts an org into archiving inference prompts and responses
+// to object storage. The API router encrypts each record, applies PII
+// redaction when RedactPII is set, and deletes it after RetentionDays.
+type InferenceArchival struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retentionDays,omitempty"`
+	RedactPII     bool `json:"redactPii"`
+}
+
+// normalize applies the default retention and validates its range.
+func (a *InferenceArchival) normalize() error {
+	if !a.Enabled {
+		*a = InferenceArchival{}
+		return nil
+	}
+	if a.RetentionDays == 0 {
+		a.RetentionDays = defaultArchiveRetentionDays
+	}
+	if a.RetentionDays < 1 || a.RetentionDays > maxArchiveRetentionDays {
+		return fmt.Errorf("retentionDays must be between 1 and %d", maxArchiveRetentionDays)
+	}
+	return nil
+}
+
+// ArchivalFromMetadata returns the org's archival settings, or nil when archival is off.
+func ArchivalFromMetadata(metadata map[string]any) *InferenceArchival {
+	raw, ok := metadata[ArchivalMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var archival InferenceArchival
+	if err := json.Unmarshal(data, &archival); err != nil || !archival.Enabled {
+		return nil
+	}
+	return &archival
+}
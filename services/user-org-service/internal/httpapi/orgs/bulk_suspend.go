@@ -0,0 +1,233 @@
+package orgs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const maxSuspensionNoteLength = 1000
+
+// Bulk suspension result statuses.
+const (
+	BulkSuspendStatusSuspended        = "suspended"
+	BulkSuspendStatusAlreadySuspended = "already_suspended"
+	BulkSuspendStatusNotFound         = "not_found"
+	BulkSuspendStatusFailed           = "failed"
+)
+
+// RegisterAdminRoutes mounts platform-operator org routes. The caller must
+// restrict them to the admin scope.
+func RegisterAdminRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger) {
+	if rt == nil || rt.Postgres == nil {
+		return
+	}
+	handler := &AdminHandler{
+		runtime:     rt,
+		logger:      logger,
+		routerCache: NewRouterCacheInvalidator(rt.Config.RouterAdminURLs, rt.Config.RouterAdminAPIKey, logger),
+		maxOrgs:     rt.Config.BulkSuspendMaxOrgs,
+	}
+	router.Post("/v1/admin/orgs:bulk-suspend", handler.BulkSuspend)
+}
+
+// AdminHandler serves platform-operator org endpoints.
+type AdminHandler struct {
+	runtime     *bootstrap.Runtime
+	logger      *zap.Logger
+	routerCache RouterCacheInvalidator
+	maxOrgs     int
+}
+
+// BulkSuspendRequest suspends orgs for one reason, e.g. a non-payment run.
+type BulkSuspendRequest struct {
+	// OrgIDs are org UUIDs or slugs.
+	OrgIDs []string `json:"orgIds"`
+	// Reason is a reason code: non_payment, abuse, terms_violation, security or other.
+	Reason string `json:"reason"`
+	Note   string `json:"note,omitempty"`
+}
+
+// BulkSuspendResult is the outcome for one requested org.
+type BulkSuspendResult struct {
+	Ref    string `json:"ref"`
+	OrgID  string `json:"orgId,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkSuspendResponse reports each org's outcome.
+type BulkSuspendResponse struct {
+	BulkID    string              `json:"bulkId"`
+	Reason    string              `json:"reason"`
+	Suspended int                 `json:"suspended"`
+	Results   []BulkSuspendResult `json:"results"`
+	// RouterCacheInvalidated is false when API routers could not be told (or
+	// none is configured); cached keys then keep working for up to a minute.
+	RouterCacheInvalidated bool `json:"routerCacheInvalidated"`
+}
+
+// BulkSuspend handles POST /v1/admin/orgs:bulk-suspend. Each org is suspended
+// independently, so one failure does not stop the rest. Suspended orgs' API
+// keys are rejected at validation, their users cannot log in or refresh
+// sessions, and the API routers drop cached validations for them.
+func (h *AdminHandler) BulkSuspend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req BulkSuspendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+	if !ValidSuspensionReason(req.Reason) {
+		http.Error(w, "reason must be one of non_payment, abuse, terms_violation, security, other", http.StatusBadRequest)
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > maxSuspensionNoteLength {
+		http.Error(w, "note is too long", http.StatusBadRequest)
+		return
+	}
+	refs := make([]string, 0, len(req.OrgIDs))
+	seen := make(map[string]bool, len(req.OrgIDs))
+	for _, ref := range req.OrgIDs {
+		ref = strings.TrimSpace(ref)
+		if ref != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		http.Error(w, "orgIds is required", http.StatusBadRequest)
+		return
+	}
+	if h.maxOrgs > 0 && len(refs) > h.maxOrgs {
+		http.Error(w, "too many orgIds in one request", http.StatusBadRequest)
+		return
+	}
+
+	bulkID := uuid.New()
+	actorID := getActorID(r)
+	resp := BulkSuspendResponse{
+		BulkID:  bulkID.String(),
+		Reason:  req.Reason,
+		Results: make([]BulkSuspendResult, 0, len(refs)),
+	}
+	var suspended []string
+	for _, ref := range refs {
+		result := h.suspend(r, ref, req, bulkID, actorID)
+		if result.Status == BulkSuspendStatusSuspended {
+			resp.Suspended++
+			suspended = append(suspended, result.OrgID)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if len(suspended) > 0 && h.routerCache.Enabled() {
+		invalidateCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := h.routerCache.InvalidateOrgs(invalidateCtx, suspended)
+		cancel()
+		resp.RouterCacheInvalidated = err == nil
+	}
+
+	h.logger.Warn("orgs bulk suspended",
+		zap.String("bulk_id", resp.BulkID),
+		zap.String("reason", req.Reason),
+		zap.Int("requested", len(refs)),
+		zap.Int("suspended", resp.Suspended),
+		zap.Bool("router_cache_invalidated", resp.RouterCacheInvalidated),
+		zap.String("actor_id", actorID.String()),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// suspend suspends one org and records the reason in its metadata and audit trail.
+func (h *AdminHandler) suspend(r *http.Request, ref string, req BulkSuspendRequest, bulkID, actorID uuid.UUID) BulkSuspendResult {
+	ctx := r.Context()
+	result := BulkSuspendResult{Ref: ref}
+
+	org, err := h.runtime.OrgResolver.Resolve(ctx, ref)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			result.Status = BulkSuspendStatusNotFound
+			return result
+		}
+		h.logger.Error("failed to get organization", zap.Error(err), zap.String("orgId", ref))
+		result.Status = BulkSuspendStatusFailed
+		result.Error = "failed to retrieve organization"
+		return result
+	}
+	result.OrgID = org.ID.String()
+	if org.Status == OrgStatusSuspended {
+		result.Status = BulkSuspendStatusAlreadySuspended
+		return result
+	}
+
+	metadata := make(map[string]any, len(org.Metadata)+1)
+	for k, v := range org.Metadata {
+		metadata[k] = v
+	}
+	metadata[SuspensionMetadataKey] = Suspension{
+		Reason:      req.Reason,
+		Note:        req.Note,
+		SuspendedAt: time.Now().UTC(),
+		SuspendedBy: actorID.String(),
+		BulkID:      bulkID.String(),
+	}
+	// UpdateOrg writes every column, so carry the current values over
+	updated, err := h.runtime.Postgres.UpdateOrg(ctx, postgres.UpdateOrgParams{
+		ID:                    org.ID,
+		Version:               org.Version,
+		Name:                  org.Name,
+		Status:                OrgStatusSuspended,
+		BillingOwnerUserID:    org.BillingOwnerUserID,
+		BudgetPolicyID:        org.BudgetPolicyID,
+		DeclarativeMode:       org.DeclarativeMode,
+		DeclarativeRepoURL:    org.DeclarativeRepoURL,
+		DeclarativeBranch:     org.DeclarativeBranch,
+		DeclarativeLastCommit: org.DeclarativeLastCommit,
+		MFARequiredRoles:      org.MFARequiredRoles,
+		Metadata:              metadata,
+	})
+	if err != nil {
+		result.Status = BulkSuspendStatusFailed
+		if err == postgres.ErrOptimisticLock {
+			result.Error = "organization was modified concurrently"
+		} else {
+			h.logger.Error("failed to suspend organization", zap.Error(err), zap.String("orgId", result.OrgID))
+			result.Error = "failed to suspend organization"
+		}
+		return result
+	}
+
+	event := audit.BuildEvent(updated.ID, actorID, audit.ActorTypeUser, audit.ActionOrgSuspend, audit.TargetTypeOrg, &updated.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"previous_status": org.Status,
+		"new_status":      updated.Status,
+		"reason_code":     req.Reason,
+		"bulk_id":         bulkID.String(),
+	}
+	if req.Note != "" {
+		event.Metadata["note"] = req.Note
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	result.Status = BulkSuspendStatusSuspended
+	return result
+}
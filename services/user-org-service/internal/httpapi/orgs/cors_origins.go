@@ -0,0 +1,74 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CORSMetadataKey is the org metadata key holding browser origin settings.
+const CORSMetadataKey = "cors"
+
+const maxAllowedOrigins = 32
+
+// CORSSettings lists the browser origins allowed to call the API router with
+// the org's keys. The router's CORS_ALLOWED_ORIGINS decides which origins get
+// a preflight at all; an org list narrows that further, so a key leaked into
+// a web page cannot be used from another site. An empty list means no
+// restriction. Entries may use one "*" wildcard, e.g. "https://*.example.com".
+type CORSSettings struct {
+	AllowedOrigins []string `json:"allowedOrigins"`
+}
+
+// normalize validates the origins, strips trailing slashes and de-duplicates them.
+func (c *CORSSettings) normalize() error {
+	if len(c.AllowedOrigins) > maxAllowedOrigins {
+		return fmt.Errorf("at most %d allowedOrigins are supported", maxAllowedOrigins)
+	}
+	origins := make([]string, 0, len(c.AllowedOrigins))
+	seen := make(map[string]bool, len(c.AllowedOrigins))
+	for _, raw := range c.AllowedOrigins {
+		origin := strings.ToLower(strings.TrimRight(strings.TrimSpace(raw), "/"))
+		if !validOriginPattern(origin) {
+			return fmt.Errorf("invalid origin %q: must be scheme://host[:port]", raw)
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	c.AllowedOrigins = origins
+	return nil
+}
+
+// validOriginPattern reports whether origin is an http(s) origin with no
+// path, optionally containing a single "*" in the host or port.
+func validOriginPattern(origin string) bool {
+	if strings.Count(origin, "*") > 1 {
+		return false
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+// CORSOriginsFromMetadata returns the org's allowed browser origins, or nil when unrestricted.
+func CORSOriginsFromMetadata(metadata map[string]any) []string {
+	raw, ok := metadata[CORSMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var settings CORSSettings
+	if err := json.Unmarshal(data, &settings); err != nil || len(settings.AllowedOrigins) == 0 {
+		return nil
+	}
+	return settings.AllowedOrigins
+}
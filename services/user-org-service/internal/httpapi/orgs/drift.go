@@ -0,0 +1,167 @@
+package orgs
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/reconcile"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// DriftResponse is an org's open change set and drift history.
+type DriftResponse struct {
+	DeclarativeMode string                `json:"declarativeMode"`
+	LastRevision    string                `json:"lastRevision,omitempty"`
+	Policy          reconcile.Policy      `json:"policy"`
+	Pending         *reconcile.ChangeSet  `json:"pending,omitempty"`
+	History         []reconcile.ChangeSet `json:"history"`
+}
+
+// DriftDecisionRequest is the optional payload for approving or rejecting a
+// change set. When ChangeSetID is given it must match the pending set, so a
+// decision never lands on a set that replaced the one the caller reviewed.
+type DriftDecisionRequest struct {
+	ChangeSetID string `json:"changeSetId,omitempty"`
+}
+
+// GetDrift handles GET /v1/orgs/{orgId}/drift - The change set awaiting
+// approval (if any) and the last 50 change sets, newest first. Only the
+// billing owner or an admin may view drift; change sets name members.
+func (h *Handler) GetDrift(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	isOwner := org.BillingOwnerUserID != nil && *org.BillingOwnerUserID == getActorID(r)
+	if !isOwner && !h.isAdmin(r) {
+		http.Error(w, "only the billing owner or an admin can view drift", http.StatusForbidden)
+		return
+	}
+	h.writeDrift(w, http.StatusOK, org)
+}
+
+// ApproveDrift handles POST /v1/orgs/{orgId}/drift/pending/approve - Approve
+// the pending change set (admin only). A previously rejected set may be
+// approved. The reconciler applies it on its next pass, provided the document
+// has not changed in the meantime.
+func (h *Handler) ApproveDrift(w http.ResponseWriter, r *http.Request) {
+	h.decideDrift(w, r, reconcile.StatusApproved, audit.ActionOrgDriftApprove)
+}
+
+// RejectDrift handles POST /v1/orgs/{orgId}/drift/pending/reject - Reject the
+// pending change set (admin only). It stays open, unapplied, until the
+// document or the live state changes and a new set supersedes it.
+func (h *Handler) RejectDrift(w http.ResponseWriter, r *http.Request) {
+	h.decideDrift(w, r, reconcile.StatusRejected, audit.ActionOrgDriftReject)
+}
+
+func (h *Handler) decideDrift(w http.ResponseWriter, r *http.Request, status, action string) {
+	ctx := r.Context()
+
+	var req DriftDecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+	if !h.isAdmin(r) {
+		http.Error(w, "only an admin can approve or reject drift", http.StatusForbidden)
+		return
+	}
+
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	pending := reconcile.PendingFromMetadata(org.Metadata)
+	if pending == nil {
+		http.Error(w, "no pending change set", http.StatusNotFound)
+		return
+	}
+	if req.ChangeSetID != "" && req.ChangeSetID != pending.ID {
+		http.Error(w, "pending change set has been superseded", http.StatusConflict)
+		return
+	}
+	switch {
+	case pending.Status == status:
+		h.writeDrift(w, http.StatusOK, org)
+		return
+	case pending.Status == reconcile.StatusApproved:
+		http.Error(w, "change set is already approved", http.StatusConflict)
+		return
+	case status == reconcile.StatusRejected && pending.Status != reconcile.StatusPending:
+		http.Error(w, "only a pending change set can be rejected", http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC()
+	pending.Status = status
+	pending.DecidedBy = getActorID(r).String()
+	pending.DecidedAt = &now
+
+	metadata := make(map[string]any, len(org.Metadata))
+	for k, v := range org.Metadata {
+		metadata[k] = v
+	}
+	metadata[reconcile.PendingMetadataKey] = pending
+	updated, err := h.runtime.Postgres.UpdateOrg(ctx, postgres.UpdateOrgParams{
+		ID:                    org.ID,
+		Version:               org.Version,
+		Name:                  org.Name,
+		Status:                org.Status,
+		BillingOwnerUserID:    org.BillingOwnerUserID,
+		BudgetPolicyID:        org.BudgetPolicyID,
+		DeclarativeMode:       org.DeclarativeMode,
+		DeclarativeRepoURL:    org.DeclarativeRepoURL,
+		DeclarativeBranch:     org.DeclarativeBranch,
+		DeclarativeLastCommit: org.DeclarativeLastCommit,
+		MFARequiredRoles:      org.MFARequiredRoles,
+		Metadata:              metadata,
+	})
+	if err != nil {
+		if err == postgres.ErrOptimisticLock {
+			http.Error(w, "organization was modified concurrently", http.StatusConflict)
+			return
+		}
+		h.logger.Error("failed to record drift decision", zap.Error(err), zap.String("orgId", org.ID.String()))
+		http.Error(w, "failed to update organization", http.StatusInternalServerError)
+		return
+	}
+
+	event := audit.BuildEvent(org.ID, getActorID(r), audit.ActorTypeUser, action, audit.TargetTypeOrg, &org.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"change_set_id": pending.ID,
+		"revision":      pending.Revision,
+		"changes":       len(pending.Changes),
+		"destructive":   pending.Destructive(),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	h.writeDrift(w, http.StatusOK, updated)
+}
+
+func (h *Handler) writeDrift(w http.ResponseWriter, status int, org postgres.Org) {
+	resp := DriftResponse{
+		DeclarativeMode: org.DeclarativeMode,
+		Policy:          reconcile.PolicyFromMetadata(org.Metadata),
+		Pending:         reconcile.PendingFromMetadata(org.Metadata),
+		History:         reconcile.HistoryFromMetadata(org.Metadata),
+	}
+	if org.DeclarativeLastCommit != nil {
+		resp.LastRevision = *org.DeclarativeLastCommit
+	}
+	if resp.History == nil {
+		resp.History = []reconcile.ChangeSet{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
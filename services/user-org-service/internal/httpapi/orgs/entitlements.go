@@ -0,0 +1,313 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// EntitlementsMetadataKey is the org metadata key holding model entitlements.
+const EntitlementsMetadataKey = "model_entitlements"
+
+// EntitlementsAnnotation is the API key annotation holding per-key model entitlements.
+const EntitlementsAnnotation = "model_entitlements"
+
+const (
+	maxEntitlementModels = 256
+	maxBulkAPIKeys       = 500
+)
+
+var modelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/-]{0,127}\*?$`)
+
+// ModelEntitlements restricts which models an org or API key may list and
+// call. Entries are model IDs or prefixes ending in "*" (e.g. "llama-3*").
+// The API router rejects models matching DeniedModels and, when AllowedModels
+// is non-empty, models matching none of them. API key entitlements can only
+// narrow the org's, never widen them.
+type ModelEntitlements struct {
+	AllowedModels []string `json:"allowedModels,omitempty"`
+	DeniedModels  []string `json:"deniedModels,omitempty"`
+}
+
+// IsEmpty reports whether e imposes no restriction.
+func (e *ModelEntitlements) IsEmpty() bool {
+	return e == nil || (len(e.AllowedModels) == 0 && len(e.DeniedModels) == 0)
+}
+
+// normalize trims, validates and de-duplicates both lists.
+func (e *ModelEntitlements) normalize() error {
+	var err error
+	if e.AllowedModels, err = normalizeModelList("allowedModels", e.AllowedModels); err != nil {
+		return err
+	}
+	e.DeniedModels, err = normalizeModelList("deniedModels", e.DeniedModels)
+	return err
+}
+
+func normalizeModelList(field string, raw []string) ([]string, error) {
+	if len(raw) > maxEntitlementModels {
+		return nil, fmt.Errorf("at most %d %s are supported", maxEntitlementModels, field)
+	}
+	models := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, entry := range raw {
+		model := strings.TrimSpace(entry)
+		if !modelPattern.MatchString(model) {
+			return nil, fmt.Errorf("invalid model in %s: %q", field, entry)
+		}
+		if !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// EntitlementsFromMetadata returns the org's model entitlements, or nil when unrestricted.
+func EntitlementsFromMetadata(metadata map[string]any) *ModelEntitlements {
+	return decodeEntitlements(metadata[EntitlementsMetadataKey])
+}
+
+// EntitlementsFromAnnotations returns an API key's model entitlements, or nil when unrestricted.
+func EntitlementsFromAnnotations(annotations map[string]any) *ModelEntitlements {
+	return decodeEntitlements(annotations[EntitlementsAnnotation])
+}
+
+func decodeEntitlements(raw any) *ModelEntitlements {
+	if raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var entitlements ModelEntitlements
+	if err := json.Unmarshal(data, &entitlements); err != nil || entitlements.IsEmpty() {
+		return nil
+	}
+	return &entitlements
+}
+
+// EntitlementsDocument is an org's entitlements together with those of its API keys.
+type EntitlementsDocument struct {
+	Org     ModelEntitlements             `json:"org"`
+	APIKeys map[string]*ModelEntitlements `json:"apiKeys"` // Keyed by API key ID; only restricted keys
+}
+
+// SetEntitlementsRequest updates entitlements in bulk. Org is left unchanged
+// when omitted; each listed key's entitlements are replaced, and null or an
+// empty object clears them.
+type SetEntitlementsRequest struct {
+	Org     *ModelEntitlements            `json:"org,omitempty"`
+	APIKeys map[string]*ModelEntitlements `json:"apiKeys,omitempty"`
+}
+
+// GetEntitlements handles GET /v1/orgs/{orgId}/entitlements.
+func (h *Handler) GetEntitlements(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	keys, err := h.runtime.Postgres.ListAPIKeysForOrg(r.Context(), org.ID)
+	if err != nil {
+		h.logger.Error("failed to list API keys", zap.Error(err), zap.String("orgId", org.ID.String()))
+		http.Error(w, "failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+	h.writeEntitlements(w, org, keys)
+}
+
+// SetEntitlements handles PUT /v1/orgs/{orgId}/entitlements. Every entry is
+// validated before anything is written; the org is updated first, then each
+// key. Changes reach the router once its validation cache expires (about a minute).
+func (h *Handler) SetEntitlements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req SetEntitlementsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.Org == nil && len(req.APIKeys) == 0 {
+		http.Error(w, "org or apiKeys is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.APIKeys) > maxBulkAPIKeys {
+		http.Error(w, fmt.Sprintf("at most %d apiKeys per request are supported", maxBulkAPIKeys), http.StatusBadRequest)
+		return
+	}
+	if req.Org != nil {
+		if err := req.Org.normalize(); err != nil {
+			http.Error(w, "org: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for keyID, entitlements := range req.APIKeys {
+		if _, err := uuid.Parse(keyID); err != nil {
+			http.Error(w, fmt.Sprintf("invalid API key ID %q", keyID), http.StatusBadRequest)
+			return
+		}
+		if entitlements != nil {
+			if err := entitlements.normalize(); err != nil {
+				http.Error(w, fmt.Sprintf("apiKeys[%s]: %v", keyID, err), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	keys, err := h.runtime.Postgres.ListAPIKeysForOrg(ctx, org.ID)
+	if err != nil {
+		h.logger.Error("failed to list API keys", zap.Error(err), zap.String("orgId", org.ID.String()))
+		http.Error(w, "failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+	keysByID := make(map[string]postgres.APIKey, len(keys))
+	for _, key := range keys {
+		keysByID[key.ID.String()] = key
+	}
+	for keyID := range req.APIKeys {
+		key, found := keysByID[keyID]
+		if !found {
+			http.Error(w, fmt.Sprintf("API key %s not found", keyID), http.StatusNotFound)
+			return
+		}
+		if key.Status == "revoked" || key.RevokedAt != nil {
+			http.Error(w, fmt.Sprintf("API key %s is revoked", keyID), http.StatusConflict)
+			return
+		}
+	}
+
+	previousOrg := EntitlementsFromMetadata(org.Metadata)
+	if req.Org != nil {
+		metadata := make(map[string]any, len(org.Metadata)+1)
+		for k, v := range org.Metadata {
+			metadata[k] = v
+		}
+		if req.Org.IsEmpty() {
+			delete(metadata, EntitlementsMetadataKey)
+		} else {
+			metadata[EntitlementsMetadataKey] = req.Org
+		}
+		updatedOrg, err := h.runtime.Postgres.UpdateOrg(ctx, postgres.UpdateOrgParams{
+			ID:                    org.ID,
+			Version:               org.Version,
+			Name:                  org.Name,
+			Status:                org.Status,
+			BillingOwnerUserID:    org.BillingOwnerUserID,
+			BudgetPolicyID:        org.BudgetPolicyID,
+			DeclarativeMode:       org.DeclarativeMode,
+			DeclarativeRepoURL:    org.DeclarativeRepoURL,
+			DeclarativeBranch:     org.DeclarativeBranch,
+			DeclarativeLastCommit: org.DeclarativeLastCommit,
+			MFARequiredRoles:      org.MFARequiredRoles,
+			Metadata:              metadata,
+		})
+		if err != nil {
+			if err == postgres.ErrOptimisticLock {
+				http.Error(w, "organization was modified concurrently", http.StatusConflict)
+				return
+			}
+			h.logger.Error("failed to update org entitlements", zap.Error(err), zap.String("orgId", org.ID.String()))
+			http.Error(w, "failed to update organization", http.StatusInternalServerError)
+			return
+		}
+		org = updatedOrg
+	}
+
+	keyIDs := make([]string, 0, len(req.APIKeys))
+	for keyID := range req.APIKeys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+	for _, keyID := range keyIDs {
+		key := keysByID[keyID]
+		annotations := make(map[string]any, len(key.Annotations)+1)
+		for k, v := range key.Annotations {
+			annotations[k] = v
+		}
+		if entitlements := req.APIKeys[keyID]; entitlements.IsEmpty() {
+			delete(annotations, EntitlementsAnnotation)
+		} else {
+			annotations[EntitlementsAnnotation] = entitlements
+		}
+		updated, err := h.runtime.Postgres.UpdateAPIKeyAnnotations(ctx, org.ID, key.ID, key.Version, annotations)
+		if err != nil {
+			// Earlier entries are already saved; the caller can safely retry the whole request
+			if err == postgres.ErrOptimisticLock {
+				http.Error(w, fmt.Sprintf("API key %s was modified concurrently", keyID), http.StatusConflict)
+				return
+			}
+			h.logger.Error("failed to update API key entitlements", zap.Error(err), zap.String("apiKeyId", keyID))
+			http.Error(w, "failed to update API key entitlements", http.StatusInternalServerError)
+			return
+		}
+		keysByID[keyID] = updated
+	}
+
+	event := audit.BuildEvent(org.ID, getActorID(r), audit.ActorTypeUser, audit.ActionEntitlementSet, audit.TargetTypeOrg, &org.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{"api_key_ids": keyIDs}
+	if req.Org != nil {
+		event.Metadata["previous_model_entitlements"] = previousOrg
+		event.Metadata["model_entitlements"] = req.Org
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	updatedKeys := make([]postgres.APIKey, 0, len(keysByID))
+	for _, key := range keysByID {
+		updatedKeys = append(updatedKeys, key)
+	}
+	h.writeEntitlements(w, org, updatedKeys)
+}
+
+// writeEntitlements writes the entitlements document for org and its active keys.
+func (h *Handler) writeEntitlements(w http.ResponseWriter, org postgres.Org, keys []postgres.APIKey) {
+	doc := EntitlementsDocument{APIKeys: make(map[string]*ModelEntitlements)}
+	if entitlements := EntitlementsFromMetadata(org.Metadata); entitlements != nil {
+		doc.Org = *entitlements
+	}
+	for _, key := range keys {
+		if key.Status == "revoked" || key.RevokedAt != nil {
+			continue
+		}
+		if entitlements := EntitlementsFromAnnotations(key.Annotations); entitlements != nil {
+			doc.APIKeys[key.ID.String()] = entitlements
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// loadOrg resolves {orgId} (UUID or slug), writing an error response and
+// returning false when it cannot.
+func (h *Handler) loadOrg(w http.ResponseWriter, r *http.Request) (postgres.Org, bool) {
+	orgIDParam := chi.URLParam(r, "orgId")
+	org, err := h.runtime.OrgResolver.Resolve(r.Context(), orgIDParam)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			http.Error(w, "organization not found", http.StatusNotFound)
+			return postgres.Org{}, false
+		}
+		h.logger.Error("failed to get organization", zap.Error(err), zap.String("orgId", orgIDParam))
+		http.Error(w, "failed to retrieve organization", http.StatusInternalServerError)
+		return postgres.Org{}, false
+	}
+	return org, true
+}
@@ -0,0 +1,54 @@
+package orgs
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+)
+
+// ExportOrg handles GET /v1/orgs/{orgId}/export - Return the org as a
+// declarative YAML document in the schema the reconciler applies, so an
+// existing org can be adopted into GitOps. Only the billing owner or an admin
+// may export; the document lists every member.
+func (h *Handler) ExportOrg(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	isOwner := org.BillingOwnerUserID != nil && *org.BillingOwnerUserID == getActorID(r)
+	if !isOwner && !h.isAdmin(r) {
+		http.Error(w, "only the billing owner or an admin can export the organization", http.StatusForbidden)
+		return
+	}
+
+	doc, err := declarative.Build(ctx, h.runtime.Postgres, org)
+	if err != nil {
+		h.logger.Error("failed to build organization export", zap.Error(err), zap.String("orgId", org.ID.String()))
+		http.Error(w, "failed to export organization", http.StatusInternalServerError)
+		return
+	}
+	data, err := declarative.Marshal(doc)
+	if err != nil {
+		h.logger.Error("failed to encode organization export", zap.Error(err), zap.String("orgId", org.ID.String()))
+		http.Error(w, "failed to export organization", http.StatusInternalServerError)
+		return
+	}
+
+	event := audit.BuildEvent(org.ID, getActorID(r), audit.ActorTypeUser, audit.ActionOrgExport, audit.TargetTypeOrg, &org.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"users":            len(doc.Spec.Users),
+		"service_accounts": len(doc.Spec.ServiceAccounts),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.yaml"`, org.Slug))
+	_, _ = w.Write(data)
+}
@@ -18,6 +18,11 @@
 //   - GetOrg: GET /v1/orgs/{orgId} - Retrieve organization by ID or slug
 //   - UpdateOrg: PATCH /v1/orgs/{orgId} - Update organization metadata
 //   - ListOrgs: GET /v1/orgs - List organizations (future: pagination)
+//   - StartOwnershipTransfer/ConfirmOwnershipTransfer/CancelOwnershipTransfer:
+//     /v1/orgs/{orgId}/ownership-transfer - Move the billing owner with two-sided confirmation
+//   - CreateProject/ListProjects: /v1/orgs/{orgId}/projects - Teams and projects under an org
+//   - ExportOrg: GET /v1/orgs/{orgId}/export - Declarative YAML (internal/declarative) for GitOps adoption
+//   - GetDrift/ApproveDrift/RejectDrift: /v1/orgs/{orgId}/drift - Reconciler change sets (internal/reconcile)
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#US-001 (User & Organization Management)
@@ -26,9 +31,27 @@
 //
 // Debugging Notes:
 //   - Organization lookups support both UUID and slug (slug preferred for human-readable APIs)
+//   - {orgId} resolution goes through internal/orgresolver (cached, including misses)
 //   - Optimistic locking prevents concurrent update conflicts (returns 409 Conflict)
 //   - Soft deletes are enforced (deleted_at IS NULL)
 //   - Status transitions: pending -> active -> suspended -> active or pending_delete
+//   - Data residency (allowed backend regions) lives in metadata["data_residency"]
+//     and is returned to the API router with API key validation
+//   - Inference archival settings (metadata["inference_archival"]) and tool-calling
+//     limits (metadata["tool_limits"]) are returned to the API router the same way
+//   - Usage record payload handling (metadata["usage_payloads"]: full, truncated,
+//     hash_only or omitted) is returned the same way and applied by the router
+//   - A pending ownership transfer lives in metadata["ownership_transfer"] until both
+//     owners confirm (billing_owner_user_id is then re-pointed) or OWNERSHIP_TRANSFER_TTL passes
+//   - Teams and projects are orgs with metadata["parent_org"]; the link survives
+//     metadata replacement in UpdateOrg, and EffectiveMetadata merges the parent's settings
+//   - POST /v1/admin/orgs:bulk-suspend (admin scope) suspends orgs with a reason code kept in
+//     metadata["suspension"] and the org.suspend audit event; suspended orgs' keys fail
+//     validation, their users cannot log in or refresh, and ROUTER_ADMIN_URLS drop cached keys
+//   - Lifting a suspension through PATCH /v1/orgs/{orgId} also needs the admin scope (403 otherwise)
+//   - Passwordless login is enabled per org with metadata["magic_link"] ({"enabled": true})
+//   - Declarative orgs choose which drift the reconciler applies without approval via
+//     metadata["declarative_policy"] ({"autoApply": "none"|"non_destructive"|"all"})
 //
 // Thread Safety:
 //   - Handler methods are safe for concurrent use (stateless, uses runtime dependencies)
@@ -41,7 +64,9 @@
 package orgs
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -52,6 +77,7 @@ import (
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/reconcile"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
 
@@ -72,6 +98,18 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger
 		// to ensure GET /v1/orgs/{orgId} matches correctly
 		r.Get("/{orgId}", handler.GetOrg)
 		r.Patch("/{orgId}", handler.UpdateOrg)
+		r.Get("/{orgId}/entitlements", handler.GetEntitlements)
+		r.Put("/{orgId}/entitlements", handler.SetEntitlements)
+		r.Get("/{orgId}/ownership-transfer", handler.GetOwnershipTransfer)
+		r.Post("/{orgId}/ownership-transfer", handler.StartOwnershipTransfer)
+		r.Post("/{orgId}/ownership-transfer/confirm", handler.ConfirmOwnershipTransfer)
+		r.Delete("/{orgId}/ownership-transfer", handler.CancelOwnershipTransfer)
+		r.Get("/{orgId}/projects", handler.ListProjects)
+		r.Get("/{orgId}/export", handler.ExportOrg)
+		r.Get("/{orgId}/drift", handler.GetDrift)
+		r.Post("/{orgId}/drift/pending/approve", handler.ApproveDrift)
+		r.Post("/{orgId}/drift/pending/reject", handler.RejectDrift)
+		r.Post("/{orgId}/projects", handler.CreateProject)
 	})
 }
 
@@ -104,6 +142,24 @@ type UpdateOrgRequest struct {
 	BudgetPolicyID *string            `json:"budgetPolicyId,omitempty"`
 	Declarative    *DeclarativeConfig `json:"declarative,omitempty"`
 	Metadata       map[string]any     `json:"metadata,omitempty"`
+	// DataResidency replaces the allowed backend regions; an empty list clears them.
+	DataResidency *DataResidency `json:"dataResidency,omitempty"`
+	// InferenceArchival replaces the prompt/response archival settings; enabled=false turns it off.
+	InferenceArchival *InferenceArchival `json:"inferenceArchival,omitempty"`
+	// ToolLimits replaces the per-request tool-calling limits; zero values clear them.
+	ToolLimits *ToolLimits `json:"toolLimits,omitempty"`
+	// ModelEntitlements replaces the org's model allow/deny lists; empty lists clear them.
+	ModelEntitlements *ModelEntitlements `json:"modelEntitlements,omitempty"`
+	// RateLimits replaces the rate limit algorithm and concurrency caps; empty values clear them.
+	RateLimits *RateLimits `json:"rateLimits,omitempty"`
+	// UsagePayloads replaces how prompts and responses appear in usage records; an empty mode clears it.
+	UsagePayloads *UsagePayloads `json:"usagePayloads,omitempty"`
+	// CORS replaces the browser origins allowed to use the org's keys; an empty list clears them.
+	CORS *CORSSettings `json:"cors,omitempty"`
+	// LogExport replaces the request/audit log export settings; enabled=false turns it off.
+	LogExport *LogExport `json:"logExport,omitempty"`
+	// MagicLink turns passwordless (emailed link) login on or off for the org's users.
+	MagicLink *MagicLink `json:"magicLink,omitempty"`
 }
 
 // OrganizationResponse represents an organization in API responses.
@@ -115,6 +171,28 @@ type OrganizationResponse struct {
 	Metadata  map[string]any `json:"metadata,omitempty"`
 	CreatedAt string         `json:"createdAt"`
 	UpdatedAt string         `json:"updatedAt"`
+	// DataResidency is set when the org restricts backend regions.
+	DataResidency *DataResidency `json:"dataResidency,omitempty"`
+	// InferenceArchival is set when prompt/response archival is enabled.
+	InferenceArchival *InferenceArchival `json:"inferenceArchival,omitempty"`
+	// ToolLimits is set when the org overrides the router's tool-calling limits.
+	ToolLimits *ToolLimits `json:"toolLimits,omitempty"`
+	// ModelEntitlements is set when the org is restricted to specific models.
+	ModelEntitlements *ModelEntitlements `json:"modelEntitlements,omitempty"`
+	// RateLimits is set when the org overrides the router's rate limit algorithm or concurrency caps.
+	RateLimits *RateLimits `json:"rateLimits,omitempty"`
+	// UsagePayloads is set when the org chooses how prompts and responses appear in usage records.
+	UsagePayloads *UsagePayloads `json:"usagePayloads,omitempty"`
+	// CORS is set when the org restricts which browser origins may use its keys.
+	CORS *CORSSettings `json:"cors,omitempty"`
+	// LogExport is set when the org streams its logs to its own destination; secrets are masked.
+	LogExport *LogExport `json:"logExport,omitempty"`
+	// MagicLink is set when the org's users may sign in with an emailed link.
+	MagicLink *MagicLink `json:"magicLink,omitempty"`
+	// Parent is set when the org is a team or project under another org.
+	Parent *OrgParent `json:"parent,omitempty"`
+	// Suspension is set while the org is suspended with a recorded reason.
+	Suspension *Suspension `json:"suspension,omitempty"`
 }
 
 // CreateOrg handles POST /v1/orgs - Create a new organization.
@@ -150,6 +228,11 @@ func (h *Handler) CreateOrg(w http.ResponseWriter, r *http.Request) {
 	}
 	// ErrNotFound is expected, continue
 
+	// Sub-orgs are created through POST /v1/orgs/{orgId}/projects
+	delete(req.Metadata, ParentMetadataKey)
+	delete(req.Metadata, reconcile.PendingMetadataKey)
+	delete(req.Metadata, reconcile.HistoryMetadataKey)
+
 	// TODO: Lookup billing owner user by email if provided
 	var billingOwnerID *uuid.UUID
 
@@ -184,6 +267,9 @@ func (h *Handler) CreateOrg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Drop any cached "not found" for the new slug
+	h.runtime.OrgResolver.Forget(ctx, org.Slug)
+
 	// Emit audit event
 	actorID := getActorID(r) // TODO: Extract from authenticated session
 	event := audit.BuildEvent(org.ID, actorID, audit.ActorTypeSystem, audit.ActionOrgCreate, audit.TargetTypeOrg, &org.ID)
@@ -204,27 +290,8 @@ func (h *Handler) CreateOrg(w http.ResponseWriter, r *http.Request) {
 
 // GetOrg handles GET /v1/orgs/{orgId} - Retrieve organization by ID or slug.
 func (h *Handler) GetOrg(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	orgIDParam := chi.URLParam(r, "orgId")
-
-	var org postgres.Org
-	var err error
-
-	// Try parsing as UUID first, then fall back to slug lookup
-	if orgID, err := uuid.Parse(orgIDParam); err == nil {
-		org, err = h.runtime.Postgres.GetOrg(ctx, orgID)
-	} else {
-		// Treat as slug
-		org, err = h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-	}
-
-	if err != nil {
-		if err == postgres.ErrNotFound {
-			http.Error(w, "organization not found", http.StatusNotFound)
-			return
-		}
-		h.logger.Error("failed to get organization", zap.Error(err), zap.String("orgId", orgIDParam))
-		http.Error(w, "failed to retrieve organization", http.StatusInternalServerError)
+	org, ok := h.loadOrg(w, r)
+	if !ok {
 		return
 	}
 
@@ -239,24 +306,10 @@ func (h *Handler) GetOrg(w http.ResponseWriter, r *http.Request) {
 // Uses optimistic locking to prevent concurrent update conflicts.
 func (h *Handler) UpdateOrg(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	orgIDParam := chi.URLParam(r, "orgId")
 
 	// Get existing org to obtain current version
-	var existingOrg postgres.Org
-	var err error
-	if orgID, err := uuid.Parse(orgIDParam); err == nil {
-		existingOrg, err = h.runtime.Postgres.GetOrg(ctx, orgID)
-	} else {
-		existingOrg, err = h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-	}
-
-	if err != nil {
-		if err == postgres.ErrNotFound {
-			http.Error(w, "organization not found", http.StatusNotFound)
-			return
-		}
-		h.logger.Error("failed to get organization for update", zap.Error(err), zap.String("orgId", orgIDParam))
-		http.Error(w, "failed to retrieve organization", http.StatusInternalServerError)
+	existingOrg, ok := h.loadOrg(w, r)
+	if !ok {
 		return
 	}
 
@@ -266,78 +319,145 @@ func (h *Handler) UpdateOrg(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid request payload", http.StatusBadRequest)
 		return
 	}
-
-	// Build update params (only include fields that are provided)
-	params := postgres.UpdateOrgParams{
-		ID:      existingOrg.ID,
-		Version: existingOrg.Version,
-		Name:    existingOrg.Name, // Default to existing
-		Status:  existingOrg.Status,
+	if req.DataResidency != nil {
+		if err := req.DataResidency.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
-
-	if req.DisplayName != nil {
-		params.Name = *req.DisplayName
+	if req.InferenceArchival != nil {
+		if err := req.InferenceArchival.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
+	if req.ToolLimits != nil {
+		if err := req.ToolLimits.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.ModelEntitlements != nil {
+		if err := req.ModelEntitlements.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.RateLimits != nil {
+		if err := req.RateLimits.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.UsagePayloads != nil {
+		if err := req.UsagePayloads.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.CORS != nil {
+		if err := req.CORS.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.LogExport != nil {
+		if err := req.LogExport.normalize(existingOrg.ID.String(), LogExportFromMetadata(existingOrg.Metadata)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	if req.Status != nil {
-		// Validate status
 		validStatuses := map[string]bool{"active": true, "suspended": true}
 		if !validStatuses[*req.Status] {
 			http.Error(w, "invalid status", http.StatusBadRequest)
 			return
 		}
-		params.Status = *req.Status
 	}
-	if req.BudgetPolicyID != nil {
-		if policyID, err := uuid.Parse(*req.BudgetPolicyID); err == nil {
-			params.BudgetPolicyID = &policyID
-		}
+	// Suspensions are set by operators, so only they may lift one
+	if req.liftsSuspension(existingOrg) && !h.isAdmin(r) {
+		http.Error(w, errReactivationForbidden.Error(), http.StatusForbidden)
+		return
 	}
 
-	// Handle declarative config updates
-	if req.Declarative != nil {
-		if req.Declarative.Enabled {
-			params.DeclarativeMode = "enabled"
-			if req.Declarative.RepoURL != "" {
-				params.DeclarativeRepoURL = &req.Declarative.RepoURL
+	// A concurrent update is re-read and the request applied on top of it
+	var org postgres.Org
+	err := h.runtime.Postgres.RetryOnConflict(ctx, "org.update", func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			var err error
+			if existingOrg, err = h.runtime.Postgres.GetOrg(ctx, existingOrg.ID); err != nil {
+				return err
 			}
-			if req.Declarative.Branch != "" {
-				params.DeclarativeBranch = &req.Declarative.Branch
+			if req.liftsSuspension(existingOrg) && !h.isAdmin(r) {
+				return errReactivationForbidden
 			}
-		} else {
-			params.DeclarativeMode = "disabled"
 		}
-	}
-
-	// Merge metadata if provided
-	if req.Metadata != nil {
-		params.Metadata = req.Metadata
-	} else {
-		params.Metadata = existingOrg.Metadata
-	}
-
-	org, err := h.runtime.Postgres.UpdateOrg(ctx, params)
+		var err error
+		org, err = h.runtime.Postgres.UpdateOrg(ctx, req.updateParams(existingOrg))
+		return err
+	})
 	if err != nil {
-		if err == postgres.ErrOptimisticLock {
+		if errors.Is(err, postgres.ErrOptimisticLock) {
 			http.Error(w, "organization was modified concurrently", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, errReactivationForbidden) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		h.logger.Error("failed to update organization", zap.Error(err), zap.String("orgId", existingOrg.ID.String()))
 		http.Error(w, "failed to update organization", http.StatusInternalServerError)
 		return
 	}
 
 	// Emit audit event
-	actorID := getActorID(r) // TODO: Extract from authenticated session
 	action := audit.ActionOrgUpdate
 	if req.Status != nil && *req.Status == "suspended" {
 		action = audit.ActionOrgSuspend
 	}
-	event := audit.BuildEvent(org.ID, actorID, audit.ActorTypeSystem, action, audit.TargetTypeOrg, &org.ID)
+	event := audit.BuildEvent(org.ID, getActorID(r), audit.ActorTypeUser, action, audit.TargetTypeOrg, &org.ID)
 	event = audit.BuildEventFromRequest(event, r)
 	event.Metadata = map[string]any{
 		"previous_status": existingOrg.Status,
 		"new_status":      org.Status,
 	}
+	if req.DataResidency != nil {
+		event.Metadata["previous_allowed_regions"] = AllowedRegionsFromMetadata(existingOrg.Metadata)
+		event.Metadata["allowed_regions"] = req.DataResidency.AllowedRegions
+	}
+	if req.InferenceArchival != nil {
+		event.Metadata["previous_inference_archival"] = ArchivalFromMetadata(existingOrg.Metadata)
+		event.Metadata["inference_archival"] = req.InferenceArchival
+	}
+	if req.ToolLimits != nil {
+		event.Metadata["previous_tool_limits"] = ToolLimitsFromMetadata(existingOrg.Metadata)
+		event.Metadata["tool_limits"] = req.ToolLimits
+	}
+	if req.ModelEntitlements != nil {
+		event.Metadata["previous_model_entitlements"] = EntitlementsFromMetadata(existingOrg.Metadata)
+		event.Metadata["model_entitlements"] = req.ModelEntitlements
+	}
+	if req.RateLimits != nil {
+		event.Metadata["previous_rate_limits"] = RateLimitsFromMetadata(existingOrg.Metadata)
+		event.Metadata["rate_limits"] = req.RateLimits
+	}
+	if req.UsagePayloads != nil {
+		event.Metadata["previous_usage_payloads"] = UsagePayloadsFromMetadata(existingOrg.Metadata)
+		event.Metadata["usage_payloads"] = req.UsagePayloads
+	}
+	if req.CORS != nil {
+		event.Metadata["previous_cors_origins"] = CORSOriginsFromMetadata(existingOrg.Metadata)
+		event.Metadata["cors_origins"] = req.CORS.AllowedOrigins
+	}
+	if req.LogExport != nil {
+		event.Metadata["previous_log_export"] = LogExportFromMetadata(existingOrg.Metadata).Masked()
+		event.Metadata["log_export"] = req.LogExport.Masked()
+	}
+	if req.MagicLink != nil {
+		event.Metadata["previous_magic_link"] = MagicLinkFromMetadata(existingOrg.Metadata) != nil
+		event.Metadata["magic_link"] = req.MagicLink.Enabled
+	}
 	_ = h.runtime.Audit.Emit(ctx, event)
 
 	resp := toOrgResponse(org)
@@ -347,6 +467,152 @@ func (h *Handler) UpdateOrg(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// errReactivationForbidden rejects lifting a suspension without the admin scope.
+var errReactivationForbidden = errors.New("only an admin can reactivate a suspended organization")
+
+// liftsSuspension reports whether req moves a suspended org to another status.
+func (req UpdateOrgRequest) liftsSuspension(existing postgres.Org) bool {
+	return req.Status != nil && *req.Status != OrgStatusSuspended && existing.Status == OrgStatusSuspended
+}
+
+// updateParams applies req to existing, keeping the fields it does not set.
+func (req UpdateOrgRequest) updateParams(existing postgres.Org) postgres.UpdateOrgParams {
+	// Build update params (only include fields that are provided)
+	params := postgres.UpdateOrgParams{
+		ID:      existing.ID,
+		Version: existing.Version,
+		Name:    existing.Name, // Default to existing
+		Status:  existing.Status,
+	}
+
+	if req.DisplayName != nil {
+		params.Name = *req.DisplayName
+	}
+	if req.Status != nil {
+		params.Status = *req.Status
+	}
+	if req.BudgetPolicyID != nil {
+		if policyID, err := uuid.Parse(*req.BudgetPolicyID); err == nil {
+			params.BudgetPolicyID = &policyID
+		}
+	}
+
+	// Handle declarative config updates
+	if req.Declarative != nil {
+		if req.Declarative.Enabled {
+			params.DeclarativeMode = "enabled"
+			if req.Declarative.RepoURL != "" {
+				params.DeclarativeRepoURL = &req.Declarative.RepoURL
+			}
+			if req.Declarative.Branch != "" {
+				params.DeclarativeBranch = &req.Declarative.Branch
+			}
+		} else {
+			params.DeclarativeMode = "disabled"
+		}
+	}
+
+	// Merge metadata if provided
+	if req.Metadata != nil {
+		params.Metadata = req.Metadata
+		// A project stays under its parent, the reconciler's change sets are
+		// only changed through the drift endpoints, log export holds a
+		// write-only secret and the suspension reason is set by operators;
+		// none is client-editable
+		for _, key := range []string{ParentMetadataKey, reconcile.PendingMetadataKey, reconcile.HistoryMetadataKey, LogExportMetadataKey, SuspensionMetadataKey} {
+			delete(params.Metadata, key)
+			if value, ok := existing.Metadata[key]; ok {
+				params.Metadata[key] = value
+			}
+		}
+	} else {
+		params.Metadata = existing.Metadata
+	}
+	if req.DataResidency != nil || req.InferenceArchival != nil || req.ToolLimits != nil || req.ModelEntitlements != nil || req.RateLimits != nil || req.UsagePayloads != nil || req.CORS != nil || req.LogExport != nil || req.MagicLink != nil {
+		metadata := make(map[string]any, len(params.Metadata)+8)
+		for k, v := range params.Metadata {
+			metadata[k] = v
+		}
+		if req.DataResidency != nil {
+			if len(req.DataResidency.AllowedRegions) == 0 {
+				delete(metadata, ResidencyMetadataKey)
+			} else {
+				metadata[ResidencyMetadataKey] = req.DataResidency
+			}
+		}
+		if req.InferenceArchival != nil {
+			if !req.InferenceArchival.Enabled {
+				delete(metadata, ArchivalMetadataKey)
+			} else {
+				metadata[ArchivalMetadataKey] = req.InferenceArchival
+			}
+		}
+		if req.ToolLimits != nil {
+			if req.ToolLimits.IsEmpty() {
+				delete(metadata, ToolLimitsMetadataKey)
+			} else {
+				metadata[ToolLimitsMetadataKey] = req.ToolLimits
+			}
+		}
+		if req.ModelEntitlements != nil {
+			if req.ModelEntitlements.IsEmpty() {
+				delete(metadata, EntitlementsMetadataKey)
+			} else {
+				metadata[EntitlementsMetadataKey] = req.ModelEntitlements
+			}
+		}
+		if req.RateLimits != nil {
+			if req.RateLimits.IsEmpty() {
+				delete(metadata, RateLimitsMetadataKey)
+			} else {
+				metadata[RateLimitsMetadataKey] = req.RateLimits
+			}
+		}
+		if req.UsagePayloads != nil {
+			if req.UsagePayloads.IsEmpty() {
+				delete(metadata, UsagePayloadsMetadataKey)
+			} else {
+				metadata[UsagePayloadsMetadataKey] = req.UsagePayloads
+			}
+		}
+		if req.CORS != nil {
+			if len(req.CORS.AllowedOrigins) == 0 {
+				delete(metadata, CORSMetadataKey)
+			} else {
+				metadata[CORSMetadataKey] = req.CORS
+			}
+		}
+		if req.LogExport != nil {
+			if !req.LogExport.Enabled {
+				delete(metadata, LogExportMetadataKey)
+			} else {
+				metadata[LogExportMetadataKey] = req.LogExport
+			}
+		}
+		if req.MagicLink != nil {
+			if !req.MagicLink.Enabled {
+				delete(metadata, MagicLinkMetadataKey)
+			} else {
+				metadata[MagicLinkMetadataKey] = req.MagicLink
+			}
+		}
+		params.Metadata = metadata
+	}
+	// The suspension reason only describes a suspended org
+	if params.Status != OrgStatusSuspended {
+		if _, ok := params.Metadata[SuspensionMetadataKey]; ok {
+			metadata := make(map[string]any, len(params.Metadata))
+			for k, v := range params.Metadata {
+				if k != SuspensionMetadataKey {
+					metadata[k] = v
+				}
+			}
+			params.Metadata = metadata
+		}
+	}
+	return params
+}
+
 // ListOrgs handles GET /v1/orgs - List organizations.
 // TODO: Add pagination, filtering, and authorization checks.
 func (h *Handler) ListOrgs(w http.ResponseWriter, r *http.Request) {
@@ -357,15 +623,31 @@ func (h *Handler) ListOrgs(w http.ResponseWriter, r *http.Request) {
 
 // toOrgResponse converts a postgres.Org to an OrganizationResponse.
 func toOrgResponse(org postgres.Org) OrganizationResponse {
-	return OrganizationResponse{
+	resp := OrganizationResponse{
 		OrgID:     org.ID.String(),
 		Name:      org.Name,
 		Slug:      org.Slug,
 		Status:    org.Status,
-		Metadata:  org.Metadata,
+		Metadata:  maskMetadata(org.Metadata),
 		CreatedAt: org.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: org.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+	if regions := AllowedRegionsFromMetadata(org.Metadata); regions != nil {
+		resp.DataResidency = &DataResidency{AllowedRegions: regions}
+	}
+	resp.InferenceArchival = ArchivalFromMetadata(org.Metadata)
+	resp.ToolLimits = ToolLimitsFromMetadata(org.Metadata)
+	resp.ModelEntitlements = EntitlementsFromMetadata(org.Metadata)
+	resp.RateLimits = RateLimitsFromMetadata(org.Metadata)
+	resp.UsagePayloads = UsagePayloadsFromMetadata(org.Metadata)
+	if origins := CORSOriginsFromMetadata(org.Metadata); origins != nil {
+		resp.CORS = &CORSSettings{AllowedOrigins: origins}
+	}
+	resp.LogExport = LogExportFromMetadata(org.Metadata).Masked()
+	resp.MagicLink = MagicLinkFromMetadata(org.Metadata)
+	resp.Parent = ParentFromMetadata(org.Metadata)
+	resp.Suspension = SuspensionFromMetadata(org.Metadata)
+	return resp
 }
 
 // getActorID extracts the actor ID from the request context.
@@ -0,0 +1,216 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// LogExportMetadataKey is the org metadata key holding log export settings.
+const LogExportMetadataKey = "log_export"
+
+// Log streams an org can export.
+const (
+	LogStreamRequest = "request"
+	LogStreamAudit   = "audit"
+)
+
+// Log export destination types.
+const (
+	LogDestinationS3    = "s3"
+	LogDestinationHTTPS = "https"
+)
+
+const (
+	defaultLogExportRegion  = "us-east-1"
+	minLogSigningSecretSize = 16
+	maskedLogSigningSecret  = "********"
+)
+
+// roleARNPattern matches an IAM role ARN in any AWS partition.
+var roleARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]{1,512}$`)
+
+// bucketPattern matches S3 bucket names.
+var bucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// redactableLogFields are the exported log fields an org may ask the router
+// to redact before entries leave the platform.
+var redactableLogFields = map[string]bool{
+	"api_key_id":  true,
+	"client_ip":   true,
+	"path":        true,
+	"query":       true,
+	"remote_addr": true,
+	"user_agent":  true,
+}
+
+// LogExport streams the org's request and audit logs to a destination the
+// customer owns. The API router batches entries as newline-delimited JSON,
+// drops entries outside Streams or below MinStatus, and replaces each field in
+// RedactFields before sending.
+type LogExport struct {
+	Enabled     bool                  `json:"enabled"`
+	Destination *LogExportDestination `json:"destination,omitempty"`
+	// Streams selects request and/or audit logs; empty exports both.
+	Streams []string `json:"streams,omitempty"`
+	// MinStatus exports only request logs with at least this HTTP status, e.g. 400 for errors.
+	MinStatus    int      `json:"minStatus,omitempty"`
+	RedactFields []string `json:"redactFields,omitempty"`
+}
+
+// LogExportDestination is an S3 bucket written with an assumed role, or an
+// HTTPS endpoint receiving signed POSTs.
+type LogExportDestination struct {
+	Type string `json:"type"`
+
+	// S3: the router assumes RoleARN with ExternalID (always the org ID, so
+	// the role's trust policy can pin it) and writes under Prefix.
+	Bucket     string `json:"bucket,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Prefix     string `json:"prefix,omitempty"`
+	RoleARN    string `json:"roleArn,omitempty"`
+	ExternalID string `json:"externalId,omitempty"`
+
+	// HTTPS: each batch is signed with HMAC-SHA256 over the body using
+	// SigningSecret. The secret is write-only and masked in responses.
+	URL           string `json:"url,omitempty"`
+	SigningSecret string `json:"signingSecret,omitempty"`
+}
+
+// normalize validates the settings for orgID. An omitted signing secret is
+// kept from previous when the HTTPS endpoint is unchanged.
+func (e *LogExport) normalize(orgID string, previous *LogExport) error {
+	if !e.Enabled {
+		*e = LogExport{}
+		return nil
+	}
+	if e.Destination == nil {
+		return fmt.Errorf("destination is required")
+	}
+
+	streams := make([]string, 0, len(e.Streams))
+	seen := make(map[string]bool, len(e.Streams))
+	for _, raw := range e.Streams {
+		stream := strings.ToLower(strings.TrimSpace(raw))
+		if stream != LogStreamRequest && stream != LogStreamAudit {
+			return fmt.Errorf("invalid stream %q: must be request or audit", raw)
+		}
+		if !seen[stream] {
+			seen[stream] = true
+			streams = append(streams, stream)
+		}
+	}
+	e.Streams = streams
+
+	if e.MinStatus != 0 && (e.MinStatus < 100 || e.MinStatus > 599) {
+		return fmt.Errorf("minStatus must be between 100 and 599")
+	}
+
+	fields := make([]string, 0, len(e.RedactFields))
+	seen = make(map[string]bool, len(e.RedactFields))
+	for _, raw := range e.RedactFields {
+		field := strings.ToLower(strings.TrimSpace(raw))
+		if !redactableLogFields[field] {
+			return fmt.Errorf("invalid redactFields entry %q", raw)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	e.RedactFields = fields
+
+	var previousDest *LogExportDestination
+	if previous != nil {
+		previousDest = previous.Destination
+	}
+	return e.Destination.normalize(orgID, previousDest)
+}
+
+func (d *LogExportDestination) normalize(orgID string, previous *LogExportDestination) error {
+	d.Type = strings.ToLower(strings.TrimSpace(d.Type))
+	switch d.Type {
+	case LogDestinationS3:
+		d.Bucket = strings.TrimSpace(d.Bucket)
+		if !bucketPattern.MatchString(d.Bucket) {
+			return fmt.Errorf("invalid bucket %q", d.Bucket)
+		}
+		d.Region = strings.TrimSpace(d.Region)
+		if d.Region == "" {
+			d.Region = defaultLogExportRegion
+		}
+		d.Prefix = strings.Trim(strings.TrimSpace(d.Prefix), "/")
+		if !roleARNPattern.MatchString(d.RoleARN) {
+			return fmt.Errorf("roleArn must be an IAM role ARN")
+		}
+		d.ExternalID = orgID
+		d.URL, d.SigningSecret = "", ""
+	case LogDestinationHTTPS:
+		u, err := url.Parse(strings.TrimSpace(d.URL))
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+			return fmt.Errorf("url must be an https URL")
+		}
+		d.URL = u.String()
+		if (d.SigningSecret == "" || d.SigningSecret == maskedLogSigningSecret) &&
+			previous != nil && previous.Type == LogDestinationHTTPS && previous.URL == d.URL {
+			d.SigningSecret = previous.SigningSecret
+		}
+		if len(d.SigningSecret) < minLogSigningSecretSize {
+			return fmt.Errorf("signingSecret must be at least %d characters", minLogSigningSecretSize)
+		}
+		d.Bucket, d.Region, d.Prefix, d.RoleARN, d.ExternalID = "", "", "", "", ""
+	default:
+		return fmt.Errorf("destination type must be s3 or https")
+	}
+	return nil
+}
+
+// Masked returns a copy safe to show to users and write to audit events.
+func (e *LogExport) Masked() *LogExport {
+	if e == nil {
+		return nil
+	}
+	masked := *e
+	if e.Destination != nil {
+		dest := *e.Destination
+		if dest.SigningSecret != "" {
+			dest.SigningSecret = maskedLogSigningSecret
+		}
+		masked.Destination = &dest
+	}
+	return &masked
+}
+
+// LogExportFromMetadata returns the org's log export settings, or nil when export is off.
+func LogExportFromMetadata(metadata map[string]any) *LogExport {
+	raw, ok := metadata[LogExportMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var export LogExport
+	if err := json.Unmarshal(data, &export); err != nil || !export.Enabled || export.Destination == nil {
+		return nil
+	}
+	return &export
+}
+
+// maskMetadata returns metadata with secrets in the log export settings masked.
+func maskMetadata(metadata map[string]any) map[string]any {
+	export := LogExportFromMetadata(metadata)
+	if export == nil {
+		return metadata
+	}
+	out := make(map[string]any, len(metadata))
+	for k, v := range metadata {
+		out[k] = v
+	}
+	out[LogExportMetadataKey] = export.Masked()
+	return out
+}
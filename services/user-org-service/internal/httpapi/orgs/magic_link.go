@@ -0,0 +1,33 @@
+package orgs
+
+import (
+	"encoding/json"
+)
+
+// MagicLinkMetadataKey is the org metadata key holding passwordless login settings.
+const MagicLinkMetadataKey = "magic_link"
+
+// MagicLink lets the org's users sign in with a one-time link emailed to
+// them (POST /v1/auth/magic-link) instead of a password.
+type MagicLink struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MagicLinkFromMetadata returns the org's magic link settings, or nil when
+// magic link login is off.
+func MagicLinkFromMetadata(metadata map[string]any) *MagicLink {
+	raw, ok := metadata[MagicLinkMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var settings MagicLink
+	if err := json.Unmarshal(data, &settings); err != nil || !settings.Enabled {
+		return nil
+	}
+	return &settings
+}
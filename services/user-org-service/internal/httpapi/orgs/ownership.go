@@ -0,0 +1,314 @@
+package orgs
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// OwnershipTransferMetadataKey is the org metadata key holding a pending billing ownership transfer.
+const OwnershipTransferMetadataKey = "ownership_transfer"
+
+// OwnershipTransfer moves billing_owner_user_id from one user to another.
+// It completes only once both the current and the new owner have confirmed.
+type OwnershipTransfer struct {
+	TransferID      string     `json:"transferId"`
+	FromUserID      string     `json:"fromUserId,omitempty"` // Empty when the org has no billing owner
+	ToUserID        string     `json:"toUserId"`
+	RequestedBy     string     `json:"requestedBy"`
+	RequestedAt     time.Time  `json:"requestedAt"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	FromConfirmedAt *time.Time `json:"fromConfirmedAt,omitempty"`
+	ToConfirmedAt   *time.Time `json:"toConfirmedAt,omitempty"`
+}
+
+// complete reports whether both sides have confirmed.
+func (t *OwnershipTransfer) complete() bool {
+	return (t.FromUserID == "" || t.FromConfirmedAt != nil) && t.ToConfirmedAt != nil
+}
+
+// OwnershipTransferFromMetadata returns the org's pending transfer, or nil when there is none.
+func OwnershipTransferFromMetadata(metadata map[string]any) *OwnershipTransfer {
+	raw, ok := metadata[OwnershipTransferMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var transfer OwnershipTransfer
+	if err := json.Unmarshal(data, &transfer); err != nil || transfer.ToUserID == "" {
+		return nil
+	}
+	return &transfer
+}
+
+// StartOwnershipTransferRequest is the payload for POST /v1/orgs/{orgId}/ownership-transfer.
+type StartOwnershipTransferRequest struct {
+	NewOwnerUserID string `json:"newOwnerUserId"`
+}
+
+// OwnershipTransferResponse reports a transfer and, once complete, the new owner.
+type OwnershipTransferResponse struct {
+	Status   string            `json:"status"` // "pending", "completed" or "cancelled"
+	Transfer OwnershipTransfer `json:"transfer"`
+}
+
+// StartOwnershipTransfer handles POST /v1/orgs/{orgId}/ownership-transfer.
+// The current billing owner or an admin may start a transfer to an active
+// user of the org. A transfer started by the current owner counts as their
+// confirmation.
+func (h *Handler) StartOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req StartOwnershipTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	newOwnerID, err := uuid.Parse(req.NewOwnerUserID)
+	if err != nil {
+		http.Error(w, "newOwnerUserId must be a user ID", http.StatusBadRequest)
+		return
+	}
+
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	actorID := getActorID(r)
+	currentOwner := org.BillingOwnerUserID
+	isOwner := currentOwner != nil && *currentOwner == actorID
+	if !isOwner && !h.isAdmin(r) {
+		http.Error(w, "only the billing owner or an admin can transfer ownership", http.StatusForbidden)
+		return
+	}
+	if currentOwner != nil && *currentOwner == newOwnerID {
+		http.Error(w, "user is already the billing owner", http.StatusConflict)
+		return
+	}
+	now := time.Now().UTC()
+	if pending := OwnershipTransferFromMetadata(org.Metadata); pending != nil && now.Before(pending.ExpiresAt) {
+		http.Error(w, "an ownership transfer is already pending; cancel it first", http.StatusConflict)
+		return
+	}
+
+	newOwner, err := h.runtime.Postgres.GetUserByID(ctx, org.ID, newOwnerID)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			http.Error(w, "new owner is not a member of this organization", http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("failed to get new owner", zap.Error(err), zap.String("userId", newOwnerID.String()))
+		http.Error(w, "failed to retrieve user", http.StatusInternalServerError)
+		return
+	}
+	if newOwner.Status != "active" {
+		http.Error(w, "new owner must be an active user", http.StatusBadRequest)
+		return
+	}
+	if _, deleting := newOwner.Metadata["account_deletion"]; deleting {
+		http.Error(w, "new owner has scheduled their account for deletion", http.StatusBadRequest)
+		return
+	}
+
+	transfer := OwnershipTransfer{
+		TransferID:  uuid.NewString(),
+		ToUserID:    newOwnerID.String(),
+		RequestedBy: actorID.String(),
+		RequestedAt: now,
+		ExpiresAt:   now.Add(h.runtime.Config.OwnershipTransferTTL),
+	}
+	if currentOwner != nil {
+		transfer.FromUserID = currentOwner.String()
+		if isOwner {
+			transfer.FromConfirmedAt = &now
+		}
+	}
+
+	if _, err := h.saveOwnership(r, org, org.BillingOwnerUserID, &transfer); err != nil {
+		h.writeSaveError(w, org, err)
+		return
+	}
+	h.emitTransfer(r, org.ID, audit.ActionOrgTransferRequest, &transfer)
+	writeTransfer(w, h.logger, http.StatusAccepted, "pending", transfer)
+}
+
+// GetOwnershipTransfer handles GET /v1/orgs/{orgId}/ownership-transfer.
+func (h *Handler) GetOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	transfer := OwnershipTransferFromMetadata(org.Metadata)
+	if transfer == nil || !time.Now().Before(transfer.ExpiresAt) {
+		http.Error(w, "no ownership transfer is pending", http.StatusNotFound)
+		return
+	}
+	writeTransfer(w, h.logger, http.StatusOK, "pending", *transfer)
+}
+
+// ConfirmOwnershipTransfer handles POST /v1/orgs/{orgId}/ownership-transfer/confirm.
+// The caller confirms as whichever side they are; when both sides have
+// confirmed, billing_owner_user_id is re-pointed to the new owner.
+func (h *Handler) ConfirmOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	transfer := OwnershipTransferFromMetadata(org.Metadata)
+	if transfer == nil {
+		http.Error(w, "no ownership transfer is pending", http.StatusNotFound)
+		return
+	}
+	now := time.Now().UTC()
+	if !now.Before(transfer.ExpiresAt) {
+		http.Error(w, "ownership transfer has expired", http.StatusGone)
+		return
+	}
+	if !transferStillValid(org, transfer) {
+		http.Error(w, "billing owner changed since the transfer was requested; start a new transfer", http.StatusConflict)
+		return
+	}
+
+	actor := getActorID(r).String()
+	switch actor {
+	case transfer.FromUserID:
+		transfer.FromConfirmedAt = &now
+	case transfer.ToUserID:
+		transfer.ToConfirmedAt = &now
+	default:
+		http.Error(w, "only the current or new billing owner can confirm the transfer", http.StatusForbidden)
+		return
+	}
+
+	if !transfer.complete() {
+		if _, err := h.saveOwnership(r, org, org.BillingOwnerUserID, transfer); err != nil {
+			h.writeSaveError(w, org, err)
+			return
+		}
+		h.emitTransfer(r, org.ID, audit.ActionOrgTransferConfirm, transfer)
+		writeTransfer(w, h.logger, http.StatusOK, "pending", *transfer)
+		return
+	}
+
+	newOwner, err := uuid.Parse(transfer.ToUserID)
+	if err != nil {
+		http.Error(w, "ownership transfer is malformed; cancel it and start a new one", http.StatusConflict)
+		return
+	}
+	if _, err := h.saveOwnership(r, org, &newOwner, nil); err != nil {
+		h.writeSaveError(w, org, err)
+		return
+	}
+	h.emitTransfer(r, org.ID, audit.ActionOrgTransferComplete, transfer)
+	writeTransfer(w, h.logger, http.StatusOK, "completed", *transfer)
+}
+
+// CancelOwnershipTransfer handles DELETE /v1/orgs/{orgId}/ownership-transfer.
+// Either party or an admin may cancel.
+func (h *Handler) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	transfer := OwnershipTransferFromMetadata(org.Metadata)
+	if transfer == nil {
+		http.Error(w, "no ownership transfer is pending", http.StatusNotFound)
+		return
+	}
+	actor := getActorID(r).String()
+	if actor != transfer.FromUserID && actor != transfer.ToUserID && !h.isAdmin(r) {
+		http.Error(w, "only the current or new billing owner or an admin can cancel the transfer", http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.saveOwnership(r, org, org.BillingOwnerUserID, nil); err != nil {
+		h.writeSaveError(w, org, err)
+		return
+	}
+	h.emitTransfer(r, org.ID, audit.ActionOrgTransferCancel, transfer)
+	writeTransfer(w, h.logger, http.StatusOK, "cancelled", *transfer)
+}
+
+// transferStillValid reports whether the org's billing owner is still the one the transfer started from.
+func transferStillValid(org postgres.Org, transfer *OwnershipTransfer) bool {
+	if org.BillingOwnerUserID == nil {
+		return transfer.FromUserID == ""
+	}
+	return org.BillingOwnerUserID.String() == transfer.FromUserID
+}
+
+// saveOwnership writes the billing owner and pending transfer (nil clears it).
+func (h *Handler) saveOwnership(r *http.Request, org postgres.Org, billingOwner *uuid.UUID, transfer *OwnershipTransfer) (postgres.Org, error) {
+	metadata := make(map[string]any, len(org.Metadata)+1)
+	for k, v := range org.Metadata {
+		metadata[k] = v
+	}
+	if transfer == nil {
+		delete(metadata, OwnershipTransferMetadataKey)
+	} else {
+		metadata[OwnershipTransferMetadataKey] = transfer
+	}
+	return h.runtime.Postgres.UpdateOrg(r.Context(), postgres.UpdateOrgParams{
+		ID:                    org.ID,
+		Version:               org.Version,
+		Name:                  org.Name,
+		Status:                org.Status,
+		BillingOwnerUserID:    billingOwner,
+		BudgetPolicyID:        org.BudgetPolicyID,
+		DeclarativeMode:       org.DeclarativeMode,
+		DeclarativeRepoURL:    org.DeclarativeRepoURL,
+		DeclarativeBranch:     org.DeclarativeBranch,
+		DeclarativeLastCommit: org.DeclarativeLastCommit,
+		MFARequiredRoles:      org.MFARequiredRoles,
+		Metadata:              metadata,
+	})
+}
+
+func (h *Handler) writeSaveError(w http.ResponseWriter, org postgres.Org, err error) {
+	if err == postgres.ErrOptimisticLock {
+		http.Error(w, "organization was modified concurrently", http.StatusConflict)
+		return
+	}
+	h.logger.Error("failed to update org ownership", zap.Error(err), zap.String("orgId", org.ID.String()))
+	http.Error(w, "failed to update organization", http.StatusInternalServerError)
+}
+
+func (h *Handler) emitTransfer(r *http.Request, orgID uuid.UUID, action string, transfer *OwnershipTransfer) {
+	event := audit.BuildEvent(orgID, getActorID(r), audit.ActorTypeUser, action, audit.TargetTypeOrg, &orgID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"transfer_id":    transfer.TransferID,
+		"from_user_id":   transfer.FromUserID,
+		"to_user_id":     transfer.ToUserID,
+		"requested_by":   transfer.RequestedBy,
+		"expires_at":     transfer.ExpiresAt.Format(time.RFC3339),
+		"from_confirmed": transfer.FromConfirmedAt != nil,
+		"to_confirmed":   transfer.ToConfirmedAt != nil,
+	}
+	_ = h.runtime.Audit.Emit(r.Context(), event)
+}
+
+// isAdmin reports whether the caller holds the admin scope.
+func (h *Handler) isAdmin(r *http.Request) bool {
+	user := middleware.GetAuthenticatedUser(r.Context())
+	return user != nil && user.HasScope(h.runtime.Config.AdminScope)
+}
+
+func writeTransfer(w http.ResponseWriter, logger *zap.Logger, status int, state string, transfer OwnershipTransfer) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(OwnershipTransferResponse{Status: state, Transfer: transfer}); err != nil {
+		logger.Error("failed to encode response", zap.Error(err))
+	}
+}
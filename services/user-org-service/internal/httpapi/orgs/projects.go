@@ -0,0 +1,216 @@
+package orgs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// ParentMetadataKey is the org metadata key linking a team or project to its parent org.
+const ParentMetadataKey = "parent_org"
+
+// Sub-org kinds.
+const (
+	KindTeam    = "team"
+	KindProject = "project"
+)
+
+// OrgParent marks an org as a team or project beneath another org. Sub-orgs
+// are one level deep: a parent cannot itself have a parent.
+type OrgParent struct {
+	ParentOrgID string `json:"parentOrgId"`
+	Kind        string `json:"kind"` // "team" or "project"
+}
+
+// ParentFromMetadata returns the org's parent link, or nil for a top-level org.
+func ParentFromMetadata(metadata map[string]any) *OrgParent {
+	raw, ok := metadata[ParentMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var parent OrgParent
+	if err := json.Unmarshal(data, &parent); err != nil || parent.ParentOrgID == "" {
+		return nil
+	}
+	return &parent
+}
+
+// EffectiveMetadata returns a sub-org's metadata with its parent's settings
+// inherited. Tool limits, rate limits, archival, CORS origins and log export
+// are taken from the sub-org when it sets them and from the parent otherwise.
+// Data residency and model entitlements can only be narrowed: a sub-org's
+// allowed regions are intersected with the parent's, denied models are
+// combined, and the parent's allowed models win when it has any. Usage payload handling is the stricter
+// of the two, so a sub-org cannot record more than its parent allows.
+func EffectiveMetadata(parent, child map[string]any) map[string]any {
+	out := make(map[string]any, len(child)+6)
+	for k, v := range child {
+		out[k] = v
+	}
+	for _, key := range []string{ToolLimitsMetadataKey, RateLimitsMetadataKey, ArchivalMetadataKey, CORSMetadataKey, LogExportMetadataKey} {
+		if _, ok := out[key]; !ok {
+			if v, ok := parent[key]; ok {
+				out[key] = v
+			}
+		}
+	}
+
+	if parentRegions := AllowedRegionsFromMetadata(parent); parentRegions != nil {
+		regions := parentRegions
+		if childRegions := AllowedRegionsFromMetadata(child); childRegions != nil {
+			allowed := make(map[string]bool, len(parentRegions))
+			for _, region := range parentRegions {
+				allowed[region] = true
+			}
+			regions = []string{}
+			for _, region := range childRegions {
+				if allowed[region] {
+					regions = append(regions, region)
+				}
+			}
+			if len(regions) == 0 {
+				// No region satisfies both; fall back to the parent's rather than
+				// returning an empty (unrestricted) list
+				regions = parentRegions
+			}
+		}
+		out[ResidencyMetadataKey] = &DataResidency{AllowedRegions: regions}
+	}
+
+	if parentModels := EntitlementsFromMetadata(parent); parentModels != nil {
+		merged := *parentModels
+		if childModels := EntitlementsFromMetadata(child); childModels != nil {
+			if len(merged.AllowedModels) == 0 {
+				merged.AllowedModels = childModels.AllowedModels
+			}
+			merged.DeniedModels = append(append([]string{}, merged.DeniedModels...), childModels.DeniedModels...)
+		}
+		out[EntitlementsMetadataKey] = &merged
+	}
+
+	if payloads := stricterUsagePayloads(UsagePayloadsFromMetadata(parent), UsagePayloadsFromMetadata(child)); payloads != nil {
+		out[UsagePayloadsMetadataKey] = payloads
+	}
+	return out
+}
+
+// CreateProjectRequest is the payload for POST /v1/orgs/{orgId}/projects.
+type CreateProjectRequest struct {
+	Name     string         `json:"name"`
+	Slug     string         `json:"slug"`
+	Kind     string         `json:"kind,omitempty"` // "project" (default) or "team"
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// CreateProject handles POST /v1/orgs/{orgId}/projects - Create a team or
+// project under an org. The sub-org inherits the parent's billing owner and
+// budget policy; API keys issued in it are scoped to it and attributed to it
+// in usage analytics.
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if req.Name == "" || slug == "" {
+		http.Error(w, "name and slug are required", http.StatusBadRequest)
+		return
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = KindProject
+	}
+	if kind != KindProject && kind != KindTeam {
+		http.Error(w, "kind must be 'project' or 'team'", http.StatusBadRequest)
+		return
+	}
+
+	parent, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	if ParentFromMetadata(parent.Metadata) != nil {
+		http.Error(w, "projects cannot be nested under another project", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.runtime.Postgres.GetOrgBySlug(ctx, slug); err == nil {
+		http.Error(w, "organization with this slug already exists", http.StatusConflict)
+		return
+	}
+
+	metadata := make(map[string]any, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[ParentMetadataKey] = OrgParent{ParentOrgID: parent.ID.String(), Kind: kind}
+
+	project, err := h.runtime.Postgres.CreateOrg(ctx, postgres.CreateOrgParams{
+		ID:                 uuid.New(),
+		Slug:               slug,
+		Name:               req.Name,
+		Status:             "active",
+		BillingOwnerUserID: parent.BillingOwnerUserID,
+		BudgetPolicyID:     parent.BudgetPolicyID,
+		Metadata:           metadata,
+	})
+	if err != nil {
+		h.logger.Error("failed to create project", zap.Error(err), zap.String("slug", slug), zap.String("parentOrgId", parent.ID.String()))
+		http.Error(w, "failed to create project", http.StatusInternalServerError)
+		return
+	}
+
+	h.runtime.OrgResolver.Forget(ctx, project.Slug)
+
+	event := audit.BuildEvent(parent.ID, getActorID(r), audit.ActorTypeUser, audit.ActionOrgCreate, audit.TargetTypeOrg, &project.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"slug":          project.Slug,
+		"name":          project.Name,
+		"kind":          kind,
+		"parent_org_id": parent.ID.String(),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toOrgResponse(project)); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// ListProjects handles GET /v1/orgs/{orgId}/projects - List an org's teams and projects.
+func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	parent, ok := h.loadOrg(w, r)
+	if !ok {
+		return
+	}
+	children, err := h.runtime.Postgres.ListChildOrgs(r.Context(), parent.ID)
+	if err != nil {
+		h.logger.Error("failed to list projects", zap.Error(err), zap.String("orgId", parent.ID.String()))
+		http.Error(w, "failed to list projects", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]OrganizationResponse, 0, len(children))
+	for _, child := range children {
+		resp = append(resp, toOrgResponse(child))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
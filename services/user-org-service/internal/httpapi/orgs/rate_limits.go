@@ -0,0 +1,83 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// RateLimitsMetadataKey is the org metadata key holding rate limit settings.
+const RateLimitsMetadataKey = "rate_limits"
+
+const maxConcurrentCeiling = 10000
+
+// tierPattern matches plan tier labels such as "free" or "enterprise-plus".
+var tierPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Rate limit algorithms the API router supports.
+var rateLimitAlgorithms = map[string]bool{
+	"token_bucket":   true,
+	"fixed_window":   true,
+	"sliding_window": true,
+}
+
+// RateLimits selects how the API router limits an org's request rate and
+// caps its concurrent requests and streams. Empty fields fall back to the
+// router defaults.
+// Tier labels the org's rate limit metrics on the router.
+type RateLimits struct {
+	Tier                string `json:"tier,omitempty"`
+	Algorithm           string `json:"algorithm,omitempty"`
+	MaxConcurrent       int    `json:"maxConcurrent,omitempty"`
+	MaxConcurrentPerKey int    `json:"maxConcurrentPerKey,omitempty"`
+
+	MaxConcurrentStreams       int `json:"maxConcurrentStreams,omitempty"`
+	MaxConcurrentStreamsPerKey int `json:"maxConcurrentStreamsPerKey,omitempty"`
+}
+
+// IsEmpty reports whether nothing overrides the router defaults.
+func (l *RateLimits) IsEmpty() bool {
+	return l.Tier == "" && l.Algorithm == "" && l.MaxConcurrent == 0 && l.MaxConcurrentPerKey == 0 &&
+		l.MaxConcurrentStreams == 0 && l.MaxConcurrentStreamsPerKey == 0
+}
+
+// validate checks the settings are ones the router accepts.
+func (l *RateLimits) validate() error {
+	if l.Tier != "" && !tierPattern.MatchString(l.Tier) {
+		return fmt.Errorf("tier must be a lowercase label of at most 32 characters")
+	}
+	if l.Algorithm != "" && !rateLimitAlgorithms[l.Algorithm] {
+		return fmt.Errorf("algorithm must be one of token_bucket, fixed_window or sliding_window")
+	}
+	if l.MaxConcurrent < 0 || l.MaxConcurrent > maxConcurrentCeiling {
+		return fmt.Errorf("maxConcurrent must be between 0 and %d", maxConcurrentCeiling)
+	}
+	if l.MaxConcurrentPerKey < 0 || l.MaxConcurrentPerKey > maxConcurrentCeiling {
+		return fmt.Errorf("maxConcurrentPerKey must be between 0 and %d", maxConcurrentCeiling)
+	}
+	if l.MaxConcurrentStreams < 0 || l.MaxConcurrentStreams > maxConcurrentCeiling {
+		return fmt.Errorf("maxConcurrentStreams must be between 0 and %d", maxConcurrentCeiling)
+	}
+	if l.MaxConcurrentStreamsPerKey < 0 || l.MaxConcurrentStreamsPerKey > maxConcurrentCeiling {
+		return fmt.Errorf("maxConcurrentStreamsPerKey must be between 0 and %d", maxConcurrentCeiling)
+	}
+	return nil
+}
+
+// RateLimitsFromMetadata returns the org's rate limit settings, or nil when unset.
+func RateLimitsFromMetadata(metadata map[string]any) *RateLimits {
+	raw, ok := metadata[RateLimitsMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var limits RateLimits
+	if err := json.Unmarshal(data, &limits); err != nil || limits.IsEmpty() {
+		return nil
+	}
+	return &limits
+}
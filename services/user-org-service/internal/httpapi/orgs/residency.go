@@ -0,0 +1,61 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ResidencyMetadataKey is the org metadata key holding data-residency settings.
+const ResidencyMetadataKey = "data_residency"
+
+const maxAllowedRegions = 16
+
+var regionPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// DataResidency restricts which backend regions may serve an org's inference
+// traffic. The API router excludes backends outside AllowedRegions; an empty
+// list means no restriction.
+type DataResidency struct {
+	AllowedRegions []string `json:"allowedRegions"`
+}
+
+// normalize lowercases, validates and de-duplicates the region labels.
+func (d *DataResidency) normalize() error {
+	if len(d.AllowedRegions) > maxAllowedRegions {
+		return fmt.Errorf("at most %d allowedRegions are supported", maxAllowedRegions)
+	}
+	regions := make([]string, 0, len(d.AllowedRegions))
+	seen := make(map[string]bool, len(d.AllowedRegions))
+	for _, raw := range d.AllowedRegions {
+		region := strings.ToLower(strings.TrimSpace(raw))
+		if !regionPattern.MatchString(region) {
+			return fmt.Errorf("invalid region %q", raw)
+		}
+		if !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+	d.AllowedRegions = regions
+	return nil
+}
+
+// AllowedRegionsFromMetadata returns the org's allowed backend regions, or nil when unrestricted.
+func AllowedRegionsFromMetadata(metadata map[string]any) []string {
+	raw, ok := metadata[ResidencyMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var residency DataResidency
+	if err := json.Unmarshal(data, &residency); err != nil || len(residency.AllowedRegions) == 0 {
+		return nil
+	}
+	return residency.AllowedRegions
+}
@@ -0,0 +1,97 @@
+package orgs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// routerInvalidatePath drops the API router's cached key validations for orgs.
+const routerInvalidatePath = "/v1/admin/caches/auth-validation/invalidate-orgs"
+
+// RouterCacheInvalidator tells API router pods to forget cached API key
+// validations, so keys of suspended orgs stop working before the router's
+// one-minute cache expires.
+type RouterCacheInvalidator interface {
+	// InvalidateOrgs returns an error if any router could not be reached.
+	InvalidateOrgs(ctx context.Context, orgIDs []string) error
+	// Enabled reports whether any router is configured.
+	Enabled() bool
+}
+
+// NewRouterCacheInvalidator calls each router admin URL with apiKey (which
+// needs the router's admin scope). With no URLs it does nothing.
+func NewRouterCacheInvalidator(urls []string, apiKey string, logger *zap.Logger) RouterCacheInvalidator {
+	trimmed := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u = strings.TrimSuffix(strings.TrimSpace(u), "/"); u != "" {
+			trimmed = append(trimmed, u)
+		}
+	}
+	return &routerCacheInvalidator{
+		urls:   trimmed,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 5 * time.Second, Transport: &requestid.Transport{}},
+		logger: logger,
+	}
+}
+
+type routerCacheInvalidator struct {
+	urls   []string
+	apiKey string
+	client *http.Client
+	logger *zap.Logger
+}
+
+func (i *routerCacheInvalidator) Enabled() bool {
+	return len(i.urls) > 0
+}
+
+func (i *routerCacheInvalidator) InvalidateOrgs(ctx context.Context, orgIDs []string) error {
+	if len(i.urls) == 0 || len(orgIDs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]any{"orgIds": orgIDs})
+	if err != nil {
+		return fmt.Errorf("marshal invalidation: %w", err)
+	}
+	var failed []string
+	for _, base := range i.urls {
+		if err := i.post(ctx, base+routerInvalidatePath, body); err != nil {
+			i.logger.Warn("router cache invalidation failed", zap.String("router", base), zap.Error(err))
+			failed = append(failed, base)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("router cache invalidation failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (i *routerCacheInvalidator) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", i.apiKey)
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post invalidation: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("router returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolLimitsMetadataKey is the org metadata key holding tool-calling limits.
+const ToolLimitsMetadataKey = "tool_limits"
+
+const (
+	maxToolsCeiling     = 512
+	maxToolBytesCeiling = 1 << 20
+)
+
+// ToolLimits caps the tool/function definitions an org may send per request.
+// The API router enforces them; zero fields fall back to the router defaults.
+type ToolLimits struct {
+	MaxTools      int `json:"maxTools,omitempty"`
+	MaxTotalBytes int `json:"maxTotalBytes,omitempty"`
+}
+
+// IsEmpty reports whether no limit is set.
+func (l *ToolLimits) IsEmpty() bool {
+	return l.MaxTools == 0 && l.MaxTotalBytes == 0
+}
+
+// validate checks the limits are within what the router accepts.
+func (l *ToolLimits) validate() error {
+	if l.MaxTools < 0 || l.MaxTools > maxToolsCeiling {
+		return fmt.Errorf("maxTools must be between 0 and %d", maxToolsCeiling)
+	}
+	if l.MaxTotalBytes < 0 || l.MaxTotalBytes > maxToolBytesCeiling {
+		return fmt.Errorf("maxTotalBytes must be between 0 and %d", maxToolBytesCeiling)
+	}
+	return nil
+}
+
+// ToolLimitsFromMetadata returns the org's tool limits, or nil when unset.
+func ToolLimitsFromMetadata(metadata map[string]any) *ToolLimits {
+	raw, ok := metadata[ToolLimitsMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var limits ToolLimits
+	if err := json.Unmarshal(data, &limits); err != nil || limits.IsEmpty() {
+		return nil
+	}
+	return &limits
+}
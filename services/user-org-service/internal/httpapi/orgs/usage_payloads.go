@@ -0,0 +1,103 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UsagePayloadsMetadataKey is the org metadata key holding usage record payload settings.
+const UsagePayloadsMetadataKey = "usage_payloads"
+
+// Usage payload modes, from least to most restrictive.
+const (
+	UsagePayloadsFull      = "full"
+	UsagePayloadsTruncated = "truncated"
+	UsagePayloadsHashOnly  = "hash_only"
+	UsagePayloadsOmitted   = "omitted"
+)
+
+const (
+	defaultUsagePayloadMaxBytes = 1024
+	maxUsagePayloadMaxBytes     = 64 << 10
+)
+
+// usagePayloadRank orders the modes by how much of the payload they keep.
+var usagePayloadRank = map[string]int{
+	UsagePayloadsFull:      0,
+	UsagePayloadsTruncated: 1,
+	UsagePayloadsHashOnly:  2,
+	UsagePayloadsOmitted:   3,
+}
+
+// UsagePayloads controls whether prompt and response text is carried in the
+// org's usage records and from there into analytics. The API router applies
+// it before publishing: full keeps the text, truncated keeps the first
+// MaxBytes, hash_only replaces it with a SHA-256 digest and omitted (the
+// router's behaviour when unset) drops it.
+type UsagePayloads struct {
+	Mode     string `json:"mode"`
+	MaxBytes int    `json:"maxBytes,omitempty"`
+}
+
+// IsEmpty reports whether no mode is set.
+func (p *UsagePayloads) IsEmpty() bool {
+	return p.Mode == ""
+}
+
+// normalize validates the mode and applies the default truncation length.
+func (p *UsagePayloads) normalize() error {
+	p.Mode = strings.ToLower(strings.TrimSpace(p.Mode))
+	if p.Mode == "" {
+		*p = UsagePayloads{}
+		return nil
+	}
+	if _, ok := usagePayloadRank[p.Mode]; !ok {
+		return fmt.Errorf("mode must be one of full, truncated, hash_only or omitted")
+	}
+	if p.Mode != UsagePayloadsTruncated {
+		p.MaxBytes = 0
+		return nil
+	}
+	if p.MaxBytes == 0 {
+		p.MaxBytes = defaultUsagePayloadMaxBytes
+	}
+	if p.MaxBytes < 1 || p.MaxBytes > maxUsagePayloadMaxBytes {
+		return fmt.Errorf("maxBytes must be between 1 and %d", maxUsagePayloadMaxBytes)
+	}
+	return nil
+}
+
+// stricterUsagePayloads returns whichever of a and b keeps less of the
+// payload; for two truncations the shorter one.
+func stricterUsagePayloads(a, b *UsagePayloads) *UsagePayloads {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case usagePayloadRank[b.Mode] > usagePayloadRank[a.Mode]:
+		return b
+	case b.Mode == UsagePayloadsTruncated && a.Mode == UsagePayloadsTruncated && b.MaxBytes < a.MaxBytes:
+		return b
+	}
+	return a
+}
+
+// UsagePayloadsFromMetadata returns the org's usage payload settings, or nil when unset.
+func UsagePayloadsFromMetadata(metadata map[string]any) *UsagePayloads {
+	raw, ok := metadata[UsagePayloadsMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var payloads UsagePayloads
+	if err := json.Unmarshal(data, &payloads); err != nil || payloads.IsEmpty() {
+		return nil
+	}
+	return &payloads
+}
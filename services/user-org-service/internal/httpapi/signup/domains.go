@@ -0,0 +1,62 @@
+package signup
+
+import "strings"
+
+// disposableDomains is a built-in list of well-known throwaway email
+// providers. Operators extend it with SIGNUP_BLOCKED_EMAIL_DOMAINS.
+var disposableDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"emailondeck.com",
+	"fakeinbox.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"guerrillamail.net",
+	"maildrop.cc",
+	"mailinator.com",
+	"mailnesia.com",
+	"mintemail.com",
+	"mohmal.com",
+	"sharklasers.com",
+	"spamgourmet.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"tempmailo.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// domainBlocklist matches an email domain and any of its subdomains.
+type domainBlocklist map[string]struct{}
+
+func newDomainBlocklist(extra []string) domainBlocklist {
+	list := make(domainBlocklist, len(disposableDomains)+len(extra))
+	for _, d := range append(disposableDomains, extra...) {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" {
+			list[d] = struct{}{}
+		}
+	}
+	return list
+}
+
+// Blocked reports whether the domain of email is on the list.
+func (l domainBlocklist) Blocked(email string) bool {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+	domain = strings.ToLower(domain)
+	for {
+		if _, blocked := l[domain]; blocked {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return false
+		}
+		domain = parent
+	}
+}
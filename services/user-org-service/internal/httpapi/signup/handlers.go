@@ -0,0 +1,482 @@
+// Package signup provides the public self-service signup endpoints.
+//
+// Purpose:
+//
+//	Lets prospective customers start a trial without an operator: POST
+//	/v1/signup creates a pending organization and an unverified owner and
+//	issues an email verification token; POST /v1/signup/verify checks the
+//	token and activates both. The routes are only mounted when
+//	SIGNUP_ENABLED is true.
+//
+// Dependencies:
+//   - internal/bootstrap: Runtime dependencies (Postgres store, Redis, config, audit)
+//   - internal/storage/postgres: Data access layer (Store.InTx for atomicity)
+//   - internal/security: Password and token hashing
+//
+// Key Responsibilities:
+//   - Signup: POST /v1/signup - Create pending org and invited owner, issue verification token
+//   - Verify: POST /v1/signup/verify - Verify token, activate owner and org
+//   - Rate limit attempts per client IP and reject disposable email domains
+//
+// Requirements Reference:
+//   - specs/005-user-org-service/spec.md#US-001 (User & Organization Management)
+//
+// Debugging Notes:
+//   - Org status is "pending" and owner status "invited" until verification
+//   - The verification token is stored hashed in user metadata under
+//     "email_verification" and cleared once used
+//   - The verification link is posted to SIGNUP_WEBHOOK_URL (default
+//     ONBOARDING_WEBHOOK_URL) as signup.verification_requested; the token is
+//     also returned in the response when ENVIRONMENT=development
+//   - Rate limiting uses Redis when configured, otherwise per-process memory;
+//     a Redis error refuses signups with 503 unless SIGNUP_RATE_LIMIT_FAIL_OPEN is set
+//
+// Thread Safety:
+//   - Handler methods are safe for concurrent use
+//
+// Error Handling:
+//   - Invalid payload or blocked domain returns 400 Bad Request
+//   - Existing slug returns 409 Conflict
+//   - Rate limit exceeded returns 429 Too Many Requests, an unavailable limiter 503
+//   - Invalid token returns 400, expired token returns 410 Gone
+package signup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/bootstrap"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const (
+	verificationMetadataKey = "email_verification"
+	minPasswordLength       = 8
+)
+
+var (
+	slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+	errSlugTaken      = errors.New("organization with this slug already exists")
+	errNotPending     = errors.New("signup is not pending verification")
+	errInvalidToken   = errors.New("invalid verification token")
+	errExpiredToken   = errors.New("verification token has expired")
+	errConcurrentEdit = errors.New("signup was modified concurrently")
+)
+
+// RegisterRoutes mounts the public signup routes when SIGNUP_ENABLED is set.
+func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger) {
+	if rt == nil || rt.Postgres == nil || !rt.Config.SignupEnabled {
+		return
+	}
+	webhook := rt.Config.SignupWebhookURL
+	if webhook == "" {
+		webhook = rt.Config.OnboardingWebhookURL
+	}
+	if webhook == "" && rt.Config.Environment != "development" {
+		logger.Warn("signup enabled without SIGNUP_WEBHOOK_URL or ONBOARDING_WEBHOOK_URL; verification links are only logged")
+	}
+	handler := &Handler{
+		runtime:   rt,
+		logger:    logger,
+		limiter:   security.NewAttemptLimiter(rt.Redis, "signup", rt.Config.SignupRateLimitPerHour, time.Hour),
+		blocklist: newDomainBlocklist(rt.Config.SignupBlockedEmailDomains),
+		notifier:  newVerificationNotifier(webhook, logger),
+	}
+	router.Post("/v1/signup", handler.Signup)
+	router.Post("/v1/signup/verify", handler.Verify)
+}
+
+// Handler serves the signup endpoints.
+type Handler struct {
+	runtime   *bootstrap.Runtime
+	logger    *zap.Logger
+	limiter   *security.AttemptLimiter
+	blocklist domainBlocklist
+	notifier  verificationNotifier
+}
+
+// SignupRequest is the payload for POST /v1/signup.
+type SignupRequest struct {
+	OrgName     string `json:"orgName"`
+	OrgSlug     string `json:"orgSlug"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// SignupResponse is returned once the pending org and owner exist.
+type SignupResponse struct {
+	OrgID     string `json:"orgId"`
+	UserID    string `json:"userId"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expiresAt"`
+	// Token is only returned in development; otherwise it is only emailed
+	Token string `json:"token,omitempty"`
+}
+
+// VerifyRequest is the payload for POST /v1/signup/verify.
+type VerifyRequest struct {
+	OrgID  string `json:"orgId"`
+	UserID string `json:"userId"`
+	Token  string `json:"token"`
+}
+
+// VerifyResponse is returned once the signup is activated.
+type VerifyResponse struct {
+	OrgID  string `json:"orgId"`
+	UserID string `json:"userId"`
+	Status string `json:"status"`
+}
+
+// Signup handles POST /v1/signup.
+func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	allowed, err := h.limiter.Allow(ctx, clientIP(r))
+	if err != nil {
+		if !h.runtime.Config.SignupRateLimitFailOpen {
+			h.logger.Error("signup rate limiter unavailable, refusing request", zap.Error(err))
+			http.Error(w, "signup is temporarily unavailable, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		h.logger.Warn("signup rate limiter unavailable, allowing request", zap.Error(err))
+	} else if !allowed {
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "too many signup attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var req SignupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request payload", zap.Error(err))
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.OrgSlug))
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	orgName := strings.TrimSpace(req.OrgName)
+	if orgName == "" || slug == "" || email == "" || req.Password == "" {
+		http.Error(w, "orgName, orgSlug, email and password are required", http.StatusBadRequest)
+		return
+	}
+	if !slugPattern.MatchString(slug) {
+		http.Error(w, "orgSlug must be 2-63 lowercase letters, digits or hyphens", http.StatusBadRequest)
+		return
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		http.Error(w, "invalid email address", http.StatusBadRequest)
+		return
+	}
+	if h.blocklist.Blocked(email) {
+		http.Error(w, "email domain is not allowed for signup", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		http.Error(w, "password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+
+	passwordHash, err := security.HashPassword(req.Password)
+	if err != nil {
+		h.logger.Error("failed to hash password", zap.Error(err))
+		http.Error(w, "failed to create signup", http.StatusInternalServerError)
+		return
+	}
+	token, tokenHash, err := newVerificationToken()
+	if err != nil {
+		h.logger.Error("failed to generate verification token", zap.Error(err))
+		http.Error(w, "failed to create signup", http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().UTC().Add(h.runtime.Config.SignupVerificationTTL)
+
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		displayName = email
+	}
+
+	var org postgres.Org
+	var user postgres.User
+	err = h.runtime.Postgres.InTx(ctx, func(ctx context.Context) error {
+		if _, err := h.runtime.Postgres.GetOrgBySlug(ctx, slug); err == nil {
+			return errSlugTaken
+		} else if !errors.Is(err, postgres.ErrNotFound) {
+			return err
+		}
+
+		var err error
+		org, err = h.runtime.Postgres.CreateOrg(ctx, postgres.CreateOrgParams{
+			ID:       uuid.New(),
+			Slug:     slug,
+			Name:     orgName,
+			Status:   "pending",
+			Metadata: map[string]any{"source": "self_signup"},
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = h.runtime.Postgres.CreateUser(ctx, postgres.CreateUserParams{
+			ID:             uuid.New(),
+			OrgID:          org.ID,
+			Email:          email,
+			DisplayName:    displayName,
+			PasswordHash:   passwordHash,
+			Status:         "invited",
+			MFAMethods:     []string{},
+			RecoveryTokens: []string{},
+			Metadata: map[string]any{
+				"roles": []string{"owner"},
+				verificationMetadataKey: map[string]any{
+					"hash":       tokenHash,
+					"expires_at": expiresAt.Format(time.RFC3339),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return h.emit(ctx, r, org.ID, user.ID, audit.ActionOrgSignup, audit.TargetTypeOrg, org.ID, map[string]any{"slug": org.Slug, "email": user.Email})
+	})
+	if errors.Is(err, errSlugTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.logger.Error("signup rolled back", zap.Error(err), zap.String("slug", slug))
+		http.Error(w, "failed to create signup", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SignupResponse{
+		OrgID:     org.ID.String(),
+		UserID:    user.ID.String(),
+		Status:    org.Status,
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}
+	h.notifier.SendVerification(VerificationEvent{
+		OrgID:      resp.OrgID,
+		OrgSlug:    org.Slug,
+		UserID:     resp.UserID,
+		Email:      user.Email,
+		VerifyURL:  verificationLink(h.runtime.Config.SignupVerificationURL, resp.OrgID, resp.UserID, token),
+		ExpiresAt:  expiresAt,
+		OccurredAt: time.Now().UTC(),
+	})
+	if h.runtime.Config.Environment == "development" {
+		resp.Token = token
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// Verify handles POST /v1/signup/verify.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request payload", zap.Error(err))
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		http.Error(w, "invalid orgId", http.StatusBadRequest)
+		return
+	}
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		http.Error(w, "invalid userId", http.StatusBadRequest)
+		return
+	}
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	var org postgres.Org
+	err = h.runtime.Postgres.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		org, err = h.runtime.Postgres.GetOrg(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		user, err := h.runtime.Postgres.GetUserByID(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if org.Status != "pending" || user.Status != "invited" {
+			return errNotPending
+		}
+		if err := checkVerificationToken(user.Metadata, req.Token); err != nil {
+			return err
+		}
+
+		user, err = h.runtime.Postgres.UpdateUserStatus(ctx, postgres.UpdateUserStatusParams{
+			OrgID:   orgID,
+			ID:      userID,
+			Version: user.Version,
+			Status:  "active",
+		})
+		if err != nil {
+			return err
+		}
+
+		metadata := make(map[string]any, len(user.Metadata))
+		for k, v := range user.Metadata {
+			if k != verificationMetadataKey {
+				metadata[k] = v
+			}
+		}
+		metadata["email_verified_at"] = time.Now().UTC().Format(time.RFC3339)
+		if _, err := h.runtime.Postgres.UpdateUserProfile(ctx, postgres.UpdateUserProfileParams{
+			OrgID:       orgID,
+			ID:          userID,
+			Version:     user.Version,
+			DisplayName: user.DisplayName,
+			MFAEnrolled: user.MFAEnrolled,
+			MFAMethods:  user.MFAMethods,
+			MFASecret:   user.MFASecret,
+			Metadata:    metadata,
+		}); err != nil {
+			return err
+		}
+
+		org, err = h.runtime.Postgres.UpdateOrg(ctx, postgres.UpdateOrgParams{
+			ID:                    org.ID,
+			Version:               org.Version,
+			Name:                  org.Name,
+			Status:                "active",
+			BillingOwnerUserID:    &userID,
+			BudgetPolicyID:        org.BudgetPolicyID,
+			DeclarativeMode:       org.DeclarativeMode,
+			DeclarativeRepoURL:    org.DeclarativeRepoURL,
+			DeclarativeBranch:     org.DeclarativeBranch,
+			DeclarativeLastCommit: org.DeclarativeLastCommit,
+			MFARequiredRoles:      org.MFARequiredRoles,
+			Metadata:              org.Metadata,
+		})
+		if errors.Is(err, postgres.ErrOptimisticLock) {
+			return errConcurrentEdit
+		}
+		if err != nil {
+			return err
+		}
+		if err := h.emit(ctx, r, orgID, userID, audit.ActionUserVerifyEmail, audit.TargetTypeUser, userID, nil); err != nil {
+			return err
+		}
+		return h.emit(ctx, r, orgID, userID, audit.ActionOrgActivate, audit.TargetTypeOrg, orgID, map[string]any{"slug": org.Slug})
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, postgres.ErrNotFound), errors.Is(err, errInvalidToken):
+		// Same response for unknown ids and bad tokens to avoid enumeration
+		http.Error(w, errInvalidToken.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, errExpiredToken):
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case errors.Is(err, errNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, errConcurrentEdit), errors.Is(err, postgres.ErrOptimisticLock):
+		http.Error(w, errConcurrentEdit.Error(), http.StatusConflict)
+		return
+	default:
+		h.logger.Error("signup verification failed", zap.Error(err), zap.String("orgId", req.OrgID))
+		http.Error(w, "failed to verify signup", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(VerifyResponse{
+		OrgID:  orgID.String(),
+		UserID: userID.String(),
+		Status: org.Status,
+	}); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// emit records an audit event attributed to the signing-up user in the
+// transaction carried by ctx.
+func (h *Handler) emit(ctx context.Context, r *http.Request, orgID, actorID uuid.UUID, action, targetType string, targetID uuid.UUID, metadata map[string]any) error {
+	event := audit.BuildEvent(orgID, actorID, audit.ActorTypeUser, action, targetType, &targetID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = metadata
+	return h.runtime.EmitAuditInTx(ctx, event)
+}
+
+// newVerificationToken returns a 32-byte base64url token and its hash.
+func newVerificationToken() (token, hash string, err error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(tokenBytes)
+	hash, err = security.HashPassword(token)
+	if err != nil {
+		return "", "", err
+	}
+	return token, hash, nil
+}
+
+// checkVerificationToken validates token against the hash stored in user metadata.
+func checkVerificationToken(metadata map[string]any, token string) error {
+	record, ok := metadata[verificationMetadataKey].(map[string]any)
+	if !ok {
+		return errInvalidToken
+	}
+	hash, _ := record["hash"].(string)
+	if hash == "" {
+		return errInvalidToken
+	}
+	match, err := security.VerifyPassword(token, hash)
+	if err != nil || !match {
+		return errInvalidToken
+	}
+	expiresRaw, _ := record["expires_at"].(string)
+	expiresAt, err := time.Parse(time.RFC3339, expiresRaw)
+	if err != nil || time.Now().UTC().After(expiresAt) {
+		return errExpiredToken
+	}
+	return nil
+}
+
+// clientIP returns the first client address from the proxy headers or RemoteAddr.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,67 @@
+package signup
+
+import (
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// VerificationEvent is posted to the signup webhook; the receiver emails
+// VerifyURL to Email.
+type VerificationEvent struct {
+	Type       string    `json:"type"`
+	OrgID      string    `json:"orgId"`
+	OrgSlug    string    `json:"orgSlug"`
+	UserID     string    `json:"userId"`
+	Email      string    `json:"email"`
+	VerifyURL  string    `json:"verifyUrl"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// verificationNotifier delivers verification links. Delivery is asynchronous
+// and best-effort; the signup itself has already committed.
+type verificationNotifier interface {
+	SendVerification(event VerificationEvent)
+}
+
+func newVerificationNotifier(url string, logger *zap.Logger) verificationNotifier {
+	return webhookNotifier{notifier: notify.New(url, logger), logger: logger}
+}
+
+// webhookNotifier posts VerificationEvent as JSON. The link itself is never logged.
+type webhookNotifier struct {
+	notifier *notify.Notifier
+	logger   *zap.Logger
+}
+
+func (n webhookNotifier) SendVerification(event VerificationEvent) {
+	event.Type = "signup.verification_requested"
+	n.notifier.SendAsync(event, func(err error) {
+		if err != nil {
+			n.logger.Warn("signup verification webhook failed", zap.Error(err), zap.String("user_id", event.UserID))
+		}
+	}, "signup verification link issued",
+		zap.String("org_id", event.OrgID),
+		zap.String("user_id", event.UserID),
+		zap.Time("expires_at", event.ExpiresAt),
+	)
+}
+
+// verificationLink returns base with the org, user and token query parameters
+// POST /v1/signup/verify needs.
+func verificationLink(base, orgID, userID, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		u = &url.URL{Path: base}
+	}
+	query := u.Query()
+	query.Set("orgId", orgID)
+	query.Set("userId", userID)
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,329 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/httpapi/middleware"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// DeletionMetadataKey is the user metadata key holding a scheduled
+// self-service deletion. The account deletion worker purges the account once
+// purge_after has passed.
+const DeletionMetadataKey = "account_deletion"
+
+// exportPath is where a user downloads their data before deletion.
+const exportPath = "/v1/users/me/export"
+
+// DeletionSchedule records when deletion was requested and when the account will be purged.
+type DeletionSchedule struct {
+	RequestedAt time.Time `json:"requested_at"`
+	PurgeAfter  time.Time `json:"purge_after"`
+}
+
+// DeletionFromMetadata returns the user's scheduled deletion, or nil when none is pending.
+func DeletionFromMetadata(metadata map[string]any) *DeletionSchedule {
+	raw, ok := metadata[DeletionMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var schedule DeletionSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil || schedule.PurgeAfter.IsZero() {
+		return nil
+	}
+	return &schedule
+}
+
+// ScheduleDeletionRequest is the payload for POST /v1/users/me/deletion.
+type ScheduleDeletionRequest struct {
+	// Password re-confirms the request; required unless the account signs in through an external IdP.
+	Password string `json:"password,omitempty"`
+}
+
+// AccountDeletionResponse describes the state of a self-service deletion.
+type AccountDeletionResponse struct {
+	Status      string `json:"status"` // "scheduled" or "cancelled"
+	RequestedAt string `json:"requestedAt,omitempty"`
+	PurgeAfter  string `json:"purgeAfter,omitempty"`
+	ExportURL   string `json:"exportUrl,omitempty"`
+}
+
+// AccountExport is everything the service stores about a user, returned by GET /v1/users/me/export.
+type AccountExport struct {
+	ExportedAt      string            `json:"exportedAt"`
+	User            UserResponse      `json:"user"`
+	LastLoginAt     string            `json:"lastLoginAt,omitempty"`
+	Organization    ExportedOrg       `json:"organization"`
+	APIKeys         []ExportedAPIKey  `json:"apiKeys"`
+	PendingDeletion *DeletionSchedule `json:"pendingDeletion,omitempty"`
+}
+
+// ExportedOrg identifies the organization the user belongs to.
+type ExportedOrg struct {
+	OrgID        string `json:"orgId"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	BillingOwner bool   `json:"billingOwner"`
+}
+
+// ExportedAPIKey describes one of the user's API keys; secrets are never stored and so never exported.
+type ExportedAPIKey struct {
+	APIKeyID    string   `json:"apiKeyId"`
+	Fingerprint string   `json:"fingerprint"`
+	Status      string   `json:"status"`
+	Scopes      []string `json:"scopes"`
+	IssuedAt    string   `json:"issuedAt"`
+	ExpiresAt   string   `json:"expiresAt,omitempty"`
+	RevokedAt   string   `json:"revokedAt,omitempty"`
+	LastUsedAt  string   `json:"lastUsedAt,omitempty"`
+}
+
+// ExportAccount handles GET /v1/users/me/export - Download the caller's data.
+func (h *Handler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	org, err := h.runtime.Postgres.GetOrg(ctx, user.OrgID)
+	if err != nil {
+		h.logger.Error("failed to get organization for export", zap.Error(err), zap.String("userId", user.ID.String()))
+		http.Error(w, "failed to export account", http.StatusInternalServerError)
+		return
+	}
+	keys, err := h.runtime.Postgres.ListAPIKeysForPrincipal(ctx, user.OrgID, postgres.PrincipalTypeUser, user.ID)
+	if err != nil {
+		h.logger.Error("failed to list API keys for export", zap.Error(err), zap.String("userId", user.ID.String()))
+		http.Error(w, "failed to export account", http.StatusInternalServerError)
+		return
+	}
+
+	export := AccountExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		User:       toUserResponse(user),
+		Organization: ExportedOrg{
+			OrgID:        org.ID.String(),
+			Name:         org.Name,
+			Slug:         org.Slug,
+			BillingOwner: isBillingOwner(org, user.ID),
+		},
+		APIKeys:         make([]ExportedAPIKey, 0, len(keys)),
+		PendingDeletion: DeletionFromMetadata(user.Metadata),
+	}
+	if user.LastLoginAt != nil {
+		export.LastLoginAt = user.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+	for _, key := range keys {
+		export.APIKeys = append(export.APIKeys, ExportedAPIKey{
+			APIKeyID:    key.ID.String(),
+			Fingerprint: key.Fingerprint,
+			Status:      key.Status,
+			Scopes:      key.Scopes,
+			IssuedAt:    key.IssuedAt.UTC().Format(time.RFC3339),
+			ExpiresAt:   formatOptionalTime(key.ExpiresAt),
+			RevokedAt:   formatOptionalTime(key.RevokedAt),
+			LastUsedAt:  formatOptionalTime(key.LastUsedAt),
+		})
+	}
+
+	event := audit.BuildEvent(user.OrgID, user.ID, audit.ActorTypeUser, audit.ActionUserExport, audit.TargetTypeUser, &user.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="account-export.json"`)
+	if err := json.NewEncoder(w).Encode(export); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+// ScheduleDeletion handles POST /v1/users/me/deletion - Schedule deletion of the caller's account.
+// The account keeps working during the grace period so the user can export
+// their data or cancel. Billing owners must transfer org ownership first.
+func (h *Handler) ScheduleDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req ScheduleDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if user.PasswordHash != "" {
+		if req.Password == "" {
+			http.Error(w, "password is required", http.StatusBadRequest)
+			return
+		}
+		if valid, err := security.VerifyPassword(req.Password, user.PasswordHash); err != nil || !valid {
+			http.Error(w, "password is incorrect", http.StatusForbidden)
+			return
+		}
+	}
+	if schedule := DeletionFromMetadata(user.Metadata); schedule != nil {
+		writeDeletion(w, h.logger, http.StatusAccepted, "scheduled", schedule)
+		return
+	}
+
+	org, err := h.runtime.Postgres.GetOrg(ctx, user.OrgID)
+	if err != nil {
+		h.logger.Error("failed to get organization for deletion", zap.Error(err), zap.String("userId", user.ID.String()))
+		http.Error(w, "failed to schedule deletion", http.StatusInternalServerError)
+		return
+	}
+	if isBillingOwner(org, user.ID) {
+		metrics.RecordAccountDeletion("blocked")
+		http.Error(w, "you are the organization's billing owner; transfer ownership before deleting your account", http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC()
+	schedule := &DeletionSchedule{
+		RequestedAt: now,
+		PurgeAfter:  now.Add(h.runtime.Config.AccountDeletionGracePeriod),
+	}
+	metadata := make(map[string]any, len(user.Metadata)+1)
+	for k, v := range user.Metadata {
+		metadata[k] = v
+	}
+	metadata[DeletionMetadataKey] = schedule
+	if !h.saveUserMetadata(w, r, user, metadata) {
+		return
+	}
+	metrics.RecordAccountDeletion("scheduled")
+
+	event := audit.BuildEvent(user.OrgID, user.ID, audit.ActorTypeUser, audit.ActionUserDeleteSchedule, audit.TargetTypeUser, &user.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	event.Metadata = map[string]any{
+		"purge_after": schedule.PurgeAfter.Format(time.RFC3339),
+	}
+	_ = h.runtime.Audit.Emit(ctx, event)
+
+	writeDeletion(w, h.logger, http.StatusAccepted, "scheduled", schedule)
+}
+
+// CancelDeletion handles DELETE /v1/users/me/deletion - Cancel a scheduled deletion.
+func (h *Handler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if DeletionFromMetadata(user.Metadata) == nil {
+		http.Error(w, "no account deletion is scheduled", http.StatusNotFound)
+		return
+	}
+
+	metadata := make(map[string]any, len(user.Metadata))
+	for k, v := range user.Metadata {
+		if k != DeletionMetadataKey {
+			metadata[k] = v
+		}
+	}
+	if !h.saveUserMetadata(w, r, user, metadata) {
+		return
+	}
+	metrics.RecordAccountDeletion("cancelled")
+
+	event := audit.BuildEvent(user.OrgID, user.ID, audit.ActorTypeUser, audit.ActionUserDeleteCancel, audit.TargetTypeUser, &user.ID)
+	event = audit.BuildEventFromRequest(event, r)
+	_ = h.runtime.Audit.Emit(r.Context(), event)
+
+	writeDeletion(w, h.logger, http.StatusOK, "cancelled", nil)
+}
+
+// currentUser loads the authenticated user, writing an error response and
+// returning false when it cannot.
+func (h *Handler) currentUser(w http.ResponseWriter, r *http.Request) (postgres.User, bool) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	if userID == uuid.Nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return postgres.User{}, false
+	}
+	orgID := middleware.GetOrgID(ctx)
+	if orgID == uuid.Nil {
+		var err error
+		if orgID, err = h.runtime.Postgres.GetUserOrgIDByUserID(ctx, userID); err != nil {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return postgres.User{}, false
+		}
+	}
+	user, err := h.runtime.Postgres.GetUserByID(ctx, orgID, userID)
+	if err != nil {
+		if err == postgres.ErrNotFound {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return postgres.User{}, false
+		}
+		h.logger.Error("failed to get user", zap.Error(err), zap.String("userId", userID.String()))
+		http.Error(w, "failed to retrieve user", http.StatusInternalServerError)
+		return postgres.User{}, false
+	}
+	return user, true
+}
+
+// saveUserMetadata replaces the user's metadata, writing an error response and
+// returning false on failure.
+func (h *Handler) saveUserMetadata(w http.ResponseWriter, r *http.Request, user postgres.User, metadata map[string]any) bool {
+	_, err := h.runtime.Postgres.UpdateUserProfile(r.Context(), postgres.UpdateUserProfileParams{
+		OrgID:       user.OrgID,
+		ID:          user.ID,
+		Version:     user.Version,
+		DisplayName: user.DisplayName,
+		MFAEnrolled: user.MFAEnrolled,
+		MFAMethods:  user.MFAMethods,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		if err == postgres.ErrOptimisticLock {
+			http.Error(w, "user was modified concurrently", http.StatusConflict)
+			return false
+		}
+		h.logger.Error("failed to update user metadata", zap.Error(err), zap.String("userId", user.ID.String()))
+		http.Error(w, "failed to update user", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func writeDeletion(w http.ResponseWriter, logger *zap.Logger, status int, state string, schedule *DeletionSchedule) {
+	resp := AccountDeletionResponse{Status: state}
+	if schedule != nil {
+		resp.RequestedAt = schedule.RequestedAt.UTC().Format(time.RFC3339)
+		resp.PurgeAfter = schedule.PurgeAfter.UTC().Format(time.RFC3339)
+		resp.ExportURL = exportPath
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+func isBillingOwner(org postgres.Org, userID uuid.UUID) bool {
+	return org.BillingOwnerUserID != nil && *org.BillingOwnerUserID == userID
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -20,6 +20,8 @@
 //   - GetUser: GET /v1/orgs/{orgId}/users/{userId} - Retrieve user details
 //   - UpdateUserStatus: PATCH /v1/orgs/{orgId}/users/{userId} - Update user status
 //   - UpdateUserRoles: PUT /v1/orgs/{orgId}/users/{userId}/roles - Update role assignments
+//   - ExportAccount: GET /v1/users/me/export - Download the caller's data
+//   - ScheduleDeletion/CancelDeletion: POST/DELETE /v1/users/me/deletion - Self-service account deletion
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#US-001 (User & Organization Management)
@@ -32,6 +34,9 @@
 //   - User status transitions: invited -> active -> suspended -> active or deleted
 //   - Role assignments require roles table (TODO: implement role storage)
 //   - Optimistic locking prevents concurrent update conflicts
+//   - Self-service deletion is recorded in metadata["account_deletion"]; the
+//     reconciler's account deletion worker purges the account after
+//     ACCOUNT_DELETION_GRACE_PERIOD. Billing owners must transfer ownership first
 //
 // Thread Safety:
 //   - Handler methods are safe for concurrent use (stateless, uses runtime dependencies)
@@ -49,6 +54,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -83,6 +89,10 @@ func RegisterRoutes(router chi.Router, rt *bootstrap.Runtime, logger *zap.Logger
 	router.Get("/v1/orgs/{orgId}/users/{userId}", handler.GetUser)
 	router.Patch("/v1/orgs/{orgId}/users/{userId}", handler.UpdateUser)
 	router.Put("/v1/orgs/{orgId}/users/{userId}/roles", handler.UpdateUserRoles)
+	// Self-service account routes act on the authenticated user
+	router.Get("/v1/users/me/export", handler.ExportAccount)
+	router.Post("/v1/users/me/deletion", handler.ScheduleDeletion)
+	router.Delete("/v1/users/me/deletion", handler.CancelDeletion)
 }
 
 // Handler serves user management endpoints.
@@ -138,7 +148,7 @@ func (h *Handler) InviteUser(w http.ResponseWriter, r *http.Request) {
 	orgIDParam := chi.URLParam(r, "orgId")
 
 	// Resolve org ID (UUID or slug)
-	orgID, err := h.resolveOrgID(ctx, orgIDParam)
+	orgID, err := h.runtime.OrgResolver.ResolveID(ctx, orgIDParam)
 	if err != nil {
 		http.Error(w, "organization not found", http.StatusNotFound)
 		return
@@ -299,7 +309,7 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	orgIDParam := chi.URLParam(r, "orgId")
 
-	_, err := h.resolveOrgID(ctx, orgIDParam)
+	_, err := h.runtime.OrgResolver.ResolveID(ctx, orgIDParam)
 	if err != nil {
 		http.Error(w, "organization not found", http.StatusNotFound)
 		return
@@ -317,7 +327,7 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	orgIDParam := chi.URLParam(r, "orgId")
 	userIDParam := chi.URLParam(r, "userId")
 
-	orgID, err := h.resolveOrgID(ctx, orgIDParam)
+	orgID, err := h.runtime.OrgResolver.ResolveID(ctx, orgIDParam)
 	if err != nil {
 		http.Error(w, "organization not found", http.StatusNotFound)
 		return
@@ -354,7 +364,7 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	orgIDParam := chi.URLParam(r, "orgId")
 	userIDParam := chi.URLParam(r, "userId")
 
-	orgID, err := h.resolveOrgID(ctx, orgIDParam)
+	orgID, err := h.runtime.OrgResolver.ResolveID(ctx, orgIDParam)
 	if err != nil {
 		http.Error(w, "organization not found", http.StatusNotFound)
 		return
@@ -393,16 +403,25 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		statusParams := postgres.UpdateUserStatusParams{
-			OrgID:   orgID,
-			ID:      userID,
-			Version: existingUser.Version,
-			Status:  *req.Status,
-		}
-
-		user, err := h.runtime.Postgres.UpdateUserStatus(ctx, statusParams)
+		var user postgres.User
+		err := h.runtime.Postgres.RetryOnConflict(ctx, "user.update_status", func(ctx context.Context, attempt int) error {
+			if attempt > 1 {
+				var err error
+				if existingUser, err = h.runtime.Postgres.GetUserByID(ctx, orgID, userID); err != nil {
+					return err
+				}
+			}
+			var err error
+			user, err = h.runtime.Postgres.UpdateUserStatus(ctx, postgres.UpdateUserStatusParams{
+				OrgID:   orgID,
+				ID:      userID,
+				Version: existingUser.Version,
+				Status:  *req.Status,
+			})
+			return err
+		})
 		if err != nil {
-			if err == postgres.ErrOptimisticLock {
+			if errors.Is(err, postgres.ErrOptimisticLock) {
 				http.Error(w, "user was modified concurrently", http.StatusConflict)
 				return
 			}
@@ -437,26 +456,35 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Update profile (display name, metadata) if provided
 	if req.DisplayName != nil || req.Metadata != nil {
-		profileParams := postgres.UpdateUserProfileParams{
-			OrgID:       orgID,
-			ID:          userID,
-			Version:     existingUser.Version,
-			DisplayName: existingUser.DisplayName,
-			MFAEnrolled: existingUser.MFAEnrolled,
-			MFAMethods:  existingUser.MFAMethods,
-			Metadata:    existingUser.Metadata,
-		}
-
-		if req.DisplayName != nil {
-			profileParams.DisplayName = *req.DisplayName
-		}
-		if req.Metadata != nil {
-			profileParams.Metadata = req.Metadata
-		}
-
-		user, err := h.runtime.Postgres.UpdateUserProfile(ctx, profileParams)
+		var user postgres.User
+		err := h.runtime.Postgres.RetryOnConflict(ctx, "user.update_profile", func(ctx context.Context, attempt int) error {
+			if attempt > 1 {
+				var err error
+				if existingUser, err = h.runtime.Postgres.GetUserByID(ctx, orgID, userID); err != nil {
+					return err
+				}
+			}
+			profileParams := postgres.UpdateUserProfileParams{
+				OrgID:       orgID,
+				ID:          userID,
+				Version:     existingUser.Version,
+				DisplayName: existingUser.DisplayName,
+				MFAEnrolled: existingUser.MFAEnrolled,
+				MFAMethods:  existingUser.MFAMethods,
+				Metadata:    existingUser.Metadata,
+			}
+			if req.DisplayName != nil {
+				profileParams.DisplayName = *req.DisplayName
+			}
+			if req.Metadata != nil {
+				profileParams.Metadata = req.Metadata
+			}
+			var err error
+			user, err = h.runtime.Postgres.UpdateUserProfile(ctx, profileParams)
+			return err
+		})
 		if err != nil {
-			if err == postgres.ErrOptimisticLock {
+			if errors.Is(err, postgres.ErrOptimisticLock) {
 				http.Error(w, "user was modified concurrently", http.StatusConflict)
 				return
 			}
@@ -499,7 +527,7 @@ func (h *Handler) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 	orgIDParam := chi.URLParam(r, "orgId")
 	userIDParam := chi.URLParam(r, "userId")
 
-	orgID, err := h.resolveOrgID(ctx, orgIDParam)
+	orgID, err := h.runtime.OrgResolver.ResolveID(ctx, orgIDParam)
 	if err != nil {
 		http.Error(w, "organization not found", http.StatusNotFound)
 		return
@@ -531,21 +559,6 @@ func (h *Handler) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
-// resolveOrgID resolves an org identifier (UUID or slug) to a UUID.
-func (h *Handler) resolveOrgID(ctx context.Context, orgIDParam string) (uuid.UUID, error) {
-	if orgID, err := uuid.Parse(orgIDParam); err == nil {
-		// Verify org exists
-		_, err := h.runtime.Postgres.GetOrg(ctx, orgID)
-		return orgID, err
-	}
-	// Treat as slug
-	org, err := h.runtime.Postgres.GetOrgBySlug(ctx, orgIDParam)
-	if err != nil {
-		return uuid.Nil, err
-	}
-	return org.ID, nil
-}
-
 // toUserResponse converts a postgres.User to a UserResponse.
 func toUserResponse(user postgres.User) UserResponse {
 	return UserResponse{
@@ -0,0 +1,57 @@
+package keyexpiry
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// Notice types posted to the webhook.
+const (
+	NoticeExpiring = "api_key.expiring"
+	NoticeExpired  = "api_key.expired"
+)
+
+// Notice describes an expiring or expired API key. The webhook receiver
+// turns it into an email to RecipientEmail (user keys) or the org admins.
+type Notice struct {
+	Type           string    `json:"type"`
+	OrgID          string    `json:"orgId"`
+	APIKeyID       string    `json:"apiKeyId"`
+	Fingerprint    string    `json:"fingerprint"`
+	DisplayName    string    `json:"displayName,omitempty"`
+	PrincipalType  string    `json:"principalType"`
+	PrincipalID    string    `json:"principalId"`
+	PrincipalName  string    `json:"principalName,omitempty"`
+	RecipientEmail string    `json:"recipientEmail,omitempty"`
+	Lead           string    `json:"lead,omitempty"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	OccurredAt     time.Time `json:"occurredAt"`
+}
+
+// Notifier delivers notices. Unlike onboarding notifications, delivery is
+// synchronous so the worker only records reminders that were accepted.
+type Notifier interface {
+	Notify(ctx context.Context, notice Notice) error
+}
+
+// NewNotifier returns a notifier posting to url, or logging when url is empty.
+func NewNotifier(url string, logger *zap.Logger) Notifier {
+	return webhookNotifier{notifier: notify.New(url, logger)}
+}
+
+type webhookNotifier struct {
+	notifier *notify.Notifier
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, notice Notice) error {
+	return n.notifier.Send(ctx, notice, "API key expiry notice",
+		zap.String("type", notice.Type),
+		zap.String("api_key_id", notice.APIKeyID),
+		zap.String("recipient_email", notice.RecipientEmail),
+		zap.Time("expires_at", notice.ExpiresAt),
+	)
+}
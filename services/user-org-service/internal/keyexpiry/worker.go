@@ -0,0 +1,311 @@
+// Package keyexpiry sends API key expiry reminders and enforces expiry.
+//
+// Purpose:
+//
+//	API keys with expires_at stop validating silently, which breaks
+//	integrations without warning. The worker periodically finds keys
+//	approaching expiry, notifies their owners at configurable lead times
+//	(e.g. 7 days and 1 day before), and moves keys past expires_at to status
+//	"expired" so listings and audits reflect reality.
+//
+// Dependencies:
+//   - internal/storage/postgres: API key, user and service account lookups
+//   - internal/audit: api_key.expiry_reminder and api_key.expire events
+//   - internal/metrics: Expired and reminder counters
+//
+// Key Responsibilities:
+//   - Worker.Run: Scan on an interval until the context is cancelled
+//   - Worker.RunOnce: One pass; reminders for due lead times, expiry for past-due keys
+//
+// Debugging Notes:
+//   - Sent reminders are recorded in the key's annotations under
+//     "expiry_reminders_sent" (lead time strings) so each is sent once
+//   - A key first seen inside several lead times gets only the tightest reminder
+//   - A reminder is only marked sent after delivery succeeds; failures retry next pass
+//   - Optimistic lock conflicts (key changed concurrently) are skipped until the next pass
+//
+// Thread Safety:
+//   - Run must only be called once per Worker; RunOnce is not reentrant
+//
+// Error Handling:
+//   - Per-key failures are logged and never stop the pass
+//   - RunOnce returns an error only when the scan query fails
+package keyexpiry
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const remindersAnnotation = "expiry_reminders_sent"
+
+// Store is the subset of postgres.Store the worker needs.
+type Store interface {
+	ListAPIKeysExpiringBefore(ctx context.Context, before time.Time, limit int) ([]postgres.APIKey, error)
+	ExpireAPIKey(ctx context.Context, orgID, apiKeyID uuid.UUID, version int64) (postgres.APIKey, error)
+	UpdateAPIKeyAnnotations(ctx context.Context, orgID, apiKeyID uuid.UUID, version int64, annotations map[string]any) (postgres.APIKey, error)
+	GetUserByID(ctx context.Context, orgID, userID uuid.UUID) (postgres.User, error)
+	GetServiceAccountByID(ctx context.Context, serviceAccountID uuid.UUID) (postgres.ServiceAccount, error)
+}
+
+// Config configures the worker.
+type Config struct {
+	Store    Store
+	Audit    audit.Emitter
+	Notifier Notifier
+	Logger   *zap.Logger
+	// Interval between scans.
+	Interval time.Duration
+	// LeadTimes before expires_at at which reminders are sent.
+	LeadTimes []time.Duration
+	// BatchSize caps keys handled per scan.
+	BatchSize int
+}
+
+// Worker scans for expiring keys.
+type Worker struct {
+	store     Store
+	audit     audit.Emitter
+	notifier  Notifier
+	logger    *zap.Logger
+	interval  time.Duration
+	leadTimes []time.Duration // sorted descending
+	batchSize int
+	now       func() time.Time
+}
+
+// NewWorker creates a worker from cfg, applying defaults for unset fields.
+func NewWorker(cfg Config) *Worker {
+	leads := make([]time.Duration, 0, len(cfg.LeadTimes))
+	for _, l := range cfg.LeadTimes {
+		if l > 0 {
+			leads = append(leads, l)
+		}
+	}
+	sort.Slice(leads, func(i, j int) bool { return leads[i] > leads[j] })
+
+	w := &Worker{
+		store:     cfg.Store,
+		audit:     cfg.Audit,
+		notifier:  cfg.Notifier,
+		logger:    cfg.Logger,
+		interval:  cfg.Interval,
+		leadTimes: leads,
+		batchSize: cfg.BatchSize,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	if w.logger == nil {
+		w.logger = zap.NewNop()
+	}
+	if w.audit == nil {
+		w.audit = audit.NewNoopEmitter()
+	}
+	if w.notifier == nil {
+		w.notifier = NewNotifier("", w.logger)
+	}
+	if w.interval <= 0 {
+		w.interval = 5 * time.Minute
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = 500
+	}
+	return w
+}
+
+// Run scans immediately and then every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("key expiry worker started",
+		zap.Duration("interval", w.interval),
+		zap.Int("lead_times", len(w.leadTimes)))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("key expiry scan failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			w.logger.Info("key expiry worker stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single scan.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	now := w.now()
+	horizon := now
+	if len(w.leadTimes) > 0 {
+		horizon = now.Add(w.leadTimes[0])
+	}
+	keys, err := w.store.ListAPIKeysExpiringBefore(ctx, horizon, w.batchSize)
+	if err != nil {
+		return err
+	}
+
+	var expired, reminded int
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !key.ExpiresAt.After(now) {
+			if w.expire(ctx, key) {
+				expired++
+			}
+			continue
+		}
+		if w.remind(ctx, key, now) {
+			reminded++
+		}
+	}
+	if expired > 0 || reminded > 0 {
+		w.logger.Info("key expiry scan complete",
+			zap.Int("scanned", len(keys)),
+			zap.Int("expired", expired),
+			zap.Int("reminded", reminded))
+	}
+	return nil
+}
+
+// expire transitions a past-due key and reports whether it did.
+func (w *Worker) expire(ctx context.Context, key postgres.APIKey) bool {
+	updated, err := w.store.ExpireAPIKey(ctx, key.OrgID, key.ID, key.Version)
+	if err != nil {
+		if !errors.Is(err, postgres.ErrOptimisticLock) {
+			w.logger.Warn("failed to expire API key", zap.Error(err), zap.String("api_key_id", key.ID.String()))
+		}
+		return false
+	}
+	metrics.RecordAPIKeyExpired()
+
+	event := audit.BuildEvent(key.OrgID, uuid.Nil, audit.ActorTypeSystem, audit.ActionAPIKeyExpire, audit.TargetTypeAPIKey, &key.ID)
+	event.Metadata = map[string]any{
+		"principal_type": string(key.PrincipalType),
+		"principal_id":   key.PrincipalID.String(),
+		"expires_at":     key.ExpiresAt.Format(time.RFC3339),
+	}
+	_ = w.audit.Emit(ctx, event)
+
+	notice := w.notice(ctx, updated, 0)
+	notice.Type = NoticeExpired
+	if err := w.notifier.Notify(ctx, notice); err != nil {
+		// The key is already expired; the notice is informational only
+		w.logger.Warn("failed to send key expired notice", zap.Error(err), zap.String("api_key_id", key.ID.String()))
+	}
+	return true
+}
+
+// remind sends the tightest unsent reminder that is due and reports whether it did.
+func (w *Worker) remind(ctx context.Context, key postgres.APIKey, now time.Time) bool {
+	remaining := key.ExpiresAt.Sub(now)
+	sent := sentReminders(key.Annotations)
+
+	var due []time.Duration
+	for _, lead := range w.leadTimes {
+		if remaining <= lead && !sent[lead.String()] {
+			due = append(due, lead)
+		}
+	}
+	if len(due) == 0 {
+		return false
+	}
+	// leadTimes is sorted descending, so the last due lead is the tightest
+	lead := due[len(due)-1]
+
+	notice := w.notice(ctx, key, lead)
+	notice.Type = NoticeExpiring
+	if err := w.notifier.Notify(ctx, notice); err != nil {
+		metrics.RecordAPIKeyExpiryReminder(lead.String(), "failed")
+		w.logger.Warn("failed to send key expiry reminder", zap.Error(err),
+			zap.String("api_key_id", key.ID.String()), zap.Duration("lead", lead))
+		return false
+	}
+	metrics.RecordAPIKeyExpiryReminder(lead.String(), "sent")
+
+	// Looser leads are skipped too: a key first seen at 2h left should not later get the 7d reminder
+	annotations := make(map[string]any, len(key.Annotations)+1)
+	for k, v := range key.Annotations {
+		annotations[k] = v
+	}
+	for _, l := range due {
+		sent[l.String()] = true
+	}
+	marked := make([]string, 0, len(sent))
+	for l := range sent {
+		marked = append(marked, l)
+	}
+	sort.Strings(marked)
+	annotations[remindersAnnotation] = marked
+	if _, err := w.store.UpdateAPIKeyAnnotations(ctx, key.OrgID, key.ID, key.Version, annotations); err != nil {
+		// The reminder may be repeated next pass; that is preferable to never sending it
+		w.logger.Warn("failed to record key expiry reminder", zap.Error(err), zap.String("api_key_id", key.ID.String()))
+	}
+
+	event := audit.BuildEvent(key.OrgID, uuid.Nil, audit.ActorTypeSystem, audit.ActionAPIKeyRemind, audit.TargetTypeAPIKey, &key.ID)
+	event.Metadata = map[string]any{
+		"lead":       lead.String(),
+		"expires_at": key.ExpiresAt.Format(time.RFC3339),
+		"recipient":  notice.RecipientEmail,
+	}
+	_ = w.audit.Emit(ctx, event)
+	return true
+}
+
+// notice builds the notification for key, resolving the owner's contact details.
+func (w *Worker) notice(ctx context.Context, key postgres.APIKey, lead time.Duration) Notice {
+	n := Notice{
+		OrgID:         key.OrgID.String(),
+		APIKeyID:      key.ID.String(),
+		Fingerprint:   key.Fingerprint,
+		PrincipalType: string(key.PrincipalType),
+		PrincipalID:   key.PrincipalID.String(),
+		ExpiresAt:     *key.ExpiresAt,
+		OccurredAt:    w.now(),
+	}
+	if lead > 0 {
+		n.Lead = lead.String()
+	}
+	if name, ok := key.Annotations["display_name"].(string); ok {
+		n.DisplayName = name
+	}
+	switch key.PrincipalType {
+	case postgres.PrincipalTypeUser:
+		if user, err := w.store.GetUserByID(ctx, key.OrgID, key.PrincipalID); err == nil {
+			n.RecipientEmail = user.Email
+			n.PrincipalName = user.DisplayName
+		}
+	case postgres.PrincipalTypeServiceAccount:
+		if sa, err := w.store.GetServiceAccountByID(ctx, key.PrincipalID); err == nil {
+			n.PrincipalName = sa.Name
+		}
+	}
+	return n
+}
+
+// sentReminders reads the lead times already notified for a key.
+func sentReminders(annotations map[string]any) map[string]bool {
+	sent := make(map[string]bool)
+	switch v := annotations[remindersAnnotation].(type) {
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				sent[s] = true
+			}
+		}
+	case []string:
+		for _, s := range v {
+			sent[s] = true
+		}
+	}
+	return sent
+}
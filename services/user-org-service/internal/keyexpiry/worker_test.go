@@ -0,0 +1,151 @@
+package keyexpiry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type fakeStore struct {
+	keys        []postgres.APIKey
+	expired     []uuid.UUID
+	annotations map[uuid.UUID]map[string]any
+}
+
+func (s *fakeStore) ListAPIKeysExpiringBefore(_ context.Context, before time.Time, _ int) ([]postgres.APIKey, error) {
+	var out []postgres.APIKey
+	for _, k := range s.keys {
+		if !k.ExpiresAt.After(before) {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) ExpireAPIKey(_ context.Context, _, apiKeyID uuid.UUID, _ int64) (postgres.APIKey, error) {
+	s.expired = append(s.expired, apiKeyID)
+	for _, k := range s.keys {
+		if k.ID == apiKeyID {
+			k.Status = "expired"
+			return k, nil
+		}
+	}
+	return postgres.APIKey{}, postgres.ErrNotFound
+}
+
+func (s *fakeStore) UpdateAPIKeyAnnotations(_ context.Context, _, apiKeyID uuid.UUID, _ int64, annotations map[string]any) (postgres.APIKey, error) {
+	if s.annotations == nil {
+		s.annotations = make(map[uuid.UUID]map[string]any)
+	}
+	s.annotations[apiKeyID] = annotations
+	return postgres.APIKey{ID: apiKeyID, Annotations: annotations}, nil
+}
+
+func (s *fakeStore) GetUserByID(_ context.Context, _, userID uuid.UUID) (postgres.User, error) {
+	return postgres.User{ID: userID, Email: "owner@example.com", DisplayName: "Owner"}, nil
+}
+
+func (s *fakeStore) GetServiceAccountByID(_ context.Context, id uuid.UUID) (postgres.ServiceAccount, error) {
+	return postgres.ServiceAccount{ID: id, Name: "ci"}, nil
+}
+
+type recordingNotifier struct {
+	notices []Notice
+	err     error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notice Notice) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+func newTestKey(expiresIn time.Duration, now time.Time, annotations map[string]any) postgres.APIKey {
+	exp := now.Add(expiresIn)
+	return postgres.APIKey{
+		ID:            uuid.New(),
+		OrgID:         uuid.New(),
+		PrincipalType: postgres.PrincipalTypeUser,
+		PrincipalID:   uuid.New(),
+		Status:        "active",
+		ExpiresAt:     &exp,
+		Annotations:   annotations,
+		Version:       1,
+	}
+}
+
+func newTestWorker(store Store, notifier Notifier, now time.Time) *Worker {
+	w := NewWorker(Config{
+		Store:     store,
+		Notifier:  notifier,
+		LeadTimes: []time.Duration{24 * time.Hour, 168 * time.Hour},
+	})
+	w.now = func() time.Time { return now }
+	return w
+}
+
+func TestRunOnceExpiresPastDueKeys(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := newTestKey(-time.Minute, now, nil)
+	store := &fakeStore{keys: []postgres.APIKey{key}}
+	notifier := &recordingNotifier{}
+
+	require.NoError(t, newTestWorker(store, notifier, now).RunOnce(context.Background()))
+
+	require.Equal(t, []uuid.UUID{key.ID}, store.expired)
+	require.Len(t, notifier.notices, 1)
+	require.Equal(t, NoticeExpired, notifier.notices[0].Type)
+	require.Equal(t, "owner@example.com", notifier.notices[0].RecipientEmail)
+}
+
+func TestRunOnceSendsTightestReminderOnce(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := newTestKey(2*time.Hour, now, map[string]any{"display_name": "ci"})
+	store := &fakeStore{keys: []postgres.APIKey{key}}
+	notifier := &recordingNotifier{}
+
+	require.NoError(t, newTestWorker(store, notifier, now).RunOnce(context.Background()))
+
+	require.Len(t, notifier.notices, 1)
+	require.Equal(t, NoticeExpiring, notifier.notices[0].Type)
+	require.Equal(t, "24h0m0s", notifier.notices[0].Lead)
+	require.Equal(t, "ci", notifier.notices[0].DisplayName)
+	require.Empty(t, store.expired)
+
+	annotations := store.annotations[key.ID]
+	require.Equal(t, "ci", annotations["display_name"])
+	require.Equal(t, []string{"168h0m0s", "24h0m0s"}, annotations[remindersAnnotation])
+}
+
+func TestRunOnceSkipsSentReminders(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Stored annotations come back from JSON as []any
+	key := newTestKey(72*time.Hour, now, map[string]any{remindersAnnotation: []any{"168h0m0s"}})
+	store := &fakeStore{keys: []postgres.APIKey{key}}
+	notifier := &recordingNotifier{}
+
+	require.NoError(t, newTestWorker(store, notifier, now).RunOnce(context.Background()))
+
+	require.Empty(t, notifier.notices)
+	require.Empty(t, store.annotations)
+}
+
+func TestRunOnceRetriesFailedReminders(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := newTestKey(100*time.Hour, now, nil)
+	store := &fakeStore{keys: []postgres.APIKey{key}}
+	notifier := &recordingNotifier{err: errors.New("webhook down")}
+
+	require.NoError(t, newTestWorker(store, notifier, now).RunOnce(context.Background()))
+
+	// Not marked as sent, so the next pass tries again
+	require.Empty(t, store.annotations)
+}
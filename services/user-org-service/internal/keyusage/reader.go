@@ -0,0 +1,134 @@
+// Package keyusage reads per-API-key usage summaries from Redis.
+//
+// Purpose:
+//
+//	analytics-service maintains a rolling summary for every API key it sees in
+//	usage events (request counts per hour, total spend, last error). This
+//	package reads that cache so key listings can flag stale or abusive keys
+//	without calling analytics on every request.
+//
+// Dependencies:
+//   - github.com/redis/go-redis/v9: Redis client (shared with the OAuth cache)
+//
+// Key Responsibilities:
+//   - Reader.Summaries: Batch-load usage summaries for a set of API keys
+//
+// Debugging Notes:
+//   - The Redis layout is written by analytics-service internal/keyusage; keep them in sync:
+//     keyusage:{id} (hash: total_requests, total_spend_cents, last_seen_at, last_error_at,
+//     last_error_code) and keyusage:{id}:h:{unix hour} (request counter, 25h TTL)
+//   - Keys with no traffic have no entry; Summaries omits them
+//   - With no Redis configured every lookup returns an empty result
+//
+// Thread Safety:
+//   - Reader is safe for concurrent use
+//
+// Error Handling:
+//   - Redis failures are returned wrapped; callers treat usage as best-effort
+package keyusage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const windowHours = 24
+
+// Summary is the recent usage of one API key.
+type Summary struct {
+	// Requests24h counts requests in the trailing 24 hours (hourly granularity).
+	Requests24h int64 `json:"requests24h"`
+	// TotalRequests counts every request seen while the summary was retained.
+	TotalRequests int64 `json:"totalRequests"`
+	// TotalSpendUSD is the estimated spend across TotalRequests.
+	TotalSpendUSD float64 `json:"totalSpendUsd"`
+	// LastSeenAt is the time of the most recent request.
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	// LastErrorCode and LastErrorAt describe the most recent failed request.
+	LastErrorCode string     `json:"lastErrorCode,omitempty"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+	// UpdatedAt is when the summary was read, so clients can judge staleness.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Reader loads summaries from Redis.
+type Reader struct {
+	client *redis.Client
+}
+
+// NewReader creates a reader. A nil client yields a reader that finds nothing.
+func NewReader(client *redis.Client) *Reader {
+	return &Reader{client: client}
+}
+
+// Summaries returns usage for the given keys, omitting keys without usage.
+func (r *Reader) Summaries(ctx context.Context, keyIDs []uuid.UUID) (map[uuid.UUID]Summary, error) {
+	out := make(map[uuid.UUID]Summary, len(keyIDs))
+	if r == nil || r.client == nil || len(keyIDs) == 0 {
+		return out, nil
+	}
+
+	now := time.Now().UTC()
+	currentHour := now.Unix() / 3600
+	pipe := r.client.Pipeline()
+	hashes := make([]*redis.MapStringStringCmd, len(keyIDs))
+	buckets := make([]*redis.SliceCmd, len(keyIDs))
+	for i, id := range keyIDs {
+		hashes[i] = pipe.HGetAll(ctx, summaryKey(id))
+		bucketKeys := make([]string, windowHours)
+		for h := 0; h < windowHours; h++ {
+			bucketKeys[h] = bucketKey(id, currentHour-int64(h))
+		}
+		buckets[i] = pipe.MGet(ctx, bucketKeys...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("keyusage: read summaries: %w", err)
+	}
+
+	for i, id := range keyIDs {
+		fields, _ := hashes[i].Result()
+		if len(fields) == 0 {
+			continue
+		}
+		s := Summary{UpdatedAt: now}
+		s.TotalRequests, _ = strconv.ParseInt(fields["total_requests"], 10, 64)
+		if cents, err := strconv.ParseFloat(fields["total_spend_cents"], 64); err == nil {
+			s.TotalSpendUSD = cents / 100
+		}
+		s.LastSeenAt = parseUnix(fields["last_seen_at"])
+		s.LastErrorAt = parseUnix(fields["last_error_at"])
+		s.LastErrorCode = fields["last_error_code"]
+
+		values, _ := buckets[i].Result()
+		for _, v := range values {
+			if str, ok := v.(string); ok {
+				n, _ := strconv.ParseInt(str, 10, 64)
+				s.Requests24h += n
+			}
+		}
+		out[id] = s
+	}
+	return out, nil
+}
+
+func summaryKey(id uuid.UUID) string {
+	return "keyusage:" + id.String()
+}
+
+func bucketKey(id uuid.UUID, unixHour int64) string {
+	return "keyusage:" + id.String() + ":h:" + strconv.FormatInt(unixHour, 10)
+}
+
+func parseUnix(raw string) *time.Time {
+	secs, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || secs <= 0 {
+		return nil
+	}
+	t := time.Unix(secs, 0).UTC()
+	return &t
+}
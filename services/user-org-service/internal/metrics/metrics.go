@@ -121,6 +121,27 @@ var (
 		},
 	)
 
+	// APIKeysExpiredTotal counts API keys transitioned to expired by the expiry worker.
+	APIKeysExpiredTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "apikeys",
+			Name:      "expired_total",
+			Help:      "Total number of API keys transitioned to expired",
+		},
+	)
+
+	// APIKeyExpiryRemindersTotal counts expiry reminders by lead time and result.
+	APIKeyExpiryRemindersTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "apikeys",
+			Name:      "expiry_reminders_total",
+			Help:      "Total number of API key expiry reminders by lead time and result",
+		},
+		[]string{"lead", "result"}, // lead: e.g. 168h0m0s; result: sent, failed
+	)
+
 	// OIDCLoginAttemptsTotal counts OIDC login attempts by provider.
 	OIDCLoginAttemptsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -153,6 +174,140 @@ var (
 		},
 		[]string{"action"}, // action: initiate, verify, reset
 	)
+
+	// PasswordResetsTotal counts password reset flow outcomes; completed over
+	// requested is the completion rate.
+	PasswordResetsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "password_resets_total",
+			Help:      "Total number of password reset flow outcomes",
+		},
+		[]string{"outcome"}, // outcome: requested, rate_limited, link_sent, link_failed, verified, invalid_token, expired_token, completed
+	)
+
+	// MagicLinksTotal counts passwordless login flow outcomes; completed over
+	// requested is the completion rate.
+	MagicLinksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "magic_links_total",
+			Help:      "Total number of magic link login flow outcomes",
+		},
+		[]string{"outcome"}, // outcome: requested, rate_limited, limiter_unavailable, disabled, link_sent, link_failed, invalid_token, expired_token, completed
+	)
+
+	// PasswordResetCompletionSeconds measures time from link issuance to a completed reset.
+	PasswordResetCompletionSeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "password_reset_completion_seconds",
+			Help:      "Time from password reset link issuance to completed reset",
+			Buckets:   []float64{30, 60, 120, 300, 600, 1800, 3600, 7200, 21600, 86400},
+		},
+	)
+
+	// AccountDeletionsTotal counts self-service account deletion outcomes.
+	AccountDeletionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "users",
+			Name:      "account_deletions_total",
+			Help:      "Total number of self-service account deletion outcomes",
+		},
+		[]string{"outcome"}, // outcome: scheduled, cancelled, purged, blocked, failed
+	)
+
+	// AuditOutboxEventsTotal counts audit outbox entries by result.
+	AuditOutboxEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "audit",
+			Name:      "outbox_events_total",
+			Help:      "Total number of audit outbox entries by result",
+		},
+		[]string{"result"}, // result: delivered, failed, replayed
+	)
+
+	// AuditOutboxLagSeconds observes how long entries waited before delivery.
+	AuditOutboxLagSeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "audit",
+			Name:      "outbox_lag_seconds",
+			Help:      "Time from audit outbox insert to delivery",
+			Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 300, 900},
+		},
+	)
+
+	// OptimisticLockConflictsTotal counts optimistic lock conflicts by table.
+	OptimisticLockConflictsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "optimistic_lock_conflicts_total",
+			Help:      "Total number of optimistic lock conflicts by table",
+		},
+		[]string{"table"},
+	)
+
+	// OptimisticLockRetriesTotal counts retried conflicts by operation and outcome.
+	OptimisticLockRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "optimistic_lock_retries_total",
+			Help:      "Total number of operations retried after an optimistic lock conflict",
+		},
+		[]string{"operation", "outcome"}, // outcome: resolved, exhausted
+	)
+
+	// RetentionRowsTotal counts soft-deleted rows handled by the retention worker.
+	RetentionRowsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "retention",
+			Name:      "rows_total",
+			Help:      "Total number of soft-deleted rows handled by the retention worker",
+		},
+		[]string{"entity", "result"}, // result: anonymized, deleted, planned, skipped, failed
+	)
+
+	// SecurityEventsTotal counts detected security events by type.
+	SecurityEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "security",
+			Name:      "events_total",
+			Help:      "Total number of security events detected by type",
+		},
+		[]string{"type"}, // type: account.repeated_lockouts, api_key.new_asn, etc.
+	)
+
+	// SecurityEventDeliveriesTotal counts security event deliveries by sink and result.
+	SecurityEventDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "security",
+			Name:      "event_deliveries_total",
+			Help:      "Total number of security event deliveries by sink and result",
+		},
+		[]string{"sink", "result"}, // sink: store, kafka, log, webhook; result: success, failure
+	)
+
+	// ServiceAuthTotal counts service-to-service authentication outcomes.
+	ServiceAuthTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "service_auth",
+			Name:      "requests_total",
+			Help:      "Total number of calls from other services by authentication result",
+		},
+		[]string{"result"}, // result: authenticated, missing_token, invalid_token, not_allowed, error
+	)
 )
 
 // RecordAuthSuccess records a successful authentication attempt.
@@ -198,6 +353,16 @@ func RecordAPIKeyRevoked() {
 	APIKeysRevokedTotal.Inc()
 }
 
+// RecordAPIKeyExpired records an API key transitioned to expired.
+func RecordAPIKeyExpired() {
+	APIKeysExpiredTotal.Inc()
+}
+
+// RecordAPIKeyExpiryReminder records an expiry reminder attempt.
+func RecordAPIKeyExpiryReminder(lead, result string) {
+	APIKeyExpiryRemindersTotal.WithLabelValues(lead, result).Inc()
+}
+
 // RecordOIDCLoginAttempt records an OIDC login attempt.
 func RecordOIDCLoginAttempt(provider string) {
 	OIDCLoginAttemptsTotal.WithLabelValues(provider).Inc()
@@ -219,3 +384,59 @@ func RecordOIDCCallbackFailure(provider, reason string) {
 func RecordRecoveryAttempt(action string) {
 	RecoveryAttemptsTotal.WithLabelValues(action).Inc()
 }
+
+// RecordPasswordReset records a password reset flow outcome.
+func RecordPasswordReset(outcome string) {
+	PasswordResetsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordPasswordResetCompleted records a completed reset and how long after issuance it happened.
+func RecordPasswordResetCompleted(sinceIssued float64) {
+	PasswordResetsTotal.WithLabelValues("completed").Inc()
+	PasswordResetCompletionSeconds.Observe(sinceIssued)
+}
+
+// RecordMagicLink records a magic link login flow outcome.
+func RecordMagicLink(outcome string) {
+	MagicLinksTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordAccountDeletion records an account deletion outcome.
+func RecordAccountDeletion(outcome string) {
+	AccountDeletionsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordAuditOutbox records audit outbox entries with the given result.
+func RecordAuditOutbox(result string, count int) {
+	AuditOutboxEventsTotal.WithLabelValues(result).Add(float64(count))
+}
+
+// RecordOptimisticLockConflict records an optimistic lock conflict on table.
+func RecordOptimisticLockConflict(table string) {
+	OptimisticLockConflictsTotal.WithLabelValues(table).Inc()
+}
+
+// RecordOptimisticLockRetry records the outcome of an operation retried after a conflict.
+func RecordOptimisticLockRetry(operation, outcome string) {
+	OptimisticLockRetriesTotal.WithLabelValues(operation, outcome).Inc()
+}
+
+// RecordRetention records a soft-deleted row handled by the retention worker.
+func RecordRetention(entity, result string) {
+	RetentionRowsTotal.WithLabelValues(entity, result).Inc()
+}
+
+// RecordSecurityEvent records a detected security event.
+func RecordSecurityEvent(eventType string) {
+	SecurityEventsTotal.WithLabelValues(eventType).Inc()
+}
+
+// RecordSecurityEventDelivery records a security event delivery attempt.
+func RecordSecurityEventDelivery(sink, result string) {
+	SecurityEventDeliveriesTotal.WithLabelValues(sink, result).Inc()
+}
+
+// RecordServiceAuth records the outcome of authenticating a call from another service.
+func RecordServiceAuth(result string) {
+	ServiceAuthTotal.WithLabelValues(result).Inc()
+}
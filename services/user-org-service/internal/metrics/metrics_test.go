@@ -35,6 +35,12 @@ func TestMetricsRegistration(t *testing.T) {
 	if APIKeysRevokedTotal == nil {
 		t.Error("APIKeysRevokedTotal metric not registered")
 	}
+	if APIKeysExpiredTotal == nil {
+		t.Error("APIKeysExpiredTotal metric not registered")
+	}
+	if APIKeyExpiryRemindersTotal == nil {
+		t.Error("APIKeyExpiryRemindersTotal metric not registered")
+	}
 	if OIDCLoginAttemptsTotal == nil {
 		t.Error("OIDCLoginAttemptsTotal metric not registered")
 	}
@@ -0,0 +1,119 @@
+// Package notify posts notifications to webhooks for delivery.
+//
+// Purpose:
+//
+//	Onboarding welcomes, signup verification, password reset and magic
+//	links, API key expiry reminders and drift notices are all delivered the
+//	same way: the service posts a JSON event to a configured webhook and the
+//	receiver sends the email. Without a webhook the event is logged instead.
+//	Security events post through Webhook directly.
+//
+// Key Responsibilities:
+//   - Notifier.Send: Post synchronously, for workers that record delivery
+//   - Notifier.SendAsync: Post in the background, for request handlers
+//   - Webhook.Post: One JSON POST; any non-2xx response is an error
+//
+// Debugging Notes:
+//   - Log-only delivery writes "<message> (no webhook configured)"; callers
+//     must not pass secrets such as links or tokens as log fields
+//   - Background posts are bounded by the 5s client timeout and carry no
+//     request context
+//
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// Timeout bounds each webhook request.
+const Timeout = 5 * time.Second
+
+// Webhook posts JSON payloads to a URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook returns a webhook for url, or nil when url is empty.
+func NewWebhook(url string) *Webhook {
+	if url == "" {
+		return nil
+	}
+	return &Webhook{url: url, client: &http.Client{Timeout: Timeout, Transport: &requestid.Transport{}}}
+}
+
+// Post sends payload as JSON; any non-2xx response is an error.
+func (w *Webhook) Post(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Notifier delivers notifications to a webhook, or logs them when none is
+// configured.
+type Notifier struct {
+	webhook *Webhook
+	logger  *zap.Logger
+}
+
+// New returns a notifier posting to url, or logging when url is empty.
+func New(url string, logger *zap.Logger) *Notifier {
+	return &Notifier{webhook: NewWebhook(url), logger: logger}
+}
+
+// Send posts payload. Without a webhook it logs message with fields and
+// returns nil.
+func (n *Notifier) Send(ctx context.Context, payload any, message string, fields ...zap.Field) error {
+	if n.webhook == nil {
+		n.log(message, fields)
+		return nil
+	}
+	return n.webhook.Post(ctx, payload)
+}
+
+// SendAsync posts payload in the background and passes the result to done,
+// if set. Without a webhook it logs like Send and done is not called.
+func (n *Notifier) SendAsync(payload any, done func(error), message string, fields ...zap.Field) {
+	if n.webhook == nil {
+		n.log(message, fields)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		err := n.webhook.Post(ctx, payload)
+		if done != nil {
+			done(err)
+		}
+	}()
+}
+
+// log stands in for email delivery until a webhook is configured.
+func (n *Notifier) log(message string, fields []zap.Field) {
+	// TODO: Send email directly once an email provider is integrated
+	n.logger.Info(message+" (no webhook configured)", fields...)
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNotifierPostsToWebhook(t *testing.T) {
+	received := make(chan map[string]string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer srv.Close()
+
+	notifier := New(srv.URL, zap.NewNop())
+	require.NoError(t, notifier.Send(context.Background(), map[string]string{"type": "sync"}, "unused"))
+	require.Equal(t, "sync", (<-received)["type"])
+
+	done := make(chan error, 1)
+	notifier.SendAsync(map[string]string{"type": "async"}, func(err error) { done <- err }, "unused")
+	require.NoError(t, <-done)
+	require.Equal(t, "async", (<-received)["type"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	require.Error(t, New(failing.URL, zap.NewNop()).Send(context.Background(), map[string]string{}, "unused"))
+}
+
+func TestNotifierLogsWithoutWebhook(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	notifier := New("", zap.New(core))
+
+	require.NoError(t, notifier.Send(context.Background(), nil, "expiry notice", zap.String("api_key_id", "key-1")))
+	notifier.SendAsync(nil, func(error) { t.Fatal("done must not be called without a webhook") }, "welcome notification")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	require.Equal(t, "expiry notice (no webhook configured)", entries[0].Message)
+	require.Equal(t, "key-1", entries[0].ContextMap()["api_key_id"])
+	require.Equal(t, "welcome notification (no webhook configured)", entries[1].Message)
+	require.Nil(t, NewWebhook(""))
+}
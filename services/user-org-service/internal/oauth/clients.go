@@ -0,0 +1,188 @@
+// Package oauth (clients.go) validates and maps registered OAuth2 clients.
+//
+// Purpose:
+//
+//	Clients registered through the client management API are stored in
+//	oauth_clients and resolved by Store.GetClient after the statically
+//	configured clients. This file holds the registration rules shared by
+//	the HTTP handlers and the store.
+//
+// Debugging Notes:
+//   - Confidential clients get a generated secret; only its bcrypt hash is
+//     stored and the plaintext is returned once
+//   - Public clients have no secret and may not use client_credentials
+//   - authorization_code requires at least one redirect URI; redirect URIs
+//     must be https, except http on localhost for development
+//   - Disabled or deleted clients are reported as not found to Fosite
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ory/fosite"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// Registered client states.
+const (
+	ClientStatusActive   = "active"
+	ClientStatusDisabled = "disabled"
+)
+
+// Registration limits.
+const (
+	maxClientRedirectURIs = 20
+	maxClientScopes       = 50
+)
+
+// SupportedGrantTypes are the grant types a registered client may be allowed.
+var SupportedGrantTypes = []string{
+	"authorization_code",
+	"refresh_token",
+	"password",
+	"client_credentials",
+	GrantTypeDeviceCode,
+}
+
+// ErrInvalidClientRegistration is wrapped by ClientRegistration.Normalize errors.
+var ErrInvalidClientRegistration = errors.New("invalid client registration")
+
+// ClientRegistration describes a client to register or update.
+type ClientRegistration struct {
+	Name          string
+	Public        bool
+	RedirectURIs  []string
+	GrantTypes    []string
+	ResponseTypes []string
+	Scopes        []string
+}
+
+// Normalize trims and de-duplicates the registration, applies defaults and
+// validates it.
+func (r *ClientRegistration) Normalize() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidClientRegistration)
+	}
+	r.RedirectURIs = dedupe(r.RedirectURIs)
+	r.GrantTypes = dedupe(r.GrantTypes)
+	r.ResponseTypes = dedupe(r.ResponseTypes)
+	r.Scopes = dedupe(r.Scopes)
+
+	if len(r.GrantTypes) == 0 {
+		r.GrantTypes = []string{"authorization_code", "refresh_token"}
+	}
+	for _, grant := range r.GrantTypes {
+		if !containsString(SupportedGrantTypes, grant) {
+			return fmt.Errorf("%w: unsupported grant type %q", ErrInvalidClientRegistration, grant)
+		}
+	}
+	if r.Public && containsString(r.GrantTypes, "client_credentials") {
+		return fmt.Errorf("%w: public clients cannot use client_credentials", ErrInvalidClientRegistration)
+	}
+
+	if len(r.RedirectURIs) > maxClientRedirectURIs {
+		return fmt.Errorf("%w: at most %d redirect URIs are allowed", ErrInvalidClientRegistration, maxClientRedirectURIs)
+	}
+	for _, raw := range r.RedirectURIs {
+		if err := validateRedirectURI(raw); err != nil {
+			return err
+		}
+	}
+	if containsString(r.GrantTypes, "authorization_code") && len(r.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: authorization_code requires a redirect URI", ErrInvalidClientRegistration)
+	}
+
+	if len(r.ResponseTypes) == 0 {
+		r.ResponseTypes = []string{"token"}
+		if containsString(r.GrantTypes, "authorization_code") {
+			r.ResponseTypes = []string{"code"}
+		}
+	}
+	if len(r.Scopes) == 0 {
+		r.Scopes = []string{"openid", "profile", "email"}
+	}
+	if len(r.Scopes) > maxClientScopes {
+		return fmt.Errorf("%w: at most %d scopes are allowed", ErrInvalidClientRegistration, maxClientScopes)
+	}
+	for _, scope := range r.Scopes {
+		if strings.ContainsAny(scope, " \t\"\\") {
+			return fmt.Errorf("%w: invalid scope %q", ErrInvalidClientRegistration, scope)
+		}
+	}
+	return nil
+}
+
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+		return fmt.Errorf("%w: redirect URI %q must be an absolute URL without a fragment", ErrInvalidClientRegistration, raw)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if host := u.Hostname(); host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: redirect URI %q must use https (http is allowed for localhost)", ErrInvalidClientRegistration, raw)
+}
+
+// GenerateClientSecret returns a new random client secret.
+func GenerateClientSecret() (string, error) {
+	return randomToken(32)
+}
+
+// HashClientSecret hashes secret with the provider's client secret hasher.
+func HashClientSecret(ctx context.Context, cfg *fosite.Config, secret string) (string, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	hash, err := cfg.GetSecretsHasher(ctx).Hash(ctx, []byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("hash client secret: %w", err)
+	}
+	return string(hash), nil
+}
+
+// clientFromRecord converts a stored client to a Fosite client.
+func clientFromRecord(record postgres.OAuthClient) *fosite.DefaultClient {
+	client := &fosite.DefaultClient{
+		ID:            record.ID,
+		RedirectURIs:  record.RedirectURIs,
+		GrantTypes:    record.GrantTypes,
+		ResponseTypes: record.ResponseTypes,
+		Scopes:        record.Scopes,
+		Public:        record.Public,
+	}
+	if record.SecretHash != nil {
+		client.Secret = []byte(*record.SecretHash)
+	}
+	return client
+}
+
+func dedupe(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !containsString(out, value) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
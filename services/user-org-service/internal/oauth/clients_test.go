@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+func TestClientRegistrationNormalize(t *testing.T) {
+	reg := ClientRegistration{
+		Name:         " Reporting ",
+		RedirectURIs: []string{"https://reports.example.com/cb", "https://reports.example.com/cb", "http://localhost:3000/cb"},
+	}
+	require.NoError(t, reg.Normalize())
+	require.Equal(t, "Reporting", reg.Name)
+	require.Equal(t, []string{"https://reports.example.com/cb", "http://localhost:3000/cb"}, reg.RedirectURIs)
+	require.Equal(t, []string{"authorization_code", "refresh_token"}, reg.GrantTypes)
+	require.Equal(t, []string{"code"}, reg.ResponseTypes)
+	require.Equal(t, []string{"openid", "profile", "email"}, reg.Scopes)
+
+	cases := map[string]ClientRegistration{
+		"missing name":              {RedirectURIs: []string{"https://a.example.com/cb"}},
+		"unsupported grant":         {Name: "x", GrantTypes: []string{"implicit"}},
+		"public client credentials": {Name: "x", Public: true, GrantTypes: []string{"client_credentials"}},
+		"code without redirect":     {Name: "x", GrantTypes: []string{"authorization_code"}},
+		"plain http redirect":       {Name: "x", RedirectURIs: []string{"http://app.example.com/cb"}},
+		"relative redirect":         {Name: "x", RedirectURIs: []string{"/cb"}},
+		"scope with space":          {Name: "x", GrantTypes: []string{"client_credentials"}, Scopes: []string{"a b"}},
+	}
+	for name, reg := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, reg.Normalize(), ErrInvalidClientRegistration)
+		})
+	}
+}
+
+func TestClientFromRecord(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	hash, err := HashClientSecret(ctx, cfg, "s3cret")
+	require.NoError(t, err)
+
+	client := clientFromRecord(postgres.OAuthClient{
+		ID:         "reporting-app",
+		SecretHash: &hash,
+		GrantTypes: []string{"client_credentials"},
+		Scopes:     []string{"reports.read"},
+	})
+	require.False(t, client.IsPublic())
+	require.NoError(t, cfg.GetSecretsHasher(ctx).Compare(ctx, client.GetHashedSecret(), []byte("s3cret")))
+	require.True(t, client.GetGrantTypes().Has("client_credentials"))
+
+	public := clientFromRecord(postgres.OAuthClient{ID: "spa", Public: true})
+	require.True(t, public.IsPublic())
+	require.Empty(t, public.GetHashedSecret())
+}
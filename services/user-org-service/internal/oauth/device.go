@@ -0,0 +1,508 @@
+// Package oauth (device.go) implements the OAuth 2.0 device authorization
+// grant (RFC 8628).
+//
+// Purpose:
+//
+//	CLI tools such as admin-cli cannot keep a client secret and have no
+//	browser to redirect. They request a device code and a short user code,
+//	show the user the verification URI, and poll the token endpoint while the
+//	user approves the request in the web console. Once approved the poll
+//	returns ordinary access and refresh tokens for the approving user.
+//
+// Dependencies:
+//   - github.com/ory/fosite: token endpoint handler and token strategies
+//   - DeviceStore: pending authorizations (Redis, or in-memory without Redis)
+//
+// Key Responsibilities:
+//   - DeviceFlow.Authorize issues device and user codes (RFC 8628 section 3.2)
+//   - DeviceFlow.Approve/Deny record the user's decision
+//   - DeviceCodeGrantHandler answers token polls (RFC 8628 section 3.5)
+//
+// Debugging Notes:
+//   - Only clients with the device_code grant type may use the flow; the
+//     configured device client is public (no secret)
+//   - Polls faster than the interval get slow_down and the interval grows by 5s
+//   - A device code is deleted once it has been exchanged for tokens; when
+//     two polls race, only the one whose delete succeeds gets tokens
+//   - Writes are compare-and-set on DeviceAuthorization.Version, so a poll
+//     never overwrites a concurrent approval or denial
+//   - Granted scopes are the requested scopes the approving user holds;
+//     request offline_access to also get a refresh token
+//
+// Thread Safety:
+//   - DeviceFlow and the handler are safe for concurrent use; the store
+//     implementations synchronize their own state
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/x/errorsx"
+)
+
+// GrantTypeDeviceCode is the RFC 8628 grant type polled at the token endpoint.
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Device authorization defaults.
+const (
+	DefaultDeviceCodeLifespan = 10 * time.Minute
+	DefaultDevicePollInterval = 5 * time.Second
+	slowDownIncrement         = 5 * time.Second
+)
+
+// userCodeAlphabet avoids vowels and look-alike characters (RFC 8628 section 6.1).
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// Device authorization states.
+const (
+	DeviceStatusPending  = "pending"
+	DeviceStatusApproved = "approved"
+	DeviceStatusDenied   = "denied"
+)
+
+// RFC 8628 token endpoint errors.
+var (
+	ErrAuthorizationPending = &fosite.RFC6749Error{
+		ErrorField:       "authorization_pending",
+		DescriptionField: "The authorization request is still pending as the end user hasn't yet completed the user-interaction steps.",
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrSlowDown = &fosite.RFC6749Error{
+		ErrorField:       "slow_down",
+		DescriptionField: "The authorization request is still pending and polling should slow down.",
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrExpiredToken = &fosite.RFC6749Error{
+		ErrorField:       "expired_token",
+		DescriptionField: "The device code has expired; start a new device authorization request.",
+		CodeField:        http.StatusBadRequest,
+	}
+)
+
+// ErrDeviceCodeNotFound is returned for unknown, expired or already used codes.
+var ErrDeviceCodeNotFound = errors.New("device authorization not found")
+
+// ErrDeviceAuthorizationChanged is returned by CompareAndSetDeviceAuthorization
+// when the stored authorization was changed since it was read.
+var ErrDeviceAuthorizationChanged = errors.New("device authorization changed concurrently")
+
+// deviceUpdateAttempts bounds Approve and Deny retries after a concurrent poll.
+const deviceUpdateAttempts = 3
+
+// DeviceAuthorization is a pending or decided device authorization request.
+type DeviceAuthorization struct {
+	ClientID        string    `json:"client_id"`
+	UserCode        string    `json:"user_code"`
+	RequestedScopes []string  `json:"requested_scopes,omitempty"`
+	GrantedScopes   []string  `json:"granted_scopes,omitempty"`
+	Status          string    `json:"status"`
+	UserID          string    `json:"user_id,omitempty"`
+	OrgID           string    `json:"org_id,omitempty"`
+	Interval        int64     `json:"interval"` // Minimum seconds between polls
+	ExpiresAt       time.Time `json:"expires_at"`
+	LastPolledAt    time.Time `json:"last_polled_at,omitempty"`
+	// Version increases with every write; see CompareAndSetDeviceAuthorization
+	Version int64 `json:"version"`
+}
+
+// DeviceStore persists device authorizations, keyed by a hash of the device
+// code and indexed by user code. Entries expire at ExpiresAt.
+//
+// Polls, approvals and denials race: CompareAndSetDeviceAuthorization stores
+// auth only if the stored Version still equals auth.Version, returning the
+// stored copy with the next Version, or ErrDeviceAuthorizationChanged.
+// DeleteDeviceAuthorization reports whether this call removed the entry, so
+// exactly one poll consumes an approved code.
+type DeviceStore interface {
+	CreateDeviceAuthorization(ctx context.Context, deviceCodeHash string, auth DeviceAuthorization) error
+	GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error)
+	GetDeviceCodeHashByUserCode(ctx context.Context, userCode string) (string, error)
+	CompareAndSetDeviceAuthorization(ctx context.Context, deviceCodeHash string, auth DeviceAuthorization) (DeviceAuthorization, error)
+	DeleteDeviceAuthorization(ctx context.Context, deviceCodeHash string) (bool, error)
+}
+
+// DeviceAuthorizationResponse is the RFC 8628 section 3.2 response.
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceFlowConfig configures a DeviceFlow.
+type DeviceFlowConfig struct {
+	Store DeviceStore
+	// VerificationURI is the console page where users enter the user code.
+	VerificationURI string
+	Lifespan        time.Duration
+	Interval        time.Duration
+}
+
+// DeviceFlow issues and decides device authorizations. NewProvider attaches
+// the client registry and registers its token endpoint handler.
+type DeviceFlow struct {
+	store           DeviceStore
+	verificationURI string
+	lifespan        time.Duration
+	interval        time.Duration
+	clients         fosite.ClientManager
+}
+
+// NewDeviceFlow creates a device flow, applying default lifespan and interval.
+func NewDeviceFlow(cfg DeviceFlowConfig) *DeviceFlow {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryDeviceStore()
+	}
+	if cfg.Lifespan <= 0 {
+		cfg.Lifespan = DefaultDeviceCodeLifespan
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultDevicePollInterval
+	}
+	return &DeviceFlow{
+		store:           cfg.Store,
+		verificationURI: cfg.VerificationURI,
+		lifespan:        cfg.Lifespan,
+		interval:        cfg.Interval,
+	}
+}
+
+// Authorize starts a device authorization for clientID (RFC 8628 section 3.1).
+func (f *DeviceFlow) Authorize(ctx context.Context, clientID string, scopes []string) (DeviceAuthorizationResponse, error) {
+	if f.clients == nil {
+		return DeviceAuthorizationResponse{}, errorsx.WithStack(fosite.ErrServerError.WithHint("The device flow is not attached to a provider."))
+	}
+	client, err := f.clients.GetClient(ctx, clientID)
+	if err != nil {
+		return DeviceAuthorizationResponse{}, errorsx.WithStack(fosite.ErrInvalidClient.WithWrap(err))
+	}
+	if !client.GetGrantTypes().Has(GrantTypeDeviceCode) {
+		return DeviceAuthorizationResponse{}, errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The client is not allowed to use the device authorization grant."))
+	}
+	for _, scope := range scopes {
+		if !fosite.ExactScopeStrategy(client.GetScopes(), scope) {
+			return DeviceAuthorizationResponse{}, errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	deviceCode, err := randomToken(32)
+	if err != nil {
+		return DeviceAuthorizationResponse{}, err
+	}
+	userCode, err := newUserCode()
+	if err != nil {
+		return DeviceAuthorizationResponse{}, err
+	}
+	auth := DeviceAuthorization{
+		ClientID:        clientID,
+		UserCode:        userCode,
+		RequestedScopes: scopes,
+		Status:          DeviceStatusPending,
+		Interval:        int64(f.interval / time.Second),
+		ExpiresAt:       time.Now().UTC().Add(f.lifespan),
+	}
+	if err := f.store.CreateDeviceAuthorization(ctx, hashDeviceCode(deviceCode), auth); err != nil {
+		return DeviceAuthorizationResponse{}, err
+	}
+
+	resp := DeviceAuthorizationResponse{
+		DeviceCode:      deviceCode,
+		UserCode:        userCode,
+		VerificationURI: f.verificationURI,
+		ExpiresIn:       int64(f.lifespan / time.Second),
+		Interval:        auth.Interval,
+	}
+	if f.verificationURI != "" {
+		resp.VerificationURIComplete = f.verificationURI + "?user_code=" + url.QueryEscape(userCode)
+	}
+	return resp, nil
+}
+
+// Lookup returns the pending authorization for a user code.
+func (f *DeviceFlow) Lookup(ctx context.Context, userCode string) (DeviceAuthorization, error) {
+	_, auth, err := f.byUserCode(ctx, userCode)
+	return auth, err
+}
+
+// Approve grants the authorization for userCode to the user. Granted scopes
+// are the requested ones (or all the client's when none were requested)
+// that the approving user also holds, plus offline access for a refresh token.
+func (f *DeviceFlow) Approve(ctx context.Context, userCode, userID, orgID string, userScopes []string) (DeviceAuthorization, error) {
+	return f.decide(ctx, userCode, func(auth *DeviceAuthorization) {
+		requested := auth.RequestedScopes
+		if len(requested) == 0 && f.clients != nil {
+			if client, err := f.clients.GetClient(ctx, auth.ClientID); err == nil {
+				requested = client.GetScopes()
+			}
+		}
+		granted := make([]string, 0, len(requested))
+		for _, scope := range requested {
+			if scope == "offline" || scope == "offline_access" || fosite.ExactScopeStrategy(userScopes, scope) {
+				granted = append(granted, scope)
+			}
+		}
+		auth.Status = DeviceStatusApproved
+		auth.UserID = userID
+		auth.OrgID = orgID
+		auth.GrantedScopes = granted
+	})
+}
+
+// Deny rejects the authorization for userCode.
+func (f *DeviceFlow) Deny(ctx context.Context, userCode string) error {
+	_, err := f.decide(ctx, userCode, func(auth *DeviceAuthorization) {
+		auth.Status = DeviceStatusDenied
+	})
+	return err
+}
+
+// decide applies a decision to the pending authorization for userCode,
+// re-reading it when a concurrent poll changed it first.
+func (f *DeviceFlow) decide(ctx context.Context, userCode string, apply func(auth *DeviceAuthorization)) (DeviceAuthorization, error) {
+	for attempt := 1; ; attempt++ {
+		hash, auth, err := f.byUserCode(ctx, userCode)
+		if err != nil {
+			return DeviceAuthorization{}, err
+		}
+		apply(&auth)
+		stored, err := f.store.CompareAndSetDeviceAuthorization(ctx, hash, auth)
+		if errors.Is(err, ErrDeviceAuthorizationChanged) && attempt < deviceUpdateAttempts {
+			continue
+		}
+		return stored, err
+	}
+}
+
+// byUserCode loads a pending authorization by its user code.
+func (f *DeviceFlow) byUserCode(ctx context.Context, userCode string) (string, DeviceAuthorization, error) {
+	hash, err := f.store.GetDeviceCodeHashByUserCode(ctx, NormalizeUserCode(userCode))
+	if err != nil {
+		return "", DeviceAuthorization{}, err
+	}
+	auth, err := f.store.GetDeviceAuthorization(ctx, hash)
+	if err != nil {
+		return "", DeviceAuthorization{}, err
+	}
+	if auth.Status != DeviceStatusPending || time.Now().After(auth.ExpiresAt) {
+		return "", DeviceAuthorization{}, ErrDeviceCodeNotFound
+	}
+	return hash, auth, nil
+}
+
+// NormalizeUserCode uppercases a user code and restores its dash, so users
+// may type it in any case with or without separators.
+func NormalizeUserCode(userCode string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(userCode) {
+		if strings.ContainsRune(userCodeAlphabet, r) {
+			b.WriteRune(r)
+		}
+	}
+	code := b.String()
+	if len(code) == 8 {
+		return code[:4] + "-" + code[4:]
+	}
+	return code
+}
+
+// newUserCode returns an 8 character user code formatted XXXX-XXXX.
+func newUserCode() (string, error) {
+	// Reject bytes past the largest multiple of the alphabet size to avoid bias
+	limit := byte(256 / len(userCodeAlphabet) * len(userCodeAlphabet))
+	code := make([]byte, 0, 9)
+	buf := make([]byte, 16)
+	for len(code) < 9 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if b >= limit || len(code) == 9 {
+				continue
+			}
+			if len(code) == 4 {
+				code = append(code, '-')
+			}
+			code = append(code, userCodeAlphabet[int(b)%len(userCodeAlphabet)])
+		}
+	}
+	return string(code), nil
+}
+
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashDeviceCode keeps raw device codes out of storage.
+func hashDeviceCode(deviceCode string) string {
+	sum := sha256.Sum256([]byte(deviceCode))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ fosite.TokenEndpointHandler = (*DeviceCodeGrantHandler)(nil)
+
+// DeviceCodeGrantHandler exchanges approved device codes for tokens at the
+// token endpoint (RFC 8628 section 3.4).
+type DeviceCodeGrantHandler struct {
+	*oauth2.HandleHelper
+	Flow                 *DeviceFlow
+	RefreshTokenStrategy oauth2.RefreshTokenStrategy
+	RefreshTokenStorage  oauth2.RefreshTokenStorage
+	Config               interface {
+		fosite.RefreshTokenScopesProvider
+		fosite.RefreshTokenLifespanProvider
+		fosite.AccessTokenLifespanProvider
+	}
+}
+
+// HandleTokenEndpointRequest validates the device code and, once approved,
+// sets up the session for the approving user.
+func (h *DeviceCodeGrantHandler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !h.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+	client := request.GetClient()
+	if !client.GetGrantTypes().Has(GrantTypeDeviceCode) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The client is not allowed to use the device authorization grant."))
+	}
+
+	deviceCode := request.GetRequestForm().Get("device_code")
+	if deviceCode == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The device_code parameter is missing."))
+	}
+	hash := hashDeviceCode(deviceCode)
+	auth, err := h.Flow.store.GetDeviceAuthorization(ctx, hash)
+	if errors.Is(err, ErrDeviceCodeNotFound) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The device code is invalid or has already been used."))
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	if auth.ClientID != client.GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The device code was issued to another client."))
+	}
+
+	now := time.Now().UTC()
+	if now.After(auth.ExpiresAt) {
+		_, _ = h.Flow.store.DeleteDeviceAuthorization(ctx, hash)
+		return errorsx.WithStack(ErrExpiredToken)
+	}
+	switch auth.Status {
+	case DeviceStatusDenied:
+		_, _ = h.Flow.store.DeleteDeviceAuthorization(ctx, hash)
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The user denied the device authorization request."))
+	case DeviceStatusPending:
+		tooFast := !auth.LastPolledAt.IsZero() && now.Sub(auth.LastPolledAt) < time.Duration(auth.Interval)*time.Second
+		auth.LastPolledAt = now
+		if tooFast {
+			auth.Interval += int64(slowDownIncrement / time.Second)
+		}
+		// Losing the race to an approval or denial keeps this poll pending;
+		// the next poll sees the decision
+		_, err := h.Flow.store.CompareAndSetDeviceAuthorization(ctx, hash, auth)
+		if err != nil && !errors.Is(err, ErrDeviceAuthorizationChanged) && !errors.Is(err, ErrDeviceCodeNotFound) {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		if tooFast {
+			return errorsx.WithStack(ErrSlowDown)
+		}
+		return errorsx.WithStack(ErrAuthorizationPending)
+	}
+
+	// Approved: device codes are single use, and only the poll that deletes
+	// the code gets tokens
+	consumed, err := h.Flow.store.DeleteDeviceAuthorization(ctx, hash)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	if !consumed {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The device code is invalid or has already been used."))
+	}
+	if sess, ok := request.GetSession().(*Session); ok {
+		sess.SetSubject(auth.UserID)
+		sess.UserID = auth.UserID
+		sess.OrgID = auth.OrgID
+		sess.GrantedScopes = append([]string{}, auth.GrantedScopes...)
+	}
+	for _, scope := range auth.GrantedScopes {
+		request.GrantScope(scope)
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantType(GrantTypeDeviceCode), fosite.AccessToken, h.Config.GetAccessTokenLifespan(ctx))
+	request.GetSession().SetExpiresAt(fosite.AccessToken, now.Add(atLifespan).Round(time.Second))
+	rtLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantType(GrantTypeDeviceCode), fosite.RefreshToken, h.Config.GetRefreshTokenLifespan(ctx))
+	if rtLifespan > -1 {
+		request.GetSession().SetExpiresAt(fosite.RefreshToken, now.Add(rtLifespan).Round(time.Second))
+	}
+	return nil
+}
+
+// PopulateTokenEndpointResponse issues the access token and, when the client
+// may refresh, a refresh token.
+func (h *DeviceCodeGrantHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) error {
+	if !h.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	var refresh string
+	canRefresh := requester.GetClient().GetGrantTypes().Has("refresh_token")
+	if canRefresh && (len(h.Config.GetRefreshTokenScopes(ctx)) == 0 || requester.GetGrantedScopes().HasOneOf(h.Config.GetRefreshTokenScopes(ctx)...)) {
+		token, signature, err := h.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
+		if err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		if err := h.RefreshTokenStorage.CreateRefreshTokenSession(ctx, signature, requester.Sanitize([]string{})); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		refresh = token
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantType(GrantTypeDeviceCode), fosite.AccessToken, h.Config.GetAccessTokenLifespan(ctx))
+	if err := h.IssueAccessToken(ctx, atLifespan, requester, responder); err != nil {
+		return err
+	}
+	if refresh != "" {
+		responder.SetExtra("refresh_token", refresh)
+	}
+	return nil
+}
+
+// CanSkipClientAuth is false; the device client authenticates as a public client.
+func (h *DeviceCodeGrantHandler) CanSkipClientAuth(context.Context, fosite.AccessRequester) bool {
+	return false
+}
+
+// CanHandleTokenEndpointRequest reports whether the request uses the device code grant.
+func (h *DeviceCodeGrantHandler) CanHandleTokenEndpointRequest(_ context.Context, requester fosite.AccessRequester) bool {
+	return requester.GetGrantTypes().ExactOne(GrantTypeDeviceCode)
+}
+
+// factory returns a compose.Factory registering the device code grant handler.
+func (f *DeviceFlow) factory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &DeviceCodeGrantHandler{
+		HandleHelper: &oauth2.HandleHelper{
+			AccessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
+			AccessTokenStorage:  storage.(oauth2.AccessTokenStorage),
+			Config:              config,
+		},
+		Flow:                 f,
+		RefreshTokenStrategy: strategy.(oauth2.RefreshTokenStrategy),
+		RefreshTokenStorage:  storage.(oauth2.RefreshTokenStorage),
+		Config:               config,
+	}
+}
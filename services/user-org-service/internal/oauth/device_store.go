@@ -0,0 +1,211 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryDeviceStore keeps device authorizations in process. It suits a single
+// replica or development; use RedisDeviceStore when running several replicas.
+type MemoryDeviceStore struct {
+	mu        sync.Mutex
+	byHash    map[string]DeviceAuthorization
+	userCodes map[string]string
+}
+
+// NewMemoryDeviceStore creates an empty in-memory device store.
+func NewMemoryDeviceStore() *MemoryDeviceStore {
+	return &MemoryDeviceStore{
+		byHash:    make(map[string]DeviceAuthorization),
+		userCodes: make(map[string]string),
+	}
+}
+
+func (s *MemoryDeviceStore) CreateDeviceAuthorization(_ context.Context, hash string, auth DeviceAuthorization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeExpired(time.Now())
+	if _, taken := s.userCodes[auth.UserCode]; taken {
+		return fmt.Errorf("device store: user code collision")
+	}
+	s.byHash[hash] = auth
+	s.userCodes[auth.UserCode] = hash
+	return nil
+}
+
+func (s *MemoryDeviceStore) GetDeviceAuthorization(_ context.Context, hash string) (DeviceAuthorization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, ok := s.byHash[hash]
+	if !ok {
+		return DeviceAuthorization{}, ErrDeviceCodeNotFound
+	}
+	return auth, nil
+}
+
+func (s *MemoryDeviceStore) GetDeviceCodeHashByUserCode(_ context.Context, userCode string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash, ok := s.userCodes[userCode]
+	if !ok {
+		return "", ErrDeviceCodeNotFound
+	}
+	return hash, nil
+}
+
+func (s *MemoryDeviceStore) CompareAndSetDeviceAuthorization(_ context.Context, hash string, auth DeviceAuthorization) (DeviceAuthorization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.byHash[hash]
+	if !ok {
+		return DeviceAuthorization{}, ErrDeviceCodeNotFound
+	}
+	if current.Version != auth.Version {
+		return DeviceAuthorization{}, ErrDeviceAuthorizationChanged
+	}
+	auth.Version++
+	s.byHash[hash] = auth
+	return auth, nil
+}
+
+func (s *MemoryDeviceStore) DeleteDeviceAuthorization(_ context.Context, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, ok := s.byHash[hash]
+	if !ok {
+		return false, nil
+	}
+	delete(s.userCodes, auth.UserCode)
+	delete(s.byHash, hash)
+	return true, nil
+}
+
+// purgeExpired drops expired authorizations; callers hold s.mu.
+func (s *MemoryDeviceStore) purgeExpired(now time.Time) {
+	for hash, auth := range s.byHash {
+		if now.After(auth.ExpiresAt) {
+			delete(s.userCodes, auth.UserCode)
+			delete(s.byHash, hash)
+		}
+	}
+}
+
+// RedisDeviceStore implements DeviceStore backed by Redis, letting any
+// replica answer polls and approvals.
+type RedisDeviceStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisDeviceStore creates a redis-backed device store.
+func NewRedisDeviceStore(client *redis.Client, prefix string) *RedisDeviceStore {
+	if prefix == "" {
+		prefix = "oauth"
+	}
+	return &RedisDeviceStore{client: client, prefix: prefix}
+}
+
+func (s *RedisDeviceStore) CreateDeviceAuthorization(ctx context.Context, hash string, auth DeviceAuthorization) error {
+	payload, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(auth.ExpiresAt)
+	ok, err := s.client.SetNX(ctx, s.userCodeKey(auth.UserCode), hash, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("device store: user code collision")
+	}
+	return s.client.Set(ctx, s.deviceKey(hash), payload, ttl).Err()
+}
+
+func (s *RedisDeviceStore) GetDeviceAuthorization(ctx context.Context, hash string) (DeviceAuthorization, error) {
+	data, err := s.client.Get(ctx, s.deviceKey(hash)).Bytes()
+	if err == redis.Nil {
+		return DeviceAuthorization{}, ErrDeviceCodeNotFound
+	} else if err != nil {
+		return DeviceAuthorization{}, err
+	}
+	var auth DeviceAuthorization
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return DeviceAuthorization{}, err
+	}
+	return auth, nil
+}
+
+func (s *RedisDeviceStore) GetDeviceCodeHashByUserCode(ctx context.Context, userCode string) (string, error) {
+	hash, err := s.client.Get(ctx, s.userCodeKey(userCode)).Result()
+	if err == redis.Nil {
+		return "", ErrDeviceCodeNotFound
+	}
+	return hash, err
+}
+
+// CompareAndSetDeviceAuthorization watches the device key, so the write is
+// discarded when another replica changes the authorization in between.
+func (s *RedisDeviceStore) CompareAndSetDeviceAuthorization(ctx context.Context, hash string, auth DeviceAuthorization) (DeviceAuthorization, error) {
+	key := s.deviceKey(hash)
+	next := auth
+	next.Version++
+	payload, err := json.Marshal(next)
+	if err != nil {
+		return DeviceAuthorization{}, err
+	}
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return ErrDeviceCodeNotFound
+		} else if err != nil {
+			return err
+		}
+		var current DeviceAuthorization
+		if err := json.Unmarshal(data, &current); err != nil {
+			return err
+		}
+		if current.Version != auth.Version {
+			return ErrDeviceAuthorizationChanged
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			// KeepTTL: the authorization still expires when it was issued to
+			pipe.Set(ctx, key, payload, redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return DeviceAuthorization{}, ErrDeviceAuthorizationChanged
+	} else if err != nil {
+		return DeviceAuthorization{}, err
+	}
+	return next, nil
+}
+
+func (s *RedisDeviceStore) DeleteDeviceAuthorization(ctx context.Context, hash string) (bool, error) {
+	auth, err := s.GetDeviceAuthorization(ctx, hash)
+	if err == ErrDeviceCodeNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	// Only the caller whose DEL removes the key has consumed it
+	deleted, err := s.client.Del(ctx, s.deviceKey(hash)).Result()
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+	return true, s.client.Del(ctx, s.userCodeKey(auth.UserCode)).Err()
+}
+
+func (s *RedisDeviceStore) deviceKey(hash string) string {
+	return fmt.Sprintf("%s:device:%s", s.prefix, hash)
+}
+
+func (s *RedisDeviceStore) userCodeKey(userCode string) string {
+	return fmt.Sprintf("%s:device_user_code:%s", s.prefix, userCode)
+}
@@ -0,0 +1,197 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeviceTestProvider(t *testing.T, store DeviceStore) (fosite.OAuth2Provider, *DeviceFlow) {
+	t.Helper()
+	memStore := storage.NewMemoryStore()
+	memStore.Clients["admin-cli"] = &fosite.DefaultClient{
+		ID:         "admin-cli",
+		Public:     true,
+		GrantTypes: []string{GrantTypeDeviceCode, "refresh_token"},
+		Scopes:     []string{"openid", "admin", "offline_access"},
+	}
+	memStore.Clients["web"] = &fosite.DefaultClient{ID: "web", Public: true, GrantTypes: []string{"password"}}
+
+	flow := NewDeviceFlow(DeviceFlowConfig{Store: store, VerificationURI: "https://console.example.com/device"})
+	provider, err := NewProvider(ProviderDependencies{
+		Storage:    memStore,
+		HMACSecret: []byte("0123456789abcdef0123456789abcdef"),
+		DeviceFlow: flow,
+	})
+	require.NoError(t, err)
+	return provider, flow
+}
+
+func pollDeviceToken(ctx context.Context, provider fosite.OAuth2Provider, deviceCode string) (fosite.AccessResponder, error) {
+	form := url.Values{"grant_type": {GrantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {"admin-cli"}}
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/device/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	accessRequest, err := provider.NewAccessRequest(ctx, req, &Session{})
+	if err != nil {
+		return nil, err
+	}
+	return provider.NewAccessResponse(ctx, accessRequest)
+}
+
+func TestDeviceFlowApproveAndExchange(t *testing.T) {
+	ctx := context.Background()
+	provider, flow := newDeviceTestProvider(t, nil)
+
+	resp, err := flow.Authorize(ctx, "admin-cli", []string{"openid", "admin", "offline_access"})
+	require.NoError(t, err)
+	require.Regexp(t, `^[A-Z]{4}-[A-Z]{4}$`, resp.UserCode)
+	require.Equal(t, "https://console.example.com/device?user_code="+url.QueryEscape(resp.UserCode), resp.VerificationURIComplete)
+
+	_, err = pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.True(t, errors.Is(err, ErrAuthorizationPending), "got %v", err)
+	_, err = pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.True(t, errors.Is(err, ErrSlowDown), "got %v", err)
+
+	// Users may type the code in lower case without the dash
+	typed := strings.ToLower(strings.ReplaceAll(resp.UserCode, "-", ""))
+	auth, err := flow.Approve(ctx, typed, "user-1", "org-1", []string{"openid"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"openid", "offline_access"}, auth.GrantedScopes)
+
+	token, err := pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.NoError(t, err)
+	require.NotEmpty(t, token.GetAccessToken())
+	require.NotEmpty(t, token.GetExtra("refresh_token"))
+	require.Equal(t, "openid offline_access", token.GetExtra("scope"))
+
+	// Device codes are single use
+	_, err = pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.True(t, errors.Is(err, fosite.ErrInvalidGrant), "got %v", err)
+}
+
+func TestDeviceFlowDeny(t *testing.T) {
+	ctx := context.Background()
+	provider, flow := newDeviceTestProvider(t, nil)
+
+	resp, err := flow.Authorize(ctx, "admin-cli", nil)
+	require.NoError(t, err)
+	require.NoError(t, flow.Deny(ctx, resp.UserCode))
+
+	_, err = pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.True(t, errors.Is(err, fosite.ErrAccessDenied), "got %v", err)
+	_, err = flow.Lookup(ctx, resp.UserCode)
+	require.ErrorIs(t, err, ErrDeviceCodeNotFound)
+}
+
+func TestDeviceFlowAuthorizeRejectsClients(t *testing.T) {
+	ctx := context.Background()
+	_, flow := newDeviceTestProvider(t, nil)
+
+	_, err := flow.Authorize(ctx, "web", nil)
+	require.True(t, errors.Is(err, fosite.ErrUnauthorizedClient), "got %v", err)
+	_, err = flow.Authorize(ctx, "admin-cli", []string{"billing"})
+	require.True(t, errors.Is(err, fosite.ErrInvalidScope), "got %v", err)
+}
+
+// approvingDeviceStore approves the authorization just before the first
+// compare-and-set, as a console approval racing a poll would.
+type approvingDeviceStore struct {
+	DeviceStore
+	approve func()
+}
+
+func (s *approvingDeviceStore) CompareAndSetDeviceAuthorization(ctx context.Context, hash string, auth DeviceAuthorization) (DeviceAuthorization, error) {
+	if approve := s.approve; approve != nil {
+		s.approve = nil
+		approve()
+	}
+	return s.DeviceStore.CompareAndSetDeviceAuthorization(ctx, hash, auth)
+}
+
+func TestDevicePollKeepsConcurrentApproval(t *testing.T) {
+	ctx := context.Background()
+	store := &approvingDeviceStore{DeviceStore: NewMemoryDeviceStore()}
+	provider, flow := newDeviceTestProvider(t, store)
+
+	resp, err := flow.Authorize(ctx, "admin-cli", []string{"openid"})
+	require.NoError(t, err)
+	store.approve = func() {
+		_, err := flow.Approve(ctx, resp.UserCode, "user-1", "org-1", []string{"openid"})
+		require.NoError(t, err)
+	}
+
+	// The poll read the pending authorization before the approval landed
+	_, err = pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.True(t, errors.Is(err, ErrAuthorizationPending), "got %v", err)
+
+	token, err := pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.NoError(t, err)
+	require.NotEmpty(t, token.GetAccessToken())
+}
+
+func TestDevicePollsConsumeApprovalOnce(t *testing.T) {
+	ctx := context.Background()
+	provider, flow := newDeviceTestProvider(t, nil)
+
+	resp, err := flow.Authorize(ctx, "admin-cli", []string{"openid"})
+	require.NoError(t, err)
+	_, err = flow.Approve(ctx, resp.UserCode, "user-1", "org-1", []string{"openid"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var issued atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pollDeviceToken(ctx, provider, resp.DeviceCode); err == nil {
+				issued.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), issued.Load())
+}
+
+func TestMemoryDeviceStoreCompareAndSet(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDeviceStore()
+	auth := DeviceAuthorization{ClientID: "admin-cli", UserCode: "BCDF-GHJK", Status: DeviceStatusPending, ExpiresAt: time.Now().Add(time.Minute)}
+	require.NoError(t, store.CreateDeviceAuthorization(ctx, "hash", auth))
+
+	first, err := store.GetDeviceAuthorization(ctx, "hash")
+	require.NoError(t, err)
+	stale := first
+
+	first.Status = DeviceStatusApproved
+	stored, err := store.CompareAndSetDeviceAuthorization(ctx, "hash", first)
+	require.NoError(t, err)
+	require.Equal(t, first.Version+1, stored.Version)
+
+	stale.LastPolledAt = time.Now()
+	_, err = store.CompareAndSetDeviceAuthorization(ctx, "hash", stale)
+	require.ErrorIs(t, err, ErrDeviceAuthorizationChanged)
+	current, err := store.GetDeviceAuthorization(ctx, "hash")
+	require.NoError(t, err)
+	require.Equal(t, DeviceStatusApproved, current.Status)
+
+	deleted, err := store.DeleteDeviceAuthorization(ctx, "hash")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = store.DeleteDeviceAuthorization(ctx, "hash")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	_, err = store.CompareAndSetDeviceAuthorization(ctx, "hash", current)
+	require.ErrorIs(t, err, ErrDeviceCodeNotFound)
+}
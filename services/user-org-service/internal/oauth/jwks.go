@@ -0,0 +1,310 @@
+// Package oauth (jwks.go) signs JWT access tokens with a rotating key set.
+//
+// Purpose:
+//
+//	Access tokens are RS256 JWTs so downstream services can validate them
+//	locally against /.well-known/jwks.json instead of calling
+//	user-org-service. Refresh tokens and authorization codes stay opaque
+//	(HMAC) and are only ever checked by this service.
+//
+// Dependencies:
+//   - github.com/ory/fosite/token/jwt: Signer interface used by the JWT strategy
+//   - github.com/go-jose/go-jose/v3: JSON Web Key encoding
+//
+// Key Responsibilities:
+//   - KeySet signs with its newest key and verifies with any retained key
+//   - Rotate/RotateEvery add a key and keep the previous ones until the
+//     tokens they signed have expired
+//   - Replace loads keys persisted by internal/signingkeys
+//   - PublicJWKS publishes the verification keys
+//
+// Debugging Notes:
+//   - Every token carries the signing key's kid; unknown kids fail validation
+//   - Keys from JWT_SIGNING_KEY_FILES are shared by all replicas: rotate by
+//     putting the new key first and keeping the old file until tokens expire
+//   - With JWT_KEY_ENCRYPTION_KEY the key manager stores keys in Postgres
+//     and every replica loads the same set
+//   - Otherwise each process generates its own keys, which only works with
+//     a single replica
+//
+// Thread Safety:
+//   - KeySet is safe for concurrent use
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// signingKeyBits is the RSA modulus size of generated signing keys.
+const signingKeyBits = 2048
+
+// ErrUnknownSigningKey is the inner error of the jwt.ValidationError returned
+// when a token's kid is not in the key set.
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+var _ jwt.Signer = (*KeySet)(nil)
+
+// signingKey is a key in the set; retired keys verify until expiresAt.
+type signingKey struct {
+	jwk       *jose.JSONWebKey
+	expiresAt time.Time // zero while the key is active or configured statically
+}
+
+// KeySet holds the active signing key and the retired keys still accepted
+// for verification.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []signingKey // newest first
+}
+
+// NewKeySet creates a key set from RSA private keys; the first one signs.
+func NewKeySet(keys ...*rsa.PrivateKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("oauth keyset: at least one signing key is required")
+	}
+	set := &KeySet{}
+	for _, key := range keys {
+		jwk, err := newSigningJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		set.keys = append(set.keys, signingKey{jwk: jwk})
+	}
+	return set, nil
+}
+
+// LoadKeySet reads PEM-encoded RSA private keys (PKCS#1 or PKCS#8) from
+// paths; the first file holds the active signing key.
+func LoadKeySet(paths []string) (*KeySet, error) {
+	keys := make([]*rsa.PrivateKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("oauth keyset: read %s: %w", path, err)
+		}
+		key, err := parseRSAPrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("oauth keyset: %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(keys...)
+}
+
+// GenerateKeySet creates a key set with a freshly generated signing key.
+func GenerateKeySet() (*KeySet, error) {
+	key, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("oauth keyset: generate key: %w", err)
+	}
+	return NewKeySet(key)
+}
+
+// KeySetEntry is a key handed to Replace.
+type KeySetEntry struct {
+	Key *rsa.PrivateKey
+	// VerifyUntil ends verification for a retired key; zero for the signing key.
+	VerifyUntil time.Time
+}
+
+// Replace swaps in keys managed elsewhere, such as the signing key manager;
+// the first entry signs.
+func (k *KeySet) Replace(entries []KeySetEntry) error {
+	if len(entries) == 0 {
+		return errors.New("oauth keyset: at least one signing key is required")
+	}
+	keys := make([]signingKey, 0, len(entries))
+	for _, entry := range entries {
+		jwk, err := newSigningJWK(entry.Key)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, signingKey{jwk: jwk, expiresAt: entry.VerifyUntil})
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// SigningKeyID returns the kid a KeySet assigns to key.
+func SigningKeyID(key *rsa.PrivateKey) (string, error) {
+	jwk, err := newSigningJWK(key)
+	if err != nil {
+		return "", err
+	}
+	return jwk.KeyID, nil
+}
+
+// Rotate makes key the signing key. The previous keys keep verifying tokens
+// for retain (at least the access token lifespan), then are dropped.
+func (k *KeySet) Rotate(key *rsa.PrivateKey, retain time.Duration) error {
+	jwk, err := newSigningJWK(key)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	keys := []signingKey{{jwk: jwk}}
+	for _, existing := range k.keys {
+		if existing.expiresAt.IsZero() {
+			existing.expiresAt = now.Add(retain)
+		}
+		if existing.expiresAt.After(now) {
+			keys = append(keys, existing)
+		}
+	}
+	k.keys = keys
+	return nil
+}
+
+// RotateEvery generates a new signing key every interval until ctx is done.
+func (k *KeySet) RotateEvery(ctx context.Context, interval, retain time.Duration, onError func(error)) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			key, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+			if err == nil {
+				err = k.Rotate(key, retain)
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// PublicJWKS returns the public halves of all keys accepted for verification.
+func (k *KeySet) PublicJWKS() jose.JSONWebKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	set := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, 0, len(k.keys))}
+	for _, key := range k.keys {
+		if !key.expiresAt.IsZero() && !key.expiresAt.After(now) {
+			continue
+		}
+		set.Keys = append(set.Keys, key.jwk.Public())
+	}
+	return set
+}
+
+// Generate signs claims with the active key, setting its kid in the header.
+func (k *KeySet) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	k.mu.RLock()
+	if len(k.keys) == 0 {
+		k.mu.RUnlock()
+		return "", "", errors.New("oauth keyset: no signing key")
+	}
+	active := k.keys[0].jwk
+	k.mu.RUnlock()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(context.Context) (interface{}, error) { return active, nil }}
+	return signer.Generate(ctx, claims, header)
+}
+
+// Validate verifies a token and returns its signature.
+func (k *KeySet) Validate(ctx context.Context, token string) (string, error) {
+	if _, err := k.Decode(ctx, token); err != nil {
+		return "", err
+	}
+	return k.GetSignature(ctx, token)
+}
+
+// Decode parses a token and verifies it with the key named by its kid.
+func (k *KeySet) Decode(_ context.Context, token string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, jwt.MapClaims{}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key := k.verificationKey(kid)
+		if key == nil {
+			return nil, ErrUnknownSigningKey
+		}
+		return key, nil
+	})
+}
+
+// Hash returns the SHA-256 hash of in.
+func (k *KeySet) Hash(ctx context.Context, in []byte) ([]byte, error) {
+	return (&jwt.DefaultSigner{}).Hash(ctx, in)
+}
+
+// GetSignature returns the signature part of a token.
+func (k *KeySet) GetSignature(ctx context.Context, token string) (string, error) {
+	return (&jwt.DefaultSigner{}).GetSignature(ctx, token)
+}
+
+// GetSigningMethodLength returns the size of the hash used with RS256.
+func (k *KeySet) GetSigningMethodLength(ctx context.Context) int {
+	return (&jwt.DefaultSigner{}).GetSigningMethodLength(ctx)
+}
+
+// verificationKey returns the public key for kid, or nil when it is unknown or expired.
+func (k *KeySet) verificationKey(kid string) interface{} {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	for _, key := range k.keys {
+		if key.jwk.KeyID != kid {
+			continue
+		}
+		if !key.expiresAt.IsZero() && !key.expiresAt.After(now) {
+			return nil
+		}
+		return key.jwk.Public().Key
+	}
+	return nil
+}
+
+// newSigningJWK wraps key as an RS256 signing JWK whose kid is its RFC 7638 thumbprint.
+func newSigningJWK(key *rsa.PrivateKey) (*jose.JSONWebKey, error) {
+	if key == nil {
+		return nil, errors.New("oauth keyset: nil signing key")
+	}
+	jwk := &jose.JSONWebKey{Key: key, Algorithm: string(jose.RS256), Use: "sig"}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("oauth keyset: key thumbprint: %w", err)
+	}
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+	return jwk, nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T (RSA required)", parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func TestKeySetSignsAndVerifiesAcrossRotation(t *testing.T) {
+	ctx := context.Background()
+	keys, err := NewKeySet(newTestRSAKey(t))
+	require.NoError(t, err)
+
+	oldToken, _, err := keys.Generate(ctx, jwt.MapClaims{"sub": "user-1"}, &jwt.Headers{})
+	require.NoError(t, err)
+	oldKid := keys.PublicJWKS().Keys[0].KeyID
+
+	require.NoError(t, keys.Rotate(newTestRSAKey(t), time.Hour))
+	newToken, _, err := keys.Generate(ctx, jwt.MapClaims{"sub": "user-2"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	published := keys.PublicJWKS()
+	require.Len(t, published.Keys, 2)
+	require.NotEqual(t, oldKid, published.Keys[0].KeyID)
+	require.Equal(t, oldKid, published.Keys[1].KeyID)
+	for _, jwk := range published.Keys {
+		require.True(t, jwk.IsPublic())
+		require.Equal(t, "RS256", jwk.Algorithm)
+	}
+
+	for _, token := range []string{oldToken, newToken} {
+		signature, err := keys.Validate(ctx, token)
+		require.NoError(t, err)
+		require.Equal(t, token[strings.LastIndex(token, ".")+1:], signature)
+	}
+
+	// Retired keys are dropped once their retention has passed; keys already
+	// retired keep their original deadline
+	require.NoError(t, keys.Rotate(newTestRSAKey(t), -time.Second))
+	published = keys.PublicJWKS()
+	require.Len(t, published.Keys, 2)
+	require.Equal(t, oldKid, published.Keys[1].KeyID)
+	_, err = keys.Validate(ctx, newToken)
+	require.Error(t, err)
+	_, err = keys.Validate(ctx, oldToken)
+	require.NoError(t, err)
+}
+
+func TestKeySetRejectsUnknownKid(t *testing.T) {
+	ctx := context.Background()
+	issuer, err := NewKeySet(newTestRSAKey(t))
+	require.NoError(t, err)
+	verifier, err := NewKeySet(newTestRSAKey(t))
+	require.NoError(t, err)
+
+	token, _, err := issuer.Generate(ctx, jwt.MapClaims{"sub": "user-1"}, &jwt.Headers{})
+	require.NoError(t, err)
+	_, err = verifier.Decode(ctx, token)
+	var validationErr *jwt.ValidationError
+	require.True(t, errors.As(err, &validationErr), "got %v", err)
+	require.Equal(t, ErrUnknownSigningKey, validationErr.Inner)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	pkcs1 := filepath.Join(dir, "current.pem")
+	require.NoError(t, os.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(newTestRSAKey(t)),
+	}), 0o600))
+	der, err := x509.MarshalPKCS8PrivateKey(newTestRSAKey(t))
+	require.NoError(t, err)
+	pkcs8 := filepath.Join(dir, "previous.pem")
+	require.NoError(t, os.WriteFile(pkcs8, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	keys, err := LoadKeySet([]string{pkcs1, pkcs8})
+	require.NoError(t, err)
+	require.Len(t, keys.PublicJWKS().Keys, 2)
+
+	_, err = LoadKeySet([]string{filepath.Join(dir, "missing.pem")})
+	require.Error(t, err)
+}
+
+func TestProviderIssuesJWTAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	memStore := storage.NewMemoryStore()
+	memStore.Clients["admin-cli"] = &fosite.DefaultClient{
+		ID:         "admin-cli",
+		Public:     true,
+		GrantTypes: []string{GrantTypeDeviceCode, "refresh_token"},
+		Scopes:     []string{"openid", "offline_access"},
+	}
+	keys, err := NewKeySet(newTestRSAKey(t))
+	require.NoError(t, err)
+	flow := NewDeviceFlow(DeviceFlowConfig{VerificationURI: "https://console.example.com/device"})
+	provider, err := NewProvider(ProviderDependencies{
+		Storage:    memStore,
+		HMACSecret: []byte("0123456789abcdef0123456789abcdef"),
+		DeviceFlow: flow,
+		KeySet:     keys,
+		Issuer:     "https://auth.example.com",
+	})
+	require.NoError(t, err)
+
+	resp, err := flow.Authorize(ctx, "admin-cli", []string{"openid", "offline_access"})
+	require.NoError(t, err)
+	_, err = flow.Approve(ctx, resp.UserCode, "user-1", "org-1", []string{"openid"})
+	require.NoError(t, err)
+	token, err := pollDeviceToken(ctx, provider, resp.DeviceCode)
+	require.NoError(t, err)
+
+	accessToken := token.GetAccessToken()
+	parsed, err := keys.Decode(ctx, accessToken)
+	require.NoError(t, err)
+	claims := parsed.Claims
+	require.Equal(t, "user-1", claims["sub"])
+	require.Equal(t, "org-1", claims["org_id"])
+	require.Equal(t, "https://auth.example.com", claims["iss"])
+
+	// Refresh tokens stay opaque
+	refreshToken, _ := token.GetExtra("refresh_token").(string)
+	require.NotEmpty(t, refreshToken)
+	require.Equal(t, 1, strings.Count(refreshToken, "."))
+
+	_, requester, err := provider.IntrospectToken(ctx, accessToken, fosite.AccessToken, &Session{})
+	require.NoError(t, err)
+	require.Equal(t, "org-1", requester.GetSession().(*Session).OrgID)
+}
@@ -19,6 +19,8 @@
 //   - Constructs static clients from configuration (hashed secrets)
 //   - Applies default token lifetimes and PKCE settings
 //   - Wraps storage with ClientStore for static client support
+//   - Registers the device authorization grant when a DeviceFlow is supplied
+//   - Issues JWT access tokens signed by a KeySet when one is supplied
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#FR-005 (OAuth2 Support)
@@ -49,6 +51,7 @@ import (
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
 
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
 )
@@ -77,6 +80,16 @@ type ProviderDependencies struct {
 
 	// Factories allows overriding the default factories used to register handlers.
 	Factories []compose.Factory
+
+	// DeviceFlow enables the device authorization grant (RFC 8628) when set.
+	DeviceFlow *DeviceFlow
+
+	// KeySet switches access tokens to JWTs signed by its active key when set.
+	// Refresh tokens and authorization codes stay opaque HMAC tokens.
+	KeySet *KeySet
+
+	// Issuer is the iss claim of JWT access tokens.
+	Issuer string
 }
 
 // StaticClient describes a client definition supplied via configuration.
@@ -88,6 +101,8 @@ type StaticClient struct {
 	ResponseTypes []string
 	Scopes        []string
 	Audience      []string
+	// Public clients authenticate with their ID alone, e.g. CLI tools using the device flow.
+	Public bool
 }
 
 // NewProvider composes a Fosité OAuth2 provider configured for the user-org service.
@@ -98,7 +113,7 @@ func NewProvider(deps ProviderDependencies) (fosite.OAuth2Provider, error) {
 
 	cfg := deps.Config
 	if cfg == nil {
-		cfg = defaultConfig()
+		cfg = DefaultConfig()
 	}
 
 	// Ensure the global secret is populated.
@@ -148,12 +163,27 @@ func NewProvider(deps ProviderDependencies) (fosite.OAuth2Provider, error) {
 		cfg.ClientSecretsHasher = &fosite.BCrypt{Config: cfg}
 	}
 
-	strategy := compose.NewOAuth2HMACStrategy(cfg)
+	if deps.Issuer != "" && cfg.AccessTokenIssuer == "" {
+		cfg.AccessTokenIssuer = deps.Issuer
+	}
+
+	var strategy oauth2.CoreStrategy = compose.NewOAuth2HMACStrategy(cfg)
+	if deps.KeySet != nil {
+		strategy = &oauth2.DefaultJWTStrategy{
+			Signer:          deps.KeySet,
+			HMACSHAStrategy: strategy,
+			Config:          cfg,
+		}
+	}
 
-	factories := deps.Factories
+	factories := append([]compose.Factory{}, deps.Factories...)
 	if len(factories) == 0 {
 		factories = defaultFactories()
 	}
+	if deps.DeviceFlow != nil {
+		deps.DeviceFlow.clients = storage
+		factories = append(factories, deps.DeviceFlow.factory)
+	}
 
 	switch typed := storage.(type) {
 	case *Store:
@@ -170,7 +200,9 @@ func NewProvider(deps ProviderDependencies) (fosite.OAuth2Provider, error) {
 	), nil
 }
 
-func defaultConfig() *fosite.Config {
+// DefaultConfig returns the service's Fosite configuration: 1h access
+// tokens, 24h refresh tokens, exact scope matching and enforced PKCE.
+func DefaultConfig() *fosite.Config {
 	return &fosite.Config{
 		AccessTokenLifespan:            time.Hour,
 		RefreshTokenLifespan:           24 * time.Hour,
@@ -226,6 +258,7 @@ func constructStaticClients(cfg *fosite.Config, defs []StaticClient) (map[string
 			GrantTypes:    def.GrantTypes,
 			Scopes:        def.Scopes,
 			Audience:      def.Audience,
+			Public:        def.Public,
 		}
 		if len(client.GrantTypes) == 0 {
 			client.GrantTypes = []string{"password", "refresh_token"}
@@ -35,8 +35,12 @@ package oauth
 
 import (
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/token/jwt"
 )
 
+var _ oauth2.JWTSessionContainer = (*Session)(nil)
+
 // Session extends fosite's DefaultSession with user-org specific metadata.
 // This session type is used throughout the OAuth2 flow and stored in the
 // oauth_sessions table. The org_id and user_id fields enable multi-tenant
@@ -65,3 +69,26 @@ func (s *Session) Clone() fosite.Session {
 	}
 	return &clone
 }
+
+// GetJWTClaims returns the claims for a JWT access token. The strategy adds
+// exp, scope, aud, iat, iss and jti; org_id and user_id let downstream
+// services authorize without calling this service.
+func (s *Session) GetJWTClaims() jwt.JWTClaimsContainer {
+	subject := s.Subject
+	if subject == "" {
+		subject = s.UserID
+	}
+	extra := map[string]interface{}{}
+	if s.OrgID != "" {
+		extra["org_id"] = s.OrgID
+	}
+	if s.UserID != "" {
+		extra["user_id"] = s.UserID
+	}
+	return &jwt.JWTClaims{Subject: subject, Extra: extra}
+}
+
+// GetJWTHeader returns the JWT header; the key set adds the kid.
+func (s *Session) GetJWTHeader() *jwt.Headers {
+	return &jwt.Headers{}
+}
@@ -20,6 +20,8 @@
 //   - Authenticate validates user credentials and enforces lockout policies
 //   - Session caching via Redis (optional, falls back to no-op)
 //   - TTL calculations honor fosite.Config when attached
+//   - RevokeUserSessions deactivates all of a user's tokens (e.g. after a password reset)
+//   - GetClient resolves clients registered through the client management API
 //
 // Requirements Reference:
 //   - specs/005-user-org-service/spec.md#US-001 (User Authentication)
@@ -119,9 +121,23 @@ type storedRequest struct {
 	Session           *Session            `json:"session"`
 }
 
-// GetClient is a placeholder until client persistence exists.
-func (s *Store) GetClient(_ context.Context, _ string) (fosite.Client, error) {
-	return nil, fosite.ErrNotFound
+// GetClient loads an active registered client from oauth_clients.
+// Statically configured clients are resolved first by ClientStore.
+func (s *Store) GetClient(ctx context.Context, id string) (fosite.Client, error) {
+	if s.Store == nil {
+		return nil, fosite.ErrNotFound
+	}
+	record, err := s.Store.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, fosite.ErrNotFound
+		}
+		return nil, fmt.Errorf("get oauth client: %w", err)
+	}
+	if record.Status != ClientStatusActive {
+		return nil, fosite.ErrNotFound
+	}
+	return clientFromRecord(record), nil
 }
 
 func (s *Store) ClientAssertionJWTValid(context.Context, string) error {
@@ -155,6 +171,9 @@ func (s *Store) SetClientAssertionJWT(context.Context, string, time.Time) error
 // Side effects:
 //   - Updates last_login_at in background goroutine (non-blocking)
 func (s *Store) Authenticate(ctx context.Context, username, password string) (string, error) {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	fmt.Printf("[AUTHENTICATE START] username=%s, ctx_done=%v\n", username, ctx.Err())
 	if username == "" || password == "" {
 		fmt.Printf("[AUTHENTICATE] Empty username or password\n")
@@ -302,6 +321,9 @@ func (s *Store) DeleteRefreshTokenSession(ctx context.Context, signature string)
 }
 
 func (s *Store) RevokeRefreshToken(ctx context.Context, requestID string) error {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	rid, err := uuid.Parse(requestID)
 	if err != nil {
 		return err
@@ -322,6 +344,9 @@ func (s *Store) RevokeRefreshTokenMaybeGracePeriod(ctx context.Context, _ string
 }
 
 func (s *Store) RevokeAccessToken(ctx context.Context, requestID string) error {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	rid, err := uuid.Parse(requestID)
 	if err != nil {
 		return err
@@ -337,6 +362,39 @@ func (s *Store) RevokeAccessToken(ctx context.Context, requestID string) error {
 	return s.sessionCache().DeleteByRequestID(ctx, tokenTypeAccessToken, rid)
 }
 
+// RevokeUserSessions deactivates every active token of userID (access,
+// refresh and authorization codes), e.g. after a password change, and
+// returns how many were revoked.
+func (s *Store) RevokeUserSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
+	rows, err := s.Store.Pool().Query(ctx, `
+		UPDATE oauth_sessions
+		SET active = FALSE
+		WHERE user_id = $1 AND active
+		RETURNING token_type, signature
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	revoked := 0
+	for rows.Next() {
+		var tokenType, signature string
+		if err := rows.Scan(&tokenType, &signature); err != nil {
+			return revoked, err
+		}
+		revoked++
+		// A stale cache entry would keep the token usable until it expires
+		if err := s.sessionCache().Delete(ctx, tokenType, signature); err != nil {
+			return revoked, err
+		}
+	}
+	return revoked, rows.Err()
+}
+
 func (s *Store) CreatePKCERequestSession(ctx context.Context, signature string, request fosite.Requester) error {
 	return s.storeRequest(ctx, tokenTypePKCE, signature, request)
 }
@@ -361,6 +419,9 @@ func (s *Store) DeletePKCERequestSession(ctx context.Context, signature string)
 
 // storeRequest persists a fosite request for the given token type and signature.
 func (s *Store) storeRequest(ctx context.Context, tokenType, signature string, requester fosite.Requester) error {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	if requester == nil {
 		return errors.New("requester cannot be nil")
 	}
@@ -506,6 +567,9 @@ func (s *Store) storeRequest(ctx context.Context, tokenType, signature string, r
 }
 
 func (s *Store) fetchRequest(ctx context.Context, expectedType, signature string) (fosite.Requester, error) {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	row := s.Store.Pool().QueryRow(ctx, `
 		SELECT token_type, request_id, client_id, subject, org_id, user_id,
 		       scopes, granted_scopes, audience, granted_audience,
@@ -570,6 +634,9 @@ func (s *Store) fetchRequest(ctx context.Context, expectedType, signature string
 }
 
 func (s *Store) deactivateSignature(ctx context.Context, tokenType, signature string) error {
+	ctx, cancel := s.Store.QueryContext(ctx)
+	defer cancel()
+
 	_, err := s.Store.Pool().Exec(ctx, `
 		UPDATE oauth_sessions
 		SET active = FALSE
@@ -0,0 +1,250 @@
+// Package orgresolver resolves the {orgId} path parameter, which may be an org
+// UUID or slug, to an organization.
+//
+// Purpose:
+//
+//	Most /v1/orgs/{orgId}/... handlers start by turning the path parameter into
+//	an org ID, which used to cost a database round trip per request. The
+//	resolver caches reference -> org ID mappings in process and, when
+//	configured, in Redis so every replica shares them. Unknown references are
+//	cached too (negative caching) so a client hammering a missing slug does not
+//	reach Postgres on every request.
+//
+// Dependencies:
+//   - internal/storage/postgres: GetOrg / GetOrgBySlug on cache misses
+//   - github.com/redis/go-redis/v9: Shared cache (optional)
+//
+// Key Responsibilities:
+//   - Resolver.ResolveID: Reference -> org ID, verifying the org exists
+//   - Resolver.Resolve: Reference -> current org row (only the mapping is cached)
+//   - Resolver.Forget: Drop cached entries after an org is created or renamed
+//
+// Debugging Notes:
+//   - Redis layout: orgref:{reference} = org UUID, or "-" for a missing org
+//   - Positive entries live ORG_RESOLVER_CACHE_TTL; negative ones ORG_RESOLVER_NEGATIVE_TTL
+//   - Redis failures are ignored and fall through to the database
+//
+// Thread Safety:
+//   - Resolver is safe for concurrent use
+//
+// Error Handling:
+//   - Missing (or soft-deleted) orgs return postgres.ErrNotFound
+//   - Other store errors are returned unchanged and are never cached
+package orgresolver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+const (
+	redisPrefix     = "orgref:"
+	redisMissing    = "-"
+	maxLocalEntries = 10000
+)
+
+// Store is the subset of postgres.Store the resolver needs.
+type Store interface {
+	GetOrg(ctx context.Context, id uuid.UUID) (postgres.Org, error)
+	GetOrgBySlug(ctx context.Context, slug string) (postgres.Org, error)
+}
+
+// Config configures a Resolver.
+type Config struct {
+	Store  Store
+	Redis  *redis.Client // Optional
+	Logger *zap.Logger
+	// TTL is how long a resolved reference is cached.
+	TTL time.Duration
+	// NegativeTTL is how long a reference to a missing org is cached.
+	NegativeTTL time.Duration
+}
+
+// Resolver maps org references (UUID or slug) to org IDs with caching.
+type Resolver struct {
+	store       Store
+	redis       *redis.Client
+	logger      *zap.Logger
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	id        uuid.UUID // uuid.Nil for a missing org
+	expiresAt time.Time
+}
+
+// New creates a resolver from cfg, applying defaults for unset fields.
+func New(cfg Config) *Resolver {
+	r := &Resolver{
+		store:       cfg.Store,
+		redis:       cfg.Redis,
+		logger:      cfg.Logger,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+		now:         time.Now,
+		entries:     make(map[string]entry),
+	}
+	if r.logger == nil {
+		r.logger = zap.NewNop()
+	}
+	if r.ttl <= 0 {
+		r.ttl = 5 * time.Minute
+	}
+	if r.negativeTTL <= 0 {
+		r.negativeTTL = 30 * time.Second
+	}
+	return r
+}
+
+// ResolveID returns the ID of the org that ref names.
+func (r *Resolver) ResolveID(ctx context.Context, ref string) (uuid.UUID, error) {
+	if id, ok := r.cached(ctx, ref); ok {
+		if id == uuid.Nil {
+			return uuid.Nil, postgres.ErrNotFound
+		}
+		return id, nil
+	}
+	org, err := r.load(ctx, ref)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return org.ID, nil
+}
+
+// Resolve returns the org that ref names. Only the reference mapping is cached;
+// the org itself is always read from the store so its settings are current.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (postgres.Org, error) {
+	id, ok := r.cached(ctx, ref)
+	if !ok {
+		return r.load(ctx, ref)
+	}
+	if id == uuid.Nil {
+		return postgres.Org{}, postgres.ErrNotFound
+	}
+	org, err := r.store.GetOrg(ctx, id)
+	if errors.Is(err, postgres.ErrNotFound) {
+		r.put(ctx, ref, uuid.Nil)
+	}
+	return org, err
+}
+
+// Forget drops cached entries for the given references.
+func (r *Resolver) Forget(ctx context.Context, refs ...string) {
+	r.mu.Lock()
+	for _, ref := range refs {
+		delete(r.entries, ref)
+	}
+	r.mu.Unlock()
+	if r.redis == nil || len(refs) == 0 {
+		return
+	}
+	keys := make([]string, len(refs))
+	for i, ref := range refs {
+		keys[i] = redisPrefix + ref
+	}
+	if err := r.redis.Del(ctx, keys...).Err(); err != nil {
+		r.logger.Debug("org resolver cache delete failed", zap.Error(err))
+	}
+}
+
+// load reads the org from the store and caches the outcome.
+func (r *Resolver) load(ctx context.Context, ref string) (postgres.Org, error) {
+	var org postgres.Org
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		org, err = r.store.GetOrg(ctx, id)
+	} else {
+		org, err = r.store.GetOrgBySlug(ctx, ref)
+	}
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		r.put(ctx, ref, uuid.Nil)
+	case err == nil:
+		r.put(ctx, ref, org.ID)
+	}
+	return org, err
+}
+
+// cached returns the cached ID for ref (uuid.Nil for a known-missing org).
+func (r *Resolver) cached(ctx context.Context, ref string) (uuid.UUID, bool) {
+	now := r.now()
+	r.mu.Lock()
+	e, ok := r.entries[ref]
+	r.mu.Unlock()
+	if ok && now.Before(e.expiresAt) {
+		return e.id, true
+	}
+	if r.redis == nil {
+		return uuid.Nil, false
+	}
+
+	key := redisPrefix + ref
+	pipe := r.redis.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.logger.Debug("org resolver cache read failed", zap.Error(err))
+		}
+		return uuid.Nil, false
+	}
+	id := uuid.Nil
+	if value := get.Val(); value != redisMissing {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return uuid.Nil, false
+		}
+		id = parsed
+	}
+	if remaining := ttl.Val(); remaining > 0 {
+		r.putLocal(ref, entry{id: id, expiresAt: now.Add(remaining)})
+	}
+	return id, true
+}
+
+// put caches id (uuid.Nil for a missing org) for ref locally and in Redis.
+func (r *Resolver) put(ctx context.Context, ref string, id uuid.UUID) {
+	ttl, value := r.ttl, id.String()
+	if id == uuid.Nil {
+		ttl, value = r.negativeTTL, redisMissing
+	}
+	r.putLocal(ref, entry{id: id, expiresAt: r.now().Add(ttl)})
+	if r.redis == nil {
+		return
+	}
+	if err := r.redis.Set(ctx, redisPrefix+ref, value, ttl).Err(); err != nil {
+		r.logger.Debug("org resolver cache write failed", zap.Error(err))
+	}
+}
+
+func (r *Resolver) putLocal(ref string, e entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) >= maxLocalEntries {
+		// Bound memory when clients probe many references: sweep expired
+		// entries, and start over if that was not enough
+		now := r.now()
+		for k, v := range r.entries {
+			if !now.Before(v.expiresAt) {
+				delete(r.entries, k)
+			}
+		}
+		if len(r.entries) >= maxLocalEntries {
+			r.entries = make(map[string]entry)
+		}
+	}
+	r.entries[ref] = e
+}
@@ -0,0 +1,91 @@
+package orgresolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type countingStore struct {
+	orgs  map[uuid.UUID]postgres.Org
+	calls int
+}
+
+func (s *countingStore) GetOrg(_ context.Context, id uuid.UUID) (postgres.Org, error) {
+	s.calls++
+	org, ok := s.orgs[id]
+	if !ok {
+		return postgres.Org{}, postgres.ErrNotFound
+	}
+	return org, nil
+}
+
+func (s *countingStore) GetOrgBySlug(_ context.Context, slug string) (postgres.Org, error) {
+	s.calls++
+	for _, org := range s.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return postgres.Org{}, postgres.ErrNotFound
+}
+
+func TestResolveIDCachesSlugs(t *testing.T) {
+	org := postgres.Org{ID: uuid.New(), Slug: "acme"}
+	store := &countingStore{orgs: map[uuid.UUID]postgres.Org{org.ID: org}}
+	r := New(Config{Store: store})
+
+	for i := 0; i < 3; i++ {
+		id, err := r.ResolveID(context.Background(), "acme")
+		require.NoError(t, err)
+		require.Equal(t, org.ID, id)
+	}
+	require.Equal(t, 1, store.calls)
+
+	// Resolve re-reads the org by ID so its settings are current
+	got, err := r.Resolve(context.Background(), "acme")
+	require.NoError(t, err)
+	require.Equal(t, org.ID, got.ID)
+	require.Equal(t, 2, store.calls)
+}
+
+func TestResolveIDNegativeCacheExpires(t *testing.T) {
+	store := &countingStore{orgs: map[uuid.UUID]postgres.Org{}}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	r := New(Config{Store: store, NegativeTTL: time.Minute})
+	r.now = func() time.Time { return now }
+
+	_, err := r.ResolveID(context.Background(), "missing")
+	require.ErrorIs(t, err, postgres.ErrNotFound)
+	_, err = r.ResolveID(context.Background(), "missing")
+	require.ErrorIs(t, err, postgres.ErrNotFound)
+	require.Equal(t, 1, store.calls)
+
+	org := postgres.Org{ID: uuid.New(), Slug: "missing"}
+	store.orgs[org.ID] = org
+	now = now.Add(2 * time.Minute)
+	id, err := r.ResolveID(context.Background(), "missing")
+	require.NoError(t, err)
+	require.Equal(t, org.ID, id)
+}
+
+func TestForgetDropsEntry(t *testing.T) {
+	store := &countingStore{orgs: map[uuid.UUID]postgres.Org{}}
+	r := New(Config{Store: store})
+
+	_, err := r.ResolveID(context.Background(), "new-org")
+	require.ErrorIs(t, err, postgres.ErrNotFound)
+
+	org := postgres.Org{ID: uuid.New(), Slug: "new-org"}
+	store.orgs[org.ID] = org
+	r.Forget(context.Background(), "new-org")
+
+	id, err := r.ResolveID(context.Background(), "new-org")
+	require.NoError(t, err)
+	require.Equal(t, org.ID, id)
+}
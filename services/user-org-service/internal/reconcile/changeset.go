@@ -0,0 +1,147 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Org metadata keys owned by the reconciler.
+const (
+	PendingMetadataKey = "declarative_pending"
+	HistoryMetadataKey = "declarative_drift_history"
+	PolicyMetadataKey  = "declarative_policy"
+)
+
+// maxHistory caps the change sets kept in an org's drift history.
+const maxHistory = 50
+
+// Change kinds.
+const (
+	ChangeOrgUpdate     = "org.update"
+	ChangeSettingUpdate = "settings.update"
+	ChangeSettingRemove = "settings.remove"
+	ChangeUserAdd       = "user.add"
+	ChangeUserUpdate    = "user.update"
+	ChangeUserRemove    = "user.remove"
+	ChangeAccountAdd    = "service_account.add"
+	ChangeAccountUpdate = "service_account.update"
+	ChangeAccountRemove = "service_account.remove"
+)
+
+// Change set statuses.
+const (
+	StatusPending    = "pending"
+	StatusApproved   = "approved"
+	StatusRejected   = "rejected"
+	StatusApplied    = "applied"
+	StatusFailed     = "failed"
+	StatusSuperseded = "superseded"
+)
+
+// Auto-apply policies.
+const (
+	AutoApplyNone           = "none"
+	AutoApplyNonDestructive = "non_destructive"
+	AutoApplyAll            = "all"
+)
+
+// Change is one difference between an org's document and its live state.
+type Change struct {
+	Kind        string `json:"kind"`
+	Target      string `json:"target"` // org slug, setting key, user email or service account name
+	Field       string `json:"field,omitempty"`
+	From        any    `json:"from,omitempty"`
+	To          any    `json:"to,omitempty"`
+	Destructive bool   `json:"destructive,omitempty"`
+}
+
+// ChangeSet is the drift found for one document revision and what was decided about it.
+type ChangeSet struct {
+	ID         string     `json:"id"`
+	Revision   string     `json:"revision"`
+	DetectedAt time.Time  `json:"detectedAt"`
+	Status     string     `json:"status"`
+	Changes    []Change   `json:"changes"`
+	AutoApply  bool       `json:"autoApply,omitempty"`
+	DecidedBy  string     `json:"decidedBy,omitempty"`
+	DecidedAt  *time.Time `json:"decidedAt,omitempty"`
+	AppliedAt  *time.Time `json:"appliedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Destructive reports whether any change removes a setting or principal.
+func (c *ChangeSet) Destructive() bool {
+	for _, change := range c.Changes {
+		if change.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy controls which change sets are applied without approval.
+type Policy struct {
+	AutoApply string `json:"autoApply"` // "none", "non_destructive" (default) or "all"
+}
+
+// Allows reports whether set may be applied without approval.
+func (p Policy) Allows(set *ChangeSet) bool {
+	switch p.AutoApply {
+	case AutoApplyAll:
+		return true
+	case AutoApplyNone:
+		return false
+	default:
+		return !set.Destructive()
+	}
+}
+
+// PendingFromMetadata returns the org's change set awaiting approval or application, if any.
+func PendingFromMetadata(metadata map[string]any) *ChangeSet {
+	var set ChangeSet
+	if !decodeMetadata(metadata, PendingMetadataKey, &set) || set.ID == "" {
+		return nil
+	}
+	return &set
+}
+
+// HistoryFromMetadata returns the org's past change sets, newest first.
+func HistoryFromMetadata(metadata map[string]any) []ChangeSet {
+	var history []ChangeSet
+	decodeMetadata(metadata, HistoryMetadataKey, &history)
+	return history
+}
+
+// PolicyFromMetadata returns the org's auto-apply policy.
+func PolicyFromMetadata(metadata map[string]any) Policy {
+	policy := Policy{AutoApply: AutoApplyNonDestructive}
+	decodeMetadata(metadata, PolicyMetadataKey, &policy)
+	switch policy.AutoApply {
+	case AutoApplyNone, AutoApplyAll:
+	default:
+		policy.AutoApply = AutoApplyNonDestructive
+	}
+	return policy
+}
+
+// withHistory returns history with set prepended, capped at maxHistory.
+func withHistory(history []ChangeSet, set ChangeSet) []ChangeSet {
+	out := append([]ChangeSet{set}, history...)
+	if len(out) > maxHistory {
+		out = out[:maxHistory]
+	}
+	return out
+}
+
+func decodeMetadata(metadata map[string]any, key string, out any) bool {
+	raw, ok := metadata[key]
+	if !ok || raw == nil {
+		return false
+	}
+	// Stored metadata comes back from JSONB as map[string]any
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,142 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+)
+
+// Status given to users and service accounts that were removed from the document.
+const removedStatus = "suspended"
+
+// Diff lists the changes needed to bring actual in line with desired. The
+// order is deterministic so a re-detected drift compares equal to the
+// pending change set. API keys, the billing owner and parent org are not
+// reconciled; service account descriptions are only set on creation.
+func Diff(desired, actual *declarative.Organization) []Change {
+	var changes []Change
+	slug := actual.Metadata.Slug
+
+	if desired.Spec.Name != actual.Spec.Name {
+		changes = append(changes, Change{Kind: ChangeOrgUpdate, Target: slug, Field: "name", From: actual.Spec.Name, To: desired.Spec.Name})
+	}
+	if desired.Spec.Status != "" && desired.Spec.Status != actual.Spec.Status {
+		changes = append(changes, Change{Kind: ChangeOrgUpdate, Target: slug, Field: "status", From: actual.Spec.Status, To: desired.Spec.Status})
+	}
+	if !sameSet(desired.Spec.MFARequiredRoles, actual.Spec.MFARequiredRoles) {
+		changes = append(changes, Change{Kind: ChangeOrgUpdate, Target: slug, Field: "mfaRequiredRoles", From: actual.Spec.MFARequiredRoles, To: desired.Spec.MFARequiredRoles})
+	}
+
+	for _, key := range declarative.SettingKeys {
+		want, wantOK := desired.Spec.Settings[key]
+		have, haveOK := actual.Spec.Settings[key]
+		switch {
+		case wantOK && !haveOK:
+			changes = append(changes, Change{Kind: ChangeSettingUpdate, Target: key, To: want})
+		case !wantOK && haveOK:
+			changes = append(changes, Change{Kind: ChangeSettingRemove, Target: key, From: have, Destructive: true})
+		case wantOK && haveOK && !sameJSON(want, have):
+			changes = append(changes, Change{Kind: ChangeSettingUpdate, Target: key, From: have, To: want})
+		}
+	}
+
+	actualUsers := make(map[string]declarative.User, len(actual.Spec.Users))
+	for _, user := range actual.Spec.Users {
+		actualUsers[strings.ToLower(user.Email)] = user
+	}
+	desiredUsers := make(map[string]bool, len(desired.Spec.Users))
+	for _, user := range desired.Spec.Users {
+		email := strings.ToLower(user.Email)
+		desiredUsers[email] = true
+		have, ok := actualUsers[email]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeUserAdd, Target: email, To: map[string]any{
+				"displayName": user.DisplayName,
+				"roles":       user.Roles,
+			}})
+			continue
+		}
+		if user.DisplayName != "" && user.DisplayName != have.DisplayName {
+			changes = append(changes, Change{Kind: ChangeUserUpdate, Target: email, Field: "displayName", From: have.DisplayName, To: user.DisplayName})
+		}
+		if !sameSet(user.Roles, have.Roles) {
+			changes = append(changes, Change{Kind: ChangeUserUpdate, Target: email, Field: "roles", From: have.Roles, To: user.Roles})
+		}
+		if user.Status != "" && user.Status != have.Status {
+			changes = append(changes, Change{Kind: ChangeUserUpdate, Target: email, Field: "status", From: have.Status, To: user.Status})
+		}
+	}
+	for _, user := range actual.Spec.Users {
+		email := strings.ToLower(user.Email)
+		if !desiredUsers[email] && user.Status != removedStatus {
+			changes = append(changes, Change{Kind: ChangeUserRemove, Target: email, Field: "status", From: user.Status, To: removedStatus, Destructive: true})
+		}
+	}
+
+	actualAccounts := make(map[string]declarative.ServiceAccount, len(actual.Spec.ServiceAccounts))
+	for _, account := range actual.Spec.ServiceAccounts {
+		actualAccounts[account.Name] = account
+	}
+	desiredAccounts := make(map[string]bool, len(desired.Spec.ServiceAccounts))
+	for _, account := range desired.Spec.ServiceAccounts {
+		desiredAccounts[account.Name] = true
+		have, ok := actualAccounts[account.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeAccountAdd, Target: account.Name, To: map[string]any{"description": account.Description}})
+			continue
+		}
+		if account.Status != "" && account.Status != have.Status {
+			changes = append(changes, Change{Kind: ChangeAccountUpdate, Target: account.Name, Field: "status", From: have.Status, To: account.Status})
+		}
+	}
+	for _, account := range actual.Spec.ServiceAccounts {
+		if !desiredAccounts[account.Name] && account.Status != removedStatus {
+			changes = append(changes, Change{Kind: ChangeAccountRemove, Target: account.Name, Field: "status", From: account.Status, To: removedStatus, Destructive: true})
+		}
+	}
+	return changes
+}
+
+// sameChanges reports whether two change lists describe the same drift. The
+// pending set has been through JSONB, so both sides are compared as JSON.
+func sameChanges(a, b []Change) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return sameJSON(a, b)
+}
+
+func sameJSON(a, b any) bool {
+	var left, right any
+	if !normalize(a, &left) || !normalize(b, &right) {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func normalize(in any, out *any) bool {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	left := append([]string(nil), a...)
+	right := append([]string(nil), b...)
+	sort.Strings(left)
+	sort.Strings(right)
+	for i := range left {
+		if left[i] != right[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// Notice types posted to the webhook.
+const (
+	NoticeDriftDetected = "drift.detected"
+	NoticeDriftApplied  = "drift.applied"
+	NoticeDriftFailed   = "drift.failed"
+)
+
+// Notice describes a change set. drift.detected means it awaits approval.
+type Notice struct {
+	Type        string    `json:"type"`
+	OrgID       string    `json:"orgId"`
+	OrgSlug     string    `json:"orgSlug"`
+	ChangeSetID string    `json:"changeSetId"`
+	Revision    string    `json:"revision"`
+	Destructive bool      `json:"destructive"`
+	Changes     []Change  `json:"changes"`
+	Error       string    `json:"error,omitempty"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+// Notifier delivers notices. Failures are logged by the worker and not retried;
+// the change set stays visible through GET /v1/orgs/{orgId}/drift.
+type Notifier interface {
+	Notify(ctx context.Context, notice Notice) error
+}
+
+// NewNotifier returns a notifier posting to url, or logging when url is empty.
+func NewNotifier(url string, logger *zap.Logger) Notifier {
+	return webhookNotifier{notifier: notify.New(url, logger)}
+}
+
+type webhookNotifier struct {
+	notifier *notify.Notifier
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, notice Notice) error {
+	return n.notifier.Send(ctx, notice, "declarative drift notice",
+		zap.String("type", notice.Type),
+		zap.String("org_slug", notice.OrgSlug),
+		zap.String("change_set_id", notice.ChangeSetID),
+		zap.Int("changes", len(notice.Changes)),
+	)
+}
@@ -0,0 +1,52 @@
+package reconcile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+)
+
+// ErrNoDocument is returned by a Source that has no document for an org.
+var ErrNoDocument = errors.New("no declarative document for org")
+
+// Source provides the desired state of declarative orgs.
+type Source interface {
+	// Load returns the org's document and a revision identifying its content.
+	Load(ctx context.Context, slug string) (*declarative.Organization, string, error)
+}
+
+// DirSource reads <Dir>/<slug>.yaml, typically a checkout kept current by a
+// git-sync sidecar. The revision is derived from the file content, so it
+// changes whenever the document does regardless of which commit touched it.
+type DirSource struct {
+	Dir string
+}
+
+// Load implements Source.
+func (s DirSource) Load(_ context.Context, slug string) (*declarative.Organization, string, error) {
+	if slug == "" || filepath.Base(slug) != slug {
+		return nil, "", fmt.Errorf("invalid org slug %q", slug)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, slug+".yaml"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", ErrNoDocument
+		}
+		return nil, "", err
+	}
+	doc, err := declarative.Parse(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if doc.Metadata.Slug != slug {
+		return nil, "", fmt.Errorf("document %s.yaml declares slug %q", slug, doc.Metadata.Slug)
+	}
+	sum := sha256.Sum256(data)
+	return doc, hex.EncodeToString(sum[:])[:12], nil
+}
@@ -0,0 +1,488 @@
+// Package reconcile detects drift between declarative orgs and their GitOps
+// documents and applies approved changes.
+//
+// Purpose:
+//
+//	Orgs with declarative_mode "enabled" are described by a YAML document
+//	(see internal/declarative). Each pass compares the document with the
+//	org's live state. Drift is recorded as a change set on the org; it is
+//	applied immediately when the org's auto-apply policy allows, otherwise it
+//	waits for an admin to approve it via POST /v1/orgs/{orgId}/drift/pending/approve.
+//	Either way a webhook notice is sent and the set is kept in the org's drift
+//	history (GET /v1/orgs/{orgId}/drift).
+//
+// Dependencies:
+//   - internal/declarative: Document schema and Build (live state)
+//   - internal/storage/postgres: Org, user and service account updates
+//   - internal/security: Placeholder password hashes for added users
+//   - internal/audit: org.drift_detect and org.drift_apply events
+//
+// Key Responsibilities:
+//   - Worker.Run: Reconcile on an interval until the context is cancelled
+//   - Worker.RunOnce: One pass over every declarative org
+//   - Diff: Compute the changes between a document and live state
+//
+// Debugging Notes:
+//   - State lives in org metadata: declarative_pending (the open change set),
+//     declarative_drift_history (last 50 sets, newest first) and
+//     declarative_policy ({"autoApply": "none"|"non_destructive"|"all"},
+//     default non_destructive)
+//   - Destructive changes are removed settings and removed users or service
+//     accounts; removed principals are suspended, never deleted
+//   - A pending set is kept while the document revision and computed changes
+//     stay the same; if either moves, it is superseded by a new set
+//   - Added users are created "invited" with an unusable password; they sign
+//     in through SSO or a password reset
+//   - declarative_last_commit records the revision last applied
+//
+// Thread Safety:
+//   - Run must only be called once per Worker; RunOnce is not reentrant
+//
+// Error Handling:
+//   - Per-org failures are logged and never stop the pass
+//   - A change set that fails part-way is recorded as "failed"; the remaining
+//     drift is detected again on the next pass
+//   - RunOnce returns an error only when listing declarative orgs fails
+package reconcile
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// Store is the subset of postgres.Store the worker needs.
+type Store interface {
+	declarative.Store
+	ListDeclarativeOrgs(ctx context.Context) ([]postgres.Org, error)
+	UpdateOrg(ctx context.Context, params postgres.UpdateOrgParams) (postgres.Org, error)
+	CreateUser(ctx context.Context, params postgres.CreateUserParams) (postgres.User, error)
+	UpdateUserStatus(ctx context.Context, params postgres.UpdateUserStatusParams) (postgres.User, error)
+	UpdateUserProfile(ctx context.Context, params postgres.UpdateUserProfileParams) (postgres.User, error)
+	CreateServiceAccount(ctx context.Context, params postgres.CreateServiceAccountParams) (postgres.ServiceAccount, error)
+	UpdateServiceAccountStatus(ctx context.Context, orgID, serviceAccountID uuid.UUID, version int64, status string) (postgres.ServiceAccount, error)
+}
+
+// Config configures the worker.
+type Config struct {
+	Store    Store
+	Source   Source
+	Audit    audit.Emitter
+	Notifier Notifier
+	Logger   *zap.Logger
+	// Interval between passes.
+	Interval time.Duration
+}
+
+// Worker reconciles declarative orgs.
+type Worker struct {
+	store    Store
+	source   Source
+	audit    audit.Emitter
+	notifier Notifier
+	logger   *zap.Logger
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewWorker creates a worker from cfg, applying defaults for unset fields.
+func NewWorker(cfg Config) *Worker {
+	w := &Worker{
+		store:    cfg.Store,
+		source:   cfg.Source,
+		audit:    cfg.Audit,
+		notifier: cfg.Notifier,
+		logger:   cfg.Logger,
+		interval: cfg.Interval,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+	if w.logger == nil {
+		w.logger = zap.NewNop()
+	}
+	if w.audit == nil {
+		w.audit = audit.NewNoopEmitter()
+	}
+	if w.notifier == nil {
+		w.notifier = NewNotifier("", w.logger)
+	}
+	if w.interval <= 0 {
+		w.interval = 5 * time.Minute
+	}
+	return w
+}
+
+// Run reconciles immediately and then every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("reconciler worker started", zap.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("reconciliation pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			w.logger.Info("reconciler worker stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single pass.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	orgs, err := w.store.ListDeclarativeOrgs(ctx)
+	if err != nil {
+		return fmt.Errorf("list declarative orgs: %w", err)
+	}
+	for _, org := range orgs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := w.reconcileOrg(ctx, org); err != nil {
+			if errors.Is(err, postgres.ErrOptimisticLock) {
+				w.logger.Debug("org changed during reconciliation, retrying next pass", zap.String("org_slug", org.Slug))
+				continue
+			}
+			w.logger.Error("failed to reconcile org", zap.Error(err), zap.String("org_slug", org.Slug))
+		}
+	}
+	return nil
+}
+
+func (w *Worker) reconcileOrg(ctx context.Context, org postgres.Org) error {
+	doc, revision, err := w.source.Load(ctx, org.Slug)
+	if errors.Is(err, ErrNoDocument) {
+		w.logger.Debug("no declarative document for org", zap.String("org_slug", org.Slug))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load document: %w", err)
+	}
+	actual, err := declarative.Build(ctx, w.store, org)
+	if err != nil {
+		return fmt.Errorf("build live state: %w", err)
+	}
+	changes := Diff(doc, actual)
+	pending := PendingFromMetadata(org.Metadata)
+	now := w.now()
+
+	if len(changes) == 0 {
+		if pending == nil && org.DeclarativeLastCommit != nil && *org.DeclarativeLastCommit == revision {
+			return nil
+		}
+		// In sync: close out any pending set (the drift was fixed by hand)
+		history := HistoryFromMetadata(org.Metadata)
+		if pending != nil {
+			pending.Status = StatusSuperseded
+			history = withHistory(history, *pending)
+		}
+		_, err := w.store.UpdateOrg(ctx, bookkeeping(org, nil, history, revision))
+		return err
+	}
+
+	if pending != nil && pending.Revision == revision && sameChanges(pending.Changes, changes) {
+		if pending.Status != StatusApproved {
+			// Still awaiting a decision, or rejected until the document changes
+			return nil
+		}
+		return w.apply(ctx, org, doc, revision, pending)
+	}
+
+	set := &ChangeSet{
+		ID:         uuid.New().String(),
+		Revision:   revision,
+		DetectedAt: now,
+		Status:     StatusPending,
+		Changes:    changes,
+	}
+	history := HistoryFromMetadata(org.Metadata)
+	if pending != nil {
+		pending.Status = StatusSuperseded
+		history = withHistory(history, *pending)
+		org.Metadata = copyMetadata(org.Metadata)
+		org.Metadata[HistoryMetadataKey] = history
+	}
+
+	w.emitAudit(ctx, org, audit.ActionOrgDriftDetect, set)
+	if PolicyFromMetadata(org.Metadata).Allows(set) {
+		set.AutoApply = true
+		set.Status = StatusApproved
+		set.DecidedBy = "policy"
+		set.DecidedAt = &now
+		return w.apply(ctx, org, doc, revision, set)
+	}
+
+	if _, err := w.store.UpdateOrg(ctx, bookkeeping(org, set, history, derefString(org.DeclarativeLastCommit))); err != nil {
+		return err
+	}
+	w.notify(ctx, org, NoticeDriftDetected, set)
+	return nil
+}
+
+// apply makes the user and service account changes, then updates the org
+// fields, settings and bookkeeping in one write.
+func (w *Worker) apply(ctx context.Context, org postgres.Org, doc *declarative.Organization, revision string, set *ChangeSet) error {
+	history := HistoryFromMetadata(org.Metadata)
+	applyErr := w.applyPrincipals(ctx, org, doc, set.Changes)
+
+	params := bookkeeping(org, nil, nil, revision)
+	now := w.now()
+	if applyErr != nil {
+		set.Status = StatusFailed
+		set.Error = applyErr.Error()
+		params.DeclarativeLastCommit = org.DeclarativeLastCommit
+	} else {
+		set.Status = StatusApplied
+		set.AppliedAt = &now
+		params.Name = doc.Spec.Name
+		if doc.Spec.Status != "" {
+			params.Status = doc.Spec.Status
+		}
+		params.MFARequiredRoles = doc.Spec.MFARequiredRoles
+		for _, key := range declarative.SettingKeys {
+			if value, ok := doc.Spec.Settings[key]; ok {
+				params.Metadata[key] = value
+			} else {
+				delete(params.Metadata, key)
+			}
+		}
+	}
+	params.Metadata[HistoryMetadataKey] = withHistory(history, *set)
+
+	if _, err := w.store.UpdateOrg(ctx, params); err != nil {
+		return err
+	}
+	w.emitAudit(ctx, org, audit.ActionOrgDriftApply, set)
+	if applyErr != nil {
+		w.notify(ctx, org, NoticeDriftFailed, set)
+		return fmt.Errorf("apply change set %s: %w", set.ID, applyErr)
+	}
+	w.notify(ctx, org, NoticeDriftApplied, set)
+	return nil
+}
+
+func (w *Worker) applyPrincipals(ctx context.Context, org postgres.Org, doc *declarative.Organization, changes []Change) error {
+	users, err := w.store.ListUsersForOrg(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("list users: %w", err)
+	}
+	usersByEmail := make(map[string]postgres.User, len(users))
+	for _, user := range users {
+		usersByEmail[strings.ToLower(user.Email)] = user
+	}
+	accounts, err := w.store.ListServiceAccountsForOrg(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("list service accounts: %w", err)
+	}
+	accountsByName := make(map[string]postgres.ServiceAccount, len(accounts))
+	for _, account := range accounts {
+		accountsByName[account.Name] = account
+	}
+	desiredUsers := make(map[string]declarative.User, len(doc.Spec.Users))
+	for _, user := range doc.Spec.Users {
+		desiredUsers[strings.ToLower(user.Email)] = user
+	}
+	desiredAccounts := make(map[string]declarative.ServiceAccount, len(doc.Spec.ServiceAccounts))
+	for _, account := range doc.Spec.ServiceAccounts {
+		desiredAccounts[account.Name] = account
+	}
+
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeUserAdd:
+			created, err := w.createUser(ctx, org.ID, desiredUsers[change.Target])
+			if err != nil {
+				return fmt.Errorf("add user %s: %w", change.Target, err)
+			}
+			usersByEmail[change.Target] = created
+		case ChangeUserUpdate, ChangeUserRemove:
+			user, ok := usersByEmail[change.Target]
+			if !ok {
+				return fmt.Errorf("user %s not found", change.Target)
+			}
+			updated, err := w.updateUser(ctx, user, desiredUsers[change.Target], change)
+			if err != nil {
+				return fmt.Errorf("update user %s: %w", change.Target, err)
+			}
+			usersByEmail[change.Target] = updated
+		case ChangeAccountAdd:
+			account := desiredAccounts[change.Target]
+			params := postgres.CreateServiceAccountParams{
+				OrgID:  org.ID,
+				Name:   account.Name,
+				Status: account.Status,
+			}
+			if account.Description != "" {
+				params.Description = &account.Description
+			}
+			if _, err := w.store.CreateServiceAccount(ctx, params); err != nil {
+				return fmt.Errorf("add service account %s: %w", change.Target, err)
+			}
+		case ChangeAccountUpdate, ChangeAccountRemove:
+			account, ok := accountsByName[change.Target]
+			if !ok {
+				return fmt.Errorf("service account %s not found", change.Target)
+			}
+			updated, err := w.store.UpdateServiceAccountStatus(ctx, org.ID, account.ID, account.Version, fmt.Sprint(change.To))
+			if err != nil {
+				return fmt.Errorf("update service account %s: %w", change.Target, err)
+			}
+			accountsByName[change.Target] = updated
+		}
+	}
+	return nil
+}
+
+func (w *Worker) createUser(ctx context.Context, orgID uuid.UUID, user declarative.User) (postgres.User, error) {
+	// Declared users never sign in with this password
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return postgres.User{}, err
+	}
+	passwordHash, err := security.HashPassword(hex.EncodeToString(secret))
+	if err != nil {
+		return postgres.User{}, err
+	}
+	status := user.Status
+	if status == "" {
+		status = "invited"
+	}
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.Email
+	}
+	params := postgres.CreateUserParams{
+		OrgID:        orgID,
+		Email:        strings.ToLower(user.Email),
+		DisplayName:  displayName,
+		PasswordHash: passwordHash,
+		Status:       status,
+		Metadata:     map[string]any{"roles": user.Roles},
+	}
+	if user.ExternalIDP != "" {
+		params.ExternalIDP = &user.ExternalIDP
+	}
+	return w.store.CreateUser(ctx, params)
+}
+
+func (w *Worker) updateUser(ctx context.Context, user postgres.User, desired declarative.User, change Change) (postgres.User, error) {
+	if change.Field == "status" {
+		return w.store.UpdateUserStatus(ctx, postgres.UpdateUserStatusParams{
+			OrgID:        user.OrgID,
+			ID:           user.ID,
+			Version:      user.Version,
+			Status:       fmt.Sprint(change.To),
+			LockoutUntil: user.LockoutUntil,
+		})
+	}
+	params := postgres.UpdateUserProfileParams{
+		OrgID:       user.OrgID,
+		ID:          user.ID,
+		Version:     user.Version,
+		DisplayName: user.DisplayName,
+		MFAEnrolled: user.MFAEnrolled,
+		MFAMethods:  user.MFAMethods,
+		MFASecret:   user.MFASecret,
+		Metadata:    copyMetadata(user.Metadata),
+	}
+	switch change.Field {
+	case "displayName":
+		params.DisplayName = desired.DisplayName
+	case "roles":
+		params.Metadata["roles"] = desired.Roles
+	}
+	return w.store.UpdateUserProfile(ctx, params)
+}
+
+func (w *Worker) emitAudit(ctx context.Context, org postgres.Org, action string, set *ChangeSet) {
+	event := audit.BuildEvent(org.ID, uuid.Nil, audit.ActorTypeSystem, action, audit.TargetTypeOrg, &org.ID)
+	event.Metadata = map[string]any{
+		"change_set_id": set.ID,
+		"revision":      set.Revision,
+		"status":        set.Status,
+		"changes":       len(set.Changes),
+		"destructive":   set.Destructive(),
+	}
+	if err := w.audit.Emit(ctx, event); err != nil {
+		w.logger.Warn("failed to emit drift audit event", zap.Error(err), zap.String("org_slug", org.Slug))
+	}
+}
+
+func (w *Worker) notify(ctx context.Context, org postgres.Org, noticeType string, set *ChangeSet) {
+	notice := Notice{
+		Type:        noticeType,
+		OrgID:       org.ID.String(),
+		OrgSlug:     org.Slug,
+		ChangeSetID: set.ID,
+		Revision:    set.Revision,
+		Destructive: set.Destructive(),
+		Changes:     set.Changes,
+		Error:       set.Error,
+		OccurredAt:  w.now(),
+	}
+	if err := w.notifier.Notify(ctx, notice); err != nil {
+		w.logger.Warn("failed to send drift notice", zap.Error(err),
+			zap.String("org_slug", org.Slug),
+			zap.String("type", noticeType))
+	}
+}
+
+// bookkeeping returns UpdateOrg params that keep org's fields and set the
+// reconciler's metadata: pending (nil clears it), history (nil keeps it) and
+// the last applied revision.
+func bookkeeping(org postgres.Org, pending *ChangeSet, history []ChangeSet, revision string) postgres.UpdateOrgParams {
+	metadata := copyMetadata(org.Metadata)
+	if pending != nil {
+		metadata[PendingMetadataKey] = pending
+	} else {
+		delete(metadata, PendingMetadataKey)
+	}
+	if history != nil {
+		metadata[HistoryMetadataKey] = history
+	}
+	params := postgres.UpdateOrgParams{
+		ID:                 org.ID,
+		Version:            org.Version,
+		Name:               org.Name,
+		Status:             org.Status,
+		BillingOwnerUserID: org.BillingOwnerUserID,
+		BudgetPolicyID:     org.BudgetPolicyID,
+		DeclarativeMode:    org.DeclarativeMode,
+		DeclarativeRepoURL: org.DeclarativeRepoURL,
+		DeclarativeBranch:  org.DeclarativeBranch,
+		MFARequiredRoles:   org.MFARequiredRoles,
+		Metadata:           metadata,
+	}
+	if revision != "" {
+		params.DeclarativeLastCommit = &revision
+	}
+	return params
+}
+
+func copyMetadata(metadata map[string]any) map[string]any {
+	out := make(map[string]any, len(metadata)+2)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	return out
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,267 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/declarative"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type fakeStore struct {
+	org      postgres.Org
+	users    []postgres.User
+	accounts []postgres.ServiceAccount
+}
+
+func (s *fakeStore) GetOrg(_ context.Context, id uuid.UUID) (postgres.Org, error) {
+	if id != s.org.ID {
+		return postgres.Org{}, postgres.ErrNotFound
+	}
+	return s.org, nil
+}
+
+func (s *fakeStore) ListDeclarativeOrgs(context.Context) ([]postgres.Org, error) {
+	return []postgres.Org{s.org}, nil
+}
+
+func (s *fakeStore) ListUsersForOrg(context.Context, uuid.UUID) ([]postgres.User, error) {
+	return append([]postgres.User(nil), s.users...), nil
+}
+
+func (s *fakeStore) ListServiceAccountsForOrg(context.Context, uuid.UUID) ([]postgres.ServiceAccount, error) {
+	return append([]postgres.ServiceAccount(nil), s.accounts...), nil
+}
+
+func (s *fakeStore) ListAPIKeysForOrg(context.Context, uuid.UUID) ([]postgres.APIKey, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) UpdateOrg(_ context.Context, params postgres.UpdateOrgParams) (postgres.Org, error) {
+	if params.Version != s.org.Version {
+		return postgres.Org{}, postgres.ErrOptimisticLock
+	}
+	// Round-trip metadata through JSON as JSONB would
+	data, err := json.Marshal(params.Metadata)
+	if err != nil {
+		return postgres.Org{}, err
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return postgres.Org{}, err
+	}
+	s.org.Name = params.Name
+	s.org.Status = params.Status
+	s.org.MFARequiredRoles = params.MFARequiredRoles
+	s.org.DeclarativeLastCommit = params.DeclarativeLastCommit
+	s.org.Metadata = metadata
+	s.org.Version++
+	return s.org, nil
+}
+
+func (s *fakeStore) CreateUser(_ context.Context, params postgres.CreateUserParams) (postgres.User, error) {
+	user := postgres.User{ID: uuid.New(), OrgID: params.OrgID, Email: params.Email, DisplayName: params.DisplayName, Status: params.Status, Metadata: params.Metadata}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *fakeStore) UpdateUserStatus(_ context.Context, params postgres.UpdateUserStatusParams) (postgres.User, error) {
+	for i := range s.users {
+		if s.users[i].ID == params.ID {
+			s.users[i].Status = params.Status
+			s.users[i].Version++
+			return s.users[i], nil
+		}
+	}
+	return postgres.User{}, postgres.ErrNotFound
+}
+
+func (s *fakeStore) UpdateUserProfile(_ context.Context, params postgres.UpdateUserProfileParams) (postgres.User, error) {
+	for i := range s.users {
+		if s.users[i].ID == params.ID {
+			s.users[i].DisplayName = params.DisplayName
+			s.users[i].Metadata = params.Metadata
+			s.users[i].Version++
+			return s.users[i], nil
+		}
+	}
+	return postgres.User{}, postgres.ErrNotFound
+}
+
+func (s *fakeStore) CreateServiceAccount(_ context.Context, params postgres.CreateServiceAccountParams) (postgres.ServiceAccount, error) {
+	account := postgres.ServiceAccount{ID: uuid.New(), OrgID: params.OrgID, Name: params.Name, Status: "active"}
+	s.accounts = append(s.accounts, account)
+	return account, nil
+}
+
+func (s *fakeStore) UpdateServiceAccountStatus(_ context.Context, _, id uuid.UUID, _ int64, status string) (postgres.ServiceAccount, error) {
+	for i := range s.accounts {
+		if s.accounts[i].ID == id {
+			s.accounts[i].Status = status
+			s.accounts[i].Version++
+			return s.accounts[i], nil
+		}
+	}
+	return postgres.ServiceAccount{}, postgres.ErrNotFound
+}
+
+type staticSource struct {
+	doc      *declarative.Organization
+	revision string
+}
+
+func (s *staticSource) Load(context.Context, string) (*declarative.Organization, string, error) {
+	if s.doc == nil {
+		return nil, "", ErrNoDocument
+	}
+	return s.doc, s.revision, nil
+}
+
+type recordingNotifier struct {
+	notices []Notice
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notice Notice) error {
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+func newFixture(policy string) (*fakeStore, *staticSource, *recordingNotifier, *Worker) {
+	org := postgres.Org{ID: uuid.New(), Slug: "acme", Name: "Acme", Status: "active", DeclarativeMode: "enabled", Metadata: map[string]any{}}
+	if policy != "" {
+		org.Metadata[PolicyMetadataKey] = map[string]any{"autoApply": policy}
+	}
+	store := &fakeStore{
+		org: org,
+		users: []postgres.User{
+			{ID: uuid.New(), OrgID: org.ID, Email: "alice@acme.test", DisplayName: "Alice", Status: "active", Metadata: map[string]any{"roles": []any{"owner"}}},
+			{ID: uuid.New(), OrgID: org.ID, Email: "bob@acme.test", DisplayName: "Bob", Status: "active"},
+		},
+	}
+	source := &staticSource{revision: "rev1", doc: &declarative.Organization{
+		APIVersion: declarative.APIVersion,
+		Kind:       declarative.KindOrganization,
+		Metadata:   declarative.ObjectMeta{Slug: "acme"},
+		Spec: declarative.OrgSpec{
+			Name: "Acme",
+			Users: []declarative.User{
+				{Email: "alice@acme.test", Roles: []string{"owner"}},
+				{Email: "bob@acme.test"},
+			},
+		},
+	}}
+	notifier := &recordingNotifier{}
+	worker := NewWorker(Config{Store: store, Source: source, Notifier: notifier})
+	worker.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return store, source, notifier, worker
+}
+
+func TestRunOnceInSyncRecordsRevision(t *testing.T) {
+	store, _, notifier, worker := newFixture("")
+
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.NotNil(t, store.org.DeclarativeLastCommit)
+	require.Equal(t, "rev1", *store.org.DeclarativeLastCommit)
+	require.Empty(t, notifier.notices)
+
+	version := store.org.Version
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Equal(t, version, store.org.Version, "no write when nothing changed")
+}
+
+func TestRunOnceAutoAppliesNonDestructiveDrift(t *testing.T) {
+	store, source, notifier, worker := newFixture("")
+	source.doc.Spec.Name = "Acme Corp"
+	source.doc.Spec.ServiceAccounts = []declarative.ServiceAccount{{Name: "ci"}}
+
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Equal(t, "Acme Corp", store.org.Name)
+	require.Len(t, store.accounts, 1)
+	require.Nil(t, PendingFromMetadata(store.org.Metadata))
+
+	history := HistoryFromMetadata(store.org.Metadata)
+	require.Len(t, history, 1)
+	require.Equal(t, StatusApplied, history[0].Status)
+	require.True(t, history[0].AutoApply)
+	require.Len(t, notifier.notices, 1)
+	require.Equal(t, NoticeDriftApplied, notifier.notices[0].Type)
+}
+
+func TestRunOnceHoldsDestructiveDriftUntilApproved(t *testing.T) {
+	store, source, notifier, worker := newFixture("")
+	source.doc.Spec.Users = source.doc.Spec.Users[:1] // bob removed
+
+	require.NoError(t, worker.RunOnce(context.Background()))
+	pending := PendingFromMetadata(store.org.Metadata)
+	require.NotNil(t, pending)
+	require.Equal(t, StatusPending, pending.Status)
+	require.True(t, pending.Destructive())
+	require.Equal(t, "active", store.users[1].Status)
+	require.Len(t, notifier.notices, 1)
+	require.Equal(t, NoticeDriftDetected, notifier.notices[0].Type)
+
+	// Re-detecting the same drift keeps the same pending set and does not notify again
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Equal(t, pending.ID, PendingFromMetadata(store.org.Metadata).ID)
+	require.Len(t, notifier.notices, 1)
+
+	pending.Status = StatusApproved
+	store.org.Metadata[PendingMetadataKey] = pending
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Equal(t, "suspended", store.users[1].Status)
+	require.Nil(t, PendingFromMetadata(store.org.Metadata))
+	history := HistoryFromMetadata(store.org.Metadata)
+	require.Len(t, history, 1)
+	require.Equal(t, StatusApplied, history[0].Status)
+	require.Equal(t, pending.ID, history[0].ID)
+}
+
+func TestRunOnceSupersedesPendingOnNewRevision(t *testing.T) {
+	store, source, _, worker := newFixture(AutoApplyNone)
+	source.doc.Spec.Name = "Acme Corp"
+
+	require.NoError(t, worker.RunOnce(context.Background()))
+	first := PendingFromMetadata(store.org.Metadata)
+	require.NotNil(t, first)
+
+	source.doc.Spec.Name = "Acme Inc"
+	source.revision = "rev2"
+	require.NoError(t, worker.RunOnce(context.Background()))
+	second := PendingFromMetadata(store.org.Metadata)
+	require.NotNil(t, second)
+	require.NotEqual(t, first.ID, second.ID)
+	require.Equal(t, "rev2", second.Revision)
+	require.Equal(t, "Acme", store.org.Name)
+
+	history := HistoryFromMetadata(store.org.Metadata)
+	require.Len(t, history, 1)
+	require.Equal(t, StatusSuperseded, history[0].Status)
+}
+
+func TestDiffComparesRolesAsSetsAndFlagsRemovals(t *testing.T) {
+	actual := &declarative.Organization{
+		Metadata: declarative.ObjectMeta{Slug: "acme"},
+		Spec: declarative.OrgSpec{
+			Name:     "Acme",
+			Settings: map[string]any{"rate_limits": map[string]any{"requestsPerMinute": float64(60)}},
+			Users:    []declarative.User{{Email: "Alice@acme.test", Roles: []string{"owner", "admin"}}},
+		},
+	}
+	desired := &declarative.Organization{
+		Spec: declarative.OrgSpec{
+			Name:  "Acme",
+			Users: []declarative.User{{Email: "alice@acme.test", Roles: []string{"admin", "owner"}}},
+		},
+	}
+
+	changes := Diff(desired, actual)
+	require.Len(t, changes, 1)
+	require.Equal(t, ChangeSettingRemove, changes[0].Kind)
+	require.Equal(t, "rate_limits", changes[0].Target)
+	require.True(t, changes[0].Destructive)
+}
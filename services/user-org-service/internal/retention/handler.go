@@ -0,0 +1,24 @@
+package retention
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// ReportHandler serves GET /v1/admin/retention/report: a dry run listing the
+// soft-deleted rows the next scan would anonymize or delete. Nothing is
+// changed.
+func ReportHandler(w *Worker, logger *zap.Logger) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		report, err := w.Plan(r.Context())
+		if err != nil {
+			logger.Error("failed to build retention report", zap.Error(err))
+			http.Error(rw, "failed to build retention report", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(report)
+	}
+}
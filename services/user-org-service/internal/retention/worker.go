@@ -0,0 +1,282 @@
+// Package retention cleans up soft-deleted organizations, users and API keys.
+//
+// Purpose:
+//
+//	Soft deletes set deleted_at and leave the row behind forever. The worker
+//	periodically finds rows soft-deleted longer than RETENTION_PERIOD and,
+//	depending on RETENTION_MODE, anonymizes them in place (personal data
+//	scrubbed, row kept for foreign keys and history) or hard-deletes them.
+//	Every row handled is recorded as an audit event.
+//
+// Dependencies:
+//   - internal/storage/postgres: Candidate scan, AnonymizeSoftDeleted and PurgeSoftDeleted
+//   - internal/audit: retention.anonymize and retention.purge events
+//   - internal/metrics: Rows handled by entity and result
+//
+// Key Responsibilities:
+//   - Worker.Run: Scan on an interval until the context is cancelled
+//   - Worker.RunOnce: One pass; returns a Report of every row handled
+//   - Worker.Plan: A dry-run pass, served by ReportHandler
+//
+// Debugging Notes:
+//   - RETENTION_DRY_RUN defaults to true: passes only log and report what
+//     would be cleaned up (result "planned") until it is switched off
+//   - Entities are handled children first (API keys, users, orgs) so org
+//     purges find little left to delete
+//   - Anonymized rows carry metadata (annotations for keys) "anonymized_at"
+//     and are not picked up again; switching to delete mode purges them
+//   - Purging a user also deletes its sessions and user-owned API keys;
+//     purging an org deletes every row scoped to it
+//
+// Thread Safety:
+//   - Run must only be called once per Worker; RunOnce is not reentrant
+//
+// Error Handling:
+//   - Per-row failures are logged, reported and never stop the pass
+//   - RunOnce returns an error only when a candidate scan fails
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// entities are handled in this order, children first.
+var entities = []string{postgres.RetentionEntityAPIKey, postgres.RetentionEntityUser, postgres.RetentionEntityOrg}
+
+// targetTypes maps entities to audit target types.
+var targetTypes = map[string]string{
+	postgres.RetentionEntityAPIKey: audit.TargetTypeAPIKey,
+	postgres.RetentionEntityUser:   audit.TargetTypeUser,
+	postgres.RetentionEntityOrg:    audit.TargetTypeOrg,
+}
+
+// Row results.
+const (
+	ResultAnonymized = "anonymized"
+	ResultDeleted    = "deleted"
+	ResultPlanned    = "planned"
+	ResultSkipped    = "skipped"
+	ResultFailed     = "failed"
+)
+
+// ParseMode validates a RETENTION_MODE value.
+func ParseMode(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case postgres.RetentionModeAnonymize, postgres.RetentionModeDelete:
+		return m, nil
+	default:
+		return "", fmt.Errorf("retention mode must be %q or %q, got %q", postgres.RetentionModeAnonymize, postgres.RetentionModeDelete, mode)
+	}
+}
+
+// Store is the subset of postgres.Store the worker needs.
+type Store interface {
+	ListRetentionCandidates(ctx context.Context, entity, mode string, deletedBefore time.Time, limit int) ([]postgres.RetentionCandidate, error)
+	AnonymizeSoftDeleted(ctx context.Context, c postgres.RetentionCandidate, now time.Time) error
+	PurgeSoftDeleted(ctx context.Context, c postgres.RetentionCandidate) error
+}
+
+// Config configures the worker.
+type Config struct {
+	Store  Store
+	Audit  audit.Emitter
+	Logger *zap.Logger
+	// Period is how long rows stay soft-deleted before cleanup.
+	Period time.Duration
+	// Mode is postgres.RetentionModeAnonymize or postgres.RetentionModeDelete.
+	Mode string
+	// DryRun only reports the rows that would be cleaned up.
+	DryRun bool
+	// Interval between scans.
+	Interval time.Duration
+	// BatchSize caps rows of each entity handled per scan.
+	BatchSize int
+}
+
+// Item is one row in a Report.
+type Item struct {
+	Entity    string    `json:"entity"`
+	ID        string    `json:"id"`
+	OrgID     string    `json:"orgId"`
+	DeletedAt time.Time `json:"deletedAt"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Report lists the rows a pass handled, or would handle in a dry run.
+type Report struct {
+	Mode          string    `json:"mode"`
+	DryRun        bool      `json:"dryRun"`
+	DeletedBefore time.Time `json:"deletedBefore"`
+	Items         []Item    `json:"items"`
+}
+
+// Worker anonymizes or purges rows soft-deleted longer than the retention period.
+type Worker struct {
+	store     Store
+	audit     audit.Emitter
+	logger    *zap.Logger
+	period    time.Duration
+	mode      string
+	dryRun    bool
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+// NewWorker creates a worker from cfg, applying defaults for unset fields.
+// An unrecognized mode falls back to anonymize.
+func NewWorker(cfg Config) *Worker {
+	w := &Worker{
+		store:     cfg.Store,
+		audit:     cfg.Audit,
+		logger:    cfg.Logger,
+		period:    cfg.Period,
+		dryRun:    cfg.DryRun,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	if w.logger == nil {
+		w.logger = zap.NewNop()
+	}
+	if w.audit == nil {
+		w.audit = audit.NewNoopEmitter()
+	}
+	mode, err := ParseMode(cfg.Mode)
+	if err != nil {
+		mode = postgres.RetentionModeAnonymize
+	}
+	w.mode = mode
+	if w.period <= 0 {
+		w.period = 90 * 24 * time.Hour
+	}
+	if w.interval <= 0 {
+		w.interval = 24 * time.Hour
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = 100
+	}
+	return w
+}
+
+// Run scans immediately and then every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("retention worker started",
+		zap.Duration("interval", w.interval),
+		zap.Duration("period", w.period),
+		zap.String("mode", w.mode),
+		zap.Bool("dry_run", w.dryRun))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("retention scan failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			w.logger.Info("retention worker stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single scan, honouring the configured dry run.
+func (w *Worker) RunOnce(ctx context.Context) (Report, error) {
+	return w.pass(ctx, w.dryRun)
+}
+
+// Plan reports the rows the next scan would handle without changing them.
+func (w *Worker) Plan(ctx context.Context) (Report, error) {
+	return w.pass(ctx, true)
+}
+
+func (w *Worker) pass(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{
+		Mode:          w.mode,
+		DryRun:        dryRun,
+		DeletedBefore: w.now().Add(-w.period),
+		Items:         []Item{},
+	}
+	for _, entity := range entities {
+		candidates, err := w.store.ListRetentionCandidates(ctx, entity, w.mode, report.DeletedBefore, w.batchSize)
+		if err != nil {
+			return report, fmt.Errorf("list %s retention candidates: %w", entity, err)
+		}
+		for _, c := range candidates {
+			if ctx.Err() != nil {
+				return report, nil
+			}
+			report.Items = append(report.Items, w.handle(ctx, c, dryRun))
+		}
+	}
+
+	if len(report.Items) > 0 {
+		counts := map[string]int{}
+		for _, item := range report.Items {
+			counts[item.Result]++
+		}
+		w.logger.Info("retention scan complete",
+			zap.String("mode", w.mode),
+			zap.Bool("dry_run", dryRun),
+			zap.Time("deleted_before", report.DeletedBefore),
+			zap.Any("results", counts))
+	}
+	return report, nil
+}
+
+// handle anonymizes or purges one row and records the outcome.
+func (w *Worker) handle(ctx context.Context, c postgres.RetentionCandidate, dryRun bool) Item {
+	item := Item{Entity: c.Entity, ID: c.ID.String(), OrgID: c.OrgID.String(), DeletedAt: c.DeletedAt}
+	if dryRun {
+		item.Result = ResultPlanned
+		metrics.RecordRetention(c.Entity, item.Result)
+		return item
+	}
+
+	var err error
+	action := audit.ActionRetentionAnonymize
+	if w.mode == postgres.RetentionModeDelete {
+		action = audit.ActionRetentionPurge
+		err = w.store.PurgeSoftDeleted(ctx, c)
+		item.Result = ResultDeleted
+	} else {
+		err = w.store.AnonymizeSoftDeleted(ctx, c, w.now())
+		item.Result = ResultAnonymized
+	}
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		// Restored or already handled since the scan
+		item.Result = ResultSkipped
+	case err != nil:
+		item.Result = ResultFailed
+		item.Error = err.Error()
+		w.logger.Warn("failed to clean up soft-deleted row",
+			zap.Error(err),
+			zap.String("entity", c.Entity),
+			zap.String("id", item.ID),
+			zap.String("mode", w.mode))
+	default:
+		event := audit.BuildEvent(c.OrgID, uuid.Nil, audit.ActorTypeSystem, action, targetTypes[c.Entity], &c.ID)
+		event.Metadata = map[string]any{
+			"entity":           c.Entity,
+			"deleted_at":       c.DeletedAt.Format(time.RFC3339),
+			"retention_period": w.period.String(),
+		}
+		_ = w.audit.Emit(ctx, event)
+	}
+	metrics.RecordRetention(c.Entity, item.Result)
+	return item
+}
@@ -0,0 +1,117 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+type fakeStore struct {
+	candidates map[string][]postgres.RetentionCandidate
+	before     time.Time
+	anonymized []uuid.UUID
+	purged     []uuid.UUID
+	gone       map[uuid.UUID]bool
+}
+
+func (s *fakeStore) ListRetentionCandidates(_ context.Context, entity, _ string, deletedBefore time.Time, _ int) ([]postgres.RetentionCandidate, error) {
+	s.before = deletedBefore
+	return s.candidates[entity], nil
+}
+
+func (s *fakeStore) AnonymizeSoftDeleted(_ context.Context, c postgres.RetentionCandidate, _ time.Time) error {
+	if s.gone[c.ID] {
+		return postgres.ErrNotFound
+	}
+	s.anonymized = append(s.anonymized, c.ID)
+	return nil
+}
+
+func (s *fakeStore) PurgeSoftDeleted(_ context.Context, c postgres.RetentionCandidate) error {
+	s.purged = append(s.purged, c.ID)
+	return nil
+}
+
+type recordingEmitter struct {
+	events []audit.Event
+}
+
+func (e *recordingEmitter) Emit(_ context.Context, event audit.Event) error {
+	e.events = append(e.events, event)
+	return nil
+}
+
+func candidate(entity string) postgres.RetentionCandidate {
+	return postgres.RetentionCandidate{Entity: entity, ID: uuid.New(), OrgID: uuid.New(), DeletedAt: time.Now().Add(-100 * 24 * time.Hour)}
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	store := &fakeStore{candidates: map[string][]postgres.RetentionCandidate{
+		postgres.RetentionEntityUser: {candidate(postgres.RetentionEntityUser)},
+	}}
+	emitter := &recordingEmitter{}
+
+	report, err := NewWorker(Config{Store: store, Audit: emitter, DryRun: true}).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	require.True(t, report.DryRun)
+	require.Len(t, report.Items, 1)
+	require.Equal(t, ResultPlanned, report.Items[0].Result)
+	require.Empty(t, store.anonymized)
+	require.Empty(t, emitter.events)
+}
+
+func TestRunOnceAnonymizesAndAudits(t *testing.T) {
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	key := candidate(postgres.RetentionEntityAPIKey)
+	restored := candidate(postgres.RetentionEntityUser)
+	org := candidate(postgres.RetentionEntityOrg)
+	store := &fakeStore{
+		candidates: map[string][]postgres.RetentionCandidate{
+			postgres.RetentionEntityAPIKey: {key},
+			postgres.RetentionEntityUser:   {restored},
+			postgres.RetentionEntityOrg:    {org},
+		},
+		gone: map[uuid.UUID]bool{restored.ID: true},
+	}
+	emitter := &recordingEmitter{}
+
+	w := NewWorker(Config{Store: store, Audit: emitter, Period: 30 * 24 * time.Hour})
+	w.now = func() time.Time { return now }
+	report, err := w.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, now.Add(-30*24*time.Hour), store.before)
+	require.Equal(t, []uuid.UUID{key.ID, org.ID}, store.anonymized)
+	require.Equal(t, []string{ResultAnonymized, ResultSkipped, ResultAnonymized},
+		[]string{report.Items[0].Result, report.Items[1].Result, report.Items[2].Result})
+	require.Len(t, emitter.events, 2)
+	require.Equal(t, audit.ActionRetentionAnonymize, emitter.events[0].Action)
+	require.Equal(t, audit.TargetTypeOrg, emitter.events[1].TargetType)
+}
+
+func TestDeleteModePurges(t *testing.T) {
+	user := candidate(postgres.RetentionEntityUser)
+	store := &fakeStore{candidates: map[string][]postgres.RetentionCandidate{postgres.RetentionEntityUser: {user}}}
+	emitter := &recordingEmitter{}
+
+	_, err := NewWorker(Config{Store: store, Audit: emitter, Mode: "DELETE"}).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, []uuid.UUID{user.ID}, store.purged)
+	require.Equal(t, audit.ActionRetentionPurge, emitter.events[0].Action)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode(" Anonymize ")
+	require.NoError(t, err)
+	require.Equal(t, postgres.RetentionModeAnonymize, mode)
+	_, err = ParseMode("shred")
+	require.Error(t, err)
+}
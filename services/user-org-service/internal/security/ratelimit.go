@@ -0,0 +1,84 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AttemptLimiter counts attempts per key (client IP, email, ...) in fixed
+// windows. Redis is used when configured so the limit holds across replicas;
+// otherwise counts are kept in memory per process.
+type AttemptLimiter struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewAttemptLimiter allows limit attempts per key per window. Redis keys are
+// "<prefix>:attempts:<key>".
+func NewAttemptLimiter(client *redis.Client, prefix string, limit int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		client:  client,
+		prefix:  prefix,
+		limit:   limit,
+		window:  window,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow records an attempt for key and reports whether it is within the limit.
+// A limit <= 0 disables rate limiting.
+func (l *AttemptLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	if l.limit <= 0 {
+		return true, nil
+	}
+	if l.client != nil {
+		redisKey := fmt.Sprintf("%s:attempts:%s", l.prefix, key)
+		count, err := l.client.Incr(ctx, redisKey).Result()
+		if err != nil {
+			return false, fmt.Errorf("%s rate limiter: %w", l.prefix, err)
+		}
+		if count == 1 {
+			// First attempt opens the window
+			if err := l.client.Expire(ctx, redisKey, l.window).Err(); err != nil {
+				return false, fmt.Errorf("%s rate limiter: %w", l.prefix, err)
+			}
+		}
+		return count <= int64(l.limit), nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok || now.After(b.resetAt) {
+		// Drop expired buckets opportunistically so the map stays bounded
+		for k, v := range l.buckets {
+			if now.After(v.resetAt) {
+				delete(l.buckets, k)
+			}
+		}
+		b = &bucket{resetAt: now.Add(l.window)}
+		l.buckets[key] = b
+	}
+	b.count++
+	return b.count <= l.limit, nil
+}
+
+// Window returns the limiter's window, e.g. for a Retry-After header.
+func (l *AttemptLimiter) Window() time.Duration {
+	return l.window
+}
@@ -0,0 +1,71 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetTokenRoundTrip(t *testing.T) {
+	signer := NewResetTokenSigner(DeriveKey("hmac-secret", "password-reset"))
+	userID, orgID := uuid.New(), uuid.New()
+
+	token, issued, err := signer.Issue(userID, orgID, time.Hour)
+	require.NoError(t, err)
+
+	claims, err := signer.Parse(token, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, userID, claims.UserID)
+	require.Equal(t, orgID, claims.OrgID)
+	require.Equal(t, issued.Nonce, claims.Nonce)
+
+	other, _, err := signer.Issue(userID, orgID, time.Hour)
+	require.NoError(t, err)
+	require.NotEqual(t, token, other, "every link must be unique")
+
+	_, err = signer.Parse(token, issued.ExpiresAt)
+	require.ErrorIs(t, err, ErrResetTokenExpired)
+}
+
+func TestResetTokenRejectsTampering(t *testing.T) {
+	signer := NewResetTokenSigner(DeriveKey("hmac-secret", "password-reset"))
+	token, _, err := signer.Issue(uuid.New(), uuid.New(), time.Hour)
+	require.NoError(t, err)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	for _, bad := range []string{
+		"",
+		payload,
+		payload + ".",
+		payload + "x." + signature,
+		"e30." + signature,
+	} {
+		_, err := signer.Parse(bad, time.Now())
+		require.ErrorIs(t, err, ErrResetTokenInvalid, "token %q", bad)
+	}
+
+	otherPurpose := NewResetTokenSigner(DeriveKey("hmac-secret", "magic-link"))
+	_, err = otherPurpose.Parse(token, time.Now())
+	require.ErrorIs(t, err, ErrResetTokenInvalid)
+}
+
+func TestAttemptLimiterInMemory(t *testing.T) {
+	limiter := NewAttemptLimiter(nil, "test", 2, time.Hour)
+	ctx := context.Background()
+	for i, want := range []bool{true, true, false} {
+		allowed, err := limiter.Allow(ctx, "203.0.113.1")
+		require.NoError(t, err)
+		require.Equal(t, want, allowed, "attempt %d", i+1)
+	}
+	allowed, err := limiter.Allow(ctx, "203.0.113.2")
+	require.NoError(t, err)
+	require.True(t, allowed, "limits are per key")
+
+	allowed, err = NewAttemptLimiter(nil, "test", 0, time.Hour).Allow(ctx, "any")
+	require.NoError(t, err)
+	require.True(t, allowed, "a zero limit disables limiting")
+}
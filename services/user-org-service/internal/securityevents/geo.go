@@ -0,0 +1,141 @@
+package securityevents
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Location is what the geo table knows about an IP address.
+type Location struct {
+	ASN       uint32  `json:"asn,omitempty"`
+	ASOrg     string  `json:"asOrg,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GeoResolver maps client IPs to network and location data.
+type GeoResolver interface {
+	Lookup(ip netip.Addr) (Location, bool)
+}
+
+// GeoTable is a longest-prefix-match table loaded from CSV, typically an
+// export of a GeoIP/ASN database trimmed to the columns below.
+type GeoTable struct {
+	// prefixes are grouped by length so a lookup costs one map probe per
+	// distinct length rather than a scan over every range.
+	byBits map[int]map[netip.Prefix]Location
+	bits   []int // descending
+}
+
+// LoadGeoTable reads a GeoTable from path. Each row is
+// cidr,asn,as_org,country,latitude,longitude; blank lines, a header row and
+// lines starting with # are skipped.
+func LoadGeoTable(path string) (*GeoTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geo table: %w", err)
+	}
+	defer f.Close()
+	return ParseGeoTable(f)
+}
+
+// ParseGeoTable reads a GeoTable in LoadGeoTable's format.
+func ParseGeoTable(r io.Reader) (*GeoTable, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 6
+	reader.TrimLeadingSpace = true
+
+	table := &GeoTable{byBits: make(map[int]map[netip.Prefix]Location)}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geo table: %w", err)
+		}
+		if row == 1 && strings.EqualFold(record[0], "cidr") {
+			continue
+		}
+		prefix, loc, err := parseGeoRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("geo table row %d: %w", row, err)
+		}
+		bucket, ok := table.byBits[prefix.Bits()]
+		if !ok {
+			bucket = make(map[netip.Prefix]Location)
+			table.byBits[prefix.Bits()] = bucket
+			table.bits = append(table.bits, prefix.Bits())
+		}
+		bucket[prefix] = loc
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(table.bits)))
+	return table, nil
+}
+
+func parseGeoRecord(record []string) (netip.Prefix, Location, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+	if err != nil {
+		return netip.Prefix{}, Location{}, err
+	}
+	asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(record[1])), "AS"), 10, 32)
+	if err != nil {
+		return netip.Prefix{}, Location{}, fmt.Errorf("invalid asn %q", record[1])
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return netip.Prefix{}, Location{}, fmt.Errorf("invalid latitude %q", record[4])
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(record[5]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return netip.Prefix{}, Location{}, fmt.Errorf("invalid longitude %q", record[5])
+	}
+	return prefix.Masked(), Location{
+		ASN:       uint32(asn),
+		ASOrg:     strings.TrimSpace(record[2]),
+		Country:   strings.ToUpper(strings.TrimSpace(record[3])),
+		Latitude:  lat,
+		Longitude: lon,
+	}, nil
+}
+
+// Lookup returns the most specific range containing ip.
+func (t *GeoTable) Lookup(ip netip.Addr) (Location, bool) {
+	if t == nil || !ip.IsValid() {
+		return Location{}, false
+	}
+	ip = ip.Unmap()
+	for _, bits := range t.bits {
+		if bits > ip.BitLen() {
+			continue
+		}
+		prefix, err := ip.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if loc, ok := t.byBits[bits][prefix]; ok {
+			return loc, true
+		}
+	}
+	return Location{}, false
+}
+
+// distanceKM is the great-circle distance between two locations.
+func distanceKM(a, b Location) float64 {
+	const earthRadiusKM = 6371.0
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
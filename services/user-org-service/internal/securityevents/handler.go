@@ -0,0 +1,67 @@
+package securityevents
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// QueryResponse is the body of GET /v1/admin/security-events.
+type QueryResponse struct {
+	Events []Event `json:"events"`
+}
+
+// QueryHandler serves GET /v1/admin/security-events. Optional query
+// parameters: orgId, type, since (RFC 3339) and limit (default 100, max 1000).
+func QueryHandler(m *Monitor, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if m == nil {
+			http.Error(w, "security events require Redis", http.StatusServiceUnavailable)
+			return
+		}
+		query := r.URL.Query()
+		filter := Filter{Type: query.Get("type"), Limit: defaultQueryLimit}
+		if raw := query.Get("orgId"); raw != "" {
+			orgID, err := uuid.Parse(raw)
+			if err != nil {
+				http.Error(w, "invalid orgId", http.StatusBadRequest)
+				return
+			}
+			filter.OrgID = orgID
+		}
+		if raw := query.Get("since"); raw != "" {
+			since, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			filter.Since = since
+		}
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxQueryLimit {
+				http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+				return
+			}
+			filter.Limit = limit
+		}
+
+		events, err := m.Query(r.Context(), filter)
+		if err != nil {
+			logger.Error("failed to query security events", zap.Error(err))
+			http.Error(w, "failed to query security events", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(QueryResponse{Events: events})
+	}
+}
@@ -0,0 +1,496 @@
+// Package securityevents detects authentication anomalies and streams them
+// as security events for SIEM integration.
+//
+// Purpose:
+//
+//	Audit events record what happened; security events flag what looks
+//	wrong. The Monitor watches lockouts, logins, API key use and revocations
+//	and raises an event when a pattern crosses a threshold: an account locked
+//	out repeatedly, a login from a location no one could have reached since
+//	the previous one, an API key used from a network (ASN) it has never been
+//	used from, or a burst of key revocations in one org. Events are kept in
+//	Redis for the query endpoint and published to Kafka (or the log) and an
+//	optional webhook.
+//
+// Dependencies:
+//   - github.com/redis/go-redis/v9: Detector state and the queryable event store
+//   - github.com/segmentio/kafka-go: Event stream (security.events topic)
+//   - internal/metrics: Detection and delivery counters
+//
+// Key Responsibilities:
+//   - ObserveLockout: Repeated lockouts of one account within a window
+//   - ObserveLogin: Impossible travel between consecutive logins
+//   - ObserveAPIKeyUse: API key used from a new ASN
+//   - ObserveAPIKeyRevocation: Mass key revocation within an org
+//   - Query / QueryHandler: Recent events for GET /v1/admin/security-events
+//
+// Debugging Notes:
+//   - Impossible travel and new-ASN detection need a geo table
+//     (SECURITY_EVENTS_GEOIP_FILE); IPs missing from it are ignored
+//   - A key's first ASN is recorded silently; only later, different ASNs raise events
+//   - Travel shorter than 500 km never counts, since IP geolocation is coarse
+//   - Mass revocation raises one event per window, when the threshold is reached
+//   - Every Observe method is a no-op on a nil Monitor (Redis not configured)
+//
+// Thread Safety:
+//   - Monitor is safe for concurrent use
+//
+// Error Handling:
+//   - Detection never fails the calling request; Redis and delivery errors
+//     are logged and counted in user_org_service_security_event_deliveries_total
+package securityevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+)
+
+// Event types.
+const (
+	TypeRepeatedLockouts = "account.repeated_lockouts"
+	TypeImpossibleTravel = "account.impossible_travel"
+	TypeAPIKeyNewASN     = "api_key.new_asn"
+	TypeMassRevocation   = "api_key.mass_revocation"
+)
+
+// Severities, in increasing order.
+const (
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+const (
+	eventsKey = "security:events"
+	// maxStoredEvents caps the queryable store regardless of retention.
+	maxStoredEvents = 10000
+	// minTravelKM ignores short hops that IP geolocation cannot resolve reliably.
+	minTravelKM = 500
+	// loginHistoryTTL and keyASNTTL bound how long per-user and per-key history is kept.
+	loginHistoryTTL = 30 * 24 * time.Hour
+	keyASNTTL       = 90 * 24 * time.Hour
+	deliveryTimeout = 10 * time.Second
+)
+
+// Event is one detected anomaly. SubjectType/SubjectID name the account,
+// API key or org the event is about.
+type Event struct {
+	EventID     uuid.UUID      `json:"eventId"`
+	Type        string         `json:"type"`
+	Severity    string         `json:"severity"`
+	OrgID       uuid.UUID      `json:"orgId"`
+	SubjectType string         `json:"subjectType"`
+	SubjectID   string         `json:"subjectId"`
+	IPAddress   string         `json:"ipAddress,omitempty"`
+	Location    *Location      `json:"location,omitempty"`
+	Summary     string         `json:"summary"`
+	Details     map[string]any `json:"details,omitempty"`
+	OccurredAt  time.Time      `json:"occurredAt"`
+}
+
+// Config configures a Monitor.
+type Config struct {
+	Redis *redis.Client
+	// Geo resolves IPs for travel and ASN detection; nil disables both.
+	Geo GeoResolver
+	// Publishers receive every event (Kafka or log, plus an optional webhook).
+	Publishers []Publisher
+	Logger     *zap.Logger
+	// Retention is how long events stay queryable.
+	Retention time.Duration
+	// LockoutThreshold lockouts of one account within LockoutWindow raise an event.
+	LockoutThreshold int
+	LockoutWindow    time.Duration
+	// MaxTravelKMH is the fastest plausible speed between consecutive logins.
+	MaxTravelKMH float64
+	// RevocationThreshold revocations in one org within RevocationWindow raise an event.
+	RevocationThreshold int
+	RevocationWindow    time.Duration
+}
+
+// Monitor runs the detectors and delivers their events.
+type Monitor struct {
+	cfg      Config
+	redis    *redis.Client
+	geo      GeoResolver
+	logger   *zap.Logger
+	inflight sync.WaitGroup
+	now      func() time.Time
+}
+
+// New returns a Monitor, or nil when cfg.Redis is nil (every method is then a no-op).
+func New(cfg Config) *Monitor {
+	if cfg.Redis == nil {
+		return nil
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	return &Monitor{
+		cfg:    cfg,
+		redis:  cfg.Redis,
+		geo:    cfg.Geo,
+		logger: cfg.Logger.With(zap.String("component", "security-events")),
+		now:    time.Now,
+	}
+}
+
+// ObserveLockout records that a lockout was enforced on userID.
+func (m *Monitor) ObserveLockout(ctx context.Context, orgID, userID uuid.UUID, ip string) {
+	if m == nil || m.cfg.LockoutThreshold <= 0 {
+		return
+	}
+	count, err := m.windowCount(ctx, "security:lockouts:"+userID.String(), m.cfg.LockoutWindow)
+	if err != nil {
+		m.logger.Warn("failed to count lockouts", zap.Error(err), zap.String("user_id", userID.String()))
+		return
+	}
+	if count < int64(m.cfg.LockoutThreshold) {
+		return
+	}
+	m.emit(Event{
+		Type:        TypeRepeatedLockouts,
+		Severity:    SeverityHigh,
+		OrgID:       orgID,
+		SubjectType: "user",
+		SubjectID:   userID.String(),
+		IPAddress:   ip,
+		Summary:     fmt.Sprintf("account locked out %d times within %s", count, m.cfg.LockoutWindow),
+		Details: map[string]any{
+			"lockouts": count,
+			"window":   m.cfg.LockoutWindow.String(),
+		},
+	})
+}
+
+// login is the last successful login remembered per user.
+type login struct {
+	IP       string    `json:"ip"`
+	Location Location  `json:"location"`
+	At       time.Time `json:"at"`
+}
+
+// ObserveLogin compares a successful login's location with the user's previous one.
+func (m *Monitor) ObserveLogin(ctx context.Context, orgID, userID uuid.UUID, ip string) {
+	if m == nil || m.geo == nil {
+		return
+	}
+	loc, ok := m.geo.Lookup(parseAddr(ip))
+	if !ok {
+		return
+	}
+	current := login{IP: ip, Location: loc, At: m.now().UTC()}
+	key := "security:last_login:" + userID.String()
+
+	var previous login
+	raw, err := m.redis.Get(ctx, key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+	case err != nil:
+		m.logger.Warn("failed to load last login", zap.Error(err), zap.String("user_id", userID.String()))
+		return
+	default:
+		_ = json.Unmarshal(raw, &previous)
+	}
+	if payload, err := json.Marshal(current); err == nil {
+		if err := m.redis.Set(ctx, key, payload, loginHistoryTTL).Err(); err != nil {
+			m.logger.Warn("failed to store last login", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+	}
+	if previous.At.IsZero() {
+		return
+	}
+
+	km, kmh, impossible := impossibleTravel(previous, current, m.cfg.MaxTravelKMH)
+	if !impossible {
+		return
+	}
+	m.emit(Event{
+		Type:        TypeImpossibleTravel,
+		Severity:    SeverityHigh,
+		OrgID:       orgID,
+		SubjectType: "user",
+		SubjectID:   userID.String(),
+		IPAddress:   ip,
+		Location:    &loc,
+		Summary: fmt.Sprintf("login from %s %.0f km from the previous login (%s) %s earlier",
+			placeName(loc), km, placeName(previous.Location), current.At.Sub(previous.At).Round(time.Minute)),
+		Details: map[string]any{
+			"distanceKm":       math.Round(km),
+			"speedKmh":         math.Round(kmh),
+			"previousIp":       previous.IP,
+			"previousLocation": previous.Location,
+			"previousLoginAt":  previous.At.Format(time.RFC3339),
+		},
+	})
+}
+
+// impossibleTravel reports the distance and implied speed between two logins
+// and whether that speed exceeds maxKMH.
+func impossibleTravel(previous, current login, maxKMH float64) (km, kmh float64, impossible bool) {
+	km = distanceKM(previous.Location, current.Location)
+	// Logins within the same minute still imply finite (very high) speed
+	hours := math.Max(current.At.Sub(previous.At).Hours(), 1.0/60)
+	kmh = km / hours
+	return km, kmh, maxKMH > 0 && km >= minTravelKM && kmh > maxKMH
+}
+
+// ObserveAPIKeyUse records the ASN a validated API key was used from.
+func (m *Monitor) ObserveAPIKeyUse(ctx context.Context, orgID, apiKeyID uuid.UUID, ip string) {
+	if m == nil || m.geo == nil {
+		return
+	}
+	loc, ok := m.geo.Lookup(parseAddr(ip))
+	if !ok || loc.ASN == 0 {
+		return
+	}
+	key := "security:key_asns:" + apiKeyID.String()
+	pipe := m.redis.TxPipeline()
+	added := pipe.SAdd(ctx, key, loc.ASN)
+	known := pipe.SCard(ctx, key)
+	pipe.Expire(ctx, key, keyASNTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.logger.Warn("failed to record API key ASN", zap.Error(err), zap.String("api_key_id", apiKeyID.String()))
+		return
+	}
+	// The first ASN a key is seen from is its baseline
+	if added.Val() == 0 || known.Val() <= 1 {
+		return
+	}
+	m.emit(Event{
+		Type:        TypeAPIKeyNewASN,
+		Severity:    SeverityMedium,
+		OrgID:       orgID,
+		SubjectType: "api_key",
+		SubjectID:   apiKeyID.String(),
+		IPAddress:   ip,
+		Location:    &loc,
+		Summary:     fmt.Sprintf("API key used from new network AS%d %s", loc.ASN, loc.ASOrg),
+		Details: map[string]any{
+			"asn":       loc.ASN,
+			"asOrg":     loc.ASOrg,
+			"knownAsns": known.Val() - 1,
+		},
+	})
+}
+
+// ObserveAPIKeyRevocation records a revocation and flags bursts within an org.
+func (m *Monitor) ObserveAPIKeyRevocation(ctx context.Context, orgID, apiKeyID, actorID uuid.UUID, ip string) {
+	if m == nil || m.cfg.RevocationThreshold <= 0 {
+		return
+	}
+	count, err := m.windowCount(ctx, "security:revocations:"+orgID.String(), m.cfg.RevocationWindow)
+	if err != nil {
+		m.logger.Warn("failed to count revocations", zap.Error(err), zap.String("org_id", orgID.String()))
+		return
+	}
+	// Exactly at the threshold so a burst raises one event, not one per key
+	if count != int64(m.cfg.RevocationThreshold) {
+		return
+	}
+	m.emit(Event{
+		Type:        TypeMassRevocation,
+		Severity:    SeverityCritical,
+		OrgID:       orgID,
+		SubjectType: "org",
+		SubjectID:   orgID.String(),
+		IPAddress:   ip,
+		Summary:     fmt.Sprintf("%d API keys revoked within %s", count, m.cfg.RevocationWindow),
+		Details: map[string]any{
+			"revocations":  count,
+			"window":       m.cfg.RevocationWindow.String(),
+			"actorId":      actorID.String(),
+			"lastApiKeyId": apiKeyID.String(),
+		},
+	})
+}
+
+// windowCount increments a fixed-window counter, starting the window on the first hit.
+func (m *Monitor) windowCount(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := m.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := m.redis.Expire(ctx, key, window).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// emit stores and publishes an event in the background so detection never
+// delays the request that triggered it.
+func (m *Monitor) emit(event Event) {
+	event.EventID = uuid.New()
+	event.OccurredAt = m.now().UTC()
+	metrics.RecordSecurityEvent(event.Type)
+
+	m.inflight.Add(1)
+	go func() {
+		defer m.inflight.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		defer cancel()
+
+		m.deliver("store", event, func() error { return m.store(ctx, event) })
+		for _, publisher := range m.cfg.Publishers {
+			m.deliver(sinkName(publisher), event, func() error { return publisher.Publish(ctx, event) })
+		}
+	}()
+}
+
+func (m *Monitor) deliver(sink string, event Event, fn func() error) {
+	if err := fn(); err != nil {
+		metrics.RecordSecurityEventDelivery(sink, "failure")
+		m.logger.Error("failed to deliver security event",
+			zap.Error(err),
+			zap.String("sink", sink),
+			zap.String("event_id", event.EventID.String()),
+			zap.String("type", event.Type))
+		return
+	}
+	metrics.RecordSecurityEventDelivery(sink, "success")
+}
+
+func sinkName(publisher Publisher) string {
+	switch publisher.(type) {
+	case *KafkaPublisher:
+		return "kafka"
+	case *WebhookPublisher:
+		return "webhook"
+	case LogPublisher:
+		return "log"
+	default:
+		return "other"
+	}
+}
+
+// store adds the event to the queryable sorted set and trims it to the
+// retention period and size cap.
+func (m *Monitor) store(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal security event: %w", err)
+	}
+	cutoff := event.OccurredAt.Add(-m.cfg.Retention).UnixMilli()
+	pipe := m.redis.TxPipeline()
+	pipe.ZAdd(ctx, eventsKey, redis.Z{Score: float64(event.OccurredAt.UnixMilli()), Member: payload})
+	if m.cfg.Retention > 0 {
+		pipe.ZRemRangeByScore(ctx, eventsKey, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	}
+	pipe.ZRemRangeByRank(ctx, eventsKey, 0, -maxStoredEvents-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Filter selects events for Query. Zero values match everything.
+type Filter struct {
+	OrgID uuid.UUID
+	Type  string
+	Since time.Time
+	Limit int
+}
+
+// Query returns stored events matching f, newest first.
+func (m *Monitor) Query(ctx context.Context, f Filter) ([]Event, error) {
+	if m == nil {
+		return nil, errors.New("security events are not enabled")
+	}
+	min := "-inf"
+	if !f.Since.IsZero() {
+		min = strconv.FormatInt(f.Since.UnixMilli(), 10)
+	}
+	raw, err := m.redis.ZRevRangeByScore(ctx, eventsKey, &redis.ZRangeBy{Min: min, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("query security events: %w", err)
+	}
+	return filterEvents(raw, f), nil
+}
+
+func filterEvents(raw []string, f Filter) []Event {
+	events := make([]Event, 0)
+	for _, member := range raw {
+		var event Event
+		if err := json.Unmarshal([]byte(member), &event); err != nil {
+			continue
+		}
+		if f.OrgID != uuid.Nil && event.OrgID != f.OrgID {
+			continue
+		}
+		if f.Type != "" && event.Type != f.Type {
+			continue
+		}
+		events = append(events, event)
+		if f.Limit > 0 && len(events) == f.Limit {
+			break
+		}
+	}
+	return events
+}
+
+// Close waits for in-flight deliveries and closes publishers that hold connections.
+func (m *Monitor) Close() error {
+	if m == nil {
+		return nil
+	}
+	m.inflight.Wait()
+	var firstErr error
+	for _, publisher := range m.cfg.Publishers {
+		if closer, ok := publisher.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// ClientIP returns the originating client IP of r, preferring the first
+// X-Forwarded-For entry and X-Real-IP set by the ingress.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return strings.TrimSpace(realIP)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// parseAddr parses an IP with or without a port; invalid input yields the zero Addr.
+func parseAddr(raw string) netip.Addr {
+	raw = strings.TrimSpace(raw)
+	if host, _, err := net.SplitHostPort(raw); err == nil {
+		raw = host
+	}
+	addr, _ := netip.ParseAddr(strings.Trim(raw, "[]"))
+	return addr
+}
+
+func placeName(loc Location) string {
+	if loc.Country != "" {
+		return loc.Country
+	}
+	return fmt.Sprintf("%.2f,%.2f", loc.Latitude, loc.Longitude)
+}
@@ -0,0 +1,172 @@
+package securityevents
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const testGeoTable = `cidr,asn,as_org,country,latitude,longitude
+# comment rows are ignored
+203.0.113.0/24,AS64500,Example Transit,gb,51.5,-0.12
+203.0.113.128/25,64501,Example Mobile,GB,53.48,-2.24
+198.51.100.0/24,64502,Example Cloud,US,40.71,-74.0
+2001:db8::/32,64503,Example IPv6,DE,52.52,13.4
+`
+
+func TestGeoTableLongestPrefixMatch(t *testing.T) {
+	table, err := ParseGeoTable(strings.NewReader(testGeoTable))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	cases := map[string]uint32{
+		"203.0.113.10":        64500,
+		"203.0.113.200":       64501,
+		"::ffff:198.51.100.7": 64502,
+		"2001:db8:1::1":       64503,
+		"192.0.2.1":           0,
+		"not-an-ip":           0,
+		"[2001:db8::5]:443":   64503,
+		"198.51.100.9:52000":  64502,
+	}
+	for ip, want := range cases {
+		loc, ok := table.Lookup(parseAddr(ip))
+		if (want != 0) != ok || loc.ASN != want {
+			t.Errorf("Lookup(%s) = %+v, %v; want ASN %d", ip, loc, ok, want)
+		}
+	}
+	if loc, _ := table.Lookup(netip.MustParseAddr("203.0.113.10")); loc.Country != "GB" || loc.ASOrg != "Example Transit" {
+		t.Errorf("unexpected location %+v", loc)
+	}
+}
+
+func TestParseGeoTableRejectsBadRows(t *testing.T) {
+	for _, body := range []string{
+		"203.0.113.0/33,1,x,GB,0,0\n",
+		"203.0.113.0/24,ASX,x,GB,0,0\n",
+		"203.0.113.0/24,1,x,GB,91,0\n",
+		"203.0.113.0/24,1,x,GB,0\n",
+	} {
+		if _, err := ParseGeoTable(strings.NewReader(body)); err == nil {
+			t.Errorf("expected error for %q", body)
+		}
+	}
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	london := Location{Latitude: 51.5, Longitude: -0.12}
+	newYork := Location{Latitude: 40.71, Longitude: -74.0}
+	manchester := Location{Latitude: 53.48, Longitude: -2.24}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	km, kmh, impossible := impossibleTravel(login{Location: london, At: start}, login{Location: newYork, At: start.Add(time.Hour)}, 900)
+	if !impossible || km < 5500 || km > 5600 || kmh < 5500 {
+		t.Fatalf("London to New York in an hour: km=%.0f kmh=%.0f impossible=%v", km, kmh, impossible)
+	}
+	if _, _, impossible := impossibleTravel(login{Location: london, At: start}, login{Location: newYork, At: start.Add(8 * time.Hour)}, 900); impossible {
+		t.Fatal("London to New York in eight hours is a plausible flight")
+	}
+	// Short hops are geolocation noise even when instantaneous
+	if _, _, impossible := impossibleTravel(login{Location: london, At: start}, login{Location: manchester, At: start}, 900); impossible {
+		t.Fatal("London to Manchester should never count")
+	}
+}
+
+func TestFilterEvents(t *testing.T) {
+	orgA, orgB := uuid.New(), uuid.New()
+	var raw []string
+	for _, event := range []Event{
+		{Type: TypeMassRevocation, OrgID: orgA, Summary: "newest"},
+		{Type: TypeAPIKeyNewASN, OrgID: orgB},
+		{Type: TypeAPIKeyNewASN, OrgID: orgA},
+		{Type: TypeRepeatedLockouts, OrgID: orgA, Summary: "oldest"},
+	} {
+		payload, _ := json.Marshal(event)
+		raw = append(raw, string(payload))
+	}
+	raw = append(raw, "{corrupt")
+
+	if got := filterEvents(raw, Filter{OrgID: orgA}); len(got) != 3 || got[0].Summary != "newest" || got[2].Summary != "oldest" {
+		t.Fatalf("org filter returned %+v", got)
+	}
+	if got := filterEvents(raw, Filter{Type: TypeAPIKeyNewASN}); len(got) != 2 {
+		t.Fatalf("type filter returned %d events", len(got))
+	}
+	if got := filterEvents(raw, Filter{Limit: 1}); len(got) != 1 || got[0].Summary != "newest" {
+		t.Fatalf("limit returned %+v", got)
+	}
+}
+
+func TestNilMonitorIsNoop(t *testing.T) {
+	var m *Monitor
+	if New(Config{}) != nil {
+		t.Fatal("expected nil monitor without Redis")
+	}
+	ctx := context.Background()
+	m.ObserveLockout(ctx, uuid.New(), uuid.New(), "203.0.113.1")
+	m.ObserveLogin(ctx, uuid.New(), uuid.New(), "203.0.113.1")
+	m.ObserveAPIKeyUse(ctx, uuid.New(), uuid.New(), "203.0.113.1")
+	m.ObserveAPIKeyRevocation(ctx, uuid.New(), uuid.New(), uuid.New(), "203.0.113.1")
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	QueryHandler(m, zap.NewNop()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/security-events", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without Redis, got %d", rec.Code)
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	received := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	if NewWebhookPublisher("") != nil {
+		t.Fatal("expected nil publisher without a URL")
+	}
+	event := Event{EventID: uuid.New(), Type: TypeImpossibleTravel, Severity: SeverityHigh, OrgID: uuid.New()}
+	if err := NewWebhookPublisher(srv.URL).Publish(context.Background(), event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if got := <-received; got.EventID != event.EventID || got.Type != TypeImpossibleTravel {
+		t.Fatalf("webhook received %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewWebhookPublisher(failing.URL).Publish(context.Background(), event); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	if got := ClientIP(r); got != "10.0.0.1" {
+		t.Fatalf("RemoteAddr: got %q", got)
+	}
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.2")
+	if got := ClientIP(r); got != "203.0.113.9" {
+		t.Fatalf("X-Forwarded-For: got %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package securityevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/requestid"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/notify"
+)
+
+// Publisher delivers security events to a downstream consumer.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NewKafkaPublisher writes events to topic on the comma-separated brokers,
+// keyed by org so each org's events stay ordered. Returns nil when brokers
+// is empty.
+func NewKafkaPublisher(brokers, topic, clientID string) *KafkaPublisher {
+	if brokers == "" {
+		return nil
+	}
+	brokerList := strings.Split(brokers, ",")
+	for i := range brokerList {
+		brokerList[i] = strings.TrimSpace(brokerList[i])
+	}
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokerList...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: 5 * time.Second,
+		Transport:    &kafka.Transport{ClientID: clientID},
+	}}
+}
+
+// KafkaPublisher produces events as JSON.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// Publish writes one event synchronously.
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal security event: %w", err)
+	}
+	headers := []kafka.Header{
+		{Key: "event_id", Value: []byte(event.EventID.String())},
+		{Key: "type", Value: []byte(event.Type)},
+		{Key: "severity", Value: []byte(event.Severity)},
+	}
+	for key, value := range requestid.Headers(ctx) {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.OrgID.String()),
+		Value:   payload,
+		Headers: headers,
+		Time:    event.OccurredAt,
+	})
+}
+
+// Close flushes and closes the writer.
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// LogPublisher logs events when no broker is configured.
+type LogPublisher struct {
+	Logger *zap.Logger
+}
+
+// Publish logs the event at warn level so it stands out from audit noise.
+func (p LogPublisher) Publish(_ context.Context, event Event) error {
+	p.Logger.Warn("security event",
+		zap.String("event_id", event.EventID.String()),
+		zap.String("type", event.Type),
+		zap.String("severity", event.Severity),
+		zap.String("org_id", event.OrgID.String()),
+		zap.String("subject_type", event.SubjectType),
+		zap.String("subject_id", event.SubjectID),
+		zap.String("ip_address", event.IPAddress),
+		zap.String("summary", event.Summary),
+	)
+	return nil
+}
+
+// WebhookPublisher posts each event as JSON, e.g. to a SIEM HTTP collector.
+type WebhookPublisher struct {
+	webhook *notify.Webhook
+}
+
+// NewWebhookPublisher returns a publisher for url, or nil when url is empty.
+func NewWebhookPublisher(url string) *WebhookPublisher {
+	webhook := notify.NewWebhook(url)
+	if webhook == nil {
+		return nil
+	}
+	return &WebhookPublisher{webhook: webhook}
+}
+
+// Publish posts the event; any non-2xx response is an error.
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	return p.webhook.Post(ctx, event)
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/apiversion"
+	"github.com/ai-aas/shared-go/requestid"
+	sharedserver "github.com/ai-aas/shared-go/server"
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code
@@ -31,68 +35,97 @@ type Options struct {
 	ServiceName    string
 	Readiness      func(context.Context) error
 	RegisterRoutes func(chi.Router)
+	// EnableDebug exposes /debug/pprof (off by default).
+	EnableDebug bool
+	// DrainDelay keeps serving after readiness starts failing on shutdown.
+	DrainDelay time.Duration
+	// PreStopAddr serves POST /prestop on a separate listener; empty disables it.
+	PreStopAddr string
+	// CORS sets the browser origins allowed to call the service; nil uses
+	// DevelopmentCORS.
+	CORS *sharedserver.CORS
+	// Deprecations are routes announced with Deprecation/Sunset headers.
+	Deprecations []apiversion.Deprecation
+}
+
+// DevelopmentCORS allows the console dev server and any other localhost
+// port, with credentials, and caches preflights for an hour.
+func DevelopmentCORS() sharedserver.CORS {
+	return sharedserver.CORS{
+		AllowedOrigins:   []string{"http://localhost:*", "https://localhost:*"},
+		ExposedHeaders:   append([]string{"Retry-After"}, apiversion.ExposedHeaders...),
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+}
+
+// CORSForEnvironment returns the CORS policy for a deployment. Without
+// configured origins development environments fall back to DevelopmentCORS
+// and every other environment allows no cross-origin callers.
+func CORSForEnvironment(environment string, origins []string, maxAge time.Duration) sharedserver.CORS {
+	cors := DevelopmentCORS()
+	if len(origins) == 0 {
+		switch strings.ToLower(environment) {
+		case "development", "dev", "local":
+		default:
+			cors.AllowedOrigins = nil
+		}
+	} else {
+		cors.AllowedOrigins = origins
+	}
+	cors.MaxAge = maxAge
+	return cors
 }
 
-// New constructs an http.Server pre-configured with health and readiness routes.
-func New(opts Options) *http.Server {
+// New constructs a server pre-configured with health, readiness, startup and
+// metrics routes using the shared lifecycle conventions.
+func New(opts Options) *sharedserver.Server {
 	if opts.Readiness == nil {
 		opts.Readiness = func(context.Context) error { return nil }
 	}
 
+	lifecycle := sharedserver.New(sharedserver.Options{
+		Addr:          fmt.Sprintf(":%d", opts.Port),
+		Logger:        opts.Logger,
+		Readiness:     opts.Readiness,
+		DrainDelay:    opts.DrainDelay,
+		PreStopAddr:   opts.PreStopAddr,
+		EnableMetrics: true,
+		EnableDebug:   opts.EnableDebug,
+	})
+
 	router := chi.NewRouter()
 
-	// Helper function to check if origin is allowed
-	isAllowedOrigin := func(origin string) bool {
-		if origin == "" {
-			return false
-		}
-		return origin == "http://localhost:5173" || origin == "https://localhost:5173" ||
-			(len(origin) >= 17 && origin[:17] == "http://localhost:") ||
-			(len(origin) >= 18 && origin[:18] == "https://localhost:")
+	cors := DevelopmentCORS()
+	if opts.CORS != nil {
+		cors = *opts.CORS
 	}
-	
-	// CORS middleware for local development - must be first to handle OPTIONS
+
+	// CORS middleware - must be first to handle OPTIONS
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			origin := r.Header.Get("Origin")
+			allowed := cors.SetHeaders(w, r)
 
 			// Handle preflight OPTIONS requests - intercept before route matching
 			if r.Method == "OPTIONS" {
-				if isAllowedOrigin(origin) {
-					w.Header().Set("Access-Control-Allow-Origin", origin)
-					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token, X-Correlation-ID, X-API-Key")
-					w.Header().Set("Access-Control-Allow-Credentials", "true")
-					w.Header().Set("Access-Control-Max-Age", "3600")
-					
+				if allowed {
 					opts.Logger.Debug("CORS preflight request handled",
 						zap.String("method", r.Method),
 						zap.String("path", r.URL.Path),
-						zap.String("origin", origin))
+						zap.String("origin", r.Header.Get("Origin")))
 				}
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
 
-			// For actual requests, add CORS headers
-			if isAllowedOrigin(origin) {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Credentials", "true")
-			}
-
 			next.ServeHTTP(w, r)
 		})
 	})
 
-	// Helper function to add CORS headers (reuses isAllowedOrigin for consistency)
+	// Helper function to add CORS headers to router-level error responses
 	addCORSHeaders := func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if isAllowedOrigin(origin) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token, X-Correlation-ID, X-API-Key")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			w.Header().Set("Access-Control-Max-Age", "3600")
+		if w.Header().Get("Access-Control-Allow-Origin") == "" {
+			cors.SetHeaders(w, r)
 		}
 	}
 	
@@ -108,11 +141,11 @@ func New(opts Options) *http.Server {
 		opts.Logger.Warn("method not allowed",
 			zap.String("method", r.Method),
 			zap.String("path", r.URL.Path),
-			zap.String("request_id", middleware.GetReqID(r.Context())))
+			zap.String("request_id", requestid.RequestID(r.Context())))
 		
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		_, _ = w.Write([]byte(`{"error":"method not allowed","method":"` + r.Method + `","path":"` + r.URL.Path + `"}`))
+		writeRouteError(w, r, "method not allowed")
 	})
 	
 	// Set NotFound handler to add CORS headers and log missing routes
@@ -127,23 +160,28 @@ func New(opts Options) *http.Server {
 		opts.Logger.Warn("route not found",
 			zap.String("method", r.Method),
 			zap.String("path", r.URL.Path),
-			zap.String("request_id", middleware.GetReqID(r.Context())))
+			zap.String("request_id", requestid.RequestID(r.Context())))
 		
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
-		_, _ = w.Write([]byte(`{"error":"route not found","method":"` + r.Method + `","path":"` + r.URL.Path + `"}`))
+		writeRouteError(w, r, "route not found")
 	})
 
 	// Request logging and recovery middleware
-	router.Use(middleware.RequestID)
+	router.Use(requestid.Middleware)
 	router.Use(middleware.RealIP)
+	router.Use(apiversion.Middleware(apiversion.Config{
+		ServiceName:  opts.ServiceName,
+		Deprecations: opts.Deprecations,
+	}))
 	router.Use(middleware.Recoverer)
 	
 	// Comprehensive request/response logging middleware for debugging
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			requestID := middleware.GetReqID(r.Context())
+			requestID := requestid.RequestID(r.Context())
+			correlationID := requestid.CorrelationID(r.Context())
 			
 			// Log incoming request (key headers only to avoid verbosity)
 			fields := []zap.Field{
@@ -152,6 +190,7 @@ func New(opts Options) *http.Server {
 				zap.String("query", r.URL.RawQuery),
 				zap.String("remote_addr", r.RemoteAddr),
 				zap.String("request_id", requestID),
+				zap.String("correlation_id", correlationID),
 				zap.String("origin", r.Header.Get("Origin")),
 			}
 			
@@ -183,6 +222,7 @@ func New(opts Options) *http.Server {
 				zap.Int("status", ww.statusCode),
 				zap.Duration("duration_ms", duration),
 				zap.String("request_id", requestID),
+				zap.String("correlation_id", correlationID),
 			}
 			
 			// Log CORS headers if present
@@ -194,30 +234,11 @@ func New(opts Options) *http.Server {
 		})
 	})
 
-	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	})
-
-	router.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-		defer cancel()
-
-		if err := opts.Readiness(ctx); err != nil {
-			opts.Logger.Warn("readiness check failed", zap.Error(err))
-			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
-			return
-		}
+	// Probes are served by the shared lifecycle handler; they are also registered
+	// here so they appear in /debug/routes.
+	router.Get("/healthz", lifecycle.LivenessHandler)
+	router.Get("/readyz", lifecycle.ReadinessHandler)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ready"}`))
-	})
-
-	// Prometheus metrics endpoint
-	router.Get("/metrics", promhttp.Handler().ServeHTTP)
-	
 	// Debug endpoint to list registered routes (development only)
 	router.Get("/debug/routes", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -250,9 +271,18 @@ func New(opts Options) *http.Server {
 		opts.RegisterRoutes(router)
 	}
 
-	addr := fmt.Sprintf(":%d", opts.Port)
-	return &http.Server{
-		Addr:    addr,
-		Handler: router,
-	}
+	lifecycle.SetHandler(router)
+	return lifecycle
+}
+
+// writeRouteError writes the JSON body for unrouted requests, echoing the
+// request and correlation IDs so callers can quote them when reporting issues.
+func writeRouteError(w http.ResponseWriter, r *http.Request, message string) {
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":          message,
+		"method":         r.Method,
+		"path":           r.URL.Path,
+		"request_id":     requestid.RequestID(r.Context()),
+		"correlation_id": requestid.CorrelationID(r.Context()),
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -304,6 +305,39 @@ func TestCORS_AllowedHeaders(t *testing.T) {
 	assert.Contains(t, allowedHeaders, "Authorization")
 }
 
+func TestCORS_ConfiguredOrigins(t *testing.T) {
+	cors := CORSForEnvironment("production", []string{"https://console.example.com"}, 10*time.Minute)
+	srv := New(Options{
+		Port:   8081,
+		Logger: zap.NewNop(),
+		CORS:   &cors,
+		RegisterRoutes: func(r chi.Router) {
+			r.Get("/test", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+		},
+	})
+	handler := srv.Handler()
+
+	req := httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://console.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://console.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "localhost is only allowed by default in development")
+
+	assert.Empty(t, CORSForEnvironment("production", nil, time.Hour).AllowedOrigins)
+	assert.NotEmpty(t, CORSForEnvironment("development", nil, time.Hour).AllowedOrigins)
+}
+
 // setupTestServer creates a test server with the given route registration function
 // Returns the HTTP handler (router) for direct testing
 func setupTestServer(t *testing.T, registerRoutes func(chi.Router)) http.Handler {
@@ -317,5 +351,5 @@ func setupTestServer(t *testing.T, registerRoutes func(chi.Router)) http.Handler
 		RegisterRoutes: registerRoutes,
 	})
 
-	return srv.Handler
+	return srv.Handler()
 }
@@ -0,0 +1,68 @@
+package signingkeys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopeVersion prefixes every ciphertext so the format can change later.
+const envelopeVersion byte = 1
+
+// sealer encrypts private keys with AES-256-GCM.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// ParseEncryptionKey decodes a base64-encoded 32-byte key (JWT_KEY_ENCRYPTION_KEY).
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode signing key encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("signing key encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("signing key encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal returns version || nonce || ciphertext. additionalData binds the
+// ciphertext to its row (the kid) so it cannot be swapped between keys.
+func (s *sealer) seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	out := make([]byte, 0, 1+len(nonce)+len(plaintext)+s.aead.Overhead())
+	out = append(out, envelopeVersion)
+	out = append(out, nonce...)
+	return s.aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+func (s *sealer) open(envelope, additionalData []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(envelope) < 1+nonceSize || envelope[0] != envelopeVersion {
+		return nil, errors.New("unsupported signing key envelope")
+	}
+	nonce := envelope[1 : 1+nonceSize]
+	return s.aead.Open(nil, nonce, envelope[1+nonceSize:], additionalData)
+}
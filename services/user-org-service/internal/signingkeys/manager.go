@@ -0,0 +1,290 @@
+// Package signingkeys generates, stores, rotates and retires JWT signing keys.
+//
+// Purpose:
+//
+//	JWT access tokens must verify on every replica, and signing keys must
+//	change regularly without invalidating tokens already issued. The
+//	manager keeps the keys in Postgres, encrypted with
+//	JWT_KEY_ENCRYPTION_KEY, rotates the active key on a schedule, keeps the
+//	previous key verifying for a grace window and loads the result into the
+//	oauth.KeySet that signs tokens and backs /.well-known/jwks.json.
+//
+// Dependencies:
+//   - internal/storage/postgres: signing_keys table
+//   - internal/oauth: KeySet signer
+//   - internal/audit: signing_key.rotate and signing_key.retire events
+//
+// Key Responsibilities:
+//   - Manager.Run: Sync on an interval until the context is cancelled
+//   - Manager.RunOnce: Retire keys past their grace window, rotate the
+//     active key when it is older than the rotation interval, reload the key set
+//
+// Debugging Notes:
+//   - Every replica runs the manager; the store allows one active key, so
+//     when replicas rotate at the same time one wins and the others reload
+//   - Retired keys have their private key erased
+//   - A key that fails to decrypt (wrong JWT_KEY_ENCRYPTION_KEY) fails the
+//     sync if it is the active key and is skipped otherwise
+//   - Replicas pick up a rotation within SyncInterval; the grace window must
+//     cover that plus the access token lifespan
+//
+// Thread Safety:
+//   - Run must only be called once per Manager; RunOnce is not reentrant
+//   - KeySet is safe for concurrent use
+//
+// Error Handling:
+//   - RunOnce returns store and crypto errors; Run logs them and retries next tick
+package signingkeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/oauth"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// keyBits is the RSA modulus size of generated signing keys.
+const keyBits = 2048
+
+// Store is the subset of postgres.Store the manager needs.
+type Store interface {
+	ListSigningKeys(ctx context.Context) ([]postgres.SigningKey, error)
+	RotateSigningKey(ctx context.Context, params postgres.RotateSigningKeyParams) (postgres.SigningKey, error)
+	RetireSigningKeys(ctx context.Context, now time.Time) ([]postgres.SigningKey, error)
+}
+
+// Config configures the manager.
+type Config struct {
+	Store Store
+	// EncryptionKey is the 32-byte AES key protecting stored private keys.
+	EncryptionKey []byte
+	Audit         audit.Emitter
+	Logger        *zap.Logger
+	// RotationInterval is the age at which the active key is replaced.
+	RotationInterval time.Duration
+	// GracePeriod is how long a replaced key keeps verifying tokens.
+	GracePeriod time.Duration
+	// SyncInterval between reloads of the key set.
+	SyncInterval time.Duration
+}
+
+// Manager owns the persisted signing keys and the key set built from them.
+type Manager struct {
+	store            Store
+	sealer           *sealer
+	audit            audit.Emitter
+	logger           *zap.Logger
+	rotationInterval time.Duration
+	gracePeriod      time.Duration
+	syncInterval     time.Duration
+	keys             *oauth.KeySet
+	now              func() time.Time
+	generateKey      func() (*rsa.PrivateKey, error)
+}
+
+// NewManager creates a manager from cfg, applying defaults for unset fields.
+// The key set is empty until the first RunOnce succeeds.
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Store == nil {
+		return nil, errors.New("signing keys: store is required")
+	}
+	s, err := newSealer(cfg.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		store:            cfg.Store,
+		sealer:           s,
+		audit:            cfg.Audit,
+		logger:           cfg.Logger,
+		rotationInterval: cfg.RotationInterval,
+		gracePeriod:      cfg.GracePeriod,
+		syncInterval:     cfg.SyncInterval,
+		keys:             new(oauth.KeySet),
+		now:              func() time.Time { return time.Now().UTC() },
+		generateKey:      func() (*rsa.PrivateKey, error) { return rsa.GenerateKey(rand.Reader, keyBits) },
+	}
+	if m.logger == nil {
+		m.logger = zap.NewNop()
+	}
+	if m.audit == nil {
+		m.audit = audit.NewNoopEmitter()
+	}
+	if m.rotationInterval <= 0 {
+		m.rotationInterval = 24 * time.Hour
+	}
+	if m.gracePeriod <= 0 {
+		m.gracePeriod = 2 * time.Hour
+	}
+	if m.syncInterval <= 0 {
+		m.syncInterval = time.Minute
+	}
+	return m, nil
+}
+
+// KeySet returns the key set the manager keeps up to date.
+func (m *Manager) KeySet() *oauth.KeySet {
+	return m.keys
+}
+
+// Run syncs every SyncInterval until ctx is cancelled. Call RunOnce first
+// so the key set is populated before tokens are issued.
+func (m *Manager) Run(ctx context.Context) {
+	m.logger.Info("signing key manager started",
+		zap.Duration("rotation_interval", m.rotationInterval),
+		zap.Duration("grace_period", m.gracePeriod))
+	ticker := time.NewTicker(m.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("signing key manager stopping")
+			return
+		case <-ticker.C:
+			if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Error("signing key sync failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunOnce retires expired keys, rotates the active key when due and reloads
+// the key set.
+func (m *Manager) RunOnce(ctx context.Context) error {
+	now := m.now()
+	retired, err := m.store.RetireSigningKeys(ctx, now)
+	if err != nil {
+		return fmt.Errorf("retire signing keys: %w", err)
+	}
+	for _, key := range retired {
+		m.logger.Info("retired JWT signing key", zap.String("kid", key.KID))
+		m.emit(ctx, audit.ActionSigningKeyRetire, map[string]any{"kid": key.KID})
+	}
+
+	keys, err := m.store.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("list signing keys: %w", err)
+	}
+	active := activeKey(keys)
+	if active == nil || !active.CreatedAt.Add(m.rotationInterval).After(now) {
+		if err := m.rotate(ctx, active, now); err != nil {
+			return err
+		}
+		if keys, err = m.store.ListSigningKeys(ctx); err != nil {
+			return fmt.Errorf("list signing keys: %w", err)
+		}
+	}
+	return m.load(keys, now)
+}
+
+// rotate generates a key and makes it active, retiring previous.
+func (m *Manager) rotate(ctx context.Context, previous *postgres.SigningKey, now time.Time) error {
+	key, err := m.generateKey()
+	if err != nil {
+		return fmt.Errorf("generate signing key: %w", err)
+	}
+	kid, err := oauth.SigningKeyID(key)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshal signing key: %w", err)
+	}
+	ciphertext, err := m.sealer.seal(der, []byte(kid))
+	if err != nil {
+		return fmt.Errorf("encrypt signing key: %w", err)
+	}
+
+	params := postgres.RotateSigningKeyParams{
+		KID:                  kid,
+		Algorithm:            "RS256",
+		PrivateKeyCiphertext: ciphertext,
+	}
+	metadata := map[string]any{"kid": kid}
+	if previous != nil {
+		params.PreviousKID = previous.KID
+		params.VerifyUntil = now.Add(m.gracePeriod)
+		metadata["previous_kid"] = previous.KID
+		metadata["previous_verify_until"] = params.VerifyUntil.Format(time.RFC3339)
+	}
+	if _, err := m.store.RotateSigningKey(ctx, params); err != nil {
+		if errors.Is(err, postgres.ErrConflict) || errors.Is(err, postgres.ErrOptimisticLock) {
+			// Another replica rotated first; its key is picked up by the reload
+			m.logger.Debug("signing key rotated concurrently", zap.String("kid", kid))
+			return nil
+		}
+		return fmt.Errorf("store signing key: %w", err)
+	}
+
+	m.logger.Info("rotated JWT signing key", zap.String("kid", kid), zap.String("previous_kid", params.PreviousKID))
+	m.emit(ctx, audit.ActionSigningKeyRotate, metadata)
+	return nil
+}
+
+// load decrypts keys and replaces the key set, active key first.
+func (m *Manager) load(keys []postgres.SigningKey, now time.Time) error {
+	active := activeKey(keys)
+	if active == nil {
+		return errors.New("signing keys: no active key")
+	}
+	signer, err := m.decrypt(*active)
+	if err != nil {
+		return err
+	}
+	entries := []oauth.KeySetEntry{{Key: signer}}
+	for _, key := range keys {
+		if key.Status != postgres.SigningKeyStatusRetiring || key.VerifyUntil == nil || !key.VerifyUntil.After(now) {
+			continue
+		}
+		private, err := m.decrypt(key)
+		if err != nil {
+			m.logger.Error("skipping undecryptable signing key", zap.String("kid", key.KID), zap.Error(err))
+			continue
+		}
+		entries = append(entries, oauth.KeySetEntry{Key: private, VerifyUntil: *key.VerifyUntil})
+	}
+	return m.keys.Replace(entries)
+}
+
+func (m *Manager) decrypt(key postgres.SigningKey) (*rsa.PrivateKey, error) {
+	der, err := m.sealer.open(key.PrivateKeyCiphertext, []byte(key.KID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt signing key %s: %w", key.KID, err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key %s: %w", key.KID, err)
+	}
+	private, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is %T, not RSA", key.KID, parsed)
+	}
+	return private, nil
+}
+
+// emit records a platform-wide audit event for a key lifecycle change.
+func (m *Manager) emit(ctx context.Context, action string, metadata map[string]any) {
+	event := audit.BuildEvent(uuid.Nil, uuid.Nil, audit.ActorTypeSystem, action, audit.TargetTypeSigningKey, nil)
+	event.Metadata = metadata
+	_ = m.audit.Emit(ctx, event)
+}
+
+func activeKey(keys []postgres.SigningKey) *postgres.SigningKey {
+	for i := range keys {
+		if keys[i].Status == postgres.SigningKeyStatusActive {
+			return &keys[i]
+		}
+	}
+	return nil
+}
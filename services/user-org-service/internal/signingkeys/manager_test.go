@@ -0,0 +1,203 @@
+package signingkeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/audit"
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/storage/postgres"
+)
+
+// fakeStore mimics the signing_keys table, including its single active key rule.
+type fakeStore struct {
+	mu   sync.Mutex
+	keys []postgres.SigningKey // oldest first
+	now  time.Time
+}
+
+func (s *fakeStore) ListSigningKeys(context.Context) ([]postgres.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []postgres.SigningKey
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if s.keys[i].Status != postgres.SigningKeyStatusRetired {
+			out = append(out, s.keys[i])
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) RotateSigningKey(_ context.Context, params postgres.RotateSigningKeyParams) (postgres.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].Status != postgres.SigningKeyStatusActive {
+			continue
+		}
+		if s.keys[i].KID != params.PreviousKID {
+			return postgres.SigningKey{}, postgres.ErrConflict
+		}
+		verifyUntil := params.VerifyUntil
+		s.keys[i].Status = postgres.SigningKeyStatusRetiring
+		s.keys[i].VerifyUntil = &verifyUntil
+	}
+	key := postgres.SigningKey{
+		KID:                  params.KID,
+		Algorithm:            params.Algorithm,
+		PrivateKeyCiphertext: params.PrivateKeyCiphertext,
+		Status:               postgres.SigningKeyStatusActive,
+		CreatedAt:            s.now,
+	}
+	s.keys = append(s.keys, key)
+	return key, nil
+}
+
+func (s *fakeStore) RetireSigningKeys(_ context.Context, now time.Time) ([]postgres.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []postgres.SigningKey
+	for i := range s.keys {
+		key := &s.keys[i]
+		if key.Status == postgres.SigningKeyStatusRetiring && !key.VerifyUntil.After(now) {
+			key.Status = postgres.SigningKeyStatusRetired
+			key.PrivateKeyCiphertext = nil
+			out = append(out, *key)
+		}
+	}
+	return out, nil
+}
+
+type recordingEmitter struct {
+	events []audit.Event
+}
+
+func (e *recordingEmitter) Emit(_ context.Context, event audit.Event) error {
+	e.events = append(e.events, event)
+	return nil
+}
+
+func (e *recordingEmitter) actions() []string {
+	out := make([]string, 0, len(e.events))
+	for _, event := range e.events {
+		out = append(out, event.Action)
+	}
+	return out
+}
+
+var testEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestManager(t *testing.T, store *fakeStore, emitter audit.Emitter, clock *time.Time) *Manager {
+	t.Helper()
+	m, err := NewManager(Config{
+		Store:            store,
+		EncryptionKey:    testEncryptionKey,
+		Audit:            emitter,
+		RotationInterval: 24 * time.Hour,
+		GracePeriod:      2 * time.Hour,
+	})
+	require.NoError(t, err)
+	m.now = func() time.Time { return *clock }
+	// Small keys keep the test fast; production keys are 2048 bits
+	m.generateKey = func() (*rsa.PrivateKey, error) { return rsa.GenerateKey(rand.Reader, 1024) }
+	return m
+}
+
+func TestManagerRotatesAndRetires(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := &fakeStore{now: clock}
+	emitter := &recordingEmitter{}
+	m := newTestManager(t, store, emitter, &clock)
+
+	// First sync creates the active key
+	require.NoError(t, m.RunOnce(ctx))
+	require.Len(t, store.keys, 1)
+	firstKID := store.keys[0].KID
+	require.NotContains(t, string(store.keys[0].PrivateKeyCiphertext), "PRIVATE KEY")
+	oldToken, _, err := m.KeySet().Generate(ctx, jwt.MapClaims{"sub": "user-1"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	// Nothing is due an hour later
+	clock = clock.Add(time.Hour)
+	require.NoError(t, m.RunOnce(ctx))
+	require.Len(t, store.keys, 1)
+
+	// Past the rotation interval a new key signs and the old one still verifies
+	clock = clock.Add(24 * time.Hour)
+	store.now = clock
+	require.NoError(t, m.RunOnce(ctx))
+	require.Len(t, store.keys, 2)
+	require.Equal(t, postgres.SigningKeyStatusRetiring, store.keys[0].Status)
+	require.Equal(t, clock.Add(2*time.Hour), *store.keys[0].VerifyUntil)
+	published := m.KeySet().PublicJWKS()
+	require.Len(t, published.Keys, 2)
+	require.Equal(t, store.keys[1].KID, published.Keys[0].KeyID)
+	_, err = m.KeySet().Validate(ctx, oldToken)
+	require.NoError(t, err)
+
+	// After the grace window the old key is retired and stops verifying
+	clock = clock.Add(2 * time.Hour)
+	require.NoError(t, m.RunOnce(ctx))
+	require.Equal(t, postgres.SigningKeyStatusRetired, store.keys[0].Status)
+	require.Len(t, m.KeySet().PublicJWKS().Keys, 1)
+	_, err = m.KeySet().Validate(ctx, oldToken)
+	require.Error(t, err)
+
+	require.Equal(t, []string{
+		audit.ActionSigningKeyRotate,
+		audit.ActionSigningKeyRotate,
+		audit.ActionSigningKeyRetire,
+	}, emitter.actions())
+	require.Equal(t, firstKID, emitter.events[1].Metadata["previous_kid"])
+	require.Equal(t, firstKID, emitter.events[2].Metadata["kid"])
+}
+
+func TestManagerReplicasShareKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := &fakeStore{now: clock}
+	first := newTestManager(t, store, nil, &clock)
+	second := newTestManager(t, store, nil, &clock)
+
+	require.NoError(t, first.RunOnce(ctx))
+	require.NoError(t, second.RunOnce(ctx))
+	require.Len(t, store.keys, 1)
+
+	token, _, err := first.KeySet().Generate(ctx, jwt.MapClaims{"sub": "user-1"}, &jwt.Headers{})
+	require.NoError(t, err)
+	_, err = second.KeySet().Validate(ctx, token)
+	require.NoError(t, err)
+}
+
+func TestManagerRejectsWrongEncryptionKey(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := &fakeStore{now: clock}
+	require.NoError(t, newTestManager(t, store, nil, &clock).RunOnce(ctx))
+
+	other, err := NewManager(Config{Store: store, EncryptionKey: []byte("fedcba9876543210fedcba9876543210")})
+	require.NoError(t, err)
+	other.now = func() time.Time { return clock }
+	require.Error(t, other.RunOnce(ctx))
+
+	_, err = NewManager(Config{Store: store, EncryptionKey: []byte("short")})
+	require.Error(t, err)
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key, err := ParseEncryptionKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, err)
+	require.Equal(t, testEncryptionKey, key)
+
+	_, err = ParseEncryptionKey("c2hvcnQ=")
+	require.Error(t, err)
+	_, err = ParseEncryptionKey("not base64!")
+	require.Error(t, err)
+}
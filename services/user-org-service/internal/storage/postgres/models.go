@@ -229,3 +229,141 @@ type RevokeSessionParams struct {
 	Version int64
 	Time    time.Time
 }
+
+// OAuthClient is a registered OAuth2 client. Clients are platform-wide;
+// OrgID records the owning organization, if any.
+type OAuthClient struct {
+	ID            string
+	OrgID         *uuid.UUID
+	Name          string
+	SecretHash    *string
+	Public        bool
+	RedirectURIs  []string
+	GrantTypes    []string
+	ResponseTypes []string
+	Scopes        []string
+	Status        string
+	Metadata      map[string]any
+	Version       int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     *time.Time
+}
+
+type CreateOAuthClientParams struct {
+	ID            string
+	OrgID         *uuid.UUID
+	Name          string
+	SecretHash    *string
+	Public        bool
+	RedirectURIs  []string
+	GrantTypes    []string
+	ResponseTypes []string
+	Scopes        []string
+	Status        string
+	Metadata      map[string]any
+}
+
+type UpdateOAuthClientParams struct {
+	ID            string
+	Version       int64
+	Name          string
+	SecretHash    *string
+	RedirectURIs  []string
+	GrantTypes    []string
+	ResponseTypes []string
+	Scopes        []string
+	Status        string
+	Metadata      map[string]any
+}
+
+// Signing key states. A retiring key no longer signs but still verifies
+// until VerifyUntil.
+const (
+	SigningKeyStatusActive   = "active"
+	SigningKeyStatusRetiring = "retiring"
+	SigningKeyStatusRetired  = "retired"
+)
+
+// SigningKey is a JWT signing key. PrivateKeyCiphertext is the encrypted
+// PKCS#8 private key; the store never sees the plaintext.
+type SigningKey struct {
+	KID                  string
+	Algorithm            string
+	PrivateKeyCiphertext []byte
+	Status               string
+	CreatedAt            time.Time
+	RotatedAt            *time.Time
+	VerifyUntil          *time.Time
+	RetiredAt            *time.Time
+}
+
+// RotateSigningKeyParams replaces the active key. PreviousKID must name the
+// current active key, or be empty when there is none.
+type RotateSigningKeyParams struct {
+	PreviousKID          string
+	VerifyUntil          time.Time
+	KID                  string
+	Algorithm            string
+	PrivateKeyCiphertext []byte
+}
+
+// AuditOutboxEntry is an audit event recorded in the transactional outbox.
+// DeliveredAt is nil until the relay has published it.
+type AuditOutboxEntry struct {
+	ID            int64
+	EventID       uuid.UUID
+	OrgID         uuid.UUID
+	Action        string
+	Payload       []byte
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+}
+
+// InsertAuditOutboxParams records an audit event. Payload is the JSON
+// encoded event.
+type InsertAuditOutboxParams struct {
+	EventID uuid.UUID
+	OrgID   uuid.UUID
+	Action  string
+	Payload []byte
+}
+
+// AuditOutboxFilter selects outbox entries for listing and replay. Zero
+// fields match every entry.
+type AuditOutboxFilter struct {
+	OrgID    *uuid.UUID
+	Action   string
+	EventIDs []uuid.UUID
+	Since    time.Time
+	Until    time.Time
+	// Pending restricts listing to undelivered (true) or delivered (false) entries.
+	Pending *bool
+	Limit   int
+}
+
+// Soft-deleted entities handled by the retention worker.
+const (
+	RetentionEntityAPIKey = "api_key"
+	RetentionEntityUser   = "user"
+	RetentionEntityOrg    = "org"
+)
+
+// Retention modes: anonymize scrubs soft-deleted rows in place, delete
+// removes them.
+const (
+	RetentionModeAnonymize = "anonymize"
+	RetentionModeDelete    = "delete"
+)
+
+// RetentionCandidate is a soft-deleted row past the retention period. For
+// orgs ID and OrgID are the same.
+type RetentionCandidate struct {
+	Entity    string
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	DeletedAt time.Time
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/metrics"
+)
+
+// ConflictAttempts bounds how often RetryOnConflict runs its function.
+const ConflictAttempts = 3
+
+// conflictBackoff is the largest pause before each retry, per attempt made.
+const conflictBackoff = 10 * time.Millisecond
+
+// conflict counts an optimistic lock conflict on table and returns
+// ErrOptimisticLock.
+func conflict(table string) error {
+	metrics.RecordOptimisticLockConflict(table)
+	return ErrOptimisticLock
+}
+
+// RetryOnConflict runs fn until it returns anything but ErrOptimisticLock,
+// at most ConflictAttempts times, pausing briefly (with jitter) between
+// attempts. Each attempt runs in its own InTx transaction, so reads made with
+// the context passed to fn come from the primary. fn is told the attempt
+// number, starting at 1; on later attempts it must re-read the rows it
+// updates and re-apply its change to them, so a concurrent writer's change is
+// kept rather than overwritten. operation labels the retry metrics (e.g.
+// "org.update").
+func (s *Store) RetryOnConflict(ctx context.Context, operation string, fn func(ctx context.Context, attempt int) error) error {
+	return retryOnConflict(ctx, operation, func(attempt int) error {
+		return s.InTx(ctx, func(ctx context.Context) error {
+			return fn(ctx, attempt)
+		})
+	})
+}
+
+func retryOnConflict(ctx context.Context, operation string, fn func(attempt int) error) error {
+	var err error
+	for attempt := 1; attempt <= ConflictAttempts; attempt++ {
+		if attempt > 1 {
+			pause := rand.N(time.Duration(attempt-1) * conflictBackoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pause):
+			}
+		}
+		err = fn(attempt)
+		if !errors.Is(err, ErrOptimisticLock) {
+			if attempt > 1 && err == nil {
+				metrics.RecordOptimisticLockRetry(operation, "resolved")
+			}
+			return err
+		}
+	}
+	metrics.RecordOptimisticLockRetry(operation, "exhausted")
+	return err
+}
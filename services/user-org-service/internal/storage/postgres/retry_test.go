@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOnConflictRetriesUntilResolved(t *testing.T) {
+	var attempts []int
+	err := retryOnConflict(context.Background(), "test.update", func(attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return conflict("orgs")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, attempts)
+}
+
+func TestRetryOnConflictIsBounded(t *testing.T) {
+	calls := 0
+	err := retryOnConflict(context.Background(), "test.update", func(int) error {
+		calls++
+		return ErrOptimisticLock
+	})
+	require.ErrorIs(t, err, ErrOptimisticLock)
+	require.Equal(t, ConflictAttempts, calls)
+}
+
+func TestRetryOnConflictReturnsOtherErrors(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	err := retryOnConflict(context.Background(), "test.update", func(int) error {
+		calls++
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+	require.Equal(t, 1, calls)
+}
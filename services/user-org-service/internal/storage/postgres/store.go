@@ -4,28 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ai-aas/shared-go/dataaccess/pgpool"
+	"github.com/ai-aas/shared-go/dataaccess/pgreplica"
+
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/columncrypt"
 )
 
 // Store provides Postgres-backed persistence for the user-org service.
 type Store struct {
 	pool     *pgxpool.Pool
 	ownsPool bool
+	// replicas routes read-only queries when read replicas are configured (optional).
+	replicas *pgreplica.Router
+	// queryTimeout bounds each store call; zero leaves the caller's deadline as-is.
+	queryTimeout time.Duration
+	// columns encrypts sensitive user columns when configured (optional).
+	columns *columncrypt.Keyring
 }
 
 // NewStore creates a store using the provided connection string and takes ownership of the pool.
 func NewStore(ctx context.Context, connString string) (*Store, error) {
-	pool, err := pgxpool.New(ctx, connString)
+	return NewStoreWithConfig(ctx, connString, pgpool.Config{})
+}
+
+// NewStoreWithConfig creates a store with tuned pool settings, per-query
+// timeouts and pool saturation metrics, and takes ownership of the pool.
+func NewStoreWithConfig(ctx context.Context, connString string, cfg pgpool.Config) (*Store, error) {
+	pool, err := pgpool.New(ctx, "user-org", connString, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("create pgx pool: %w", err)
+		return nil, err
 	}
-	return &Store{pool: pool, ownsPool: true}, nil
+	return &Store{pool: pool, ownsPool: true, queryTimeout: cfg.QueryTimeout}, nil
 }
 
 // NewStoreFromPool wraps an existing pgx pool.
@@ -33,8 +52,23 @@ func NewStoreFromPool(pool *pgxpool.Pool) *Store {
 	return &Store{pool: pool}
 }
 
+// UseReplicas routes read-only queries through the given replica router. The
+// store takes ownership of the router and closes it in Close.
+//
+// Only lookups that tolerate bounded staleness use replicas: listings, org
+// lookup by slug and service accounts. Everything authentication depends on
+// (login by email, org membership and suspension, user status, API key
+// validation) stays on the primary so that revocations, suspensions and
+// lockouts take effect immediately.
+func (s *Store) UseReplicas(r *pgreplica.Router) {
+	s.replicas = r
+}
+
 // Close closes the underlying pool if the store owns it.
 func (s *Store) Close() {
+	if s.replicas != nil {
+		s.replicas.Close()
+	}
 	if s.ownsPool && s.pool != nil {
 		s.pool.Close()
 	}
@@ -45,8 +79,55 @@ func (s *Store) Pool() *pgxpool.Pool {
 	return s.pool
 }
 
+// reader returns a pool for read-only queries: a fresh replica when available,
+// otherwise the primary.
+func (s *Store) reader() *pgxpool.Pool {
+	if s.replicas == nil {
+		return s.pool
+	}
+	return s.replicas.Reader()
+}
+
 func (s *Store) withTx(ctx context.Context, fn func(context.Context, pgx.Tx) error) error {
-	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	return s.withPoolTx(ctx, s.pool, pgx.TxOptions{}, fn)
+}
+
+// withReadTx runs fn in a read-only transaction on the reader pool.
+func (s *Store) withReadTx(ctx context.Context, fn func(context.Context, pgx.Tx) error) error {
+	return s.withPoolTx(ctx, s.reader(), pgx.TxOptions{AccessMode: pgx.ReadOnly}, fn)
+}
+
+// QueryContext applies the configured per-query timeout to ctx. Collaborators
+// that query Pool() directly (e.g., the OAuth store) should use it too.
+func (s *Store) QueryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return pgpool.WithTimeout(ctx, s.queryTimeout)
+}
+
+// txKey carries the transaction opened by InTx.
+type txKey struct{}
+
+// InTx runs fn in a single transaction on the primary. Store calls made with
+// the context passed to fn join that transaction instead of opening their own,
+// so they commit together or all roll back when fn returns an error.
+func (s *Store) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	return s.withPoolTx(ctx, s.pool, pgx.TxOptions{}, func(ctx context.Context, tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+func (s *Store) withPoolTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func(context.Context, pgx.Tx) error) error {
+	// Join an enclosing InTx transaction (reads included, so they see its writes)
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx, tx)
+	}
+
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	tx, err := pool.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -67,14 +148,38 @@ func (s *Store) withTx(ctx context.Context, fn func(context.Context, pgx.Tx) err
 }
 
 func (s *Store) withTenantTx(ctx context.Context, orgID uuid.UUID, fn func(context.Context, pgx.Tx) error) error {
+	return s.withTx(ctx, tenantScoped(orgID, fn))
+}
+
+// withTenantReadTx is the read-only, replica-routed variant of withTenantTx.
+func (s *Store) withTenantReadTx(ctx context.Context, orgID uuid.UUID, fn func(context.Context, pgx.Tx) error) error {
+	return s.withReadTx(ctx, tenantScoped(orgID, fn))
+}
+
+// auditOutboxRelayRole is the database role the audit outbox relay assumes to
+// read and update entries of every org past audit_outbox's tenant policy.
+const auditOutboxRelayRole = "audit_outbox_relay"
+
+// withAuditRelayTx runs fn as auditOutboxRelayRole. Only the cross-org outbox
+// operations (claim, mark, list, replay, purge) use it.
+func (s *Store) withAuditRelayTx(ctx context.Context, fn func(context.Context, pgx.Tx) error) error {
 	return s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SET LOCAL ROLE "+pgx.Identifier{auditOutboxRelayRole}.Sanitize()); err != nil {
+			return err
+		}
+		return fn(ctx, tx)
+	})
+}
+
+func tenantScoped(orgID uuid.UUID, fn func(context.Context, pgx.Tx) error) func(context.Context, pgx.Tx) error {
+	return func(ctx context.Context, tx pgx.Tx) error {
 		// SET LOCAL doesn't support parameters, use string interpolation with proper escaping
 		escapedOrgID := strings.ReplaceAll(orgID.String(), "'", "''")
 		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL app.org_id = '%s'", escapedOrgID)); err != nil {
 			return err
 		}
 		return fn(ctx, tx)
-	})
+	}
 }
 
 // CreateOrg inserts a new organization row.
@@ -153,7 +258,8 @@ func (s *Store) CreateOrg(ctx context.Context, params CreateOrgParams) (Org, err
 	return out, err
 }
 
-// GetOrg retrieves an organization by ID.
+// GetOrg retrieves an organization by ID. It reads the primary: login, token
+// refresh and API key validation check the org's suspension through it.
 func (s *Store) GetOrg(ctx context.Context, id uuid.UUID) (Org, error) {
 	var out Org
 	err := s.withTenantTx(ctx, id, func(ctx context.Context, tx pgx.Tx) error {
@@ -174,7 +280,7 @@ func (s *Store) GetOrg(ctx context.Context, id uuid.UUID) (Org, error) {
 // GetOrgBySlug retrieves an organization by slug.
 func (s *Store) GetOrgBySlug(ctx context.Context, slug string) (Org, error) {
 	var out Org
-	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+	err := s.withReadTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
 		row := tx.QueryRow(ctx, `SELECT * FROM orgs WHERE slug = $1 AND deleted_at IS NULL`, slug)
 		org, err := scanOrg(row)
 		if err != nil {
@@ -189,6 +295,62 @@ func (s *Store) GetOrgBySlug(ctx context.Context, slug string) (Org, error) {
 	return out, err
 }
 
+// ListChildOrgs lists the teams and projects whose metadata["parent_org"]
+// points at parentID, oldest first.
+func (s *Store) ListChildOrgs(ctx context.Context, parentID uuid.UUID) ([]Org, error) {
+	var out []Org
+	err := s.withReadTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM orgs
+			WHERE metadata->'parent_org'->>'parentOrgId' = $1
+			  AND deleted_at IS NULL
+			ORDER BY created_at ASC
+		`, parentID.String())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			org, err := scanOrg(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, org)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// ListDeclarativeOrgs lists the organizations managed through GitOps
+// (declarative_mode = 'enabled'). Used by the reconciler.
+func (s *Store) ListDeclarativeOrgs(ctx context.Context) ([]Org, error) {
+	var out []Org
+	err := s.withReadTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM orgs
+			WHERE declarative_mode = 'enabled'
+			  AND deleted_at IS NULL
+			ORDER BY slug ASC
+		`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			org, err := scanOrg(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, org)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
 // GetUserByEmail retrieves a user by email within an organization.
 func (s *Store) GetUserByEmail(ctx context.Context, orgID uuid.UUID, email string) (User, error) {
 	var out User
@@ -197,7 +359,7 @@ func (s *Store) GetUserByEmail(ctx context.Context, orgID uuid.UUID, email strin
 			SELECT * FROM users
 			WHERE org_id = $1 AND email = LOWER($2) AND deleted_at IS NULL
 		`, orgID, email)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
 				return ErrNotFound
@@ -210,7 +372,8 @@ func (s *Store) GetUserByEmail(ctx context.Context, orgID uuid.UUID, email strin
 	return out, err
 }
 
-// GetUserByID retrieves a user by ID within an organization.
+// GetUserByID retrieves a user by ID within an organization. It reads the
+// primary: token refresh and introspection check the user's status through it.
 func (s *Store) GetUserByID(ctx context.Context, orgID, userID uuid.UUID) (User, error) {
 	var out User
 	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
@@ -218,7 +381,7 @@ func (s *Store) GetUserByID(ctx context.Context, orgID, userID uuid.UUID) (User,
 			SELECT * FROM users
 			WHERE org_id = $1 AND user_id = $2 AND deleted_at IS NULL
 		`, orgID, userID)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
 				return ErrNotFound
@@ -239,7 +402,7 @@ func (s *Store) GetUserByExternalIDP(ctx context.Context, orgID uuid.UUID, exter
 			SELECT * FROM users
 			WHERE org_id = $1 AND external_idp_id = $2 AND deleted_at IS NULL
 		`, orgID, externalIDP)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
 				return ErrNotFound
@@ -254,7 +417,11 @@ func (s *Store) GetUserByExternalIDP(ctx context.Context, orgID uuid.UUID, exter
 
 // GetUserOrgIDByUserID retrieves the organization ID for a user by their user ID.
 // This method does not use tenant transactions since we're looking up the user's org.
+// It reads the primary because login resolves the user's org through it.
 func (s *Store) GetUserOrgIDByUserID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
 	var orgID uuid.UUID
 	err := s.pool.QueryRow(ctx, `
 		SELECT org_id FROM users
@@ -271,7 +438,8 @@ func (s *Store) GetUserOrgIDByUserID(ctx context.Context, userID uuid.UUID) (uui
 }
 
 // ValidateUserOrgMembership checks if a user belongs to a specific organization.
-// Returns nil if the user belongs to the org, ErrNotFound otherwise.
+// Returns nil if the user belongs to the org, ErrNotFound otherwise. It reads
+// the primary so a removed member cannot log in from a lagging replica.
 func (s *Store) ValidateUserOrgMembership(ctx context.Context, userID, orgID uuid.UUID) error {
 	var count int
 	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
@@ -342,7 +510,7 @@ func (s *Store) UpdateOrg(ctx context.Context, params UpdateOrgParams) (Org, err
 		org, err := scanOrg(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("orgs")
 			}
 			return err
 		}
@@ -370,14 +538,22 @@ func (s *Store) CreateUser(ctx context.Context, params CreateUserParams) (User,
 	if userID == uuid.Nil {
 		userID = uuid.New()
 	}
+	mfaSecret, err := s.sealMFASecret(userID, params.MFASecret)
+	if err != nil {
+		return User{}, err
+	}
+	recoveryTokens, err := s.sealRecoveryTokens(userID, params.RecoveryTokens)
+	if err != nil {
+		return User{}, err
+	}
 
 	var out User
-	err := s.withTenantTx(ctx, params.OrgID, func(ctx context.Context, tx pgx.Tx) error {
+	err = s.withTenantTx(ctx, params.OrgID, func(ctx context.Context, tx pgx.Tx) error {
 		mfaJSON, err := mustJSONB(params.MFAMethods)
 		if err != nil {
 			return err
 		}
-		recoveryJSON, err := mustJSONB(params.RecoveryTokens)
+		recoveryJSON, err := mustJSONB(recoveryTokens)
 		if err != nil {
 			return err
 		}
@@ -413,7 +589,7 @@ func (s *Store) CreateUser(ctx context.Context, params CreateUserParams) (User,
 			params.Status,
 			params.MFAEnrolled,
 			string(mfaJSON),
-			params.MFASecret,
+			mfaSecret,
 			params.LastLoginAt,
 			params.LockoutUntil,
 			string(recoveryJSON),
@@ -421,10 +597,10 @@ func (s *Store) CreateUser(ctx context.Context, params CreateUserParams) (User,
 			string(metadataJSON),
 		)
 
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -450,10 +626,10 @@ func (s *Store) UpdateUserStatus(ctx context.Context, params UpdateUserStatusPar
 			params.ID,
 			params.Version,
 		)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -471,9 +647,13 @@ func (s *Store) UpdateUserProfile(ctx context.Context, params UpdateUserProfileP
 	if params.MFAMethods == nil {
 		params.MFAMethods = []string{}
 	}
+	mfaSecret, err := s.sealMFASecret(params.ID, params.MFASecret)
+	if err != nil {
+		return User{}, err
+	}
 
 	var out User
-	err := s.withTenantTx(ctx, params.OrgID, func(ctx context.Context, tx pgx.Tx) error {
+	err = s.withTenantTx(ctx, params.OrgID, func(ctx context.Context, tx pgx.Tx) error {
 		mfaJSON, err := mustJSONB(params.MFAMethods)
 		if err != nil {
 			return err
@@ -497,15 +677,15 @@ func (s *Store) UpdateUserProfile(ctx context.Context, params UpdateUserProfileP
 			params.DisplayName,
 			params.MFAEnrolled,
 			string(mfaJSON),
-			params.MFASecret,
+			mfaSecret,
 			string(metadataJSON),
 			params.ID,
 			params.Version,
 		)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -533,10 +713,10 @@ func (s *Store) UpdateUserPasswordHash(ctx context.Context, params UpdateUserPas
 			params.ID,
 			params.Version,
 		)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -556,10 +736,10 @@ func (s *Store) UpdateUserExternalIDP(ctx context.Context, orgID, userID uuid.UU
 			WHERE org_id = $1 AND user_id = $2 AND version = $3 AND deleted_at IS NULL
 			RETURNING *
 		`, orgID, userID, version, externalIDP)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -571,11 +751,12 @@ func (s *Store) UpdateUserExternalIDP(ctx context.Context, orgID, userID uuid.UU
 
 // UpdateUserRecoveryTokens updates the recovery_tokens array using optimistic locking.
 func (s *Store) UpdateUserRecoveryTokens(ctx context.Context, orgID, userID uuid.UUID, version int64, recoveryTokens []string) (User, error) {
-	if recoveryTokens == nil {
-		recoveryTokens = []string{}
+	recoveryTokens, err := s.sealRecoveryTokens(userID, recoveryTokens)
+	if err != nil {
+		return User{}, err
 	}
 	var out User
-	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+	err = s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
 		recoveryJSON, err := mustJSONB(recoveryTokens)
 		if err != nil {
 			return err
@@ -591,10 +772,10 @@ func (s *Store) UpdateUserRecoveryTokens(ctx context.Context, orgID, userID uuid
 			userID,
 			version,
 		)
-		user, err := scanUser(row)
+		user, err := s.scanUser(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("users")
 			}
 			return err
 		}
@@ -604,6 +785,89 @@ func (s *Store) UpdateUserRecoveryTokens(ctx context.Context, orgID, userID uuid
 	return out, err
 }
 
+// ListUsersDueForDeletion lists users across all organizations whose
+// self-service deletion grace period (metadata["account_deletion"]["purge_after"])
+// ended at or before the given time, oldest first. Used by the account deletion worker.
+func (s *Store) ListUsersDueForDeletion(ctx context.Context, before time.Time, limit int) ([]User, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT *
+		FROM users
+		WHERE deleted_at IS NULL
+		  AND metadata ? 'account_deletion'
+		  AND (metadata->'account_deletion'->>'purge_after')::timestamptz <= $1
+		ORDER BY (metadata->'account_deletion'->>'purge_after')::timestamptz ASC
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []User
+	for rows.Next() {
+		user, err := s.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, user)
+	}
+	return out, rows.Err()
+}
+
+// DeleteUser soft-deletes a user using optimistic locking. Personal data is
+// scrubbed (email, display name, credentials, MFA, metadata), and the user's
+// API keys and sessions are revoked in the same transaction. Returns the
+// number of API keys revoked.
+func (s *Store) DeleteUser(ctx context.Context, orgID, userID uuid.UUID, version int64) (int, error) {
+	var revoked int
+	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE users
+			SET status = 'deleted',
+				email = 'deleted+' || user_id::text || '@deleted.invalid',
+				display_name = 'Deleted user',
+				password_hash = '',
+				mfa_enrolled = FALSE,
+				mfa_methods = '[]'::jsonb,
+				mfa_secret = NULL,
+				recovery_tokens = '[]'::jsonb,
+				external_idp_id = NULL,
+				metadata = '{}'::jsonb,
+				deleted_at = NOW(),
+				version = version + 1
+			WHERE org_id = $1 AND user_id = $2 AND version = $3 AND deleted_at IS NULL
+		`, orgID, userID, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return conflict("users")
+		}
+		tag, err = tx.Exec(ctx, `
+			UPDATE api_keys
+			SET status = 'revoked',
+				revoked_at = NOW(),
+				version = version + 1
+			WHERE org_id = $1 AND principal_type = 'user' AND principal_id = $2 AND revoked_at IS NULL
+		`, orgID, userID)
+		if err != nil {
+			return err
+		}
+		revoked = int(tag.RowsAffected())
+		_, err = tx.Exec(ctx, `
+			UPDATE sessions
+			SET revoked_at = NOW(),
+				version = version + 1
+			WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL
+		`, orgID, userID)
+		return err
+	})
+	return revoked, err
+}
+
 // CreateSession inserts a new session row.
 func (s *Store) CreateSession(ctx context.Context, params CreateSessionParams) (Session, error) {
 	sessionID := params.ID
@@ -661,7 +925,7 @@ func (s *Store) RevokeSession(ctx context.Context, params RevokeSessionParams, o
 			return err
 		}
 		if cmd.RowsAffected() == 0 {
-			return ErrOptimisticLock
+			return conflict("sessions")
 		}
 		return nil
 	})
@@ -725,6 +989,9 @@ func (s *Store) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (AP
 
 // GetAPIKeyByID retrieves an API key by its ID.
 func (s *Store) GetAPIKeyByID(ctx context.Context, apiKeyID uuid.UUID) (APIKey, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
 	row := s.pool.QueryRow(ctx, `
 		SELECT *
 		FROM api_keys
@@ -766,6 +1033,9 @@ func (s *Store) GetAPIKeyByFingerprint(ctx context.Context, orgID uuid.UUID, fin
 // This is less efficient than GetAPIKeyByFingerprint but supports org-agnostic validation.
 // Use this only when org_id is not available (e.g., API Router initial lookup).
 func (s *Store) GetAPIKeyByFingerprintAnyOrg(ctx context.Context, fingerprint string) (APIKey, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
 	row := s.pool.QueryRow(ctx, `
 		SELECT *
 		FROM api_keys
@@ -786,7 +1056,7 @@ func (s *Store) GetAPIKeyByFingerprintAnyOrg(ctx context.Context, fingerprint st
 // ListAPIKeysForPrincipal lists all API keys for a given principal (user or service account) within an organization.
 func (s *Store) ListAPIKeysForPrincipal(ctx context.Context, orgID uuid.UUID, principalType PrincipalType, principalID uuid.UUID) ([]APIKey, error) {
 	var out []APIKey
-	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+	err := s.withTenantReadTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
 		rows, err := tx.Query(ctx, `
 			SELECT *
 			FROM api_keys
@@ -813,6 +1083,180 @@ func (s *Store) ListAPIKeysForPrincipal(ctx context.Context, orgID uuid.UUID, pr
 	return out, err
 }
 
+// ListAPIKeysForOrg lists all API keys (user and service account) within an organization.
+func (s *Store) ListAPIKeysForOrg(ctx context.Context, orgID uuid.UUID) ([]APIKey, error) {
+	var out []APIKey
+	err := s.withTenantReadTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM api_keys
+			WHERE org_id = $1
+			  AND deleted_at IS NULL
+			ORDER BY created_at DESC
+		`, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			key, err := scanAPIKey(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, key)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// ListUsersForOrg lists the live (not soft-deleted) users of an organization, oldest first.
+func (s *Store) ListUsersForOrg(ctx context.Context, orgID uuid.UUID) ([]User, error) {
+	var out []User
+	err := s.withTenantReadTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM users
+			WHERE org_id = $1
+			  AND deleted_at IS NULL
+			ORDER BY created_at ASC
+		`, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user, err := s.scanUser(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, user)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// ListServiceAccountsForOrg lists the live service accounts of an organization, oldest first.
+func (s *Store) ListServiceAccountsForOrg(ctx context.Context, orgID uuid.UUID) ([]ServiceAccount, error) {
+	var out []ServiceAccount
+	err := s.withTenantReadTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM service_accounts
+			WHERE org_id = $1
+			  AND deleted_at IS NULL
+			ORDER BY created_at ASC
+		`, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			account, err := scanServiceAccount(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, account)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// ListAPIKeysExpiringBefore lists active keys across all organizations whose
+// expires_at is at or before the given time (including keys already past it),
+// soonest first. Used by the key expiry worker.
+func (s *Store) ListAPIKeysExpiringBefore(ctx context.Context, before time.Time, limit int) ([]APIKey, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT *
+		FROM api_keys
+		WHERE status = 'active'
+		  AND revoked_at IS NULL
+		  AND expires_at IS NOT NULL
+		  AND expires_at <= $1
+		  AND deleted_at IS NULL
+		ORDER BY expires_at ASC
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, key)
+	}
+	return out, rows.Err()
+}
+
+// ExpireAPIKey transitions an active key to status "expired" using optimistic locking.
+func (s *Store) ExpireAPIKey(ctx context.Context, orgID, apiKeyID uuid.UUID, version int64) (APIKey, error) {
+	var out APIKey
+	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+			UPDATE api_keys
+			SET status = 'expired',
+				version = version + 1
+			WHERE api_key_id = $1 AND version = $2 AND status = 'active' AND deleted_at IS NULL
+			RETURNING *
+		`, apiKeyID, version)
+		key, err := scanAPIKey(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return conflict("api_keys")
+			}
+			return err
+		}
+		out = key
+		return nil
+	})
+	return out, err
+}
+
+// UpdateAPIKeyAnnotations replaces a key's annotations using optimistic locking.
+func (s *Store) UpdateAPIKeyAnnotations(ctx context.Context, orgID, apiKeyID uuid.UUID, version int64, annotations map[string]any) (APIKey, error) {
+	if annotations == nil {
+		annotations = map[string]any{}
+	}
+
+	var out APIKey
+	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		annotationsJSON, err := mustJSONB(annotations)
+		if err != nil {
+			return err
+		}
+		row := tx.QueryRow(ctx, `
+			UPDATE api_keys
+			SET annotations = $1,
+				version = version + 1
+			WHERE api_key_id = $2 AND version = $3 AND deleted_at IS NULL
+			RETURNING *
+		`, string(annotationsJSON), apiKeyID, version)
+		key, err := scanAPIKey(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return conflict("api_keys")
+			}
+			return err
+		}
+		out = key
+		return nil
+	})
+	return out, err
+}
+
 // CreateServiceAccount creates a new service account within an organization.
 func (s *Store) CreateServiceAccount(ctx context.Context, params CreateServiceAccountParams) (ServiceAccount, error) {
 	if params.Metadata == nil {
@@ -864,9 +1308,36 @@ func (s *Store) CreateServiceAccount(ctx context.Context, params CreateServiceAc
 	return out, err
 }
 
+// UpdateServiceAccountStatus sets a service account's status using optimistic locking.
+func (s *Store) UpdateServiceAccountStatus(ctx context.Context, orgID, serviceAccountID uuid.UUID, version int64, status string) (ServiceAccount, error) {
+	var out ServiceAccount
+	err := s.withTenantTx(ctx, orgID, func(ctx context.Context, tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+			UPDATE service_accounts
+			SET status = $1,
+				version = version + 1
+			WHERE service_account_id = $2 AND version = $3 AND deleted_at IS NULL
+			RETURNING *
+		`, status, serviceAccountID, version)
+		sa, err := scanServiceAccount(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return conflict("service_accounts")
+			}
+			return err
+		}
+		out = sa
+		return nil
+	})
+	return out, err
+}
+
 // GetServiceAccountByID retrieves a service account by its ID.
 func (s *Store) GetServiceAccountByID(ctx context.Context, serviceAccountID uuid.UUID) (ServiceAccount, error) {
-	row := s.pool.QueryRow(ctx, `
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	row := s.reader().QueryRow(ctx, `
 		SELECT *
 		FROM service_accounts
 		WHERE service_account_id = $1 AND deleted_at IS NULL
@@ -883,6 +1354,9 @@ func (s *Store) GetServiceAccountByID(ctx context.Context, serviceAccountID uuid
 
 // UpdateAPIKeyLastUsed updates the last_used_at timestamp for an API key.
 func (s *Store) UpdateAPIKeyLastUsed(ctx context.Context, apiKeyID uuid.UUID, lastUsedAt time.Time) error {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
 	_, err := s.pool.Exec(ctx, `
 		UPDATE api_keys
 		SET last_used_at = $1,
@@ -911,7 +1385,267 @@ func (s *Store) RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams, org
 		key, err := scanAPIKey(row)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return ErrOptimisticLock
+				return conflict("api_keys")
+			}
+			return err
+		}
+		out = key
+		return nil
+	})
+	return out, err
+}
+
+// CreateOAuthClient registers an OAuth2 client. Returns ErrConflict when the client ID is taken.
+func (s *Store) CreateOAuthClient(ctx context.Context, params CreateOAuthClientParams) (OAuthClient, error) {
+	if params.Metadata == nil {
+		params.Metadata = map[string]any{}
+	}
+	if params.Status == "" {
+		params.Status = "active"
+	}
+
+	var out OAuthClient
+	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		redirectJSON, grantJSON, responseJSON, scopesJSON, metadataJSON, err := oauthClientJSON(params.RedirectURIs, params.GrantTypes, params.ResponseTypes, params.Scopes, params.Metadata)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRow(ctx, `
+			INSERT INTO oauth_clients (
+				client_id,
+				org_id,
+				name,
+				secret_hash,
+				public,
+				redirect_uris,
+				grant_types,
+				response_types,
+				scopes,
+				status,
+				metadata
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (client_id) DO NOTHING
+			RETURNING *
+		`,
+			params.ID,
+			params.OrgID,
+			params.Name,
+			params.SecretHash,
+			params.Public,
+			string(redirectJSON),
+			string(grantJSON),
+			string(responseJSON),
+			string(scopesJSON),
+			params.Status,
+			string(metadataJSON),
+		)
+		client, err := scanOAuthClient(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrConflict
+			}
+			return err
+		}
+		out = client
+		return nil
+	})
+	return out, err
+}
+
+// GetOAuthClient retrieves a registered OAuth2 client by its client ID.
+func (s *Store) GetOAuthClient(ctx context.Context, clientID string) (OAuthClient, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	row := s.pool.QueryRow(ctx, `
+		SELECT *
+		FROM oauth_clients
+		WHERE client_id = $1 AND deleted_at IS NULL
+	`, clientID)
+	client, err := scanOAuthClient(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return OAuthClient{}, ErrNotFound
+		}
+		return OAuthClient{}, err
+	}
+	return client, nil
+}
+
+// ListOAuthClients lists registered OAuth2 clients, oldest first. A non-nil
+// orgID limits the list to clients owned by that organization.
+func (s *Store) ListOAuthClients(ctx context.Context, orgID *uuid.UUID) ([]OAuthClient, error) {
+	var out []OAuthClient
+	err := s.withReadTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM oauth_clients
+			WHERE deleted_at IS NULL
+			  AND ($1::uuid IS NULL OR org_id = $1)
+			ORDER BY created_at ASC
+		`, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			client, err := scanOAuthClient(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, client)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// UpdateOAuthClient replaces a client's mutable fields using optimistic
+// locking. A nil SecretHash keeps the current secret.
+func (s *Store) UpdateOAuthClient(ctx context.Context, params UpdateOAuthClientParams) (OAuthClient, error) {
+	if params.Metadata == nil {
+		params.Metadata = map[string]any{}
+	}
+
+	var out OAuthClient
+	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		redirectJSON, grantJSON, responseJSON, scopesJSON, metadataJSON, err := oauthClientJSON(params.RedirectURIs, params.GrantTypes, params.ResponseTypes, params.Scopes, params.Metadata)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRow(ctx, `
+			UPDATE oauth_clients
+			SET name = $1,
+				secret_hash = COALESCE($2, secret_hash),
+				redirect_uris = $3,
+				grant_types = $4,
+				response_types = $5,
+				scopes = $6,
+				status = $7,
+				metadata = $8,
+				updated_at = NOW(),
+				version = version + 1
+			WHERE client_id = $9 AND version = $10 AND deleted_at IS NULL
+			RETURNING *
+		`,
+			params.Name,
+			params.SecretHash,
+			string(redirectJSON),
+			string(grantJSON),
+			string(responseJSON),
+			string(scopesJSON),
+			params.Status,
+			string(metadataJSON),
+			params.ID,
+			params.Version,
+		)
+		client, err := scanOAuthClient(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return conflict("oauth_clients")
+			}
+			return err
+		}
+		out = client
+		return nil
+	})
+	return out, err
+}
+
+// DeleteOAuthClient soft-deletes a client using optimistic locking. Tokens
+// already issued to it fail their next client lookup.
+func (s *Store) DeleteOAuthClient(ctx context.Context, clientID string, version int64) error {
+	return s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE oauth_clients
+			SET status = 'deleted',
+				deleted_at = NOW(),
+				updated_at = NOW(),
+				version = version + 1
+			WHERE client_id = $1 AND version = $2 AND deleted_at IS NULL
+		`, clientID, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return conflict("oauth_clients")
+		}
+		return nil
+	})
+}
+
+// ListSigningKeys returns the active and retiring JWT signing keys, newest
+// first. It reads the primary so a key rotated by another replica is seen
+// before tokens signed with it arrive.
+func (s *Store) ListSigningKeys(ctx context.Context) ([]SigningKey, error) {
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT *
+		FROM signing_keys
+		WHERE status IN ('active', 'retiring')
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []SigningKey
+	for rows.Next() {
+		key, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, key)
+	}
+	return out, rows.Err()
+}
+
+// RotateSigningKey moves the active key to retiring and inserts a new active
+// key. Returns ErrOptimisticLock when PreviousKID is no longer the active key
+// and ErrConflict when another key became active concurrently.
+func (s *Store) RotateSigningKey(ctx context.Context, params RotateSigningKeyParams) (SigningKey, error) {
+	if params.Algorithm == "" {
+		params.Algorithm = "RS256"
+	}
+
+	var out SigningKey
+	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if params.PreviousKID != "" {
+			tag, err := tx.Exec(ctx, `
+				UPDATE signing_keys
+				SET status = 'retiring',
+					rotated_at = NOW(),
+					verify_until = $2
+				WHERE kid = $1 AND status = 'active'
+			`, params.PreviousKID, params.VerifyUntil)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return conflict("signing_keys")
+			}
+		}
+
+		row := tx.QueryRow(ctx, `
+			INSERT INTO signing_keys (
+				kid,
+				algorithm,
+				private_key_ciphertext,
+				status
+			) VALUES ($1, $2, $3, 'active')
+			ON CONFLICT DO NOTHING
+			RETURNING *
+		`, params.KID, params.Algorithm, params.PrivateKeyCiphertext)
+		key, err := scanSigningKey(row)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrConflict
 			}
 			return err
 		}
@@ -921,6 +1655,414 @@ func (s *Store) RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams, org
 	return out, err
 }
 
+// RetireSigningKeys marks retiring keys whose verification window ended
+// before now as retired and erases their private key material.
+func (s *Store) RetireSigningKeys(ctx context.Context, now time.Time) ([]SigningKey, error) {
+	var out []SigningKey
+	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			UPDATE signing_keys
+			SET status = 'retired',
+				retired_at = NOW(),
+				private_key_ciphertext = ''::bytea
+			WHERE status = 'retiring' AND verify_until <= $1
+			RETURNING *
+		`, now)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			key, err := scanSigningKey(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, key)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// InsertAuditOutbox records an audit event for the relay to publish. Called
+// inside InTx it commits or rolls back with the mutation it describes.
+func (s *Store) InsertAuditOutbox(ctx context.Context, params InsertAuditOutboxParams) error {
+	return s.withTenantTx(ctx, params.OrgID, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO audit_outbox (event_id, org_id, action, payload)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (event_id) DO NOTHING
+		`, params.EventID, params.OrgID, params.Action, params.Payload)
+		return err
+	})
+}
+
+// ClaimAuditOutbox returns up to limit undelivered entries that are due, in
+// insertion order, and leases them for lease so concurrent relays skip them.
+// An entry whose publisher dies becomes due again when the lease ends.
+func (s *Store) ClaimAuditOutbox(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]AuditOutboxEntry, error) {
+	var out []AuditOutboxEntry
+	err := s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			UPDATE audit_outbox
+			SET attempts = attempts + 1,
+				next_attempt_at = $2
+			WHERE id IN (
+				SELECT id FROM audit_outbox
+				WHERE delivered_at IS NULL AND next_attempt_at <= $1
+				ORDER BY id
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		`, now, now.Add(lease), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			entry, err := scanAuditOutboxEntry(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, entry)
+		}
+		return rows.Err()
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+// MarkAuditOutboxDelivered records that the entries were published.
+func (s *Store) MarkAuditOutboxDelivered(ctx context.Context, ids []int64, deliveredAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE audit_outbox
+			SET delivered_at = $2, last_error = NULL
+			WHERE id = ANY($1)
+		`, ids, deliveredAt)
+		return err
+	})
+}
+
+// MarkAuditOutboxFailed records a failed publish and when to retry it.
+func (s *Store) MarkAuditOutboxFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	return s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE audit_outbox
+			SET last_error = $2, next_attempt_at = $3
+			WHERE id = $1 AND delivered_at IS NULL
+		`, id, lastError, nextAttemptAt)
+		return err
+	})
+}
+
+// ListAuditOutbox returns the entries matching filter, oldest first.
+func (s *Store) ListAuditOutbox(ctx context.Context, filter AuditOutboxFilter) ([]AuditOutboxEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args := append(auditOutboxFilterArgs(filter), filter.Pending, limit)
+
+	var out []AuditOutboxEntry
+	err := s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT *
+			FROM audit_outbox
+			WHERE `+auditOutboxFilterSQL+`
+			  AND ($6::boolean IS NULL OR (delivered_at IS NULL) = $6)
+			ORDER BY id
+			LIMIT $7
+		`, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			entry, err := scanAuditOutboxEntry(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, entry)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// ReplayAuditOutbox queues delivered entries matching filter for the relay to
+// publish again, e.g. after the audit sink lost data. Returns how many
+// entries were queued. Consumers deduplicate on the event ID.
+func (s *Store) ReplayAuditOutbox(ctx context.Context, filter AuditOutboxFilter, now time.Time) (int64, error) {
+	var affected int64
+	err := s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE audit_outbox
+			SET delivered_at = NULL, attempts = 0, last_error = NULL, next_attempt_at = $6
+			WHERE delivered_at IS NOT NULL
+			  AND `+auditOutboxFilterSQL, append(auditOutboxFilterArgs(filter), now)...)
+		if err != nil {
+			return err
+		}
+		affected = tag.RowsAffected()
+		return nil
+	})
+	return affected, err
+}
+
+// PurgeAuditOutbox deletes entries delivered before the cutoff. Returns the
+// number deleted.
+func (s *Store) PurgeAuditOutbox(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := s.withAuditRelayTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM audit_outbox WHERE delivered_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		affected = tag.RowsAffected()
+		return nil
+	})
+	return affected, err
+}
+
+// auditOutboxFilterSQL matches AuditOutboxFilter fields bound as $1-$5 by
+// auditOutboxFilterArgs.
+const auditOutboxFilterSQL = `($1::uuid IS NULL OR org_id = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND (cardinality($3::uuid[]) = 0 OR event_id = ANY($3))
+		  AND ($4::timestamptz IS NULL OR created_at >= $4)
+		  AND ($5::timestamptz IS NULL OR created_at < $5)`
+
+func auditOutboxFilterArgs(filter AuditOutboxFilter) []any {
+	var since, until pgtype.Timestamptz
+	if !filter.Since.IsZero() {
+		since = pgtype.Timestamptz{Time: filter.Since, Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		until = pgtype.Timestamptz{Time: filter.Until, Valid: true}
+	}
+	eventIDs := filter.EventIDs
+	if eventIDs == nil {
+		eventIDs = []uuid.UUID{}
+	}
+	return []any{filter.OrgID, filter.Action, eventIDs, since, until}
+}
+
+// purgeTables lists org-scoped tables in dependency order for PurgeOrgsByMetadata.
+var purgeTables = []string{"invite_tokens", "sessions", "api_keys", "service_accounts", "oauth_clients", "users", "orgs"}
+
+// PurgeOrgsByMetadata hard-deletes every organization whose metadata[key]
+// equals value, together with its users, service accounts, API keys, sessions
+// and invite tokens. It exists for tearing down seeded fixtures; application
+// code uses soft deletes. Returns the IDs of the purged organizations.
+func (s *Store) PurgeOrgsByMetadata(ctx context.Context, key, value string) ([]uuid.UUID, error) {
+	var orgIDs []uuid.UUID
+	err := s.withTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `SELECT org_id FROM orgs WHERE metadata->>$1 = $2`, key, value)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			orgIDs = append(orgIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, orgID := range orgIDs {
+			if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL app.org_id = '%s'", orgID.String())); err != nil {
+				return err
+			}
+			if err := purgeOrgRows(ctx, tx, orgID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orgIDs, nil
+}
+
+// purgeOrgRows hard-deletes an organization and every row scoped to it.
+func purgeOrgRows(ctx context.Context, tx pgx.Tx, orgID uuid.UUID) error {
+	for _, table := range purgeTables {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE org_id = $1`, orgID); err != nil {
+			return fmt.Errorf("purge %s for org %s: %w", table, orgID, err)
+		}
+	}
+	return nil
+}
+
+// anonymizedUserSQL scrubs a user's personal data, like DeleteUser, and
+// marks the row with metadata["anonymized_at"] = $1.
+const anonymizedUserSQL = `
+	status = 'deleted',
+	email = 'deleted+' || user_id::text || '@deleted.invalid',
+	display_name = 'Deleted user',
+	password_hash = '',
+	mfa_enrolled = FALSE,
+	mfa_methods = '[]'::jsonb,
+	mfa_secret = NULL,
+	recovery_tokens = '[]'::jsonb,
+	external_idp_id = NULL,
+	metadata = jsonb_build_object('anonymized_at', $1::text),
+	deleted_at = COALESCE(deleted_at, NOW()),
+	version = version + 1`
+
+// ListRetentionCandidates returns up to limit rows of entity soft-deleted
+// before deletedBefore, oldest first. For RetentionModeAnonymize rows that
+// were already anonymized are skipped.
+func (s *Store) ListRetentionCandidates(ctx context.Context, entity, mode string, deletedBefore time.Time, limit int) ([]RetentionCandidate, error) {
+	var query string
+	switch entity {
+	case RetentionEntityAPIKey:
+		query = `SELECT api_key_id, org_id, deleted_at FROM api_keys
+			WHERE deleted_at < $1 AND ($3 <> 'anonymize' OR NOT annotations ? 'anonymized_at')`
+	case RetentionEntityUser:
+		query = `SELECT user_id, org_id, deleted_at FROM users
+			WHERE deleted_at < $1 AND ($3 <> 'anonymize' OR NOT metadata ? 'anonymized_at')`
+	case RetentionEntityOrg:
+		query = `SELECT org_id, org_id, deleted_at FROM orgs
+			WHERE deleted_at < $1 AND ($3 <> 'anonymize' OR NOT metadata ? 'anonymized_at')`
+	default:
+		return nil, fmt.Errorf("unknown retention entity %q", entity)
+	}
+
+	ctx, cancel := s.QueryContext(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, query+` ORDER BY deleted_at ASC LIMIT $2`, deletedBefore, limit, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RetentionCandidate
+	for rows.Next() {
+		c := RetentionCandidate{Entity: entity}
+		if err := rows.Scan(&c.ID, &c.OrgID, &c.DeletedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// AnonymizeSoftDeleted scrubs a soft-deleted row in place and marks it
+// anonymized, keeping the row for referential integrity. Anonymizing an org
+// also anonymizes its users and API key annotations. Returns ErrNotFound
+// when the row is no longer soft-deleted.
+func (s *Store) AnonymizeSoftDeleted(ctx context.Context, c RetentionCandidate, now time.Time) error {
+	stamp := now.UTC().Format(time.RFC3339)
+	return s.withTenantTx(ctx, c.OrgID, func(ctx context.Context, tx pgx.Tx) error {
+		var (
+			tag pgconn.CommandTag
+			err error
+		)
+		switch c.Entity {
+		case RetentionEntityAPIKey:
+			tag, err = tx.Exec(ctx, `
+				UPDATE api_keys
+				SET annotations = jsonb_build_object('anonymized_at', $1::text),
+					version = version + 1
+				WHERE api_key_id = $2 AND deleted_at IS NOT NULL
+			`, stamp, c.ID)
+		case RetentionEntityUser:
+			tag, err = tx.Exec(ctx, `UPDATE users SET `+anonymizedUserSQL+`
+				WHERE user_id = $2 AND deleted_at IS NOT NULL
+			`, stamp, c.ID)
+		case RetentionEntityOrg:
+			tag, err = tx.Exec(ctx, `
+				UPDATE orgs
+				SET name = 'Deleted organization',
+					slug = 'deleted-' || org_id::text,
+					metadata = jsonb_build_object('anonymized_at', $1::text),
+					version = version + 1
+				WHERE org_id = $2 AND deleted_at IS NOT NULL
+			`, stamp, c.ID)
+			if err == nil && tag.RowsAffected() > 0 {
+				if _, err = tx.Exec(ctx, `UPDATE users SET `+anonymizedUserSQL+` WHERE org_id = $2`, stamp, c.ID); err != nil {
+					return err
+				}
+				_, err = tx.Exec(ctx, `
+					UPDATE api_keys
+					SET annotations = jsonb_build_object('anonymized_at', $1::text),
+						version = version + 1
+					WHERE org_id = $2
+				`, stamp, c.ID)
+			}
+		default:
+			return fmt.Errorf("unknown retention entity %q", c.Entity)
+		}
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// PurgeSoftDeleted hard-deletes a soft-deleted row. Purging a user also
+// deletes its sessions and user-owned API keys; purging an org deletes every
+// row scoped to it. Returns ErrNotFound when the row is no longer
+// soft-deleted.
+func (s *Store) PurgeSoftDeleted(ctx context.Context, c RetentionCandidate) error {
+	return s.withTenantTx(ctx, c.OrgID, func(ctx context.Context, tx pgx.Tx) error {
+		var (
+			tag pgconn.CommandTag
+			err error
+		)
+		switch c.Entity {
+		case RetentionEntityAPIKey:
+			tag, err = tx.Exec(ctx, `DELETE FROM api_keys WHERE api_key_id = $1 AND deleted_at IS NOT NULL`, c.ID)
+		case RetentionEntityUser:
+			if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, c.ID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM api_keys WHERE principal_type = 'user' AND principal_id = $1`, c.ID); err != nil {
+				return err
+			}
+			tag, err = tx.Exec(ctx, `DELETE FROM users WHERE user_id = $1 AND deleted_at IS NOT NULL`, c.ID)
+		case RetentionEntityOrg:
+			var deleted bool
+			if err := tx.QueryRow(ctx, `SELECT deleted_at IS NOT NULL FROM orgs WHERE org_id = $1`, c.ID).Scan(&deleted); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrNotFound
+				}
+				return err
+			}
+			if !deleted {
+				return ErrNotFound
+			}
+			return purgeOrgRows(ctx, tx, c.ID)
+		default:
+			return fmt.Errorf("unknown retention entity %q", c.Entity)
+		}
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
 // scan helpers ---------------------------------------------------------------
 
 func scanOrg(row pgx.Row) (Org, error) {
@@ -1172,3 +2314,135 @@ func scanSession(row pgx.Row) (Session, error) {
 	s.DeletedAt = timePtr(deleted)
 	return s, nil
 }
+
+func oauthClientJSON(redirectURIs, grantTypes, responseTypes, scopes []string, metadata map[string]any) (redirectJSON, grantJSON, responseJSON, scopesJSON, metadataJSON []byte, err error) {
+	for _, field := range []struct {
+		out *[]byte
+		v   any
+	}{
+		{&redirectJSON, nonNilStrings(redirectURIs)},
+		{&grantJSON, nonNilStrings(grantTypes)},
+		{&responseJSON, nonNilStrings(responseTypes)},
+		{&scopesJSON, nonNilStrings(scopes)},
+		{&metadataJSON, metadata},
+	} {
+		if *field.out, err = mustJSONB(field.v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func scanOAuthClient(row pgx.Row) (OAuthClient, error) {
+	var (
+		c            OAuthClient
+		orgID        pgtype.UUID
+		secretHash   pgtype.Text
+		redirectJSON []byte
+		grantJSON    []byte
+		responseJSON []byte
+		scopesJSON   []byte
+		metadataJSON []byte
+		deleted      pgtype.Timestamptz
+	)
+	err := row.Scan(
+		&c.ID,
+		&orgID,
+		&c.Name,
+		&secretHash,
+		&c.Public,
+		&redirectJSON,
+		&grantJSON,
+		&responseJSON,
+		&scopesJSON,
+		&c.Status,
+		&metadataJSON,
+		&c.Version,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&deleted,
+	)
+	if err != nil {
+		return OAuthClient{}, err
+	}
+	c.OrgID = uuidPtr(orgID)
+	c.SecretHash = textPtr(secretHash)
+	c.DeletedAt = timePtr(deleted)
+
+	for _, field := range []struct {
+		out *[]string
+		raw []byte
+	}{
+		{&c.RedirectURIs, redirectJSON},
+		{&c.GrantTypes, grantJSON},
+		{&c.ResponseTypes, responseJSON},
+		{&c.Scopes, scopesJSON},
+	} {
+		if *field.out, err = jsonSliceStringDefault(field.raw); err != nil {
+			return OAuthClient{}, err
+		}
+	}
+	if c.Metadata, err = jsonStringMap(metadataJSON); err != nil {
+		return OAuthClient{}, err
+	}
+	return c, nil
+}
+
+func scanSigningKey(row pgx.Row) (SigningKey, error) {
+	var (
+		k           SigningKey
+		rotatedAt   pgtype.Timestamptz
+		verifyUntil pgtype.Timestamptz
+		retiredAt   pgtype.Timestamptz
+	)
+	err := row.Scan(
+		&k.KID,
+		&k.Algorithm,
+		&k.PrivateKeyCiphertext,
+		&k.Status,
+		&k.CreatedAt,
+		&rotatedAt,
+		&verifyUntil,
+		&retiredAt,
+	)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	k.RotatedAt = timePtr(rotatedAt)
+	k.VerifyUntil = timePtr(verifyUntil)
+	k.RetiredAt = timePtr(retiredAt)
+	return k, nil
+}
+
+func scanAuditOutboxEntry(row pgx.Row) (AuditOutboxEntry, error) {
+	var (
+		e           AuditOutboxEntry
+		lastError   pgtype.Text
+		deliveredAt pgtype.Timestamptz
+	)
+	err := row.Scan(
+		&e.ID,
+		&e.EventID,
+		&e.OrgID,
+		&e.Action,
+		&e.Payload,
+		&e.Attempts,
+		&lastError,
+		&e.NextAttemptAt,
+		&deliveredAt,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return AuditOutboxEntry{}, err
+	}
+	e.LastError = textPtr(lastError)
+	e.DeliveredAt = timePtr(deliveredAt)
+	return e, nil
+}
@@ -1,8 +1,10 @@
 package postgres
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -16,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
 
+	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/columncrypt"
 	"github.com/otherjamesbrown/ai-aas/services/user-org-service/internal/security"
 )
 
@@ -198,6 +201,37 @@ func TestStoreAPIKeyLifecycle(t *testing.T) {
 	require.Equal(t, int64(1), key.Version)
 	require.Equal(t, []string{"billing.read"}, key.Scopes)
 
+	keys, err := store.ListAPIKeysForOrg(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, key.ID, keys[0].ID)
+
+	expiresAt := time.Now().UTC().Add(-time.Minute)
+	expiring, err := store.CreateAPIKey(ctx, CreateAPIKeyParams{
+		OrgID:         org.ID,
+		PrincipalType: PrincipalTypeUser,
+		PrincipalID:   user.ID,
+		Fingerprint:   "fp-456",
+		Status:        "active",
+		ExpiresAt:     &expiresAt,
+	})
+	require.NoError(t, err)
+
+	due, err := store.ListAPIKeysExpiringBefore(ctx, time.Now().UTC(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, expiring.ID, due[0].ID)
+
+	expiring, err = store.UpdateAPIKeyAnnotations(ctx, org.ID, expiring.ID, expiring.Version, map[string]any{"note": "ci"})
+	require.NoError(t, err)
+	require.Equal(t, "ci", expiring.Annotations["note"])
+
+	expired, err := store.ExpireAPIKey(ctx, org.ID, expiring.ID, expiring.Version)
+	require.NoError(t, err)
+	require.Equal(t, "expired", expired.Status)
+	_, err = store.ExpireAPIKey(ctx, org.ID, expiring.ID, expired.Version)
+	require.ErrorIs(t, err, ErrOptimisticLock)
+
 	revokedAt := time.Now().UTC()
 	key, err = store.RevokeAPIKey(ctx, RevokeAPIKeyParams{
 		ID:        key.ID,
@@ -217,3 +251,299 @@ func TestStoreAPIKeyLifecycle(t *testing.T) {
 	}, org.ID)
 	require.ErrorIs(t, err, ErrOptimisticLock)
 }
+
+func TestStoreOAuthClientLifecycle(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	secretHash := "$2a$10$hash"
+	client, err := store.CreateOAuthClient(ctx, CreateOAuthClientParams{
+		ID:           "reporting-app",
+		Name:         "Reporting",
+		SecretHash:   &secretHash,
+		RedirectURIs: []string{"https://reports.example.com/callback"},
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+		Scopes:       []string{"openid"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "active", client.Status)
+	require.Equal(t, []string{"https://reports.example.com/callback"}, client.RedirectURIs)
+	require.Equal(t, []string{}, client.ResponseTypes)
+
+	_, err = store.CreateOAuthClient(ctx, CreateOAuthClientParams{ID: "reporting-app", Name: "Duplicate", Public: true})
+	require.ErrorIs(t, err, ErrConflict)
+
+	updated, err := store.UpdateOAuthClient(ctx, UpdateOAuthClientParams{
+		ID:         client.ID,
+		Version:    client.Version,
+		Name:       "Reporting v2",
+		GrantTypes: client.GrantTypes,
+		Scopes:     []string{"openid", "profile"},
+		Status:     "disabled",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "disabled", updated.Status)
+	require.Equal(t, &secretHash, updated.SecretHash)
+	_, err = store.UpdateOAuthClient(ctx, UpdateOAuthClientParams{ID: client.ID, Version: client.Version, Name: "stale"})
+	require.ErrorIs(t, err, ErrOptimisticLock)
+
+	clients, err := store.ListOAuthClients(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+
+	require.NoError(t, store.DeleteOAuthClient(ctx, client.ID, updated.Version))
+	_, err = store.GetOAuthClient(ctx, client.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreSigningKeyRotation(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	first, err := store.RotateSigningKey(ctx, RotateSigningKeyParams{KID: "kid-1", PrivateKeyCiphertext: []byte("sealed-1")})
+	require.NoError(t, err)
+	require.Equal(t, SigningKeyStatusActive, first.Status)
+	require.Equal(t, "RS256", first.Algorithm)
+
+	// A second replica that saw no active key loses the race
+	_, err = store.RotateSigningKey(ctx, RotateSigningKeyParams{KID: "kid-racer", PrivateKeyCiphertext: []byte("sealed")})
+	require.ErrorIs(t, err, ErrConflict)
+
+	verifyUntil := time.Now().Add(-time.Minute)
+	second, err := store.RotateSigningKey(ctx, RotateSigningKeyParams{
+		PreviousKID:          first.KID,
+		VerifyUntil:          verifyUntil,
+		KID:                  "kid-2",
+		PrivateKeyCiphertext: []byte("sealed-2"),
+	})
+	require.NoError(t, err)
+	_, err = store.RotateSigningKey(ctx, RotateSigningKeyParams{PreviousKID: first.KID, KID: "kid-3", PrivateKeyCiphertext: []byte("sealed-3")})
+	require.ErrorIs(t, err, ErrOptimisticLock)
+
+	keys, err := store.ListSigningKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, second.KID, keys[0].KID)
+	require.Equal(t, SigningKeyStatusRetiring, keys[1].Status)
+	require.NotNil(t, keys[1].VerifyUntil)
+
+	retired, err := store.RetireSigningKeys(ctx, time.Now())
+	require.NoError(t, err)
+	require.Len(t, retired, 1)
+	require.Equal(t, first.KID, retired[0].KID)
+	require.Empty(t, retired[0].PrivateKeyCiphertext)
+
+	keys, err = store.ListSigningKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
+
+func TestStorePurgeOrgsByMetadata(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	seeded, err := store.CreateOrg(ctx, CreateOrgParams{
+		Slug:     "seeded",
+		Name:     "Seeded Org",
+		Status:   "active",
+		Metadata: map[string]any{"seed_marker": "fixtures"},
+	})
+	require.NoError(t, err)
+	kept, err := store.CreateOrg(ctx, CreateOrgParams{
+		Slug:   "kept",
+		Name:   "Kept Org",
+		Status: "active",
+	})
+	require.NoError(t, err)
+
+	user, err := store.CreateUser(ctx, CreateUserParams{
+		OrgID:       seeded.ID,
+		Email:       "user@seeded.io",
+		DisplayName: "Seeded User",
+		Status:      "active",
+	})
+	require.NoError(t, err)
+	_, err = store.CreateAPIKey(ctx, CreateAPIKeyParams{
+		OrgID:         seeded.ID,
+		PrincipalType: PrincipalTypeUser,
+		PrincipalID:   user.ID,
+		Fingerprint:   "fp-seeded",
+		Status:        "active",
+	})
+	require.NoError(t, err)
+
+	purged, err := store.PurgeOrgsByMetadata(ctx, "seed_marker", "fixtures")
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{seeded.ID}, purged)
+
+	_, err = store.GetOrg(ctx, seeded.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = store.GetOrg(ctx, kept.ID)
+	require.NoError(t, err)
+}
+
+func TestStoreAuditOutbox(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	orgID := uuid.New()
+	eventID := uuid.New()
+
+	// Rolled back with the transaction it was written in
+	errRollback := errors.New("rollback")
+	err := store.InTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, store.InsertAuditOutbox(ctx, InsertAuditOutboxParams{EventID: uuid.New(), OrgID: orgID, Action: "org.create", Payload: []byte(`{}`)}))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	require.NoError(t, store.InTx(ctx, func(ctx context.Context) error {
+		return store.InsertAuditOutbox(ctx, InsertAuditOutboxParams{EventID: eventID, OrgID: orgID, Action: "api_key.issue", Payload: []byte(`{"action":"api_key.issue"}`)})
+	}))
+
+	now := time.Now()
+	claimed, err := store.ClaimAuditOutbox(ctx, now, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, eventID, claimed[0].EventID)
+	require.Equal(t, 1, claimed[0].Attempts)
+
+	// Leased rows are not claimed again
+	again, err := store.ClaimAuditOutbox(ctx, now, 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	require.NoError(t, store.MarkAuditOutboxDelivered(ctx, []int64{claimed[0].ID}, now))
+	delivered := false
+	listed, err := store.ListAuditOutbox(ctx, AuditOutboxFilter{OrgID: &orgID, Pending: &delivered})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.NotNil(t, listed[0].DeliveredAt)
+
+	replayed, err := store.ReplayAuditOutbox(ctx, AuditOutboxFilter{EventIDs: []uuid.UUID{eventID}}, now)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, replayed)
+	claimed, err = store.ClaimAuditOutbox(ctx, now, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+}
+
+func TestStoreRetentionAnonymizeAndPurge(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	org, err := store.CreateOrg(ctx, CreateOrgParams{Slug: "vela", Name: "Vela Systems", Status: "active"})
+	require.NoError(t, err)
+	user, err := store.CreateUser(ctx, CreateUserParams{
+		OrgID:       org.ID,
+		Email:       "gone@vela.io",
+		DisplayName: "Gone User",
+		Status:      "active",
+	})
+	require.NoError(t, err)
+	_, err = store.DeleteUser(ctx, org.ID, user.ID, user.Version)
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Hour)
+	candidates, err := store.ListRetentionCandidates(ctx, RetentionEntityUser, RetentionModeAnonymize, later, 10)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.Equal(t, user.ID, candidates[0].ID)
+
+	require.NoError(t, store.AnonymizeSoftDeleted(ctx, candidates[0], time.Now()))
+	candidates, err = store.ListRetentionCandidates(ctx, RetentionEntityUser, RetentionModeAnonymize, later, 10)
+	require.NoError(t, err)
+	require.Empty(t, candidates)
+
+	// Delete mode also purges anonymized rows
+	candidates, err = store.ListRetentionCandidates(ctx, RetentionEntityUser, RetentionModeDelete, later, 10)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.NoError(t, store.PurgeSoftDeleted(ctx, candidates[0]))
+	require.ErrorIs(t, store.PurgeSoftDeleted(ctx, candidates[0]), ErrNotFound)
+}
+
+func TestStoreColumnEncryption(t *testing.T) {
+	store, cleanup := setupStore(t)
+	if store == nil {
+		return // Test was skipped
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	org, err := store.CreateOrg(ctx, CreateOrgParams{Slug: "lyra", Name: "Lyra Labs", Status: "active"})
+	require.NoError(t, err)
+	secret := "JBSWY3DPEHPK3PXP"
+	user, err := store.CreateUser(ctx, CreateUserParams{
+		OrgID:          org.ID,
+		PasswordHash:   "hash",
+		Email:          "mfa@lyra.io",
+		DisplayName:    "MFA User",
+		Status:         "active",
+		MFASecret:      &secret,
+		RecoveryTokens: []string{`{"hash":"abc"}`},
+	})
+	require.NoError(t, err)
+
+	oldKeys, err := columncrypt.New(map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "")
+	require.NoError(t, err)
+	store.UseColumnEncryption(oldKeys)
+
+	// Plaintext written before encryption was enabled is still readable and backfilled
+	batch, err := store.EncryptUserSecrets(ctx, uuid.Nil, 10, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, batch.Rewritten)
+
+	var raw string
+	require.NoError(t, store.Pool().QueryRow(ctx, `SELECT mfa_secret FROM users WHERE user_id = $1`, user.ID).Scan(&raw))
+	require.True(t, oldKeys.Current(raw))
+
+	got, err := store.GetUserByID(ctx, org.ID, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, secret, *got.MFASecret)
+	require.Equal(t, []string{`{"hash":"abc"}`}, got.RecoveryTokens)
+	require.Equal(t, user.Version, got.Version, "backfill must not bump the version")
+
+	// Rotation re-encrypts with the new key
+	rotated, err := columncrypt.New(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}, "k2")
+	require.NoError(t, err)
+	store.UseColumnEncryption(rotated)
+	batch, err = store.EncryptUserSecrets(ctx, uuid.Nil, 10, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, batch.Rewritten)
+	batch, err = store.EncryptUserSecrets(ctx, uuid.Nil, 10, false)
+	require.NoError(t, err)
+	require.Zero(t, batch.Rewritten)
+
+	got, err = store.GetUserByID(ctx, org.ID, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, secret, *got.MFASecret)
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Registered OAuth2 clients, looked up by the Fosite store after the
+-- statically configured clients.
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    client_id      TEXT PRIMARY KEY,
+    org_id         UUID REFERENCES orgs (org_id),
+    name           TEXT NOT NULL,
+    secret_hash    TEXT,
+    public         BOOLEAN NOT NULL DEFAULT FALSE,
+    redirect_uris  JSONB NOT NULL DEFAULT '[]'::jsonb,
+    grant_types    JSONB NOT NULL DEFAULT '[]'::jsonb,
+    response_types JSONB NOT NULL DEFAULT '[]'::jsonb,
+    scopes         JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status         TEXT NOT NULL DEFAULT 'active',
+    metadata       JSONB NOT NULL DEFAULT '{}'::jsonb,
+    version        BIGINT NOT NULL DEFAULT 1,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at     TIMESTAMPTZ,
+    CONSTRAINT oauth_clients_secret_chk CHECK (public OR secret_hash IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS oauth_clients_org_idx ON oauth_clients (org_id) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS oauth_clients;
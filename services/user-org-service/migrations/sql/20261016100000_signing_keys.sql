@@ -0,0 +1,20 @@
+-- +goose Up
+-- JWT signing keys managed by the key manager. Private keys are stored
+-- encrypted (AES-256-GCM) with the key ID as additional data.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid                    TEXT PRIMARY KEY,
+    algorithm              TEXT NOT NULL DEFAULT 'RS256',
+    private_key_ciphertext BYTEA NOT NULL,
+    status                 TEXT NOT NULL DEFAULT 'active',
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at             TIMESTAMPTZ,
+    verify_until           TIMESTAMPTZ,
+    retired_at             TIMESTAMPTZ,
+    CONSTRAINT signing_keys_status_chk CHECK (status IN ('active', 'retiring', 'retired'))
+);
+
+-- At most one key signs at a time; concurrent rotations conflict here
+CREATE UNIQUE INDEX IF NOT EXISTS signing_keys_active_idx ON signing_keys ((TRUE)) WHERE status = 'active';
+
+-- +goose Down
+DROP TABLE IF EXISTS signing_keys;
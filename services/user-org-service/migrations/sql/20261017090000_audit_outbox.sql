@@ -0,0 +1,23 @@
+-- +goose Up
+-- Transactional outbox for audit events. Rows are written in the same
+-- transaction as the mutation they describe and published to the audit sink
+-- by the relay; delivered rows are kept for replay until the retention ends.
+CREATE TABLE IF NOT EXISTS audit_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        UUID NOT NULL UNIQUE,
+    org_id          UUID NOT NULL,
+    action          TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The relay claims due, undelivered rows in insertion order
+CREATE INDEX IF NOT EXISTS audit_outbox_pending_idx ON audit_outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS audit_outbox_created_idx ON audit_outbox (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_outbox;
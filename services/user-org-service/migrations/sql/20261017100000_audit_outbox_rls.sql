@@ -0,0 +1,41 @@
+-- +goose Up
+-- Tenant isolation for audit_outbox, like the other org-scoped tables:
+-- requests only see rows of the org in app.org_id (Store.withTenantTx).
+-- FORCE applies the policies to the table owner too, which is the role the
+-- service connects as.
+ALTER TABLE audit_outbox ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_outbox FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY audit_outbox_tenant_isolation ON audit_outbox
+    USING (org_id = current_setting('app.org_id', true)::uuid)
+    WITH CHECK (org_id = current_setting('app.org_id', true)::uuid);
+
+-- The relay, replay and purge work across orgs. They switch to this role with
+-- SET LOCAL ROLE for their own transactions (Store.withAuditRelayTx) rather
+-- than running unscoped; it cannot log in and only reaches audit_outbox.
+-- Membership is granted to the role running migrations, which is the
+-- service's database role; grant it to any other role running the relay.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'audit_outbox_relay') THEN
+        CREATE ROLE audit_outbox_relay NOLOGIN;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+GRANT SELECT, UPDATE, DELETE ON audit_outbox TO audit_outbox_relay;
+GRANT audit_outbox_relay TO CURRENT_USER;
+
+CREATE POLICY audit_outbox_relay_all ON audit_outbox TO audit_outbox_relay
+    USING (true)
+    WITH CHECK (true);
+
+-- +goose Down
+DROP POLICY IF EXISTS audit_outbox_relay_all ON audit_outbox;
+DROP POLICY IF EXISTS audit_outbox_tenant_isolation ON audit_outbox;
+ALTER TABLE audit_outbox NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE audit_outbox DISABLE ROW LEVEL SECURITY;
+REVOKE ALL ON audit_outbox FROM audit_outbox_relay;
+-- The role is left in place: it is cluster-wide and may be granted elsewhere
@@ -1,38 +1,88 @@
+// Command world-service is the reference HTTP service built on the shared Go
+// service template.
+//
+// Purpose:
+//
+//	Serves GET /world with the conventions every service follows: configuration
+//	from shared-go config (SERVICE_NAME, SERVICE_ADDRESS, OTEL_*), telemetry
+//	from observability.MustInit, structured JSON logs, /healthz, /readyz and
+//	/metrics from the shared lifecycle server, and drain-aware graceful
+//	shutdown on SIGINT/SIGTERM.
+//
+// Debugging Notes:
+//   - SERVICE_NAME defaults to world-service and SERVICE_ADDRESS to :8080
+//   - An unreachable OTLP endpoint degrades telemetry rather than failing startup
 package main
 
 import (
+	"context"
 	"errors"
-	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/config"
+	"github.com/ai-aas/shared-go/logging"
+	"github.com/ai-aas/shared-go/observability"
+	"github.com/ai-aas/shared-go/server"
+
 	"github.com/otherjamesbrown/ai-aas/services/world-service/pkg/world"
 )
 
+const serviceName = "world-service"
+
 func main() {
-	addr := defaultAddr()
-	mux := http.NewServeMux()
-	mux.HandleFunc("/world", world.Handler)
-
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadTimeout:       5 * time.Second,
-		WriteTimeout:      10 * time.Second,
-		ReadHeaderTimeout: 2 * time.Second,
-		IdleTimeout:       60 * time.Second,
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if _, ok := os.LookupEnv("SERVICE_NAME"); !ok {
+		_ = os.Setenv("SERVICE_NAME", serviceName)
 	}
+	cfg := config.MustLoad(ctx)
 
-	log.Printf("world-service listening on %s", addr)
-	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("server error: %v", err)
+	logCfg := logging.DefaultConfig().WithServiceName(cfg.Service.Name)
+	logger := logging.MustNew(logCfg).Logger
+	defer logger.Sync() //nolint:errcheck
+
+	telemetry := observability.MustInit(ctx, observability.Config{
+		ServiceName: cfg.Service.Name,
+		Environment: logCfg.Environment,
+		Endpoint:    cfg.Telemetry.Endpoint,
+		Protocol:    cfg.Telemetry.Protocol,
+		Headers:     cfg.Telemetry.Headers,
+		Insecure:    cfg.Telemetry.Insecure,
+	})
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := telemetry.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("failed to shutdown telemetry", zap.Error(err))
+		}
+	}()
+
+	srv := newServer(cfg, logger)
+	logger.Info("world-service listening", zap.String("addr", cfg.Service.Address))
+	if err := srv.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal("server error", zap.Error(err))
 	}
+	logger.Info("world-service stopped")
 }
 
-func defaultAddr() string {
-	if addr := os.Getenv("WORLD_SERVICE_ADDR"); addr != "" {
-		return addr
-	}
-	return ":8080"
+// newServer wires the application routes into the shared lifecycle server.
+func newServer(cfg config.Config, logger *zap.Logger) *server.Server {
+	router := chi.NewRouter()
+	router.Use(observability.RequestContextMiddleware)
+	router.Get("/world", world.Handler)
+
+	return server.New(server.Options{
+		Addr:          cfg.Service.Address,
+		Handler:       router,
+		Logger:        logger,
+		EnableMetrics: true,
+	})
 }
@@ -0,0 +1,260 @@
+// Package apiversion negotiates the API version of a request and announces
+// deprecated routes.
+//
+// The version comes from a /vN path segment (/v1/orgs, /analytics/v2/...) or,
+// for unversioned paths, from an Accept header naming a vendor media type
+// (application/vnd.ai-aas.v2+json) or a version parameter
+// (application/json; version=2). Middleware stores it in the request
+// context, echoes it in the API-Version response header and, for routes
+// matching a Deprecation, sets the Deprecation (RFC 9745), Sunset (RFC 8594)
+// and Link headers and counts the request in
+// api_deprecated_requests_total so owners can see who still calls them.
+package apiversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// Response headers.
+const (
+	VersionHeader     = "API-Version"
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// MediaTypePrefix starts the vendor media type selecting a version.
+const MediaTypePrefix = "application/vnd.ai-aas.v"
+
+// ErrCodeUnsupportedVersion is returned in 406 bodies.
+const ErrCodeUnsupportedVersion = "UNSUPPORTED_API_VERSION"
+
+// ExposedHeaders are the headers browser scripts need to read to notice
+// deprecations; add them to CORS exposed headers.
+var ExposedHeaders = []string{VersionHeader, DeprecationHeader, SunsetHeader, "Link"}
+
+var deprecatedRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "api_deprecated_requests_total",
+		Help: "Requests served by deprecated API routes by service, route prefix and version.",
+	},
+	[]string{"service_name", "route", "version"},
+)
+
+// Deprecation marks routes due for removal.
+type Deprecation struct {
+	// Prefix matches request paths; empty matches every path of Version.
+	Prefix string `json:"prefix,omitempty"`
+	// Version limits the deprecation to one API version; 0 matches any.
+	Version int `json:"version,omitempty"`
+	// Since is when the routes were deprecated; zero announces "Deprecation: true".
+	Since time.Time `json:"since,omitempty"`
+	// Sunset is when the routes stop being served.
+	Sunset time.Time `json:"sunset,omitempty"`
+	// Link documents the migration path.
+	Link string `json:"link,omitempty"`
+}
+
+func (d Deprecation) matches(path string, version int) bool {
+	if d.Version != 0 && d.Version != version {
+		return false
+	}
+	return strings.HasPrefix(path, d.Prefix)
+}
+
+func (d Deprecation) route() string {
+	if d.Prefix == "" {
+		return "*"
+	}
+	return d.Prefix
+}
+
+// ParseDeprecations parses a JSON array of deprecations, as set in service
+// configuration. An empty string yields none.
+func ParseDeprecations(raw string) ([]Deprecation, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var deprecations []Deprecation
+	if err := json.Unmarshal([]byte(raw), &deprecations); err != nil {
+		return nil, fmt.Errorf("parse API deprecations: %w", err)
+	}
+	for n, d := range deprecations {
+		if d.Prefix == "" && d.Version == 0 {
+			return nil, fmt.Errorf("API deprecation %d: prefix or version is required", n)
+		}
+		if !d.Since.IsZero() && !d.Sunset.IsZero() && d.Sunset.Before(d.Since) {
+			return nil, fmt.Errorf("API deprecation %d: sunset is before since", n)
+		}
+	}
+	return deprecations, nil
+}
+
+// Config configures Middleware.
+type Config struct {
+	// ServiceName labels the deprecated request metric.
+	ServiceName string
+	// Supported lists the versions callers may request; defaults to [1].
+	Supported []int
+	// Default is used when neither the path nor Accept names a version;
+	// defaults to the highest supported version.
+	Default int
+	// Deprecations are checked in order; the first match applies.
+	Deprecations []Deprecation
+}
+
+type contextKey struct{}
+
+// FromContext returns the negotiated version, or 0 outside Middleware.
+func FromContext(ctx context.Context) int {
+	version, _ := ctx.Value(contextKey{}).(int)
+	return version
+}
+
+// NewContext returns ctx carrying version.
+func NewContext(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, contextKey{}, version)
+}
+
+// FromPath returns the version of the first /vN path segment.
+func FromPath(path string) (int, bool) {
+	for _, segment := range strings.Split(path, "/") {
+		if version, ok := parseVersion(segment, "v"); ok {
+			return version, true
+		}
+	}
+	return 0, false
+}
+
+// FromAccept returns the version requested by an Accept header, from a
+// vendor media type or a version parameter.
+func FromAccept(accept string) (int, bool) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		parts := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(parts[0]))
+		if strings.HasPrefix(mediaType, MediaTypePrefix) {
+			rest := strings.TrimPrefix(mediaType, MediaTypePrefix)
+			if i := strings.IndexByte(rest, '+'); i >= 0 {
+				rest = rest[:i]
+			}
+			if version, ok := parseVersion(rest, ""); ok {
+				return version, true
+			}
+		}
+		for _, param := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.EqualFold(strings.TrimSpace(key), "version") {
+				if version, ok := parseVersion(strings.Trim(strings.TrimSpace(value), `"`), ""); ok {
+					return version, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func parseVersion(s, prefix string) (int, bool) {
+	if !strings.HasPrefix(s, prefix) || len(s) == len(prefix) {
+		return 0, false
+	}
+	version, err := strconv.Atoi(s[len(prefix):])
+	if err != nil || version <= 0 || strconv.Itoa(version) != s[len(prefix):] {
+		return 0, false
+	}
+	return version, true
+}
+
+// Middleware negotiates the request's API version. Requests whose Accept
+// header names an unsupported version, or a version other than the one in
+// the path, are rejected with 406.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	supported := cfg.Supported
+	if len(supported) == 0 {
+		supported = []int{1}
+	}
+	defaultVersion := cfg.Default
+	if defaultVersion == 0 {
+		for _, v := range supported {
+			if v > defaultVersion {
+				defaultVersion = v
+			}
+		}
+	}
+	isSupported := func(version int) bool {
+		for _, v := range supported {
+			if v == version {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pathVersion, inPath := FromPath(r.URL.Path)
+			acceptVersion, inAccept := FromAccept(r.Header.Get("Accept"))
+
+			version := defaultVersion
+			switch {
+			case inAccept && inPath && acceptVersion != pathVersion:
+				notAcceptable(w, r, fmt.Sprintf("Accept requests API version %d but the path is version %d", acceptVersion, pathVersion), supported)
+				return
+			case inAccept && !isSupported(acceptVersion):
+				notAcceptable(w, r, fmt.Sprintf("API version %d is not supported", acceptVersion), supported)
+				return
+			case inPath:
+				version = pathVersion
+			case inAccept:
+				version = acceptVersion
+			}
+
+			w.Header().Set(VersionHeader, strconv.Itoa(version))
+			for _, d := range cfg.Deprecations {
+				if d.matches(r.URL.Path, version) {
+					setDeprecationHeaders(w.Header(), d)
+					deprecatedRequests.WithLabelValues(cfg.ServiceName, d.route(), strconv.Itoa(version)).Inc()
+					break
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), version)))
+		})
+	}
+}
+
+func setDeprecationHeaders(header http.Header, d Deprecation) {
+	if d.Since.IsZero() {
+		header.Set(DeprecationHeader, "true")
+	} else {
+		header.Set(DeprecationHeader, "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		header.Set(SunsetHeader, d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, d.Link))
+	}
+}
+
+func notAcceptable(w http.ResponseWriter, r *http.Request, message string, supported []int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotAcceptable)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error":              message,
+		"code":               ErrCodeUnsupportedVersion,
+		"supported_versions": supported,
+		"request_id":         requestid.RequestID(r.Context()),
+		"correlation_id":     requestid.CorrelationID(r.Context()),
+	})
+}
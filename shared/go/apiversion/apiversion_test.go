@@ -0,0 +1,124 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func serve(t *testing.T, cfg Config, path, accept string) (*httptest.ResponseRecorder, int) {
+	t.Helper()
+	var got int
+	handler := Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr, got
+}
+
+func TestFromAccept(t *testing.T) {
+	cases := map[string]int{
+		"application/vnd.ai-aas.v2+json":               2,
+		"text/html, application/vnd.ai-aas.v3":         3,
+		"application/json; version=2":                  2,
+		`application/json; charset=utf-8; version="4"`: 4,
+		"application/json":                             0,
+		"application/vnd.ai-aas.v0+json":               0,
+		"application/vnd.ai-aas.vx+json":               0,
+	}
+	for accept, want := range cases {
+		got, ok := FromAccept(accept)
+		if got != want || ok != (want != 0) {
+			t.Errorf("FromAccept(%q) = %d, %v; want %d", accept, got, ok, want)
+		}
+	}
+}
+
+func TestFromPath(t *testing.T) {
+	cases := map[string]int{
+		"/v1/orgs":             1,
+		"/analytics/v2/orgs/x": 2,
+		"/healthz":             0,
+		"/v01/orgs":            0,
+		"/orgs/vendor/usage":   0,
+	}
+	for path, want := range cases {
+		got, ok := FromPath(path)
+		if got != want || ok != (want != 0) {
+			t.Errorf("FromPath(%q) = %d, %v; want %d", path, got, ok, want)
+		}
+	}
+}
+
+func TestMiddlewareNegotiatesVersion(t *testing.T) {
+	cfg := Config{Supported: []int{1, 2}, Default: 1}
+
+	if rr, got := serve(t, cfg, "/v2/orgs", ""); got != 2 || rr.Header().Get(VersionHeader) != "2" {
+		t.Fatalf("expected path version 2, got %d (header %q)", got, rr.Header().Get(VersionHeader))
+	}
+	if _, got := serve(t, cfg, "/healthz", "application/vnd.ai-aas.v2+json"); got != 2 {
+		t.Fatalf("expected Accept version 2, got %d", got)
+	}
+	if _, got := serve(t, cfg, "/healthz", ""); got != 1 {
+		t.Fatalf("expected default version 1, got %d", got)
+	}
+	if rr, _ := serve(t, cfg, "/healthz", "application/vnd.ai-aas.v3+json"); rr.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected 406 for unsupported version, got %d", rr.Code)
+	}
+	if rr, _ := serve(t, cfg, "/v1/orgs", "application/json; version=2"); rr.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected 406 for Accept/path mismatch, got %d", rr.Code)
+	}
+}
+
+func TestMiddlewareAnnouncesDeprecation(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	cfg := Config{
+		ServiceName: "test-deprecation",
+		Deprecations: []Deprecation{
+			{Prefix: "/v1/inference", Since: since, Sunset: sunset, Link: "https://docs.example.com/migrate"},
+		},
+	}
+
+	rr, _ := serve(t, cfg, "/v1/inference", "")
+	if rr.Header().Get(DeprecationHeader) != "@1767225600" {
+		t.Fatalf("unexpected Deprecation header %q", rr.Header().Get(DeprecationHeader))
+	}
+	if rr.Header().Get(SunsetHeader) != "Wed, 01 Jul 2026 00:00:00 GMT" {
+		t.Fatalf("unexpected Sunset header %q", rr.Header().Get(SunsetHeader))
+	}
+	if rr.Header().Get("Link") != `<https://docs.example.com/migrate>; rel="deprecation"` {
+		t.Fatalf("unexpected Link header %q", rr.Header().Get("Link"))
+	}
+	if got := testutil.ToFloat64(deprecatedRequests.WithLabelValues("test-deprecation", "/v1/inference", "1")); got != 1 {
+		t.Fatalf("expected one deprecated request counted, got %v", got)
+	}
+
+	if rr, _ := serve(t, cfg, "/v1/chat/completions", ""); rr.Header().Get(DeprecationHeader) != "" {
+		t.Fatal("expected no deprecation headers on other routes")
+	}
+}
+
+func TestParseDeprecations(t *testing.T) {
+	deprecations, err := ParseDeprecations(`[{"prefix":"/v1/inference","sunset":"2026-07-01T00:00:00Z"},{"version":1}]`)
+	if err != nil || len(deprecations) != 2 || deprecations[0].Sunset.IsZero() {
+		t.Fatalf("unexpected result %+v, %v", deprecations, err)
+	}
+	if deprecations, err := ParseDeprecations(""); err != nil || deprecations != nil {
+		t.Fatalf("expected no deprecations, got %+v, %v", deprecations, err)
+	}
+	if _, err := ParseDeprecations(`[{"link":"https://example.com"}]`); err == nil {
+		t.Fatal("expected error without prefix or version")
+	}
+	if _, err := ParseDeprecations(`[{"version":1,"since":"2026-07-01T00:00:00Z","sunset":"2026-01-01T00:00:00Z"}]`); err == nil {
+		t.Fatal("expected error for sunset before since")
+	}
+}
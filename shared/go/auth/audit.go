@@ -1,13 +1,24 @@
 package auth
 
-import "sync/atomic"
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // AuditEvent captures a single authorization decision.
 type AuditEvent struct {
-	Action  string   `json:"action"`
-	Subject string   `json:"subject"`
-	Roles   []string `json:"roles"`
-	Allowed bool     `json:"allowed"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	Subject   string    `json:"subject"`
+	Roles     []string  `json:"roles"`
+	Allowed   bool      `json:"allowed"`
+	// PolicyRevision identifies the bundle that made the decision.
+	PolicyRevision string `json:"policyRevision,omitempty"`
+	// Error is set when the policy engine failed and the request was denied.
+	Error string `json:"error,omitempty"`
 }
 
 // AuditRecorder records audit events. It is configurable via SetAuditRecorder.
@@ -35,9 +46,22 @@ func recordAudit(event AuditEvent) {
 // NewAuditEvent constructs an audit event from the supplied action and actor.
 func NewAuditEvent(action string, actor Actor, allowed bool) AuditEvent {
 	return AuditEvent{
-		Action:  action,
-		Subject: actor.Subject,
-		Roles:   append([]string(nil), actor.Roles...),
-		Allowed: allowed,
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		Subject:   actor.Subject,
+		Roles:     append([]string(nil), actor.Roles...),
+		Allowed:   allowed,
+	}
+}
+
+// NewDecisionLog returns an AuditRecorder writing each decision to w as a
+// JSON line, for shipping to a log pipeline alongside service logs.
+func NewDecisionLog(w io.Writer) AuditRecorder {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(event AuditEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(event)
 	}
 }
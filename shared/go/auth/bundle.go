@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Media types and annotations for policy bundles published as OCI artifacts.
+const (
+	PolicyMediaType     = "application/vnd.ai-aas.policy.v1+json"
+	SignatureAnnotation = "dev.ai-aas.policy.signature"
+	ociManifestType     = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// maxBundleSize bounds bundles and signatures read from remote sources.
+const maxBundleSize = 4 << 20
+
+// ErrSignatureInvalid is returned when a bundle does not match its signature.
+var ErrSignatureInvalid = errors.New("policy bundle signature invalid")
+
+// BundleSource fetches a policy bundle. Sources with a Verifier only return
+// bundles whose signature checks out.
+type BundleSource interface {
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// Verifier checks a detached signature over a bundle.
+type Verifier interface {
+	Verify(bundle, signature []byte) error
+}
+
+// Ed25519Verifier verifies Ed25519 signatures, raw or base64 encoded.
+type Ed25519Verifier struct {
+	PublicKey ed25519.PublicKey
+}
+
+// Verify implements Verifier.
+func (v Ed25519Verifier) Verify(bundle, signature []byte) error {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(v.PublicKey, bundle, sig) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// LoadEd25519Verifier reads a PEM-encoded (PKIX) Ed25519 public key.
+func LoadEd25519Verifier(path string) (Ed25519Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Ed25519Verifier{}, fmt.Errorf("read policy public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Ed25519Verifier{}, fmt.Errorf("policy public key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return Ed25519Verifier{}, fmt.Errorf("parse policy public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return Ed25519Verifier{}, fmt.Errorf("policy public key %s is not an Ed25519 key", path)
+	}
+	return Ed25519Verifier{PublicKey: pub}, nil
+}
+
+func decodeSignature(signature []byte) ([]byte, error) {
+	if len(signature) == ed25519.SignatureSize {
+		return signature, nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: malformed signature", ErrSignatureInvalid)
+	}
+	return sig, nil
+}
+
+// verify checks bundle against signature when verifier is set.
+func verify(verifier Verifier, bundle, signature []byte) error {
+	if verifier == nil {
+		return nil
+	}
+	if len(signature) == 0 {
+		return fmt.Errorf("%w: bundle is unsigned", ErrSignatureInvalid)
+	}
+	return verifier.Verify(bundle, signature)
+}
+
+// FileBundle reads a bundle from disk, with an optional detached signature.
+type FileBundle struct {
+	Path string
+	// SignaturePath defaults to Path + ".sig" when Verifier is set.
+	SignaturePath string
+	Verifier      Verifier
+}
+
+// Fetch implements BundleSource.
+func (s FileBundle) Fetch(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy bundle: %w", err)
+	}
+	var signature []byte
+	if s.Verifier != nil {
+		path := s.SignaturePath
+		if path == "" {
+			path = s.Path + ".sig"
+		}
+		if signature, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read policy signature: %w", err)
+		}
+	}
+	if err := verify(s.Verifier, data, signature); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// HTTPBundle downloads a bundle over HTTP(S), with an optional detached
+// signature served alongside it.
+type HTTPBundle struct {
+	URL string
+	// SignatureURL defaults to URL + ".sig" when Verifier is set.
+	SignatureURL string
+	Verifier     Verifier
+	// Header is added to both requests (e.g. Authorization).
+	Header http.Header
+	Client *http.Client
+}
+
+// Fetch implements BundleSource.
+func (s HTTPBundle) Fetch(ctx context.Context) ([]byte, error) {
+	client := httpClient(s.Client)
+	data, err := get(ctx, client, s.URL, s.Header)
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy bundle: %w", err)
+	}
+	var signature []byte
+	if s.Verifier != nil {
+		url := s.SignatureURL
+		if url == "" {
+			url = s.URL + ".sig"
+		}
+		if signature, err = get(ctx, client, url, s.Header); err != nil {
+			return nil, fmt.Errorf("fetch policy signature: %w", err)
+		}
+	}
+	if err := verify(s.Verifier, data, signature); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// OCIBundle pulls a bundle published as an OCI artifact: the manifest's
+// PolicyMediaType layer is the bundle and its SignatureAnnotation (on the
+// layer or the manifest) carries the base64 signature.
+type OCIBundle struct {
+	// Reference is registry/repository:tag or registry/repository@digest.
+	Reference string
+	Verifier  Verifier
+	// Token is sent as a bearer token when set.
+	Token string
+	// PlainHTTP talks to the registry without TLS (local registries only).
+	PlainHTTP bool
+	Client    *http.Client
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	Layers      []ociDescriptor   `json:"layers"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Fetch implements BundleSource.
+func (s OCIBundle) Fetch(ctx context.Context) ([]byte, error) {
+	registry, repository, ref, err := parseOCIReference(s.Reference)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "https"
+	if s.PlainHTTP {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, registry, repository)
+	header := http.Header{}
+	if s.Token != "" {
+		header.Set("Authorization", "Bearer "+s.Token)
+	}
+	client := httpClient(s.Client)
+
+	manifestHeader := header.Clone()
+	manifestHeader.Set("Accept", ociManifestType)
+	raw, err := get(ctx, client, base+"/manifests/"+ref, manifestHeader)
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy manifest: %w", err)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("decode policy manifest: %w", err)
+	}
+	var layer *ociDescriptor
+	for i := range manifest.Layers {
+		if manifest.Layers[i].MediaType == PolicyMediaType {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return nil, fmt.Errorf("manifest %s has no %s layer", s.Reference, PolicyMediaType)
+	}
+
+	data, err := get(ctx, client, base+"/blobs/"+layer.Digest, header)
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy blob: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if digest := "sha256:" + hex.EncodeToString(sum[:]); digest != layer.Digest {
+		return nil, fmt.Errorf("policy blob digest %s does not match manifest %s", digest, layer.Digest)
+	}
+
+	signature := layer.Annotations[SignatureAnnotation]
+	if signature == "" {
+		signature = manifest.Annotations[SignatureAnnotation]
+	}
+	if err := verify(s.Verifier, data, []byte(signature)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseOCIReference splits registry/repository:tag (or @digest).
+func parseOCIReference(reference string) (registry, repository, ref string, err error) {
+	reference = strings.TrimPrefix(reference, "oci://")
+	registry, rest, ok := strings.Cut(reference, "/")
+	if !ok || registry == "" || rest == "" {
+		return "", "", "", fmt.Errorf("invalid OCI reference %q: want registry/repository:tag", reference)
+	}
+	if repo, digest, ok := strings.Cut(rest, "@"); ok {
+		return registry, repo, digest, nil
+	}
+	if i := strings.LastIndex(rest, ":"); i > 0 {
+		return registry, rest[:i], rest[i+1:], nil
+	}
+	return registry, rest, "latest", nil
+}
+
+// NewBundleSource picks a source for location: oci:// references, http(s)://
+// URLs, or a file path.
+func NewBundleSource(location string, verifier Verifier) BundleSource {
+	switch {
+	case strings.HasPrefix(location, "oci://"):
+		return OCIBundle{Reference: location, Verifier: verifier}
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+		return HTTPBundle{URL: location, Verifier: verifier}
+	default:
+		return FileBundle{Path: location, Verifier: verifier}
+	}
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func get(ctx context.Context, client *http.Client, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBundleSize {
+		return nil, fmt.Errorf("GET %s: response exceeds %d bytes", url, maxBundleSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBundle = `{"rules":{"GET:/secure":["admin"]}}`
+
+func newTestKey(t *testing.T) (ed25519.PrivateKey, Ed25519Verifier) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return priv, Ed25519Verifier{PublicKey: pub}
+}
+
+func sign(priv ed25519.PrivateKey, data string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(data)))
+}
+
+func TestFileBundleVerifiesSignature(t *testing.T) {
+	priv, verifier := newTestKey(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	writeFile(t, path, testBundle)
+	writeFile(t, path+".sig", sign(priv, testBundle))
+
+	data, err := FileBundle{Path: path, Verifier: verifier}.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if string(data) != testBundle {
+		t.Fatalf("unexpected bundle %q", data)
+	}
+
+	writeFile(t, path, `{"rules":{"GET:/secure":["anyone"]}}`)
+	if _, err := (FileBundle{Path: path, Verifier: verifier}).Fetch(context.Background()); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected ErrSignatureInvalid for tampered bundle, got %v", err)
+	}
+}
+
+func TestLoadEd25519Verifier(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.pub")
+	writeFile(t, path, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+	verifier, err := LoadEd25519Verifier(path)
+	if err != nil {
+		t.Fatalf("load verifier: %v", err)
+	}
+	if err := verifier.Verify([]byte(testBundle), []byte(sign(priv, testBundle))); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestHTTPBundle(t *testing.T) {
+	priv, verifier := newTestKey(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/policy.json":
+			_, _ = w.Write([]byte(testBundle))
+		case "/policy.json.sig":
+			_, _ = w.Write([]byte(sign(priv, testBundle)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	source := HTTPBundle{URL: srv.URL + "/policy.json", Verifier: verifier, Header: http.Header{"Authorization": {"Bearer secret"}}}
+	data, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if string(data) != testBundle {
+		t.Fatalf("unexpected bundle %q", data)
+	}
+
+	source.Header = nil
+	if _, err := source.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+}
+
+func TestOCIBundle(t *testing.T) {
+	priv, verifier := newTestKey(t)
+	sum := sha256.Sum256([]byte(testBundle))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	manifest, _ := json.Marshal(ociManifest{
+		Layers: []ociDescriptor{{
+			MediaType:   PolicyMediaType,
+			Digest:      digest,
+			Size:        int64(len(testBundle)),
+			Annotations: map[string]string{SignatureAnnotation: sign(priv, testBundle)},
+		}},
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/policies/router/manifests/v3":
+			if r.Header.Get("Accept") != ociManifestType {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			_, _ = w.Write(manifest)
+		case "/v2/policies/router/blobs/" + digest:
+			_, _ = w.Write([]byte(testBundle))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	source := OCIBundle{Reference: "oci://" + registry + "/policies/router:v3", Verifier: verifier, PlainHTTP: true}
+	data, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if string(data) != testBundle {
+		t.Fatalf("unexpected bundle %q", data)
+	}
+
+	_, other := newTestKey(t)
+	source.Verifier = other
+	if _, err := source.Fetch(context.Background()); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected ErrSignatureInvalid with the wrong key, got %v", err)
+	}
+}
+
+func TestParseOCIReference(t *testing.T) {
+	tests := []struct {
+		in                  string
+		registry, repo, ref string
+	}{
+		{"oci://ghcr.io/acme/policy:v1", "ghcr.io", "acme/policy", "v1"},
+		{"localhost:5000/policy", "localhost:5000", "policy", "latest"},
+		{"ghcr.io/acme/policy@sha256:abc", "ghcr.io", "acme/policy", "sha256:abc"},
+	}
+	for _, tc := range tests {
+		registry, repo, ref, err := parseOCIReference(tc.in)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.in, err)
+		}
+		if registry != tc.registry || repo != tc.repo || ref != tc.ref {
+			t.Fatalf("parse %q = %s %s %s", tc.in, registry, repo, ref)
+		}
+	}
+	if _, _, _, err := parseOCIReference("policy"); err == nil {
+		t.Fatal("expected error for reference without registry")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
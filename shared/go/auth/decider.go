@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+	"github.com/ai-aas/shared-go/requestid"
+)
+
+// Policy engine names accepted by NewPolicyStore (POLICY_ENGINE).
+const (
+	EngineJSON = "json"
+	EngineRego = "rego"
+)
+
+// DefaultRegoQuery is evaluated when PolicyConfig.Query is empty.
+const DefaultRegoQuery = "data.authz.allow"
+
+// ErrRegoUnavailable is returned when the rego engine is selected in a binary
+// built without the opa build tag.
+var ErrRegoUnavailable = errors.New("rego policy engine not compiled in (build with -tags opa)")
+
+var (
+	policyDecisions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "policy_decisions_total",
+			Help: "Authorization decisions by policy engine and outcome (allowed, denied or error).",
+		},
+		[]string{"engine", "outcome"},
+	)
+	policyDecisionDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "policy_decision_duration_seconds",
+			Help:    "Time spent evaluating authorization decisions by policy engine.",
+			Buckets: []float64{.00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025},
+		},
+		[]string{"engine"},
+	)
+)
+
+// Input is the authorization query handed to a policy engine. Rego policies
+// see it as `input`.
+type Input struct {
+	Action     string         `json:"action"` // METHOD:path, as keyed in JSON policies
+	Method     string         `json:"method"`
+	Path       string         `json:"path"`
+	Subject    string         `json:"subject"`
+	Roles      []string       `json:"roles"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Decider evaluates authorization inputs.
+type Decider interface {
+	Decide(ctx context.Context, input Input) (bool, error)
+	// Revision identifies the policy bundle currently in effect.
+	Revision() string
+}
+
+// PolicyStore is a Decider whose bundle can be replaced while serving.
+type PolicyStore interface {
+	Decider
+	Update(data []byte) error
+}
+
+// PolicyConfig selects and configures a policy engine.
+type PolicyConfig struct {
+	// Engine is json (default) or rego.
+	Engine string
+	// Query is the rego rule deciding access; defaults to DefaultRegoQuery.
+	Query string
+}
+
+// NewPolicyStore returns an empty store for the configured engine; load it
+// with NewPolicyReloaderFor or Update.
+func NewPolicyStore(cfg PolicyConfig) (PolicyStore, error) {
+	switch strings.ToLower(cfg.Engine) {
+	case "", EngineJSON:
+		return &Engine{}, nil
+	case EngineRego:
+		query := cfg.Query
+		if query == "" {
+			query = DefaultRegoQuery
+		}
+		return newRegoEngine(query)
+	default:
+		return nil, fmt.Errorf("unknown policy engine %q (want %s or %s)", cfg.Engine, EngineJSON, EngineRego)
+	}
+}
+
+// Decide implements Decider for JSON policies.
+func (e *Engine) Decide(_ context.Context, input Input) (bool, error) {
+	return e.Allowed(input.Action, input.Roles), nil
+}
+
+// engineName labels decision metrics.
+func engineName(d Decider) string {
+	switch d := d.(type) {
+	case *Engine:
+		return EngineJSON
+	case interface{ EngineName() string }:
+		return d.EngineName()
+	}
+	return "custom"
+}
+
+// Enricher adds request or auth context to the policy input.
+type Enricher func(r *http.Request, input *Input)
+
+// setAttribute sets input.Attributes[key], allocating the map as needed.
+func setAttribute(input *Input, key string, value any) {
+	if input.Attributes == nil {
+		input.Attributes = map[string]any{}
+	}
+	input.Attributes[key] = value
+}
+
+// RequestEnricher exposes the query string and request ID to policies.
+func RequestEnricher(r *http.Request, input *Input) {
+	if len(r.URL.Query()) > 0 {
+		setAttribute(input, "query", r.URL.Query())
+	}
+	if id := requestID(r); id != "" {
+		setAttribute(input, "request_id", id)
+	}
+	if id := r.Header.Get(requestid.CorrelationIDHeader); id != "" {
+		setAttribute(input, "correlation_id", id)
+	}
+}
+
+// requestID prefers the ID assigned by requestid.Middleware over the raw
+// header.
+func requestID(r *http.Request) string {
+	if id := requestid.RequestID(r.Context()); id != "" {
+		return id
+	}
+	return r.Header.Get(requestid.RequestIDHeader)
+}
+
+type claimsContextKey struct{}
+
+// ContextWithClaims attaches validated token claims for ClaimsEnricher.
+func ContextWithClaims(ctx context.Context, claims tokens.Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns claims attached by ContextWithClaims.
+func ClaimsFromContext(ctx context.Context) (tokens.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(tokens.Claims)
+	return claims, ok
+}
+
+// ClaimsEnricher exposes the token claims verified upstream (see
+// ContextWithClaims) so policies can match on org, user, client and scopes.
+func ClaimsEnricher(r *http.Request, input *Input) {
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		return
+	}
+	setAttribute(input, "org_id", claims.OrgID)
+	setAttribute(input, "user_id", claims.UserID)
+	setAttribute(input, "client_id", claims.ClientID)
+	setAttribute(input, "scopes", claims.Scopes)
+}
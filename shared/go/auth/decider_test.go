@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-aas/shared-go/auth/tokens"
+)
+
+// captureDecider records the input it was asked about.
+type captureDecider struct {
+	input   Input
+	allowed bool
+	err     error
+}
+
+func (d *captureDecider) Decide(_ context.Context, input Input) (bool, error) {
+	d.input = input
+	return d.allowed, d.err
+}
+
+func (d *captureDecider) Revision() string { return "test" }
+
+func TestDeciderMiddlewareEnrichesInput(t *testing.T) {
+	decider := &captureDecider{allowed: true}
+	handler := DeciderMiddleware(decider, HeaderExtractor, RequestEnricher, ClaimsEnricher)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/usage?window=7d", nil)
+	req.Header.Set("X-Actor-Subject", "alice")
+	req.Header.Set("X-Actor-Roles", "analyst")
+	req.Header.Set("X-Request-ID", "req-1")
+	req = req.WithContext(ContextWithClaims(req.Context(), tokens.Claims{OrgID: "org-1", Scopes: []string{"usage:read"}}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	in := decider.input
+	if in.Action != "GET:/v1/usage" || in.Method != http.MethodGet || in.Path != "/v1/usage" || in.Subject != "alice" {
+		t.Fatalf("unexpected input %+v", in)
+	}
+	if in.Attributes["org_id"] != "org-1" || in.Attributes["request_id"] != "req-1" {
+		t.Fatalf("expected enriched attributes, got %+v", in.Attributes)
+	}
+}
+
+func TestDeciderMiddlewareDeniesOnError(t *testing.T) {
+	var recorded []AuditEvent
+	SetAuditRecorder(func(event AuditEvent) { recorded = append(recorded, event) })
+	t.Cleanup(func() { SetAuditRecorder(nil) })
+
+	decider := &captureDecider{allowed: true, err: errors.New("policy engine unavailable")}
+	handler := DeciderMiddleware(decider, nil)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/secure", nil))
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+	if len(recorded) != 1 || recorded[0].Allowed || recorded[0].Error == "" {
+		t.Fatalf("expected denied audit event with error, got %+v", recorded)
+	}
+}
+
+func TestNewPolicyStore(t *testing.T) {
+	store, err := NewPolicyStore(PolicyConfig{})
+	if err != nil {
+		t.Fatalf("default engine: %v", err)
+	}
+	if _, ok := store.(*Engine); !ok {
+		t.Fatalf("expected JSON engine by default, got %T", store)
+	}
+	if _, err := NewPolicyStore(PolicyConfig{Engine: "cedar"}); err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+}
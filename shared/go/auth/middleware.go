@@ -4,8 +4,10 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/ai-aas/shared-go/errors"
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 type contextKey string
@@ -46,15 +48,50 @@ func HeaderExtractor(r *http.Request) Actor {
 
 // Middleware enforces authorization using the supplied engine.
 func Middleware(engine *Engine, extractor Extractor) func(http.Handler) http.Handler {
+	return DeciderMiddleware(engine, extractor)
+}
+
+// DeciderMiddleware enforces authorization using any policy engine (see
+// NewPolicyStore). Enrichers add request and auth context to the policy
+// input. Evaluation errors deny the request.
+func DeciderMiddleware(decider Decider, extractor Extractor, enrichers ...Enricher) func(http.Handler) http.Handler {
 	if extractor == nil {
 		extractor = HeaderExtractor
 	}
+	engine := engineName(decider)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			actor := extractor(r)
-			action := r.Method + ":" + r.URL.Path
-			allowed := engine.Allowed(action, actor.Roles)
-			recordAudit(NewAuditEvent(action, actor, allowed))
+			input := Input{
+				Action:  r.Method + ":" + r.URL.Path,
+				Method:  r.Method,
+				Path:    r.URL.Path,
+				Subject: actor.Subject,
+				Roles:   actor.Roles,
+			}
+			for _, enrich := range enrichers {
+				enrich(r, &input)
+			}
+
+			start := time.Now()
+			allowed, err := decider.Decide(r.Context(), input)
+			policyDecisionDuration.WithLabelValues(engine).Observe(time.Since(start).Seconds())
+			outcome := "denied"
+			switch {
+			case err != nil:
+				outcome = "error"
+				allowed = false
+			case allowed:
+				outcome = "allowed"
+			}
+			policyDecisions.WithLabelValues(engine, outcome).Inc()
+
+			event := NewAuditEvent(input.Action, actor, allowed)
+			event.PolicyRevision = decider.Revision()
+			if err != nil {
+				event.Error = err.Error()
+			}
+			recordAudit(event)
 
 			if !allowed {
 				resp := errors.New("UNAUTHORIZED", "access denied",
@@ -62,7 +99,8 @@ func Middleware(engine *Engine, extractor Extractor) func(http.Handler) http.Han
 						Subject: actor.Subject,
 						Roles:   actor.Roles,
 					}),
-					errors.WithRequestID(r.Header.Get("X-Request-ID")),
+					errors.WithRequestID(requestID(r)),
+					errors.WithCorrelationID(r.Header.Get(requestid.CorrelationIDHeader)),
 				)
 				data, _ := errors.Marshal(resp)
 				w.Header().Set("Content-Type", "application/json")
@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 )
 
 // Policy describes authorization rules keyed by method:path → allowed roles.
@@ -13,9 +16,17 @@ type Policy struct {
 	Rules map[string][]string `json:"rules"`
 }
 
-// Engine evaluates requests against an in-memory policy.
+// Engine evaluates requests against an in-memory policy. Its rules can be
+// replaced while serving (see PolicyReloader); evaluations always see one
+// complete bundle.
 type Engine struct {
-	allowed map[string]map[string]struct{}
+	rules atomic.Pointer[ruleSet]
+}
+
+// ruleSet is one compiled policy bundle.
+type ruleSet struct {
+	allowed  map[string]map[string]struct{}
+	revision string
 }
 
 // LoadPolicyFromFile loads a JSON policy bundle from disk.
@@ -30,23 +41,50 @@ func LoadPolicyFromFile(path string) (*Engine, error) {
 
 // LoadPolicy loads a policy from any reader.
 func LoadPolicy(r io.Reader) (*Engine, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read policy bundle: %w", err)
+	}
+	engine := &Engine{}
+	if err := engine.Update(data); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Update replaces the engine's rules with the JSON policy bundle in data. On
+// error the current rules stay in effect.
+func (e *Engine) Update(data []byte) error {
 	var policy Policy
-	if err := json.NewDecoder(r).Decode(&policy); err != nil {
-		return nil, fmt.Errorf("decode policy bundle: %w", err)
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return fmt.Errorf("decode policy bundle: %w", err)
 	}
-	engine := &Engine{allowed: map[string]map[string]struct{}{}}
+	rules := &ruleSet{allowed: map[string]map[string]struct{}{}, revision: bundleRevision(data)}
 	for resource, roles := range policy.Rules {
 		key := strings.ToUpper(resource)
-		set := engine.allowed[key]
+		set := rules.allowed[key]
 		if set == nil {
 			set = map[string]struct{}{}
-			engine.allowed[key] = set
+			rules.allowed[key] = set
 		}
 		for _, role := range roles {
 			set[strings.ToLower(role)] = struct{}{}
 		}
 	}
-	return engine, nil
+	e.rules.Store(rules)
+	return nil
+}
+
+// Revision identifies the loaded bundle (a digest of its contents), so
+// decisions can be traced to the rules that made them.
+func (e *Engine) Revision() string {
+	if e == nil {
+		return ""
+	}
+	if rules := e.rules.Load(); rules != nil {
+		return rules.revision
+	}
+	return ""
 }
 
 // Allowed returns true when the supplied roles satisfy the rule for the action.
@@ -54,7 +92,11 @@ func (e *Engine) Allowed(action string, roles []string) bool {
 	if e == nil {
 		return false
 	}
-	set := e.allowed[strings.ToUpper(action)]
+	rules := e.rules.Load()
+	if rules == nil {
+		return false
+	}
+	set := rules.allowed[strings.ToUpper(action)]
 	if len(set) == 0 {
 		return false
 	}
@@ -65,3 +107,8 @@ func (e *Engine) Allowed(action string, roles []string) bool {
 	}
 	return false
 }
+
+func bundleRevision(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
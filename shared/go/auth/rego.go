@@ -0,0 +1,70 @@
+//go:build opa
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// regoEngine evaluates a Rego module from the policy bundle with an embedded
+// OPA. The bundle is the module source; Update compiles it once so requests
+// only pay for evaluation.
+type regoEngine struct {
+	query    string
+	compiled atomic.Pointer[regoPolicy]
+}
+
+type regoPolicy struct {
+	prepared rego.PreparedEvalQuery
+	revision string
+}
+
+func newRegoEngine(query string) (PolicyStore, error) {
+	return &regoEngine{query: query}, nil
+}
+
+// EngineName labels decision metrics.
+func (e *regoEngine) EngineName() string {
+	return EngineRego
+}
+
+// Update compiles data as a Rego module. On error the current policy stays in
+// effect.
+func (e *regoEngine) Update(data []byte) error {
+	prepared, err := rego.New(
+		rego.Query(e.query),
+		rego.Module("policy.rego", string(data)),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return fmt.Errorf("compile rego policy: %w", err)
+	}
+	e.compiled.Store(&regoPolicy{prepared: prepared, revision: bundleRevision(data)})
+	return nil
+}
+
+// Revision identifies the compiled bundle.
+func (e *regoEngine) Revision() string {
+	if policy := e.compiled.Load(); policy != nil {
+		return policy.revision
+	}
+	return ""
+}
+
+// Decide evaluates the query against input; anything but a single true
+// result denies.
+func (e *regoEngine) Decide(ctx context.Context, input Input) (bool, error) {
+	policy := e.compiled.Load()
+	if policy == nil {
+		return false, errors.New("no rego policy loaded")
+	}
+	results, err := policy.prepared.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return false, fmt.Errorf("evaluate %s: %w", e.query, err)
+	}
+	return results.Allowed(), nil
+}
@@ -0,0 +1,9 @@
+//go:build !opa
+
+package auth
+
+// newRegoEngine reports that OPA support was not compiled in; build with
+// -tags opa to embed the evaluator.
+func newRegoEngine(string) (PolicyStore, error) {
+	return nil, ErrRegoUnavailable
+}
@@ -0,0 +1,60 @@
+//go:build opa
+
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+const testRegoPolicy = `package authz
+
+default allow := false
+
+allow if {
+	input.action == "GET:/secure"
+	"admin" in input.roles
+}
+
+allow if {
+	input.method == "GET"
+	input.attributes.org_id == "org-1"
+}
+`
+
+func TestRegoEngine(t *testing.T) {
+	store, err := NewPolicyStore(PolicyConfig{Engine: EngineRego})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Update([]byte(testRegoPolicy)); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input Input
+		want  bool
+	}{
+		{"role rule", Input{Action: "GET:/secure", Method: "GET", Roles: []string{"admin"}}, true},
+		{"missing role", Input{Action: "GET:/secure", Method: "GET", Roles: []string{"viewer"}}, false},
+		{"enriched attribute", Input{Action: "GET:/other", Method: "GET", Attributes: map[string]any{"org_id": "org-1"}}, true},
+	}
+	for _, tc := range tests {
+		got, err := store.Decide(context.Background(), tc.input)
+		if err != nil {
+			t.Fatalf("%s: decide: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	revision := store.Revision()
+	if err := store.Update([]byte("package authz\nallow if {")); err == nil {
+		t.Fatal("expected compile error")
+	}
+	if store.Revision() != revision {
+		t.Fatal("expected previous policy to stay in effect")
+	}
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// reloadDebounce coalesces the burst of file events a bundle update produces
+// (e.g. a ConfigMap symlink swap).
+const reloadDebounce = 200 * time.Millisecond
+
+var policyReloads = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "policy_reloads_total",
+		Help: "Policy bundle reload attempts by outcome (changed, unchanged or failed).",
+	},
+	[]string{"outcome"},
+)
+
+// PolicyReloader keeps a PolicyStore in sync with a BundleSource. Requests
+// keep being evaluated against the previous bundle until a new one has been
+// fetched, verified and parsed (or compiled, for rego).
+type PolicyReloader struct {
+	source BundleSource
+	store  PolicyStore
+	logger *zap.Logger
+
+	mu sync.Mutex
+}
+
+// NewPolicyReloader loads the initial JSON bundle from source.
+func NewPolicyReloader(ctx context.Context, source BundleSource, logger *zap.Logger) (*PolicyReloader, error) {
+	return NewPolicyReloaderFor(ctx, source, &Engine{}, logger)
+}
+
+// NewPolicyReloaderFor loads the initial bundle from source into store (see
+// NewPolicyStore).
+func NewPolicyReloaderFor(ctx context.Context, source BundleSource, store PolicyStore, logger *zap.Logger) (*PolicyReloader, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	data, err := source.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load policy bundle: %w", err)
+	}
+	if err := store.Update(data); err != nil {
+		return nil, err
+	}
+	logger.Info("policy bundle loaded", zap.String("engine", engineName(store)), zap.String("revision", store.Revision()))
+	return &PolicyReloader{source: source, store: store, logger: logger}, nil
+}
+
+// Engine returns the JSON engine the reloader updates (nil for other
+// engines); pass it to Middleware.
+func (r *PolicyReloader) Engine() *Engine {
+	engine, _ := r.store.(*Engine)
+	return engine
+}
+
+// Store returns the policy store the reloader updates; pass it to
+// DeciderMiddleware.
+func (r *PolicyReloader) Store() PolicyStore {
+	return r.store
+}
+
+// Reload fetches the bundle and swaps it in when it changed. On error the
+// previous rules stay in effect.
+func (r *PolicyReloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := r.source.Fetch(ctx)
+	if err != nil {
+		policyReloads.WithLabelValues("failed").Inc()
+		return fmt.Errorf("load policy bundle: %w", err)
+	}
+	if bundleRevision(data) == r.store.Revision() {
+		policyReloads.WithLabelValues("unchanged").Inc()
+		return nil
+	}
+	previous := r.store.Revision()
+	if err := r.store.Update(data); err != nil {
+		policyReloads.WithLabelValues("failed").Inc()
+		return err
+	}
+	policyReloads.WithLabelValues("changed").Inc()
+	r.logger.Info("policy bundle reloaded",
+		zap.String("previous_revision", previous), zap.String("revision", r.store.Revision()))
+	return nil
+}
+
+// Watch reloads on SIGHUP, whenever a FileBundle's directory changes, and
+// every interval (when positive, e.g. to poll HTTP or OCI sources). It blocks
+// until ctx is cancelled.
+func (r *PolicyReloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	if fileSource, ok := r.source.(FileBundle); ok {
+		// Watch the directory: editors and ConfigMap updates replace the file.
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			err = watcher.Add(filepath.Dir(fileSource.Path))
+		}
+		if err != nil {
+			r.logger.Warn("policy file watch unavailable, relying on SIGHUP and polling", zap.Error(err))
+		} else {
+			defer watcher.Close()
+			events, watchErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	reload := func(trigger string) {
+		if err := r.Reload(ctx); err != nil {
+			r.logger.Error("policy reload failed, keeping previous bundle",
+				zap.String("trigger", trigger), zap.String("revision", r.store.Revision()), zap.Error(err))
+		}
+	}
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("sighup")
+		case <-tick:
+			reload("interval")
+		case <-events:
+			settle = time.After(reloadDebounce)
+		case <-settle:
+			settle = nil
+			reload("file")
+		case err := <-watchErrors:
+			r.logger.Warn("policy file watch error", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPolicyReloaderKeepsPreviousBundleOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writeFile(t, path, testBundle)
+
+	reloader, err := NewPolicyReloader(context.Background(), FileBundle{Path: path}, nil)
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+	engine := reloader.Engine()
+	first := engine.Revision()
+	if !engine.Allowed("GET:/secure", []string{"admin"}) {
+		t.Fatal("expected admin to be allowed")
+	}
+
+	writeFile(t, path, `{"rules":`)
+	if err := reloader.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload of malformed bundle to fail")
+	}
+	if engine.Revision() != first || !engine.Allowed("GET:/secure", []string{"admin"}) {
+		t.Fatal("expected previous bundle to stay in effect")
+	}
+
+	writeFile(t, path, `{"rules":{"GET:/secure":["viewer"]}}`)
+	if err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if engine.Revision() == first {
+		t.Fatal("expected revision to change")
+	}
+	if engine.Allowed("GET:/secure", []string{"admin"}) || !engine.Allowed("GET:/secure", []string{"viewer"}) {
+		t.Fatal("expected new rules to apply")
+	}
+}
+
+func TestPolicyReloaderWatchesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writeFile(t, path, testBundle)
+
+	reloader, err := NewPolicyReloader(context.Background(), FileBundle{Path: path}, nil)
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 0)
+
+	// Give the watcher time to register before changing the file.
+	time.Sleep(100 * time.Millisecond)
+	writeFile(t, path, `{"rules":{"GET:/secure":["viewer"]}}`)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !reloader.Engine().Allowed("GET:/secure", []string{"viewer"}) {
+		if time.Now().After(deadline) {
+			t.Fatal("bundle change was not picked up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDecisionLogRecordsRevision(t *testing.T) {
+	engine, err := LoadPolicy(bytes.NewBufferString(testBundle))
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+	var buf bytes.Buffer
+	SetAuditRecorder(NewDecisionLog(&buf))
+	t.Cleanup(func() { SetAuditRecorder(nil) })
+
+	handler := Middleware(engine, HeaderExtractor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("X-Actor-Subject", "bob")
+	req.Header.Set("X-Actor-Roles", "viewer")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var event AuditEvent
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatalf("decode decision log %q: %v", buf.String(), err)
+	}
+	if event.Allowed || event.Subject != "bob" || event.Action != "GET:/secure" {
+		t.Fatalf("unexpected decision %+v", event)
+	}
+	if event.PolicyRevision == "" || event.PolicyRevision != engine.Revision() {
+		t.Fatalf("expected policy revision %q, got %q", engine.Revision(), event.PolicyRevision)
+	}
+	if event.Timestamp.IsZero() {
+		t.Fatal("expected timestamp")
+	}
+}
@@ -0,0 +1,124 @@
+// Package tokens validates platform access tokens issued by user-org-service.
+//
+// Services that accept bearer tokens share these helpers so expiry, not
+// before, issuer and audience are checked the same way everywhere, with a
+// configurable clock skew allowance, and so introspection results are cached
+// consistently. Service tokens (service.go) carry a workload identity and
+// authenticate calls between platform services.
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultSkew is the clock skew allowance used when Validator.Skew is zero.
+const DefaultSkew = 30 * time.Second
+
+// Validation errors; every one wraps ErrInvalidToken.
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInactive         = fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	ErrExpired          = fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	ErrNotYetValid      = fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	ErrIssuedInFuture   = fmt.Errorf("%w: token was issued in the future", ErrInvalidToken)
+	ErrIssuerMismatch   = fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	ErrAudienceMismatch = fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+)
+
+// Claims are the validated facts about a token. Zero times mean the claim
+// was absent.
+type Claims struct {
+	Active    bool
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	// OrgID, UserID and Roles are the platform's private claims; Roles are
+	// the user's org roles (e.g. org_admin, finance, viewer).
+	OrgID  string
+	UserID string
+	Roles  []string
+}
+
+// HasScope reports whether the token was granted scope.
+func (c Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Validator checks the time-based and identity claims of a token.
+type Validator struct {
+	// Issuer is the required iss; empty accepts any issuer.
+	Issuer string
+	// Audience lists accepted aud values; empty accepts any audience.
+	Audience []string
+	// Skew tolerates clock drift between issuer and validator; zero uses
+	// DefaultSkew and a negative value disables the allowance.
+	Skew time.Duration
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Validate returns nil when claims are acceptable now.
+func (v Validator) Validate(c Claims) error {
+	if !c.Active {
+		return ErrInactive
+	}
+	now := v.now()
+	skew := v.skew()
+	if !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt.Add(skew)) {
+		return ErrExpired
+	}
+	if !c.NotBefore.IsZero() && now.Add(skew).Before(c.NotBefore) {
+		return ErrNotYetValid
+	}
+	if !c.IssuedAt.IsZero() && now.Add(skew).Before(c.IssuedAt) {
+		return ErrIssuedInFuture
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("%w: %q", ErrIssuerMismatch, c.Issuer)
+	}
+	if len(v.Audience) > 0 && !intersects(v.Audience, c.Audience) {
+		return ErrAudienceMismatch
+	}
+	return nil
+}
+
+func (v Validator) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func (v Validator) skew() time.Duration {
+	switch {
+	case v.Skew == 0:
+		return DefaultSkew
+	case v.Skew < 0:
+		return 0
+	default:
+		return v.Skew
+	}
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Introspector resolves a token to its claims. Inactive tokens return
+// Claims with Active false and a nil error; errors mean the answer is unknown.
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (Claims, error)
+}
+
+// HTTPIntrospector calls an RFC 7662 token introspection endpoint such as
+// user-org-service's /v1/auth/introspect.
+type HTTPIntrospector struct {
+	// URL of the introspection endpoint.
+	URL string
+	// ClientID and ClientSecret authenticate the caller with HTTP Basic auth.
+	ClientID     string
+	ClientSecret string
+	// Client performs the request; nil uses a client with a 5s timeout.
+	Client *http.Client
+}
+
+var defaultIntrospectionClient = &http.Client{Timeout: 5 * time.Second}
+
+// introspectionResponse is the RFC 7662 response plus the platform's private claims.
+type introspectionResponse struct {
+	Active    bool     `json:"active"`
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ClientID  string   `json:"client_id"`
+	Scope     string   `json:"scope"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	OrgID     string   `json:"org_id"`
+	UserID    string   `json:"user_id"`
+	Roles     []string `json:"roles"`
+}
+
+// audience accepts aud as a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single != "" {
+			*a = audience{single}
+		}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings: %w", err)
+	}
+	*a = many
+	return nil
+}
+
+// Introspect posts token to the endpoint and maps the response to Claims.
+func (i *HTTPIntrospector) Introspect(ctx context.Context, token string) (Claims, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+	}
+
+	client := i.Client
+	if client == nil {
+		client = defaultIntrospectionClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Claims{}, fmt.Errorf("introspect token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Claims{}, fmt.Errorf("introspection endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Claims{}, fmt.Errorf("decode introspection response: %w", err)
+	}
+	if !payload.Active {
+		return Claims{}, nil
+	}
+	return Claims{
+		Active:    true,
+		Subject:   payload.Subject,
+		Issuer:    payload.Issuer,
+		Audience:  payload.Audience,
+		ClientID:  payload.ClientID,
+		Scopes:    strings.Fields(payload.Scope),
+		ExpiresAt: unixTime(payload.ExpiresAt),
+		NotBefore: unixTime(payload.NotBefore),
+		IssuedAt:  unixTime(payload.IssuedAt),
+		OrgID:     payload.OrgID,
+		UserID:    payload.UserID,
+		Roles:     payload.Roles,
+	}, nil
+}
+
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,203 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceScope is the scope of service tokens: access tokens minted for a
+// workload (a registered OAuth client) with the client credentials grant.
+// The workload's identity is the token's client ID.
+const ServiceScope = "service"
+
+// maxServiceRefresh caps how early a service token is replaced.
+const maxServiceRefresh = 5 * time.Minute
+
+// Service authentication errors; the first two wrap ErrInvalidToken.
+var (
+	ErrNotServiceToken   = fmt.Errorf("%w: not a service token", ErrInvalidToken)
+	ErrMissingToken      = fmt.Errorf("%w: bearer token required", ErrInvalidToken)
+	ErrServiceNotAllowed = errors.New("service is not allowed to call this endpoint")
+)
+
+// Service returns the workload identity of a service token.
+func (c Claims) Service() (string, bool) {
+	if !c.HasScope(ServiceScope) || c.ClientID == "" || c.UserID != "" {
+		return "", false
+	}
+	return c.ClientID, true
+}
+
+// ServiceTokenSource mints service tokens from user-org-service's
+// /v1/auth/service-token and reuses each one until shortly before it expires.
+type ServiceTokenSource struct {
+	// URL of the token endpoint.
+	URL string
+	// ClientID and ClientSecret are the workload's client credentials.
+	ClientID     string
+	ClientSecret string
+	// Client performs the request; nil uses a client with a 5s timeout.
+	Client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+// Token returns a service token, minting a new one when the cached token is
+// within a fifth of its lifetime (at most five minutes) of expiring.
+func (s *ServiceTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {ServiceScope}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create service token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.ClientID), url.QueryEscape(s.ClientSecret))
+
+	client := s.Client
+	if client == nil {
+		client = defaultIntrospectionClient
+	}
+	issuedAt := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("service token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("service token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode service token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", errors.New("service token response has no access_token")
+	}
+
+	lifetime := time.Duration(payload.ExpiresIn) * time.Second
+	margin := min(lifetime/5, maxServiceRefresh)
+	s.token = payload.AccessToken
+	s.refreshAt = issuedAt.Add(lifetime - margin)
+	return s.token, nil
+}
+
+// ServiceTransport authenticates outgoing requests with a service token.
+// Requests that already carry an Authorization header are sent unchanged.
+type ServiceTransport struct {
+	Source *ServiceTokenSource
+	Base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ServiceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Header.Get("Authorization") != "" {
+		return base.RoundTrip(req)
+	}
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return base.RoundTrip(req)
+}
+
+// TokenVerifier resolves a bearer token to validated claims; *Verifier
+// implements it, and the token issuer can verify its own tokens locally.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Claims, error)
+}
+
+// ServiceAuth configures RequireService.
+type ServiceAuth struct {
+	Verifier TokenVerifier
+	// Allowed are the client IDs that may call; empty allows any service.
+	Allowed []string
+	// Enforce rejects calls without a valid service token. When false such
+	// calls are let through (and reported), for rolling out identities.
+	Enforce bool
+	// OnFailure, if set, is told about every call that failed authentication.
+	OnFailure func(r *http.Request, err error)
+}
+
+type serviceContextKey struct{}
+
+// ServiceFromContext returns the workload identity authenticated by RequireService.
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(serviceContextKey{}).(string)
+	return service, ok
+}
+
+// RequireService authenticates calls from other platform services by their
+// service token, replacing trust in the caller's network location. Missing
+// or invalid tokens are 401, services outside Allowed 403 and verification
+// errors 503.
+func RequireService(cfg ServiceAuth) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			service, err := cfg.authenticate(r)
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), serviceContextKey{}, service)))
+				return
+			}
+			if cfg.OnFailure != nil {
+				cfg.OnFailure(r, err)
+			}
+			switch {
+			case !cfg.Enforce:
+				next.ServeHTTP(w, r)
+			case errors.Is(err, ErrServiceNotAllowed):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is(err, ErrInvalidToken):
+				http.Error(w, "service token required", http.StatusUnauthorized)
+			default:
+				http.Error(w, "service authentication unavailable", http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+func (cfg ServiceAuth) authenticate(r *http.Request) (string, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		return "", ErrMissingToken
+	}
+	claims, err := cfg.Verifier.Verify(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		return "", err
+	}
+	service, ok := claims.Service()
+	if !ok {
+		return "", ErrNotServiceToken
+	}
+	if len(cfg.Allowed) > 0 && !intersects(cfg.Allowed, []string{service}) {
+		return "", fmt.Errorf("%w: %s", ErrServiceNotAllowed, service)
+	}
+	return service, nil
+}
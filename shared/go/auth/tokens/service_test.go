@@ -0,0 +1,111 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type staticVerifier map[string]Claims
+
+func (v staticVerifier) Verify(_ context.Context, token string) (Claims, error) {
+	if token == "down" {
+		return Claims{}, errors.New("introspection unavailable")
+	}
+	claims, ok := v[token]
+	if !ok {
+		return Claims{}, ErrInactive
+	}
+	return claims, nil
+}
+
+func TestServiceTransportReusesToken(t *testing.T) {
+	var minted atomic.Int32
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "api-router" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != ServiceScope {
+			http.Error(w, "bad request", http.StatusUnauthorized)
+			return
+		}
+		minted.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "svc-token", "expires_in": 3600})
+	}))
+	defer issuer.Close()
+
+	var seen []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+	}))
+	defer upstream.Close()
+
+	source := &ServiceTokenSource{URL: issuer.URL, ClientID: "api-router", ClientSecret: "s3cret"}
+	client := &http.Client{Transport: &ServiceTransport{Source: source}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(upstream.URL)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if minted.Load() != 1 {
+		t.Fatalf("expected one minted token, got %d", minted.Load())
+	}
+	if len(seen) != 2 || seen[0] != "Bearer svc-token" || seen[1] != "Bearer svc-token" {
+		t.Fatalf("unexpected authorization headers %v", seen)
+	}
+}
+
+func TestRequireService(t *testing.T) {
+	verifier := staticVerifier{
+		"router":  {Active: true, ClientID: "api-router", Scopes: []string{ServiceScope}},
+		"other":   {Active: true, ClientID: "batch-worker", Scopes: []string{ServiceScope}},
+		"user":    {Active: true, ClientID: "web-portal", UserID: "user-1", Scopes: []string{ServiceScope}},
+		"noscope": {Active: true, ClientID: "api-router"},
+	}
+	var caller string
+	handler := func(enforce bool) http.Handler {
+		return RequireService(ServiceAuth{Verifier: verifier, Allowed: []string{"api-router"}, Enforce: enforce})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				caller, _ = ServiceFromContext(r.Context())
+			}))
+	}
+
+	cases := []struct {
+		token string
+		want  int
+	}{
+		{"router", http.StatusOK},
+		{"", http.StatusUnauthorized},
+		{"unknown", http.StatusUnauthorized},
+		{"user", http.StatusUnauthorized},
+		{"noscope", http.StatusUnauthorized},
+		{"other", http.StatusForbidden},
+		{"down", http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		caller = ""
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/validate-api-key", nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler(true).ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("token %q: expected %d, got %d", tc.token, tc.want, rec.Code)
+		}
+		if tc.want == http.StatusOK && caller != "api-router" {
+			t.Fatalf("expected caller identity, got %q", caller)
+		}
+
+		// Without enforcement every call passes, unauthenticated
+		rec = httptest.NewRecorder()
+		handler(false).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("token %q: expected permissive pass, got %d", tc.token, rec.Code)
+		}
+	}
+}
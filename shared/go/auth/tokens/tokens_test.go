@@ -0,0 +1,238 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var baseTime = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func fixedClock(t *time.Time) func() time.Time {
+	return func() time.Time { return *t }
+}
+
+func TestValidatorSkew(t *testing.T) {
+	now := baseTime
+	v := Validator{Skew: 30 * time.Second, Now: fixedClock(&now)}
+	expiring := Claims{Active: true, ExpiresAt: baseTime}
+	starting := Claims{Active: true, NotBefore: baseTime, ExpiresAt: baseTime.Add(time.Hour)}
+
+	cases := []struct {
+		name   string
+		claims Claims
+		now    time.Time
+		want   error
+	}{
+		{"expired within skew", expiring, baseTime.Add(29 * time.Second), nil},
+		{"expired beyond skew", expiring, baseTime.Add(30 * time.Second), ErrExpired},
+		{"not before within skew", starting, baseTime.Add(-30 * time.Second), nil},
+		{"not before beyond skew", starting, baseTime.Add(-31 * time.Second), ErrNotYetValid},
+	}
+	for _, tc := range cases {
+		now = tc.now
+		if err := v.Validate(tc.claims); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	now = baseTime
+	if err := v.Validate(Claims{Active: true, IssuedAt: baseTime.Add(time.Minute)}); !errors.Is(err, ErrIssuedInFuture) {
+		t.Fatalf("expected ErrIssuedInFuture, got %v", err)
+	}
+	strict := Validator{Skew: -1, Now: fixedClock(&now)}
+	if err := strict.Validate(Claims{Active: true, ExpiresAt: baseTime}); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired without skew, got %v", err)
+	}
+	if err := (Validator{}).Validate(Claims{}); !errors.Is(err, ErrInactive) || !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInactive wrapping ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestValidatorIssuerAndAudience(t *testing.T) {
+	v := Validator{Issuer: "https://auth.example.com", Audience: []string{"api-router", "analytics"}}
+	ok := Claims{Active: true, Issuer: "https://auth.example.com", Audience: []string{"analytics"}}
+	if err := v.Validate(ok); err != nil {
+		t.Fatalf("expected valid claims, got %v", err)
+	}
+
+	wrongIssuer := ok
+	wrongIssuer.Issuer = "https://evil.example.com"
+	if err := v.Validate(wrongIssuer); !errors.Is(err, ErrIssuerMismatch) {
+		t.Fatalf("expected ErrIssuerMismatch, got %v", err)
+	}
+	wrongAudience := ok
+	wrongAudience.Audience = []string{"billing"}
+	if err := v.Validate(wrongAudience); !errors.Is(err, ErrAudienceMismatch) {
+		t.Fatalf("expected ErrAudienceMismatch, got %v", err)
+	}
+}
+
+func TestHTTPIntrospector(t *testing.T) {
+	var gotToken, gotClient string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		gotToken = r.PostForm.Get("token")
+		gotClient, _, _ = r.BasicAuth()
+		switch gotToken {
+		case "active":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"active": true, "sub": "user-1", "iss": "https://auth.example.com", "aud": "api-router",
+				"scope": "openid inference:read", "exp": baseTime.Unix(), "iat": baseTime.Add(-time.Hour).Unix(),
+				"client_id": "web", "org_id": "org-1", "user_id": "user-1", "roles": []string{"finance"},
+			})
+		case "broken":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+		}
+	}))
+	defer server.Close()
+
+	introspector := &HTTPIntrospector{URL: server.URL, ClientID: "api-router", ClientSecret: "secret"}
+	claims, err := introspector.Introspect(context.Background(), "active")
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if gotClient != "api-router" || gotToken != "active" {
+		t.Fatalf("unexpected request: client %q token %q", gotClient, gotToken)
+	}
+	if !claims.Active || claims.OrgID != "org-1" || !claims.HasScope("inference:read") || claims.Audience[0] != "api-router" ||
+		len(claims.Roles) != 1 || claims.Roles[0] != "finance" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if !claims.ExpiresAt.Equal(baseTime) || !claims.NotBefore.IsZero() {
+		t.Fatalf("unexpected times: %+v", claims)
+	}
+
+	claims, err = introspector.Introspect(context.Background(), "revoked")
+	if err != nil || claims.Active {
+		t.Fatalf("expected inactive claims, got %+v, %v", claims, err)
+	}
+	if _, err := introspector.Introspect(context.Background(), "broken"); err == nil {
+		t.Fatalf("expected error for failed introspection")
+	}
+}
+
+type countingIntrospector struct {
+	calls  int
+	claims map[string]Claims
+	err    error
+}
+
+func (c *countingIntrospector) Introspect(_ context.Context, token string) (Claims, error) {
+	c.calls++
+	if c.err != nil {
+		return Claims{}, c.err
+	}
+	return c.claims[token], nil
+}
+
+func TestVerifierCachesUntilExpiry(t *testing.T) {
+	now := baseTime
+	introspector := &countingIntrospector{claims: map[string]Claims{
+		"short": {Active: true, Subject: "user-1", ExpiresAt: baseTime.Add(10 * time.Second)},
+	}}
+	v := NewVerifier(VerifierConfig{
+		Introspector: introspector,
+		Validator:    Validator{Skew: 5 * time.Second, Now: fixedClock(&now)},
+		CacheTTL:     time.Minute,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(ctx, "short"); err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+	}
+	if introspector.calls != 1 || v.Len() != 1 {
+		t.Fatalf("expected one introspection and one entry, got %d calls and %d entries", introspector.calls, v.Len())
+	}
+
+	// The cached entry ends at exp plus skew even though CacheTTL is longer
+	now = baseTime.Add(15 * time.Second)
+	if _, err := v.Verify(ctx, "short"); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+	if introspector.calls != 2 {
+		t.Fatalf("expected re-introspection after the cache entry expired, got %d calls", introspector.calls)
+	}
+}
+
+func TestVerifierNegativeCacheAndErrors(t *testing.T) {
+	now := baseTime
+	introspector := &countingIntrospector{claims: map[string]Claims{}}
+	v := NewVerifier(VerifierConfig{
+		Introspector:     introspector,
+		Validator:        Validator{Now: fixedClock(&now)},
+		NegativeCacheTTL: 5 * time.Second,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := v.Verify(ctx, "revoked"); !errors.Is(err, ErrInactive) {
+			t.Fatalf("expected ErrInactive, got %v", err)
+		}
+	}
+	if introspector.calls != 1 {
+		t.Fatalf("expected the inactive result to be cached, got %d calls", introspector.calls)
+	}
+	now = now.Add(5 * time.Second)
+	_, _ = v.Verify(ctx, "revoked")
+	if introspector.calls != 2 {
+		t.Fatalf("expected the negative entry to expire, got %d calls", introspector.calls)
+	}
+
+	if n := v.Flush(); n != 1 {
+		t.Fatalf("expected one flushed entry, got %d", n)
+	}
+	introspector.err = errors.New("unavailable")
+	_, err := v.Verify(ctx, "other")
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected introspection error, got %v", err)
+	}
+	if v.Len() != 0 {
+		t.Fatalf("failed introspections must not be cached")
+	}
+}
+
+func TestVerifierInvalidateOrgs(t *testing.T) {
+	now := baseTime
+	introspector := &countingIntrospector{claims: map[string]Claims{
+		"org-1-user": {Active: true, Subject: "user-1", OrgID: "org-1", ExpiresAt: baseTime.Add(time.Hour)},
+		"org-2-user": {Active: true, Subject: "user-2", OrgID: "org-2", ExpiresAt: baseTime.Add(time.Hour)},
+	}}
+	v := NewVerifier(VerifierConfig{
+		Introspector: introspector,
+		Validator:    Validator{Now: fixedClock(&now)},
+	})
+	ctx := context.Background()
+	for _, token := range []string{"org-1-user", "org-2-user", "revoked"} {
+		_, _ = v.Verify(ctx, token)
+	}
+	if v.Len() != 3 {
+		t.Fatalf("expected three cached entries, got %d", v.Len())
+	}
+
+	if n := v.InvalidateOrgs(map[string]bool{"org-1": true}); n != 1 {
+		t.Fatalf("expected one invalidated entry, got %d", n)
+	}
+	if v.Len() != 2 {
+		t.Fatalf("expected the other org and the rejection to stay cached, got %d entries", v.Len())
+	}
+
+	// The suspended org's token is introspected again and now rejected
+	introspector.claims["org-1-user"] = Claims{}
+	if _, err := v.Verify(ctx, "org-1-user"); !errors.Is(err, ErrInactive) {
+		t.Fatalf("expected ErrInactive after invalidation, got %v", err)
+	}
+	if introspector.calls != 4 {
+		t.Fatalf("expected re-introspection of the invalidated token only, got %d calls", introspector.calls)
+	}
+}
@@ -0,0 +1,163 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Cache defaults used when VerifierConfig leaves them unset.
+const (
+	DefaultCacheTTL         = time.Minute
+	DefaultNegativeCacheTTL = 5 * time.Second
+	DefaultMaxCacheEntries  = 10000
+)
+
+// VerifierConfig configures a Verifier.
+type VerifierConfig struct {
+	Introspector Introspector
+	Validator    Validator
+	// CacheTTL caps how long an active result is reused; results never
+	// outlive the token's exp plus skew. Negative disables caching.
+	CacheTTL time.Duration
+	// NegativeCacheTTL is how long inactive results are reused, so a client
+	// retrying a revoked token does not hit the endpoint on every request.
+	NegativeCacheTTL time.Duration
+	// MaxCacheEntries bounds memory; the cache is cleared when it is full.
+	MaxCacheEntries int
+}
+
+// Verifier introspects tokens, caches the results and validates the claims
+// on every call, so a cached token still expires on time.
+type Verifier struct {
+	introspector Introspector
+	validator    Validator
+	ttl          time.Duration
+	negativeTTL  time.Duration
+	maxEntries   int
+
+	mu    sync.Mutex
+	cache map[string]cachedClaims
+}
+
+type cachedClaims struct {
+	claims    Claims
+	expiresAt time.Time
+}
+
+// NewVerifier creates a verifier from cfg, applying defaults for unset fields.
+func NewVerifier(cfg VerifierConfig) *Verifier {
+	v := &Verifier{
+		introspector: cfg.Introspector,
+		validator:    cfg.Validator,
+		ttl:          cfg.CacheTTL,
+		negativeTTL:  cfg.NegativeCacheTTL,
+		maxEntries:   cfg.MaxCacheEntries,
+		cache:        make(map[string]cachedClaims),
+	}
+	if v.ttl == 0 {
+		v.ttl = DefaultCacheTTL
+	}
+	if v.negativeTTL == 0 {
+		v.negativeTTL = DefaultNegativeCacheTTL
+	}
+	if v.maxEntries <= 0 {
+		v.maxEntries = DefaultMaxCacheEntries
+	}
+	return v
+}
+
+// Verify returns the token's claims when it is active and passes the
+// validator. Errors wrapping ErrInvalidToken mean the token was rejected;
+// other errors mean introspection failed.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	key := cacheKey(token)
+	now := v.validator.now()
+
+	v.mu.Lock()
+	entry, ok := v.cache[key]
+	if ok && !now.Before(entry.expiresAt) {
+		delete(v.cache, key)
+		ok = false
+	}
+	v.mu.Unlock()
+
+	claims := entry.claims
+	if !ok {
+		var err error
+		claims, err = v.introspector.Introspect(ctx, token)
+		if err != nil {
+			return Claims{}, err
+		}
+		v.store(key, claims, now)
+	}
+
+	if err := v.validator.Validate(claims); err != nil {
+		return Claims{}, err
+	}
+	return claims, nil
+}
+
+// Flush drops every cached result, e.g. after a revocation that must take
+// effect immediately.
+func (v *Verifier) Flush() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	n := len(v.cache)
+	v.cache = make(map[string]cachedClaims)
+	return n
+}
+
+// InvalidateOrgs drops cached results for tokens of the given orgs, e.g.
+// after an org is suspended, and returns how many were dropped. Cached
+// rejections carry no org and are kept.
+func (v *Verifier) InvalidateOrgs(orgIDs map[string]bool) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	n := 0
+	for key, entry := range v.cache {
+		if entry.claims.OrgID != "" && orgIDs[entry.claims.OrgID] {
+			delete(v.cache, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of cached results.
+func (v *Verifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.cache)
+}
+
+func (v *Verifier) store(key string, claims Claims, now time.Time) {
+	ttl := v.ttl
+	if !claims.Active {
+		ttl = v.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	expiresAt := now.Add(ttl)
+	if claims.Active && !claims.ExpiresAt.IsZero() {
+		if limit := claims.ExpiresAt.Add(v.validator.skew()); limit.Before(expiresAt) {
+			expiresAt = limit
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.cache) >= v.maxEntries {
+		v.cache = make(map[string]cachedClaims)
+	}
+	v.cache[key] = cachedClaims{claims: claims, expiresAt: expiresAt}
+}
+
+// cacheKey keeps raw tokens out of memory dumps and map keys.
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
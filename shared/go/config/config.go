@@ -14,6 +14,7 @@ type Config struct {
 	Service   ServiceConfig
 	Telemetry TelemetryConfig
 	Database  DatabaseConfig
+	Policy    PolicyConfig
 }
 
 // ServiceConfig captures generic service settings.
@@ -38,6 +39,21 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 }
 
+// PolicyConfig selects the authorization policy engine and bundle used with
+// the shared auth middleware.
+type PolicyConfig struct {
+	// Engine is json or rego (rego requires building with -tags opa).
+	Engine string
+	// Bundle is a file path, http(s):// URL or oci:// reference.
+	Bundle string
+	// Query is the rego rule deciding access.
+	Query string
+	// PublicKey is a PEM Ed25519 key; when set bundles must be signed.
+	PublicKey string
+	// PollInterval is how often remote bundles are re-fetched.
+	PollInterval time.Duration
+}
+
 // Load reads environment variables and returns a populated Config.
 func Load(ctx context.Context) (Config, error) {
 	_ = ctx // reserved for future use (Vault, remote stores, etc.)
@@ -59,6 +75,13 @@ func Load(ctx context.Context) (Config, error) {
 			MaxOpenConns:    getEnvInt("DATABASE_MAX_OPEN_CONNS", 10),
 			ConnMaxLifetime: getEnvDuration("DATABASE_CONN_MAX_LIFETIME", time.Minute*5),
 		},
+		Policy: PolicyConfig{
+			Engine:       strings.ToLower(getEnv("POLICY_ENGINE", "json")),
+			Bundle:       getEnv("POLICY_BUNDLE", ""),
+			Query:        getEnv("POLICY_REGO_QUERY", "data.authz.allow"),
+			PublicKey:    getEnv("POLICY_PUBLIC_KEY", ""),
+			PollInterval: getEnvDuration("POLICY_POLL_INTERVAL", time.Minute),
+		},
 	}
 
 	if err := validate(cfg); err != nil {
@@ -83,6 +106,9 @@ func validate(cfg Config) error {
 	if cfg.Telemetry.Protocol != "grpc" && cfg.Telemetry.Protocol != "http" {
 		return fmt.Errorf("unsupported OTLP protocol %q", cfg.Telemetry.Protocol)
 	}
+	if cfg.Policy.Engine != "json" && cfg.Policy.Engine != "rego" {
+		return fmt.Errorf("unsupported policy engine %q", cfg.Policy.Engine)
+	}
 	return nil
 }
 
@@ -79,6 +79,26 @@ func TestLoadValidation(t *testing.T) {
 	}
 }
 
+func TestLoadPolicyEngine(t *testing.T) {
+	defer snapshotEnv(t, []string{"SERVICE_NAME", "POLICY_ENGINE", "POLICY_POLL_INTERVAL"})()
+	os.Setenv("SERVICE_NAME", "ok")
+
+	os.Setenv("POLICY_ENGINE", "Rego")
+	os.Setenv("POLICY_POLL_INTERVAL", "30s")
+	cfg, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Policy.Engine != "rego" || cfg.Policy.Query != "data.authz.allow" || cfg.Policy.PollInterval != 30*time.Second {
+		t.Fatalf("unexpected policy config %+v", cfg.Policy)
+	}
+
+	os.Setenv("POLICY_ENGINE", "cedar")
+	if _, err := Load(context.Background()); err == nil {
+		t.Fatalf("expected invalid policy engine error")
+	}
+}
+
 func TestMustLoadPanics(t *testing.T) {
 	defer snapshotEnv(t, []string{"SERVICE_NAME"})()
 	os.Setenv("SERVICE_NAME", "")
@@ -0,0 +1,102 @@
+package pgpool
+
+import (
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statCollector exports pgxpool.Stat for the pool currently registered under
+// its label.
+type statCollector struct {
+	pool atomic.Pointer[pgxpool.Pool]
+
+	maxConns        *prometheus.Desc
+	totalConns      *prometheus.Desc
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	constructing    *prometheus.Desc
+	saturation      *prometheus.Desc
+	acquires        *prometheus.Desc
+	emptyAcquires   *prometheus.Desc
+	canceled        *prometheus.Desc
+	acquireDuration *prometheus.Desc
+}
+
+func newStatCollector(name string, pool *pgxpool.Pool) *statCollector {
+	labels := prometheus.Labels{"pool": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+metric, help, nil, labels)
+	}
+	c := &statCollector{
+		maxConns:        desc("max_connections", "Maximum connections allowed in the pool."),
+		totalConns:      desc("connections", "Connections currently open (idle, acquired and constructing)."),
+		acquiredConns:   desc("acquired_connections", "Connections currently checked out by callers."),
+		idleConns:       desc("idle_connections", "Idle connections available for acquisition."),
+		constructing:    desc("constructing_connections", "Connections currently being established."),
+		saturation:      desc("saturation_ratio", "Acquired connections divided by max connections; sustained values near 1 precede exhaustion."),
+		acquires:        desc("acquires_total", "Successful connection acquisitions."),
+		emptyAcquires:   desc("empty_acquires_total", "Acquisitions that had to wait because no idle connection was available."),
+		canceled:        desc("canceled_acquires_total", "Acquisitions canceled by the caller's context, typically timeouts while the pool was exhausted."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Cumulative time spent waiting to acquire connections."),
+	}
+	c.pool.Store(pool)
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *statCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxConns
+	ch <- c.totalConns
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructing
+	ch <- c.saturation
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.canceled
+	ch <- c.acquireDuration
+}
+
+// Collect implements prometheus.Collector.
+func (c *statCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Load().Stat()
+	saturation := 0.0
+	if s.MaxConns() > 0 {
+		saturation = float64(s.AcquiredConns()) / float64(s.MaxConns())
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.saturation, prometheus.GaugeValue, saturation)
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
+
+// RegisterMetrics exports pool statistics on the default Prometheus registry
+// under the given pool label. Registering a label again points its collector
+// at the new pool, so a pool replaced under the same name (e.g. after a
+// credential reload) is the one reported.
+func RegisterMetrics(name string, pool *pgxpool.Pool) {
+	registerMetrics(prometheus.DefaultRegisterer, name, pool)
+}
+
+func registerMetrics(reg prometheus.Registerer, name string, pool *pgxpool.Pool) {
+	err := reg.Register(newStatCollector(name, pool))
+	if err == nil {
+		return
+	}
+	are, ok := err.(prometheus.AlreadyRegisteredError)
+	if !ok {
+		panic(err)
+	}
+	if existing, ok := are.ExistingCollector.(*statCollector); ok {
+		existing.pool.Store(pool)
+	}
+}
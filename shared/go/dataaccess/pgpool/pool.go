@@ -0,0 +1,105 @@
+// Package pgpool builds pgx connection pools from tunable settings, applies
+// per-query timeouts, and exports pool saturation metrics.
+package pgpool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config holds pool tuning knobs. Zero values keep pgxpool defaults.
+type Config struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementTimeout is enforced server-side via the statement_timeout
+	// runtime parameter on every connection.
+	StatementTimeout time.Duration
+	// QueryTimeout bounds each store call client-side (see WithTimeout).
+	QueryTimeout time.Duration
+	// Credentials, when set, supplies the user and password for each new
+	// connection, overriding the connection string's. Empty values keep the
+	// parsed ones. Pair it with Pool.Reset to rotate credentials without a
+	// restart.
+	Credentials func() (user, password string)
+}
+
+// Apply copies non-zero settings onto a parsed pgxpool config.
+func (c Config) Apply(pc *pgxpool.Config) {
+	if c.MaxConns > 0 {
+		pc.MaxConns = c.MaxConns
+	}
+	if c.MinConns > 0 {
+		pc.MinConns = c.MinConns
+	}
+	if c.MaxConnLifetime > 0 {
+		pc.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	if c.HealthCheckPeriod > 0 {
+		pc.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	if c.StatementTimeout > 0 {
+		if pc.ConnConfig.RuntimeParams == nil {
+			pc.ConnConfig.RuntimeParams = map[string]string{}
+		}
+		pc.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	if c.Credentials != nil {
+		credentials := c.Credentials
+		previous := pc.BeforeConnect
+		pc.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+			user, password := credentials()
+			if user != "" {
+				cc.User = user
+			}
+			if password != "" {
+				cc.Password = password
+			}
+			if previous != nil {
+				return previous(ctx, cc)
+			}
+			return nil
+		}
+	}
+}
+
+// New parses connString, applies cfg and opens a pool. When name is non-empty
+// the pool's statistics are registered as Prometheus metrics under that label.
+func New(ctx context.Context, name, connString string, cfg Config) (*pgxpool.Pool, error) {
+	pc, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("parse pool config: %w", err)
+	}
+	cfg.Apply(pc)
+
+	pool, err := pgxpool.NewWithConfig(ctx, pc)
+	if err != nil {
+		return nil, fmt.Errorf("create pgx pool: %w", err)
+	}
+	if name != "" {
+		RegisterMetrics(name, pool)
+	}
+	return pool, nil
+}
+
+// WithTimeout derives a context bounded by timeout. A zero timeout, or a
+// parent deadline that is already sooner, leaves ctx unchanged.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
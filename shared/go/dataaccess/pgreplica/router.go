@@ -0,0 +1,256 @@
+// Package pgreplica routes read-only queries to Postgres read replicas with
+// lag awareness, falling back to the primary when no replica is fresh enough.
+package pgreplica
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/ai-aas/shared-go/dataaccess/pgpool"
+)
+
+// Defaults applied when Options leaves them unset.
+const (
+	DefaultMaxLag        = 10 * time.Second
+	DefaultCheckInterval = 5 * time.Second
+)
+
+// lagQuery reports replay lag in seconds; 0 when fully caught up or on a primary.
+const lagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8`
+
+var (
+	replicaLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_lag_seconds",
+			Help: "Replication replay lag observed on each read replica.",
+		},
+		[]string{"store", "replica"},
+	)
+	replicaAvailable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_available",
+			Help: "Whether a read replica is reachable and within the lag threshold (1) or not (0).",
+		},
+		[]string{"store", "replica"},
+	)
+	readsRouted = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_read_queries_routed_total",
+			Help: "Read-only queries routed by target (replica or primary).",
+		},
+		[]string{"store", "target"},
+	)
+)
+
+// Options configure a Router.
+type Options struct {
+	// Store labels metrics (e.g. "user-org", "analytics").
+	Store string
+	// MaxLag is the replay lag above which a replica is skipped.
+	MaxLag time.Duration
+	// CheckInterval controls how often replica lag is sampled.
+	CheckInterval time.Duration
+	// Pool tunes replica connection pools; replicas also export pool metrics
+	// labelled "<store>-replica-<n>".
+	Pool   pgpool.Config
+	Logger *zap.Logger
+}
+
+type replica struct {
+	name      string
+	pool      *pgxpool.Pool
+	lag       atomic.Int64 // nanoseconds
+	available atomic.Bool
+}
+
+// Router hands out the primary pool for writes and a fresh replica pool (or the
+// primary) for reads.
+type Router struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	opts     Options
+	next     atomic.Uint64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	checks   sync.WaitGroup
+}
+
+// New connects to each replica DSN and returns a Router. Replicas start as
+// unavailable until the first lag check passes; call Start to begin checks.
+func New(ctx context.Context, primary *pgxpool.Pool, replicaDSNs []string, opts Options) (*Router, error) {
+	if opts.MaxLag <= 0 {
+		opts.MaxLag = DefaultMaxLag
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = DefaultCheckInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.Store == "" {
+		opts.Store = "default"
+	}
+
+	r := &Router{
+		primary: primary,
+		opts:    opts,
+		stop:    make(chan struct{}),
+	}
+	for i, dsn := range replicaDSNs {
+		name := fmt.Sprintf("replica-%d", i)
+		pool, err := pgpool.New(ctx, opts.Store+"-"+name, dsn, opts.Pool)
+		if err != nil {
+			r.closeReplicas()
+			return nil, fmt.Errorf("create replica pool %d: %w", i, err)
+		}
+		r.replicas = append(r.replicas, &replica{name: name, pool: pool})
+	}
+	return r, nil
+}
+
+// Primary returns the primary pool. Use it for writes and read-your-writes.
+func (r *Router) Primary() *pgxpool.Pool {
+	return r.primary
+}
+
+// Reader returns a replica pool whose lag is within MaxLag, rotating between
+// eligible replicas, or the primary when none qualifies.
+func (r *Router) Reader() *pgxpool.Pool {
+	if r == nil {
+		return nil
+	}
+	n := len(r.replicas)
+	if n > 0 {
+		start := r.next.Add(1)
+		for i := 0; i < n; i++ {
+			rep := r.replicas[(start+uint64(i))%uint64(n)]
+			if rep.available.Load() {
+				readsRouted.WithLabelValues(r.opts.Store, "replica").Inc()
+				return rep.pool
+			}
+		}
+	}
+	readsRouted.WithLabelValues(r.opts.Store, "primary").Inc()
+	return r.primary
+}
+
+// Start samples replica lag immediately and then every CheckInterval until
+// Close is called or ctx is cancelled.
+func (r *Router) Start(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+	r.checkAll(ctx)
+	r.checks.Add(1)
+	go func() {
+		defer r.checks.Done()
+		ticker := time.NewTicker(r.opts.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.checkAll(ctx)
+			}
+		}
+	}()
+}
+
+// Lag returns the last observed lag per replica name.
+func (r *Router) Lag() map[string]time.Duration {
+	out := make(map[string]time.Duration, len(r.replicas))
+	for _, rep := range r.replicas {
+		out[rep.name] = time.Duration(rep.lag.Load())
+	}
+	return out
+}
+
+// Close stops lag checks, waits for a running check to finish and closes
+// replica pools. The primary pool is owned by the caller and left open.
+func (r *Router) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		r.checks.Wait()
+		r.closeReplicas()
+	})
+}
+
+// Reset closes idle replica connections and retires busy ones once released,
+// so new connections pick up rotated credentials. The primary pool is owned
+// by the caller and left untouched.
+func (r *Router) Reset() {
+	if r == nil {
+		return
+	}
+	for _, rep := range r.replicas {
+		rep.pool.Reset()
+	}
+}
+
+func (r *Router) closeReplicas() {
+	for _, rep := range r.replicas {
+		rep.available.Store(false)
+		rep.pool.Close()
+	}
+}
+
+func (r *Router) checkAll(ctx context.Context) {
+	for _, rep := range r.replicas {
+		r.check(ctx, rep)
+	}
+}
+
+func (r *Router) check(ctx context.Context, rep *replica) {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.CheckInterval)
+	defer cancel()
+
+	var seconds float64
+	if err := rep.pool.QueryRow(ctx, lagQuery).Scan(&seconds); err != nil {
+		if rep.available.Swap(false) {
+			r.opts.Logger.Warn("read replica unavailable, routing reads to primary",
+				zap.String("store", r.opts.Store), zap.String("replica", rep.name), zap.Error(err))
+		}
+		replicaAvailable.WithLabelValues(r.opts.Store, rep.name).Set(0)
+		return
+	}
+	r.observe(rep, time.Duration(seconds*float64(time.Second)))
+}
+
+func (r *Router) observe(rep *replica, lag time.Duration) {
+	rep.lag.Store(int64(lag))
+	replicaLag.WithLabelValues(r.opts.Store, rep.name).Set(lag.Seconds())
+
+	fresh := lag <= r.opts.MaxLag
+	if was := rep.available.Swap(fresh); was != fresh {
+		if fresh {
+			r.opts.Logger.Info("read replica within lag threshold",
+				zap.String("store", r.opts.Store), zap.String("replica", rep.name), zap.Duration("lag", lag))
+		} else {
+			r.opts.Logger.Warn("read replica lag exceeds threshold, routing reads to primary",
+				zap.String("store", r.opts.Store), zap.String("replica", rep.name),
+				zap.Duration("lag", lag), zap.Duration("max_lag", r.opts.MaxLag))
+		}
+	}
+	if fresh {
+		replicaAvailable.WithLabelValues(r.opts.Store, rep.name).Set(1)
+	} else {
+		replicaAvailable.WithLabelValues(r.opts.Store, rep.name).Set(0)
+	}
+}
@@ -0,0 +1,95 @@
+package pgreplica
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func newTestRouter(t *testing.T, replicas int) (*Router, *pgxpool.Pool) {
+	t.Helper()
+	// pgxpool connects lazily, so no server is needed for routing tests.
+	primary, err := pgxpool.New(context.Background(), "postgres://primary.invalid/db")
+	if err != nil {
+		t.Fatalf("primary pool: %v", err)
+	}
+	t.Cleanup(primary.Close)
+
+	dsns := make([]string, replicas)
+	for i := range dsns {
+		dsns[i] = "postgres://replica.invalid/db"
+	}
+	r, err := New(context.Background(), primary, dsns, Options{Store: "test", MaxLag: time.Second})
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	t.Cleanup(r.Close)
+	return r, primary
+}
+
+func TestReaderFallsBackToPrimaryWithoutReplicas(t *testing.T) {
+	r, primary := newTestRouter(t, 0)
+	if r.Reader() != primary {
+		t.Fatal("expected primary when no replicas are configured")
+	}
+}
+
+func TestReaderHonoursLagThreshold(t *testing.T) {
+	r, primary := newTestRouter(t, 2)
+
+	if r.Reader() != primary {
+		t.Fatal("expected primary before any lag check succeeds")
+	}
+
+	r.observe(r.replicas[0], 200*time.Millisecond)
+	r.observe(r.replicas[1], 5*time.Second)
+	for i := 0; i < 4; i++ {
+		if got := r.Reader(); got != r.replicas[0].pool {
+			t.Fatalf("expected only the fresh replica, got %p", got)
+		}
+	}
+
+	r.observe(r.replicas[0], 2*time.Second)
+	if r.Reader() != primary {
+		t.Fatal("expected primary when every replica exceeds the lag threshold")
+	}
+	if lag := r.Lag()["replica-1"]; lag != 5*time.Second {
+		t.Fatalf("unexpected lag for replica-1: %s", lag)
+	}
+}
+
+func TestReaderRotatesBetweenFreshReplicas(t *testing.T) {
+	r, _ := newTestRouter(t, 2)
+	r.observe(r.replicas[0], 0)
+	r.observe(r.replicas[1], 0)
+
+	seen := map[*pgxpool.Pool]bool{}
+	for i := 0; i < 4; i++ {
+		seen[r.Reader()] = true
+	}
+	if len(seen) != 2 {
+		t.Fatalf("expected reads spread over both replicas, got %d", len(seen))
+	}
+}
+
+func TestCloseStopsLagChecks(t *testing.T) {
+	r, primary := newTestRouter(t, 1)
+	r.opts.CheckInterval = time.Millisecond
+	r.Start(context.Background())
+
+	closed := make(chan struct{})
+	go func() {
+		r.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after stopping lag checks")
+	}
+	if r.Reader() != primary {
+		t.Fatal("expected primary after Close")
+	}
+}
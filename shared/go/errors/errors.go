@@ -14,13 +14,14 @@ type Actor struct {
 
 // Error represents the standardized error schema shared across services.
 type Error struct {
-	Message   string    `json:"error"`
-	Code      string    `json:"code"`
-	Detail    string    `json:"detail,omitempty"`
-	RequestID string    `json:"request_id,omitempty"`
-	TraceID   string    `json:"trace_id,omitempty"`
-	Actor     *Actor    `json:"actor,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	Message       string    `json:"error"`
+	Code          string    `json:"code"`
+	Detail        string    `json:"detail,omitempty"`
+	RequestID     string    `json:"request_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	TraceID       string    `json:"trace_id,omitempty"`
+	Actor         *Actor    `json:"actor,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
 }
 
 // Option mutates an Error during construction.
@@ -58,6 +59,13 @@ func WithRequestID(id string) Option {
 	}
 }
 
+// WithCorrelationID attaches a correlation ID.
+func WithCorrelationID(id string) Option {
+	return func(e *Error) {
+		e.CorrelationID = id
+	}
+}
+
 // WithTraceID attaches a trace ID.
 func WithTraceID(id string) Option {
 	return func(e *Error) {
@@ -11,6 +11,7 @@ func TestNewAndOptions(t *testing.T) {
 	err := New("EXAMPLE", "example failure",
 		WithDetail("detail"),
 		WithRequestID("req-123"),
+		WithCorrelationID("corr-789"),
 		WithTraceID("trace-456"),
 		WithActor(&Actor{Subject: "user-1", Roles: []string{"admin"}}),
 		WithTimestamp(ts),
@@ -19,7 +20,7 @@ func TestNewAndOptions(t *testing.T) {
 	if err.Code != "EXAMPLE" || err.Message != "example failure" {
 		t.Fatalf("unexpected code or message: %+v", err)
 	}
-	if err.Detail != "detail" || err.RequestID != "req-123" || err.TraceID != "trace-456" {
+	if err.Detail != "detail" || err.RequestID != "req-123" || err.TraceID != "trace-456" || err.CorrelationID != "corr-789" {
 		t.Fatalf("unexpected metadata: %+v", err)
 	}
 	if err.Actor == nil || err.Actor.Subject != "user-1" {
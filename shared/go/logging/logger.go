@@ -9,6 +9,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 // Logger wraps zap.Logger with standardized configuration and OpenTelemetry integration.
@@ -78,17 +80,18 @@ func MustNew(cfg Config) *Logger {
 	return logger
 }
 
-// WithContext returns a logger with OpenTelemetry trace context fields.
+// WithContext returns a logger with OpenTelemetry trace context and
+// request/correlation ID fields.
 func (l *Logger) WithContext(ctx context.Context) *zap.Logger {
-	span := trace.SpanFromContext(ctx)
-	if !span.SpanContext().IsValid() {
-		return l.Logger
+	fields := requestid.Fields(ctx)
+	if spanCtx := trace.SpanFromContext(ctx).SpanContext(); spanCtx.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		)
 	}
-
-	spanCtx := span.SpanContext()
-	fields := []zap.Field{
-		zap.String("trace_id", spanCtx.TraceID().String()),
-		zap.String("span_id", spanCtx.SpanID().String()),
+	if len(fields) == 0 {
+		return l.Logger
 	}
 
 	return l.Logger.With(fields...)
@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/ai-aas/shared-go/requestid"
 )
 
 type ctxKey string
@@ -21,21 +21,13 @@ func RequestIDFromContext(ctx context.Context) (string, bool) {
 	return id, ok
 }
 
-// RequestContextMiddleware injects request IDs and timing metadata.
+// RequestContextMiddleware injects request and correlation IDs (see
+// requestid.Middleware) and timing metadata.
 func RequestContextMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.NewString()
-		}
-
-		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+	return requestid.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestIDKey, requestid.RequestID(r.Context()))
 		ctx = context.WithValue(ctx, startTimeKey, time.Now())
 
-		r = r.WithContext(ctx)
-		r.Header.Set("X-Request-ID", requestID)
-		w.Header().Set("X-Request-ID", requestID)
-
-		next.ServeHTTP(w, r)
-	})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}))
 }
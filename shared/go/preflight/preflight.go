@@ -0,0 +1,202 @@
+// Package preflight validates a service's configuration and the reachability
+// of its declared dependencies without starting it. Services expose it as a
+// --validate-config flag for CI and deploy preflight checks: dependency checks
+// run in parallel with a per-check timeout and the result is a JSON report
+// plus a non-zero exit code on any failure.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultTimeout bounds each dependency check when Run is given no timeout.
+const DefaultTimeout = 5 * time.Second
+
+// Check probes one declared dependency.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of a single Check.
+type Result struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+// Report is the structured outcome of a preflight run.
+type Report struct {
+	Service      string   `json:"service"`
+	OK           bool     `json:"ok"`
+	ConfigErrors []string `json:"config_errors,omitempty"`
+	Dependencies []Result `json:"dependencies"`
+}
+
+// Run records configErr (errors.Join results are listed individually) and
+// executes checks in parallel, each bounded by timeout.
+func Run(ctx context.Context, service string, configErr error, checks []Check, timeout time.Duration) Report {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	report := Report{Service: service, Dependencies: make([]Result, len(checks))}
+	report.ConfigErrors = flatten(configErr)
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runCheck(checkCtx, check)
+			result := Result{Name: check.Name, OK: err == nil, Latency: time.Since(start).Round(time.Millisecond).String()}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report.Dependencies[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	sort.SliceStable(report.Dependencies, func(i, j int) bool {
+		return report.Dependencies[i].Name < report.Dependencies[j].Name
+	})
+	report.OK = len(report.ConfigErrors) == 0
+	for _, result := range report.Dependencies {
+		report.OK = report.OK && result.OK
+	}
+	return report
+}
+
+// runCheck runs check, giving up when ctx expires even if the check ignores it.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() { done <- check.Run(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
+
+func flatten(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var messages []string
+		for _, inner := range joined.Unwrap() {
+			messages = append(messages, flatten(inner)...)
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
+
+// ExitCode returns 0 when everything passed and 1 otherwise.
+func (r Report) ExitCode() int {
+	if r.OK {
+		return 0
+	}
+	return 1
+}
+
+// Write encodes the report as indented JSON.
+func (r Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// TCP returns a check function that dials addr (host:port).
+func TCP(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// defaultPorts are used by URLHost when a URL has no explicit port.
+var defaultPorts = map[string]string{
+	"http":   "80",
+	"https":  "443",
+	"amqp":   "5672",
+	"amqps":  "5671",
+	"redis":  "6379",
+	"rediss": "6379",
+}
+
+// URLHost returns a check function that dials the host of rawURL, using the
+// scheme's default port when the URL has none.
+func URLHost(rawURL string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("parse URL: %w", err)
+		}
+		if u.Hostname() == "" {
+			return errors.New("URL has no host")
+		}
+		port := u.Port()
+		if port == "" {
+			if port = defaultPorts[u.Scheme]; port == "" {
+				return fmt.Errorf("URL has no port and scheme %q has no default", u.Scheme)
+			}
+		}
+		return TCP(net.JoinHostPort(u.Hostname(), port))(ctx)
+	}
+}
+
+// HTTP returns a check function that sends GET rawURL and fails on transport
+// errors or 5xx responses.
+func HTTP(rawURL string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Postgres returns a check function that connects to dsn and pings it, which
+// also validates credentials and the database name.
+func Postgres(dsn string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		conn, err := pgx.Connect(ctx, dsn)
+		if err != nil {
+			return err
+		}
+		defer conn.Close(context.Background())
+		return conn.Ping(ctx)
+	}
+}
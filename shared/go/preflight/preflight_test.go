@@ -0,0 +1,92 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunReportsConfigErrorsAndChecks(t *testing.T) {
+	configErr := errors.Join(errors.New("HTTP_PORT out of range"), errors.New("DATABASE_URL is required"))
+	checks := []Check{
+		{Name: "redis", Run: func(context.Context) error { return nil }},
+		{Name: "kafka", Run: func(context.Context) error { return errors.New("connection refused") }},
+	}
+
+	report := Run(context.Background(), "svc", configErr, checks, time.Second)
+	if report.OK || report.ExitCode() != 1 {
+		t.Fatalf("expected failing report, got %+v", report)
+	}
+	if len(report.ConfigErrors) != 2 {
+		t.Fatalf("expected joined config errors listed individually, got %v", report.ConfigErrors)
+	}
+	if report.Dependencies[0].Name != "kafka" || report.Dependencies[0].OK || report.Dependencies[1].Name != "redis" || !report.Dependencies[1].OK {
+		t.Fatalf("unexpected dependency results %+v", report.Dependencies)
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Service != "svc" {
+		t.Fatalf("expected JSON report, got %s (%v)", buf.String(), err)
+	}
+}
+
+func TestRunTimesOutChecksInParallel(t *testing.T) {
+	hang := func(context.Context) error { select {} }
+	start := time.Now()
+	report := Run(context.Background(), "svc", nil, []Check{{Name: "a", Run: hang}, {Name: "b", Run: hang}}, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected checks to run in parallel with timeouts, took %v", elapsed)
+	}
+	if report.OK || report.Dependencies[0].OK || report.Dependencies[1].OK {
+		t.Fatalf("expected timed-out checks to fail, got %+v", report)
+	}
+}
+
+func TestRunPassesWithoutProblems(t *testing.T) {
+	if report := Run(context.Background(), "svc", nil, nil, 0); !report.OK || report.ExitCode() != 0 {
+		t.Fatalf("expected passing report, got %+v", report)
+	}
+}
+
+func TestNetworkChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if err := HTTP(srv.URL)(ctx); err != nil {
+		t.Fatalf("expected HTTP check to pass: %v", err)
+	}
+	if err := HTTP(srv.URL + "/broken")(ctx); err == nil {
+		t.Fatal("expected HTTP check to fail on 5xx")
+	}
+	if err := URLHost(srv.URL)(ctx); err != nil {
+		t.Fatalf("expected URL host check to pass: %v", err)
+	}
+	if err := URLHost("ftp://files.internal")(ctx); err == nil {
+		t.Fatal("expected URL without a known default port to fail")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	if err := TCP(addr)(ctx); err == nil {
+		t.Fatal("expected TCP check to fail against a closed port")
+	}
+}
@@ -0,0 +1,180 @@
+// Package requestid generates and propagates request and correlation IDs.
+//
+// The request ID identifies a single hop (one inbound HTTP request); the
+// correlation ID is shared by every hop that serves the same end-user action
+// and defaults to the request ID of the first service to see it. Middleware
+// attaches both to the request context, and the helpers here carry them into
+// zap loggers, outgoing HTTP requests and Kafka message headers.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Header names used on inbound and outbound requests.
+const (
+	RequestIDHeader     = "X-Request-ID"
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// Kafka message header keys, following the snake_case keys already used on
+// usage records.
+const (
+	RequestIDKey     = "request_id"
+	CorrelationIDKey = "correlation_id"
+)
+
+// maxIDLength bounds caller-supplied IDs so they cannot bloat logs.
+const maxIDLength = 128
+
+// IDs holds the identifiers attached to a request.
+type IDs struct {
+	RequestID     string
+	CorrelationID string
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying ids.
+func NewContext(ctx context.Context, ids IDs) context.Context {
+	return context.WithValue(ctx, contextKey{}, ids)
+}
+
+// FromContext returns the IDs attached by Middleware or NewContext.
+func FromContext(ctx context.Context) (IDs, bool) {
+	ids, ok := ctx.Value(contextKey{}).(IDs)
+	return ids, ok
+}
+
+// RequestID returns the request ID in ctx, or "" if none.
+func RequestID(ctx context.Context) string {
+	ids, _ := FromContext(ctx)
+	return ids.RequestID
+}
+
+// CorrelationID returns the correlation ID in ctx, or "" if none.
+func CorrelationID(ctx context.Context) string {
+	ids, _ := FromContext(ctx)
+	return ids.CorrelationID
+}
+
+// New generates a request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Middleware accepts well-formed X-Request-ID and X-Correlation-ID headers
+// from the caller, generating a request ID when it is missing or invalid and
+// defaulting the correlation ID to the request ID. Both are stored in the
+// request context and echoed on the request and response headers.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := IDs{
+			RequestID:     r.Header.Get(RequestIDHeader),
+			CorrelationID: r.Header.Get(CorrelationIDHeader),
+		}
+		if !valid(ids.RequestID) {
+			ids.RequestID = New()
+		}
+		if !valid(ids.CorrelationID) {
+			ids.CorrelationID = ids.RequestID
+		}
+
+		r.Header.Set(RequestIDHeader, ids.RequestID)
+		r.Header.Set(CorrelationIDHeader, ids.CorrelationID)
+		w.Header().Set(RequestIDHeader, ids.RequestID)
+		w.Header().Set(CorrelationIDHeader, ids.CorrelationID)
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), ids)))
+	})
+}
+
+// valid reports whether a caller-supplied ID is safe to log and forward:
+// non-empty, bounded and limited to printable ASCII without spaces.
+func valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Fields returns zap fields for the IDs in ctx.
+func Fields(ctx context.Context) []zap.Field {
+	ids, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("request_id", ids.RequestID),
+		zap.String("correlation_id", ids.CorrelationID),
+	}
+}
+
+// Logger returns base annotated with the IDs in ctx.
+func Logger(ctx context.Context, base *zap.Logger) *zap.Logger {
+	if fields := Fields(ctx); len(fields) > 0 {
+		return base.With(fields...)
+	}
+	return base
+}
+
+// Inject sets the correlation headers on an outgoing request. The request ID
+// is forwarded too so the callee can log its caller's hop.
+func Inject(ctx context.Context, header http.Header) {
+	ids, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	if ids.RequestID != "" {
+		header.Set(RequestIDHeader, ids.RequestID)
+	}
+	if ids.CorrelationID != "" {
+		header.Set(CorrelationIDHeader, ids.CorrelationID)
+	}
+}
+
+// Transport is an http.RoundTripper that injects the IDs from each request's
+// context before delegating to Base (http.DefaultTransport when nil).
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := FromContext(req.Context()); ok {
+		// RoundTrippers must not modify the caller's request.
+		req = req.Clone(req.Context())
+		Inject(req.Context(), req.Header)
+	}
+	return base.RoundTrip(req)
+}
+
+// Headers returns the IDs in ctx as message headers (e.g. for Kafka), keyed
+// by RequestIDKey and CorrelationIDKey.
+func Headers(ctx context.Context) map[string]string {
+	ids, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	headers := map[string]string{}
+	if ids.RequestID != "" {
+		headers[RequestIDKey] = ids.RequestID
+	}
+	if ids.CorrelationID != "" {
+		headers[CorrelationIDKey] = ids.CorrelationID
+	}
+	return headers
+}
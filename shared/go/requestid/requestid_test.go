@@ -0,0 +1,93 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMiddlewareGeneratesIDs(t *testing.T) {
+	var got IDs
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got.RequestID == "" || got.CorrelationID != got.RequestID {
+		t.Fatalf("expected generated request ID reused as correlation ID, got %+v", got)
+	}
+	if rr.Header().Get(RequestIDHeader) != got.RequestID || rr.Header().Get(CorrelationIDHeader) != got.CorrelationID {
+		t.Fatalf("expected IDs echoed on response, got %v", rr.Header())
+	}
+}
+
+func TestMiddlewarePropagatesValidIDs(t *testing.T) {
+	var got IDs
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.Header.Set(CorrelationIDHeader, "corr-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.RequestID != "req-1" || got.CorrelationID != "corr-1" {
+		t.Fatalf("expected caller IDs, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	req.Header.Set(CorrelationIDHeader, strings.Repeat("x", maxIDLength+1))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.RequestID == "bad id\n" || got.CorrelationID != got.RequestID {
+		t.Fatalf("expected invalid IDs to be replaced, got %+v", got)
+	}
+}
+
+func TestTransportInjectsHeaders(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	ctx := NewContext(context.Background(), IDs{RequestID: "req-1", CorrelationID: "corr-1"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := (&http.Client{Transport: &Transport{}}).Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+
+	if header.Get(RequestIDHeader) != "req-1" || header.Get(CorrelationIDHeader) != "corr-1" {
+		t.Fatalf("expected propagated headers, got %v", header)
+	}
+	if req.Header.Get(CorrelationIDHeader) != "" {
+		t.Fatal("transport must not modify the caller's request")
+	}
+}
+
+func TestLoggerAndHeaders(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := NewContext(context.Background(), IDs{RequestID: "req-1", CorrelationID: "corr-1"})
+	Logger(ctx, zap.New(core)).Info("hello")
+
+	fields := logs.All()[0].ContextMap()
+	if fields["request_id"] != "req-1" || fields["correlation_id"] != "corr-1" {
+		t.Fatalf("unexpected log fields %v", fields)
+	}
+
+	headers := Headers(ctx)
+	if headers[RequestIDKey] != "req-1" || headers[CorrelationIDKey] != "corr-1" {
+		t.Fatalf("unexpected headers %v", headers)
+	}
+	if Headers(context.Background()) != nil {
+		t.Fatal("expected no headers without IDs")
+	}
+}
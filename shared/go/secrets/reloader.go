@@ -0,0 +1,204 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// debounce coalesces the burst of file events a secret volume update produces.
+const debounce = 200 * time.Millisecond
+
+var reloads = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "credentials_reloads_total",
+		Help: "Credential reload attempts by outcome (changed, unchanged or failed).",
+	},
+	[]string{"outcome"},
+)
+
+type subscription struct {
+	keys []string
+	fn   func(ctx context.Context)
+}
+
+// Reloader holds the latest credentials from a Source and notifies
+// subscribers when they change. A nil *Reloader is valid and holds nothing,
+// so callers can use Lookup unconditionally.
+type Reloader struct {
+	source Source
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	values map[string]string
+	subs   []subscription
+
+	reloadMu sync.Mutex
+}
+
+// NewReloader loads the initial credentials from source.
+func NewReloader(ctx context.Context, source Source, logger *zap.Logger) (*Reloader, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	values, err := source.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load credentials: %w", err)
+	}
+	return &Reloader{source: source, logger: logger, values: values}, nil
+}
+
+// Lookup returns the current value of key, or fallback when the source does
+// not define it.
+func (r *Reloader) Lookup(key, fallback string) string {
+	if r == nil {
+		return fallback
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if value, ok := r.values[key]; ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// OnChange registers fn to run after a reload that changes any of keys.
+// Callbacks run sequentially on the reloading goroutine.
+func (r *Reloader) OnChange(keys []string, fn func(ctx context.Context)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, subscription{keys: keys, fn: fn})
+}
+
+// Reload loads credentials from the source and notifies subscribers of the
+// keys that changed. On error the previous credentials stay in effect.
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	values, err := r.source.Load(ctx)
+	if err != nil {
+		reloads.WithLabelValues("failed").Inc()
+		return fmt.Errorf("load credentials: %w", err)
+	}
+
+	r.mu.Lock()
+	changed := changedKeys(r.values, values)
+	r.values = values
+	subs := append([]subscription(nil), r.subs...)
+	r.mu.Unlock()
+
+	if len(changed) == 0 {
+		reloads.WithLabelValues("unchanged").Inc()
+		return nil
+	}
+	reloads.WithLabelValues("changed").Inc()
+	r.logger.Info("credentials rotated", zap.Strings("keys", changed)) // Never log values
+
+	for _, sub := range subs {
+		if overlaps(sub.keys, changed) {
+			sub.fn(ctx)
+		}
+	}
+	return nil
+}
+
+// Watch reloads on SIGHUP, whenever a FileSource's directory changes, and
+// every interval (when positive, e.g. to follow Vault rotations). It blocks
+// until ctx is cancelled.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if r == nil {
+		return
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	if fileSource, ok := r.source.(FileSource); ok {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			err = watcher.Add(fileSource.Dir)
+		}
+		if err != nil {
+			r.logger.Warn("credential file watch unavailable, relying on SIGHUP and polling", zap.Error(err))
+		} else {
+			defer watcher.Close()
+			events, watchErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	reload := func(trigger string) {
+		if err := r.Reload(ctx); err != nil {
+			r.logger.Error("credential reload failed, keeping previous credentials",
+				zap.String("trigger", trigger), zap.Error(err))
+		}
+	}
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("sighup")
+		case <-tick:
+			reload("interval")
+		case <-events:
+			settle = time.After(debounce)
+		case <-settle:
+			settle = nil
+			reload("file")
+		case err := <-watchErrors:
+			r.logger.Warn("credential file watch error", zap.Error(err))
+		}
+	}
+}
+
+// changedKeys lists keys added, removed or modified between old and new, sorted.
+func changedKeys(old, new map[string]string) []string {
+	var changed []string
+	for key, value := range new {
+		if previous, ok := old[key]; !ok || previous != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func overlaps(keys, changed []string) bool {
+	for _, key := range keys {
+		for _, c := range changed {
+			if key == c {
+				return true
+			}
+		}
+	}
+	return false
+}
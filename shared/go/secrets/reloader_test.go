@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCredential(t *testing.T, dir, key, value string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, key), []byte(value+"\n"), 0o600); err != nil {
+		t.Fatalf("write %s: %v", key, err)
+	}
+}
+
+func TestFileSourceReloadNotifiesChangedKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeCredential(t, dir, RedisPassword, "old")
+	writeCredential(t, dir, DatabasePassword, "db")
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReloader(context.Background(), FileSource{Dir: dir}, nil)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	if got := r.Lookup(RedisPassword, "env"); got != "old" {
+		t.Fatalf("expected trimmed file value, got %q", got)
+	}
+	if got := r.Lookup(KafkaPassword, "env"); got != "env" {
+		t.Fatalf("expected fallback for missing key, got %q", got)
+	}
+
+	var redisCalls, dbCalls int
+	r.OnChange([]string{RedisPassword, RedisUsername}, func(context.Context) { redisCalls++ })
+	r.OnChange([]string{DatabasePassword}, func(context.Context) { dbCalls++ })
+
+	writeCredential(t, dir, RedisPassword, "new")
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if redisCalls != 1 || dbCalls != 0 || r.Lookup(RedisPassword, "") != "new" {
+		t.Fatalf("expected only the redis subscriber, got redis=%d db=%d", redisCalls, dbCalls)
+	}
+
+	if err := r.Reload(context.Background()); err != nil || redisCalls != 1 {
+		t.Fatalf("expected no notification without changes, got %d (%v)", redisCalls, err)
+	}
+}
+
+func TestReloadKeepsCredentialsOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeCredential(t, dir, RedisPassword, "secret")
+	r, err := NewReloader(context.Background(), FileSource{Dir: dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.source = FileSource{Dir: filepath.Join(dir, "missing")}
+	if err := r.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if got := r.Lookup(RedisPassword, ""); got != "secret" {
+		t.Fatalf("expected previous credentials to stay, got %q", got)
+	}
+}
+
+func TestVaultSourceReadsKVv2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/router" || r.Header.Get("X-Vault-Token") != "tok" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"redis-password":"p","port":6379},"metadata":{"version":3}}}`))
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	values, err := VaultSource{Addr: srv.URL, Path: "secret/data/router", TokenFile: tokenFile}.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if values[RedisPassword] != "p" || values["port"] != "6379" {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	if _, err := (VaultSource{Addr: srv.URL, Path: "secret/data/other", Token: "tok"}).Load(context.Background()); err == nil {
+		t.Fatal("expected error for forbidden path")
+	}
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	writeCredential(t, dir, KafkaPassword, "old")
+	r, err := NewReloader(context.Background(), FileSource{Dir: dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 1)
+	r.OnChange([]string{KafkaPassword}, func(context.Context) { changed <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 0)
+	time.Sleep(50 * time.Millisecond) // Let the watcher register
+
+	writeCredential(t, dir, KafkaPassword, "new")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected reload after the file changed")
+	}
+}
+
+func TestNilReloader(t *testing.T) {
+	var r *Reloader
+	if got := r.Lookup(RedisPassword, "env"); got != "env" {
+		t.Fatalf("expected fallback, got %q", got)
+	}
+	r.OnChange([]string{RedisPassword}, func(context.Context) {})
+	r.Watch(context.Background(), 0)
+}
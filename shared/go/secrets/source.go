@@ -0,0 +1,163 @@
+// Package secrets reloads service-internal credentials (database, Redis and
+// Kafka usernames and passwords) from mounted files or Vault, so they can be
+// rotated without restarting the service.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Well-known credential keys. A FileSource reads each from a file of the same
+// name; a VaultSource reads them from the secret's data.
+const (
+	DatabaseUser     = "database-user"
+	DatabasePassword = "database-password"
+	RedisUsername    = "redis-username"
+	RedisPassword    = "redis-password"
+	KafkaUsername    = "kafka-username"
+	KafkaPassword    = "kafka-password"
+)
+
+// Source loads the current set of credentials.
+type Source interface {
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// Config selects a Source from service settings.
+type Config struct {
+	// Dir is a directory of credential files (CREDENTIALS_DIR).
+	Dir string
+	// VaultAddr, VaultPath, VaultToken and VaultTokenFile configure a VaultSource.
+	VaultAddr      string
+	VaultPath      string
+	VaultToken     string
+	VaultTokenFile string
+}
+
+// Source returns the configured Source, preferring Dir over Vault, or nil
+// when neither is set and credentials come only from the environment.
+func (c Config) Source() Source {
+	switch {
+	case c.Dir != "":
+		return FileSource{Dir: c.Dir}
+	case c.VaultAddr != "":
+		return VaultSource{Addr: c.VaultAddr, Path: c.VaultPath, Token: c.VaultToken, TokenFile: c.VaultTokenFile}
+	default:
+		return nil
+	}
+}
+
+// FileSource reads one credential per file from Dir, as written by a
+// Kubernetes secret volume or the Vault Agent injector. File names are the
+// keys; surrounding whitespace is trimmed from the contents.
+type FileSource struct {
+	Dir string
+}
+
+// Load implements Source. Hidden entries (such as Kubernetes' ..data
+// symlinks) and subdirectories are skipped.
+func (s FileSource) Load(_ context.Context) (map[string]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read credentials dir: %w", err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.Dir, entry.Name())
+		info, err := os.Stat(path) // Follows the symlinks secret volumes use
+		if err != nil {
+			return nil, fmt.Errorf("stat credential %s: %w", entry.Name(), err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read credential %s: %w", entry.Name(), err)
+		}
+		values[entry.Name()] = strings.TrimSpace(string(data))
+	}
+	return values, nil
+}
+
+// VaultSource reads credentials from a Vault KV secret (v1 or v2) over the
+// HTTP API.
+type VaultSource struct {
+	// Addr is the Vault address, e.g. https://vault.internal:8200.
+	Addr string
+	// Path is the secret's API path below /v1/, e.g. secret/data/api-router.
+	Path string
+	// Token authenticates requests. When empty, TokenFile is read on every
+	// load so a token renewed by Vault Agent is picked up.
+	Token     string
+	TokenFile string
+	// Client defaults to an http.Client with a 10s timeout.
+	Client *http.Client
+}
+
+// Load implements Source.
+func (s VaultSource) Load(ctx context.Context) (map[string]string, error) {
+	token := s.Token
+	if token == "" && s.TokenFile != "" {
+		data, err := os.ReadFile(s.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read vault token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	url := strings.TrimRight(s.Addr, "/") + "/v1/" + strings.TrimLeft(s.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build vault request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode vault secret: %w", err)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = inner // KV v2 nests the values under data.data
+		}
+	}
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if str, ok := value.(string); ok {
+			values[key] = str
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS describes which browser origins may call a service directly and
+// what they may send and read.
+//
+// AllowedOrigins entries are exact origins ("https://app.example.com"), "*"
+// for any origin, or patterns with a single "*" such as
+// "https://*.example.com" or "http://localhost:*".
+type CORS struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are response headers scripts may read, e.g. rate limit headers.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies. The matched origin is
+	// always echoed, never "*", so Validate rejects origins matching any host.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response; zero omits it.
+	MaxAge time.Duration
+}
+
+// Default CORS request methods and headers.
+var (
+	DefaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	DefaultCORSHeaders = []string{"Content-Type", "Authorization", "X-CSRF-Token", "X-Correlation-ID", "X-API-Key"}
+)
+
+// ParseOrigins splits a comma-separated origin list, dropping empty entries
+// and trailing slashes.
+func ParseOrigins(raw string) []string {
+	var origins []string
+	for _, origin := range strings.Split(raw, ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// MatchOrigin reports whether origin matches any of the patterns.
+func MatchOrigin(patterns []string, origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if wildcard && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate rejects AllowedOrigins entries that match any host, such as "*"
+// or "https://*", when AllowCredentials is set: echoing every origin with
+// credentials would let any site make authenticated requests.
+func (c CORS) Validate() error {
+	if !c.AllowCredentials {
+		return nil
+	}
+	for _, pattern := range c.AllowedOrigins {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if wildcard && (prefix == "" || strings.HasSuffix(prefix, "://")) && !strings.HasPrefix(suffix, ".") {
+			return fmt.Errorf("origin %q matches any host and cannot be allowed with credentials", pattern)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether requests from origin may be served CORS headers.
+func (c CORS) Allows(origin string) bool {
+	return MatchOrigin(c.AllowedOrigins, origin)
+}
+
+// IsPreflight reports whether r is a CORS preflight request.
+func IsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != ""
+}
+
+// SetHeaders adds CORS response headers for r when its origin is allowed,
+// including the preflight headers for OPTIONS requests. It reports whether
+// the origin was allowed.
+func (c CORS) SetHeaders(w http.ResponseWriter, r *http.Request) bool {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if !c.Allows(origin) {
+		return false
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if r.Method != http.MethodOptions {
+		if len(c.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+		return true
+	}
+
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	headers := c.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	if c.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+	}
+	return true
+}
+
+// Middleware adds CORS headers to every response and answers preflight
+// requests with 204 before they reach routing or authentication.
+func (c CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.SetHeaders(w, r)
+		if IsPreflight(r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
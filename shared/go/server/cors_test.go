@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	patterns := ParseOrigins("https://app.example.com/, https://*.customer.io,http://localhost:*")
+	cases := map[string]bool{
+		"https://app.example.com":      true,
+		"https://eu.customer.io":       true,
+		"https://customer.io":          false,
+		"http://localhost:5173":        true,
+		"https://localhost:5173":       false,
+		"https://evil.com":             false,
+		"https://app.example.com.evil": false,
+		"":                             false,
+	}
+	for origin, want := range cases {
+		if got := MatchOrigin(patterns, origin); got != want {
+			t.Errorf("MatchOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+	if !MatchOrigin([]string{"*"}, "https://anything.test") {
+		t.Fatal("expected * to match any origin")
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	cors := CORS{
+		AllowedOrigins: []string{"https://app.example.com"},
+		ExposedHeaders: []string{"X-RateLimit-Remaining", "Retry-After"},
+		MaxAge:         10 * time.Minute,
+	}
+	handler := cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", "POST")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, preflight)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected preflight 204, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Fatalf("expected Max-Age 600, got %q", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Methods"); got == "" {
+		t.Fatal("expected Allow-Methods on preflight")
+	}
+	if rr.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatal("expected no Allow-Credentials when credentials are disabled")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("expected allowed origin echoed, got %d %q", rr.Code, rr.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if got := rr.Header().Get("Access-Control-Expose-Headers"); got != "X-RateLimit-Remaining, Retry-After" {
+		t.Fatalf("unexpected Expose-Headers %q", got)
+	}
+
+	req.Header.Set("Origin", "https://evil.com")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("expected no CORS headers for a disallowed origin")
+	}
+	if rr.Header().Get("Vary") != "Origin" {
+		t.Fatalf("expected Vary: Origin, got %q", rr.Header().Get("Vary"))
+	}
+}
+
+func TestCORSValidate(t *testing.T) {
+	cases := map[string]bool{
+		"*":                       false,
+		"https://*":               false,
+		"http://*:3000":           false,
+		"https://*example.com":    false,
+		"https://*.example.com":   true,
+		"http://localhost:*":      true,
+		"https://app.example.com": true,
+	}
+	for origin, valid := range cases {
+		cors := CORS{AllowedOrigins: []string{origin}, AllowCredentials: true}
+		if err := cors.Validate(); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", origin, err, valid)
+		}
+		cors.AllowCredentials = false
+		if err := cors.Validate(); err != nil {
+			t.Errorf("Validate(%q) without credentials = %v", origin, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+// Diagnostics is a point-in-time snapshot of process runtime state.
+type Diagnostics struct {
+	Timestamp         time.Time `json:"timestamp"`
+	Uptime            string    `json:"uptime"`
+	Goroutines        int       `json:"goroutines"`
+	CPUs              int       `json:"cpus"`
+	Heap              HeapStats `json:"heap"`
+	GC                GCStats   `json:"gc"`
+	Build             BuildInfo `json:"build"`
+	ConfigFingerprint string    `json:"config_fingerprint,omitempty"`
+}
+
+// HeapStats summarises heap usage in bytes.
+type HeapStats struct {
+	AllocBytes    uint64 `json:"alloc_bytes"`
+	SysBytes      uint64 `json:"sys_bytes"`
+	InuseBytes    uint64 `json:"inuse_bytes"`
+	IdleBytes     uint64 `json:"idle_bytes"`
+	ReleasedBytes uint64 `json:"released_bytes"`
+	Objects       uint64 `json:"objects"`
+}
+
+// GCStats summarises garbage collector activity.
+type GCStats struct {
+	NumGC       uint32    `json:"num_gc"`
+	LastGC      time.Time `json:"last_gc,omitempty"`
+	PauseTotal  string    `json:"pause_total"`
+	LastPause   string    `json:"last_pause"`
+	NextGCBytes uint64    `json:"next_gc_bytes"`
+	CPUFraction float64   `json:"cpu_fraction"`
+	GOGCPercent int       `json:"gogc_percent"`
+	MemoryLimit int64     `json:"memory_limit_bytes"`
+}
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	GoVersion   string `json:"go_version"`
+	Path        string `json:"path,omitempty"`
+	Version     string `json:"version,omitempty"`
+	VCSRevision string `json:"vcs_revision,omitempty"`
+	VCSTime     string `json:"vcs_time,omitempty"`
+	VCSModified bool   `json:"vcs_modified,omitempty"`
+}
+
+var processStart = time.Now()
+
+// CollectDiagnostics gathers runtime diagnostics. fingerprint is reported as-is
+// (see ConfigFingerprint).
+func CollectDiagnostics(fingerprint string) Diagnostics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	samples := []metrics.Sample{{Name: "/gc/gogc:percent"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(samples)
+
+	gc := GCStats{
+		NumGC:       mem.NumGC,
+		PauseTotal:  time.Duration(mem.PauseTotalNs).String(),
+		LastPause:   time.Duration(mem.PauseNs[(mem.NumGC+255)%256]).String(),
+		NextGCBytes: mem.NextGC,
+		CPUFraction: mem.GCCPUFraction,
+		GOGCPercent: int(sampleUint64(samples[0])),
+		MemoryLimit: int64(sampleUint64(samples[1])),
+	}
+	if mem.LastGC > 0 {
+		gc.LastGC = time.Unix(0, int64(mem.LastGC)).UTC()
+	}
+
+	return Diagnostics{
+		Timestamp:  time.Now().UTC(),
+		Uptime:     time.Since(processStart).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		CPUs:       runtime.NumCPU(),
+		Heap: HeapStats{
+			AllocBytes:    mem.HeapAlloc,
+			SysBytes:      mem.HeapSys,
+			InuseBytes:    mem.HeapInuse,
+			IdleBytes:     mem.HeapIdle,
+			ReleasedBytes: mem.HeapReleased,
+			Objects:       mem.HeapObjects,
+		},
+		GC:                gc,
+		Build:             readBuildInfo(),
+		ConfigFingerprint: fingerprint,
+	}
+}
+
+// DiagnosticsHandler serves CollectDiagnostics as JSON. Callers are
+// responsible for restricting access (e.g. to an admin scope).
+func DiagnosticsHandler(fingerprint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(CollectDiagnostics(fingerprint))
+	}
+}
+
+// ConfigFingerprint returns a short, stable hash of cfg's JSON encoding so
+// replicas can be compared for configuration drift without exposing values.
+func ConfigFingerprint(cfg any) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func sampleUint64(s metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.Value.Uint64()
+}
+
+func readBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = bi.Main.Path
+	info.Version = bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.VCSRevision = setting.Value
+		case "vcs.time":
+			info.VCSTime = setting.Value
+		case "vcs.modified":
+			info.VCSModified = setting.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,447 @@
+// Package server provides HTTP server lifecycle conventions shared by services:
+// Kubernetes liveness/readiness/startup probes, preStop draining, graceful
+// shutdown, consistent timeouts, and optional /metrics and /debug/pprof exposure.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Default timeouts applied when Options leaves them unset.
+const (
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultWriteTimeout      = 60 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultShutdownTimeout   = 10 * time.Second
+	DefaultProbeTimeout      = 2 * time.Second
+)
+
+// Default probe and lifecycle paths.
+const (
+	DefaultLivenessPath  = "/healthz"
+	DefaultReadinessPath = "/readyz"
+	DefaultStartupPath   = "/startupz"
+	DefaultPreStopPath   = "/prestop"
+	DefaultMetricsPath   = "/metrics"
+	DebugPathPrefix      = "/debug/pprof/"
+	DebugVarsPath        = "/debug/vars"
+)
+
+// Check reports an error when a dependency is not ready.
+type Check func(ctx context.Context) error
+
+// Options configure a Server.
+type Options struct {
+	// Addr is the listen address (e.g. ":8080").
+	Addr string
+	// Handler serves application routes. Probe, metrics and debug paths take
+	// precedence over it.
+	Handler http.Handler
+	Logger  *zap.Logger
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish.
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long the server keeps serving after it starts failing
+	// readiness, giving load balancers time to remove the pod from rotation.
+	DrainDelay time.Duration
+	// ProbeTimeout bounds readiness and startup checks.
+	ProbeTimeout time.Duration
+
+	// Readiness gates /readyz in addition to the drain state.
+	Readiness Check
+	// Startup gates /startupz until it succeeds once. When nil the server is
+	// considered started as soon as it begins listening.
+	Startup Check
+
+	// EnableMetrics exposes the Prometheus handler at MetricsPath.
+	EnableMetrics bool
+	MetricsPath   string
+	// EnableDebug exposes net/http/pprof handlers under /debug/pprof/ and
+	// expvar at /debug/vars without authentication. Services that need the
+	// endpoints behind auth should mount DebugHandler on their own router.
+	EnableDebug bool
+
+	// PreStopAddr is the listen address of a separate listener serving only
+	// POST PreStopPath, e.g. "127.0.0.1:8081" for an exec preStop hook. Empty
+	// disables the endpoint; it is never served on Addr, where any caller
+	// could take the pod out of rotation.
+	PreStopAddr string
+
+	// StreamingPaths are path prefixes whose responses may outlive
+	// WriteTimeout (e.g. server-sent events); their write deadline is cleared
+	// before the application handler runs.
+	StreamingPaths []string
+
+	LivenessPath  string
+	ReadinessPath string
+	StartupPath   string
+	PreStopPath   string
+}
+
+// Server wraps http.Server with probe handlers and drain-aware shutdown.
+type Server struct {
+	opts     Options
+	logger   *zap.Logger
+	http     *http.Server
+	preStop  *http.Server // nil unless PreStopAddr is set
+	handler  atomic.Pointer[http.Handler]
+	started  atomic.Bool
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks []func(context.Context) error
+}
+
+// New constructs a Server, applying defaults for unset options.
+func New(opts Options) *Server {
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.ReadHeaderTimeout == 0 {
+		opts.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if opts.ReadTimeout == 0 {
+		opts.ReadTimeout = DefaultReadTimeout
+	}
+	if opts.WriteTimeout == 0 {
+		opts.WriteTimeout = DefaultWriteTimeout
+	}
+	if opts.IdleTimeout == 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if opts.ProbeTimeout == 0 {
+		opts.ProbeTimeout = DefaultProbeTimeout
+	}
+	if opts.MetricsPath == "" {
+		opts.MetricsPath = DefaultMetricsPath
+	}
+	if opts.LivenessPath == "" {
+		opts.LivenessPath = DefaultLivenessPath
+	}
+	if opts.ReadinessPath == "" {
+		opts.ReadinessPath = DefaultReadinessPath
+	}
+	if opts.StartupPath == "" {
+		opts.StartupPath = DefaultStartupPath
+	}
+	if opts.PreStopPath == "" {
+		opts.PreStopPath = DefaultPreStopPath
+	}
+
+	if opts.Handler == nil {
+		opts.Handler = http.NotFoundHandler()
+	}
+
+	s := &Server{opts: opts, logger: opts.Logger}
+	s.handler.Store(&opts.Handler)
+
+	s.http = &http.Server{
+		Addr:              opts.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+	if opts.PreStopAddr != "" {
+		preStop := http.NewServeMux()
+		preStop.HandleFunc(opts.PreStopPath, s.PreStopHandler)
+		s.preStop = &http.Server{
+			Addr:              opts.PreStopAddr,
+			Handler:           preStop,
+			ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		}
+	}
+	return s
+}
+
+// SetHandler replaces the application handler. It allows routes that need a
+// reference to the Server (for example ReadinessGate) to be built after New.
+func (s *Server) SetHandler(h http.Handler) {
+	s.handler.Store(&h)
+}
+
+// HTTPServer exposes the underlying http.Server.
+func (s *Server) HTTPServer() *http.Server {
+	return s.http
+}
+
+// OnShutdown registers a hook that runs after the HTTP server stops accepting
+// requests. Hooks run in reverse registration order.
+func (s *Server) OnShutdown(hook func(context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook)
+}
+
+// MarkStarted marks the startup probe as passing.
+func (s *Server) MarkStarted() {
+	s.started.Store(true)
+}
+
+// Drain marks the server as draining so readiness fails while requests continue
+// to be served.
+func (s *Server) Drain() {
+	if !s.draining.Swap(true) {
+		s.logger.Info("server draining")
+	}
+}
+
+// Draining reports whether Drain has been called.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Handler returns the root handler: lifecycle endpoints first, then the
+// application handler. The preStop endpoint is only on the PreStopAddr listener.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.opts.LivenessPath, s.LivenessHandler)
+	mux.HandleFunc(s.opts.ReadinessPath, s.ReadinessHandler)
+	mux.HandleFunc(s.opts.StartupPath, s.StartupHandler)
+	if s.opts.EnableMetrics {
+		mux.Handle(s.opts.MetricsPath, promhttp.Handler())
+	}
+	if s.opts.EnableDebug {
+		RegisterDebug(mux)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if s.streaming(r.URL.Path) {
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		(*s.handler.Load()).ServeHTTP(w, r)
+	})
+	return mux
+}
+
+// streaming reports whether path is under one of the StreamingPaths.
+func (s *Server) streaming(path string) bool {
+	for _, prefix := range s.opts.StreamingPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterDebug mounts net/http/pprof handlers under /debug/pprof/ and expvar
+// at /debug/vars.
+func RegisterDebug(mux *http.ServeMux) {
+	mux.HandleFunc(DebugPathPrefix, pprof.Index)
+	mux.HandleFunc(DebugPathPrefix+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(DebugPathPrefix+"profile", pprof.Profile)
+	mux.HandleFunc(DebugPathPrefix+"symbol", pprof.Symbol)
+	mux.HandleFunc(DebugPathPrefix+"trace", pprof.Trace)
+	mux.Handle(DebugVarsPath, expvar.Handler())
+}
+
+// DebugHandler returns a handler serving the RegisterDebug endpoints at their
+// full paths, suitable for mounting behind a service's auth middleware.
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	RegisterDebug(mux)
+	return mux
+}
+
+// ReadinessGate wraps a service-specific readiness handler so it also fails
+// while the server is draining or has not finished starting.
+func (s *Server) ReadinessGate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Draining() {
+			writeStatus(w, http.StatusServiceUnavailable, "draining", "")
+			return
+		}
+		if err := s.ensureStarted(r.Context()); err != nil {
+			writeStatus(w, http.StatusServiceUnavailable, "starting", err.Error())
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ListenAndServe starts serving and blocks until the server is shut down.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections on ln and blocks until the server is shut down.
+// With PreStopAddr set it also starts the preStop listener.
+func (s *Server) Serve(ln net.Listener) error {
+	if s.preStop != nil {
+		preStopLn, err := net.Listen("tcp", s.preStop.Addr)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		s.logger.Info("preStop listener starting", zap.String("addr", preStopLn.Addr().String()))
+		go func() {
+			if err := s.preStop.Serve(preStopLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("preStop listener failed", zap.Error(err))
+			}
+		}()
+	}
+	if s.opts.Startup == nil {
+		s.MarkStarted()
+	}
+	s.logger.Info("HTTP server starting", zap.String("addr", ln.Addr().String()))
+	if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Run serves until ctx is cancelled, then drains and shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.opts.DrainDelay+s.opts.ShutdownTimeout)
+	defer cancel()
+	return s.Shutdown(shutdownCtx)
+}
+
+// Shutdown fails readiness, waits DrainDelay, stops the HTTP server, then runs
+// shutdown hooks.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Drain()
+	if s.opts.DrainDelay > 0 {
+		select {
+		case <-time.After(s.opts.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error("graceful shutdown failed", zap.Error(err))
+	}
+	if s.preStop != nil {
+		if preStopErr := s.preStop.Shutdown(ctx); preStopErr != nil {
+			err = errors.Join(err, preStopErr)
+		}
+	}
+
+	s.mu.Lock()
+	hooks := append([]func(context.Context) error(nil), s.hooks...)
+	s.mu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hookErr := hooks[i](ctx); hookErr != nil {
+			s.logger.Error("shutdown hook failed", zap.Error(hookErr))
+			err = errors.Join(err, hookErr)
+		}
+	}
+	return err
+}
+
+// LivenessHandler always reports ok while the process can serve requests.
+func (s *Server) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeStatus(w, http.StatusOK, "ok", "")
+}
+
+// ReadinessHandler reports ready once started, not draining, and Readiness passes.
+func (s *Server) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	s.ReadinessGate(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.runCheck(r.Context(), s.opts.Readiness); err != nil {
+			s.logger.Warn("readiness check failed", zap.Error(err))
+			writeStatus(w, http.StatusServiceUnavailable, "not_ready", err.Error())
+			return
+		}
+		writeStatus(w, http.StatusOK, "ready", "")
+	})(w, r)
+}
+
+// StartupHandler reports started once the Startup check has passed.
+func (s *Server) StartupHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.ensureStarted(r.Context()); err != nil {
+		writeStatus(w, http.StatusServiceUnavailable, "starting", err.Error())
+		return
+	}
+	writeStatus(w, http.StatusOK, "started", "")
+}
+
+// ensureStarted runs the startup check until it first succeeds.
+func (s *Server) ensureStarted(ctx context.Context) error {
+	if s.started.Load() {
+		return nil
+	}
+	if s.opts.Startup == nil {
+		return errors.New("server not listening")
+	}
+	if err := s.runCheck(ctx, s.opts.Startup); err != nil {
+		return err
+	}
+	s.MarkStarted()
+	return nil
+}
+
+// PreStopHandler is intended for a Kubernetes preStop exec hook posting to
+// the PreStopAddr listener. It starts draining and blocks for DrainDelay so
+// the pod is removed from endpoints before SIGTERM triggers shutdown. Only
+// POST is accepted, so a stray GET cannot drain the pod.
+func (s *Server) PreStopHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeStatus(w, http.StatusMethodNotAllowed, "method_not_allowed", "")
+		return
+	}
+	s.Drain()
+	if s.opts.DrainDelay > 0 {
+		select {
+		case <-time.After(s.opts.DrainDelay):
+		case <-r.Context().Done():
+		}
+	}
+	writeStatus(w, http.StatusOK, "draining", "")
+}
+
+func (s *Server) runCheck(ctx context.Context, check Check) error {
+	if check == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opts.ProbeTimeout)
+	defer cancel()
+	return check(ctx)
+}
+
+func writeStatus(w http.ResponseWriter, code int, status, errMsg string) {
+	payload := map[string]string{"status": status}
+	if errMsg != "" {
+		payload["error"] = errMsg
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, s *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	return rr
+}
+
+func TestProbesAndDrain(t *testing.T) {
+	ready := errors.New("db down")
+	s := New(Options{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+		Readiness: func(ctx context.Context) error { return ready },
+	})
+	s.MarkStarted()
+
+	if rr := serve(t, s, "/healthz"); rr.Code != http.StatusOK {
+		t.Fatalf("expected liveness 200, got %d", rr.Code)
+	}
+	if rr := serve(t, s, "/readyz"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness 503 while check fails, got %d", rr.Code)
+	}
+
+	ready = nil
+	if rr := serve(t, s, "/readyz"); rr.Code != http.StatusOK {
+		t.Fatalf("expected readiness 200, got %d", rr.Code)
+	}
+	if rr := serve(t, s, "/app"); rr.Code != http.StatusTeapot {
+		t.Fatalf("expected application handler, got %d", rr.Code)
+	}
+
+	s.Drain()
+	if rr := serve(t, s, "/readyz"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness 503 while draining, got %d", rr.Code)
+	}
+	if rr := serve(t, s, "/healthz"); rr.Code != http.StatusOK {
+		t.Fatalf("expected liveness to pass while draining, got %d", rr.Code)
+	}
+}
+
+func TestStartupProbe(t *testing.T) {
+	started := false
+	s := New(Options{
+		Startup: func(ctx context.Context) error {
+			if !started {
+				return errors.New("warming caches")
+			}
+			return nil
+		},
+	})
+
+	if rr := serve(t, s, "/startupz"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected startup 503, got %d", rr.Code)
+	}
+	gated := s.ReadinessGate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	rr := httptest.NewRecorder()
+	gated(rr, httptest.NewRequest(http.MethodGet, "/v1/status/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected gated readiness 503 before startup, got %d", rr.Code)
+	}
+
+	started = true
+	if rr := serve(t, s, "/startupz"); rr.Code != http.StatusOK {
+		t.Fatalf("expected startup 200, got %d", rr.Code)
+	}
+}
+
+func TestMetricsAndDebugAreOptIn(t *testing.T) {
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	off := New(Options{Handler: app})
+	if rr := serve(t, off, "/debug/pprof/"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected pprof disabled by default, got %d", rr.Code)
+	}
+	if rr := serve(t, off, "/metrics"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected metrics disabled by default, got %d", rr.Code)
+	}
+
+	on := New(Options{Handler: app, EnableDebug: true, EnableMetrics: true})
+	if rr := serve(t, on, "/debug/pprof/"); rr.Code != http.StatusOK {
+		t.Fatalf("expected pprof index, got %d", rr.Code)
+	}
+	if rr := serve(t, on, "/metrics"); rr.Code != http.StatusOK {
+		t.Fatalf("expected metrics, got %d", rr.Code)
+	}
+}
+
+func TestRunShutsDownAndRunsHooks(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := New(Options{Addr: addr, DrainDelay: 10 * time.Millisecond})
+	hookRan := make(chan struct{})
+	s.OnShutdown(func(ctx context.Context) error {
+		close(hookRan)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+	select {
+	case <-hookRan:
+	default:
+		t.Fatal("expected shutdown hook to run")
+	}
+	if !s.Draining() {
+		t.Fatal("expected server to be draining after shutdown")
+	}
+}
+
+func TestDiagnosticsHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	DiagnosticsHandler(ConfigFingerprint(map[string]string{"a": "b"}))(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/diagnostics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var diag Diagnostics
+	if err := json.NewDecoder(rr.Body).Decode(&diag); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if diag.Goroutines == 0 || diag.Heap.SysBytes == 0 || diag.Build.GoVersion == "" {
+		t.Fatalf("expected runtime stats to be populated: %+v", diag)
+	}
+	if diag.ConfigFingerprint != ConfigFingerprint(map[string]string{"a": "b"}) {
+		t.Fatalf("unexpected fingerprint %q", diag.ConfigFingerprint)
+	}
+	if ConfigFingerprint(map[string]string{"a": "c"}) == diag.ConfigFingerprint {
+		t.Fatal("expected fingerprint to change with config")
+	}
+}
+
+func TestDebugHandlerServesVars(t *testing.T) {
+	rr := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, DebugVarsPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected expvar 200, got %d", rr.Code)
+	}
+}
+
+func TestPreStopIsOptInAndPostOnly(t *testing.T) {
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// Never on the public handler, enabled or not
+	for _, opts := range []Options{{Handler: app}, {Handler: app, PreStopAddr: "127.0.0.1:0"}} {
+		s := New(opts)
+		rr := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, DefaultPreStopPath, nil))
+		if rr.Code != http.StatusNotFound || s.Draining() {
+			t.Fatalf("expected public %s to reach the app without draining, got %d (draining %v)", DefaultPreStopPath, rr.Code, s.Draining())
+		}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	preStopLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	preStopAddr := preStopLn.Addr().String()
+	preStopLn.Close()
+
+	s := New(Options{Handler: app, PreStopAddr: preStopAddr})
+	go func() { _ = s.Serve(ln) }()
+	defer s.Shutdown(context.Background())
+
+	url := "http://" + preStopAddr + DefaultPreStopPath
+	var resp *http.Response
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err = http.Get(url)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("preStop listener did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || s.Draining() {
+		t.Fatalf("expected GET to be rejected without draining, got %d (draining %v)", resp.StatusCode, s.Draining())
+	}
+
+	resp, err = http.Post(url, "", nil)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !s.Draining() {
+		t.Fatalf("expected POST to drain, got %d (draining %v)", resp.StatusCode, s.Draining())
+	}
+}
+
+func TestStreamingPathsOutliveWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := New(Options{
+		WriteTimeout:   50 * time.Millisecond,
+		StreamingPaths: []string{"/v1/chat/completions"},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(150 * time.Millisecond)
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+		}),
+	})
+	go func() { _ = s.Serve(ln) }()
+	defer s.Shutdown(context.Background())
+
+	base := "http://" + ln.Addr().String()
+	resp, err := http.Get(base + "/v1/chat/completions")
+	if err != nil {
+		t.Fatalf("streaming request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected streaming response, got %d", resp.StatusCode)
+	}
+
+	if resp, err := http.Get(base + "/v1/models"); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the write timeout to cut off a non-streaming route")
+	}
+}
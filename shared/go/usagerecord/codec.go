@@ -0,0 +1,379 @@
+package usagerecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Content types of encoded records, carried in the message's content-type header.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Encoding selects how a producer serializes records.
+type Encoding string
+
+// Supported encodings.
+const (
+	EncodingJSON     Encoding = "json"
+	EncodingProtobuf Encoding = "protobuf" // usage_record.proto; about a third of the JSON size
+)
+
+// ParseEncoding parses an encoding name; empty means JSON.
+func ParseEncoding(name string) (Encoding, error) {
+	switch Encoding(name) {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingProtobuf:
+		return EncodingProtobuf, nil
+	}
+	return "", fmt.Errorf("unknown usage record encoding %q (want json or protobuf)", name)
+}
+
+// ContentType returns the content type of records in the encoding.
+func (e Encoding) ContentType() string {
+	if e == EncodingProtobuf {
+		return ContentTypeProtobuf
+	}
+	return ContentTypeJSON
+}
+
+// Marshal encodes record as the current version.
+func (e Encoding) Marshal(record Record) ([]byte, error) {
+	if e == EncodingProtobuf {
+		return MarshalProto(record)
+	}
+	return Encode(record)
+}
+
+// ReadMode selects which encodings a consumer accepts.
+type ReadMode string
+
+// Read modes. During a migration consumers run in ReadDual, which accepts
+// both encodings, until every producer has switched; they can then be pinned
+// to the new encoding so stray records in the old one are rejected.
+const (
+	ReadDual     ReadMode = "dual"
+	ReadJSON     ReadMode = "json"
+	ReadProtobuf ReadMode = "protobuf"
+)
+
+// ErrEncodingNotAccepted is returned when a record's encoding is excluded by the read mode.
+var ErrEncodingNotAccepted = errors.New("usage record encoding not accepted")
+
+// ParseReadMode parses a read mode name; empty means ReadDual.
+func ParseReadMode(name string) (ReadMode, error) {
+	switch ReadMode(name) {
+	case "", ReadDual:
+		return ReadDual, nil
+	case ReadJSON, ReadProtobuf:
+		return ReadMode(name), nil
+	}
+	return "", fmt.Errorf("unknown usage record read mode %q (want dual, json or protobuf)", name)
+}
+
+// Unmarshal decodes a record in either encoding and upgrades it to the current
+// Record, returning the version it was written in. contentType is the
+// message's content-type header; when it is empty or unknown the encoding is
+// detected from the payload (JSON records start with '{', protobuf records
+// with the schema_version tag).
+func (m ReadMode) Unmarshal(contentType string, data []byte) (Record, int, error) {
+	isProto := isProtobuf(contentType, data)
+	if (isProto && m == ReadJSON) || (!isProto && m == ReadProtobuf) {
+		return Record{}, 0, fmt.Errorf("%w: read mode %s", ErrEncodingNotAccepted, m)
+	}
+	if !isProto {
+		return Decode(data)
+	}
+
+	record, err := UnmarshalProto(data)
+	if err != nil {
+		return Record{}, 0, err
+	}
+	version := record.SchemaVersion
+	record = record.Upgrade()
+	record.SchemaVersion = CurrentVersion
+	return record, version, nil
+}
+
+func isProtobuf(contentType string, data []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case ContentTypeProtobuf:
+			return true
+		case ContentTypeJSON:
+			return false
+		}
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] != '{'
+}
+
+// Field numbers from usage_record.proto.
+const (
+	fieldSchemaVersion protowire.Number = iota + 1
+	fieldRecordID
+	fieldRequestID
+	fieldOrganizationID
+	fieldAPIKeyID
+	fieldModel
+	fieldBackendID
+	fieldTokensInput
+	fieldTokensOutput
+	fieldLatencyMS
+	fieldCostUSD
+	fieldStatus
+	fieldErrorCode
+	fieldLimitState
+	fieldDecisionReason
+	fieldBudgetSnapshot
+	fieldRetryCount
+	fieldTraceID
+	fieldSpanID
+	fieldMetadataJSON
+	fieldPrompt
+	fieldResponse
+	fieldPayloadMode
+	fieldTimestamp
+)
+
+const (
+	fieldBudgetPeriod protowire.Number = iota + 1
+	fieldBudgetTokensRemaining
+	fieldBudgetCurrencyRemaining
+)
+
+// MarshalProto encodes record as the current version in the protobuf format.
+// Zero values are omitted, as in proto3.
+func MarshalProto(record Record) ([]byte, error) {
+	var b []byte
+	b = appendVarint(b, fieldSchemaVersion, CurrentVersion)
+	b = appendString(b, fieldRecordID, record.RecordID)
+	b = appendString(b, fieldRequestID, record.RequestID)
+	b = appendString(b, fieldOrganizationID, record.OrganizationID)
+	b = appendString(b, fieldAPIKeyID, record.APIKeyID)
+	b = appendString(b, fieldModel, record.Model)
+	b = appendString(b, fieldBackendID, record.BackendID)
+	b = appendVarint(b, fieldTokensInput, int64(record.TokensInput))
+	b = appendVarint(b, fieldTokensOutput, int64(record.TokensOutput))
+	b = appendVarint(b, fieldLatencyMS, int64(record.LatencyMS))
+	b = appendDouble(b, fieldCostUSD, record.CostUSD)
+	b = appendString(b, fieldStatus, record.Status)
+	b = appendString(b, fieldErrorCode, record.ErrorCode)
+	b = appendString(b, fieldLimitState, record.LimitState)
+	b = appendString(b, fieldDecisionReason, record.DecisionReason)
+	if s := record.BudgetSnapshot; s != nil {
+		var sb []byte
+		sb = appendString(sb, fieldBudgetPeriod, s.Period)
+		sb = appendVarint(sb, fieldBudgetTokensRemaining, int64(s.TokensRemaining))
+		sb = appendDouble(sb, fieldBudgetCurrencyRemaining, s.CurrencyRemaining)
+		b = protowire.AppendTag(b, fieldBudgetSnapshot, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	b = appendVarint(b, fieldRetryCount, int64(record.RetryCount))
+	b = appendString(b, fieldTraceID, record.TraceID)
+	b = appendString(b, fieldSpanID, record.SpanID)
+	if len(record.Metadata) > 0 {
+		metadata, err := json.Marshal(record.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("marshal usage record metadata: %w", err)
+		}
+		b = protowire.AppendTag(b, fieldMetadataJSON, protowire.BytesType)
+		b = protowire.AppendBytes(b, metadata)
+	}
+	b = appendString(b, fieldPrompt, record.Prompt)
+	b = appendString(b, fieldResponse, record.Response)
+	b = appendString(b, fieldPayloadMode, record.PayloadMode)
+	if !record.Timestamp.IsZero() {
+		b = appendVarint(b, fieldTimestamp, record.Timestamp.UnixNano())
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes a protobuf record without upgrading it; SchemaVersion
+// is the version it was written in. Unknown fields are skipped.
+func UnmarshalProto(data []byte) (Record, error) {
+	var record Record
+	hasVersion := false
+	err := consumeMessage(data, recordFieldTypes, func(num protowire.Number, value []byte, n uint64) error {
+		switch num {
+		case fieldSchemaVersion:
+			hasVersion = true
+			record.SchemaVersion = int(int32(n))
+		case fieldRecordID:
+			record.RecordID = string(value)
+		case fieldRequestID:
+			record.RequestID = string(value)
+		case fieldOrganizationID:
+			record.OrganizationID = string(value)
+		case fieldAPIKeyID:
+			record.APIKeyID = string(value)
+		case fieldModel:
+			record.Model = string(value)
+		case fieldBackendID:
+			record.BackendID = string(value)
+		case fieldTokensInput:
+			record.TokensInput = int(int64(n))
+		case fieldTokensOutput:
+			record.TokensOutput = int(int64(n))
+		case fieldLatencyMS:
+			record.LatencyMS = int(int64(n))
+		case fieldCostUSD:
+			record.CostUSD = math.Float64frombits(n)
+		case fieldStatus:
+			record.Status = string(value)
+		case fieldErrorCode:
+			record.ErrorCode = string(value)
+		case fieldLimitState:
+			record.LimitState = string(value)
+		case fieldDecisionReason:
+			record.DecisionReason = string(value)
+		case fieldBudgetSnapshot:
+			snapshot, err := unmarshalBudgetSnapshot(value)
+			if err != nil {
+				return err
+			}
+			record.BudgetSnapshot = snapshot
+		case fieldRetryCount:
+			record.RetryCount = int(int64(n))
+		case fieldTraceID:
+			record.TraceID = string(value)
+		case fieldSpanID:
+			record.SpanID = string(value)
+		case fieldMetadataJSON:
+			if err := json.Unmarshal(value, &record.Metadata); err != nil {
+				return fmt.Errorf("metadata: %w", err)
+			}
+		case fieldPrompt:
+			record.Prompt = string(value)
+		case fieldResponse:
+			record.Response = string(value)
+		case fieldPayloadMode:
+			record.PayloadMode = string(value)
+		case fieldTimestamp:
+			record.Timestamp = time.Unix(0, int64(n)).UTC()
+		}
+		return nil
+	})
+	if err != nil {
+		return Record{}, fmt.Errorf("unmarshal usage record protobuf: %w", err)
+	}
+	if !hasVersion {
+		return Record{}, errors.New("unmarshal usage record protobuf: schema_version is missing")
+	}
+	if record.SchemaVersion < 0 {
+		return Record{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, record.SchemaVersion)
+	}
+	return record, nil
+}
+
+func unmarshalBudgetSnapshot(data []byte) (*BudgetSnapshot, error) {
+	var s BudgetSnapshot
+	err := consumeMessage(data, budgetFieldTypes, func(num protowire.Number, value []byte, n uint64) error {
+		switch num {
+		case fieldBudgetPeriod:
+			s.Period = string(value)
+		case fieldBudgetTokensRemaining:
+			s.TokensRemaining = int(int64(n))
+		case fieldBudgetCurrencyRemaining:
+			s.CurrencyRemaining = math.Float64frombits(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("budget_snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// recordFieldTypes and budgetFieldTypes are the wire types of the known
+// fields; a field received with another type is an error rather than misread.
+var recordFieldTypes = func() map[protowire.Number]protowire.Type {
+	types := make(map[protowire.Number]protowire.Type, int(fieldTimestamp))
+	for num := fieldSchemaVersion; num <= fieldTimestamp; num++ {
+		types[num] = protowire.BytesType
+	}
+	for _, num := range []protowire.Number{fieldSchemaVersion, fieldTokensInput, fieldTokensOutput, fieldLatencyMS, fieldRetryCount, fieldTimestamp} {
+		types[num] = protowire.VarintType
+	}
+	types[fieldCostUSD] = protowire.Fixed64Type
+	return types
+}()
+
+var budgetFieldTypes = map[protowire.Number]protowire.Type{
+	fieldBudgetPeriod:            protowire.BytesType,
+	fieldBudgetTokensRemaining:   protowire.VarintType,
+	fieldBudgetCurrencyRemaining: protowire.Fixed64Type,
+}
+
+// consumeMessage calls fn for each field of a message with the field's bytes
+// (length-delimited types) or numeric value (varint and fixed64).
+func consumeMessage(data []byte, types map[protowire.Number]protowire.Type, fn func(num protowire.Number, value []byte, n uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		var number uint64
+		switch typ {
+		case protowire.VarintType:
+			number, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			number, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("field %d: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if want, ok := types[num]; ok && want != typ {
+			return fmt.Errorf("field %d: wire type %d, want %d", num, typ, want)
+		}
+		if _, ok := types[num]; !ok {
+			continue // Unknown field from a newer producer
+		}
+		if err := fn(num, value, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
@@ -0,0 +1,136 @@
+package usagerecord
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func sampleRecord() Record {
+	return Record{
+		SchemaVersion:  CurrentVersion,
+		RecordID:       "r-1",
+		RequestID:      "req-1",
+		OrganizationID: "org-1",
+		APIKeyID:       "k-1",
+		Model:          "gpt-4o",
+		BackendID:      "b-1",
+		TokensInput:    120,
+		TokensOutput:   48,
+		LatencyMS:      310,
+		CostUSD:        0.0125,
+		Status:         StatusSuccess,
+		LimitState:     "WITHIN_LIMIT",
+		DecisionReason: "PRIMARY",
+		BudgetSnapshot: &BudgetSnapshot{Period: "MONTHLY", TokensRemaining: 1000, CurrencyRemaining: 4.5},
+		RetryCount:     1,
+		TraceID:        "trace",
+		SpanID:         "span",
+		Metadata:       map[string]any{"project_id": "p-1", "attempt": float64(2)},
+		Prompt:         "sha256:abc",
+		PayloadMode:    "hash_only",
+		Timestamp:      time.Date(2025, 3, 1, 12, 0, 0, 123, time.UTC),
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	record := sampleRecord()
+	data, err := EncodingProtobuf.Marshal(record)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	jsonData, _ := EncodingJSON.Marshal(record)
+	if len(data) >= len(jsonData) {
+		t.Fatalf("expected protobuf (%d bytes) to be smaller than JSON (%d bytes)", len(data), len(jsonData))
+	}
+
+	got, version, err := ReadDual.Unmarshal(ContentTypeProtobuf, data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if version != CurrentVersion {
+		t.Fatalf("expected version %d, got %d", CurrentVersion, version)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Fatalf("round trip mismatch\n got %+v\nwant %+v", got, record)
+	}
+}
+
+func TestDualReadDetectsEncodingWithoutContentType(t *testing.T) {
+	record := sampleRecord()
+	for _, enc := range []Encoding{EncodingJSON, EncodingProtobuf} {
+		data, err := enc.Marshal(record)
+		if err != nil {
+			t.Fatalf("%s marshal: %v", enc, err)
+		}
+		got, _, err := ReadDual.Unmarshal("", data)
+		if err != nil {
+			t.Fatalf("%s unmarshal: %v", enc, err)
+		}
+		if got.RecordID != record.RecordID || got.CostUSD != record.CostUSD {
+			t.Fatalf("%s: unexpected record %+v", enc, got)
+		}
+	}
+
+	// Unversioned JSON from before the migration still decodes
+	got, version, err := ReadDual.Unmarshal("application/json; charset=utf-8", []byte(`{"record_id":"r-9","organization_id":"org-1"}`))
+	if err != nil || version != 1 || got.RecordID != "r-9" {
+		t.Fatalf("expected v1 JSON record, got %+v version %d err %v", got, version, err)
+	}
+}
+
+func TestPinnedReadModeRejectsOtherEncoding(t *testing.T) {
+	record := sampleRecord()
+	protoData, _ := EncodingProtobuf.Marshal(record)
+	jsonData, _ := EncodingJSON.Marshal(record)
+
+	if _, _, err := ReadJSON.Unmarshal("", protoData); !errors.Is(err, ErrEncodingNotAccepted) {
+		t.Fatalf("expected json read mode to reject protobuf, got %v", err)
+	}
+	if _, _, err := ReadProtobuf.Unmarshal(ContentTypeJSON, jsonData); !errors.Is(err, ErrEncodingNotAccepted) {
+		t.Fatalf("expected protobuf read mode to reject JSON, got %v", err)
+	}
+	if _, _, err := ReadProtobuf.Unmarshal(ContentTypeProtobuf, protoData); err != nil {
+		t.Fatalf("expected protobuf read mode to accept protobuf, got %v", err)
+	}
+}
+
+func TestUnmarshalProtoSkipsUnknownAndRejectsMistypedFields(t *testing.T) {
+	data, _ := MarshalProto(Record{RecordID: "r-1"})
+
+	// A field added by a newer producer is ignored
+	withUnknown := protowire.AppendTag(append([]byte{}, data...), 99, protowire.BytesType)
+	withUnknown = protowire.AppendString(withUnknown, "later")
+	if got, err := UnmarshalProto(withUnknown); err != nil || got.RecordID != "r-1" {
+		t.Fatalf("expected unknown field skipped, got %+v err %v", got, err)
+	}
+
+	mistyped := protowire.AppendTag(append([]byte{}, data...), fieldTokensInput, protowire.BytesType)
+	mistyped = protowire.AppendString(mistyped, "many")
+	if _, err := UnmarshalProto(mistyped); err == nil {
+		t.Fatal("expected error for mistyped field")
+	}
+
+	unversioned := protowire.AppendString(protowire.AppendTag(nil, fieldRecordID, protowire.BytesType), "r-1")
+	if _, err := UnmarshalProto(unversioned); err == nil {
+		t.Fatal("expected error without schema_version")
+	}
+}
+
+func TestParseEncodingAndReadMode(t *testing.T) {
+	if enc, err := ParseEncoding(""); err != nil || enc != EncodingJSON {
+		t.Fatalf("expected empty encoding to default to json, got %q %v", enc, err)
+	}
+	if _, err := ParseEncoding("avro"); err == nil {
+		t.Fatal("expected unknown encoding rejected")
+	}
+	if mode, err := ParseReadMode(""); err != nil || mode != ReadDual {
+		t.Fatalf("expected empty read mode to default to dual, got %q %v", mode, err)
+	}
+	if _, err := ParseReadMode("both"); err == nil {
+		t.Fatal("expected unknown read mode rejected")
+	}
+}
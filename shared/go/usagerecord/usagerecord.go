@@ -0,0 +1,251 @@
+// Package usagerecord defines the versioned usage record schema shared by the
+// API router, which publishes records, and analytics ingestion, which consumes
+// them. Every record carries schema_version; Decode recognises each registered
+// version and upgrades it to the current Record, so producers and consumers
+// can be deployed independently.
+//
+// Records published before versioning carry no schema_version and are told
+// apart by their ID field: event_id marks the original analytics event (V0),
+// record_id the router's unversioned usage record (V1).
+//
+// Compatibility rules: a new version may only add optional fields. Anything
+// else needs a new message type. A consumer therefore decodes versions newer
+// than CurrentVersion as the current Record and ignores the fields it does not
+// know yet.
+package usagerecord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CurrentVersion is the schema version producers write.
+const CurrentVersion = 2
+
+// Request outcomes carried in Record.Status.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// ErrUnsupportedVersion is returned for schema versions that were never registered.
+var ErrUnsupportedVersion = errors.New("unsupported usage record schema version")
+
+// Record is the current usage record.
+type Record = V2
+
+// BudgetSnapshot represents budget state at the time of the request.
+type BudgetSnapshot struct {
+	Period            string  `json:"period"` // "DAILY" or "MONTHLY"
+	TokensRemaining   int     `json:"tokens_remaining"`
+	CurrencyRemaining float64 `json:"currency_remaining"`
+}
+
+// V2 is the versioned usage record. Compared with V1 it adds schema_version
+// and the request outcome (status, error_code), and its metadata values may be
+// any JSON value.
+type V2 struct {
+	SchemaVersion  int             `json:"schema_version"`
+	RecordID       string          `json:"record_id"`
+	RequestID      string          `json:"request_id"`
+	OrganizationID string          `json:"organization_id"`
+	APIKeyID       string          `json:"api_key_id"`
+	Model          string          `json:"model"`
+	BackendID      string          `json:"backend_id"`
+	TokensInput    int             `json:"tokens_input"`
+	TokensOutput   int             `json:"tokens_output"`
+	LatencyMS      int             `json:"latency_ms"`
+	CostUSD        float64         `json:"cost_usd"`
+	Status         string          `json:"status,omitempty"` // StatusSuccess when empty
+	ErrorCode      string          `json:"error_code,omitempty"`
+	LimitState     string          `json:"limit_state"`
+	DecisionReason string          `json:"decision_reason"`
+	BudgetSnapshot *BudgetSnapshot `json:"budget_snapshot,omitempty"`
+	RetryCount     int             `json:"retry_count,omitempty"`
+	TraceID        string          `json:"trace_id,omitempty"`
+	SpanID         string          `json:"span_id,omitempty"`
+	Metadata       map[string]any  `json:"metadata,omitempty"`
+	Prompt         string          `json:"prompt,omitempty"`
+	Response       string          `json:"response,omitempty"`
+	PayloadMode    string          `json:"payload_mode,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
+}
+
+// Upgrade returns the record as the current version.
+func (v *V2) Upgrade() Record {
+	r := *v
+	if r.Status == "" {
+		r.Status = StatusSuccess
+	}
+	return r
+}
+
+// V1 is the router's usage record before schema versioning. The router only
+// recorded successful requests.
+type V1 struct {
+	RecordID       string            `json:"record_id"`
+	RequestID      string            `json:"request_id"`
+	OrganizationID string            `json:"organization_id"`
+	APIKeyID       string            `json:"api_key_id"`
+	Model          string            `json:"model"`
+	BackendID      string            `json:"backend_id"`
+	TokensInput    int               `json:"tokens_input"`
+	TokensOutput   int               `json:"tokens_output"`
+	LatencyMS      int               `json:"latency_ms"`
+	CostUSD        float64           `json:"cost_usd"`
+	LimitState     string            `json:"limit_state"`
+	DecisionReason string            `json:"decision_reason"`
+	BudgetSnapshot *BudgetSnapshot   `json:"budget_snapshot,omitempty"`
+	RetryCount     int               `json:"retry_count,omitempty"`
+	TraceID        string            `json:"trace_id,omitempty"`
+	SpanID         string            `json:"span_id,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Prompt         string            `json:"prompt,omitempty"`
+	Response       string            `json:"response,omitempty"`
+	PayloadMode    string            `json:"payload_mode,omitempty"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// Upgrade returns the record as the current version.
+func (v *V1) Upgrade() Record {
+	r := Record{
+		RecordID:       v.RecordID,
+		RequestID:      v.RequestID,
+		OrganizationID: v.OrganizationID,
+		APIKeyID:       v.APIKeyID,
+		Model:          v.Model,
+		BackendID:      v.BackendID,
+		TokensInput:    v.TokensInput,
+		TokensOutput:   v.TokensOutput,
+		LatencyMS:      v.LatencyMS,
+		CostUSD:        v.CostUSD,
+		Status:         StatusSuccess,
+		LimitState:     v.LimitState,
+		DecisionReason: v.DecisionReason,
+		BudgetSnapshot: v.BudgetSnapshot,
+		RetryCount:     v.RetryCount,
+		TraceID:        v.TraceID,
+		SpanID:         v.SpanID,
+		Prompt:         v.Prompt,
+		Response:       v.Response,
+		PayloadMode:    v.PayloadMode,
+		Timestamp:      v.Timestamp,
+	}
+	if len(v.Metadata) > 0 {
+		r.Metadata = make(map[string]any, len(v.Metadata))
+		for k, val := range v.Metadata {
+			r.Metadata[k] = val
+		}
+	}
+	return r
+}
+
+// V0 is the original analytics usage event, still written by tooling such as
+// synthesize-usage and found in older backfill archives.
+type V0 struct {
+	EventID      string         `json:"event_id"`
+	OrgID        string         `json:"org_id"`
+	ModelID      string         `json:"model_id"`
+	OccurredAt   time.Time      `json:"occurred_at"`
+	InputTokens  int64          `json:"input_tokens"`
+	OutputTokens int64          `json:"output_tokens"`
+	LatencyMS    int            `json:"latency_ms"`
+	Status       string         `json:"status"`
+	ErrorCode    string         `json:"error_code,omitempty"`
+	CostEstimate float64        `json:"cost_estimate"` // USD
+	APIKeyID     string         `json:"api_key_id,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+}
+
+// Upgrade returns the event as the current version. The event ID becomes the
+// record ID and the model ID the model.
+func (v *V0) Upgrade() Record {
+	return Record{
+		RecordID:       v.EventID,
+		OrganizationID: v.OrgID,
+		APIKeyID:       v.APIKeyID,
+		Model:          v.ModelID,
+		TokensInput:    int(v.InputTokens),
+		TokensOutput:   int(v.OutputTokens),
+		LatencyMS:      v.LatencyMS,
+		CostUSD:        v.CostEstimate,
+		Status:         v.Status,
+		ErrorCode:      v.ErrorCode,
+		Metadata:       v.Metadata,
+		Timestamp:      v.OccurredAt,
+	}
+}
+
+// upgrader is implemented by every registered version.
+type upgrader interface {
+	Upgrade() Record
+}
+
+// registry maps each schema version to its decoder.
+var registry = map[int]func(data []byte) (Record, error){
+	0: decoder[V0](),
+	1: decoder[V1](),
+	2: decoder[V2](),
+}
+
+func decoder[T any, P interface {
+	*T
+	upgrader
+}]() func(data []byte) (Record, error) {
+	return func(data []byte) (Record, error) {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return Record{}, err
+		}
+		return P(&v).Upgrade(), nil
+	}
+}
+
+// Version returns the schema version of an encoded record without decoding it.
+func Version(data []byte) (int, error) {
+	var probe struct {
+		SchemaVersion *int   `json:"schema_version"`
+		EventID       string `json:"event_id"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return 0, fmt.Errorf("unmarshal usage record: %w", err)
+	}
+	switch {
+	case probe.SchemaVersion != nil:
+		return *probe.SchemaVersion, nil
+	case probe.EventID != "":
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Decode decodes a usage record of any registered version and upgrades it to
+// the current Record. It also returns the version the record was written in.
+func Decode(data []byte) (Record, int, error) {
+	version, err := Version(data)
+	if err != nil {
+		return Record{}, 0, err
+	}
+	if version < 0 {
+		return Record{}, version, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	decode, ok := registry[min(version, CurrentVersion)]
+	if !ok {
+		return Record{}, version, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	record, err := decode(data)
+	if err != nil {
+		return Record{}, version, fmt.Errorf("unmarshal usage record v%d: %w", version, err)
+	}
+	record.SchemaVersion = CurrentVersion
+	return record, version, nil
+}
+
+// Encode marshals a record as the current version.
+func Encode(record Record) ([]byte, error) {
+	record.SchemaVersion = CurrentVersion
+	return json.Marshal(record)
+}
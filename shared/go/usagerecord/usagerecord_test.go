@@ -0,0 +1,101 @@
+package usagerecord
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeUpgradesEveryVersion(t *testing.T) {
+	ts := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name    string
+		data    string
+		version int
+		want    Record
+	}{
+		{
+			name:    "v0 analytics event",
+			data:    `{"event_id":"e-1","org_id":"org-1","model_id":"m-1","occurred_at":"2025-03-01T12:00:00Z","input_tokens":10,"output_tokens":20,"latency_ms":30,"status":"error","error_code":"TIMEOUT","cost_estimate":0.5,"metadata":{"attempt":2}}`,
+			version: 0,
+			want: Record{
+				RecordID: "e-1", OrganizationID: "org-1", Model: "m-1", TokensInput: 10, TokensOutput: 20,
+				LatencyMS: 30, CostUSD: 0.5, Status: StatusError, ErrorCode: "TIMEOUT", Timestamp: ts,
+			},
+		},
+		{
+			name:    "v1 unversioned router record",
+			data:    `{"record_id":"r-1","request_id":"req-1","organization_id":"org-1","api_key_id":"k-1","model":"gpt-4o","backend_id":"b-1","tokens_input":10,"tokens_output":20,"latency_ms":30,"cost_usd":0.5,"limit_state":"WITHIN_LIMIT","decision_reason":"PRIMARY","metadata":{"project_id":"p-1"},"timestamp":"2025-03-01T12:00:00Z"}`,
+			version: 1,
+			want: Record{
+				RecordID: "r-1", RequestID: "req-1", OrganizationID: "org-1", APIKeyID: "k-1", Model: "gpt-4o", BackendID: "b-1",
+				TokensInput: 10, TokensOutput: 20, LatencyMS: 30, CostUSD: 0.5, Status: StatusSuccess,
+				LimitState: "WITHIN_LIMIT", DecisionReason: "PRIMARY", Timestamp: ts,
+			},
+		},
+		{
+			name:    "v3 decodes as current",
+			data:    `{"schema_version":3,"record_id":"r-2","organization_id":"org-1","model":"gpt-4o","status":"success","timestamp":"2025-03-01T12:00:00Z","added_later":true}`,
+			version: 3,
+			want:    Record{RecordID: "r-2", OrganizationID: "org-1", Model: "gpt-4o", Status: StatusSuccess, Timestamp: ts},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, version, err := Decode([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if version != tc.version {
+				t.Fatalf("expected version %d, got %d", tc.version, version)
+			}
+			if got.SchemaVersion != CurrentVersion {
+				t.Fatalf("expected upgraded record at version %d, got %d", CurrentVersion, got.SchemaVersion)
+			}
+			got.SchemaVersion = 0
+			metadata := got.Metadata
+			got.Metadata = nil
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected record\n got %+v\nwant %+v", got, tc.want)
+			}
+			if tc.version == 0 && metadata["attempt"] != float64(2) {
+				t.Fatalf("expected v0 metadata kept as JSON values, got %v", metadata)
+			}
+			if tc.version == 1 && metadata["project_id"] != "p-1" {
+				t.Fatalf("expected v1 metadata carried over, got %v", metadata)
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	record := Record{RecordID: "r-1", OrganizationID: "org-1", Status: StatusSuccess, Metadata: map[string]any{"project_id": "p-1"}}
+	data, err := Encode(record)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if version, _ := Version(data); version != CurrentVersion {
+		t.Fatalf("expected encoded version %d, got %d", CurrentVersion, version)
+	}
+	got, _, err := Decode(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.RecordID != "r-1" || got.Metadata["project_id"] != "p-1" {
+		t.Fatalf("unexpected round trip %+v", got)
+	}
+}
+
+func TestDecodeRejectsInvalidRecords(t *testing.T) {
+	if _, _, err := Decode([]byte(`{"schema_version":-1}`)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if _, _, err := Decode([]byte(`not json`)); err == nil {
+		t.Fatal("expected error for malformed record")
+	}
+	if _, _, err := Decode([]byte(`{"schema_version":2,"tokens_input":"many"}`)); err == nil {
+		t.Fatal("expected error for mistyped field")
+	}
+}